@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/usecase"
+)
+
+func TestReport(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, report(&out, &usecase.AuditVerification{
+		Entries:   12,
+		Unchained: 3,
+		Anchors:   2,
+		HeadSeq:   15,
+		HeadHash:  "ab12",
+		Problems:  []usecase.AuditProblem{},
+	}, false))
+	assert.Equal(t, "Checked 12 entries against 2 anchors; 3 entries recorded before chaining were skipped\n"+
+		"Head: entry 15, hash ab12\n"+
+		"The audit chain is intact\n", out.String())
+
+	out.Reset()
+	require.NoError(t, report(&out, &usecase.AuditVerification{
+		Entries:  4,
+		HeadSeq:  5,
+		HeadHash: "cd34",
+		Problems: []usecase.AuditProblem{{Seq: 4, Reason: "entry 3 is missing"}},
+	}, false))
+	assert.Contains(t, out.String(), "The audit chain is broken:\n  entry 4: entry 3 is missing\n")
+}
+
+func TestReport_JSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, report(&out, &usecase.AuditVerification{
+		Entries:  1,
+		Problems: []usecase.AuditProblem{{Seq: 1, Reason: "content does not match its hash"}},
+	}, true))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, float64(1), decoded["entries"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"seq": float64(1), "reason": "content does not match its hash"}}, decoded["problems"])
+}
@@ -0,0 +1,146 @@
+// Command eventreplay replays domain events recorded as they were
+// published, to recover consumers that lost them. It asks the admin
+// listener of a server to publish the selected events again, on their
+// topic or another one, or to hand them to one event handler of that
+// server. It exits with 1 when an event could not be replayed and 2 when
+// the replay could not be requested.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"clean-architecture/internal/interfaces/http/handlers"
+	"clean-architecture/internal/usecase"
+)
+
+func main() {
+	admin := flag.String("admin", "http://localhost:8081", "Base URL of the admin listener of the server replaying the events")
+	ids := flag.String("ids", "", "Comma-separated IDs of the events to replay, used instead of the filters")
+	eventType := flag.String("type", "", "Replay events of this type")
+	aggregate := flag.String("aggregate", "", "Replay events of this aggregate, such as a user ID")
+	correlationID := flag.String("correlation", "", "Replay events caused by the API call with this correlation ID")
+	since := flag.String("since", "", "Replay events published at or after this RFC 3339 time")
+	until := flag.String("until", "", "Replay events published before this RFC 3339 time")
+	failed := flag.Bool("failed", false, "Replay only events the broker refused")
+	topic := flag.String("topic", "", "Publish the events on this topic instead of their own")
+	handler := flag.String("handler", "", "Hand the events to this event handler only instead of publishing them")
+	timeout := flag.Duration("timeout", time.Minute, "Timeout of the replay request")
+	asJSON := flag.Bool("json", false, "Print the result as JSON")
+	flag.Parse()
+
+	req, err := replayRequest(*ids, *eventType, *aggregate, *correlationID, *since, *until, *failed, *topic, *handler)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "eventreplay:", err)
+		os.Exit(2)
+	}
+	result, err := replay(&http.Client{Timeout: *timeout}, *admin, req)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "eventreplay:", err)
+		os.Exit(2)
+	}
+	if err := report(os.Stdout, result, *asJSON); err != nil {
+		fmt.Fprintln(os.Stderr, "eventreplay:", err)
+		os.Exit(2)
+	}
+	if len(result.Failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+// replayRequest builds the body of the replay request from the flags
+func replayRequest(ids, eventType, aggregate, correlationID, since, until string, failed bool, topic, handler string) (handlers.ReplayEventsRequest, error) {
+	req := handlers.ReplayEventsRequest{
+		ReplayEventRequest: handlers.ReplayEventRequest{Topic: topic, Handler: handler},
+		Type:               eventType,
+		AggregateID:        aggregate,
+		CorrelationID:      correlationID,
+		Failed:             failed,
+	}
+	for _, id := range strings.Split(ids, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			req.IDs = append(req.IDs, id)
+		}
+	}
+	var err error
+	if req.Since, err = parseTime("since", since); err != nil {
+		return req, err
+	}
+	if req.Until, err = parseTime("until", until); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+// parseTime parses the RFC 3339 value of the flag called name, zero when
+// it is empty
+func parseTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return t, fmt.Errorf("-%s must be an RFC 3339 time such as 2026-10-01T00:00:00Z", name)
+	}
+	return t, nil
+}
+
+// replay asks the admin listener at admin to replay the events of req
+func replay(client *http.Client, admin string, req handlers.ReplayEventsRequest) (*usecase.ReplayResult, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(context.Background(), http.MethodPost, strings.TrimSuffix(admin, "/")+"/events/replay", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Status  string                `json:"status"`
+		Message string                `json:"message"`
+		Data    *usecase.ReplayResult `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("replay events: %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK || response.Status != "success" || response.Data == nil {
+		return nil, fmt.Errorf("replay events: %s: %s", resp.Status, response.Message)
+	}
+	return response.Data, nil
+}
+
+// report writes result to w, as JSON or as lines for people
+func report(w io.Writer, result *usecase.ReplayResult, asJSON bool) error {
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(result)
+	}
+
+	fmt.Fprintf(w, "Replayed %d events", len(result.Replayed))
+	if len(result.Failed) > 0 {
+		fmt.Fprintf(w, ", %d failed:", len(result.Failed))
+	}
+	fmt.Fprintln(w)
+	for _, failure := range result.Failed {
+		if _, err := fmt.Fprintf(w, "  %s: %s\n", failure.ID, failure.Error); err != nil {
+			return err
+		}
+	}
+	return nil
+}
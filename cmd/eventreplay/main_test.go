@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/usecase"
+)
+
+func TestReplayRequest(t *testing.T) {
+	req, err := replayRequest("evt_1, evt_2,", "user.created", "", "", "2026-10-01T00:00:00Z", "", true, "", "webhooks")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"evt_1", "evt_2"}, req.IDs)
+	assert.Equal(t, "user.created", req.Type)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), req.Since)
+	assert.True(t, req.Until.IsZero())
+	assert.True(t, req.Failed)
+	assert.Equal(t, "webhooks", req.Handler)
+
+	_, err = replayRequest("", "", "", "", "", "yesterday", false, "", "")
+	assert.EqualError(t, err, "-until must be an RFC 3339 time such as 2026-10-01T00:00:00Z")
+}
+
+func TestReplay(t *testing.T) {
+	var got map[string]interface{}
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/events/replay" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":"error","message":"Endpoint not found"}`)) //nolint:errcheck
+			return
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		if got["handler"] == "mailer" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"status":"error","message":"unknown event handler"}`)) //nolint:errcheck
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"replayed":["evt_1"],"failed":[{"id":"evt_9","error":"published event not found"}]}}`)) //nolint:errcheck
+	}))
+	defer admin.Close()
+
+	req, err := replayRequest("evt_1,evt_9", "", "", "", "", "", false, "users.rebuild", "")
+	require.NoError(t, err)
+	result, err := replay(admin.Client(), admin.URL+"/", req)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"evt_1", "evt_9"}, got["ids"])
+	assert.Equal(t, "users.rebuild", got["topic"])
+	assert.Equal(t, []string{"evt_1"}, result.Replayed)
+
+	var out bytes.Buffer
+	require.NoError(t, report(&out, result, false))
+	assert.Equal(t, "Replayed 1 events, 1 failed:\n  evt_9: published event not found\n", out.String())
+
+	req.Topic, req.Handler = "", "mailer"
+	_, err = replay(admin.Client(), admin.URL, req)
+	assert.EqualError(t, err, "replay events: 422 Unprocessable Entity: unknown event handler")
+}
+
+func TestReport_JSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, report(&out, &usecase.ReplayResult{Replayed: []string{"evt_1"}, Failed: []usecase.ReplayFailure{}}, true))
+	assert.JSONEq(t, `{"replayed":["evt_1"],"failed":[]}`, out.String())
+}
@@ -0,0 +1,153 @@
+// Command replay sends a request captured by a server running with
+// REPLAY_ENABLED again to another environment, to reproduce a bug seen
+// there. It fetches the capture from the admin listener of that server, or
+// reads one saved with -print, and prints the response the target returned.
+// Credentials redacted from the capture are supplied again with -H. It
+// exits with 1 when the request could not be replayed.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"clean-architecture/pkg/replay"
+)
+
+func main() {
+	admin := flag.String("admin", "http://localhost:8081", "Base URL of the admin listener holding the capture")
+	id := flag.String("id", "", "ID of the captured request to replay")
+	file := flag.String("file", "", "Path to a capture saved with -print, used instead of -admin and -id")
+	target := flag.String("target", "", "Base URL of the environment to replay the request against")
+	printOnly := flag.Bool("print", false, "Print the capture as JSON instead of replaying it")
+	timeout := flag.Duration("timeout", 30*time.Second, "Timeout of the admin and replayed requests")
+	headers := headerFlag{}
+	flag.Var(headers, "H", `Header to replace on the replayed request, as "Name: value" (repeatable)`)
+	flag.Parse()
+
+	if err := run(os.Stdout, *admin, *id, *file, *target, *printOnly, http.Header(headers), *timeout); err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		os.Exit(1)
+	}
+}
+
+// run loads the capture and prints it or replays it against target
+func run(w io.Writer, admin, id, file, target string, printOnly bool, header http.Header, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	client := &http.Client{Timeout: timeout}
+
+	if !printOnly && target == "" {
+		return errors.New("-target is required")
+	}
+	captured, err := load(ctx, client, admin, id, file)
+	if err != nil {
+		return err
+	}
+	if printOnly {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(captured)
+	}
+
+	result, err := replay.Send(ctx, client, captured, target, header)
+	if err != nil {
+		return err
+	}
+	return report(w, captured, result)
+}
+
+// load reads the capture from file or, when file is empty, fetches capture
+// id from the admin listener at admin
+func load(ctx context.Context, client *http.Client, admin, id, file string) (replay.Request, error) {
+	var captured replay.Request
+	if file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return captured, err
+		}
+		if err := json.Unmarshal(b, &captured); err != nil {
+			return captured, fmt.Errorf("parse %s: %w", file, err)
+		}
+		return captured, nil
+	}
+	if id == "" {
+		return captured, errors.New("-id or -file is required")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(admin, "/")+"/replay/requests/"+url.PathEscape(id), nil)
+	if err != nil {
+		return captured, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return captured, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Message string         `json:"message"`
+		Data    replay.Request `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return captured, fmt.Errorf("fetch capture %s: %s", id, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return captured, fmt.Errorf("fetch capture %s: %s: %s", id, resp.Status, body.Message)
+	}
+	return body.Data, nil
+}
+
+// report writes the response result of replaying captured to w
+func report(w io.Writer, captured replay.Request, result *replay.Result) error {
+	fmt.Fprintf(w, "%s %s -> %d in %s\n", captured.Method, captured.Path, result.Status, result.Duration.Round(time.Millisecond))
+	if captured.Truncated {
+		fmt.Fprintln(w, "Warning: the captured body was truncated; only part of it was sent")
+	}
+	if captured.BodyOmitted != "" {
+		fmt.Fprintf(w, "Warning: no body was sent; %s\n", captured.BodyOmitted)
+	}
+
+	names := make([]string, 0, len(result.Header))
+	for name := range result.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range result.Header[name] {
+			fmt.Fprintf(w, "%s: %s\n", name, value)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, result.Body)
+	if result.Truncated {
+		_, err := fmt.Fprintln(w, "(response body truncated)")
+		return err
+	}
+	return nil
+}
+
+// headerFlag collects repeated -H "Name: value" flags
+type headerFlag http.Header
+
+func (h headerFlag) String() string {
+	return ""
+}
+
+func (h headerFlag) Set(value string) error {
+	name, v, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header %q is not of the form \"Name: value\"", value)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(v))
+	return nil
+}
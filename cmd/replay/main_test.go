@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/replay"
+)
+
+func TestLoad(t *testing.T) {
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/replay/requests/cap_1" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":"error","message":"Captured request not found"}`)) //nolint:errcheck
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"id":"cap_1","method":"POST","path":"/api/v1/users","body":"{}"}}`)) //nolint:errcheck
+	}))
+	defer admin.Close()
+
+	captured, err := load(context.Background(), admin.Client(), admin.URL+"/", "cap_1", "")
+	require.NoError(t, err)
+	assert.Equal(t, replay.Request{ID: "cap_1", Method: "POST", Path: "/api/v1/users", Body: "{}"}, captured)
+
+	_, err = load(context.Background(), admin.Client(), admin.URL, "cap_9", "")
+	assert.ErrorContains(t, err, "404 Not Found: Captured request not found")
+
+	file := filepath.Join(t.TempDir(), "capture.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"id":"cap_2","method":"GET","path":"/api/v1/audit"}`), 0o600))
+	captured, err = load(context.Background(), admin.Client(), admin.URL, "", file)
+	require.NoError(t, err)
+	assert.Equal(t, "cap_2", captured.ID)
+
+	_, err = load(context.Background(), admin.Client(), admin.URL, "", "")
+	assert.ErrorContains(t, err, "-id or -file is required")
+}
+
+func TestRun(t *testing.T) {
+	var got *http.Request
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"status":"error"}`)) //nolint:errcheck
+	}))
+	defer target.Close()
+
+	file := filepath.Join(t.TempDir(), "capture.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"id":"cap_1","method":"POST","path":"/api/v1/users","header":{"Authorization":["[REDACTED]"]},"body":"{}"}`), 0o600))
+
+	var out bytes.Buffer
+	require.NoError(t, run(&out, "", "", file, target.URL, false, http.Header{"Authorization": {"Bearer staging"}}, time.Second))
+	require.NotNil(t, got)
+	assert.Equal(t, "Bearer staging", got.Header.Get("Authorization"))
+	assert.Contains(t, out.String(), "POST /api/v1/users -> 409 in ")
+	assert.Contains(t, out.String(), "Content-Type: application/json\n")
+	assert.Contains(t, out.String(), "\n\n{\"status\":\"error\"}\n")
+
+	out.Reset()
+	require.NoError(t, run(&out, "", "", file, "", true, nil, time.Second))
+	assert.Contains(t, out.String(), `"id": "cap_1"`)
+
+	assert.ErrorContains(t, run(&out, "", "", file, "", false, nil, time.Second), "-target is required")
+}
+
+func TestHeaderFlag(t *testing.T) {
+	headers := headerFlag{}
+	require.NoError(t, headers.Set("Authorization: Bearer abc"))
+	require.NoError(t, headers.Set("x-tenant:acme"))
+	assert.Equal(t, headerFlag{"Authorization": {"Bearer abc"}, "X-Tenant": {"acme"}}, headers)
+	assert.Error(t, headers.Set("Authorization"))
+}
@@ -0,0 +1,84 @@
+// Command schemagen writes the JSON Schemas of the DTOs listed in
+// handlers.SchemaTypes to a directory, one {name}.json file each, and removes
+// schemas of types no longer listed. It runs through go generate ./docs.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"clean-architecture/internal/interfaces/http/handlers"
+	"clean-architecture/pkg/jsonschema"
+)
+
+func main() {
+	out := flag.String("out", "schemas", "Directory to write the schemas to")
+	flag.Parse()
+
+	if err := write(*out); err != nil {
+		fmt.Fprintln(os.Stderr, "schemagen:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the encoded schema of every published type, keyed by
+// file name
+func generate() (map[string][]byte, error) {
+	files := make(map[string][]byte, len(handlers.SchemaTypes))
+	for _, schemaType := range handlers.SchemaTypes {
+		schema, err := jsonschema.For(schemaType.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", schemaType.Name, err)
+		}
+		schema.Schema = jsonschema.Draft
+		schema.ID = "/api/v1/schemas/" + schemaType.Name
+		schema.Title = reflect.TypeOf(schemaType.Value).Name()
+		schema.Description = schemaType.Description
+
+		encoded, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", schemaType.Name, err)
+		}
+
+		file := schemaType.Name + ".json"
+		if _, ok := files[file]; ok {
+			return nil, fmt.Errorf("%s is listed more than once", schemaType.Name)
+		}
+		files[file] = append(encoded, '\n')
+	}
+	return files, nil
+}
+
+// write replaces the schemas in dir with freshly generated ones
+func write(dir string) error {
+	files, err := generate()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, path := range existing {
+		if _, ok := files[filepath.Base(path)]; !ok {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemasDir holds the schemas embedded by the docs package
+const schemasDir = "../../docs/schemas"
+
+func TestSchemasAreUpToDate(t *testing.T) {
+	files, err := generate()
+	require.NoError(t, err)
+
+	existing, err := filepath.Glob(filepath.Join(schemasDir, "*.json"))
+	require.NoError(t, err)
+	names := make([]string, 0, len(existing))
+	for _, path := range existing {
+		names = append(names, filepath.Base(path))
+	}
+
+	expected := make([]string, 0, len(files))
+	for file, content := range files {
+		expected = append(expected, file)
+		current, err := os.ReadFile(filepath.Join(schemasDir, file))
+		if assert.NoError(t, err, "run go generate ./docs") {
+			assert.Equal(t, string(content), string(current), "%s is out of date, run go generate ./docs", file)
+		}
+	}
+	sort.Strings(expected)
+	assert.Equal(t, expected, names, "run go generate ./docs")
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "removed.json"), []byte("{}"), 0o644))
+
+	require.NoError(t, write(dir))
+
+	_, err := os.Stat(filepath.Join(dir, "removed.json"))
+	assert.True(t, os.IsNotExist(err), "schemas of unlisted types are removed")
+	content, err := os.ReadFile(filepath.Join(dir, "create_user_request.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"required": [`)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/pflag"
+
+	"clean-architecture/configs"
+)
+
+// cliFlags holds command-line overrides for configuration.
+//
+// Precedence, from highest to lowest:
+//  1. command-line flags
+//  2. environment variables
+//  3. the --config file
+//  4. built-in defaults
+type cliFlags struct {
+	fs         *pflag.FlagSet
+	configFile string
+	port       string
+	logLevel   string
+	dbDSN      string
+}
+
+// parseFlags parses command-line arguments
+func parseFlags(args []string, output io.Writer) (*cliFlags, error) {
+	f := &cliFlags{fs: pflag.NewFlagSet("server", pflag.ContinueOnError)}
+	f.fs.SetOutput(output)
+
+	f.fs.StringVar(&f.configFile, "config", "", "path to a KEY=VALUE config file (overridden by environment variables)")
+	f.fs.StringVar(&f.port, "port", "", "HTTP port to listen on (overrides SERVER_PORT)")
+	f.fs.StringVar(&f.logLevel, "log-level", "", "log level: debug, info, warn or error (overrides LOG_LEVEL)")
+	f.fs.StringVar(&f.dbDSN, "db-dsn", "", "PostgreSQL DSN (overrides DATABASE_DSN and individual DATABASE_* settings)")
+
+	if err := f.fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// loadConfig loads configuration from the config file, environment and
+// flags, in increasing order of precedence
+func (f *cliFlags) loadConfig() (*configs.Config, error) {
+	if f.configFile != "" {
+		if err := configs.LoadFile(f.configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := configs.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if f.fs.Changed("port") {
+		cfg.Server.Port = f.port
+	}
+	if f.fs.Changed("log-level") {
+		cfg.Log.Level = f.logLevel
+	}
+	if f.fs.Changed("db-dsn") {
+		cfg.Database.DSN = f.dbDSN
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setEnv sets an environment variable for the duration of the test
+func setEnv(t *testing.T, key, value string) {
+	original, present := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if present {
+			os.Setenv(key, original)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// unsetEnv removes an environment variable for the duration of the test
+func unsetEnv(t *testing.T, key string) {
+	original, present := os.LookupEnv(key)
+	os.Unsetenv(key)
+	t.Cleanup(func() {
+		if present {
+			os.Setenv(key, original)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+func TestLoadConfig_Precedence(t *testing.T) {
+	for _, key := range []string{"SERVER_PORT", "SERVER_HOST", "LOG_LEVEL", "DATABASE_DSN"} {
+		unsetEnv(t, key)
+	}
+
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "app.env")
+	require.NoError(t, os.WriteFile(configFile, []byte(
+		"# file values\nSERVER_PORT=7000\nSERVER_HOST=file-host\nLOG_LEVEL=warn\n",
+	), 0o600))
+
+	// Environment overrides the file, flags override the environment
+	setEnv(t, "SERVER_HOST", "env-host")
+	setEnv(t, "LOG_LEVEL", "error")
+
+	flags, err := parseFlags([]string{
+		"--config", configFile,
+		"--log-level", "debug",
+		"--db-dsn", "host=db user=app",
+	}, io.Discard)
+	require.NoError(t, err)
+
+	cfg, err := flags.loadConfig()
+	require.NoError(t, err)
+
+	assert.Equal(t, "7000", cfg.Server.Port)
+	assert.Equal(t, "env-host", cfg.Server.Host)
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.Equal(t, "host=db user=app", cfg.Database.DSN)
+}
+
+func TestLoadConfig_PortFlag(t *testing.T) {
+	setEnv(t, "SERVER_PORT", "9000")
+
+	flags, err := parseFlags([]string{"--port", "9100"}, io.Discard)
+	require.NoError(t, err)
+
+	cfg, err := flags.loadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "9100", cfg.Server.Port)
+}
+
+func TestLoadConfig_InvalidFlagValue(t *testing.T) {
+	flags, err := parseFlags([]string{"--log-level", "verbose"}, io.Discard)
+	require.NoError(t, err)
+
+	_, err = flags.loadConfig()
+	assert.EqualError(t, err, `invalid LOG_LEVEL="verbose": must be one of debug, info, warn or error`)
+}
+
+func TestParseFlags_Unknown(t *testing.T) {
+	_, err := parseFlags([]string{"--unknown"}, io.Discard)
+	assert.Error(t, err)
+}
@@ -21,59 +21,144 @@ package main
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
+
+	"github.com/spf13/pflag"
 
 	_ "clean-architecture/docs" // This is required for swagger docs
 	"clean-architecture/internal/app"
+	"clean-architecture/pkg/httpserver"
 	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/shutdown"
 )
 
 func main() {
-	// Initialize logger
-	logger := logger.New()
+	// Parse command-line flags and load configuration
+	flags, err := parseFlags(os.Args[1:], os.Stderr)
+	if err != nil {
+		if err == pflag.ErrHelp {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+
+	cfg, err := flags.loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Configuration error:", err)
+		os.Exit(1)
+	}
+
+	// Initialize the root and module loggers
+	logs := logger.NewRegistry(cfg.Log.Level)
+	logger := logs.Root()
 
 	// Create application context
-	appCtx := app.NewApp(logger)
+	appCtx := app.NewApp(logs, cfg)
+	appCtx.Startup.Begin("background processing", "event consumer", "job queue", "rabbitmq", "locks", "worker pool", "cron")
+	if err := appCtx.Start(appCtx.Context()); err != nil {
+		logger.Fatal("Failed to start background processing: " + err.Error())
+	}
+
+	servers := appCtx.NewServers()
+
+	// Inherit listeners from a previous process when graceful upgrades are enabled
+	appCtx.Startup.Begin("listeners", "routers")
+	var upgrader *gracefulUpgrader
+	var upgraded <-chan struct{}
+	if cfg.Server.GracefulUpgrade {
+		upgrader, err = newGracefulUpgrader(cfg.Server, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize graceful upgrader: " + err.Error())
+		}
+		defer upgrader.Stop()
+		servers.Listen = upgrader.Listen
+		upgraded = upgrader.Exit()
+	}
 
-	// Create HTTP server using configuration
-	serverAddr := fmt.Sprintf("%s:%s", appCtx.Config.Server.Host, appCtx.Config.Server.Port)
-	server := &http.Server{
-		Addr:    serverAddr,
-		Handler: appCtx.Router,
+	// Start the API, admin and health listeners
+	serverErrors := make(chan error, len(servers.Servers()))
+	if err := servers.Start(serverErrors); err != nil {
+		logger.Fatal("Server error: " + err.Error())
 	}
+	for _, s := range servers.Servers() {
+		logger.Info("Starting " + s.Name + " server on " + s.Addr().String())
+	}
+	appCtx.Startup.Finish()
+	appCtx.Startup.Log(logger)
 
-	// Start server in a goroutine
-	go func() {
-		logger.Info("Starting server on " + serverAddr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("Server error: " + err.Error())
+	// Only let a parent process exit once this one is verified healthy
+	if upgrader != nil {
+		handoffCtx, cancelHandoff := context.WithTimeout(context.Background(), cfg.Server.UpgradeTimeout)
+		err := upgrader.CompleteHandoff(handoffCtx, readinessURL(servers))
+		cancelHandoff()
+		if err != nil {
+			logger.Fatal("Graceful upgrade aborted: " + err.Error())
 		}
-	}()
+		go upgrader.HandleSignals()
+	}
 
-	// Wait for interrupt signal to gracefully shutdown the server
+	// Wait for interrupt signal, an upgrade handoff or a listener failure to
+	// shut down the servers
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	var reason string
+	select {
+	case sig := <-quit:
+		reason = "signal " + sig.String()
+	case <-upgraded:
+		reason = "graceful upgrade"
+		logger.Info("New process took over listeners, draining")
+	case err := <-serverErrors:
+		reason = "server error"
+		logger.Error("Server error: " + err.Error())
+	}
 
 	logger.Info("Shutting down server...")
+	report := shutdown.NewReport(reason)
+	servers.OnShutdown = func(result httpserver.ShutdownResult) {
+		report.Add(shutdown.Step{
+			Phase:    "http",
+			Name:     result.Server.Name,
+			Started:  result.Started,
+			Duration: result.Duration,
+			Error:    errorString(result.Err),
+			Aborted:  result.Forced,
+		})
+	}
+
+	// Fail readiness probes first so load balancers stop routing traffic
+	appCtx.HealthHandler.SetDraining()
 
 	// Create a deadline for server shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), appCtx.Config.Server.ShutdownTimeout)
 	defer cancel()
 
-	// Attempt graceful shutdown
-	if err := server.Shutdown(ctx); err != nil {
+	// Attempt graceful shutdown, API first and health probes last
+	if err := servers.Shutdown(ctx); err != nil {
 		logger.Error("Server forced to shutdown: " + err.Error())
 	}
 
 	// Shutdown application
-	if err := appCtx.Shutdown(ctx); err != nil {
+	if err := appCtx.Shutdown(ctx, report); err != nil {
 		logger.Error("Application shutdown error: " + err.Error())
 	}
 
+	report.Finish()
+	report.Log(logger)
+	if path := cfg.Server.ShutdownReportFile; path != "" {
+		if err := report.WriteFile(path); err != nil {
+			logger.Error("Failed to write shutdown report: " + err.Error())
+		}
+	}
+
 	logger.Info("Server exited")
 }
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cloudflare/tableflip"
+
+	"clean-architecture/configs"
+	"clean-architecture/pkg/httpserver"
+	"clean-architecture/pkg/logger"
+)
+
+// healthPollInterval is how often a new process probes its own readiness
+// before completing an upgrade handoff
+const healthPollInterval = 200 * time.Millisecond
+
+// gracefulUpgrader hands listeners over to a newly started binary on SIGHUP.
+// The old process keeps serving until the new one reports ready.
+type gracefulUpgrader struct {
+	upg    *tableflip.Upgrader
+	logger logger.Logger
+}
+
+// newGracefulUpgrader creates an upgrader, inheriting listeners from the
+// parent process if there is one
+func newGracefulUpgrader(cfg configs.ServerConfig, logger logger.Logger) (*gracefulUpgrader, error) {
+	upg, err := tableflip.New(tableflip.Options{
+		UpgradeTimeout: cfg.UpgradeTimeout,
+		PIDFile:        cfg.PIDFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &gracefulUpgrader{upg: upg, logger: logger}, nil
+}
+
+// Listen returns an inherited listener for addr, or opens a new one
+func (u *gracefulUpgrader) Listen(network, addr string) (net.Listener, error) {
+	return u.upg.Listen(network, addr)
+}
+
+// CompleteHandoff waits until the process answers its own readiness probe and
+// then tells the parent, if any, that it can drain and exit
+func (u *gracefulUpgrader) CompleteHandoff(ctx context.Context, readinessURL string) error {
+	if err := waitHealthy(ctx, readinessURL, healthPollInterval); err != nil {
+		return fmt.Errorf("new process did not become healthy: %w", err)
+	}
+	if u.upg.HasParent() {
+		u.logger.Info("New process is healthy, completing upgrade handoff")
+	}
+	return u.upg.Ready()
+}
+
+// HandleSignals triggers an upgrade whenever SIGHUP is received
+func (u *gracefulUpgrader) HandleSignals() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	for range sig {
+		u.logger.Info("Received SIGHUP, starting graceful upgrade")
+		if err := u.upg.Upgrade(); err != nil {
+			u.logger.Error("Graceful upgrade failed: " + err.Error())
+		}
+	}
+}
+
+// Exit is closed once a new process has taken over the listeners
+func (u *gracefulUpgrader) Exit() <-chan struct{} {
+	return u.upg.Exit()
+}
+
+// Stop releases the upgrader's resources
+func (u *gracefulUpgrader) Stop() {
+	u.upg.Stop()
+}
+
+// readinessURL returns the URL a process can probe to check its own
+// readiness, preferring the dedicated health listener
+func readinessURL(servers *httpserver.Group) string {
+	var api string
+	for _, s := range servers.Servers() {
+		switch s.Name {
+		case "health":
+			return "http://" + s.Addr().String() + "/health/ready"
+		case "api":
+			api = "http://" + s.Addr().String() + "/health"
+		}
+	}
+	return api
+}
+
+// waitHealthy polls url until it answers 200 OK or ctx is done
+func waitHealthy(ctx context.Context, url string, interval time.Duration) error {
+	client := &http.Client{Timeout: interval * 5}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := client.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
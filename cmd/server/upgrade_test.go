@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/httpserver"
+)
+
+func TestWaitHealthy(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := waitHealthy(ctx, server.URL, 10*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestWaitHealthy_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := waitHealthy(ctx, server.URL, 10*time.Millisecond)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestReadinessURL(t *testing.T) {
+	newGroup := func(names ...string) *httpserver.Group {
+		group := httpserver.NewGroup()
+		for _, name := range names {
+			group.Add(name, &http.Server{Addr: "127.0.0.1:0", Handler: http.NotFoundHandler()})
+		}
+		require.NoError(t, group.Start(make(chan error, len(names))))
+		t.Cleanup(func() { _ = group.Shutdown(context.Background()) })
+		return group
+	}
+
+	t.Run("prefers health listener", func(t *testing.T) {
+		group := newGroup("api", "health")
+		expected := "http://" + group.Servers()[1].Addr().String() + "/health/ready"
+		assert.Equal(t, expected, readinessURL(group))
+	})
+
+	t.Run("falls back to api listener", func(t *testing.T) {
+		group := newGroup("api")
+		expected := "http://" + group.Servers()[0].Addr().String() + "/health"
+		assert.Equal(t, expected, readinessURL(group))
+	})
+}
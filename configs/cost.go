@@ -0,0 +1,85 @@
+package configs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CostBudget bounds the costs of each request to a single route. Zero
+// limits are not enforced.
+type CostBudget struct {
+	Method string
+	// Route is the chi route pattern, e.g. /api/v1/users/{id}
+	Route         string
+	Queries       int64
+	Rows          int64
+	ExternalCalls int64
+}
+
+// CostBudgets is a list of route cost budgets parsed from entries separated
+// by semicolons, each written as METHOD ROUTE LIMIT=N..., where the limits
+// are queries, rows and external_calls, e.g.
+// "GET /api/v1/users queries=3 rows=500; GET /api/v1/users/{id} queries=2"
+type CostBudgets []CostBudget
+
+// ParseCostBudgets parses a list of route cost budgets
+func ParseCostBudgets(value string) (CostBudgets, error) {
+	var budgets CostBudgets
+	seen := make(map[string]bool)
+
+	for _, entry := range strings.Split(value, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("budget %q must be METHOD ROUTE LIMIT=N...", strings.TrimSpace(entry))
+		}
+
+		budget := CostBudget{
+			Method: strings.ToUpper(fields[0]),
+			Route:  fields[1],
+		}
+		if !strings.HasPrefix(budget.Route, "/") {
+			return nil, fmt.Errorf("budget %q: route must start with /", strings.TrimSpace(entry))
+		}
+
+		for _, field := range fields[2:] {
+			name, number, ok := strings.Cut(field, "=")
+			limit, err := strconv.ParseInt(number, 10, 64)
+			if !ok || err != nil || limit <= 0 {
+				return nil, fmt.Errorf("budget %q: limit %q must be written as NAME=N with N a positive integer", strings.TrimSpace(entry), field)
+			}
+			switch name {
+			case "queries":
+				budget.Queries = limit
+			case "rows":
+				budget.Rows = limit
+			case "external_calls":
+				budget.ExternalCalls = limit
+			default:
+				return nil, fmt.Errorf("budget %q: unknown limit %q, expected queries, rows or external_calls", strings.TrimSpace(entry), name)
+			}
+		}
+
+		key := budget.Method + " " + budget.Route
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate budget for %s", key)
+		}
+		seen[key] = true
+		budgets = append(budgets, budget)
+	}
+
+	return budgets, nil
+}
+
+// Decode implements envconfig.Decoder
+func (b *CostBudgets) Decode(value string) error {
+	budgets, err := ParseCostBudgets(value)
+	if err != nil {
+		return err
+	}
+	*b = budgets
+	return nil
+}
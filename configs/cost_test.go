@@ -0,0 +1,43 @@
+package configs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCostBudgets(t *testing.T) {
+	budgets, err := ParseCostBudgets("GET /api/v1/users queries=3 rows=500; get /api/v1/users/{id} external_calls=1 ;")
+	require.NoError(t, err)
+	require.Len(t, budgets, 2)
+
+	assert.Equal(t, CostBudget{Method: "GET", Route: "/api/v1/users", Queries: 3, Rows: 500}, budgets[0])
+	assert.Equal(t, CostBudget{Method: "GET", Route: "/api/v1/users/{id}", ExternalCalls: 1}, budgets[1])
+
+	budgets, err = ParseCostBudgets("")
+	require.NoError(t, err)
+	assert.Empty(t, budgets)
+}
+
+func TestParseCostBudgets_Invalid(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "GET /api/v1/users", expected: "must be METHOD ROUTE LIMIT=N..."},
+		{input: "GET api/v1/users queries=3", expected: "route must start with /"},
+		{input: "GET /api/v1/users queries", expected: `limit "queries" must be written as NAME=N`},
+		{input: "GET /api/v1/users queries=0", expected: `limit "queries=0" must be written as NAME=N with N a positive integer`},
+		{input: "GET /api/v1/users calls=2", expected: `unknown limit "calls"`},
+		{input: "GET /a queries=1; GET /a rows=2", expected: "duplicate budget for GET /a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseCostBudgets(tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.env")
+	content := `# comment
+TEST_CONFIG_PLAIN=plain
+export TEST_CONFIG_EXPORTED=exported
+TEST_CONFIG_QUOTED="quoted value"
+TEST_CONFIG_EXISTING=from-file
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	os.Setenv("TEST_CONFIG_EXISTING", "from-env")
+	defer func() {
+		for _, key := range []string{"TEST_CONFIG_PLAIN", "TEST_CONFIG_EXPORTED", "TEST_CONFIG_QUOTED", "TEST_CONFIG_EXISTING"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	err := LoadFile(path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "plain", os.Getenv("TEST_CONFIG_PLAIN"))
+	assert.Equal(t, "exported", os.Getenv("TEST_CONFIG_EXPORTED"))
+	assert.Equal(t, "quoted value", os.Getenv("TEST_CONFIG_QUOTED"))
+	assert.Equal(t, "from-env", os.Getenv("TEST_CONFIG_EXISTING"))
+}
+
+func TestLoadFile_Errors(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		assert.Error(t, LoadFile("/does/not/exist.env"))
+	})
+
+	t.Run("malformed line", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bad.env")
+		require.NoError(t, os.WriteFile(path, []byte("NOT_A_PAIR\n"), 0o600))
+
+		err := LoadFile(path)
+		assert.EqualError(t, err, path+":1: expected KEY=VALUE")
+	})
+}
@@ -0,0 +1,108 @@
+package configs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SAMLTenant is the SAML identity provider of one tenant
+type SAMLTenant struct {
+	// ID names the tenant in its URLs below /api/v1/auth/saml/{id}
+	ID string `json:"id"`
+
+	// The identity provider is described by the metadata at
+	// IDPMetadataURL, which must be https, or in IDPMetadataFile
+	IDPMetadataURL  string `json:"idp_metadata_url"`
+	IDPMetadataFile string `json:"idp_metadata_file"`
+
+	// Attributes mapped to user fields and roles; empty names fall back to
+	// the common names for each, and the email to an email NameID
+	EmailAttribute  string `json:"email_attribute"`
+	NameAttribute   string `json:"name_attribute"`
+	GroupsAttribute string `json:"groups_attribute"`
+	// EmailDomains limits the emails the identity provider may assert, so
+	// one tenant cannot log in as the users of another
+	EmailDomains []string `json:"email_domains"`
+	// GroupRoles maps group names to policy roles; users in no mapped
+	// group get DefaultRole, or are refused when it is empty
+	GroupRoles  map[string]string `json:"group_roles"`
+	DefaultRole string            `json:"default_role"`
+
+	// RedirectURLs lists the URLs logins may return to with the access
+	// token in the fragment; without one the token is returned as JSON
+	RedirectURLs []string `json:"redirect_urls"`
+}
+
+// samlTenantID matches the IDs tenants are published under
+var samlTenantID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// LoadSAMLTenants reads the JSON file of SAML tenants, an object whose
+// "tenants" key lists them
+func LoadSAMLTenants(path string) ([]SAMLTenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SAML tenants: %w", err)
+	}
+
+	var file struct {
+		Tenants []json.RawMessage `json:"tenants"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(file.Tenants) == 0 {
+		return nil, fmt.Errorf("%s: no tenants", path)
+	}
+
+	tenants := make([]SAMLTenant, 0, len(file.Tenants))
+	seen := make(map[string]bool)
+	for i, raw := range file.Tenants {
+		tenant := SAMLTenant{DefaultRole: "user"}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&tenant); err != nil {
+			return nil, fmt.Errorf("%s: tenant %d: %w", path, i+1, err)
+		}
+		if err := validateSAMLTenant(tenant); err != nil {
+			return nil, fmt.Errorf("%s: tenant %q: %w", path, tenant.ID, err)
+		}
+		if seen[tenant.ID] {
+			return nil, fmt.Errorf("%s: tenant %q is listed twice", path, tenant.ID)
+		}
+		seen[tenant.ID] = true
+		tenants = append(tenants, tenant)
+	}
+	return tenants, nil
+}
+
+func validateSAMLTenant(tenant SAMLTenant) error {
+	if !samlTenantID.MatchString(tenant.ID) {
+		return fmt.Errorf("id must be lowercase letters, digits and dashes")
+	}
+	if (tenant.IDPMetadataURL == "") == (tenant.IDPMetadataFile == "") {
+		return fmt.Errorf("exactly one of idp_metadata_url and idp_metadata_file is required")
+	}
+	if tenant.IDPMetadataURL != "" {
+		if u, err := url.Parse(tenant.IDPMetadataURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("idp_metadata_url must be an https:// URL")
+		}
+	}
+	for _, domain := range tenant.EmailDomains {
+		if domain == "" || strings.ContainsAny(domain, "@/ ") || domain != strings.ToLower(domain) {
+			return fmt.Errorf("email domain %q must be a lowercase domain name", domain)
+		}
+	}
+	for _, redirect := range tenant.RedirectURLs {
+		if u, err := url.Parse(redirect); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") || u.Fragment != "" {
+			return fmt.Errorf("redirect URL %q must be an http:// or https:// URL without a fragment", redirect)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSAMLTenants(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "saml.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadSAMLTenants(t *testing.T) {
+	path := writeSAMLTenants(t, `{"tenants": [
+		{"id": "acme", "idp_metadata_url": "https://acme.okta.com/app/exk1/sso/saml/metadata", "group_roles": {"Admins": "admin"}, "email_domains": ["acme.com"], "redirect_urls": ["https://app.acme.com/sso"]},
+		{"id": "globex", "idp_metadata_file": "/etc/saml/globex.xml", "email_attribute": "upn", "default_role": ""}
+	]}`)
+
+	tenants, err := LoadSAMLTenants(path)
+	require.NoError(t, err)
+	require.Len(t, tenants, 2)
+
+	assert.Equal(t, "acme", tenants[0].ID)
+	assert.Equal(t, map[string]string{"Admins": "admin"}, tenants[0].GroupRoles)
+	assert.Equal(t, "user", tenants[0].DefaultRole, "default role applies when absent")
+	assert.Equal(t, []string{"acme.com"}, tenants[0].EmailDomains)
+	assert.Equal(t, []string{"https://app.acme.com/sso"}, tenants[0].RedirectURLs)
+
+	assert.Equal(t, "/etc/saml/globex.xml", tenants[1].IDPMetadataFile)
+	assert.Equal(t, "upn", tenants[1].EmailAttribute)
+	assert.Empty(t, tenants[1].DefaultRole, "an empty default role refuses unmapped users")
+}
+
+func TestLoadSAMLTenants_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "no tenants", content: `{"tenants": []}`, expected: "no tenants"},
+		{name: "unknown field", content: `{"tenants": [{"id": "acme", "idp_metadata_file": "a.xml", "role": "admin"}]}`, expected: `unknown field "role"`},
+		{name: "invalid id", content: `{"tenants": [{"id": "Acme Corp", "idp_metadata_file": "a.xml"}]}`, expected: "id must be lowercase letters, digits and dashes"},
+		{name: "no metadata", content: `{"tenants": [{"id": "acme"}]}`, expected: "exactly one of idp_metadata_url and idp_metadata_file is required"},
+		{name: "metadata over http", content: `{"tenants": [{"id": "acme", "idp_metadata_url": "http://idp.acme.com/metadata"}]}`, expected: "idp_metadata_url must be an https:// URL"},
+		{name: "email address as domain", content: `{"tenants": [{"id": "acme", "idp_metadata_file": "a.xml", "email_domains": ["@acme.com"]}]}`, expected: `email domain "@acme.com" must be a lowercase domain name`},
+		{name: "relative redirect", content: `{"tenants": [{"id": "acme", "idp_metadata_file": "a.xml", "redirect_urls": ["/sso"]}]}`, expected: `redirect URL "/sso" must be an http:// or https:// URL`},
+		{name: "duplicate", content: `{"tenants": [{"id": "acme", "idp_metadata_file": "a.xml"}, {"id": "acme", "idp_metadata_file": "b.xml"}]}`, expected: `tenant "acme" is listed twice`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadSAMLTenants(writeSAMLTenants(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+
+	_, err := LoadSAMLTenants(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read SAML tenants")
+}
@@ -0,0 +1,88 @@
+package configs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes that can be parsed from human-friendly values
+// such as "512", "64KB" or "10MiB"
+type ByteSize int64
+
+// Size units
+const (
+	Byte     ByteSize = 1
+	Kilobyte          = 1024 * Byte
+	Megabyte          = 1024 * Kilobyte
+	Gigabyte          = 1024 * Megabyte
+)
+
+// sizeUnits maps accepted unit suffixes to their multiplier. Decimal and
+// binary suffixes are both treated as powers of 1024.
+var sizeUnits = map[string]ByteSize{
+	"":    Byte,
+	"B":   Byte,
+	"K":   Kilobyte,
+	"KB":  Kilobyte,
+	"KIB": Kilobyte,
+	"M":   Megabyte,
+	"MB":  Megabyte,
+	"MIB": Megabyte,
+	"G":   Gigabyte,
+	"GB":  Gigabyte,
+	"GIB": Gigabyte,
+}
+
+// ParseByteSize parses a human-friendly size string
+func ParseByteSize(value string) (ByteSize, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("size is empty")
+	}
+
+	i := 0
+	for i < len(value) && (value[i] >= '0' && value[i] <= '9' || value[i] == '.') {
+		i++
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("size %q must start with a number", value)
+	}
+
+	number, err := strconv.ParseFloat(value[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number in size %q", value)
+	}
+
+	unit := strings.ToUpper(strings.TrimSpace(value[i:]))
+	multiplier, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown size unit %q (expected B, KB, MB or GB)", value[i:])
+	}
+
+	return ByteSize(number * float64(multiplier)), nil
+}
+
+// Decode implements envconfig.Decoder
+func (s *ByteSize) Decode(value string) error {
+	size, err := ParseByteSize(value)
+	if err != nil {
+		return err
+	}
+	*s = size
+	return nil
+}
+
+// String formats the size using the largest unit that divides it evenly
+func (s ByteSize) String() string {
+	switch {
+	case s >= Gigabyte && s%Gigabyte == 0:
+		return fmt.Sprintf("%dGB", s/Gigabyte)
+	case s >= Megabyte && s%Megabyte == 0:
+		return fmt.Sprintf("%dMB", s/Megabyte)
+	case s >= Kilobyte && s%Kilobyte == 0:
+		return fmt.Sprintf("%dKB", s/Kilobyte)
+	default:
+		return fmt.Sprintf("%dB", int64(s))
+	}
+}
@@ -0,0 +1,49 @@
+package configs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ByteSize
+		wantErr  bool
+	}{
+		{input: "512", expected: 512},
+		{input: "512B", expected: 512},
+		{input: "64KB", expected: 64 * Kilobyte},
+		{input: "64kb", expected: 64 * Kilobyte},
+		{input: "10MB", expected: 10 * Megabyte},
+		{input: "10 MiB", expected: 10 * Megabyte},
+		{input: "1.5GB", expected: Gigabyte + 512*Megabyte},
+		{input: "2G", expected: 2 * Gigabyte},
+		{input: "", wantErr: true},
+		{input: "MB", wantErr: true},
+		{input: "10XB", wantErr: true},
+		{input: "1.2.3MB", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			size, err := ParseByteSize(tt.input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, size)
+		})
+	}
+}
+
+func TestByteSize_String(t *testing.T) {
+	assert.Equal(t, "512B", ByteSize(512).String())
+	assert.Equal(t, "64KB", (64 * Kilobyte).String())
+	assert.Equal(t, "10MB", (10 * Megabyte).String())
+	assert.Equal(t, "1GB", Gigabyte.String())
+	assert.Equal(t, "1025B", ByteSize(1025).String())
+}
@@ -0,0 +1,104 @@
+package configs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SLORoute is the service level objective of a single route
+type SLORoute struct {
+	Method string
+	// Route is the chi route pattern, e.g. /api/v1/users/{id}
+	Route string
+	// Availability is the fraction of requests that must not fail with a
+	// 5xx status
+	Availability float64
+	// Latency, when set, is the threshold LatencyTarget of requests must be
+	// served within
+	Latency       time.Duration
+	LatencyTarget float64
+}
+
+// SLORoutes is a list of route objectives parsed from entries separated by
+// semicolons, each written as METHOD ROUTE AVAILABILITY [LATENCY@TARGET],
+// e.g. "GET /api/v1/users 99.9% 300ms@99%; POST /api/v1/users 99.5%"
+type SLORoutes []SLORoute
+
+// ParseSLORoutes parses a list of route objectives
+func ParseSLORoutes(value string) (SLORoutes, error) {
+	var routes SLORoutes
+	seen := make(map[string]bool)
+
+	for _, entry := range strings.Split(value, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 3 && len(fields) != 4 {
+			return nil, fmt.Errorf("objective %q must be METHOD ROUTE AVAILABILITY [LATENCY@TARGET]", strings.TrimSpace(entry))
+		}
+
+		route := SLORoute{
+			Method: strings.ToUpper(fields[0]),
+			Route:  fields[1],
+		}
+		if !strings.HasPrefix(route.Route, "/") {
+			return nil, fmt.Errorf("objective %q: route must start with /", strings.TrimSpace(entry))
+		}
+
+		availability, err := parsePercent(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("objective %q: availability %w", strings.TrimSpace(entry), err)
+		}
+		route.Availability = availability
+
+		if len(fields) == 4 {
+			threshold, target, ok := strings.Cut(fields[3], "@")
+			if !ok {
+				return nil, fmt.Errorf("objective %q: latency must be written as THRESHOLD@TARGET, e.g. 300ms@99%%", strings.TrimSpace(entry))
+			}
+			route.Latency, err = time.ParseDuration(threshold)
+			if err != nil || route.Latency <= 0 {
+				return nil, fmt.Errorf("objective %q: invalid latency threshold %q", strings.TrimSpace(entry), threshold)
+			}
+			route.LatencyTarget, err = parsePercent(target)
+			if err != nil {
+				return nil, fmt.Errorf("objective %q: latency target %w", strings.TrimSpace(entry), err)
+			}
+		}
+
+		key := route.Method + " " + route.Route
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate objective for %s", key)
+		}
+		seen[key] = true
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+// Decode implements envconfig.Decoder
+func (r *SLORoutes) Decode(value string) error {
+	routes, err := ParseSLORoutes(value)
+	if err != nil {
+		return err
+	}
+	*r = routes
+	return nil
+}
+
+// parsePercent parses a percentage such as "99.9%" or "99.9" into a
+// fraction strictly between 0 and 1
+func parsePercent(value string) (float64, error) {
+	number, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a percentage", value)
+	}
+	if number <= 0 || number >= 100 {
+		return 0, fmt.Errorf("%q must be between 0%% and 100%% exclusive", value)
+	}
+	return number / 100, nil
+}
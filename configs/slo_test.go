@@ -0,0 +1,53 @@
+package configs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSLORoutes(t *testing.T) {
+	routes, err := ParseSLORoutes("GET /api/v1/users 99.9% 300ms@99%; post /api/v1/users 99.5 ;")
+	require.NoError(t, err)
+	require.Len(t, routes, 2)
+
+	assert.Equal(t, "GET", routes[0].Method)
+	assert.Equal(t, "/api/v1/users", routes[0].Route)
+	assert.InDelta(t, 0.999, routes[0].Availability, 1e-9)
+	assert.Equal(t, 300*time.Millisecond, routes[0].Latency)
+	assert.InDelta(t, 0.99, routes[0].LatencyTarget, 1e-9)
+
+	assert.Equal(t, "POST", routes[1].Method)
+	assert.InDelta(t, 0.995, routes[1].Availability, 1e-9)
+	assert.Zero(t, routes[1].Latency)
+
+	routes, err = ParseSLORoutes("")
+	require.NoError(t, err)
+	assert.Empty(t, routes)
+}
+
+func TestParseSLORoutes_Invalid(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "GET /api/v1/users", expected: "must be METHOD ROUTE AVAILABILITY [LATENCY@TARGET]"},
+		{input: "GET api/v1/users 99%", expected: "route must start with /"},
+		{input: "GET /api/v1/users high", expected: `availability "high" is not a percentage`},
+		{input: "GET /api/v1/users 100%", expected: `availability "100%" must be between 0% and 100% exclusive`},
+		{input: "GET /api/v1/users 99% 300ms", expected: "latency must be written as THRESHOLD@TARGET"},
+		{input: "GET /api/v1/users 99% fast@99%", expected: `invalid latency threshold "fast"`},
+		{input: "GET /api/v1/users 99% 300ms@0%", expected: `latency target "0%" must be between 0% and 100% exclusive`},
+		{input: "GET /a 99%; GET /a 98%", expected: "duplicate objective for GET /a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseSLORoutes(tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
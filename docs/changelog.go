@@ -0,0 +1,9 @@
+package docs
+
+import _ "embed"
+
+// Changelog is the machine-readable API changelog served at
+// /api/v1/changelog. Add an entry for every client-visible change.
+//
+//go:embed changelog.json
+var Changelog []byte
@@ -0,0 +1,13 @@
+package docs
+
+import (
+	"testing"
+
+	"clean-architecture/pkg/changelog"
+)
+
+func TestChangelogIsValid(t *testing.T) {
+	if _, err := changelog.Parse(Changelog); err != nil {
+		t.Fatalf("embedded changelog is invalid: %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+package docs
+
+import "embed"
+
+// Schemas holds the JSON Schemas of API payloads served at
+// /api/v1/schemas/{name}, one {name}.json file below schemas/. They are
+// generated from the types in handlers.SchemaTypes.
+//
+//go:generate go run ../cmd/schemagen -out schemas
+//go:embed schemas/*.json
+var Schemas embed.FS
@@ -2,44 +2,185 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"io/fs"
 	"net/http"
+	"time"
+
+	"github.com/segmentio/kafka-go"
 
 	"clean-architecture/configs"
-	"clean-architecture/internal/domain/entities"
+	"clean-architecture/docs"
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/notification"
+	"clean-architecture/internal/domain/policy"
 	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/domain/request"
+	auditinfra "clean-architecture/internal/infrastructure/audit"
+	authinfra "clean-architecture/internal/infrastructure/auth"
+	billinginfra "clean-architecture/internal/infrastructure/billing"
 	"clean-architecture/internal/infrastructure/database"
+	emailinfra "clean-architecture/internal/infrastructure/email"
+	messaginginfra "clean-architecture/internal/infrastructure/messaging"
+	metricsinfra "clean-architecture/internal/infrastructure/metrics"
+	notificationinfra "clean-architecture/internal/infrastructure/notification"
+	policyinfra "clean-architecture/internal/infrastructure/policy"
+	redisinfra "clean-architecture/internal/infrastructure/redis"
+	storageinfra "clean-architecture/internal/infrastructure/storage"
+	webhookinfra "clean-architecture/internal/infrastructure/webhook"
+	"clean-architecture/internal/interfaces/http/adminui"
 	"clean-architecture/internal/interfaces/http/handlers"
+	authmw "clean-architecture/internal/interfaces/http/middleware/auth"
+	"clean-architecture/internal/interfaces/http/middleware/features"
+	replaymw "clean-architecture/internal/interfaces/http/middleware/replay"
+	"clean-architecture/internal/interfaces/http/middleware/usage"
 	"clean-architecture/internal/interfaces/http/router"
 	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/canary"
+	"clean-architecture/pkg/capabilities"
+	"clean-architecture/pkg/changelog"
+	"clean-architecture/pkg/clientip"
+	"clean-architecture/pkg/cost"
+	"clean-architecture/pkg/cron"
+	"clean-architecture/pkg/degrade"
+	"clean-architecture/pkg/distlock"
+	"clean-architecture/pkg/experiments"
+	"clean-architecture/pkg/httpclient"
+	"clean-architecture/pkg/jobs"
+	"clean-architecture/pkg/ldap"
 	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/consumer"
+	"clean-architecture/pkg/metrics"
+	"clean-architecture/pkg/redis"
+	"clean-architecture/pkg/replay"
+	"clean-architecture/pkg/s3"
+	"clean-architecture/pkg/saml"
+	"clean-architecture/pkg/shutdown"
+	"clean-architecture/pkg/slo"
+	"clean-architecture/pkg/startup"
+	"clean-architecture/pkg/storage"
+	"clean-architecture/pkg/workers"
 
+	goredis "github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
+// featureLDAPLogin is switched off while the LDAP directory is down
+const featureLDAPLogin = "ldap_login"
+
 // App represents the main application context
 type App struct {
-	Logger logger.Logger
-	Router http.Handler
-	ctx    context.Context
-	DB     *gorm.DB
+	Logger       logger.Logger
+	Router       http.Handler
+	AdminRouter  http.Handler
+	HealthRouter http.Handler
+	ctx          context.Context
+	DB           *gorm.DB
+	// Redis is nil unless REDIS_ADDR or REDIS_URL is set
+	Redis *goredis.Client
+	// HTTPClient makes outbound calls through the configured proxy and
+	// egress allowlist
+	HTTPClient *http.Client
+	// GuardedHTTPClient additionally refuses private, loopback and metadata
+	// addresses, for calls to user-supplied URLs such as webhooks
+	GuardedHTTPClient *http.Client
+	// Logs creates the module loggers and changes their levels
+	Logs   *logger.Registry
 	Config *configs.Config
+	// AuthProvider verifies login credentials; it is nil unless
+	// AUTH_LDAP_URL or AUTH_SIGNUP_ENABLED is set
+	AuthProvider auth.Provider
+	ldapPool     *ldap.Pool
+	// AuthUseCase issues and verifies access tokens; it is nil unless
+	// AUTH_JWT_SECRET is set
+	AuthUseCase *usecase.AuthUseCase
+	// APIKeyUseCase issues and verifies service API keys; it is nil unless
+	// AUTH_JWT_SECRET is set
+	APIKeyUseCase *usecase.APIKeyUseCase
 
 	// Dependencies
 	UserRepository repositories.UserRepository
 	UserUseCase    *usecase.UserUseCase
 	UserHandler    *handlers.UserHandler
+	HealthHandler  *handlers.HealthHandler
+	PolicyEngine   policy.Engine
+	Metrics        *metrics.Registry
+	Locker         distlock.Locker
+	Elections      *distlock.Elections
+	Broker         messaging.Broker
+	Consumer       *consumer.Consumer
+	Kafka          *kafka.Writer
+	Capabilities   *capabilities.Registry
+	SLO            *slo.Tracker
+	Degradation    *degrade.Manager
+	listMetrics    *metricsinfra.ListMetrics
+
+	// Commands runs the commands consumed from RabbitMQ; it is nil unless
+	// MESSAGING_RABBITMQ_URL is set
+	Commands *messaginginfra.CommandWorker
+	// Jobs runs the jobs and email queued on Redis; it is nil unless
+	// JOBS_DRIVER=redis
+	Jobs *jobs.Worker
+	// Cron runs the periodic jobs, logging each run and exporting its
+	// outcome as metrics
+	Cron *cron.Scheduler
+	// Workers runs asynchronous tasks in process, such as those handed off
+	// by requests; shutdown waits for them to finish
+	Workers *workers.Pool
+
+	// Startup records how long each component took to initialize and what
+	// it was built from; the admin listener serves it
+	Startup *startup.Report
+
+	DeadLetterRepository repositories.DeadLetterRepository
+	DeadLetterUseCase    *usecase.DeadLetterUseCase
+
+	UserDeletionUseCase *usecase.UserDeletionUseCase
+	deletionPurge       *distlock.Elector
+
+	// UserSuggestUseCase answers user suggestions from an index every
+	// instance refreshes on its own
+	UserSuggestUseCase *usecase.UserSuggestUseCase
+
+	// EmailSender delivers transactional email through the EMAIL_DRIVER
+	// transport
+	EmailSender email.Sender
+
+	Storage       storage.Storage
+	ExportUseCase *usecase.ExportUseCase
+	exportCleanup *distlock.Elector
+	ImportUseCase *usecase.ImportUseCase
+	uploadCleanup *distlock.Elector
+	// UsageUseCase meters API calls; it is nil unless USAGE_ENABLED is set
+	UsageUseCase *usecase.UsageUseCase
+	// AuditUseCase records and ships the audit log; it is nil unless
+	// AUDIT_SINKS is set
+	AuditUseCase   *usecase.AuditUseCase
+	auditShipping  *distlock.Elector
+	auditAnchoring *distlock.Elector
+	// WebhookUseCase delivers domain events to the webhooks registered by
+	// admins
+	WebhookUseCase  *usecase.WebhookUseCase
+	webhookDelivery *distlock.Elector
+	// EventStreams fans user changes out to the Server-Sent Events streams
+	// open on this instance
+	EventStreams *usecase.EventStreamUseCase
 }
 
-// NewApp creates a new application instance
-func NewApp(logger logger.Logger) *App {
+// NewApp creates a new application instance from the loaded configuration
+func NewApp(logs *logger.Registry, cfg *configs.Config) *App {
 	ctx := context.Background()
-
-	// Load configuration
-	cfg, err := configs.Load()
+	logger := logs.Root()
+	// Each component is timed with the components it is built from
+	boot := startup.NewReport()
+	modules, err := newModuleLoggers(logs, cfg.Log.Modules)
 	if err != nil {
-		logger.Fatal("Failed to load configuration:", err)
+		logger.Fatal("Failed to configure log levels:", err)
 	}
 
+	boot.Begin("database")
 	// Initialize database
 	if err := database.InitDatabase(cfg); err != nil {
 		logger.Fatal("Failed to initialize database:", err)
@@ -48,33 +189,813 @@ func NewApp(logger logger.Logger) *App {
 	// Get database instance
 	db := database.GetDB()
 
+	boot.Begin("migrations", "database")
 	// Run migrations
-	if err := db.AutoMigrate(&entities.User{}); err != nil {
+	if err := database.MigrateDatabase(); err != nil {
 		logger.Fatal("Failed to run database migrations:", err)
 	}
-	logger.Info("Database migrations completed successfully")
+	modules.database.Info("Database migrations completed successfully")
+
+	boot.Begin("locks", "database")
+	// Initialize distributed locks for singleton background work
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("Failed to access database connection pool:", err)
+	}
+	locker := distlock.NewPostgresLocker(sqlDB, cfg.Server.InstanceID)
+	elections := distlock.NewElections(locker, cfg.Server.InstanceID, distlock.DefaultRetryInterval, logger)
+
+	boot.Begin("redis")
+	// Connect to Redis when configured; modules fall back to in-process
+	// state without it
+	redisClient, err := redisinfra.NewClient(ctx, cfg.Redis)
+	if err != nil {
+		logger.Fatal("Failed to connect to Redis:", err)
+	}
+	if redisClient != nil {
+		logger.Info("Redis connection established successfully")
+	}
+
+	boot.Begin("degradation")
+	// Optional dependencies switch off the features relying on them while
+	// they are down instead of failing readiness
+	degradation := degrade.NewManager(degrade.Options{
+		Interval:         cfg.Degradation.CheckInterval,
+		Timeout:          cfg.APIDefaults.HealthCheckTimeout,
+		FailureThreshold: cfg.Degradation.FailureThreshold,
+	}, logger)
+
+	// Verify credentials against the directory when one is configured
+	var authProvider auth.Provider
+	var ldapPool *ldap.Pool
+	if cfg.Auth.LDAP.Enabled() {
+		boot.Begin("ldap", "degradation")
+		ldapPool, err = authinfra.NewLDAPPool(cfg.Auth.LDAP)
+		if err != nil {
+			logger.Fatal("Failed to configure LDAP:", err)
+		}
+		if cfg.Auth.LDAP.InsecureSkipVerify {
+			modules.auth.Warn("AUTH_LDAP_INSECURE_SKIP_VERIFY is set; LDAP server certificates are not verified")
+		}
+		// While the directory is down its users get a quick 503 and
+		// password users can still log in
+		degradation.AddDependency("ldap", ldapPool.Ping)
+		degradation.AddFeature(featureLDAPLogin, "ldap")
+		authProvider = authinfra.NewGatedProvider(authinfra.NewLDAPProvider(ldapPool, cfg.Auth.LDAP), func() bool {
+			return degradation.Enabled(featureLDAPLogin)
+		}).WithRetryAfter(cfg.Degradation.CheckInterval)
+		modules.auth.WithField("url", cfg.Auth.LDAP.URL).Info("LDAP authentication enabled")
+	}
+
+	boot.Begin("http clients")
+	// Outbound calls share one client so the proxy and egress allowlist
+	// apply everywhere
+	httpClient, err := httpclient.New(httpclient.Config{
+		Timeout:      cfg.Outbound.Timeout,
+		ProxyURL:     cfg.Outbound.ProxyURL,
+		NoProxy:      cfg.Outbound.NoProxy,
+		AllowedHosts: cfg.Outbound.AllowedHosts,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize outbound HTTP client:", err)
+	}
+	guardedHTTPClient, err := httpclient.New(httpclient.Config{
+		Timeout:              cfg.Outbound.Timeout,
+		ProxyURL:             cfg.Outbound.ProxyURL,
+		NoProxy:              cfg.Outbound.NoProxy,
+		AllowedHosts:         cfg.Outbound.AllowedHosts,
+		BlockPrivateNetworks: true,
+		AllowedNetworks:      cfg.Outbound.AllowedPrivateNetworks,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize outbound HTTP client:", err)
+	}
 
 	// Initialize repositories
-	userRepo := database.NewPostgresUserRepository(db)
+	userRepo := database.NewReplicatedUserRepository(db, database.GetReplicaDB())
+
+	boot.Begin("broker")
+	// Initialize the event broker
+	broker, err := messaginginfra.NewBroker(cfg.Messaging, modules.messaging)
+	if err != nil {
+		logger.Fatal("Failed to initialize messaging broker:", err)
+	}
+	modules.messaging.WithField("driver", cfg.Messaging.Driver).Info("Messaging broker initialized")
+
+	boot.Begin("event consumer", "broker", "database")
+	// Initialize the event handler framework; subscribers register on it and
+	// failed messages land in the dead letter store
+	deadLetterRepo := database.NewPostgresDeadLetterRepository(db)
+	eventConsumer := consumer.New(broker, consumer.Options{
+		DeadLetters:    messaginginfra.NewDeadLetterSink(deadLetterRepo),
+		Idempotency:    database.NewPostgresIdempotencyStore(db),
+		MaxAttempts:    cfg.Messaging.MaxAttempts,
+		InitialBackoff: cfg.Messaging.RetryBackoff,
+		MaxBackoff:     cfg.Messaging.MaxRetryBackoff,
+	}, modules.messaging)
+
+	boot.Begin("worker pool")
+	// Asynchronous tasks run in process; shutdown waits for them
+	workerPool := workers.New(workers.Config{
+		Name:        "default",
+		Concurrency: cfg.Workers.Concurrency,
+		QueueSize:   cfg.Workers.QueueSize,
+	}, logger)
+
+	boot.Begin("job queue", "broker", "redis")
+	// Jobs are queued on the broker unless a Redis job queue is configured,
+	// whose worker every instance runs next to the HTTP server
+	var jobQueue usecase.JobQueue = messaginginfra.NewJobQueue(broker)
+	var jobClient *jobs.Client
+	var jobWorker *jobs.Worker
+	if cfg.Jobs.Redis() {
+		jobClient = jobs.NewClient(redisClient, jobs.ClientConfig{Queue: cfg.Jobs.Queue, MaxRetry: cfg.Jobs.MaxRetry})
+		jobQueue = redisinfra.NewJobQueue(jobClient)
+		jobWorker = jobs.NewWorker(redisClient, jobs.WorkerConfig{
+			Queues:          []string{cfg.Jobs.Queue},
+			Concurrency:     cfg.Jobs.Concurrency,
+			Lease:           cfg.Jobs.Lease,
+			PollInterval:    cfg.Jobs.PollInterval,
+			RetryBackoff:    cfg.Jobs.RetryBackoff,
+			MaxRetryBackoff: cfg.Jobs.MaxRetryBackoff,
+			DeadRetention:   cfg.Jobs.DeadRetention,
+		}, modules.messaging)
+		modules.messaging.WithFields(map[string]interface{}{
+			"queue":       cfg.Jobs.Queue,
+			"concurrency": cfg.Jobs.Concurrency,
+		}).Info("Redis job queue enabled")
+	}
+
+	boot.Begin("email", "database", "job queue")
+	// Transactional email goes through one sender whatever the transport
+	emailSender, err := emailinfra.NewSender(cfg.Email, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize email sender:", err)
+	}
+	logger.WithField("driver", cfg.Email.Driver).Info("Email sender initialized")
+	// Queued email is sent by the job workers; suppressed recipients are
+	// still dropped before it is queued
+	if jobWorker != nil && cfg.Jobs.Email {
+		jobWorker.Handle(redisinfra.TaskSendEmail, redisinfra.SendEmailTask(emailSender))
+		emailSender = redisinfra.NewQueuedSender(jobClient)
+	}
+	// Addresses that bounced or complained are never sent to again
+	suppressionRepo := database.NewPostgresEmailSuppressionRepository(db)
+	emailSender = emailinfra.NewSuppressingSender(emailSender, suppressionRepo, logger)
+	emailRenderer, err := emailinfra.NewTemplateRenderer("en")
+	if err != nil {
+		logger.Fatal("Failed to parse email templates:", err)
+	}
+	notifier := notificationinfra.NewEmailNotifier(emailSender, emailRenderer, cfg.Email.Locale)
+
+	boot.Begin("users", "database", "broker", "event consumer", "email")
+	// Every domain event is recorded as it is published, so it can be
+	// audited and replayed from the admin listener
+	publishedEventRepo := database.NewPostgresPublishedEventRepository(db)
+	eventPublisher := messaginginfra.NewEventPublisher(broker).WithLog(publishedEventRepo)
 
 	// Initialize use cases
-	userUseCase := usecase.NewUserUseCase(userRepo, logger)
+	quotaUseCase := usecase.NewQuotaUseCase(database.NewPostgresQuotaRepository(db), userRepo, cfg.Users.MaxUsers, logger)
+	userUseCase := usecase.NewUserUseCase(userRepo, eventPublisher, logger).
+		WithQuotas(quotaUseCase).
+		WithEmailConfirmation(database.NewPostgresEmailChangeRepository(db), notifier, usecase.EmailConfirmationPolicy{
+			TTL:        cfg.Users.EmailChangeTTL,
+			ConfirmURL: cfg.Users.EmailChangeConfirmURL,
+		})
+	deadLetterUseCase := usecase.NewDeadLetterUseCase(deadLetterRepo, broker, modules.messaging)
+	eventLogUseCase := usecase.NewEventLogUseCase(publishedEventRepo, broker, eventConsumer, modules.messaging)
+
+	// Billing webhooks keep the plan in sync, which sets the user quota and
+	// gates exports and imports
+	var billingHandler *handlers.BillingHandler
+	var featureGate features.Gate
+	if cfg.Billing.Enabled() {
+		boot.Begin("billing", "database", "users")
+		provider, err := billinginfra.NewProvider(cfg.Billing)
+		if err != nil {
+			logger.Fatal("Failed to initialize billing provider:", err)
+		}
+		billingUseCase, err := usecase.NewBillingUseCase(provider, database.NewPostgresSubscriptionRepository(db), quotaUseCase, cfg.Billing.DefaultPlan, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize billing:", err)
+		}
+		billingHandler = handlers.NewBillingHandler(billingUseCase, provider.SignatureHeader(), modules.http)
+		featureGate = billingUseCase
+		logger.WithField("provider", provider.Name()).Info("Billing enabled")
+	}
+
+	boot.Begin("email suppression", "database", "users", "email")
+	// Feedback webhooks report the bounces and complaints that suppress
+	// addresses
+	var feedbackProvider email.FeedbackProvider
+	var feedbackHeader string
+	if cfg.Email.Feedback.Enabled() {
+		feedbackProvider, err = emailinfra.NewFeedbackProvider(cfg.Email.Feedback)
+		if err != nil {
+			logger.Fatal("Failed to initialize email feedback provider:", err)
+		}
+		feedbackHeader = feedbackProvider.SignatureHeader()
+		logger.WithField("provider", feedbackProvider.Name()).Info("Email feedback webhooks enabled")
+	}
+	suppressionUseCase := usecase.NewEmailSuppressionUseCase(feedbackProvider, suppressionRepo, userRepo, logger)
+
+	boot.Begin("storage")
+	// Initialize object storage for job artifacts
+	objectStorage, storageDriver, err := storageinfra.NewStorage(cfg.Storage, modules.storage)
+	if err != nil {
+		logger.Fatal("Failed to initialize object storage:", err)
+	}
+	modules.storage.WithField("driver", storageDriver).Info("Object storage initialized")
+
+	boot.Begin("exports", "database", "storage", "job queue", "event consumer")
+	jobRepo := database.NewPostgresJobRepository(db)
+	exportUseCase := usecase.NewExportUseCase(
+		userRepo,
+		jobRepo,
+		jobQueue,
+		objectStorage,
+		usecase.ExportOptions{
+			PageSize:  cfg.Exports.PageSize,
+			Retention: cfg.Exports.Retention,
+			URLTTL:    cfg.Exports.URLTTL,
+		},
+		logger,
+	)
+	if jobWorker != nil {
+		jobWorker.Handle(usecase.JobTypeUserExport, redisinfra.JobTask(exportUseCase.RunExport))
+	} else {
+		eventConsumer.Register(messaginginfra.JobHandler(usecase.JobTypeUserExport, cfg.Exports.Workers, exportUseCase.RunExport))
+	}
+
+	boot.Begin("imports", "users", "database", "storage", "job queue", "event consumer")
+	importUseCase := usecase.NewImportUseCase(
+		userUseCase,
+		database.NewPostgresUploadRepository(db),
+		jobRepo,
+		jobQueue,
+		objectStorage,
+		usecase.ImportOptions{
+			MaxSize:   int64(cfg.Imports.MaxSize),
+			ChunkSize: int64(cfg.Imports.ChunkSize),
+			UploadTTL: cfg.Imports.UploadTTL,
+		},
+		logger,
+	)
+	if jobWorker != nil {
+		jobWorker.Handle(usecase.JobTypeUserImport, redisinfra.JobTask(importUseCase.RunImport))
+	} else {
+		eventConsumer.Register(messaginginfra.JobHandler(usecase.JobTypeUserImport, cfg.Imports.Workers, importUseCase.RunImport))
+	}
+
+	boot.Begin("user creations", "users", "database", "job queue", "event consumer")
+	// Users are created in the background when USERS_CREATE_MODE says so.
+	// The handler is registered in every mode so jobs queued before a
+	// switch back to sync still run.
+	userCreationUseCase := usecase.NewUserCreationUseCase(userUseCase, jobRepo, jobQueue, logger)
+	if jobWorker != nil {
+		jobWorker.Handle(usecase.JobTypeUserCreate, redisinfra.JobTask(userCreationUseCase.RunCreation))
+	} else {
+		eventConsumer.Register(messaginginfra.JobHandler(usecase.JobTypeUserCreate, cfg.Users.CreateWorkers, userCreationUseCase.RunCreation))
+	}
+
+	boot.Begin("user deletion", "users", "email", "broker")
+	userDeletionUseCase := usecase.NewUserDeletionUseCase(
+		userRepo,
+		database.NewPostgresUserDeletionRepository(db),
+		notifier,
+		eventPublisher,
+		cfg.Users.DeletionGracePeriod,
+		cfg.Users.DeletionCancelURL,
+		logger,
+	)
+
+	// Initialize authorization policy engine
+	var policyEngine policy.Engine
+	if cfg.Policy.Enabled {
+		boot.Begin("policy engine", "http clients")
+		policyEngine, err = policyinfra.NewEngine(ctx, cfg.Policy, httpClient, modules.policy)
+		if err != nil {
+			logger.Fatal("Failed to initialize policy engine:", err)
+		}
+		modules.policy.WithField("driver", cfg.Policy.Driver).Info("Authorization policy engine initialized")
+	}
 
+	boot.Begin("user handlers", "users", "user deletion", "user creations", "email suppression", "policy engine")
 	// Initialize handlers
-	userHandler := handlers.NewUserHandler(userUseCase)
+	// Related resources clients may embed in users with ?include=
+	userPresenter := handlers.NewUserPresenter(policyEngine).
+		Include("deletion", handlers.ActionReadUserDeletion, handlers.DeletionLoader(userDeletionUseCase)).
+		Include("email_suppression", handlers.ActionReadUserEmailSuppression, handlers.EmailSuppressionLoader(suppressionUseCase))
+	savedViewUseCase := usecase.NewSavedViewUseCase(database.NewPostgresSavedViewRepository(db), logger)
+	apiDefaults := newAPIDefaults(cfg.APIDefaults)
+	// Suggestions are answered from an index of every user kept in memory
+	userSuggestUseCase := usecase.NewUserSuggestUseCase(userRepo, usecase.UserSuggestOptions{
+		RetryAfter: cfg.Users.SuggestRefreshInterval,
+	}, modules.http)
+	// Operators find users who registered twice and merge them on the
+	// admin listener
+	userMergeUseCase := usecase.NewUserMergeUseCase(userRepo, database.NewPostgresUserMergeRepository(db),
+		eventPublisher, usecase.UserMergeOptions{
+			Threshold:    cfg.Users.DuplicateThreshold,
+			MaxGroupSize: cfg.Users.DuplicateMaxGroup,
+		}, modules.http)
+	userHandler := handlers.NewUserHandler(userUseCase, userPresenter, modules.http).
+		WithSavedViews(savedViewUseCase).
+		WithSuggestions(userSuggestUseCase, cfg.Users.SuggestLimit, cfg.Users.SuggestMaxLimit).
+		WithAsyncCreation(userCreationUseCase, cfg.Users.CreateMode).
+		WithDefaults(apiDefaults)
+	// Responses are localized with the preferences of the caller
+	preferencesUseCase := usecase.NewUserPreferencesUseCase(userRepo, eventPublisher, cfg.Users.PreferencesCacheTTL, modules.http)
+	userDeletionHandler := handlers.NewUserDeletionHandler(userDeletionUseCase, modules.http).WithDefaults(apiDefaults)
+
+	boot.Begin("api docs")
+	apiChangelog, err := changelog.Parse(docs.Changelog)
+	if err != nil {
+		logger.Fatal("Failed to load API changelog:", err)
+	}
+	schemas, err := fs.Sub(docs.Schemas, "schemas")
+	if err != nil {
+		logger.Fatal("Failed to load JSON Schemas:", err)
+	}
+
+	caps := newCapabilities(cfg)
+
+	boot.Begin("health checks", "database", "redis", "broker", "degradation")
+	healthChecks := map[string]handlers.HealthCheckFunc{
+		"database": func(ctx context.Context) error {
+			return sqlDB.PingContext(ctx)
+		},
+	}
+	if redisClient != nil {
+		healthChecks["redis"] = func(ctx context.Context) error {
+			return redis.Ping(ctx, redisClient)
+		}
+	}
+	if natsBroker, ok := broker.(*messaginginfra.NATSBroker); ok {
+		healthChecks["nats"] = natsBroker.Ping
+	}
+	healthHandler := handlers.NewHealthHandler(healthChecks).
+		WithCheckTimeout(apiDefaults.HealthCheckTimeout).
+		WithDegradation(degradation)
+
+	boot.Begin("metrics", "locks", "degradation", "event consumer", "users", "worker pool")
+	// Initialize metrics
+	metricsRegistry := metrics.NewRegistry()
+	metricsRegistry.MustRegister(elections, degradation, workerPool)
+
+	// Register business metrics; modules feeding counters from domain
+	// events subscribe through the event consumer
+	userMetrics := metricsinfra.NewUserMetrics(userRepo, logger)
+	metricsRegistry.MustRegister(userMetrics)
+	eventConsumer.Register(userMetrics.Handler())
+
+	boot.Begin("cron", "locks", "users", "user handlers", "metrics")
+	scheduler, err := newScheduler(cfg, locker, userUseCase, userSuggestUseCase, logger)
+	if err != nil {
+		logger.Fatal("Failed to register cron jobs:", err)
+	}
+	metricsRegistry.MustRegister(scheduler)
+
+	// Record domain events in the audit log and ship it to the external
+	// sinks
+	var auditUseCase *usecase.AuditUseCase
+	if cfg.Audit.Enabled() {
+		boot.Begin("audit", "database", "http clients", "event consumer")
+		sinks, err := newAuditSinks(cfg.Audit, httpClient)
+		if err != nil {
+			logger.Fatal("Failed to configure audit sinks:", err)
+		}
+		auditUseCase = usecase.NewAuditUseCase(database.NewPostgresAuditRepository(db), sinks, cfg.Audit.BatchSize, modules.messaging)
+		eventConsumer.Register(messaginginfra.AuditHandler(auditUseCase.Record))
+		modules.messaging.WithField("sinks", cfg.Audit.Sinks).Info("Audit log shipping enabled")
+	}
+
+	// Notify the configured channels of domain events; each channel
+	// subscribes on its own
+	if len(cfg.Notifications.Channels) > 0 {
+		boot.Begin("notifications", "email", "http clients", "event consumer")
+		channels, err := newNotificationChannels(cfg.Notifications, notifier, httpClient, modules.messaging)
+		if err != nil {
+			logger.Fatal("Failed to configure notification channels:", err)
+		}
+		notificationUseCase := usecase.NewNotificationUseCase(channels, modules.messaging)
+		for _, channel := range notificationUseCase.Channels() {
+			eventConsumer.Register(messaginginfra.NotificationHandler(channel, cfg.Notifications.Events, notificationUseCase.Notify))
+		}
+		modules.messaging.WithFields(map[string]interface{}{
+			"channels": cfg.Notifications.Channels,
+			"events":   cfg.Notifications.Events,
+		}).Info("Notifications enabled")
+	}
+
+	boot.Begin("webhooks", "database", "http clients", "event consumer")
+	// Record deliveries of domain events to the webhooks subscribing to
+	// them; the leader attempts them. Webhook URLs are chosen by admins, so
+	// private addresses are refused.
+	webhookUseCase := usecase.NewWebhookUseCase(database.NewPostgresWebhookRepository(db), webhookinfra.NewSender(guardedHTTPClient), usecase.WebhookOptions{
+		MaxAttempts:    cfg.Webhooks.MaxAttempts,
+		InitialBackoff: cfg.Webhooks.InitialBackoff,
+		MaxBackoff:     cfg.Webhooks.MaxBackoff,
+		BatchSize:      cfg.Webhooks.BatchSize,
+		Timeout:        cfg.Webhooks.Timeout,
+	}, modules.messaging)
+	eventConsumer.Register(messaginginfra.WebhookHandler(webhookUseCase.Enqueue))
+
+	boot.Begin("event streams", "event consumer")
+	// Stream user changes to the clients connected to this instance. The
+	// streams are held in memory, so each instance consumes every change
+	// in a group of its own.
+	eventStreams := usecase.NewEventStreamUseCase(usecase.EventStreamOptions{
+		Buffer:     cfg.EventStream.Buffer,
+		MaxStreams: cfg.EventStream.MaxConnections,
+		RetryAfter: cfg.EventStream.RetryInterval,
+	}, modules.messaging)
+	eventConsumer.Register(messaginginfra.EventStreamHandler("event-stream-"+cfg.Server.InstanceID, eventStreams.Publish))
+
+	// Forward domain events to Kafka for the services consuming them there
+	var kafkaWriter *kafka.Writer
+	if cfg.Messaging.Kafka.Enabled() {
+		boot.Begin("kafka", "event consumer")
+		kafkaWriter, err = messaginginfra.NewKafkaWriter(cfg.Messaging.Kafka)
+		if err != nil {
+			logger.Fatal("Failed to configure Kafka producer:", err)
+		}
+		eventConsumer.Register(messaginginfra.KafkaHandler(cfg.Messaging.Kafka.TopicPrefix, cfg.Messaging.Kafka.Workers, kafkaWriter.WriteMessages))
+		modules.messaging.WithFields(map[string]interface{}{
+			"brokers":      cfg.Messaging.Kafka.Brokers,
+			"topic_prefix": cfg.Messaging.Kafka.TopicPrefix,
+		}).Info("Kafka event forwarding enabled")
+	}
+
+	// Other services send commands, such as user imports, through RabbitMQ
+	var commandWorker *messaginginfra.CommandWorker
+	if cfg.Messaging.RabbitMQ.Enabled() {
+		boot.Begin("rabbitmq", "users")
+		commandWorker = messaginginfra.NewCommandWorker(cfg.Messaging.RabbitMQ, modules.messaging)
+		commandWorker.Handle(messaginginfra.CommandImportUser, messaginginfra.ImportUserCommand(userUseCase.UpsertUser))
+		commandWorker.Handle(messaginginfra.CommandDeleteUser, messaginginfra.DeleteUserCommand(userUseCase.DeleteUser))
+	}
+
+	boot.Begin("query instrumentation", "database", "metrics")
+	// Record how deep list queries page and sample their query plans
+	listMetrics := metricsinfra.NewListMetrics(modules.database)
+	metricsRegistry.MustRegister(listMetrics)
+	if err := db.Use(database.NewListInstrumentation(listMetrics, cfg.Database.ExplainSampleRate, modules.database)); err != nil {
+		logger.Fatal("Failed to instrument list queries:", err)
+	}
+	if replica := database.GetReplicaDB(); replica != nil {
+		if err := replica.Use(database.NewListInstrumentation(listMetrics, cfg.Database.ExplainSampleRate, modules.database)); err != nil {
+			logger.Fatal("Failed to instrument list queries:", err)
+		}
+	}
+
+	// Record the queries of slow requests in their diagnostic bundles
+	if cfg.Server.SlowRequestThreshold > 0 {
+		for _, conn := range []*gorm.DB{db, database.GetReplicaDB()} {
+			if conn == nil {
+				continue
+			}
+			if err := conn.Use(database.NewQueryTimings()); err != nil {
+				logger.Fatal("Failed to instrument queries:", err)
+			}
+		}
+	}
+
+	// Count the queries of each request for the access log and route
+	// cost budgets, and stop statements exceeding the budget of their
+	// operation class
+	queryBudgets := newQueryBudgets(cfg.Database)
+	for _, conn := range []*gorm.DB{db, database.GetReplicaDB()} {
+		if conn == nil {
+			continue
+		}
+		if err := conn.Use(database.NewQueryCosts()); err != nil {
+			logger.Fatal("Failed to instrument queries:", err)
+		}
+		if err := conn.Use(database.NewQueryBudgets(queryBudgets, modules.database)); err != nil {
+			logger.Fatal("Failed to guard queries:", err)
+		}
+	}
+	costTracker := newCostTracker(cfg.Cost, logger)
+	metricsRegistry.MustRegister(costTracker)
+	if len(cfg.Cost.Budgets) > 0 {
+		logger.WithField("budgets", len(cfg.Cost.Budgets)).Info("Request cost budgets enabled")
+	}
 
-	// Create router with dependencies
-	r := router.NewRouter(logger, userHandler)
+	// Track route SLOs when objectives are configured
+	var sloTracker *slo.Tracker
+	if len(cfg.SLO.Routes) > 0 {
+		boot.Begin("slo", "metrics")
+		sloTracker = newSLOTracker(cfg.SLO, logger)
+		metricsRegistry.MustRegister(sloTracker)
+		logger.WithField("objectives", len(cfg.SLO.Routes)).Info("SLO tracking enabled")
+	}
+
+	boot.Begin("organizations", "database")
+	// Organizations own the service accounts API keys can be issued to
+	orgRepo := database.NewPostgresOrganizationRepository(db)
+	orgHandler := handlers.NewOrganizationHandler(usecase.NewOrganizationUseCase(orgRepo, modules.http), modules.http).WithDefaults(apiDefaults)
+
+	// API calls are counted by each instance and flushed to daily rollups
+	var usageUseCase *usecase.UsageUseCase
+	var usageRecorder usage.Recorder
+	var usageHandler *handlers.UsageHandler
+	if cfg.Usage.Enabled {
+		boot.Begin("usage", "database")
+		usageUseCase = usecase.NewUsageUseCase(database.NewPostgresUsageRepository(db), modules.http)
+		usageRecorder = usageUseCase
+		usageHandler = handlers.NewUsageHandler(usageUseCase, modules.http)
+	}
+
+	// Protected routes require a bearer token once tokens can be signed
+	var authUseCase *usecase.AuthUseCase
+	var authHandler *handlers.AuthHandler
+	var authenticator authmw.Authenticator
+	var apiKeyUseCase *usecase.APIKeyUseCase
+	var apiKeys authmw.Authenticator
+	var apiKeyHandler *handlers.APIKeyHandler
+	var serviceAccountHandler *handlers.ServiceAccountHandler
+	var sessions authmw.SessionAuthenticator
+	var mfaHandler *handlers.MFAHandler
+	var lockoutHandler *handlers.LockoutHandler
+	if cfg.Auth.Enabled() {
+		boot.Begin("auth", "database", "redis", "ldap", "http clients", "users", "organizations")
+		tokens := authinfra.NewJWTService(cfg.Auth.JWTSecret, cfg.Auth.JWTIssuer, cfg.Auth.AccessTokenTTL)
+		var hasher auth.PasswordHasher
+		if cfg.Auth.SignupEnabled {
+			hasher = authinfra.NewBcryptHasher(cfg.Auth.BcryptCost)
+			passwords, err := authinfra.NewPasswordProvider(userRepo, hasher)
+			if err != nil {
+				logger.Fatal("Failed to configure password login:", err)
+			}
+			// The directory stays authoritative for the users it knows
+			if authProvider != nil {
+				authProvider = authinfra.ChainProvider{authProvider, passwords}
+			} else {
+				authProvider = passwords
+			}
+		}
+		authUseCase = usecase.NewAuthUseCase(authProvider, tokens, userRepo, userUseCase, modules.auth).
+			WithRevocations(newRevocationStore(redisClient, modules.auth))
+		if hasher != nil {
+			authUseCase.WithSignup(hasher, cfg.Auth.PasswordMinLength, notifier, usecase.SignupVerificationPolicy{
+				Key:       []byte(cfg.Auth.JWTSecret),
+				TTL:       cfg.Auth.SignupVerifyTTL,
+				VerifyURL: cfg.Auth.SignupVerifyURL,
+			})
+		}
+		authHandler = handlers.NewAuthHandler(authUseCase, userPresenter, modules.auth)
+		if cfg.Auth.SAML.Enabled() {
+			authHandler.WithSAML(newSAMLTenants(cfg.Auth, httpClient, modules.auth))
+		}
+		if cfg.Auth.OIDC.Enabled() {
+			authHandler.WithOIDC(newOIDCProviders(cfg.Auth, httpClient, modules.auth))
+		}
+		if cfg.Auth.Sessions.Enabled {
+			authUseCase.WithSessions(newSessionStore(redisClient, modules.auth), cfg.Auth.Sessions.TTL, cfg.Auth.Sessions.IdleTimeout)
+			authHandler.WithSessions(handlers.SessionCookie{
+				Name:   cfg.Auth.Sessions.CookieName,
+				Domain: cfg.Auth.Sessions.CookieDomain,
+				Secure: cfg.Auth.Sessions.CookieSecure,
+			})
+			sessions = authUseCase
+		}
+		if cfg.Auth.MFA.Enabled() {
+			mfaUseCase := usecase.NewMFAUseCase(userRepo, newSecretCipher(cfg.Auth.MFA, logger), cfg.Auth.MFA.Issuer, modules.auth)
+			authUseCase.WithMFA(mfaUseCase)
+			mfaHandler = handlers.NewMFAHandler(mfaUseCase, modules.auth)
+		}
+		if cfg.Auth.Lockout.Enabled() {
+			lockoutUseCase := usecase.NewLockoutUseCase(newAttemptStore(redisClient, modules.auth), usecase.LockoutPolicy{
+				MaxAttempts:      cfg.Auth.Lockout.MaxAttempts,
+				MaxAttemptsPerIP: cfg.Auth.Lockout.MaxAttemptsPerIP,
+				Window:           cfg.Auth.Lockout.Window,
+				Duration:         cfg.Auth.Lockout.Duration,
+			}, modules.auth)
+			authUseCase.WithLockout(lockoutUseCase)
+			lockoutHandler = handlers.NewLockoutHandler(lockoutUseCase, modules.auth)
+		}
+		authenticator = authUseCase
+		apiKeyUseCase = usecase.NewAPIKeyUseCase(database.NewPostgresAPIKeyRepository(db), modules.auth).
+			WithServiceAccounts(userRepo)
+		apiKeys = apiKeyUseCase
+		apiKeyHandler = handlers.NewAPIKeyHandler(apiKeyUseCase, modules.auth).WithDefaults(apiDefaults)
+		serviceAccounts := usecase.NewServiceAccountUseCase(orgRepo, userRepo, apiKeyUseCase, modules.auth)
+		serviceAccountHandler = handlers.NewServiceAccountHandler(serviceAccounts, modules.auth).WithDefaults(apiDefaults)
+		modules.auth.WithField("issuer", cfg.Auth.JWTIssuer).Info("Authentication enabled")
+	} else {
+		logger.Warn("AUTH_JWT_SECRET is not set; API routes do not require authentication")
+	}
+
+	// SCIM provisioning is only served once identity providers have a token
+	var scimHandler *handlers.SCIMHandler
+	if cfg.SCIM.Token != "" {
+		boot.Begin("scim", "users")
+		scimHandler = handlers.NewSCIMHandler(userUseCase, cfg.SCIM.Token, modules.http).WithMaxResults(cfg.SCIM.MaxResults)
+	}
+
+	// The local storage driver serves its own signed URLs
+	downloads, _ := objectStorage.(http.Handler)
+
+	boot.Begin("feature flags", "database", "audit")
+	// Admins browse the audit log and switch feature flags, also from the
+	// embedded admin UI
+	featureFlagUseCase := usecase.NewFeatureFlagUseCase(database.NewPostgresFeatureFlagRepository(db), cfg.FeatureFlags.Defaults, modules.http)
+	var auditLogHandler *handlers.AuditLogHandler
+	if auditUseCase != nil {
+		auditLogHandler = handlers.NewAuditLogHandler(auditUseCase, modules.http).WithDefaults(apiDefaults)
+	}
+	var adminUI http.Handler
+	if cfg.Server.AdminUI {
+		boot.Begin("admin ui")
+		ui, err := adminui.New()
+		if err != nil {
+			logger.Fatal("Failed to load admin UI:", err)
+		}
+		adminUI = ui
+	}
+
+	// Captured requests are listed and replayed from the admin listener
+	var capture func(http.Handler) http.Handler
+	var replayHandler *handlers.RequestReplayHandler
+	if cfg.Replay.Enabled {
+		boot.Begin("replay", "http clients")
+		captures := replay.NewBuffer(cfg.Replay.BufferSize)
+		capture = replaymw.Capture(captures, replay.NewRedactor(cfg.Replay.RedactFields), int64(cfg.Replay.MaxBodySize))
+		replayHandler = handlers.NewRequestReplayHandler(captures, cfg.Replay.Targets, httpClient, modules.http).WithDefaults(apiDefaults)
+		modules.http.Warn("REPLAY_ENABLED is set; redacted API requests are kept in memory for replaying")
+	}
+
+	boot.Begin("canary", "metrics")
+	// Users stay on one variant of each experiment across their requests
+	canaries := canary.New(cfg.Canary.Rollouts, canary.Options{
+		Header: cfg.Canary.Header,
+		Key: func(r *http.Request) string {
+			return request.Current(r.Context()).UserID
+		},
+	})
+	metricsRegistry.MustRegister(canaries)
+	if len(cfg.Canary.Rollouts) > 0 {
+		modules.http.WithField("rollouts", cfg.Canary.Rollouts).Info("Canary rollouts enabled")
+	}
+
+	boot.Begin("experiments", "metrics")
+	// Users are assigned to the variants of A/B experiments by a hash of
+	// their ID, and the first time a request reads one it is logged for
+	// analysis
+	abExperiments := make([]experiments.Experiment, 0, len(cfg.Experiments.Variants))
+	for name, spec := range cfg.Experiments.Variants {
+		experiment, err := experiments.Parse(name, spec)
+		if err != nil {
+			logger.Fatal("Failed to parse experiment:", err)
+		}
+		abExperiments = append(abExperiments, experiment)
+	}
+	assigner, err := experiments.New(abExperiments, experiments.LogExposures(modules.http))
+	if err != nil {
+		logger.Fatal("Failed to initialize experiments:", err)
+	}
+	metricsRegistry.MustRegister(assigner)
+	assignments := func(userID string) request.Assignments {
+		return assigner.For(userID)
+	}
+	if len(abExperiments) > 0 {
+		modules.http.WithField("experiments", assigner.Names()).Info("A/B experiments enabled")
+	}
+
+	// Only the proxies in front of the API may forward the client address
+	// login lockouts count by
+	clientIPs, err := clientip.NewResolver(cfg.Server.TrustedProxies)
+	if err != nil {
+		logger.Fatal("Failed to initialize client address resolver:", err)
+	}
+
+	boot.Begin("routers", "user handlers", "exports", "imports", "user creations", "api docs", "health checks", "metrics",
+		"auth", "scim", "organizations", "usage", "feature flags", "admin ui", "webhooks", "event streams",
+		"billing", "email suppression", "policy engine", "slo", "replay", "locks", "canary", "experiments")
+	// Create routers with dependencies
+	r := router.NewRouter(router.Dependencies{
+		Logger:                  modules.http,
+		Config:                  cfg,
+		UserHandler:             userHandler,
+		UserDeletionHandler:     userDeletionHandler,
+		ExportHandler:           handlers.NewExportHandler(exportUseCase, modules.http).WithStreaming(cfg.Exports.StreamFlushInterval, cfg.Exports.StreamStallTimeout),
+		ImportHandler:           handlers.NewImportHandler(importUseCase, modules.http),
+		JobHandler:              handlers.NewJobHandler(usecase.NewJobUseCase(jobRepo, logger), modules.http),
+		SavedViewHandler:        handlers.NewSavedViewHandler(savedViewUseCase, policyEngine, modules.http).WithDefaults(apiDefaults),
+		ChangelogHandler:        handlers.NewChangelogHandler(apiChangelog),
+		CapabilitiesHandler:     handlers.NewCapabilitiesHandler(caps, apiChangelog.CurrentVersion),
+		SchemaHandler:           handlers.NewSchemaHandler(schemas, modules.http),
+		SCIMHandler:             scimHandler,
+		AuthHandler:             authHandler,
+		Authenticator:           authenticator,
+		APIKeys:                 apiKeys,
+		APIKeyHandler:           apiKeyHandler,
+		MFAHandler:              mfaHandler,
+		PreferencesHandler:      handlers.NewUserPreferencesHandler(preferencesUseCase, modules.http),
+		Preferences:             preferencesUseCase.Lookup,
+		LockoutHandler:          lockoutHandler,
+		OrganizationHandler:     orgHandler,
+		ServiceAccountHandler:   serviceAccountHandler,
+		QuotaHandler:            handlers.NewQuotaHandler(quotaUseCase, modules.http),
+		FeatureFlagHandler:      handlers.NewFeatureFlagHandler(featureFlagUseCase, modules.http),
+		FeatureFlags:            featureFlagUseCase.Flags,
+		AuditLogHandler:         auditLogHandler,
+		WebhookHandler:          handlers.NewWebhookHandler(webhookUseCase, modules.http).WithDefaults(apiDefaults),
+		EventStreamHandler:      handlers.NewEventStreamHandler(eventStreams, modules.http).WithTimings(cfg.EventStream.HeartbeatInterval, cfg.EventStream.RetryInterval),
+		AdminUI:                 adminUI,
+		BillingHandler:          billingHandler,
+		Features:                featureGate,
+		EmailSuppressionHandler: handlers.NewEmailSuppressionHandler(suppressionUseCase, feedbackHeader, modules.http).WithDefaults(apiDefaults),
+		Usage:                   usageRecorder,
+		UsageHandler:            usageHandler,
+		Sessions:                sessions,
+		Metrics:                 metricsRegistry,
+		PolicyEngine:            policyEngine,
+		SLO:                     sloTracker,
+		Costs:                   costTracker,
+		Downloads:               downloads,
+		Replay:                  capture,
+		Canary:                  canaries,
+		ClientIP:                clientIPs,
+		Experiments:             assignments,
+	})
+	adminRouter := router.NewAdminRouter(router.AdminDependencies{
+		Logger:      modules.http,
+		Metrics:     metricsRegistry,
+		Leadership:  handlers.NewLeadershipHandler(elections),
+		DeadLetters: handlers.NewDeadLetterHandler(deadLetterUseCase, modules.http).WithDefaults(apiDefaults),
+		Events:      handlers.NewEventLogHandler(eventLogUseCase, modules.http).WithDefaults(apiDefaults),
+		Deletions:   userDeletionHandler,
+		UserMerges:  handlers.NewUserMergeHandler(userMergeUseCase, modules.http).WithDefaults(apiDefaults),
+		LogLevels:   handlers.NewLogLevelHandler(logs, logger),
+		Startup:     handlers.NewStartupHandler(boot),
+		Replays:     replayHandler,
+	})
+	healthRouter := router.NewHealthRouter(healthHandler)
+	boot.End()
 
 	return &App{
 		Logger:         logger,
+		Logs:           logs,
 		Router:         r,
+		AdminRouter:    adminRouter,
+		HealthRouter:   healthRouter,
+		Startup:        boot,
 		ctx:            ctx,
 		DB:             db,
+		Redis:          redisClient,
+		HTTPClient:     httpClient,
 		Config:         cfg,
 		UserRepository: userRepo,
 		UserUseCase:    userUseCase,
 		UserHandler:    userHandler,
+		HealthHandler:  healthHandler,
+		PolicyEngine:   policyEngine,
+		Metrics:        metricsRegistry,
+		Locker:         locker,
+		Elections:      elections,
+		Broker:         broker,
+		Consumer:       eventConsumer,
+		Commands:       commandWorker,
+		Jobs:           jobWorker,
+		Cron:           scheduler,
+		Workers:        workerPool,
+		Kafka:          kafkaWriter,
+		Capabilities:   caps,
+		SLO:            sloTracker,
+		Degradation:    degradation,
+		listMetrics:    listMetrics,
+
+		DeadLetterRepository: deadLetterRepo,
+		DeadLetterUseCase:    deadLetterUseCase,
+
+		UserDeletionUseCase: userDeletionUseCase,
+		deletionPurge:       elections.Elector("user-deletion-purge"),
+
+		UserSuggestUseCase: userSuggestUseCase,
+
+		EmailSender: emailSender,
+
+		Storage:        objectStorage,
+		ExportUseCase:  exportUseCase,
+		exportCleanup:  elections.Elector("export-cleanup"),
+		ImportUseCase:  importUseCase,
+		uploadCleanup:  elections.Elector("upload-cleanup"),
+		UsageUseCase:   usageUseCase,
+		AuditUseCase:   auditUseCase,
+		auditShipping:  elections.Elector("audit-shipping"),
+		auditAnchoring: elections.Elector("audit-anchoring"),
+
+		WebhookUseCase:  webhookUseCase,
+		webhookDelivery: elections.Elector("webhook-delivery"),
+		EventStreams:    eventStreams,
+
+		GuardedHTTPClient: guardedHTTPClient,
+		AuthProvider:      authProvider,
+		ldapPool:          ldapPool,
+		AuthUseCase:       authUseCase,
+		APIKeyUseCase:     apiKeyUseCase,
 	}
 }
 
@@ -87,22 +1008,476 @@ func (a *App) Context() context.Context {
 func (a *App) WithContext(ctx context.Context) *App {
 	return &App{
 		Logger:         a.Logger.WithContext(ctx),
+		Logs:           a.Logs,
 		Router:         a.Router,
+		AdminRouter:    a.AdminRouter,
+		HealthRouter:   a.HealthRouter,
+		Startup:        a.Startup,
 		ctx:            ctx,
 		DB:             a.DB,
+		Redis:          a.Redis,
+		HTTPClient:     a.HTTPClient,
 		Config:         a.Config,
 		UserRepository: a.UserRepository,
 		UserUseCase:    a.UserUseCase,
 		UserHandler:    a.UserHandler,
+		HealthHandler:  a.HealthHandler,
+		PolicyEngine:   a.PolicyEngine,
+		Metrics:        a.Metrics,
+		Locker:         a.Locker,
+		Elections:      a.Elections,
+		Broker:         a.Broker,
+		Consumer:       a.Consumer,
+		Commands:       a.Commands,
+		Jobs:           a.Jobs,
+		Cron:           a.Cron,
+		Workers:        a.Workers,
+		Kafka:          a.Kafka,
+		Capabilities:   a.Capabilities,
+		SLO:            a.SLO,
+		Degradation:    a.Degradation,
+		listMetrics:    a.listMetrics,
+
+		DeadLetterRepository: a.DeadLetterRepository,
+		DeadLetterUseCase:    a.DeadLetterUseCase,
+
+		UserDeletionUseCase: a.UserDeletionUseCase,
+		deletionPurge:       a.deletionPurge,
+
+		UserSuggestUseCase: a.UserSuggestUseCase,
+
+		EmailSender: a.EmailSender,
+
+		Storage:        a.Storage,
+		ExportUseCase:  a.ExportUseCase,
+		exportCleanup:  a.exportCleanup,
+		ImportUseCase:  a.ImportUseCase,
+		uploadCleanup:  a.uploadCleanup,
+		UsageUseCase:   a.UsageUseCase,
+		AuditUseCase:   a.AuditUseCase,
+		auditShipping:  a.auditShipping,
+		auditAnchoring: a.auditAnchoring,
+
+		WebhookUseCase:  a.WebhookUseCase,
+		webhookDelivery: a.webhookDelivery,
+		EventStreams:    a.EventStreams,
+
+		GuardedHTTPClient: a.GuardedHTTPClient,
+		AuthProvider:      a.AuthProvider,
+		ldapPool:          a.ldapPool,
+		AuthUseCase:       a.AuthUseCase,
+		APIKeyUseCase:     a.APIKeyUseCase,
+	}
+}
+
+// Start starts the application's background processing
+func (a *App) Start(ctx context.Context) error {
+	a.Workers.Start(ctx)
+	if a.SLO != nil {
+		go a.SLO.Run(ctx)
+	}
+	go a.Degradation.Run(ctx)
+	if interval := a.Config.Database.ListSummaryInterval; interval > 0 {
+		go a.listMetrics.Run(ctx, interval)
+	}
+	go a.deletionPurge.Run(ctx, a.purgeDeletions)
+	// The suggestion index is built right away; the cron job rebuilds it.
+	// Failures are logged and retried on the next run.
+	if err := a.Workers.Submit("users.refresh-suggestions", a.UserSuggestUseCase.Refresh); err != nil {
+		return fmt.Errorf("failed to refresh user suggestions: %w", err)
+	}
+	go a.exportCleanup.Run(ctx, a.cleanupExports)
+	go a.uploadCleanup.Run(ctx, a.cleanupUploads)
+	if a.UsageUseCase != nil {
+		go a.flushUsage(ctx)
+	}
+	if a.AuditUseCase != nil {
+		go a.auditShipping.Run(ctx, a.shipAudit)
+		go a.auditAnchoring.Run(ctx, a.anchorAudit)
 	}
+	go a.webhookDelivery.Run(ctx, a.deliverWebhooks)
+	if a.Commands != nil {
+		a.Commands.Start(ctx)
+	}
+	if a.Jobs != nil {
+		a.Jobs.Start(ctx)
+	}
+	a.Cron.Start(ctx)
+	return a.Consumer.Start(ctx)
+}
+
+// purgeDeletions periodically deletes users whose deletion grace period has
+// passed. It runs on the leader only so users are purged once.
+func (a *App) purgeDeletions(ctx context.Context) {
+	every(ctx, a.Config.Users.DeletionPurgeInterval, func(now time.Time) {
+		purged, err := a.UserDeletionUseCase.PurgeDue(ctx, now)
+		if err != nil {
+			a.Logger.WithField("error", err.Error()).Error("Failed to purge scheduled user deletions")
+		}
+		if purged > 0 {
+			a.Logger.WithField("purged", purged).Info("Purged users after deletion grace period")
+		}
+	})
+}
+
+// cleanupExports periodically deletes export artifacts past their retention.
+// It runs on the leader only.
+func (a *App) cleanupExports(ctx context.Context) {
+	every(ctx, a.Config.Exports.CleanupInterval, func(now time.Time) {
+		removed, err := a.ExportUseCase.CleanupExpired(ctx, now)
+		if err != nil {
+			a.Logger.WithField("error", err.Error()).Error("Failed to clean up expired exports")
+		}
+		if removed > 0 {
+			a.Logger.WithField("removed", removed).Info("Removed expired export artifacts")
+		}
+	})
+}
+
+// cleanupUploads periodically discards import uploads left unfinished past
+// their expiry. It runs on the leader only.
+func (a *App) cleanupUploads(ctx context.Context) {
+	every(ctx, a.Config.Imports.CleanupInterval, func(now time.Time) {
+		removed, err := a.ImportUseCase.CleanupExpired(ctx, now)
+		if err != nil {
+			a.Logger.WithField("error", err.Error()).Error("Failed to clean up expired uploads")
+		}
+		if removed > 0 {
+			a.Logger.WithField("removed", removed).Info("Removed expired import uploads")
+		}
+	})
+}
+
+// flushUsage periodically adds the API calls counted by this instance to
+// the daily rollups. Every instance flushes its own counts.
+func (a *App) flushUsage(ctx context.Context) {
+	every(ctx, a.Config.Usage.FlushInterval, func(now time.Time) {
+		// Failures are logged and retried on the next tick
+		_ = a.UsageUseCase.Flush(ctx)
+	})
+}
+
+// shipAudit periodically ships the audit entries recorded since each
+// sink's checkpoint. It runs on the leader only so sinks see one shipper.
+func (a *App) shipAudit(ctx context.Context) {
+	every(ctx, a.Config.Audit.ShipInterval, func(now time.Time) {
+		// Failures are logged and the entries shipped again on the next tick
+		_ = a.AuditUseCase.Ship(ctx)
+	})
+}
+
+// anchorAudit periodically anchors the head of the audit chain. It runs on
+// the leader only.
+func (a *App) anchorAudit(ctx context.Context) {
+	every(ctx, a.Config.Audit.AnchorInterval, func(now time.Time) {
+		if _, err := a.AuditUseCase.Anchor(ctx); err != nil {
+			a.Logger.WithField("error", err.Error()).Error("Failed to anchor audit chain")
+		}
+	})
 }
 
-// Shutdown gracefully shuts down the application
-func (a *App) Shutdown(ctx context.Context) error {
+// deliverWebhooks periodically attempts the webhook deliveries that are
+// due. It runs on the leader only so each attempt is made once.
+func (a *App) deliverWebhooks(ctx context.Context) {
+	every(ctx, a.Config.Webhooks.PollInterval, func(now time.Time) {
+		delivered, err := a.WebhookUseCase.DeliverDue(ctx, now)
+		if err != nil {
+			a.Logger.WithField("error", err.Error()).Error("Failed to deliver webhooks")
+		}
+		if delivered > 0 {
+			a.Logger.WithField("delivered", delivered).Info("Delivered webhooks")
+		}
+	})
+}
+
+// every calls fn on each tick of interval until ctx is done
+func every(ctx context.Context, interval time.Duration, fn func(now time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			fn(now)
+		}
+	}
+}
+
+// newSLOTracker creates a tracker for the configured route objectives that
+// logs burn rate alerts
+func newSLOTracker(cfg configs.SLOConfig, logger logger.Logger) *slo.Tracker {
+	objectives := make([]slo.Objective, 0, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		objectives = append(objectives, slo.Objective{
+			Method:        route.Method,
+			Route:         route.Route,
+			Availability:  route.Availability,
+			Latency:       route.Latency,
+			LatencyTarget: route.LatencyTarget,
+		})
+	}
+	return slo.NewTracker(objectives, slo.Options{
+		ShortWindow:       cfg.ShortWindow,
+		LongWindow:        cfg.LongWindow,
+		BurnRateThreshold: cfg.BurnRateThreshold,
+		CheckInterval:     cfg.CheckInterval,
+		Notifier:          slo.NewLogNotifier(logger),
+	})
+}
+
+// newQueryBudgets converts the configured query budgets for the database
+// plugin enforcing them
+func newQueryBudgets(cfg configs.DatabaseConfig) map[string]database.QueryBudget {
+	return map[string]database.QueryBudget{
+		repositories.OperationRead: {Timeout: cfg.QueryReadTimeout},
+		repositories.OperationList: {
+			Timeout:        cfg.QueryListTimeout,
+			MaxRows:        cfg.QueryListMaxRows,
+			MaxRowsScanned: cfg.QueryListMaxRowsScanned,
+		},
+		repositories.OperationWrite: {Timeout: cfg.QueryWriteTimeout},
+	}
+}
+
+// newCostTracker creates the tracker counting request costs against the
+// configured route budgets
+func newCostTracker(cfg configs.CostConfig, logger logger.Logger) *cost.Tracker {
+	budgets := make([]cost.Budget, 0, len(cfg.Budgets))
+	for _, budget := range cfg.Budgets {
+		budgets = append(budgets, cost.Budget{
+			Method:        budget.Method,
+			Route:         budget.Route,
+			Queries:       budget.Queries,
+			Rows:          budget.Rows,
+			ExternalCalls: budget.ExternalCalls,
+		})
+	}
+	return cost.NewTracker(budgets, logger)
+}
+
+// newAPIDefaults converts the configured API defaults for the handlers
+func newAPIDefaults(cfg configs.APIDefaultsConfig) handlers.APIDefaults {
+	return handlers.APIDefaults{
+		PageSize:             cfg.DefaultPageSize,
+		MaxPageSize:          cfg.MaxPageSize,
+		AdminPageSize:        cfg.AdminPageSize,
+		MaxFilters:           cfg.MaxFilters,
+		MaxFilterValueLength: cfg.MaxFilterValueLength,
+		MaxFilterInValues:    cfg.MaxFilterInValues,
+		MaxSortFields:        cfg.MaxSortFields,
+		HealthCheckTimeout:   cfg.HealthCheckTimeout,
+	}
+}
+
+// newSessionStore keeps server-side sessions in Redis when it is configured,
+// so they are shared by every instance
+func newSessionStore(client *goredis.Client, logger logger.Logger) auth.SessionStore {
+	if client != nil {
+		return redisinfra.NewSessionStore(client)
+	}
+	logger.Warn("REDIS_ADDR or REDIS_URL is not set; sessions are kept in memory and only valid on this instance")
+	return authinfra.NewMemorySessionStore()
+}
+
+// newRevocationStore keeps the access tokens revoked by logging out in
+// Redis when it is configured, so every instance refuses them
+func newRevocationStore(client *goredis.Client, logger logger.Logger) auth.RevocationStore {
+	if client != nil {
+		return redisinfra.NewRevocationStore(client)
+	}
+	logger.Warn("REDIS_ADDR or REDIS_URL is not set; logged out tokens are only refused by the instance that revoked them")
+	return authinfra.NewMemoryRevocationStore()
+}
+
+// newAttemptStore counts failed logins in Redis when it is configured, so
+// every instance enforces the same lockouts
+func newAttemptStore(client *goredis.Client, logger logger.Logger) auth.AttemptStore {
+	if client != nil {
+		return redisinfra.NewAttemptStore(client)
+	}
+	logger.Warn("REDIS_ADDR or REDIS_URL is not set; failed logins are counted in memory and per instance")
+	return authinfra.NewMemoryAttemptStore()
+}
+
+// newSecretCipher creates the cipher TOTP secrets are encrypted with. The
+// key was validated with the config.
+func newSecretCipher(cfg configs.MFAConfig, logger logger.Logger) auth.SecretCipher {
+	key, err := cfg.Key()
+	if err != nil {
+		logger.Fatal("Failed to decode AUTH_MFA_ENCRYPTION_KEY:", err)
+	}
+	cipher, err := authinfra.NewAESCipher(key)
+	if err != nil {
+		logger.Fatal("Failed to configure MFA:", err)
+	}
+	return cipher
+}
+
+// newAuditSinks creates the audit sinks listed by configuration, in order
+func newAuditSinks(cfg configs.AuditConfig, httpClient *http.Client) ([]usecase.AuditSink, error) {
+	sinks := make([]usecase.AuditSink, 0, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "syslog":
+			sinks = append(sinks, auditinfra.NewSyslogSink(cfg.Syslog.Network, cfg.Syslog.Addr, cfg.Syslog.AppName))
+		case "s3":
+			client, err := s3.New(s3.Config{
+				Bucket:          cfg.S3.Bucket,
+				Region:          cfg.S3.Region,
+				AccessKeyID:     cfg.S3.AccessKeyID,
+				SecretAccessKey: cfg.S3.SecretAccessKey,
+				Endpoint:        cfg.S3.Endpoint,
+			}, httpClient)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, auditinfra.NewS3Sink(client, cfg.S3.Prefix))
+		case "http":
+			sinks = append(sinks, auditinfra.NewHTTPSink(cfg.HTTP.URL, cfg.HTTP.Token, httpClient))
+		default:
+			return nil, fmt.Errorf("unknown audit sink %q", name)
+		}
+	}
+	return sinks, nil
+}
+
+// newNotificationChannels creates the notification channels named in
+// cfg.Channels; email goes through notifier
+func newNotificationChannels(cfg configs.NotificationsConfig, notifier *notificationinfra.EmailNotifier, httpClient *http.Client, logger logger.Logger) ([]notification.Channel, error) {
+	channels := make([]notification.Channel, 0, len(cfg.Channels))
+	for _, name := range cfg.Channels {
+		switch name {
+		case "email":
+			channels = append(channels, notifier)
+		case "webhook":
+			channels = append(channels, notificationinfra.NewWebhookChannel(cfg.Webhook.URL, cfg.Webhook.Token, httpClient))
+		case "log":
+			channels = append(channels, notificationinfra.NewLogChannel(logger))
+		default:
+			return nil, fmt.Errorf("unknown notification channel %q", name)
+		}
+	}
+	return channels, nil
+}
+
+// newSAMLTenants creates the service providers of the configured SAML
+// tenants, keyed by tenant ID
+func newSAMLTenants(cfg configs.AuthConfig, httpClient *http.Client, logger logger.Logger) map[string]handlers.SAMLTenant {
+	cert, key, err := saml.LoadKeyPair(cfg.SAML.CertFile, cfg.SAML.KeyFile)
+	if err != nil {
+		logger.Fatal("Failed to load SAML key pair:", err)
+	}
+	tenants, err := configs.LoadSAMLTenants(cfg.SAML.TenantsFile)
+	if err != nil {
+		logger.Fatal("Failed to load SAML tenants:", err)
+	}
+
+	samlTenants := make(map[string]handlers.SAMLTenant, len(tenants))
+	for _, tenant := range tenants {
+		sp, err := authinfra.NewSAMLServiceProvider(cfg.SAML, tenant, cert, key, []byte(cfg.JWTSecret), httpClient)
+		if err != nil {
+			logger.Fatalf("Failed to configure SAML tenant %q: %v", tenant.ID, err)
+		}
+		if len(tenant.EmailDomains) == 0 {
+			logger.WithField("tenant", tenant.ID).Warn("SAML tenant has no email_domains; its identity provider may assert any email")
+		}
+		samlTenants[tenant.ID] = authinfra.NewSAMLTenant(sp, tenant)
+	}
+	logger.WithField("tenants", len(samlTenants)).Info("SAML single sign-on enabled")
+	return samlTenants
+}
+
+// newOIDCProviders creates the clients of the configured OpenID providers,
+// keyed by provider ID
+func newOIDCProviders(cfg configs.AuthConfig, httpClient *http.Client, logger logger.Logger) map[string]handlers.OIDCProvider {
+	configured := map[string]configs.OIDCProviderConfig{
+		authinfra.OIDCGoogle:  cfg.OIDC.Google,
+		authinfra.OIDCGeneric: cfg.OIDC.Generic,
+	}
+
+	providers := make(map[string]handlers.OIDCProvider, len(configured))
+	for id, provider := range configured {
+		if !provider.Enabled() {
+			continue
+		}
+		rp, err := authinfra.NewOIDCRelyingParty(cfg.OIDC, id, provider, []byte(cfg.JWTSecret), httpClient)
+		if err != nil {
+			logger.Fatalf("Failed to configure OIDC provider %q: %v", id, err)
+		}
+		if len(provider.EmailDomains) == 0 {
+			logger.WithField("provider", id).Warn("OIDC provider has no email domains; any of its accounts may log in")
+		}
+		providers[id] = authinfra.NewOIDCProvider(rp, id, cfg.OIDC, provider)
+	}
+	logger.WithField("providers", len(providers)).Info("OIDC single sign-on enabled")
+	return providers
+}
+
+// Shutdown gracefully shuts down the application, recording each step in
+// report
+func (a *App) Shutdown(ctx context.Context, report *shutdown.Report) error {
 	a.Logger.Info("Shutting down application...")
 
+	// Drain in-flight commands and events before the database goes away
+	if a.Commands != nil {
+		if err := report.Run(ctx, "workers", "commands", a.Commands.Stop); err != nil {
+			a.Logger.Error("Failed to stop command worker:", err)
+		}
+	}
+	if a.Jobs != nil {
+		if err := report.Run(ctx, "workers", "jobs", a.Jobs.Stop); err != nil {
+			a.Logger.Error("Failed to stop job worker:", err)
+		}
+	}
+	if a.Cron != nil {
+		if err := report.Run(ctx, "workers", "cron", a.Cron.Stop); err != nil {
+			a.Logger.Error("Failed to stop cron scheduler:", err)
+		}
+	}
+	if err := report.Run(ctx, "workers", "event handlers", a.Consumer.Stop); err != nil {
+		a.Logger.Error("Failed to stop event handlers:", err)
+	}
+	// Tasks handed off by requests, commands and events finish before the
+	// connections they use are closed
+	if a.Workers != nil {
+		if err := report.Run(ctx, "workers", "pool", a.Workers.Stop); err != nil {
+			a.Logger.Error("Failed to stop worker pool:", err)
+		}
+	}
+	// Events still lingering in the Kafka producer are flushed once no
+	// handler can add more
+	if a.Kafka != nil {
+		if err := report.Run(ctx, "broker", "kafka", a.Kafka.Close); err != nil {
+			a.Logger.Error("Failed to flush Kafka producer:", err)
+		}
+	}
+	if err := report.Run(ctx, "broker", "messaging", a.Broker.Close); err != nil {
+		a.Logger.Error("Failed to close messaging broker:", err)
+	}
+
+	if a.Redis != nil {
+		if err := report.Run(ctx, "redis", "redis", func() error { return redis.Close(a.Redis) }); err != nil {
+			a.Logger.Error("Failed to close Redis connection:", err)
+		}
+	}
+
+	if a.ldapPool != nil {
+		if err := report.Run(ctx, "ldap", "ldap", a.ldapPool.Close); err != nil {
+			a.Logger.Error("Failed to close LDAP connections:", err)
+		}
+	}
+
+	// Counts not yet flushed would be lost with the process
+	if a.UsageUseCase != nil {
+		if err := report.Run(ctx, "workers", "usage", func() error { return a.UsageUseCase.Flush(ctx) }); err != nil {
+			a.Logger.Error("Failed to flush API usage:", err)
+		}
+	}
+
 	// Close database connection
-	if err := database.CloseDatabase(); err != nil {
+	if err := report.Run(ctx, "database", "postgres", database.CloseDatabase); err != nil {
 		a.Logger.Error("Failed to close database connection:", err)
 	}
 
@@ -0,0 +1,227 @@
+package app
+
+import (
+	"net/http"
+	"sort"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/policy"
+	authinfra "clean-architecture/internal/infrastructure/auth"
+	"clean-architecture/internal/interfaces/http/adminui"
+	authmw "clean-architecture/internal/interfaces/http/middleware/auth"
+	"clean-architecture/internal/interfaces/http/middleware/servertiming"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/capabilities"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/retryafter"
+	"clean-architecture/pkg/utils"
+	"clean-architecture/pkg/webhooksig"
+)
+
+// newCapabilities registers the optional subsystems of this deployment and
+// their client-relevant settings. Modules that are not part of the build are
+// registered as disabled so clients can rely on every key being present.
+func newCapabilities(cfg *configs.Config) *capabilities.Registry {
+	caps := capabilities.NewRegistry()
+	// Features whose routes SERVER_DISABLED_ROUTES leaves out are disabled
+	routes := cfg.Server.RouteEnabled
+
+	caps.Register("authorization", cfg.Policy.Enabled, map[string]interface{}{
+		"driver": cfg.Policy.Driver,
+	})
+	caps.Register("email_change_confirmation", routes("users"), map[string]interface{}{
+		"path":        "/api/v1/email-changes/confirm",
+		"ttl_seconds": int64(cfg.Users.EmailChangeTTL.Seconds()),
+	})
+	caps.Register("email_masking", cfg.Policy.Enabled, nil)
+	caps.Register("scopes", cfg.Auth.RequireScopes, map[string]interface{}{
+		"available": policy.Scopes,
+	})
+	caps.Register("domain_events", true, map[string]interface{}{
+		"driver": cfg.Messaging.Driver,
+	})
+	caps.Register("correlation_ids", true, map[string]interface{}{
+		"header": correlation.Header,
+	})
+	caps.Register("server_timing", cfg.Server.DebugTimings == "header" || cfg.Server.DebugTimings == "envelope", map[string]interface{}{
+		"header":   servertiming.Header,
+		"envelope": cfg.Server.DebugTimings == "envelope",
+	})
+	caps.Register("envelope", true, map[string]interface{}{
+		"header":   utils.EnvelopeHeader,
+		"default":  utils.EnvelopeV1,
+		"versions": []int{utils.EnvelopeV1, utils.EnvelopeV2},
+	})
+	caps.Register("changelog", true, map[string]interface{}{
+		"path": "/api/v1/changelog",
+	})
+	caps.Register("json_schemas", true, map[string]interface{}{
+		"path": "/api/v1/schemas",
+	})
+	caps.Register("account_deletion", routes("me"), map[string]interface{}{
+		"grace_period_seconds": int64(cfg.Users.DeletionGracePeriod.Seconds()),
+	})
+	caps.Register("user_suggest", routes("users"), map[string]interface{}{
+		"path":            "/api/v1/users/suggest",
+		"limit":           cfg.Users.SuggestLimit,
+		"max_limit":       cfg.Users.SuggestMaxLimit,
+		"refresh_seconds": int64(cfg.Users.SuggestRefreshInterval.Seconds()),
+	})
+	caps.Register("export", routes("bulk"), map[string]interface{}{
+		"formats": usecase.ExportFormats,
+		"async":   true,
+		"stream":  true,
+	})
+	caps.Register("import", routes("bulk"), map[string]interface{}{
+		"formats":        []string{"csv"},
+		"resumable":      true,
+		"max_size":       int64(cfg.Imports.MaxSize),
+		"max_chunk_size": int64(cfg.Imports.ChunkSize),
+	})
+	caps.Register("request_limits", true, map[string]interface{}{
+		"max_body_size": int64(cfg.Server.MaxBodySize),
+	})
+	// Clients wait at least Retry-After before retrying a 429 or 503, and
+	// back off exponentially with jitter while retries keep failing. Only
+	// idempotent methods are retried automatically.
+	caps.Register("retries", true, map[string]interface{}{
+		"header":                      retryafter.Header,
+		"statuses":                    []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		"default_retry_after_seconds": int64(cfg.APIDefaults.RetryAfter.Seconds()),
+		"backoff":                     "exponential_jitter",
+		"idempotent_methods":          []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete},
+	})
+	caps.Register("api_defaults", true, map[string]interface{}{
+		"default_page_size":       cfg.APIDefaults.DefaultPageSize,
+		"max_page_size":           cfg.APIDefaults.MaxPageSize,
+		"max_filters":             cfg.APIDefaults.MaxFilters,
+		"max_filter_value_length": cfg.APIDefaults.MaxFilterValueLength,
+		"max_filter_in_values":    cfg.APIDefaults.MaxFilterInValues,
+		"max_sort_fields":         cfg.APIDefaults.MaxSortFields,
+	})
+	caps.Register("authentication", cfg.Auth.Enabled() && routes("auth"), map[string]interface{}{
+		"login_path":        "/api/v1/auth/login",
+		"logout_path":       "/api/v1/auth/logout",
+		"token_type":        "Bearer",
+		"token_ttl_seconds": int64(cfg.Auth.AccessTokenTTL.Seconds()),
+		"password_login":    cfg.Auth.LDAP.Enabled() || cfg.Auth.SignupEnabled,
+		"signup":            cfg.Auth.SignupEnabled,
+	})
+	caps.Register("sessions", cfg.Auth.Enabled() && cfg.Auth.Sessions.Enabled && routes("auth"), map[string]interface{}{
+		"login_path":           "/api/v1/auth/session",
+		"cookie_name":          cfg.Auth.Sessions.CookieName,
+		"ttl_seconds":          int64(cfg.Auth.Sessions.TTL.Seconds()),
+		"idle_timeout_seconds": int64(cfg.Auth.Sessions.IdleTimeout.Seconds()),
+	})
+	caps.Register("api_keys", cfg.Auth.Enabled() && routes("apikeys"), map[string]interface{}{
+		"header": authmw.APIKeyHeader,
+		"path":   "/api/v1/apikeys",
+	})
+	caps.Register("service_accounts", cfg.Auth.Enabled() && routes("organizations"), map[string]interface{}{
+		"path": "/api/v1/organizations/{id}/service-accounts",
+	})
+	caps.Register("ldap", cfg.Auth.LDAP.Enabled(), nil)
+	caps.Register("saml", cfg.Auth.Enabled() && cfg.Auth.SAML.Enabled() && routes("auth"), map[string]interface{}{
+		"login_path":    "/api/v1/auth/saml/{tenant}/login",
+		"metadata_path": "/api/v1/auth/saml/{tenant}/metadata",
+	})
+	caps.Register("oidc", cfg.Auth.Enabled() && cfg.Auth.OIDC.Enabled() && routes("auth"), map[string]interface{}{
+		"login_path": "/api/v1/auth/oidc/login",
+		"providers":  oidcProviders(cfg.Auth.OIDC),
+	})
+	caps.Register("mfa", cfg.Auth.Enabled() && cfg.Auth.MFA.Enabled() && routes("me"), map[string]interface{}{
+		"path":   "/api/v1/me/mfa",
+		"method": "totp",
+	})
+	caps.Register("login_lockout", cfg.Auth.Enabled() && cfg.Auth.Lockout.Enabled(), map[string]interface{}{
+		"max_attempts":        cfg.Auth.Lockout.MaxAttempts,
+		"max_attempts_per_ip": cfg.Auth.Lockout.MaxAttemptsPerIP,
+		"window_seconds":      int64(cfg.Auth.Lockout.Window.Seconds()),
+		"duration_seconds":    int64(cfg.Auth.Lockout.Duration.Seconds()),
+	})
+	caps.Register("quotas", routes("quotas"), map[string]interface{}{
+		"path":      "/api/v1/quotas",
+		"resources": []string{entities.QuotaResourceUsers},
+	})
+	caps.Register("billing", cfg.Billing.Enabled() && routes("billing"), map[string]interface{}{
+		"provider":     cfg.Billing.Provider,
+		"webhook_path": "/api/v1/billing/webhooks",
+		"default_plan": cfg.Billing.DefaultPlan,
+		"plans":        entities.PlanIDs(),
+	})
+	caps.Register("usage_metering", cfg.Usage.Enabled && routes("usage"), map[string]interface{}{
+		"path":                   "/api/v1/usage",
+		"formats":                []string{"json", "csv"},
+		"flush_interval_seconds": int64(cfg.Usage.FlushInterval.Seconds()),
+	})
+	caps.Register("audit_shipping", cfg.Audit.Enabled() && routes("audit-log"), map[string]interface{}{
+		"path":                    "/api/v1/audit-log",
+		"sinks":                   append([]string{}, cfg.Audit.Sinks...),
+		"ship_interval_seconds":   int64(cfg.Audit.ShipInterval.Seconds()),
+		"anchor_interval_seconds": int64(cfg.Audit.AnchorInterval.Seconds()),
+	})
+	caps.Register("notifications", len(cfg.Notifications.Channels) > 0, map[string]interface{}{
+		"channels": append([]string{}, cfg.Notifications.Channels...),
+		"events":   append([]string{}, cfg.Notifications.Events...),
+	})
+	caps.Register("webhooks", routes("webhooks"), map[string]interface{}{
+		"path":                    "/api/v1/webhooks",
+		"events":                  append([]string{}, events.Types...),
+		"max_attempts":            cfg.Webhooks.MaxAttempts,
+		"initial_backoff_seconds": int64(cfg.Webhooks.InitialBackoff.Seconds()),
+		"max_backoff_seconds":     int64(cfg.Webhooks.MaxBackoff.Seconds()),
+		"signature_header":        webhooksig.Header,
+	})
+	caps.Register("event_stream", routes("events"), map[string]interface{}{
+		"path":              "/api/v1/events",
+		"events":            append([]string{}, events.UserChanges...),
+		"heartbeat_seconds": int64(cfg.EventStream.HeartbeatInterval.Seconds()),
+	})
+	caps.Register("email_suppression", routes("email-suppressions"), map[string]interface{}{
+		"path":              "/api/v1/email-suppressions",
+		"feedback_path":     "/api/v1/email/feedback",
+		"feedback_provider": cfg.Email.Feedback.Provider,
+		"include":           "email_suppression",
+	})
+	caps.Register("feature_flags", routes("feature-flags"), map[string]interface{}{
+		"path":  "/api/v1/feature-flags",
+		"flags": featureFlagKeys(cfg.FeatureFlags),
+	})
+	caps.Register("admin_ui", cfg.Server.AdminUI && routes("admin"), map[string]interface{}{
+		"path": adminui.Path + "/",
+	})
+	caps.Register("scim", cfg.SCIM.Token != "" && routes("scim"), map[string]interface{}{
+		"path":        "/scim/v2",
+		"max_results": cfg.SCIM.MaxResults,
+	})
+
+	caps.Register("search", false, nil)
+	caps.Register("rate_limits", false, nil)
+
+	return caps
+}
+
+// oidcProviders lists the IDs of the configured OpenID providers, passed as
+// the provider parameter of OIDC logins
+func oidcProviders(cfg configs.OIDCConfig) []string {
+	providers := []string{}
+	if cfg.Google.Enabled() {
+		providers = append(providers, authinfra.OIDCGoogle)
+	}
+	if cfg.Generic.Enabled() {
+		providers = append(providers, authinfra.OIDCGeneric)
+	}
+	return providers
+}
+
+// featureFlagKeys lists the keys of the configured feature flags in order
+func featureFlagKeys(cfg configs.FeatureFlagsConfig) []string {
+	keys := make([]string, 0, len(cfg.Defaults))
+	for key := range cfg.Defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
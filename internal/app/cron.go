@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/cron"
+	"clean-architecture/pkg/distlock"
+	"clean-architecture/pkg/logger"
+)
+
+// newScheduler registers the periodic jobs of the application. Jobs working
+// on shared data are singletons, so one replica runs them; jobs refreshing
+// state kept in memory run on every instance.
+func newScheduler(cfg *configs.Config, locker distlock.Locker, users *usecase.UserUseCase,
+	suggestions *usecase.UserSuggestUseCase, logger logger.Logger) (*cron.Scheduler, error) {
+	scheduler := cron.New(logger).WithLocker(locker).WithLocation(cfg.Cron.Location())
+
+	// Every instance keeps its own suggestion index
+	if err := scheduler.Register(cron.Job{
+		Name:     "users.refresh-suggestions",
+		Schedule: cron.Every(cfg.Users.SuggestRefreshInterval),
+		Timeout:  cfg.Cron.Timeout,
+		Run:      suggestions.Refresh,
+	}); err != nil {
+		return nil, err
+	}
+
+	// Soft-deleted users are kept forever unless a retention is set
+	if retention := cfg.Users.DeletedRetention; retention > 0 {
+		schedule, err := cron.Parse(cfg.Users.PurgeDeletedSchedule)
+		if err != nil {
+			return nil, err
+		}
+		if err := scheduler.Register(cron.Job{
+			Name:      "users.purge-deleted",
+			Schedule:  schedule,
+			Singleton: true,
+			Timeout:   cfg.Cron.Timeout,
+			Run: func(ctx context.Context) error {
+				purged, err := users.PurgeDeletedUsers(ctx, time.Now().Add(-retention))
+				if purged > 0 {
+					logger.WithFields(map[string]interface{}{
+						"purged":    purged,
+						"retention": retention.String(),
+					}).Info("Purged soft-deleted users past their retention")
+				}
+				return err
+			},
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return scheduler, nil
+}
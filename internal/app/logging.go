@@ -0,0 +1,47 @@
+package app
+
+import (
+	"fmt"
+
+	"clean-architecture/pkg/logger"
+)
+
+// Modules whose log level can be set with LOG_MODULES and changed at
+// runtime on the admin listener
+const (
+	logModuleAuth      = "auth"
+	logModuleDatabase  = "database"
+	logModuleHTTP      = "http"
+	logModuleMessaging = "messaging"
+	logModulePolicy    = "policy"
+	logModuleStorage   = "storage"
+)
+
+// moduleLoggers holds the named loggers of the application's modules
+type moduleLoggers struct {
+	auth      logger.Logger
+	database  logger.Logger
+	http      logger.Logger
+	messaging logger.Logger
+	policy    logger.Logger
+	storage   logger.Logger
+}
+
+// newModuleLoggers creates the module loggers of logs and applies the
+// configured levels, which may only name these modules
+func newModuleLoggers(logs *logger.Registry, levels map[string]string) (moduleLoggers, error) {
+	loggers := moduleLoggers{
+		auth:      logs.Logger(logModuleAuth),
+		database:  logs.Logger(logModuleDatabase),
+		http:      logs.Logger(logModuleHTTP),
+		messaging: logs.Logger(logModuleMessaging),
+		policy:    logs.Logger(logModulePolicy),
+		storage:   logs.Logger(logModuleStorage),
+	}
+	for module, level := range levels {
+		if err := logs.SetLevel(module, level); err != nil {
+			return loggers, fmt.Errorf("LOG_MODULES %s:%s: %w", module, level, err)
+		}
+	}
+	return loggers, nil
+}
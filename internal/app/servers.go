@@ -0,0 +1,60 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	"clean-architecture/pkg/httpserver"
+)
+
+// NewServers builds the HTTP listeners for the API, admin and health
+// routers. Servers are shut down in the order they are added: the API stops
+// accepting traffic first and health probes keep answering until the end.
+func (a *App) NewServers() *httpserver.Group {
+	cfg := a.Config.Server
+	group := httpserver.NewGroup()
+
+	api := &http.Server{
+		Addr:         fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		Handler:      a.Router,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		MaxHeaderBytes:    int(cfg.MaxHeaderSize),
+	}
+	// Shutdown waits for requests to finish, which event streams never do
+	// on their own
+	if a.EventStreams != nil {
+		api.RegisterOnShutdown(a.EventStreams.Close)
+	}
+	group.Add("api", api)
+
+	if cfg.AdminPort != "" {
+		group.Add("admin", &http.Server{
+			Addr:        fmt.Sprintf("%s:%s", cfg.Host, cfg.AdminPort),
+			Handler:     a.AdminRouter,
+			ReadTimeout: cfg.ReadTimeout,
+			IdleTimeout: cfg.IdleTimeout,
+
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			MaxHeaderBytes:    int(cfg.MaxHeaderSize),
+		})
+	}
+
+	if cfg.HealthPort != "" {
+		group.Add("health", &http.Server{
+			Addr:         fmt.Sprintf("%s:%s", cfg.Host, cfg.HealthPort),
+			Handler:      a.HealthRouter,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			MaxHeaderBytes:    int(cfg.MaxHeaderSize),
+		})
+	}
+
+	return group
+}
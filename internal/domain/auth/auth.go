@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidCredentials is returned for unknown users and wrong
+	// passwords alike, so callers cannot tell which it was
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrAccessDenied is returned when the credentials are valid but the
+	// user is not granted any role
+	ErrAccessDenied = errors.New("access denied")
+	// ErrEmailNotVerified is returned when the password is right but the
+	// user has not yet followed the link sent to their email at signup
+	ErrEmailNotVerified = errors.New("email not verified")
+	// ErrInvalidToken is returned for access tokens that are malformed,
+	// tampered with or expired
+	ErrInvalidToken = errors.New("invalid or expired token")
+	// ErrProviderUnavailable is returned when a provider cannot verify
+	// credentials right now, such as while its directory is down
+	ErrProviderUnavailable = errors.New("identity provider unavailable")
+	// ErrSessionNotFound is returned for server-side sessions that do not
+	// exist, were ended or expired
+	ErrSessionNotFound = errors.New("session not found or expired")
+)
+
+// Identity is a user whose credentials a provider verified
+type Identity struct {
+	// Provider names the provider that verified the credentials
+	Provider string `json:"provider"`
+	// Subject identifies the user within the provider, e.g. an LDAP DN
+	Subject  string   `json:"subject"`
+	Username string   `json:"username"`
+	Email    string   `json:"email,omitempty"`
+	Name     string   `json:"name,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+	// Roles are the policy roles the user's groups map to
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Provider verifies a username and password, such as against a local
+// password store or an external directory
+type Provider interface {
+	// Name identifies the provider, e.g. "ldap"
+	Name() string
+	Authenticate(ctx context.Context, username, password string) (*Identity, error)
+}
+
+// SecretCipher encrypts secrets for storage, such as the TOTP secrets of
+// users. The additional data is authenticated but not encrypted, binding a
+// ciphertext to its owner so it cannot be copied to another row.
+type SecretCipher interface {
+	Encrypt(plaintext, additional []byte) (string, error)
+	// Decrypt returns the plaintext of ciphertext, failing when it or the
+	// additional data were tampered with
+	Decrypt(ciphertext string, additional []byte) ([]byte, error)
+}
+
+// PasswordHasher hashes the passwords of local users for storage
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash
+	Verify(hash, password string) bool
+}
+
+// Claims are the contents of an access token
+type Claims struct {
+	// ID uniquely identifies the token
+	ID string
+	// Subject is the ID of the user the token was issued to
+	Subject   string
+	Roles     []string
+	Scopes    []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// TokenService issues and verifies access tokens
+type TokenService interface {
+	// Issue returns a signed token carrying claims. ID, IssuedAt and
+	// ExpiresAt are set by the service.
+	Issue(ctx context.Context, claims Claims) (token string, issued Claims, err error)
+	// Verify returns the claims of a token, or ErrInvalidToken
+	Verify(ctx context.Context, token string) (*Claims, error)
+}
+
+// ServerSession is a login kept on the server and referenced by an opaque
+// cookie, as an alternative to access tokens
+type ServerSession struct {
+	// ID is the SHA-256 of the cookie value, so stored sessions cannot be
+	// used as cookies
+	ID string `json:"id"`
+	// UserID is the ID of the user who logged in
+	UserID    string    `json:"user_id"`
+	Roles     []string  `json:"roles"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt is when the session ends however active it is
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SessionStore keeps server-side sessions. Sessions not touched for their
+// idle timeout are dropped.
+type SessionStore interface {
+	// Create stores a new session that is dropped after idle without use
+	Create(ctx context.Context, session *ServerSession, idle time.Duration) error
+	// Touch returns the session with id and keeps it for idle more, or
+	// ErrSessionNotFound
+	Touch(ctx context.Context, id string, idle time.Duration) (*ServerSession, error)
+	// Delete removes the session with id; unknown sessions are no error
+	Delete(ctx context.Context, id string) error
+}
+
+// AttemptStore counts the failed logins of keys, such as a username or a
+// client address, and locks keys that failed too often
+type AttemptStore interface {
+	// Fail records a failed login of key and returns the failures counted
+	// since the first one, which are forgotten window after it
+	Fail(ctx context.Context, key string, window time.Duration) (int, error)
+	// Lock refuses logins of key until the given time and forgets its
+	// failures
+	Lock(ctx context.Context, key string, until time.Time) error
+	// LockedUntil returns when the lock of key ends, or the zero time when
+	// key is not locked
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	// Reset forgets the failures and lock of key
+	Reset(ctx context.Context, key string) error
+}
+
+// RevocationStore keeps the IDs of access tokens revoked before they
+// expired
+type RevocationStore interface {
+	// Revoke refuses the token with id until it expires at until
+	Revoke(ctx context.Context, id string, until time.Time) error
+	// Revoked reports whether the token with id was revoked
+	Revoked(ctx context.Context, id string) (bool, error)
+}
@@ -0,0 +1,57 @@
+package billing
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrInvalidSignature is returned for webhooks whose signature is
+	// missing, wrong or too old
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrIgnoredEvent is returned for webhooks of events other than
+	// subscription changes, which are acknowledged without processing
+	ErrIgnoredEvent = errors.New("event type is not processed")
+)
+
+// Event types of subscription changes
+const (
+	EventSubscriptionCreated = "subscription.created"
+	EventSubscriptionUpdated = "subscription.updated"
+	EventSubscriptionDeleted = "subscription.deleted"
+)
+
+// Event is a change to a subscription a billing provider notified
+type Event struct {
+	// ID identifies the event within the provider
+	ID   string
+	Type string
+	// OccurredAt is when the provider created the event. Providers do not
+	// deliver events in order, so older events than the last one applied
+	// to a subscription are ignored.
+	OccurredAt   time.Time
+	Subscription Subscription
+}
+
+// Subscription is the state of a subscription carried by an event
+type Subscription struct {
+	// ID identifies the subscription within the provider
+	ID         string
+	CustomerID string
+	// PlanID is the plan subscribed to, as mapped from the provider's
+	// price
+	PlanID           string
+	Status           string
+	CurrentPeriodEnd time.Time
+}
+
+// Provider verifies and decodes the webhooks a billing provider sends
+type Provider interface {
+	// Name identifies the provider, e.g. "stripe"
+	Name() string
+	// SignatureHeader names the header webhooks carry their signature in
+	SignatureHeader() string
+	// ParseEvent verifies signature over payload and decodes the event.
+	// Events other than subscription changes return ErrIgnoredEvent.
+	ParseEvent(payload []byte, signature string) (*Event, error)
+}
@@ -0,0 +1,26 @@
+package email
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoRecipients is returned for messages addressed to nobody
+var ErrNoRecipients = errors.New("email has no recipients")
+
+// Message is a transactional email. At least one of Text and HTML is set;
+// with both, clients show the HTML and fall back to the text.
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender delivers transactional email, hiding the transport from the use
+// cases that send it
+type Sender interface {
+	// Send delivers msg from the configured sender address. It returns
+	// once the transport accepted the message, which may still bounce.
+	Send(ctx context.Context, msg Message) error
+}
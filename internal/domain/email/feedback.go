@@ -0,0 +1,52 @@
+package email
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRecipientSuppressed is returned by senders checking the
+	// suppression list when every recipient of a message is suppressed
+	ErrRecipientSuppressed = errors.New("every recipient is suppressed")
+	// ErrInvalidSignature is returned for feedback webhooks whose signature
+	// is missing, wrong or too old
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// Feedback types
+const (
+	FeedbackBounce    = "bounce"
+	FeedbackComplaint = "complaint"
+)
+
+// Feedback is a report by the email provider that mail to an address
+// bounced or that its recipient complained about it
+type Feedback struct {
+	// ID identifies the report within the provider
+	ID    string
+	Type  string
+	Email string
+	// Permanent is set for bounces the provider does not expect to
+	// resolve, such as an unknown mailbox. Temporary bounces, such as a
+	// full mailbox, are retried by the provider and do not suppress the
+	// address.
+	Permanent bool
+	// Detail is the provider's diagnostic
+	Detail     string
+	OccurredAt time.Time
+}
+
+// FeedbackProvider verifies and decodes the bounce and complaint webhooks
+// an email provider sends
+type FeedbackProvider interface {
+	// Name identifies the provider, e.g. "mailgun"
+	Name() string
+	// SignatureHeader names the header webhooks carry their signature in;
+	// empty for providers signing within the body
+	SignatureHeader() string
+	// ParseFeedback verifies signature over payload and decodes the
+	// reports it carries. Reports of other events, such as deliveries,
+	// are left out.
+	ParseFeedback(payload []byte, signature string) ([]Feedback, error)
+}
@@ -0,0 +1,68 @@
+package email
+
+import "time"
+
+// Template is the data of a transactional email. Each template has its own
+// data type, whose fields the template's files refer to.
+type Template interface {
+	// TemplateName names the files of the template
+	TemplateName() string
+}
+
+// Renderer renders templates into messages
+type Renderer interface {
+	// Render renders the template of data in locale, such as "de-AT", or in
+	// the closest locale the template is translated into. The message has
+	// no recipients.
+	Render(locale string, data Template) (Message, error)
+}
+
+// EmailChangeConfirmation asks a user to confirm their new address
+type EmailChangeConfirmation struct {
+	Name     string
+	NewEmail string
+	// ConfirmURL applies the change without signing in
+	ConfirmURL string
+	ExpiresAt  time.Time
+}
+
+// TemplateName implements Template
+func (EmailChangeConfirmation) TemplateName() string { return "email_change_confirmation" }
+
+// SignupVerification asks a user who signed up to verify their address
+type SignupVerification struct {
+	Name string
+	// VerifyURL activates the account without signing in
+	VerifyURL string
+	ExpiresAt time.Time
+}
+
+// TemplateName implements Template
+func (SignupVerification) TemplateName() string { return "signup_verification" }
+
+// DeletionScheduled tells a user their account is about to be deleted
+type DeletionScheduled struct {
+	Name       string
+	PurgeAfter time.Time
+	// CancelURL aborts the deletion without signing in
+	CancelURL string
+}
+
+// TemplateName implements Template
+func (DeletionScheduled) TemplateName() string { return "deletion_scheduled" }
+
+// Welcome greets a user whose account was just created
+type Welcome struct {
+	Name string
+}
+
+// TemplateName implements Template
+func (Welcome) TemplateName() string { return "welcome" }
+
+// AccountDeleted confirms to a user that their account was deleted
+type AccountDeleted struct {
+	Name string
+}
+
+// TemplateName implements Template
+func (AccountDeleted) TemplateName() string { return "account_deleted" }
@@ -0,0 +1,74 @@
+package entities
+
+import "time"
+
+// API key statuses
+const (
+	APIKeyActive  = "active"
+	APIKeyExpired = "expired"
+	APIKeyRevoked = "revoked"
+)
+
+// APIKey authenticates another service through the X-API-Key header. The
+// key itself is only shown when it is created: Prefix identifies it and
+// SecretHash verifies it.
+type APIKey struct {
+	ID   string `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	Name string `json:"name" gorm:"type:varchar(255);not null"`
+	// Prefix is the public start of the key, used to look it up
+	Prefix string `json:"prefix" gorm:"type:varchar(32);not null;uniqueIndex"`
+	// SecretHash is the SHA-256 of the full key; the key itself is never
+	// stored
+	SecretHash string `json:"-" gorm:"type:varchar(64);not null"`
+	// Scopes are the OAuth scopes requests made with the key are granted
+	Scopes    []string `json:"scopes" gorm:"serializer:json"`
+	CreatedBy string   `json:"created_by,omitempty" gorm:"type:varchar(255)"`
+	// OwnerID is the service account requests made with the key act as;
+	// empty for keys that act as themselves
+	OwnerID    string     `json:"owner_id,omitempty" gorm:"type:varchar(255);index"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"not null"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// TableName specifies the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// NewAPIKey creates a key created by createdBy. expiresAt may be nil for
+// keys that do not expire.
+func NewAPIKey(name, prefix, secretHash string, scopes []string, createdBy string, expiresAt *time.Time) *APIKey {
+	return &APIKey{
+		Name:       name,
+		Prefix:     prefix,
+		SecretHash: secretHash,
+		Scopes:     scopes,
+		CreatedBy:  createdBy,
+		CreatedAt:  time.Now(),
+		ExpiresAt:  expiresAt,
+	}
+}
+
+// Status returns whether the key is active, expired or revoked at now
+func (k *APIKey) Status(now time.Time) string {
+	switch {
+	case k.RevokedAt != nil:
+		return APIKeyRevoked
+	case k.ExpiresAt != nil && !now.Before(*k.ExpiresAt):
+		return APIKeyExpired
+	default:
+		return APIKeyActive
+	}
+}
+
+// Active reports whether the key may still authenticate at now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.Status(now) == APIKeyActive
+}
+
+// Revoke disables the key from at on
+func (k *APIKey) Revoke(at time.Time) {
+	k.RevokedAt = &at
+}
@@ -0,0 +1,38 @@
+package entities
+
+import "time"
+
+// Dead letter kinds
+const (
+	DeadLetterKindEvent = "event"
+	DeadLetterKindJob   = "job"
+)
+
+// DeadLetter is a message that failed processing after every retry
+type DeadLetter struct {
+	ID            string            `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	Kind          string            `json:"kind" gorm:"type:varchar(32);not null;index"`
+	Topic         string            `json:"topic" gorm:"type:varchar(255);not null;index"`
+	ConsumerGroup string            `json:"consumer_group" gorm:"type:varchar(255);not null;index"`
+	MessageID     string            `json:"message_id" gorm:"type:varchar(255);not null"`
+	MessageKey    string            `json:"message_key,omitempty" gorm:"type:varchar(255)"`
+	CorrelationID string            `json:"correlation_id,omitempty" gorm:"type:varchar(255);index"`
+	Payload       []byte            `json:"payload"`
+	Headers       map[string]string `json:"headers,omitempty" gorm:"serializer:json"`
+	Error         string            `json:"error" gorm:"type:text;not null"`
+	Attempts      int               `json:"attempts" gorm:"not null"`
+	FailedAt      time.Time         `json:"failed_at" gorm:"not null;index"`
+	ReplayCount   int               `json:"replay_count" gorm:"not null;default:0"`
+	ReplayedAt    *time.Time        `json:"replayed_at,omitempty"`
+}
+
+// TableName specifies the table name for the DeadLetter model
+func (DeadLetter) TableName() string {
+	return "dead_letters"
+}
+
+// MarkReplayed records a replay of the dead letter
+func (d *DeadLetter) MarkReplayed(at time.Time) {
+	d.ReplayCount++
+	d.ReplayedAt = &at
+}
@@ -0,0 +1,58 @@
+package entities
+
+import "time"
+
+// EmailChange is a request to change a user's email that is only applied
+// once the new address confirms it through an emailed link, so a mistyped
+// address cannot take over the account.
+type EmailChange struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	UserID   string `json:"user_id" gorm:"type:varchar(255);not null;index"`
+	NewEmail string `json:"new_email" gorm:"type:varchar(255);not null"`
+	// TokenHash is the SHA-256 of the token sent in the confirmation link;
+	// the token itself is never stored
+	TokenHash   string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	RequestedAt time.Time  `json:"requested_at" gorm:"not null"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	// SupersededAt is set when a later request replaced this one
+	SupersededAt *time.Time `json:"superseded_at,omitempty"`
+}
+
+// TableName specifies the table name for the EmailChange model
+func (EmailChange) TableName() string {
+	return "email_changes"
+}
+
+// NewEmailChange creates a request to change a user's email to newEmail
+// that can be confirmed until ttl has passed
+func NewEmailChange(userID, newEmail, tokenHash string, ttl time.Duration) *EmailChange {
+	now := time.Now()
+	return &EmailChange{
+		UserID:      userID,
+		NewEmail:    newEmail,
+		TokenHash:   tokenHash,
+		RequestedAt: now,
+		ExpiresAt:   now.Add(ttl),
+	}
+}
+
+// Pending reports whether the request was neither confirmed nor superseded
+func (c *EmailChange) Pending() bool {
+	return c.ConfirmedAt == nil && c.SupersededAt == nil
+}
+
+// Expired reports whether the request can no longer be confirmed at now
+func (c *EmailChange) Expired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
+
+// Confirm records that the new address confirmed the change
+func (c *EmailChange) Confirm(at time.Time) {
+	c.ConfirmedAt = &at
+}
+
+// Supersede records that a later request replaced this one
+func (c *EmailChange) Supersede(at time.Time) {
+	c.SupersededAt = &at
+}
@@ -0,0 +1,45 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// Reasons an address is suppressed
+const (
+	// SuppressionBounce marks addresses whose mail bounced permanently
+	SuppressionBounce = "bounce"
+	// SuppressionComplaint marks addresses whose recipient reported mail
+	// as spam
+	SuppressionComplaint = "complaint"
+)
+
+// EmailSuppression is an address no email is sent to, because the email
+// provider reported it undeliverable or its recipient complained. Sending
+// to it anyway would hurt the sender's reputation.
+type EmailSuppression struct {
+	// Email is the normalized address, see NormalizeEmailAddress
+	Email  string `json:"email" gorm:"primaryKey;type:varchar(255)"`
+	Reason string `json:"reason" gorm:"type:varchar(32);not null"`
+	// Detail is the provider's diagnostic, such as the SMTP reply of a
+	// bounce
+	Detail string `json:"detail,omitempty" gorm:"type:text"`
+	// Provider and FeedbackID identify the report that suppressed the
+	// address
+	Provider   string    `json:"provider" gorm:"type:varchar(64);not null"`
+	FeedbackID string    `json:"feedback_id,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt  time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the EmailSuppression model
+func (EmailSuppression) TableName() string {
+	return "email_suppressions"
+}
+
+// NormalizeEmailAddress returns the form suppressed addresses are stored
+// and looked up in. Providers report addresses in the case they were sent
+// to, which need not match how users entered them.
+func NormalizeEmailAddress(address string) string {
+	return strings.ToLower(strings.TrimSpace(address))
+}
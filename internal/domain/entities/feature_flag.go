@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// FeatureFlag is the state an administrator switched a feature flag to.
+// Flags without one are in their configured default state.
+type FeatureFlag struct {
+	Key       string    `json:"key" gorm:"primaryKey;type:varchar(64)"`
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the FeatureFlag model
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
+
+// NewFeatureFlag creates the state of the flag key
+func NewFeatureFlag(key string, enabled bool) *FeatureFlag {
+	return &FeatureFlag{
+		Key:       key,
+		Enabled:   enabled,
+		UpdatedAt: time.Now(),
+	}
+}
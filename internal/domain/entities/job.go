@@ -0,0 +1,87 @@
+package entities
+
+import "time"
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	// JobExpired marks a succeeded job whose results were discarded
+	JobExpired = "expired"
+)
+
+// Job is a unit of background work requested through the API
+type Job struct {
+	ID          string            `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	Type        string            `json:"type" gorm:"type:varchar(64);not null;index"`
+	Status      string            `json:"status" gorm:"type:varchar(32);not null;index"`
+	Params      map[string]string `json:"params,omitempty" gorm:"serializer:json"`
+	Result      map[string]string `json:"result,omitempty" gorm:"serializer:json"`
+	Error       string            `json:"error,omitempty"`
+	Attempts    int               `json:"attempts" gorm:"not null;default:0"`
+	RequestedBy string            `json:"requested_by,omitempty" gorm:"type:varchar(255);index"`
+	CreatedAt   time.Time         `json:"created_at"`
+	StartedAt   *time.Time        `json:"started_at,omitempty"`
+	FinishedAt  *time.Time        `json:"finished_at,omitempty"`
+	// ExpiresAt is when the results of a succeeded job are discarded
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"index"`
+}
+
+// TableName specifies the table name for the Job model
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// NewJob creates a queued job of the given type
+func NewJob(jobType, requestedBy string, params map[string]string) *Job {
+	return &Job{
+		Type:        jobType,
+		Status:      JobQueued,
+		Params:      params,
+		RequestedBy: requestedBy,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Start records the beginning of an attempt
+func (j *Job) Start(at time.Time) {
+	j.Status = JobRunning
+	j.Attempts++
+	j.Error = ""
+	j.StartedAt = &at
+	j.FinishedAt = nil
+}
+
+// Succeed records the job's results, kept until expiresAt
+func (j *Job) Succeed(result map[string]string, expiresAt, at time.Time) {
+	j.Status = JobSucceeded
+	j.Result = result
+	j.FinishedAt = &at
+	j.ExpiresAt = &expiresAt
+}
+
+// Complete records the job's results, kept as long as the job
+func (j *Job) Complete(result map[string]string, at time.Time) {
+	j.Status = JobSucceeded
+	j.Result = result
+	j.FinishedAt = &at
+}
+
+// Fail records why the latest attempt failed
+func (j *Job) Fail(reason string, at time.Time) {
+	j.Status = JobFailed
+	j.Error = reason
+	j.FinishedAt = &at
+}
+
+// Expire marks the job's results as discarded
+func (j *Job) Expire() {
+	j.Status = JobExpired
+}
+
+// IsFinished reports whether the job will not run again
+func (j *Job) IsFinished() bool {
+	return j.Status == JobSucceeded || j.Status == JobExpired
+}
@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+// Organization groups the service accounts other systems act as
+type Organization struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the Organization model
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// NewOrganization creates a new organization instance
+func NewOrganization(name string) *Organization {
+	now := time.Now()
+	return &Organization{
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
@@ -0,0 +1,103 @@
+package entities
+
+import (
+	"sort"
+	"time"
+)
+
+// Features plans can include
+const (
+	FeatureExports = "exports"
+	FeatureImports = "imports"
+)
+
+// Subscription statuses
+const (
+	SubscriptionActive   = "active"
+	SubscriptionTrialing = "trialing"
+	SubscriptionPastDue  = "past_due"
+	SubscriptionCanceled = "canceled"
+)
+
+// Plan is what a subscription includes: how many users may exist and
+// which features are available
+type Plan struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// MaxUsers becomes the user quota while the plan is subscribed to; 0
+	// is unlimited
+	MaxUsers int      `json:"max_users"`
+	Features []string `json:"features"`
+}
+
+// plans are the plans that can be subscribed to
+var plans = map[string]*Plan{
+	"free":       {ID: "free", Name: "Free", MaxUsers: 5, Features: []string{}},
+	"pro":        {ID: "pro", Name: "Pro", MaxUsers: 100, Features: []string{FeatureExports, FeatureImports}},
+	"enterprise": {ID: "enterprise", Name: "Enterprise", MaxUsers: 0, Features: []string{FeatureExports, FeatureImports}},
+}
+
+// LookupPlan returns a copy of the plan with id
+func LookupPlan(id string) (*Plan, bool) {
+	plan, ok := plans[id]
+	if !ok {
+		return nil, false
+	}
+	copied := *plan
+	return &copied, true
+}
+
+// PlanIDs returns the IDs of every plan, sorted
+func PlanIDs() []string {
+	ids := make([]string, 0, len(plans))
+	for id := range plans {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Includes reports whether the plan includes feature
+func (p *Plan) Includes(feature string) bool {
+	for _, included := range p.Features {
+		if included == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription is the deployment's subscription to a plan with a billing
+// provider, as last notified by the provider's webhooks
+type Subscription struct {
+	// ID identifies the subscription within the provider
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	Provider   string    `json:"provider" gorm:"type:varchar(32);not null"`
+	CustomerID string    `json:"customer_id" gorm:"type:varchar(255)"`
+	PlanID     string    `json:"plan_id" gorm:"type:varchar(64);not null"`
+	Status     string    `json:"status" gorm:"type:varchar(32);not null;index"`
+	PeriodEnd  time.Time `json:"current_period_end"`
+	// EventAt is when the provider created the last event applied and
+	// EventID which it was, so events delivered out of order or twice do
+	// not undo newer ones
+	EventAt   time.Time `json:"-" gorm:"not null"`
+	EventID   string    `json:"-" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the Subscription model
+func (Subscription) TableName() string {
+	return "subscriptions"
+}
+
+// Current reports whether the subscription grants its plan. Past due
+// subscriptions keep it while the provider retries the payment.
+func (s *Subscription) Current() bool {
+	switch s.Status {
+	case SubscriptionActive, SubscriptionTrialing, SubscriptionPastDue:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+// PublishedEvent is a domain event as it was published, recorded before it
+// reaches the broker so it can be audited and published again when its
+// consumers lost it
+type PublishedEvent struct {
+	// ID is the ID of the event, which is also the ID of its message
+	ID            string    `json:"id" gorm:"primaryKey;type:varchar(64)"`
+	Type          string    `json:"type" gorm:"type:varchar(100);not null;index"`
+	AggregateID   string    `json:"aggregate_id" gorm:"type:varchar(64);not null;index"`
+	CorrelationID string    `json:"correlation_id,omitempty" gorm:"type:varchar(255);index"`
+	OccurredAt    time.Time `json:"occurred_at" gorm:"not null"`
+	PublishedAt   time.Time `json:"published_at" gorm:"not null;index"`
+	// Payload and Headers are those of the published message
+	Payload []byte            `json:"payload"`
+	Headers map[string]string `json:"headers,omitempty" gorm:"serializer:json"`
+	// PublishError is why the broker refused the event, empty when it
+	// accepted it
+	PublishError string     `json:"publish_error,omitempty" gorm:"type:text"`
+	ReplayCount  int        `json:"replay_count" gorm:"not null;default:0"`
+	ReplayedAt   *time.Time `json:"replayed_at,omitempty"`
+}
+
+// TableName specifies the table name for the PublishedEvent model
+func (PublishedEvent) TableName() string {
+	return "published_events"
+}
+
+// MarkReplayed records a replay of the event
+func (e *PublishedEvent) MarkReplayed(at time.Time) {
+	e.ReplayCount++
+	e.ReplayedAt = &at
+}
@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+// QuotaResourceUsers names the quota on the number of users
+const QuotaResourceUsers = "users"
+
+// Quota is a limit an administrator set on how many of a resource may
+// exist. Resources without one use the configured default.
+type Quota struct {
+	Resource string `json:"resource" gorm:"primaryKey;type:varchar(64)"`
+	// Limit is the most of the resource that may exist; 0 is unlimited
+	Limit     int       `json:"limit" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the Quota model
+func (Quota) TableName() string {
+	return "quotas"
+}
+
+// NewQuota creates a quota of limit on resource
+func NewQuota(resource string, limit int) *Quota {
+	return &Quota{
+		Resource:  resource,
+		Limit:     limit,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Allows reports whether another of the resource may be created while
+// used of it exist
+func (q *Quota) Allows(used int64) bool {
+	return q.Limit == 0 || used < int64(q.Limit)
+}
@@ -0,0 +1,44 @@
+package entities
+
+import "time"
+
+// SavedView is a named user listing that can be run again with
+// GET /users?view={id}. Personal views are only visible to their owner,
+// while shared views are visible to the whole organization.
+type SavedView struct {
+	ID      string `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	OwnerID string `json:"owner_id" gorm:"type:varchar(255);not null;index"`
+	Name    string `json:"name" gorm:"type:varchar(255);not null"`
+	Shared  bool   `json:"shared" gorm:"not null;default:false;index"`
+	// Filters maps filter parameters such as filter[name][contains] to
+	// their values. They are validated again each time the view runs.
+	Filters map[string]string `json:"filters" gorm:"serializer:json"`
+	// Sort is the sort parameter of the listing, e.g. -created_at,name
+	Sort      string    `json:"sort,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the SavedView model
+func (SavedView) TableName() string {
+	return "saved_views"
+}
+
+// NewSavedView creates a view owned by ownerID
+func NewSavedView(ownerID, name string, shared bool, filters map[string]string, sort string) *SavedView {
+	now := time.Now()
+	return &SavedView{
+		OwnerID:   ownerID,
+		Name:      name,
+		Shared:    shared,
+		Filters:   filters,
+		Sort:      sort,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// VisibleTo reports whether the user may see and run the view
+func (v *SavedView) VisibleTo(userID string) bool {
+	return v.Shared || (userID != "" && v.OwnerID == userID)
+}
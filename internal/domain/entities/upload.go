@@ -0,0 +1,83 @@
+package entities
+
+import "time"
+
+// Upload statuses
+const (
+	UploadActive    = "active"
+	UploadCompleted = "completed"
+	UploadAborted   = "aborted"
+	UploadExpired   = "expired"
+)
+
+// Upload is a file uploaded in chunks so a transfer can resume after the
+// connection drops. Received chunks are stored as separate objects until the
+// upload completes.
+type Upload struct {
+	ID       string `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	Purpose  string `json:"purpose" gorm:"type:varchar(64);not null;index"`
+	Filename string `json:"filename,omitempty" gorm:"type:varchar(255)"`
+	// Size is the total length announced by the client
+	Size int64 `json:"size" gorm:"not null"`
+	// Checksum is the hex SHA-256 of the whole file, verified on assembly
+	Checksum string `json:"checksum" gorm:"type:varchar(64);not null"`
+	// Offset is the number of bytes received so far
+	Offset int64 `json:"offset" gorm:"not null;default:0"`
+	// Chunks are the storage keys of the received chunks, in order
+	Chunks    []string  `json:"-" gorm:"serializer:json"`
+	Status    string    `json:"status" gorm:"type:varchar(32);not null;index"`
+	JobID     string    `json:"job_id,omitempty" gorm:"type:varchar(255)"`
+	CreatedBy string    `json:"created_by,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// ExpiresAt is when an unfinished upload is discarded
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+// TableName specifies the table name for the Upload model
+func (Upload) TableName() string {
+	return "uploads"
+}
+
+// NewUpload creates an active upload that is discarded after ttl unless it
+// completes first
+func NewUpload(purpose, filename string, size int64, checksum, createdBy string, ttl time.Duration) *Upload {
+	now := time.Now()
+	return &Upload{
+		Purpose:   purpose,
+		Filename:  filename,
+		Size:      size,
+		Checksum:  checksum,
+		Status:    UploadActive,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
+
+// Append records a chunk of n bytes stored under key
+func (u *Upload) Append(key string, n int64) {
+	u.Chunks = append(u.Chunks, key)
+	u.Offset += n
+	u.UpdatedAt = time.Now()
+}
+
+// IsComplete reports whether every announced byte was received
+func (u *Upload) IsComplete() bool {
+	return u.Offset == u.Size
+}
+
+// Complete records the job processing the upload
+func (u *Upload) Complete(jobID string) {
+	u.Status = UploadCompleted
+	u.JobID = jobID
+	u.UpdatedAt = time.Now()
+}
+
+// Discard marks the upload as aborted or expired and forgets its chunks
+func (u *Upload) Discard(status string) {
+	u.Status = status
+	u.Chunks = nil
+	u.UpdatedAt = time.Now()
+}
@@ -0,0 +1,38 @@
+package entities
+
+import "time"
+
+// UsageRecord counts the API calls made to a route on a day with one key on
+// behalf of one tenant. KeyID is empty for calls made without an API key and
+// TenantID for calls made outside an organization.
+type UsageRecord struct {
+	// Day is the UTC day of the calls, at midnight
+	Day      time.Time `json:"day" gorm:"primaryKey;type:date"`
+	KeyID    string    `json:"key_id" gorm:"primaryKey;type:varchar(64)"`
+	TenantID string    `json:"tenant_id" gorm:"primaryKey;type:varchar(64)"`
+	Method   string    `json:"method" gorm:"primaryKey;type:varchar(16)"`
+	// Route is the route pattern the call matched, such as
+	// /api/v1/users/{id}, or unmatched
+	Route    string `json:"route" gorm:"primaryKey;type:varchar(255)"`
+	Requests int64  `json:"requests" gorm:"not null"`
+	// BytesIn and BytesOut are the request and response body bytes
+	BytesIn  int64 `json:"bytes_in" gorm:"not null"`
+	BytesOut int64 `json:"bytes_out" gorm:"not null"`
+}
+
+// TableName specifies the table name for the UsageRecord model
+func (UsageRecord) TableName() string {
+	return "usage_daily"
+}
+
+// UsageDay returns the day t is counted on
+func UsageDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// Add adds the counts of other to the record
+func (u *UsageRecord) Add(other *UsageRecord) {
+	u.Requests += other.Requests
+	u.BytesIn += other.BytesIn
+	u.BytesOut += other.BytesOut
+}
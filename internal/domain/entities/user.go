@@ -6,14 +6,61 @@ import (
 	"gorm.io/gorm"
 )
 
-// User represents a user entity in the domain
+// User kinds
+const (
+	// UserKindHuman is a person signing in
+	UserKindHuman = "human"
+	// UserKindService is a service account another system acts as through
+	// the API keys of its organization
+	UserKindService = "service"
+)
+
+// User represents a user entity in the domain. Emails are unique among users
+// that are not deleted, so the email of a deleted user can be registered again.
 type User struct {
 	ID        string         `json:"id" gorm:"primaryKey;type:varchar(255)"`
-	Email     string         `json:"email" gorm:"uniqueIndex;type:varchar(255);not null"`
+	Email     string         `json:"email" gorm:"uniqueIndex:idx_users_email_active,where:deleted_at IS NULL;type:varchar(255);not null"`
 	Name      string         `json:"name" gorm:"type:varchar(255);not null"`
 	CreatedAt time.Time      `json:"created_at" gorm:"not null"`
 	UpdatedAt time.Time      `json:"updated_at" gorm:"not null"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+
+	// Kind is UserKindHuman or, for service accounts, UserKindService.
+	// Service accounts belong to OrganizationID and may be granted at most
+	// Scopes through their API keys.
+	Kind           string   `json:"kind" gorm:"type:varchar(16);not null;default:'human';index"`
+	OrganizationID string   `json:"organization_id,omitempty" gorm:"type:varchar(255);index"`
+	Scopes         []string `json:"scopes,omitempty" gorm:"serializer:json"`
+
+	// PasswordHash is set for users who signed up with a password. It is
+	// never serialized, so it cannot leak through responses or events.
+	PasswordHash string `json:"-" gorm:"type:varchar(255);not null;default:''"`
+	// EmailUnverified is set for users who signed up until they follow the
+	// link sent to their email; until then they cannot log in
+	EmailUnverified bool `json:"-" gorm:"not null;default:false"`
+
+	// MFASecret is the user's encrypted TOTP secret, set once they started
+	// enrolling. MFAEnabled is set once they confirmed it with a code, from
+	// when password logins require one.
+	MFASecret  string `json:"-" gorm:"type:text;not null;default:''"`
+	MFAEnabled bool   `json:"-" gorm:"not null;default:false"`
+	// MFALastStep is the TOTP time step of the last code accepted, so a
+	// code cannot be used twice
+	MFALastStep int64 `json:"-" gorm:"not null;default:0"`
+
+	// Preferences localize the user's API responses
+	Preferences UserPreferences `json:"preferences" gorm:"embedded"`
+}
+
+// UserPreferences are the locale and time zone a user's API responses are
+// localized with when a request does not name them itself
+type UserPreferences struct {
+	// Locale is a BCP 47 language tag such as de or en-GB; empty follows
+	// the Accept-Language header of each request
+	Locale string `json:"locale" gorm:"type:varchar(35);not null;default:''"`
+	// TimeZone is an IANA time zone such as Europe/Berlin; empty leaves
+	// times in UTC
+	TimeZone string `json:"time_zone" gorm:"type:varchar(64);not null;default:''"`
 }
 
 // TableName specifies the table name for the User model
@@ -27,19 +74,88 @@ func NewUser(email, name string) *User {
 	return &User{
 		Email:     email,
 		Name:      name,
+		Kind:      UserKindHuman,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 }
 
+// NewServiceAccount creates a service account of organizationID that may
+// be granted scopes. Service accounts have no password; email only keeps
+// them unique among users.
+func NewServiceAccount(organizationID, email, name string, scopes []string) *User {
+	user := NewUser(email, name)
+	user.Kind = UserKindService
+	user.OrganizationID = organizationID
+	user.Scopes = scopes
+	return user
+}
+
+// IsServiceAccount reports whether the user is a service account
+func (u *User) IsServiceAccount() bool {
+	return u.Kind == UserKindService
+}
+
 // UpdateName updates the user's name
 func (u *User) UpdateName(name string) {
 	u.Name = name
 	u.UpdatedAt = time.Now()
 }
 
+// SetPasswordHash replaces the user's password hash
+func (u *User) SetPasswordHash(hash string) {
+	u.PasswordHash = hash
+	u.UpdatedAt = time.Now()
+}
+
+// HasPassword reports whether the user can log in with a password
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// EmailVerified reports whether the user's email is known to be theirs.
+// Only users who signed up start out unverified.
+func (u *User) EmailVerified() bool {
+	return !u.EmailUnverified
+}
+
+// VerifyEmail records that the user followed the link sent to their email
+func (u *User) VerifyEmail() {
+	u.EmailUnverified = false
+	u.UpdatedAt = time.Now()
+}
+
 // UpdateEmail updates the user's email
 func (u *User) UpdateEmail(email string) {
 	u.Email = email
 	u.UpdatedAt = time.Now()
 }
+
+// EnrollMFA stores the encrypted TOTP secret the user is to confirm,
+// replacing any earlier one they did not confirm
+func (u *User) EnrollMFA(secret string) {
+	u.MFASecret = secret
+	u.MFAEnabled = false
+	u.MFALastStep = 0
+	u.UpdatedAt = time.Now()
+}
+
+// EnableMFA requires a one-time code on the user's password logins
+func (u *User) EnableMFA() {
+	u.MFAEnabled = true
+	u.UpdatedAt = time.Now()
+}
+
+// DisableMFA removes the user's TOTP secret
+func (u *User) DisableMFA() {
+	u.MFASecret = ""
+	u.MFAEnabled = false
+	u.MFALastStep = 0
+	u.UpdatedAt = time.Now()
+}
+
+// MFAPending reports whether the user started enrolling but has not
+// confirmed a code yet
+func (u *User) MFAPending() bool {
+	return u.MFASecret != "" && !u.MFAEnabled
+}
@@ -0,0 +1,65 @@
+package entities
+
+import "time"
+
+// Deletion request statuses
+const (
+	UserDeletionPending   = "pending"
+	UserDeletionCancelled = "cancelled"
+	UserDeletionCompleted = "completed"
+)
+
+// UserDeletion is a request to delete a user once a grace period has passed.
+// Until then the user or an admin may cancel it.
+type UserDeletion struct {
+	ID     string `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	UserID string `json:"user_id" gorm:"type:varchar(255);not null;index"`
+	// CancelTokenHash is the SHA-256 of the token sent in the cancellation
+	// link; the token itself is never stored
+	CancelTokenHash string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	RequestedAt     time.Time  `json:"requested_at" gorm:"not null"`
+	PurgeAfter      time.Time  `json:"purge_after" gorm:"not null;index"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty"`
+	CancelledBy     string     `json:"cancelled_by,omitempty" gorm:"type:varchar(255)"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName specifies the table name for the UserDeletion model
+func (UserDeletion) TableName() string {
+	return "user_deletions"
+}
+
+// NewUserDeletion creates a deletion request for a user that purges after
+// the grace period
+func NewUserDeletion(userID, cancelTokenHash string, gracePeriod time.Duration) *UserDeletion {
+	now := time.Now()
+	return &UserDeletion{
+		UserID:          userID,
+		CancelTokenHash: cancelTokenHash,
+		RequestedAt:     now,
+		PurgeAfter:      now.Add(gracePeriod),
+	}
+}
+
+// Status returns whether the request is pending, cancelled or completed
+func (d *UserDeletion) Status() string {
+	switch {
+	case d.CompletedAt != nil:
+		return UserDeletionCompleted
+	case d.CancelledAt != nil:
+		return UserDeletionCancelled
+	default:
+		return UserDeletionPending
+	}
+}
+
+// Cancel aborts the request, recording who cancelled it
+func (d *UserDeletion) Cancel(by string, at time.Time) {
+	d.CancelledAt = &at
+	d.CancelledBy = by
+}
+
+// Complete records that the user was purged
+func (d *UserDeletion) Complete(at time.Time) {
+	d.CompletedAt = &at
+}
@@ -0,0 +1,34 @@
+package entities
+
+import "time"
+
+// UserMergeCancelledBy is who the pending deletion of a merged away user
+// is recorded as cancelled by
+const UserMergeCancelledBy = "merge"
+
+// UserMerge records two accounts of the same person being merged: what the
+// loser owned was moved to the winner and the loser was deleted. The
+// counts say how many records the merge changed.
+type UserMerge struct {
+	WinnerID string    `json:"winner_id"`
+	LoserID  string    `json:"loser_id"`
+	MergedAt time.Time `json:"merged_at"`
+	// SavedViews is the number of the loser's saved views the winner now
+	// owns
+	SavedViews int64 `json:"saved_views"`
+	// CancelledDeletions is the number of the loser's pending deletion
+	// requests cancelled, as the loser is deleted right away
+	CancelledDeletions int64 `json:"cancelled_deletions"`
+	// SupersededEmailChanges is the number of the loser's email changes
+	// that can no longer be confirmed
+	SupersededEmailChanges int64 `json:"superseded_email_changes"`
+}
+
+// NewUserMerge creates the merge of loserID into winnerID
+func NewUserMerge(winnerID, loserID string) *UserMerge {
+	return &UserMerge{
+		WinnerID: winnerID,
+		LoserID:  loserID,
+		MergedAt: time.Now(),
+	}
+}
@@ -0,0 +1,134 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is a URL registered by an admin that the domain events it
+// subscribes to are posted to
+type Webhook struct {
+	ID  string `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	URL string `json:"url" gorm:"type:varchar(2048);not null"`
+	// Secret is shared with the receiver to authenticate deliveries. It is
+	// only shown when the webhook is created.
+	Secret string `json:"-" gorm:"type:varchar(255);not null"`
+	// Events are the event types posted; empty subscribes to every event
+	Events []string `json:"events" gorm:"serializer:json"`
+	// Active webhooks receive new events; inactive ones keep their
+	// deliveries for inspection
+	Active    bool      `json:"active" gorm:"not null;default:true"`
+	CreatedBy string    `json:"created_by,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+}
+
+// TableName specifies the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// NewWebhook creates an active webhook created by createdBy
+func NewWebhook(url, secret string, events []string, createdBy string) *Webhook {
+	now := time.Now()
+	return &Webhook{
+		URL:       url,
+		Secret:    secret,
+		Events:    events,
+		Active:    true,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Subscribes reports whether events of eventType are posted to the webhook
+func (w *Webhook) Subscribes(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range w.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is a domain event posted, or still to be posted, to a
+// webhook, with the log of its attempts
+type WebhookDelivery struct {
+	ID        string `json:"id" gorm:"primaryKey;type:varchar(255)"`
+	WebhookID string `json:"webhook_id" gorm:"type:varchar(255);not null;uniqueIndex:idx_webhook_deliveries_event,priority:1;index"`
+	EventID   string `json:"event_id" gorm:"type:varchar(64);not null;uniqueIndex:idx_webhook_deliveries_event,priority:2"`
+	EventType string `json:"event_type" gorm:"type:varchar(100);not null"`
+	// Payload is the body posted, the event as JSON
+	Payload json.RawMessage `json:"payload" gorm:"serializer:json"`
+	Status  string          `json:"status" gorm:"type:varchar(32);not null;index"`
+	// Attempts logs every attempt made, oldest first
+	Attempts []WebhookAttempt `json:"attempts" gorm:"serializer:json"`
+	// NextAttemptAt is when a pending delivery is attempted next
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"not null"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+}
+
+// TableName specifies the table name for the WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookAttempt is one attempt of a delivery
+type WebhookAttempt struct {
+	At time.Time `json:"at"`
+	// StatusCode is the status the receiver answered with; 0 when no
+	// response was received
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Succeeded reports whether the receiver accepted the attempt
+func (a WebhookAttempt) Succeeded() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode <= 299
+}
+
+// NewWebhookDelivery creates a pending delivery of the event eventID to
+// webhookID, attempted right away
+func NewWebhookDelivery(webhookID, eventID, eventType string, payload json.RawMessage) *WebhookDelivery {
+	now := time.Now()
+	return &WebhookDelivery{
+		WebhookID:     webhookID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}
+}
+
+// Record logs attempt. Successful attempts complete the delivery; failed
+// ones are retried at next, or fail the delivery when next is nil.
+func (d *WebhookDelivery) Record(attempt WebhookAttempt, next *time.Time) {
+	d.Attempts = append(d.Attempts, attempt)
+	switch {
+	case attempt.Succeeded():
+		d.Status = WebhookDeliverySucceeded
+		d.DeliveredAt = &attempt.At
+		d.NextAttemptAt = nil
+	case next == nil:
+		d.Status = WebhookDeliveryFailed
+		d.NextAttemptAt = nil
+	default:
+		d.Status = WebhookDeliveryPending
+		d.NextAttemptAt = next
+	}
+}
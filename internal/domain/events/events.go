@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Event types emitted by the user use cases
+const (
+	UserCreated = "user.created"
+	UserUpdated = "user.updated"
+	UserDeleted = "user.deleted"
+
+	UserDeletionScheduled = "user.deletion_scheduled"
+	UserDeletionCancelled = "user.deletion_cancelled"
+
+	UserEmailChangeRequested = "user.email_change_requested"
+
+	UserMerged = "user.merged"
+)
+
+// Types lists every event type, for subscribers such as the audit log that
+// record them all
+var Types = []string{
+	UserCreated,
+	UserUpdated,
+	UserDeleted,
+	UserDeletionScheduled,
+	UserDeletionCancelled,
+	UserEmailChangeRequested,
+	UserMerged,
+}
+
+// UserChanges lists the event types of users being created, updated or
+// deleted, for subscribers mirroring users such as event streams
+var UserChanges = []string{
+	UserCreated,
+	UserUpdated,
+	UserDeleted,
+}
+
+// Event is a domain event describing something that happened to an aggregate
+type Event struct {
+	ID          string      `json:"id"`
+	Type        string      `json:"type"`
+	AggregateID string      `json:"aggregate_id"`
+	OccurredAt  time.Time   `json:"occurred_at"`
+	Data        interface{} `json:"data,omitempty"`
+
+	// CorrelationID links the event to the API call that caused it
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// NewEvent creates a new event of the given type for an aggregate
+func NewEvent(eventType, aggregateID string, data interface{}) Event {
+	return Event{
+		ID:          newEventID(),
+		Type:        eventType,
+		AggregateID: aggregateID,
+		OccurredAt:  time.Now(),
+		Data:        data,
+	}
+}
+
+// Publisher publishes domain events to interested subscribers
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// newEventID generates a random event identifier
+func newEventID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "evt_" + time.Now().Format("20060102150405.000000")
+	}
+	return "evt_" + hex.EncodeToString(randBytes)
+}
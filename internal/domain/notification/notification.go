@@ -0,0 +1,31 @@
+// Package notification defines the channels notifications about domain
+// events are delivered through
+package notification
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// Notification tells a channel about a domain event
+type Notification struct {
+	EventID       string    `json:"event_id"`
+	Type          string    `json:"type"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	UserID        string    `json:"user_id"`
+	// User is the user the event is about as of the event, nil when the
+	// event did not carry them
+	User *entities.User `json:"user,omitempty"`
+}
+
+// Channel delivers notifications, e.g. by email or to a webhook
+type Channel interface {
+	// Name identifies the channel in configuration and logs
+	Name() string
+	// Notify delivers n. Failed notifications are retried, so channels
+	// may see one more than once and can tell by its EventID.
+	Notify(ctx context.Context, n Notification) error
+}
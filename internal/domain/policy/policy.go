@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"context"
+
+	"clean-architecture/pkg/ctxkeys"
+)
+
+// RoleAdmin is the role policy engines grant every action
+const RoleAdmin = "admin"
+
+// RoleUser is the role of ordinary users, such as those who signed up
+const RoleUser = "user"
+
+// RoleService is the role of API keys that may change users
+const RoleService = "service"
+
+// Subject represents the caller an authorization decision is made for
+type Subject struct {
+	ID    string   `json:"id"`
+	Roles []string `json:"roles,omitempty"`
+	// Scopes limit what the credential used for the request may do
+	Scopes     []string               `json:"scopes,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// HasRole reports whether the subject has the given role
+func (s Subject) HasRole(role string) bool {
+	for _, r := range s.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Resource represents the object an action is performed on
+type Resource struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	OwnerID    string                 `json:"owner_id,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// RequestInfo holds request attributes that policies may reason about
+type RequestInfo struct {
+	Method   string            `json:"method"`
+	Path     string            `json:"path"`
+	RemoteIP string            `json:"remote_ip,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// Input is the full set of attributes an authorization decision is based on
+type Input struct {
+	Subject  Subject     `json:"subject"`
+	Action   string      `json:"action"`
+	Resource Resource    `json:"resource"`
+	Request  RequestInfo `json:"request"`
+}
+
+// Decision is the outcome of a policy evaluation
+type Decision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Engine defines the interface for policy decision points
+type Engine interface {
+	Evaluate(ctx context.Context, input Input) (Decision, error)
+}
+
+// subjectKey stores the authenticated subject in a request context
+var subjectKey = ctxkeys.NewKey[Subject]("policy_subject")
+
+// WithSubject returns a copy of ctx carrying the given subject
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return subjectKey.With(ctx, subject)
+}
+
+// SubjectFromContext returns the subject stored in ctx, if any
+func SubjectFromContext(ctx context.Context) (Subject, bool) {
+	return subjectKey.From(ctx)
+}
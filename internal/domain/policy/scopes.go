@@ -0,0 +1,37 @@
+package policy
+
+// OAuth-style scopes granted to access tokens and API keys
+const (
+	ScopeUsersRead  = "users:read"
+	ScopeUsersWrite = "users:write"
+	// ScopeAdmin grants every other scope
+	ScopeAdmin = "admin"
+)
+
+// Scopes describes every scope a credential may be granted
+var Scopes = map[string]string{
+	ScopeUsersRead:  "Read user profiles",
+	ScopeUsersWrite: "Create, update and delete users",
+	ScopeAdmin:      "Full access to every API operation",
+}
+
+// impliedScopes lists the scopes each scope grants in addition to itself
+var impliedScopes = map[string][]string{
+	ScopeUsersWrite: {ScopeUsersRead},
+}
+
+// HasScope reports whether the subject was granted scope, directly or
+// through a broader scope
+func (s Subject) HasScope(scope string) bool {
+	for _, granted := range s.Scopes {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+		for _, implied := range impliedScopes[granted] {
+			if implied == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
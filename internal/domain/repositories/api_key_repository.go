@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// APIKeyRepository defines the interface for service API keys
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entities.APIKey) error
+	GetByID(ctx context.Context, id string) (*entities.APIKey, error)
+	// GetByPrefix returns the key with the given public prefix, including
+	// revoked and expired keys
+	GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error)
+	// List returns keys, newest first
+	List(ctx context.Context, limit, offset int) ([]*entities.APIKey, error)
+	// ListByOwner returns the keys of the service account with ownerID,
+	// newest first
+	ListByOwner(ctx context.Context, ownerID string) ([]*entities.APIKey, error)
+	Update(ctx context.Context, key *entities.APIKey) error
+	// TouchLastUsed records that a key authenticated a request at at
+	// without overwriting concurrent changes such as a revocation
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// AuditFilter narrows an audit log listing. Empty fields match all.
+type AuditFilter struct {
+	Type          string
+	AggregateID   string
+	CorrelationID string
+	Limit         int
+	Offset        int
+}
+
+// AuditRepository defines the interface for the audit log, its anchors and
+// the checkpoints of the sinks it is shipped to
+type AuditRepository interface {
+	// Append chains entry after the last entry with entry.Chain and
+	// records it. Appends are serialized so the chain never forks. Entries
+	// of an event already recorded are ignored.
+	Append(ctx context.Context, entry *entities.AuditEntry) error
+	// GetHead returns the last entry, or nil when there is none
+	GetHead(ctx context.Context) (*entities.AuditEntry, error)
+	// ListAfter returns up to limit entries after seq recorded before
+	// recordedBefore, by Seq
+	ListAfter(ctx context.Context, seq int64, recordedBefore time.Time, limit int) ([]*entities.AuditEntry, error)
+	// List returns the entries matching filter, newest first
+	List(ctx context.Context, filter AuditFilter) ([]*entities.AuditEntry, error)
+	// SaveAnchor records an anchor
+	SaveAnchor(ctx context.Context, anchor *entities.AuditAnchor) error
+	// ListAnchors returns every anchor, by Seq
+	ListAnchors(ctx context.Context) ([]*entities.AuditAnchor, error)
+	// GetCheckpoint returns the Seq of the last entry shipped to sink, or
+	// 0 when none was
+	GetCheckpoint(ctx context.Context, sink string) (int64, error)
+	// SaveCheckpoint creates or replaces the checkpoint of
+	// checkpoint.Sink
+	SaveCheckpoint(ctx context.Context, checkpoint *entities.AuditCheckpoint) error
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// DeadLetterFilter narrows a dead letter listing. Empty fields match all.
+type DeadLetterFilter struct {
+	Kind          string
+	Topic         string
+	ConsumerGroup string
+	CorrelationID string
+	// Pending restricts the listing to dead letters never replayed
+	Pending bool
+	Limit   int
+	Offset  int
+}
+
+// DeadLetterRepository defines the interface for dead letter storage
+type DeadLetterRepository interface {
+	Create(ctx context.Context, deadLetter *entities.DeadLetter) error
+	GetByID(ctx context.Context, id string) (*entities.DeadLetter, error)
+	List(ctx context.Context, filter DeadLetterFilter) ([]*entities.DeadLetter, error)
+	Update(ctx context.Context, deadLetter *entities.DeadLetter) error
+}
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// EmailChangeRepository defines the interface for email changes awaiting
+// confirmation
+type EmailChangeRepository interface {
+	Create(ctx context.Context, change *entities.EmailChange) error
+	// GetPendingByUserID and GetPendingByTokenHash only return requests that
+	// were neither confirmed nor superseded; they may have expired
+	GetPendingByUserID(ctx context.Context, userID string) (*entities.EmailChange, error)
+	GetPendingByTokenHash(ctx context.Context, tokenHash string) (*entities.EmailChange, error)
+	Update(ctx context.Context, change *entities.EmailChange) error
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// EmailSuppressionRepository defines the interface for the addresses no
+// email is sent to. Addresses are passed normalized, see
+// entities.NormalizeEmailAddress.
+type EmailSuppressionRepository interface {
+	// Save creates or replaces the suppression of suppression.Email,
+	// keeping when it was first created
+	Save(ctx context.Context, suppression *entities.EmailSuppression) error
+	// Get returns the suppression of email, or
+	// ErrEmailSuppressionNotFound when it is not suppressed
+	Get(ctx context.Context, email string) (*entities.EmailSuppression, error)
+	// GetMany returns the suppressions of those of emails that are
+	// suppressed, keyed by address
+	GetMany(ctx context.Context, emails []string) (map[string]*entities.EmailSuppression, error)
+	// List returns suppressions, most recently updated first
+	List(ctx context.Context, limit, offset int) ([]*entities.EmailSuppression, error)
+	// Delete lifts the suppression of email, returning
+	// ErrEmailSuppressionNotFound when it is not suppressed
+	Delete(ctx context.Context, email string) error
+}
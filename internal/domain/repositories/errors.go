@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"fmt"
+
+	"clean-architecture/pkg/apperrors"
+)
+
+var (
+	// ErrUserNotFound is returned when a user does not exist
+	ErrUserNotFound = apperrors.NotFound("user_not_found", "user not found")
+	// ErrUserAlreadyExists is returned when a user with the same email exists
+	ErrUserAlreadyExists = apperrors.Conflict("user_already_exists", "user with this email already exists")
+	// ErrDeadLetterNotFound is returned when a dead letter does not exist
+	ErrDeadLetterNotFound = apperrors.NotFound("dead_letter_not_found", "dead letter not found")
+	// ErrPublishedEventNotFound is returned when no published event has
+	// the ID
+	ErrPublishedEventNotFound = apperrors.NotFound("published_event_not_found", "published event not found")
+	// ErrUserDeletionNotFound is returned when no matching deletion request
+	// is pending
+	ErrUserDeletionNotFound = apperrors.NotFound("user_deletion_not_found", "deletion request not found")
+	// ErrJobNotFound is returned when a job does not exist
+	ErrJobNotFound = apperrors.NotFound("job_not_found", "job not found")
+	// ErrUploadNotFound is returned when an upload does not exist
+	ErrUploadNotFound = apperrors.NotFound("upload_not_found", "upload not found")
+	// ErrUploadConflict is returned when an upload changed since it was read
+	ErrUploadConflict = apperrors.Internal("upload_modified_concurrently", "upload was modified concurrently")
+	// ErrSavedViewNotFound is returned when a saved view does not exist or
+	// is not visible to the caller
+	ErrSavedViewNotFound = apperrors.NotFound("saved_view_not_found", "saved view not found")
+	// ErrAPIKeyNotFound is returned when an API key does not exist
+	ErrAPIKeyNotFound = apperrors.NotFound("api_key_not_found", "API key not found")
+	// ErrQuotaNotFound is returned when no quota was set on a resource
+	ErrQuotaNotFound = apperrors.NotFound("quota_not_found", "quota not found")
+	// ErrSubscriptionNotFound is returned when a subscription does not
+	// exist
+	ErrSubscriptionNotFound = apperrors.NotFound("subscription_not_found", "subscription not found")
+	// ErrOrganizationNotFound is returned when an organization does not
+	// exist
+	ErrOrganizationNotFound = apperrors.NotFound("organization_not_found", "organization not found")
+	// ErrEmailChangeNotFound is returned when no matching email change
+	// awaits confirmation
+	ErrEmailChangeNotFound = apperrors.NotFound("email_change_not_found", "email change not found")
+	// ErrFeatureFlagNotFound is returned when no state was set on a
+	// feature flag
+	ErrFeatureFlagNotFound = apperrors.NotFound("feature_flag_not_found", "feature flag not found")
+	// ErrWebhookNotFound is returned when a webhook does not exist
+	ErrWebhookNotFound = apperrors.NotFound("webhook_not_found", "webhook not found")
+	// ErrEmailSuppressionNotFound is returned when an address is not
+	// suppressed
+	ErrEmailSuppressionNotFound = apperrors.NotFound("email_suppression_not_found", "email suppression not found")
+	// ErrQueryBudgetExceeded is returned, wrapped in a QueryBudgetError,
+	// for statements stopped because they exceeded the budget of their
+	// operation class
+	ErrQueryBudgetExceeded = apperrors.Validation("query_budget_exceeded", "query exceeded its budget; narrow the filters or request fewer results")
+)
+
+// Operation classes statements are budgeted by
+const (
+	// OperationRead is a query returning at most one row, such as a lookup
+	// by ID or a count
+	OperationRead = "read"
+	// OperationList is a query returning many rows
+	OperationList = "list"
+	// OperationWrite is an insert, update, delete or other statement
+	OperationWrite = "write"
+)
+
+// QueryBudgetError describes a statement stopped by its query budget
+type QueryBudgetError struct {
+	Operation string
+	Table     string
+	// Reason names the exceeded cap, such as "ran longer than 5s"
+	Reason string
+}
+
+func (e *QueryBudgetError) Error() string {
+	return fmt.Sprintf("%s query on %s %s", e.Operation, e.Table, e.Reason)
+}
+
+// Unwrap lets errors.Is match ErrQueryBudgetExceeded
+func (e *QueryBudgetError) Unwrap() error {
+	return ErrQueryBudgetExceeded
+}
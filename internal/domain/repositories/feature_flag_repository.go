@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// FeatureFlagRepository defines the interface for the feature flag states
+// administrators set
+type FeatureFlagRepository interface {
+	// Get returns the state set on the flag key, or
+	// ErrFeatureFlagNotFound when none was
+	Get(ctx context.Context, key string) (*entities.FeatureFlag, error)
+	// List returns every flag state that was set, by key
+	List(ctx context.Context) ([]*entities.FeatureFlag, error)
+	// Save creates or replaces the state of flag.Key
+	Save(ctx context.Context, flag *entities.FeatureFlag) error
+}
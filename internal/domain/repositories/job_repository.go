@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// JobRepository defines the interface for background job records
+type JobRepository interface {
+	Create(ctx context.Context, job *entities.Job) error
+	GetByID(ctx context.Context, id string) (*entities.Job, error)
+	Update(ctx context.Context, job *entities.Job) error
+	// ListExpired returns succeeded jobs of jobType whose results expired
+	// before now, oldest first
+	ListExpired(ctx context.Context, jobType string, now time.Time, limit int) ([]*entities.Job, error)
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// OrganizationRepository defines the interface for organizations
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *entities.Organization) error
+	GetByID(ctx context.Context, id string) (*entities.Organization, error)
+	// List returns organizations, oldest first
+	List(ctx context.Context, limit, offset int) ([]*entities.Organization, error)
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// PublishedEventFilter narrows a published event listing. Empty fields
+// match all.
+type PublishedEventFilter struct {
+	Type          string
+	AggregateID   string
+	CorrelationID string
+	// Since and Until bound PublishedAt, Since included
+	Since time.Time
+	Until time.Time
+	// Failed restricts the listing to events the broker refused
+	Failed bool
+	Limit  int
+	Offset int
+}
+
+// Empty reports whether the filter matches every event
+func (f PublishedEventFilter) Empty() bool {
+	return f.Type == "" && f.AggregateID == "" && f.CorrelationID == "" && f.Since.IsZero() && f.Until.IsZero() && !f.Failed
+}
+
+// PublishedEventRepository defines the interface for the log of published
+// domain events
+type PublishedEventRepository interface {
+	Create(ctx context.Context, event *entities.PublishedEvent) error
+	GetByID(ctx context.Context, id string) (*entities.PublishedEvent, error)
+	// List returns the events matching filter in the order they were
+	// published, so replaying them keeps it
+	List(ctx context.Context, filter PublishedEventFilter) ([]*entities.PublishedEvent, error)
+	Update(ctx context.Context, event *entities.PublishedEvent) error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// QuotaRepository defines the interface for the quotas administrators set
+type QuotaRepository interface {
+	// Get returns the quota set on resource, or ErrQuotaNotFound when none
+	// was
+	Get(ctx context.Context, resource string) (*entities.Quota, error)
+	// Save creates or replaces the quota on quota.Resource
+	Save(ctx context.Context, quota *entities.Quota) error
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// SavedViewRepository defines the interface for saved list views
+type SavedViewRepository interface {
+	Create(ctx context.Context, view *entities.SavedView) error
+	GetByID(ctx context.Context, id string) (*entities.SavedView, error)
+	// ListVisible returns the views owned by ownerID and every shared view,
+	// by name
+	ListVisible(ctx context.Context, ownerID string) ([]*entities.SavedView, error)
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,41 @@
+package repositories
+
+// Operator compares a field with a condition's value
+type Operator string
+
+// Operators a specification condition may use
+const (
+	OpEq         Operator = "eq"
+	OpNe         Operator = "ne"
+	OpContains   Operator = "contains"
+	OpStartsWith Operator = "starts_with"
+	OpGt         Operator = "gt"
+	OpGte        Operator = "gte"
+	OpLt         Operator = "lt"
+	OpLte        Operator = "lte"
+	OpIn         Operator = "in"
+)
+
+// Condition restricts a field of an entity. Value is a string or a
+// time.Time, or a []string for OpIn; contains and starts_with only apply
+// to strings and match case-insensitively.
+type Condition struct {
+	Field    string
+	Operator Operator
+	Value    interface{}
+}
+
+// SortOrder orders results by a field
+type SortOrder struct {
+	Field      string
+	Descending bool
+}
+
+// Specification selects the entities matching every condition, ordered by
+// Sort and paginated by Limit and Offset
+type Specification struct {
+	Conditions []Condition
+	Sort       []SortOrder
+	Limit      int
+	Offset     int
+}
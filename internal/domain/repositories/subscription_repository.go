@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// SubscriptionRepository defines the interface for billing subscriptions
+type SubscriptionRepository interface {
+	GetByID(ctx context.Context, id string) (*entities.Subscription, error)
+	// Save creates or replaces the subscription with subscription.ID
+	Save(ctx context.Context, subscription *entities.Subscription) error
+	// Current returns the most recently updated subscription that grants
+	// its plan, or ErrSubscriptionNotFound when none does
+	Current(ctx context.Context) (*entities.Subscription, error)
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// UploadRepository defines the interface for chunked uploads
+type UploadRepository interface {
+	Create(ctx context.Context, upload *entities.Upload) error
+	GetByID(ctx context.Context, id string) (*entities.Upload, error)
+	Update(ctx context.Context, upload *entities.Upload) error
+	// Advance saves an upload that received a chunk, provided it is still
+	// active at fromOffset. It returns ErrUploadConflict otherwise, so
+	// concurrent chunks for the same offset cannot both be accepted.
+	Advance(ctx context.Context, upload *entities.Upload, fromOffset int64) error
+	// ListExpired returns active uploads whose expiry passed before now
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*entities.Upload, error)
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// UsageFilter selects the usage records of a report
+type UsageFilter struct {
+	// From and To are the first and last day of the report
+	From time.Time
+	To   time.Time
+	// KeyID and TenantID, when set, keep the usage of one key or tenant
+	KeyID    string
+	TenantID string
+}
+
+// UsageRepository defines the interface for the daily rollups of API usage
+type UsageRepository interface {
+	// Add adds the counts of records to those stored for the same day,
+	// key, tenant, method and route. Records must not repeat one.
+	Add(ctx context.Context, records []*entities.UsageRecord) error
+	// List returns the records filter selects, by day, key, tenant, route
+	// and method
+	List(ctx context.Context, filter UsageFilter) ([]*entities.UsageRecord, error)
+}
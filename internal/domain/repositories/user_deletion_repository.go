@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// UserDeletionRepository defines the interface for scheduled user deletions
+type UserDeletionRepository interface {
+	Create(ctx context.Context, deletion *entities.UserDeletion) error
+	GetByID(ctx context.Context, id string) (*entities.UserDeletion, error)
+	// GetPendingByUserID and GetPendingByTokenHash only return requests that
+	// were neither cancelled nor completed
+	GetPendingByUserID(ctx context.Context, userID string) (*entities.UserDeletion, error)
+	GetPendingByTokenHash(ctx context.Context, tokenHash string) (*entities.UserDeletion, error)
+	// ListPendingByUserIDs returns the pending requests of any of the users
+	// in one query
+	ListPendingByUserIDs(ctx context.Context, userIDs []string) ([]*entities.UserDeletion, error)
+	// ListPending returns pending requests, soonest purge first
+	ListPending(ctx context.Context, limit, offset int) ([]*entities.UserDeletion, error)
+	// ListDue returns pending requests whose grace period ended before now
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*entities.UserDeletion, error)
+	Update(ctx context.Context, deletion *entities.UserDeletion) error
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// UserMergeRepository defines the interface for merging duplicate users
+type UserMergeRepository interface {
+	// Merge moves the records of merge.LoserID to merge.WinnerID and
+	// soft-deletes the loser in one transaction, counting the records
+	// changed into merge. It returns ErrUserNotFound unless both users
+	// exist and are not deleted.
+	Merge(ctx context.Context, merge *entities.UserMerge) error
+}
@@ -2,16 +2,43 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"clean-architecture/internal/domain/entities"
 )
 
+// UserResource names users in consistency tokens
+const UserResource = "users"
+
+// UserKindField is the field conditions select service accounts with;
+// List, Count, Find and CountMatching leave service accounts out unless a
+// condition is on it
+const UserKindField = "kind"
+
 // UserRepository defines the interface for user data access
 type UserRepository interface {
 	Create(ctx context.Context, user *entities.User) error
 	GetByID(ctx context.Context, id string) (*entities.User, error)
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
 	Update(ctx context.Context, user *entities.User) error
+	// Upsert creates user, or renames the user with the same email, in one
+	// atomic step and fills in the stored ID and timestamps. It reports
+	// whether the user was created.
+	Upsert(ctx context.Context, user *entities.User) (created bool, err error)
 	Delete(ctx context.Context, id string) error
+	// AdvanceMFAStep records step as the user's last accepted TOTP step if
+	// it is later than the recorded one, in one atomic step. It reports
+	// whether it was, so concurrent logins cannot both use a code.
+	AdvanceMFAStep(ctx context.Context, id string, step int64) (bool, error)
 	List(ctx context.Context, limit, offset int) ([]*entities.User, error)
+	// Find returns the users matching every condition of spec
+	Find(ctx context.Context, spec Specification) ([]*entities.User, error)
+	Count(ctx context.Context) (int64, error)
+	// CountMatching returns the number of users matching every condition of
+	// spec, ignoring its sort and pagination
+	CountMatching(ctx context.Context, spec Specification) (int64, error)
+	// PurgeDeleted permanently removes up to limit users deleted before
+	// before, so creating them again no longer restores their ID. It
+	// returns the number removed.
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error)
 }
@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// WebhookRepository defines the interface for webhooks and their
+// deliveries
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *entities.Webhook) error
+	GetByID(ctx context.Context, id string) (*entities.Webhook, error)
+	// List returns every webhook, oldest first
+	List(ctx context.Context) ([]*entities.Webhook, error)
+	Update(ctx context.Context, webhook *entities.Webhook) error
+	// Delete removes a webhook and its deliveries
+	Delete(ctx context.Context, id string) error
+
+	// CreateDelivery stores a new delivery. Deliveries of an event already
+	// delivered to the same webhook are ignored.
+	CreateDelivery(ctx context.Context, delivery *entities.WebhookDelivery) error
+	// ListDueDeliveries returns up to limit pending deliveries whose next
+	// attempt is due at now, the longest due first
+	ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*entities.WebhookDelivery, error)
+	// ListDeliveries returns the deliveries of a webhook, newest first
+	ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*entities.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *entities.WebhookDelivery) error
+}
@@ -0,0 +1,147 @@
+// Package request defines what is known about the caller of an API
+// request, resolved once by middleware so handlers and use cases read it
+// from the context instead of deriving it themselves.
+package request
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/ctxkeys"
+)
+
+// Trace identifies a request in logs, events and traces
+type Trace struct {
+	RequestID     string `json:"request_id,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	// TraceID and SpanID are set when the request is traced with
+	// OpenTelemetry
+	TraceID string `json:"trace_id,omitempty"`
+	SpanID  string `json:"span_id,omitempty"`
+}
+
+// FlagSource resolves the state of every feature flag
+type FlagSource func(ctx context.Context) (map[string]bool, error)
+
+// Assignments are the variants of the A/B experiments the caller is
+// assigned to. Reading a variant records that the caller was exposed to it.
+type Assignments interface {
+	// Variant returns the variant of experiment, or "" when it is unknown
+	Variant(ctx context.Context, experiment string) string
+	// All returns the variant of every experiment by experiment name
+	All(ctx context.Context) map[string]string
+}
+
+// Context is the caller and request a piece of work is done for. The zero
+// value is an anonymous caller with every flag off and in no experiment.
+type Context struct {
+	// UserID is the authenticated subject, empty for anonymous requests
+	UserID string
+	// TenantID is the organization the caller acts for, empty when they
+	// act for none
+	TenantID string
+	Roles    []string
+	Scopes   []string
+	// Locale is the language tag responses and emails are written in
+	Locale string
+	// TimeZone is the zone times in responses are shown in; nil when the
+	// caller named none, which leaves them in UTC
+	TimeZone *time.Location
+	Trace    Trace
+
+	// Flags are resolved on first use, since most requests read none
+	flagCtx    context.Context
+	flagSource FlagSource
+	flagsOnce  sync.Once
+	flags      map[string]bool
+
+	experimentCtx context.Context
+	experiments   Assignments
+}
+
+// WithFlags makes c resolve its feature flags from source, with ctx, the
+// first time one is read
+func (c *Context) WithFlags(ctx context.Context, source FlagSource) *Context {
+	c.flagCtx = ctx
+	c.flagSource = source
+	return c
+}
+
+// WithExperiments makes c read the variants of its experiments from
+// assignments, which record exposures with ctx
+func (c *Context) WithExperiments(ctx context.Context, assignments Assignments) *Context {
+	c.experimentCtx = ctx
+	c.experiments = assignments
+	return c
+}
+
+// Authenticated reports whether the caller is authenticated
+func (c *Context) Authenticated() bool {
+	return c.UserID != ""
+}
+
+// HasRole reports whether the caller has the given role
+func (c *Context) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// Flag reports whether the feature flag key is on for the request. Flags
+// that are unknown, or could not be resolved, are off.
+func (c *Context) Flag(key string) bool {
+	return c.Flags()[key]
+}
+
+// Flags returns the state of every feature flag for the request
+func (c *Context) Flags() map[string]bool {
+	c.flagsOnce.Do(func() {
+		if c.flagSource == nil {
+			return
+		}
+		// Resolution errors are the source's to log; the request goes on
+		// with every flag off
+		c.flags, _ = c.flagSource(c.flagCtx)
+	})
+	return c.flags
+}
+
+// Variant returns the variant of the experiment the caller is assigned to,
+// or "" when the experiment is unknown or the request is in none
+func (c *Context) Variant(experiment string) string {
+	if c.experiments == nil {
+		return ""
+	}
+	return c.experiments.Variant(c.experimentCtx, experiment)
+}
+
+// Experiments returns the variant of every experiment by experiment name
+func (c *Context) Experiments() map[string]string {
+	if c.experiments == nil {
+		return map[string]string{}
+	}
+	return c.experiments.All(c.experimentCtx)
+}
+
+// key stores the request context of a request
+var key = ctxkeys.NewKey[*Context]("request_context")
+
+// With returns a copy of ctx carrying rc
+func With(ctx context.Context, rc *Context) context.Context {
+	return key.With(ctx, rc)
+}
+
+// From returns the request context stored in ctx, if any
+func From(ctx context.Context) (*Context, bool) {
+	rc, ok := key.From(ctx)
+	return rc, ok && rc != nil
+}
+
+// Current returns the request context stored in ctx, or an anonymous one
+// for work done outside a request, such as background jobs
+func Current(ctx context.Context) *Context {
+	if rc, ok := From(ctx); ok {
+		return rc
+	}
+	return &Context{}
+}
@@ -0,0 +1,22 @@
+// Package audit implements the sinks audit entries are shipped to.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// encodeJSONL encodes entries as JSON Lines, one entry per line
+func encodeJSONL(entries []*entities.AuditEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return nil, fmt.Errorf("encode audit entry %d: %w", entry.Seq, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
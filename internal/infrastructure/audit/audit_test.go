@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/pkg/s3"
+)
+
+func testEntries() []*entities.AuditEntry {
+	recorded := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return []*entities.AuditEntry{
+		{Seq: 7, EventID: "evt_7", Type: "user.created", AggregateID: "user_1", RecordedAt: recorded, OccurredAt: recorded, Data: json.RawMessage(`{"email":"jane@example.com"}`)},
+		{Seq: 8, EventID: "evt_8", Type: "user.deleted", AggregateID: "user_1", RecordedAt: recorded, OccurredAt: recorded},
+	}
+}
+
+func TestEncodeJSONL(t *testing.T) {
+	body, err := encodeJSONL(testEntries())
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var entry entities.AuditEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "evt_7", entry.EventID)
+	assert.JSONEq(t, `{"email":"jane@example.com"}`, string(entry.Data))
+}
+
+func TestSyslogSink_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		var messages []string
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(length))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(reader, msg); err != nil {
+				break
+			}
+			messages = append(messages, string(msg))
+		}
+		received <- messages
+	}()
+
+	sink := NewSyslogSink("tcp", listener.Addr().String(), "clean-architecture")
+	require.NoError(t, sink.Ship(context.Background(), testEntries()))
+
+	messages := <-received
+	require.Len(t, messages, 2, "messages are framed by octet counting")
+	assert.True(t, strings.HasPrefix(messages[0], "<86>1 2024-05-01T12:00:00Z "), messages[0])
+	assert.Contains(t, messages[0], " clean-architecture ")
+	assert.Contains(t, messages[0], " user.created - {")
+	assert.Contains(t, messages[1], `"event_id":"evt_8"`)
+}
+
+func TestSyslogSink_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink := NewSyslogSink("udp", conn.LocalAddr().String(), "clean-architecture")
+	require.NoError(t, sink.Ship(context.Background(), testEntries()[:1]))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(buf[:n]), "<86>1 "), "datagrams carry no length prefix")
+}
+
+func TestSyslogSink_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	err = NewSyslogSink("tcp", addr, "clean-architecture").Ship(context.Background(), testEntries())
+	assert.ErrorContains(t, err, "syslog:")
+}
+
+func TestS3Sink(t *testing.T) {
+	var path, contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	client, err := s3.New(s3.Config{
+		Bucket:          "logs",
+		Region:          "us-east-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Endpoint:        server.URL,
+	}, server.Client())
+	require.NoError(t, err)
+
+	sink := NewS3Sink(client, "audit/")
+	require.NoError(t, sink.Ship(context.Background(), testEntries()))
+
+	assert.Equal(t, "/logs/audit/2024/05/01/00000000000000000007-00000000000000000008.jsonl", path)
+	assert.Equal(t, "application/x-ndjson", contentType)
+	assert.Equal(t, 2, strings.Count(string(body), "\n"))
+}
+
+func TestHTTPSink(t *testing.T) {
+	var headers http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, "siem-token", server.Client())
+	require.NoError(t, sink.Ship(context.Background(), testEntries()))
+
+	assert.Equal(t, "Bearer siem-token", headers.Get("Authorization"))
+	assert.Equal(t, "audit-7-8", headers.Get("Idempotency-Key"))
+	assert.Equal(t, "application/x-ndjson", headers.Get("Content-Type"))
+	assert.Equal(t, 2, strings.Count(string(body), "\n"))
+}
+
+func TestHTTPSink_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"), "no token is sent without one configured")
+		http.Error(w, "quota exceeded", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	err := NewHTTPSink(server.URL, "", server.Client()).Ship(context.Background(), testEntries())
+	assert.EqualError(t, err, "http sink: 429 Too Many Requests: quota exceeded")
+}
@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// maxErrorBody bounds how much of an error response is read into the
+// returned error
+const maxErrorBody = 1024
+
+// HTTPSink posts each batch as JSON Lines to a SIEM's HTTP collector. The
+// Idempotency-Key header names the sequence numbers the batch spans, so
+// collectors that honour it can drop a batch shipped again.
+type HTTPSink struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// NewHTTPSink creates a sink posting to url, with token as a bearer token
+// unless it is empty
+func NewHTTPSink(url, token string, httpClient *http.Client) *HTTPSink {
+	return &HTTPSink{url: url, token: token, httpClient: httpClient}
+}
+
+// Name implements usecase.AuditSink
+func (s *HTTPSink) Name() string {
+	return "http"
+}
+
+// Ship implements usecase.AuditSink. Any status but 2xx fails the batch.
+func (s *HTTPSink) Ship(ctx context.Context, entries []*entities.AuditEntry) error {
+	body, err := encodeJSONL(entries)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("http sink: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("audit-%d-%d", entries[0].Seq, entries[len(entries)-1].Seq))
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http sink: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("http sink: %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
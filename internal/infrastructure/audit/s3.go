@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/pkg/s3"
+)
+
+// S3Sink ships each batch as a JSON Lines object. Objects are keyed by the
+// day the batch's first entry was recorded and the sequence numbers it
+// spans, so a batch shipped again after a failed checkpoint replaces its
+// object instead of duplicating it.
+type S3Sink struct {
+	client *s3.Client
+	prefix string
+}
+
+// NewS3Sink creates a sink putting objects with client under prefix
+func NewS3Sink(client *s3.Client, prefix string) *S3Sink {
+	return &S3Sink{client: client, prefix: prefix}
+}
+
+// Name implements usecase.AuditSink
+func (s *S3Sink) Name() string {
+	return "s3"
+}
+
+// Ship implements usecase.AuditSink
+func (s *S3Sink) Ship(ctx context.Context, entries []*entities.AuditEntry) error {
+	body, err := encodeJSONL(entries)
+	if err != nil {
+		return err
+	}
+	return s.client.PutObject(ctx, s.key(entries), body, "application/x-ndjson")
+}
+
+// key returns the key of the object of entries, such as
+// audit/2024/05/01/00000000000000000001-00000000000000000500.jsonl; the
+// padded sequence numbers keep the objects of a day listed in order
+func (s *S3Sink) key(entries []*entities.AuditEntry) string {
+	first, last := entries[0], entries[len(entries)-1]
+	return fmt.Sprintf("%s%s/%020d-%020d.jsonl",
+		s.prefix, first.RecordedAt.UTC().Format("2006/01/02"), first.Seq, last.Seq)
+}
@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// syslogPriority is the PRI of the messages: the security/authorization
+// facility (10) at the informational severity (6)
+const syslogPriority = 10*8 + 6
+
+// SyslogSink ships each entry as an RFC 5424 message whose body is the
+// entry as JSON. Over TCP messages are framed by octet counting (RFC 6587);
+// over UDP each is its own datagram.
+type SyslogSink struct {
+	network  string
+	addr     string
+	appName  string
+	hostname string
+	dialer   net.Dialer
+}
+
+// NewSyslogSink creates a sink sending to the syslog server at addr over
+// network, tcp or udp, as appName
+func NewSyslogSink(network, addr, appName string) *SyslogSink {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &SyslogSink{
+		network:  network,
+		addr:     addr,
+		appName:  appName,
+		hostname: hostname,
+		dialer:   net.Dialer{Timeout: 10 * time.Second},
+	}
+}
+
+// Name implements usecase.AuditSink
+func (s *SyslogSink) Name() string {
+	return "syslog"
+}
+
+// Ship implements usecase.AuditSink. A connection is opened for each
+// batch, so a restarted server is reconnected to on the next one.
+func (s *SyslogSink) Ship(ctx context.Context, entries []*entities.AuditEntry) error {
+	conn, err := s.dialer.DialContext(ctx, s.network, s.addr)
+	if err != nil {
+		return fmt.Errorf("syslog: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	for _, entry := range entries {
+		msg, err := s.message(entry)
+		if err != nil {
+			return err
+		}
+		if s.network != "udp" {
+			msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+		}
+		if _, err := conn.Write(msg); err != nil {
+			return fmt.Errorf("syslog: %w", err)
+		}
+	}
+	return nil
+}
+
+// message formats entry as an RFC 5424 message with the event type as
+// MSGID
+func (s *SyslogSink) message(entry *entities.AuditEntry) ([]byte, error) {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("encode audit entry %d: %w", entry.Seq, err)
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s - ",
+		syslogPriority,
+		entry.RecordedAt.UTC().Format(time.RFC3339Nano),
+		s.hostname,
+		s.appName,
+		os.Getpid(),
+		entry.Type,
+	)
+	return append([]byte(header), body...), nil
+}
@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/cache"
+)
+
+// maxMemoryAttempts bounds the counters and locks a MemoryAttemptStore
+// keeps; the least recently used are dropped beyond it
+const maxMemoryAttempts = 100000
+
+// attempts is the state of a key in a MemoryAttemptStore
+type attempts struct {
+	failures int
+	// expires is when the failures are forgotten
+	expires time.Time
+	// lockedUntil is when the lock ends; zero while not locked
+	lockedUntil time.Time
+}
+
+// MemoryAttemptStore counts failed logins in process memory. It is the
+// fallback when Redis is not configured: each instance counts on its own,
+// and counts are lost on restart.
+type MemoryAttemptStore struct {
+	mu      sync.Mutex
+	entries *cache.Cache[string, attempts]
+	now     func() time.Time
+}
+
+// NewMemoryAttemptStore creates an empty in-memory attempt store
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{
+		entries: cache.New[string, attempts](cache.Options{
+			Name:       "login_attempts",
+			MaxEntries: maxMemoryAttempts,
+		}),
+		now: time.Now,
+	}
+}
+
+// Fail records a failed login of key
+func (s *MemoryAttemptStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	entry, _ := s.entries.Get(key)
+	if !now.Before(entry.expires) {
+		entry.failures = 0
+		entry.expires = now.Add(window)
+	}
+	entry.failures++
+	s.store(key, entry, now)
+	return entry.failures, nil
+}
+
+// Lock refuses logins of key until the given time
+func (s *MemoryAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store(key, attempts{lockedUntil: until}, s.now())
+	return nil
+}
+
+// LockedUntil returns when the lock of key ends
+func (s *MemoryAttemptStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, _ := s.entries.Get(key)
+	if !s.now().Before(entry.lockedUntil) {
+		return time.Time{}, nil
+	}
+	return entry.lockedUntil, nil
+}
+
+// Reset forgets the failures and lock of key
+func (s *MemoryAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries.Delete(key)
+	return nil
+}
+
+// store keeps entry until both its failures and its lock expired
+func (s *MemoryAttemptStore) store(key string, entry attempts, now time.Time) {
+	expires := entry.expires
+	if entry.lockedUntil.After(expires) {
+		expires = entry.lockedUntil
+	}
+	if !now.Before(expires) {
+		s.entries.Delete(key)
+		return
+	}
+	s.entries.SetWithTTL(key, entry, expires.Sub(now))
+}
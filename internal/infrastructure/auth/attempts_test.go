@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryAttemptStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryAttemptStore()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	for want := 1; want <= 3; want++ {
+		failures, err := store.Fail(ctx, "user:bjensen", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, want, failures)
+	}
+	failures, err := store.Fail(ctx, "ip:192.0.2.1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures, "keys are counted separately")
+
+	now = now.Add(time.Minute)
+	failures, err = store.Fail(ctx, "user:bjensen", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures, "failures are forgotten after the window")
+
+	until := now.Add(time.Minute)
+	require.NoError(t, store.Lock(ctx, "user:bjensen", until))
+	locked, err := store.LockedUntil(ctx, "user:bjensen")
+	require.NoError(t, err)
+	assert.Equal(t, until, locked)
+	failures, err = store.Fail(ctx, "user:bjensen", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures, "locking forgets the failures")
+	locked, err = store.LockedUntil(ctx, "user:bjensen")
+	require.NoError(t, err)
+	assert.Equal(t, until, locked, "failures while locked keep the lock")
+
+	now = until
+	locked, err = store.LockedUntil(ctx, "user:bjensen")
+	require.NoError(t, err)
+	assert.True(t, locked.IsZero(), "locks end at their time")
+
+	require.NoError(t, store.Lock(ctx, "ip:192.0.2.1", now.Add(time.Minute)))
+	require.NoError(t, store.Reset(ctx, "ip:192.0.2.1"))
+	locked, err = store.LockedUntil(ctx, "ip:192.0.2.1")
+	require.NoError(t, err)
+	assert.True(t, locked.IsZero())
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// cipherVersion prefixes ciphertexts so the format or key can be rotated
+// later without guessing how a stored secret was encrypted
+const cipherVersion = "v1:"
+
+// ErrUndecryptable is returned for ciphertexts that are malformed, were
+// tampered with or were encrypted with another key
+var ErrUndecryptable = errors.New("ciphertext cannot be decrypted")
+
+// AESCipher encrypts secrets with AES-256-GCM
+type AESCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESCipher creates a cipher with a 32 byte key
+func NewAESCipher(key []byte) (*AESCipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESCipher{aead: aead}, nil
+}
+
+// Encrypt implements auth.SecretCipher. Each ciphertext has a random
+// nonce, so encrypting a secret twice gives different results.
+func (c *AESCipher) Encrypt(plaintext, additional []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, plaintext, additional)
+	return cipherVersion + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt implements auth.SecretCipher
+func (c *AESCipher) Decrypt(ciphertext string, additional []byte) ([]byte, error) {
+	encoded, ok := strings.CutPrefix(ciphertext, cipherVersion)
+	if !ok {
+		return nil, ErrUndecryptable
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return nil, ErrUndecryptable
+	}
+	nonce, sealed := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, additional)
+	if err != nil {
+		return nil, ErrUndecryptable
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESCipher(t *testing.T) {
+	c, err := NewAESCipher(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+
+	ciphertext, err := c.Encrypt([]byte("totp secret"), []byte("user_1"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, "v1:"))
+	assert.NotContains(t, ciphertext, "totp secret")
+
+	plaintext, err := c.Decrypt(ciphertext, []byte("user_1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("totp secret"), plaintext)
+
+	again, err := c.Encrypt([]byte("totp secret"), []byte("user_1"))
+	require.NoError(t, err)
+	assert.NotEqual(t, ciphertext, again, "nonces are random")
+}
+
+func TestAESCipher_Refuses(t *testing.T) {
+	c, err := NewAESCipher(bytes.Repeat([]byte{7}, 32))
+	require.NoError(t, err)
+	other, err := NewAESCipher(bytes.Repeat([]byte{8}, 32))
+	require.NoError(t, err)
+	ciphertext, err := c.Encrypt([]byte("totp secret"), []byte("user_1"))
+	require.NoError(t, err)
+
+	_, err = c.Decrypt(ciphertext, []byte("user_2"))
+	assert.ErrorIs(t, err, ErrUndecryptable, "ciphertexts are bound to their user")
+	_, err = other.Decrypt(ciphertext, []byte("user_1"))
+	assert.ErrorIs(t, err, ErrUndecryptable)
+
+	flipped := byte('A')
+	if ciphertext[10] == 'A' {
+		flipped = 'B'
+	}
+	tampered := ciphertext[:10] + string(flipped) + ciphertext[11:]
+	_, err = c.Decrypt(tampered, []byte("user_1"))
+	assert.ErrorIs(t, err, ErrUndecryptable)
+	_, err = c.Decrypt(strings.TrimPrefix(ciphertext, "v1:"), []byte("user_1"))
+	assert.ErrorIs(t, err, ErrUndecryptable)
+	_, err = c.Decrypt("v1:AA", []byte("user_1"))
+	assert.ErrorIs(t, err, ErrUndecryptable)
+}
+
+func TestNewAESCipher_KeySize(t *testing.T) {
+	_, err := NewAESCipher([]byte("short"))
+	assert.Error(t, err)
+}
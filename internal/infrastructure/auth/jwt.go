@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"clean-architecture/internal/domain/auth"
+)
+
+// clockSkew is the leeway given to the exp, iat and nbf claims of tokens
+// verified on another instance
+const clockSkew = 30 * time.Second
+
+// jwtClaims is the JSON payload of an access token. Scopes are a single
+// space separated claim, as in OAuth 2.0.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+	Scope string   `json:"scope,omitempty"`
+}
+
+// JWTService implements auth.TokenService with HS256 signed JSON Web
+// Tokens
+type JWTService struct {
+	secret []byte
+	issuer string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewJWTService creates a token service signing with secret. Tokens are
+// issued by issuer and expire after ttl.
+func NewJWTService(secret, issuer string, ttl time.Duration) *JWTService {
+	return &JWTService{
+		secret: []byte(secret),
+		issuer: issuer,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+// Issue implements auth.TokenService
+func (s *JWTService) Issue(ctx context.Context, claims auth.Claims) (string, auth.Claims, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", auth.Claims{}, fmt.Errorf("generating token ID: %w", err)
+	}
+	now := s.now().UTC().Truncate(time.Second)
+	claims.ID = hex.EncodeToString(id)
+	claims.IssuedAt = now
+	claims.ExpiresAt = now.Add(s.ttl)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        claims.ID,
+			Issuer:    s.issuer,
+			Subject:   claims.Subject,
+			IssuedAt:  jwt.NewNumericDate(claims.IssuedAt),
+			NotBefore: jwt.NewNumericDate(claims.IssuedAt),
+			ExpiresAt: jwt.NewNumericDate(claims.ExpiresAt),
+		},
+		Roles: claims.Roles,
+		Scope: strings.Join(claims.Scopes, " "),
+	})
+	signed, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", auth.Claims{}, fmt.Errorf("signing token: %w", err)
+	}
+	return signed, claims, nil
+}
+
+// Verify implements auth.TokenService. Only HS256 tokens from this
+// service's issuer that carry a subject and an expiry are accepted.
+func (s *JWTService) Verify(ctx context.Context, token string) (*auth.Claims, error) {
+	var parsed jwtClaims
+	_, err := jwt.ParseWithClaims(token, &parsed, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil || parsed.Subject == "" {
+		return nil, auth.ErrInvalidToken
+	}
+
+	claims := &auth.Claims{
+		ID:        parsed.ID,
+		Subject:   parsed.Subject,
+		Roles:     parsed.Roles,
+		Scopes:    strings.Fields(parsed.Scope),
+		ExpiresAt: parsed.ExpiresAt.UTC(),
+	}
+	if parsed.IssuedAt != nil {
+		claims.IssuedAt = parsed.IssuedAt.UTC()
+	}
+	return claims, nil
+}
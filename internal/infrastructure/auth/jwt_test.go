@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/auth"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func newTestJWTService(now time.Time) *JWTService {
+	s := NewJWTService(testSecret, "clean-architecture", 15*time.Minute)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestJWTService_IssueAndVerify(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestJWTService(now)
+
+	token, issued, err := s.Issue(context.Background(), auth.Claims{
+		Subject: "user_1",
+		Roles:   []string{"admin"},
+		Scopes:  []string{"admin", "users:write"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, issued.ID, 32)
+	assert.Equal(t, now, issued.IssuedAt)
+	assert.Equal(t, now.Add(15*time.Minute), issued.ExpiresAt)
+
+	claims, err := s.Verify(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, &issued, claims)
+}
+
+func TestJWTService_Verify_Rejects(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newTestJWTService(now)
+	token, _, err := s.Issue(context.Background(), auth.Claims{Subject: "user_1"})
+	require.NoError(t, err)
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+		signed, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{"iss": "clean-architecture", "sub": "user_1", "iat": now.Unix(), "exp": now.Add(time.Minute).Unix()}
+	}
+	withClaim := func(name string, value interface{}) jwt.MapClaims {
+		claims := valid()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	// The payload of a token for another subject with this token's signature
+	forged := strings.Split(sign(jwt.SigningMethodHS256, []byte("guessed"), withClaim("sub", "admin_1")), ".")
+	tampered := forged[0] + "." + forged[1] + "." + strings.Split(token, ".")[2]
+
+	tests := map[string]string{
+		"garbage":          "not-a-token",
+		"tampered payload": tampered,
+		"other secret":     sign(jwt.SigningMethodHS256, []byte("another secret that is long enough"), valid()),
+		"other algorithm":  sign(jwt.SigningMethodHS512, []byte(testSecret), valid()),
+		"unsigned":         sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid()),
+		"other issuer":     sign(jwt.SigningMethodHS256, []byte(testSecret), withClaim("iss", "someone-else")),
+		"no expiry":        sign(jwt.SigningMethodHS256, []byte(testSecret), withClaim("exp", nil)),
+		"no subject":       sign(jwt.SigningMethodHS256, []byte(testSecret), withClaim("sub", nil)),
+		"expired":          sign(jwt.SigningMethodHS256, []byte(testSecret), withClaim("exp", now.Add(-time.Minute).Unix())),
+		"issued later":     sign(jwt.SigningMethodHS256, []byte(testSecret), withClaim("iat", now.Add(time.Hour).Unix())),
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.Verify(context.Background(), token)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+}
+
+func TestJWTService_Verify_ClockSkew(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	token, _, err := newTestJWTService(now).Issue(context.Background(), auth.Claims{Subject: "user_1"})
+	require.NoError(t, err)
+
+	_, err = newTestJWTService(now.Add(15*time.Minute+10*time.Second)).Verify(context.Background(), token)
+	assert.NoError(t, err)
+	_, err = newTestJWTService(now.Add(-10*time.Second)).Verify(context.Background(), token)
+	assert.NoError(t, err)
+	_, err = newTestJWTService(now.Add(16*time.Minute)).Verify(context.Background(), token)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
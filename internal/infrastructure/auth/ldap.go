@@ -0,0 +1,124 @@
+// Package auth implements the authentication providers of
+// internal/domain/auth
+package auth
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/pkg/ldap"
+)
+
+// directory is the part of *ldap.Pool the LDAP provider uses
+type directory interface {
+	Authenticate(ctx context.Context, baseDN, filter string, attributes []string, password string) (*ldap.Entry, error)
+	Search(ctx context.Context, baseDN, filter string, attributes []string) ([]*ldap.Entry, error)
+}
+
+// LDAPProvider verifies credentials by binding to an LDAP or Active
+// Directory server as the user, and maps the user's groups to roles
+type LDAPProvider struct {
+	directory directory
+	cfg       configs.LDAPConfig
+	roles     roleMapper
+}
+
+// NewLDAPPool creates the connection pool for the configured directory
+func NewLDAPPool(cfg configs.LDAPConfig) (*ldap.Pool, error) {
+	return ldap.New(ldap.Config{
+		URL:                cfg.URL,
+		StartTLS:           cfg.StartTLS,
+		CAFile:             cfg.CAFile,
+		ServerName:         cfg.TLSServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		BindDN:             cfg.BindDN,
+		BindPassword:       cfg.BindPassword,
+		PoolSize:           cfg.PoolSize,
+		Timeout:            cfg.Timeout,
+	})
+}
+
+// NewLDAPProvider creates a provider that authenticates against pool
+func NewLDAPProvider(pool *ldap.Pool, cfg configs.LDAPConfig) *LDAPProvider {
+	return newLDAPProvider(pool, cfg)
+}
+
+func newLDAPProvider(directory directory, cfg configs.LDAPConfig) *LDAPProvider {
+	return &LDAPProvider{directory: directory, cfg: cfg, roles: newRoleMapper(cfg.GroupRoles, cfg.DefaultRole)}
+}
+
+// Name implements auth.Provider
+func (p *LDAPProvider) Name() string {
+	return "ldap"
+}
+
+// Authenticate implements auth.Provider
+func (p *LDAPProvider) Authenticate(ctx context.Context, username, password string) (*auth.Identity, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	attributes := []string{p.cfg.EmailAttribute, p.cfg.NameAttribute}
+	if p.cfg.GroupBaseDN == "" {
+		attributes = append(attributes, p.cfg.GroupAttribute)
+	}
+	entry, err := p.directory.Authenticate(ctx, p.cfg.BaseDN, ldap.UserFilter(p.cfg.UserFilter, username), attributes, password)
+	switch {
+	case errors.Is(err, ldap.ErrInvalidCredentials), errors.Is(err, ldap.ErrNotFound), errors.Is(err, ldap.ErrAmbiguous):
+		return nil, auth.ErrInvalidCredentials
+	case err != nil:
+		return nil, err
+	}
+
+	groups, err := p.groups(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	roles := p.roles.roles(groups)
+	if len(roles) == 0 {
+		return nil, auth.ErrAccessDenied
+	}
+
+	name := entry.Get(p.cfg.NameAttribute)
+	if name == "" {
+		name = username
+	}
+	return &auth.Identity{
+		Provider: p.Name(),
+		Subject:  entry.DN,
+		Username: username,
+		Email:    strings.ToLower(entry.Get(p.cfg.EmailAttribute)),
+		Name:     name,
+		Groups:   groups,
+		Roles:    roles,
+	}, nil
+}
+
+// groups returns the names of the user's groups, from the user entry or
+// from a group search
+func (p *LDAPProvider) groups(ctx context.Context, user *ldap.Entry) ([]string, error) {
+	var dns []string
+	if p.cfg.GroupBaseDN == "" {
+		dns = user.Values(p.cfg.GroupAttribute)
+	} else {
+		entries, err := p.directory.Search(ctx, p.cfg.GroupBaseDN, ldap.DNFilter(p.cfg.GroupFilter, user.DN), []string{"cn"})
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			dns = append(dns, entry.DN)
+		}
+	}
+
+	groups := make([]string, 0, len(dns))
+	for _, dn := range dns {
+		groups = append(groups, ldap.CommonName(dn))
+	}
+	sort.Strings(groups)
+	return groups, nil
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/pkg/ldap"
+)
+
+type fakeDirectory struct {
+	user     *ldap.Entry
+	password string
+	groups   []*ldap.Entry
+	err      error
+
+	filter      string
+	groupFilter string
+}
+
+func (d *fakeDirectory) Authenticate(ctx context.Context, baseDN, filter string, attributes []string, password string) (*ldap.Entry, error) {
+	d.filter = filter
+	if d.err != nil {
+		return nil, d.err
+	}
+	if d.user == nil {
+		return nil, ldap.ErrNotFound
+	}
+	if password != d.password {
+		return nil, ldap.ErrInvalidCredentials
+	}
+	return d.user, nil
+}
+
+func (d *fakeDirectory) Search(ctx context.Context, baseDN, filter string, attributes []string) ([]*ldap.Entry, error) {
+	d.groupFilter = filter
+	return d.groups, nil
+}
+
+func ldapConfig() configs.LDAPConfig {
+	return configs.LDAPConfig{
+		BaseDN:         "DC=corp,DC=example,DC=com",
+		UserFilter:     "(sAMAccountName={username})",
+		EmailAttribute: "mail",
+		NameAttribute:  "displayName",
+		GroupAttribute: "memberOf",
+		GroupFilter:    "(member={dn})",
+		GroupRoles:     map[string]string{"Domain Admins": "admin", "engineering": "user", "Support": "support"},
+		DefaultRole:    "user",
+	}
+}
+
+func TestLDAPProvider_Authenticate(t *testing.T) {
+	directory := &fakeDirectory{
+		password: "hunter2",
+		user: &ldap.Entry{
+			DN: "CN=Barbara Jensen,OU=People,DC=corp,DC=example,DC=com",
+			Attributes: map[string][]string{
+				"mail":        {"BJensen@Example.com"},
+				"displayName": {"Barbara Jensen"},
+				"memberOf": {
+					"CN=Engineering,OU=Groups,DC=corp,DC=example,DC=com",
+					"CN=Domain Admins,OU=Groups,DC=corp,DC=example,DC=com",
+					"CN=Parking,OU=Groups,DC=corp,DC=example,DC=com",
+				},
+			},
+		},
+	}
+	provider := newLDAPProvider(directory, ldapConfig())
+
+	identity, err := provider.Authenticate(context.Background(), " bjensen ", "hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, "(sAMAccountName=bjensen)", directory.filter)
+	assert.Equal(t, &auth.Identity{
+		Provider: "ldap",
+		Subject:  "CN=Barbara Jensen,OU=People,DC=corp,DC=example,DC=com",
+		Username: "bjensen",
+		Email:    "bjensen@example.com",
+		Name:     "Barbara Jensen",
+		Groups:   []string{"Domain Admins", "Engineering", "Parking"},
+		Roles:    []string{"admin", "user"},
+	}, identity)
+}
+
+func TestLDAPProvider_Authenticate_Failures(t *testing.T) {
+	user := &ldap.Entry{DN: "uid=bjensen,dc=example,dc=com"}
+
+	tests := []struct {
+		name      string
+		directory *fakeDirectory
+		username  string
+		password  string
+		expected  error
+	}{
+		{name: "wrong password", directory: &fakeDirectory{user: user, password: "hunter2"}, username: "bjensen", password: "hunter3", expected: auth.ErrInvalidCredentials},
+		{name: "unknown user", directory: &fakeDirectory{}, username: "nobody", password: "hunter2", expected: auth.ErrInvalidCredentials},
+		{name: "ambiguous user", directory: &fakeDirectory{err: ldap.ErrAmbiguous}, username: "b*", password: "hunter2", expected: auth.ErrInvalidCredentials},
+		{name: "empty username", directory: &fakeDirectory{user: user}, username: " ", password: "", expected: auth.ErrInvalidCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newLDAPProvider(tt.directory, ldapConfig()).Authenticate(context.Background(), tt.username, tt.password)
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+
+	unavailable := errors.New("ldap: connecting: connection refused")
+	_, err := newLDAPProvider(&fakeDirectory{err: unavailable}, ldapConfig()).Authenticate(context.Background(), "bjensen", "hunter2")
+	assert.ErrorIs(t, err, unavailable)
+	assert.NotErrorIs(t, err, auth.ErrInvalidCredentials)
+}
+
+func TestLDAPProvider_DefaultRole(t *testing.T) {
+	directory := &fakeDirectory{user: &ldap.Entry{DN: "uid=bjensen,dc=example,dc=com"}, password: "hunter2"}
+
+	identity, err := newLDAPProvider(directory, ldapConfig()).Authenticate(context.Background(), "bjensen", "hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user"}, identity.Roles)
+	assert.Equal(t, "bjensen", identity.Name)
+
+	cfg := ldapConfig()
+	cfg.DefaultRole = ""
+	_, err = newLDAPProvider(directory, cfg).Authenticate(context.Background(), "bjensen", "hunter2")
+	assert.ErrorIs(t, err, auth.ErrAccessDenied)
+}
+
+func TestLDAPProvider_GroupSearch(t *testing.T) {
+	directory := &fakeDirectory{
+		user:     &ldap.Entry{DN: "uid=bjensen,ou=people,dc=example,dc=com"},
+		password: "hunter2",
+		groups:   []*ldap.Entry{{DN: "cn=support,ou=groups,dc=example,dc=com"}},
+	}
+	cfg := ldapConfig()
+	cfg.GroupBaseDN = "ou=groups,dc=example,dc=com"
+
+	identity, err := newLDAPProvider(directory, cfg).Authenticate(context.Background(), "bjensen", "hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, "(member=uid=bjensen,ou=people,dc=example,dc=com)", directory.groupFilter)
+	assert.Equal(t, []string{"support"}, identity.Groups)
+	assert.Equal(t, []string{"support"}, identity.Roles)
+}
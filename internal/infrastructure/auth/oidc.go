@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/pkg/oidc"
+)
+
+// OIDCCallbackPath is where every OpenID provider redirects back to; the
+// state of a login names its provider
+const OIDCCallbackPath = "/api/v1/auth/oidc/callback"
+
+// GoogleIssuer is the issuer of Google accounts
+const GoogleIssuer = "https://accounts.google.com"
+
+// IDs of the configurable OpenID providers
+const (
+	OIDCGoogle  = "google"
+	OIDCGeneric = "generic"
+)
+
+// relyingParty is the part of *oidc.Provider an OIDC provider uses
+type relyingParty interface {
+	StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error)
+	FinishLogin(w http.ResponseWriter, r *http.Request) (*oidc.Claims, error)
+}
+
+// OIDCProvider logs in users at an OpenID provider, mapping the claims of
+// their ID tokens to identities
+type OIDCProvider struct {
+	rp        relyingParty
+	id        string
+	cfg       configs.OIDCProviderConfig
+	roles     roleMapper
+	redirects map[string]bool
+}
+
+// NewOIDCRelyingParty creates the client of the provider id, which
+// redirects back below the configured base URL
+func NewOIDCRelyingParty(cfg configs.OIDCConfig, id string, provider configs.OIDCProviderConfig, stateKey []byte, httpClient *http.Client) (*oidc.Provider, error) {
+	issuer := provider.Issuer
+	var params url.Values
+	if id == OIDCGoogle {
+		issuer = GoogleIssuer
+		// Google preselects the account of a single Workspace domain
+		if len(provider.EmailDomains) == 1 {
+			params = url.Values{"hd": {provider.EmailDomains[0]}}
+		}
+	}
+	return oidc.New(oidc.Config{
+		ID:               id,
+		Issuer:           issuer,
+		DiscoveryRefresh: cfg.DiscoveryRefresh,
+		HTTPClient:       httpClient,
+		ClientID:         provider.ClientID,
+		ClientSecret:     provider.ClientSecret,
+		RedirectURL:      strings.TrimSuffix(cfg.BaseURL, "/") + OIDCCallbackPath,
+		Scopes:           provider.Scopes,
+		AuthParams:       params,
+		StateKey:         stateKey,
+		RequestTTL:       cfg.RequestTTL,
+	})
+}
+
+// NewOIDCProvider creates the provider id that logs in at rp
+func NewOIDCProvider(rp *oidc.Provider, id string, cfg configs.OIDCConfig, provider configs.OIDCProviderConfig) *OIDCProvider {
+	return newOIDCProvider(rp, id, cfg.RedirectURLs, provider)
+}
+
+func newOIDCProvider(rp relyingParty, id string, redirectURLs []string, cfg configs.OIDCProviderConfig) *OIDCProvider {
+	redirects := make(map[string]bool, len(redirectURLs))
+	for _, redirect := range redirectURLs {
+		redirects[redirect] = true
+	}
+	return &OIDCProvider{rp: rp, id: id, cfg: cfg, roles: newRoleMapper(cfg.GroupRoles, cfg.DefaultRole), redirects: redirects}
+}
+
+// ID returns the provider's ID
+func (p *OIDCProvider) ID() string {
+	return p.id
+}
+
+// AllowsRedirect reports whether logins may return to uri. Only the
+// configured redirect URLs are allowed, compared exactly.
+func (p *OIDCProvider) AllowsRedirect(uri string) bool {
+	return p.redirects[uri]
+}
+
+// StartLogin returns the URL of the provider to send the user to
+func (p *OIDCProvider) StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error) {
+	return p.rp.StartLogin(w, r, returnTo)
+}
+
+// FinishLogin verifies the callback the provider redirected the user to
+// and returns the identity of its ID token, along with the URL passed to
+// StartLogin
+func (p *OIDCProvider) FinishLogin(w http.ResponseWriter, r *http.Request) (*auth.Identity, string, error) {
+	claims, err := p.rp.FinishLogin(w, r)
+	if errors.Is(err, oidc.ErrAccessDenied) {
+		return nil, "", auth.ErrAccessDenied
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	identity, err := p.identity(claims)
+	if err != nil {
+		return nil, "", err
+	}
+	return identity, claims.ReturnTo, nil
+}
+
+// identity maps the claims of an ID token to an identity. Users are linked
+// to local accounts by email, so unverified emails are refused.
+func (p *OIDCProvider) identity(claims *oidc.Claims) (*auth.Identity, error) {
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email != "" && (!claims.EmailVerified || !p.allowsEmail(email)) {
+		return nil, auth.ErrAccessDenied
+	}
+	// Any Google account may use an address at another domain; only the
+	// hosted domain claim shows that a Workspace domain manages it
+	if p.id == OIDCGoogle && len(p.cfg.EmailDomains) > 0 && !p.allowsEmail("@"+claims.Get("hd")) {
+		return nil, auth.ErrAccessDenied
+	}
+
+	var groups []string
+	if p.cfg.GroupsClaim != "" {
+		groups = claims.Values(p.cfg.GroupsClaim)
+		sort.Strings(groups)
+	}
+	roles := p.roles.roles(groups)
+	if len(roles) == 0 {
+		return nil, auth.ErrAccessDenied
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name = email
+	}
+	return &auth.Identity{
+		Provider: "oidc:" + p.id,
+		Subject:  claims.Subject,
+		Username: email,
+		Email:    email,
+		Name:     name,
+		Groups:   groups,
+		Roles:    roles,
+	}, nil
+}
+
+// allowsEmail reports whether email is in one of the provider's domains.
+// Providers without domains accept any email.
+func (p *OIDCProvider) allowsEmail(email string) bool {
+	if len(p.cfg.EmailDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, allowed := range p.cfg.EmailDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/pkg/oidc"
+)
+
+type fakeRelyingParty struct {
+	claims *oidc.Claims
+	err    error
+}
+
+func (rp *fakeRelyingParty) StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error) {
+	return "https://accounts.example.com/authorize", nil
+}
+
+func (rp *fakeRelyingParty) FinishLogin(w http.ResponseWriter, r *http.Request) (*oidc.Claims, error) {
+	return rp.claims, rp.err
+}
+
+func oidcProviderConfig() configs.OIDCProviderConfig {
+	return configs.OIDCProviderConfig{
+		GroupsClaim:  "groups",
+		GroupRoles:   map[string]string{"Admins": "admin"},
+		DefaultRole:  "user",
+		EmailDomains: []string{"example.com"},
+	}
+}
+
+func oidcClaims() *oidc.Claims {
+	return &oidc.Claims{
+		Subject:       "248289761001",
+		Email:         "BJensen@Example.com",
+		EmailVerified: true,
+		Name:          "Barbara Jensen",
+		ReturnTo:      "https://app.example.com/sso",
+		Raw: map[string]interface{}{
+			"hd":     "example.com",
+			"groups": []interface{}{"everyone", "admins"},
+		},
+	}
+}
+
+func finishOIDCLogin(t *testing.T, id string, cfg configs.OIDCProviderConfig, claims *oidc.Claims) (*auth.Identity, error) {
+	t.Helper()
+	provider := newOIDCProvider(&fakeRelyingParty{claims: claims}, id, nil, cfg)
+	identity, _, err := provider.FinishLogin(httptest.NewRecorder(), httptest.NewRequest("GET", OIDCCallbackPath, nil))
+	return identity, err
+}
+
+func TestOIDCProvider_FinishLogin(t *testing.T) {
+	provider := newOIDCProvider(&fakeRelyingParty{claims: oidcClaims()}, OIDCGeneric, []string{"https://app.example.com/sso"}, oidcProviderConfig())
+
+	identity, returnTo, err := provider.FinishLogin(httptest.NewRecorder(), httptest.NewRequest("GET", OIDCCallbackPath, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.example.com/sso", returnTo)
+	assert.Equal(t, &auth.Identity{
+		Provider: "oidc:generic",
+		Subject:  "248289761001",
+		Username: "bjensen@example.com",
+		Email:    "bjensen@example.com",
+		Name:     "Barbara Jensen",
+		Groups:   []string{"admins", "everyone"},
+		Roles:    []string{"admin"},
+	}, identity)
+	assert.True(t, provider.AllowsRedirect("https://app.example.com/sso"))
+	assert.False(t, provider.AllowsRedirect("https://app.example.com/other"))
+}
+
+func TestOIDCProvider_FinishLogin_DefaultRole(t *testing.T) {
+	cfg := oidcProviderConfig()
+	cfg.GroupsClaim = ""
+	claims := oidcClaims()
+	claims.Name = ""
+
+	identity, err := finishOIDCLogin(t, OIDCGeneric, cfg, claims)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user"}, identity.Roles)
+	assert.Empty(t, identity.Groups)
+	assert.Equal(t, "bjensen@example.com", identity.Name)
+}
+
+func TestOIDCProvider_FinishLogin_Refused(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		mutate func(cfg *configs.OIDCProviderConfig, claims *oidc.Claims)
+	}{
+		{"unverified email", OIDCGeneric, func(cfg *configs.OIDCProviderConfig, claims *oidc.Claims) {
+			claims.EmailVerified = false
+		}},
+		{"email of another domain", OIDCGeneric, func(cfg *configs.OIDCProviderConfig, claims *oidc.Claims) {
+			claims.Email = "bjensen@example.org"
+		}},
+		{"no role", OIDCGeneric, func(cfg *configs.OIDCProviderConfig, claims *oidc.Claims) {
+			cfg.DefaultRole = ""
+			claims.Raw["groups"] = []interface{}{"everyone"}
+		}},
+		{"Google account outside the Workspace domain", OIDCGoogle, func(cfg *configs.OIDCProviderConfig, claims *oidc.Claims) {
+			delete(claims.Raw, "hd")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, claims := oidcProviderConfig(), oidcClaims()
+			tt.mutate(&cfg, claims)
+
+			_, err := finishOIDCLogin(t, tt.id, cfg, claims)
+			assert.ErrorIs(t, err, auth.ErrAccessDenied)
+		})
+	}
+}
+
+func TestOIDCProvider_FinishLogin_DeniedAtProvider(t *testing.T) {
+	provider := newOIDCProvider(&fakeRelyingParty{err: oidc.ErrAccessDenied}, OIDCGoogle, nil, oidcProviderConfig())
+
+	_, _, err := provider.FinishLogin(httptest.NewRecorder(), httptest.NewRequest("GET", OIDCCallbackPath, nil))
+	assert.ErrorIs(t, err, auth.ErrAccessDenied)
+}
+
+func TestNewOIDCRelyingParty(t *testing.T) {
+	cfg := configs.OIDCConfig{BaseURL: "https://api.example.com/"}
+	provider := configs.OIDCProviderConfig{ClientID: "client_1", ClientSecret: "secret"}
+
+	_, err := NewOIDCRelyingParty(cfg, OIDCGoogle, provider, []byte("state-key"), http.DefaultClient)
+	assert.NoError(t, err, "Google's issuer is fixed")
+
+	_, err = NewOIDCRelyingParty(cfg, OIDCGeneric, provider, []byte("state-key"), http.DefaultClient)
+	assert.Error(t, err, "a generic provider needs an issuer")
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/retryafter"
+)
+
+// BcryptHasher hashes passwords with bcrypt
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher creates a hasher of the given cost. Hashes of other costs
+// still verify, so the cost can be raised without resetting passwords.
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+// Hash implements auth.PasswordHasher
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify implements auth.PasswordHasher
+func (h *BcryptHasher) Verify(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// PasswordProvider verifies the passwords of local users, who log in with
+// their email as the username
+type PasswordProvider struct {
+	userRepo repositories.UserRepository
+	hasher   auth.PasswordHasher
+	// absentHash is verified for unknown users so that they take as long
+	// to refuse as wrong passwords
+	absentHash string
+}
+
+// NewPasswordProvider creates a provider that verifies passwords against
+// the hashes stored with users
+func NewPasswordProvider(userRepo repositories.UserRepository, hasher auth.PasswordHasher) (*PasswordProvider, error) {
+	absentHash, err := hasher.Hash("absent user")
+	if err != nil {
+		return nil, err
+	}
+	return &PasswordProvider{userRepo: userRepo, hasher: hasher, absentHash: absentHash}, nil
+}
+
+// Name implements auth.Provider
+func (p *PasswordProvider) Name() string {
+	return "password"
+}
+
+// Authenticate implements auth.Provider
+func (p *PasswordProvider) Authenticate(ctx context.Context, username, password string) (*auth.Identity, error) {
+	email := strings.ToLower(strings.TrimSpace(username))
+	if email == "" || password == "" {
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	user, err := p.userRepo.GetByEmail(consistency.WithPrimary(ctx), email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.HasPassword() {
+		p.hasher.Verify(p.absentHash, password)
+		return nil, auth.ErrInvalidCredentials
+	}
+	if !p.hasher.Verify(user.PasswordHash, password) {
+		return nil, auth.ErrInvalidCredentials
+	}
+	// Checked after the password, so it does not tell who signed up
+	if !user.EmailVerified() {
+		return nil, auth.ErrEmailNotVerified
+	}
+
+	return &auth.Identity{
+		Provider: p.Name(),
+		Subject:  user.ID,
+		Username: email,
+		Email:    user.Email,
+		Name:     user.Name,
+		Roles:    []string{policy.RoleUser},
+	}, nil
+}
+
+// ChainProvider tries each provider in turn until one accepts the
+// credentials. Invalid credentials and unavailable providers fall through to
+// the next provider; other errors stop the chain, so a failing directory is
+// not mistaken for a wrong password. When no provider accepts the
+// credentials and one was unavailable, the chain is unavailable too, since
+// that provider might have accepted them.
+type ChainProvider []auth.Provider
+
+// Name implements auth.Provider
+func (c ChainProvider) Name() string {
+	names := make([]string, 0, len(c))
+	for _, provider := range c {
+		names = append(names, provider.Name())
+	}
+	return strings.Join(names, "+")
+}
+
+// Authenticate implements auth.Provider
+func (c ChainProvider) Authenticate(ctx context.Context, username, password string) (*auth.Identity, error) {
+	refusal := auth.ErrInvalidCredentials
+	for _, provider := range c {
+		identity, err := provider.Authenticate(ctx, username, password)
+		switch {
+		case errors.Is(err, auth.ErrProviderUnavailable):
+			refusal = err
+		case !errors.Is(err, auth.ErrInvalidCredentials):
+			return identity, err
+		}
+	}
+	return nil, refusal
+}
+
+// GatedProvider refuses logins with auth.ErrProviderUnavailable while its
+// feature is degraded, rather than waiting on a directory known to be down
+type GatedProvider struct {
+	auth.Provider
+	enabled    func() bool
+	retryAfter time.Duration
+}
+
+// NewGatedProvider wraps provider so it is only asked while enabled
+// reports true
+func NewGatedProvider(provider auth.Provider, enabled func() bool) *GatedProvider {
+	return &GatedProvider{Provider: provider, enabled: enabled}
+}
+
+// WithRetryAfter makes refused logins suggest retrying after d, such as
+// the interval the feature's dependency is checked at
+func (p *GatedProvider) WithRetryAfter(d time.Duration) *GatedProvider {
+	p.retryAfter = d
+	return p
+}
+
+// Authenticate implements auth.Provider
+func (p *GatedProvider) Authenticate(ctx context.Context, username, password string) (*auth.Identity, error) {
+	if !p.enabled() {
+		err := auth.ErrProviderUnavailable
+		if p.retryAfter > 0 {
+			err = retryafter.Wrap(err, p.retryAfter)
+		}
+		return nil, fmt.Errorf("%s: %w", p.Name(), err)
+	}
+	return p.Provider.Authenticate(ctx, username, password)
+}
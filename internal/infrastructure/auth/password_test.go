@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/retryafter"
+)
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+
+	hash, err := hasher.Hash("correct horse battery staple")
+	require.NoError(t, err)
+	assert.NotContains(t, hash, "correct horse")
+	assert.True(t, hasher.Verify(hash, "correct horse battery staple"))
+	assert.False(t, hasher.Verify(hash, "correct horse battery stapler"))
+	assert.False(t, hasher.Verify("not a hash", "correct horse battery staple"))
+
+	other, err := hasher.Hash("correct horse battery staple")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other, "hashes are salted")
+
+	costlier := NewBcryptHasher(bcrypt.MinCost + 1)
+	assert.True(t, costlier.Verify(hash, "correct horse battery staple"), "hashes of other costs still verify")
+}
+
+func TestPasswordProvider_Authenticate(t *testing.T) {
+	hasher := NewBcryptHasher(bcrypt.MinCost)
+	hash, err := hasher.Hash("hunter2hunter2")
+	require.NoError(t, err)
+
+	userRepo := database.NewMockUserRepository()
+	user := entities.NewUser("bjensen@example.com", "Barbara Jensen")
+	user.SetPasswordHash(hash)
+	require.NoError(t, userRepo.Create(context.Background(), user))
+	require.NoError(t, userRepo.Create(context.Background(), entities.NewUser("sso@example.com", "Single Sign-On")))
+	unverified := entities.NewUser("unverified@example.com", "Not Yet")
+	unverified.SetPasswordHash(hash)
+	unverified.EmailUnverified = true
+	require.NoError(t, userRepo.Create(context.Background(), unverified))
+
+	provider, err := NewPasswordProvider(userRepo, hasher)
+	require.NoError(t, err)
+
+	identity, err := provider.Authenticate(context.Background(), " BJensen@Example.com ", "hunter2hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Identity{
+		Provider: "password",
+		Subject:  user.ID,
+		Username: "bjensen@example.com",
+		Email:    "bjensen@example.com",
+		Name:     "Barbara Jensen",
+		Roles:    []string{"user"},
+	}, identity)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{name: "wrong password", username: "bjensen@example.com", password: "hunter3hunter3"},
+		{name: "unknown user", username: "nobody@example.com", password: "hunter2hunter2"},
+		{name: "user without password", username: "sso@example.com", password: ""},
+		{name: "user without password, any password", username: "sso@example.com", password: "hunter2hunter2"},
+		{name: "empty username", username: " ", password: "hunter2hunter2"},
+		{name: "unverified user, wrong password", username: "unverified@example.com", password: "hunter3hunter3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.Authenticate(context.Background(), tt.username, tt.password)
+			assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+		})
+	}
+
+	_, err = provider.Authenticate(context.Background(), "unverified@example.com", "hunter2hunter2")
+	assert.ErrorIs(t, err, auth.ErrEmailNotVerified)
+}
+
+// namedProvider returns its identity or error for any credentials
+type namedProvider struct {
+	name     string
+	identity *auth.Identity
+	err      error
+	calls    int
+}
+
+func (p *namedProvider) Name() string { return p.name }
+
+func (p *namedProvider) Authenticate(ctx context.Context, username, password string) (*auth.Identity, error) {
+	p.calls++
+	return p.identity, p.err
+}
+
+func TestChainProvider(t *testing.T) {
+	ldapIdentity := &auth.Identity{Provider: "ldap"}
+	passwordIdentity := &auth.Identity{Provider: "password"}
+
+	t.Run("first accepting provider wins", func(t *testing.T) {
+		ldap := &namedProvider{name: "ldap", identity: ldapIdentity}
+		passwords := &namedProvider{name: "password", identity: passwordIdentity}
+		chain := ChainProvider{ldap, passwords}
+
+		identity, err := chain.Authenticate(context.Background(), "bjensen", "hunter2")
+		require.NoError(t, err)
+		assert.Same(t, ldapIdentity, identity)
+		assert.Equal(t, 0, passwords.calls)
+		assert.Equal(t, "ldap+password", chain.Name())
+	})
+
+	t.Run("invalid credentials fall through", func(t *testing.T) {
+		chain := ChainProvider{
+			&namedProvider{name: "ldap", err: auth.ErrInvalidCredentials},
+			&namedProvider{name: "password", identity: passwordIdentity},
+		}
+		identity, err := chain.Authenticate(context.Background(), "bjensen@example.com", "hunter2")
+		require.NoError(t, err)
+		assert.Same(t, passwordIdentity, identity)
+
+		chain = ChainProvider{
+			&namedProvider{name: "ldap", err: auth.ErrInvalidCredentials},
+			&namedProvider{name: "password", err: auth.ErrInvalidCredentials},
+		}
+		_, err = chain.Authenticate(context.Background(), "bjensen@example.com", "hunter2")
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+	})
+
+	t.Run("unavailable providers fall through", func(t *testing.T) {
+		unavailable := fmt.Errorf("ldap: %w", auth.ErrProviderUnavailable)
+		chain := ChainProvider{
+			&namedProvider{name: "ldap", err: unavailable},
+			&namedProvider{name: "password", identity: passwordIdentity},
+		}
+		identity, err := chain.Authenticate(context.Background(), "bjensen@example.com", "hunter2")
+		require.NoError(t, err)
+		assert.Same(t, passwordIdentity, identity)
+
+		chain = ChainProvider{
+			&namedProvider{name: "ldap", err: unavailable},
+			&namedProvider{name: "password", err: auth.ErrInvalidCredentials},
+		}
+		_, err = chain.Authenticate(context.Background(), "bjensen", "hunter2")
+		assert.ErrorIs(t, err, auth.ErrProviderUnavailable, "the unavailable provider might have accepted the credentials")
+	})
+
+	t.Run("other errors stop the chain", func(t *testing.T) {
+		unavailable := errors.New("ldap: connection refused")
+		passwords := &namedProvider{name: "password", identity: passwordIdentity}
+		chain := ChainProvider{&namedProvider{name: "ldap", err: unavailable}, passwords}
+
+		_, err := chain.Authenticate(context.Background(), "bjensen", "hunter2")
+		assert.ErrorIs(t, err, unavailable)
+		assert.Equal(t, 0, passwords.calls)
+
+		chain = ChainProvider{&namedProvider{name: "ldap", err: auth.ErrAccessDenied}, passwords}
+		_, err = chain.Authenticate(context.Background(), "bjensen", "hunter2")
+		assert.ErrorIs(t, err, auth.ErrAccessDenied)
+	})
+}
+
+func TestGatedProvider(t *testing.T) {
+	ldapIdentity := &auth.Identity{Provider: "ldap"}
+	ldap := &namedProvider{name: "ldap", identity: ldapIdentity}
+	enabled := true
+	gated := NewGatedProvider(ldap, func() bool { return enabled })
+
+	identity, err := gated.Authenticate(context.Background(), "bjensen", "hunter2")
+	require.NoError(t, err)
+	assert.Same(t, ldapIdentity, identity)
+	assert.Equal(t, "ldap", gated.Name())
+
+	enabled = false
+	_, err = gated.Authenticate(context.Background(), "bjensen", "hunter2")
+	assert.ErrorIs(t, err, auth.ErrProviderUnavailable)
+	assert.Equal(t, 1, ldap.calls, "degraded providers are not asked")
+	_, ok := retryafter.Of(err)
+	assert.False(t, ok)
+
+	gated.WithRetryAfter(15 * time.Second)
+	_, err = gated.Authenticate(context.Background(), "bjensen", "hunter2")
+	assert.ErrorIs(t, err, auth.ErrProviderUnavailable)
+	after, ok := retryafter.Of(err)
+	assert.True(t, ok)
+	assert.Equal(t, 15*time.Second, after)
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/pkg/cache"
+)
+
+// maxMemoryRevocations bounds the token IDs a MemoryRevocationStore keeps.
+// Beyond it the least recently used are dropped, and their tokens are
+// accepted again until they expire.
+const maxMemoryRevocations = 100000
+
+// MemoryRevocationStore keeps revoked token IDs in process memory. It is
+// the fallback when Redis is not configured: tokens are only refused by
+// the instance they were revoked on, and revocations are lost on restart.
+type MemoryRevocationStore struct {
+	revoked *cache.Cache[string, struct{}]
+}
+
+// NewMemoryRevocationStore creates an empty in-memory revocation store
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		revoked: cache.New[string, struct{}](cache.Options{
+			Name:       "revoked_tokens",
+			MaxEntries: maxMemoryRevocations,
+		}),
+	}
+}
+
+// Revoke refuses the token with id until it expires
+func (s *MemoryRevocationStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	if ttl := time.Until(until); ttl > 0 {
+		s.revoked.SetWithTTL(id, struct{}{}, ttl)
+	}
+	return nil
+}
+
+// Revoked reports whether the token with id was revoked
+func (s *MemoryRevocationStore) Revoked(ctx context.Context, id string) (bool, error) {
+	_, ok := s.revoked.Get(id)
+	return ok, nil
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRevocationStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRevocationStore()
+
+	revoked, err := store.Revoked(ctx, "token_1")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, store.Revoke(ctx, "token_1", time.Now().Add(time.Minute)))
+	require.NoError(t, store.Revoke(ctx, "token_2", time.Now().Add(-time.Minute)))
+
+	revoked, err = store.Revoked(ctx, "token_1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = store.Revoked(ctx, "token_2")
+	require.NoError(t, err)
+	assert.False(t, revoked, "expired tokens need not be kept")
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+)
+
+// roleMapper maps the groups of an identity to policy roles
+type roleMapper struct {
+	// groupRoles is keyed by lowercased group name
+	groupRoles  map[string]string
+	defaultRole string
+}
+
+func newRoleMapper(groupRoles map[string]string, defaultRole string) roleMapper {
+	mapped := make(map[string]string, len(groupRoles))
+	for group, role := range groupRoles {
+		mapped[strings.ToLower(strings.TrimSpace(group))] = strings.TrimSpace(role)
+	}
+	return roleMapper{groupRoles: mapped, defaultRole: defaultRole}
+}
+
+// roles maps groups to roles, falling back to the default role. No roles
+// means the identity may not log in.
+func (m roleMapper) roles(groups []string) []string {
+	seen := make(map[string]bool)
+	var roles []string
+	for _, group := range groups {
+		role, ok := m.groupRoles[strings.ToLower(group)]
+		if ok && role != "" && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 && m.defaultRole != "" {
+		roles = []string{m.defaultRole}
+	}
+	sort.Strings(roles)
+	return roles
+}
@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/pkg/saml"
+)
+
+// SAMLPath is where the endpoints of a SAML tenant are mounted; the
+// service provider's metadata and ACS URLs are derived from it
+const SAMLPath = "/api/v1/auth/saml/"
+
+// Attribute names tried when a tenant does not configure one, covering
+// the common identity providers' defaults
+var (
+	samlEmailAttributes = []string{
+		"email",
+		"mail",
+		"urn:oid:0.9.2342.19200300.100.1.3",
+		"http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress",
+	}
+	samlNameAttributes = []string{
+		"displayName",
+		"name",
+		"urn:oid:2.16.840.1.113730.3.1.241",
+		"http://schemas.microsoft.com/identity/claims/displayname",
+	}
+	samlGroupsAttributes = []string{
+		"groups",
+		"memberOf",
+		"http://schemas.microsoft.com/ws/2008/06/identity/claims/groups",
+	}
+)
+
+// samlEmailNameID is the NameID format of an email address
+const samlEmailNameID = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+
+// serviceProvider is the part of *saml.ServiceProvider a SAML tenant uses
+type serviceProvider interface {
+	Metadata() ([]byte, error)
+	StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error)
+	FinishLogin(w http.ResponseWriter, r *http.Request) (*saml.Assertion, error)
+}
+
+// SAMLTenant logs in the users of one tenant at its SAML identity
+// provider, mapping their attributes to identities
+type SAMLTenant struct {
+	sp        serviceProvider
+	cfg       configs.SAMLTenant
+	roles     roleMapper
+	redirects map[string]bool
+}
+
+// NewSAMLServiceProvider creates the service provider of tenant, published
+// below the configured base URL
+func NewSAMLServiceProvider(cfg configs.SAMLConfig, tenant configs.SAMLTenant, cert *x509.Certificate, key *rsa.PrivateKey, stateKey []byte, httpClient *http.Client) (*saml.ServiceProvider, error) {
+	var metadata []byte
+	if tenant.IDPMetadataFile != "" {
+		data, err := os.ReadFile(tenant.IDPMetadataFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read identity provider metadata: %w", err)
+		}
+		metadata = data
+	}
+
+	base := strings.TrimSuffix(cfg.BaseURL, "/") + SAMLPath + tenant.ID
+	return saml.New(saml.Config{
+		EntityID:        base + "/metadata",
+		ACSURL:          base + "/acs",
+		Certificate:     cert,
+		Key:             key,
+		IDPMetadata:     metadata,
+		IDPMetadataURL:  tenant.IDPMetadataURL,
+		MetadataRefresh: cfg.MetadataRefresh,
+		HTTPClient:      httpClient,
+		StateKey:        stateKey,
+		RequestTTL:      cfg.RequestTTL,
+	})
+}
+
+// NewSAMLTenant creates a tenant that logs in at sp
+func NewSAMLTenant(sp *saml.ServiceProvider, cfg configs.SAMLTenant) *SAMLTenant {
+	return newSAMLTenant(sp, cfg)
+}
+
+func newSAMLTenant(sp serviceProvider, cfg configs.SAMLTenant) *SAMLTenant {
+	redirects := make(map[string]bool, len(cfg.RedirectURLs))
+	for _, redirect := range cfg.RedirectURLs {
+		redirects[redirect] = true
+	}
+	return &SAMLTenant{sp: sp, cfg: cfg, roles: newRoleMapper(cfg.GroupRoles, cfg.DefaultRole), redirects: redirects}
+}
+
+// ID returns the tenant's ID
+func (t *SAMLTenant) ID() string {
+	return t.cfg.ID
+}
+
+// Metadata returns the service provider metadata to register at the
+// identity provider
+func (t *SAMLTenant) Metadata() ([]byte, error) {
+	return t.sp.Metadata()
+}
+
+// AllowsRedirect reports whether logins may return to uri. Only the
+// configured redirect URLs are allowed, compared exactly.
+func (t *SAMLTenant) AllowsRedirect(uri string) bool {
+	return t.redirects[uri]
+}
+
+// StartLogin returns the URL of the identity provider to send the user to
+func (t *SAMLTenant) StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error) {
+	return t.sp.StartLogin(w, r, returnTo)
+}
+
+// FinishLogin verifies the response posted back by the identity provider
+// and returns the identity it asserts, along with the URL passed to
+// StartLogin
+func (t *SAMLTenant) FinishLogin(w http.ResponseWriter, r *http.Request) (*auth.Identity, string, error) {
+	assertion, err := t.sp.FinishLogin(w, r)
+	if err != nil {
+		return nil, "", err
+	}
+	identity, err := t.identity(assertion)
+	if err != nil {
+		return nil, "", err
+	}
+	return identity, assertion.ReturnTo, nil
+}
+
+// identity maps the attributes of assertion to an identity
+func (t *SAMLTenant) identity(assertion *saml.Assertion) (*auth.Identity, error) {
+	email := strings.ToLower(strings.TrimSpace(attribute(assertion, t.cfg.EmailAttribute, samlEmailAttributes)))
+	if email == "" && assertion.NameIDFormat == samlEmailNameID {
+		email = strings.ToLower(strings.TrimSpace(assertion.NameID))
+	}
+	if email != "" && !t.allowsEmail(email) {
+		return nil, auth.ErrAccessDenied
+	}
+
+	var groups []string
+	if t.cfg.GroupsAttribute != "" {
+		groups = assertion.Values(t.cfg.GroupsAttribute)
+	} else {
+		for _, name := range samlGroupsAttributes {
+			if groups = assertion.Values(name); len(groups) > 0 {
+				break
+			}
+		}
+	}
+	groups = append([]string(nil), groups...)
+	sort.Strings(groups)
+	roles := t.roles.roles(groups)
+	if len(roles) == 0 {
+		return nil, auth.ErrAccessDenied
+	}
+
+	name := attribute(assertion, t.cfg.NameAttribute, samlNameAttributes)
+	if name == "" {
+		name = assertion.NameID
+	}
+	return &auth.Identity{
+		Provider: "saml:" + t.cfg.ID,
+		Subject:  assertion.NameID,
+		Username: assertion.NameID,
+		Email:    email,
+		Name:     name,
+		Groups:   groups,
+		Roles:    roles,
+	}, nil
+}
+
+// allowsEmail reports whether email is in one of the tenant's domains.
+// Tenants without domains accept any email.
+func (t *SAMLTenant) allowsEmail(email string) bool {
+	if len(t.cfg.EmailDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := email[at+1:]
+	for _, allowed := range t.cfg.EmailDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// attribute returns the value of the configured attribute, or of the first
+// fallback present when none is configured
+func attribute(assertion *saml.Assertion, configured string, fallbacks []string) string {
+	if configured != "" {
+		return assertion.Get(configured)
+	}
+	for _, name := range fallbacks {
+		if value := assertion.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/pkg/saml"
+)
+
+type fakeServiceProvider struct {
+	assertion *saml.Assertion
+	err       error
+}
+
+func (sp *fakeServiceProvider) Metadata() ([]byte, error) {
+	return []byte("<EntityDescriptor/>"), nil
+}
+
+func (sp *fakeServiceProvider) StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error) {
+	return "https://idp.example.com/sso", nil
+}
+
+func (sp *fakeServiceProvider) FinishLogin(w http.ResponseWriter, r *http.Request) (*saml.Assertion, error) {
+	return sp.assertion, sp.err
+}
+
+func samlTenantConfig() configs.SAMLTenant {
+	return configs.SAMLTenant{
+		ID:           "acme",
+		GroupRoles:   map[string]string{"Admins": "admin"},
+		DefaultRole:  "user",
+		EmailDomains: []string{"example.com"},
+		RedirectURLs: []string{"https://app.example.com/sso"},
+	}
+}
+
+func finishSAMLLogin(t *testing.T, cfg configs.SAMLTenant, assertion *saml.Assertion) (*auth.Identity, error) {
+	t.Helper()
+	tenant := newSAMLTenant(&fakeServiceProvider{assertion: assertion}, cfg)
+	identity, _, err := tenant.FinishLogin(httptest.NewRecorder(), httptest.NewRequest("POST", "/acs", nil))
+	return identity, err
+}
+
+func TestSAMLTenant_FinishLogin(t *testing.T) {
+	assertion := &saml.Assertion{
+		NameID: "00u1abcd",
+		Attributes: []saml.Attribute{
+			{Name: "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress", Values: []string{"BJensen@Example.com"}},
+			{Name: "urn:oid:2.16.840.1.113730.3.1.241", FriendlyName: "displayName", Values: []string{"Barbara Jensen"}},
+			{Name: "groups", Values: []string{"Everyone", "admins"}},
+		},
+		ReturnTo: "https://app.example.com/sso",
+	}
+	tenant := newSAMLTenant(&fakeServiceProvider{assertion: assertion}, samlTenantConfig())
+
+	identity, returnTo, err := tenant.FinishLogin(httptest.NewRecorder(), httptest.NewRequest("POST", "/acs", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "https://app.example.com/sso", returnTo)
+	assert.Equal(t, &auth.Identity{
+		Provider: "saml:acme",
+		Subject:  "00u1abcd",
+		Username: "00u1abcd",
+		Email:    "bjensen@example.com",
+		Name:     "Barbara Jensen",
+		Groups:   []string{"Everyone", "admins"},
+		Roles:    []string{"admin"},
+	}, identity)
+}
+
+func TestSAMLTenant_FinishLogin_ConfiguredAttributes(t *testing.T) {
+	cfg := samlTenantConfig()
+	cfg.EmailAttribute = "upn"
+	cfg.NameAttribute = "cn"
+	cfg.GroupsAttribute = "roles"
+
+	identity, err := finishSAMLLogin(t, cfg, &saml.Assertion{
+		NameID: "bjensen",
+		Attributes: []saml.Attribute{
+			{Name: "email", Values: []string{"other@example.com"}},
+			{Name: "upn", Values: []string{"bjensen@example.com"}},
+			{Name: "cn", Values: []string{"Barbara"}},
+			{Name: "groups", Values: []string{"Admins"}},
+			{Name: "roles", Values: []string{"Staff"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "bjensen@example.com", identity.Email)
+	assert.Equal(t, "Barbara", identity.Name)
+	assert.Equal(t, []string{"Staff"}, identity.Groups)
+	assert.Equal(t, []string{"user"}, identity.Roles, "unmapped groups get the default role")
+}
+
+func TestSAMLTenant_FinishLogin_EmailNameID(t *testing.T) {
+	identity, err := finishSAMLLogin(t, samlTenantConfig(), &saml.Assertion{
+		NameID:       "BJensen@example.com",
+		NameIDFormat: "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "bjensen@example.com", identity.Email)
+	assert.Equal(t, "BJensen@example.com", identity.Name)
+
+	identity, err = finishSAMLLogin(t, samlTenantConfig(), &saml.Assertion{NameID: "bjensen@example.com"})
+	require.NoError(t, err)
+	assert.Empty(t, identity.Email, "only email NameIDs are taken as the email")
+}
+
+func TestSAMLTenant_FinishLogin_Refused(t *testing.T) {
+	_, err := finishSAMLLogin(t, samlTenantConfig(), &saml.Assertion{
+		NameID:     "mallory",
+		Attributes: []saml.Attribute{{Name: "email", Values: []string{"admin@globex.com"}}},
+	})
+	assert.ErrorIs(t, err, auth.ErrAccessDenied, "emails outside the tenant's domains are refused")
+
+	_, err = finishSAMLLogin(t, samlTenantConfig(), &saml.Assertion{
+		NameID:     "mallory",
+		Attributes: []saml.Attribute{{Name: "email", Values: []string{"admin@example.com.globex.com"}}},
+	})
+	assert.ErrorIs(t, err, auth.ErrAccessDenied)
+
+	cfg := samlTenantConfig()
+	cfg.DefaultRole = ""
+	_, err = finishSAMLLogin(t, cfg, &saml.Assertion{
+		NameID:     "bjensen",
+		Attributes: []saml.Attribute{{Name: "email", Values: []string{"bjensen@example.com"}}},
+	})
+	assert.ErrorIs(t, err, auth.ErrAccessDenied)
+
+	cfg = samlTenantConfig()
+	cfg.EmailDomains = nil
+	identity, err := finishSAMLLogin(t, cfg, &saml.Assertion{
+		NameID:     "bjensen",
+		Attributes: []saml.Attribute{{Name: "email", Values: []string{"bjensen@globex.com"}}},
+	})
+	require.NoError(t, err, "tenants without domains accept any email")
+	assert.Equal(t, "bjensen@globex.com", identity.Email)
+}
+
+func TestSAMLTenant_FinishLogin_InvalidResponse(t *testing.T) {
+	invalid := errors.Join(saml.ErrInvalidResponse, errors.New("signature mismatch"))
+	tenant := newSAMLTenant(&fakeServiceProvider{err: invalid}, samlTenantConfig())
+
+	_, _, err := tenant.FinishLogin(httptest.NewRecorder(), httptest.NewRequest("POST", "/acs", nil))
+	assert.ErrorIs(t, err, saml.ErrInvalidResponse)
+}
+
+func TestSAMLTenant_AllowsRedirect(t *testing.T) {
+	tenant := newSAMLTenant(&fakeServiceProvider{}, samlTenantConfig())
+
+	assert.True(t, tenant.AllowsRedirect("https://app.example.com/sso"))
+	assert.False(t, tenant.AllowsRedirect("https://app.example.com/sso/"))
+	assert.False(t, tenant.AllowsRedirect("https://evil.example.net/sso"))
+	assert.False(t, tenant.AllowsRedirect(""))
+}
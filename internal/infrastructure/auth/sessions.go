@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/pkg/cache"
+)
+
+// maxMemorySessions bounds the sessions a MemorySessionStore keeps; the
+// least recently used are dropped beyond it
+const maxMemorySessions = 100000
+
+// MemorySessionStore keeps server-side sessions in process memory. It is
+// the fallback when Redis is not configured: sessions are only valid on the
+// instance that created them and are lost on restart.
+type MemorySessionStore struct {
+	// mu keeps Touch from restoring a session Delete just removed
+	mu       sync.Mutex
+	sessions *cache.Cache[string, auth.ServerSession]
+}
+
+// NewMemorySessionStore creates an empty in-memory session store
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		sessions: cache.New[string, auth.ServerSession](cache.Options{
+			Name:       "sessions",
+			MaxEntries: maxMemorySessions,
+		}),
+	}
+}
+
+// Create stores a new session that expires after idle without use
+func (s *MemorySessionStore) Create(ctx context.Context, session *auth.ServerSession, idle time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions.Get(session.ID); ok {
+		return errors.New("session ID already exists")
+	}
+	s.sessions.SetWithTTL(session.ID, *session, idle)
+	return nil
+}
+
+// Touch returns the session with id and keeps it for idle more
+func (s *MemorySessionStore) Touch(ctx context.Context, id string, idle time.Duration) (*auth.ServerSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return nil, auth.ErrSessionNotFound
+	}
+	s.sessions.SetWithTTL(id, session, idle)
+	return &session, nil
+}
+
+// Delete removes the session with id
+func (s *MemorySessionStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions.Delete(id)
+	return nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/auth"
+)
+
+func TestMemorySessionStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemorySessionStore()
+	session := &auth.ServerSession{ID: "session_1", UserID: "user_1", Roles: []string{"user"}}
+
+	require.NoError(t, store.Create(ctx, session, time.Minute))
+	assert.Error(t, store.Create(ctx, session, time.Minute), "IDs are never reused")
+
+	got, err := store.Touch(ctx, "session_1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, session, got)
+
+	require.NoError(t, store.Delete(ctx, "session_1"))
+	_, err = store.Touch(ctx, "session_1", time.Minute)
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+	assert.NoError(t, store.Delete(ctx, "session_1"))
+}
+
+func TestMemorySessionStore_IdleTimeout(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemorySessionStore()
+	require.NoError(t, store.Create(ctx, &auth.ServerSession{ID: "idle"}, 20*time.Millisecond))
+	require.NoError(t, store.Create(ctx, &auth.ServerSession{ID: "active"}, 20*time.Millisecond))
+
+	// Touching keeps the active session past its first timeout
+	_, err := store.Touch(ctx, "active", time.Minute)
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = store.Touch(ctx, "idle", time.Minute)
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+	_, err = store.Touch(ctx, "active", time.Minute)
+	assert.NoError(t, err)
+}
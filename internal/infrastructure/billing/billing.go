@@ -0,0 +1,22 @@
+// Package billing adapts billing providers to the billing.Provider
+// interface.
+package billing
+
+import (
+	"fmt"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/billing"
+)
+
+// NewProvider creates the billing provider selected by configuration
+func NewProvider(cfg configs.BillingConfig) (billing.Provider, error) {
+	switch cfg.Provider {
+	case "stub":
+		return NewStubProvider(cfg.WebhookSecret), nil
+	case "stripe":
+		return NewStripeProvider(cfg.WebhookSecret, cfg.StripePrices), nil
+	default:
+		return nil, fmt.Errorf("unknown billing provider %q", cfg.Provider)
+	}
+}
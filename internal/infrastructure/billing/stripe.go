@@ -0,0 +1,177 @@
+package billing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"clean-architecture/internal/domain/billing"
+)
+
+const (
+	// StripeSignatureHeader carries the signature of Stripe webhooks
+	StripeSignatureHeader = "Stripe-Signature"
+	// stripeTolerance is how old a signature's timestamp may be, which
+	// bounds replays of captured webhooks
+	stripeTolerance = 5 * time.Minute
+)
+
+// stripeEventTypes maps the Stripe events of subscription changes to
+// billing event types
+var stripeEventTypes = map[string]string{
+	"customer.subscription.created": billing.EventSubscriptionCreated,
+	"customer.subscription.updated": billing.EventSubscriptionUpdated,
+	"customer.subscription.deleted": billing.EventSubscriptionDeleted,
+}
+
+// StripeProvider verifies and decodes Stripe webhooks. Only the
+// subscription events are processed: subscriptions are created in Stripe,
+// e.g. by Checkout, and their prices mapped to plans.
+type StripeProvider struct {
+	secret []byte
+	// prices maps Stripe price IDs to plan IDs
+	prices map[string]string
+	now    func() time.Time
+}
+
+// NewStripeProvider creates a Stripe provider verifying webhooks with the
+// whsec_ signing secret of their endpoint
+func NewStripeProvider(secret string, prices map[string]string) *StripeProvider {
+	return &StripeProvider{
+		secret: []byte(secret),
+		prices: prices,
+		now:    time.Now,
+	}
+}
+
+// stripeEvent is the part of Stripe events this provider reads
+type stripeEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object stripeSubscription `json:"object"`
+	} `json:"data"`
+}
+
+type stripeSubscription struct {
+	ID       string `json:"id"`
+	Customer string `json:"customer"`
+	Status   string `json:"status"`
+	// CurrentPeriodEnd is set on subscriptions by API versions before
+	// 2025-03-31 and on their items since
+	CurrentPeriodEnd int64 `json:"current_period_end"`
+	Items            struct {
+		Data []struct {
+			CurrentPeriodEnd int64 `json:"current_period_end"`
+			Price            struct {
+				ID string `json:"id"`
+			} `json:"price"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// Name implements billing.Provider
+func (p *StripeProvider) Name() string {
+	return "stripe"
+}
+
+// SignatureHeader implements billing.Provider
+func (p *StripeProvider) SignatureHeader() string {
+	return StripeSignatureHeader
+}
+
+// ParseEvent implements billing.Provider. The subscription's plan is that
+// of its first item's price; prices missing from the mapping leave it
+// empty.
+func (p *StripeProvider) ParseEvent(payload []byte, signature string) (*billing.Event, error) {
+	if err := p.verify(payload, signature); err != nil {
+		return nil, err
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode Stripe event: %w", err)
+	}
+	eventType, ok := stripeEventTypes[event.Type]
+	if !ok {
+		return nil, billing.ErrIgnoredEvent
+	}
+
+	sub := event.Data.Object
+	if sub.ID == "" {
+		return nil, fmt.Errorf("stripe event %s without a subscription", event.ID)
+	}
+	periodEnd := sub.CurrentPeriodEnd
+	var planID string
+	if len(sub.Items.Data) > 0 {
+		item := sub.Items.Data[0]
+		planID = p.prices[item.Price.ID]
+		if periodEnd == 0 {
+			periodEnd = item.CurrentPeriodEnd
+		}
+	}
+
+	result := &billing.Event{
+		ID:         event.ID,
+		Type:       eventType,
+		OccurredAt: time.Unix(event.Created, 0),
+		Subscription: billing.Subscription{
+			ID:         sub.ID,
+			CustomerID: sub.Customer,
+			PlanID:     planID,
+			Status:     sub.Status,
+		},
+	}
+	if periodEnd > 0 {
+		result.Subscription.CurrentPeriodEnd = time.Unix(periodEnd, 0)
+	}
+	return result, nil
+}
+
+// verify checks the Stripe-Signature header of payload: an HMAC-SHA256 of
+// "<timestamp>.<payload>" in one of its v1 entries, signed within the
+// tolerance
+func (p *StripeProvider) verify(payload []byte, header string) error {
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			if signature, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return billing.ErrInvalidSignature
+	}
+	if age := p.now().Sub(time.Unix(unix, 0)); age > stripeTolerance || age < -stripeTolerance {
+		return billing.ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+	return billing.ErrInvalidSignature
+}
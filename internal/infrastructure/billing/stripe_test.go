@@ -0,0 +1,96 @@
+package billing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/billing"
+)
+
+const stripePayload = `{
+	"id": "evt_1",
+	"object": "event",
+	"type": "customer.subscription.updated",
+	"created": 1704067200,
+	"data": {"object": {
+		"id": "sub_1",
+		"object": "subscription",
+		"customer": "cus_1",
+		"status": "past_due",
+		"items": {"data": [{"current_period_end": 1706745600, "price": {"id": "price_pro"}}]}
+	}}
+}`
+
+// stripeSignature returns a Stripe-Signature header for payload signed
+// with secret at t
+func stripeSignature(secret, payload string, t time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%s", t.Unix(), payload)
+	return fmt.Sprintf("t=%d,v1=%s,v0=ignored", t.Unix(), hex.EncodeToString(mac.Sum(nil)))
+}
+
+func newTestStripeProvider(now time.Time) *StripeProvider {
+	provider := NewStripeProvider("whsec_test", map[string]string{"price_pro": "pro"})
+	provider.now = func() time.Time { return now }
+	return provider
+}
+
+func TestStripeProvider_ParseEvent(t *testing.T) {
+	now := time.Unix(1704067230, 0)
+	provider := newTestStripeProvider(now)
+
+	event, err := provider.ParseEvent([]byte(stripePayload), stripeSignature("whsec_test", stripePayload, now))
+	require.NoError(t, err)
+	assert.Equal(t, "evt_1", event.ID)
+	assert.Equal(t, billing.EventSubscriptionUpdated, event.Type)
+	assert.Equal(t, time.Unix(1704067200, 0), event.OccurredAt)
+	assert.Equal(t, billing.Subscription{
+		ID:               "sub_1",
+		CustomerID:       "cus_1",
+		PlanID:           "pro",
+		Status:           "past_due",
+		CurrentPeriodEnd: time.Unix(1706745600, 0),
+	}, event.Subscription)
+}
+
+func TestStripeProvider_ParseEvent_Rejected(t *testing.T) {
+	now := time.Unix(1704067230, 0)
+	provider := newTestStripeProvider(now)
+
+	tests := []struct {
+		name      string
+		signature string
+	}{
+		{"wrong secret", stripeSignature("whsec_other", stripePayload, now)},
+		{"too old", stripeSignature("whsec_test", stripePayload, now.Add(-10*time.Minute))},
+		{"no v1 entry", fmt.Sprintf("t=%d", now.Unix())},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.ParseEvent([]byte(stripePayload), tt.signature)
+			assert.ErrorIs(t, err, billing.ErrInvalidSignature)
+		})
+	}
+
+	invoice := `{"id": "evt_2", "type": "invoice.paid", "created": 1704067200, "data": {"object": {"id": "in_1"}}}`
+	_, err := provider.ParseEvent([]byte(invoice), stripeSignature("whsec_test", invoice, now))
+	assert.ErrorIs(t, err, billing.ErrIgnoredEvent)
+}
+
+func TestStripeProvider_ParseEvent_UnmappedPrice(t *testing.T) {
+	now := time.Unix(1704067230, 0)
+	provider := NewStripeProvider("whsec_test", map[string]string{})
+	provider.now = func() time.Time { return now }
+
+	event, err := provider.ParseEvent([]byte(stripePayload), stripeSignature("whsec_test", stripePayload, now))
+	require.NoError(t, err)
+	assert.Empty(t, event.Subscription.PlanID)
+}
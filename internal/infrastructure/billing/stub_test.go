@@ -0,0 +1,44 @@
+package billing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/billing"
+)
+
+const stubPayload = `{"id": "evt_1", "type": "subscription.updated", "created_at": "2024-01-01T00:00:00Z",
+	"subscription": {"id": "sub_1", "customer_id": "cus_1", "plan": "pro", "status": "active", "current_period_end": "2024-02-01T00:00:00Z"}}`
+
+func TestStubProvider_ParseEvent(t *testing.T) {
+	provider := NewStubProvider("secret")
+
+	event, err := provider.ParseEvent([]byte(stubPayload), provider.Sign([]byte(stubPayload)))
+	require.NoError(t, err)
+	assert.Equal(t, "evt_1", event.ID)
+	assert.Equal(t, billing.EventSubscriptionUpdated, event.Type)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), event.OccurredAt)
+	assert.Equal(t, billing.Subscription{
+		ID:               "sub_1",
+		CustomerID:       "cus_1",
+		PlanID:           "pro",
+		Status:           "active",
+		CurrentPeriodEnd: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}, event.Subscription)
+}
+
+func TestStubProvider_ParseEvent_Rejected(t *testing.T) {
+	provider := NewStubProvider("secret")
+
+	_, err := provider.ParseEvent([]byte(stubPayload), NewStubProvider("other").Sign([]byte(stubPayload)))
+	assert.ErrorIs(t, err, billing.ErrInvalidSignature)
+	_, err = provider.ParseEvent([]byte(stubPayload), "")
+	assert.ErrorIs(t, err, billing.ErrInvalidSignature)
+
+	invoice := []byte(`{"id": "evt_2", "type": "invoice.paid"}`)
+	_, err = provider.ParseEvent(invoice, provider.Sign(invoice))
+	assert.ErrorIs(t, err, billing.ErrIgnoredEvent)
+}
@@ -10,7 +10,11 @@ import (
 	"gorm.io/gorm"
 )
 
-var db *gorm.DB
+var (
+	db *gorm.DB
+	// replica is nil unless DATABASE_REPLICA_DSN is set
+	replica *gorm.DB
+)
 
 // InitDatabase initializes the PostgreSQL database connection
 func InitDatabase(cfg *configs.Config) error {
@@ -23,6 +27,9 @@ func InitDatabase(cfg *configs.Config) error {
 		SSLMode:  cfg.Database.SSLMode,
 	}
 	dsn := postgres.BuildDSN(opts)
+	if cfg.Database.DSN != "" {
+		dsn = cfg.Database.DSN
+	}
 
 	config := postgres.Config{
 		DSN:             dsn,
@@ -40,6 +47,16 @@ func InitDatabase(cfg *configs.Config) error {
 
 	log.Println("Database connection established successfully")
 
+	if cfg.Database.ReplicaDSN != "" {
+		replicaConfig := config
+		replicaConfig.DSN = cfg.Database.ReplicaDSN
+		replica, err = postgres.New(replicaConfig)
+		if err != nil {
+			return err
+		}
+		log.Println("Database replica connection established successfully")
+	}
+
 	// Run migrations
 	if err := MigrateDatabase(); err != nil {
 		return err
@@ -53,8 +70,18 @@ func GetDB() *gorm.DB {
 	return db
 }
 
-// CloseDatabase closes the database connection
+// GetReplicaDB returns the read replica, or nil when none is configured
+func GetReplicaDB() *gorm.DB {
+	return replica
+}
+
+// CloseDatabase closes the database connections
 func CloseDatabase() error {
+	if replica != nil {
+		if err := postgres.Close(replica); err != nil {
+			return err
+		}
+	}
 	if db != nil {
 		return postgres.Close(db)
 	}
@@ -67,11 +94,28 @@ func MigrateDatabase() error {
 		return nil
 	}
 
+	if err := dropLegacyEmailIndex(db); err != nil {
+		return err
+	}
+
 	// Run migrations for all entities
-	if err := db.AutoMigrate(&entities.User{}); err != nil {
+	if err := db.AutoMigrate(&entities.User{}, &entities.DeadLetter{}, &entities.PublishedEvent{}, &entities.UserDeletion{}, &entities.Job{}, &entities.Upload{}, &entities.SavedView{}, &entities.APIKey{}, &entities.Quota{}, &entities.Subscription{}, &entities.Organization{}, &entities.EmailChange{}, &entities.UsageRecord{}, &entities.AuditEntry{}, &entities.AuditAnchor{}, &entities.AuditCheckpoint{}, &entities.FeatureFlag{}, &entities.Webhook{}, &entities.WebhookDelivery{}, &entities.EmailSuppression{}, &ProcessedMessage{}); err != nil {
 		return err
 	}
 
 	log.Println("Database migrations completed successfully")
 	return nil
 }
+
+// legacyEmailIndex is the unique index on users.email that also covered
+// soft-deleted users. AutoMigrate creates its partial replacement but only
+// adds indexes, so the old one is dropped first.
+const legacyEmailIndex = "idx_users_email"
+
+func dropLegacyEmailIndex(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&entities.User{}) || !migrator.HasIndex(&entities.User{}, legacyEmailIndex) {
+		return nil
+	}
+	return migrator.DropIndex(&entities.User{}, legacyEmailIndex)
+}
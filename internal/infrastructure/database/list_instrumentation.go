@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// QueryPlan summarizes the planner's estimate for a query
+type QueryPlan struct {
+	// RowsScanned is the number of rows the scan nodes of the plan are
+	// expected to read
+	RowsScanned float64
+	// Cost is the planner's total cost, in its arbitrary units
+	Cost float64
+}
+
+// ListQuery describes a list query: a SELECT into a slice with a LIMIT
+type ListQuery struct {
+	Table string
+	// Background is set for queries made outside an HTTP request, such as
+	// the pages read by exports
+	Background bool
+	Limit      int
+	Offset     int
+	// Plan is nil unless the query was sampled for EXPLAIN
+	Plan *QueryPlan
+}
+
+// ListObserver receives the list queries run through ListInstrumentation
+type ListObserver interface {
+	ObserveList(query ListQuery)
+}
+
+// ListInstrumentation is a gorm plugin reporting the pagination of every list
+// query to an observer. A sample of them is run through EXPLAIN, which only
+// plans the query, to estimate how many rows it scans.
+type ListInstrumentation struct {
+	observer   ListObserver
+	sampleRate float64
+	logger     logger.Logger
+
+	// sample and explain are replaced in tests
+	sample  func() float64
+	explain func(db *gorm.DB) ([]byte, error)
+}
+
+// NewListInstrumentation creates the plugin. sampleRate is the fraction of
+// list queries explained; 0 disables EXPLAIN.
+func NewListInstrumentation(observer ListObserver, sampleRate float64, logger logger.Logger) *ListInstrumentation {
+	return &ListInstrumentation{
+		observer:   observer,
+		sampleRate: sampleRate,
+		logger:     logger,
+		sample:     rand.Float64,
+		explain:    explainQuery,
+	}
+}
+
+// Name implements gorm.Plugin
+func (p *ListInstrumentation) Name() string {
+	return "list_instrumentation"
+}
+
+// Initialize implements gorm.Plugin
+func (p *ListInstrumentation) Initialize(db *gorm.DB) error {
+	return db.Callback().Query().After("gorm:query").Register("list_instrumentation:observe", p.observe)
+}
+
+// observe runs after each query and reports it when it is a list query
+func (p *ListInstrumentation) observe(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Table == "" || !intoSlice(stmt.Dest) {
+		return
+	}
+	limitClause, ok := stmt.Clauses["LIMIT"]
+	if !ok {
+		return
+	}
+	limit, ok := limitClause.Expression.(clause.Limit)
+	if !ok || limit.Limit == nil {
+		return
+	}
+
+	query := ListQuery{
+		Table:      stmt.Table,
+		Background: !hasRequestID(stmt.Context),
+		Limit:      *limit.Limit,
+		Offset:     limit.Offset,
+	}
+	if p.sampleRate > 0 && p.sample() < p.sampleRate {
+		plan, err := p.plan(db)
+		if err != nil {
+			p.logger.WithFields(map[string]interface{}{
+				"table": stmt.Table,
+				"error": err.Error(),
+			}).Debug("Failed to explain list query")
+		} else {
+			query.Plan = plan
+		}
+	}
+	p.observer.ObserveList(query)
+}
+
+func (p *ListInstrumentation) plan(db *gorm.DB) (*QueryPlan, error) {
+	output, err := p.explain(db)
+	if err != nil {
+		return nil, err
+	}
+	return parseQueryPlan(output)
+}
+
+// explainQuery plans the statement of db without running it. The pool is
+// used directly so the EXPLAIN does not pass through the callbacks again.
+func explainQuery(db *gorm.DB) ([]byte, error) {
+	var output []byte
+	err := db.Statement.ConnPool.
+		QueryRowContext(db.Statement.Context, "EXPLAIN (FORMAT JSON) "+db.Statement.SQL.String(), db.Statement.Vars...).
+		Scan(&output)
+	return output, err
+}
+
+// planNode is a node of PostgreSQL's JSON EXPLAIN output
+type planNode struct {
+	NodeType  string     `json:"Node Type"`
+	TotalCost float64    `json:"Total Cost"`
+	PlanRows  float64    `json:"Plan Rows"`
+	Plans     []planNode `json:"Plans"`
+}
+
+// parseQueryPlan summarizes the output of EXPLAIN (FORMAT JSON)
+func parseQueryPlan(output []byte) (*QueryPlan, error) {
+	var plans []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(output, &plans); err != nil {
+		return nil, fmt.Errorf("failed to parse query plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("failed to parse query plan: empty plan")
+	}
+
+	root := plans[0].Plan
+	return &QueryPlan{RowsScanned: scannedRows(root), Cost: root.TotalCost}, nil
+}
+
+// scannedRows sums the estimated rows of the scan nodes below node
+func scannedRows(node planNode) float64 {
+	var rows float64
+	if strings.HasSuffix(node.NodeType, "Scan") {
+		rows += node.PlanRows
+	}
+	for _, child := range node.Plans {
+		rows += scannedRows(child)
+	}
+	return rows
+}
+
+// intoSlice reports whether dest, a query destination, holds many rows
+func intoSlice(dest interface{}) bool {
+	if dest == nil {
+		return false
+	}
+	kind := reflect.Indirect(reflect.ValueOf(dest)).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+func hasRequestID(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	_, ok := ctxkeys.RequestID.From(ctx)
+	return ok
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// explainOutput is trimmed EXPLAIN (FORMAT JSON) output for a paginated,
+// filtered user listing
+const explainOutput = `[{"Plan": {"Node Type": "Limit", "Startup Cost": 12.5, "Total Cost": 48.25, "Plan Rows": 20, "Plans": [
+	{"Node Type": "Sort", "Total Cost": 47.1, "Plan Rows": 640, "Plans": [
+		{"Node Type": "Seq Scan", "Relation Name": "users", "Total Cost": 31.4, "Plan Rows": 640}
+	]}
+]}}]`
+
+type recordingListObserver struct {
+	queries []ListQuery
+}
+
+func (o *recordingListObserver) ObserveList(query ListQuery) {
+	o.queries = append(o.queries, query)
+}
+
+func TestListInstrumentation(t *testing.T) {
+	observer := &recordingListObserver{}
+	plugin := NewListInstrumentation(observer, 0.5, logger.New())
+	explained := 0
+	plugin.explain = func(db *gorm.DB) ([]byte, error) {
+		explained++
+		return []byte(explainOutput), nil
+	}
+	samples := []float64{0.1, 0.9}
+	plugin.sample = func() float64 {
+		sample := samples[0]
+		samples = samples[1:]
+		return sample
+	}
+
+	db := dryRunDB(t)
+	require.NoError(t, db.Use(plugin))
+	repo := NewPostgresUserRepository(db)
+	requestCtx := ctxkeys.RequestID.With(context.Background(), "req_1")
+
+	_, err := repo.Find(requestCtx, repositories.Specification{Limit: 20, Offset: 2000})
+	require.NoError(t, err)
+	_, err = repo.List(context.Background(), 500, 1000)
+	require.NoError(t, err)
+
+	// Single rows and counts are not list queries
+	_, _ = repo.GetByID(requestCtx, "user_1")
+	_, err = repo.Count(requestCtx)
+	require.NoError(t, err)
+
+	require.Len(t, observer.queries, 2)
+	assert.Equal(t, ListQuery{
+		Table:  "users",
+		Limit:  20,
+		Offset: 2000,
+		Plan:   &QueryPlan{RowsScanned: 640, Cost: 48.25},
+	}, observer.queries[0])
+	assert.Equal(t, ListQuery{Table: "users", Background: true, Limit: 500, Offset: 1000}, observer.queries[1])
+	assert.Equal(t, 1, explained, "only the sampled query is explained")
+}
+
+func TestListInstrumentation_ExplainFailure(t *testing.T) {
+	observer := &recordingListObserver{}
+	plugin := NewListInstrumentation(observer, 1, logger.New())
+	plugin.explain = func(db *gorm.DB) ([]byte, error) {
+		return nil, errors.New("permission denied")
+	}
+
+	db := dryRunDB(t)
+	require.NoError(t, db.Use(plugin))
+	_, err := NewPostgresUserRepository(db).List(context.Background(), 10, 0)
+	require.NoError(t, err)
+
+	require.Len(t, observer.queries, 1)
+	assert.Nil(t, observer.queries[0].Plan, "the query is still observed without its plan")
+}
+
+func TestParseQueryPlan(t *testing.T) {
+	plan, err := parseQueryPlan([]byte(explainOutput))
+	require.NoError(t, err)
+	assert.Equal(t, &QueryPlan{RowsScanned: 640, Cost: 48.25}, plan)
+
+	_, err = parseQueryPlan([]byte(`[]`))
+	assert.Error(t, err)
+	_, err = parseQueryPlan([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestIntoSlice(t *testing.T) {
+	assert.True(t, intoSlice(&[]*entities.User{}))
+	assert.False(t, intoSlice(&entities.User{}))
+	assert.False(t, intoSlice(nil))
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockAPIKeyRepository implements APIKeyRepository interface for testing
+type MockAPIKeyRepository struct {
+	keys  map[string]*entities.APIKey
+	mutex sync.RWMutex
+}
+
+// NewMockAPIKeyRepository creates a new mock API key repository
+func NewMockAPIKeyRepository() repositories.APIKeyRepository {
+	return &MockAPIKeyRepository{
+		keys: make(map[string]*entities.APIKey),
+	}
+}
+
+// Create stores a new API key
+func (r *MockAPIKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if key.ID == "" {
+		key.ID = fmt.Sprintf("key_%d", time.Now().UnixNano())
+	}
+	for _, existing := range r.keys {
+		if existing.Prefix == key.Prefix {
+			return fmt.Errorf("duplicate API key prefix %q", key.Prefix)
+		}
+	}
+	stored := *key
+	r.keys[key.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves an API key by ID
+func (r *MockAPIKeyRepository) GetByID(ctx context.Context, id string) (*entities.APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return nil, repositories.ErrAPIKeyNotFound
+	}
+
+	// Return a copy to avoid external modifications
+	result := *key
+	return &result, nil
+}
+
+// GetByPrefix retrieves an API key by its public prefix
+func (r *MockAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			result := *key
+			return &result, nil
+		}
+	}
+	return nil, repositories.ErrAPIKeyNotFound
+}
+
+// List retrieves API keys, newest first
+func (r *MockAPIKeyRepository) List(ctx context.Context, limit, offset int) ([]*entities.APIKey, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	keys := make([]*entities.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		copied := *key
+		keys = append(keys, &copied)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+
+	if offset >= len(keys) {
+		return nil, nil
+	}
+	keys = keys[offset:]
+	if limit > 0 && limit < len(keys) {
+		keys = keys[:limit]
+	}
+	return keys, nil
+}
+
+// ListByOwner retrieves the API keys of a service account, newest first
+func (r *MockAPIKeyRepository) ListByOwner(ctx context.Context, ownerID string) ([]*entities.APIKey, error) {
+	keys, err := r.List(ctx, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	owned := make([]*entities.APIKey, 0, len(keys))
+	for _, key := range keys {
+		if key.OwnerID == ownerID {
+			owned = append(owned, key)
+		}
+	}
+	return owned, nil
+}
+
+// Update saves changes to an API key
+func (r *MockAPIKeyRepository) Update(ctx context.Context, key *entities.APIKey) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.keys[key.ID]; !exists {
+		return repositories.ErrAPIKeyNotFound
+	}
+	stored := *key
+	r.keys[key.ID] = &stored
+	return nil
+}
+
+// TouchLastUsed sets the last use of an API key
+func (r *MockAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return repositories.ErrAPIKeyNotFound
+	}
+	key.LastUsedAt = &at
+	return nil
+}
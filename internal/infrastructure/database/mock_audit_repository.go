@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockAuditRepository implements AuditRepository interface for testing
+type MockAuditRepository struct {
+	entries     []*entities.AuditEntry
+	anchors     []*entities.AuditAnchor
+	checkpoints map[string]int64
+	mutex       sync.RWMutex
+}
+
+// NewMockAuditRepository creates a new mock audit repository
+func NewMockAuditRepository() repositories.AuditRepository {
+	return &MockAuditRepository{
+		checkpoints: make(map[string]int64),
+	}
+}
+
+// Append chains and records an entry unless its event was already recorded
+func (r *MockAuditRepository) Append(ctx context.Context, entry *entities.AuditEntry) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, existing := range r.entries {
+		if existing.EventID == entry.EventID {
+			return nil
+		}
+	}
+	var head *entities.AuditEntry
+	if len(r.entries) > 0 {
+		head = r.entries[len(r.entries)-1]
+	}
+	entry.Chain(head)
+	stored := *entry
+	r.entries = append(r.entries, &stored)
+	return nil
+}
+
+// ListAfter retrieves the entries following seq
+func (r *MockAuditRepository) ListAfter(ctx context.Context, seq int64, recordedBefore time.Time, limit int) ([]*entities.AuditEntry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var entries []*entities.AuditEntry
+	for _, entry := range r.entries {
+		if entry.Seq <= seq || !entry.RecordedAt.Before(recordedBefore) {
+			continue
+		}
+		if len(entries) == limit {
+			break
+		}
+		result := *entry
+		entries = append(entries, &result)
+	}
+	return entries, nil
+}
+
+// List retrieves the entries matching a filter, newest first
+func (r *MockAuditRepository) List(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var entries []*entities.AuditEntry
+	skipped := 0
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		entry := r.entries[i]
+		if (filter.Type != "" && entry.Type != filter.Type) ||
+			(filter.AggregateID != "" && entry.AggregateID != filter.AggregateID) ||
+			(filter.CorrelationID != "" && entry.CorrelationID != filter.CorrelationID) {
+			continue
+		}
+		if skipped < filter.Offset {
+			skipped++
+			continue
+		}
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+		result := *entry
+		entries = append(entries, &result)
+	}
+	return entries, nil
+}
+
+// GetHead retrieves the last entry
+func (r *MockAuditRepository) GetHead(ctx context.Context) (*entities.AuditEntry, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if len(r.entries) == 0 {
+		return nil, nil
+	}
+	head := *r.entries[len(r.entries)-1]
+	return &head, nil
+}
+
+// SaveAnchor records an anchor unless its entry was already anchored
+func (r *MockAuditRepository) SaveAnchor(ctx context.Context, anchor *entities.AuditAnchor) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, existing := range r.anchors {
+		if existing.Seq == anchor.Seq {
+			return nil
+		}
+	}
+	stored := *anchor
+	r.anchors = append(r.anchors, &stored)
+	return nil
+}
+
+// ListAnchors retrieves every anchor
+func (r *MockAuditRepository) ListAnchors(ctx context.Context) ([]*entities.AuditAnchor, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	anchors := make([]*entities.AuditAnchor, len(r.anchors))
+	for i, anchor := range r.anchors {
+		stored := *anchor
+		anchors[i] = &stored
+	}
+	return anchors, nil
+}
+
+// GetCheckpoint retrieves the checkpoint of a sink
+func (r *MockAuditRepository) GetCheckpoint(ctx context.Context, sink string) (int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.checkpoints[sink], nil
+}
+
+// SaveCheckpoint creates or replaces the checkpoint of a sink
+func (r *MockAuditRepository) SaveCheckpoint(ctx context.Context, checkpoint *entities.AuditCheckpoint) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.checkpoints[checkpoint.Sink] = checkpoint.Seq
+	return nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockDeadLetterRepository implements DeadLetterRepository interface for testing
+type MockDeadLetterRepository struct {
+	deadLetters map[string]*entities.DeadLetter
+	mutex       sync.RWMutex
+}
+
+// NewMockDeadLetterRepository creates a new mock dead letter repository
+func NewMockDeadLetterRepository() repositories.DeadLetterRepository {
+	return &MockDeadLetterRepository{
+		deadLetters: make(map[string]*entities.DeadLetter),
+	}
+}
+
+// Create stores a new dead letter
+func (r *MockDeadLetterRepository) Create(ctx context.Context, deadLetter *entities.DeadLetter) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if deadLetter.ID == "" {
+		deadLetter.ID = fmt.Sprintf("dlq_%d", time.Now().UnixNano())
+	}
+	stored := *deadLetter
+	r.deadLetters[deadLetter.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a dead letter by ID
+func (r *MockDeadLetterRepository) GetByID(ctx context.Context, id string) (*entities.DeadLetter, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	deadLetter, exists := r.deadLetters[id]
+	if !exists {
+		return nil, repositories.ErrDeadLetterNotFound
+	}
+
+	// Return a copy to avoid external modifications
+	result := *deadLetter
+	return &result, nil
+}
+
+// List retrieves dead letters matching filter, most recent failures first
+func (r *MockDeadLetterRepository) List(ctx context.Context, filter repositories.DeadLetterFilter) ([]*entities.DeadLetter, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var matched []*entities.DeadLetter
+	for _, deadLetter := range r.deadLetters {
+		if filter.Kind != "" && deadLetter.Kind != filter.Kind {
+			continue
+		}
+		if filter.Topic != "" && deadLetter.Topic != filter.Topic {
+			continue
+		}
+		if filter.ConsumerGroup != "" && deadLetter.ConsumerGroup != filter.ConsumerGroup {
+			continue
+		}
+		if filter.CorrelationID != "" && deadLetter.CorrelationID != filter.CorrelationID {
+			continue
+		}
+		if filter.Pending && deadLetter.ReplayedAt != nil {
+			continue
+		}
+		result := *deadLetter
+		matched = append(matched, &result)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].FailedAt.After(matched[j].FailedAt)
+	})
+
+	if filter.Offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, nil
+}
+
+// Update saves changes to a dead letter
+func (r *MockDeadLetterRepository) Update(ctx context.Context, deadLetter *entities.DeadLetter) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.deadLetters[deadLetter.ID]; !exists {
+		return repositories.ErrDeadLetterNotFound
+	}
+	stored := *deadLetter
+	r.deadLetters[deadLetter.ID] = &stored
+	return nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockEmailChangeRepository implements EmailChangeRepository interface for testing
+type MockEmailChangeRepository struct {
+	changes map[string]*entities.EmailChange
+	mutex   sync.RWMutex
+}
+
+// NewMockEmailChangeRepository creates a new mock email change repository
+func NewMockEmailChangeRepository() repositories.EmailChangeRepository {
+	return &MockEmailChangeRepository{
+		changes: make(map[string]*entities.EmailChange),
+	}
+}
+
+// Create stores a new email change
+func (r *MockEmailChangeRepository) Create(ctx context.Context, change *entities.EmailChange) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if change.ID == "" {
+		change.ID = fmt.Sprintf("emc_%d", time.Now().UnixNano())
+	}
+	stored := *change
+	r.changes[change.ID] = &stored
+	return nil
+}
+
+// GetPendingByUserID retrieves the email change of a user awaiting
+// confirmation
+func (r *MockEmailChangeRepository) GetPendingByUserID(ctx context.Context, userID string) (*entities.EmailChange, error) {
+	return r.findPending(func(c *entities.EmailChange) bool { return c.UserID == userID })
+}
+
+// GetPendingByTokenHash retrieves the email change awaiting confirmation
+// a confirmation token was issued for
+func (r *MockEmailChangeRepository) GetPendingByTokenHash(ctx context.Context, tokenHash string) (*entities.EmailChange, error) {
+	return r.findPending(func(c *entities.EmailChange) bool { return c.TokenHash == tokenHash })
+}
+
+// Update saves changes to an email change
+func (r *MockEmailChangeRepository) Update(ctx context.Context, change *entities.EmailChange) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.changes[change.ID]; !exists {
+		return repositories.ErrEmailChangeNotFound
+	}
+	stored := *change
+	r.changes[change.ID] = &stored
+	return nil
+}
+
+// findPending returns a copy of the latest pending change accepted by match
+func (r *MockEmailChangeRepository) findPending(match func(*entities.EmailChange) bool) (*entities.EmailChange, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var latest *entities.EmailChange
+	for _, change := range r.changes {
+		if !change.Pending() || !match(change) {
+			continue
+		}
+		if latest == nil || change.RequestedAt.After(latest.RequestedAt) {
+			latest = change
+		}
+	}
+	if latest == nil {
+		return nil, repositories.ErrEmailChangeNotFound
+	}
+	result := *latest
+	return &result, nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockEmailSuppressionRepository implements EmailSuppressionRepository
+// interface for testing
+type MockEmailSuppressionRepository struct {
+	suppressions map[string]*entities.EmailSuppression
+	mutex        sync.RWMutex
+}
+
+// NewMockEmailSuppressionRepository creates a new mock email suppression
+// repository
+func NewMockEmailSuppressionRepository() repositories.EmailSuppressionRepository {
+	return &MockEmailSuppressionRepository{
+		suppressions: make(map[string]*entities.EmailSuppression),
+	}
+}
+
+// Save creates or replaces the suppression of an address
+func (r *MockEmailSuppressionRepository) Save(ctx context.Context, suppression *entities.EmailSuppression) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *suppression
+	if existing, exists := r.suppressions[suppression.Email]; exists {
+		stored.CreatedAt = existing.CreatedAt
+	}
+	r.suppressions[suppression.Email] = &stored
+	return nil
+}
+
+// Get retrieves the suppression of an address
+func (r *MockEmailSuppressionRepository) Get(ctx context.Context, email string) (*entities.EmailSuppression, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	suppression, exists := r.suppressions[email]
+	if !exists {
+		return nil, repositories.ErrEmailSuppressionNotFound
+	}
+	result := *suppression
+	return &result, nil
+}
+
+// GetMany retrieves the suppressions of several addresses
+func (r *MockEmailSuppressionRepository) GetMany(ctx context.Context, emails []string) (map[string]*entities.EmailSuppression, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	result := make(map[string]*entities.EmailSuppression)
+	for _, email := range emails {
+		if suppression, exists := r.suppressions[email]; exists {
+			copied := *suppression
+			result[email] = &copied
+		}
+	}
+	return result, nil
+}
+
+// List retrieves suppressions, most recently updated first
+func (r *MockEmailSuppressionRepository) List(ctx context.Context, limit, offset int) ([]*entities.EmailSuppression, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	suppressions := make([]*entities.EmailSuppression, 0, len(r.suppressions))
+	for _, suppression := range r.suppressions {
+		copied := *suppression
+		suppressions = append(suppressions, &copied)
+	}
+	sort.Slice(suppressions, func(i, j int) bool {
+		if !suppressions[i].UpdatedAt.Equal(suppressions[j].UpdatedAt) {
+			return suppressions[i].UpdatedAt.After(suppressions[j].UpdatedAt)
+		}
+		return suppressions[i].Email < suppressions[j].Email
+	})
+	if offset >= len(suppressions) {
+		return nil, nil
+	}
+	suppressions = suppressions[offset:]
+	if limit > 0 && len(suppressions) > limit {
+		suppressions = suppressions[:limit]
+	}
+	return suppressions, nil
+}
+
+// Delete lifts the suppression of an address
+func (r *MockEmailSuppressionRepository) Delete(ctx context.Context, email string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.suppressions[email]; !exists {
+		return repositories.ErrEmailSuppressionNotFound
+	}
+	delete(r.suppressions, email)
+	return nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockFeatureFlagRepository implements FeatureFlagRepository interface for
+// testing
+type MockFeatureFlagRepository struct {
+	flags map[string]*entities.FeatureFlag
+	mutex sync.RWMutex
+}
+
+// NewMockFeatureFlagRepository creates a new mock feature flag repository
+func NewMockFeatureFlagRepository() repositories.FeatureFlagRepository {
+	return &MockFeatureFlagRepository{
+		flags: make(map[string]*entities.FeatureFlag),
+	}
+}
+
+// Get retrieves the state set on a flag
+func (r *MockFeatureFlagRepository) Get(ctx context.Context, key string) (*entities.FeatureFlag, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	flag, exists := r.flags[key]
+	if !exists {
+		return nil, repositories.ErrFeatureFlagNotFound
+	}
+	result := *flag
+	return &result, nil
+}
+
+// List retrieves every flag state that was set
+func (r *MockFeatureFlagRepository) List(ctx context.Context) ([]*entities.FeatureFlag, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	flags := make([]*entities.FeatureFlag, 0, len(r.flags))
+	for _, flag := range r.flags {
+		result := *flag
+		flags = append(flags, &result)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags, nil
+}
+
+// Save creates or replaces the state of a flag
+func (r *MockFeatureFlagRepository) Save(ctx context.Context, flag *entities.FeatureFlag) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *flag
+	r.flags[flag.Key] = &stored
+	return nil
+}
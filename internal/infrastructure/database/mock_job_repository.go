@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockJobRepository implements JobRepository interface for testing
+type MockJobRepository struct {
+	jobs  map[string]*entities.Job
+	mutex sync.RWMutex
+}
+
+// NewMockJobRepository creates a new mock job repository
+func NewMockJobRepository() repositories.JobRepository {
+	return &MockJobRepository{
+		jobs: make(map[string]*entities.Job),
+	}
+}
+
+// Create stores a new job
+func (r *MockJobRepository) Create(ctx context.Context, job *entities.Job) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if job.ID == "" {
+		job.ID = fmt.Sprintf("job_%d", time.Now().UnixNano())
+	}
+	r.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+// GetByID retrieves a job by ID
+func (r *MockJobRepository) GetByID(ctx context.Context, id string) (*entities.Job, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return nil, repositories.ErrJobNotFound
+	}
+
+	// Return a copy to avoid external modifications
+	return copyJob(job), nil
+}
+
+// Update saves changes to a job
+func (r *MockJobRepository) Update(ctx context.Context, job *entities.Job) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.jobs[job.ID]; !exists {
+		return repositories.ErrJobNotFound
+	}
+	r.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+// ListExpired retrieves succeeded jobs whose results expired before now
+func (r *MockJobRepository) ListExpired(ctx context.Context, jobType string, now time.Time, limit int) ([]*entities.Job, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var matched []*entities.Job
+	for _, job := range r.jobs {
+		if job.Type != jobType || job.Status != entities.JobSucceeded || job.ExpiresAt == nil || job.ExpiresAt.After(now) {
+			continue
+		}
+		matched = append(matched, copyJob(job))
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].ExpiresAt.Before(*matched[j].ExpiresAt)
+	})
+
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+// copyJob copies a job including its maps
+func copyJob(job *entities.Job) *entities.Job {
+	result := *job
+	if job.Params != nil {
+		result.Params = make(map[string]string, len(job.Params))
+		for k, v := range job.Params {
+			result.Params[k] = v
+		}
+	}
+	if job.Result != nil {
+		result.Result = make(map[string]string, len(job.Result))
+		for k, v := range job.Result {
+			result.Result[k] = v
+		}
+	}
+	return &result
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockOrganizationRepository implements OrganizationRepository interface
+// for testing
+type MockOrganizationRepository struct {
+	orgs  map[string]*entities.Organization
+	mutex sync.RWMutex
+}
+
+// NewMockOrganizationRepository creates a new mock organization repository
+func NewMockOrganizationRepository() repositories.OrganizationRepository {
+	return &MockOrganizationRepository{
+		orgs: make(map[string]*entities.Organization),
+	}
+}
+
+// Create stores a new organization
+func (r *MockOrganizationRepository) Create(ctx context.Context, org *entities.Organization) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if org.ID == "" {
+		org.ID = fmt.Sprintf("org_%d", time.Now().UnixNano())
+	}
+	stored := *org
+	r.orgs[org.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves an organization by ID
+func (r *MockOrganizationRepository) GetByID(ctx context.Context, id string) (*entities.Organization, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	org, exists := r.orgs[id]
+	if !exists {
+		return nil, repositories.ErrOrganizationNotFound
+	}
+	result := *org
+	return &result, nil
+}
+
+// List retrieves organizations, oldest first
+func (r *MockOrganizationRepository) List(ctx context.Context, limit, offset int) ([]*entities.Organization, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	orgs := make([]*entities.Organization, 0, len(r.orgs))
+	for _, org := range r.orgs {
+		copied := *org
+		orgs = append(orgs, &copied)
+	}
+	sort.Slice(orgs, func(i, j int) bool {
+		if !orgs[i].CreatedAt.Equal(orgs[j].CreatedAt) {
+			return orgs[i].CreatedAt.Before(orgs[j].CreatedAt)
+		}
+		return orgs[i].ID < orgs[j].ID
+	})
+
+	if offset >= len(orgs) {
+		return nil, nil
+	}
+	orgs = orgs[offset:]
+	if limit > 0 && limit < len(orgs) {
+		orgs = orgs[:limit]
+	}
+	return orgs, nil
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockPublishedEventRepository implements PublishedEventRepository
+// interface for testing
+type MockPublishedEventRepository struct {
+	events map[string]*entities.PublishedEvent
+	mutex  sync.RWMutex
+}
+
+// NewMockPublishedEventRepository creates a new mock published event
+// repository
+func NewMockPublishedEventRepository() repositories.PublishedEventRepository {
+	return &MockPublishedEventRepository{
+		events: make(map[string]*entities.PublishedEvent),
+	}
+}
+
+// Create records a published event
+func (r *MockPublishedEventRepository) Create(ctx context.Context, event *entities.PublishedEvent) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *event
+	r.events[event.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a published event by ID
+func (r *MockPublishedEventRepository) GetByID(ctx context.Context, id string) (*entities.PublishedEvent, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	event, exists := r.events[id]
+	if !exists {
+		return nil, repositories.ErrPublishedEventNotFound
+	}
+
+	// Return a copy to avoid external modifications
+	result := *event
+	return &result, nil
+}
+
+// List retrieves published events matching filter, oldest first
+func (r *MockPublishedEventRepository) List(ctx context.Context, filter repositories.PublishedEventFilter) ([]*entities.PublishedEvent, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var matched []*entities.PublishedEvent
+	for _, event := range r.events {
+		if filter.Type != "" && event.Type != filter.Type {
+			continue
+		}
+		if filter.AggregateID != "" && event.AggregateID != filter.AggregateID {
+			continue
+		}
+		if filter.CorrelationID != "" && event.CorrelationID != filter.CorrelationID {
+			continue
+		}
+		if !filter.Since.IsZero() && event.PublishedAt.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !event.PublishedAt.Before(filter.Until) {
+			continue
+		}
+		if filter.Failed && event.PublishError == "" {
+			continue
+		}
+		result := *event
+		matched = append(matched, &result)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].PublishedAt.Equal(matched[j].PublishedAt) {
+			return matched[i].PublishedAt.Before(matched[j].PublishedAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	if filter.Offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, nil
+}
+
+// Update saves changes to a published event
+func (r *MockPublishedEventRepository) Update(ctx context.Context, event *entities.PublishedEvent) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.events[event.ID]; !exists {
+		return repositories.ErrPublishedEventNotFound
+	}
+	stored := *event
+	r.events[event.ID] = &stored
+	return nil
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockQuotaRepository implements QuotaRepository interface for testing
+type MockQuotaRepository struct {
+	quotas map[string]*entities.Quota
+	mutex  sync.RWMutex
+}
+
+// NewMockQuotaRepository creates a new mock quota repository
+func NewMockQuotaRepository() repositories.QuotaRepository {
+	return &MockQuotaRepository{
+		quotas: make(map[string]*entities.Quota),
+	}
+}
+
+// Get retrieves the quota set on a resource
+func (r *MockQuotaRepository) Get(ctx context.Context, resource string) (*entities.Quota, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	quota, exists := r.quotas[resource]
+	if !exists {
+		return nil, repositories.ErrQuotaNotFound
+	}
+	result := *quota
+	return &result, nil
+}
+
+// Save creates or replaces the quota on a resource
+func (r *MockQuotaRepository) Save(ctx context.Context, quota *entities.Quota) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *quota
+	r.quotas[quota.Resource] = &stored
+	return nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockSavedViewRepository implements SavedViewRepository interface for testing
+type MockSavedViewRepository struct {
+	views map[string]*entities.SavedView
+	mutex sync.RWMutex
+}
+
+// NewMockSavedViewRepository creates a new mock saved view repository
+func NewMockSavedViewRepository() repositories.SavedViewRepository {
+	return &MockSavedViewRepository{
+		views: make(map[string]*entities.SavedView),
+	}
+}
+
+// Create stores a new saved view
+func (r *MockSavedViewRepository) Create(ctx context.Context, view *entities.SavedView) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if view.ID == "" {
+		view.ID = fmt.Sprintf("view_%d", time.Now().UnixNano())
+	}
+	stored := *view
+	r.views[view.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a saved view by ID
+func (r *MockSavedViewRepository) GetByID(ctx context.Context, id string) (*entities.SavedView, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	view, exists := r.views[id]
+	if !exists {
+		return nil, repositories.ErrSavedViewNotFound
+	}
+
+	// Return a copy to avoid external modifications
+	result := *view
+	return &result, nil
+}
+
+// ListVisible retrieves the views of an owner and every shared view
+func (r *MockSavedViewRepository) ListVisible(ctx context.Context, ownerID string) ([]*entities.SavedView, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var views []*entities.SavedView
+	for _, view := range r.views {
+		if view.OwnerID == ownerID || view.Shared {
+			copied := *view
+			views = append(views, &copied)
+		}
+	}
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Name != views[j].Name {
+			return views[i].Name < views[j].Name
+		}
+		return views[i].ID < views[j].ID
+	})
+	return views, nil
+}
+
+// Delete removes a saved view
+func (r *MockSavedViewRepository) Delete(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.views[id]; !exists {
+		return repositories.ErrSavedViewNotFound
+	}
+	delete(r.views, id)
+	return nil
+}
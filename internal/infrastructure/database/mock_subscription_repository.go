@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"sync"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockSubscriptionRepository implements SubscriptionRepository interface
+// for testing
+type MockSubscriptionRepository struct {
+	subscriptions map[string]*entities.Subscription
+	mutex         sync.RWMutex
+}
+
+// NewMockSubscriptionRepository creates a new mock subscription repository
+func NewMockSubscriptionRepository() repositories.SubscriptionRepository {
+	return &MockSubscriptionRepository{
+		subscriptions: make(map[string]*entities.Subscription),
+	}
+}
+
+// GetByID retrieves a subscription by ID
+func (r *MockSubscriptionRepository) GetByID(ctx context.Context, id string) (*entities.Subscription, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	subscription, exists := r.subscriptions[id]
+	if !exists {
+		return nil, repositories.ErrSubscriptionNotFound
+	}
+	result := *subscription
+	return &result, nil
+}
+
+// Save creates or replaces a subscription
+func (r *MockSubscriptionRepository) Save(ctx context.Context, subscription *entities.Subscription) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *subscription
+	r.subscriptions[subscription.ID] = &stored
+	return nil
+}
+
+// Current retrieves the most recently updated subscription granting its
+// plan
+func (r *MockSubscriptionRepository) Current(ctx context.Context) (*entities.Subscription, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var current *entities.Subscription
+	for _, subscription := range r.subscriptions {
+		if !subscription.Current() {
+			continue
+		}
+		if current == nil || subscription.UpdatedAt.After(current.UpdatedAt) ||
+			(subscription.UpdatedAt.Equal(current.UpdatedAt) && subscription.ID < current.ID) {
+			current = subscription
+		}
+	}
+	if current == nil {
+		return nil, repositories.ErrSubscriptionNotFound
+	}
+	result := *current
+	return &result, nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockUploadRepository implements UploadRepository interface for testing
+type MockUploadRepository struct {
+	uploads map[string]*entities.Upload
+	mutex   sync.RWMutex
+}
+
+// NewMockUploadRepository creates a new mock upload repository
+func NewMockUploadRepository() repositories.UploadRepository {
+	return &MockUploadRepository{
+		uploads: make(map[string]*entities.Upload),
+	}
+}
+
+// Create stores a new upload
+func (r *MockUploadRepository) Create(ctx context.Context, upload *entities.Upload) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if upload.ID == "" {
+		upload.ID = fmt.Sprintf("upl_%d", time.Now().UnixNano())
+	}
+	r.uploads[upload.ID] = copyUpload(upload)
+	return nil
+}
+
+// GetByID retrieves an upload by ID
+func (r *MockUploadRepository) GetByID(ctx context.Context, id string) (*entities.Upload, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	upload, exists := r.uploads[id]
+	if !exists {
+		return nil, repositories.ErrUploadNotFound
+	}
+
+	// Return a copy to avoid external modifications
+	return copyUpload(upload), nil
+}
+
+// Update saves changes to an upload
+func (r *MockUploadRepository) Update(ctx context.Context, upload *entities.Upload) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.uploads[upload.ID]; !exists {
+		return repositories.ErrUploadNotFound
+	}
+	r.uploads[upload.ID] = copyUpload(upload)
+	return nil
+}
+
+// Advance saves an upload that received a chunk if it is still active at
+// fromOffset
+func (r *MockUploadRepository) Advance(ctx context.Context, upload *entities.Upload, fromOffset int64) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.uploads[upload.ID]
+	if !exists || stored.Status != entities.UploadActive || stored.Offset != fromOffset {
+		return repositories.ErrUploadConflict
+	}
+	r.uploads[upload.ID] = copyUpload(upload)
+	return nil
+}
+
+// ListExpired retrieves active uploads whose expiry passed before now
+func (r *MockUploadRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entities.Upload, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var matched []*entities.Upload
+	for _, upload := range r.uploads {
+		if upload.Status == entities.UploadActive && !upload.ExpiresAt.After(now) {
+			matched = append(matched, copyUpload(upload))
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].ExpiresAt.Before(matched[j].ExpiresAt)
+	})
+
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+// copyUpload copies an upload including its chunk list
+func copyUpload(upload *entities.Upload) *entities.Upload {
+	result := *upload
+	result.Chunks = append([]string(nil), upload.Chunks...)
+	return &result
+}
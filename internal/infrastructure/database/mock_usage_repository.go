@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockUsageRepository implements UsageRepository interface for testing
+type MockUsageRepository struct {
+	records []*entities.UsageRecord
+	mutex   sync.RWMutex
+}
+
+// NewMockUsageRepository creates a new mock usage repository
+func NewMockUsageRepository() repositories.UsageRepository {
+	return &MockUsageRepository{}
+}
+
+// Add adds the counts of records to the stored rollups
+func (r *MockUsageRepository) Add(ctx context.Context, records []*entities.UsageRecord) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, record := range records {
+		if stored := r.find(record); stored != nil {
+			stored.Add(record)
+			continue
+		}
+		added := *record
+		r.records = append(r.records, &added)
+	}
+	return nil
+}
+
+// List retrieves the rollups of the filtered days
+func (r *MockUsageRepository) List(ctx context.Context, filter repositories.UsageFilter) ([]*entities.UsageRecord, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var records []*entities.UsageRecord
+	for _, record := range r.records {
+		if record.Day.Before(filter.From) || record.Day.After(filter.To) {
+			continue
+		}
+		if (filter.KeyID != "" && record.KeyID != filter.KeyID) || (filter.TenantID != "" && record.TenantID != filter.TenantID) {
+			continue
+		}
+		result := *record
+		records = append(records, &result)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		switch {
+		case !a.Day.Equal(b.Day):
+			return a.Day.Before(b.Day)
+		case a.KeyID != b.KeyID:
+			return a.KeyID < b.KeyID
+		case a.TenantID != b.TenantID:
+			return a.TenantID < b.TenantID
+		case a.Route != b.Route:
+			return a.Route < b.Route
+		default:
+			return a.Method < b.Method
+		}
+	})
+	return records, nil
+}
+
+// find returns the stored rollup counting the same calls as record
+func (r *MockUsageRepository) find(record *entities.UsageRecord) *entities.UsageRecord {
+	for _, stored := range r.records {
+		if stored.Day.Equal(record.Day) && stored.KeyID == record.KeyID && stored.TenantID == record.TenantID &&
+			stored.Method == record.Method && stored.Route == record.Route {
+			return stored
+		}
+	}
+	return nil
+}
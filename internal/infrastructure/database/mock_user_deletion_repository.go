@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockUserDeletionRepository implements UserDeletionRepository interface for testing
+type MockUserDeletionRepository struct {
+	deletions map[string]*entities.UserDeletion
+	mutex     sync.RWMutex
+}
+
+// NewMockUserDeletionRepository creates a new mock user deletion repository
+func NewMockUserDeletionRepository() repositories.UserDeletionRepository {
+	return &MockUserDeletionRepository{
+		deletions: make(map[string]*entities.UserDeletion),
+	}
+}
+
+// Create stores a new deletion request
+func (r *MockUserDeletionRepository) Create(ctx context.Context, deletion *entities.UserDeletion) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if deletion.ID == "" {
+		deletion.ID = fmt.Sprintf("del_%d", time.Now().UnixNano())
+	}
+	stored := *deletion
+	r.deletions[deletion.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a deletion request by ID
+func (r *MockUserDeletionRepository) GetByID(ctx context.Context, id string) (*entities.UserDeletion, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	deletion, exists := r.deletions[id]
+	if !exists {
+		return nil, repositories.ErrUserDeletionNotFound
+	}
+
+	// Return a copy to avoid external modifications
+	result := *deletion
+	return &result, nil
+}
+
+// GetPendingByUserID retrieves the pending deletion request of a user
+func (r *MockUserDeletionRepository) GetPendingByUserID(ctx context.Context, userID string) (*entities.UserDeletion, error) {
+	return r.findPending(func(d *entities.UserDeletion) bool { return d.UserID == userID })
+}
+
+// GetPendingByTokenHash retrieves the pending deletion request a
+// cancellation token was issued for
+func (r *MockUserDeletionRepository) GetPendingByTokenHash(ctx context.Context, tokenHash string) (*entities.UserDeletion, error) {
+	return r.findPending(func(d *entities.UserDeletion) bool { return d.CancelTokenHash == tokenHash })
+}
+
+// ListPendingByUserIDs retrieves the pending deletion requests of the
+// given users
+func (r *MockUserDeletionRepository) ListPendingByUserIDs(ctx context.Context, userIDs []string) ([]*entities.UserDeletion, error) {
+	wanted := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		wanted[id] = true
+	}
+	return r.listPending(func(d *entities.UserDeletion) bool { return wanted[d.UserID] }), nil
+}
+
+// ListPending retrieves pending deletion requests, soonest purge first
+func (r *MockUserDeletionRepository) ListPending(ctx context.Context, limit, offset int) ([]*entities.UserDeletion, error) {
+	matched := r.listPending(func(d *entities.UserDeletion) bool { return true })
+
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+// ListDue retrieves pending deletion requests whose grace period has ended
+func (r *MockUserDeletionRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*entities.UserDeletion, error) {
+	matched := r.listPending(func(d *entities.UserDeletion) bool { return !d.PurgeAfter.After(now) })
+
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+// Update saves changes to a deletion request
+func (r *MockUserDeletionRepository) Update(ctx context.Context, deletion *entities.UserDeletion) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.deletions[deletion.ID]; !exists {
+		return repositories.ErrUserDeletionNotFound
+	}
+	stored := *deletion
+	r.deletions[deletion.ID] = &stored
+	return nil
+}
+
+func (r *MockUserDeletionRepository) findPending(match func(*entities.UserDeletion) bool) (*entities.UserDeletion, error) {
+	matched := r.listPending(match)
+	if len(matched) == 0 {
+		return nil, repositories.ErrUserDeletionNotFound
+	}
+	return matched[0], nil
+}
+
+// listPending returns copies of the pending requests accepted by match,
+// soonest purge first
+func (r *MockUserDeletionRepository) listPending(match func(*entities.UserDeletion) bool) []*entities.UserDeletion {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var matched []*entities.UserDeletion
+	for _, deletion := range r.deletions {
+		if deletion.Status() != entities.UserDeletionPending || !match(deletion) {
+			continue
+		}
+		result := *deletion
+		matched = append(matched, &result)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].PurgeAfter.Before(matched[j].PurgeAfter)
+	})
+	return matched
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockUserMergeRepository implements UserMergeRepository interface for
+// testing on top of mock user and deletion repositories. Saved views and
+// email changes are not reassigned.
+type MockUserMergeRepository struct {
+	users     repositories.UserRepository
+	deletions repositories.UserDeletionRepository
+	mutex     sync.Mutex
+}
+
+// NewMockUserMergeRepository creates a new mock user merge repository
+// merging the users of users and cancelling their requests in deletions
+func NewMockUserMergeRepository(users repositories.UserRepository, deletions repositories.UserDeletionRepository) repositories.UserMergeRepository {
+	return &MockUserMergeRepository{users: users, deletions: deletions}
+}
+
+// Merge cancels the loser's pending deletion and deletes the loser
+func (r *MockUserMergeRepository) Merge(ctx context.Context, merge *entities.UserMerge) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, id := range []string{merge.WinnerID, merge.LoserID} {
+		user, err := r.users.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return repositories.ErrUserNotFound
+		}
+	}
+
+	deletion, err := r.deletions.GetPendingByUserID(ctx, merge.LoserID)
+	switch {
+	case err == nil:
+		deletion.Cancel(entities.UserMergeCancelledBy, merge.MergedAt)
+		if err := r.deletions.Update(ctx, deletion); err != nil {
+			return err
+		}
+		merge.CancelledDeletions = 1
+	case !errors.Is(err, repositories.ErrUserDeletionNotFound):
+		return err
+	}
+
+	return r.users.Delete(ctx, merge.LoserID)
+}
@@ -2,8 +2,9 @@ package database
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -46,7 +47,7 @@ func (r *MockUserRepository) Create(ctx context.Context, user *entities.User) er
 	// Check if user with same email exists
 	for _, existingUser := range r.users {
 		if existingUser.Email == user.Email {
-			return errors.New("user with this email already exists")
+			return repositories.ErrUserAlreadyExists
 		}
 	}
 
@@ -67,11 +68,20 @@ func (r *MockUserRepository) GetByID(ctx context.Context, id string) (*entities.
 
 	// Return a copy to avoid external modifications
 	return &entities.User{
-		ID:        user.ID,
-		Email:     user.Email,
-		Name:      user.Name,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:              user.ID,
+		Email:           user.Email,
+		Name:            user.Name,
+		Kind:            user.Kind,
+		OrganizationID:  user.OrganizationID,
+		Scopes:          user.Scopes,
+		PasswordHash:    user.PasswordHash,
+		EmailUnverified: user.EmailUnverified,
+		MFASecret:       user.MFASecret,
+		MFAEnabled:      user.MFAEnabled,
+		MFALastStep:     user.MFALastStep,
+		Preferences:     user.Preferences,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
 	}, nil
 }
 
@@ -84,11 +94,20 @@ func (r *MockUserRepository) GetByEmail(ctx context.Context, email string) (*ent
 		if user.Email == email {
 			// Return a copy to avoid external modifications
 			return &entities.User{
-				ID:        user.ID,
-				Email:     user.Email,
-				Name:      user.Name,
-				CreatedAt: user.CreatedAt,
-				UpdatedAt: user.UpdatedAt,
+				ID:              user.ID,
+				Email:           user.Email,
+				Name:            user.Name,
+				Kind:            user.Kind,
+				OrganizationID:  user.OrganizationID,
+				Scopes:          user.Scopes,
+				PasswordHash:    user.PasswordHash,
+				EmailUnverified: user.EmailUnverified,
+				MFASecret:       user.MFASecret,
+				MFAEnabled:      user.MFAEnabled,
+				MFALastStep:     user.MFALastStep,
+				Preferences:     user.Preferences,
+				CreatedAt:       user.CreatedAt,
+				UpdatedAt:       user.UpdatedAt,
 			}, nil
 		}
 	}
@@ -103,20 +122,62 @@ func (r *MockUserRepository) Update(ctx context.Context, user *entities.User) er
 
 	existingUser, exists := r.users[user.ID]
 	if !exists {
-		return errors.New("user not found")
+		return repositories.ErrUserNotFound
 	}
 
 	// Update the user with current timestamp
 	now := time.Now()
+	r.users[user.ID] = &entities.User{
+		ID:              user.ID,
+		Email:           user.Email,
+		Name:            user.Name,
+		Kind:            user.Kind,
+		OrganizationID:  user.OrganizationID,
+		Scopes:          user.Scopes,
+		PasswordHash:    user.PasswordHash,
+		EmailUnverified: user.EmailUnverified,
+		MFASecret:       user.MFASecret,
+		MFAEnabled:      user.MFAEnabled,
+		MFALastStep:     user.MFALastStep,
+		Preferences:     user.Preferences,
+		CreatedAt:       existingUser.CreatedAt,
+		UpdatedAt:       now,
+	}
+
+	return nil
+}
+
+// Upsert creates a user, or renames the user with the same email
+func (r *MockUserRepository) Upsert(ctx context.Context, user *entities.User) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	for _, existingUser := range r.users {
+		if existingUser.Email == user.Email {
+			existingUser.Name = user.Name
+			existingUser.UpdatedAt = now
+
+			user.ID = existingUser.ID
+			user.CreatedAt = existingUser.CreatedAt
+			user.UpdatedAt = now
+			return false, nil
+		}
+	}
+
+	if user.ID == "" {
+		user.ID = fmt.Sprintf("user_%d", now.UnixNano())
+	}
+	user.CreatedAt = now
+	user.UpdatedAt = now
 	r.users[user.ID] = &entities.User{
 		ID:        user.ID,
 		Email:     user.Email,
 		Name:      user.Name,
-		CreatedAt: existingUser.CreatedAt,
+		CreatedAt: now,
 		UpdatedAt: now,
 	}
-
-	return nil
+	return true, nil
 }
 
 // Delete deletes a user
@@ -125,13 +186,32 @@ func (r *MockUserRepository) Delete(ctx context.Context, id string) error {
 	defer r.mutex.Unlock()
 
 	if _, exists := r.users[id]; !exists {
-		return errors.New("user not found")
+		return repositories.ErrUserNotFound
 	}
 
 	delete(r.users, id)
 	return nil
 }
 
+// PurgeDeleted removes nothing: the mock deletes users for good right away
+func (r *MockUserRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return 0, nil
+}
+
+// AdvanceMFAStep records step as the user's last accepted TOTP step unless
+// an equal or later one was recorded
+func (r *MockUserRepository) AdvanceMFAStep(ctx context.Context, id string, step int64) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user, exists := r.users[id]
+	if !exists || user.MFALastStep >= step {
+		return false, nil
+	}
+	user.MFALastStep = step
+	return true, nil
+}
+
 // List retrieves a list of users
 func (r *MockUserRepository) List(ctx context.Context, limit, offset int) ([]*entities.User, error) {
 	r.mutex.RLock()
@@ -141,17 +221,29 @@ func (r *MockUserRepository) List(ctx context.Context, limit, offset int) ([]*en
 	count := 0
 
 	for _, user := range r.users {
+		if user.IsServiceAccount() {
+			continue
+		}
 		if count >= offset {
 			if len(users) >= limit {
 				break
 			}
 			// Return a copy to avoid external modifications
 			users = append(users, &entities.User{
-				ID:        user.ID,
-				Email:     user.Email,
-				Name:      user.Name,
-				CreatedAt: user.CreatedAt,
-				UpdatedAt: user.UpdatedAt,
+				ID:              user.ID,
+				Email:           user.Email,
+				Name:            user.Name,
+				Kind:            user.Kind,
+				OrganizationID:  user.OrganizationID,
+				Scopes:          user.Scopes,
+				PasswordHash:    user.PasswordHash,
+				EmailUnverified: user.EmailUnverified,
+				MFASecret:       user.MFASecret,
+				MFAEnabled:      user.MFAEnabled,
+				MFALastStep:     user.MFALastStep,
+				Preferences:     user.Preferences,
+				CreatedAt:       user.CreatedAt,
+				UpdatedAt:       user.UpdatedAt,
 			})
 		}
 		count++
@@ -159,3 +251,186 @@ func (r *MockUserRepository) List(ctx context.Context, limit, offset int) ([]*en
 
 	return users, nil
 }
+
+// Count returns the number of users
+func (r *MockUserRepository) Count(ctx context.Context) (int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var count int64
+	for _, user := range r.users {
+		if !user.IsServiceAccount() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountMatching returns the number of users matching spec's conditions
+func (r *MockUserRepository) CountMatching(ctx context.Context, spec repositories.Specification) (int64, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var count int64
+	for _, user := range r.users {
+		matched, err := matchesUser(user, humansUnlessKind(spec).Conditions)
+		if err != nil {
+			return 0, err
+		}
+		if matched {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Find retrieves the users matching spec, oldest first unless spec sorts
+// them otherwise
+func (r *MockUserRepository) Find(ctx context.Context, spec repositories.Specification) ([]*entities.User, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var users []*entities.User
+	for _, user := range r.users {
+		matched, err := matchesUser(user, humansUnlessKind(spec).Conditions)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+	for _, order := range spec.Sort {
+		if _, err := userField(&entities.User{}, order.Field); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		for _, order := range spec.Sort {
+			a, _ := userField(users[i], order.Field)
+			b, _ := userField(users[j], order.Field)
+			if cmp := compareFields(a, b); cmp != 0 {
+				return (cmp < 0) != order.Descending
+			}
+		}
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return users[i].ID < users[j].ID
+	})
+
+	if spec.Offset >= len(users) {
+		return nil, nil
+	}
+	users = users[spec.Offset:]
+	if spec.Limit > 0 && spec.Limit < len(users) {
+		users = users[:spec.Limit]
+	}
+	return users, nil
+}
+
+// matchesUser reports whether user satisfies every condition
+func matchesUser(user *entities.User, conditions []repositories.Condition) (bool, error) {
+	for _, condition := range conditions {
+		field, err := userField(user, condition.Field)
+		if err != nil {
+			return false, err
+		}
+
+		matched, err := matchCondition(field, condition)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// userField returns the value of the named field of user
+func userField(user *entities.User, name string) (interface{}, error) {
+	switch name {
+	case "id":
+		return user.ID, nil
+	case "email":
+		return user.Email, nil
+	case "name":
+		return user.Name, nil
+	case "created_at":
+		return user.CreatedAt, nil
+	case "updated_at":
+		return user.UpdatedAt, nil
+	case repositories.UserKindField:
+		if user.Kind == "" {
+			return entities.UserKindHuman, nil
+		}
+		return user.Kind, nil
+	case "organization_id":
+		return user.OrganizationID, nil
+	default:
+		return nil, fmt.Errorf("unsupported field %q", name)
+	}
+}
+
+// compareFields compares two values returned by userField for the same field
+func compareFields(a, b interface{}) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		return 0
+	}
+}
+
+// matchCondition evaluates a condition against a string or time field
+func matchCondition(field interface{}, condition repositories.Condition) (bool, error) {
+	if condition.Operator == repositories.OpIn {
+		values, _ := condition.Value.([]string)
+		for _, value := range values {
+			if field == value {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	var cmp int
+	switch field := field.(type) {
+	case string:
+		value, ok := condition.Value.(string)
+		if !ok {
+			return false, fmt.Errorf("filter %s needs a string", condition.Field)
+		}
+		switch condition.Operator {
+		case repositories.OpContains:
+			return strings.Contains(strings.ToLower(field), strings.ToLower(value)), nil
+		case repositories.OpStartsWith:
+			return strings.HasPrefix(strings.ToLower(field), strings.ToLower(value)), nil
+		}
+		cmp = strings.Compare(field, value)
+	case time.Time:
+		value, ok := condition.Value.(time.Time)
+		if !ok {
+			return false, fmt.Errorf("filter %s needs a time", condition.Field)
+		}
+		cmp = field.Compare(value)
+	}
+
+	switch condition.Operator {
+	case repositories.OpEq:
+		return cmp == 0, nil
+	case repositories.OpNe:
+		return cmp != 0, nil
+	case repositories.OpGt:
+		return cmp > 0, nil
+	case repositories.OpGte:
+		return cmp >= 0, nil
+	case repositories.OpLt:
+		return cmp < 0, nil
+	case repositories.OpLte:
+		return cmp <= 0, nil
+	default:
+		return false, fmt.Errorf("unsupported filter operator %q", condition.Operator)
+	}
+}
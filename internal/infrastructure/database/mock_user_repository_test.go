@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
 )
 
 func TestMockUserRepository_Create(t *testing.T) {
@@ -327,6 +328,107 @@ func TestMockUserRepository_List(t *testing.T) {
 	}
 }
 
+func TestMockUserRepository_Count(t *testing.T) {
+	repo := NewMockUserRepository()
+
+	count, err := repo.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	user1 := &entities.User{Email: "user1@example.com", Name: "User 1"}
+	user2 := &entities.User{Email: "user2@example.com", Name: "User 2"}
+	assert.NoError(t, repo.Create(context.Background(), user1))
+	assert.NoError(t, repo.Create(context.Background(), user2))
+
+	count, err = repo.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	assert.NoError(t, repo.Delete(context.Background(), user1.ID))
+
+	count, err = repo.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMockUserRepository_Find(t *testing.T) {
+	repo := NewMockUserRepository()
+	for _, user := range []*entities.User{
+		{Email: "jane.smith@example.com", Name: "Jane Smith"},
+		{Email: "john.doe@example.com", Name: "John Doe"},
+		{Email: "sam.smith@example.org", Name: "Sam Smith"},
+	} {
+		assert.NoError(t, repo.Create(context.Background(), user))
+	}
+
+	tests := []struct {
+		name     string
+		spec     repositories.Specification
+		expected []string
+	}{
+		{
+			name:     "no conditions",
+			spec:     repositories.Specification{},
+			expected: []string{"Jane Smith", "John Doe", "Sam Smith"},
+		},
+		{
+			name: "contains is case-insensitive",
+			spec: repositories.Specification{Conditions: []repositories.Condition{
+				{Field: "name", Operator: repositories.OpContains, Value: "SMITH"},
+			}},
+			expected: []string{"Jane Smith", "Sam Smith"},
+		},
+		{
+			name: "conditions are combined",
+			spec: repositories.Specification{Conditions: []repositories.Condition{
+				{Field: "name", Operator: repositories.OpContains, Value: "smith"},
+				{Field: "email", Operator: repositories.OpNe, Value: "jane.smith@example.com"},
+			}},
+			expected: []string{"Sam Smith"},
+		},
+		{
+			name: "in",
+			spec: repositories.Specification{Conditions: []repositories.Condition{
+				{Field: "email", Operator: repositories.OpIn, Value: []string{"john.doe@example.com", "nobody@example.com"}},
+			}},
+			expected: []string{"John Doe"},
+		},
+		{
+			name:     "limit and offset",
+			spec:     repositories.Specification{Limit: 1, Offset: 1},
+			expected: []string{"John Doe"},
+		},
+		{
+			name: "sorted",
+			spec: repositories.Specification{Sort: []repositories.SortOrder{
+				{Field: "email", Descending: true},
+			}},
+			expected: []string{"Sam Smith", "John Doe", "Jane Smith"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.Find(context.Background(), tt.spec)
+			assert.NoError(t, err)
+
+			names := make([]string, len(got))
+			for i, user := range got {
+				names[i] = user.Name
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+
+	_, err := repo.Find(context.Background(), repositories.Specification{Conditions: []repositories.Condition{
+		{Field: "password", Operator: repositories.OpEq, Value: "secret"},
+	}})
+	assert.Error(t, err)
+
+	_, err = repo.Find(context.Background(), repositories.Specification{Sort: []repositories.SortOrder{{Field: "password"}}})
+	assert.Error(t, err)
+}
+
 func TestMockUserRepository_Concurrency(t *testing.T) {
 	repo := NewMockUserRepository()
 
@@ -394,3 +496,73 @@ func TestMockUserRepository_DataIntegrity(t *testing.T) {
 	assert.Equal(t, originalName, retrievedAgain.Name)
 	assert.NotEqual(t, retrieved.Name, retrievedAgain.Name)
 }
+
+func TestMockUserRepository_DeleteThenRecreate(t *testing.T) {
+	repo := NewMockUserRepository()
+	ctx := context.Background()
+
+	user := &entities.User{Email: "test@example.com", Name: "Test User"}
+	assert.NoError(t, repo.Create(ctx, user))
+	assert.NoError(t, repo.Delete(ctx, user.ID))
+
+	recreated := &entities.User{Email: "test@example.com", Name: "Returning User"}
+	assert.NoError(t, repo.Create(ctx, recreated))
+
+	found, err := repo.GetByEmail(ctx, "test@example.com")
+	assert.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, "Returning User", found.Name)
+	}
+}
+
+func TestMockUserRepository_Upsert(t *testing.T) {
+	repo := NewMockUserRepository()
+	ctx := context.Background()
+
+	user := &entities.User{Email: "test@example.com", Name: "Test User"}
+	created, err := repo.Upsert(ctx, user)
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.NotEmpty(t, user.ID)
+
+	renamed := &entities.User{Email: "test@example.com", Name: "Renamed"}
+	created, err = repo.Upsert(ctx, renamed)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, user.ID, renamed.ID)
+	assert.Equal(t, user.CreatedAt, renamed.CreatedAt)
+
+	found, err := repo.GetByID(ctx, user.ID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, "Renamed", found.Name)
+	}
+}
+
+func TestMockUserRepository_AdvanceMFAStep(t *testing.T) {
+	repo := NewMockUserRepository()
+	ctx := context.Background()
+
+	user := &entities.User{Email: "test@example.com", Name: "Test User"}
+	assert.NoError(t, repo.Create(ctx, user))
+
+	advanced, err := repo.AdvanceMFAStep(ctx, user.ID, 100)
+	assert.NoError(t, err)
+	assert.True(t, advanced)
+
+	advanced, err = repo.AdvanceMFAStep(ctx, user.ID, 100)
+	assert.NoError(t, err)
+	assert.False(t, advanced, "a step is accepted once")
+
+	advanced, err = repo.AdvanceMFAStep(ctx, user.ID, 99)
+	assert.NoError(t, err)
+	assert.False(t, advanced)
+
+	found, err := repo.GetByID(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), found.MFALastStep)
+
+	advanced, err = repo.AdvanceMFAStep(ctx, "missing", 1)
+	assert.NoError(t, err)
+	assert.False(t, advanced)
+}
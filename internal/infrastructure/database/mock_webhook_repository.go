@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// MockWebhookRepository implements WebhookRepository interface for testing
+type MockWebhookRepository struct {
+	webhooks   map[string]*entities.Webhook
+	deliveries map[string]*entities.WebhookDelivery
+	mutex      sync.RWMutex
+}
+
+// NewMockWebhookRepository creates a new mock webhook repository
+func NewMockWebhookRepository() repositories.WebhookRepository {
+	return &MockWebhookRepository{
+		webhooks:   make(map[string]*entities.Webhook),
+		deliveries: make(map[string]*entities.WebhookDelivery),
+	}
+}
+
+// Create stores a new webhook
+func (r *MockWebhookRepository) Create(ctx context.Context, webhook *entities.Webhook) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if webhook.ID == "" {
+		webhook.ID = fmt.Sprintf("whk_%d", time.Now().UnixNano())
+	}
+	stored := *webhook
+	r.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a webhook by ID
+func (r *MockWebhookRepository) GetByID(ctx context.Context, id string) (*entities.Webhook, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	webhook, exists := r.webhooks[id]
+	if !exists {
+		return nil, repositories.ErrWebhookNotFound
+	}
+	result := *webhook
+	return &result, nil
+}
+
+// List retrieves every webhook, oldest first
+func (r *MockWebhookRepository) List(ctx context.Context) ([]*entities.Webhook, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	webhooks := make([]*entities.Webhook, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		copied := *webhook
+		webhooks = append(webhooks, &copied)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		if !webhooks[i].CreatedAt.Equal(webhooks[j].CreatedAt) {
+			return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+		}
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks, nil
+}
+
+// Update saves every field of a webhook
+func (r *MockWebhookRepository) Update(ctx context.Context, webhook *entities.Webhook) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.webhooks[webhook.ID]; !exists {
+		return repositories.ErrWebhookNotFound
+	}
+	stored := *webhook
+	r.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+// Delete removes a webhook and its deliveries
+func (r *MockWebhookRepository) Delete(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.webhooks[id]; !exists {
+		return repositories.ErrWebhookNotFound
+	}
+	delete(r.webhooks, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.WebhookID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// CreateDelivery stores a delivery unless the event was already delivered
+// to the webhook
+func (r *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, existing := range r.deliveries {
+		if existing.WebhookID == delivery.WebhookID && existing.EventID == delivery.EventID {
+			return nil
+		}
+	}
+	if delivery.ID == "" {
+		delivery.ID = fmt.Sprintf("whd_%d", time.Now().UnixNano())
+	}
+	stored := *delivery
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
+
+// ListDueDeliveries retrieves the pending deliveries due at now
+func (r *MockWebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*entities.WebhookDelivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var deliveries []*entities.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == entities.WebhookDeliveryPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			copied := *delivery
+			deliveries = append(deliveries, &copied)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].NextAttemptAt.Equal(*deliveries[j].NextAttemptAt) {
+			return deliveries[i].NextAttemptAt.Before(*deliveries[j].NextAttemptAt)
+		}
+		return deliveries[i].ID < deliveries[j].ID
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// ListDeliveries retrieves the deliveries of a webhook, newest first
+func (r *MockWebhookRepository) ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*entities.WebhookDelivery, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var deliveries []*entities.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID {
+			copied := *delivery
+			deliveries = append(deliveries, &copied)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+		}
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	if offset >= len(deliveries) {
+		return nil, nil
+	}
+	deliveries = deliveries[offset:]
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery saves every field of a delivery
+func (r *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.deliveries[delivery.ID]; !exists {
+		return repositories.ErrWebhookNotFound
+	}
+	stored := *delivery
+	stored.Attempts = append([]entities.WebhookAttempt(nil), delivery.Attempts...)
+	r.deliveries[delivery.ID] = &stored
+	return nil
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PostgresAPIKeyRepository implements APIKeyRepository using PostgreSQL
+type PostgresAPIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAPIKeyRepository creates a new PostgreSQL API key repository
+func NewPostgresAPIKeyRepository(db *gorm.DB) repositories.APIKeyRepository {
+	return &PostgresAPIKeyRepository{db: db}
+}
+
+// Create stores a new API key
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	if key.ID == "" {
+		key.ID = generateAPIKeyID()
+	}
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// GetByID retrieves an API key by ID
+func (r *PostgresAPIKeyRepository) GetByID(ctx context.Context, id string) (*entities.APIKey, error) {
+	return r.first(r.db.WithContext(ctx).Where("id = ?", id))
+}
+
+// GetByPrefix retrieves an API key by its public prefix
+func (r *PostgresAPIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*entities.APIKey, error) {
+	return r.first(r.db.WithContext(ctx).Where("prefix = ?", prefix))
+}
+
+// List retrieves API keys, newest first
+func (r *PostgresAPIKeyRepository) List(ctx context.Context, limit, offset int) ([]*entities.APIKey, error) {
+	var keys []*entities.APIKey
+	err := pagedList(r.db.WithContext(ctx)).
+		Order("created_at DESC").Order("id ASC").
+		Limit(limit).Offset(offset).
+		Find(&keys).Error
+	return keys, err
+}
+
+// ListByOwner retrieves the API keys of a service account, newest first
+func (r *PostgresAPIKeyRepository) ListByOwner(ctx context.Context, ownerID string) ([]*entities.APIKey, error) {
+	var keys []*entities.APIKey
+	err := r.db.WithContext(ctx).
+		Where("owner_id = ?", ownerID).
+		Order("created_at DESC").Order("id ASC").
+		Find(&keys).Error
+	return keys, err
+}
+
+// Update saves changes to an API key
+func (r *PostgresAPIKeyRepository) Update(ctx context.Context, key *entities.APIKey) error {
+	result := r.db.WithContext(ctx).Model(key).Select("*").Updates(key)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed sets the last use of an API key
+func (r *PostgresAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&entities.APIKey{}).Where("id = ?", id).Update("last_used_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *PostgresAPIKeyRepository) first(query *gorm.DB) (*entities.APIKey, error) {
+	var key entities.APIKey
+	err := query.First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// generateAPIKeyID generates a unique ID for API keys
+func generateAPIKeyID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "key_" + time.Now().Format("20060102150405.000000")
+	}
+	return "key_" + hex.EncodeToString(randBytes)
+}
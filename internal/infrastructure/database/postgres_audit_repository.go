@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// auditChainLock is the transaction-level advisory lock serializing
+// appends, so each entry chains to the one committed before it
+const auditChainLock = 0x6175646974 // "audit"
+
+// PostgresAuditRepository implements AuditRepository using PostgreSQL
+type PostgresAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAuditRepository creates a new PostgreSQL audit repository
+func NewPostgresAuditRepository(db *gorm.DB) repositories.AuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+// Append chains and inserts an entry unless its event was already recorded
+func (r *PostgresAuditRepository) Append(ctx context.Context, entry *entities.AuditEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLock).Error; err != nil {
+			return err
+		}
+
+		var recorded int64
+		if err := tx.Model(&entities.AuditEntry{}).Where("event_id = ?", entry.EventID).Count(&recorded).Error; err != nil {
+			return err
+		}
+		if recorded > 0 {
+			return nil
+		}
+
+		head, err := r.head(tx)
+		if err != nil {
+			return err
+		}
+		entry.Chain(head)
+		return tx.Create(entry).Error
+	})
+}
+
+// GetHead retrieves the last entry
+func (r *PostgresAuditRepository) GetHead(ctx context.Context) (*entities.AuditEntry, error) {
+	return r.head(r.db.WithContext(ctx))
+}
+
+// head retrieves the last entry through db, or nil when there is none
+func (r *PostgresAuditRepository) head(db *gorm.DB) (*entities.AuditEntry, error) {
+	var entries []*entities.AuditEntry
+	if err := db.Order("seq DESC").Limit(1).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return entries[0], nil
+}
+
+// ListAfter retrieves the entries following seq
+func (r *PostgresAuditRepository) ListAfter(ctx context.Context, seq int64, recordedBefore time.Time, limit int) ([]*entities.AuditEntry, error) {
+	var entries []*entities.AuditEntry
+	err := r.db.WithContext(ctx).
+		Where("seq > ? AND recorded_at < ?", seq, recordedBefore).
+		Order("seq").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// List retrieves the entries matching a filter, newest first
+func (r *PostgresAuditRepository) List(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error) {
+	query := pagedList(r.db.WithContext(ctx)).Order("seq DESC")
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.AggregateID != "" {
+		query = query.Where("aggregate_id = ?", filter.AggregateID)
+	}
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+
+	var entries []*entities.AuditEntry
+	err := query.Limit(filter.Limit).Offset(filter.Offset).Find(&entries).Error
+	return entries, err
+}
+
+// SaveAnchor inserts an anchor; anchoring the same entry twice is a no-op
+func (r *PostgresAuditRepository) SaveAnchor(ctx context.Context, anchor *entities.AuditAnchor) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "seq"}},
+		DoNothing: true,
+	}).Create(anchor).Error
+}
+
+// ListAnchors retrieves every anchor
+func (r *PostgresAuditRepository) ListAnchors(ctx context.Context) ([]*entities.AuditAnchor, error) {
+	var anchors []*entities.AuditAnchor
+	if err := r.db.WithContext(ctx).Order("seq").Find(&anchors).Error; err != nil {
+		return nil, err
+	}
+	return anchors, nil
+}
+
+// GetCheckpoint retrieves the checkpoint of a sink
+func (r *PostgresAuditRepository) GetCheckpoint(ctx context.Context, sink string) (int64, error) {
+	var checkpoint entities.AuditCheckpoint
+	err := r.db.WithContext(ctx).Where("sink = ?", sink).First(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return checkpoint.Seq, nil
+}
+
+// SaveCheckpoint creates or replaces the checkpoint of a sink
+func (r *PostgresAuditRepository) SaveCheckpoint(ctx context.Context, checkpoint *entities.AuditCheckpoint) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "sink"}},
+		DoUpdates: clause.AssignmentColumns([]string{"seq", "updated_at"}),
+	}).Create(checkpoint).Error
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PostgresDeadLetterRepository implements DeadLetterRepository using PostgreSQL
+type PostgresDeadLetterRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresDeadLetterRepository creates a new PostgreSQL dead letter repository
+func NewPostgresDeadLetterRepository(db *gorm.DB) repositories.DeadLetterRepository {
+	return &PostgresDeadLetterRepository{db: db}
+}
+
+// Create stores a new dead letter
+func (r *PostgresDeadLetterRepository) Create(ctx context.Context, deadLetter *entities.DeadLetter) error {
+	if deadLetter.ID == "" {
+		deadLetter.ID = generateDeadLetterID()
+	}
+	return r.db.WithContext(ctx).Create(deadLetter).Error
+}
+
+// GetByID retrieves a dead letter by ID
+func (r *PostgresDeadLetterRepository) GetByID(ctx context.Context, id string) (*entities.DeadLetter, error) {
+	var deadLetter entities.DeadLetter
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&deadLetter).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deadLetter, nil
+}
+
+// List retrieves dead letters matching filter, most recent failures first
+func (r *PostgresDeadLetterRepository) List(ctx context.Context, filter repositories.DeadLetterFilter) ([]*entities.DeadLetter, error) {
+	query := r.db.WithContext(ctx).Order("failed_at DESC")
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+	if filter.Topic != "" {
+		query = query.Where("topic = ?", filter.Topic)
+	}
+	if filter.ConsumerGroup != "" {
+		query = query.Where("consumer_group = ?", filter.ConsumerGroup)
+	}
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+	if filter.Pending {
+		query = query.Where("replayed_at IS NULL")
+	}
+
+	var deadLetters []*entities.DeadLetter
+	err := pagedList(query).Limit(filter.Limit).Offset(filter.Offset).Find(&deadLetters).Error
+	return deadLetters, err
+}
+
+// Update saves changes to a dead letter
+func (r *PostgresDeadLetterRepository) Update(ctx context.Context, deadLetter *entities.DeadLetter) error {
+	result := r.db.WithContext(ctx).Model(deadLetter).Select("*").Updates(deadLetter)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// generateDeadLetterID generates a unique ID for dead letters
+func generateDeadLetterID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "dlq_" + time.Now().Format("20060102150405.000000")
+	}
+	return "dlq_" + hex.EncodeToString(randBytes)
+}
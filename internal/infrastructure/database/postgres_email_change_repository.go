@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PostgresEmailChangeRepository implements EmailChangeRepository using PostgreSQL
+type PostgresEmailChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresEmailChangeRepository creates a new PostgreSQL email change repository
+func NewPostgresEmailChangeRepository(db *gorm.DB) repositories.EmailChangeRepository {
+	return &PostgresEmailChangeRepository{db: db}
+}
+
+// Create stores a new email change
+func (r *PostgresEmailChangeRepository) Create(ctx context.Context, change *entities.EmailChange) error {
+	if change.ID == "" {
+		change.ID = generateEmailChangeID()
+	}
+	return r.db.WithContext(ctx).Create(change).Error
+}
+
+// GetPendingByUserID retrieves the email change of a user awaiting
+// confirmation
+func (r *PostgresEmailChangeRepository) GetPendingByUserID(ctx context.Context, userID string) (*entities.EmailChange, error) {
+	return r.first(r.pending(ctx).Where("user_id = ?", userID).Order("requested_at DESC"))
+}
+
+// GetPendingByTokenHash retrieves the email change awaiting confirmation
+// a confirmation token was issued for
+func (r *PostgresEmailChangeRepository) GetPendingByTokenHash(ctx context.Context, tokenHash string) (*entities.EmailChange, error) {
+	return r.first(r.pending(ctx).Where("token_hash = ?", tokenHash))
+}
+
+// Update saves changes to an email change
+func (r *PostgresEmailChangeRepository) Update(ctx context.Context, change *entities.EmailChange) error {
+	result := r.db.WithContext(ctx).Model(change).Select("*").Updates(change)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrEmailChangeNotFound
+	}
+	return nil
+}
+
+func (r *PostgresEmailChangeRepository) pending(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Where("confirmed_at IS NULL AND superseded_at IS NULL")
+}
+
+func (r *PostgresEmailChangeRepository) first(query *gorm.DB) (*entities.EmailChange, error) {
+	var change entities.EmailChange
+	err := query.First(&change).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrEmailChangeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// generateEmailChangeID generates a unique ID for email changes
+func generateEmailChangeID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "emc_" + time.Now().Format("20060102150405.000000")
+	}
+	return "emc_" + hex.EncodeToString(randBytes)
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresEmailSuppressionRepository implements EmailSuppressionRepository
+// using PostgreSQL
+type PostgresEmailSuppressionRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresEmailSuppressionRepository creates a new PostgreSQL email
+// suppression repository
+func NewPostgresEmailSuppressionRepository(db *gorm.DB) repositories.EmailSuppressionRepository {
+	return &PostgresEmailSuppressionRepository{db: db}
+}
+
+// Save creates or replaces the suppression of an address
+func (r *PostgresEmailSuppressionRepository) Save(ctx context.Context, suppression *entities.EmailSuppression) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "email"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "detail", "provider", "feedback_id", "updated_at"}),
+	}).Create(suppression).Error
+}
+
+// Get retrieves the suppression of an address
+func (r *PostgresEmailSuppressionRepository) Get(ctx context.Context, email string) (*entities.EmailSuppression, error) {
+	var suppression entities.EmailSuppression
+	err := r.db.WithContext(ctx).Where("email = ?", email).First(&suppression).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrEmailSuppressionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &suppression, nil
+}
+
+// GetMany retrieves the suppressions of several addresses in one query
+func (r *PostgresEmailSuppressionRepository) GetMany(ctx context.Context, emails []string) (map[string]*entities.EmailSuppression, error) {
+	result := make(map[string]*entities.EmailSuppression)
+	if len(emails) == 0 {
+		return result, nil
+	}
+	var suppressions []*entities.EmailSuppression
+	if err := r.db.WithContext(ctx).Where("email IN ?", emails).Find(&suppressions).Error; err != nil {
+		return nil, err
+	}
+	for _, suppression := range suppressions {
+		result[suppression.Email] = suppression
+	}
+	return result, nil
+}
+
+// List retrieves suppressions, most recently updated first
+func (r *PostgresEmailSuppressionRepository) List(ctx context.Context, limit, offset int) ([]*entities.EmailSuppression, error) {
+	var suppressions []*entities.EmailSuppression
+	err := pagedList(r.db.WithContext(ctx)).
+		Order("updated_at DESC").Order("email ASC").
+		Limit(limit).Offset(offset).
+		Find(&suppressions).Error
+	return suppressions, err
+}
+
+// Delete lifts the suppression of an address
+func (r *PostgresEmailSuppressionRepository) Delete(ctx context.Context, email string) error {
+	result := r.db.WithContext(ctx).Where("email = ?", email).Delete(&entities.EmailSuppression{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrEmailSuppressionNotFound
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresFeatureFlagRepository implements FeatureFlagRepository using
+// PostgreSQL
+type PostgresFeatureFlagRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresFeatureFlagRepository creates a new PostgreSQL feature flag
+// repository
+func NewPostgresFeatureFlagRepository(db *gorm.DB) repositories.FeatureFlagRepository {
+	return &PostgresFeatureFlagRepository{db: db}
+}
+
+// Get retrieves the state set on a flag
+func (r *PostgresFeatureFlagRepository) Get(ctx context.Context, key string) (*entities.FeatureFlag, error) {
+	var flag entities.FeatureFlag
+	err := r.db.WithContext(ctx).Where("key = ?", key).First(&flag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrFeatureFlagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+// List retrieves every flag state that was set
+func (r *PostgresFeatureFlagRepository) List(ctx context.Context) ([]*entities.FeatureFlag, error) {
+	var flags []*entities.FeatureFlag
+	if err := r.db.WithContext(ctx).Order("key").Find(&flags).Error; err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// Save creates or replaces the state of a flag
+func (r *PostgresFeatureFlagRepository) Save(ctx context.Context, flag *entities.FeatureFlag) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(flag).Error
+}
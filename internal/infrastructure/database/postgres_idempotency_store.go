@@ -0,0 +1,52 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProcessedMessage records a message handled by a consumer group
+type ProcessedMessage struct {
+	ConsumerGroup string    `gorm:"primaryKey;type:varchar(255)"`
+	MessageKey    string    `gorm:"primaryKey;type:varchar(255)"`
+	ProcessedAt   time.Time `gorm:"not null;index"`
+}
+
+// TableName specifies the table name for the ProcessedMessage model
+func (ProcessedMessage) TableName() string {
+	return "processed_messages"
+}
+
+// PostgresIdempotencyStore implements consumer.IdempotencyStore so every
+// replica sees which messages a consumer group already handled
+type PostgresIdempotencyStore struct {
+	db *gorm.DB
+}
+
+// NewPostgresIdempotencyStore creates a new PostgreSQL idempotency store
+func NewPostgresIdempotencyStore(db *gorm.DB) *PostgresIdempotencyStore {
+	return &PostgresIdempotencyStore{db: db}
+}
+
+// Claim records key as processed by group, returning false if it already was
+func (s *PostgresIdempotencyStore) Claim(ctx context.Context, group, key string) (bool, error) {
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&ProcessedMessage{
+		ConsumerGroup: group,
+		MessageKey:    key,
+		ProcessedAt:   time.Now(),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Release forgets a claim so the message can be processed again
+func (s *PostgresIdempotencyStore) Release(ctx context.Context, group, key string) error {
+	return s.db.WithContext(ctx).
+		Where("consumer_group = ? AND message_key = ?", group, key).
+		Delete(&ProcessedMessage{}).Error
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PostgresJobRepository implements JobRepository using PostgreSQL
+type PostgresJobRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresJobRepository creates a new PostgreSQL job repository
+func NewPostgresJobRepository(db *gorm.DB) repositories.JobRepository {
+	return &PostgresJobRepository{db: db}
+}
+
+// Create stores a new job
+func (r *PostgresJobRepository) Create(ctx context.Context, job *entities.Job) error {
+	if job.ID == "" {
+		job.ID = generateJobID()
+	}
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// GetByID retrieves a job by ID
+func (r *PostgresJobRepository) GetByID(ctx context.Context, id string) (*entities.Job, error) {
+	var job entities.Job
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Update saves changes to a job
+func (r *PostgresJobRepository) Update(ctx context.Context, job *entities.Job) error {
+	result := r.db.WithContext(ctx).Model(job).Select("*").Updates(job)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrJobNotFound
+	}
+	return nil
+}
+
+// ListExpired retrieves succeeded jobs whose results expired before now
+func (r *PostgresJobRepository) ListExpired(ctx context.Context, jobType string, now time.Time, limit int) ([]*entities.Job, error) {
+	var jobs []*entities.Job
+	err := r.db.WithContext(ctx).
+		Where("type = ? AND status = ? AND expires_at <= ?", jobType, entities.JobSucceeded, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+// generateJobID generates a unique ID for jobs
+func generateJobID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "job_" + time.Now().Format("20060102150405.000000")
+	}
+	return "job_" + hex.EncodeToString(randBytes)
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PostgresOrganizationRepository implements OrganizationRepository using
+// PostgreSQL
+type PostgresOrganizationRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresOrganizationRepository creates a new PostgreSQL organization
+// repository
+func NewPostgresOrganizationRepository(db *gorm.DB) repositories.OrganizationRepository {
+	return &PostgresOrganizationRepository{db: db}
+}
+
+// Create stores a new organization
+func (r *PostgresOrganizationRepository) Create(ctx context.Context, org *entities.Organization) error {
+	if org.ID == "" {
+		org.ID = generateOrganizationID()
+	}
+	return r.db.WithContext(ctx).Create(org).Error
+}
+
+// GetByID retrieves an organization by ID
+func (r *PostgresOrganizationRepository) GetByID(ctx context.Context, id string) (*entities.Organization, error) {
+	var org entities.Organization
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&org).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// List retrieves organizations, oldest first
+func (r *PostgresOrganizationRepository) List(ctx context.Context, limit, offset int) ([]*entities.Organization, error) {
+	var orgs []*entities.Organization
+	err := pagedList(r.db.WithContext(ctx)).
+		Order("created_at ASC").Order("id ASC").
+		Limit(limit).Offset(offset).
+		Find(&orgs).Error
+	return orgs, err
+}
+
+// generateOrganizationID generates a unique ID for organizations
+func generateOrganizationID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "org_" + time.Now().Format("20060102150405.000000")
+	}
+	return "org_" + hex.EncodeToString(randBytes)
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PostgresPublishedEventRepository implements PublishedEventRepository
+// using PostgreSQL
+type PostgresPublishedEventRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresPublishedEventRepository creates a new PostgreSQL published
+// event repository
+func NewPostgresPublishedEventRepository(db *gorm.DB) repositories.PublishedEventRepository {
+	return &PostgresPublishedEventRepository{db: db}
+}
+
+// Create records a published event
+func (r *PostgresPublishedEventRepository) Create(ctx context.Context, event *entities.PublishedEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+// GetByID retrieves a published event by ID
+func (r *PostgresPublishedEventRepository) GetByID(ctx context.Context, id string) (*entities.PublishedEvent, error) {
+	var event entities.PublishedEvent
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&event).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrPublishedEventNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// List retrieves published events matching filter, oldest first
+func (r *PostgresPublishedEventRepository) List(ctx context.Context, filter repositories.PublishedEventFilter) ([]*entities.PublishedEvent, error) {
+	query := r.db.WithContext(ctx).Order("published_at ASC, id ASC")
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.AggregateID != "" {
+		query = query.Where("aggregate_id = ?", filter.AggregateID)
+	}
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("published_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("published_at < ?", filter.Until)
+	}
+	if filter.Failed {
+		query = query.Where("publish_error <> ''")
+	}
+
+	var events []*entities.PublishedEvent
+	err := pagedList(query).Limit(filter.Limit).Offset(filter.Offset).Find(&events).Error
+	return events, err
+}
+
+// Update saves changes to a published event
+func (r *PostgresPublishedEventRepository) Update(ctx context.Context, event *entities.PublishedEvent) error {
+	result := r.db.WithContext(ctx).Model(event).Select("*").Updates(event)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrPublishedEventNotFound
+	}
+	return nil
+}
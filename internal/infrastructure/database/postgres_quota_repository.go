@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresQuotaRepository implements QuotaRepository using PostgreSQL
+type PostgresQuotaRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresQuotaRepository creates a new PostgreSQL quota repository
+func NewPostgresQuotaRepository(db *gorm.DB) repositories.QuotaRepository {
+	return &PostgresQuotaRepository{db: db}
+}
+
+// Get retrieves the quota set on a resource
+func (r *PostgresQuotaRepository) Get(ctx context.Context, resource string) (*entities.Quota, error) {
+	var quota entities.Quota
+	err := r.db.WithContext(ctx).Where("resource = ?", resource).First(&quota).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrQuotaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// Save creates or replaces the quota on a resource
+func (r *PostgresQuotaRepository) Save(ctx context.Context, quota *entities.Quota) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "resource"}},
+		DoUpdates: clause.AssignmentColumns([]string{"limit", "updated_at"}),
+	}).Create(quota).Error
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PostgresSavedViewRepository implements SavedViewRepository using PostgreSQL
+type PostgresSavedViewRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresSavedViewRepository creates a new PostgreSQL saved view repository
+func NewPostgresSavedViewRepository(db *gorm.DB) repositories.SavedViewRepository {
+	return &PostgresSavedViewRepository{db: db}
+}
+
+// Create stores a new saved view
+func (r *PostgresSavedViewRepository) Create(ctx context.Context, view *entities.SavedView) error {
+	if view.ID == "" {
+		view.ID = generateSavedViewID()
+	}
+	return r.db.WithContext(ctx).Create(view).Error
+}
+
+// GetByID retrieves a saved view by ID
+func (r *PostgresSavedViewRepository) GetByID(ctx context.Context, id string) (*entities.SavedView, error) {
+	var view entities.SavedView
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&view).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrSavedViewNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
+
+// ListVisible retrieves the views of an owner and every shared view
+func (r *PostgresSavedViewRepository) ListVisible(ctx context.Context, ownerID string) ([]*entities.SavedView, error) {
+	var views []*entities.SavedView
+	err := r.db.WithContext(ctx).
+		Where("owner_id = ? OR shared", ownerID).
+		Order("name ASC").Order("id ASC").
+		Find(&views).Error
+	return views, err
+}
+
+// Delete removes a saved view
+func (r *PostgresSavedViewRepository) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&entities.SavedView{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrSavedViewNotFound
+	}
+	return nil
+}
+
+// generateSavedViewID generates a unique ID for saved views
+func generateSavedViewID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "view_" + time.Now().Format("20060102150405.000000")
+	}
+	return "view_" + hex.EncodeToString(randBytes)
+}
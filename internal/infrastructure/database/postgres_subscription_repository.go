@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PostgresSubscriptionRepository implements SubscriptionRepository using
+// PostgreSQL
+type PostgresSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresSubscriptionRepository creates a new PostgreSQL subscription
+// repository
+func NewPostgresSubscriptionRepository(db *gorm.DB) repositories.SubscriptionRepository {
+	return &PostgresSubscriptionRepository{db: db}
+}
+
+// GetByID retrieves a subscription by ID
+func (r *PostgresSubscriptionRepository) GetByID(ctx context.Context, id string) (*entities.Subscription, error) {
+	var subscription entities.Subscription
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&subscription).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Save creates or replaces a subscription
+func (r *PostgresSubscriptionRepository) Save(ctx context.Context, subscription *entities.Subscription) error {
+	return r.db.WithContext(ctx).Save(subscription).Error
+}
+
+// Current retrieves the most recently updated subscription granting its
+// plan
+func (r *PostgresSubscriptionRepository) Current(ctx context.Context) (*entities.Subscription, error) {
+	var subscription entities.Subscription
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", []string{entities.SubscriptionActive, entities.SubscriptionTrialing, entities.SubscriptionPastDue}).
+		Order("updated_at DESC").Order("id ASC").
+		First(&subscription).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PostgresUploadRepository implements UploadRepository using PostgreSQL
+type PostgresUploadRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresUploadRepository creates a new PostgreSQL upload repository
+func NewPostgresUploadRepository(db *gorm.DB) repositories.UploadRepository {
+	return &PostgresUploadRepository{db: db}
+}
+
+// Create stores a new upload
+func (r *PostgresUploadRepository) Create(ctx context.Context, upload *entities.Upload) error {
+	if upload.ID == "" {
+		upload.ID = generateUploadID()
+	}
+	return r.db.WithContext(ctx).Create(upload).Error
+}
+
+// GetByID retrieves an upload by ID
+func (r *PostgresUploadRepository) GetByID(ctx context.Context, id string) (*entities.Upload, error) {
+	var upload entities.Upload
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&upload).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// Update saves changes to an upload
+func (r *PostgresUploadRepository) Update(ctx context.Context, upload *entities.Upload) error {
+	result := r.db.WithContext(ctx).Model(upload).Select("*").Updates(upload)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrUploadNotFound
+	}
+	return nil
+}
+
+// Advance saves an upload that received a chunk if it is still active at
+// fromOffset
+func (r *PostgresUploadRepository) Advance(ctx context.Context, upload *entities.Upload, fromOffset int64) error {
+	result := r.db.WithContext(ctx).Model(upload).
+		Where("status = ? AND \"offset\" = ?", entities.UploadActive, fromOffset).
+		Select("*").Updates(upload)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrUploadConflict
+	}
+	return nil
+}
+
+// ListExpired retrieves active uploads whose expiry passed before now
+func (r *PostgresUploadRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entities.Upload, error) {
+	var uploads []*entities.Upload
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND expires_at <= ?", entities.UploadActive, now).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&uploads).Error
+	return uploads, err
+}
+
+// generateUploadID generates a unique ID for uploads
+func generateUploadID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "upl_" + time.Now().Format("20060102150405.000000")
+	}
+	return "upl_" + hex.EncodeToString(randBytes)
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresUsageRepository implements UsageRepository using PostgreSQL
+type PostgresUsageRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresUsageRepository creates a new PostgreSQL usage repository
+func NewPostgresUsageRepository(db *gorm.DB) repositories.UsageRepository {
+	return &PostgresUsageRepository{db: db}
+}
+
+// Add upserts the records in one statement, adding their counts to the
+// stored rollups
+func (r *PostgresUsageRepository) Add(ctx context.Context, records []*entities.UsageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "day"}, {Name: "key_id"}, {Name: "tenant_id"}, {Name: "method"}, {Name: "route"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":  gorm.Expr("usage_daily.requests + excluded.requests"),
+			"bytes_in":  gorm.Expr("usage_daily.bytes_in + excluded.bytes_in"),
+			"bytes_out": gorm.Expr("usage_daily.bytes_out + excluded.bytes_out"),
+		}),
+	}).Create(&records).Error
+}
+
+// List retrieves the rollups of the filtered days
+func (r *PostgresUsageRepository) List(ctx context.Context, filter repositories.UsageFilter) ([]*entities.UsageRecord, error) {
+	query := r.db.WithContext(ctx).Where("day BETWEEN ? AND ?", filter.From, filter.To)
+	if filter.KeyID != "" {
+		query = query.Where("key_id = ?", filter.KeyID)
+	}
+	if filter.TenantID != "" {
+		query = query.Where("tenant_id = ?", filter.TenantID)
+	}
+
+	var records []*entities.UsageRecord
+	if err := query.Order("day, key_id, tenant_id, route, method").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
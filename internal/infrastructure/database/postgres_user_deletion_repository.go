@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PostgresUserDeletionRepository implements UserDeletionRepository using PostgreSQL
+type PostgresUserDeletionRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresUserDeletionRepository creates a new PostgreSQL user deletion repository
+func NewPostgresUserDeletionRepository(db *gorm.DB) repositories.UserDeletionRepository {
+	return &PostgresUserDeletionRepository{db: db}
+}
+
+// Create stores a new deletion request
+func (r *PostgresUserDeletionRepository) Create(ctx context.Context, deletion *entities.UserDeletion) error {
+	if deletion.ID == "" {
+		deletion.ID = generateUserDeletionID()
+	}
+	return r.db.WithContext(ctx).Create(deletion).Error
+}
+
+// GetByID retrieves a deletion request by ID
+func (r *PostgresUserDeletionRepository) GetByID(ctx context.Context, id string) (*entities.UserDeletion, error) {
+	return r.first(r.db.WithContext(ctx).Where("id = ?", id))
+}
+
+// GetPendingByUserID retrieves the pending deletion request of a user
+func (r *PostgresUserDeletionRepository) GetPendingByUserID(ctx context.Context, userID string) (*entities.UserDeletion, error) {
+	return r.first(r.pending(ctx).Where("user_id = ?", userID))
+}
+
+// GetPendingByTokenHash retrieves the pending deletion request a
+// cancellation token was issued for
+func (r *PostgresUserDeletionRepository) GetPendingByTokenHash(ctx context.Context, tokenHash string) (*entities.UserDeletion, error) {
+	return r.first(r.pending(ctx).Where("cancel_token_hash = ?", tokenHash))
+}
+
+// ListPendingByUserIDs retrieves the pending deletion requests of the
+// given users
+func (r *PostgresUserDeletionRepository) ListPendingByUserIDs(ctx context.Context, userIDs []string) ([]*entities.UserDeletion, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var deletions []*entities.UserDeletion
+	err := r.pending(ctx).Where("user_id IN ?", userIDs).Find(&deletions).Error
+	return deletions, err
+}
+
+// ListPending retrieves pending deletion requests, soonest purge first
+func (r *PostgresUserDeletionRepository) ListPending(ctx context.Context, limit, offset int) ([]*entities.UserDeletion, error) {
+	var deletions []*entities.UserDeletion
+	err := pagedList(r.pending(ctx)).Order("purge_after ASC").Limit(limit).Offset(offset).Find(&deletions).Error
+	return deletions, err
+}
+
+// ListDue retrieves pending deletion requests whose grace period has ended
+func (r *PostgresUserDeletionRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*entities.UserDeletion, error) {
+	var deletions []*entities.UserDeletion
+	err := r.pending(ctx).Where("purge_after <= ?", now).Order("purge_after ASC").Limit(limit).Find(&deletions).Error
+	return deletions, err
+}
+
+// Update saves changes to a deletion request
+func (r *PostgresUserDeletionRepository) Update(ctx context.Context, deletion *entities.UserDeletion) error {
+	result := r.db.WithContext(ctx).Model(deletion).Select("*").Updates(deletion)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrUserDeletionNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserDeletionRepository) pending(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Where("cancelled_at IS NULL AND completed_at IS NULL")
+}
+
+func (r *PostgresUserDeletionRepository) first(query *gorm.DB) (*entities.UserDeletion, error) {
+	var deletion entities.UserDeletion
+	err := query.First(&deletion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrUserDeletionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// generateUserDeletionID generates a unique ID for deletion requests
+func generateUserDeletionID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "del_" + time.Now().Format("20060102150405.000000")
+	}
+	return "del_" + hex.EncodeToString(randBytes)
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresUserMergeRepository implements UserMergeRepository using PostgreSQL
+type PostgresUserMergeRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresUserMergeRepository creates a new PostgreSQL user merge repository
+func NewPostgresUserMergeRepository(db *gorm.DB) repositories.UserMergeRepository {
+	return &PostgresUserMergeRepository{db: db}
+}
+
+// Merge reassigns the loser's saved views to the winner, closes the
+// loser's pending deletion requests and email changes, and soft-deletes
+// the loser. Both users are locked first, so a concurrent merge or
+// deletion of either waits for this one and then finds it gone.
+func (r *PostgresUserMergeRepository) Merge(ctx context.Context, merge *entities.UserMerge) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked []string
+		err := tx.Model(&entities.User{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", []string{merge.WinnerID, merge.LoserID}).
+			Pluck("id", &locked).Error
+		if err != nil {
+			return err
+		}
+		if len(locked) != 2 {
+			return repositories.ErrUserNotFound
+		}
+
+		result := tx.Model(&entities.SavedView{}).
+			Where("owner_id = ?", merge.LoserID).
+			Updates(map[string]interface{}{"owner_id": merge.WinnerID, "updated_at": merge.MergedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		merge.SavedViews = result.RowsAffected
+
+		result = tx.Model(&entities.UserDeletion{}).
+			Where("user_id = ? AND cancelled_at IS NULL AND completed_at IS NULL", merge.LoserID).
+			Updates(map[string]interface{}{"cancelled_at": merge.MergedAt, "cancelled_by": entities.UserMergeCancelledBy})
+		if result.Error != nil {
+			return result.Error
+		}
+		merge.CancelledDeletions = result.RowsAffected
+
+		result = tx.Model(&entities.EmailChange{}).
+			Where("user_id = ? AND confirmed_at IS NULL AND superseded_at IS NULL", merge.LoserID).
+			Update("superseded_at", merge.MergedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		merge.SupersededEmailChanges = result.RowsAffected
+
+		return tx.Where("id = ?", merge.LoserID).Delete(&entities.User{}).Error
+	})
+}
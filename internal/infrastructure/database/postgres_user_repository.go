@@ -9,13 +9,18 @@ import (
 
 	"clean-architecture/internal/domain/entities"
 	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
 // PostgresUserRepository implements UserRepository interface using PostgreSQL
 type PostgresUserRepository struct {
 	db *gorm.DB
+	// replica serves reads that need not see the latest writes; nil reads
+	// everything from db
+	replica *gorm.DB
 }
 
 // NewPostgresUserRepository creates a new PostgreSQL user repository
@@ -23,35 +28,88 @@ func NewPostgresUserRepository(db *gorm.DB) repositories.UserRepository {
 	return &PostgresUserRepository{db: db}
 }
 
-// Create creates a new user
-func (r *PostgresUserRepository) Create(ctx context.Context, user *entities.User) error {
-	// Check if user with same email exists
-	var existingUser entities.User
-	if err := r.db.WithContext(ctx).Where("email = ?", user.Email).First(&existingUser).Error; err == nil {
-		return errors.New("user with this email already exists")
-	}
+// NewReplicatedUserRepository creates a PostgreSQL user repository reading
+// from replica, unless the context requires the latest writes, and writing
+// to db. A nil replica reads from db.
+func NewReplicatedUserRepository(db, replica *gorm.DB) repositories.UserRepository {
+	return &PostgresUserRepository{db: db, replica: replica}
+}
 
-	// Generate ID if not set
-	if user.ID == "" {
-		user.ID = generateID()
+// reader returns the connection for a read of the user with id, or of many
+// users when id is empty. Replicas lag behind the primary, so reads that
+// must see a recent write go to the primary.
+func (r *PostgresUserRepository) reader(ctx context.Context, id string) *gorm.DB {
+	if r.replica == nil || consistency.RequiresPrimary(ctx, repositories.UserResource, id) {
+		return r.db.WithContext(ctx)
 	}
+	return r.replica.WithContext(ctx)
+}
 
-	// Set timestamps if not set
-	now := time.Now()
-	if user.CreatedAt.IsZero() {
-		user.CreatedAt = now
-	}
-	if user.UpdatedAt.IsZero() {
-		user.UpdatedAt = now
+// Create creates a new user. When a deleted user had the same email and no
+// other ID is set, that user is reactivated with the new user's details
+// instead, keeping its ID and creation time.
+func (r *PostgresUserRepository) Create(ctx context.Context, user *entities.User) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Check if user with same email exists
+		var existingUser entities.User
+		if err := tx.Where("email = ?", user.Email).First(&existingUser).Error; err == nil {
+			return repositories.ErrUserAlreadyExists
+		}
+
+		// Set timestamps if not set
+		now := time.Now()
+		if user.CreatedAt.IsZero() {
+			user.CreatedAt = now
+		}
+		if user.UpdatedAt.IsZero() {
+			user.UpdatedAt = now
+		}
+
+		deletedUser, err := r.deletedUser(tx, user.Email)
+		if err != nil {
+			return err
+		}
+		if deletedUser != nil && (user.ID == "" || user.ID == deletedUser.ID) {
+			user.ID = deletedUser.ID
+			user.CreatedAt = deletedUser.CreatedAt
+			user.DeletedAt = gorm.DeletedAt{}
+			return tx.Unscoped().Save(user).Error
+		}
+
+		// Generate ID if not set
+		if user.ID == "" {
+			user.ID = generateID()
+		}
+		return tx.Create(user).Error
+	})
+	// A concurrent create of the same email passes the check above and
+	// fails on the unique index instead
+	if isUniqueViolation(err) {
+		return repositories.ErrUserAlreadyExists
 	}
+	return err
+}
 
-	return r.db.WithContext(ctx).Create(user).Error
+// deletedUser returns the most recently deleted user with email, or nil
+func (r *PostgresUserRepository) deletedUser(tx *gorm.DB, email string) (*entities.User, error) {
+	var user entities.User
+	err := tx.Unscoped().
+		Where("email = ? AND deleted_at IS NOT NULL", email).
+		Order("deleted_at DESC").
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
 }
 
 // GetByID retrieves a user by ID
 func (r *PostgresUserRepository) GetByID(ctx context.Context, id string) (*entities.User, error) {
 	var user entities.User
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error
+	err := r.reader(ctx, id).Where("id = ?", id).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -64,7 +122,7 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id string) (*entit
 // GetByEmail retrieves a user by email
 func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
 	var user entities.User
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	err := r.reader(ctx, "").Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -80,7 +138,7 @@ func (r *PostgresUserRepository) Update(ctx context.Context, user *entities.User
 	var existingUser entities.User
 	if err := r.db.WithContext(ctx).Where("id = ?", user.ID).First(&existingUser).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+			return repositories.ErrUserNotFound
 		}
 		return err
 	}
@@ -89,7 +147,45 @@ func (r *PostgresUserRepository) Update(ctx context.Context, user *entities.User
 	user.UpdatedAt = time.Now()
 	user.CreatedAt = existingUser.CreatedAt // Preserve original creation time
 
-	return r.db.WithContext(ctx).Save(user).Error
+	err := r.db.WithContext(ctx).Save(user).Error
+	if isUniqueViolation(err) {
+		return repositories.ErrUserAlreadyExists
+	}
+	return err
+}
+
+// upsertUserSQL inserts a user, or renames the user that is not deleted and
+// has the same email. xmax is 0 only for rows the statement inserted.
+const upsertUserSQL = `INSERT INTO users (id, email, name, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (email) WHERE deleted_at IS NULL
+DO UPDATE SET name = EXCLUDED.name, updated_at = EXCLUDED.updated_at
+RETURNING id, created_at, updated_at, xmax = 0 AS inserted`
+
+// Upsert creates a user, or renames the user with the same email. Unlike
+// Create it never reactivates a deleted user.
+func (r *PostgresUserRepository) Upsert(ctx context.Context, user *entities.User) (bool, error) {
+	id := user.ID
+	if id == "" {
+		id = generateID()
+	}
+	now := time.Now()
+
+	var row struct {
+		ID        string
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		Inserted  bool
+	}
+	err := r.db.WithContext(ctx).Raw(upsertUserSQL, id, user.Email, user.Name, now, now).Scan(&row).Error
+	if err != nil {
+		return false, err
+	}
+
+	user.ID = row.ID
+	user.CreatedAt = row.CreatedAt
+	user.UpdatedAt = row.UpdatedAt
+	return row.Inserted, nil
 }
 
 // Delete deletes a user
@@ -99,18 +195,108 @@ func (r *PostgresUserRepository) Delete(ctx context.Context, id string) error {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("user not found")
+		return repositories.ErrUserNotFound
 	}
 	return nil
 }
 
+// PurgeDeleted removes soft-deleted users for good, oldest deletions first
+func (r *PostgresUserRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	db := r.db.WithContext(ctx).Unscoped()
+	due := db.Model(&entities.User{}).
+		Select("id").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("deleted_at").
+		Limit(limit)
+	result := db.Where("id IN (?)", due).Delete(&entities.User{})
+	return result.RowsAffected, result.Error
+}
+
+// AdvanceMFAStep records step as the user's last accepted TOTP step unless
+// an equal or later one was recorded
+func (r *PostgresUserRepository) AdvanceMFAStep(ctx context.Context, id string, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&entities.User{}).
+		Where("id = ? AND mfa_last_step < ?", id, step).
+		Update("mfa_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
 // List retrieves a list of users
 func (r *PostgresUserRepository) List(ctx context.Context, limit, offset int) ([]*entities.User, error) {
 	var users []*entities.User
-	err := r.db.WithContext(ctx).Limit(limit).Offset(offset).Find(&users).Error
+	err := pagedList(r.reader(ctx, "")).Where("kind = ?", entities.UserKindHuman).Limit(limit).Offset(offset).Find(&users).Error
+	return users, err
+}
+
+// userColumns maps the fields user specifications may filter and sort on
+// to columns
+var userColumns = map[string]string{
+	"id":         "id",
+	"email":      "email",
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+
+	repositories.UserKindField: "kind",
+	"organization_id":          "organization_id",
+}
+
+// Find retrieves the users matching spec
+func (r *PostgresUserRepository) Find(ctx context.Context, spec repositories.Specification) ([]*entities.User, error) {
+	query, err := applySpecification(pagedList(r.reader(ctx, "")), userColumns, humansUnlessKind(spec))
+	if err != nil {
+		return nil, err
+	}
+	var users []*entities.User
+	err = query.Find(&users).Error
 	return users, err
 }
 
+// Count returns the number of users that are not deleted or service
+// accounts
+func (r *PostgresUserRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.reader(ctx, "").Model(&entities.User{}).Where("kind = ?", entities.UserKindHuman).Count(&count).Error
+	return count, err
+}
+
+// CountMatching returns the number of users matching spec's conditions
+func (r *PostgresUserRepository) CountMatching(ctx context.Context, spec repositories.Specification) (int64, error) {
+	query, err := applySpecification(r.reader(ctx, "").Model(&entities.User{}), userColumns, humansUnlessKind(repositories.Specification{Conditions: spec.Conditions}))
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = query.Count(&count).Error
+	return count, err
+}
+
+// humansUnlessKind returns spec restricted to users who are not service
+// accounts, unless it has a condition on their kind already
+func humansUnlessKind(spec repositories.Specification) repositories.Specification {
+	for _, condition := range spec.Conditions {
+		if condition.Field == repositories.UserKindField {
+			return spec
+		}
+	}
+	spec.Conditions = append(spec.Conditions[:len(spec.Conditions):len(spec.Conditions)], repositories.Condition{
+		Field:    repositories.UserKindField,
+		Operator: repositories.OpEq,
+		Value:    entities.UserKindHuman,
+	})
+	return spec
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint
+// violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
 // generateID generates a unique ID for users
 func generateID() string {
 	randBytes := make([]byte, 16)
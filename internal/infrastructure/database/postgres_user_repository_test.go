@@ -2,14 +2,19 @@ package database
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"clean-architecture/configs"
 	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
 )
 
 func TestPostgresUserRepository_Create(t *testing.T) {
@@ -429,3 +434,208 @@ func TestPostgresUserRepository_List(t *testing.T) {
 		})
 	}
 }
+
+func TestPostgresUserRepository_Count(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database tests in short mode")
+	}
+
+	// Load configuration
+	cfg, err := configs.Load()
+	require.NoError(t, err)
+
+	err = InitDatabase(cfg)
+	require.NoError(t, err)
+	defer CloseDatabase()
+
+	db := GetDB()
+	repo := NewPostgresUserRepository(db)
+
+	defer func() {
+		db.Exec("DELETE FROM users")
+	}()
+
+	user1 := &entities.User{Email: "user1@example.com", Name: "User 1"}
+	user2 := &entities.User{Email: "user2@example.com", Name: "User 2"}
+	require.NoError(t, repo.Create(context.Background(), user1))
+	require.NoError(t, repo.Create(context.Background(), user2))
+
+	count, err := repo.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Deleted users are not counted
+	require.NoError(t, repo.Delete(context.Background(), user1.ID))
+
+	count, err = repo.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestPostgresUserRepository_ReadsFromReplica(t *testing.T) {
+	primary, replica := dryRunDB(t), dryRunDB(t)
+	repo := NewReplicatedUserRepository(primary, replica).(*PostgresUserRepository)
+	ctx := context.Background()
+
+	assert.Same(t, replica.ConnPool, repo.reader(ctx, "user_1").ConnPool)
+	assert.Same(t, replica.ConnPool, repo.reader(ctx, "").ConnPool)
+
+	written := consistency.WithToken(ctx, consistency.Token{Resource: repositories.UserResource, ID: "user_1", WrittenAt: time.Now()})
+	assert.Same(t, primary.ConnPool, repo.reader(written, "user_1").ConnPool, "the written user is read from the primary")
+	assert.Same(t, primary.ConnPool, repo.reader(written, "").ConnPool, "lists include the written user")
+	assert.Same(t, replica.ConnPool, repo.reader(written, "user_2").ConnPool)
+
+	assert.Same(t, primary.ConnPool, repo.reader(consistency.WithPrimary(ctx), "user_2").ConnPool)
+
+	unreplicated := NewReplicatedUserRepository(primary, nil).(*PostgresUserRepository)
+	assert.Same(t, primary.ConnPool, unreplicated.reader(ctx, "user_1").ConnPool)
+}
+
+func TestPostgresUserRepository_DeleteThenRecreate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database tests in short mode")
+	}
+
+	cfg, err := configs.Load()
+	require.NoError(t, err)
+
+	err = InitDatabase(cfg)
+	require.NoError(t, err)
+	defer CloseDatabase()
+
+	db := GetDB()
+	repo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	defer func() {
+		db.Exec("DELETE FROM users")
+	}()
+
+	user := &entities.User{Email: "test@example.com", Name: "Test User"}
+	require.NoError(t, repo.Create(ctx, user))
+	require.NoError(t, repo.Delete(ctx, user.ID))
+
+	// The deleted user is reactivated with the new details
+	recreated := &entities.User{Email: "test@example.com", Name: "Returning User"}
+	require.NoError(t, repo.Create(ctx, recreated))
+	assert.Equal(t, user.ID, recreated.ID)
+	assert.True(t, recreated.CreatedAt.Equal(user.CreatedAt))
+
+	found, err := repo.GetByEmail(ctx, "test@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "Returning User", found.Name)
+	assert.False(t, found.DeletedAt.Valid)
+
+	// Active users still block their email
+	err = repo.Create(ctx, &entities.User{Email: "test@example.com", Name: "Duplicate"})
+	assert.ErrorIs(t, err, repositories.ErrUserAlreadyExists)
+
+	// Users with an ID of their own are created beside the deleted user
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	replacement := &entities.User{ID: "user_replacement", Email: "test@example.com", Name: "Replacement"}
+	require.NoError(t, repo.Create(ctx, replacement))
+
+	var rows int64
+	require.NoError(t, db.Unscoped().Model(&entities.User{}).Where("email = ?", "test@example.com").Count(&rows).Error)
+	assert.Equal(t, int64(2), rows)
+}
+
+func TestPostgresUserRepository_PurgeDeleted(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database tests in short mode")
+	}
+
+	cfg, err := configs.Load()
+	require.NoError(t, err)
+
+	err = InitDatabase(cfg)
+	require.NoError(t, err)
+	defer CloseDatabase()
+
+	db := GetDB()
+	repo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	defer func() {
+		db.Exec("DELETE FROM users")
+	}()
+
+	var users []*entities.User
+	for _, email := range []string{"old@example.com", "older@example.com", "recent@example.com", "active@example.com"} {
+		user := &entities.User{Email: email, Name: "Test User"}
+		require.NoError(t, repo.Create(ctx, user))
+		users = append(users, user)
+	}
+	now := time.Now()
+	for i, deletedAt := range []time.Time{now.Add(-48 * time.Hour), now.Add(-72 * time.Hour), now} {
+		require.NoError(t, db.Unscoped().Model(&entities.User{}).Where("id = ?", users[i].ID).Update("deleted_at", deletedAt).Error)
+	}
+
+	purged, err := repo.PurgeDeleted(ctx, now.Add(-24*time.Hour), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	var rows int64
+	require.NoError(t, db.Unscoped().Model(&entities.User{}).Where("id = ?", users[1].ID).Count(&rows).Error)
+	assert.Zero(t, rows, "the oldest deletion is purged first")
+
+	purged, err = repo.PurgeDeleted(ctx, now.Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	require.NoError(t, db.Unscoped().Model(&entities.User{}).Count(&rows).Error)
+	assert.Equal(t, int64(2), rows, "recent deletions and active users are kept")
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	assert.True(t, isUniqueViolation(fmt.Errorf("create: %w", &pgconn.PgError{Code: "23505"})))
+	assert.False(t, isUniqueViolation(&pgconn.PgError{Code: "23503"}))
+	assert.False(t, isUniqueViolation(errors.New("connection refused")))
+	assert.False(t, isUniqueViolation(nil))
+}
+
+func TestPostgresUserRepository_Upsert(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping database tests in short mode")
+	}
+
+	cfg, err := configs.Load()
+	require.NoError(t, err)
+
+	err = InitDatabase(cfg)
+	require.NoError(t, err)
+	defer CloseDatabase()
+
+	db := GetDB()
+	repo := NewPostgresUserRepository(db)
+	ctx := context.Background()
+
+	defer func() {
+		db.Exec("DELETE FROM users")
+	}()
+
+	user := &entities.User{Email: "test@example.com", Name: "Test User"}
+	created, err := repo.Upsert(ctx, user)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.NotEmpty(t, user.ID)
+
+	renamed := &entities.User{Email: "test@example.com", Name: "Renamed"}
+	created, err = repo.Upsert(ctx, renamed)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, user.ID, renamed.ID)
+	assert.True(t, renamed.CreatedAt.Equal(user.CreatedAt))
+
+	found, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, "Renamed", found.Name)
+
+	// Deleted users do not match, so their email gets a new user
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	recreated := &entities.User{Email: "test@example.com", Name: "Recreated"}
+	created, err = repo.Upsert(ctx, recreated)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.NotEqual(t, user.ID, recreated.ID)
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresWebhookRepository implements WebhookRepository using PostgreSQL
+type PostgresWebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresWebhookRepository creates a new PostgreSQL webhook repository
+func NewPostgresWebhookRepository(db *gorm.DB) repositories.WebhookRepository {
+	return &PostgresWebhookRepository{db: db}
+}
+
+// Create stores a new webhook
+func (r *PostgresWebhookRepository) Create(ctx context.Context, webhook *entities.Webhook) error {
+	if webhook.ID == "" {
+		webhook.ID = generateWebhookID()
+	}
+	return r.db.WithContext(ctx).Create(webhook).Error
+}
+
+// GetByID retrieves a webhook by ID
+func (r *PostgresWebhookRepository) GetByID(ctx context.Context, id string) (*entities.Webhook, error) {
+	var webhook entities.Webhook
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&webhook).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repositories.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// List retrieves every webhook, oldest first
+func (r *PostgresWebhookRepository) List(ctx context.Context) ([]*entities.Webhook, error) {
+	var webhooks []*entities.Webhook
+	err := r.db.WithContext(ctx).
+		Order("created_at ASC").Order("id ASC").
+		Find(&webhooks).Error
+	return webhooks, err
+}
+
+// Update saves every field of a webhook
+func (r *PostgresWebhookRepository) Update(ctx context.Context, webhook *entities.Webhook) error {
+	result := r.db.WithContext(ctx).Model(webhook).Select("*").Updates(webhook)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// Delete removes a webhook and its deliveries
+func (r *PostgresWebhookRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&entities.Webhook{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return repositories.ErrWebhookNotFound
+		}
+		return tx.Where("webhook_id = ?", id).Delete(&entities.WebhookDelivery{}).Error
+	})
+}
+
+// CreateDelivery inserts a delivery; delivering the same event to a webhook
+// twice is a no-op
+func (r *PostgresWebhookRepository) CreateDelivery(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	if delivery.ID == "" {
+		delivery.ID = generateWebhookDeliveryID()
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}},
+		DoNothing: true,
+	}).Create(delivery).Error
+}
+
+// ListDueDeliveries retrieves the pending deliveries due at now
+func (r *PostgresWebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time, limit int) ([]*entities.WebhookDelivery, error) {
+	var deliveries []*entities.WebhookDelivery
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", entities.WebhookDeliveryPending, now).
+		Order("next_attempt_at ASC").Order("id ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ListDeliveries retrieves the deliveries of a webhook, newest first
+func (r *PostgresWebhookRepository) ListDeliveries(ctx context.Context, webhookID string, limit, offset int) ([]*entities.WebhookDelivery, error) {
+	var deliveries []*entities.WebhookDelivery
+	err := pagedList(r.db.WithContext(ctx)).
+		Where("webhook_id = ?", webhookID).
+		Order("created_at DESC").Order("id DESC").
+		Limit(limit).Offset(offset).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// UpdateDelivery saves every field of a delivery
+func (r *PostgresWebhookRepository) UpdateDelivery(ctx context.Context, delivery *entities.WebhookDelivery) error {
+	result := r.db.WithContext(ctx).Model(delivery).Select("*").Updates(delivery)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repositories.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// generateWebhookID generates a unique ID for webhooks
+func generateWebhookID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "whk_" + time.Now().Format("20060102150405.000000")
+	}
+	return "whk_" + hex.EncodeToString(randBytes)
+}
+
+// generateWebhookDeliveryID generates a unique ID for webhook deliveries
+func generateWebhookDeliveryID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "whd_" + time.Now().Format("20060102150405.000000")
+	}
+	return "whd_" + hex.EncodeToString(randBytes)
+}
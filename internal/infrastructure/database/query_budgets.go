@@ -0,0 +1,237 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	gormcallbacks "gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// queryGuardKey stores a statement's guard between the callbacks around it
+const queryGuardKey = "query_budgets:guard"
+
+// pagedListKey marks the lists served to API callers page by page
+const pagedListKey = "query_budgets:paged_list"
+
+// pagedList marks the lists of db as pages of an API listing, the only
+// lists held to the MaxRows of the list budget. Internal reads such as
+// usage reports and audit verification return every row they select.
+func pagedList(db *gorm.DB) *gorm.DB {
+	return db.Set(pagedListKey, true)
+}
+
+// QueryBudget caps the statements of one operation class. Zero values
+// disable a cap.
+type QueryBudget struct {
+	// Timeout cancels statements running longer, which makes PostgreSQL
+	// stop them as statement_timeout would
+	Timeout time.Duration
+	// MaxRows caps the rows a paged API list returns
+	MaxRows int
+	// MaxRowsScanned caps the rows the planner expects a list to scan. Each
+	// list is planned with EXPLAIN before it runs, costing a round trip.
+	MaxRowsScanned int
+}
+
+// QueryBudgets is a gorm plugin stopping statements that exceed the budget
+// of their operation class, so a pathological filter fails fast with a
+// repositories.QueryBudgetError instead of tying up the database. Queries
+// into a slice are lists, other queries reads, and every other statement a
+// write. Rows read through Row and Rows are streamed by the caller and not
+// guarded.
+type QueryBudgets struct {
+	budgets map[string]QueryBudget
+	logger  logger.Logger
+
+	// explain is replaced in tests
+	explain func(db *gorm.DB) ([]byte, error)
+}
+
+// NewQueryBudgets creates the plugin enforcing budgets, keyed by
+// repositories.OperationRead, OperationList and OperationWrite
+func NewQueryBudgets(budgets map[string]QueryBudget, logger logger.Logger) *QueryBudgets {
+	return &QueryBudgets{budgets: budgets, logger: logger, explain: explainQuery}
+}
+
+// Name implements gorm.Plugin
+func (p *QueryBudgets) Name() string {
+	return "query_budgets"
+}
+
+// Initialize implements gorm.Plugin
+func (p *QueryBudgets) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("query_budgets:start", p.start(repositories.OperationWrite)),
+		callbacks.Create().After("gorm:create").Register("query_budgets:end", p.end),
+		callbacks.Query().Before("gorm:query").Register("query_budgets:start", p.start("")),
+		callbacks.Query().After("gorm:query").Register("query_budgets:end", p.end),
+		callbacks.Update().Before("gorm:update").Register("query_budgets:start", p.start(repositories.OperationWrite)),
+		callbacks.Update().After("gorm:update").Register("query_budgets:end", p.end),
+		callbacks.Delete().Before("gorm:delete").Register("query_budgets:start", p.start(repositories.OperationWrite)),
+		callbacks.Delete().After("gorm:delete").Register("query_budgets:end", p.end),
+		callbacks.Raw().Before("gorm:raw").Register("query_budgets:start", p.start(repositories.OperationWrite)),
+		callbacks.Raw().After("gorm:raw").Register("query_budgets:end", p.end),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryGuard holds the budget of a running statement
+type queryGuard struct {
+	operation string
+	parent    context.Context
+	ctx       context.Context
+	cancel    context.CancelFunc
+	timeout   time.Duration
+	// maxRows is set when the statement's LIMIT was lowered to detect
+	// lists returning more rows than their budget
+	maxRows int
+}
+
+// start applies the budget of operation to a statement; an empty operation
+// classifies queries as lists or reads by their destination
+func (p *QueryBudgets) start(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		if db.Error != nil || stmt.Context == nil {
+			return
+		}
+		operation := operation
+		if operation == "" {
+			operation = repositories.OperationRead
+			if intoSlice(stmt.Dest) {
+				operation = repositories.OperationList
+			}
+		}
+		budget, ok := p.budgets[operation]
+		if !ok {
+			return
+		}
+
+		guard := &queryGuard{operation: operation, parent: stmt.Context}
+		if _, paged := db.Get(pagedListKey); paged && operation == repositories.OperationList && budget.MaxRows > 0 {
+			guard.maxRows = capRows(stmt, budget.MaxRows)
+		}
+		if budget.Timeout > 0 {
+			guard.timeout = budget.Timeout
+			guard.ctx, guard.cancel = context.WithTimeout(stmt.Context, budget.Timeout)
+			stmt.Context = guard.ctx
+		}
+		db.InstanceSet(queryGuardKey, guard)
+
+		if operation == repositories.OperationList && budget.MaxRowsScanned > 0 {
+			p.checkScan(db, guard, budget.MaxRowsScanned)
+		}
+	}
+}
+
+// capRows lowers the LIMIT of stmt to one more than max, so a list
+// returning more rows than max is detected without reading them all. It
+// returns max, or 0 when the statement's own LIMIT is within it already.
+func capRows(stmt *gorm.Statement, max int) int {
+	var limit clause.Limit
+	if c, ok := stmt.Clauses["LIMIT"]; ok {
+		limit, _ = c.Expression.(clause.Limit)
+	}
+	if limit.Limit != nil && *limit.Limit <= max {
+		return 0
+	}
+	capped := max + 1
+	limit.Limit = &capped
+	stmt.AddClause(limit)
+	return max
+}
+
+// checkScan builds the SQL of a list and refuses to run it when the planner
+// expects it to scan more than max rows. Lists that cannot be planned run
+// anyway.
+func (p *QueryBudgets) checkScan(db *gorm.DB, guard *queryGuard, max int) {
+	gormcallbacks.BuildQuerySQL(db)
+	if db.Error != nil {
+		return
+	}
+	plan, err := p.plan(db)
+	if err != nil {
+		p.logger.WithFields(map[string]interface{}{
+			"table": db.Statement.Table,
+			"error": err.Error(),
+		}).Debug("Failed to explain list query")
+		return
+	}
+	if plan.RowsScanned > float64(max) {
+		p.exceeded(db, guard, fmt.Sprintf("would scan about %.0f rows, more than %d", plan.RowsScanned, max))
+	}
+}
+
+func (p *QueryBudgets) plan(db *gorm.DB) (*QueryPlan, error) {
+	output, err := p.explain(db)
+	if err != nil {
+		return nil, err
+	}
+	return parseQueryPlan(output)
+}
+
+// end releases the statement's timeout and reports the statement when it
+// exceeded its budget
+func (p *QueryBudgets) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(queryGuardKey)
+	if !ok {
+		return
+	}
+	guard := value.(*queryGuard)
+	timedOut := false
+	if guard.cancel != nil {
+		timedOut = errors.Is(guard.ctx.Err(), context.DeadlineExceeded)
+		guard.cancel()
+		db.Statement.Context = guard.parent
+	}
+	if errors.Is(db.Error, repositories.ErrQueryBudgetExceeded) {
+		return
+	}
+
+	switch {
+	case db.Error != nil && guard.parent.Err() == nil && (timedOut || isQueryCanceled(db.Error)):
+		reason := "was canceled by the database's statement_timeout"
+		if timedOut {
+			reason = fmt.Sprintf("ran longer than %s", guard.timeout)
+		}
+		p.exceeded(db, guard, reason)
+	case db.Error == nil && guard.maxRows > 0 && db.RowsAffected > int64(guard.maxRows):
+		p.exceeded(db, guard, fmt.Sprintf("returned more than %d rows", guard.maxRows))
+	}
+}
+
+// exceeded fails the statement with a QueryBudgetError
+func (p *QueryBudgets) exceeded(db *gorm.DB, guard *queryGuard, reason string) {
+	err := &repositories.QueryBudgetError{
+		Operation: guard.operation,
+		Table:     db.Statement.Table,
+		Reason:    reason,
+	}
+	p.logger.WithFields(map[string]interface{}{
+		"operation": err.Operation,
+		"table":     err.Table,
+		"reason":    err.Reason,
+		"sql":       db.Statement.SQL.String(),
+	}).Warn("Query exceeded its budget")
+	db.Error = err
+}
+
+// isQueryCanceled reports whether err is PostgreSQL canceling a statement,
+// as it does once statement_timeout passes
+func isQueryCanceled(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "57014"
+}
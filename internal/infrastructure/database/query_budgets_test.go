@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+func TestQueryBudgets_CapsListRows(t *testing.T) {
+	db := dryRunDB(t)
+	require.NoError(t, db.Use(NewQueryBudgets(map[string]QueryBudget{
+		repositories.OperationList: {MaxRows: 100},
+	}, logger.New())))
+
+	var users []*entities.User
+	result := pagedList(db.WithContext(context.Background())).Find(&users)
+	require.NoError(t, result.Error)
+	assert.Equal(t, clause.Limit{Limit: intPtr(101)}, limitOf(result))
+
+	result = pagedList(db.WithContext(context.Background())).Limit(20).Offset(40).Find(&users)
+	require.NoError(t, result.Error)
+	assert.Equal(t, clause.Limit{Limit: intPtr(20), Offset: 40}, limitOf(result))
+
+	result = pagedList(db.WithContext(context.Background())).Limit(500).Offset(40).Find(&users)
+	require.NoError(t, result.Error)
+	assert.Equal(t, clause.Limit{Limit: intPtr(101), Offset: 40}, limitOf(result))
+
+	// Reads of a single row are not lists
+	var user entities.User
+	result = db.WithContext(context.Background()).First(&user)
+	require.NoError(t, result.Error)
+	assert.Equal(t, clause.Limit{Limit: intPtr(1)}, limitOf(result))
+}
+
+func limitOf(db *gorm.DB) clause.Limit {
+	limit, _ := db.Statement.Clauses["LIMIT"].Expression.(clause.Limit)
+	return limit
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestQueryBudgets_RowsExceeded(t *testing.T) {
+	db := dryRunDB(t)
+	require.NoError(t, db.Use(NewQueryBudgets(map[string]QueryBudget{
+		repositories.OperationList: {MaxRows: 100},
+	}, logger.New())))
+	rows := int64(101)
+	require.NoError(t, db.Callback().Query().After("gorm:query").Before("query_budgets:end").
+		Register("test:rows", func(db *gorm.DB) { db.RowsAffected = rows }))
+
+	_, err := NewPostgresUserRepository(db).List(context.Background(), 500, 0)
+	var budgetErr *repositories.QueryBudgetError
+	require.ErrorAs(t, err, &budgetErr)
+	assert.ErrorIs(t, err, repositories.ErrQueryBudgetExceeded)
+	assert.Equal(t, "list query on users returned more than 100 rows", budgetErr.Error())
+
+	rows = 100
+	_, err = NewPostgresUserRepository(db).List(context.Background(), 500, 0)
+	assert.NoError(t, err)
+}
+
+func TestQueryBudgets_InternalReadsNotCapped(t *testing.T) {
+	db := dryRunDB(t)
+	require.NoError(t, db.Use(NewQueryBudgets(map[string]QueryBudget{
+		repositories.OperationList: {MaxRows: 100},
+	}, logger.New())))
+	var limits []clause.Limit
+	require.NoError(t, db.Callback().Query().After("gorm:query").Before("query_budgets:end").
+		Register("test:rows", func(db *gorm.DB) {
+			limits = append(limits, limitOf(db))
+			db.RowsAffected = 101
+		}))
+
+	now := time.Now()
+	_, err := NewPostgresUsageRepository(db).List(context.Background(), repositories.UsageFilter{From: now.AddDate(0, 0, -366), To: now})
+	assert.NoError(t, err)
+	_, err = NewPostgresAuditRepository(db).ListAnchors(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []clause.Limit{{}, {}}, limits)
+}
+
+func TestQueryBudgets_RowsScanned(t *testing.T) {
+	db := dryRunDB(t)
+	plugin := NewQueryBudgets(map[string]QueryBudget{
+		repositories.OperationList: {MaxRowsScanned: 500},
+	}, logger.New())
+	plugin.explain = func(db *gorm.DB) ([]byte, error) {
+		return []byte(explainOutput), nil
+	}
+	require.NoError(t, db.Use(plugin))
+	ran := false
+	require.NoError(t, db.Callback().Query().Before("gorm:query").After("query_budgets:start").
+		Register("test:ran", func(db *gorm.DB) { ran = db.Error == nil }))
+
+	_, err := NewPostgresUserRepository(db).Find(context.Background(), repositories.Specification{Limit: 20})
+	assert.EqualError(t, err, "list query on users would scan about 640 rows, more than 500")
+	assert.False(t, ran, "the list is refused before it runs")
+
+	plugin.explain = func(db *gorm.DB) ([]byte, error) {
+		return nil, errors.New("explain failed")
+	}
+	_, err = NewPostgresUserRepository(db).Find(context.Background(), repositories.Specification{Limit: 20})
+	assert.NoError(t, err, "lists that cannot be planned run anyway")
+}
+
+func TestQueryBudgets_Timeout(t *testing.T) {
+	db := dryRunDB(t)
+	require.NoError(t, db.Use(NewQueryBudgets(map[string]QueryBudget{
+		repositories.OperationRead:  {Timeout: 10 * time.Millisecond},
+		repositories.OperationWrite: {Timeout: time.Minute},
+	}, logger.New())))
+	// Stands in for a query running until its context is done
+	require.NoError(t, db.Callback().Query().After("gorm:query").Before("query_budgets:end").
+		Register("test:slow", func(db *gorm.DB) {
+			<-db.Statement.Context.Done()
+			db.AddError(db.Statement.Context.Err())
+		}))
+	repo := NewPostgresUserRepository(db)
+
+	_, err := repo.GetByID(context.Background(), "user_1")
+	var budgetErr *repositories.QueryBudgetError
+	require.ErrorAs(t, err, &budgetErr)
+	assert.Equal(t, repositories.OperationRead, budgetErr.Operation)
+	assert.Equal(t, "ran longer than 10ms", budgetErr.Reason)
+
+	// A caller giving up is not a budget exceeded
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = repo.GetByID(ctx, "user_1")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, repositories.ErrQueryBudgetExceeded)
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"gorm.io/gorm"
+
+	"clean-architecture/pkg/cost"
+)
+
+// QueryCosts is a gorm plugin counting each statement run for a request
+// towards the request's cost.Counter, with the rows returned by queries.
+// Preloads run statements of their own, so an association loaded per row
+// shows up as one query per row.
+type QueryCosts struct{}
+
+// NewQueryCosts creates the plugin
+func NewQueryCosts() *QueryCosts {
+	return &QueryCosts{}
+}
+
+// Name implements gorm.Plugin
+func (p *QueryCosts) Name() string {
+	return "query_costs"
+}
+
+// Initialize implements gorm.Plugin
+func (p *QueryCosts) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After("gorm:create").Register("query_costs:count", p.count(false)),
+		callbacks.Query().After("gorm:query").Register("query_costs:count", p.count(true)),
+		callbacks.Update().After("gorm:update").Register("query_costs:count", p.count(false)),
+		callbacks.Delete().After("gorm:delete").Register("query_costs:count", p.count(false)),
+		callbacks.Row().After("gorm:row").Register("query_costs:count", p.count(false)),
+		callbacks.Raw().After("gorm:raw").Register("query_costs:count", p.count(false)),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count counts a statement, with the rows it scanned when returning is set.
+// Rows read through Row and Rows are scanned by the caller and not counted.
+func (p *QueryCosts) count(returning bool) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		if stmt.Context == nil {
+			return
+		}
+		var rows int64
+		if returning && db.Error == nil {
+			rows = db.RowsAffected
+		}
+		cost.AddQuery(stmt.Context, rows)
+	}
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/cost"
+)
+
+func TestQueryCosts(t *testing.T) {
+	db := dryRunDB(t)
+	require.NoError(t, db.Use(NewQueryCosts()))
+	repo := NewPostgresUserRepository(db)
+
+	counter := cost.NewCounter()
+	ctx := cost.WithCounter(context.Background(), counter)
+	_, err := repo.Find(ctx, repositories.Specification{Limit: 20})
+	require.NoError(t, err)
+	_, err = repo.Count(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(2), counter.Costs().Queries)
+
+	// Statements of requests without a counter are not counted
+	_, err = repo.List(context.Background(), 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), counter.Costs().Queries)
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+
+	"clean-architecture/pkg/timing"
+)
+
+// queryTimerKey stores a statement's timer between the callbacks around it
+const queryTimerKey = "query_timings:timer"
+
+// QueryTimings is a gorm plugin recording each statement run for a request
+// as a repository span of the request's timing.Recorder. The SQL is
+// attached as soon as it is sent, so slow requests show the query still
+// running.
+type QueryTimings struct{}
+
+// NewQueryTimings creates the plugin
+func NewQueryTimings() *QueryTimings {
+	return &QueryTimings{}
+}
+
+// Name implements gorm.Plugin
+func (p *QueryTimings) Name() string {
+	return "query_timings"
+}
+
+// Initialize implements gorm.Plugin. The callbacks run right around the
+// statement itself, inside any transaction gorm opened for it.
+func (p *QueryTimings) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("query_timings:start", p.start("create")),
+		callbacks.Create().After("gorm:create").Register("query_timings:end", p.end),
+		callbacks.Query().Before("gorm:query").Register("query_timings:start", p.start("query")),
+		callbacks.Query().After("gorm:query").Register("query_timings:end", p.end),
+		callbacks.Update().Before("gorm:update").Register("query_timings:start", p.start("update")),
+		callbacks.Update().After("gorm:update").Register("query_timings:end", p.end),
+		callbacks.Delete().Before("gorm:delete").Register("query_timings:start", p.start("delete")),
+		callbacks.Delete().After("gorm:delete").Register("query_timings:end", p.end),
+		callbacks.Row().Before("gorm:row").Register("query_timings:start", p.start("row")),
+		callbacks.Row().After("gorm:row").Register("query_timings:end", p.end),
+		callbacks.Raw().Before("gorm:raw").Register("query_timings:start", p.start("raw")),
+		callbacks.Raw().After("gorm:raw").Register("query_timings:end", p.end),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start begins the span of a statement when its request is being timed,
+// and routes the statement through a pool that describes the span with
+// the SQL it sends
+func (p *QueryTimings) start(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		if stmt.Context == nil || timing.FromContext(stmt.Context) == nil {
+			return
+		}
+		name := operation
+		if stmt.Table != "" {
+			name += " " + stmt.Table
+		}
+		timer := timing.Start(stmt.Context, timing.LayerRepository, name)
+		db.InstanceSet(queryTimerKey, timer)
+		stmt.ConnPool = &describingPool{ConnPool: stmt.ConnPool, timer: timer}
+	}
+}
+
+// end ends the span begun by start and restores the statement's pool, which
+// gorm needs unwrapped to commit the transaction
+func (p *QueryTimings) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(queryTimerKey)
+	if !ok {
+		return
+	}
+	timer := value.(*timing.Timer)
+	if pool, ok := db.Statement.ConnPool.(*describingPool); ok {
+		db.Statement.ConnPool = pool.ConnPool
+	}
+	if sql := db.Statement.SQL.String(); sql != "" {
+		timer.Describe(sql)
+	}
+	timer.End()
+}
+
+// describingPool describes a span with the SQL sent through it
+type describingPool struct {
+	gorm.ConnPool
+	timer *timing.Timer
+}
+
+func (p *describingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	p.timer.Describe(query)
+	return p.ConnPool.PrepareContext(ctx, query)
+}
+
+func (p *describingPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.timer.Describe(query)
+	return p.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (p *describingPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	p.timer.Describe(query)
+	return p.ConnPool.QueryContext(ctx, query, args...)
+}
+
+func (p *describingPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	p.timer.Describe(query)
+	return p.ConnPool.QueryRowContext(ctx, query, args...)
+}
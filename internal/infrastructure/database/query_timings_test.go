@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/timing"
+)
+
+func TestQueryTimings(t *testing.T) {
+	db := dryRunDB(t)
+	require.NoError(t, db.Use(NewQueryTimings()))
+	repo := NewPostgresUserRepository(db)
+
+	rec := timing.NewRecorder()
+	ctx := timing.WithRecorder(context.Background(), rec)
+	_, err := repo.Find(ctx, repositories.Specification{Limit: 20})
+	require.NoError(t, err)
+	_, err = repo.Count(ctx)
+	require.NoError(t, err)
+
+	spans := rec.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, timing.LayerRepository, spans[0].Layer)
+	assert.Equal(t, "query users", spans[0].Name)
+	assert.Contains(t, spans[0].Detail, `FROM "users"`)
+	assert.False(t, spans[0].Active)
+	assert.Equal(t, "query users", spans[1].Name)
+	assert.Contains(t, spans[1].Detail, "SELECT count(*)")
+	assert.Contains(t, rec.Layers(), timing.LayerRepository)
+	_, wrapped := db.Statement.ConnPool.(*describingPool)
+	assert.False(t, wrapped, "the pool is restored after the statement")
+}
+
+func TestQueryTimings_WithoutRecorder(t *testing.T) {
+	db := dryRunDB(t)
+	require.NoError(t, db.Use(NewQueryTimings()))
+
+	_, err := NewPostgresUserRepository(db).List(context.Background(), 10, 0)
+	assert.NoError(t, err)
+}
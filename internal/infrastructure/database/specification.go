@@ -0,0 +1,75 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"clean-architecture/internal/domain/repositories"
+
+	"gorm.io/gorm"
+)
+
+// likeEscaper escapes the wildcards of LIKE patterns
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// comparisons maps ordering and equality operators to SQL
+var comparisons = map[repositories.Operator]string{
+	repositories.OpEq:  "=",
+	repositories.OpNe:  "<>",
+	repositories.OpGt:  ">",
+	repositories.OpGte: ">=",
+	repositories.OpLt:  "<",
+	repositories.OpLte: "<=",
+}
+
+// applySpecification adds the conditions, order and pagination of spec to
+// query. columns maps the fields conditions and sort orders may use to
+// their columns, so field names never reach the SQL unchecked.
+func applySpecification(query *gorm.DB, columns map[string]string, spec repositories.Specification) (*gorm.DB, error) {
+	for _, condition := range spec.Conditions {
+		column, ok := columns[condition.Field]
+		if !ok {
+			return nil, fmt.Errorf("unsupported filter field %q", condition.Field)
+		}
+
+		switch condition.Operator {
+		case repositories.OpContains, repositories.OpStartsWith:
+			value, ok := condition.Value.(string)
+			if !ok {
+				return nil, fmt.Errorf("filter %s %s needs a string", condition.Field, condition.Operator)
+			}
+			pattern := likeEscaper.Replace(value) + "%"
+			if condition.Operator == repositories.OpContains {
+				pattern = "%" + pattern
+			}
+			query = query.Where(column+" ILIKE ?", pattern)
+		case repositories.OpIn:
+			query = query.Where(column+" IN ?", condition.Value)
+		default:
+			op, ok := comparisons[condition.Operator]
+			if !ok {
+				return nil, fmt.Errorf("unsupported filter operator %q", condition.Operator)
+			}
+			query = query.Where(column+" "+op+" ?", condition.Value)
+		}
+	}
+
+	for _, order := range spec.Sort {
+		column, ok := columns[order.Field]
+		if !ok {
+			return nil, fmt.Errorf("unsupported sort field %q", order.Field)
+		}
+		if order.Descending {
+			column += " DESC"
+		}
+		query = query.Order(column)
+	}
+
+	if spec.Limit > 0 {
+		query = query.Limit(spec.Limit)
+	}
+	if spec.Offset > 0 {
+		query = query.Offset(spec.Offset)
+	}
+	return query, nil
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// dryRunDB builds statements without connecting to a database
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	require.NoError(t, err)
+	return db
+}
+
+func TestApplySpecification(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query, err := applySpecification(dryRunDB(t).Model(&entities.User{}), userColumns, repositories.Specification{
+		Conditions: []repositories.Condition{
+			{Field: "name", Operator: repositories.OpContains, Value: "50%_off"},
+			{Field: "email", Operator: repositories.OpStartsWith, Value: "jane"},
+			{Field: "created_at", Operator: repositories.OpGte, Value: since},
+			{Field: "id", Operator: repositories.OpIn, Value: []string{"user_1", "user_2"}},
+		},
+		Sort:   []repositories.SortOrder{{Field: "name", Descending: true}, {Field: "created_at"}},
+		Limit:  20,
+		Offset: 40,
+	})
+	require.NoError(t, err)
+
+	stmt := query.Find(&[]*entities.User{}).Statement
+	assert.Equal(t,
+		`SELECT * FROM "users" WHERE name ILIKE $1 AND email ILIKE $2 AND created_at >= $3 AND id IN ($4,$5) AND "users"."deleted_at" IS NULL ORDER BY name DESC,created_at LIMIT $6 OFFSET $7`,
+		stmt.SQL.String())
+	assert.Equal(t, []interface{}{`%50\%\_off%`, "jane%", since, "user_1", "user_2", 20, 40}, stmt.Vars)
+}
+
+func TestApplySpecification_RejectsUnknownFields(t *testing.T) {
+	_, err := applySpecification(dryRunDB(t), userColumns, repositories.Specification{
+		Conditions: []repositories.Condition{{Field: "password; DROP TABLE users", Operator: repositories.OpEq, Value: "x"}},
+	})
+	assert.Error(t, err)
+
+	_, err = applySpecification(dryRunDB(t), userColumns, repositories.Specification{
+		Conditions: []repositories.Condition{{Field: "name", Operator: "like", Value: "x"}},
+	})
+	assert.Error(t, err)
+
+	_, err = applySpecification(dryRunDB(t), userColumns, repositories.Specification{
+		Sort: []repositories.SortOrder{{Field: "(SELECT 1)"}},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+// Package email adapts mail transports to the email.Sender interface.
+package email
+
+import (
+	"fmt"
+	"net/mail"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/pkg/logger"
+)
+
+// NewSender creates the email sender selected by configuration
+func NewSender(cfg configs.EmailConfig, logger logger.Logger) (email.Sender, error) {
+	from := mail.Address{Name: cfg.FromName, Address: cfg.From}
+	switch cfg.Driver {
+	case "", "log":
+		return NewLogSender(from, logger), nil
+	case "smtp":
+		return NewSMTPSender(cfg.SMTP, from), nil
+	default:
+		return nil, fmt.Errorf("unknown email driver %q", cfg.Driver)
+	}
+}
+
+// parseRecipients parses the addresses of to, refusing any that could
+// inject headers or commands
+func parseRecipients(to []string) ([]*mail.Address, error) {
+	if len(to) == 0 {
+		return nil, email.ErrNoRecipients
+	}
+	recipients := make([]*mail.Address, len(to))
+	for i, addr := range to {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q: %w", addr, err)
+		}
+		recipients[i] = parsed
+	}
+	return recipients, nil
+}
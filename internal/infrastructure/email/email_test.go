@@ -0,0 +1,35 @@
+package email
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/pkg/logger"
+)
+
+func TestNewSender(t *testing.T) {
+	sender, err := NewSender(configs.EmailConfig{Driver: "log", From: "no-reply@localhost"}, logger.New())
+	require.NoError(t, err)
+	assert.IsType(t, &LogSender{}, sender)
+
+	sender, err = NewSender(configs.EmailConfig{Driver: "smtp", From: "no-reply@localhost", SMTP: configs.SMTPConfig{Host: "smtp.example.com"}}, logger.New())
+	require.NoError(t, err)
+	assert.IsType(t, &SMTPSender{}, sender)
+
+	_, err = NewSender(configs.EmailConfig{Driver: "sendmail"}, logger.New())
+	assert.EqualError(t, err, `unknown email driver "sendmail"`)
+}
+
+func TestLogSender_Send(t *testing.T) {
+	sender, err := NewSender(configs.EmailConfig{Driver: "log", From: "no-reply@localhost"}, logger.New())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NoError(t, sender.Send(ctx, email.Message{To: []string{"jane@example.com"}, Subject: "Welcome", Text: "Hello"}))
+	assert.ErrorIs(t, sender.Send(ctx, email.Message{Subject: "Welcome"}), email.ErrNoRecipients)
+}
@@ -0,0 +1,105 @@
+package email
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/email"
+)
+
+// NewFeedbackProvider creates the provider decoding the bounce and
+// complaint webhooks selected by configuration
+func NewFeedbackProvider(cfg configs.EmailFeedbackConfig) (email.FeedbackProvider, error) {
+	switch cfg.Provider {
+	case "stub":
+		return NewStubFeedbackProvider(cfg.Secret), nil
+	case "mailgun":
+		return NewMailgunFeedbackProvider(cfg.Secret), nil
+	default:
+		return nil, fmt.Errorf("unknown email feedback provider %q", cfg.Provider)
+	}
+}
+
+// StubFeedbackSignatureHeader carries the signature of stub feedback
+// webhooks
+const StubFeedbackSignatureHeader = "X-Email-Signature"
+
+// StubFeedbackProvider is a feedback provider for development and tests,
+// and for relays that report bounces themselves. Its webhooks are reports
+// in its own JSON form, signed with an HMAC-SHA256 of the body in the
+// X-Email-Signature header as sha256=<hex>:
+//
+//	{"id": "fb_1", "type": "bounce", "email": "ada@example.com", "permanent": true,
+//	 "detail": "550 5.1.1 mailbox unknown", "occurred_at": "2024-01-01T00:00:00Z"}
+type StubFeedbackProvider struct {
+	secret []byte
+}
+
+// NewStubFeedbackProvider creates a stub provider verifying webhooks with
+// secret
+func NewStubFeedbackProvider(secret string) *StubFeedbackProvider {
+	return &StubFeedbackProvider{secret: []byte(secret)}
+}
+
+// stubFeedback is the JSON form of stub webhooks
+type stubFeedback struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Email      string    `json:"email"`
+	Permanent  bool      `json:"permanent"`
+	Detail     string    `json:"detail"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Name implements email.FeedbackProvider
+func (p *StubFeedbackProvider) Name() string {
+	return "stub"
+}
+
+// SignatureHeader implements email.FeedbackProvider
+func (p *StubFeedbackProvider) SignatureHeader() string {
+	return StubFeedbackSignatureHeader
+}
+
+// Sign returns the signature header value of payload, for sending stub
+// webhooks
+func (p *StubFeedbackProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseFeedback implements email.FeedbackProvider
+func (p *StubFeedbackProvider) ParseFeedback(payload []byte, signature string) ([]email.Feedback, error) {
+	if !hmac.Equal([]byte(strings.TrimSpace(signature)), []byte(p.Sign(payload))) {
+		return nil, email.ErrInvalidSignature
+	}
+
+	var report stubFeedback
+	if err := json.Unmarshal(payload, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode stub feedback: %w", err)
+	}
+	switch report.Type {
+	case email.FeedbackBounce, email.FeedbackComplaint:
+	default:
+		return nil, nil
+	}
+	if report.ID == "" || report.Email == "" {
+		return nil, fmt.Errorf("stub feedback without an id or email")
+	}
+
+	return []email.Feedback{{
+		ID:         report.ID,
+		Type:       report.Type,
+		Email:      report.Email,
+		Permanent:  report.Permanent || report.Type == email.FeedbackComplaint,
+		Detail:     report.Detail,
+		OccurredAt: report.OccurredAt,
+	}}, nil
+}
@@ -0,0 +1,107 @@
+package email
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/email"
+)
+
+func TestNewFeedbackProvider(t *testing.T) {
+	provider, err := NewFeedbackProvider(configs.EmailFeedbackConfig{Provider: "mailgun", Secret: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, "mailgun", provider.Name())
+
+	_, err = NewFeedbackProvider(configs.EmailFeedbackConfig{Provider: "ses"})
+	assert.EqualError(t, err, `unknown email feedback provider "ses"`)
+}
+
+const stubFeedbackPayload = `{"id": "fb_1", "type": "bounce", "email": "Ada@Example.com", "permanent": true,
+	"detail": "550 5.1.1 mailbox unknown", "occurred_at": "2024-01-01T00:00:00Z"}`
+
+func TestStubFeedbackProvider_ParseFeedback(t *testing.T) {
+	provider := NewStubFeedbackProvider("secret")
+
+	feedback, err := provider.ParseFeedback([]byte(stubFeedbackPayload), provider.Sign([]byte(stubFeedbackPayload)))
+	require.NoError(t, err)
+	assert.Equal(t, []email.Feedback{{
+		ID:         "fb_1",
+		Type:       email.FeedbackBounce,
+		Email:      "Ada@Example.com",
+		Permanent:  true,
+		Detail:     "550 5.1.1 mailbox unknown",
+		OccurredAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}, feedback)
+
+	_, err = provider.ParseFeedback([]byte(stubFeedbackPayload), NewStubFeedbackProvider("other").Sign([]byte(stubFeedbackPayload)))
+	assert.ErrorIs(t, err, email.ErrInvalidSignature)
+
+	delivered := []byte(`{"id": "fb_2", "type": "delivered", "email": "ada@example.com"}`)
+	feedback, err = provider.ParseFeedback(delivered, provider.Sign(delivered))
+	require.NoError(t, err)
+	assert.Empty(t, feedback)
+}
+
+// mailgunPayload returns a Mailgun webhook of event signed at timestamp
+func mailgunPayload(provider *MailgunFeedbackProvider, timestamp time.Time, event, severity string) []byte {
+	unix := fmt.Sprint(timestamp.Unix())
+	return []byte(fmt.Sprintf(`{
+		"signature": {"timestamp": %q, "token": "tok_1", "signature": %q},
+		"event-data": {"id": "mg_1", "event": %q, "timestamp": 1704067200.5, "severity": %q, "reason": "bounce",
+			"recipient": "ada@example.com",
+			"delivery-status": {"code": 550, "message": "5.1.1 The email account does not exist", "description": ""}}
+	}`, unix, provider.Sign(unix, "tok_1"), event, severity))
+}
+
+func TestMailgunFeedbackProvider_ParseFeedback(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	provider := NewMailgunFeedbackProvider("key")
+	provider.now = func() time.Time { return now }
+
+	feedback, err := provider.ParseFeedback(mailgunPayload(provider, now, "failed", "permanent"), "")
+	require.NoError(t, err)
+	assert.Equal(t, []email.Feedback{{
+		ID:         "mg_1",
+		Type:       email.FeedbackBounce,
+		Email:      "ada@example.com",
+		Permanent:  true,
+		Detail:     "550 5.1.1 The email account does not exist",
+		OccurredAt: time.Date(2024, 1, 1, 0, 0, 0, 500_000_000, time.UTC),
+	}}, feedback)
+
+	feedback, err = provider.ParseFeedback(mailgunPayload(provider, now, "failed", "temporary"), "")
+	require.NoError(t, err)
+	require.Len(t, feedback, 1)
+	assert.False(t, feedback[0].Permanent)
+
+	feedback, err = provider.ParseFeedback(mailgunPayload(provider, now, "complained", ""), "")
+	require.NoError(t, err)
+	require.Len(t, feedback, 1)
+	assert.Equal(t, email.FeedbackComplaint, feedback[0].Type)
+	assert.True(t, feedback[0].Permanent)
+
+	feedback, err = provider.ParseFeedback(mailgunPayload(provider, now, "delivered", ""), "")
+	require.NoError(t, err)
+	assert.Empty(t, feedback)
+}
+
+func TestMailgunFeedbackProvider_ParseFeedback_Rejected(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	provider := NewMailgunFeedbackProvider("key")
+	provider.now = func() time.Time { return now }
+
+	other := NewMailgunFeedbackProvider("other")
+	_, err := provider.ParseFeedback(mailgunPayload(other, now, "failed", "permanent"), "")
+	assert.ErrorIs(t, err, email.ErrInvalidSignature)
+
+	_, err = provider.ParseFeedback(mailgunPayload(provider, now.Add(-time.Hour), "failed", "permanent"), "")
+	assert.ErrorIs(t, err, email.ErrInvalidSignature, "signatures older than the tolerance are replays")
+
+	_, err = provider.ParseFeedback([]byte("{"), "")
+	assert.ErrorContains(t, err, "failed to decode mailgun webhook")
+}
@@ -0,0 +1,147 @@
+package email
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"clean-architecture/internal/domain/email"
+)
+
+// mailgunTolerance is how old a signature's timestamp may be, which
+// bounds how long a captured webhook can be replayed
+const mailgunTolerance = 5 * time.Minute
+
+// MailgunFeedbackProvider decodes Mailgun's failed and complained event
+// webhooks, which are signed within the body with the webhook signing key
+type MailgunFeedbackProvider struct {
+	signingKey []byte
+	now        func() time.Time
+}
+
+// NewMailgunFeedbackProvider creates a provider verifying webhooks with
+// Mailgun's webhook signing key
+func NewMailgunFeedbackProvider(signingKey string) *MailgunFeedbackProvider {
+	return &MailgunFeedbackProvider{signingKey: []byte(signingKey), now: time.Now}
+}
+
+// mailgunWebhook is the JSON form of Mailgun's event webhooks
+type mailgunWebhook struct {
+	Signature struct {
+		Timestamp string `json:"timestamp"`
+		Token     string `json:"token"`
+		Signature string `json:"signature"`
+	} `json:"signature"`
+	EventData struct {
+		ID        string  `json:"id"`
+		Event     string  `json:"event"`
+		Timestamp float64 `json:"timestamp"`
+		Severity  string  `json:"severity"`
+		Reason    string  `json:"reason"`
+		Recipient string  `json:"recipient"`
+		Delivery  struct {
+			Code        int    `json:"code"`
+			Message     string `json:"message"`
+			Description string `json:"description"`
+		} `json:"delivery-status"`
+	} `json:"event-data"`
+}
+
+// Name implements email.FeedbackProvider
+func (p *MailgunFeedbackProvider) Name() string {
+	return "mailgun"
+}
+
+// SignatureHeader implements email.FeedbackProvider; Mailgun signs within
+// the body
+func (p *MailgunFeedbackProvider) SignatureHeader() string {
+	return ""
+}
+
+// Sign returns the signature of a webhook sent at timestamp with token,
+// for sending test webhooks
+func (p *MailgunFeedbackProvider) Sign(timestamp, token string) string {
+	mac := hmac.New(sha256.New, p.signingKey)
+	mac.Write([]byte(timestamp + token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseFeedback implements email.FeedbackProvider. Permanent failures are
+// reported as permanent bounces, temporary ones as bounces the address is
+// not suppressed for.
+func (p *MailgunFeedbackProvider) ParseFeedback(payload []byte, _ string) ([]email.Feedback, error) {
+	var webhook mailgunWebhook
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		return nil, fmt.Errorf("failed to decode mailgun webhook: %w", err)
+	}
+	if err := p.verify(webhook); err != nil {
+		return nil, err
+	}
+
+	data := webhook.EventData
+	feedback := email.Feedback{
+		ID:         data.ID,
+		Email:      data.Recipient,
+		OccurredAt: unixSeconds(data.Timestamp),
+	}
+	switch data.Event {
+	case "failed":
+		feedback.Type = email.FeedbackBounce
+		feedback.Permanent = data.Severity == "permanent"
+		feedback.Detail = mailgunDetail(data.Delivery.Code, data.Delivery.Message, data.Delivery.Description, data.Reason)
+	case "complained":
+		feedback.Type = email.FeedbackComplaint
+		feedback.Permanent = true
+	default:
+		return nil, nil
+	}
+	if feedback.ID == "" || feedback.Email == "" {
+		return nil, fmt.Errorf("mailgun event without an id or recipient")
+	}
+	return []email.Feedback{feedback}, nil
+}
+
+// verify checks the webhook's signature and that it is recent
+func (p *MailgunFeedbackProvider) verify(webhook mailgunWebhook) error {
+	sig := webhook.Signature
+	if sig.Timestamp == "" || sig.Token == "" ||
+		!hmac.Equal([]byte(strings.ToLower(sig.Signature)), []byte(p.Sign(sig.Timestamp, sig.Token))) {
+		return email.ErrInvalidSignature
+	}
+	var unix int64
+	if _, err := fmt.Sscan(sig.Timestamp, &unix); err != nil {
+		return email.ErrInvalidSignature
+	}
+	if age := p.now().Sub(time.Unix(unix, 0)); age > mailgunTolerance || age < -mailgunTolerance {
+		return email.ErrInvalidSignature
+	}
+	return nil
+}
+
+// mailgunDetail joins the SMTP reply of a failure into one diagnostic
+func mailgunDetail(code int, message, description, reason string) string {
+	var parts []string
+	if code != 0 {
+		parts = append(parts, fmt.Sprint(code))
+	}
+	for _, part := range []string{message, description} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return reason
+	}
+	return strings.Join(parts, " ")
+}
+
+// unixSeconds converts fractional Unix seconds to a time
+func unixSeconds(seconds float64) time.Time {
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*1e9)).UTC()
+}
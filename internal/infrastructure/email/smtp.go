@@ -0,0 +1,221 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/email"
+)
+
+// SMTPSender delivers messages through an SMTP server, opening a
+// connection for each message
+type SMTPSender struct {
+	cfg  configs.SMTPConfig
+	from mail.Address
+	// tlsConfig verifies the server's certificate; it names the host
+	tlsConfig *tls.Config
+}
+
+// NewSMTPSender creates a sender submitting to the server of cfg as from
+func NewSMTPSender(cfg configs.SMTPConfig, from mail.Address) *SMTPSender {
+	return &SMTPSender{
+		cfg:       cfg,
+		from:      from,
+		tlsConfig: &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12},
+	}
+}
+
+// Send implements email.Sender
+func (s *SMTPSender) Send(ctx context.Context, msg email.Message) error {
+	recipients, err := parseRecipients(msg.To)
+	if err != nil {
+		return err
+	}
+	body, err := s.build(msg, recipients, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer client.Close()
+	if err := s.submit(client, recipients, body); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return nil
+}
+
+// dial connects to the server, over TLS unless the connection is upgraded
+// with STARTTLS or left in the clear
+func (s *SMTPSender) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	if s.cfg.TLS == "tls" {
+		dialer := &tls.Dialer{Config: s.tlsConfig}
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// submit runs the SMTP transaction delivering body to recipients
+func (s *SMTPSender) submit(client *smtp.Client, recipients []*mail.Address, body []byte) error {
+	if s.cfg.TLS == "starttls" {
+		// Refuse to send credentials and mail in the clear to a server
+		// that does not offer, or an attacker that stripped, STARTTLS
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("server does not support STARTTLS")
+		}
+		if err := client.StartTLS(s.tlsConfig); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.from.Address); err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		if err := client.Rcpt(recipient.Address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// build formats msg as a MIME message, with a multipart/alternative body
+// when it has both a text and an HTML part
+func (s *SMTPSender) build(msg email.Message, recipients []*mail.Address, now time.Time) ([]byte, error) {
+	if msg.Text == "" && msg.HTML == "" {
+		return nil, errors.New("email has no body")
+	}
+	to := make([]string, len(recipients))
+	for i, recipient := range recipients {
+		to[i] = recipient.String()
+	}
+	messageID, err := s.messageID()
+	if err != nil {
+		return nil, err
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", s.from.String())
+	header.Set("To", strings.Join(to, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header.Set("Date", now.Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID)
+	header.Set("MIME-Version", "1.0")
+
+	var body bytes.Buffer
+	if msg.Text != "" && msg.HTML != "" {
+		parts := multipart.NewWriter(&body)
+		header.Set("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": parts.Boundary()}))
+		for _, part := range []struct{ mediaType, content string }{
+			{"text/plain", msg.Text},
+			{"text/html", msg.HTML},
+		} {
+			w, err := parts.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.mediaType + "; charset=utf-8"},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(w, part.content); err != nil {
+				return nil, err
+			}
+		}
+		if err := parts.Close(); err != nil {
+			return nil, err
+		}
+	} else {
+		mediaType, content := "text/plain", msg.Text
+		if msg.HTML != "" {
+			mediaType, content = "text/html", msg.HTML
+		}
+		header.Set("Content-Type", mediaType+"; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		if err := writeQuotedPrintable(&body, content); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, name := range headerOrder {
+		if value := header.Get(name); value != "" {
+			buf.WriteString(name + ": " + value + "\r\n")
+		}
+	}
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+// messageID returns a unique Message-ID in the domain of the sender address
+func (s *SMTPSender) messageID() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate message ID: %w", err)
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(s.from.Address, "@"); at >= 0 {
+		domain = s.from.Address[at+1:]
+	}
+	return "<" + hex.EncodeToString(random) + "@" + domain + ">", nil
+}
+
+// headerOrder is the order headers are written in, which textproto's map
+// does not keep
+var headerOrder = []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"}
+
+// writeQuotedPrintable writes content to w in the quoted-printable
+// encoding, which keeps lines within the 998 characters SMTP allows
+func writeQuotedPrintable(w io.Writer, content string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
@@ -0,0 +1,168 @@
+package email
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/email"
+)
+
+// smtpSession is what the fake server received in one session
+type smtpSession struct {
+	commands []string
+	data     string
+}
+
+// fakeSMTPServer accepts one session, answering every command with
+// success, and sends what it received on the returned channel
+func fakeSMTPServer(t *testing.T, extensions ...string) (configs.SMTPConfig, <-chan smtpSession) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		text := textproto.NewConn(conn)
+		var session smtpSession
+		defer func() { sessions <- session }()
+
+		text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			session.commands = append(session.commands, line)
+			switch verb := strings.ToUpper(strings.Fields(line)[0]); verb {
+			case "EHLO":
+				lines := append([]string{"localhost"}, extensions...)
+				for i, l := range lines {
+					sep := "-"
+					if i == len(lines)-1 {
+						sep = " "
+					}
+					text.PrintfLine("250%s%s", sep, l)
+				}
+			case "DATA":
+				text.PrintfLine("354 go ahead")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				session.data = string(data)
+				text.PrintfLine("250 queued")
+			case "QUIT":
+				text.PrintfLine("221 bye")
+				return
+			default:
+				text.PrintfLine("250 ok")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return configs.SMTPConfig{Host: host, Port: portNumber, TLS: "none", Timeout: 5 * time.Second}, sessions
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	cfg, sessions := fakeSMTPServer(t)
+	sender := NewSMTPSender(cfg, mail.Address{Name: "Acme", Address: "no-reply@acme.example"})
+
+	err := sender.Send(context.Background(), email.Message{
+		To:      []string{"Jane Doe <jane@example.com>", "ops@example.com"},
+		Subject: "Confirm your émail",
+		Text:    "Open https://app.example.com/confirm?token=abc",
+		HTML:    `<a href="https://app.example.com/confirm?token=abc">Confirm</a>`,
+	})
+	require.NoError(t, err)
+
+	session := <-sessions
+	assert.Contains(t, session.commands, "MAIL FROM:<no-reply@acme.example>")
+	assert.Contains(t, session.commands, "RCPT TO:<jane@example.com>")
+	assert.Contains(t, session.commands, "RCPT TO:<ops@example.com>")
+
+	msg, err := mail.ReadMessage(strings.NewReader(session.data))
+	require.NoError(t, err)
+	assert.Equal(t, `"Acme" <no-reply@acme.example>`, msg.Header.Get("From"))
+	assert.Equal(t, `"Jane Doe" <jane@example.com>, <ops@example.com>`, msg.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Confirm your émail", subject)
+	assert.True(t, strings.HasSuffix(msg.Header.Get("Message-ID"), "@acme.example>"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		body, err := io.ReadAll(part)
+		require.NoError(t, err)
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	assert.Equal(t, []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}, types)
+	assert.Equal(t, "Open https://app.example.com/confirm?token=abc", bodies[0], "quoted-printable parts are decoded by the reader")
+}
+
+func TestSMTPSender_TextOnly(t *testing.T) {
+	cfg, sessions := fakeSMTPServer(t)
+	sender := NewSMTPSender(cfg, mail.Address{Address: "no-reply@acme.example"})
+
+	require.NoError(t, sender.Send(context.Background(), email.Message{
+		To:      []string{"jane@example.com"},
+		Subject: "Welcome",
+		Text:    "Hello",
+	}))
+
+	msg, err := mail.ReadMessage(strings.NewReader((<-sessions).data))
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", msg.Header.Get("Content-Type"))
+	assert.Equal(t, "quoted-printable", msg.Header.Get("Content-Transfer-Encoding"))
+}
+
+func TestSMTPSender_RequiresSTARTTLS(t *testing.T) {
+	cfg, sessions := fakeSMTPServer(t)
+	cfg.TLS = "starttls"
+	sender := NewSMTPSender(cfg, mail.Address{Address: "no-reply@acme.example"})
+
+	err := sender.Send(context.Background(), email.Message{To: []string{"jane@example.com"}, Text: "Hello"})
+	assert.EqualError(t, err, "smtp: server does not support STARTTLS")
+	for _, command := range (<-sessions).commands {
+		assert.False(t, strings.HasPrefix(command, "MAIL"), "no mail is sent in the clear")
+	}
+}
+
+func TestSMTPSender_InvalidMessage(t *testing.T) {
+	sender := NewSMTPSender(configs.SMTPConfig{Host: "127.0.0.1", Port: 1, TLS: "none", Timeout: time.Second}, mail.Address{Address: "no-reply@acme.example"})
+	ctx := context.Background()
+
+	assert.ErrorIs(t, sender.Send(ctx, email.Message{Text: "Hello"}), email.ErrNoRecipients)
+	assert.ErrorContains(t, sender.Send(ctx, email.Message{To: []string{"jane@example.com\r\nBcc: all@example.com"}, Text: "Hello"}), "invalid recipient")
+	assert.EqualError(t, sender.Send(ctx, email.Message{To: []string{"jane@example.com"}}), "email has no body")
+}
@@ -0,0 +1,72 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// SuppressingSender checks the recipients of messages against the
+// suppression list before another sender delivers them. Suppressed
+// recipients are dropped, and messages left without any are not sent and
+// fail with email.ErrRecipientSuppressed.
+type SuppressingSender struct {
+	next         email.Sender
+	suppressions repositories.EmailSuppressionRepository
+	logger       logger.Logger
+}
+
+// NewSuppressingSender creates a sender delivering through next to the
+// recipients not in suppressions
+func NewSuppressingSender(next email.Sender, suppressions repositories.EmailSuppressionRepository, logger logger.Logger) *SuppressingSender {
+	return &SuppressingSender{next: next, suppressions: suppressions, logger: logger}
+}
+
+// Send implements email.Sender. A failed lookup fails the message, so it
+// is retried rather than sent to an address that may be suppressed.
+func (s *SuppressingSender) Send(ctx context.Context, msg email.Message) error {
+	if len(msg.To) == 0 {
+		return s.next.Send(ctx, msg)
+	}
+	addresses := make([]string, len(msg.To))
+	for i, to := range msg.To {
+		addresses[i] = to
+		// Unparsable recipients are refused by the next sender
+		if parsed, err := mail.ParseAddress(to); err == nil {
+			addresses[i] = parsed.Address
+		}
+		addresses[i] = entities.NormalizeEmailAddress(addresses[i])
+	}
+	suppressed, err := s.suppressions.GetMany(ctx, addresses)
+	if err != nil {
+		return fmt.Errorf("failed to check suppression list: %w", err)
+	}
+	if len(suppressed) == 0 {
+		return s.next.Send(ctx, msg)
+	}
+
+	var to, dropped []string
+	for i, address := range addresses {
+		if _, ok := suppressed[address]; ok {
+			dropped = append(dropped, address)
+			continue
+		}
+		to = append(to, msg.To[i])
+	}
+	log := s.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"subject":    msg.Subject,
+		"suppressed": dropped,
+	})
+	if len(to) == 0 {
+		log.Info("Not sending email to suppressed recipients")
+		return email.ErrRecipientSuppressed
+	}
+	log.Info("Dropping suppressed recipients from email")
+	msg.To = to
+	return s.next.Send(ctx, msg)
+}
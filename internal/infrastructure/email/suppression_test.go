@@ -0,0 +1,58 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+// recordingSender records the messages sent through it
+type recordingSender struct {
+	sent []email.Message
+}
+
+func (s *recordingSender) Send(ctx context.Context, msg email.Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestSuppressingSender_Send(t *testing.T) {
+	ctx := context.Background()
+	suppressions := database.NewMockEmailSuppressionRepository()
+	require.NoError(t, suppressions.Save(ctx, &entities.EmailSuppression{Email: "bounced@example.com", Reason: entities.SuppressionBounce, Provider: "stub"}))
+	next := &recordingSender{}
+	sender := NewSuppressingSender(next, suppressions, logger.New())
+
+	require.NoError(t, sender.Send(ctx, email.Message{To: []string{"jane@example.com", "Bob <Bounced@Example.com>"}, Subject: "Welcome"}))
+	err := sender.Send(ctx, email.Message{To: []string{"bounced@example.com"}, Subject: "Welcome"})
+	assert.ErrorIs(t, err, email.ErrRecipientSuppressed)
+
+	require.Len(t, next.sent, 1)
+	assert.Equal(t, []string{"jane@example.com"}, next.sent[0].To)
+}
+
+// failingSuppressions fails every lookup
+type failingSuppressions struct {
+	database.MockEmailSuppressionRepository
+}
+
+func (*failingSuppressions) GetMany(ctx context.Context, emails []string) (map[string]*entities.EmailSuppression, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestSuppressingSender_Send_LookupFails(t *testing.T) {
+	next := &recordingSender{}
+	sender := NewSuppressingSender(next, &failingSuppressions{}, logger.New())
+
+	err := sender.Send(context.Background(), email.Message{To: []string{"jane@example.com"}, Subject: "Welcome"})
+	assert.ErrorContains(t, err, "failed to check suppression list")
+	assert.Empty(t, next.sent)
+}
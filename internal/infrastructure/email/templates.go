@@ -0,0 +1,187 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"clean-architecture/internal/domain/email"
+)
+
+// templateFiles holds the templates of each locale: in templates/<locale>,
+// <name>.txt defines the "subject" and "text" of template name with
+// text/template, and <name>.html, if present, the "content" of the HTML
+// part with html/template, which templates/layout.html wraps
+//
+//go:embed templates
+var templateFiles embed.FS
+
+// dateTimeFormats is how each locale formats times; times are shown in UTC
+var dateTimeFormats = map[string]string{
+	"en": "January 2, 2006 15:04 MST",
+	"de": "02.01.2006, 15:04 MST",
+}
+
+// localeTemplates are the templates translated into one locale, by name
+type localeTemplates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// TemplateRenderer renders the embedded email templates. A template
+// missing from a locale falls back to the default locale, which must have
+// every template.
+type TemplateRenderer struct {
+	defaultLocale string
+	locales       map[string]*localeTemplates
+}
+
+// NewTemplateRenderer parses the embedded templates, rendering them in
+// defaultLocale when a template is not translated into the requested one
+func NewTemplateRenderer(defaultLocale string) (*TemplateRenderer, error) {
+	r := &TemplateRenderer{
+		defaultLocale: normalizeLocale(defaultLocale),
+		locales:       make(map[string]*localeTemplates),
+	}
+	dirs, err := fs.ReadDir(templateFiles, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		templates, err := parseLocale(dir.Name())
+		if err != nil {
+			return nil, err
+		}
+		r.locales[dir.Name()] = templates
+	}
+
+	defaults, ok := r.locales[r.defaultLocale]
+	if !ok {
+		return nil, fmt.Errorf("no email templates for the default locale %q", defaultLocale)
+	}
+	for locale, templates := range r.locales {
+		for name := range templates.text {
+			if defaults.text[name] == nil {
+				return nil, fmt.Errorf("email template %q of locale %q is missing from the default locale", name, locale)
+			}
+		}
+	}
+	return r, nil
+}
+
+// parseLocale parses the templates of locale
+func parseLocale(locale string) (*localeTemplates, error) {
+	dir := path.Join("templates", locale)
+	files, err := fs.ReadDir(templateFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+	funcs := templateFuncs(locale)
+	templates := &localeTemplates{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+	for _, file := range files {
+		name, ext, _ := strings.Cut(file.Name(), ".")
+		file := path.Join(dir, file.Name())
+		switch ext {
+		case "txt":
+			t, err := texttemplate.New(name).Funcs(funcs).ParseFS(templateFiles, file)
+			if err != nil {
+				return nil, err
+			}
+			if t.Lookup("subject") == nil || t.Lookup("text") == nil {
+				return nil, fmt.Errorf("%s must define subject and text", file)
+			}
+			templates.text[name] = t
+		case "html":
+			t, err := htmltemplate.New(name).Funcs(htmltemplate.FuncMap(funcs)).ParseFS(templateFiles, "templates/layout.html", file)
+			if err != nil {
+				return nil, err
+			}
+			if t.Lookup("content") == nil {
+				return nil, fmt.Errorf("%s must define content", file)
+			}
+			templates.html[name] = t
+		}
+	}
+	for name := range templates.html {
+		if templates.text[name] == nil {
+			return nil, fmt.Errorf("email template %q of locale %q has no text part", name, locale)
+		}
+	}
+	return templates, nil
+}
+
+// templateFuncs are the functions the templates of locale call
+func templateFuncs(locale string) texttemplate.FuncMap {
+	format, ok := dateTimeFormats[locale]
+	if !ok {
+		format = time.RFC1123
+	}
+	return texttemplate.FuncMap{
+		"locale":   func() string { return locale },
+		"datetime": func(t time.Time) string { return t.UTC().Format(format) },
+	}
+}
+
+// Render implements email.Renderer
+func (r *TemplateRenderer) Render(locale string, data email.Template) (email.Message, error) {
+	name := data.TemplateName()
+	templates := r.resolve(locale, name)
+	if templates == nil {
+		return email.Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text bytes.Buffer
+	t := templates.text[name]
+	if err := t.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return email.Message{}, fmt.Errorf("render email template %q: %w", name, err)
+	}
+	if err := t.ExecuteTemplate(&text, "text", data); err != nil {
+		return email.Message{}, fmt.Errorf("render email template %q: %w", name, err)
+	}
+	msg := email.Message{
+		// Subjects are one line, whatever the data they show
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}
+
+	if h := templates.html[name]; h != nil {
+		var html bytes.Buffer
+		if err := h.ExecuteTemplate(&html, "layout", data); err != nil {
+			return email.Message{}, fmt.Errorf("render email template %q: %w", name, err)
+		}
+		msg.HTML = html.String()
+	}
+	return msg, nil
+}
+
+// resolve returns the templates of the locale closest to locale that has
+// template name: the locale itself, its language without the region, then
+// the default locale
+func (r *TemplateRenderer) resolve(locale, name string) *localeTemplates {
+	locale = normalizeLocale(locale)
+	language, _, _ := strings.Cut(locale, "-")
+	for _, candidate := range []string{locale, language, r.defaultLocale} {
+		if templates, ok := r.locales[candidate]; ok && templates.text[name] != nil {
+			return templates
+		}
+	}
+	return nil
+}
+
+// normalizeLocale lowercases locale and separates its subtags by hyphens,
+// so "de_AT" and "de-at" are "de-at"
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
@@ -0,0 +1,67 @@
+package email
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/email"
+)
+
+// unknownTemplate is a template no files exist for
+type unknownTemplate struct{}
+
+func (unknownTemplate) TemplateName() string { return "newsletter" }
+
+func TestTemplateRenderer_Render(t *testing.T) {
+	renderer, err := NewTemplateRenderer("en")
+	require.NoError(t, err)
+
+	data := email.EmailChangeConfirmation{
+		Name:       "Jane <script>",
+		NewEmail:   "jane@example.com",
+		ConfirmURL: "https://app.example.com/confirm?token=a&b",
+		ExpiresAt:  time.Date(2024, 5, 1, 14, 30, 0, 0, time.FixedZone("CEST", 2*3600)),
+	}
+	msg, err := renderer.Render("en-US", data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Confirm your new email address", msg.Subject)
+	assert.Contains(t, msg.Text, "Hi Jane <script>,", "the text part is not HTML escaped")
+	assert.Contains(t, msg.Text, "https://app.example.com/confirm?token=a&b")
+	assert.Contains(t, msg.Text, "May 1, 2024 12:30 UTC")
+	assert.Contains(t, msg.HTML, `<html lang="en">`)
+	assert.Contains(t, msg.HTML, "Hi Jane &lt;script&gt;,")
+	assert.Contains(t, msg.HTML, `href="https://app.example.com/confirm?token=a&amp;b"`)
+	assert.Empty(t, msg.To)
+}
+
+func TestTemplateRenderer_Locales(t *testing.T) {
+	renderer, err := NewTemplateRenderer("en")
+	require.NoError(t, err)
+	data := email.DeletionScheduled{
+		Name:       "Jana",
+		PurgeAfter: time.Date(2024, 5, 31, 8, 0, 0, 0, time.UTC),
+		CancelURL:  "https://app.example.com/cancel",
+	}
+
+	msg, err := renderer.Render("de_AT", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Ihr Konto wird gelöscht", msg.Subject, "regions fall back to their language")
+	assert.Contains(t, msg.Text, "am 31.05.2024, 08:00 UTC")
+	assert.Contains(t, msg.HTML, `<html lang="de">`)
+
+	msg, err = renderer.Render("fr", data)
+	require.NoError(t, err)
+	assert.Equal(t, "Your account is scheduled for deletion", msg.Subject, "untranslated locales fall back to the default")
+
+	_, err = renderer.Render("en", unknownTemplate{})
+	assert.EqualError(t, err, `unknown email template "newsletter"`)
+}
+
+func TestNewTemplateRenderer_UnknownDefaultLocale(t *testing.T) {
+	_, err := NewTemplateRenderer("fr")
+	assert.EqualError(t, err, `no email templates for the default locale "fr"`)
+}
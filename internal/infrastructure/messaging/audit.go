@@ -0,0 +1,28 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+// AuditHandler returns the event handler recording every domain event in
+// the audit log with record. Redelivered events are recorded once by the
+// log itself.
+func AuditHandler(record func(ctx context.Context, event events.Event) error) consumer.Handler {
+	return consumer.Handler{
+		Name:   "audit-log",
+		Topics: events.Types,
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			var event events.Event
+			if err := json.Unmarshal(msg.Payload, &event); err != nil {
+				return consumer.Permanent(fmt.Errorf("decode event %s: %w", msg.Topic, err))
+			}
+			return record(ctx, event)
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+// Types of the commands the command worker runs, given by the type
+// property of their messages
+const (
+	CommandImportUser = "user.import"
+	CommandDeleteUser = "user.delete"
+)
+
+// CommandHandler runs a command from its JSON payload. Errors wrapped with
+// consumer.Permanent reject the command without retrying it.
+type CommandHandler func(ctx context.Context, payload []byte) error
+
+// ImportUserCommand returns the handler of user.import commands, which
+// create the user {"email", "name"} or rename the user with that email with
+// upsert, so a redelivered command imports the user once
+func ImportUserCommand(upsert func(ctx context.Context, email, name string) (*entities.User, bool, error)) CommandHandler {
+	return func(ctx context.Context, payload []byte) error {
+		var command struct {
+			Email string `json:"email"`
+			Name  string `json:"name"`
+		}
+		if err := decodeCommand(payload, &command); err != nil {
+			return err
+		}
+		if command.Email == "" || command.Name == "" {
+			return consumer.Permanent(errors.New("email and name are required"))
+		}
+		_, _, err := upsert(ctx, command.Email, command.Name)
+		return err
+	}
+}
+
+// DeleteUserCommand returns the handler of user.delete commands, which
+// delete the user {"id"} with deleteUser. Users already deleted are done.
+func DeleteUserCommand(deleteUser func(ctx context.Context, id string) error) CommandHandler {
+	return func(ctx context.Context, payload []byte) error {
+		var command struct {
+			ID string `json:"id"`
+		}
+		if err := decodeCommand(payload, &command); err != nil {
+			return err
+		}
+		if command.ID == "" {
+			return consumer.Permanent(errors.New("id is required"))
+		}
+		if err := deleteUser(ctx, command.ID); err != nil && !errors.Is(err, repositories.ErrUserNotFound) {
+			return err
+		}
+		return nil
+	}
+}
+
+func decodeCommand(payload []byte, v interface{}) error {
+	if err := json.Unmarshal(payload, v); err != nil {
+		return consumer.Permanent(fmt.Errorf("decode command: %w", err))
+	}
+	return nil
+}
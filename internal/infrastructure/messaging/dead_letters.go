@@ -0,0 +1,44 @@
+package messaging
+
+import (
+	"context"
+	"strings"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+// DeadLetterSink stores dead-lettered events in a repository so they can be
+// inspected and replayed
+type DeadLetterSink struct {
+	repo repositories.DeadLetterRepository
+}
+
+// NewDeadLetterSink creates a new repository-backed dead letter sink
+func NewDeadLetterSink(repo repositories.DeadLetterRepository) *DeadLetterSink {
+	return &DeadLetterSink{repo: repo}
+}
+
+// DeadLetter implements consumer.DeadLetterSink
+func (s *DeadLetterSink) DeadLetter(ctx context.Context, dl consumer.DeadLetter) error {
+	kind := entities.DeadLetterKindEvent
+	if strings.HasPrefix(dl.Message.Topic, JobTopicPrefix) {
+		kind = entities.DeadLetterKindJob
+	}
+
+	return s.repo.Create(ctx, &entities.DeadLetter{
+		Kind:          kind,
+		Topic:         dl.Message.Topic,
+		ConsumerGroup: dl.Group,
+		MessageID:     dl.Message.ID,
+		MessageKey:    dl.Message.Key,
+		CorrelationID: dl.Message.Headers[correlation.MessageHeader],
+		Payload:       dl.Message.Payload,
+		Headers:       dl.Message.Headers,
+		Error:         dl.Error,
+		Attempts:      dl.Attempts,
+		FailedAt:      dl.FailedAt,
+	})
+}
@@ -0,0 +1,32 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+// EventStreamHandler returns the event handler passing user changes to the
+// event streams open on this instance with publish. Streams live on a
+// single instance, so the handler joins group, which must be unique to the
+// instance for every one of them to see every change.
+func EventStreamHandler(group string, publish func(ctx context.Context, event events.Event) error) consumer.Handler {
+	return consumer.Handler{
+		Name:   group,
+		Topics: events.UserChanges,
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			var event events.Event
+			if err := json.Unmarshal(msg.Payload, &event); err != nil {
+				return consumer.Permanent(fmt.Errorf("decode event %s: %w", msg.Topic, err))
+			}
+			return publish(ctx, event)
+		},
+		// Streams drop events their clients cannot keep up with, so a
+		// retry would only deliver an event twice
+		MaxAttempts: 1,
+	}
+}
@@ -0,0 +1,58 @@
+package messaging
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+// JobTopicPrefix prefixes the topic each job type is queued on
+const JobTopicPrefix = "jobs."
+
+// JobTopic returns the topic jobs of jobType are queued on
+func JobTopic(jobType string) string {
+	return JobTopicPrefix + jobType
+}
+
+// JobQueue queues jobs on the broker. The message carries only the job ID;
+// workers load the job from its repository.
+type JobQueue struct {
+	publisher messaging.Publisher
+}
+
+// NewJobQueue creates a new broker-backed job queue
+func NewJobQueue(publisher messaging.Publisher) *JobQueue {
+	return &JobQueue{publisher: publisher}
+}
+
+// Enqueue implements usecase.JobQueue
+func (q *JobQueue) Enqueue(ctx context.Context, job *entities.Job) error {
+	headers := map[string]string{}
+	if id := correlation.ID(ctx); id != "" {
+		headers[correlation.MessageHeader] = id
+	}
+
+	return q.publisher.Publish(ctx, messaging.Message{
+		ID:      job.ID,
+		Topic:   JobTopic(job.Type),
+		Key:     job.ID,
+		Payload: []byte(job.ID),
+		Headers: headers,
+	})
+}
+
+// JobHandler returns the event handler running jobs of jobType with run.
+// Failed attempts are retried and finally dead-lettered like any event.
+func JobHandler(jobType string, concurrency int, run func(ctx context.Context, id string) error) consumer.Handler {
+	return consumer.Handler{
+		Name:        "jobs-" + jobType,
+		Topics:      []string{JobTopic(jobType)},
+		Concurrency: concurrency,
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			return run(ctx, string(msg.Payload))
+		},
+	}
+}
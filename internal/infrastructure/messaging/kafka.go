@@ -0,0 +1,127 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+// NewKafkaWriter returns the writer producing records to the cluster of
+// cfg, over TLS and authenticated with SASL when configured. Keys are
+// partitioned with murmur2 like Kafka's own clients, and records written
+// to a partition within Linger of each other share a request. Topics the
+// cluster does not have are created if the brokers allow it.
+func NewKafkaWriter(cfg configs.KafkaConfig) (*kafka.Writer, error) {
+	transport := &kafka.Transport{
+		ClientID:    cfg.ClientID,
+		DialTimeout: cfg.Timeout,
+	}
+	if cfg.TLS {
+		tlsConfig, err := tlsConfig(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLS = tlsConfig
+	}
+	if cfg.SASLMechanism != "" {
+		mechanism, err := kafkaSASL(cfg)
+		if err != nil {
+			return nil, err
+		}
+		transport.SASL = mechanism
+	}
+
+	acks := kafka.RequireAll
+	if cfg.Acks == "leader" {
+		acks = kafka.RequireOne
+	}
+	return &kafka.Writer{
+		Addr:      kafka.TCP(cfg.Brokers...),
+		Balancer:  &kafka.Murmur2Balancer{},
+		BatchSize: cfg.BatchSize,
+		// Zero would take the writer's default of a second
+		BatchTimeout:           max(cfg.Linger, time.Nanosecond),
+		ReadTimeout:            cfg.Timeout,
+		WriteTimeout:           cfg.Timeout,
+		RequiredAcks:           acks,
+		AllowAutoTopicCreation: true,
+		Transport:              transport,
+	}, nil
+}
+
+// kafkaSASL returns the SASL mechanism of cfg authenticating its user
+func kafkaSASL(cfg configs.KafkaConfig) (sasl.Mechanism, error) {
+	switch cfg.SASLMechanism {
+	case "plain":
+		return plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, cfg.SASLUsername, cfg.SASLPassword)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, cfg.SASLUsername, cfg.SASLPassword)
+	}
+	return nil, fmt.Errorf("unknown SASL mechanism %q", cfg.SASLMechanism)
+}
+
+// KafkaHandler returns the event handler forwarding every domain event to
+// the Kafka topic named prefix followed by its type with produce. Events
+// keep their key, so the events of an aggregate stay ordered in one
+// partition, and their headers, with the message ID added for consumers
+// to deduplicate redeliveries by. Errors a broker would answer again are
+// dead-lettered without retrying.
+func KafkaHandler(prefix string, workers int, produce func(ctx context.Context, records ...kafka.Message) error) consumer.Handler {
+	return consumer.Handler{
+		Name:        "kafka",
+		Topics:      events.Types,
+		Concurrency: workers,
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			headers := make([]kafka.Header, 0, len(msg.Headers)+1)
+			for key, value := range msg.Headers {
+				headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+			}
+			headers = append(headers, kafka.Header{Key: "message-id", Value: []byte(msg.ID)})
+
+			record := kafka.Message{
+				Topic:   prefix + msg.Topic,
+				Value:   msg.Payload,
+				Headers: headers,
+				Time:    msg.Timestamp,
+			}
+			// Records without a key are spread over the partitions
+			if msg.Key != "" {
+				record.Key = []byte(msg.Key)
+			}
+			err := produce(ctx, record)
+			if kafkaPermanent(err) {
+				return consumer.Permanent(err)
+			}
+			return err
+		},
+	}
+}
+
+// kafkaPermanent reports whether err holds an error code the brokers would
+// answer the write with again, such as for records too large for the topic
+func kafkaPermanent(err error) bool {
+	var werrs kafka.WriteErrors
+	if errors.As(err, &werrs) {
+		for _, werr := range werrs {
+			if kafkaPermanent(werr) {
+				return true
+			}
+		}
+		return false
+	}
+	var kerr kafka.Error
+	return errors.As(err, &kerr) && !kerr.Temporary()
+}
@@ -0,0 +1,105 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/memory"
+)
+
+// NewBroker creates the messaging broker selected by configuration. Without
+// a driver the in-process memory broker is used.
+func NewBroker(cfg configs.MessagingConfig, logger logger.Logger) (messaging.Broker, error) {
+	switch cfg.Driver {
+	case "", "memory":
+		return memory.New(cfg.BufferSize, logger), nil
+	case "nats":
+		broker, err := NewNATSBroker(cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		return broker, nil
+	default:
+		return nil, fmt.Errorf("unknown messaging driver %q", cfg.Driver)
+	}
+}
+
+// EventPublisher publishes domain events as JSON messages on a topic named
+// after the event type, keyed by aggregate ID so events for one aggregate
+// stay ordered
+type EventPublisher struct {
+	publisher messaging.Publisher
+	log       repositories.PublishedEventRepository
+}
+
+// NewEventPublisher creates a new domain event publisher
+func NewEventPublisher(publisher messaging.Publisher) *EventPublisher {
+	return &EventPublisher{publisher: publisher}
+}
+
+// WithLog records every event in log before publishing it, with the error
+// of the broker when it refused the event, so events can be audited and
+// replayed
+func (p *EventPublisher) WithLog(log repositories.PublishedEventRepository) *EventPublisher {
+	p.log = log
+	return p
+}
+
+// Publish encodes and publishes a domain event. An event that could not be
+// recorded is published anyway, and the error is returned once it was.
+func (p *EventPublisher) Publish(ctx context.Context, event events.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event %s: %w", event.Type, err)
+	}
+
+	headers := map[string]string{"content-type": "application/json"}
+	if event.CorrelationID != "" {
+		headers[correlation.MessageHeader] = event.CorrelationID
+	}
+	msg := messaging.Message{
+		ID:        event.ID,
+		Topic:     event.Type,
+		Key:       event.AggregateID,
+		Payload:   payload,
+		Headers:   headers,
+		Timestamp: event.OccurredAt,
+	}
+
+	var recorded *entities.PublishedEvent
+	var recordErr error
+	if p.log != nil {
+		recorded = &entities.PublishedEvent{
+			ID:            event.ID,
+			Type:          event.Type,
+			AggregateID:   event.AggregateID,
+			CorrelationID: event.CorrelationID,
+			OccurredAt:    event.OccurredAt,
+			PublishedAt:   time.Now(),
+			Payload:       payload,
+			Headers:       headers,
+		}
+		if recordErr = p.log.Create(ctx, recorded); recordErr != nil {
+			recorded, recordErr = nil, fmt.Errorf("record event %s: %w", event.ID, recordErr)
+		}
+	}
+
+	publishErr := p.publisher.Publish(ctx, msg)
+	if publishErr != nil && recorded != nil {
+		recorded.PublishError = publishErr.Error()
+		if err := p.log.Update(context.WithoutCancel(ctx), recorded); err != nil {
+			recordErr = fmt.Errorf("record publish error of event %s: %w", event.ID, err)
+		}
+	}
+	return errors.Join(publishErr, recordErr)
+}
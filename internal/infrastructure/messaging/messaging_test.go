@@ -0,0 +1,486 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+func TestNewBroker(t *testing.T) {
+	broker, err := NewBroker(configs.MessagingConfig{Driver: "memory", BufferSize: 8}, logger.New())
+	require.NoError(t, err)
+	assert.NoError(t, broker.Close())
+
+	broker, err = NewBroker(configs.MessagingConfig{BufferSize: 8}, logger.New())
+	require.NoError(t, err, "the memory broker is used without a driver")
+	assert.NoError(t, broker.Close())
+
+	_, err = NewBroker(configs.MessagingConfig{Driver: "carrier-pigeon"}, logger.New())
+	assert.EqualError(t, err, `unknown messaging driver "carrier-pigeon"`)
+}
+
+func TestEventPublisher_Publish(t *testing.T) {
+	ctx := context.Background()
+	broker, err := NewBroker(configs.MessagingConfig{Driver: "memory", BufferSize: 8}, logger.New())
+	require.NoError(t, err)
+
+	received := make(chan messaging.Message, 1)
+	_, err = broker.Subscribe(ctx, events.UserCreated, "test", func(ctx context.Context, msg messaging.Message) error {
+		received <- msg
+		return nil
+	})
+	require.NoError(t, err)
+
+	event := events.NewEvent(events.UserCreated, "user_1", map[string]string{"email": "jane@example.com"})
+	event.CorrelationID = "cor_123"
+	require.NoError(t, NewEventPublisher(broker).Publish(ctx, event))
+	require.NoError(t, broker.Close())
+
+	msg := <-received
+	assert.Equal(t, event.ID, msg.ID)
+	assert.Equal(t, "user_1", msg.Key)
+	assert.Equal(t, "cor_123", msg.Headers[correlation.MessageHeader])
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(msg.Payload, &decoded))
+	assert.Equal(t, "user.created", decoded["type"])
+	assert.Equal(t, "user_1", decoded["aggregate_id"])
+	assert.Equal(t, "cor_123", decoded["correlation_id"])
+	assert.Equal(t, map[string]interface{}{"email": "jane@example.com"}, decoded["data"])
+}
+
+func TestEventPublisher_WithLog(t *testing.T) {
+	ctx := context.Background()
+	broker, err := NewBroker(configs.MessagingConfig{Driver: "memory", BufferSize: 8}, logger.New())
+	require.NoError(t, err)
+	log := database.NewMockPublishedEventRepository()
+	publisher := NewEventPublisher(broker).WithLog(log)
+
+	event := events.NewEvent(events.UserCreated, "user_1", map[string]string{"email": "jane@example.com"})
+	event.CorrelationID = "cor_123"
+	require.NoError(t, publisher.Publish(ctx, event))
+
+	recorded, err := log.GetByID(ctx, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, events.UserCreated, recorded.Type)
+	assert.Equal(t, "user_1", recorded.AggregateID)
+	assert.Equal(t, "cor_123", recorded.CorrelationID)
+	assert.Equal(t, "cor_123", recorded.Headers[correlation.MessageHeader])
+	assert.False(t, recorded.PublishedAt.IsZero())
+	assert.Empty(t, recorded.PublishError)
+	var decoded events.Event
+	require.NoError(t, json.Unmarshal(recorded.Payload, &decoded))
+	assert.Equal(t, event.ID, decoded.ID)
+
+	// Events the broker refuses are recorded with its error, so they can
+	// be replayed once it is back
+	require.NoError(t, broker.Close())
+	refused := events.NewEvent(events.UserDeleted, "user_1", nil)
+	assert.ErrorIs(t, publisher.Publish(ctx, refused), messaging.ErrClosed)
+	recorded, err = log.GetByID(ctx, refused.ID)
+	require.NoError(t, err)
+	assert.Equal(t, messaging.ErrClosed.Error(), recorded.PublishError)
+
+	failed, err := log.List(ctx, repositories.PublishedEventFilter{Failed: true})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, refused.ID, failed[0].ID)
+}
+
+func TestDeadLetterSink(t *testing.T) {
+	ctx := context.Background()
+	repo := database.NewMockDeadLetterRepository()
+
+	err := NewDeadLetterSink(repo).DeadLetter(ctx, consumer.DeadLetter{
+		Message: messaging.Message{
+			ID:      "msg_1",
+			Topic:   "user.created",
+			Key:     "user_1",
+			Payload: []byte("{}"),
+			Headers: map[string]string{correlation.MessageHeader: "cor_123"},
+		},
+		Group:    "webhooks",
+		Error:    "timeout",
+		Attempts: 5,
+	})
+	require.NoError(t, err)
+
+	stored, err := repo.List(ctx, repositories.DeadLetterFilter{})
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, entities.DeadLetterKindEvent, stored[0].Kind)
+	assert.Equal(t, "webhooks", stored[0].ConsumerGroup)
+	assert.Equal(t, "msg_1", stored[0].MessageID)
+	assert.Equal(t, "timeout", stored[0].Error)
+	assert.Equal(t, "cor_123", stored[0].CorrelationID)
+	assert.NotEmpty(t, stored[0].ID)
+}
+
+func TestJobQueue(t *testing.T) {
+	ctx := correlation.WithID(context.Background(), "cor_123")
+	broker, err := NewBroker(configs.MessagingConfig{Driver: "memory", BufferSize: 8}, logger.New())
+	require.NoError(t, err)
+
+	ran := make(chan string, 1)
+	handler := JobHandler("users.export", 1, func(ctx context.Context, id string) error {
+		ran <- id
+		return nil
+	})
+	assert.Equal(t, []string{"jobs.users.export"}, handler.Topics)
+
+	_, err = broker.Subscribe(ctx, handler.Topics[0], handler.Name, handler.Handle)
+	require.NoError(t, err)
+
+	job := entities.NewJob("users.export", "user_1", nil)
+	job.ID = "job_1"
+	require.NoError(t, NewJobQueue(broker).Enqueue(ctx, job))
+	require.NoError(t, broker.Close())
+
+	assert.Equal(t, "job_1", <-ran)
+}
+
+func TestDeadLetterSink_Job(t *testing.T) {
+	ctx := context.Background()
+	repo := database.NewMockDeadLetterRepository()
+
+	err := NewDeadLetterSink(repo).DeadLetter(ctx, consumer.DeadLetter{
+		Message: messaging.Message{ID: "job_1", Topic: JobTopic("users.export"), Payload: []byte("job_1")},
+		Group:   "jobs-users.export",
+		Error:   "storage unavailable",
+	})
+	require.NoError(t, err)
+
+	stored, err := repo.List(ctx, repositories.DeadLetterFilter{Kind: entities.DeadLetterKindJob})
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+}
+
+func TestAuditHandler(t *testing.T) {
+	ctx := context.Background()
+	var recorded []events.Event
+	handler := AuditHandler(func(ctx context.Context, event events.Event) error {
+		recorded = append(recorded, event)
+		return nil
+	})
+	assert.Equal(t, events.Types, handler.Topics)
+
+	event := events.NewEvent(events.UserDeleted, "user_1", map[string]string{"email": "jane@example.com"})
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	require.NoError(t, handler.Handle(ctx, messaging.Message{Topic: event.Type, Payload: payload}))
+	require.Len(t, recorded, 1)
+	assert.Equal(t, event.ID, recorded[0].ID)
+	assert.Equal(t, "user_1", recorded[0].AggregateID)
+
+	err = handler.Handle(ctx, messaging.Message{Topic: event.Type, Payload: []byte("{")})
+	assert.ErrorContains(t, err, "decode event user.deleted")
+}
+
+func TestNotificationHandler(t *testing.T) {
+	ctx := context.Background()
+	topics := []string{events.UserCreated, events.UserDeleted}
+	var notified []string
+	handler := NotificationHandler("webhook", topics, func(ctx context.Context, channel string, event events.Event) error {
+		notified = append(notified, channel+" "+event.Type)
+		return nil
+	})
+	assert.Equal(t, "notifications-webhook", handler.Name, "each channel is its own consumer group")
+	assert.Equal(t, topics, handler.Topics)
+
+	payload, err := json.Marshal(events.NewEvent(events.UserCreated, "user_1", nil))
+	require.NoError(t, err)
+	require.NoError(t, handler.Handle(ctx, messaging.Message{Topic: events.UserCreated, Payload: payload}))
+	assert.Equal(t, []string{"webhook user.created"}, notified)
+
+	err = handler.Handle(ctx, messaging.Message{Topic: events.UserCreated, Payload: []byte("{")})
+	assert.ErrorContains(t, err, "decode event user.created")
+}
+
+func TestWebhookHandler(t *testing.T) {
+	ctx := context.Background()
+	var enqueued []events.Event
+	handler := WebhookHandler(func(ctx context.Context, event events.Event) error {
+		enqueued = append(enqueued, event)
+		return nil
+	})
+	assert.Equal(t, "webhooks", handler.Name)
+	assert.Equal(t, events.Types, handler.Topics)
+
+	event := events.NewEvent(events.UserCreated, "user_1", nil)
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	require.NoError(t, handler.Handle(ctx, messaging.Message{Topic: event.Type, Payload: payload}))
+	require.Len(t, enqueued, 1)
+	assert.Equal(t, event.ID, enqueued[0].ID)
+
+	err = handler.Handle(ctx, messaging.Message{Topic: event.Type, Payload: []byte("{")})
+	assert.ErrorContains(t, err, "decode event user.created")
+}
+
+func TestEventStreamHandler(t *testing.T) {
+	ctx := context.Background()
+	var published []events.Event
+	handler := EventStreamHandler("event-stream-api-1", func(ctx context.Context, event events.Event) error {
+		published = append(published, event)
+		return nil
+	})
+	assert.Equal(t, "event-stream-api-1", handler.Name)
+	assert.Equal(t, events.UserChanges, handler.Topics)
+	assert.Equal(t, 1, handler.MaxAttempts)
+
+	event := events.NewEvent(events.UserDeleted, "user_1", nil)
+	payload, err := json.Marshal(event)
+	require.NoError(t, err)
+	require.NoError(t, handler.Handle(ctx, messaging.Message{Topic: event.Type, Payload: payload}))
+	require.Len(t, published, 1)
+	assert.Equal(t, event.ID, published[0].ID)
+
+	err = handler.Handle(ctx, messaging.Message{Topic: event.Type, Payload: []byte("{")})
+	assert.ErrorContains(t, err, "decode event user.deleted")
+}
+
+func TestKafkaHandler(t *testing.T) {
+	ctx := context.Background()
+	var produced []kafka.Message
+	produceErr := error(nil)
+	handler := KafkaHandler("app.", 4, func(ctx context.Context, records ...kafka.Message) error {
+		produced = append(produced, records...)
+		return produceErr
+	})
+	assert.Equal(t, "kafka", handler.Name)
+	assert.Equal(t, events.Types, handler.Topics)
+	assert.Equal(t, 4, handler.Concurrency)
+
+	msg := messaging.Message{
+		ID:      "msg_1",
+		Topic:   events.UserCreated,
+		Key:     "user_1",
+		Payload: []byte(`{"type":"user.created"}`),
+		Headers: map[string]string{correlation.MessageHeader: "cor_123"},
+	}
+	require.NoError(t, handler.Handle(ctx, msg))
+	require.Len(t, produced, 1)
+	assert.Equal(t, "app.user.created", produced[0].Topic)
+	assert.Equal(t, []byte("user_1"), produced[0].Key)
+	assert.Equal(t, msg.Payload, produced[0].Value)
+	assert.ElementsMatch(t, []kafka.Header{
+		{Key: correlation.MessageHeader, Value: []byte("cor_123")},
+		{Key: "message-id", Value: []byte("msg_1")},
+	}, produced[0].Headers)
+	assert.NotContains(t, msg.Headers, "message-id", "the message's own headers are left alone")
+
+	msg.Key = ""
+	require.NoError(t, handler.Handle(ctx, msg))
+	assert.Nil(t, produced[1].Key, "records without a key are spread over the partitions")
+
+	produceErr = kafka.WriteErrors{kafka.NotLeaderForPartition}
+	assert.Equal(t, produceErr, handler.Handle(ctx, msg), "leader changes are retried")
+
+	produceErr = kafka.WriteErrors{kafka.MessageSizeTooLarge}
+	err := handler.Handle(ctx, msg)
+	assert.ErrorContains(t, err, "Message Size Too Large")
+	assert.True(t, consumer.IsPermanent(err), "records the broker rejects are dead-lettered")
+}
+
+func TestNewBroker_NATSUnreachable(t *testing.T) {
+	_, err := NewBroker(configs.MessagingConfig{
+		Driver: "nats",
+		NATS:   configs.NATSConfig{Servers: []string{"127.0.0.1:1"}, Timeout: time.Second, ReconnectWait: time.Second},
+	}, logger.New())
+	assert.ErrorContains(t, err, "connect to nats")
+}
+
+func TestNATSBroker_MessageRoundTrip(t *testing.T) {
+	broker := &NATSBroker{cfg: configs.NATSConfig{SubjectPrefix: "events."}}
+	sent := messaging.Message{
+		ID:        "evt_1",
+		Topic:     events.UserCreated,
+		Key:       "user_1",
+		Payload:   []byte(`{"type":"user.created"}`),
+		Headers:   map[string]string{correlation.MessageHeader: "cor_123"},
+		Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 5, time.UTC),
+	}
+
+	header := natsHeader(sent)
+	assert.Equal(t, "evt_1", header.Get("Nats-Msg-Id"), "JetStream deduplicates by the message ID")
+	assert.NotContains(t, sent.Headers, "Nats-Msg-Id", "the message's own headers are left alone")
+
+	received := broker.message(&nats.Msg{Subject: "events.user.created", Header: header, Data: sent.Payload}, nil)
+	assert.Equal(t, sent, received)
+}
+
+func TestNATSBroker_ForeignMessage(t *testing.T) {
+	broker := &NATSBroker{cfg: configs.NATSConfig{SubjectPrefix: "events."}}
+	meta := &jetstream.MsgMetadata{Stream: "EVENTS", Sequence: jetstream.SequencePair{Stream: 42}, Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	msg := broker.message(&nats.Msg{Subject: "events.order.placed", Header: nats.Header{"source": {"orders"}}, Data: []byte("{}")}, meta)
+	assert.Equal(t, "order.placed", msg.Topic)
+	assert.Equal(t, "EVENTS-42", msg.ID, "redeliveries keep the ID of their stream sequence")
+	assert.Equal(t, meta.Timestamp, msg.Timestamp)
+	assert.Equal(t, map[string]string{"source": "orders"}, msg.Headers)
+
+	msg = broker.message(&nats.Msg{Subject: "events.order.placed"}, nil)
+	assert.NotEmpty(t, msg.ID)
+	assert.False(t, msg.Timestamp.IsZero())
+}
+
+func TestNATSName(t *testing.T) {
+	assert.Equal(t, "audit-log_user_created", natsName("audit-log_user.created"))
+	assert.Equal(t, "event-streams_host_1__a", natsName("event-streams host/1.*a"))
+}
+
+func TestImportUserCommand(t *testing.T) {
+	var imported []string
+	handler := ImportUserCommand(func(ctx context.Context, email, name string) (*entities.User, bool, error) {
+		imported = append(imported, email+"/"+name)
+		return &entities.User{Email: email, Name: name}, true, nil
+	})
+
+	require.NoError(t, handler(context.Background(), []byte(`{"email":"ada@example.com","name":"Ada"}`)))
+	assert.Equal(t, []string{"ada@example.com/Ada"}, imported)
+
+	err := handler(context.Background(), []byte(`{"email":"ada@example.com"}`))
+	assert.True(t, consumer.IsPermanent(err), "commands without a name are rejected")
+	err = handler(context.Background(), []byte(`not json`))
+	assert.True(t, consumer.IsPermanent(err), "malformed commands are rejected")
+	assert.Len(t, imported, 1)
+
+	failing := ImportUserCommand(func(ctx context.Context, email, name string) (*entities.User, bool, error) {
+		return nil, false, errors.New("database is down")
+	})
+	err = failing(context.Background(), []byte(`{"email":"ada@example.com","name":"Ada"}`))
+	require.Error(t, err)
+	assert.False(t, consumer.IsPermanent(err), "failed imports are retried")
+}
+
+func TestDeleteUserCommand(t *testing.T) {
+	var deleted []string
+	handler := DeleteUserCommand(func(ctx context.Context, id string) error {
+		deleted = append(deleted, id)
+		if id == "gone" {
+			return repositories.ErrUserNotFound
+		}
+		return nil
+	})
+
+	require.NoError(t, handler(context.Background(), []byte(`{"id":"user-1"}`)))
+	assert.NoError(t, handler(context.Background(), []byte(`{"id":"gone"}`)), "users already deleted are done")
+	assert.Equal(t, []string{"user-1", "gone"}, deleted)
+
+	assert.True(t, consumer.IsPermanent(handler(context.Background(), []byte(`{}`))))
+}
+
+// settlement records how a test delivery was settled
+type settlement struct {
+	acked, nacked, requeued bool
+}
+
+func (s *settlement) Ack(tag uint64, multiple bool) error {
+	s.acked = true
+	return nil
+}
+
+func (s *settlement) Nack(tag uint64, multiple, requeue bool) error {
+	s.nacked, s.requeued = true, requeue
+	return nil
+}
+
+func (s *settlement) Reject(tag uint64, requeue bool) error {
+	return s.Nack(tag, false, requeue)
+}
+
+func TestCommandWorker_Process(t *testing.T) {
+	worker := NewCommandWorker(configs.RabbitMQConfig{Queue: "commands"}, logger.New())
+	var correlationID string
+	worker.Handle("ok", func(ctx context.Context, payload []byte) error {
+		correlationID = correlation.ID(ctx)
+		return nil
+	})
+	worker.Handle("flaky", func(ctx context.Context, payload []byte) error {
+		return errors.New("database is down")
+	})
+	worker.Handle("invalid", func(ctx context.Context, payload []byte) error {
+		return consumer.Permanent(errors.New("id is required"))
+	})
+
+	tests := []struct {
+		name        string
+		commandType string
+		redelivered bool
+		want        settlement
+	}{
+		{name: "handled", commandType: "ok", want: settlement{acked: true}},
+		{name: "failed", commandType: "flaky", want: settlement{nacked: true, requeued: true}},
+		{name: "failed again", commandType: "flaky", redelivered: true, want: settlement{nacked: true}},
+		{name: "permanent failure", commandType: "invalid", want: settlement{nacked: true}},
+		{name: "unknown type", commandType: "user.launch", want: settlement{nacked: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &settlement{}
+			worker.process(context.Background(), amqp.Delivery{
+				Type:          tt.commandType,
+				CorrelationId: "req-1",
+				Acknowledger:  got,
+				Redelivered:   tt.redelivered,
+			})
+			assert.Equal(t, tt.want, *got)
+		})
+	}
+	assert.Equal(t, "req-1", correlationID, "handlers see the correlation ID of the command")
+}
+
+func TestCommandWorker_StopWhileReconnecting(t *testing.T) {
+	worker := NewCommandWorker(configs.RabbitMQConfig{
+		URL:           "amqp://127.0.0.1:1",
+		Queue:         "commands",
+		Timeout:       100 * time.Millisecond,
+		ReconnectWait: time.Hour,
+	}, logger.New())
+	worker.Start(context.Background())
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- worker.Stop() }()
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop waited for the reconnect")
+	}
+	assert.NoError(t, worker.Stop(), "stopping twice is fine")
+}
+
+func TestTLSConfig(t *testing.T) {
+	cfg, err := tlsConfig("")
+	require.NoError(t, err)
+	assert.Nil(t, cfg.RootCAs, "the system roots are trusted without a CA file")
+
+	_, err = tlsConfig(filepath.Join(t.TempDir(), "missing.pem"))
+	assert.ErrorContains(t, err, "read CA file")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+	_, err = tlsConfig(notPEM)
+	assert.ErrorContains(t, err, "contains no PEM certificates")
+}
@@ -0,0 +1,499 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"clean-architecture/configs"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+)
+
+// Headers carrying the message fields NATS has no place for. The ID goes in
+// Nats-Msg-Id, which JetStream deduplicates publishes by.
+const (
+	natsIDHeader        = "Nats-Msg-Id"
+	natsKeyHeader       = "message-key"
+	natsTimestampHeader = "message-timestamp"
+)
+
+const (
+	// natsFetchExpiry is how long a pull consumer waits for a message
+	// before asking again
+	natsFetchExpiry = 30 * time.Second
+	// natsInactiveThreshold is how long JetStream keeps the durable
+	// consumer of a group nobody fetches for. Groups unique to an instance
+	// would otherwise pile up with every deployment.
+	natsInactiveThreshold = 24 * time.Hour
+)
+
+// NATSBroker is a messaging.Broker publishing each topic to the NATS
+// subject of the same name under the configured prefix. Consumer groups
+// are queue groups, or with JetStream durable pull consumers, so a group
+// catches up on the messages published while none of its members ran.
+// Either way NATS shares a group's messages between its members without
+// regard to their key, so messages sharing a key may be handled out of
+// order by a group with more than one member. The connection is made and
+// kept up by nats.go, over TLS for tls:// servers or with a CA file.
+type NATSBroker struct {
+	conn         *nats.Conn
+	js           jetstream.JetStream
+	cfg          configs.NATSConfig
+	retryBackoff time.Duration
+	// pendingLimit bounds the messages a queue group member buffers for
+	// its handler; more are dropped and reported as a slow consumer
+	pendingLimit int
+	logger       logger.Logger
+
+	mu     sync.Mutex
+	closed bool
+	subs   map[messaging.Subscription]struct{}
+}
+
+// NewNATSBroker connects to the NATS servers of cfg and, with JetStream,
+// creates the stream capturing every subject under the prefix unless it
+// exists
+func NewNATSBroker(cfg configs.MessagingConfig, logger logger.Logger) (*NATSBroker, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.NATS.Timeout)
+	defer cancel()
+
+	opts := []nats.Option{
+		nats.Name(cfg.NATS.Name),
+		nats.Timeout(cfg.NATS.Timeout),
+		nats.ReconnectWait(cfg.NATS.ReconnectWait),
+		nats.MaxReconnects(-1),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			logger.WithField("error", err.Error()).Warn("NATS connection error")
+		}),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.WithField("error", err.Error()).Warn("NATS connection lost, reconnecting")
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			logger.WithField("server", conn.ConnectedUrlRedacted()).Info("NATS connection reestablished")
+		}),
+	}
+	if cfg.NATS.User != "" {
+		opts = append(opts, nats.UserInfo(cfg.NATS.User, cfg.NATS.Password))
+	}
+	if cfg.NATS.Token != "" {
+		opts = append(opts, nats.Token(cfg.NATS.Token))
+	}
+	if cfg.NATS.CAFile != "" || natsTLS(cfg.NATS.Servers) {
+		tlsConfig, err := tlsConfig(cfg.NATS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("connect to nats: %w", err)
+		}
+		opts = append(opts, nats.Secure(tlsConfig))
+	}
+	conn, err := nats.Connect(strings.Join(cfg.NATS.Servers, ","), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+
+	b := &NATSBroker{
+		conn:         conn,
+		cfg:          cfg.NATS,
+		retryBackoff: cfg.MaxRetryBackoff,
+		pendingLimit: cfg.BufferSize,
+		logger:       logger,
+		subs:         make(map[messaging.Subscription]struct{}),
+	}
+	if cfg.NATS.JetStream {
+		if b.js, err = jetstream.New(conn); err == nil {
+			err = b.ensureStream(ctx)
+		}
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ensure nats stream %s: %w", cfg.NATS.Stream, err)
+		}
+	}
+	return b, nil
+}
+
+// natsTLS reports whether any of servers is a tls:// URL
+func natsTLS(servers []string) bool {
+	for _, server := range servers {
+		if strings.HasPrefix(server, "tls://") {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureStream creates the stream capturing every subject under the prefix
+// unless it exists. An existing stream is left as it is, so its
+// configuration can be managed out of band.
+func (b *NATSBroker) ensureStream(ctx context.Context) error {
+	_, err := b.js.Stream(ctx, b.cfg.Stream)
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return err
+	}
+	_, err = b.js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:       b.cfg.Stream,
+		Subjects:   []string{b.cfg.SubjectPrefix + ">"},
+		MaxAge:     b.cfg.StreamMaxAge,
+		Duplicates: 2 * time.Minute,
+	})
+	if errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		// Another instance created it meanwhile
+		return nil
+	}
+	return err
+}
+
+// Publish implements messaging.Publisher. With JetStream it returns once
+// the stream stored the message.
+func (b *NATSBroker) Publish(ctx context.Context, msg messaging.Message) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return messaging.ErrClosed
+	}
+
+	m := &nats.Msg{Subject: b.cfg.SubjectPrefix + msg.Topic, Header: natsHeader(msg), Data: msg.Payload}
+	var err error
+	if b.js != nil {
+		_, err = b.js.PublishMsg(ctx, m)
+	} else {
+		err = b.conn.PublishMsg(m)
+	}
+	if errors.Is(err, nats.ErrConnectionClosed) {
+		return messaging.ErrClosed
+	}
+	if err != nil {
+		return fmt.Errorf("publish %s: %w", m.Subject, err)
+	}
+	return nil
+}
+
+// Subscribe implements messaging.Subscriber. Handlers run one message at a
+// time per subscription; a failing message is left for JetStream to
+// deliver again after the maximum retry backoff, or dropped without it.
+func (b *NATSBroker) Subscribe(ctx context.Context, topic, group string, handler messaging.Handler) (messaging.Subscription, error) {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return nil, messaging.ErrClosed
+	}
+
+	subject := b.cfg.SubjectPrefix + topic
+	var sub messaging.Subscription
+	var err error
+	if b.js != nil {
+		sub, err = b.pullSubscribe(ctx, subject, group, handler)
+	} else {
+		sub, err = b.queueSubscribe(subject, group, handler)
+	}
+	if errors.Is(err, nats.ErrConnectionClosed) {
+		return nil, messaging.ErrClosed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("subscribe %s for %s: %w", subject, group, err)
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		sub.Unsubscribe() //nolint:errcheck
+		return nil, messaging.ErrClosed
+	}
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub, nil
+}
+
+func (b *NATSBroker) queueSubscribe(subject, group string, handler messaging.Handler) (messaging.Subscription, error) {
+	sub, err := b.conn.QueueSubscribe(subject, natsName(group), func(m *nats.Msg) {
+		msg := b.message(m, nil)
+		if err := handler(context.Background(), msg); err != nil {
+			b.handlerFailed(msg, group, err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := sub.SetPendingLimits(b.pendingLimit, nats.DefaultSubPendingBytesLimit); err != nil {
+		sub.Unsubscribe() //nolint:errcheck
+		return nil, err
+	}
+	return &natsQueueSubscription{broker: b, sub: sub}, nil
+}
+
+func (b *NATSBroker) pullSubscribe(ctx context.Context, subject, group string, handler messaging.Handler) (messaging.Subscription, error) {
+	consumer := jetstream.ConsumerConfig{
+		Durable:       natsName(group + "_" + strings.TrimPrefix(subject, b.cfg.SubjectPrefix)),
+		FilterSubject: subject,
+		// A new group starts with the messages published from now on, as
+		// it would on any other driver
+		DeliverPolicy:     jetstream.DeliverNewPolicy,
+		AckPolicy:         jetstream.AckExplicitPolicy,
+		AckWait:           b.cfg.AckWait,
+		MaxDeliver:        -1,
+		InactiveThreshold: natsInactiveThreshold,
+	}
+	if _, err := b.js.CreateOrUpdateConsumer(ctx, b.cfg.Stream, consumer); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	sub := &natsPullSubscription{
+		broker:   b,
+		consumer: consumer,
+		group:    group,
+		handler:  handler,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	go sub.run(runCtx)
+	return sub, nil
+}
+
+// Ping checks the connection to the server is alive
+func (b *NATSBroker) Ping(ctx context.Context) error {
+	return b.conn.FlushWithContext(ctx)
+}
+
+// Close unsubscribes every group member, waiting for their handlers, and
+// closes the connection
+func (b *NATSBroker) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	subs := make([]messaging.Subscription, 0, len(b.subs))
+	for sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		if err := sub.Unsubscribe(); err != nil {
+			b.logger.WithField("error", err.Error()).Warn("Failed to unsubscribe from NATS")
+		}
+	}
+	b.conn.Close()
+	return nil
+}
+
+func (b *NATSBroker) forget(sub messaging.Subscription) {
+	b.mu.Lock()
+	delete(b.subs, sub)
+	b.mu.Unlock()
+}
+
+// message decodes a NATS message, meta set for those fetched from
+// JetStream. Messages from publishers that do not set the ID header get
+// one from their stream sequence, so redeliveries keep it.
+func (b *NATSBroker) message(m *nats.Msg, meta *jetstream.MsgMetadata) messaging.Message {
+	msg := messaging.Message{
+		Topic:   strings.TrimPrefix(m.Subject, b.cfg.SubjectPrefix),
+		Payload: m.Data,
+		Headers: make(map[string]string, len(m.Header)),
+	}
+	for key := range m.Header {
+		value := m.Header.Get(key)
+		switch key {
+		case natsIDHeader:
+			msg.ID = value
+		case natsKeyHeader:
+			msg.Key = value
+		case natsTimestampHeader:
+			msg.Timestamp, _ = time.Parse(time.RFC3339Nano, value)
+		default:
+			msg.Headers[key] = value
+		}
+	}
+	if msg.ID == "" {
+		msg.ID = messaging.NewMessageID()
+		if meta != nil {
+			msg.ID = fmt.Sprintf("%s-%d", meta.Stream, meta.Sequence.Stream)
+		}
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+		if meta != nil {
+			msg.Timestamp = meta.Timestamp
+		}
+	}
+	return msg
+}
+
+func (b *NATSBroker) handlerFailed(msg messaging.Message, group string, err error) {
+	b.logger.WithFields(map[string]interface{}{
+		"topic":      msg.Topic,
+		"group":      group,
+		"message_id": msg.ID,
+		"error":      err.Error(),
+	}).Error("Message handler failed")
+}
+
+// natsHeader encodes the fields of msg NATS has no place for as headers
+func natsHeader(msg messaging.Message) nats.Header {
+	header := make(nats.Header, len(msg.Headers)+3)
+	for key, value := range msg.Headers {
+		header.Set(key, value)
+	}
+	header.Set(natsIDHeader, msg.ID)
+	if msg.Key != "" {
+		header.Set(natsKeyHeader, msg.Key)
+	}
+	timestamp := msg.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	header.Set(natsTimestampHeader, timestamp.UTC().Format(time.RFC3339Nano))
+	return header
+}
+
+// natsName turns a group or consumer name into one NATS accepts, which
+// excludes dots, wildcards, slashes and whitespace
+func natsName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', '/', '\\', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// natsQueueSubscription is a queue group member on core NATS
+type natsQueueSubscription struct {
+	broker *NATSBroker
+	sub    *nats.Subscription
+}
+
+// Unsubscribe implements messaging.Subscription
+func (s *natsQueueSubscription) Unsubscribe() error {
+	s.broker.forget(s)
+	return s.sub.Unsubscribe()
+}
+
+// natsPullSubscription is a member of the group sharing a JetStream
+// durable consumer
+type natsPullSubscription struct {
+	broker   *NATSBroker
+	consumer jetstream.ConsumerConfig
+	group    string
+	handler  messaging.Handler
+
+	once   sync.Once
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// run handles messages until ctx is canceled, pulling them again after
+// ReconnectWait whenever pulling fails
+func (s *natsPullSubscription) run(ctx context.Context) {
+	defer close(s.done)
+	b := s.broker
+	for ctx.Err() == nil {
+		err := s.pull(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		b.logger.WithFields(map[string]interface{}{
+			"consumer": s.consumer.Durable,
+			"error":    err.Error(),
+		}).Warn("Failed to fetch from NATS consumer")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(b.cfg.ReconnectWait):
+		}
+		// The server removes consumers nobody fetched from for a while, so
+		// one may be gone after a long disconnection
+		ensureCtx, cancel := context.WithTimeout(ctx, b.cfg.Timeout)
+		_, _ = b.js.CreateOrUpdateConsumer(ensureCtx, b.cfg.Stream, s.consumer)
+		cancel()
+	}
+}
+
+// pull handles the messages of the durable consumer one at a time until
+// ctx is canceled or pulling fails
+func (s *natsPullSubscription) pull(ctx context.Context) error {
+	b := s.broker
+	consumer, err := b.js.Consumer(ctx, b.cfg.Stream, s.consumer.Durable)
+	if err != nil {
+		return err
+	}
+	msgs, err := consumer.Messages(jetstream.PullMaxMessages(1), jetstream.PullExpiry(natsFetchExpiry))
+	if err != nil {
+		return err
+	}
+	defer msgs.Stop()
+	for {
+		m, err := msgs.Next(jetstream.NextContext(ctx))
+		if err != nil {
+			return err
+		}
+		s.handle(m)
+	}
+}
+
+// handle runs the handler on m, telling JetStream the message is still
+// being worked on for as long as it runs
+func (s *natsPullSubscription) handle(m jetstream.Msg) {
+	b := s.broker
+	meta, _ := m.Metadata()
+	msg := b.message(&nats.Msg{Subject: m.Subject(), Header: m.Headers(), Data: m.Data()}, meta)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(b.cfg.AckWait / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				_ = m.InProgress()
+			}
+		}
+	}()
+	err := s.handler(context.Background(), msg)
+	close(stop)
+	wg.Wait()
+
+	if err != nil {
+		b.handlerFailed(msg, s.group, err)
+		err = m.NakWithDelay(b.retryBackoff)
+	} else {
+		err = m.Ack()
+	}
+	if err != nil {
+		b.logger.WithFields(map[string]interface{}{
+			"topic":      msg.Topic,
+			"message_id": msg.ID,
+			"error":      err.Error(),
+		}).Warn("Failed to acknowledge NATS message")
+	}
+}
+
+// Unsubscribe implements messaging.Subscription. It waits for the message
+// being handled, if any; the durable consumer is kept for the group's
+// other members and its next start.
+func (s *natsPullSubscription) Unsubscribe() error {
+	s.once.Do(func() {
+		s.broker.forget(s)
+		s.cancel()
+		<-s.done
+	})
+	return nil
+}
@@ -0,0 +1,28 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+// NotificationHandler returns the event handler notifying channel of the
+// events on topics with notify. Each channel is its own consumer group, so
+// its failures are retried and dead-lettered apart from the others.
+func NotificationHandler(channel string, topics []string, notify func(ctx context.Context, channel string, event events.Event) error) consumer.Handler {
+	return consumer.Handler{
+		Name:   "notifications-" + channel,
+		Topics: topics,
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			var event events.Event
+			if err := json.Unmarshal(msg.Payload, &event); err != nil {
+				return consumer.Permanent(fmt.Errorf("decode event %s: %w", msg.Topic, err))
+			}
+			return notify(ctx, channel, event)
+		},
+	}
+}
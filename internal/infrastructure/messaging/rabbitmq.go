@@ -0,0 +1,230 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"clean-architecture/configs"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+// commandConsumerTag tags the worker's consumer on its channel
+const commandConsumerTag = "command-worker"
+
+// CommandWorker runs the commands consumed from a RabbitMQ queue with the
+// handler registered for their type. Handled commands are acknowledged.
+// Failed commands are requeued once to be retried, by any instance, and
+// rejected when they fail again or fail permanently, as do commands of
+// unknown types; rejected commands go to the queue's dead letter exchange,
+// if it has one. The worker reconnects when the connection drops, and
+// commands being handled then are delivered again. amqps:// URLs connect
+// over TLS.
+type CommandWorker struct {
+	cfg    configs.RabbitMQConfig
+	dial   func() (*amqp.Connection, error)
+	logger logger.Logger
+
+	mu       sync.Mutex
+	handlers map[string]CommandHandler
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewCommandWorker creates a worker consuming the queue of cfg
+func NewCommandWorker(cfg configs.RabbitMQConfig, logger logger.Logger) *CommandWorker {
+	return &CommandWorker{
+		cfg:      cfg,
+		dial:     func() (*amqp.Connection, error) { return dialRabbitMQ(cfg) },
+		logger:   logger,
+		handlers: make(map[string]CommandHandler),
+	}
+}
+
+// dialRabbitMQ connects to the server of cfg, over TLS for amqps:// URLs
+func dialRabbitMQ(cfg configs.RabbitMQConfig) (*amqp.Connection, error) {
+	config := amqp.Config{
+		// Intervals under a second take the server's
+		Heartbeat: max(cfg.Heartbeat, time.Millisecond),
+		Locale:    "en_US",
+		Dial:      amqp.DefaultDial(cfg.Timeout),
+		Properties: amqp.Table{
+			"product":         "clean-architecture",
+			"connection_name": "clean-architecture command worker",
+		},
+	}
+	if u, err := url.Parse(cfg.URL); err == nil && u.Scheme == "amqps" {
+		tlsConfig, err := tlsConfig(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.TLSClientConfig = tlsConfig
+	}
+	return amqp.DialConfig(cfg.URL, config)
+}
+
+// Handle registers handler for commands of commandType. Handlers
+// registered after Start are used right away.
+func (w *CommandWorker) Handle(commandType string, handler CommandHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[commandType] = handler
+}
+
+// Start consumes commands in the background until Stop
+func (w *CommandWorker) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+	go w.run(ctx, w.done)
+}
+
+// Stop stops consuming and waits for the commands already delivered to be
+// handled and settled
+func (w *CommandWorker) Stop() error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-done
+	return nil
+}
+
+// run consumes commands, connecting again after ReconnectWait whenever the
+// connection fails
+func (w *CommandWorker) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	for {
+		err := w.consume(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.WithFields(map[string]interface{}{
+			"queue": w.cfg.Queue,
+			"error": err.Error(),
+		}).Warn("RabbitMQ command consumer failed, reconnecting")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.cfg.ReconnectWait):
+		}
+	}
+}
+
+// consume runs the commands of one connection until ctx is cancelled or
+// the connection fails
+func (w *CommandWorker) consume(ctx context.Context) error {
+	conn, err := w.dial()
+	if err != nil {
+		return fmt.Errorf("connect to rabbitmq: %w", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("open channel: %w", err)
+	}
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+	if err := ch.Qos(w.cfg.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("set prefetch: %w", err)
+	}
+	args := amqp.Table{}
+	if w.cfg.DeadLetterExchange != "" {
+		args["x-dead-letter-exchange"] = w.cfg.DeadLetterExchange
+	}
+	if _, err := ch.QueueDeclare(w.cfg.Queue, true, false, false, false, args); err != nil {
+		return fmt.Errorf("declare queue %s: %w", w.cfg.Queue, err)
+	}
+	deliveries, err := ch.Consume(w.cfg.Queue, commandConsumerTag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("consume queue %s: %w", w.cfg.Queue, err)
+	}
+	w.logger.WithFields(map[string]interface{}{
+		"queue":    w.cfg.Queue,
+		"prefetch": w.cfg.Prefetch,
+		"workers":  w.cfg.Workers,
+	}).Info("Consuming commands from RabbitMQ")
+
+	// Commands run to completion once delivered, also while stopping
+	handlerCtx := context.WithoutCancel(ctx)
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range deliveries {
+				w.process(handlerCtx, d)
+			}
+		}()
+	}
+
+	var closeErr *amqp.Error
+	select {
+	case <-ctx.Done():
+		// Commands delivered before the cancel are still handled
+		if err := ch.Cancel(commandConsumerTag, false); err != nil {
+			w.logger.WithField("error", err.Error()).Warn("Failed to cancel RabbitMQ command consumer")
+		}
+	case closeErr = <-closed:
+	}
+	wg.Wait()
+	if closeErr != nil {
+		return closeErr
+	}
+	if ctx.Err() == nil {
+		return amqp.ErrClosed
+	}
+	return ctx.Err()
+}
+
+// process runs one command and acknowledges or rejects it
+func (w *CommandWorker) process(ctx context.Context, d amqp.Delivery) {
+	log := w.logger.WithFields(map[string]interface{}{
+		"command":        d.Type,
+		"message_id":     d.MessageId,
+		"correlation_id": d.CorrelationId,
+		"redelivered":    d.Redelivered,
+	})
+
+	w.mu.Lock()
+	handler := w.handlers[d.Type]
+	w.mu.Unlock()
+	var err error
+	if handler == nil {
+		err = consumer.Permanent(fmt.Errorf("unknown command type %q", d.Type))
+	} else {
+		// Handlers see the correlation ID of the call that sent the command
+		if d.CorrelationId != "" {
+			ctx = correlation.WithID(ctx, d.CorrelationId)
+		}
+		err = handler(ctx, d.Body)
+	}
+
+	var settleErr error
+	switch {
+	case err == nil:
+		settleErr = d.Ack(false)
+		log.Debug("Command handled")
+	case consumer.IsPermanent(err) || d.Redelivered:
+		settleErr = d.Nack(false, false)
+		log.WithField("error", err.Error()).Warn("Command failed, rejecting it")
+	default:
+		settleErr = d.Nack(false, true)
+		log.WithField("error", err.Error()).Warn("Command failed, requeueing it")
+	}
+	if settleErr != nil {
+		// The server delivers the command again once the channel closes
+		log.WithField("error", settleErr.Error()).Error("Failed to settle command")
+	}
+}
@@ -0,0 +1,29 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+// WebhookHandler returns the event handler recording a delivery of every
+// domain event to the webhooks subscribing to it with enqueue. The
+// deliveries are attempted later, so a slow receiver does not hold up the
+// consumer.
+func WebhookHandler(enqueue func(ctx context.Context, event events.Event) error) consumer.Handler {
+	return consumer.Handler{
+		Name:   "webhooks",
+		Topics: events.Types,
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			var event events.Event
+			if err := json.Unmarshal(msg.Payload, &event); err != nil {
+				return consumer.Permanent(fmt.Errorf("decode event %s: %w", msg.Topic, err))
+			}
+			return enqueue(ctx, event)
+		},
+	}
+}
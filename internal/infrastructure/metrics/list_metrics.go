@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+// deepOffset is the offset from which a page counts as deep in summaries;
+// OFFSET reads and discards every row before it
+const deepOffset = 1000
+
+// ListMetrics exports how deep list queries page and how expensive the
+// planner expects them to be, to guide index and caching work. It observes
+// the queries reported by database.ListInstrumentation.
+type ListMetrics struct {
+	logger logger.Logger
+
+	offsets     *prometheus.HistogramVec
+	rowsScanned *prometheus.HistogramVec
+	cost        *prometheus.HistogramVec
+
+	// window accumulates per-table statistics until the next summary
+	mu     sync.Mutex
+	window map[string]*listSummary
+}
+
+// listSummary aggregates the list queries of one table
+type listSummary struct {
+	queries     int
+	deepQueries int
+	maxOffset   int
+	sampled     int
+	rowsScanned float64
+	maxRows     float64
+	maxCost     float64
+}
+
+// NewListMetrics creates the list query metrics
+func NewListMetrics(logger logger.Logger) *ListMetrics {
+	labels := []string{"table", "source"}
+	return &ListMetrics{
+		logger: logger,
+		offsets: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_list_query_offset_rows",
+			Help:    "Offset of list queries, showing how deep clients paginate.",
+			Buckets: []float64{0, 10, 50, 100, 500, 1000, 5000, 10000, 50000, 100000},
+		}, labels),
+		rowsScanned: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_list_query_scanned_rows",
+			Help:    "Rows the planner expects sampled list queries to scan, from EXPLAIN.",
+			Buckets: prometheus.ExponentialBuckets(10, 10, 7),
+		}, labels),
+		cost: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_list_query_plan_cost",
+			Help:    "Planner cost estimate of sampled list queries, from EXPLAIN.",
+			Buckets: prometheus.ExponentialBuckets(1, 10, 8),
+		}, labels),
+		window: make(map[string]*listSummary),
+	}
+}
+
+// ObserveList implements database.ListObserver
+func (m *ListMetrics) ObserveList(query database.ListQuery) {
+	source := "request"
+	if query.Background {
+		source = "background"
+	}
+	m.offsets.WithLabelValues(query.Table, source).Observe(float64(query.Offset))
+	if query.Plan != nil {
+		m.rowsScanned.WithLabelValues(query.Table, source).Observe(query.Plan.RowsScanned)
+		m.cost.WithLabelValues(query.Table, source).Observe(query.Plan.Cost)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	summary, ok := m.window[query.Table]
+	if !ok {
+		summary = &listSummary{}
+		m.window[query.Table] = summary
+	}
+	summary.queries++
+	if query.Offset >= deepOffset {
+		summary.deepQueries++
+	}
+	summary.maxOffset = max(summary.maxOffset, query.Offset)
+	if query.Plan != nil {
+		summary.sampled++
+		summary.rowsScanned += query.Plan.RowsScanned
+		summary.maxRows = max(summary.maxRows, query.Plan.RowsScanned)
+		summary.maxCost = max(summary.maxCost, query.Plan.Cost)
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *ListMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.offsets.Describe(ch)
+	m.rowsScanned.Describe(ch)
+	m.cost.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (m *ListMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.offsets.Collect(ch)
+	m.rowsScanned.Collect(ch)
+	m.cost.Collect(ch)
+}
+
+// Run logs a summary of the list queries seen every interval until ctx
+// is done
+func (m *ListMetrics) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.LogSummary()
+		}
+	}
+}
+
+// LogSummary logs one line per table queried since the previous summary
+// and starts a new window
+func (m *ListMetrics) LogSummary() {
+	m.mu.Lock()
+	window := m.window
+	m.window = make(map[string]*listSummary)
+	m.mu.Unlock()
+
+	tables := make([]string, 0, len(window))
+	for table := range window {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		summary := window[table]
+		fields := map[string]interface{}{
+			"table":        table,
+			"queries":      summary.queries,
+			"deep_queries": summary.deepQueries,
+			"max_offset":   summary.maxOffset,
+			"sampled":      summary.sampled,
+		}
+		if summary.sampled > 0 {
+			fields["avg_scanned_rows"] = summary.rowsScanned / float64(summary.sampled)
+			fields["max_scanned_rows"] = summary.maxRows
+			fields["max_plan_cost"] = summary.maxCost
+		}
+		m.logger.WithFields(fields).Info("List query summary")
+	}
+}
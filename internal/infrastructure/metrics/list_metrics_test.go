@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/metrics"
+)
+
+// summaryRecorder records the fields of each logged summary line
+type summaryRecorder struct {
+	logger.Logger
+	fields []map[string]interface{}
+}
+
+func (l *summaryRecorder) WithFields(fields map[string]interface{}) logger.Logger {
+	l.fields = append(l.fields, fields)
+	return l
+}
+
+func (l *summaryRecorder) Info(args ...interface{}) {}
+
+func TestListMetrics(t *testing.T) {
+	log := &summaryRecorder{}
+	listMetrics := NewListMetrics(log)
+	registry := metrics.NewRegistry()
+	registry.MustRegister(listMetrics)
+
+	listMetrics.ObserveList(database.ListQuery{Table: "users", Limit: 10, Offset: 0})
+	listMetrics.ObserveList(database.ListQuery{Table: "users", Limit: 10, Offset: 5000, Plan: &database.QueryPlan{RowsScanned: 120000, Cost: 4200}})
+	listMetrics.ObserveList(database.ListQuery{Table: "users", Limit: 10, Offset: 20, Plan: &database.QueryPlan{RowsScanned: 40000, Cost: 900}})
+	listMetrics.ObserveList(database.ListQuery{Table: "dead_letters", Background: true, Limit: 500, Offset: 1000})
+
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, `db_list_query_offset_rows_bucket{source="request",table="users",le="1000"} 2`)
+	assert.Contains(t, body, `db_list_query_offset_rows_count{source="request",table="users"} 3`)
+	assert.Contains(t, body, `db_list_query_offset_rows_count{source="background",table="dead_letters"} 1`)
+	assert.Contains(t, body, `db_list_query_scanned_rows_count{source="request",table="users"} 2`)
+	assert.Contains(t, body, `db_list_query_plan_cost_sum{source="request",table="users"} 5100`)
+
+	listMetrics.LogSummary()
+	assert.Equal(t, []map[string]interface{}{
+		{"table": "dead_letters", "queries": 1, "deep_queries": 1, "max_offset": 1000, "sampled": 0},
+		{
+			"table": "users", "queries": 3, "deep_queries": 1, "max_offset": 5000, "sampled": 2,
+			"avg_scanned_rows": float64(80000), "max_scanned_rows": float64(120000), "max_plan_cost": float64(4200),
+		},
+	}, log.fields)
+
+	log.fields = nil
+	listMetrics.LogSummary()
+	assert.Empty(t, log.fields, "each summary covers the queries since the previous one")
+}
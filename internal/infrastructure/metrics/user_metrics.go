@@ -0,0 +1,117 @@
+// Package metrics holds the business metrics exported by application
+// modules. Each module owns a prometheus.Collector that is registered on the
+// shared metrics registry in app.NewApp.
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/consumer"
+)
+
+const (
+	// activeUsersRefresh is how long a counted number of active users is
+	// reused before the repository is queried again
+	activeUsersRefresh = 30 * time.Second
+	countTimeout       = 2 * time.Second
+)
+
+// UserMetrics exports user lifecycle counters fed from domain events and an
+// active user gauge read from the repository
+type UserMetrics struct {
+	userRepo repositories.UserRepository
+	logger   logger.Logger
+
+	created prometheus.Counter
+	deleted prometheus.Counter
+	active  *prometheus.Desc
+
+	mu          sync.Mutex
+	activeUsers float64
+	countedAt   time.Time
+}
+
+// NewUserMetrics creates the user module's business metrics
+func NewUserMetrics(userRepo repositories.UserRepository, logger logger.Logger) *UserMetrics {
+	return &UserMetrics{
+		userRepo: userRepo,
+		logger:   logger,
+		created: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "users_created_total",
+			Help: "Total number of users created.",
+		}),
+		deleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "users_deleted_total",
+			Help: "Total number of users deleted.",
+		}),
+		active: prometheus.NewDesc(
+			"users_active",
+			"Number of users that are not deleted.",
+			nil, nil,
+		),
+	}
+}
+
+// Handler returns the event handler that feeds the lifecycle counters
+func (m *UserMetrics) Handler() consumer.Handler {
+	return consumer.Handler{
+		Name:   "user-metrics",
+		Topics: []string{events.UserCreated, events.UserDeleted},
+		Handle: m.handle,
+	}
+}
+
+func (m *UserMetrics) handle(ctx context.Context, msg messaging.Message) error {
+	switch msg.Topic {
+	case events.UserCreated:
+		m.created.Inc()
+	case events.UserDeleted:
+		m.deleted.Inc()
+	}
+	return nil
+}
+
+// Describe implements prometheus.Collector
+func (m *UserMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.created.Describe(ch)
+	m.deleted.Describe(ch)
+	ch <- m.active
+}
+
+// Collect implements prometheus.Collector. The active user count is cached
+// so frequent scrapes do not turn into frequent COUNT queries; when counting
+// fails the last known value is reported.
+func (m *UserMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.created.Collect(ch)
+	m.deleted.Collect(ch)
+	ch <- prometheus.MustNewConstMetric(m.active, prometheus.GaugeValue, m.activeUserCount())
+}
+
+func (m *UserMetrics) activeUserCount() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.countedAt.IsZero() && time.Since(m.countedAt) < activeUsersRefresh {
+		return m.activeUsers
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	defer cancel()
+
+	count, err := m.userRepo.Count(ctx)
+	if err != nil {
+		m.logger.WithField("error", err.Error()).Warn("Failed to count active users for metrics")
+		return m.activeUsers
+	}
+	m.activeUsers = float64(count)
+	m.countedAt = time.Now()
+	return m.activeUsers
+}
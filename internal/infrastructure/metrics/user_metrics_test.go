@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/metrics"
+)
+
+func TestUserMetrics(t *testing.T) {
+	repo := database.NewMockUserRepository()
+	require.NoError(t, repo.Create(context.Background(), &entities.User{Email: "a@example.com", Name: "A"}))
+	require.NoError(t, repo.Create(context.Background(), &entities.User{Email: "b@example.com", Name: "B"}))
+
+	userMetrics := NewUserMetrics(repo, logger.New())
+	registry := metrics.NewRegistry()
+	registry.MustRegister(userMetrics)
+
+	handler := userMetrics.Handler()
+	assert.ElementsMatch(t, []string{events.UserCreated, events.UserDeleted}, handler.Topics)
+	for _, topic := range []string{events.UserCreated, events.UserCreated, events.UserDeleted} {
+		require.NoError(t, handler.Handle(context.Background(), messaging.Message{Topic: topic}))
+	}
+
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := w.Body.String()
+	assert.Contains(t, body, "users_created_total 2")
+	assert.Contains(t, body, "users_deleted_total 1")
+	assert.Contains(t, body, "users_active 2")
+}
+
+func TestUserMetrics_CachesActiveUsers(t *testing.T) {
+	repo := database.NewMockUserRepository()
+	userMetrics := NewUserMetrics(repo, logger.New())
+
+	assert.Equal(t, float64(0), userMetrics.activeUserCount())
+
+	require.NoError(t, repo.Create(context.Background(), &entities.User{Email: "a@example.com", Name: "A"}))
+	assert.Equal(t, float64(0), userMetrics.activeUserCount(), "count is reused within the refresh interval")
+
+	userMetrics.countedAt = userMetrics.countedAt.Add(-activeUsersRefresh)
+	assert.Equal(t, float64(1), userMetrics.activeUserCount())
+}
@@ -0,0 +1,102 @@
+// Package notification delivers user-facing notifications
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/notification"
+)
+
+// EmailNotifier delivers notifications as templated emails. With the log
+// email driver they are logged instead, which stands in for delivery in
+// development. It is also the email channel of domain event
+// notifications.
+type EmailNotifier struct {
+	sender   email.Sender
+	renderer email.Renderer
+	locale   string
+}
+
+// NewEmailNotifier creates a notifier rendering its emails with renderer in
+// locale and sending them with sender
+func NewEmailNotifier(sender email.Sender, renderer email.Renderer, locale string) *EmailNotifier {
+	return &EmailNotifier{sender: sender, renderer: renderer, locale: locale}
+}
+
+// DeletionScheduled tells a user about their scheduled deletion
+func (n *EmailNotifier) DeletionScheduled(ctx context.Context, user *entities.User, deletion *entities.UserDeletion, cancelURL string) error {
+	return n.send(ctx, user.Email, email.DeletionScheduled{
+		Name:       user.Name,
+		PurgeAfter: deletion.PurgeAfter,
+		CancelURL:  cancelURL,
+	})
+}
+
+// EmailChangeRequested asks a user to confirm their new email at that
+// address
+func (n *EmailNotifier) EmailChangeRequested(ctx context.Context, user *entities.User, change *entities.EmailChange, confirmURL string) error {
+	return n.send(ctx, change.NewEmail, email.EmailChangeConfirmation{
+		Name:       user.Name,
+		NewEmail:   change.NewEmail,
+		ConfirmURL: confirmURL,
+		ExpiresAt:  change.ExpiresAt,
+	})
+}
+
+// SignupVerificationRequested asks a user who signed up to verify their
+// email
+func (n *EmailNotifier) SignupVerificationRequested(ctx context.Context, user *entities.User, verifyURL string, expiresAt time.Time) error {
+	return n.send(ctx, user.Email, email.SignupVerification{
+		Name:      user.Name,
+		VerifyURL: verifyURL,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// Name implements notification.Channel
+func (n *EmailNotifier) Name() string {
+	return "email"
+}
+
+// Notify implements notification.Channel, welcoming created users and
+// confirming deletions to deleted ones. Events that carry no user, and
+// service accounts, which have no mailbox, are skipped.
+func (n *EmailNotifier) Notify(ctx context.Context, note notification.Notification) error {
+	user := note.User
+	if user == nil || user.Email == "" || user.Kind == entities.UserKindService {
+		return nil
+	}
+	switch note.Type {
+	case events.UserCreated:
+		return n.send(ctx, user.Email, email.Welcome{Name: user.Name})
+	case events.UserDeleted:
+		return n.send(ctx, user.Email, email.AccountDeleted{Name: user.Name})
+	default:
+		return nil
+	}
+}
+
+// send renders data and sends it to the address to. Addresses on the
+// suppression list are skipped rather than failed, as retrying would not
+// reach them either.
+func (n *EmailNotifier) send(ctx context.Context, to string, data email.Template) error {
+	msg, err := n.renderer.Render(n.locale, data)
+	if err != nil {
+		return err
+	}
+	msg.To = []string{to}
+	err = n.sender.Send(ctx, msg)
+	if errors.Is(err, email.ErrRecipientSuppressed) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send %s email: %w", data.TemplateName(), err)
+	}
+	return nil
+}
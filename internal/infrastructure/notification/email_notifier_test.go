@@ -0,0 +1,100 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/notification"
+	"clean-architecture/internal/infrastructure/database"
+	emailinfra "clean-architecture/internal/infrastructure/email"
+	"clean-architecture/pkg/logger"
+)
+
+// recordingSender records the messages sent through it
+type recordingSender struct {
+	sent []email.Message
+}
+
+func (s *recordingSender) Send(ctx context.Context, msg email.Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestEmailNotifier(t *testing.T) {
+	ctx := context.Background()
+	renderer, err := emailinfra.NewTemplateRenderer("en")
+	require.NoError(t, err)
+	sender := &recordingSender{}
+	notifier := NewEmailNotifier(sender, renderer, "de")
+	user := &entities.User{ID: "user_1", Name: "Jana", Email: "jana@example.com"}
+
+	change := &entities.EmailChange{NewEmail: "jana@example.org", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, notifier.EmailChangeRequested(ctx, user, change, "https://app.example.com/confirm?token=abc"))
+	deletion := &entities.UserDeletion{PurgeAfter: time.Now().Add(30 * 24 * time.Hour)}
+	require.NoError(t, notifier.DeletionScheduled(ctx, user, deletion, "https://app.example.com/cancel?token=def"))
+	require.NoError(t, notifier.SignupVerificationRequested(ctx, user, "https://app.example.com/signup/verify?token=ghi", time.Now().Add(time.Hour)))
+
+	require.Len(t, sender.sent, 3)
+	assert.Equal(t, []string{"jana@example.org"}, sender.sent[0].To, "the confirmation goes to the new address")
+	assert.Equal(t, "Bestätigen Sie Ihre neue E-Mail-Adresse", sender.sent[0].Subject)
+	assert.Contains(t, sender.sent[0].Text, "https://app.example.com/confirm?token=abc")
+	assert.Equal(t, []string{"jana@example.com"}, sender.sent[1].To)
+	assert.Contains(t, sender.sent[1].HTML, "https://app.example.com/cancel?token=def")
+	assert.Equal(t, []string{"jana@example.com"}, sender.sent[2].To)
+	assert.Equal(t, "Bestätigen Sie Ihre E-Mail-Adresse", sender.sent[2].Subject)
+	assert.Contains(t, sender.sent[2].Text, "https://app.example.com/signup/verify?token=ghi")
+}
+
+func testNotification(eventType string) notification.Notification {
+	return notification.Notification{
+		EventID:    "evt_1",
+		Type:       eventType,
+		OccurredAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		UserID:     "user_1",
+		User:       &entities.User{ID: "user_1", Name: "Jana", Email: "jana@example.com", Kind: entities.UserKindHuman},
+	}
+}
+
+func TestEmailNotifier_Notify(t *testing.T) {
+	ctx := context.Background()
+	renderer, err := emailinfra.NewTemplateRenderer("en")
+	require.NoError(t, err)
+	sender := &recordingSender{}
+	notifier := NewEmailNotifier(sender, renderer, "en")
+	assert.Equal(t, "email", notifier.Name())
+
+	require.NoError(t, notifier.Notify(ctx, testNotification(events.UserCreated)))
+	require.NoError(t, notifier.Notify(ctx, testNotification(events.UserDeleted)))
+	require.Len(t, sender.sent, 2)
+	assert.Equal(t, []string{"jana@example.com"}, sender.sent[0].To)
+	assert.Equal(t, "Welcome to your new account", sender.sent[0].Subject)
+	assert.Equal(t, "Your account has been deleted", sender.sent[1].Subject)
+
+	service := testNotification(events.UserCreated)
+	service.User.Kind = entities.UserKindService
+	require.NoError(t, notifier.Notify(ctx, service))
+	unknown := testNotification(events.UserCreated)
+	unknown.User = nil
+	require.NoError(t, notifier.Notify(ctx, unknown))
+	assert.Len(t, sender.sent, 2, "service accounts and events without a user are skipped")
+}
+
+func TestEmailNotifier_SkipsSuppressedRecipients(t *testing.T) {
+	ctx := context.Background()
+	renderer, err := emailinfra.NewTemplateRenderer("en")
+	require.NoError(t, err)
+	suppressions := database.NewMockEmailSuppressionRepository()
+	require.NoError(t, suppressions.Save(ctx, &entities.EmailSuppression{Email: "jana@example.com", Reason: entities.SuppressionBounce, Provider: "stub"}))
+	sender := &recordingSender{}
+	notifier := NewEmailNotifier(emailinfra.NewSuppressingSender(sender, suppressions, logger.New()), renderer, "en")
+
+	require.NoError(t, notifier.Notify(ctx, testNotification(events.UserCreated)))
+	assert.Empty(t, sender.sent)
+}
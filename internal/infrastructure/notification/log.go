@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/notification"
+	"clean-architecture/pkg/logger"
+)
+
+// LogChannel logs notifications, which shows what the other channels
+// would be told without delivering anything
+type LogChannel struct {
+	logger logger.Logger
+}
+
+// NewLogChannel creates a channel logging to logger
+func NewLogChannel(logger logger.Logger) *LogChannel {
+	return &LogChannel{logger: logger}
+}
+
+// Name implements notification.Channel
+func (c *LogChannel) Name() string {
+	return "log"
+}
+
+// Notify implements notification.Channel. The user's details are left
+// out so the log holds no personal data.
+func (c *LogChannel) Notify(ctx context.Context, n notification.Notification) error {
+	c.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"event_id": n.EventID,
+		"type":     n.Type,
+		"user_id":  n.UserID,
+	}).Info("Notification")
+	return nil
+}
@@ -0,0 +1,66 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"clean-architecture/internal/domain/notification"
+)
+
+// maxErrorBody bounds how much of an error response is read into the
+// returned error
+const maxErrorBody = 1024
+
+// WebhookChannel posts each notification as JSON to a URL. The
+// Idempotency-Key header is the event ID, so receivers that honour it can
+// drop a notification posted again after a retry.
+type WebhookChannel struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+// NewWebhookChannel creates a channel posting to url, with token as a
+// bearer token unless it is empty
+func NewWebhookChannel(url, token string, httpClient *http.Client) *WebhookChannel {
+	return &WebhookChannel{url: url, token: token, httpClient: httpClient}
+}
+
+// Name implements notification.Channel
+func (c *WebhookChannel) Name() string {
+	return "webhook"
+}
+
+// Notify implements notification.Channel. Any status but 2xx fails the
+// notification.
+func (c *WebhookChannel) Notify(ctx context.Context, n notification.Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("webhook: encode notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", n.EventID)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("webhook: %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
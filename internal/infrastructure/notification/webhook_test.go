@@ -0,0 +1,50 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/notification"
+)
+
+func TestWebhookChannel_Notify(t *testing.T) {
+	var got notification.Notification
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	channel := NewWebhookChannel(server.URL, "secret", server.Client())
+	assert.Equal(t, "webhook", channel.Name())
+	require.NoError(t, channel.Notify(context.Background(), testNotification(events.UserCreated)))
+
+	assert.Equal(t, "Bearer secret", header.Get("Authorization"))
+	assert.Equal(t, "evt_1", header.Get("Idempotency-Key"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, events.UserCreated, got.Type)
+	assert.Equal(t, "user_1", got.UserID)
+	require.NotNil(t, got.User)
+	assert.Equal(t, "jana@example.com", got.User.Email)
+}
+
+func TestWebhookChannel_NotifyFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "try again later", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := NewWebhookChannel(server.URL, "", server.Client()).Notify(context.Background(), testNotification(events.UserDeleted))
+	assert.EqualError(t, err, "webhook: 503 Service Unavailable: try again later")
+}
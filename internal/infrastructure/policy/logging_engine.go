@@ -0,0 +1,41 @@
+package policy
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/pkg/logger"
+)
+
+// LoggingEngine decorates a policy.Engine with decision logging
+type LoggingEngine struct {
+	next   policy.Engine
+	logger logger.Logger
+}
+
+// NewLoggingEngine creates a new decision logging engine
+func NewLoggingEngine(next policy.Engine, logger logger.Logger) *LoggingEngine {
+	return &LoggingEngine{next: next, logger: logger}
+}
+
+// Evaluate delegates to the wrapped engine and logs the decision
+func (e *LoggingEngine) Evaluate(ctx context.Context, input policy.Input) (policy.Decision, error) {
+	decision, err := e.next.Evaluate(ctx, input)
+
+	fields := map[string]interface{}{
+		"subject":       input.Subject.ID,
+		"action":        input.Action,
+		"resource_type": input.Resource.Type,
+		"resource_id":   input.Resource.ID,
+		"allowed":       decision.Allowed,
+		"reason":        decision.Reason,
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		e.logger.WithFields(fields).Error("Policy evaluation failed")
+		return decision, err
+	}
+
+	e.logger.WithFields(fields).Info("Policy decision")
+	return decision, nil
+}
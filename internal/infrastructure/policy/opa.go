@@ -0,0 +1,159 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"clean-architecture/internal/domain/policy"
+)
+
+// OPAEngine implements policy.Engine by querying a remote OPA server (or any
+// PDP exposing the OPA Data API)
+type OPAEngine struct {
+	baseURL      string
+	decisionPath string
+	client       *http.Client
+}
+
+// NewOPAEngine creates a new OPA-backed policy engine. Requests are sent
+// with client, or http.DefaultClient when nil, bounded by timeout.
+func NewOPAEngine(baseURL, decisionPath string, timeout time.Duration, client *http.Client) *OPAEngine {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	bounded := *client
+	bounded.Timeout = timeout
+	return &OPAEngine{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		decisionPath: strings.Trim(decisionPath, "/"),
+		client:       &bounded,
+	}
+}
+
+// opaResult is the result document returned by OPA. Policies may either
+// return a plain boolean or an object with allow and reason fields.
+type opaResult struct {
+	Result json.RawMessage `json:"result"`
+}
+
+// Evaluate asks OPA for a decision on the given input
+func (e *OPAEngine) Evaluate(ctx context.Context, input policy.Input) (policy.Decision, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return policy.Decision{}, fmt.Errorf("failed to encode policy input: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/data/%s", e.baseURL, e.decisionPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return policy.Decision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return policy.Decision{}, fmt.Errorf("failed to query policy engine: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return policy.Decision{}, fmt.Errorf("policy engine returned status %d", resp.StatusCode)
+	}
+
+	var result opaResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return policy.Decision{}, fmt.Errorf("failed to decode policy decision: %w", err)
+	}
+
+	return parseResult(result.Result)
+}
+
+// parseResult converts an OPA result document into a decision. An undefined
+// result (no matching rule) is treated as a deny.
+func parseResult(raw json.RawMessage) (policy.Decision, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return policy.Decision{Allowed: false, Reason: "policy result undefined"}, nil
+	}
+
+	var allowed bool
+	if err := json.Unmarshal(raw, &allowed); err == nil {
+		return policy.Decision{Allowed: allowed}, nil
+	}
+
+	var obj struct {
+		Allow  bool   `json:"allow"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return policy.Decision{}, fmt.Errorf("unexpected policy result: %s", string(raw))
+	}
+	return policy.Decision{Allowed: obj.Allow, Reason: obj.Reason}, nil
+}
+
+// LoadPolicies uploads Rego policy files to OPA. Sources may be local file
+// paths or http(s) URLs, e.g. pre-signed object storage links.
+func (e *OPAEngine) LoadPolicies(ctx context.Context, sources []string) error {
+	for _, source := range sources {
+		content, err := readPolicySource(ctx, e.client, source)
+		if err != nil {
+			return fmt.Errorf("failed to read policy %s: %w", source, err)
+		}
+
+		url := fmt.Sprintf("%s/v1/policies/%s", e.baseURL, policyID(source))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(content))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "text/plain")
+
+		resp, err := e.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to upload policy %s: %w", source, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("policy engine rejected policy %s with status %d", source, resp.StatusCode)
+		}
+	}
+	return nil
+}
+
+// readPolicySource reads a policy from disk or over HTTP
+func readPolicySource(ctx context.Context, client *http.Client, source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return os.ReadFile(source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// policyID derives a stable OPA policy ID from a source location
+func policyID(source string) string {
+	if i := strings.IndexAny(source, "?#"); i >= 0 {
+		source = source[:i]
+	}
+	name := strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	return strings.ReplaceAll(name, ".", "_")
+}
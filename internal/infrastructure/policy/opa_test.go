@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/policy"
+)
+
+func TestOPAEngine_Evaluate(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode int
+		responseBody string
+		wantAllowed  bool
+		wantReason   string
+		wantErr      bool
+	}{
+		{
+			name:         "boolean allow",
+			responseCode: http.StatusOK,
+			responseBody: `{"result": true}`,
+			wantAllowed:  true,
+		},
+		{
+			name:         "object deny with reason",
+			responseCode: http.StatusOK,
+			responseBody: `{"result": {"allow": false, "reason": "not owner"}}`,
+			wantAllowed:  false,
+			wantReason:   "not owner",
+		},
+		{
+			name:         "undefined result",
+			responseCode: http.StatusOK,
+			responseBody: `{}`,
+			wantAllowed:  false,
+			wantReason:   "policy result undefined",
+		},
+		{
+			name:         "server error",
+			responseCode: http.StatusInternalServerError,
+			responseBody: `{}`,
+			wantErr:      true,
+		},
+		{
+			name:         "unexpected result",
+			responseCode: http.StatusOK,
+			responseBody: `{"result": "yes"}`,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received map[string]policy.Input
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v1/data/authz/allow", r.URL.Path)
+				_ = json.NewDecoder(r.Body).Decode(&received)
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			engine := NewOPAEngine(server.URL, "/authz/allow", time.Second, nil)
+			input := policy.Input{
+				Subject:  policy.Subject{ID: "user_1"},
+				Action:   "users:read",
+				Resource: policy.Resource{Type: "user", ID: "user_2"},
+			}
+
+			decision, err := engine.Evaluate(context.Background(), input)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAllowed, decision.Allowed)
+			assert.Equal(t, tt.wantReason, decision.Reason)
+			assert.Equal(t, input, received["input"])
+		})
+	}
+}
+
+func TestOPAEngine_LoadPolicies(t *testing.T) {
+	uploaded := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/bundles/remote.rego":
+			_, _ = w.Write([]byte("package remote"))
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			uploaded[r.URL.Path] = string(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "authz.rego")
+	require.NoError(t, os.WriteFile(localPath, []byte("package authz"), 0o600))
+
+	engine := NewOPAEngine(server.URL, "authz/allow", time.Second, nil)
+	err := engine.LoadPolicies(context.Background(), []string{
+		localPath,
+		server.URL + "/bundles/remote.rego?signature=abc",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "package authz", uploaded["/v1/policies/authz"])
+	assert.Equal(t, "package remote", uploaded["/v1/policies/remote"])
+}
+
+func TestOPAEngine_LoadPolicies_MissingFile(t *testing.T) {
+	engine := NewOPAEngine("http://127.0.0.1:0", "authz/allow", time.Second, nil)
+
+	err := engine.LoadPolicies(context.Background(), []string{"/does/not/exist.rego"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/pkg/logger"
+)
+
+// NewEngine creates the policy engine selected by configuration. Remote
+// engines are reached through client.
+func NewEngine(ctx context.Context, cfg configs.PolicyConfig, client *http.Client, logger logger.Logger) (policy.Engine, error) {
+	var engine policy.Engine
+
+	switch cfg.Driver {
+	case "opa":
+		if cfg.OPAURL == "" {
+			return nil, fmt.Errorf("POLICY_OPA_URL is required when POLICY_DRIVER=opa")
+		}
+		opa := NewOPAEngine(cfg.OPAURL, cfg.DecisionPath, cfg.Timeout, client)
+		if len(cfg.Files) > 0 {
+			if err := opa.LoadPolicies(ctx, cfg.Files); err != nil {
+				return nil, err
+			}
+			logger.WithField("count", len(cfg.Files)).Info("Policies loaded into OPA")
+		}
+		engine = opa
+	case "builtin", "":
+		engine = NewRoleEngine(DefaultRules())
+	default:
+		return nil, fmt.Errorf("unknown policy driver %q", cfg.Driver)
+	}
+
+	if cfg.DecisionLog {
+		engine = NewLoggingEngine(engine, logger)
+	}
+	return engine, nil
+}
+
+// DefaultRules returns the role rules used by the builtin engine
+func DefaultRules() map[string][]string {
+	return map[string][]string{
+		policy.RoleUser:    {"users:read", "users:list", "views:list", "views:read", "views:create", "views:delete", "jobs:read"},
+		policy.RoleService: {"users:read", "users:list", "users:create", "users:update", "users:delete", "users:upsert", "jobs:read"},
+	}
+}
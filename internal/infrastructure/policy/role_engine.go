@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"context"
+	"strings"
+
+	"clean-architecture/internal/domain/policy"
+)
+
+// RoleAdmin is the role that is granted every action by the role engine
+const RoleAdmin = policy.RoleAdmin
+
+// RoleEngine implements policy.Engine with in-process role based rules. It is
+// used when no external policy decision point is configured.
+type RoleEngine struct {
+	rules map[string][]string
+}
+
+// NewRoleEngine creates a new role engine. Rules map a role to the actions
+// it may perform; an action ending in "*" matches by prefix.
+func NewRoleEngine(rules map[string][]string) *RoleEngine {
+	return &RoleEngine{rules: rules}
+}
+
+// Evaluate decides based on the subject's roles and resource ownership
+func (e *RoleEngine) Evaluate(ctx context.Context, input policy.Input) (policy.Decision, error) {
+	if input.Subject.HasRole(RoleAdmin) {
+		return policy.Decision{Allowed: true, Reason: "admin"}, nil
+	}
+
+	if input.Subject.ID != "" && input.Resource.OwnerID == input.Subject.ID {
+		return policy.Decision{Allowed: true, Reason: "owner"}, nil
+	}
+
+	for _, role := range input.Subject.Roles {
+		for _, action := range e.rules[role] {
+			if matchAction(action, input.Action) {
+				return policy.Decision{Allowed: true, Reason: "role " + role}, nil
+			}
+		}
+	}
+
+	return policy.Decision{Allowed: false, Reason: "no matching rule"}, nil
+}
+
+// matchAction reports whether a rule pattern matches the requested action
+func matchAction(pattern, action string) bool {
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(action, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == action
+}
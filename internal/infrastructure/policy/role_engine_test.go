@@ -0,0 +1,84 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"clean-architecture/internal/domain/policy"
+)
+
+func TestRoleEngine_Evaluate(t *testing.T) {
+	engine := NewRoleEngine(map[string][]string{
+		"user":    {"users:read"},
+		"support": {"users:*"},
+	})
+
+	tests := []struct {
+		name    string
+		input   policy.Input
+		allowed bool
+	}{
+		{
+			name: "admin is allowed everything",
+			input: policy.Input{
+				Subject: policy.Subject{ID: "admin_1", Roles: []string{"admin"}},
+				Action:  "users:delete",
+			},
+			allowed: true,
+		},
+		{
+			name: "owner is allowed on own resource",
+			input: policy.Input{
+				Subject:  policy.Subject{ID: "user_1"},
+				Action:   "users:update",
+				Resource: policy.Resource{Type: "user", ID: "user_1", OwnerID: "user_1"},
+			},
+			allowed: true,
+		},
+		{
+			name: "role rule matches exact action",
+			input: policy.Input{
+				Subject:  policy.Subject{ID: "user_1", Roles: []string{"user"}},
+				Action:   "users:read",
+				Resource: policy.Resource{Type: "user", ID: "user_2", OwnerID: "user_2"},
+			},
+			allowed: true,
+		},
+		{
+			name: "role rule matches prefix",
+			input: policy.Input{
+				Subject: policy.Subject{ID: "support_1", Roles: []string{"support"}},
+				Action:  "users:delete",
+			},
+			allowed: true,
+		},
+		{
+			name: "no matching rule",
+			input: policy.Input{
+				Subject:  policy.Subject{ID: "user_1", Roles: []string{"user"}},
+				Action:   "users:delete",
+				Resource: policy.Resource{Type: "user", ID: "user_2", OwnerID: "user_2"},
+			},
+			allowed: false,
+		},
+		{
+			name: "anonymous subject does not own unowned resource",
+			input: policy.Input{
+				Action:   "users:list",
+				Resource: policy.Resource{Type: "user"},
+			},
+			allowed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := engine.Evaluate(context.Background(), tt.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.allowed, decision.Allowed)
+		})
+	}
+}
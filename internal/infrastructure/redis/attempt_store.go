@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Key prefixes of login attempt counters and locks in the shared Redis
+// database
+const (
+	failuresKeyPrefix = "login_failures:"
+	lockKeyPrefix     = "login_lock:"
+)
+
+// failScript counts a failure and starts the window with the first one, so
+// a steady trickle of failures does not keep extending it
+var failScript = goredis.NewScript(`
+local failures = redis.call("INCR", KEYS[1])
+if failures == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return failures
+`)
+
+// AttemptStore counts failed logins in Redis, so every instance enforces
+// the same lockouts. Counters and locks expire with their window.
+type AttemptStore struct {
+	client *goredis.Client
+}
+
+// NewAttemptStore creates an attempt store on client
+func NewAttemptStore(client *goredis.Client) *AttemptStore {
+	return &AttemptStore{client: client}
+}
+
+// Fail records a failed login of key
+func (s *AttemptStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	failures, err := failScript.Run(ctx, s.client, []string{failuresKeyPrefix + key}, window.Milliseconds()).Int()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count login failure: %w", err)
+	}
+	return failures, nil
+}
+
+// Lock refuses logins of key until the given time
+func (s *AttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, lockKeyPrefix+key, until.UnixMilli(), ttl)
+		pipe.Del(ctx, failuresKeyPrefix+key)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+	return nil
+}
+
+// LockedUntil returns when the lock of key ends
+func (s *AttemptStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	until, err := s.client.Get(ctx, lockKeyPrefix+key).Int64()
+	if errors.Is(err, goredis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get login lock: %w", err)
+	}
+	return time.UnixMilli(until), nil
+}
+
+// Reset forgets the failures and lock of key
+func (s *AttemptStore) Reset(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, failuresKeyPrefix+key, lockKeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+	return nil
+}
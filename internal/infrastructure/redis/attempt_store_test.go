@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+)
+
+func TestAttemptStore(t *testing.T) {
+	// Skip if no Redis server
+	if testing.Short() {
+		t.Skip("Skipping Redis tests in short mode")
+	}
+	cfg, err := configs.Load()
+	require.NoError(t, err)
+	if !cfg.Redis.Enabled() {
+		t.Skip("REDIS_ADDR or REDIS_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := NewClient(ctx, cfg.Redis)
+	require.NoError(t, err)
+	defer client.Close()
+
+	store := NewAttemptStore(client)
+	key := "user:test-attempts"
+	defer store.Reset(ctx, key)
+
+	for want := 1; want <= 3; want++ {
+		failures, err := store.Fail(ctx, key, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, want, failures)
+	}
+	ttl, err := client.PTTL(ctx, failuresKeyPrefix+key).Result()
+	require.NoError(t, err)
+	assert.Greater(t, ttl, 50*time.Second, "the window starts with the first failure")
+
+	until := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+	require.NoError(t, store.Lock(ctx, key, until))
+	locked, err := store.LockedUntil(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, until.Equal(locked))
+	failures, err := store.Fail(ctx, key, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures, "locking forgets the failures")
+
+	require.NoError(t, store.Reset(ctx, key))
+	locked, err = store.LockedUntil(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, locked.IsZero())
+}
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/jobs"
+)
+
+// TaskSendEmail is the type of the tasks sending queued email. Tasks of
+// the job use cases have the type of their job.
+const TaskSendEmail = "email.send"
+
+// JobQueue queues jobs on the Redis job queue. The task carries only the
+// job ID; workers load the job from its repository.
+type JobQueue struct {
+	client *jobs.Client
+}
+
+// NewJobQueue creates a job queue enqueueing with client
+func NewJobQueue(client *jobs.Client) *JobQueue {
+	return &JobQueue{client: client}
+}
+
+// Enqueue implements usecase.JobQueue. A job still queued is not queued
+// again.
+func (q *JobQueue) Enqueue(ctx context.Context, job *entities.Job) error {
+	_, err := q.client.Enqueue(ctx, job.Type, []byte(job.ID), jobs.ID(job.ID), jobs.Headers(taskHeaders(ctx)))
+	if err != nil && !errors.Is(err, jobs.ErrDuplicate) {
+		return fmt.Errorf("failed to queue job: %w", err)
+	}
+	return nil
+}
+
+// JobTask returns the task handler running the jobs it is registered for
+// with run
+func JobTask(run func(ctx context.Context, id string) error) jobs.Handler {
+	return func(ctx context.Context, task *jobs.Task) error {
+		return run(taskContext(ctx, task), string(task.Payload))
+	}
+}
+
+// QueuedSender queues email to be sent by the job workers, so requests do
+// not wait for the transport and messages it refuses for a while are
+// retried. Send returns once the message is queued.
+type QueuedSender struct {
+	client *jobs.Client
+}
+
+// NewQueuedSender creates a sender queueing email with client
+func NewQueuedSender(client *jobs.Client) *QueuedSender {
+	return &QueuedSender{client: client}
+}
+
+// Send implements email.Sender
+func (s *QueuedSender) Send(ctx context.Context, msg email.Message) error {
+	if len(msg.To) == 0 {
+		return email.ErrNoRecipients
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode email: %w", err)
+	}
+	if _, err := s.client.Enqueue(ctx, TaskSendEmail, payload, jobs.Headers(taskHeaders(ctx))); err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+	return nil
+}
+
+// SendEmailTask returns the handler of email.send tasks, which sends the
+// queued message with sender
+func SendEmailTask(sender email.Sender) jobs.Handler {
+	return func(ctx context.Context, task *jobs.Task) error {
+		var msg email.Message
+		if err := json.Unmarshal(task.Payload, &msg); err != nil {
+			return jobs.Permanent(fmt.Errorf("decode email: %w", err))
+		}
+		err := sender.Send(taskContext(ctx, task), msg)
+		if errors.Is(err, email.ErrNoRecipients) || errors.Is(err, email.ErrRecipientSuppressed) {
+			return jobs.Permanent(err)
+		}
+		return err
+	}
+}
+
+// taskHeaders carries the correlation ID of ctx to the task
+func taskHeaders(ctx context.Context) map[string]string {
+	if id := correlation.ID(ctx); id != "" {
+		return map[string]string{correlation.MessageHeader: id}
+	}
+	return nil
+}
+
+// taskContext restores the correlation ID of the call that queued task
+func taskContext(ctx context.Context, task *jobs.Task) context.Context {
+	if id := task.Headers[correlation.MessageHeader]; id != "" {
+		return correlation.WithID(ctx, id)
+	}
+	return ctx
+}
@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/jobs"
+)
+
+// recordingSender records the messages sent through it, failing with err
+type recordingSender struct {
+	sent          []email.Message
+	correlationID string
+	err           error
+}
+
+func (s *recordingSender) Send(ctx context.Context, msg email.Message) error {
+	s.sent = append(s.sent, msg)
+	s.correlationID = correlation.ID(ctx)
+	return s.err
+}
+
+func TestJobTask(t *testing.T) {
+	var ranID, correlationID string
+	handler := JobTask(func(ctx context.Context, id string) error {
+		ranID, correlationID = id, correlation.ID(ctx)
+		return nil
+	})
+
+	task := &jobs.Task{Type: "users.export", Payload: []byte("job-1"), Headers: taskHeaders(correlation.WithID(context.Background(), "req-1"))}
+	require.NoError(t, handler(context.Background(), task))
+	assert.Equal(t, "job-1", ranID)
+	assert.Equal(t, "req-1", correlationID, "jobs run with the correlation ID of the request that queued them")
+}
+
+func TestSendEmailTask(t *testing.T) {
+	msg := email.Message{To: []string{"ada@example.com"}, Subject: "Welcome", Text: "Your account is ready."}
+	payload, err := json.Marshal(msg)
+	require.NoError(t, err)
+	sender := &recordingSender{}
+	handler := SendEmailTask(sender)
+
+	require.NoError(t, handler(context.Background(), &jobs.Task{Payload: payload, Headers: map[string]string{correlation.MessageHeader: "req-1"}}))
+	assert.Equal(t, []email.Message{msg}, sender.sent)
+	assert.Equal(t, "req-1", sender.correlationID)
+
+	err = handler(context.Background(), &jobs.Task{Payload: []byte("not json")})
+	assert.True(t, jobs.IsPermanent(err), "malformed tasks are not retried")
+
+	sender.err = errors.New("connection refused")
+	err = handler(context.Background(), &jobs.Task{Payload: payload})
+	require.Error(t, err)
+	assert.False(t, jobs.IsPermanent(err), "the transport being down is retried")
+
+	sender.err = email.ErrRecipientSuppressed
+	assert.True(t, jobs.IsPermanent(handler(context.Background(), &jobs.Task{Payload: payload})))
+}
+
+func TestQueuedSender_NoRecipients(t *testing.T) {
+	err := NewQueuedSender(nil).Send(context.Background(), email.Message{Subject: "Welcome"})
+	assert.ErrorIs(t, err, email.ErrNoRecipients, "messages to nobody fail before they are queued")
+}
+
+func TestJobQueue(t *testing.T) {
+	// Skip if no Redis server
+	if testing.Short() {
+		t.Skip("Skipping Redis tests in short mode")
+	}
+	cfg, err := configs.Load()
+	require.NoError(t, err)
+	if !cfg.Redis.Enabled() {
+		t.Skip("REDIS_ADDR or REDIS_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := NewClient(ctx, cfg.Redis)
+	require.NoError(t, err)
+	defer client.Close()
+
+	prefix := "jobs-test-queue"
+	defer client.Del(ctx, prefix+":{default}:pending", prefix+":{default}:t:job-1")
+	jobClient := jobs.NewClient(client, jobs.ClientConfig{Prefix: prefix})
+	queue := NewJobQueue(jobClient)
+	job := &entities.Job{ID: "job-1", Type: "users.export"}
+
+	require.NoError(t, queue.Enqueue(ctx, job))
+	require.NoError(t, queue.Enqueue(ctx, job), "queueing a queued job again is a no-op")
+	stats, err := jobClient.Stats(ctx, jobs.DefaultQueue)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Pending)
+}
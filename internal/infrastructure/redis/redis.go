@@ -0,0 +1,37 @@
+// Package redis connects to the Redis server shared by modules that need
+// state across instances
+package redis
+
+import (
+	"context"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"clean-architecture/configs"
+	"clean-architecture/pkg/redis"
+)
+
+// NewClient connects to the configured Redis server. It returns a nil client
+// when Redis is not configured, so callers fall back to in-process state.
+func NewClient(ctx context.Context, cfg configs.RedisConfig) (*goredis.Client, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	return redis.New(ctx, redis.Config{
+		URL:             cfg.URL,
+		Addr:            cfg.Addr,
+		Username:        cfg.Username,
+		Password:        cfg.Password,
+		DB:              cfg.DB,
+		TLS:             cfg.TLS,
+		PoolSize:        cfg.PoolSize,
+		MinIdleConns:    cfg.MinIdleConns,
+		DialTimeout:     cfg.DialTimeout,
+		ReadTimeout:     cfg.ReadTimeout,
+		WriteTimeout:    cfg.WriteTimeout,
+		PoolTimeout:     cfg.PoolTimeout,
+		ConnMaxIdleTime: cfg.ConnMaxIdleTime,
+		ConnMaxLifetime: cfg.ConnMaxLifetime,
+	})
+}
@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// revokedKeyPrefix namespaces revoked token IDs in the shared Redis
+// database
+const revokedKeyPrefix = "revoked_token:"
+
+// RevocationStore keeps revoked token IDs in Redis, so a token revoked on
+// one instance is refused by every instance. Each ID expires with its
+// token.
+type RevocationStore struct {
+	client *goredis.Client
+}
+
+// NewRevocationStore creates a revocation store on client
+func NewRevocationStore(client *goredis.Client) *RevocationStore {
+	return &RevocationStore{client: client}
+}
+
+// Revoke refuses the token with id until it expires
+func (s *RevocationStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := s.client.Set(ctx, revokedKeyPrefix+id, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// Revoked reports whether the token with id was revoked
+func (s *RevocationStore) Revoked(ctx context.Context, id string) (bool, error) {
+	n, err := s.client.Exists(ctx, revokedKeyPrefix+id).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+)
+
+func TestRevocationStore(t *testing.T) {
+	// Skip if no Redis server
+	if testing.Short() {
+		t.Skip("Skipping Redis tests in short mode")
+	}
+	cfg, err := configs.Load()
+	require.NoError(t, err)
+	if !cfg.Redis.Enabled() {
+		t.Skip("REDIS_ADDR or REDIS_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := NewClient(ctx, cfg.Redis)
+	require.NoError(t, err)
+	defer client.Close()
+
+	store := NewRevocationStore(client)
+	id := "test-revocation"
+	defer client.Del(ctx, revokedKeyPrefix+id)
+
+	revoked, err := store.Revoked(ctx, id)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, store.Revoke(ctx, id, time.Now().Add(time.Minute)))
+	revoked, err = store.Revoked(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	ttl, err := client.PTTL(ctx, revokedKeyPrefix+id).Result()
+	require.NoError(t, err)
+	assert.Greater(t, ttl, 50*time.Second, "the ID expires with the token")
+
+	require.NoError(t, store.Revoke(ctx, "test-expired", time.Now().Add(-time.Minute)))
+	revoked, err = store.Revoked(ctx, "test-expired")
+	require.NoError(t, err)
+	assert.False(t, revoked, "expired tokens need not be kept")
+}
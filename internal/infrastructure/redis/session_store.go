@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"clean-architecture/internal/domain/auth"
+)
+
+// sessionKeyPrefix namespaces session keys in the shared Redis database
+const sessionKeyPrefix = "session:"
+
+// SessionStore keeps server-side sessions in Redis, so every instance sees
+// them. The idle timeout is the key's TTL.
+type SessionStore struct {
+	client *goredis.Client
+}
+
+// NewSessionStore creates a session store on client
+func NewSessionStore(client *goredis.Client) *SessionStore {
+	return &SessionStore{client: client}
+}
+
+// Create stores a new session that expires after idle without use
+func (s *SessionStore) Create(ctx context.Context, session *auth.ServerSession, idle time.Duration) error {
+	value, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	created, err := s.client.SetNX(ctx, sessionKeyPrefix+session.ID, value, idle).Result()
+	if err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+	if !created {
+		return errors.New("session ID already exists")
+	}
+	return nil
+}
+
+// Touch returns the session with id and resets its TTL to idle
+func (s *SessionStore) Touch(ctx context.Context, id string, idle time.Duration) (*auth.ServerSession, error) {
+	value, err := s.client.GetEx(ctx, sessionKeyPrefix+id, idle).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, auth.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	var session auth.ServerSession
+	if err := json.Unmarshal(value, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	return &session, nil
+}
+
+// Delete removes the session with id
+func (s *SessionStore) Delete(ctx context.Context, id string) error {
+	if err := s.client.Del(ctx, sessionKeyPrefix+id).Err(); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/auth"
+)
+
+func TestSessionStore(t *testing.T) {
+	// Skip if no Redis server
+	if testing.Short() {
+		t.Skip("Skipping Redis tests in short mode")
+	}
+	cfg, err := configs.Load()
+	require.NoError(t, err)
+	if !cfg.Redis.Enabled() {
+		t.Skip("REDIS_ADDR or REDIS_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := NewClient(ctx, cfg.Redis)
+	require.NoError(t, err)
+	defer client.Close()
+
+	store := NewSessionStore(client)
+	session := &auth.ServerSession{
+		ID:        "test-session",
+		UserID:    "user_1",
+		Roles:     []string{"user"},
+		Scopes:    []string{"users:write"},
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		ExpiresAt: time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+	}
+	defer store.Delete(ctx, session.ID)
+
+	require.NoError(t, store.Create(ctx, session, time.Minute))
+	assert.Error(t, store.Create(ctx, session, time.Minute), "IDs are never reused")
+
+	got, err := store.Touch(ctx, session.ID, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, session, got)
+	ttl, err := client.TTL(ctx, sessionKeyPrefix+session.ID).Result()
+	require.NoError(t, err)
+	assert.Greater(t, ttl, time.Minute)
+
+	require.NoError(t, store.Delete(ctx, session.ID))
+	_, err = store.Touch(ctx, session.ID, time.Hour)
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+	assert.NoError(t, store.Delete(ctx, session.ID))
+}
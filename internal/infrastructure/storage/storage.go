@@ -0,0 +1,46 @@
+// Package storage selects the object storage driver from configuration
+package storage
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"clean-architecture/configs"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/storage"
+)
+
+// NewStorage creates the object storage selected by configuration and
+// returns it with the name of its driver. Without a driver the local
+// driver is used when its directory is writable and the memory driver
+// otherwise, so the application boots without persistent storage.
+func NewStorage(cfg configs.StorageConfig, logger logger.Logger) (storage.Storage, string, error) {
+	signingKey := []byte(cfg.SigningKey)
+	if len(signingKey) == 0 {
+		signingKey = make([]byte, 32)
+		if _, err := rand.Read(signingKey); err != nil {
+			return nil, "", fmt.Errorf("generate storage signing key: %w", err)
+		}
+		logger.Warn("STORAGE_SIGNING_KEY is not set; download URLs only work on this instance until it restarts")
+	}
+
+	switch cfg.Driver {
+	case "":
+		local, err := storage.NewLocal(cfg.Dir, cfg.PublicURL, signingKey)
+		if err == nil {
+			return local, "local", nil
+		}
+		logger.WithField("error", err.Error()).
+			Warn("Storage directory is unusable; keeping objects in memory until restart. Set STORAGE_DRIVER=local to fail instead")
+		memory, err := storage.NewMemory(cfg.PublicURL, signingKey, int64(cfg.MemoryMaxSize))
+		return memory, "memory", err
+	case "local":
+		local, err := storage.NewLocal(cfg.Dir, cfg.PublicURL, signingKey)
+		return local, cfg.Driver, err
+	case "memory":
+		memory, err := storage.NewMemory(cfg.PublicURL, signingKey, int64(cfg.MemoryMaxSize))
+		return memory, cfg.Driver, err
+	default:
+		return nil, "", fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/storage"
+)
+
+func storageConfig(dir, driver string) configs.StorageConfig {
+	return configs.StorageConfig{
+		Driver:        driver,
+		Dir:           dir,
+		MemoryMaxSize: configs.Megabyte,
+		PublicURL:     "http://localhost:8080/api/v1/downloads",
+		SigningKey:    "secret",
+	}
+}
+
+func TestNewStorage(t *testing.T) {
+	// A file where the directory should be makes the local driver unusable
+	unusable := filepath.Join(t.TempDir(), "storage")
+	require.NoError(t, os.WriteFile(unusable, nil, 0o600))
+
+	tests := []struct {
+		name           string
+		cfg            configs.StorageConfig
+		expectedDriver string
+		expectedType   storage.Storage
+		expectedErr    bool
+	}{
+		{name: "auto with a usable directory", cfg: storageConfig(t.TempDir(), ""), expectedDriver: "local", expectedType: &storage.Local{}},
+		{name: "auto with an unusable directory", cfg: storageConfig(unusable, ""), expectedDriver: "memory", expectedType: &storage.Memory{}},
+		{name: "local", cfg: storageConfig(t.TempDir(), "local"), expectedDriver: "local", expectedType: &storage.Local{}},
+		{name: "local with an unusable directory", cfg: storageConfig(unusable, "local"), expectedErr: true},
+		{name: "memory", cfg: storageConfig(unusable, "memory"), expectedDriver: "memory", expectedType: &storage.Memory{}},
+		{name: "unknown", cfg: storageConfig(t.TempDir(), "s3"), expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, driver, err := NewStorage(tt.cfg, logger.New())
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDriver, driver)
+			assert.IsType(t, tt.expectedType, store)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Package webhook posts domain events to the webhooks registered by admins
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/pkg/webhooksig"
+)
+
+// maxErrorBody bounds how much of an error response is recorded on the
+// failed attempt
+const maxErrorBody = 1024
+
+// Sender posts the payload of a delivery as JSON to the URL of its webhook.
+// The X-Signature header signs the payload with the webhook's secret, see
+// pkg/webhooksig, and the Idempotency-Key header is the event ID, so
+// receivers that honour it can drop a delivery posted again after a retry.
+type Sender struct {
+	httpClient *http.Client
+}
+
+// NewSender creates a sender posting with httpClient, which should refuse
+// private addresses since webhook URLs are chosen by admins
+func NewSender(httpClient *http.Client) *Sender {
+	return &Sender{httpClient: httpClient}
+}
+
+// Send implements usecase.WebhookSender
+func (s *Sender) Send(ctx context.Context, webhook *entities.Webhook, delivery *entities.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", delivery.EventID)
+	req.Header.Set("X-Webhook-Id", webhook.ID)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	// Signed per attempt, so retries carry a fresh timestamp
+	req.Header.Set(webhooksig.Header, webhooksig.Sign(webhook.Secret, time.Now(), delivery.Payload))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("webhook: %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
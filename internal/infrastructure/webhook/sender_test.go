@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/pkg/webhooksig"
+)
+
+func testDelivery(webhookID string) *entities.WebhookDelivery {
+	delivery := entities.NewWebhookDelivery(webhookID, "evt_1", events.UserCreated, json.RawMessage(`{"id":"evt_1","type":"user.created"}`))
+	delivery.ID = "whd_1"
+	return delivery
+}
+
+func TestSender_Send(t *testing.T) {
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	webhook := &entities.Webhook{ID: "whk_1", URL: server.URL, Secret: "whsec_test"}
+	status, err := NewSender(server.Client()).Send(context.Background(), webhook, testDelivery(webhook.ID))
+	require.NoError(t, err)
+	assert.NoError(t, webhooksig.Verify("whsec_test", header.Get(webhooksig.Header), body, time.Now(), 0))
+	assert.ErrorIs(t, webhooksig.Verify("whsec_other", header.Get(webhooksig.Header), body, time.Now(), 0), webhooksig.ErrInvalidSignature)
+
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.JSONEq(t, `{"id":"evt_1","type":"user.created"}`, string(body))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+	assert.Equal(t, "evt_1", header.Get("Idempotency-Key"))
+	assert.Equal(t, "whk_1", header.Get("X-Webhook-Id"))
+	assert.Equal(t, "whd_1", header.Get("X-Webhook-Delivery"))
+	assert.Equal(t, events.UserCreated, header.Get("X-Webhook-Event"))
+}
+
+func TestSender_SendFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "try again later", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	webhook := &entities.Webhook{ID: "whk_1", URL: server.URL}
+	status, err := NewSender(server.Client()).Send(context.Background(), webhook, testDelivery(webhook.ID))
+
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.EqualError(t, err, "webhook: 503 Service Unavailable: try again later")
+}
+
+func TestSender_SendUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	webhook := &entities.Webhook{ID: "whk_1", URL: server.URL}
+	status, err := NewSender(server.Client()).Send(context.Background(), webhook, testDelivery(webhook.ID))
+
+	assert.Zero(t, status)
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+// Package adminui serves the embedded single-page admin UI. The page is
+// static: it browses users and the audit log and switches feature flags
+// through the JSON API, with the caller's own credentials.
+package adminui
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Path is where the UI is served
+const Path = "/admin"
+
+// contentSecurityPolicy only lets the page load its own assets and call
+// the API it is served with
+const contentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; img-src 'self' data:; connect-src 'self'; base-uri 'self'; form-action 'self'; frame-ancestors 'none'"
+
+//go:embed static
+var static embed.FS
+
+// asset is an embedded file with the ETag clients revalidate it with
+type asset struct {
+	content []byte
+	etag    string
+}
+
+// Handler serves the files of the UI below Path. Other paths without a
+// file extension are routes of the page, which are answered with
+// index.html so reloading and deep links work; the page routes them
+// itself.
+type Handler struct {
+	assets map[string]asset
+}
+
+// New creates the handler of the embedded UI
+func New() (*Handler, error) {
+	h := &Handler{assets: make(map[string]asset)}
+	err := fs.WalkDir(static, "static", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := static.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		h.assets[strings.TrimPrefix(name, "static/")] = asset{
+			content: content,
+			etag:    `"` + hex.EncodeToString(sum[:8]) + `"`,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	name, ok := strings.CutPrefix(r.URL.Path, Path)
+	if !ok || (name != "" && !strings.HasPrefix(name, "/")) {
+		http.NotFound(w, r)
+		return
+	}
+	if name == "" {
+		http.Redirect(w, r, Path+"/", http.StatusMovedPermanently)
+		return
+	}
+
+	name = strings.TrimPrefix(path.Clean(name), "/")
+	file, ok := h.assets[name]
+	if !ok {
+		if path.Ext(name) != "" {
+			http.NotFound(w, r)
+			return
+		}
+		name, file = "index.html", h.assets["index.html"]
+	}
+
+	header := w.Header()
+	header.Set("Content-Security-Policy", contentSecurityPolicy)
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Referrer-Policy", "no-referrer")
+	// Assets are not fingerprinted, so clients revalidate them each time
+	header.Set("Cache-Control", "no-cache")
+	header.Set("ETag", file.etag)
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(file.content))
+}
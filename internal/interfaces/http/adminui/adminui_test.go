@@ -0,0 +1,79 @@
+package adminui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, method, target string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	h, err := New()
+	require.NoError(t, err)
+	req := httptest.NewRequest(method, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_Assets(t *testing.T) {
+	rec := serve(t, http.MethodGet, "/admin/app.js", nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "javascript")
+	assert.Equal(t, contentSecurityPolicy, rec.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+	assert.NotEmpty(t, rec.Body.String())
+}
+
+func TestHandler_Routes(t *testing.T) {
+	index := serve(t, http.MethodGet, "/admin/", nil)
+	require.Equal(t, http.StatusOK, index.Code)
+	assert.Contains(t, index.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, index.Body.String(), `<base href="/admin/">`)
+
+	for _, target := range []string{"/admin/users", "/admin/flags", "/admin/audit?type=user.created", "/admin/users/usr_1"} {
+		rec := serve(t, http.MethodGet, target, nil)
+		assert.Equal(t, http.StatusOK, rec.Code, target)
+		assert.Equal(t, index.Body.String(), rec.Body.String(), "%s is a route of the page", target)
+	}
+}
+
+func TestHandler_NotFound(t *testing.T) {
+	for _, target := range []string{"/admin/missing.js", "/admin/../adminui.go", "/administration"} {
+		rec := serve(t, http.MethodGet, target, nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code, target)
+	}
+}
+
+func TestHandler_Redirect(t *testing.T) {
+	rec := serve(t, http.MethodGet, "/admin", nil)
+
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/admin/", rec.Header().Get("Location"))
+}
+
+func TestHandler_MethodNotAllowed(t *testing.T) {
+	rec := serve(t, http.MethodPost, "/admin/users", nil)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+}
+
+func TestHandler_Revalidation(t *testing.T) {
+	etag := serve(t, http.MethodGet, "/admin/app.css", nil).Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rec := serve(t, http.MethodGet, "/admin/app.css", http.Header{"If-None-Match": {etag}})
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
@@ -0,0 +1,258 @@
+// The admin UI is a page of the API it is served with. It keeps the access
+// token of the session in sessionStorage and routes below /admin itself.
+"use strict";
+
+const API = "/api/v1";
+const PAGE_SIZE = 20;
+const TOKEN_KEY = "admin.access_token";
+
+const view = document.getElementById("view");
+const logout = document.getElementById("logout");
+
+// api calls the JSON API and returns the data of the response. A 401 shows
+// the login form and rejects, so callers stop where they are, unless the
+// call is the login itself.
+async function api(method, path, body) {
+  const headers = { Accept: "application/json" };
+  const token = sessionStorage.getItem(TOKEN_KEY);
+  if (token) {
+    headers.Authorization = "Bearer " + token;
+  }
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+  const res = await fetch(API + path, {
+    method,
+    headers,
+    credentials: "same-origin",
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  const payload = await res.json().catch(() => ({}));
+  if (res.status === 401 && path !== "/auth/login") {
+    sessionStorage.removeItem(TOKEN_KEY);
+    showLogin();
+    throw new Error("unauthenticated");
+  }
+  if (!res.ok) {
+    const err = new Error(payload.message || res.statusText);
+    err.status = res.status;
+    throw err;
+  }
+  return payload.data;
+}
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs || {})) {
+    if (key.startsWith("on")) {
+      node.addEventListener(key.slice(2), value);
+    } else {
+      node.setAttribute(key, value);
+    }
+  }
+  for (const child of children) {
+    node.append(child instanceof Node ? child : String(child ?? ""));
+  }
+  return node;
+}
+
+function table(columns, rows) {
+  const head = el("tr", null, ...columns.map((c) => el("th", null, c.title)));
+  const body = rows.length
+    ? rows.map((row) => el("tr", null, ...columns.map((c) => c.cell(row))))
+    : [el("tr", null, el("td", { colspan: columns.length, class: "muted" }, "Nothing found"))];
+  return el("table", null, el("thead", null, head), el("tbody", null, ...body));
+}
+
+function text(value, cls) {
+  return el("td", cls ? { class: cls } : null, value);
+}
+
+function time(value) {
+  return text(value ? new Date(value).toLocaleString() : "");
+}
+
+function showError(err) {
+  if (err.message !== "unauthenticated") {
+    view.replaceChildren(el("p", { class: "error" }, err.message));
+  }
+}
+
+// pager renders the previous and next buttons of a page at offset that
+// returned count rows
+function pager(offset, count, go) {
+  return el(
+    "div",
+    { class: "pager" },
+    el("button", { type: "button", onclick: () => go(Math.max(0, offset - PAGE_SIZE)), ...(offset === 0 && { disabled: "" }) }, "Previous"),
+    el("button", { type: "button", onclick: () => go(offset + PAGE_SIZE), ...(count < PAGE_SIZE && { disabled: "" }) }, "Next"),
+  );
+}
+
+function filterForm(name, placeholder, value, submit) {
+  const input = el("input", { name, placeholder, value: value || "", type: "search" });
+  return el(
+    "form",
+    { class: "filters", onsubmit: (e) => { e.preventDefault(); submit(input.value.trim()); } },
+    input,
+    el("button", { type: "submit" }, "Filter"),
+  );
+}
+
+async function users(params) {
+  const offset = Number(params.get("offset")) || 0;
+  const email = params.get("email") || "";
+  const query = new URLSearchParams({ limit: PAGE_SIZE, offset });
+  if (email) {
+    query.set("filter[email][contains]", email);
+  }
+  const rows = await api("GET", "/users?" + query);
+  const go = (next) => navigate("users", { email, offset: next });
+  view.replaceChildren(
+    el("h2", null, "Users"),
+    filterForm("email", "Email contains", email, (value) => navigate("users", { email: value })),
+    table(
+      [
+        { title: "ID", cell: (u) => text(u.id, "mono") },
+        { title: "Email", cell: (u) => text(u.email) },
+        { title: "Name", cell: (u) => text(u.name) },
+        { title: "Created", cell: (u) => time(u.created_at) },
+      ],
+      rows || [],
+    ),
+    pager(offset, (rows || []).length, go),
+  );
+}
+
+async function audit(params) {
+  const offset = Number(params.get("offset")) || 0;
+  const type = params.get("type") || "";
+  const query = new URLSearchParams({ limit: PAGE_SIZE, offset });
+  if (type) {
+    query.set("type", type);
+  }
+  let rows;
+  try {
+    rows = await api("GET", "/audit-log?" + query);
+  } catch (err) {
+    if (err.status === 404) {
+      view.replaceChildren(el("h2", null, "Audit log"), el("p", { class: "muted" }, "The audit log is not enabled."));
+      return;
+    }
+    throw err;
+  }
+  const go = (next) => navigate("audit", { type, offset: next });
+  view.replaceChildren(
+    el("h2", null, "Audit log"),
+    filterForm("type", "Event type, e.g. user.created", type, (value) => navigate("audit", { type: value })),
+    table(
+      [
+        { title: "Seq", cell: (e) => text(e.seq) },
+        { title: "Type", cell: (e) => text(e.type) },
+        { title: "Aggregate", cell: (e) => text(e.aggregate_id, "mono") },
+        { title: "Correlation", cell: (e) => text(e.correlation_id, "mono") },
+        { title: "Occurred", cell: (e) => time(e.occurred_at) },
+      ],
+      rows || [],
+    ),
+    pager(offset, (rows || []).length, go),
+  );
+}
+
+async function flags() {
+  const rows = await api("GET", "/feature-flags");
+  const toggle = async (flag, button) => {
+    button.disabled = true;
+    try {
+      await api("PUT", "/feature-flags/" + encodeURIComponent(flag.key), { enabled: !flag.enabled });
+      await flags();
+    } catch (err) {
+      showError(err);
+    }
+  };
+  view.replaceChildren(
+    el("h2", null, "Feature flags"),
+    table(
+      [
+        { title: "Key", cell: (f) => text(f.key, "mono") },
+        { title: "State", cell: (f) => text(f.enabled ? "on" : "off") },
+        { title: "Default", cell: (f) => text(f.default ? "on" : "off", "muted") },
+        { title: "Updated", cell: (f) => time(f.updated_at) },
+        {
+          title: "",
+          cell: (f) => el("td", null, el("button", { type: "button", onclick: (e) => toggle(f, e.target) }, f.enabled ? "Switch off" : "Switch on")),
+        },
+      ],
+      rows || [],
+    ),
+  );
+}
+
+const routes = { users, audit, flags };
+
+function showLogin() {
+  logout.hidden = true;
+  const form = document.getElementById("login").content.firstElementChild.cloneNode(true);
+  const error = form.querySelector(".error");
+  form.addEventListener("submit", async (e) => {
+    e.preventDefault();
+    const fields = new FormData(form);
+    const credentials = { username: fields.get("username"), password: fields.get("password") };
+    if (fields.get("code")) {
+      credentials.code = fields.get("code");
+    }
+    try {
+      const session = await api("POST", "/auth/login", credentials);
+      sessionStorage.setItem(TOKEN_KEY, session.access_token);
+      render();
+    } catch (err) {
+      error.textContent = err.message;
+      error.hidden = false;
+    }
+  });
+  view.replaceChildren(form);
+}
+
+function navigate(route, params) {
+  const query = new URLSearchParams();
+  for (const [key, value] of Object.entries(params || {})) {
+    if (value) {
+      query.set(key, value);
+    }
+  }
+  const search = query.toString();
+  history.pushState(null, "", route + (search ? "?" + search : ""));
+  render();
+}
+
+// render shows the view of the current location, the first path segment
+// below /admin
+function render() {
+  const route = location.pathname.replace(/^\/admin\/?/, "").split("/")[0] || "users";
+  for (const link of document.querySelectorAll("a[data-route]")) {
+    link.classList.toggle("active", link.getAttribute("href") === route);
+  }
+  logout.hidden = !sessionStorage.getItem(TOKEN_KEY);
+  const show = routes[route];
+  if (!show) {
+    view.replaceChildren(el("p", { class: "error" }, "Page not found"));
+    return;
+  }
+  show(new URLSearchParams(location.search)).catch(showError);
+}
+
+document.addEventListener("click", (e) => {
+  const link = e.target.closest("a[data-route]");
+  if (link && !e.metaKey && !e.ctrlKey && !e.shiftKey) {
+    e.preventDefault();
+    navigate(link.getAttribute("href"));
+  }
+});
+
+logout.addEventListener("click", () => {
+  sessionStorage.removeItem(TOKEN_KEY);
+  showLogin();
+});
+
+window.addEventListener("popstate", render);
+render();
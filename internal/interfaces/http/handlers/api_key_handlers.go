@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// APIKeyHandler handles managing the API keys other services authenticate
+// with
+type APIKeyHandler struct {
+	keyUseCase usecase.APIKeyUseCaseInterface
+	defaults   APIDefaults
+	logger     logger.Logger
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(keyUseCase usecase.APIKeyUseCaseInterface, logger logger.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		keyUseCase: keyUseCase,
+		defaults:   DefaultAPIDefaults(),
+		logger:     logger,
+	}
+}
+
+// WithDefaults replaces the page sizes keys are listed with
+func (h *APIKeyHandler) WithDefaults(defaults APIDefaults) *APIKeyHandler {
+	h.defaults = defaults
+	return h
+}
+
+// APIKeyDTO is the API representation of an API key. The key itself is
+// only returned when it is created.
+type APIKeyDTO struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Status     string     `json:"status"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	OwnerID    string     `json:"owner_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreatedAPIKeyDTO is the response data of creating an API key
+type CreatedAPIKeyDTO struct {
+	APIKeyDTO
+	// Key is sent in the X-API-Key header. It cannot be retrieved again.
+	Key string `json:"key"`
+}
+
+// CreateAPIKeyRequest represents the request body for creating an API key
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is when the key stops working; keys without it do not
+	// expire
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreateAPIKey godoc
+// @Summary      Create an API key
+// @Description  Create a key other services authenticate with in the X-API-Key header. Keys may only grant scopes the caller holds, and only admins may grant admin. The key is only returned in this response.
+// @Tags         apikeys
+// @Accept       json
+// @Produce      json
+// @Param        key  body      CreateAPIKeyRequest  true  "API key definition"
+// @Success      201  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      422  {object}  ErrorResponse
+// @Router       /api/v1/apikeys [post]
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	creator, _ := policy.SubjectFromContext(r.Context())
+	key, plaintext, err := h.keyUseCase.CreateKey(r.Context(), creator, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "API key created successfully; store the key now, it is not shown again",
+		Data:      CreatedAPIKeyDTO{APIKeyDTO: presentAPIKey(key), Key: plaintext},
+		Timestamp: time.Now(),
+	})
+}
+
+// ListAPIKeys godoc
+// @Summary      List API keys
+// @Description  List API keys, newest first, including revoked and expired ones
+// @Tags         apikeys
+// @Produce      json
+// @Param        limit   query     int  false  "Maximum number of keys"
+// @Param        offset  query     int  false  "Number of keys to skip"
+// @Success      200     {object}  SuccessResponse
+// @Failure      401     {object}  ErrorResponse
+// @Failure      403     {object}  ErrorResponse
+// @Router       /api/v1/apikeys [get]
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	limit, offset, warnings := pagination(r.URL.Query(), h.defaults.PageSize, h.defaults.MaxPageSize)
+
+	keys, err := h.keyUseCase.ListKeys(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]APIKeyDTO, 0, len(keys))
+	for _, key := range keys {
+		dtos = append(dtos, presentAPIKey(key))
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "API keys retrieved successfully",
+		Data:      dtos,
+		Warnings:  warnings,
+		Meta:      listMeta(limit, offset, len(dtos)),
+		Timestamp: time.Now(),
+	})
+}
+
+// GetAPIKey godoc
+// @Summary      Get an API key
+// @Description  Get an API key without its secret
+// @Tags         apikeys
+// @Produce      json
+// @Param        id   path      string  true  "API key ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/apikeys/{id} [get]
+func (h *APIKeyHandler) GetAPIKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.keyUseCase.GetKey(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "API key retrieved successfully",
+		Data:      presentAPIKey(key),
+		Timestamp: time.Now(),
+	})
+}
+
+// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  Stop an API key from authenticating. Revoked keys stay listed.
+// @Tags         apikeys
+// @Produce      json
+// @Param        id   path      string  true  "API key ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/apikeys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.keyUseCase.RevokeKey(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "API key revoked successfully",
+		Data:      presentAPIKey(key),
+		Timestamp: time.Now(),
+	})
+}
+
+func presentAPIKey(key *entities.APIKey) APIKeyDTO {
+	scopes := key.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return APIKeyDTO{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		Status:     key.Status(time.Now()),
+		CreatedBy:  key.CreatedBy,
+		OwnerID:    key.OwnerID,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockAPIKeyUseCase is a mock implementation of APIKeyUseCaseInterface
+type MockAPIKeyUseCase struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyUseCase) CreateKey(ctx context.Context, creator policy.Subject, name string, scopes []string, expiresAt *time.Time) (*entities.APIKey, string, error) {
+	args := m.Called(ctx, creator, name, scopes, expiresAt)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*entities.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockAPIKeyUseCase) GetKey(ctx context.Context, id string) (*entities.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyUseCase) ListKeys(ctx context.Context, limit, offset int) ([]*entities.APIKey, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyUseCase) RevokeKey(ctx context.Context, id string) (*entities.APIKey, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyUseCase) Authenticate(ctx context.Context, key string) (policy.Subject, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(policy.Subject), args.Error(1)
+}
+
+func TestAPIKeyHandler_CreateAPIKey(t *testing.T) {
+	admin := policy.Subject{ID: "admin_1", Roles: []string{policy.RoleAdmin}}
+
+	t.Run("returns the key once", func(t *testing.T) {
+		mockUseCase := new(MockAPIKeyUseCase)
+		key := entities.NewAPIKey("billing", "ak_0123456789abcdef", "hash", []string{policy.ScopeUsersRead}, "admin_1", nil)
+		key.ID = "key_1"
+		mockUseCase.On("CreateKey", mock.Anything, admin, "billing", []string{policy.ScopeUsersRead}, (*time.Time)(nil)).
+			Return(key, "ak_0123456789abcdef_secret", nil)
+		handler := NewAPIKeyHandler(mockUseCase, logger.New())
+
+		w := httptest.NewRecorder()
+		handler.CreateAPIKey(w, viewRequest(http.MethodPost, "/apikeys", `{"name":"billing","scopes":["users:read"]}`, admin))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NotContains(t, w.Body.String(), "hash")
+		var response struct {
+			Data CreatedAPIKeyDTO `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "key_1", response.Data.ID)
+		assert.Equal(t, "ak_0123456789abcdef_secret", response.Data.Key)
+		assert.Equal(t, entities.APIKeyActive, response.Data.Status)
+		assert.Equal(t, "admin_1", response.Data.CreatedBy)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		mockUseCase := new(MockAPIKeyUseCase)
+		mockUseCase.On("CreateKey", mock.Anything, admin, "billing", []string(nil), (*time.Time)(nil)).
+			Return(nil, "", usecase.ErrAPIKeyScopesRequired)
+		handler := NewAPIKeyHandler(mockUseCase, logger.New())
+
+		w := httptest.NewRecorder()
+		handler.CreateAPIKey(w, viewRequest(http.MethodPost, "/apikeys", `{"name":"billing"}`, admin))
+
+		assert.Contains(t, w.Body.String(), usecase.ErrAPIKeyScopesRequired.Error())
+	})
+
+	t.Run("scope the caller does not hold", func(t *testing.T) {
+		user := policy.Subject{ID: "user_1", Roles: []string{policy.RoleUser}, Scopes: []string{policy.ScopeUsersWrite}}
+		mockUseCase := new(MockAPIKeyUseCase)
+		mockUseCase.On("CreateKey", mock.Anything, user, "escalate", []string{policy.ScopeAdmin}, (*time.Time)(nil)).
+			Return(nil, "", fmt.Errorf("%w: %q", usecase.ErrScopeNotHeld, policy.ScopeAdmin))
+		handler := NewAPIKeyHandler(mockUseCase, logger.New())
+
+		w := httptest.NewRecorder()
+		handler.CreateAPIKey(w, viewRequest(http.MethodPost, "/apikeys", `{"name":"escalate","scopes":["admin"]}`, user))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"scope_not_held"`)
+		mockUseCase.AssertExpectations(t)
+	})
+
+	t.Run("malformed body", func(t *testing.T) {
+		mockUseCase := new(MockAPIKeyUseCase)
+		handler := NewAPIKeyHandler(mockUseCase, logger.New())
+
+		w := httptest.NewRecorder()
+		handler.CreateAPIKey(w, viewRequest(http.MethodPost, "/apikeys", `{"name":`, admin))
+
+		assert.Contains(t, w.Body.String(), `"status":"error"`)
+		mockUseCase.AssertNotCalled(t, "CreateKey", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAPIKeyHandler_ListAPIKeys(t *testing.T) {
+	revokedAt := time.Now()
+	active := &entities.APIKey{ID: "key_1", Name: "billing", Prefix: "ak_1", Scopes: []string{policy.ScopeUsersRead}}
+	revoked := &entities.APIKey{ID: "key_2", Name: "legacy", Prefix: "ak_2", RevokedAt: &revokedAt}
+
+	mockUseCase := new(MockAPIKeyUseCase)
+	mockUseCase.On("ListKeys", mock.Anything, 10, 0).Return([]*entities.APIKey{active, revoked}, nil)
+	handler := NewAPIKeyHandler(mockUseCase, logger.New()).WithDefaults(APIDefaults{PageSize: 10, MaxPageSize: 10})
+
+	w := httptest.NewRecorder()
+	handler.ListAPIKeys(w, httptest.NewRequest(http.MethodGet, "/apikeys?limit=50", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data     []APIKeyDTO `json:"data"`
+		Warnings []Warning   `json:"warnings"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, entities.APIKeyActive, response.Data[0].Status)
+	assert.Equal(t, entities.APIKeyRevoked, response.Data[1].Status)
+	assert.Equal(t, []string{}, response.Data[1].Scopes)
+	require.Len(t, response.Warnings, 1)
+	assert.Equal(t, WarningParameterClamped, response.Warnings[0].Code)
+}
+
+func TestAPIKeyHandler_RevokeAPIKey(t *testing.T) {
+	revokedAt := time.Now()
+	mockUseCase := new(MockAPIKeyUseCase)
+	mockUseCase.On("RevokeKey", mock.Anything, "key_1").Return(&entities.APIKey{ID: "key_1", RevokedAt: &revokedAt}, nil)
+	mockUseCase.On("RevokeKey", mock.Anything, "key_missing").Return(nil, repositories.ErrAPIKeyNotFound)
+	handler := NewAPIKeyHandler(mockUseCase, logger.New())
+
+	r := chi.NewRouter()
+	r.Delete("/apikeys/{id}", handler.RevokeAPIKey)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/apikeys/key_1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"revoked"`)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/apikeys/key_missing", nil))
+	assert.Contains(t, w.Body.String(), repositories.ErrAPIKeyNotFound.Error())
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// AuditLogHandler handles administrators browsing the audit log
+type AuditLogHandler struct {
+	auditUseCase usecase.AuditUseCaseInterface
+	defaults     APIDefaults
+	logger       logger.Logger
+}
+
+// NewAuditLogHandler creates a new audit log handler
+func NewAuditLogHandler(auditUseCase usecase.AuditUseCaseInterface, logger logger.Logger) *AuditLogHandler {
+	return &AuditLogHandler{
+		auditUseCase: auditUseCase,
+		defaults:     DefaultAPIDefaults(),
+		logger:       logger,
+	}
+}
+
+// WithDefaults replaces the page sizes of audit log listings
+func (h *AuditLogHandler) WithDefaults(defaults APIDefaults) *AuditLogHandler {
+	h.defaults = defaults
+	return h
+}
+
+// AuditEntryDTO is the API representation of an audit entry
+type AuditEntryDTO struct {
+	Seq           int64           `json:"seq"`
+	EventID       string          `json:"event_id"`
+	Type          string          `json:"type"`
+	AggregateID   string          `json:"aggregate_id"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	RecordedAt    time.Time       `json:"recorded_at"`
+	Data          json.RawMessage `json:"data,omitempty"`
+	Hash          string          `json:"hash,omitempty"`
+}
+
+// ListAuditEntries godoc
+// @Summary      List audit entries
+// @Description  List the audit log newest first, filtered by event type, aggregate or correlation ID
+// @Tags         audit
+// @Produce      json
+// @Param        type            query     string  false  "Event type, e.g. user.created"
+// @Param        aggregate_id    query     string  false  "ID of the aggregate the events are about"
+// @Param        correlation_id  query     string  false  "Correlation ID of the API call that caused the events"
+// @Param        limit           query     int     false  "Number of entries to return"
+// @Param        offset          query     int     false  "Number of entries to skip"
+// @Success      200             {object}  SuccessResponse
+// @Failure      401             {object}  ErrorResponse
+// @Failure      403             {object}  ErrorResponse
+// @Failure      500             {object}  ErrorResponse
+// @Router       /api/v1/audit-log [get]
+func (h *AuditLogHandler) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repositories.AuditFilter{
+		Type:          query.Get("type"),
+		AggregateID:   query.Get("aggregate_id"),
+		CorrelationID: query.Get("correlation_id"),
+	}
+	var warnings []Warning
+	filter.Limit, filter.Offset, warnings = pagination(query, h.defaults.PageSize, h.defaults.MaxPageSize)
+
+	entries, err := h.auditUseCase.ListEntries(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]AuditEntryDTO, 0, len(entries))
+	for _, entry := range entries {
+		dtos = append(dtos, presentAuditEntry(entry))
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Audit entries retrieved successfully",
+		Data:      dtos,
+		Warnings:  warnings,
+		Meta:      listMeta(filter.Limit, filter.Offset, len(dtos)),
+		Timestamp: time.Now(),
+	})
+}
+
+func presentAuditEntry(entry *entities.AuditEntry) AuditEntryDTO {
+	return AuditEntryDTO{
+		Seq:           entry.Seq,
+		EventID:       entry.EventID,
+		Type:          entry.Type,
+		AggregateID:   entry.AggregateID,
+		CorrelationID: entry.CorrelationID,
+		OccurredAt:    entry.OccurredAt,
+		RecordedAt:    entry.RecordedAt,
+		Data:          entry.Data,
+		Hash:          entry.Hash,
+	}
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// MockAuditUseCase is a mock implementation of AuditUseCaseInterface
+type MockAuditUseCase struct {
+	mock.Mock
+}
+
+func (m *MockAuditUseCase) ListEntries(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AuditEntry), args.Error(1)
+}
+
+func TestAuditLogHandler_ListAuditEntries(t *testing.T) {
+	mockUseCase := new(MockAuditUseCase)
+	mockUseCase.On("ListEntries", mock.Anything, repositories.AuditFilter{Type: "user.created", Limit: 20, Offset: 20}).
+		Return([]*entities.AuditEntry{{
+			Seq:         41,
+			EventID:     "evt_1",
+			Type:        "user.created",
+			AggregateID: "user_1",
+			OccurredAt:  time.Now(),
+			RecordedAt:  time.Now(),
+			Data:        json.RawMessage(`{"id":"user_1"}`),
+			Hash:        "ab12",
+		}}, nil)
+	handler := NewAuditLogHandler(mockUseCase, logger.New())
+
+	w := httptest.NewRecorder()
+	handler.ListAuditEntries(w, httptest.NewRequest("GET", "/audit-log?type=user.created&limit=20&offset=20", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []AuditEntryDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, int64(41), response.Data[0].Seq)
+	assert.JSONEq(t, `{"id":"user_1"}`, string(response.Data[0].Data), "the event data is embedded as-is")
+	mockUseCase.AssertExpectations(t)
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	authmw "clean-architecture/internal/interfaces/http/middleware/auth"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/apperrors"
+	"clean-architecture/pkg/clientip"
+	"clean-architecture/pkg/logger"
+)
+
+// AuthHandler handles logging in, with a password or single sign-on
+type AuthHandler struct {
+	authUseCase   usecase.AuthUseCaseInterface
+	presenter     *UserPresenter
+	logger        logger.Logger
+	saml          map[string]SAMLTenant
+	oidc          map[string]OIDCProvider
+	sessionCookie *SessionCookie
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(authUseCase usecase.AuthUseCaseInterface, presenter *UserPresenter, logger logger.Logger) *AuthHandler {
+	return &AuthHandler{
+		authUseCase: authUseCase,
+		presenter:   presenter,
+		logger:      logger,
+	}
+}
+
+// LoginRequest represents the request body for logging in
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// Code is the one-time code of users who enabled MFA
+	Code string `json:"code,omitempty"`
+}
+
+// credentials returns the credentials the request logs in with from the
+// client of r
+func (req LoginRequest) credentials(r *http.Request) usecase.Credentials {
+	return usecase.Credentials{Username: req.Username, Password: req.Password, Code: req.Code, IP: clientIP(r)}
+}
+
+// clientIP returns the address of the client of r, without the port, as
+// resolved before middleware.RealIP replaced it with a forwarded address
+func clientIP(r *http.Request) string {
+	if ip, ok := clientip.FromContext(r.Context()); ok {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// SignupRequest represents the request body for signing up
+type SignupRequest struct {
+	Email    string `json:"email"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// LoginResponseDTO carries the access token issued by a login
+type LoginResponseDTO struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int64     `json:"expires_in"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	User        UserDTO   `json:"user"`
+}
+
+// Login godoc
+// @Summary      Log in
+// @Description  Exchange a username and password for a bearer access token. Users logging in for the first time are created. Users who enabled MFA also send the one-time code of their authenticator app.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      LoginRequest  true  "Credentials"
+// @Success      200      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      429      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Failure      503      {object}  ErrorResponse
+// @Router       /api/v1/auth/login [post]
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if req.Username == "" || req.Password == "" {
+		unprocessable(w, r, "credentials_required", "Username and password are required")
+		return
+	}
+
+	session, err := h.authUseCase.Login(r.Context(), req.credentials(r))
+	if err != nil {
+		h.loginError(w, r, err)
+		return
+	}
+
+	h.respondSession(w, r, session)
+}
+
+// VerifySignupRequest represents the request body for verifying the
+// email of a signup with the token from a verification link
+type VerifySignupRequest struct {
+	Token string `json:"token"`
+}
+
+// Signup godoc
+// @Summary      Sign up
+// @Description  Create a user who logs in with an email and password once they followed the verification link sent to the email
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      SignupRequest  true  "New user"
+// @Success      201      {object}  UserResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api/v1/auth/signup [post]
+func (h *AuthHandler) Signup(w http.ResponseWriter, r *http.Request) {
+	var req SignupRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	user, err := h.authUseCase.SignUp(r.Context(), req.Email, req.Name, req.Password)
+	if err != nil {
+		h.signupError(w, r, err)
+		return
+	}
+	setConsistencyToken(w, repositories.UserResource, user.ID)
+	render.Status(r, http.StatusCreated)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Signed up successfully; follow the link sent to your email to log in",
+		Data:      h.presenter.Present(ownerContext(r, user), user),
+		Timestamp: time.Now(),
+	})
+}
+
+// VerifySignup godoc
+// @Summary      Verify a signup
+// @Description  Verify the email of a user who signed up with the token from the verification email, from when they can log in. No authentication is required.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      VerifySignupRequest  true  "Verification token"
+// @Success      200      {object}  UserResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      410      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Router       /api/v1/auth/signup/verify [post]
+func (h *AuthHandler) VerifySignup(w http.ResponseWriter, r *http.Request) {
+	var req VerifySignupRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if req.Token == "" {
+		unprocessable(w, r, "token_required", "token is required")
+		return
+	}
+
+	user, err := h.authUseCase.VerifySignup(r.Context(), req.Token)
+	if err != nil {
+		h.signupError(w, r, err)
+		return
+	}
+	setConsistencyToken(w, repositories.UserResource, user.ID)
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Email verified successfully; log in to continue",
+		Data:      h.presenter.Present(ownerContext(r, user), user),
+		Timestamp: time.Now(),
+	})
+}
+
+// ownerContext returns the context of r as seen by user, so the user is
+// presented to themselves before they logged in
+func ownerContext(r *http.Request, user *entities.User) context.Context {
+	return policy.WithSubject(r.Context(), policy.Subject{ID: user.ID, Roles: []string{policy.RoleUser}})
+}
+
+// signupError writes the response for a failed signup
+func (h *AuthHandler) signupError(w http.ResponseWriter, r *http.Request, err error) {
+	var status int
+	var message string
+	switch {
+	case apperrors.KindOf(err) == apperrors.KindValidation:
+		// The message details the password policy
+		status, message = http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, usecase.ErrSignupUnavailable):
+		status, message = http.StatusNotFound, "Signup is not enabled"
+	case errors.Is(err, usecase.ErrVerificationLinkExpired):
+		status, message = http.StatusGone, "Verification link has expired"
+	case errors.Is(err, usecase.ErrQuotaExceeded):
+		status, message = http.StatusForbidden, "No more users can sign up"
+	default:
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, status)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Code:      apperrors.CodeOf(err),
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Description  Revoke the bearer access token of the Authorization header, so it is refused before it expires. Logging out twice with the same token succeeds.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	token, ok := authmw.BearerToken(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		render.Status(r, http.StatusUnauthorized)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	var status int
+	var message string
+	switch err := h.authUseCase.Logout(r.Context(), token); {
+	case err == nil:
+		respondJSON(w, r, Response{
+			Status:    "success",
+			Message:   "Logged out successfully",
+			Timestamp: time.Now(),
+		})
+		return
+	case errors.Is(err, auth.ErrInvalidToken):
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description="The access token is invalid or expired"`)
+		status, message = http.StatusUnauthorized, "Invalid or expired access token"
+	case errors.Is(err, usecase.ErrLogoutUnavailable):
+		status, message = http.StatusNotFound, "Logout is not enabled"
+	default:
+		InternalError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, status)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
+
+// respondSession writes the access token and user of a session
+func (h *AuthHandler) respondSession(w http.ResponseWriter, r *http.Request, session *usecase.Session) {
+	// The user is presented as seen by the session's own subject
+	ctx := policy.WithSubject(r.Context(), policy.Subject{
+		ID:     session.User.ID,
+		Roles:  session.Claims.Roles,
+		Scopes: session.Claims.Scopes,
+	})
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, r, Response{
+		Status:  "success",
+		Message: "Logged in successfully",
+		Data: LoginResponseDTO{
+			AccessToken: session.AccessToken,
+			TokenType:   "Bearer",
+			ExpiresIn:   int64(time.Until(session.ExpiresAt).Seconds()),
+			ExpiresAt:   session.ExpiresAt,
+			User:        h.presenter.Present(ctx, session.User),
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// loginError writes the response for a failed login. Unknown users and
+// wrong passwords share one message so accounts cannot be enumerated.
+func (h *AuthHandler) loginError(w http.ResponseWriter, r *http.Request, err error) {
+	var status int
+	var message string
+	switch {
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		status, message = http.StatusUnauthorized, "Invalid username or password"
+	case errors.Is(err, auth.ErrAccessDenied):
+		status, message = http.StatusForbidden, "Account is not permitted to log in"
+	case errors.Is(err, auth.ErrEmailNotVerified):
+		status, message = http.StatusForbidden, "Verify your email with the link sent to it before logging in"
+	case errors.Is(err, usecase.ErrIdentityWithoutEmail):
+		status, message = http.StatusForbidden, "Account has no email address"
+	case errors.Is(err, usecase.ErrQuotaExceeded):
+		status, message = http.StatusForbidden, "No more users can be provisioned"
+	case errors.Is(err, usecase.ErrLoginUnavailable):
+		status, message = http.StatusNotFound, "Password login is not configured"
+	case errors.Is(err, auth.ErrProviderUnavailable):
+		status, message = http.StatusServiceUnavailable, "Identity provider is unavailable"
+	case errors.Is(err, usecase.ErrMFARequired):
+		status, message = http.StatusUnauthorized, "One-time code required"
+	case errors.Is(err, usecase.ErrInvalidMFACode):
+		status, message = http.StatusUnauthorized, "Invalid one-time code"
+	case errors.Is(err, usecase.ErrMFAUnavailable):
+		status, message = http.StatusServiceUnavailable, "Multi-factor authentication is not configured"
+	case errors.Is(err, usecase.ErrLoginLocked):
+		status, message = http.StatusTooManyRequests, "Too many failed logins; try again later"
+	default:
+		InternalError(w, r, h.logger, err)
+		return
+	}
+
+	errorStatus(w, r, status, err)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
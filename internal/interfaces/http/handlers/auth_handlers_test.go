@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/retryafter"
+)
+
+// MockAuthUseCase is a mock implementation of AuthUseCaseInterface
+type MockAuthUseCase struct {
+	mock.Mock
+}
+
+func (m *MockAuthUseCase) Login(ctx context.Context, creds usecase.Credentials) (*usecase.Session, error) {
+	args := m.Called(ctx, creds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.Session), args.Error(1)
+}
+
+func (m *MockAuthUseCase) LoginIdentity(ctx context.Context, identity *auth.Identity) (*usecase.Session, error) {
+	args := m.Called(ctx, identity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.Session), args.Error(1)
+}
+
+func (m *MockAuthUseCase) SignUp(ctx context.Context, email, name, password string) (*entities.User, error) {
+	args := m.Called(ctx, email, name, password)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), args.Error(1)
+}
+
+func (m *MockAuthUseCase) VerifySignup(ctx context.Context, token string) (*entities.User, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), args.Error(1)
+}
+
+func (m *MockAuthUseCase) Authenticate(ctx context.Context, token string) (policy.Subject, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(policy.Subject), args.Error(1)
+}
+
+func (m *MockAuthUseCase) StartSession(ctx context.Context, creds usecase.Credentials) (*usecase.Session, error) {
+	args := m.Called(ctx, creds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.Session), args.Error(1)
+}
+
+func (m *MockAuthUseCase) AuthenticateSession(ctx context.Context, id string) (policy.Subject, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(policy.Subject), args.Error(1)
+}
+
+func (m *MockAuthUseCase) EndSession(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAuthUseCase) Logout(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	expiresAt := time.Now().Add(15 * time.Minute)
+	user := &entities.User{ID: "user_1", Email: "bjensen@example.com", Name: "Barbara Jensen"}
+
+	mockUseCase := new(MockAuthUseCase)
+	mockUseCase.On("Login", mock.Anything, usecase.Credentials{Username: "bjensen", Password: "hunter2", IP: "192.0.2.1"}).Return(&usecase.Session{
+		AccessToken: "token_1",
+		ExpiresAt:   expiresAt,
+		Claims:      auth.Claims{Subject: "user_1", Scopes: []string{policy.ScopeUsersWrite}},
+		User:        user,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBufferString(`{"username":"bjensen","password":"hunter2"}`))
+	NewAuthHandler(mockUseCase, NewUserPresenter(nil), logger.New()).Login(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var body struct {
+		Data LoginResponseDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "token_1", body.Data.AccessToken)
+	assert.Equal(t, "Bearer", body.Data.TokenType)
+	assert.InDelta(t, 900, body.Data.ExpiresIn, 2)
+	assert.Equal(t, "bjensen@example.com", body.Data.User.Email)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestAuthHandler_Login_OneTimeCode(t *testing.T) {
+	mockUseCase := new(MockAuthUseCase)
+	mockUseCase.On("Login", mock.Anything, usecase.Credentials{Username: "bjensen", Password: "hunter2", Code: "123456", IP: "192.0.2.1"}).Return(&usecase.Session{
+		AccessToken: "token_1",
+		ExpiresAt:   time.Now().Add(15 * time.Minute),
+		User:        &entities.User{ID: "user_1", Email: "bjensen@example.com"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBufferString(`{"username":"bjensen","password":"hunter2","code":"123456"}`))
+	NewAuthHandler(mockUseCase, NewUserPresenter(nil), logger.New()).Login(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestAuthHandler_Login_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		err             error
+		expectedStatus  int
+		expectedMessage string
+		// expectedRetryAfter is the Retry-After header, empty when unset
+		expectedRetryAfter string
+	}{
+		{
+			name:            "invalid credentials",
+			body:            `{"username":"bjensen","password":"wrong"}`,
+			err:             auth.ErrInvalidCredentials,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "Invalid username or password",
+		},
+		{
+			name:            "access denied",
+			body:            `{"username":"bjensen","password":"wrong"}`,
+			err:             auth.ErrAccessDenied,
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "Account is not permitted to log in",
+		},
+		{
+			name:            "login unavailable",
+			body:            `{"username":"bjensen","password":"wrong"}`,
+			err:             usecase.ErrLoginUnavailable,
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "Password login is not configured",
+		},
+		{
+			name:            "provider degraded",
+			body:            `{"username":"bjensen","password":"wrong"}`,
+			err:             fmt.Errorf("ldap: %w", auth.ErrProviderUnavailable),
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedMessage: "Identity provider is unavailable",
+		},
+		{
+			name:               "provider degraded until its next check",
+			body:               `{"username":"bjensen","password":"wrong"}`,
+			err:                fmt.Errorf("ldap: %w", retryafter.Wrap(auth.ErrProviderUnavailable, 15*time.Second)),
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedMessage:    "Identity provider is unavailable",
+			expectedRetryAfter: "15",
+		},
+		{
+			name:            "one-time code required",
+			body:            `{"username":"bjensen","password":"wrong"}`,
+			err:             usecase.ErrMFARequired,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "One-time code required",
+		},
+		{
+			name:            "invalid one-time code",
+			body:            `{"username":"bjensen","password":"wrong"}`,
+			err:             usecase.ErrInvalidMFACode,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "Invalid one-time code",
+		},
+		{
+			name:            "MFA not configured",
+			body:            `{"username":"bjensen","password":"wrong"}`,
+			err:             usecase.ErrMFAUnavailable,
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedMessage: "Multi-factor authentication is not configured",
+		},
+		{
+			name:               "locked",
+			body:               `{"username":"bjensen","password":"wrong"}`,
+			err:                &usecase.LockoutError{Until: time.Now().Add(90 * time.Second)},
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedMessage:    "Too many failed logins; try again later",
+			expectedRetryAfter: "90",
+		},
+		{
+			name:            "provider failure",
+			body:            `{"username":"bjensen","password":"wrong"}`,
+			err:             errors.New("ldap: connection refused"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: internalErrorMessage,
+		},
+		{
+			name:            "missing password",
+			body:            `{"username":"bjensen"}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "Username and password are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockAuthUseCase)
+			mockUseCase.On("Login", mock.Anything, usecase.Credentials{Username: "bjensen", Password: "wrong", IP: "192.0.2.1"}).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/auth/login", bytes.NewBufferString(tt.body))
+			NewAuthHandler(mockUseCase, NewUserPresenter(nil), logger.New()).Login(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedMessage, body["message"])
+			assert.Equal(t, tt.expectedRetryAfter, w.Header().Get(retryafter.Header))
+			assert.NotContains(t, w.Body.String(), "ldap")
+		})
+	}
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	tests := []struct {
+		name            string
+		authorization   string
+		err             error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "revoked",
+			authorization:   "Bearer token_1",
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "Logged out successfully",
+		},
+		{
+			name:            "no token",
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "Authentication required",
+		},
+		{
+			name:            "invalid token",
+			authorization:   "Bearer token_1",
+			err:             auth.ErrInvalidToken,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "Invalid or expired access token",
+		},
+		{
+			name:            "unavailable",
+			authorization:   "Bearer token_1",
+			err:             usecase.ErrLogoutUnavailable,
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "Logout is not enabled",
+		},
+		{
+			name:            "store failure",
+			authorization:   "Bearer token_1",
+			err:             errors.New("redis: connection refused"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: internalErrorMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockAuthUseCase)
+			mockUseCase.On("Logout", mock.Anything, "token_1").Return(tt.err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/auth/logout", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			NewAuthHandler(mockUseCase, NewUserPresenter(nil), logger.New()).Logout(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedMessage, body["message"])
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Signup(t *testing.T) {
+	user := &entities.User{ID: "user_1", Email: "bjensen@example.com", Name: "Barbara Jensen", PasswordHash: "$2a$12$secret", EmailUnverified: true}
+
+	mockUseCase := new(MockAuthUseCase)
+	mockUseCase.On("SignUp", mock.Anything, "bjensen@example.com", "Barbara Jensen", "correct horse").Return(user, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/auth/signup", bytes.NewBufferString(`{"email":"bjensen@example.com","name":"Barbara Jensen","password":"correct horse"}`))
+	NewAuthHandler(mockUseCase, NewUserPresenter(nil), logger.New()).Signup(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NotEmpty(t, w.Header().Get(consistency.Header))
+	assert.NotContains(t, w.Body.String(), "$2a$")
+	assert.NotContains(t, w.Body.String(), "access_token")
+	var body struct {
+		Data UserDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "user_1", body.Data.ID)
+	assert.Equal(t, "bjensen@example.com", body.Data.Email)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestAuthHandler_VerifySignup(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "verified", body: `{"token":"token_1"}`, expectedStatus: http.StatusOK},
+		{name: "missing token", body: `{}`, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "invalid link", body: `{"token":"token_1"}`, err: usecase.ErrInvalidVerificationLink, expectedStatus: http.StatusNotFound},
+		{name: "expired link", body: `{"token":"token_1"}`, err: usecase.ErrVerificationLinkExpired, expectedStatus: http.StatusGone},
+		{name: "signup disabled", body: `{"token":"token_1"}`, err: usecase.ErrSignupUnavailable, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockAuthUseCase)
+			if tt.err != nil {
+				mockUseCase.On("VerifySignup", mock.Anything, "token_1").Return(nil, tt.err)
+			} else {
+				mockUseCase.On("VerifySignup", mock.Anything, "token_1").Return(&entities.User{ID: "user_1", Email: "bjensen@example.com"}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/auth/signup/verify", bytes.NewBufferString(tt.body))
+			NewAuthHandler(mockUseCase, NewUserPresenter(nil), logger.New()).VerifySignup(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.NotEmpty(t, w.Header().Get(consistency.Header))
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Signup_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "short password",
+			err:             fmt.Errorf("%w: use at least 12 characters", usecase.ErrPasswordTooShort),
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "password is too short: use at least 12 characters",
+		},
+		{
+			name:            "missing name",
+			err:             usecase.ErrNameRequired,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "name is required",
+		},
+		{
+			name:            "email taken",
+			err:             fmt.Errorf("failed to create user: %w", repositories.ErrUserAlreadyExists),
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: "user with this email already exists",
+		},
+		{
+			name:            "signup disabled",
+			err:             usecase.ErrSignupUnavailable,
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "Signup is not enabled",
+		},
+		{
+			name:            "user quota reached",
+			err:             &usecase.QuotaExceededError{Resource: "users", Limit: 100},
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "No more users can sign up",
+		},
+		{
+			name:            "hashing failure",
+			err:             errors.New("failed to hash password: out of memory"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: internalErrorMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockAuthUseCase)
+			mockUseCase.On("SignUp", mock.Anything, "bjensen@example.com", "", "hunter2").Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/auth/signup", bytes.NewBufferString(`{"email":"bjensen@example.com","password":"hunter2"}`))
+			NewAuthHandler(mockUseCase, NewUserPresenter(nil), logger.New()).Signup(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedMessage, body["message"])
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/billing"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// BillingHandler handles billing provider webhooks and the plan in effect
+type BillingHandler struct {
+	billingUseCase usecase.BillingUseCaseInterface
+	// signatureHeader names the header the provider signs webhooks in
+	signatureHeader string
+	logger          logger.Logger
+}
+
+// NewBillingHandler creates a new billing handler reading webhook
+// signatures from signatureHeader
+func NewBillingHandler(billingUseCase usecase.BillingUseCaseInterface, signatureHeader string, logger logger.Logger) *BillingHandler {
+	return &BillingHandler{
+		billingUseCase:  billingUseCase,
+		signatureHeader: signatureHeader,
+		logger:          logger,
+	}
+}
+
+// PlanDTO is the API representation of a plan
+type PlanDTO struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// MaxUsers is the user quota the plan sets; 0 is unlimited
+	MaxUsers int      `json:"max_users"`
+	Features []string `json:"features"`
+}
+
+// SubscriptionDTO is the API representation of a subscription
+type SubscriptionDTO struct {
+	ID               string     `json:"id"`
+	Provider         string     `json:"provider"`
+	CustomerID       string     `json:"customer_id,omitempty"`
+	Status           string     `json:"status"`
+	CurrentPeriodEnd *time.Time `json:"current_period_end,omitempty"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// BillingStatusDTO is the API representation of the plan in effect
+type BillingStatusDTO struct {
+	Plan PlanDTO `json:"plan"`
+	// Subscription is omitted while the default plan is in effect
+	Subscription *SubscriptionDTO `json:"subscription,omitempty"`
+}
+
+// ReceiveWebhook godoc
+// @Summary      Receive a billing webhook
+// @Description  Endpoint for the billing provider's subscription events, authenticated by their signature. Other events are acknowledged without processing. Failures other than invalid signatures and payloads make providers retry.
+// @Tags         billing
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      422  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/billing/webhooks [post]
+func (h *BillingHandler) ReceiveWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	err = h.billingUseCase.HandleWebhook(r.Context(), payload, r.Header.Get(h.signatureHeader))
+	if err != nil {
+		h.billingError(w, r, err)
+		return
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Webhook processed",
+		Timestamp: time.Now(),
+	})
+}
+
+// GetSubscription godoc
+// @Summary      Get the plan in effect
+// @Description  Get the plan in effect with its user limit and features, and the subscription granting it. The default plan is in effect while no subscription is.
+// @Tags         billing
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/billing/subscription [get]
+func (h *BillingHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	status, err := h.billingUseCase.Status(r.Context())
+	if err != nil {
+		InternalError(w, r, h.logger, err)
+		return
+	}
+
+	dto := BillingStatusDTO{Plan: PlanDTO{
+		ID:       status.Plan.ID,
+		Name:     status.Plan.Name,
+		MaxUsers: status.Plan.MaxUsers,
+		Features: status.Plan.Features,
+	}}
+	if sub := status.Subscription; sub != nil {
+		dto.Subscription = &SubscriptionDTO{
+			ID:         sub.ID,
+			Provider:   sub.Provider,
+			CustomerID: sub.CustomerID,
+			Status:     sub.Status,
+			UpdatedAt:  sub.UpdatedAt,
+		}
+		if !sub.PeriodEnd.IsZero() {
+			periodEnd := sub.PeriodEnd
+			dto.Subscription.CurrentPeriodEnd = &periodEnd
+		}
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Subscription retrieved successfully",
+		Data:      dto,
+		Timestamp: time.Now(),
+	})
+}
+
+// billingError writes the response for a failed webhook
+func (h *BillingHandler) billingError(w http.ResponseWriter, r *http.Request, err error) {
+	var status int
+	var message string
+	switch {
+	case errors.Is(err, billing.ErrInvalidSignature):
+		status, message = http.StatusUnauthorized, "Invalid webhook signature"
+	case errors.Is(err, usecase.ErrInvalidWebhook):
+		status, message = http.StatusBadRequest, "Invalid webhook payload"
+	case errors.Is(err, usecase.ErrUnknownPlan):
+		status, message = http.StatusUnprocessableEntity, usecase.ErrUnknownPlan.Error()
+	default:
+		InternalError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, status)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
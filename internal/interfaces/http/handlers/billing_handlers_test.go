@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/billing"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockBillingUseCase is a mock implementation of BillingUseCaseInterface
+type MockBillingUseCase struct {
+	mock.Mock
+}
+
+func (m *MockBillingUseCase) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	args := m.Called(ctx, payload, signature)
+	return args.Error(0)
+}
+
+func (m *MockBillingUseCase) Status(ctx context.Context) (*usecase.BillingStatus, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.BillingStatus), args.Error(1)
+}
+
+func (m *MockBillingUseCase) Allows(ctx context.Context, feature string) (bool, error) {
+	args := m.Called(ctx, feature)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestBillingHandler_ReceiveWebhook(t *testing.T) {
+	payload := `{"id":"evt_1"}`
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"processed", nil, http.StatusOK, "Webhook processed"},
+		{"invalid signature", billing.ErrInvalidSignature, http.StatusUnauthorized, "Invalid webhook signature"},
+		{"invalid payload", usecase.ErrInvalidWebhook, http.StatusBadRequest, "Invalid webhook payload"},
+		{"unknown plan", usecase.ErrUnknownPlan, http.StatusUnprocessableEntity, usecase.ErrUnknownPlan.Error()},
+		{"store error", errors.New("connection refused"), http.StatusInternalServerError, internalErrorMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockBillingUseCase)
+			mockUseCase.On("HandleWebhook", mock.Anything, []byte(payload), "sha256=abc").Return(tt.err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/billing/webhooks", bytes.NewBufferString(payload))
+			req.Header.Set("X-Billing-Signature", "sha256=abc")
+			NewBillingHandler(mockUseCase, "X-Billing-Signature", logger.New()).ReceiveWebhook(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBillingHandler_GetSubscription(t *testing.T) {
+	pro, _ := entities.LookupPlan("pro")
+	free, _ := entities.LookupPlan("free")
+
+	t.Run("subscribed", func(t *testing.T) {
+		mockUseCase := new(MockBillingUseCase)
+		mockUseCase.On("Status", mock.Anything).Return(&usecase.BillingStatus{
+			Plan: pro,
+			Subscription: &entities.Subscription{
+				ID: "sub_1", Provider: "stripe", CustomerID: "cus_1", PlanID: "pro",
+				Status: entities.SubscriptionActive, PeriodEnd: time.Now(), UpdatedAt: time.Now(),
+			},
+		}, nil)
+
+		w := httptest.NewRecorder()
+		NewBillingHandler(mockUseCase, "Stripe-Signature", logger.New()).GetSubscription(w, httptest.NewRequest("GET", "/billing/subscription", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data BillingStatusDTO `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "pro", response.Data.Plan.ID)
+		assert.Equal(t, []string{entities.FeatureExports, entities.FeatureImports}, response.Data.Plan.Features)
+		require.NotNil(t, response.Data.Subscription)
+		assert.Equal(t, "cus_1", response.Data.Subscription.CustomerID)
+		assert.NotNil(t, response.Data.Subscription.CurrentPeriodEnd)
+	})
+
+	t.Run("default plan", func(t *testing.T) {
+		mockUseCase := new(MockBillingUseCase)
+		mockUseCase.On("Status", mock.Anything).Return(&usecase.BillingStatus{Plan: free}, nil)
+
+		w := httptest.NewRecorder()
+		NewBillingHandler(mockUseCase, "Stripe-Signature", logger.New()).GetSubscription(w, httptest.NewRequest("GET", "/billing/subscription", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"features":[]`)
+		assert.NotContains(t, w.Body.String(), `"subscription"`)
+	})
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-chi/render"
+	"golang.org/x/text/unicode/norm"
+
+	"clean-architecture/internal/interfaces/http/middleware/limits"
+	"clean-architecture/pkg/httpserver"
+)
+
+// errMalformedUTF8 is returned for request bodies that are not valid UTF-8.
+// encoding/json would otherwise silently replace invalid bytes.
+var errMalformedUTF8 = errors.New("request body is not valid UTF-8")
+
+// collectionTooLargeError is returned for request bodies containing an
+// array with more items than allowed
+type collectionTooLargeError struct {
+	limit int
+}
+
+// Error implements the error interface
+func (e *collectionTooLargeError) Error() string {
+	return fmt.Sprintf("request body contains an array of more than %d items", e.limit)
+}
+
+// decodeJSON decodes the request body into v and sanitizes every string it
+// contains: text is normalized to NFC, and control and bidirectional
+// override characters are removed, so they can neither forge log lines nor
+// be stored. Arrays may hold at most the request's collection limit, which
+// is checked before anything is allocated for them. An empty body yields an
+// error matching io.EOF.
+func decodeJSON(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if !utf8.Valid(body) {
+		return errMalformedUTF8
+	}
+	if limit := limits.CollectionSize(r.Context()); limit > 0 {
+		if err := checkCollections(body, limit); err != nil {
+			return err
+		}
+	}
+	if err := codec.NewDecoder(bytes.NewReader(body)).Decode(v); err != nil {
+		return err
+	}
+	sanitizeValue(reflect.ValueOf(v))
+	return nil
+}
+
+// malformedBody writes the response for a request body decodeJSON failed
+// on: 400 Bad Request, or 408 Request Timeout when it arrived too slowly
+func malformedBody(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, httpserver.ErrSlowClient) {
+		status = http.StatusRequestTimeout
+	}
+	render.Status(r, status)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   bodyErrorMessage(err),
+		Timestamp: time.Now(),
+	})
+}
+
+// bodyErrorMessage describes a decodeJSON failure to the client
+func bodyErrorMessage(err error) string {
+	var tooLarge *collectionTooLargeError
+	switch {
+	case errors.As(err, &tooLarge):
+		return fmt.Sprintf("Invalid request body: arrays may contain at most %d items", tooLarge.limit)
+	case errors.Is(err, errMalformedUTF8):
+		return "Invalid request body: not valid UTF-8"
+	case errors.Is(err, httpserver.ErrSlowClient):
+		return "Request body was sent too slowly"
+	default:
+		return "Invalid request body"
+	}
+}
+
+// checkCollections scans body and rejects it when any array holds more
+// than limit items. Syntax errors are left to the decoder.
+func checkCollections(body []byte, limit int) error {
+	// counts holds the item count of each enclosing array, or -1 for objects
+	var counts []int
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		if n := len(counts); n > 0 && counts[n-1] >= 0 && token != json.Delim(']') {
+			counts[n-1]++
+			if counts[n-1] > limit {
+				return &collectionTooLargeError{limit: limit}
+			}
+		}
+		switch token {
+		case json.Delim('['):
+			counts = append(counts, 0)
+		case json.Delim('{'):
+			counts = append(counts, -1)
+		case json.Delim(']'), json.Delim('}'):
+			counts = counts[:len(counts)-1]
+		}
+	}
+}
+
+// sanitizeString normalizes s to NFC and strips control characters and
+// bidirectional formatting characters
+func sanitizeString(s string) string {
+	s = norm.NFC.String(s)
+	if strings.IndexFunc(s, isStripped) < 0 {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if isStripped(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isStripped reports whether r is removed from bound strings
+func isStripped(r rune) bool {
+	if unicode.IsControl(r) {
+		return true
+	}
+	// Bidirectional embeddings, overrides and isolates can make text
+	// display differently from how it is stored
+	return (r >= '\u202a' && r <= '\u202e') || (r >= '\u2066' && r <= '\u2069')
+}
+
+// sanitizeValue sanitizes the strings reachable from v in place
+func sanitizeValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			sanitizeValue(v.Elem())
+		}
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		// Values held by an interface are not addressable, so sanitize a
+		// copy and store it back
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		sanitizeValue(elem)
+		v.Set(elem)
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(sanitizeString(v.String()))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				sanitizeValue(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			sanitizeValue(v.Index(i))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		// Keys may change too, so collect the sanitized entries before
+		// replacing the old ones
+		type entry struct{ oldKey, key, value reflect.Value }
+		entries := make([]entry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			e := entry{
+				oldKey: iter.Key(),
+				key:    reflect.New(iter.Key().Type()).Elem(),
+				value:  reflect.New(iter.Value().Type()).Elem(),
+			}
+			e.key.Set(iter.Key())
+			e.value.Set(iter.Value())
+			sanitizeValue(e.key)
+			sanitizeValue(e.value)
+			entries = append(entries, e)
+		}
+		for _, e := range entries {
+			v.SetMapIndex(e.oldKey, reflect.Value{})
+		}
+		for _, e := range entries {
+			v.SetMapIndex(e.key, e.value)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/interfaces/http/middleware/limits"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/httpserver"
+	"clean-architecture/pkg/logger"
+)
+
+func TestDecodeJSON_SanitizesStrings(t *testing.T) {
+	var req struct {
+		Name  string                 `json:"name"`
+		Tags  []string               `json:"tags"`
+		Extra map[string]interface{} `json:"extra"`
+		Note  *string                `json:"note"`
+	}
+	body := `{
+		"name": "Ada\nINFO forged log line\u0000",
+		"tags": ["cafe\u0301", "evil\u202egnp.exe"],
+		"extra": {"k\u0007ey": "va\rlue", "nested": ["a\u2066b"]},
+		"note": "tab\there"
+	}`
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+
+	require.NoError(t, decodeJSON(r, &req))
+	assert.Equal(t, "AdaINFO forged log line", req.Name)
+	assert.Equal(t, []string{"caf\u00e9", "evilgnp.exe"}, req.Tags)
+	assert.Equal(t, "value", req.Extra["key"])
+	assert.Equal(t, []interface{}{"ab"}, req.Extra["nested"])
+	assert.NotContains(t, req.Extra, "k\u0007ey")
+	require.NotNil(t, req.Note)
+	assert.Equal(t, "tabhere", *req.Note)
+}
+
+func TestDecodeJSON_RejectsMalformedUTF8(t *testing.T) {
+	var req CreateUserRequest
+	r := httptest.NewRequest("POST", "/", strings.NewReader("{\"name\": \"\xff\xfe\"}"))
+
+	err := decodeJSON(r, &req)
+	assert.ErrorIs(t, err, errMalformedUTF8)
+}
+
+func TestDecodeJSON_EmptyBody(t *testing.T) {
+	var req CreateUserRequest
+	r := httptest.NewRequest("POST", "/", strings.NewReader(""))
+
+	err := decodeJSON(r, &req)
+	assert.True(t, errors.Is(err, io.EOF))
+}
+
+func TestDecodeJSON_LimitsCollections(t *testing.T) {
+	decode := func(body string) error {
+		var req map[string]interface{}
+		r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		var err error
+		limits.MaxCollectionSize(3)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			err = decodeJSON(r, &req)
+		})).ServeHTTP(httptest.NewRecorder(), r)
+		return err
+	}
+
+	assert.NoError(t, decode(`{"ids": ["a", "b", "c"], "nested": [[1, 2, 3], {"x": [4, 5, 6]}]}`))
+	assert.NoError(t, decode(`{"a": 1, "b": 2, "c": 3, "d": 4}`), "objects are not collections")
+
+	for _, body := range []string{
+		`{"ids": ["a", "b", "c", "d"]}`,
+		`{"nested": [[1, 2, 3, 4]]}`,
+		`{"objects": [{}, {}, {}, {}]}`,
+	} {
+		err := decode(body)
+		var tooLarge *collectionTooLargeError
+		assert.ErrorAs(t, err, &tooLarge, body)
+		assert.Equal(t, "Invalid request body: arrays may contain at most 3 items", bodyErrorMessage(err))
+	}
+}
+
+func TestMalformedBody(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{name: "syntax error", err: errors.New("unexpected EOF"), expectedStatus: http.StatusBadRequest, expectedMessage: "Invalid request body"},
+		{name: "malformed UTF-8", err: errMalformedUTF8, expectedStatus: http.StatusBadRequest, expectedMessage: "Invalid request body: not valid UTF-8"},
+		{name: "slow client", err: httpserver.ErrSlowClient, expectedStatus: http.StatusRequestTimeout, expectedMessage: "Request body was sent too slowly"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			malformedBody(w, httptest.NewRequest("POST", "/", nil), tt.err)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedMessage)
+		})
+	}
+}
+
+func TestMalformedBodyAndValidationErrors(t *testing.T) {
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("CreateUser", mock.Anything, "", "").Return(nil, usecase.ErrEmailRequired)
+	handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
+
+	w := httptest.NewRecorder()
+	handler.CreateUser(w, httptest.NewRequest("POST", "/users", strings.NewReader(`{"email":`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUseCase.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything, mock.Anything)
+
+	w = httptest.NewRecorder()
+	handler.CreateUser(w, httptest.NewRequest("POST", "/users", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), usecase.ErrEmailRequired.Error())
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"clean-architecture/pkg/capabilities"
+)
+
+// CapabilitiesHandler reports which optional subsystems are enabled
+type CapabilitiesHandler struct {
+	registry *capabilities.Registry
+	version  string
+}
+
+// NewCapabilitiesHandler creates a new capabilities handler for the given
+// API version
+func NewCapabilitiesHandler(registry *capabilities.Registry, version string) *CapabilitiesHandler {
+	return &CapabilitiesHandler{registry: registry, version: version}
+}
+
+// CapabilitiesDTO is the API representation of a deployment's capabilities
+type CapabilitiesDTO struct {
+	Version      string                             `json:"version"`
+	Capabilities map[string]capabilities.Capability `json:"capabilities"`
+}
+
+// GetCapabilities godoc
+// @Summary      Get deployment capabilities
+// @Description  List the optional subsystems enabled in this deployment so clients can feature-detect
+// @Tags         meta
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Router       /api/v1/capabilities [get]
+func (h *CapabilitiesHandler) GetCapabilities(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, Response{
+		Status:  "success",
+		Message: "Capabilities retrieved successfully",
+		Data: CapabilitiesDTO{
+			Version:      h.version,
+			Capabilities: h.registry.All(),
+		},
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/capabilities"
+)
+
+func TestCapabilitiesHandler_GetCapabilities(t *testing.T) {
+	registry := capabilities.NewRegistry()
+	registry.Register("authorization", true, map[string]interface{}{"driver": "builtin"})
+	registry.Register("webhooks", false, nil)
+
+	handler := NewCapabilitiesHandler(registry, "1.1.0")
+	w := httptest.NewRecorder()
+	handler.GetCapabilities(w, httptest.NewRequest("GET", "/api/v1/capabilities", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Status string          `json:"status"`
+		Data   CapabilitiesDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "success", body.Status)
+	assert.Equal(t, "1.1.0", body.Data.Version)
+	require.Contains(t, body.Data.Capabilities, "authorization")
+	assert.True(t, body.Data.Capabilities["authorization"].Enabled)
+	assert.Equal(t, "builtin", body.Data.Capabilities["authorization"].Details["driver"])
+	require.Contains(t, body.Data.Capabilities, "webhooks")
+	assert.False(t, body.Data.Capabilities["webhooks"].Enabled)
+}
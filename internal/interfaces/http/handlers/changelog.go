@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"clean-architecture/pkg/changelog"
+)
+
+// ChangelogHandler serves the machine-readable API changelog
+type ChangelogHandler struct {
+	changelog *changelog.Changelog
+}
+
+// NewChangelogHandler creates a new changelog handler
+func NewChangelogHandler(changelog *changelog.Changelog) *ChangelogHandler {
+	return &ChangelogHandler{changelog: changelog}
+}
+
+// GetChangelog godoc
+// @Summary      Get the API changelog
+// @Description  List API releases with their changes and pending deprecations, newest first
+// @Tags         meta
+// @Produce      json
+// @Param        since  query     string  false  "Only include releases newer than this version"
+// @Success      200    {object}  SuccessResponse
+// @Failure      400    {object}  ErrorResponse
+// @Router       /api/v1/changelog [get]
+func (h *ChangelogHandler) GetChangelog(w http.ResponseWriter, r *http.Request) {
+	result := h.changelog
+	if since := r.URL.Query().Get("since"); since != "" {
+		filtered, err := h.changelog.Since(since)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			respondJSON(w, r, Response{
+				Status:    "error",
+				Message:   "Invalid since version, expected MAJOR.MINOR.PATCH",
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		result = filtered
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Changelog retrieved successfully",
+		Data:      result,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/changelog"
+)
+
+func TestChangelogHandler_GetChangelog(t *testing.T) {
+	apiChangelog, err := changelog.Parse([]byte(`{
+		"current_version": "1.1.0",
+		"releases": [
+			{"version": "1.1.0", "date": "2024-02-01", "changes": [{"type": "added", "description": "New", "breaking": false}]},
+			{"version": "1.0.0", "date": "2024-01-01", "changes": [{"type": "added", "description": "Initial", "breaking": false}]}
+		]
+	}`))
+	require.NoError(t, err)
+	handler := NewChangelogHandler(apiChangelog)
+
+	tests := []struct {
+		name             string
+		query            string
+		expectedCode     int
+		expectedStatus   string
+		expectedReleases []string
+	}{
+		{
+			name:             "full changelog",
+			expectedCode:     http.StatusOK,
+			expectedStatus:   "success",
+			expectedReleases: []string{"1.1.0", "1.0.0"},
+		},
+		{
+			name:             "since version",
+			query:            "?since=1.0.0",
+			expectedCode:     http.StatusOK,
+			expectedStatus:   "success",
+			expectedReleases: []string{"1.1.0"},
+		},
+		{
+			name:           "invalid since version",
+			query:          "?since=latest",
+			expectedCode:   http.StatusBadRequest,
+			expectedStatus: "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.GetChangelog(w, httptest.NewRequest("GET", "/api/v1/changelog"+tt.query, nil))
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			var body struct {
+				Status string               `json:"status"`
+				Data   *changelog.Changelog `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedStatus, body.Status)
+
+			if tt.expectedReleases != nil {
+				require.NotNil(t, body.Data)
+				versions := make([]string, 0, len(body.Data.Releases))
+				for _, release := range body.Data.Releases {
+					versions = append(versions, release.Version)
+				}
+				assert.Equal(t, tt.expectedReleases, versions)
+				assert.NotNil(t, body.Data.Deprecations)
+			}
+		})
+	}
+}
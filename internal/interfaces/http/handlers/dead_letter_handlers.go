@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// maxBulkReplay bounds how many dead letters a filter-based replay touches
+const maxBulkReplay = 500
+
+// DeadLetterHandler handles dead letter inspection and replay requests
+type DeadLetterHandler struct {
+	deadLetterUseCase usecase.DeadLetterUseCaseInterface
+	defaults          APIDefaults
+	logger            logger.Logger
+}
+
+// NewDeadLetterHandler creates a new dead letter handler
+func NewDeadLetterHandler(deadLetterUseCase usecase.DeadLetterUseCaseInterface, logger logger.Logger) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		deadLetterUseCase: deadLetterUseCase,
+		defaults:          DefaultAPIDefaults(),
+		logger:            logger,
+	}
+}
+
+// WithDefaults replaces the page size of dead letter listings
+func (h *DeadLetterHandler) WithDefaults(defaults APIDefaults) *DeadLetterHandler {
+	h.defaults = defaults
+	return h
+}
+
+// DeadLetterDTO is the API representation of a dead letter. JSON payloads
+// are embedded as-is; anything else is returned as a string.
+type DeadLetterDTO struct {
+	ID            string            `json:"id"`
+	Kind          string            `json:"kind"`
+	Topic         string            `json:"topic"`
+	ConsumerGroup string            `json:"consumer_group"`
+	MessageID     string            `json:"message_id"`
+	MessageKey    string            `json:"message_key,omitempty"`
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	Payload       json.RawMessage   `json:"payload"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Error         string            `json:"error"`
+	Attempts      int               `json:"attempts"`
+	FailedAt      time.Time         `json:"failed_at"`
+	ReplayCount   int               `json:"replay_count"`
+	ReplayedAt    *time.Time        `json:"replayed_at,omitempty"`
+}
+
+// ReplayDeadLettersRequest represents the request body for a bulk replay.
+// When IDs is empty every pending dead letter matching the filter is replayed.
+type ReplayDeadLettersRequest struct {
+	IDs           []string `json:"ids,omitempty"`
+	Kind          string   `json:"kind,omitempty"`
+	Topic         string   `json:"topic,omitempty"`
+	ConsumerGroup string   `json:"consumer_group,omitempty"`
+}
+
+// ListDeadLetters handles listing dead letters, filtered by the kind, topic,
+// consumer_group, correlation_id and pending query parameters
+func (h *DeadLetterHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repositories.DeadLetterFilter{
+		Kind:          query.Get("kind"),
+		Topic:         query.Get("topic"),
+		ConsumerGroup: query.Get("consumer_group"),
+		CorrelationID: query.Get("correlation_id"),
+		Pending:       query.Get("pending") == "true",
+	}
+	var warnings []Warning
+	filter.Limit, filter.Offset, warnings = pagination(query, h.defaults.AdminPageSize, 0)
+
+	fields, ok := requestFields(w, r, DeadLetterDTO{})
+	if !ok {
+		return
+	}
+
+	deadLetters, err := h.deadLetterUseCase.ListDeadLetters(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]DeadLetterDTO, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		dtos = append(dtos, presentDeadLetter(deadLetter))
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Dead letters retrieved successfully",
+		Data:      fields.apply(dtos),
+		Warnings:  warnings,
+		Meta:      listMeta(filter.Limit, filter.Offset, len(dtos)),
+		Timestamp: time.Now(),
+	})
+}
+
+// GetDeadLetter handles retrieving a dead letter with its failure reason
+func (h *DeadLetterHandler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	fields, ok := requestFields(w, r, DeadLetterDTO{})
+	if !ok {
+		return
+	}
+
+	deadLetter, err := h.deadLetterUseCase.GetDeadLetter(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Dead letter retrieved successfully",
+		Data:      fields.apply(presentDeadLetter(deadLetter)),
+		Timestamp: time.Now(),
+	})
+}
+
+// ReplayDeadLetter handles replaying a single dead letter
+func (h *DeadLetterHandler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	deadLetter, err := h.deadLetterUseCase.ReplayDeadLetter(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Dead letter replayed successfully",
+		Data:      presentDeadLetter(deadLetter),
+		Timestamp: time.Now(),
+	})
+}
+
+// ReplayDeadLetters handles replaying dead letters in bulk
+func (h *DeadLetterHandler) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	// An empty body replays every pending dead letter
+	var req ReplayDeadLettersRequest
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		malformedBody(w, r, err)
+		return
+	}
+
+	result, err := h.deadLetterUseCase.ReplayDeadLetters(r.Context(), req.IDs, repositories.DeadLetterFilter{
+		Kind:          req.Kind,
+		Topic:         req.Topic,
+		ConsumerGroup: req.ConsumerGroup,
+		Pending:       true,
+		Limit:         maxBulkReplay,
+	})
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Dead letters replayed",
+		Data:      result,
+		Timestamp: time.Now(),
+	})
+}
+
+// presentDeadLetter converts a dead letter entity into its API representation
+func presentDeadLetter(deadLetter *entities.DeadLetter) DeadLetterDTO {
+	payload := json.RawMessage(deadLetter.Payload)
+	if !json.Valid(payload) {
+		payload, _ = json.Marshal(string(deadLetter.Payload))
+	}
+
+	return DeadLetterDTO{
+		ID:            deadLetter.ID,
+		Kind:          deadLetter.Kind,
+		Topic:         deadLetter.Topic,
+		ConsumerGroup: deadLetter.ConsumerGroup,
+		MessageID:     deadLetter.MessageID,
+		MessageKey:    deadLetter.MessageKey,
+		CorrelationID: deadLetter.CorrelationID,
+		Payload:       payload,
+		Headers:       deadLetter.Headers,
+		Error:         deadLetter.Error,
+		Attempts:      deadLetter.Attempts,
+		FailedAt:      deadLetter.FailedAt,
+		ReplayCount:   deadLetter.ReplayCount,
+		ReplayedAt:    deadLetter.ReplayedAt,
+	}
+}
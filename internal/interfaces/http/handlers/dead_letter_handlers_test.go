@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockDeadLetterUseCase is a mock implementation of DeadLetterUseCaseInterface
+type MockDeadLetterUseCase struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterUseCase) ListDeadLetters(ctx context.Context, filter repositories.DeadLetterFilter) ([]*entities.DeadLetter, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterUseCase) GetDeadLetter(ctx context.Context, id string) (*entities.DeadLetter, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterUseCase) ReplayDeadLetter(ctx context.Context, id string) (*entities.DeadLetter, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterUseCase) ReplayDeadLetters(ctx context.Context, ids []string, filter repositories.DeadLetterFilter) (*usecase.ReplayResult, error) {
+	args := m.Called(ctx, ids, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.ReplayResult), args.Error(1)
+}
+
+func newDeadLetterRouter(uc usecase.DeadLetterUseCaseInterface) http.Handler {
+	handler := NewDeadLetterHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Get("/dlq", handler.ListDeadLetters)
+	r.Post("/dlq/replay", handler.ReplayDeadLetters)
+	r.Get("/dlq/{id}", handler.GetDeadLetter)
+	r.Post("/dlq/{id}/replay", handler.ReplayDeadLetter)
+	return r
+}
+
+func TestDeadLetterHandler_ListDeadLetters(t *testing.T) {
+	mockUseCase := new(MockDeadLetterUseCase)
+	mockUseCase.On("ListDeadLetters", mock.Anything, repositories.DeadLetterFilter{
+		Topic:   "user.created",
+		Pending: true,
+		Limit:   20,
+	}).Return([]*entities.DeadLetter{{
+		ID:       "dlq_1",
+		Topic:    "user.created",
+		Payload:  []byte(`{"id":"evt_1"}`),
+		Error:    "timeout",
+		FailedAt: time.Now(),
+	}, {
+		ID:      "dlq_2",
+		Topic:   "user.created",
+		Payload: []byte("not json"),
+	}}, nil)
+
+	w := httptest.NewRecorder()
+	newDeadLetterRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/dlq?topic=user.created&pending=true&limit=20", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Data, 2)
+	assert.Equal(t, map[string]interface{}{"id": "evt_1"}, body.Data[0]["payload"])
+	assert.Equal(t, "timeout", body.Data[0]["error"])
+	assert.Equal(t, "not json", body.Data[1]["payload"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestDeadLetterHandler_GetDeadLetter_NotFound(t *testing.T) {
+	mockUseCase := new(MockDeadLetterUseCase)
+	mockUseCase.On("GetDeadLetter", mock.Anything, "missing").Return(nil, repositories.ErrDeadLetterNotFound)
+
+	w := httptest.NewRecorder()
+	newDeadLetterRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/dlq/missing", nil))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "error", body["status"])
+	assert.Equal(t, repositories.ErrDeadLetterNotFound.Error(), body["message"])
+}
+
+func TestDeadLetterHandler_ReplayDeadLetter(t *testing.T) {
+	mockUseCase := new(MockDeadLetterUseCase)
+	mockUseCase.On("ReplayDeadLetter", mock.Anything, "dlq_1").Return(&entities.DeadLetter{
+		ID:          "dlq_1",
+		Payload:     []byte("{}"),
+		ReplayCount: 1,
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newDeadLetterRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("POST", "/dlq/dlq_1/replay", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, float64(1), body.Data["replay_count"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestDeadLetterHandler_ReplayDeadLetters(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		expectedIDs []string
+		filter      repositories.DeadLetterFilter
+	}{
+		{
+			name:        "explicit ids",
+			body:        `{"ids":["dlq_1","dlq_2"]}`,
+			expectedIDs: []string{"dlq_1", "dlq_2"},
+			filter:      repositories.DeadLetterFilter{Pending: true, Limit: maxBulkReplay},
+		},
+		{
+			name:   "by consumer group",
+			body:   `{"consumer_group":"webhooks"}`,
+			filter: repositories.DeadLetterFilter{ConsumerGroup: "webhooks", Pending: true, Limit: maxBulkReplay},
+		},
+		{
+			name:   "empty body replays everything pending",
+			filter: repositories.DeadLetterFilter{Pending: true, Limit: maxBulkReplay},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockDeadLetterUseCase)
+			mockUseCase.On("ReplayDeadLetters", mock.Anything, tt.expectedIDs, tt.filter).
+				Return(&usecase.ReplayResult{Replayed: []string{"dlq_1"}, Failed: []usecase.ReplayFailure{}}, nil)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/dlq/replay", bytes.NewBufferString(tt.body))
+			newDeadLetterRouter(mockUseCase).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
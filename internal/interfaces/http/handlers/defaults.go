@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// APIDefaults are the tunable defaults and bounds of API requests
+type APIDefaults struct {
+	// PageSize is the limit of list requests that give none, and
+	// MaxPageSize the largest limit accepted; 0 accepts any
+	PageSize    int
+	MaxPageSize int
+	// AdminPageSize is the default limit of lists on the admin listener,
+	// which are not capped
+	AdminPageSize int
+
+	MaxFilters           int
+	MaxFilterValueLength int
+	MaxFilterInValues    int
+	MaxSortFields        int
+
+	// HealthCheckTimeout bounds how long a single readiness check may take
+	HealthCheckTimeout time.Duration
+}
+
+// DefaultAPIDefaults returns the defaults used unless configured otherwise
+func DefaultAPIDefaults() APIDefaults {
+	return APIDefaults{
+		PageSize:             10,
+		AdminPageSize:        50,
+		MaxFilters:           10,
+		MaxFilterValueLength: 256,
+		MaxFilterInValues:    50,
+		MaxSortFields:        3,
+		HealthCheckTimeout:   2 * time.Second,
+	}
+}
+
+// pagination reads the limit and offset parameters of query. Missing values
+// fall back to pageSize and 0, invalid ones too with a warning, and limits
+// above maxPageSize are lowered to it with a warning unless it is 0.
+func pagination(query url.Values, pageSize, maxPageSize int) (limit, offset int, warnings []Warning) {
+	limit = pageSize
+	if value := query.Get("limit"); value != "" {
+		if l, err := strconv.Atoi(value); err == nil && l > 0 {
+			limit = l
+		} else {
+			warnings = append(warnings, Warning{
+				Code:      WarningParameterIgnored,
+				Parameter: "limit",
+				Message:   fmt.Sprintf("limit %q is not a positive integer; the default of %d is used", value, pageSize),
+			})
+		}
+	}
+	if maxPageSize > 0 && limit > maxPageSize {
+		warnings = append(warnings, Warning{
+			Code:      WarningParameterClamped,
+			Parameter: "limit",
+			Message:   fmt.Sprintf("limit %d exceeds the maximum of %d; at most %d items are returned", limit, maxPageSize, maxPageSize),
+		})
+		limit = maxPageSize
+	}
+	if value := query.Get("offset"); value != "" {
+		if o, err := strconv.Atoi(value); err == nil && o >= 0 {
+			offset = o
+		} else {
+			warnings = append(warnings, Warning{
+				Code:      WarningParameterIgnored,
+				Parameter: "offset",
+				Message:   fmt.Sprintf("offset %q is not a non-negative integer; 0 is used", value),
+			})
+		}
+	}
+	return limit, offset, warnings
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/pkg/logger"
+)
+
+func TestPagination(t *testing.T) {
+	tests := []struct {
+		query            string
+		maxPageSize      int
+		expectedLimit    int
+		expectedOffset   int
+		expectedWarnings []Warning
+	}{
+		{query: "", expectedLimit: 10},
+		{query: "limit=25&offset=50", expectedLimit: 25, expectedOffset: 50},
+		{query: "limit=-1&offset=x", expectedLimit: 10, expectedWarnings: []Warning{
+			{Code: WarningParameterIgnored, Parameter: "limit", Message: `limit "-1" is not a positive integer; the default of 10 is used`},
+			{Code: WarningParameterIgnored, Parameter: "offset", Message: `offset "x" is not a non-negative integer; 0 is used`},
+		}},
+		{query: "limit=500", maxPageSize: 100, expectedLimit: 100, expectedWarnings: []Warning{
+			{Code: WarningParameterClamped, Parameter: "limit", Message: "limit 500 exceeds the maximum of 100; at most 100 items are returned"},
+		}},
+		{query: "limit=500", expectedLimit: 500},
+	}
+
+	for _, tt := range tests {
+		query, err := url.ParseQuery(tt.query)
+		require.NoError(t, err)
+
+		limit, offset, warnings := pagination(query, 10, tt.maxPageSize)
+		assert.Equal(t, tt.expectedLimit, limit, tt.query)
+		assert.Equal(t, tt.expectedOffset, offset, tt.query)
+		assert.Equal(t, tt.expectedWarnings, warnings, tt.query)
+	}
+}
+
+func TestUserHandler_ListUsers_Defaults(t *testing.T) {
+	defaults := DefaultAPIDefaults()
+	defaults.PageSize = 25
+	defaults.MaxPageSize = 50
+	defaults.MaxFilters = 1
+
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("ListUsers", mock.Anything, 25, 0).Return([]*entities.User{}, nil)
+	mockUseCase.On("ListUsers", mock.Anything, 50, 0).Return([]*entities.User{}, nil)
+	handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New()).WithDefaults(defaults)
+
+	w := httptest.NewRecorder()
+	handler.ListUsers(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	assert.NotContains(t, w.Body.String(), "warnings")
+
+	w = httptest.NewRecorder()
+	handler.ListUsers(w, httptest.NewRequest(http.MethodGet, "/users?limit=1000", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Warnings []Warning `json:"warnings"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []Warning{{
+		Code:      WarningParameterClamped,
+		Parameter: "limit",
+		Message:   "limit 1000 exceeds the maximum of 50; at most 50 items are returned",
+	}}, body.Warnings)
+
+	w = httptest.NewRecorder()
+	handler.ListUsers(w, httptest.NewRequest(http.MethodGet, "/users?filter[name]=a&filter[email]=b", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "at most 1 filters are allowed")
+
+	mockUseCase.AssertExpectations(t)
+}
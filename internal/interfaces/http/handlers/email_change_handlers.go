@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+)
+
+// ConfirmEmailChangeRequest represents the request body for confirming an
+// email change with the token from a confirmation link
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token"`
+}
+
+// ConfirmEmailChange godoc
+// @Summary      Confirm an email change
+// @Description  Apply a pending email change with the token from the confirmation email sent to the new address. No authentication is required.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      ConfirmEmailChangeRequest  true  "Confirmation token"
+// @Success      200      {object}  UserResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      410      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Router       /api/v1/email-changes/confirm [post]
+func (h *UserHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var req ConfirmEmailChangeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if req.Token == "" {
+		unprocessable(w, r, "token_required", "token is required")
+		return
+	}
+
+	user, err := h.userUseCase.ConfirmEmailChange(r.Context(), req.Token)
+	if err != nil {
+		h.emailChangeError(w, r, err)
+		return
+	}
+	setConsistencyToken(w, repositories.UserResource, user.ID)
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Email changed successfully",
+		Data:      h.presenter.Present(r.Context(), user),
+		Timestamp: time.Now(),
+	})
+}
+
+// emailChangeError writes the response for a failed email change
+// confirmation
+func (h *UserHandler) emailChangeError(w http.ResponseWriter, r *http.Request, err error) {
+	var status int
+	var message string
+	switch {
+	case errors.Is(err, usecase.ErrEmailChangeExpired):
+		status, message = http.StatusGone, usecase.ErrEmailChangeExpired.Error()
+	default:
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, status)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
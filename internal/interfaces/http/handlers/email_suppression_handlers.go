@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// ActionReadUserEmailSuppression guards whether a user's address is
+// suppressed
+const ActionReadUserEmailSuppression = "users:read_email_suppression"
+
+// EmailSuppressionHandler handles the email provider's bounce and
+// complaint webhooks and administrators inspecting and lifting
+// suppressions
+type EmailSuppressionHandler struct {
+	suppressionUseCase usecase.EmailSuppressionUseCaseInterface
+	// signatureHeader names the header the provider signs webhooks in
+	signatureHeader string
+	defaults        APIDefaults
+	logger          logger.Logger
+}
+
+// NewEmailSuppressionHandler creates a new email suppression handler
+// reading webhook signatures from signatureHeader
+func NewEmailSuppressionHandler(suppressionUseCase usecase.EmailSuppressionUseCaseInterface, signatureHeader string, logger logger.Logger) *EmailSuppressionHandler {
+	return &EmailSuppressionHandler{
+		suppressionUseCase: suppressionUseCase,
+		signatureHeader:    signatureHeader,
+		defaults:           DefaultAPIDefaults(),
+		logger:             logger,
+	}
+}
+
+// WithDefaults replaces the page sizes suppressions are listed with
+func (h *EmailSuppressionHandler) WithDefaults(defaults APIDefaults) *EmailSuppressionHandler {
+	h.defaults = defaults
+	return h
+}
+
+// EmailSuppressionDTO is the API representation of a suppressed address
+type EmailSuppressionDTO struct {
+	Email      string    `json:"email"`
+	Reason     string    `json:"reason"`
+	Detail     string    `json:"detail,omitempty"`
+	Provider   string    `json:"provider"`
+	FeedbackID string    `json:"feedback_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ReceiveFeedback godoc
+// @Summary      Receive an email feedback webhook
+// @Description  Endpoint for the email provider's bounce and complaint reports, authenticated by their signature. Complaints and permanent bounces suppress the address; other events are acknowledged without processing.
+// @Tags         email
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/email/feedback [post]
+func (h *EmailSuppressionHandler) ReceiveFeedback(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	var signature string
+	if h.signatureHeader != "" {
+		signature = r.Header.Get(h.signatureHeader)
+	}
+	if err := h.suppressionUseCase.HandleFeedback(r.Context(), payload, signature); err != nil {
+		h.suppressionError(w, r, err)
+		return
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Feedback processed",
+		Timestamp: time.Now(),
+	})
+}
+
+// ListSuppressions godoc
+// @Summary      List email suppressions
+// @Description  List the addresses no email is sent to, most recently suppressed first
+// @Tags         email
+// @Produce      json
+// @Param        limit   query     int  false  "Page size"
+// @Param        offset  query     int  false  "Page offset"
+// @Success      200     {object}  SuccessResponse
+// @Failure      401     {object}  ErrorResponse
+// @Failure      403     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /api/v1/email-suppressions [get]
+func (h *EmailSuppressionHandler) ListSuppressions(w http.ResponseWriter, r *http.Request) {
+	limit, offset, warnings := pagination(r.URL.Query(), h.defaults.PageSize, h.defaults.MaxPageSize)
+
+	suppressions, err := h.suppressionUseCase.ListSuppressions(r.Context(), limit, offset)
+	if err != nil {
+		h.suppressionError(w, r, err)
+		return
+	}
+
+	dtos := make([]EmailSuppressionDTO, 0, len(suppressions))
+	for _, suppression := range suppressions {
+		dtos = append(dtos, presentEmailSuppression(suppression))
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Email suppressions retrieved successfully",
+		Data:      dtos,
+		Warnings:  warnings,
+		Meta:      listMeta(limit, offset, len(dtos)),
+		Timestamp: time.Now(),
+	})
+}
+
+// GetSuppression godoc
+// @Summary      Get an email suppression
+// @Description  Get why an address is suppressed
+// @Tags         email
+// @Produce      json
+// @Param        email  path      string  true  "Email address"
+// @Success      200    {object}  SuccessResponse
+// @Failure      401    {object}  ErrorResponse
+// @Failure      403    {object}  ErrorResponse
+// @Failure      404    {object}  ErrorResponse
+// @Failure      500    {object}  ErrorResponse
+// @Router       /api/v1/email-suppressions/{email} [get]
+func (h *EmailSuppressionHandler) GetSuppression(w http.ResponseWriter, r *http.Request) {
+	suppression, err := h.suppressionUseCase.GetSuppression(r.Context(), emailParam(r))
+	if err != nil {
+		h.suppressionError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Email suppression retrieved successfully",
+		Data:      presentEmailSuppression(suppression),
+		Timestamp: time.Now(),
+	})
+}
+
+// LiftSuppression godoc
+// @Summary      Lift an email suppression
+// @Description  Let email be sent to a suppressed address again, such as after its owner fixed their mailbox. Addresses that bounce again are suppressed again.
+// @Tags         email
+// @Produce      json
+// @Param        email  path      string  true  "Email address"
+// @Success      200    {object}  SuccessResponse
+// @Failure      401    {object}  ErrorResponse
+// @Failure      403    {object}  ErrorResponse
+// @Failure      404    {object}  ErrorResponse
+// @Failure      500    {object}  ErrorResponse
+// @Router       /api/v1/email-suppressions/{email} [delete]
+func (h *EmailSuppressionHandler) LiftSuppression(w http.ResponseWriter, r *http.Request) {
+	if err := h.suppressionUseCase.LiftSuppression(r.Context(), emailParam(r)); err != nil {
+		h.suppressionError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Email suppression lifted",
+		Timestamp: time.Now(),
+	})
+}
+
+// EmailSuppressionLoader loads the suppressions of users' addresses for
+// the email_suppression include
+func EmailSuppressionLoader(suppressionUseCase usecase.EmailSuppressionUseCaseInterface) IncludeLoader {
+	return func(ctx context.Context, userIDs []string) (map[string]interface{}, error) {
+		suppressions, err := suppressionUseCase.GetUserSuppressions(ctx, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		loaded := make(map[string]interface{}, len(suppressions))
+		for userID, suppression := range suppressions {
+			loaded[userID] = presentEmailSuppression(suppression)
+		}
+		return loaded, nil
+	}
+}
+
+// suppressionError writes the response for a failed webhook or suppression
+// lookup
+func (h *EmailSuppressionHandler) suppressionError(w http.ResponseWriter, r *http.Request, err error) {
+	var status int
+	var message string
+	switch {
+	case errors.Is(err, email.ErrInvalidSignature):
+		status, message = http.StatusUnauthorized, "Invalid webhook signature"
+	case errors.Is(err, usecase.ErrInvalidWebhook):
+		status, message = http.StatusBadRequest, "Invalid webhook payload"
+	default:
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, status)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
+
+// emailParam returns the {email} URL parameter, which clients may send
+// percent-encoded
+func emailParam(r *http.Request) string {
+	raw := chi.URLParam(r, "email")
+	if address, err := url.PathUnescape(raw); err == nil {
+		return address
+	}
+	return raw
+}
+
+func presentEmailSuppression(suppression *entities.EmailSuppression) EmailSuppressionDTO {
+	return EmailSuppressionDTO{
+		Email:      suppression.Email,
+		Reason:     suppression.Reason,
+		Detail:     suppression.Detail,
+		Provider:   suppression.Provider,
+		FeedbackID: suppression.FeedbackID,
+		CreatedAt:  suppression.CreatedAt,
+		UpdatedAt:  suppression.UpdatedAt,
+	}
+}
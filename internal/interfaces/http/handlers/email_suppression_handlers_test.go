@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockEmailSuppressionUseCase is a mock implementation of
+// EmailSuppressionUseCaseInterface
+type MockEmailSuppressionUseCase struct {
+	mock.Mock
+}
+
+func (m *MockEmailSuppressionUseCase) HandleFeedback(ctx context.Context, payload []byte, signature string) error {
+	args := m.Called(ctx, payload, signature)
+	return args.Error(0)
+}
+
+func (m *MockEmailSuppressionUseCase) GetSuppression(ctx context.Context, address string) (*entities.EmailSuppression, error) {
+	args := m.Called(ctx, address)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.EmailSuppression), args.Error(1)
+}
+
+func (m *MockEmailSuppressionUseCase) GetUserSuppressions(ctx context.Context, userIDs []string) (map[string]*entities.EmailSuppression, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*entities.EmailSuppression), args.Error(1)
+}
+
+func (m *MockEmailSuppressionUseCase) ListSuppressions(ctx context.Context, limit, offset int) ([]*entities.EmailSuppression, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.EmailSuppression), args.Error(1)
+}
+
+func (m *MockEmailSuppressionUseCase) LiftSuppression(ctx context.Context, address string) error {
+	args := m.Called(ctx, address)
+	return args.Error(0)
+}
+
+func newEmailSuppressionRouter(uc usecase.EmailSuppressionUseCaseInterface) http.Handler {
+	handler := NewEmailSuppressionHandler(uc, "X-Email-Signature", logger.New())
+	r := chi.NewRouter()
+	r.Post("/email/feedback", handler.ReceiveFeedback)
+	r.Get("/email-suppressions", handler.ListSuppressions)
+	r.Get("/email-suppressions/{email}", handler.GetSuppression)
+	r.Delete("/email-suppressions/{email}", handler.LiftSuppression)
+	return r
+}
+
+func testEmailSuppression() *entities.EmailSuppression {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &entities.EmailSuppression{
+		Email:      "ada@example.com",
+		Reason:     entities.SuppressionBounce,
+		Detail:     "550 5.1.1 mailbox unknown",
+		Provider:   "mailgun",
+		FeedbackID: "mg_1",
+		CreatedAt:  at,
+		UpdatedAt:  at,
+	}
+}
+
+func TestEmailSuppressionHandler_ReceiveFeedback(t *testing.T) {
+	payload := `{"id":"fb_1"}`
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"processed", nil, http.StatusOK, "Feedback processed"},
+		{"invalid signature", email.ErrInvalidSignature, http.StatusUnauthorized, "Invalid webhook signature"},
+		{"invalid payload", usecase.ErrInvalidWebhook, http.StatusBadRequest, "Invalid webhook payload"},
+		{"store error", errors.New("connection refused"), http.StatusInternalServerError, internalErrorMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockEmailSuppressionUseCase)
+			mockUseCase.On("HandleFeedback", mock.Anything, []byte(payload), "sha256=abc").Return(tt.err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/email/feedback", bytes.NewBufferString(payload))
+			req.Header.Set("X-Email-Signature", "sha256=abc")
+			newEmailSuppressionRouter(mockUseCase).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEmailSuppressionHandler_ReceiveFeedback_SignedInBody(t *testing.T) {
+	mockUseCase := new(MockEmailSuppressionUseCase)
+	mockUseCase.On("HandleFeedback", mock.Anything, []byte(`{}`), "").Return(nil)
+	handler := NewEmailSuppressionHandler(mockUseCase, "", logger.New())
+
+	w := httptest.NewRecorder()
+	handler.ReceiveFeedback(w, httptest.NewRequest("POST", "/email/feedback", bytes.NewBufferString(`{}`)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestEmailSuppressionHandler_GetSuppression(t *testing.T) {
+	mockUseCase := new(MockEmailSuppressionUseCase)
+	mockUseCase.On("GetSuppression", mock.Anything, "ada+news@example.com").Return(testEmailSuppression(), nil)
+	mockUseCase.On("GetSuppression", mock.Anything, "grace@example.com").Return(nil, repositories.ErrEmailSuppressionNotFound)
+	router := newEmailSuppressionRouter(mockUseCase)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/email-suppressions/ada+news%40example.com", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"reason":"bounce"`)
+	assert.Contains(t, w.Body.String(), `"detail":"550 5.1.1 mailbox unknown"`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/email-suppressions/grace@example.com", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), repositories.ErrEmailSuppressionNotFound.Error())
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestEmailSuppressionHandler_ListSuppressions(t *testing.T) {
+	mockUseCase := new(MockEmailSuppressionUseCase)
+	mockUseCase.On("ListSuppressions", mock.Anything, 10, 20).Return([]*entities.EmailSuppression{testEmailSuppression()}, nil)
+
+	w := httptest.NewRecorder()
+	newEmailSuppressionRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/email-suppressions?limit=10&offset=20", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"email":"ada@example.com"`)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestEmailSuppressionHandler_LiftSuppression(t *testing.T) {
+	mockUseCase := new(MockEmailSuppressionUseCase)
+	mockUseCase.On("LiftSuppression", mock.Anything, "ada@example.com").Return(nil)
+	mockUseCase.On("LiftSuppression", mock.Anything, "grace@example.com").Return(repositories.ErrEmailSuppressionNotFound)
+	router := newEmailSuppressionRouter(mockUseCase)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/email-suppressions/ada@example.com", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Email suppression lifted")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/email-suppressions/grace@example.com", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestEmailSuppressionLoader(t *testing.T) {
+	mockUseCase := new(MockEmailSuppressionUseCase)
+	mockUseCase.On("GetUserSuppressions", mock.Anything, []string{"user_1", "user_2"}).
+		Return(map[string]*entities.EmailSuppression{"user_1": testEmailSuppression()}, nil)
+
+	loaded, err := EmailSuppressionLoader(mockUseCase)(context.Background(), []string{"user_1", "user_2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"user_1": presentEmailSuppression(testEmailSuppression())}, loaded)
+}
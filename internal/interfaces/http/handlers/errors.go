@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/apperrors"
+	"clean-architecture/pkg/httpserver"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/retryafter"
+)
+
+// internalErrorMessage is the only detail clients receive for unexpected errors
+const internalErrorMessage = "An internal error occurred"
+
+// kindStatuses are the statuses errors of each apperrors kind are answered
+// with. Errors of KindInternal are internal errors.
+var kindStatuses = map[apperrors.Kind]int{
+	apperrors.KindNotFound:   http.StatusNotFound,
+	apperrors.KindConflict:   http.StatusConflict,
+	apperrors.KindValidation: http.StatusUnprocessableEntity,
+}
+
+// clientErrors maps errors to the status they are answered with where it is
+// more specific than that of their kind
+var clientErrors = []struct {
+	err    error
+	status int
+}{
+	{usecase.ErrChunkTooLarge, http.StatusRequestEntityTooLarge},
+	{usecase.ErrChunkChecksumMismatch, http.StatusBadRequest},
+	{httpserver.ErrSlowClient, http.StatusRequestTimeout},
+}
+
+// unavailableMessage is the message of transient failures worth retrying
+const unavailableMessage = "Service is temporarily unavailable; try again later"
+
+// writeError writes an error response. Errors of an apperrors kind other
+// than KindInternal are returned with their code and the status of their
+// kind: 404 Not Found, 409 Conflict or 422 Unprocessable Entity. Exceeded
+// quotas are returned with 403 Forbidden and transient failures that carry
+// a retry delay with 503 Service Unavailable; anything else is treated as
+// an internal error.
+func writeError(w http.ResponseWriter, r *http.Request, log logger.Logger, err error) {
+	var quota *usecase.QuotaExceededError
+	if errors.As(err, &quota) {
+		render.Status(r, http.StatusForbidden)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   quota.Error(),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+	for _, clientErr := range clientErrors {
+		if errors.Is(err, clientErr.err) {
+			render.Status(r, clientErr.status)
+			respondJSON(w, r, Response{
+				Status:    "error",
+				Code:      apperrors.CodeOf(err),
+				Message:   clientErr.err.Error(),
+				Timestamp: time.Now(),
+			})
+			return
+		}
+	}
+	if appErr, ok := apperrors.As(err); ok {
+		if status, ok := kindStatuses[appErr.Kind]; ok {
+			render.Status(r, status)
+			respondJSON(w, r, Response{
+				Status:    "error",
+				Code:      appErr.Code,
+				Message:   appErr.Message,
+				Timestamp: time.Now(),
+			})
+			return
+		}
+	}
+
+	if _, ok := retryafter.Of(err); ok {
+		log.WithField("error", err.Error()).Warn("Request refused by an unavailable dependency")
+		errorStatus(w, r, http.StatusServiceUnavailable, err)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   unavailableMessage,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	InternalError(w, r, log, err)
+}
+
+// errorStatus sets the status of an error response caused by err. For 429
+// Too Many Requests and 503 Service Unavailable it also sets the
+// Retry-After header to the delay err carries, such as the end of a login
+// lockout; the router falls back to API_RETRY_AFTER for errors carrying
+// none.
+func errorStatus(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if retryafter.Retryable(status) {
+		if after, ok := retryafter.Of(err); ok {
+			retryafter.Set(w.Header(), after)
+		}
+	}
+	render.Status(r, status)
+}
+
+// InternalError logs err with a generated error ID and writes a 500 response
+// that only carries the ID, so internal details never reach the client
+func InternalError(w http.ResponseWriter, r *http.Request, log logger.Logger, err error) {
+	errorID := newErrorID()
+
+	log.WithFields(map[string]interface{}{
+		"error_id":   errorID,
+		"error":      err.Error(),
+		"request_id": middleware.GetReqID(r.Context()),
+		"method":     r.Method,
+		"path":       r.URL.Path,
+	}).Error("Unexpected error handling request")
+
+	render.Status(r, http.StatusInternalServerError)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   internalErrorMessage,
+		ErrorID:   errorID,
+		Timestamp: time.Now(),
+	})
+}
+
+// unprocessable writes a 422 Unprocessable Entity response with code for a
+// well-formed request whose content is invalid. Invalid content found by
+// the use cases is answered by writeError instead.
+func unprocessable(w http.ResponseWriter, r *http.Request, code, message string) {
+	render.Status(r, http.StatusUnprocessableEntity)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Code:      code,
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
+
+// newErrorID generates a random identifier for correlating error reports
+func newErrorID() string {
+	randBytes := make([]byte, 8)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "err_" + time.Now().Format("20060102150405.000000")
+	}
+	return "err_" + hex.EncodeToString(randBytes)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/retryafter"
+)
+
+func TestWriteError_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name               string
+		err                error
+		expectedStatus     int
+		expectedBody       string
+		expectedCode       string
+		expectedRetryAfter string
+	}{
+		{
+			name:               "transient failure",
+			err:                fmt.Errorf("search index: %w", retryafter.Wrap(errors.New("rebuilding"), 45*time.Second)),
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedBody:       unavailableMessage,
+			expectedRetryAfter: "45",
+		},
+		{
+			name:           "missing resource",
+			err:            fmt.Errorf("failed to get user: %w", repositories.ErrUserNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   repositories.ErrUserNotFound.Error(),
+			expectedCode:   "user_not_found",
+		},
+		{
+			name:           "duplicate",
+			err:            fmt.Errorf("failed to create user: %w", repositories.ErrUserAlreadyExists),
+			expectedStatus: http.StatusConflict,
+			expectedBody:   repositories.ErrUserAlreadyExists.Error(),
+			expectedCode:   "user_already_exists",
+		},
+		{
+			name:           "query over budget",
+			err:            fmt.Errorf("list users: %w", &repositories.QueryBudgetError{Operation: "list", Table: "users", Reason: "returned more than 10000 rows"}),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   repositories.ErrQueryBudgetExceeded.Error(),
+			expectedCode:   "query_budget_exceeded",
+		},
+		{
+			name:           "chunk too large",
+			err:            fmt.Errorf("append chunk: %w", usecase.ErrChunkTooLarge),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   usecase.ErrChunkTooLarge.Error(),
+			expectedCode:   "chunk_too_large",
+		},
+		{
+			name:           "internal kind",
+			err:            fmt.Errorf("append chunk: %w", repositories.ErrUploadConflict),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   internalErrorMessage,
+		},
+		{
+			name:           "internal error",
+			err:            errors.New("connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   internalErrorMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeError(w, httptest.NewRequest("GET", "/api/v1/users/user_1", nil), logger.New(), tt.err)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			var response Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Code)
+			assert.Equal(t, tt.expectedRetryAfter, w.Header().Get(retryafter.Header))
+		})
+	}
+}
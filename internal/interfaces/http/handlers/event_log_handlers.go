@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// EventLogHandler handles inspection and replay of published domain events
+type EventLogHandler struct {
+	eventLogUseCase usecase.EventLogUseCaseInterface
+	defaults        APIDefaults
+	logger          logger.Logger
+}
+
+// NewEventLogHandler creates a new event log handler
+func NewEventLogHandler(eventLogUseCase usecase.EventLogUseCaseInterface, logger logger.Logger) *EventLogHandler {
+	return &EventLogHandler{
+		eventLogUseCase: eventLogUseCase,
+		defaults:        DefaultAPIDefaults(),
+		logger:          logger,
+	}
+}
+
+// WithDefaults replaces the page size of published event listings
+func (h *EventLogHandler) WithDefaults(defaults APIDefaults) *EventLogHandler {
+	h.defaults = defaults
+	return h
+}
+
+// PublishedEventDTO is the API representation of a published event. JSON
+// payloads are embedded as-is; anything else is returned as a string.
+type PublishedEventDTO struct {
+	ID            string            `json:"id"`
+	Type          string            `json:"type"`
+	AggregateID   string            `json:"aggregate_id"`
+	CorrelationID string            `json:"correlation_id,omitempty"`
+	OccurredAt    time.Time         `json:"occurred_at"`
+	PublishedAt   time.Time         `json:"published_at"`
+	Payload       json.RawMessage   `json:"payload"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	PublishError  string            `json:"publish_error,omitempty"`
+	ReplayCount   int               `json:"replay_count"`
+	ReplayedAt    *time.Time        `json:"replayed_at,omitempty"`
+}
+
+// ReplayEventRequest represents where a replayed event goes. Without a
+// topic or a handler it is published on its own topic again.
+type ReplayEventRequest struct {
+	Topic   string `json:"topic,omitempty"`
+	Handler string `json:"handler,omitempty"`
+}
+
+// ReplayEventsRequest represents the request body for a bulk replay. When
+// IDs is empty every event matching the filter is replayed.
+type ReplayEventsRequest struct {
+	ReplayEventRequest
+	IDs           []string  `json:"ids,omitempty"`
+	Type          string    `json:"type,omitempty"`
+	AggregateID   string    `json:"aggregate_id,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Since         time.Time `json:"since,omitempty"`
+	Until         time.Time `json:"until,omitempty"`
+	Failed        bool      `json:"failed,omitempty"`
+}
+
+// ListEvents handles listing published events, oldest first, filtered by
+// the type, aggregate_id, correlation_id, since, until and failed query
+// parameters
+func (h *EventLogHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repositories.PublishedEventFilter{
+		Type:          query.Get("type"),
+		AggregateID:   query.Get("aggregate_id"),
+		CorrelationID: query.Get("correlation_id"),
+		Failed:        query.Get("failed") == "true",
+	}
+	for name, bound := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				unprocessable(w, r, "invalid_timestamp", name+" must be an RFC 3339 timestamp")
+				return
+			}
+			*bound = t
+		}
+	}
+	var warnings []Warning
+	filter.Limit, filter.Offset, warnings = pagination(query, h.defaults.AdminPageSize, 0)
+
+	fields, ok := requestFields(w, r, PublishedEventDTO{})
+	if !ok {
+		return
+	}
+
+	events, err := h.eventLogUseCase.ListEvents(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]PublishedEventDTO, 0, len(events))
+	for _, event := range events {
+		dtos = append(dtos, presentPublishedEvent(event))
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Published events retrieved successfully",
+		Data:      fields.apply(dtos),
+		Warnings:  warnings,
+		Meta:      listMeta(filter.Limit, filter.Offset, len(dtos)),
+		Timestamp: time.Now(),
+	})
+}
+
+// GetEvent handles retrieving a published event with its payload
+func (h *EventLogHandler) GetEvent(w http.ResponseWriter, r *http.Request) {
+	fields, ok := requestFields(w, r, PublishedEventDTO{})
+	if !ok {
+		return
+	}
+
+	event, err := h.eventLogUseCase.GetEvent(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Published event retrieved successfully",
+		Data:      fields.apply(presentPublishedEvent(event)),
+		Timestamp: time.Now(),
+	})
+}
+
+// ReplayEvent handles replaying a single published event
+func (h *EventLogHandler) ReplayEvent(w http.ResponseWriter, r *http.Request) {
+	// An empty body publishes the event on its own topic
+	var req ReplayEventRequest
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		malformedBody(w, r, err)
+		return
+	}
+
+	event, err := h.eventLogUseCase.ReplayEvent(r.Context(), chi.URLParam(r, "id"), usecase.EventReplay{
+		Topic:   req.Topic,
+		Handler: req.Handler,
+	})
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Published event replayed successfully",
+		Data:      presentPublishedEvent(event),
+		Timestamp: time.Now(),
+	})
+}
+
+// ReplayEvents handles replaying published events in bulk
+func (h *EventLogHandler) ReplayEvents(w http.ResponseWriter, r *http.Request) {
+	var req ReplayEventsRequest
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		malformedBody(w, r, err)
+		return
+	}
+
+	result, err := h.eventLogUseCase.ReplayEvents(r.Context(), usecase.EventReplay{
+		IDs: req.IDs,
+		Filter: repositories.PublishedEventFilter{
+			Type:          req.Type,
+			AggregateID:   req.AggregateID,
+			CorrelationID: req.CorrelationID,
+			Since:         req.Since,
+			Until:         req.Until,
+			Failed:        req.Failed,
+			Limit:         maxBulkReplay,
+		},
+		Topic:   req.Topic,
+		Handler: req.Handler,
+	})
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Published events replayed",
+		Data:      result,
+		Timestamp: time.Now(),
+	})
+}
+
+// presentPublishedEvent converts a published event entity into its API
+// representation
+func presentPublishedEvent(event *entities.PublishedEvent) PublishedEventDTO {
+	payload := json.RawMessage(event.Payload)
+	if !json.Valid(payload) {
+		payload, _ = json.Marshal(string(event.Payload))
+	}
+
+	return PublishedEventDTO{
+		ID:            event.ID,
+		Type:          event.Type,
+		AggregateID:   event.AggregateID,
+		CorrelationID: event.CorrelationID,
+		OccurredAt:    event.OccurredAt,
+		PublishedAt:   event.PublishedAt,
+		Payload:       payload,
+		Headers:       event.Headers,
+		PublishError:  event.PublishError,
+		ReplayCount:   event.ReplayCount,
+		ReplayedAt:    event.ReplayedAt,
+	}
+}
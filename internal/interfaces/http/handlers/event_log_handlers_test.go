@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockEventLogUseCase is a mock implementation of EventLogUseCaseInterface
+type MockEventLogUseCase struct {
+	mock.Mock
+}
+
+func (m *MockEventLogUseCase) ListEvents(ctx context.Context, filter repositories.PublishedEventFilter) ([]*entities.PublishedEvent, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.PublishedEvent), args.Error(1)
+}
+
+func (m *MockEventLogUseCase) GetEvent(ctx context.Context, id string) (*entities.PublishedEvent, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PublishedEvent), args.Error(1)
+}
+
+func (m *MockEventLogUseCase) ReplayEvent(ctx context.Context, id string, replay usecase.EventReplay) (*entities.PublishedEvent, error) {
+	args := m.Called(ctx, id, replay)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.PublishedEvent), args.Error(1)
+}
+
+func (m *MockEventLogUseCase) ReplayEvents(ctx context.Context, replay usecase.EventReplay) (*usecase.ReplayResult, error) {
+	args := m.Called(ctx, replay)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.ReplayResult), args.Error(1)
+}
+
+func newEventLogRouter(uc usecase.EventLogUseCaseInterface) http.Handler {
+	handler := NewEventLogHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Get("/events", handler.ListEvents)
+	r.Post("/events/replay", handler.ReplayEvents)
+	r.Get("/events/{id}", handler.GetEvent)
+	r.Post("/events/{id}/replay", handler.ReplayEvent)
+	return r
+}
+
+func TestEventLogHandler_ListEvents(t *testing.T) {
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mockUseCase := new(MockEventLogUseCase)
+	mockUseCase.On("ListEvents", mock.Anything, repositories.PublishedEventFilter{
+		Type:   "user.created",
+		Since:  since,
+		Failed: true,
+		Limit:  20,
+	}).Return([]*entities.PublishedEvent{{
+		ID:           "evt_1",
+		Type:         "user.created",
+		Payload:      []byte(`{"id":"evt_1"}`),
+		PublishError: "broker down",
+		PublishedAt:  since,
+	}}, nil)
+
+	w := httptest.NewRecorder()
+	newEventLogRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/events?type=user.created&since=2026-10-01T00:00:00Z&failed=true&limit=20", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, map[string]interface{}{"id": "evt_1"}, body.Data[0]["payload"])
+	assert.Equal(t, "broker down", body.Data[0]["publish_error"])
+	mockUseCase.AssertExpectations(t)
+
+	w = httptest.NewRecorder()
+	newEventLogRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/events?until=yesterday", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "until must be an RFC 3339 timestamp")
+}
+
+func TestEventLogHandler_GetEvent_NotFound(t *testing.T) {
+	mockUseCase := new(MockEventLogUseCase)
+	mockUseCase.On("GetEvent", mock.Anything, "missing").Return(nil, repositories.ErrPublishedEventNotFound)
+
+	w := httptest.NewRecorder()
+	newEventLogRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/events/missing", nil))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "error", body["status"])
+	assert.Equal(t, repositories.ErrPublishedEventNotFound.Error(), body["message"])
+}
+
+func TestEventLogHandler_ReplayEvent(t *testing.T) {
+	mockUseCase := new(MockEventLogUseCase)
+	mockUseCase.On("ReplayEvent", mock.Anything, "evt_1", usecase.EventReplay{Handler: "webhooks"}).Return(&entities.PublishedEvent{
+		ID:          "evt_1",
+		Payload:     []byte("{}"),
+		ReplayCount: 1,
+	}, nil)
+	mockUseCase.On("ReplayEvent", mock.Anything, "evt_1", usecase.EventReplay{Topic: "users.rebuild", Handler: "webhooks"}).
+		Return(nil, usecase.ErrReplayTargetConflict)
+
+	w := httptest.NewRecorder()
+	newEventLogRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("POST", "/events/evt_1/replay", bytes.NewBufferString(`{"handler":"webhooks"}`)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, float64(1), body.Data["replay_count"])
+
+	w = httptest.NewRecorder()
+	newEventLogRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("POST", "/events/evt_1/replay", bytes.NewBufferString(`{"topic":"users.rebuild","handler":"webhooks"}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestEventLogHandler_ReplayEvents(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		replay usecase.EventReplay
+	}{
+		{
+			name: "explicit ids",
+			body: `{"ids":["evt_1","evt_2"],"topic":"users.rebuild"}`,
+			replay: usecase.EventReplay{
+				IDs:    []string{"evt_1", "evt_2"},
+				Filter: repositories.PublishedEventFilter{Limit: maxBulkReplay},
+				Topic:  "users.rebuild",
+			},
+		},
+		{
+			name: "by filter",
+			body: `{"type":"user.created","since":"2026-10-01T00:00:00Z","failed":true}`,
+			replay: usecase.EventReplay{
+				Filter: repositories.PublishedEventFilter{
+					Type:   "user.created",
+					Since:  time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+					Failed: true,
+					Limit:  maxBulkReplay,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockEventLogUseCase)
+			mockUseCase.On("ReplayEvents", mock.Anything, tt.replay).
+				Return(&usecase.ReplayResult{Replayed: []string{"evt_1"}, Failed: []usecase.ReplayFailure{}}, nil)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/events/replay", bytes.NewBufferString(tt.body))
+			newEventLogRouter(mockUseCase).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/sse"
+)
+
+// EventStreamHandler streams user changes to clients as Server-Sent Events
+type EventStreamHandler struct {
+	eventStreamUseCase usecase.EventStreamUseCaseInterface
+	heartbeat          time.Duration
+	retry              time.Duration
+	logger             logger.Logger
+}
+
+// NewEventStreamHandler creates a new event stream handler
+func NewEventStreamHandler(eventStreamUseCase usecase.EventStreamUseCaseInterface, logger logger.Logger) *EventStreamHandler {
+	return &EventStreamHandler{
+		eventStreamUseCase: eventStreamUseCase,
+		heartbeat:          15 * time.Second,
+		retry:              3 * time.Second,
+		logger:             logger,
+	}
+}
+
+// WithTimings replaces how often idle streams send a heartbeat comment
+// and how long clients wait before reconnecting to an ended stream
+func (h *EventStreamHandler) WithTimings(heartbeat, retry time.Duration) *EventStreamHandler {
+	h.heartbeat = heartbeat
+	h.retry = retry
+	return h
+}
+
+// Stream godoc
+// @Summary      Stream user changes
+// @Description  Stream user create, update and delete events as Server-Sent Events until the client disconnects. Each event carries the domain event ID, its type as the event name and the domain event as JSON data. Events published while a client is disconnected are not replayed.
+// @Tags         events
+// @Produce      text/event-stream
+// @Param        types  query     string  false  "Comma-separated event types to stream; every user change when omitted"
+// @Success      200    {string}  string  "Event stream"
+// @Failure      401    {object}  ErrorResponse
+// @Failure      403    {object}  ErrorResponse
+// @Failure      422    {object}  ErrorResponse
+// @Failure      503    {object}  ErrorResponse
+// @Router       /api/v1/events [get]
+func (h *EventStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	var eventTypes []string
+	for _, value := range r.URL.Query()["types"] {
+		for _, eventType := range strings.Split(value, ",") {
+			if eventType = strings.TrimSpace(eventType); eventType != "" {
+				eventTypes = append(eventTypes, eventType)
+			}
+		}
+	}
+
+	stream, err := h.eventStreamUseCase.Subscribe(r.Context(), eventTypes)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	defer stream.Close()
+
+	out, err := sse.NewStream(w, h.retry)
+	if err != nil {
+		h.logger.WithField("error", err.Error()).Error("Failed to start event stream")
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, open := <-stream.Events:
+			if !open {
+				if err := stream.Err(); err != nil {
+					h.logger.WithField("reason", err.Error()).Info("Event stream ended")
+				}
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger.WithField("error", err.Error()).Error("Failed to encode streamed event")
+				return
+			}
+			if err := out.Send(sse.Event{ID: event.ID, Type: event.Type, Data: data}); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := out.Comment("heartbeat"); err != nil {
+				return
+			}
+		}
+	}
+}
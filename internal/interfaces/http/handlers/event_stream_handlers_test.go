@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/retryafter"
+)
+
+func newEventStreamServer(t *testing.T, streams *usecase.EventStreamUseCase) *httptest.Server {
+	handler := NewEventStreamHandler(streams, logger.New()).WithTimings(time.Hour, 2*time.Second)
+	server := httptest.NewServer(http.HandlerFunc(handler.Stream))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readEvent reads the next event of an event stream, skipping the
+// reconnection delay and comments
+func readEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	fields := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			if len(fields) > 0 {
+				return fields
+			}
+			continue
+		}
+		if name, value, ok := strings.Cut(line, ": "); ok && name != "" && name != "retry" {
+			fields[name] = value
+		}
+	}
+}
+
+func TestEventStreamHandler_Stream(t *testing.T) {
+	streams := usecase.NewEventStreamUseCase(usecase.EventStreamOptions{Buffer: 4}, logger.New())
+	server := newEventStreamServer(t, streams)
+
+	resp, err := http.Get(server.URL + "?types=user.created,%20user.deleted")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	require.Eventually(t, func() bool { return streams.Streams() == 1 }, time.Second, 10*time.Millisecond)
+	ctx := context.Background()
+	updated := events.NewEvent(events.UserUpdated, "user_1", nil)
+	deleted := events.NewEvent(events.UserDeleted, "user_1", map[string]interface{}{"id": "user_1"})
+	require.NoError(t, streams.Publish(ctx, updated))
+	require.NoError(t, streams.Publish(ctx, deleted))
+
+	fields := readEvent(t, bufio.NewReader(resp.Body))
+	assert.Equal(t, deleted.ID, fields["id"])
+	assert.Equal(t, events.UserDeleted, fields["event"])
+	var data events.Event
+	require.NoError(t, json.Unmarshal([]byte(fields["data"]), &data))
+	assert.Equal(t, "user_1", data.AggregateID)
+}
+
+func TestEventStreamHandler_Stream_EndsOnShutdown(t *testing.T) {
+	streams := usecase.NewEventStreamUseCase(usecase.EventStreamOptions{Buffer: 4, RetryAfter: 10 * time.Second}, logger.New())
+	server := newEventStreamServer(t, streams)
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Eventually(t, func() bool { return streams.Streams() == 1 }, time.Second, 10*time.Millisecond)
+
+	streams.Close()
+	_, err = bufio.NewReader(resp.Body).ReadString('x')
+	assert.ErrorContains(t, err, "EOF")
+
+	refused, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer refused.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, refused.StatusCode)
+	assert.Equal(t, "10", refused.Header.Get(retryafter.Header))
+}
+
+func TestEventStreamHandler_Stream_UnknownType(t *testing.T) {
+	streams := usecase.NewEventStreamUseCase(usecase.EventStreamOptions{Buffer: 4}, logger.New())
+	handler := NewEventStreamHandler(streams, logger.New())
+
+	for _, types := range []string{"user.renamed", events.UserDeletionScheduled} {
+		w := httptest.NewRecorder()
+		handler.Stream(w, httptest.NewRequest("GET", "/api/v1/events?types="+types, nil))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, types)
+	}
+	assert.Equal(t, 0, streams.Streams())
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"clean-architecture/internal/domain/request"
+)
+
+// ExperimentAssignmentDTO is the API representation of the variant of an
+// experiment the caller is assigned to
+type ExperimentAssignmentDTO struct {
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+}
+
+// ListMyExperiments godoc
+// @Summary      List my experiment assignments
+// @Description  List the variant of every A/B experiment the authenticated user is assigned to. A user keeps their variant as long as the variants of the experiment stay the same. Listing them records the user as exposed to each experiment.
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /api/v1/me/experiments [get]
+func ListMyExperiments(w http.ResponseWriter, r *http.Request) {
+	assignments := request.Current(r.Context()).Experiments()
+	dtos := make([]ExperimentAssignmentDTO, 0, len(assignments))
+	for experiment, variant := range assignments {
+		dtos = append(dtos, ExperimentAssignmentDTO{Experiment: experiment, Variant: variant})
+	}
+	sort.Slice(dtos, func(i, j int) bool { return dtos[i].Experiment < dtos[j].Experiment })
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Experiments retrieved successfully",
+		Data:      dtos,
+		Timestamp: time.Now(),
+	})
+}
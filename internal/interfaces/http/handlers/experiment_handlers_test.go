@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/pkg/experiments"
+)
+
+func TestListMyExperiments(t *testing.T) {
+	onboarding, err := experiments.Parse("onboarding", "control|guided")
+	require.NoError(t, err)
+	pricing, err := experiments.Parse("pricing", "control|annual")
+	require.NoError(t, err)
+	assigner, err := experiments.New([]experiments.Experiment{pricing, onboarding}, nil)
+	require.NoError(t, err)
+
+	serve := func(userID string) []ExperimentAssignmentDTO {
+		rc := (&request.Context{UserID: userID}).WithExperiments(context.Background(), assigner.For(userID))
+		req := httptest.NewRequest(http.MethodGet, "/me/experiments", nil)
+		req = req.WithContext(request.With(req.Context(), rc))
+		w := httptest.NewRecorder()
+		ListMyExperiments(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data []ExperimentAssignmentDTO `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	got := serve("user_1")
+	require.Len(t, got, 2)
+	assert.Equal(t, "onboarding", got[0].Experiment, "experiments are sorted by name")
+	want, _ := assigner.Assign("onboarding", "user_1")
+	assert.Equal(t, want, got[0].Variant)
+	assert.Equal(t, got, serve("user_1"), "assignments are stable")
+
+	assert.Equal(t, []ExperimentAssignmentDTO{
+		{Experiment: "onboarding", Variant: "control"},
+		{Experiment: "pricing", Variant: "control"},
+	}, serve(""), "anonymous callers get the control")
+}
+
+func TestListMyExperiments_None(t *testing.T) {
+	w := httptest.NewRecorder()
+	ListMyExperiments(w, httptest.NewRequest(http.MethodGet, "/me/experiments", nil))
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []interface{}{}, response["data"])
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/httpserver"
+	"clean-architecture/pkg/logger"
+)
+
+// ExportHandler handles user export requests
+type ExportHandler struct {
+	exportUseCase usecase.ExportUseCaseInterface
+	flushInterval time.Duration
+	stallTimeout  time.Duration
+	logger        logger.Logger
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportUseCase usecase.ExportUseCaseInterface, logger logger.Logger) *ExportHandler {
+	return &ExportHandler{
+		exportUseCase: exportUseCase,
+		flushInterval: time.Second,
+		stallTimeout:  30 * time.Second,
+		logger:        logger,
+	}
+}
+
+// WithStreaming replaces how often streamed exports are flushed to the
+// client and how long a client may take to accept a flush before it is
+// cut off
+func (h *ExportHandler) WithStreaming(flushInterval, stallTimeout time.Duration) *ExportHandler {
+	h.flushInterval = flushInterval
+	h.stallTimeout = stallTimeout
+	return h
+}
+
+// exportContentTypes are the media types of streamed exports by format
+var exportContentTypes = map[string]string{
+	usecase.ExportFormatCSV:    "text/csv; charset=utf-8",
+	usecase.ExportFormatNDJSON: "application/x-ndjson",
+}
+
+// RequestExportRequest represents the request body for starting an export
+type RequestExportRequest struct {
+	// Format is csv or ndjson; defaults to csv
+	Format string `json:"format,omitempty"`
+}
+
+// ExportDTO is the API representation of an export job
+type ExportDTO struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Format     string     `json:"format"`
+	Rows       int64      `json:"rows"`
+	Size       int64      `json:"size"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// ExpiresAt is when the export's artifact is deleted
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
+
+// RequestExport godoc
+// @Summary      Export users
+// @Description  Start a background export of every user. Poll the returned job until it succeeds to get a signed download URL.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      RequestExportRequest  false  "Export format"
+// @Success      202      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api/v1/users/exports [post]
+func (h *ExportHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	// An empty body exports in the default format
+	var req RequestExportRequest
+	if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+		malformedBody(w, r, err)
+		return
+	}
+	if req.Format == "" {
+		req.Format = usecase.ExportFormatCSV
+	}
+
+	export, err := h.exportUseCase.RequestExport(r.Context(), req.Format, request.Current(r.Context()).UserID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/users/exports/"+export.Job.ID)
+	render.Status(r, http.StatusAccepted)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Export queued",
+		Data:      presentExport(export),
+		Timestamp: time.Now(),
+	})
+}
+
+// GetExport godoc
+// @Summary      Get a user export
+// @Description  Get the status of an export job. Succeeded exports include a time-limited download_url.
+// @Tags         users
+// @Produce      json
+// @Param        id      path      string  true   "Export job ID"
+// @Param        fields  query     string  false  "Comma-separated fields to return"
+// @Success      200     {object}  SuccessResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Router       /api/v1/users/exports/{id} [get]
+func (h *ExportHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	fields, ok := requestFields(w, r, ExportDTO{})
+	if !ok {
+		return
+	}
+
+	export, err := h.exportUseCase.GetExport(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Export retrieved successfully",
+		Data:      fields.apply(presentExport(export)),
+		Timestamp: time.Now(),
+	})
+}
+
+// StreamExport godoc
+// @Summary      Stream a user export
+// @Description  Stream every user as the export is produced instead of through a background job. The body is sent with chunked transfer encoding, compressed with gzip when Accept-Encoding allows it, and flushed periodically. Errors before the first byte are answered as JSON; a failure later aborts the connection, so a truncated export is never mistaken for a complete one.
+// @Tags         users
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Param        format  query     string  false  "csv or ndjson; defaults to csv"
+// @Success      200     {string}  string  "Exported users"
+// @Failure      422     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /api/v1/users/exports/stream [get]
+func (h *ExportHandler) StreamExport(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = usecase.ExportFormatCSV
+	}
+
+	out := httpserver.NewStreamWriter(w, r, httpserver.StreamOptions{
+		ContentType:        exportContentTypes[format],
+		ContentDisposition: `attachment; filename="users.` + format + `"`,
+		FlushInterval:      h.flushInterval,
+		StallTimeout:       h.stallTimeout,
+	})
+	rows, err := h.exportUseCase.StreamExport(r.Context(), out, format)
+	if err == nil {
+		err = out.Close()
+	}
+
+	switch {
+	case err == nil:
+	case r.Context().Err() != nil:
+		// The client disconnected; there is no one to answer
+	case !out.Started():
+		writeError(w, r, h.logger, err)
+	default:
+		h.logger.WithFields(map[string]interface{}{
+			"rows":  rows,
+			"error": err.Error(),
+		}).Error("Aborting user export stream")
+		// Aborting the connection, rather than ending the body, tells the
+		// client the export is incomplete
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func presentExport(export *usecase.Export) ExportDTO {
+	job := export.Job
+	return ExportDTO{
+		ID:                job.ID,
+		Status:            job.Status,
+		Format:            export.Format,
+		Rows:              export.Rows,
+		Size:              export.Size,
+		Error:             job.Error,
+		CreatedAt:         job.CreatedAt,
+		StartedAt:         job.StartedAt,
+		FinishedAt:        job.FinishedAt,
+		ExpiresAt:         job.ExpiresAt,
+		DownloadURL:       export.DownloadURL,
+		DownloadExpiresAt: export.DownloadExpiresAt,
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockExportUseCase is a mock implementation of ExportUseCaseInterface
+type MockExportUseCase struct {
+	mock.Mock
+}
+
+func (m *MockExportUseCase) RequestExport(ctx context.Context, format, requestedBy string) (*usecase.Export, error) {
+	args := m.Called(ctx, format, requestedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.Export), args.Error(1)
+}
+
+func (m *MockExportUseCase) GetExport(ctx context.Context, id string) (*usecase.Export, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.Export), args.Error(1)
+}
+
+func (m *MockExportUseCase) StreamExport(ctx context.Context, w io.Writer, format string) (int64, error) {
+	args := m.Called(ctx, w, format)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newExportRouter(uc usecase.ExportUseCaseInterface) http.Handler {
+	handler := NewExportHandler(uc, logger.New()).WithStreaming(0, time.Minute)
+	r := chi.NewRouter()
+	r.Post("/users/exports", handler.RequestExport)
+	r.Get("/users/exports/stream", handler.StreamExport)
+	r.Get("/users/exports/{id}", handler.GetExport)
+	return r
+}
+
+func TestExportHandler_RequestExport(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		format string
+	}{
+		{name: "default format", body: "", format: usecase.ExportFormatCSV},
+		{name: "explicit format", body: `{"format":"ndjson"}`, format: usecase.ExportFormatNDJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockExportUseCase)
+			mockUseCase.On("RequestExport", mock.Anything, tt.format, "user_1").Return(&usecase.Export{
+				Job:    &entities.Job{ID: "job_1", Status: entities.JobQueued},
+				Format: tt.format,
+			}, nil)
+
+			w := httptest.NewRecorder()
+			req := withSubject(httptest.NewRequest("POST", "/users/exports", bytes.NewBufferString(tt.body)), "user_1")
+			newExportRouter(mockUseCase).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusAccepted, w.Code)
+			assert.Equal(t, "/api/v1/users/exports/job_1", w.Header().Get("Location"))
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportHandler_RequestExport_UnsupportedFormat(t *testing.T) {
+	mockUseCase := new(MockExportUseCase)
+	mockUseCase.On("RequestExport", mock.Anything, "xlsx", "").Return(nil, usecase.ErrUnsupportedExportFormat)
+
+	w := httptest.NewRecorder()
+	newExportRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("POST", "/users/exports", bytes.NewBufferString(`{"format":"xlsx"}`)))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, usecase.ErrUnsupportedExportFormat.Error(), body["message"])
+}
+
+func TestExportHandler_GetExport(t *testing.T) {
+	expiresAt := time.Now().Add(time.Minute)
+	mockUseCase := new(MockExportUseCase)
+	mockUseCase.On("GetExport", mock.Anything, "job_1").Return(&usecase.Export{
+		Job:               &entities.Job{ID: "job_1", Status: entities.JobSucceeded},
+		Format:            usecase.ExportFormatCSV,
+		Rows:              3,
+		DownloadURL:       "http://localhost:8080/api/v1/downloads/exports/job_1.csv?expires=1&signature=abc",
+		DownloadExpiresAt: &expiresAt,
+	}, nil)
+	mockUseCase.On("GetExport", mock.Anything, "missing").Return(nil, repositories.ErrJobNotFound)
+	router := newExportRouter(mockUseCase)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/exports/job_1", nil))
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, entities.JobSucceeded, body.Data["status"])
+	assert.Equal(t, float64(3), body.Data["rows"])
+	assert.Contains(t, body.Data["download_url"], "signature=")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/exports/missing", nil))
+	var notFound map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &notFound))
+	assert.Equal(t, repositories.ErrJobNotFound.Error(), notFound["message"])
+}
+
+func TestExportHandler_StreamExport(t *testing.T) {
+	mockUseCase := new(MockExportUseCase)
+	mockUseCase.On("StreamExport", mock.Anything, mock.Anything, usecase.ExportFormatCSV).Run(func(args mock.Arguments) {
+		_, _ = io.WriteString(args.Get(1).(io.Writer), "id,name\nuser_1,John\n")
+	}).Return(int64(1), nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/users/exports/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	newExportRouter(mockUseCase).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="users.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	gz, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\nuser_1,John\n", string(body))
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestExportHandler_StreamExport_UnsupportedFormat(t *testing.T) {
+	mockUseCase := new(MockExportUseCase)
+	mockUseCase.On("StreamExport", mock.Anything, mock.Anything, "pdf").Return(int64(0), usecase.ErrUnsupportedExportFormat)
+
+	w := httptest.NewRecorder()
+	newExportRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/users/exports/stream?format=pdf", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, usecase.ErrUnsupportedExportFormat.Error(), body["message"])
+}
+
+func TestExportHandler_StreamExport_ClientGoneBeforeFirstRow(t *testing.T) {
+	mockUseCase := new(MockExportUseCase)
+	mockUseCase.On("StreamExport", mock.Anything, mock.Anything, usecase.ExportFormatCSV).Return(int64(0), context.Canceled)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	newExportRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/users/exports/stream", nil).WithContext(ctx))
+
+	// A disconnect is not an internal error, so no error is reported
+	assert.NotContains(t, w.Body.String(), "error_id")
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestExportHandler_StreamExport_ClientDisconnects(t *testing.T) {
+	canceled := make(chan struct{})
+	mockUseCase := new(MockExportUseCase)
+	mockUseCase.On("StreamExport", mock.Anything, mock.Anything, usecase.ExportFormatNDJSON).Run(func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		out := args.Get(1).(io.Writer)
+		// Produce rows until the client goes away, as the use case does
+		// between pages
+		deadline := time.After(5 * time.Second)
+		for ctx.Err() == nil {
+			select {
+			case <-deadline:
+				return
+			default:
+			}
+			_, _ = io.WriteString(out, `{"id":"user_1","name":"John"}`+"\n")
+		}
+		close(canceled)
+	}).Return(int64(0), context.Canceled)
+
+	server := httptest.NewServer(newExportRouter(mockUseCase))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/users/exports/stream?format=ndjson")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	// A slow reader takes a few rows, then hangs up
+	reader := bufio.NewReader(resp.Body)
+	for i := 0; i < 3; i++ {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":"user_1","name":"John"}`+"\n", line)
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, resp.Body.Close())
+
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the export kept running after the client disconnected")
+	}
+}
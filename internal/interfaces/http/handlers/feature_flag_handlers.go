@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// FeatureFlagHandler handles administrators viewing and switching feature
+// flags
+type FeatureFlagHandler struct {
+	flagUseCase usecase.FeatureFlagUseCaseInterface
+	logger      logger.Logger
+}
+
+// NewFeatureFlagHandler creates a new feature flag handler
+func NewFeatureFlagHandler(flagUseCase usecase.FeatureFlagUseCaseInterface, logger logger.Logger) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		flagUseCase: flagUseCase,
+		logger:      logger,
+	}
+}
+
+// FeatureFlagDTO is the API representation of a feature flag
+type FeatureFlagDTO struct {
+	Key     string `json:"key"`
+	Enabled bool   `json:"enabled"`
+	// Default is true while the flag is in its configured state
+	Default   bool       `json:"default"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// UpdateFeatureFlagRequest represents the request body for switching a
+// feature flag
+type UpdateFeatureFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// ListFeatureFlags godoc
+// @Summary      List feature flags
+// @Description  List every configured feature flag with whether it is on
+// @Tags         feature-flags
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/feature-flags [get]
+func (h *FeatureFlagHandler) ListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	flags, err := h.flagUseCase.ListFeatureFlags(r.Context())
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]FeatureFlagDTO, 0, len(flags))
+	for _, flag := range flags {
+		dtos = append(dtos, presentFeatureFlag(flag))
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Feature flags retrieved successfully",
+		Data:      dtos,
+		Timestamp: time.Now(),
+	})
+}
+
+// UpdateFeatureFlag godoc
+// @Summary      Switch a feature flag
+// @Description  Switch a feature flag on or off. The state replaces the configured default on every instance.
+// @Tags         feature-flags
+// @Accept       json
+// @Produce      json
+// @Param        key   path      string                    true  "Flag key"
+// @Param        flag  body      UpdateFeatureFlagRequest  true  "New state"
+// @Success      200   {object}  SuccessResponse
+// @Failure      400   {object}  ErrorResponse
+// @Failure      401   {object}  ErrorResponse
+// @Failure      403   {object}  ErrorResponse
+// @Failure      404   {object}  ErrorResponse
+// @Failure      422   {object}  ErrorResponse
+// @Failure      500   {object}  ErrorResponse
+// @Router       /api/v1/feature-flags/{key} [put]
+func (h *FeatureFlagHandler) UpdateFeatureFlag(w http.ResponseWriter, r *http.Request) {
+	var req UpdateFeatureFlagRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if req.Enabled == nil {
+		unprocessable(w, r, "enabled_required", "enabled is required")
+		return
+	}
+
+	flag, err := h.flagUseCase.SetFeatureFlag(r.Context(), chi.URLParam(r, "key"), *req.Enabled)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Feature flag updated successfully",
+		Data:      presentFeatureFlag(flag),
+		Timestamp: time.Now(),
+	})
+}
+
+func presentFeatureFlag(flag *usecase.FeatureFlagState) FeatureFlagDTO {
+	dto := FeatureFlagDTO{
+		Key:     flag.Key,
+		Enabled: flag.Enabled,
+		Default: flag.Default,
+	}
+	if !flag.UpdatedAt.IsZero() {
+		updatedAt := flag.UpdatedAt
+		dto.UpdatedAt = &updatedAt
+	}
+	return dto
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockFeatureFlagUseCase is a mock implementation of
+// FeatureFlagUseCaseInterface
+type MockFeatureFlagUseCase struct {
+	mock.Mock
+}
+
+func (m *MockFeatureFlagUseCase) ListFeatureFlags(ctx context.Context) ([]*usecase.FeatureFlagState, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*usecase.FeatureFlagState), args.Error(1)
+}
+
+func (m *MockFeatureFlagUseCase) SetFeatureFlag(ctx context.Context, key string, enabled bool) (*usecase.FeatureFlagState, error) {
+	args := m.Called(ctx, key, enabled)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.FeatureFlagState), args.Error(1)
+}
+
+func newFeatureFlagRouter(uc usecase.FeatureFlagUseCaseInterface) http.Handler {
+	handler := NewFeatureFlagHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Get("/feature-flags", handler.ListFeatureFlags)
+	r.Put("/feature-flags/{key}", handler.UpdateFeatureFlag)
+	return r
+}
+
+func TestFeatureFlagHandler_ListFeatureFlags(t *testing.T) {
+	mockUseCase := new(MockFeatureFlagUseCase)
+	mockUseCase.On("ListFeatureFlags", mock.Anything).Return([]*usecase.FeatureFlagState{
+		{Key: "beta_search", Enabled: true, Default: true},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newFeatureFlagRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/feature-flags", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, map[string]interface{}{"key": "beta_search", "enabled": true, "default": true}, response.Data[0])
+}
+
+func TestFeatureFlagHandler_UpdateFeatureFlag(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockFeatureFlagUseCase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "switched on",
+			body: `{"enabled":true}`,
+			setupMock: func(m *MockFeatureFlagUseCase) {
+				m.On("SetFeatureFlag", mock.Anything, "new_dashboard", true).
+					Return(&usecase.FeatureFlagState{Key: "new_dashboard", Enabled: true, UpdatedAt: time.Now()}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"enabled":true`,
+		},
+		{
+			name:           "missing state",
+			body:           `{}`,
+			setupMock:      func(m *MockFeatureFlagUseCase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   `"code":"enabled_required"`,
+		},
+		{
+			name: "unknown flag",
+			body: `{"enabled":false}`,
+			setupMock: func(m *MockFeatureFlagUseCase) {
+				m.On("SetFeatureFlag", mock.Anything, "new_dashboard", false).Return(nil, usecase.ErrUnknownFeatureFlag)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `"code":"feature_flag_not_found","message":"feature flag not found"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockFeatureFlagUseCase)
+			tt.setupMock(mockUseCase)
+
+			req := httptest.NewRequest("PUT", "/feature-flags/new_dashboard", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			newFeatureFlagRouter(mockUseCase).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
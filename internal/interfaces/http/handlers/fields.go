@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// fieldSelection is the set of DTO fields a client asked for with the
+// fields query parameter. A nil selection keeps every field.
+type fieldSelection map[string]bool
+
+// dtoField is a JSON-encoded field of a DTO struct
+type dtoField struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+// dtoFieldCache maps DTO types to their []dtoField
+var dtoFieldCache sync.Map
+
+// dtoFields returns the JSON fields of struct type t in declaration order
+func dtoFields(t reflect.Type) []dtoField {
+	if cached, ok := dtoFieldCache.Load(t); ok {
+		return cached.([]dtoField)
+	}
+
+	fields := make([]dtoField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, dtoField{
+			index:     i,
+			name:      name,
+			omitEmpty: strings.Contains(","+options+",", ",omitempty,"),
+		})
+	}
+
+	dtoFieldCache.Store(t, fields)
+	return fields
+}
+
+// unknownFieldsError is returned for a fields parameter naming fields the
+// DTO does not have
+type unknownFieldsError struct {
+	unknown []string
+	allowed []string
+}
+
+// Error implements the error interface
+func (e *unknownFieldsError) Error() string {
+	return fmt.Sprintf("unknown fields %s (allowed: %s)",
+		strings.Join(e.unknown, ", "), strings.Join(e.allowed, ", "))
+}
+
+// parseFields reads the fields query parameter, a comma-separated list of
+// the JSON names of dto's fields. It returns nil when the parameter is
+// absent and an error naming any field dto does not have.
+func parseFields(r *http.Request, dto interface{}) (fieldSelection, error) {
+	raw := r.URL.Query().Get("fields")
+	if raw == "" {
+		return nil, nil
+	}
+
+	known := make(map[string]bool)
+	var allowed []string
+	for _, field := range dtoFields(reflect.TypeOf(dto)) {
+		known[field.name] = true
+		allowed = append(allowed, field.name)
+	}
+	sort.Strings(allowed)
+
+	selection := make(fieldSelection)
+	var unknown []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !known[name] {
+			unknown = append(unknown, name)
+			continue
+		}
+		selection[name] = true
+	}
+	if len(unknown) > 0 {
+		return nil, &unknownFieldsError{unknown: unknown, allowed: allowed}
+	}
+	if len(selection) == 0 {
+		return nil, nil
+	}
+	return selection, nil
+}
+
+// requestFields parses the fields query parameter for dto, answering 400
+// Bad Request and returning false when it names unknown fields
+func requestFields(w http.ResponseWriter, r *http.Request, dto interface{}) (fieldSelection, bool) {
+	selection, err := parseFields(r, dto)
+	if err != nil {
+		badRequest(w, r, "Invalid fields parameter: "+err.Error())
+		return nil, false
+	}
+	return selection, true
+}
+
+// badRequest writes a 400 Bad Request error response
+func badRequest(w http.ResponseWriter, r *http.Request, message string) {
+	render.Status(r, http.StatusBadRequest)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
+
+// allFields selects every field of dto
+func allFields(dto interface{}) fieldSelection {
+	selection := make(fieldSelection)
+	for _, field := range dtoFields(reflect.TypeOf(dto)) {
+		selection[field.name] = true
+	}
+	return selection
+}
+
+// apply returns v, a DTO or a slice of DTOs, reduced to the selected
+// fields. Fields tagged omitempty are still omitted when empty.
+func (s fieldSelection) apply(v interface{}) interface{} {
+	if s == nil {
+		return v
+	}
+
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Slice:
+		selected := make([]map[string]interface{}, value.Len())
+		for i := range selected {
+			selected[i] = s.selectStruct(value.Index(i))
+		}
+		return selected
+	case reflect.Struct, reflect.Pointer:
+		return s.selectStruct(value)
+	default:
+		return v
+	}
+}
+
+func (s fieldSelection) selectStruct(value reflect.Value) map[string]interface{} {
+	value = reflect.Indirect(value)
+	selected := make(map[string]interface{}, len(s))
+	for _, field := range dtoFields(value.Type()) {
+		if !s[field.name] {
+			continue
+		}
+		fieldValue := value.Field(field.index)
+		if field.omitEmpty && isEmptyValue(fieldValue) {
+			continue
+		}
+		selected[field.name] = fieldValue.Interface()
+	}
+	return selected
+}
+
+// isEmptyValue reports whether encoding/json's omitempty drops v
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
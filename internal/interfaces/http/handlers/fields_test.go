@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/pkg/logger"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected fieldSelection
+		wantErr  string
+	}{
+		{name: "absent", query: "", expected: nil},
+		{name: "empty", query: "?fields=", expected: nil},
+		{name: "only separators", query: "?fields=,,", expected: nil},
+		{
+			name:     "selected",
+			query:    "?fields=id,%20name,email",
+			expected: fieldSelection{"id": true, "name": true, "email": true},
+		},
+		{
+			name:    "unknown",
+			query:   "?fields=id,password,role",
+			wantErr: "unknown fields password, role (allowed: created_at, email, id, name, updated_at)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil)
+			selection, err := parseFields(req, UserDTO{})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, selection)
+		})
+	}
+}
+
+func TestFieldSelection_Apply(t *testing.T) {
+	user := UserDTO{ID: "user_1", Email: "user@example.com", Name: "User 1"}
+
+	var none fieldSelection
+	assert.Equal(t, user, none.apply(user))
+
+	selection := fieldSelection{"id": true, "name": true}
+	assert.Equal(t, map[string]interface{}{"id": "user_1", "name": "User 1"}, selection.apply(user))
+	assert.Equal(t, map[string]interface{}{"id": "user_1", "name": "User 1"}, selection.apply(&user))
+	assert.Equal(t, []map[string]interface{}{
+		{"id": "user_1", "name": "User 1"},
+		{"id": "user_2", "name": "User 2"},
+	}, selection.apply([]UserDTO{user, {ID: "user_2", Name: "User 2"}}))
+}
+
+func TestFieldSelection_ApplyKeepsOmitEmpty(t *testing.T) {
+	selection := fieldSelection{"id": true, "cancelled_at": true, "cancelled_by": true}
+
+	assert.Equal(t, map[string]interface{}{"id": "del_1"}, selection.apply(UserDeletionDTO{ID: "del_1"}))
+}
+
+func TestUserHandler_ListUsers_Fields(t *testing.T) {
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("ListUsers", mock.Anything, 10, 0).Return([]*entities.User{
+		{ID: "user_1", Email: "user1@example.com", Name: "User 1", CreatedAt: time.Now()},
+	}, nil)
+	handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
+
+	w := httptest.NewRecorder()
+	handler.ListUsers(w, httptest.NewRequest(http.MethodGet, "/users?fields=id,email", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []map[string]interface{}{{"id": "user_1", "email": "user1@example.com"}}, response.Data)
+}
+
+func TestUserHandler_ListUsers_UnknownFields(t *testing.T) {
+	mockUseCase := new(MockUserUseCase)
+	handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
+
+	w := httptest.NewRecorder()
+	handler.ListUsers(w, httptest.NewRequest(http.MethodGet, "/users?fields=id,password", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "error", response["status"])
+	assert.Contains(t, response["message"], "unknown fields password")
+	mockUseCase.AssertNotCalled(t, "ListUsers", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// filterParam matches filter[field] and filter[field][operator]
+var filterParam = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z_]+)\])?$`)
+
+// filterKind is the type of value a filterable field holds
+type filterKind int
+
+const (
+	filterString filterKind = iota
+	filterTime
+)
+
+// filterField describes a field clients may filter on
+type filterField struct {
+	kind      filterKind
+	operators []repositories.Operator
+	// indexed reports whether an index covers the field. Only equality,
+	// in and range conditions can use it.
+	indexed bool
+}
+
+var (
+	stringOperators = []repositories.Operator{
+		repositories.OpEq, repositories.OpNe, repositories.OpContains, repositories.OpStartsWith, repositories.OpIn,
+	}
+	timeOperators = []repositories.Operator{
+		repositories.OpEq, repositories.OpGt, repositories.OpGte, repositories.OpLt, repositories.OpLte,
+	}
+)
+
+// userFilterFields is the allowlist of user fields clients may filter and
+// sort on
+var userFilterFields = map[string]filterField{
+	"id":         {kind: filterString, operators: []repositories.Operator{repositories.OpEq, repositories.OpIn}, indexed: true},
+	"email":      {kind: filterString, operators: stringOperators, indexed: true},
+	"name":       {kind: filterString, operators: stringOperators},
+	"created_at": {kind: filterTime, operators: timeOperators},
+	"updated_at": {kind: filterTime, operators: timeOperators},
+}
+
+// parseFilters parses the filter[field][operator]=value parameters of
+// query into conditions on fields, within the filter bounds of d.
+// filter[field]=value is short for the eq operator. Times are RFC 3339
+// timestamps or dates, and in takes a comma-separated list.
+func parseFilters(query url.Values, fields map[string]filterField, d APIDefaults) ([]repositories.Condition, error) {
+	var keys []string
+	for key := range query {
+		if strings.HasPrefix(key, "filter") {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) > d.MaxFilters {
+		return nil, fmt.Errorf("at most %d filters are allowed", d.MaxFilters)
+	}
+	sort.Strings(keys)
+
+	conditions := make([]repositories.Condition, 0, len(keys))
+	for _, key := range keys {
+		match := filterParam.FindStringSubmatch(key)
+		if match == nil {
+			return nil, fmt.Errorf("malformed parameter %q, expected filter[field][operator]", key)
+		}
+		name, operator := match[1], repositories.Operator(match[2])
+		if operator == "" {
+			operator = repositories.OpEq
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("%s cannot be filtered on (filterable: %s)", name, strings.Join(filterableNames(fields), ", "))
+		}
+		if !supportsOperator(field, operator) {
+			return nil, fmt.Errorf("%s does not support %s (supported: %s)", name, operator, joinOperators(field.operators))
+		}
+
+		values := query[key]
+		if len(values) != 1 {
+			return nil, fmt.Errorf("%s is given more than once", key)
+		}
+		value, err := parseFilterValue(field, operator, values[0], d)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		conditions = append(conditions, repositories.Condition{Field: name, Operator: operator, Value: value})
+	}
+	return conditions, nil
+}
+
+// parseFilterValue converts a raw filter value to the field's type
+func parseFilterValue(field filterField, operator repositories.Operator, raw string, d APIDefaults) (interface{}, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("value is empty")
+	}
+	if len(raw) > d.MaxFilterValueLength {
+		return nil, fmt.Errorf("value is longer than %d bytes", d.MaxFilterValueLength)
+	}
+	raw = sanitizeString(raw)
+
+	if operator == repositories.OpIn {
+		var values []string
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		if len(values) == 0 || len(values) > d.MaxFilterInValues {
+			return nil, fmt.Errorf("in takes 1 to %d values", d.MaxFilterInValues)
+		}
+		return values, nil
+	}
+
+	if field.kind == filterTime {
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			return nil, fmt.Errorf("value must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+		}
+		return t, nil
+	}
+	return raw, nil
+}
+
+// parseSort parses a sort parameter, a comma-separated list of at most
+// d.MaxSortFields fields each optionally prefixed with - for descending
+// order, e.g. -created_at,name
+func parseSort(raw string, fields map[string]filterField, d APIDefaults) ([]repositories.SortOrder, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	names := strings.Split(raw, ",")
+	if len(names) > d.MaxSortFields {
+		return nil, fmt.Errorf("at most %d sort fields are allowed", d.MaxSortFields)
+	}
+
+	orders := make([]repositories.SortOrder, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		order := repositories.SortOrder{Field: strings.TrimPrefix(name, "-"), Descending: strings.HasPrefix(name, "-")}
+		if _, ok := fields[order.Field]; !ok {
+			return nil, fmt.Errorf("%q cannot be sorted on (sortable: %s)", order.Field, strings.Join(filterableNames(fields), ", "))
+		}
+		if seen[order.Field] {
+			return nil, fmt.Errorf("%s is given more than once", order.Field)
+		}
+		seen[order.Field] = true
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// warnUnindexedFilters logs a warning when no condition can use an index,
+// so the query has to scan the whole table
+func warnUnindexedFilters(log logger.Logger, r *http.Request, conditions []repositories.Condition, fields map[string]filterField) {
+	if len(conditions) == 0 {
+		return
+	}
+	described := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		if usesIndex(fields[condition.Field], condition.Operator) {
+			return
+		}
+		described = append(described, condition.Field+" "+string(condition.Operator))
+	}
+
+	log.WithFields(map[string]interface{}{
+		"path":    r.URL.Path,
+		"filters": strings.Join(described, ", "),
+	}).Warn("Filter cannot use an index")
+}
+
+// usesIndex reports whether a condition with operator on field can be
+// answered from an index. Case-insensitive pattern matches never can.
+func usesIndex(field filterField, operator repositories.Operator) bool {
+	if !field.indexed {
+		return false
+	}
+	switch operator {
+	case repositories.OpEq, repositories.OpIn, repositories.OpGt, repositories.OpGte, repositories.OpLt, repositories.OpLte:
+		return true
+	default:
+		return false
+	}
+}
+
+func supportsOperator(field filterField, operator repositories.Operator) bool {
+	for _, supported := range field.operators {
+		if supported == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func filterableNames(fields map[string]filterField) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func joinOperators(operators []repositories.Operator) string {
+	names := make([]string, len(operators))
+	for i, operator := range operators {
+		names[i] = string(operator)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// warnRecorder records warnings and the fields they were logged with
+type warnRecorder struct {
+	logger.Logger
+	fields   map[string]interface{}
+	warnings []string
+}
+
+func (l *warnRecorder) WithFields(fields map[string]interface{}) logger.Logger {
+	l.fields = fields
+	return l
+}
+
+func (l *warnRecorder) Warn(args ...interface{}) {
+	l.warnings = append(l.warnings, args[0].(string))
+}
+
+func TestParseFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected []repositories.Condition
+		wantErr  string
+	}{
+		{name: "none", query: "limit=5", expected: []repositories.Condition{}},
+		{
+			name:  "operators",
+			query: "filter[name][contains]=smith&filter[created_at][gte]=2024-01-01&filter[id][in]=user_1,%20user_2",
+			expected: []repositories.Condition{
+				{Field: "created_at", Operator: repositories.OpGte, Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				{Field: "id", Operator: repositories.OpIn, Value: []string{"user_1", "user_2"}},
+				{Field: "name", Operator: repositories.OpContains, Value: "smith"},
+			},
+		},
+		{
+			name:     "equality shorthand and timestamps",
+			query:    "filter[email]=jane@example.com&filter[updated_at][lt]=2024-01-01T12:00:00Z",
+			expected: []repositories.Condition{{Field: "email", Operator: repositories.OpEq, Value: "jane@example.com"}, {Field: "updated_at", Operator: repositories.OpLt, Value: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}},
+		},
+		{name: "malformed", query: "filter[name][contains][x]=a", wantErr: `malformed parameter "filter[name][contains][x]"`},
+		{name: "unknown field", query: "filter[password]=a", wantErr: "password cannot be filtered on (filterable: created_at, email, id, name, updated_at)"},
+		{name: "unsupported operator", query: "filter[created_at][contains]=2024", wantErr: "created_at does not support contains (supported: eq, gt, gte, lt, lte)"},
+		{name: "unknown operator", query: "filter[name][regex]=.*", wantErr: "name does not support regex"},
+		{name: "repeated", query: "filter[name]=a&filter[name]=b", wantErr: "filter[name] is given more than once"},
+		{name: "empty value", query: "filter[name]=", wantErr: "filter[name]: value is empty"},
+		{name: "invalid time", query: "filter[created_at][gt]=yesterday", wantErr: "filter[created_at][gt]: value must be an RFC 3339 timestamp"},
+		{name: "long value", query: "filter[name]=" + strings.Repeat("a", DefaultAPIDefaults().MaxFilterValueLength+1), wantErr: "value is longer than 256 bytes"},
+		{name: "too many in values", query: "filter[id][in]=" + strings.Repeat("a,", DefaultAPIDefaults().MaxFilterInValues+1), wantErr: "in takes 1 to 50 values"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			conditions, err := parseFilters(query, userFilterFields, DefaultAPIDefaults())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, conditions)
+		})
+	}
+}
+
+func TestParseFilters_Bounded(t *testing.T) {
+	query := url.Values{}
+	for _, field := range []string{"id", "email", "name", "created_at", "updated_at"} {
+		for _, op := range []string{"eq", "gt", "lt"} {
+			query.Set("filter["+field+"]["+op+"]", "x")
+		}
+	}
+	_, err := parseFilters(query, userFilterFields, DefaultAPIDefaults())
+	assert.EqualError(t, err, "at most 10 filters are allowed")
+}
+
+func TestParseSort(t *testing.T) {
+	orders, err := parseSort("-created_at, name", userFilterFields, DefaultAPIDefaults())
+	require.NoError(t, err)
+	assert.Equal(t, []repositories.SortOrder{{Field: "created_at", Descending: true}, {Field: "name"}}, orders)
+
+	orders, err = parseSort("", userFilterFields, DefaultAPIDefaults())
+	require.NoError(t, err)
+	assert.Nil(t, orders)
+
+	_, err = parseSort("password", userFilterFields, DefaultAPIDefaults())
+	assert.EqualError(t, err, `"password" cannot be sorted on (sortable: created_at, email, id, name, updated_at)`)
+	_, err = parseSort("name,-name", userFilterFields, DefaultAPIDefaults())
+	assert.EqualError(t, err, "name is given more than once")
+	_, err = parseSort("id,name,email,created_at", userFilterFields, DefaultAPIDefaults())
+	assert.EqualError(t, err, "at most 3 sort fields are allowed")
+}
+
+func TestWarnUnindexedFilters(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+
+	log := &warnRecorder{}
+	warnUnindexedFilters(log, req, []repositories.Condition{
+		{Field: "email", Operator: repositories.OpEq, Value: "jane@example.com"},
+		{Field: "name", Operator: repositories.OpContains, Value: "smith"},
+	}, userFilterFields)
+	assert.Empty(t, log.warnings, "the email condition can use its index")
+
+	warnUnindexedFilters(log, req, []repositories.Condition{
+		{Field: "email", Operator: repositories.OpContains, Value: "example"},
+		{Field: "created_at", Operator: repositories.OpGte, Value: time.Now()},
+	}, userFilterFields)
+	assert.Equal(t, []string{"Filter cannot use an index"}, log.warnings)
+	assert.Equal(t, "email contains, created_at gte", log.fields["filters"])
+}
+
+func TestUserHandler_ListUsers_Filter(t *testing.T) {
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("SearchUsers", mock.Anything, repositories.Specification{
+		Conditions: []repositories.Condition{{Field: "name", Operator: repositories.OpContains, Value: "smith"}},
+		Limit:      5,
+		Offset:     10,
+	}).Return([]*entities.User{{ID: "user_1", Name: "Jane Smith"}}, nil)
+	handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), &warnRecorder{})
+
+	w := httptest.NewRecorder()
+	handler.ListUsers(w, httptest.NewRequest(http.MethodGet, "/users?filter[name][contains]=smith&limit=5&offset=10", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Jane Smith")
+
+	w = httptest.NewRecorder()
+	handler.ListUsers(w, httptest.NewRequest(http.MethodGet, "/users?filter[password]=secret", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid filter: password cannot be filtered on")
+
+	mockUseCase.AssertExpectations(t)
+	mockUseCase.AssertNotCalled(t, "ListUsers", mock.Anything, mock.Anything, mock.Anything)
+}
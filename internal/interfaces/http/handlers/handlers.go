@@ -4,65 +4,118 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/go-chi/render"
+	"clean-architecture/pkg/utils"
 )
 
 // Response represents a standard API response
 type Response struct {
-	Status    string      `json:"status"`
-	Message   string      `json:"message,omitempty"`
-	Data      interface{} `json:"data,omitempty"`
-	Timestamp time.Time   `json:"timestamp"`
+	// Version is the envelope version the response is written in, omitted
+	// from version 1 envelopes; see utils.EnvelopeVersion
+	Version int    `json:"version,omitempty"`
+	Status  string `json:"status"`
+	// Code identifies the error of error responses, e.g. user_not_found;
+	// see apperrors
+	Code    string      `json:"code,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+	ErrorID string      `json:"error_id,omitempty"`
+	// Warnings describe how the request was adjusted to serve it, such as
+	// parameters that were lowered or ignored
+	Warnings []Warning `json:"warnings,omitempty"`
+	// Meta carries the pagination of lists, in version 2 envelopes, and
+	// details on how the response was produced, which are only reported in
+	// debug mode
+	Meta      *ResponseMeta `json:"meta,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
 }
 
-// HealthCheck handles health check requests
-func HealthCheck(w http.ResponseWriter, r *http.Request) {
-	response := Response{
-		Status:    "success",
-		Message:   "Service is healthy",
-		Timestamp: time.Now(),
-	}
+// ResponseMeta are the details of a response besides its data
+type ResponseMeta struct {
+	Pagination *utils.Pagination `json:"pagination,omitempty"`
+	Timings    *TimingsDTO       `json:"timings,omitempty"`
+}
 
-	render.JSON(w, r, response)
+// listMeta is the meta of a page of count items listed with limit and
+// offset
+func listMeta(limit, offset, count int) *ResponseMeta {
+	return &ResponseMeta{Pagination: utils.NewPagination(limit, offset, count)}
 }
 
-// RootHandler handles root API requests
-func RootHandler(w http.ResponseWriter, r *http.Request) {
-	response := Response{
+// TimingsDTO is where the time of a request went until its response was
+// encoded, in milliseconds. The handler includes the use cases, which
+// include the repositories.
+type TimingsDTO struct {
+	TotalMs      float64 `json:"total_ms"`
+	MiddlewareMs float64 `json:"middleware_ms"`
+	HandlerMs    float64 `json:"handler_ms"`
+	UseCaseMs    float64 `json:"usecase_ms"`
+	RepositoryMs float64 `json:"repository_ms"`
+}
+
+// Warning codes
+const (
+	// WarningParameterClamped means a parameter was out of bounds and the
+	// nearest bound was used instead
+	WarningParameterClamped = "parameter_clamped"
+	// WarningParameterIgnored means a parameter was invalid and its default
+	// was used instead
+	WarningParameterIgnored = "parameter_ignored"
+	// WarningConfirmationPending means a change was accepted but is only
+	// applied once it is confirmed
+	WarningConfirmationPending = "confirmation_pending"
+)
+
+// Warning describes an adjustment made to a request that was served anyway
+type Warning struct {
+	Code      string `json:"code"`
+	Parameter string `json:"parameter,omitempty"`
+	Message   string `json:"message"`
+}
+
+// Responses that never change apart from their timestamp are encoded once
+var (
+	healthyResponse = newStaticResponse(Response{
+		Status:  "success",
+		Message: "Service is healthy",
+	})
+	rootResponse = newStaticResponse(Response{
 		Status:  "success",
 		Message: "Clean Architecture API",
 		Data: map[string]interface{}{
-			"version": "1.0.0",
-			"docs":    "/docs",
+			"version":   "1.1.0",
+			"docs":      "/docs",
+			"changelog": "/api/v1/changelog",
+			"schemas":   "/api/v1/schemas",
 		},
-		Timestamp: time.Now(),
-	}
+	})
+	notFoundResponse = newStaticResponse(Response{
+		Status:  "error",
+		Message: "Endpoint not found",
+	})
+	methodNotAllowedResponse = newStaticResponse(Response{
+		Status:  "error",
+		Message: "Method not allowed",
+	})
+)
 
-	render.JSON(w, r, response)
+// HealthCheck handles health check requests
+func HealthCheck(w http.ResponseWriter, r *http.Request) {
+	healthyResponse.write(w, r, http.StatusOK)
+}
+
+// RootHandler handles root API requests
+func RootHandler(w http.ResponseWriter, r *http.Request) {
+	rootResponse.write(w, r, http.StatusOK)
 }
 
 // NotFoundHandler handles 404 requests
 func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
-	response := Response{
-		Status:    "error",
-		Message:   "Endpoint not found",
-		Timestamp: time.Now(),
-	}
-
-	w.WriteHeader(http.StatusNotFound)
-	render.JSON(w, r, response)
+	notFoundResponse.write(w, r, http.StatusNotFound)
 }
 
 // MethodNotAllowedHandler handles 405 requests
 func MethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
-	response := Response{
-		Status:    "error",
-		Message:   "Method not allowed",
-		Timestamp: time.Now(),
-	}
-
-	w.WriteHeader(http.StatusMethodNotAllowed)
-	render.JSON(w, r, response)
+	methodNotAllowedResponse.write(w, r, http.StatusMethodNotAllowed)
 }
 
 // UserResponse represents a user response for Swagger
@@ -82,8 +135,11 @@ type UserResponse struct {
 type ErrorResponse struct {
 	// in: body
 	Body struct {
+		Version   int    `json:"version,omitempty"`
 		Status    string `json:"status"`
+		Code      string `json:"code,omitempty"`
 		Message   string `json:"message"`
+		ErrorID   string `json:"error_id,omitempty"`
 		Timestamp string `json:"timestamp"`
 	}
 }
@@ -93,8 +149,11 @@ type ErrorResponse struct {
 type SuccessResponse struct {
 	// in: body
 	Body struct {
-		Status    string `json:"status"`
-		Message   string `json:"message"`
-		Timestamp string `json:"timestamp"`
+		Version   int           `json:"version,omitempty"`
+		Status    string        `json:"status"`
+		Message   string        `json:"message"`
+		Warnings  []Warning     `json:"warnings,omitempty"`
+		Meta      *ResponseMeta `json:"meta,omitempty"`
+		Timestamp string        `json:"timestamp"`
 	}
 }
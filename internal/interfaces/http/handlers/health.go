@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"clean-architecture/pkg/degrade"
+)
+
+// HealthCheckFunc reports whether a dependency is healthy
+type HealthCheckFunc func(ctx context.Context) error
+
+// Degradation reports dependencies that degrade features while they are
+// down instead of failing readiness
+type Degradation interface {
+	Dependencies() []degrade.DependencyStatus
+	Degraded() []string
+}
+
+// HealthHandler serves liveness and readiness probes
+type HealthHandler struct {
+	checks map[string]HealthCheckFunc
+	// timeout bounds how long a single readiness check may take
+	timeout     time.Duration
+	degradation Degradation
+	draining    atomic.Bool
+}
+
+// NewHealthHandler creates a new health handler with the given readiness checks
+func NewHealthHandler(checks map[string]HealthCheckFunc) *HealthHandler {
+	return &HealthHandler{checks: checks, timeout: DefaultAPIDefaults().HealthCheckTimeout}
+}
+
+// WithCheckTimeout replaces how long a single readiness check may take
+func (h *HealthHandler) WithCheckTimeout(timeout time.Duration) *HealthHandler {
+	h.timeout = timeout
+	return h
+}
+
+// WithDegradation reports the dependencies watched by degradation in
+// readiness responses. They never fail readiness, since the features
+// depending on them are switched off while they are down.
+func (h *HealthHandler) WithDegradation(degradation Degradation) *HealthHandler {
+	h.degradation = degradation
+	return h
+}
+
+// SetDraining marks the service as shutting down so readiness probes fail
+// and load balancers stop routing new traffic to it
+func (h *HealthHandler) SetDraining() {
+	h.draining.Store(true)
+}
+
+// aliveResponse answers every liveness probe
+var aliveResponse = newStaticResponse(Response{
+	Status:  "success",
+	Message: "Service is alive",
+})
+
+// Live handles liveness probe requests
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	aliveResponse.write(w, r, http.StatusOK)
+}
+
+// Ready handles readiness probe requests, running every dependency check
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if h.draining.Load() {
+		render.Status(r, http.StatusServiceUnavailable)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   "Service is shutting down",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make(map[string]interface{}, len(names))
+	healthy := true
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+		err := h.checks[name](ctx)
+		cancel()
+
+		if err != nil {
+			healthy = false
+			results[name] = "unavailable"
+			continue
+		}
+		results[name] = "ok"
+	}
+
+	message := "Service is ready"
+	if h.degradation != nil {
+		for _, dependency := range h.degradation.Dependencies() {
+			results[dependency.Name] = "ok"
+			if !dependency.Healthy {
+				results[dependency.Name] = "degraded"
+			}
+		}
+		if degraded := h.degradation.Degraded(); len(degraded) > 0 {
+			results["degraded_features"] = degraded
+			message = "Service is ready with degraded features"
+		}
+	}
+
+	if !healthy {
+		render.Status(r, http.StatusServiceUnavailable)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   "Service is not ready",
+			Data:      results,
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   message,
+		Data:      results,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/degrade"
+	"clean-architecture/pkg/logger"
+)
+
+func TestHealthHandler_Ready(t *testing.T) {
+	tests := []struct {
+		name           string
+		checkErr       error
+		draining       bool
+		expectedStatus int
+		expectedBody   map[string]interface{}
+	}{
+		{
+			name:           "all checks pass",
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"status": "success",
+				"data":   map[string]interface{}{"database": "ok"},
+			},
+		},
+		{
+			name:           "check fails",
+			checkErr:       assert.AnError,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: map[string]interface{}{
+				"status": "error",
+				"data":   map[string]interface{}{"database": "unavailable"},
+			},
+		},
+		{
+			name:           "draining",
+			draining:       true,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: map[string]interface{}{
+				"status":  "error",
+				"message": "Service is shutting down",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHealthHandler(map[string]HealthCheckFunc{
+				"database": func(ctx context.Context) error { return tt.checkErr },
+			})
+			if tt.draining {
+				handler.SetDraining()
+			}
+
+			w := httptest.NewRecorder()
+			handler.Ready(w, httptest.NewRequest("GET", "/health/ready", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			for key, expectedValue := range tt.expectedBody {
+				assert.Equal(t, expectedValue, response[key])
+			}
+		})
+	}
+}
+
+func TestHealthHandler_Ready_Degraded(t *testing.T) {
+	manager := degrade.NewManager(degrade.Options{Interval: time.Hour, Timeout: time.Second, FailureThreshold: 1}, logger.New())
+	manager.AddDependency("ldap", func(ctx context.Context) error { return assert.AnError })
+	manager.AddFeature("ldap_login", "ldap")
+	handler := NewHealthHandler(map[string]HealthCheckFunc{
+		"database": func(ctx context.Context) error { return nil },
+	}).WithDegradation(manager)
+
+	ready := func() map[string]interface{} {
+		w := httptest.NewRecorder()
+		handler.Ready(w, httptest.NewRequest("GET", "/health/ready", nil))
+		require.Equal(t, http.StatusOK, w.Code, "degraded dependencies do not fail readiness")
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	response := ready()
+	assert.Equal(t, "Service is ready", response["message"])
+	assert.Equal(t, map[string]interface{}{"database": "ok", "ldap": "ok"}, response["data"])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go manager.Run(ctx)
+	require.Eventually(t, func() bool { return !manager.Enabled("ldap_login") }, time.Second, time.Millisecond)
+
+	response = ready()
+	assert.Equal(t, "success", response["status"])
+	assert.Equal(t, "Service is ready with degraded features", response["message"])
+	assert.Equal(t, map[string]interface{}{
+		"database":          "ok",
+		"ldap":              "degraded",
+		"degraded_features": []interface{}{"ldap_login"},
+	}, response["data"])
+}
+
+func TestHealthHandler_Live(t *testing.T) {
+	handler := NewHealthHandler(nil)
+	handler.SetDraining()
+
+	w := httptest.NewRecorder()
+	handler.Live(w, httptest.NewRequest("GET", "/health/live", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// Headers of the resumable upload protocol, named after their tus
+// counterparts
+const (
+	UploadOffsetHeader   = "Upload-Offset"
+	UploadLengthHeader   = "Upload-Length"
+	UploadChecksumHeader = "Upload-Checksum"
+)
+
+// ChunkContentTypes are the media types accepted for upload chunks
+var ChunkContentTypes = []string{"application/offset+octet-stream", "application/octet-stream"}
+
+// ImportHandler handles chunked uploads and user import requests
+type ImportHandler struct {
+	importUseCase usecase.ImportUseCaseInterface
+	logger        logger.Logger
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(importUseCase usecase.ImportUseCaseInterface, logger logger.Logger) *ImportHandler {
+	return &ImportHandler{
+		importUseCase: importUseCase,
+		logger:        logger,
+	}
+}
+
+// CreateUploadRequest represents the request body for starting an upload
+type CreateUploadRequest struct {
+	Filename string `json:"filename,omitempty"`
+	// Size is the total length of the file in bytes
+	Size int64 `json:"size"`
+	// Checksum is the hex encoded SHA-256 of the whole file
+	Checksum string `json:"checksum"`
+}
+
+// UploadDTO is the API representation of a chunked upload
+type UploadDTO struct {
+	ID        string    `json:"id"`
+	Filename  string    `json:"filename,omitempty"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"`
+	Status    string    `json:"status"`
+	ImportID  string    `json:"import_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ImportDTO is the API representation of an import job
+type ImportDTO struct {
+	ID         string                `json:"id"`
+	UploadID   string                `json:"upload_id"`
+	Status     string                `json:"status"`
+	Rows       int64                 `json:"rows"`
+	Created    int64                 `json:"created"`
+	Skipped    int64                 `json:"skipped"`
+	Failed     int64                 `json:"failed"`
+	Errors     []usecase.ImportError `json:"errors,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	CreatedAt  time.Time             `json:"created_at"`
+	StartedAt  *time.Time            `json:"started_at,omitempty"`
+	FinishedAt *time.Time            `json:"finished_at,omitempty"`
+}
+
+// CreateUpload godoc
+// @Summary      Start an import upload
+// @Description  Start a resumable upload of a CSV file with email and name columns. Send the file in chunks, then complete the upload to import it.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CreateUploadRequest  true  "File size and checksum"
+// @Success      201      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api/v1/users/imports/uploads [post]
+func (h *ImportHandler) CreateUpload(w http.ResponseWriter, r *http.Request) {
+	var req CreateUploadRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	upload, err := h.importUseCase.CreateUpload(r.Context(), req.Filename, req.Size, req.Checksum, request.Current(r.Context()).UserID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/users/imports/uploads/"+upload.ID)
+	writeUploadHeaders(w, upload)
+	render.Status(r, http.StatusCreated)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Upload started",
+		Data:      presentUpload(upload),
+		Timestamp: time.Now(),
+	})
+}
+
+// GetUpload godoc
+// @Summary      Get an import upload
+// @Description  Get how many bytes of an upload were received. Clients resume a dropped upload from the returned offset, which is also sent in the Upload-Offset header.
+// @Tags         users
+// @Produce      json
+// @Param        id   path      string  true  "Upload ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/users/imports/uploads/{id} [get]
+func (h *ImportHandler) GetUpload(w http.ResponseWriter, r *http.Request) {
+	upload, err := h.importUseCase.GetUpload(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	// HEAD requests only need the offset headers
+	writeUploadHeaders(w, upload)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Upload retrieved successfully",
+		Data:      presentUpload(upload),
+		Timestamp: time.Now(),
+	})
+}
+
+// AppendChunk godoc
+// @Summary      Upload a chunk
+// @Description  Append the request body to an upload. Upload-Offset must equal the bytes received so far; an optional Upload-Checksum header of the form "sha256 <base64 digest>" is verified against the chunk.
+// @Tags         users
+// @Accept       application/offset+octet-stream
+// @Produce      json
+// @Param        id               path      string  true   "Upload ID"
+// @Param        Upload-Offset    header    int     true   "Offset of the chunk"
+// @Param        Upload-Checksum  header    string  false  "Chunk checksum"
+// @Success      200              {object}  SuccessResponse
+// @Failure      400              {object}  ErrorResponse
+// @Failure      404              {object}  ErrorResponse
+// @Failure      422              {object}  ErrorResponse
+// @Router       /api/v1/users/imports/uploads/{id} [patch]
+func (h *ImportHandler) AppendChunk(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	offset, err := strconv.ParseInt(r.Header.Get(UploadOffsetHeader), 10, 64)
+	if err != nil || offset < 0 {
+		badRequest(w, r, "Upload-Offset header must be a non-negative integer")
+		return
+	}
+
+	// A malformed header is a bad request rather than an invalid upload
+	digest, err := parseUploadChecksum(r.Header.Get(UploadChecksumHeader))
+	if err != nil {
+		badRequest(w, r, err.Error())
+		return
+	}
+
+	upload, err := h.importUseCase.AppendChunk(r.Context(), id, offset, r.Body, digest)
+	if err != nil {
+		// Tell the client where to resume
+		if errors.Is(err, usecase.ErrUploadOffsetMismatch) {
+			if current, getErr := h.importUseCase.GetUpload(r.Context(), id); getErr == nil {
+				writeUploadHeaders(w, current)
+			}
+		}
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	writeUploadHeaders(w, upload)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Chunk received",
+		Data:      presentUpload(upload),
+		Timestamp: time.Now(),
+	})
+}
+
+// CompleteUpload godoc
+// @Summary      Import an uploaded file
+// @Description  Queue the import of a fully received upload. The file's checksum is verified before any user is created. Poll the returned import for progress.
+// @Tags         users
+// @Produce      json
+// @Param        id   path      string  true  "Upload ID"
+// @Success      202  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/users/imports/uploads/{id}/complete [post]
+func (h *ImportHandler) CompleteUpload(w http.ResponseWriter, r *http.Request) {
+	imp, err := h.importUseCase.CompleteUpload(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/users/imports/"+imp.Job.ID)
+	render.Status(r, http.StatusAccepted)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Import queued",
+		Data:      presentImport(imp),
+		Timestamp: time.Now(),
+	})
+}
+
+// AbortUpload godoc
+// @Summary      Abort an import upload
+// @Description  Discard an unfinished upload and the chunks received so far
+// @Tags         users
+// @Produce      json
+// @Param        id   path      string  true  "Upload ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/users/imports/uploads/{id} [delete]
+func (h *ImportHandler) AbortUpload(w http.ResponseWriter, r *http.Request) {
+	if err := h.importUseCase.AbortUpload(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Upload aborted",
+		Timestamp: time.Now(),
+	})
+}
+
+// GetImport godoc
+// @Summary      Get a user import
+// @Description  Get the progress of an import job, including counts of created, skipped and failed rows
+// @Tags         users
+// @Produce      json
+// @Param        id      path      string  true   "Import job ID"
+// @Param        fields  query     string  false  "Comma-separated fields to return"
+// @Success      200     {object}  SuccessResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Router       /api/v1/users/imports/{id} [get]
+func (h *ImportHandler) GetImport(w http.ResponseWriter, r *http.Request) {
+	fields, ok := requestFields(w, r, ImportDTO{})
+	if !ok {
+		return
+	}
+
+	imp, err := h.importUseCase.GetImport(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Import retrieved successfully",
+		Data:      fields.apply(presentImport(imp)),
+		Timestamp: time.Now(),
+	})
+}
+
+// parseUploadChecksum parses an Upload-Checksum header of the form
+// "sha256 <base64 digest>". An empty header yields no digest.
+func parseUploadChecksum(header string) ([]byte, error) {
+	if header == "" {
+		return nil, nil
+	}
+	algorithm, encoded, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(algorithm, "sha256") {
+		return nil, usecase.ErrInvalidChecksum
+	}
+	digest, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, usecase.ErrInvalidChecksum
+	}
+	return digest, nil
+}
+
+// writeUploadHeaders reports the progress of an upload in headers
+func writeUploadHeaders(w http.ResponseWriter, upload *entities.Upload) {
+	w.Header().Set(UploadOffsetHeader, strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set(UploadLengthHeader, strconv.FormatInt(upload.Size, 10))
+	w.Header().Set("Cache-Control", "no-store")
+}
+
+func presentUpload(upload *entities.Upload) UploadDTO {
+	return UploadDTO{
+		ID:        upload.ID,
+		Filename:  upload.Filename,
+		Size:      upload.Size,
+		Offset:    upload.Offset,
+		Status:    upload.Status,
+		ImportID:  upload.JobID,
+		CreatedAt: upload.CreatedAt,
+		ExpiresAt: upload.ExpiresAt,
+	}
+}
+
+func presentImport(imp *usecase.Import) ImportDTO {
+	job := imp.Job
+	return ImportDTO{
+		ID:         job.ID,
+		UploadID:   imp.UploadID,
+		Status:     job.Status,
+		Rows:       imp.Rows,
+		Created:    imp.Created,
+		Skipped:    imp.Skipped,
+		Failed:     imp.Failed,
+		Errors:     imp.Errors,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
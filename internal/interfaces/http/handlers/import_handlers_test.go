@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockImportUseCase is a mock implementation of ImportUseCaseInterface
+type MockImportUseCase struct {
+	mock.Mock
+}
+
+func (m *MockImportUseCase) CreateUpload(ctx context.Context, filename string, size int64, checksum, createdBy string) (*entities.Upload, error) {
+	args := m.Called(ctx, filename, size, checksum, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Upload), args.Error(1)
+}
+
+func (m *MockImportUseCase) GetUpload(ctx context.Context, id string) (*entities.Upload, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Upload), args.Error(1)
+}
+
+func (m *MockImportUseCase) AppendChunk(ctx context.Context, id string, offset int64, r io.Reader, digest []byte) (*entities.Upload, error) {
+	body, _ := io.ReadAll(r)
+	args := m.Called(ctx, id, offset, string(body), digest)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Upload), args.Error(1)
+}
+
+func (m *MockImportUseCase) CompleteUpload(ctx context.Context, id string) (*usecase.Import, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.Import), args.Error(1)
+}
+
+func (m *MockImportUseCase) AbortUpload(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockImportUseCase) GetImport(ctx context.Context, id string) (*usecase.Import, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.Import), args.Error(1)
+}
+
+func newImportRouter(uc usecase.ImportUseCaseInterface) http.Handler {
+	handler := NewImportHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Post("/users/imports/uploads", handler.CreateUpload)
+	r.Head("/users/imports/uploads/{id}", handler.GetUpload)
+	r.Patch("/users/imports/uploads/{id}", handler.AppendChunk)
+	r.Post("/users/imports/uploads/{id}/complete", handler.CompleteUpload)
+	r.Get("/users/imports/{id}", handler.GetImport)
+	return r
+}
+
+func TestImportHandler_CreateUpload(t *testing.T) {
+	mockUseCase := new(MockImportUseCase)
+	mockUseCase.On("CreateUpload", mock.Anything, "users.csv", int64(2048), "abc123", "user_1").
+		Return(&entities.Upload{ID: "upl_1", Size: 2048, Status: entities.UploadActive}, nil)
+
+	w := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"filename":"users.csv","size":2048,"checksum":"abc123"}`)
+	req := withSubject(httptest.NewRequest("POST", "/users/imports/uploads", body), "user_1")
+	newImportRouter(mockUseCase).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/api/v1/users/imports/uploads/upl_1", w.Header().Get("Location"))
+	assert.Equal(t, "0", w.Header().Get(UploadOffsetHeader))
+	assert.Equal(t, "2048", w.Header().Get(UploadLengthHeader))
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestImportHandler_AppendChunk(t *testing.T) {
+	chunk := "email,name\n"
+	digest := sha256.Sum256([]byte(chunk))
+
+	mockUseCase := new(MockImportUseCase)
+	mockUseCase.On("AppendChunk", mock.Anything, "upl_1", int64(0), chunk, digest[:]).
+		Return(&entities.Upload{ID: "upl_1", Size: 2048, Offset: int64(len(chunk))}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PATCH", "/users/imports/uploads/upl_1", bytes.NewBufferString(chunk))
+	req.Header.Set(UploadOffsetHeader, "0")
+	req.Header.Set(UploadChecksumHeader, "sha256 "+base64.StdEncoding.EncodeToString(digest[:]))
+	newImportRouter(mockUseCase).ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "11", w.Header().Get(UploadOffsetHeader))
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestImportHandler_AppendChunk_OffsetMismatch(t *testing.T) {
+	mockUseCase := new(MockImportUseCase)
+	mockUseCase.On("AppendChunk", mock.Anything, "upl_1", int64(0), "data", []byte(nil)).
+		Return(nil, usecase.ErrUploadOffsetMismatch)
+	mockUseCase.On("GetUpload", mock.Anything, "upl_1").
+		Return(&entities.Upload{ID: "upl_1", Size: 2048, Offset: 512}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PATCH", "/users/imports/uploads/upl_1", bytes.NewBufferString("data"))
+	req.Header.Set(UploadOffsetHeader, "0")
+	newImportRouter(mockUseCase).ServeHTTP(w, req)
+
+	var response Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "error", response.Status)
+	assert.Equal(t, usecase.ErrUploadOffsetMismatch.Error(), response.Message)
+	assert.Equal(t, "512", w.Header().Get(UploadOffsetHeader))
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestImportHandler_AppendChunk_InvalidHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		offset   string
+		checksum string
+		message  string
+	}{
+		{name: "missing offset", offset: "", message: "Upload-Offset header must be a non-negative integer"},
+		{name: "negative offset", offset: "-1", message: "Upload-Offset header must be a non-negative integer"},
+		{name: "unsupported checksum", offset: "0", checksum: "md5 abc", message: usecase.ErrInvalidChecksum.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockImportUseCase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/users/imports/uploads/upl_1", bytes.NewBufferString("data"))
+			req.Header.Set(UploadOffsetHeader, tt.offset)
+			if tt.checksum != "" {
+				req.Header.Set(UploadChecksumHeader, tt.checksum)
+			}
+			newImportRouter(mockUseCase).ServeHTTP(w, req)
+
+			var response Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.message, response.Message)
+			mockUseCase.AssertNotCalled(t, "AppendChunk")
+		})
+	}
+}
+
+func TestImportHandler_GetUpload_Head(t *testing.T) {
+	mockUseCase := new(MockImportUseCase)
+	mockUseCase.On("GetUpload", mock.Anything, "upl_1").
+		Return(&entities.Upload{ID: "upl_1", Size: 2048, Offset: 1024}, nil)
+
+	w := httptest.NewRecorder()
+	newImportRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("HEAD", "/users/imports/uploads/upl_1", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1024", w.Header().Get(UploadOffsetHeader))
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+}
+
+func TestImportHandler_CompleteUpload(t *testing.T) {
+	mockUseCase := new(MockImportUseCase)
+	mockUseCase.On("CompleteUpload", mock.Anything, "upl_1").Return(&usecase.Import{
+		Job:      &entities.Job{ID: "job_1", Status: entities.JobQueued},
+		UploadID: "upl_1",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newImportRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("POST", "/users/imports/uploads/upl_1/complete", nil))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/api/v1/users/imports/job_1", w.Header().Get("Location"))
+
+	var response struct {
+		Data ImportDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "upl_1", response.Data.UploadID)
+	assert.Equal(t, entities.JobQueued, response.Data.Status)
+}
+
+func TestImportHandler_GetImport(t *testing.T) {
+	mockUseCase := new(MockImportUseCase)
+	mockUseCase.On("GetImport", mock.Anything, "job_1").Return(&usecase.Import{
+		Job:     &entities.Job{ID: "job_1", Status: entities.JobSucceeded},
+		Rows:    3,
+		Created: 2,
+		Failed:  1,
+		Errors:  []usecase.ImportError{{Row: 2, Error: "email is required"}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newImportRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/users/imports/job_1", nil))
+
+	var response struct {
+		Data ImportDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(2), response.Data.Created)
+	assert.Equal(t, int64(1), response.Data.Failed)
+	require.Len(t, response.Data.Errors, 1)
+	assert.Equal(t, int64(2), response.Data.Errors[0].Row)
+}
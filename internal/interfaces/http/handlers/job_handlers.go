@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// JobHandler handles requests about background jobs
+type JobHandler struct {
+	jobUseCase usecase.JobUseCaseInterface
+	logger     logger.Logger
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobUseCase usecase.JobUseCaseInterface, logger logger.Logger) *JobHandler {
+	return &JobHandler{
+		jobUseCase: jobUseCase,
+		logger:     logger,
+	}
+}
+
+// JobDTO is the API representation of a background job
+type JobDTO struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	// Result describes what a succeeded job produced, such as the user_id
+	// of the user it created
+	Result     map[string]string `json:"result,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Attempts   int               `json:"attempts"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	ExpiresAt  *time.Time        `json:"expires_at,omitempty"`
+}
+
+// GetJob godoc
+// @Summary      Get a background job
+// @Description  Get the status of a job requested by the caller, such as a user creation answered with 202. Succeeded jobs include their result.
+// @Tags         jobs
+// @Produce      json
+// @Param        id      path      string  true   "Job ID"
+// @Param        fields  query     string  false  "Comma-separated fields to return"
+// @Success      200     {object}  SuccessResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Router       /api/v1/jobs/{id} [get]
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	fields, ok := requestFields(w, r, JobDTO{})
+	if !ok {
+		return
+	}
+
+	job, err := h.jobUseCase.GetJob(r.Context(), chi.URLParam(r, "id"), request.Current(r.Context()).UserID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Job retrieved successfully",
+		Data:      fields.apply(presentJob(job)),
+		Timestamp: time.Now(),
+	})
+}
+
+func presentJob(job *entities.Job) JobDTO {
+	return JobDTO{
+		ID:         job.ID,
+		Type:       job.Type,
+		Status:     job.Status,
+		Result:     job.Result,
+		Error:      job.Error,
+		Attempts:   job.Attempts,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+		ExpiresAt:  job.ExpiresAt,
+	}
+}
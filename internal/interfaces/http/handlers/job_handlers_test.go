@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockJobUseCase is a mock implementation of JobUseCaseInterface
+type MockJobUseCase struct {
+	mock.Mock
+}
+
+func (m *MockJobUseCase) GetJob(ctx context.Context, id, requestedBy string) (*entities.Job, error) {
+	args := m.Called(ctx, id, requestedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Job), args.Error(1)
+}
+
+func newJobRouter(uc usecase.JobUseCaseInterface) http.Handler {
+	handler := NewJobHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Get("/jobs/{id}", handler.GetJob)
+	return r
+}
+
+func TestJobHandler_GetJob(t *testing.T) {
+	uc := new(MockJobUseCase)
+	job := entities.NewJob(usecase.JobTypeUserCreate, "user_1", map[string]string{"email": "ada@example.com"})
+	job.ID = "job_1"
+	job.Complete(map[string]string{"user_id": "user_2"}, job.CreatedAt)
+	uc.On("GetJob", mock.Anything, "job_1", "user_1").Return(job, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs/job_1", nil)
+	req = req.WithContext(request.With(req.Context(), &request.Context{UserID: "user_1"}))
+	w := httptest.NewRecorder()
+	newJobRouter(uc).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, entities.JobSucceeded, response.Data["status"])
+	assert.Equal(t, map[string]interface{}{"user_id": "user_2"}, response.Data["result"])
+	assert.NotContains(t, response.Data, "params", "job parameters are not exposed")
+	uc.AssertExpectations(t)
+}
+
+func TestJobHandler_GetJob_NotFound(t *testing.T) {
+	uc := new(MockJobUseCase)
+	uc.On("GetJob", mock.Anything, "job_1", "").Return(nil, repositories.ErrJobNotFound)
+
+	w := httptest.NewRecorder()
+	newJobRouter(uc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs/job_1", nil))
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "error", response["status"])
+	assert.Equal(t, repositories.ErrJobNotFound.Error(), response["message"])
+	uc.AssertExpectations(t)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"clean-architecture/pkg/distlock"
+)
+
+// LeadershipHandler reports which instance holds each singleton lock
+type LeadershipHandler struct {
+	elections *distlock.Elections
+}
+
+// NewLeadershipHandler creates a new leadership status handler
+func NewLeadershipHandler(elections *distlock.Elections) *LeadershipHandler {
+	return &LeadershipHandler{elections: elections}
+}
+
+// Status handles leadership status requests
+func (h *LeadershipHandler) Status(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Leadership status retrieved successfully",
+		Data:      h.elections.Statuses(r.Context()),
+		Timestamp: time.Now(),
+	})
+}
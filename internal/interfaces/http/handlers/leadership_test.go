@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/distlock"
+	"clean-architecture/pkg/logger"
+)
+
+func TestLeadershipHandler_Status(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	elections := distlock.NewElections(distlock.NewMemoryLocker(), "instance-a", 10*time.Millisecond, logger.New())
+	scheduler := elections.Elector("scheduler")
+
+	started := make(chan struct{})
+	go scheduler.Run(ctx, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	})
+	<-started
+
+	handler := NewLeadershipHandler(elections)
+	w := httptest.NewRecorder()
+	handler.Status(w, httptest.NewRequest("GET", "/leaders", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Status string            `json:"status"`
+		Data   []distlock.Status `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "success", body.Status)
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, "scheduler", body.Data[0].Lock)
+	assert.Equal(t, "instance-a", body.Data[0].Holder)
+	assert.True(t, body.Data[0].Leader)
+}
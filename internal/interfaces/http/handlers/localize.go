@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// messageFiles holds the translations of response messages: in
+// messages/<locale>.json, an object from each English message to its
+// translation. English is what the handlers are written in, so it needs no
+// file, and messages missing from a locale stay English.
+//
+//go:embed messages/*.json
+var messageFiles embed.FS
+
+// messageCatalogs are the translations of every locale, by locale
+var messageCatalogs = loadMessageCatalogs()
+
+func loadMessageCatalogs() map[string]map[string]string {
+	files, err := messageFiles.ReadDir("messages")
+	if err != nil {
+		panic("handlers: message catalogs cannot be read: " + err.Error())
+	}
+	catalogs := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := messageFiles.ReadFile(path.Join("messages", file.Name()))
+		if err != nil {
+			panic("handlers: message catalog cannot be read: " + err.Error())
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic("handlers: message catalog " + file.Name() + " is invalid: " + err.Error())
+		}
+		catalogs[strings.ToLower(strings.TrimSuffix(file.Name(), ".json"))] = catalog
+	}
+	return catalogs
+}
+
+// MessageLocales returns the locales response messages are translated
+// into, English first
+func MessageLocales() []string {
+	locales := make([]string, 0, len(messageCatalogs)+1)
+	for locale := range messageCatalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return append([]string{"en"}, locales...)
+}
+
+// localizeMessage translates msg into locale, or into its base language
+// when the region has no catalog of its own
+func localizeMessage(locale, msg string) string {
+	if msg == "" {
+		return msg
+	}
+	catalog, ok := messageCatalogs[locale]
+	if !ok {
+		language, _, _ := strings.Cut(locale, "-")
+		catalog = messageCatalogs[language]
+	}
+	if translated, ok := catalog[msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// localizeTimes rewrites the RFC 3339 timestamps among the strings of the
+// encoded JSON b into loc, leaving the instants they name unchanged. Zero
+// times are kept as they are, since they stand for no time at all.
+func localizeTimes(b []byte, loc *time.Location) []byte {
+	// The shortest timestamp is "2006-01-02T15:04:05Z", the longest has
+	// nanoseconds and an offset
+	const minLen, maxLen = len("2006-01-02T15:04:05Z"), len("2006-01-02T15:04:05.999999999-07:00")
+
+	out := make([]byte, 0, len(b)+len(b)/8)
+	start := 0
+	for i := 0; i < len(b); i++ {
+		if b[i] != '"' {
+			continue
+		}
+		// Find the closing quote; escaped strings are never timestamps
+		end, escaped := i+1, false
+		for end < len(b) && b[end] != '"' {
+			if b[end] == '\\' {
+				escaped = true
+				end++
+			}
+			end++
+		}
+		if end >= len(b) {
+			break
+		}
+		if s := b[i+1 : end]; !escaped && len(s) >= minLen && len(s) <= maxLen && s[4] == '-' && s[10] == 'T' {
+			if t, err := time.Parse(time.RFC3339Nano, string(s)); err == nil && !t.IsZero() {
+				out = append(out, b[start:i+1]...)
+				out = t.In(loc).AppendFormat(out, time.RFC3339Nano)
+				start = end
+			}
+		}
+		i = end
+	}
+	return append(out, b[start:]...)
+}
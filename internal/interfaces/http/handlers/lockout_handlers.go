@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// LockoutHandler handles administrators lifting login lockouts
+type LockoutHandler struct {
+	lockoutUseCase usecase.LockoutUseCaseInterface
+	logger         logger.Logger
+}
+
+// NewLockoutHandler creates a new lockout handler
+func NewLockoutHandler(lockoutUseCase usecase.LockoutUseCaseInterface, logger logger.Logger) *LockoutHandler {
+	return &LockoutHandler{
+		lockoutUseCase: lockoutUseCase,
+		logger:         logger,
+	}
+}
+
+// UnlockUser godoc
+// @Summary      Lift a username's login lockout
+// @Description  Let a username log in again before its lock expires and forget its failed logins. Succeeds when the username is not locked.
+// @Tags         lockouts
+// @Produce      json
+// @Param        username  path      string  true  "Username"
+// @Success      200       {object}  SuccessResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /api/v1/lockouts/users/{username} [delete]
+func (h *LockoutHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	if err := h.lockoutUseCase.Unlock(r.Context(), chi.URLParam(r, "username")); err != nil {
+		InternalError(w, r, h.logger, err)
+		return
+	}
+	h.lifted(w, r)
+}
+
+// UnlockIP godoc
+// @Summary      Lift a client address's login lockout
+// @Description  Let a client address log in again before its lock expires and forget its failed logins. Succeeds when the address is not locked.
+// @Tags         lockouts
+// @Produce      json
+// @Param        ip   path      string  true  "Client IP address"
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      422  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/lockouts/ips/{ip} [delete]
+func (h *LockoutHandler) UnlockIP(w http.ResponseWriter, r *http.Request) {
+	ip := net.ParseIP(chi.URLParam(r, "ip"))
+	if ip == nil {
+		unprocessable(w, r, "invalid_ip", "ip must be an IP address")
+		return
+	}
+	if err := h.lockoutUseCase.UnlockIP(r.Context(), ip.String()); err != nil {
+		InternalError(w, r, h.logger, err)
+		return
+	}
+	h.lifted(w, r)
+}
+
+func (h *LockoutHandler) lifted(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Login lockout lifted",
+		Timestamp: time.Now(),
+	})
+}
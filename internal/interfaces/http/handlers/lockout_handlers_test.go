@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockLockoutUseCase is a mock implementation of LockoutUseCaseInterface
+type MockLockoutUseCase struct {
+	mock.Mock
+}
+
+func (m *MockLockoutUseCase) Unlock(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockLockoutUseCase) UnlockIP(ctx context.Context, ip string) error {
+	args := m.Called(ctx, ip)
+	return args.Error(0)
+}
+
+func newLockoutRouter(uc usecase.LockoutUseCaseInterface) http.Handler {
+	handler := NewLockoutHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Delete("/lockouts/users/{username}", handler.UnlockUser)
+	r.Delete("/lockouts/ips/{ip}", handler.UnlockIP)
+	return r
+}
+
+func TestLockoutHandler_UnlockUser(t *testing.T) {
+	mockUseCase := new(MockLockoutUseCase)
+	mockUseCase.On("Unlock", mock.Anything, "bjensen").Return(nil)
+
+	w := httptest.NewRecorder()
+	newLockoutRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("DELETE", "/lockouts/users/bjensen", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Login lockout lifted")
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestLockoutHandler_UnlockUser_StoreError(t *testing.T) {
+	mockUseCase := new(MockLockoutUseCase)
+	mockUseCase.On("Unlock", mock.Anything, "bjensen").Return(errors.New("connection refused"))
+
+	w := httptest.NewRecorder()
+	newLockoutRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("DELETE", "/lockouts/users/bjensen", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestLockoutHandler_UnlockIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		ip             string
+		expected       string
+		expectedStatus int
+	}{
+		{name: "IPv4", ip: "192.0.2.1", expected: "192.0.2.1", expectedStatus: http.StatusOK},
+		{name: "IPv6 is normalized", ip: "2001:DB8:0:0::1", expected: "2001:db8::1", expectedStatus: http.StatusOK},
+		{name: "not an address", ip: "example.com", expectedStatus: http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockLockoutUseCase)
+			if tt.expected != "" {
+				mockUseCase.On("UnlockIP", mock.Anything, tt.expected).Return(nil)
+			}
+
+			w := httptest.NewRecorder()
+			newLockoutRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("DELETE", "/lockouts/ips/"+tt.ip, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/pkg/logger"
+)
+
+// LogLevelHandler reports and changes the log levels of the application's
+// modules at runtime
+type LogLevelHandler struct {
+	logs   *logger.Registry
+	logger logger.Logger
+}
+
+// NewLogLevelHandler creates a new log level handler
+func NewLogLevelHandler(logs *logger.Registry, logger logger.Logger) *LogLevelHandler {
+	return &LogLevelHandler{logs: logs, logger: logger}
+}
+
+// SetLogLevelRequest represents the request body for changing a level
+type SetLogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// ListLevels handles listing the level of the root logger and each module
+func (h *LogLevelHandler) ListLevels(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Log levels retrieved successfully",
+		Data:      h.logs.Levels(),
+		Timestamp: time.Now(),
+	})
+}
+
+// SetLevel handles changing the level of a module until the next restart
+func (h *LogLevelHandler) SetLevel(w http.ResponseWriter, r *http.Request) {
+	var req SetLogLevelRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	module := chi.URLParam(r, "module")
+	previous, err := h.logs.Level(module)
+	if err == nil {
+		err = h.logs.SetLevel(module, req.Level)
+	}
+	switch {
+	case errors.Is(err, logger.ErrUnknownModule):
+		render.Status(r, http.StatusNotFound)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   "Unknown log module",
+			Timestamp: time.Now(),
+		})
+		return
+	case errors.Is(err, logger.ErrUnknownLevel):
+		unprocessable(w, r, "invalid_log_level", "level must be one of debug, info, warn or error")
+		return
+	case err != nil:
+		InternalError(w, r, h.logger, err)
+		return
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"module":   module,
+		"level":    req.Level,
+		"previous": previous,
+	}).Info("Log level changed")
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Log level changed successfully",
+		Data:      logger.ModuleLevel{Module: module, Level: req.Level},
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/logger"
+)
+
+// serveLogLevels routes req to the log level endpoints of a handler for logs
+func serveLogLevels(logs *logger.Registry, req *http.Request) *httptest.ResponseRecorder {
+	handler := NewLogLevelHandler(logs, logger.New())
+	r := chi.NewRouter()
+	r.Get("/loggers", handler.ListLevels)
+	r.Put("/loggers/{module}", handler.SetLevel)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLogLevelHandler_ListLevels(t *testing.T) {
+	logs := logger.NewRegistry("info")
+	logs.Logger("database")
+
+	w := serveLogLevels(logs, httptest.NewRequest("GET", "/loggers", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data []logger.ModuleLevel `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []logger.ModuleLevel{
+		{Module: logger.Root, Level: "info"},
+		{Module: "database", Level: "info"},
+	}, body.Data)
+}
+
+func TestLogLevelHandler_SetLevel(t *testing.T) {
+	logs := logger.NewRegistry("info")
+	logs.Logger("database")
+
+	w := serveLogLevels(logs, httptest.NewRequest("PUT", "/loggers/database", strings.NewReader(`{"level":"debug"}`)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"level":"debug"`)
+	level, err := logs.Level("database")
+	require.NoError(t, err)
+	assert.Equal(t, "debug", level)
+
+	w = serveLogLevels(logs, httptest.NewRequest("PUT", "/loggers/root", strings.NewReader(`{"level":"warn"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	level, err = logs.Level(logger.Root)
+	require.NoError(t, err)
+	assert.Equal(t, "warn", level)
+}
+
+func TestLogLevelHandler_SetLevel_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		module          string
+		body            string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "unknown module",
+			module:          "cache",
+			body:            `{"level":"debug"}`,
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "Unknown log module",
+		},
+		{
+			name:            "unknown level",
+			module:          "database",
+			body:            `{"level":"verbose"}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "level must be one of debug, info, warn or error",
+		},
+		{
+			name:           "malformed body",
+			module:         "database",
+			body:           `{"level":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := logger.NewRegistry("info")
+			logs.Logger("database")
+
+			w := serveLogLevels(logs, httptest.NewRequest("PUT", "/loggers/"+tt.module, strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedMessage != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedMessage)
+			}
+			level, err := logs.Level("database")
+			require.NoError(t, err)
+			assert.Equal(t, "info", level)
+		})
+	}
+}
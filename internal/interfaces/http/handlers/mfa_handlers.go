@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MFAHandler handles users managing their own TOTP multi-factor
+// authentication
+type MFAHandler struct {
+	mfaUseCase usecase.MFAUseCaseInterface
+	logger     logger.Logger
+}
+
+// NewMFAHandler creates a new MFA handler
+func NewMFAHandler(mfaUseCase usecase.MFAUseCaseInterface, logger logger.Logger) *MFAHandler {
+	return &MFAHandler{
+		mfaUseCase: mfaUseCase,
+		logger:     logger,
+	}
+}
+
+// MFAStatusDTO is whether the current user enabled MFA
+type MFAStatusDTO struct {
+	Enabled bool `json:"enabled"`
+	// Pending reports an enrollment that was not confirmed with a code
+	Pending bool `json:"pending"`
+}
+
+// MFAEnrollmentDTO is the response data of enrolling in MFA. The secret
+// cannot be retrieved again.
+type MFAEnrollmentDTO struct {
+	// Secret is the base32 secret for entering into an authenticator app
+	// by hand
+	Secret string `json:"secret"`
+	// URI is the otpauth:// URI for showing as a QR code
+	URI string `json:"uri"`
+}
+
+// MFACodeRequest represents the request body for enabling or disabling MFA
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// GetMFA godoc
+// @Summary      Get the current user's MFA status
+// @Description  Report whether the authenticated user enabled multi-factor authentication, or started enrolling
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/me/mfa [get]
+func (h *MFAHandler) GetMFA(w http.ResponseWriter, r *http.Request) {
+	subject, ok := requireSubject(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.mfaUseCase.Status(r.Context(), subject.ID)
+	if err != nil {
+		h.mfaError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "MFA status retrieved successfully",
+		Data:      MFAStatusDTO{Enabled: status.Enabled, Pending: status.Pending},
+		Timestamp: time.Now(),
+	})
+}
+
+// EnrollMFA godoc
+// @Summary      Enroll in MFA
+// @Description  Generate a TOTP secret for the authenticated user's authenticator app. It takes effect once confirmed with POST /api/v1/me/mfa:enable; enrolling again replaces an unconfirmed secret. The secret is only returned in this response.
+// @Tags         users
+// @Produce      json
+// @Success      201  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      409  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/me/mfa [post]
+func (h *MFAHandler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	subject, ok := requireSubject(w, r)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.mfaUseCase.Enroll(r.Context(), subject.ID)
+	if err != nil {
+		h.mfaError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	render.Status(r, http.StatusCreated)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "MFA enrollment started; confirm it with a one-time code",
+		Data:      MFAEnrollmentDTO{Secret: enrollment.Secret, URI: enrollment.URI},
+		Timestamp: time.Now(),
+	})
+}
+
+// EnableMFA godoc
+// @Summary      Enable MFA
+// @Description  Confirm the enrolled TOTP secret with a one-time code. Password logins of the authenticated user require a code from then on.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFACodeRequest  true  "One-time code"
+// @Success      200      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api/v1/me/mfa:enable [post]
+func (h *MFAHandler) EnableMFA(w http.ResponseWriter, r *http.Request) {
+	h.withCode(w, r, h.mfaUseCase.Enable, "MFA enabled")
+}
+
+// DisableMFA godoc
+// @Summary      Disable MFA
+// @Description  Remove the authenticated user's TOTP secret. A current one-time code is required, so a stolen session alone cannot disable MFA.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      MFACodeRequest  true  "One-time code"
+// @Success      200      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      409      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api/v1/me/mfa:disable [post]
+func (h *MFAHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	h.withCode(w, r, h.mfaUseCase.Disable, "MFA disabled")
+}
+
+// withCode runs change with the one-time code of the request body and
+// responds with the user's new status
+func (h *MFAHandler) withCode(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, userID, code string) error, message string) {
+	subject, ok := requireSubject(w, r)
+	if !ok {
+		return
+	}
+	var req MFACodeRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if req.Code == "" {
+		unprocessable(w, r, "code_required", "code is required")
+		return
+	}
+
+	if err := change(r.Context(), subject.ID, req.Code); err != nil {
+		h.mfaError(w, r, err)
+		return
+	}
+	status, err := h.mfaUseCase.Status(r.Context(), subject.ID)
+	if err != nil {
+		h.mfaError(w, r, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   message,
+		Data:      MFAStatusDTO{Enabled: status.Enabled, Pending: status.Pending},
+		Timestamp: time.Now(),
+	})
+}
+
+// mfaError writes the response for a failed MFA operation
+func (h *MFAHandler) mfaError(w http.ResponseWriter, r *http.Request, err error) {
+	var status int
+	var message string
+	switch {
+	case errors.Is(err, usecase.ErrInvalidMFACode):
+		status, message = http.StatusUnprocessableEntity, "Invalid one-time code"
+	case errors.Is(err, usecase.ErrMFAAlreadyEnabled), errors.Is(err, usecase.ErrMFANotEnrolled),
+		errors.Is(err, usecase.ErrMFANotEnabled):
+		status, message = http.StatusConflict, err.Error()
+	default:
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, status)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockMFAUseCase is a mock implementation of MFAUseCaseInterface
+type MockMFAUseCase struct {
+	mock.Mock
+}
+
+func (m *MockMFAUseCase) Status(ctx context.Context, userID string) (*usecase.MFAStatus, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.MFAStatus), args.Error(1)
+}
+
+func (m *MockMFAUseCase) Enroll(ctx context.Context, userID string) (*usecase.MFAEnrollment, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.MFAEnrollment), args.Error(1)
+}
+
+func (m *MockMFAUseCase) Enable(ctx context.Context, userID, code string) error {
+	args := m.Called(ctx, userID, code)
+	return args.Error(0)
+}
+
+func (m *MockMFAUseCase) Disable(ctx context.Context, userID, code string) error {
+	args := m.Called(ctx, userID, code)
+	return args.Error(0)
+}
+
+func newMFARouter(uc usecase.MFAUseCaseInterface) http.Handler {
+	handler := NewMFAHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Get("/me/mfa", handler.GetMFA)
+	r.Post("/me/mfa", handler.EnrollMFA)
+	r.Post("/me/mfa:enable", handler.EnableMFA)
+	r.Post("/me/mfa:disable", handler.DisableMFA)
+	return r
+}
+
+func TestMFAHandler_EnrollMFA(t *testing.T) {
+	mockUseCase := new(MockMFAUseCase)
+	mockUseCase.On("Enroll", mock.Anything, "user_1").Return(&usecase.MFAEnrollment{
+		Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		URI:    "otpauth://totp/Example:bjensen%40example.com?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newMFARouter(mockUseCase).ServeHTTP(w, withSubject(httptest.NewRequest("POST", "/me/mfa", nil), "user_1"))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	var body struct {
+		Data MFAEnrollmentDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", body.Data.Secret)
+	assert.Contains(t, body.Data.URI, "otpauth://totp/")
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestMFAHandler_EnableMFA(t *testing.T) {
+	mockUseCase := new(MockMFAUseCase)
+	mockUseCase.On("Enable", mock.Anything, "user_1", "123456").Return(nil)
+	mockUseCase.On("Status", mock.Anything, "user_1").Return(&usecase.MFAStatus{Enabled: true}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/me/mfa:enable", bytes.NewBufferString(`{"code":"123456"}`))
+	newMFARouter(mockUseCase).ServeHTTP(w, withSubject(req, "user_1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data MFAStatusDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Data.Enabled)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestMFAHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		body            string
+		err             error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "missing code",
+			path:            "/me/mfa:enable",
+			body:            `{}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "code is required",
+		},
+		{
+			name:            "invalid code",
+			path:            "/me/mfa:enable",
+			body:            `{"code":"000000"}`,
+			err:             usecase.ErrInvalidMFACode,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "Invalid one-time code",
+		},
+		{
+			name:            "not enrolled",
+			path:            "/me/mfa:enable",
+			body:            `{"code":"000000"}`,
+			err:             usecase.ErrMFANotEnrolled,
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: usecase.ErrMFANotEnrolled.Error(),
+		},
+		{
+			name:            "not enabled",
+			path:            "/me/mfa:disable",
+			body:            `{"code":"000000"}`,
+			err:             usecase.ErrMFANotEnabled,
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: usecase.ErrMFANotEnabled.Error(),
+		},
+		{
+			name:            "storage failure",
+			path:            "/me/mfa:disable",
+			body:            `{"code":"000000"}`,
+			err:             errors.New("connection refused"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: internalErrorMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockMFAUseCase)
+			mockUseCase.On("Enable", mock.Anything, "user_1", "000000").Return(tt.err)
+			mockUseCase.On("Disable", mock.Anything, "user_1", "000000").Return(tt.err)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			newMFARouter(mockUseCase).ServeHTTP(w, withSubject(req, "user_1"))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedMessage, body["message"])
+		})
+	}
+}
+
+func TestMFAHandler_RequiresSubject(t *testing.T) {
+	mockUseCase := new(MockMFAUseCase)
+	router := newMFARouter(mockUseCase)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/me/mfa", nil),
+		httptest.NewRequest("POST", "/me/mfa", nil),
+		httptest.NewRequest("POST", "/me/mfa:enable", bytes.NewBufferString(`{"code":"123456"}`)),
+		httptest.NewRequest("POST", "/me/mfa:disable", bytes.NewBufferString(`{"code":"123456"}`)),
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "%s %s", req.Method, req.URL.Path)
+	}
+	mockUseCase.AssertNotCalled(t, "Enroll", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/pkg/oidc"
+)
+
+// OIDCProvider logs in users at an OpenID Connect provider
+type OIDCProvider interface {
+	// AllowsRedirect reports whether logins may return to uri
+	AllowsRedirect(uri string) bool
+	// StartLogin returns the URL of the provider to send the user to
+	StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error)
+	// FinishLogin verifies the provider's callback and returns the
+	// identity of its ID token and the URL passed to StartLogin
+	FinishLogin(w http.ResponseWriter, r *http.Request) (*auth.Identity, string, error)
+}
+
+// WithOIDC enables single sign-on at OpenID providers, keyed by provider ID
+func (h *AuthHandler) WithOIDC(providers map[string]OIDCProvider) *AuthHandler {
+	h.oidc = providers
+	return h
+}
+
+// OIDCLogin godoc
+// @Summary      Start an OpenID Connect login
+// @Description  Redirect to an OpenID provider. After logging in there, the user returns to redirect_uri with the access token in the URL fragment; without redirect_uri the token is returned as JSON. The provider may be omitted when only one is configured.
+// @Tags         auth
+// @Param        provider      query     string  false  "Provider ID, google or generic"
+// @Param        redirect_uri  query     string  false  "Registered URL to return to"
+// @Success      302
+// @Failure      400           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      503           {object}  ErrorResponse
+// @Router       /api/v1/auth/oidc/login [get]
+func (h *AuthHandler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.oidcProvider(w, r, r.URL.Query().Get("provider"))
+	if !ok {
+		return
+	}
+
+	redirectURI := r.URL.Query().Get("redirect_uri")
+	if redirectURI != "" && !provider.AllowsRedirect(redirectURI) {
+		badRequest(w, r, "redirect_uri is not registered")
+		return
+	}
+
+	location, err := provider.StartLogin(w, r, redirectURI)
+	if err != nil {
+		h.oidcError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, location, http.StatusFound)
+}
+
+// OIDCCallback godoc
+// @Summary      Finish an OpenID Connect login
+// @Description  Redirect URI the OpenID provider returns the user to with an authorization code. Users logging in for the first time are created; existing users with the same verified email are linked.
+// @Tags         auth
+// @Produce      json
+// @Param        state  query     string  true   "State of the login request"
+// @Param        code   query     string  false  "Authorization code"
+// @Param        error  query     string  false  "Error returned by the provider"
+// @Success      200    {object}  SuccessResponse
+// @Success      303
+// @Failure      400    {object}  ErrorResponse
+// @Failure      401    {object}  ErrorResponse
+// @Failure      403    {object}  ErrorResponse
+// @Failure      500    {object}  ErrorResponse
+// @Failure      503    {object}  ErrorResponse
+// @Router       /api/v1/auth/oidc/callback [get]
+func (h *AuthHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	// The state names the provider; it verifies the state in turn
+	provider, ok := h.oidc[oidc.ProviderID(r.URL.Query().Get("state"))]
+	if !ok {
+		h.oidcError(w, r, oidc.ErrUnknownRequest)
+		return
+	}
+
+	identity, returnTo, err := provider.FinishLogin(w, r)
+	if err != nil {
+		h.oidcError(w, r, err)
+		return
+	}
+	h.completeSSO(w, r, identity, returnTo)
+}
+
+// oidcProvider returns the provider with id, or the only one configured
+// when id is empty, writing a 404 for unknown providers
+func (h *AuthHandler) oidcProvider(w http.ResponseWriter, r *http.Request, id string) (OIDCProvider, bool) {
+	if id == "" && len(h.oidc) == 1 {
+		for _, provider := range h.oidc {
+			return provider, true
+		}
+	}
+	if id == "" && len(h.oidc) > 1 {
+		badRequest(w, r, "provider is required")
+		return nil, false
+	}
+	provider, ok := h.oidc[id]
+	if !ok {
+		render.Status(r, http.StatusNotFound)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   "Unknown OIDC provider",
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+	return provider, true
+}
+
+// oidcError writes the response for a failed OpenID Connect login. Why a
+// callback was invalid is logged rather than returned.
+func (h *AuthHandler) oidcError(w http.ResponseWriter, r *http.Request, err error) {
+	var status int
+	var message string
+	switch {
+	case errors.Is(err, oidc.ErrUnknownRequest):
+		status, message = http.StatusBadRequest, "Login request is unknown or expired; start the login again"
+	case errors.Is(err, oidc.ErrInvalidResponse):
+		h.logger.WithFields(map[string]interface{}{
+			"provider": oidc.ProviderID(r.URL.Query().Get("state")),
+			"error":    err.Error(),
+		}).Warn("Rejected OIDC callback")
+		status, message = http.StatusUnauthorized, "Identity provider response is invalid"
+	case errors.Is(err, oidc.ErrProviderUnavailable):
+		h.logger.WithField("error", err.Error()).Warn("OIDC provider unavailable")
+		status, message = http.StatusServiceUnavailable, "Identity provider is unavailable"
+	default:
+		h.loginError(w, r, err)
+		return
+	}
+
+	errorStatus(w, r, status, err)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/oidc"
+)
+
+// MockOIDCProvider is a mock implementation of OIDCProvider
+type MockOIDCProvider struct {
+	mock.Mock
+}
+
+func (m *MockOIDCProvider) AllowsRedirect(uri string) bool {
+	return m.Called(uri).Bool(0)
+}
+
+func (m *MockOIDCProvider) StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error) {
+	args := m.Called(returnTo)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOIDCProvider) FinishLogin(w http.ResponseWriter, r *http.Request) (*auth.Identity, string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*auth.Identity), args.String(1), args.Error(2)
+}
+
+// serveOIDC routes req to the OIDC endpoints of a handler with providers
+func serveOIDC(authUseCase usecase.AuthUseCaseInterface, providers map[string]OIDCProvider, req *http.Request) *httptest.ResponseRecorder {
+	handler := NewAuthHandler(authUseCase, NewUserPresenter(nil), logger.New()).WithOIDC(providers)
+	r := chi.NewRouter()
+	r.Get("/auth/oidc/login", handler.OIDCLogin)
+	r.Get("/auth/oidc/callback", handler.OIDCCallback)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthHandler_OIDCLogin(t *testing.T) {
+	google := new(MockOIDCProvider)
+	google.On("AllowsRedirect", "https://app.example.com/sso").Return(true)
+	google.On("AllowsRedirect", "https://evil.example.net/").Return(false)
+	google.On("StartLogin", "https://app.example.com/sso").Return("https://accounts.google.com/o/oauth2/v2/auth?state=google.abc", nil)
+	google.On("StartLogin", "").Return("", oidc.ErrProviderUnavailable)
+	providers := map[string]OIDCProvider{"google": google}
+
+	w := serveOIDC(new(MockAuthUseCase), providers, httptest.NewRequest("GET", "/auth/oidc/login?provider=google&redirect_uri="+url.QueryEscape("https://app.example.com/sso"), nil))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://accounts.google.com/o/oauth2/v2/auth?state=google.abc", w.Header().Get("Location"))
+
+	w = serveOIDC(new(MockAuthUseCase), providers, httptest.NewRequest("GET", "/auth/oidc/login?redirect_uri="+url.QueryEscape("https://evil.example.net/"), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "redirect_uri is not registered")
+
+	w = serveOIDC(new(MockAuthUseCase), providers, httptest.NewRequest("GET", "/auth/oidc/login", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "Identity provider is unavailable")
+
+	w = serveOIDC(new(MockAuthUseCase), providers, httptest.NewRequest("GET", "/auth/oidc/login?provider=generic", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Unknown OIDC provider")
+
+	providers["generic"] = new(MockOIDCProvider)
+	w = serveOIDC(new(MockAuthUseCase), providers, httptest.NewRequest("GET", "/auth/oidc/login", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "provider is required")
+
+	w = serveOIDC(new(MockAuthUseCase), nil, httptest.NewRequest("GET", "/auth/oidc/login", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAuthHandler_OIDCCallback(t *testing.T) {
+	identity := &auth.Identity{Provider: "oidc:google", Subject: "248289761001", Email: "bjensen@example.com", Roles: []string{"user"}}
+
+	t.Run("returns JSON without a redirect", func(t *testing.T) {
+		google := new(MockOIDCProvider)
+		google.On("FinishLogin").Return(identity, "", nil)
+		authUseCase := new(MockAuthUseCase)
+		authUseCase.On("LoginIdentity", mock.Anything, identity).Return(samlSession(), nil)
+
+		w := serveOIDC(authUseCase, map[string]OIDCProvider{"google": google}, httptest.NewRequest("GET", "/auth/oidc/callback?state=google.abc&code=code_1", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		var body struct {
+			Data LoginResponseDTO `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "token_1", body.Data.AccessToken)
+		authUseCase.AssertExpectations(t)
+	})
+
+	t.Run("redirects with the token in the fragment", func(t *testing.T) {
+		google := new(MockOIDCProvider)
+		google.On("FinishLogin").Return(identity, "https://app.example.com/sso", nil)
+		authUseCase := new(MockAuthUseCase)
+		authUseCase.On("LoginIdentity", mock.Anything, identity).Return(samlSession(), nil)
+
+		w := serveOIDC(authUseCase, map[string]OIDCProvider{"google": google}, httptest.NewRequest("GET", "/auth/oidc/callback?state=google.abc&code=code_1", nil))
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		location, err := url.Parse(w.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, "https://app.example.com/sso", location.Scheme+"://"+location.Host+location.Path)
+		fragment, err := url.ParseQuery(location.Fragment)
+		require.NoError(t, err)
+		assert.Equal(t, "token_1", fragment.Get("access_token"))
+	})
+}
+
+func TestAuthHandler_OIDCCallback_Errors(t *testing.T) {
+	identity := &auth.Identity{Provider: "oidc:google", Subject: "248289761001"}
+
+	tests := []struct {
+		name            string
+		state           string
+		finishErr       error
+		loginErr        error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "unknown provider",
+			state:           "globex.abc",
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Login request is unknown or expired; start the login again",
+		},
+		{
+			name:            "unknown request",
+			finishErr:       oidc.ErrUnknownRequest,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Login request is unknown or expired; start the login again",
+		},
+		{
+			name:            "invalid response",
+			finishErr:       errors.Join(oidc.ErrInvalidResponse, errors.New("nonce mismatch")),
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "Identity provider response is invalid",
+		},
+		{
+			name:            "denied",
+			finishErr:       auth.ErrAccessDenied,
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "Account is not permitted to log in",
+		},
+		{
+			name:            "provider unavailable",
+			finishErr:       oidc.ErrProviderUnavailable,
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedMessage: "Identity provider is unavailable",
+		},
+		{
+			name:            "no email",
+			loginErr:        usecase.ErrIdentityWithoutEmail,
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "Account has no email address",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			google := new(MockOIDCProvider)
+			if tt.finishErr != nil {
+				google.On("FinishLogin").Return(nil, "", tt.finishErr)
+			} else {
+				google.On("FinishLogin").Return(identity, "", nil)
+			}
+			authUseCase := new(MockAuthUseCase)
+			authUseCase.On("LoginIdentity", mock.Anything, identity).Return(nil, tt.loginErr)
+
+			state := tt.state
+			if state == "" {
+				state = "google.abc"
+			}
+			w := serveOIDC(authUseCase, map[string]OIDCProvider{"google": google}, httptest.NewRequest("GET", "/auth/oidc/callback?code=code_1&state="+state, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedMessage, body["message"])
+			assert.NotContains(t, w.Body.String(), "nonce")
+		})
+	}
+}
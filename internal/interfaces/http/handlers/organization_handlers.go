@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// OrganizationHandler handles managing the organizations service accounts
+// belong to
+type OrganizationHandler struct {
+	orgUseCase usecase.OrganizationUseCaseInterface
+	defaults   APIDefaults
+	logger     logger.Logger
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(orgUseCase usecase.OrganizationUseCaseInterface, logger logger.Logger) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgUseCase: orgUseCase,
+		defaults:   DefaultAPIDefaults(),
+		logger:     logger,
+	}
+}
+
+// WithDefaults replaces the page sizes organizations are listed with
+func (h *OrganizationHandler) WithDefaults(defaults APIDefaults) *OrganizationHandler {
+	h.defaults = defaults
+	return h
+}
+
+// OrganizationDTO is the API representation of an organization
+type OrganizationDTO struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateOrganizationRequest represents the request body for creating an
+// organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateOrganization godoc
+// @Summary      Create an organization
+// @Description  Create an organization to own service accounts
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        organization  body      CreateOrganizationRequest  true  "Organization definition"
+// @Success      201           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      422           {object}  ErrorResponse
+// @Router       /api/v1/organizations [post]
+func (h *OrganizationHandler) CreateOrganization(w http.ResponseWriter, r *http.Request) {
+	var req CreateOrganizationRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	org, err := h.orgUseCase.CreateOrganization(r.Context(), req.Name)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Organization created successfully",
+		Data:      presentOrganization(org),
+		Timestamp: time.Now(),
+	})
+}
+
+// ListOrganizations godoc
+// @Summary      List organizations
+// @Description  List organizations, oldest first
+// @Tags         organizations
+// @Produce      json
+// @Param        limit   query     int  false  "Maximum number of organizations"
+// @Param        offset  query     int  false  "Number of organizations to skip"
+// @Success      200     {object}  SuccessResponse
+// @Failure      401     {object}  ErrorResponse
+// @Failure      403     {object}  ErrorResponse
+// @Router       /api/v1/organizations [get]
+func (h *OrganizationHandler) ListOrganizations(w http.ResponseWriter, r *http.Request) {
+	limit, offset, warnings := pagination(r.URL.Query(), h.defaults.PageSize, h.defaults.MaxPageSize)
+
+	orgs, err := h.orgUseCase.ListOrganizations(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]OrganizationDTO, 0, len(orgs))
+	for _, org := range orgs {
+		dtos = append(dtos, presentOrganization(org))
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Organizations retrieved successfully",
+		Data:      dtos,
+		Warnings:  warnings,
+		Meta:      listMeta(limit, offset, len(dtos)),
+		Timestamp: time.Now(),
+	})
+}
+
+// GetOrganization godoc
+// @Summary      Get an organization
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      string  true  "Organization ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/organizations/{id} [get]
+func (h *OrganizationHandler) GetOrganization(w http.ResponseWriter, r *http.Request) {
+	org, err := h.orgUseCase.GetOrganization(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Organization retrieved successfully",
+		Data:      presentOrganization(org),
+		Timestamp: time.Now(),
+	})
+}
+
+func presentOrganization(org *entities.Organization) OrganizationDTO {
+	return OrganizationDTO{
+		ID:        org.ID,
+		Name:      org.Name,
+		CreatedAt: org.CreatedAt,
+		UpdatedAt: org.UpdatedAt,
+	}
+}
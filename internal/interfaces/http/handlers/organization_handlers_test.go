@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockOrganizationUseCase is a mock implementation of
+// OrganizationUseCaseInterface
+type MockOrganizationUseCase struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationUseCase) CreateOrganization(ctx context.Context, name string) (*entities.Organization, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationUseCase) GetOrganization(ctx context.Context, id string) (*entities.Organization, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationUseCase) ListOrganizations(ctx context.Context, limit, offset int) ([]*entities.Organization, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Organization), args.Error(1)
+}
+
+// MockServiceAccountUseCase is a mock implementation of
+// ServiceAccountUseCaseInterface
+type MockServiceAccountUseCase struct {
+	mock.Mock
+}
+
+func (m *MockServiceAccountUseCase) CreateServiceAccount(ctx context.Context, orgID, name string, scopes []string) (*entities.User, error) {
+	args := m.Called(ctx, orgID, name, scopes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), args.Error(1)
+}
+
+func (m *MockServiceAccountUseCase) ListServiceAccounts(ctx context.Context, orgID string, limit, offset int) ([]*entities.User, error) {
+	args := m.Called(ctx, orgID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.User), args.Error(1)
+}
+
+func (m *MockServiceAccountUseCase) GetServiceAccount(ctx context.Context, orgID, id string) (*entities.User, error) {
+	args := m.Called(ctx, orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), args.Error(1)
+}
+
+func (m *MockServiceAccountUseCase) DeleteServiceAccount(ctx context.Context, orgID, id string) error {
+	return m.Called(ctx, orgID, id).Error(0)
+}
+
+func (m *MockServiceAccountUseCase) CreateKey(ctx context.Context, orgID, accountID, createdBy, name string, scopes []string, expiresAt *time.Time) (*entities.APIKey, string, error) {
+	args := m.Called(ctx, orgID, accountID, createdBy, name, scopes, expiresAt)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*entities.APIKey), args.String(1), args.Error(2)
+}
+
+func (m *MockServiceAccountUseCase) ListKeys(ctx context.Context, orgID, accountID string) ([]*entities.APIKey, error) {
+	args := m.Called(ctx, orgID, accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.APIKey), args.Error(1)
+}
+
+func (m *MockServiceAccountUseCase) RevokeKey(ctx context.Context, orgID, accountID, keyID string) (*entities.APIKey, error) {
+	args := m.Called(ctx, orgID, accountID, keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.APIKey), args.Error(1)
+}
+
+func newOrganizationRouter(orgs usecase.OrganizationUseCaseInterface, accounts usecase.ServiceAccountUseCaseInterface) http.Handler {
+	orgHandler := NewOrganizationHandler(orgs, logger.New())
+	accountHandler := NewServiceAccountHandler(accounts, logger.New())
+	r := chi.NewRouter()
+	r.Post("/organizations", orgHandler.CreateOrganization)
+	r.Get("/organizations", orgHandler.ListOrganizations)
+	r.Get("/organizations/{id}", orgHandler.GetOrganization)
+	r.Post("/organizations/{id}/service-accounts", accountHandler.CreateServiceAccount)
+	r.Get("/organizations/{id}/service-accounts", accountHandler.ListServiceAccounts)
+	r.Delete("/organizations/{id}/service-accounts/{account_id}", accountHandler.DeleteServiceAccount)
+	r.Post("/organizations/{id}/service-accounts/{account_id}/keys", accountHandler.CreateServiceAccountKey)
+	r.Delete("/organizations/{id}/service-accounts/{account_id}/keys/{key_id}", accountHandler.RevokeServiceAccountKey)
+	return r
+}
+
+func TestOrganizationHandler_CreateOrganization(t *testing.T) {
+	orgs := new(MockOrganizationUseCase)
+	orgs.On("CreateOrganization", mock.Anything, "Acme").Return(&entities.Organization{ID: "org_1", Name: "Acme"}, nil)
+	orgs.On("CreateOrganization", mock.Anything, "").Return(nil, usecase.ErrOrganizationNameRequired)
+	router := newOrganizationRouter(orgs, new(MockServiceAccountUseCase))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/organizations", bytes.NewBufferString(`{"name":"Acme"}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response struct {
+		Data OrganizationDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "org_1", response.Data.ID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/organizations", bytes.NewBufferString(`{}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), usecase.ErrOrganizationNameRequired.Error())
+}
+
+func TestOrganizationHandler_GetOrganizationNotFound(t *testing.T) {
+	orgs := new(MockOrganizationUseCase)
+	orgs.On("GetOrganization", mock.Anything, "org_x").Return(nil, repositories.ErrOrganizationNotFound)
+
+	w := httptest.NewRecorder()
+	newOrganizationRouter(orgs, new(MockServiceAccountUseCase)).ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org_x", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "organization not found")
+}
+
+func TestServiceAccountHandler_CreateServiceAccount(t *testing.T) {
+	accounts := new(MockServiceAccountUseCase)
+	account := entities.NewServiceAccount("org_1", "sa-1@service-accounts.invalid", "ci", []string{"users:read"})
+	account.ID = "user_1"
+	accounts.On("CreateServiceAccount", mock.Anything, "org_1", "ci", []string{"users:read"}).Return(account, nil)
+
+	w := httptest.NewRecorder()
+	body := bytes.NewBufferString(`{"name":"ci","scopes":["users:read"]}`)
+	newOrganizationRouter(new(MockOrganizationUseCase), accounts).ServeHTTP(w, httptest.NewRequest("POST", "/organizations/org_1/service-accounts", body))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "org_1", response.Data["organization_id"])
+	assert.Equal(t, []interface{}{"users:read"}, response.Data["scopes"])
+	assert.NotContains(t, response.Data, "email", "the generated email is not part of the account")
+}
+
+func TestServiceAccountHandler_ListServiceAccounts(t *testing.T) {
+	accounts := new(MockServiceAccountUseCase)
+	accounts.On("ListServiceAccounts", mock.Anything, "org_1", 10, 0).Return([]*entities.User{
+		entities.NewServiceAccount("org_1", "sa-1@service-accounts.invalid", "ci", nil),
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newOrganizationRouter(new(MockOrganizationUseCase), accounts).ServeHTTP(w, httptest.NewRequest("GET", "/organizations/org_1/service-accounts", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []ServiceAccountDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, []string{}, response.Data[0].Scopes)
+}
+
+func TestServiceAccountHandler_CreateServiceAccountKey(t *testing.T) {
+	accounts := new(MockServiceAccountUseCase)
+	key := &entities.APIKey{ID: "key_1", Name: "deploy", Prefix: "ak_12345678", Scopes: []string{"users:read"}, OwnerID: "user_1"}
+	accounts.On("CreateKey", mock.Anything, "org_1", "user_1", "", "deploy", []string(nil), (*time.Time)(nil)).Return(key, "ak_secret", nil)
+	accounts.On("CreateKey", mock.Anything, "org_1", "user_1", "", "admin", []string{"admin"}, (*time.Time)(nil)).
+		Return(nil, "", fmt.Errorf("%w: admin", usecase.ErrScopeNotGranted))
+	router := newOrganizationRouter(new(MockOrganizationUseCase), accounts)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/organizations/org_1/service-accounts/user_1/keys", bytes.NewBufferString(`{"name":"deploy"}`)))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response struct {
+		Data CreatedAPIKeyDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "ak_secret", response.Data.Key)
+	assert.Equal(t, "user_1", response.Data.OwnerID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/organizations/org_1/service-accounts/user_1/keys", bytes.NewBufferString(`{"name":"admin","scopes":["admin"]}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), usecase.ErrScopeNotGranted.Error())
+}
+
+func TestServiceAccountHandler_NotFound(t *testing.T) {
+	accounts := new(MockServiceAccountUseCase)
+	accounts.On("DeleteServiceAccount", mock.Anything, "org_1", "user_x").Return(usecase.ErrServiceAccountNotFound)
+	accounts.On("RevokeKey", mock.Anything, "org_1", "user_1", "key_x").Return(nil, fmt.Errorf("key key_x: %w", repositories.ErrAPIKeyNotFound))
+	router := newOrganizationRouter(new(MockOrganizationUseCase), accounts)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/organizations/org_1/service-accounts/user_x", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "service account not found")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/organizations/org_1/service-accounts/user_1/keys/key_x", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
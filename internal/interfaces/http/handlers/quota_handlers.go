@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// QuotaHandler handles administrators viewing and adjusting quotas
+type QuotaHandler struct {
+	quotaUseCase usecase.QuotaUseCaseInterface
+	logger       logger.Logger
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(quotaUseCase usecase.QuotaUseCaseInterface, logger logger.Logger) *QuotaHandler {
+	return &QuotaHandler{
+		quotaUseCase: quotaUseCase,
+		logger:       logger,
+	}
+}
+
+// QuotaDTO is the API representation of a quota
+type QuotaDTO struct {
+	Resource string `json:"resource"`
+	// Limit is the most of the resource that may exist; 0 is unlimited
+	Limit int   `json:"limit"`
+	Used  int64 `json:"used"`
+	// Default is true while the limit is the configured default
+	Default   bool       `json:"default"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// UpdateQuotaRequest represents the request body for adjusting a quota
+type UpdateQuotaRequest struct {
+	// Limit is the most of the resource that may exist; 0 is unlimited
+	Limit *int `json:"limit"`
+}
+
+// ListQuotas godoc
+// @Summary      List quotas
+// @Description  List the quota of every resource with its current usage
+// @Tags         quotas
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/quotas [get]
+func (h *QuotaHandler) ListQuotas(w http.ResponseWriter, r *http.Request) {
+	quotas, err := h.quotaUseCase.ListQuotas(r.Context())
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]QuotaDTO, 0, len(quotas))
+	for _, quota := range quotas {
+		dtos = append(dtos, presentQuota(quota))
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Quotas retrieved successfully",
+		Data:      dtos,
+		Timestamp: time.Now(),
+	})
+}
+
+// GetQuota godoc
+// @Summary      Get a quota
+// @Description  Get the quota of a resource with its current usage
+// @Tags         quotas
+// @Produce      json
+// @Param        resource  path      string  true  "Resource, e.g. users"
+// @Success      200       {object}  SuccessResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /api/v1/quotas/{resource} [get]
+func (h *QuotaHandler) GetQuota(w http.ResponseWriter, r *http.Request) {
+	quota, err := h.quotaUseCase.GetQuota(r.Context(), chi.URLParam(r, "resource"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Quota retrieved successfully",
+		Data:      presentQuota(quota),
+		Timestamp: time.Now(),
+	})
+}
+
+// UpdateQuota godoc
+// @Summary      Adjust a quota
+// @Description  Set the most of a resource that may exist, 0 being unlimited. The limit replaces the configured default and takes effect immediately; existing resources above it are kept, but no more can be created.
+// @Tags         quotas
+// @Accept       json
+// @Produce      json
+// @Param        resource  path      string              true  "Resource, e.g. users"
+// @Param        quota     body      UpdateQuotaRequest  true  "New limit"
+// @Success      200       {object}  SuccessResponse
+// @Failure      400       {object}  ErrorResponse
+// @Failure      401       {object}  ErrorResponse
+// @Failure      403       {object}  ErrorResponse
+// @Failure      404       {object}  ErrorResponse
+// @Failure      422       {object}  ErrorResponse
+// @Failure      500       {object}  ErrorResponse
+// @Router       /api/v1/quotas/{resource} [put]
+func (h *QuotaHandler) UpdateQuota(w http.ResponseWriter, r *http.Request) {
+	var req UpdateQuotaRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if req.Limit == nil {
+		unprocessable(w, r, "limit_required", "limit is required")
+		return
+	}
+
+	quota, err := h.quotaUseCase.SetQuota(r.Context(), chi.URLParam(r, "resource"), *req.Limit)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Quota updated successfully",
+		Data:      presentQuota(quota),
+		Timestamp: time.Now(),
+	})
+}
+
+func presentQuota(quota *usecase.QuotaUsage) QuotaDTO {
+	dto := QuotaDTO{
+		Resource: quota.Resource,
+		Limit:    quota.Limit,
+		Used:     quota.Used,
+		Default:  quota.Default,
+	}
+	if !quota.UpdatedAt.IsZero() {
+		updatedAt := quota.UpdatedAt
+		dto.UpdatedAt = &updatedAt
+	}
+	return dto
+}
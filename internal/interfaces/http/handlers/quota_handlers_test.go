@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockQuotaUseCase is a mock implementation of QuotaUseCaseInterface
+type MockQuotaUseCase struct {
+	mock.Mock
+}
+
+func (m *MockQuotaUseCase) ListQuotas(ctx context.Context) ([]*usecase.QuotaUsage, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*usecase.QuotaUsage), args.Error(1)
+}
+
+func (m *MockQuotaUseCase) GetQuota(ctx context.Context, resource string) (*usecase.QuotaUsage, error) {
+	args := m.Called(ctx, resource)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.QuotaUsage), args.Error(1)
+}
+
+func (m *MockQuotaUseCase) SetQuota(ctx context.Context, resource string, limit int) (*usecase.QuotaUsage, error) {
+	args := m.Called(ctx, resource, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.QuotaUsage), args.Error(1)
+}
+
+func newQuotaRouter(uc usecase.QuotaUseCaseInterface) http.Handler {
+	handler := NewQuotaHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Get("/quotas", handler.ListQuotas)
+	r.Get("/quotas/{resource}", handler.GetQuota)
+	r.Put("/quotas/{resource}", handler.UpdateQuota)
+	return r
+}
+
+func TestQuotaHandler_ListQuotas(t *testing.T) {
+	mockUseCase := new(MockQuotaUseCase)
+	mockUseCase.On("ListQuotas", mock.Anything).Return([]*usecase.QuotaUsage{
+		{Resource: "users", Limit: 0, Used: 12, Default: true},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newQuotaRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/quotas", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, map[string]interface{}{"resource": "users", "limit": 0.0, "used": 12.0, "default": true}, response.Data[0])
+}
+
+func TestQuotaHandler_GetQuota(t *testing.T) {
+	mockUseCase := new(MockQuotaUseCase)
+	mockUseCase.On("GetQuota", mock.Anything, "users").Return(&usecase.QuotaUsage{Resource: "users", Limit: 100, Used: 12, UpdatedAt: time.Now()}, nil)
+	mockUseCase.On("GetQuota", mock.Anything, "widgets").Return(nil, usecase.ErrUnknownQuota)
+
+	w := httptest.NewRecorder()
+	newQuotaRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/quotas/users", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"limit":100`)
+	assert.Contains(t, w.Body.String(), `"updated_at"`)
+
+	w = httptest.NewRecorder()
+	newQuotaRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/quotas/widgets", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestQuotaHandler_UpdateQuota(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setup          func(m *MockQuotaUseCase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "sets the limit",
+			body: `{"limit":100}`,
+			setup: func(m *MockQuotaUseCase) {
+				m.On("SetQuota", mock.Anything, "users", 100).Return(&usecase.QuotaUsage{Resource: "users", Limit: 100, Used: 12, UpdatedAt: time.Now()}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "Quota updated successfully",
+		},
+		{
+			name: "unlimited",
+			body: `{"limit":0}`,
+			setup: func(m *MockQuotaUseCase) {
+				m.On("SetQuota", mock.Anything, "users", 0).Return(&usecase.QuotaUsage{Resource: "users", UpdatedAt: time.Now()}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"limit":0`,
+		},
+		{
+			name:           "missing limit",
+			body:           `{}`,
+			setup:          func(m *MockQuotaUseCase) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "limit is required",
+		},
+		{
+			name:           "malformed body",
+			body:           `{"limit":"many"}`,
+			setup:          func(m *MockQuotaUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "negative limit",
+			body: `{"limit":-1}`,
+			setup: func(m *MockQuotaUseCase) {
+				m.On("SetQuota", mock.Anything, "users", -1).Return(nil, usecase.ErrInvalidQuotaLimit)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   usecase.ErrInvalidQuotaLimit.Error(),
+		},
+		{
+			name: "store error",
+			body: `{"limit":100}`,
+			setup: func(m *MockQuotaUseCase) {
+				m.On("SetQuota", mock.Anything, "users", 100).Return(nil, errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   internalErrorMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockQuotaUseCase)
+			tt.setup(mockUseCase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/quotas/users", bytes.NewBufferString(tt.body))
+			newQuotaRouter(mockUseCase).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/replay"
+)
+
+// RequestReplayHandler lists the requests captured on the API listener and
+// replays them against the configured targets
+type RequestReplayHandler struct {
+	captures *replay.Buffer
+	targets  []string
+	client   *http.Client
+	defaults APIDefaults
+	logger   logger.Logger
+}
+
+// NewRequestReplayHandler creates a new request replay handler replaying
+// captures to targets with client
+func NewRequestReplayHandler(captures *replay.Buffer, targets []string, client *http.Client, logger logger.Logger) *RequestReplayHandler {
+	return &RequestReplayHandler{
+		captures: captures,
+		targets:  targets,
+		client:   client,
+		defaults: DefaultAPIDefaults(),
+		logger:   logger,
+	}
+}
+
+// WithDefaults replaces the page size of capture listings
+func (h *RequestReplayHandler) WithDefaults(defaults APIDefaults) *RequestReplayHandler {
+	h.defaults = defaults
+	return h
+}
+
+// CapturedRequestDTO summarizes a captured request in listings
+type CapturedRequestDTO struct {
+	ID            string    `json:"id"`
+	RequestID     string    `json:"request_id,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	Method        string    `json:"method"`
+	Path          string    `json:"path"`
+	Status        int       `json:"status"`
+	DurationMs    float64   `json:"duration_ms"`
+	CapturedAt    time.Time `json:"captured_at"`
+}
+
+// ReplayRequestRequest represents the request body for replaying a capture.
+// Headers replace captured ones, supplying the credentials redacted from
+// the capture.
+type ReplayRequestRequest struct {
+	Target  string            `json:"target"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ListCaptures handles listing the captured requests, newest first,
+// filtered by the method, path prefix, status, request_id and
+// correlation_id query parameters
+func (h *RequestReplayHandler) ListCaptures(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, offset, warnings := pagination(query, h.defaults.AdminPageSize, 0)
+	status, _ := strconv.Atoi(query.Get("status"))
+
+	dtos := []CapturedRequestDTO{}
+	matched := 0
+	for _, captured := range h.captures.List() {
+		switch {
+		case query.Get("method") != "" && !strings.EqualFold(captured.Method, query.Get("method")),
+			!strings.HasPrefix(captured.Path, query.Get("path")),
+			status != 0 && captured.Status != status,
+			query.Get("request_id") != "" && captured.RequestID != query.Get("request_id"),
+			query.Get("correlation_id") != "" && captured.CorrelationID != query.Get("correlation_id"):
+			continue
+		}
+		matched++
+		if matched <= offset || len(dtos) >= limit {
+			continue
+		}
+		dtos = append(dtos, CapturedRequestDTO{
+			ID:            captured.ID,
+			RequestID:     captured.RequestID,
+			CorrelationID: captured.CorrelationID,
+			Method:        captured.Method,
+			Path:          captured.Path,
+			Status:        captured.Status,
+			DurationMs:    float64(captured.Duration) / float64(time.Millisecond),
+			CapturedAt:    captured.CapturedAt,
+		})
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Captured requests retrieved successfully",
+		Data:      dtos,
+		Warnings:  warnings,
+		Meta:      listMeta(limit, offset, len(dtos)),
+		Timestamp: time.Now(),
+	})
+}
+
+// GetCapture handles retrieving a captured request with its redacted
+// headers and body, for replaying it elsewhere with the replay command
+func (h *RequestReplayHandler) GetCapture(w http.ResponseWriter, r *http.Request) {
+	captured, ok := h.captures.Get(chi.URLParam(r, "id"))
+	if !ok {
+		h.captureNotFound(w, r)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Captured request retrieved successfully",
+		Data:      captured,
+		Timestamp: time.Now(),
+	})
+}
+
+// ReplayCapture handles sending a captured request again to one of the
+// configured targets and returns the response it got
+func (h *RequestReplayHandler) ReplayCapture(w http.ResponseWriter, r *http.Request) {
+	var req ReplayRequestRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if len(h.targets) == 0 {
+		unprocessable(w, r, "replay_disabled", "replaying from the server is disabled; set REPLAY_TARGETS or use the replay command")
+		return
+	}
+	if !h.allowed(req.Target) {
+		unprocessable(w, r, "invalid_replay_target", "target must be one of "+strings.Join(h.targets, ", "))
+		return
+	}
+	captured, ok := h.captures.Get(chi.URLParam(r, "id"))
+	if !ok {
+		h.captureNotFound(w, r)
+		return
+	}
+
+	header := make(http.Header, len(req.Headers))
+	for key, value := range req.Headers {
+		header.Set(key, value)
+	}
+	result, err := replay.Send(r.Context(), h.client, captured, req.Target, header)
+	if err != nil {
+		h.logger.WithFields(map[string]interface{}{
+			"capture_id": captured.ID,
+			"target":     req.Target,
+			"error":      err.Error(),
+		}).Warn("Request replay failed")
+		render.Status(r, http.StatusBadGateway)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   "The target could not be reached",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"capture_id": captured.ID,
+		"method":     captured.Method,
+		"path":       captured.Path,
+		"target":     req.Target,
+		"status":     result.Status,
+	}).Info("Request replayed")
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Request replayed successfully",
+		Data:      result,
+		Timestamp: time.Now(),
+	})
+}
+
+// allowed reports whether target is one of the configured targets, which
+// keeps the admin listener from being used to reach arbitrary hosts
+func (h *RequestReplayHandler) allowed(target string) bool {
+	target = strings.TrimSuffix(target, "/")
+	for _, allowed := range h.targets {
+		if target == strings.TrimSuffix(allowed, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *RequestReplayHandler) captureNotFound(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, http.StatusNotFound)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   "Captured request not found",
+		Timestamp: time.Now(),
+	})
+}
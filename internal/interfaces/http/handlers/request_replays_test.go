@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/replay"
+)
+
+// serveReplays routes req to the replay endpoints of a handler for captures
+// replaying to targets
+func serveReplays(captures *replay.Buffer, targets []string, req *http.Request) *httptest.ResponseRecorder {
+	handler := NewRequestReplayHandler(captures, targets, http.DefaultClient, logger.New())
+	r := chi.NewRouter()
+	r.Get("/replay/requests", handler.ListCaptures)
+	r.Get("/replay/requests/{id}", handler.GetCapture)
+	r.Post("/replay/requests/{id}/replay", handler.ReplayCapture)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func testCaptures() *replay.Buffer {
+	captures := replay.NewBuffer(10)
+	captures.Add(replay.Request{ID: "cap_1", RequestID: "host/req-000001", Method: "GET", Path: "/api/v1/users", Status: 200, Duration: 1500 * time.Microsecond})
+	captures.Add(replay.Request{
+		ID:     "cap_2",
+		Method: "POST",
+		Path:   "/api/v1/users",
+		Header: http.Header{"Authorization": {replay.Redacted}, "Content-Type": {"application/json"}},
+		Body:   `{"name":"Jane"}`,
+		Status: 400,
+	})
+	captures.Add(replay.Request{ID: "cap_3", Method: "GET", Path: "/api/v1/audit", Status: 200})
+	return captures
+}
+
+func TestRequestReplayHandler_ListCaptures(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		expectedIDs []string
+	}{
+		{name: "newest first", query: "", expectedIDs: []string{"cap_3", "cap_2", "cap_1"}},
+		{name: "method", query: "?method=post", expectedIDs: []string{"cap_2"}},
+		{name: "path prefix", query: "?path=/api/v1/users", expectedIDs: []string{"cap_2", "cap_1"}},
+		{name: "status", query: "?status=200", expectedIDs: []string{"cap_3", "cap_1"}},
+		{name: "request id", query: "?request_id=host/req-000001", expectedIDs: []string{"cap_1"}},
+		{name: "page", query: "?limit=1&offset=1", expectedIDs: []string{"cap_2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveReplays(testCaptures(), nil, httptest.NewRequest("GET", "/replay/requests"+tt.query, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var body struct {
+				Data []CapturedRequestDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			ids := []string{}
+			for _, captured := range body.Data {
+				ids = append(ids, captured.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestRequestReplayHandler_GetCapture(t *testing.T) {
+	w := serveReplays(testCaptures(), nil, httptest.NewRequest("GET", "/replay/requests/cap_2", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data replay.Request `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "POST", body.Data.Method)
+	assert.Equal(t, replay.Redacted, body.Data.Header.Get("Authorization"))
+	assert.Equal(t, `{"name":"Jane"}`, body.Data.Body)
+
+	w = serveReplays(testCaptures(), nil, httptest.NewRequest("GET", "/replay/requests/cap_9", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Captured request not found")
+}
+
+func TestRequestReplayHandler_ReplayCapture(t *testing.T) {
+	var got *http.Request
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer target.Close()
+
+	w := serveReplays(testCaptures(), []string{target.URL + "/"}, httptest.NewRequest("POST", "/replay/requests/cap_2/replay",
+		strings.NewReader(`{"target":"`+target.URL+`","headers":{"Authorization":"Bearer staging"}}`)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data replay.Result `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, http.StatusCreated, body.Data.Status)
+	require.NotNil(t, got)
+	assert.Equal(t, "/api/v1/users", got.URL.Path)
+	assert.Equal(t, "Bearer staging", got.Header.Get("Authorization"))
+	assert.Equal(t, "cap_2", got.Header.Get(replay.FromHeader))
+}
+
+func TestRequestReplayHandler_ReplayCapture_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		targets         []string
+		id              string
+		body            string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "replaying disabled",
+			id:              "cap_1",
+			body:            `{"target":"https://staging.example.com"}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "replaying from the server is disabled",
+		},
+		{
+			name:            "target not allowed",
+			targets:         []string{"https://staging.example.com"},
+			id:              "cap_1",
+			body:            `{"target":"http://169.254.169.254"}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "target must be one of https://staging.example.com",
+		},
+		{
+			name:            "unknown capture",
+			targets:         []string{"https://staging.example.com"},
+			id:              "cap_9",
+			body:            `{"target":"https://staging.example.com"}`,
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "Captured request not found",
+		},
+		{
+			name:            "unreachable target",
+			targets:         []string{"http://127.0.0.1:1"},
+			id:              "cap_1",
+			body:            `{"target":"http://127.0.0.1:1"}`,
+			expectedStatus:  http.StatusBadGateway,
+			expectedMessage: "The target could not be reached",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveReplays(testCaptures(), tt.targets, httptest.NewRequest("POST", "/replay/requests/"+tt.id+"/replay", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedMessage)
+		})
+	}
+}
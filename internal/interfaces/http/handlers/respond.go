@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/internal/interfaces/http/middleware/servertiming"
+	"clean-architecture/pkg/jsoncodec"
+	"clean-architecture/pkg/timing"
+	"clean-architecture/pkg/utils"
+)
+
+// codec encodes responses and decodes request bodies
+var codec = jsoncodec.Default
+
+// maxPooledBufferSize keeps the buffers of unusually large responses, such
+// as long user lists, from being held by the pool
+const maxPooledBufferSize = 64 << 10
+
+// encodeBuffer is a response buffer with an encoder writing to it. Both are
+// reused across responses.
+type encodeBuffer struct {
+	buf   bytes.Buffer
+	codec jsoncodec.Codec
+	enc   jsoncodec.Encoder
+}
+
+var encodeBuffers = sync.Pool{
+	New: func() interface{} {
+		return &encodeBuffer{}
+	},
+}
+
+// getEncodeBuffer returns a buffer whose encoder belongs to the current
+// codec. Benchmarks swap the codec, so pooled encoders may be stale.
+func getEncodeBuffer() *encodeBuffer {
+	b := encodeBuffers.Get().(*encodeBuffer)
+	if b.codec != codec {
+		b.codec = codec
+		b.enc = codec.NewEncoder(&b.buf)
+		b.enc.SetEscapeHTML(true)
+	}
+	return b
+}
+
+func putEncodeBuffer(b *encodeBuffer) {
+	if b.buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	b.buf.Reset()
+	encodeBuffers.Put(b)
+}
+
+// respondJSON writes v as JSON with the status set by render.Status. It
+// produces the same output as render.JSON but reuses its buffers. A
+// Response is written in the envelope version the request asks for, its
+// message is translated into the request's locale, and its timestamps are
+// shown in the request's time zone, if it has one.
+func respondJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	b := getEncodeBuffer()
+	defer putEncodeBuffer(b)
+
+	rc := request.Current(r.Context())
+	if resp, ok := v.(Response); ok {
+		resp = versioned(w, r, resp)
+		resp.Message = localizeMessage(rc.Locale, resp.Message)
+		if servertiming.Envelope(r.Context()) {
+			meta := ResponseMeta{}
+			if resp.Meta != nil {
+				meta = *resp.Meta
+			}
+			meta.Timings = requestTimings(r)
+			resp.Meta = &meta
+		}
+		v = resp
+	}
+
+	if err := b.enc.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	body := b.buf.Bytes()
+	if rc.TimeZone != nil {
+		body = localizeTimes(body, rc.TimeZone)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if status, ok := r.Context().Value(render.StatusCtxKey).(int); ok {
+		w.WriteHeader(status)
+	}
+	w.Write(body) //nolint:errcheck
+}
+
+// versioned returns resp as written in the envelope version r asks for,
+// announcing the version in the response headers. Version 1 envelopes
+// leave out the version and the pagination meta added since.
+func versioned(w http.ResponseWriter, r *http.Request, resp Response) Response {
+	version := utils.EnvelopeVersion(r)
+	w.Header().Set(utils.EnvelopeHeader, strconv.Itoa(version))
+	w.Header().Add("Vary", utils.EnvelopeHeader)
+	if version >= utils.EnvelopeV2 {
+		resp.Version = version
+		return resp
+	}
+	resp.Version = 0
+	if resp.Meta != nil && resp.Meta.Pagination != nil {
+		meta := *resp.Meta
+		meta.Pagination = nil
+		resp.Meta = &meta
+		if meta == (ResponseMeta{}) {
+			resp.Meta = nil
+		}
+	}
+	return resp
+}
+
+// requestTimings returns the breakdown of the request so far, or nil when
+// it is not being timed
+func requestTimings(r *http.Request) *TimingsDTO {
+	rec := timing.FromContext(r.Context())
+	if rec == nil {
+		return nil
+	}
+	b := rec.Breakdown()
+	return &TimingsDTO{
+		TotalMs:      servertiming.Milliseconds(b.Total),
+		MiddlewareMs: servertiming.Milliseconds(b.Middleware),
+		HandlerMs:    servertiming.Milliseconds(b.Handler),
+		UseCaseMs:    servertiming.Milliseconds(b.UseCase),
+		RepositoryMs: servertiming.Milliseconds(b.Repository),
+	}
+}
+
+// staticResponse is a Response whose JSON is encoded once for each
+// envelope version. Only the timestamp, the last field, is appended for
+// each request.
+type staticResponse struct {
+	// Everything up to the timestamp's opening quote, by envelope version
+	prefixes map[int][]byte
+}
+
+// timestampSuffix closes the timestamp string and the response object
+var timestampSuffix = []byte("\"}\n")
+
+// newStaticResponse pre-encodes resp, whose timestamp is ignored
+func newStaticResponse(resp Response) *staticResponse {
+	resp.Timestamp = time.Time{}
+	s := &staticResponse{prefixes: make(map[int][]byte, utils.LatestEnvelope)}
+	for version := utils.EnvelopeV1; version <= utils.LatestEnvelope; version++ {
+		resp.Version = 0
+		if version >= utils.EnvelopeV2 {
+			resp.Version = version
+		}
+		encoded, err := codec.Marshal(resp)
+		if err != nil {
+			panic("handlers: static response cannot be encoded: " + err.Error())
+		}
+		marker := []byte(`"timestamp":"`)
+		i := bytes.LastIndex(encoded, marker)
+		s.prefixes[version] = encoded[:i+len(marker)]
+	}
+	return s
+}
+
+// write writes the response in the envelope version r asks for, with the
+// current time and the given status
+func (s *staticResponse) write(w http.ResponseWriter, r *http.Request, status int) {
+	b := getEncodeBuffer()
+	defer putEncodeBuffer(b)
+
+	version := utils.EnvelopeVersion(r)
+	w.Header().Set(utils.EnvelopeHeader, strconv.Itoa(version))
+	w.Header().Add("Vary", utils.EnvelopeHeader)
+	b.buf.Write(s.prefixes[version])
+	b.buf.Write(time.Now().AppendFormat(b.buf.AvailableBuffer(), time.RFC3339Nano))
+	b.buf.Write(timestampSuffix)
+
+	w.Header().Set("Content-Type", "application/json")
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+	}
+	w.Write(b.buf.Bytes()) //nolint:errcheck
+}
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/internal/interfaces/http/middleware/servertiming"
+	"clean-architecture/pkg/timing"
+	"clean-architecture/pkg/utils"
+)
+
+func TestRespondJSON_MatchesRender(t *testing.T) {
+	resp := Response{
+		Status:    "success",
+		Message:   "Users <retrieved> & listed",
+		Data:      map[string]interface{}{"total": 2},
+		Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	expected := httptest.NewRecorder()
+	render.JSON(expected, req, resp)
+
+	rec := httptest.NewRecorder()
+	respondJSON(rec, req, resp)
+
+	assert.Equal(t, expected.Code, rec.Code)
+	assert.Equal(t, expected.Header().Get("Content-Type"), rec.Header().Get("Content-Type"))
+	assert.Equal(t, expected.Body.String(), rec.Body.String())
+}
+
+func TestRespondJSON_Status(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	render.Status(req, http.StatusCreated)
+
+	rec := httptest.NewRecorder()
+	respondJSON(rec, req, Response{Status: "success"})
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestRespondJSON_Timings(t *testing.T) {
+	var got Response
+	serve := func(envelope bool) {
+		handler := servertiming.Middleware(envelope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer timing.Start(r.Context(), timing.LayerHandler, "users:list").End()
+			respondJSON(w, r, Response{Status: "success"})
+		}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		got = Response{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	}
+
+	serve(false)
+	assert.Nil(t, got.Meta, "timings are only in the envelope when asked for")
+
+	serve(true)
+	require.NotNil(t, got.Meta)
+	require.NotNil(t, got.Meta.Timings)
+	assert.Greater(t, got.Meta.Timings.TotalMs, 0.0)
+	assert.GreaterOrEqual(t, got.Meta.Timings.TotalMs, got.Meta.Timings.HandlerMs)
+}
+
+func TestRespondJSON_Localized(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	resp := Response{
+		Status:    "success",
+		Message:   "User updated successfully",
+		Data:      map[string]interface{}{"created_at": "2024-01-01T12:00:00.5Z", "deleted_at": "0001-01-01T00:00:00Z", "name": "2024-01-01T12:00:00Z\""},
+		Timestamp: time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC),
+	}
+	serve := func(rc *request.Context) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(request.With(req.Context(), rc))
+		rec := httptest.NewRecorder()
+		respondJSON(rec, req, resp)
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		return got
+	}
+
+	got := serve(&request.Context{Locale: "en"})
+	assert.Equal(t, "User updated successfully", got["message"])
+	assert.Equal(t, "2024-07-01T12:00:00Z", got["timestamp"], "times stay UTC without a time zone")
+
+	got = serve(&request.Context{Locale: "de-at", TimeZone: berlin})
+	assert.Equal(t, "Benutzer aktualisiert", got["message"], "regions fall back to their language")
+	assert.Equal(t, "2024-07-01T14:00:00+02:00", got["timestamp"])
+	data := got["data"].(map[string]interface{})
+	assert.Equal(t, "2024-01-01T13:00:00.5+01:00", data["created_at"])
+	assert.Equal(t, "0001-01-01T00:00:00Z", data["deleted_at"], "zero times are kept")
+	assert.Equal(t, "2024-01-01T12:00:00Z\"", data["name"], "escaped strings are left alone")
+
+	got = serve(&request.Context{Locale: "fr"})
+	assert.Equal(t, "User updated successfully", got["message"], "untranslated locales stay English")
+}
+
+func TestRespondJSON_EnvelopeVersions(t *testing.T) {
+	resp := Response{
+		Status:    "success",
+		Data:      []string{"user_1", "user_2"},
+		Meta:      listMeta(2, 0, 2),
+		Timestamp: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	serve := func(version string, envelope bool) (*httptest.ResponseRecorder, map[string]interface{}) {
+		handler := servertiming.Middleware(envelope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			respondJSON(w, r, resp)
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if version != "" {
+			req.Header.Set(utils.EnvelopeHeader, version)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		return rec, got
+	}
+
+	rec, got := serve("", false)
+	assert.Equal(t, "1", rec.Header().Get(utils.EnvelopeHeader))
+	assert.Equal(t, utils.EnvelopeHeader, rec.Header().Get("Vary"))
+	assert.NotContains(t, got, "version", "clients asking for no version get the original envelope")
+	assert.NotContains(t, got, "meta")
+
+	rec, got = serve("2", false)
+	assert.Equal(t, "2", rec.Header().Get(utils.EnvelopeHeader))
+	assert.Equal(t, float64(2), got["version"])
+	assert.Equal(t, map[string]interface{}{
+		"pagination": map[string]interface{}{"limit": float64(2), "offset": float64(0), "count": float64(2), "next_offset": float64(2)},
+	}, got["meta"])
+
+	_, got = serve("1", true)
+	meta := got["meta"].(map[string]interface{})
+	assert.Contains(t, meta, "timings", "debug timings are kept in version 1 envelopes")
+	assert.NotContains(t, meta, "pagination")
+
+	_, got = serve("2", true)
+	meta = got["meta"].(map[string]interface{})
+	assert.Contains(t, meta, "timings")
+	assert.Contains(t, meta, "pagination", "timings are added next to the pagination")
+}
+
+func TestMessageLocales(t *testing.T) {
+	assert.Equal(t, []string{"en", "de"}, MessageLocales())
+}
+
+func TestStaticResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		expectedStatus int
+		expected       Response
+	}{
+		{
+			name:           "health check",
+			handler:        HealthCheck,
+			expectedStatus: http.StatusOK,
+			expected:       Response{Status: "success", Message: "Service is healthy"},
+		},
+		{
+			name:           "not found",
+			handler:        NotFoundHandler,
+			expectedStatus: http.StatusNotFound,
+			expected:       Response{Status: "error", Message: "Endpoint not found"},
+		},
+		{
+			name:           "method not allowed",
+			handler:        MethodNotAllowedHandler,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expected:       Response{Status: "error", Message: "Method not allowed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			rec := httptest.NewRecorder()
+			tt.handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+			var got Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+			assert.Equal(t, tt.expected.Status, got.Status)
+			assert.Equal(t, tt.expected.Message, got.Message)
+			assert.False(t, got.Timestamp.Before(before.Truncate(time.Second)), "timestamp should be the time of the request")
+		})
+	}
+}
+
+func TestStaticResponse_EnvelopeVersions(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(utils.EnvelopeHeader, "2")
+	rec := httptest.NewRecorder()
+	NotFoundHandler(rec, req)
+
+	assert.Equal(t, "2", rec.Header().Get(utils.EnvelopeHeader))
+	var got Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, utils.EnvelopeV2, got.Version)
+	assert.Equal(t, "Endpoint not found", got.Message)
+	assert.False(t, got.Timestamp.IsZero())
+
+	rec = httptest.NewRecorder()
+	NotFoundHandler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.NotContains(t, rec.Body.String(), `"version"`)
+}
+
+func TestStaticResponse_RootData(t *testing.T) {
+	rec := httptest.NewRecorder()
+	RootHandler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, "Clean Architecture API", got["message"])
+	assert.Equal(t, map[string]interface{}{
+		"version":   "1.1.0",
+		"docs":      "/docs",
+		"changelog": "/api/v1/changelog",
+		"schemas":   "/api/v1/schemas",
+	}, got["data"])
+}
+
+func benchmarkUsers() Response {
+	users := make([]*entities.User, 20)
+	for i := range users {
+		users[i] = &entities.User{
+			ID:        fmt.Sprintf("user-%d", i),
+			Email:     "user@example.com",
+			Name:      "Example User",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+	}
+	return Response{
+		Status:    "success",
+		Message:   "Users retrieved successfully",
+		Data:      map[string]interface{}{"users": users, "total": len(users)},
+		Timestamp: time.Now(),
+	}
+}
+
+func BenchmarkRespondJSON(b *testing.B) {
+	resp := benchmarkUsers()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+
+	b.Run("render", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			render.JSON(httptest.NewRecorder(), req, resp)
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			respondJSON(httptest.NewRecorder(), req, resp)
+		}
+	})
+}
+
+func BenchmarkHealthCheck(b *testing.B) {
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+
+	b.Run("render", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			render.JSON(httptest.NewRecorder(), req, Response{
+				Status:    "success",
+				Message:   "Service is healthy",
+				Timestamp: time.Now(),
+			})
+		}
+	})
+	b.Run("static", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			HealthCheck(httptest.NewRecorder(), req)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/pkg/saml"
+)
+
+// SAMLTenant logs in the users of one tenant at its SAML identity provider
+type SAMLTenant interface {
+	// Metadata returns the service provider metadata
+	Metadata() ([]byte, error)
+	// AllowsRedirect reports whether logins may return to uri
+	AllowsRedirect(uri string) bool
+	// StartLogin returns the URL of the identity provider to send the
+	// user to
+	StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error)
+	// FinishLogin verifies the identity provider's response and returns
+	// the identity it asserts and the URL passed to StartLogin
+	FinishLogin(w http.ResponseWriter, r *http.Request) (*auth.Identity, string, error)
+}
+
+// WithSAML enables single sign-on for tenants, keyed by tenant ID
+func (h *AuthHandler) WithSAML(tenants map[string]SAMLTenant) *AuthHandler {
+	h.saml = tenants
+	return h
+}
+
+// SAMLMetadata godoc
+// @Summary      Get SAML service provider metadata
+// @Description  Get the SAML metadata to register at the tenant's identity provider
+// @Tags         auth
+// @Produce      xml
+// @Param        tenant  path      string  true  "Tenant ID"
+// @Success      200     {string}  string
+// @Failure      404     {object}  ErrorResponse
+// @Router       /api/v1/auth/saml/{tenant}/metadata [get]
+func (h *AuthHandler) SAMLMetadata(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := h.samlTenant(w, r)
+	if !ok {
+		return
+	}
+
+	metadata, err := tenant.Metadata()
+	if err != nil {
+		InternalError(w, r, h.logger, err)
+		return
+	}
+	w.Header().Set("Content-Type", saml.MetadataContentType)
+	_, _ = w.Write(metadata)
+}
+
+// SAMLLogin godoc
+// @Summary      Start a SAML login
+// @Description  Redirect to the tenant's identity provider. After logging in there, the user returns to redirect_uri with the access token in the URL fragment; without redirect_uri the token is returned as JSON.
+// @Tags         auth
+// @Param        tenant        path      string  true   "Tenant ID"
+// @Param        redirect_uri  query     string  false  "Registered URL to return to"
+// @Success      302
+// @Failure      400           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      503           {object}  ErrorResponse
+// @Router       /api/v1/auth/saml/{tenant}/login [get]
+func (h *AuthHandler) SAMLLogin(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := h.samlTenant(w, r)
+	if !ok {
+		return
+	}
+
+	redirectURI := r.URL.Query().Get("redirect_uri")
+	if redirectURI != "" && !tenant.AllowsRedirect(redirectURI) {
+		render.Status(r, http.StatusBadRequest)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   "redirect_uri is not registered for this tenant",
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	location, err := tenant.StartLogin(w, r, redirectURI)
+	if err != nil {
+		h.samlError(w, r, err)
+		return
+	}
+	http.Redirect(w, r, location, http.StatusFound)
+}
+
+// SAMLACS godoc
+// @Summary      Finish a SAML login
+// @Description  Assertion consumer service the identity provider posts its response to. Users logging in for the first time are created.
+// @Tags         auth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        tenant        path      string  true  "Tenant ID"
+// @Param        SAMLResponse  formData  string  true  "SAML response"
+// @Param        RelayState    formData  string  true  "Relay state"
+// @Success      200           {object}  SuccessResponse
+// @Success      303
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/v1/auth/saml/{tenant}/acs [post]
+func (h *AuthHandler) SAMLACS(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := h.samlTenant(w, r)
+	if !ok {
+		return
+	}
+
+	identity, returnTo, err := tenant.FinishLogin(w, r)
+	if err != nil {
+		h.samlError(w, r, err)
+		return
+	}
+	h.completeSSO(w, r, identity, returnTo)
+}
+
+// completeSSO logs in an identity verified by single sign-on. The session
+// is returned as JSON, or, when the login was started with a redirect URL,
+// handed to it in the URL fragment.
+func (h *AuthHandler) completeSSO(w http.ResponseWriter, r *http.Request, identity *auth.Identity, returnTo string) {
+	session, err := h.authUseCase.LoginIdentity(r.Context(), identity)
+	if err != nil {
+		h.loginError(w, r, err)
+		return
+	}
+
+	if returnTo == "" {
+		h.respondSession(w, r, session)
+		return
+	}
+	// The fragment keeps the token out of server logs and Referer headers
+	fragment := url.Values{
+		"access_token": {session.AccessToken},
+		"token_type":   {"Bearer"},
+		"expires_in":   {strconv.FormatInt(int64(time.Until(session.ExpiresAt).Seconds()), 10)},
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, returnTo+"#"+fragment.Encode(), http.StatusSeeOther)
+}
+
+// samlTenant returns the tenant named in the path, writing a 404 for
+// unknown tenants
+func (h *AuthHandler) samlTenant(w http.ResponseWriter, r *http.Request) (SAMLTenant, bool) {
+	tenant, ok := h.saml[chi.URLParam(r, "tenant")]
+	if !ok {
+		render.Status(r, http.StatusNotFound)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   "Unknown SAML tenant",
+			Timestamp: time.Now(),
+		})
+		return nil, false
+	}
+	return tenant, true
+}
+
+// samlError writes the response for a failed SAML login. Why a response
+// was invalid is logged rather than returned.
+func (h *AuthHandler) samlError(w http.ResponseWriter, r *http.Request, err error) {
+	var status int
+	var message string
+	switch {
+	case errors.Is(err, saml.ErrUnknownRequest):
+		status, message = http.StatusBadRequest, "Login request is unknown or expired; start the login again"
+	case errors.Is(err, saml.ErrInvalidResponse):
+		h.logger.WithFields(map[string]interface{}{
+			"tenant": chi.URLParam(r, "tenant"),
+			"error":  err.Error(),
+		}).Warn("Rejected SAML response")
+		status, message = http.StatusUnauthorized, "Identity provider response is invalid"
+	case errors.Is(err, saml.ErrMetadataUnavailable):
+		status, message = http.StatusServiceUnavailable, "Identity provider is unavailable"
+	default:
+		h.loginError(w, r, err)
+		return
+	}
+
+	errorStatus(w, r, status, err)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/saml"
+)
+
+// MockSAMLTenant is a mock implementation of SAMLTenant
+type MockSAMLTenant struct {
+	mock.Mock
+}
+
+func (m *MockSAMLTenant) Metadata() ([]byte, error) {
+	args := m.Called()
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockSAMLTenant) AllowsRedirect(uri string) bool {
+	return m.Called(uri).Bool(0)
+}
+
+func (m *MockSAMLTenant) StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error) {
+	args := m.Called(returnTo)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSAMLTenant) FinishLogin(w http.ResponseWriter, r *http.Request) (*auth.Identity, string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*auth.Identity), args.String(1), args.Error(2)
+}
+
+// serveSAML routes req to the SAML endpoints of a handler with tenant acme
+func serveSAML(authUseCase usecase.AuthUseCaseInterface, tenant *MockSAMLTenant, req *http.Request) *httptest.ResponseRecorder {
+	handler := NewAuthHandler(authUseCase, NewUserPresenter(nil), logger.New()).
+		WithSAML(map[string]SAMLTenant{"acme": tenant})
+	r := chi.NewRouter()
+	r.Get("/auth/saml/{tenant}/metadata", handler.SAMLMetadata)
+	r.Get("/auth/saml/{tenant}/login", handler.SAMLLogin)
+	r.Post("/auth/saml/{tenant}/acs", handler.SAMLACS)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func samlSession() *usecase.Session {
+	return &usecase.Session{
+		AccessToken: "token_1",
+		ExpiresAt:   time.Now().Add(15 * time.Minute),
+		Claims:      auth.Claims{Subject: "user_1", Roles: []string{"user"}},
+		User:        &entities.User{ID: "user_1", Email: "bjensen@example.com", Name: "Barbara Jensen"},
+	}
+}
+
+func TestAuthHandler_SAMLMetadata(t *testing.T) {
+	tenant := new(MockSAMLTenant)
+	tenant.On("Metadata").Return([]byte("<EntityDescriptor/>"), nil)
+
+	w := serveSAML(new(MockAuthUseCase), tenant, httptest.NewRequest("GET", "/auth/saml/acme/metadata", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, saml.MetadataContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "<EntityDescriptor/>", w.Body.String())
+
+	w = serveSAML(new(MockAuthUseCase), tenant, httptest.NewRequest("GET", "/auth/saml/globex/metadata", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Unknown SAML tenant")
+}
+
+func TestAuthHandler_SAMLLogin(t *testing.T) {
+	tenant := new(MockSAMLTenant)
+	tenant.On("AllowsRedirect", "https://app.example.com/sso").Return(true)
+	tenant.On("AllowsRedirect", "https://evil.example.net/").Return(false)
+	tenant.On("StartLogin", "https://app.example.com/sso").Return("https://idp.example.com/sso?SAMLRequest=abc", nil)
+	tenant.On("StartLogin", "").Return("", saml.ErrMetadataUnavailable)
+
+	w := serveSAML(new(MockAuthUseCase), tenant, httptest.NewRequest("GET", "/auth/saml/acme/login?redirect_uri="+url.QueryEscape("https://app.example.com/sso"), nil))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://idp.example.com/sso?SAMLRequest=abc", w.Header().Get("Location"))
+
+	w = serveSAML(new(MockAuthUseCase), tenant, httptest.NewRequest("GET", "/auth/saml/acme/login?redirect_uri="+url.QueryEscape("https://evil.example.net/"), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "redirect_uri is not registered for this tenant")
+
+	w = serveSAML(new(MockAuthUseCase), tenant, httptest.NewRequest("GET", "/auth/saml/acme/login", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "Identity provider is unavailable")
+}
+
+func TestAuthHandler_SAMLACS(t *testing.T) {
+	identity := &auth.Identity{Provider: "saml:acme", Subject: "00u1abcd", Email: "bjensen@example.com", Roles: []string{"user"}}
+	newACSRequest := func() *http.Request {
+		req := httptest.NewRequest("POST", "/auth/saml/acme/acs", strings.NewReader("SAMLResponse=abc&RelayState=def"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	t.Run("returns JSON without a redirect", func(t *testing.T) {
+		tenant := new(MockSAMLTenant)
+		tenant.On("FinishLogin").Return(identity, "", nil)
+		authUseCase := new(MockAuthUseCase)
+		authUseCase.On("LoginIdentity", mock.Anything, identity).Return(samlSession(), nil)
+
+		w := serveSAML(authUseCase, tenant, newACSRequest())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		var body struct {
+			Data LoginResponseDTO `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "token_1", body.Data.AccessToken)
+		assert.Equal(t, "bjensen@example.com", body.Data.User.Email)
+		authUseCase.AssertExpectations(t)
+	})
+
+	t.Run("redirects with the token in the fragment", func(t *testing.T) {
+		tenant := new(MockSAMLTenant)
+		tenant.On("FinishLogin").Return(identity, "https://app.example.com/sso", nil)
+		authUseCase := new(MockAuthUseCase)
+		authUseCase.On("LoginIdentity", mock.Anything, identity).Return(samlSession(), nil)
+
+		w := serveSAML(authUseCase, tenant, newACSRequest())
+		assert.Equal(t, http.StatusSeeOther, w.Code)
+		location, err := url.Parse(w.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, "https://app.example.com/sso", location.Scheme+"://"+location.Host+location.Path)
+		assert.Empty(t, location.RawQuery)
+		fragment, err := url.ParseQuery(location.Fragment)
+		require.NoError(t, err)
+		assert.Equal(t, "token_1", fragment.Get("access_token"))
+		assert.Equal(t, "Bearer", fragment.Get("token_type"))
+		assert.NotEmpty(t, fragment.Get("expires_in"))
+	})
+}
+
+func TestAuthHandler_SAMLACS_Errors(t *testing.T) {
+	identity := &auth.Identity{Provider: "saml:acme", Subject: "00u1abcd"}
+
+	tests := []struct {
+		name            string
+		finishErr       error
+		loginErr        error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "unknown request",
+			finishErr:       saml.ErrUnknownRequest,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Login request is unknown or expired; start the login again",
+		},
+		{
+			name:            "invalid response",
+			finishErr:       errors.Join(saml.ErrInvalidResponse, errors.New("signature mismatch")),
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "Identity provider response is invalid",
+		},
+		{
+			name:            "no role",
+			finishErr:       auth.ErrAccessDenied,
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "Account is not permitted to log in",
+		},
+		{
+			name:            "no email",
+			loginErr:        usecase.ErrIdentityWithoutEmail,
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "Account has no email address",
+		},
+		{
+			name:            "provisioning failure",
+			loginErr:        errors.New("database unavailable"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: internalErrorMessage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant := new(MockSAMLTenant)
+			if tt.finishErr != nil {
+				tenant.On("FinishLogin").Return(nil, "", tt.finishErr)
+			} else {
+				tenant.On("FinishLogin").Return(identity, "", nil)
+			}
+			authUseCase := new(MockAuthUseCase)
+			authUseCase.On("LoginIdentity", mock.Anything, identity).Return(nil, tt.loginErr)
+
+			req := httptest.NewRequest("POST", "/auth/saml/acme/acs", strings.NewReader("SAMLResponse=abc&RelayState=def"))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := serveSAML(authUseCase, tenant, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedMessage, body["message"])
+			assert.NotContains(t, w.Body.String(), "signature")
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// ActionShareView guards creating and deleting views shared with the whole
+// organization
+const ActionShareView = "views:share"
+
+// SavedViewHandler handles saving named user listings
+type SavedViewHandler struct {
+	viewUseCase usecase.SavedViewUseCaseInterface
+	engine      policy.Engine
+	defaults    APIDefaults
+	logger      logger.Logger
+}
+
+// NewSavedViewHandler creates a new saved view handler. When engine is nil,
+// everyone may share views.
+func NewSavedViewHandler(viewUseCase usecase.SavedViewUseCaseInterface, engine policy.Engine, logger logger.Logger) *SavedViewHandler {
+	return &SavedViewHandler{
+		viewUseCase: viewUseCase,
+		engine:      engine,
+		defaults:    DefaultAPIDefaults(),
+		logger:      logger,
+	}
+}
+
+// WithDefaults replaces the bounds views are validated against
+func (h *SavedViewHandler) WithDefaults(defaults APIDefaults) *SavedViewHandler {
+	h.defaults = defaults
+	return h
+}
+
+// SavedViewDTO is the API representation of a saved view
+type SavedViewDTO struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	OwnerID   string            `json:"owner_id"`
+	Shared    bool              `json:"shared"`
+	Filters   map[string]string `json:"filters"`
+	Sort      string            `json:"sort,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// CreateSavedViewRequest represents the request body for saving a view.
+// Filters and Sort take the parameters GET /api/v1/users accepts.
+type CreateSavedViewRequest struct {
+	Name    string            `json:"name"`
+	Shared  bool              `json:"shared,omitempty"`
+	Filters map[string]string `json:"filters,omitempty"`
+	Sort    string            `json:"sort,omitempty"`
+}
+
+// CreateView godoc
+// @Summary      Save a user list view
+// @Description  Save named filters and sort order for GET /api/v1/users?view={id}. Shared views are visible to everyone and need the views:share permission.
+// @Tags         views
+// @Accept       json
+// @Produce      json
+// @Param        view  body      CreateSavedViewRequest  true  "View definition"
+// @Success      201   {object}  SuccessResponse
+// @Failure      400   {object}  ErrorResponse
+// @Failure      401   {object}  ErrorResponse
+// @Failure      403   {object}  ErrorResponse
+// @Failure      422   {object}  ErrorResponse
+// @Router       /api/v1/views [post]
+func (h *SavedViewHandler) CreateView(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.subject(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateSavedViewRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if err := validateViewQuery(req.Filters, req.Sort, h.defaults); err != nil {
+		unprocessable(w, r, "invalid_view", "Invalid view: "+err.Error())
+		return
+	}
+	if req.Shared && !h.canShare(r.Context(), "") {
+		forbidden(w, r, "Sharing views is not permitted")
+		return
+	}
+
+	view := entities.NewSavedView(subject.ID, req.Name, req.Shared, req.Filters, req.Sort)
+	if err := h.viewUseCase.CreateView(r.Context(), view); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "View saved successfully",
+		Data:      presentSavedView(view),
+		Timestamp: time.Now(),
+	})
+}
+
+// ListViews godoc
+// @Summary      List saved views
+// @Description  List the caller's own views and every shared view, by name
+// @Tags         views
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/views [get]
+func (h *SavedViewHandler) ListViews(w http.ResponseWriter, r *http.Request) {
+	subject, _ := policy.SubjectFromContext(r.Context())
+
+	views, err := h.viewUseCase.ListViews(r.Context(), subject.ID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]SavedViewDTO, 0, len(views))
+	for _, view := range views {
+		dtos = append(dtos, presentSavedView(view))
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Views retrieved successfully",
+		Data:      dtos,
+		Timestamp: time.Now(),
+	})
+}
+
+// GetView godoc
+// @Summary      Get a saved view
+// @Description  Get a view the caller owns or that is shared
+// @Tags         views
+// @Produce      json
+// @Param        id   path      string  true  "View ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/views/{id} [get]
+func (h *SavedViewHandler) GetView(w http.ResponseWriter, r *http.Request) {
+	subject, _ := policy.SubjectFromContext(r.Context())
+
+	view, err := h.viewUseCase.GetView(r.Context(), subject.ID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "View retrieved successfully",
+		Data:      presentSavedView(view),
+		Timestamp: time.Now(),
+	})
+}
+
+// DeleteView godoc
+// @Summary      Delete a saved view
+// @Description  Delete one of the caller's views. Deleting another user's shared view needs the views:share permission.
+// @Tags         views
+// @Produce      json
+// @Param        id   path      string  true  "View ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/views/{id} [delete]
+func (h *SavedViewHandler) DeleteView(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.subject(w, r)
+	if !ok {
+		return
+	}
+
+	view, err := h.viewUseCase.GetView(r.Context(), subject.ID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	if view.OwnerID != subject.ID && !h.canShare(r.Context(), view.ID) {
+		forbidden(w, r, "Only the owner may delete this view")
+		return
+	}
+
+	if err := h.viewUseCase.DeleteView(r.Context(), view.ID); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "View deleted successfully",
+		Timestamp: time.Now(),
+	})
+}
+
+// validateViewQuery checks that filters and sort are valid parameters of a
+// user listing, so a view fails when it is saved rather than when it runs
+func validateViewQuery(filters map[string]string, sort string, d APIDefaults) error {
+	query := make(url.Values, len(filters))
+	for key, value := range filters {
+		if !strings.HasPrefix(key, "filter[") {
+			return fmt.Errorf("malformed parameter %q, expected filter[field][operator]", key)
+		}
+		query.Set(key, value)
+	}
+	if _, err := parseFilters(query, userFilterFields, d); err != nil {
+		return err
+	}
+	_, err := parseSort(sort, userFilterFields, d)
+	return err
+}
+
+// canShare asks the policy engine whether the caller may manage shared
+// views. Evaluation errors fail closed.
+func (h *SavedViewHandler) canShare(ctx context.Context, viewID string) bool {
+	if h.engine == nil {
+		return true
+	}
+
+	subject, _ := policy.SubjectFromContext(ctx)
+	decision, err := h.engine.Evaluate(ctx, policy.Input{
+		Subject:  subject,
+		Action:   ActionShareView,
+		Resource: policy.Resource{Type: "view", ID: viewID},
+	})
+	if err != nil {
+		return false
+	}
+	return decision.Allowed
+}
+
+// subject returns the authenticated subject, writing a 401 response when
+// the request carries none
+func (h *SavedViewHandler) subject(w http.ResponseWriter, r *http.Request) (policy.Subject, bool) {
+	subject, ok := policy.SubjectFromContext(r.Context())
+	if !ok || subject.ID == "" {
+		render.Status(r, http.StatusUnauthorized)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return policy.Subject{}, false
+	}
+	return subject, true
+}
+
+// forbidden writes a 403 Forbidden error response
+func forbidden(w http.ResponseWriter, r *http.Request, message string) {
+	render.Status(r, http.StatusForbidden)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
+
+func presentSavedView(view *entities.SavedView) SavedViewDTO {
+	filters := view.Filters
+	if filters == nil {
+		filters = map[string]string{}
+	}
+	return SavedViewDTO{
+		ID:        view.ID,
+		Name:      view.Name,
+		OwnerID:   view.OwnerID,
+		Shared:    view.Shared,
+		Filters:   filters,
+		Sort:      view.Sort,
+		CreatedAt: view.CreatedAt,
+		UpdatedAt: view.UpdatedAt,
+	}
+}
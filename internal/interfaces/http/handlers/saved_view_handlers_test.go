@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	policyinfra "clean-architecture/internal/infrastructure/policy"
+	"clean-architecture/pkg/logger"
+)
+
+// MockSavedViewUseCase is a mock implementation of SavedViewUseCaseInterface
+type MockSavedViewUseCase struct {
+	mock.Mock
+}
+
+func (m *MockSavedViewUseCase) CreateView(ctx context.Context, view *entities.SavedView) error {
+	args := m.Called(ctx, view)
+	if args.Error(0) == nil {
+		view.ID = "view_1"
+	}
+	return args.Error(0)
+}
+
+func (m *MockSavedViewUseCase) GetView(ctx context.Context, userID, id string) (*entities.SavedView, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.SavedView), args.Error(1)
+}
+
+func (m *MockSavedViewUseCase) ListViews(ctx context.Context, userID string) ([]*entities.SavedView, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.SavedView), args.Error(1)
+}
+
+func (m *MockSavedViewUseCase) DeleteView(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func viewRequest(method, target, body string, subject policy.Subject) *http.Request {
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	return withCaller(req, subject)
+}
+
+func TestSavedViewHandler_CreateView(t *testing.T) {
+	engine := policyinfra.NewRoleEngine(policyinfra.DefaultRules())
+	user := policy.Subject{ID: "user_1", Roles: []string{"user"}}
+	admin := policy.Subject{ID: "admin_1", Roles: []string{"admin"}}
+
+	tests := []struct {
+		name           string
+		subject        policy.Subject
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "personal view",
+			subject:        user,
+			body:           `{"name":"Smiths","filters":{"filter[name][contains]":"smith"},"sort":"-created_at"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "shared view by admin",
+			subject:        admin,
+			body:           `{"name":"Recent","shared":true,"filters":{"filter[created_at][gte]":"2024-01-01"}}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "shared view by user",
+			subject:        user,
+			body:           `{"name":"Recent","shared":true}`,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  "Sharing views is not permitted",
+		},
+		{
+			name:           "invalid filter",
+			subject:        user,
+			body:           `{"name":"Secrets","filters":{"filter[password]":"x"}}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "Invalid view: password cannot be filtered on",
+		},
+		{
+			name:           "not a filter",
+			subject:        user,
+			body:           `{"name":"Paged","filters":{"limit":"1000"}}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "Invalid view: malformed parameter",
+		},
+		{
+			name:           "invalid sort",
+			subject:        user,
+			body:           `{"name":"Sorted","sort":"password"}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "cannot be sorted on",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockSavedViewUseCase)
+			mockUseCase.On("CreateView", mock.Anything, mock.MatchedBy(func(view *entities.SavedView) bool {
+				return view.OwnerID == tt.subject.ID
+			})).Return(nil)
+			handler := NewSavedViewHandler(mockUseCase, engine, logger.New())
+
+			w := httptest.NewRecorder()
+			handler.CreateView(w, viewRequest(http.MethodPost, "/views", tt.body, tt.subject))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedError)
+				mockUseCase.AssertNotCalled(t, "CreateView", mock.Anything, mock.Anything)
+				return
+			}
+			var response struct {
+				Data SavedViewDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "view_1", response.Data.ID)
+			assert.Equal(t, tt.subject.ID, response.Data.OwnerID)
+		})
+	}
+}
+
+func TestSavedViewHandler_RequiresSubject(t *testing.T) {
+	handler := NewSavedViewHandler(new(MockSavedViewUseCase), nil, logger.New())
+
+	w := httptest.NewRecorder()
+	handler.CreateView(w, httptest.NewRequest(http.MethodPost, "/views", bytes.NewBufferString(`{"name":"Smiths"}`)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSavedViewHandler_DeleteView(t *testing.T) {
+	engine := policyinfra.NewRoleEngine(policyinfra.DefaultRules())
+	shared := &entities.SavedView{ID: "view_1", OwnerID: "admin_1", Name: "Recent", Shared: true}
+
+	tests := []struct {
+		name           string
+		subject        policy.Subject
+		expectedStatus int
+	}{
+		{name: "owner", subject: policy.Subject{ID: "admin_1", Roles: []string{"user"}}, expectedStatus: http.StatusOK},
+		{name: "admin", subject: policy.Subject{ID: "admin_2", Roles: []string{"admin"}}, expectedStatus: http.StatusOK},
+		{name: "other user", subject: policy.Subject{ID: "user_1", Roles: []string{"user"}}, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockSavedViewUseCase)
+			mockUseCase.On("GetView", mock.Anything, tt.subject.ID, "view_1").Return(shared, nil)
+			mockUseCase.On("DeleteView", mock.Anything, "view_1").Return(nil)
+			handler := NewSavedViewHandler(mockUseCase, engine, logger.New())
+
+			req := viewRequest(http.MethodDelete, "/views/view_1", "", tt.subject)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "view_1")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+			handler.DeleteView(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				mockUseCase.AssertNotCalled(t, "DeleteView", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUserHandler_ListUsers_View(t *testing.T) {
+	views := new(MockSavedViewUseCase)
+	views.On("GetView", mock.Anything, "user_1", "view_1").Return(&entities.SavedView{
+		ID:      "view_1",
+		Filters: map[string]string{"filter[name][contains]": "smith"},
+		Sort:    "-created_at",
+	}, nil)
+	views.On("GetView", mock.Anything, "user_1", "view_2").Return(nil, repositories.ErrSavedViewNotFound)
+
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("SearchUsers", mock.Anything, repositories.Specification{
+		Conditions: []repositories.Condition{
+			{Field: "email", Operator: repositories.OpStartsWith, Value: "jane"},
+			{Field: "name", Operator: repositories.OpContains, Value: "smith"},
+		},
+		Sort:  []repositories.SortOrder{{Field: "name"}},
+		Limit: 10,
+	}).Return([]*entities.User{{ID: "user_2", Name: "Jane Smith"}}, nil)
+	handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New()).WithSavedViews(views)
+	subject := policy.Subject{ID: "user_1", Roles: []string{"user"}}
+
+	// Request filters are combined with the view's, and the request's sort
+	// replaces the view's
+	w := httptest.NewRecorder()
+	handler.ListUsers(w, viewRequest(http.MethodGet, "/users?view=view_1&filter[email][starts_with]=jane&sort=name", "", subject))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Jane Smith")
+
+	w = httptest.NewRecorder()
+	handler.ListUsers(w, viewRequest(http.MethodGet, "/users?view=view_1&filter[name][contains]=doe", "", subject))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "filter[name][contains] is given more than once")
+
+	w = httptest.NewRecorder()
+	handler.ListUsers(w, viewRequest(http.MethodGet, "/users?view=view_2", "", subject))
+	assert.Contains(t, w.Body.String(), repositories.ErrSavedViewNotFound.Error())
+
+	mockUseCase.AssertExpectations(t)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/pkg/logger"
+)
+
+// SchemaContentType is the media type schemas are served with
+const SchemaContentType = "application/schema+json"
+
+// SchemaType names a DTO whose JSON Schema is published
+type SchemaType struct {
+	Name        string
+	Description string
+	Value       interface{}
+}
+
+// SchemaTypes lists the request and response bodies published at
+// /api/v1/schemas/{name}. Their schemas are generated into docs/schemas; run
+// go generate ./docs after changing them or any DTO they name.
+var SchemaTypes = []SchemaType{
+	{Name: "user", Description: "A user", Value: UserDTO{}},
+	{Name: "create_user_request", Description: "Request body of POST /api/v1/users", Value: CreateUserRequest{}},
+	{Name: "update_user_request", Description: "Request body of PUT /api/v1/users/{id}", Value: UpdateUserRequest{}},
+	{Name: "confirm_email_change_request", Description: "Request body of POST /api/v1/email-changes/confirm", Value: ConfirmEmailChangeRequest{}},
+	{Name: "upsert_user_result", Description: "Response data of PUT /api/v1/users:upsert, whose request body is a create_user_request", Value: UpsertUserResultDTO{}},
+	{Name: "user_deletion", Description: "A request to delete a user account", Value: UserDeletionDTO{}},
+	{Name: "cancel_deletion_request", Description: "Request body of POST /api/v1/account-deletions/cancel", Value: CancelDeletionRequest{}},
+	{Name: "export", Description: "A background export of users", Value: ExportDTO{}},
+	{Name: "request_export_request", Description: "Request body of POST /api/v1/users/exports", Value: RequestExportRequest{}},
+	{Name: "upload", Description: "A resumable upload of a file to import", Value: UploadDTO{}},
+	{Name: "create_upload_request", Description: "Request body of POST /api/v1/users/imports/uploads", Value: CreateUploadRequest{}},
+	{Name: "import", Description: "A background import of users", Value: ImportDTO{}},
+	{Name: "saved_view", Description: "A saved user listing", Value: SavedViewDTO{}},
+	{Name: "create_saved_view_request", Description: "Request body of POST /api/v1/views", Value: CreateSavedViewRequest{}},
+	{Name: "api_key", Description: "An API key other services authenticate with", Value: APIKeyDTO{}},
+	{Name: "create_api_key_request", Description: "Request body of POST /api/v1/apikeys", Value: CreateAPIKeyRequest{}},
+	{Name: "organization", Description: "An organization service accounts belong to", Value: OrganizationDTO{}},
+	{Name: "create_organization_request", Description: "Request body of POST /api/v1/organizations", Value: CreateOrganizationRequest{}},
+	{Name: "service_account", Description: "A non-human user of an organization", Value: ServiceAccountDTO{}},
+	{Name: "create_service_account_request", Description: "Request body of POST /api/v1/organizations/{id}/service-accounts", Value: CreateServiceAccountRequest{}},
+	{Name: "usage_record", Description: "The API calls of a key to a route on a day", Value: UsageDTO{}},
+	{Name: "login_request", Description: "Request body of POST /api/v1/auth/login", Value: LoginRequest{}},
+	{Name: "login_response", Description: "Response data of POST /api/v1/auth/login and POST /api/v1/auth/signup", Value: LoginResponseDTO{}},
+	{Name: "signup_request", Description: "Request body of POST /api/v1/auth/signup", Value: SignupRequest{}},
+}
+
+// schemaName matches the names schemas are published under
+var schemaName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// SchemaHandler serves the generated JSON Schemas of API payloads
+type SchemaHandler struct {
+	schemas fs.FS
+	logger  logger.Logger
+}
+
+// NewSchemaHandler creates a new schema handler serving the {name}.json
+// files at the root of schemas
+func NewSchemaHandler(schemas fs.FS, logger logger.Logger) *SchemaHandler {
+	return &SchemaHandler{schemas: schemas, logger: logger}
+}
+
+// SchemaLinkDTO points at a published schema
+type SchemaLinkDTO struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// ListSchemas godoc
+// @Summary      List JSON Schemas
+// @Description  List the names of the published JSON Schemas of request and response bodies
+// @Tags         meta
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/schemas [get]
+func (h *SchemaHandler) ListSchemas(w http.ResponseWriter, r *http.Request) {
+	files, err := fs.Glob(h.schemas, "*.json")
+	if err != nil {
+		InternalError(w, r, h.logger, err)
+		return
+	}
+	sort.Strings(files)
+
+	links := make([]SchemaLinkDTO, 0, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(file, ".json")
+		links = append(links, SchemaLinkDTO{Name: name, URL: "/api/v1/schemas/" + name})
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Schemas retrieved successfully",
+		Data:      links,
+		Timestamp: time.Now(),
+	})
+}
+
+// GetSchema godoc
+// @Summary      Get a JSON Schema
+// @Description  Get the JSON Schema of a request or response body, as a bare schema document
+// @Tags         meta
+// @Produce      application/schema+json
+// @Param        name  path      string  true  "Schema name, e.g. create_user_request"
+// @Success      200   {object}  object
+// @Failure      404   {object}  ErrorResponse
+// @Router       /api/v1/schemas/{name} [get]
+func (h *SchemaHandler) GetSchema(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if !schemaName.MatchString(name) {
+		h.schemaNotFound(w, r)
+		return
+	}
+
+	schema, err := fs.ReadFile(h.schemas, name+".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		h.schemaNotFound(w, r)
+		return
+	}
+	if err != nil {
+		InternalError(w, r, h.logger, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", SchemaContentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(schema)
+}
+
+func (h *SchemaHandler) schemaNotFound(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, http.StatusNotFound)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   "Schema not found",
+		Timestamp: time.Now(),
+	})
+}
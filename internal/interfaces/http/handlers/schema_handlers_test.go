@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/jsonschema"
+	"clean-architecture/pkg/logger"
+)
+
+func testSchemas() fstest.MapFS {
+	return fstest.MapFS{
+		"user.json":                {Data: []byte(`{"title": "UserDTO"}`)},
+		"create_user_request.json": {Data: []byte(`{"title": "CreateUserRequest"}`)},
+	}
+}
+
+func TestSchemaHandler_ListSchemas(t *testing.T) {
+	handler := NewSchemaHandler(testSchemas(), logger.New())
+
+	w := httptest.NewRecorder()
+	handler.ListSchemas(w, httptest.NewRequest(http.MethodGet, "/api/v1/schemas", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data []SchemaLinkDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []SchemaLinkDTO{
+		{Name: "create_user_request", URL: "/api/v1/schemas/create_user_request"},
+		{Name: "user", URL: "/api/v1/schemas/user"},
+	}, body.Data)
+}
+
+func TestSchemaHandler_GetSchema(t *testing.T) {
+	handler := NewSchemaHandler(testSchemas(), logger.New())
+
+	tests := []struct {
+		name           string
+		schema         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "published schema",
+			schema:         "user",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"title": "UserDTO"}`,
+		},
+		{
+			name:           "unknown schema",
+			schema:         "account",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "invalid name",
+			schema:         "../user",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/schemas/"+tt.schema, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("name", tt.schema)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			w := httptest.NewRecorder()
+			handler.GetSchema(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, SchemaContentType, w.Header().Get("Content-Type"))
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSchemaTypes_Derivable(t *testing.T) {
+	names := make(map[string]bool, len(SchemaTypes))
+	for _, schemaType := range SchemaTypes {
+		assert.Regexp(t, schemaName, schemaType.Name)
+		assert.False(t, names[schemaType.Name], "duplicate schema %s", schemaType.Name)
+		names[schemaType.Name] = true
+
+		_, err := jsonschema.For(schemaType.Value)
+		assert.NoError(t, err, schemaType.Name)
+	}
+}
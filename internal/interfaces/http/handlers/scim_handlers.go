@@ -0,0 +1,595 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/scim"
+)
+
+// SCIMUserPath is where SCIM user resources are served
+const SCIMUserPath = "/scim/v2/Users"
+
+// SCIMHandler provisions users over SCIM 2.0, so identity providers such as
+// Okta and Azure AD can create, update and deactivate accounts. A SCIM
+// userName is the user's email and its displayName the user's name; other
+// attributes are accepted and ignored.
+type SCIMHandler struct {
+	userUseCase usecase.UserUseCaseInterface
+	token       string
+	maxResults  int
+	logger      logger.Logger
+}
+
+// NewSCIMHandler creates a new SCIM handler accepting requests that carry
+// token as their bearer token
+func NewSCIMHandler(userUseCase usecase.UserUseCaseInterface, token string, logger logger.Logger) *SCIMHandler {
+	return &SCIMHandler{
+		userUseCase: userUseCase,
+		token:       token,
+		maxResults:  100,
+		logger:      logger,
+	}
+}
+
+// WithMaxResults replaces the largest page a list request returns
+func (h *SCIMHandler) WithMaxResults(maxResults int) *SCIMHandler {
+	h.maxResults = maxResults
+	return h
+}
+
+// SCIMName is the components of a user's name
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMEmail is an email address of a user
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMUser is the SCIM representation of a user
+type SCIMUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *SCIMName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []SCIMEmail `json:"emails,omitempty"`
+	// Active is false once a user was deactivated, which deletes it
+	Active *bool      `json:"active,omitempty"`
+	Meta   *scim.Meta `json:"meta,omitempty"`
+}
+
+// displayName returns the user's name, preferring displayName over the
+// components of name and falling back to userName
+func (u *SCIMUser) displayName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name != nil {
+		if u.Name.Formatted != "" {
+			return u.Name.Formatted
+		}
+		if name := strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName); name != "" {
+			return name
+		}
+	}
+	return u.UserName
+}
+
+func (u *SCIMUser) inactive() bool {
+	return u.Active != nil && !*u.Active
+}
+
+// scimUser returns the SCIM representation of user
+func scimUser(user *entities.User, active bool) SCIMUser {
+	return SCIMUser{
+		Schemas:     []string{scim.UserSchema},
+		ID:          user.ID,
+		UserName:    user.Email,
+		Name:        &SCIMName{Formatted: user.Name},
+		DisplayName: user.Name,
+		Emails:      []SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      user.CreatedAt.UTC().Format(time.RFC3339),
+			LastModified: user.UpdatedAt.UTC().Format(time.RFC3339),
+			Location:     SCIMUserPath + "/" + user.ID,
+		},
+	}
+}
+
+// Authenticate rejects requests without the configured bearer token
+func (h *SCIMHandler) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="SCIM"`)
+			h.respond(w, http.StatusUnauthorized, scim.NewError(http.StatusUnauthorized, "", "A valid bearer token is required"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ListUsers godoc
+// @Summary      List users over SCIM
+// @Description  List users as a SCIM ListResponse. Filters may combine comparisons of userName, emails.value, displayName, name.formatted, id, meta.created and meta.lastModified with and.
+// @Tags         scim
+// @Produce      application/scim+json
+// @Param        filter      query     string  false  "SCIM filter, e.g. userName eq \"bjensen@example.com\""
+// @Param        startIndex  query     int     false  "1-based index of the first result"
+// @Param        count       query     int     false  "Maximum number of results"
+// @Success      200         {object}  scim.ListResponse
+// @Failure      400         {object}  scim.Error
+// @Failure      401         {object}  scim.Error
+// @Router       /scim/v2/Users [get]
+func (h *SCIMHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, err := scim.ParseFilter(query.Get("filter"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	conditions, err := scimConditions(filter)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	startIndex := 1
+	if i, err := strconv.Atoi(query.Get("startIndex")); err == nil && i > 1 {
+		startIndex = i
+	}
+	count := h.maxResults
+	if c, err := strconv.Atoi(query.Get("count")); err == nil && c >= 0 && c < count {
+		count = c
+	}
+
+	spec := repositories.Specification{Conditions: conditions}
+	total, err := h.userUseCase.CountUsers(r.Context(), spec)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	resources := []interface{}{}
+	if count > 0 && int64(startIndex) <= total {
+		spec.Sort = []repositories.SortOrder{{Field: "created_at"}, {Field: "id"}}
+		spec.Limit = count
+		spec.Offset = startIndex - 1
+		users, err := h.userUseCase.SearchUsers(r.Context(), spec)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		for _, user := range users {
+			resources = append(resources, scimUser(user, true))
+		}
+	}
+
+	h.respond(w, http.StatusOK, scim.NewListResponse(resources, total, startIndex))
+}
+
+// CreateUser godoc
+// @Summary      Provision a user over SCIM
+// @Description  Create a user from a SCIM User resource. Provisioning the userName of a deactivated user reactivates it.
+// @Tags         scim
+// @Accept       application/scim+json
+// @Produce      application/scim+json
+// @Param        user  body      SCIMUser  true  "SCIM user"
+// @Success      201   {object}  SCIMUser
+// @Failure      400   {object}  scim.Error
+// @Failure      401   {object}  scim.Error
+// @Failure      409   {object}  scim.Error
+// @Router       /scim/v2/Users [post]
+func (h *SCIMHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req SCIMUser
+	if err := decodeJSON(r, &req); err != nil {
+		h.writeError(w, r, scim.BadRequest(scim.InvalidSyntax, "%s", bodyErrorMessage(err)))
+		return
+	}
+	if req.inactive() {
+		h.writeError(w, r, scim.BadRequest(scim.InvalidValue, "users cannot be provisioned inactive"))
+		return
+	}
+
+	user, err := h.userUseCase.CreateUser(r.Context(), req.UserName, req.displayName())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	setConsistencyToken(w, repositories.UserResource, user.ID)
+
+	w.Header().Set("Location", SCIMUserPath+"/"+user.ID)
+	h.respond(w, http.StatusCreated, scimUser(user, true))
+}
+
+// GetUser godoc
+// @Summary      Get a user over SCIM
+// @Tags         scim
+// @Produce      application/scim+json
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  SCIMUser
+// @Failure      401  {object}  scim.Error
+// @Failure      404  {object}  scim.Error
+// @Router       /scim/v2/Users/{id} [get]
+func (h *SCIMHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	user, err := h.userUseCase.GetUserByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	h.respond(w, http.StatusOK, scimUser(user, true))
+}
+
+// ReplaceUser godoc
+// @Summary      Replace a user over SCIM
+// @Description  Replace a user's userName and name. Setting active to false deactivates the user, which deletes it.
+// @Tags         scim
+// @Accept       application/scim+json
+// @Produce      application/scim+json
+// @Param        id    path      string    true  "User ID"
+// @Param        user  body      SCIMUser  true  "SCIM user"
+// @Success      200   {object}  SCIMUser
+// @Failure      400   {object}  scim.Error
+// @Failure      401   {object}  scim.Error
+// @Failure      404   {object}  scim.Error
+// @Router       /scim/v2/Users/{id} [put]
+func (h *SCIMHandler) ReplaceUser(w http.ResponseWriter, r *http.Request) {
+	var req SCIMUser
+	if err := decodeJSON(r, &req); err != nil {
+		h.writeError(w, r, scim.BadRequest(scim.InvalidSyntax, "%s", bodyErrorMessage(err)))
+		return
+	}
+	if req.UserName == "" {
+		h.writeError(w, r, scim.BadRequest(scim.InvalidValue, "userName is required"))
+		return
+	}
+
+	h.save(w, r, chi.URLParam(r, "id"), &req)
+}
+
+// PatchUser godoc
+// @Summary      Update a user over SCIM
+// @Description  Apply SCIM PATCH operations to a user's userName, name, displayName and active attributes. Setting active to false deactivates the user, which deletes it.
+// @Tags         scim
+// @Accept       application/scim+json
+// @Produce      application/scim+json
+// @Param        id     path      string             true  "User ID"
+// @Param        patch  body      scim.PatchRequest  true  "PATCH operations"
+// @Success      200    {object}  SCIMUser
+// @Failure      400    {object}  scim.Error
+// @Failure      401    {object}  scim.Error
+// @Failure      404    {object}  scim.Error
+// @Router       /scim/v2/Users/{id} [patch]
+func (h *SCIMHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	var req scim.PatchRequest
+	if err := decodeJSON(r, &req); err != nil {
+		h.writeError(w, r, scim.BadRequest(scim.InvalidSyntax, "%s", bodyErrorMessage(err)))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	user, err := h.userUseCase.GetUserByID(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	patched := scimUser(user, true)
+	for _, op := range req.Operations {
+		if err := applySCIMPatch(&patched, op); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+	}
+
+	h.save(w, r, id, &patched)
+}
+
+// DeleteUser godoc
+// @Summary      Deprovision a user over SCIM
+// @Tags         scim
+// @Param        id   path  string  true  "User ID"
+// @Success      204
+// @Failure      401  {object}  scim.Error
+// @Failure      404  {object}  scim.Error
+// @Router       /scim/v2/Users/{id} [delete]
+func (h *SCIMHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := h.userUseCase.DeleteUser(r.Context(), id); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	setConsistencyToken(w, repositories.UserResource, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ServiceProviderConfig godoc
+// @Summary      Get the SCIM service provider configuration
+// @Tags         scim
+// @Produce      application/scim+json
+// @Success      200  {object}  object
+// @Failure      401  {object}  scim.Error
+// @Router       /scim/v2/ServiceProviderConfig [get]
+func (h *SCIMHandler) ServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	unsupported := map[string]bool{"supported": false}
+	h.respond(w, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{scim.ServiceProviderConfigSchema},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": h.maxResults},
+		"changePassword": unsupported,
+		"sort":           unsupported,
+		"etag":           unsupported,
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "The token configured in SCIM_TOKEN",
+			"primary":     true,
+		}},
+		"meta": scim.Meta{ResourceType: "ServiceProviderConfig", Location: "/scim/v2/ServiceProviderConfig"},
+	})
+}
+
+// save applies a replaced or patched representation to the user with id.
+// Inactive users are deleted.
+func (h *SCIMHandler) save(w http.ResponseWriter, r *http.Request, id string, req *SCIMUser) {
+	if req.inactive() {
+		user, err := h.userUseCase.GetUserByID(r.Context(), id)
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		if err := h.userUseCase.DeleteUser(r.Context(), id); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		setConsistencyToken(w, repositories.UserResource, id)
+		h.respond(w, http.StatusOK, scimUser(user, false))
+		return
+	}
+
+	user, err := h.userUseCase.UpdateUser(r.Context(), id, req.displayName(), req.UserName)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	setConsistencyToken(w, repositories.UserResource, user.ID)
+	h.respond(w, http.StatusOK, scimUser(user, true))
+}
+
+// scimAttribute returns the lowercased attribute path without the core user
+// schema prefix
+func scimAttribute(path string) string {
+	path = strings.ToLower(path)
+	return strings.TrimPrefix(path, strings.ToLower(scim.UserSchema)+":")
+}
+
+// scimColumns maps the SCIM attributes filters may compare to user fields
+var scimColumns = map[string]string{
+	"id":                "id",
+	"username":          "email",
+	"emails":            "email",
+	"emails.value":      "email",
+	"displayname":       "name",
+	"name.formatted":    "name",
+	"meta.created":      "created_at",
+	"meta.lastmodified": "updated_at",
+}
+
+var scimOperators = map[string]repositories.Operator{
+	scim.Eq: repositories.OpEq,
+	scim.Ne: repositories.OpNe,
+	scim.Co: repositories.OpContains,
+	scim.Sw: repositories.OpStartsWith,
+	scim.Gt: repositories.OpGt,
+	scim.Ge: repositories.OpGte,
+	scim.Lt: repositories.OpLt,
+	scim.Le: repositories.OpLte,
+}
+
+// scimConditions converts a SCIM filter into specification conditions
+func scimConditions(filter scim.Filter) ([]repositories.Condition, error) {
+	conditions := make([]repositories.Condition, 0, len(filter))
+	for _, comparison := range filter {
+		field, ok := scimColumns[scimAttribute(comparison.Attribute)]
+		if !ok {
+			return nil, scim.BadRequest(scim.InvalidFilter, "filtering on %s is not supported", comparison.Attribute)
+		}
+		operator, ok := scimOperators[comparison.Operator]
+		if !ok {
+			return nil, scim.BadRequest(scim.InvalidFilter, "the %s operator is not supported", comparison.Operator)
+		}
+		value, ok := comparison.Value.(string)
+		if !ok {
+			return nil, scim.BadRequest(scim.InvalidFilter, "%s must be compared with a string", comparison.Attribute)
+		}
+
+		condition := repositories.Condition{Field: field, Operator: operator, Value: value}
+		if field == "created_at" || field == "updated_at" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, scim.BadRequest(scim.InvalidFilter, "%s must be compared with an RFC 3339 date-time", comparison.Attribute)
+			}
+			condition.Value = t
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// applySCIMPatch applies op to user. Attributes the service does not store
+// are ignored.
+func applySCIMPatch(user *SCIMUser, op scim.PatchOperation) error {
+	if op.Path == "" {
+		if op.Op == scim.OpRemove {
+			return scim.BadRequest(scim.NoTarget, "remove operations need a path")
+		}
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attributes); err != nil {
+			return scim.BadRequest(scim.InvalidValue, "operations without a path need an object value")
+		}
+		for path, value := range attributes {
+			if err := applySCIMPatch(user, scim.PatchOperation{Op: op.Op, Path: path, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	attribute := scimAttribute(op.Path)
+	if op.Op == scim.OpRemove {
+		switch attribute {
+		case "username", "active":
+			return scim.BadRequest(scim.Mutability, "%s cannot be removed", op.Path)
+		case "displayname", "name", "name.formatted", "name.givenname", "name.familyname":
+			user.DisplayName = ""
+			user.Name = nil
+		}
+		return nil
+	}
+
+	switch attribute {
+	case "username":
+		return decodeSCIMValue(op, &user.UserName)
+	case "displayname":
+		return decodeSCIMValue(op, &user.DisplayName)
+	case "name":
+		var name SCIMName
+		if err := decodeSCIMValue(op, &name); err != nil {
+			return err
+		}
+		user.DisplayName = ""
+		user.Name = &name
+	case "name.formatted", "name.givenname", "name.familyname":
+		var value string
+		if err := decodeSCIMValue(op, &value); err != nil {
+			return err
+		}
+		user.DisplayName = ""
+		user.Name = patchSCIMName(user.Name, attribute, value)
+	case "active":
+		active, err := decodeSCIMBool(op)
+		if err != nil {
+			return err
+		}
+		user.Active = &active
+	}
+	return nil
+}
+
+// patchSCIMName sets a component of name. The formatted name is derived
+// from the components unless it is the one set.
+func patchSCIMName(name *SCIMName, attribute, value string) *SCIMName {
+	patched := SCIMName{}
+	if name != nil {
+		patched = *name
+	}
+	switch attribute {
+	case "name.formatted":
+		patched.Formatted = value
+		return &patched
+	case "name.givenname":
+		patched.GivenName = value
+	case "name.familyname":
+		patched.FamilyName = value
+	}
+	patched.Formatted = ""
+	return &patched
+}
+
+// decodeSCIMValue decodes the value of op into v. Values are raw JSON that
+// decodeJSON did not sanitize, so they are sanitized here.
+func decodeSCIMValue(op scim.PatchOperation, v interface{}) error {
+	if err := json.Unmarshal(op.Value, v); err != nil {
+		return scim.BadRequest(scim.InvalidValue, "invalid value for %s", op.Path)
+	}
+	sanitizeValue(reflect.ValueOf(v))
+	return nil
+}
+
+// decodeSCIMBool decodes a boolean value. Azure AD sends booleans as the
+// strings "True" and "False".
+func decodeSCIMBool(op scim.PatchOperation) (bool, error) {
+	var value interface{}
+	if err := json.Unmarshal(op.Value, &value); err == nil {
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+	}
+	return false, scim.BadRequest(scim.InvalidValue, "%s must be true or false", op.Path)
+}
+
+// writeError writes err as a SCIM error response. Unexpected errors are
+// logged with an error ID and only the ID is returned.
+func (h *SCIMHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var scimErr *scim.Error
+	switch {
+	case errors.As(err, &scimErr):
+		status, _ := strconv.Atoi(scimErr.Status)
+		h.respond(w, status, scimErr)
+	case errors.Is(err, repositories.ErrUserNotFound):
+		h.respond(w, http.StatusNotFound, scim.NewError(http.StatusNotFound, "", "User not found"))
+	case errors.Is(err, repositories.ErrUserAlreadyExists):
+		h.respond(w, http.StatusConflict, scim.NewError(http.StatusConflict, scim.Uniqueness, "A user with this userName already exists"))
+	case errors.Is(err, usecase.ErrQuotaExceeded):
+		h.respond(w, http.StatusForbidden, scim.NewError(http.StatusForbidden, "", "The user quota is reached"))
+	case errors.Is(err, usecase.ErrEmailRequired):
+		h.respond(w, http.StatusBadRequest, scim.BadRequest(scim.InvalidValue, "userName is required"))
+	case errors.Is(err, usecase.ErrNameRequired):
+		h.respond(w, http.StatusBadRequest, scim.BadRequest(scim.InvalidValue, "a name is required"))
+	default:
+		errorID := newErrorID()
+		h.logger.WithFields(map[string]interface{}{
+			"error_id":   errorID,
+			"error":      err.Error(),
+			"request_id": middleware.GetReqID(r.Context()),
+			"method":     r.Method,
+			"path":       r.URL.Path,
+		}).Error("Unexpected error handling SCIM request")
+		h.respond(w, http.StatusInternalServerError, scim.NewError(http.StatusInternalServerError, "", "An internal error occurred (error ID "+errorID+")"))
+	}
+}
+
+// respond writes v as a SCIM response with the given status
+func (h *SCIMHandler) respond(w http.ResponseWriter, status int, v interface{}) {
+	b := getEncodeBuffer()
+	defer putEncodeBuffer(b)
+
+	if err := b.enc.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", scim.MediaType)
+	w.WriteHeader(status)
+	w.Write(b.buf.Bytes()) //nolint:errcheck
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/scim"
+)
+
+const testSCIMToken = "scim-secret"
+
+func scimRouter(h *SCIMHandler) http.Handler {
+	r := chi.NewRouter()
+	r.Route("/scim/v2", func(r chi.Router) {
+		r.Use(h.Authenticate)
+		r.Get("/ServiceProviderConfig", h.ServiceProviderConfig)
+		r.Get("/Users", h.ListUsers)
+		r.Post("/Users", h.CreateUser)
+		r.Get("/Users/{id}", h.GetUser)
+		r.Put("/Users/{id}", h.ReplaceUser)
+		r.Patch("/Users/{id}", h.PatchUser)
+		r.Delete("/Users/{id}", h.DeleteUser)
+	})
+	return r
+}
+
+func scimRequest(t *testing.T, handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+testSCIMToken)
+	req.Header.Set("Content-Type", scim.MediaType)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func scimTestUser() *entities.User {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &entities.User{ID: "user_1", Email: "bjensen@example.com", Name: "Barbara Jensen", CreatedAt: created, UpdatedAt: created}
+}
+
+func TestSCIMHandler_Authenticate(t *testing.T) {
+	handler := scimRouter(NewSCIMHandler(new(MockUserUseCase), testSCIMToken, logger.New()))
+
+	for _, authorization := range []string{"", "Bearer wrong", "Basic " + testSCIMToken, testSCIMToken} {
+		req := httptest.NewRequest(http.MethodGet, "/scim/v2/ServiceProviderConfig", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, authorization)
+		assert.Equal(t, scim.MediaType, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+	}
+
+	w := scimRequest(t, handler, http.MethodGet, "/scim/v2/ServiceProviderConfig", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSCIMHandler_ListUsers(t *testing.T) {
+	mockUseCase := new(MockUserUseCase)
+	handler := scimRouter(NewSCIMHandler(mockUseCase, testSCIMToken, logger.New()).WithMaxResults(50))
+
+	conditions := []repositories.Condition{{Field: "email", Operator: repositories.OpEq, Value: "bjensen@example.com"}}
+	mockUseCase.On("CountUsers", mock.Anything, repositories.Specification{Conditions: conditions}).Return(int64(1), nil)
+	mockUseCase.On("SearchUsers", mock.Anything, repositories.Specification{
+		Conditions: conditions,
+		Sort:       []repositories.SortOrder{{Field: "created_at"}, {Field: "id"}},
+		Limit:      50,
+	}).Return([]*entities.User{scimTestUser()}, nil)
+
+	w := scimRequest(t, handler, http.MethodGet, `/scim/v2/Users?filter=userName+eq+"bjensen@example.com"&count=500`, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:ListResponse"],
+		"totalResults": 1,
+		"startIndex": 1,
+		"itemsPerPage": 1,
+		"Resources": [{
+			"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+			"id": "user_1",
+			"userName": "bjensen@example.com",
+			"name": {"formatted": "Barbara Jensen"},
+			"displayName": "Barbara Jensen",
+			"emails": [{"value": "bjensen@example.com", "type": "work", "primary": true}],
+			"active": true,
+			"meta": {
+				"resourceType": "User",
+				"created": "2024-01-01T00:00:00Z",
+				"lastModified": "2024-01-01T00:00:00Z",
+				"location": "/scim/v2/Users/user_1"
+			}
+		}]
+	}`, w.Body.String())
+}
+
+func TestSCIMHandler_ListUsers_InvalidFilter(t *testing.T) {
+	handler := scimRouter(NewSCIMHandler(new(MockUserUseCase), testSCIMToken, logger.New()))
+
+	for _, filter := range []string{`userName+eq+bjensen`, `title+eq+"Tour+Guide"`, `userName+ew+"example.com"`, `meta.created+gt+"yesterday"`} {
+		w := scimRequest(t, handler, http.MethodGet, "/scim/v2/Users?filter="+filter, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, filter)
+
+		var body scim.Error
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, scim.InvalidFilter, body.ScimType, filter)
+	}
+}
+
+func TestSCIMHandler_CreateUser(t *testing.T) {
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("CreateUser", mock.Anything, "bjensen@example.com", "Barbara Jensen").Return(scimTestUser(), nil)
+	mockUseCase.On("CreateUser", mock.Anything, "taken@example.com", "taken@example.com").Return(nil, repositories.ErrUserAlreadyExists)
+	mockUseCase.On("CreateUser", mock.Anything, "full@example.com", "full@example.com").Return(nil, &usecase.QuotaExceededError{Resource: "users", Limit: 100})
+	handler := scimRouter(NewSCIMHandler(mockUseCase, testSCIMToken, logger.New()))
+
+	w := scimRequest(t, handler, http.MethodPost, "/scim/v2/Users", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "bjensen@example.com",
+		"name": {"givenName": "Barbara", "familyName": "Jensen"},
+		"externalId": "00u1",
+		"active": true
+	}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/scim/v2/Users/user_1", w.Header().Get("Location"))
+	var created SCIMUser
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "user_1", created.ID)
+
+	w = scimRequest(t, handler, http.MethodPost, "/scim/v2/Users", `{"userName": "taken@example.com"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var conflict scim.Error
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &conflict))
+	assert.Equal(t, "409", conflict.Status)
+	assert.Equal(t, scim.Uniqueness, conflict.ScimType)
+
+	w = scimRequest(t, handler, http.MethodPost, "/scim/v2/Users", `{"userName": "full@example.com"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestSCIMHandler_PatchUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		expect func(m *MockUserUseCase)
+		active bool
+	}{
+		{
+			name: "rename without a path",
+			body: `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [
+				{"op": "Replace", "value": {"displayName": "Babs Jensen", "externalId": "00u1"}}
+			]}`,
+			expect: func(m *MockUserUseCase) {
+				renamed := scimTestUser()
+				renamed.Name = "Babs Jensen"
+				m.On("UpdateUser", mock.Anything, "user_1", "Babs Jensen", "bjensen@example.com").Return(renamed, nil)
+			},
+			active: true,
+		},
+		{
+			name: "change name components",
+			body: `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [
+				{"op": "replace", "path": "name.givenName", "value": "Babs"}
+			]}`,
+			expect: func(m *MockUserUseCase) {
+				m.On("UpdateUser", mock.Anything, "user_1", "Babs", "bjensen@example.com").Return(scimTestUser(), nil)
+			},
+			active: true,
+		},
+		{
+			name: "deactivate",
+			body: `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [
+				{"op": "Replace", "path": "active", "value": "False"}
+			]}`,
+			expect: func(m *MockUserUseCase) {
+				m.On("DeleteUser", mock.Anything, "user_1").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUserUseCase)
+			mockUseCase.On("GetUserByID", mock.Anything, "user_1").Return(scimTestUser(), nil)
+			tt.expect(mockUseCase)
+			handler := scimRouter(NewSCIMHandler(mockUseCase, testSCIMToken, logger.New()))
+
+			w := scimRequest(t, handler, http.MethodPatch, "/scim/v2/Users/user_1", tt.body)
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var patched SCIMUser
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &patched))
+			require.NotNil(t, patched.Active)
+			assert.Equal(t, tt.active, *patched.Active)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSCIMHandler_PatchUser_Invalid(t *testing.T) {
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("GetUserByID", mock.Anything, "user_1").Return(scimTestUser(), nil)
+	handler := scimRouter(NewSCIMHandler(mockUseCase, testSCIMToken, logger.New()))
+
+	for _, body := range []string{
+		`{"Operations": [{"op": "replace", "path": "active", "value": false}]}`,
+		`{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "replace", "path": "active", "value": "maybe"}]}`,
+		`{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "remove", "path": "userName"}]}`,
+	} {
+		w := scimRequest(t, handler, http.MethodPatch, "/scim/v2/Users/user_1", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	mockUseCase.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockUseCase.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+}
+
+func TestSCIMHandler_GetAndDeleteUser(t *testing.T) {
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("GetUserByID", mock.Anything, "missing").Return(nil, repositories.ErrUserNotFound)
+	mockUseCase.On("DeleteUser", mock.Anything, "user_1").Return(nil)
+	handler := scimRouter(NewSCIMHandler(mockUseCase, testSCIMToken, logger.New()))
+
+	w := scimRequest(t, handler, http.MethodGet, "/scim/v2/Users/missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, scim.MediaType, w.Header().Get("Content-Type"))
+
+	w = scimRequest(t, handler, http.MethodDelete, "/scim/v2/Users/user_1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// ServiceAccountHandler handles the lifecycle of the service accounts of
+// organizations and of their API keys
+type ServiceAccountHandler struct {
+	accountUseCase usecase.ServiceAccountUseCaseInterface
+	defaults       APIDefaults
+	logger         logger.Logger
+}
+
+// NewServiceAccountHandler creates a new service account handler
+func NewServiceAccountHandler(accountUseCase usecase.ServiceAccountUseCaseInterface, logger logger.Logger) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		accountUseCase: accountUseCase,
+		defaults:       DefaultAPIDefaults(),
+		logger:         logger,
+	}
+}
+
+// WithDefaults replaces the page sizes service accounts are listed with
+func (h *ServiceAccountHandler) WithDefaults(defaults APIDefaults) *ServiceAccountHandler {
+	h.defaults = defaults
+	return h
+}
+
+// ServiceAccountDTO is the API representation of a service account
+type ServiceAccountDTO struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"organization_id"`
+	Name           string `json:"name"`
+	// Scopes are the most the account's keys may grant
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateServiceAccountRequest represents the request body for creating a
+// service account
+type CreateServiceAccountRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreateServiceAccount godoc
+// @Summary      Create a service account
+// @Description  Create a non-human user of the organization that other systems act as through its API keys. Its keys may grant at most the given scopes.
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                       true  "Organization ID"
+// @Param        account  body      CreateServiceAccountRequest  true  "Service account definition"
+// @Success      201      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Router       /api/v1/organizations/{id}/service-accounts [post]
+func (h *ServiceAccountHandler) CreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	var req CreateServiceAccountRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	account, err := h.accountUseCase.CreateServiceAccount(r.Context(), chi.URLParam(r, "id"), req.Name, req.Scopes)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	setConsistencyToken(w, repositories.UserResource, account.ID)
+	render.Status(r, http.StatusCreated)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Service account created successfully",
+		Data:      presentServiceAccount(account),
+		Timestamp: time.Now(),
+	})
+}
+
+// ListServiceAccounts godoc
+// @Summary      List service accounts
+// @Description  List the service accounts of an organization, oldest first
+// @Tags         organizations
+// @Produce      json
+// @Param        id      path      string  true   "Organization ID"
+// @Param        limit   query     int     false  "Maximum number of service accounts"
+// @Param        offset  query     int     false  "Number of service accounts to skip"
+// @Success      200     {object}  SuccessResponse
+// @Failure      404     {object}  ErrorResponse
+// @Router       /api/v1/organizations/{id}/service-accounts [get]
+func (h *ServiceAccountHandler) ListServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	limit, offset, warnings := pagination(r.URL.Query(), h.defaults.PageSize, h.defaults.MaxPageSize)
+
+	accounts, err := h.accountUseCase.ListServiceAccounts(r.Context(), chi.URLParam(r, "id"), limit, offset)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]ServiceAccountDTO, 0, len(accounts))
+	for _, account := range accounts {
+		dtos = append(dtos, presentServiceAccount(account))
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Service accounts retrieved successfully",
+		Data:      dtos,
+		Warnings:  warnings,
+		Meta:      listMeta(limit, offset, len(dtos)),
+		Timestamp: time.Now(),
+	})
+}
+
+// GetServiceAccount godoc
+// @Summary      Get a service account
+// @Tags         organizations
+// @Produce      json
+// @Param        id          path      string  true  "Organization ID"
+// @Param        account_id  path      string  true  "Service account ID"
+// @Success      200         {object}  SuccessResponse
+// @Failure      404         {object}  ErrorResponse
+// @Router       /api/v1/organizations/{id}/service-accounts/{account_id} [get]
+func (h *ServiceAccountHandler) GetServiceAccount(w http.ResponseWriter, r *http.Request) {
+	account, err := h.accountUseCase.GetServiceAccount(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "account_id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Service account retrieved successfully",
+		Data:      presentServiceAccount(account),
+		Timestamp: time.Now(),
+	})
+}
+
+// DeleteServiceAccount godoc
+// @Summary      Delete a service account
+// @Description  Revoke the keys of a service account and delete it
+// @Tags         organizations
+// @Produce      json
+// @Param        id          path      string  true  "Organization ID"
+// @Param        account_id  path      string  true  "Service account ID"
+// @Success      200         {object}  SuccessResponse
+// @Failure      404         {object}  ErrorResponse
+// @Router       /api/v1/organizations/{id}/service-accounts/{account_id} [delete]
+func (h *ServiceAccountHandler) DeleteServiceAccount(w http.ResponseWriter, r *http.Request) {
+	accountID := chi.URLParam(r, "account_id")
+	if err := h.accountUseCase.DeleteServiceAccount(r.Context(), chi.URLParam(r, "id"), accountID); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	setConsistencyToken(w, repositories.UserResource, accountID)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Service account deleted successfully",
+		Timestamp: time.Now(),
+	})
+}
+
+// CreateServiceAccountKey godoc
+// @Summary      Create a service account key
+// @Description  Create a key requests authenticate with as the service account. Without scopes it grants the account's. The key is only returned in this response.
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        id          path      string               true  "Organization ID"
+// @Param        account_id  path      string               true  "Service account ID"
+// @Param        key         body      CreateAPIKeyRequest  true  "API key definition"
+// @Success      201         {object}  SuccessResponse
+// @Failure      400         {object}  ErrorResponse
+// @Failure      404         {object}  ErrorResponse
+// @Failure      422         {object}  ErrorResponse
+// @Router       /api/v1/organizations/{id}/service-accounts/{account_id}/keys [post]
+func (h *ServiceAccountHandler) CreateServiceAccountKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	key, plaintext, err := h.accountUseCase.CreateKey(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "account_id"), request.Current(r.Context()).UserID, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "API key created successfully; store the key now, it is not shown again",
+		Data:      CreatedAPIKeyDTO{APIKeyDTO: presentAPIKey(key), Key: plaintext},
+		Timestamp: time.Now(),
+	})
+}
+
+// ListServiceAccountKeys godoc
+// @Summary      List service account keys
+// @Description  List the keys of a service account, newest first, including revoked and expired ones
+// @Tags         organizations
+// @Produce      json
+// @Param        id          path      string  true  "Organization ID"
+// @Param        account_id  path      string  true  "Service account ID"
+// @Success      200         {object}  SuccessResponse
+// @Failure      404         {object}  ErrorResponse
+// @Router       /api/v1/organizations/{id}/service-accounts/{account_id}/keys [get]
+func (h *ServiceAccountHandler) ListServiceAccountKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.accountUseCase.ListKeys(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "account_id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]APIKeyDTO, 0, len(keys))
+	for _, key := range keys {
+		dtos = append(dtos, presentAPIKey(key))
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "API keys retrieved successfully",
+		Data:      dtos,
+		Timestamp: time.Now(),
+	})
+}
+
+// RevokeServiceAccountKey godoc
+// @Summary      Revoke a service account key
+// @Tags         organizations
+// @Produce      json
+// @Param        id          path      string  true  "Organization ID"
+// @Param        account_id  path      string  true  "Service account ID"
+// @Param        key_id      path      string  true  "API key ID"
+// @Success      200         {object}  SuccessResponse
+// @Failure      404         {object}  ErrorResponse
+// @Router       /api/v1/organizations/{id}/service-accounts/{account_id}/keys/{key_id} [delete]
+func (h *ServiceAccountHandler) RevokeServiceAccountKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.accountUseCase.RevokeKey(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "account_id"), chi.URLParam(r, "key_id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "API key revoked successfully",
+		Data:      presentAPIKey(key),
+		Timestamp: time.Now(),
+	})
+}
+
+func presentServiceAccount(account *entities.User) ServiceAccountDTO {
+	scopes := account.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return ServiceAccountDTO{
+		ID:             account.ID,
+		OrganizationID: account.OrganizationID,
+		Name:           account.Name,
+		Scopes:         scopes,
+		CreatedAt:      account.CreatedAt,
+		UpdatedAt:      account.UpdatedAt,
+	}
+}
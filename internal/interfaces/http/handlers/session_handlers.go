@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/usecase"
+)
+
+// SessionCookie configures the cookie that carries a server-side session
+type SessionCookie struct {
+	Name   string
+	Domain string
+	Secure bool
+}
+
+// SessionResponseDTO describes the session a cookie was set for
+type SessionResponseDTO struct {
+	ExpiresAt time.Time `json:"expires_at"`
+	User      UserDTO   `json:"user"`
+}
+
+// WithSessions enables logging in to server-side sessions carried by cookie
+func (h *AuthHandler) WithSessions(cookie SessionCookie) *AuthHandler {
+	h.sessionCookie = &cookie
+	return h
+}
+
+// CreateSession godoc
+// @Summary      Start a session
+// @Description  Exchange a username and password for a server-side session. The session ID is set as an HttpOnly cookie, sent back on later requests instead of a bearer token.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      LoginRequest  true  "Credentials"
+// @Success      200      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      429      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Failure      503      {object}  ErrorResponse
+// @Router       /api/v1/auth/session [post]
+func (h *AuthHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	if h.sessionCookie == nil {
+		sessionsUnavailable(w, r)
+		return
+	}
+	var req LoginRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if req.Username == "" || req.Password == "" {
+		unprocessable(w, r, "credentials_required", "Username and password are required")
+		return
+	}
+
+	session, err := h.authUseCase.StartSession(r.Context(), req.credentials(r))
+	if errors.Is(err, usecase.ErrSessionsUnavailable) {
+		sessionsUnavailable(w, r)
+		return
+	}
+	if err != nil {
+		h.loginError(w, r, err)
+		return
+	}
+
+	// The cookie outlives idle periods; the store ends sessions left unused
+	http.SetCookie(w, h.cookie(session.SessionID, session.ExpiresAt))
+	ctx := policy.WithSubject(r.Context(), policy.Subject{
+		ID:     session.User.ID,
+		Roles:  session.Claims.Roles,
+		Scopes: session.Claims.Scopes,
+	})
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, r, Response{
+		Status:  "success",
+		Message: "Logged in successfully",
+		Data: SessionResponseDTO{
+			ExpiresAt: session.ExpiresAt,
+			User:      h.presenter.Present(ctx, session.User),
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// DeleteSession godoc
+// @Summary      End a session
+// @Description  End the server-side session of the session cookie and clear the cookie. Ending a session that has already expired succeeds.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/auth/session [delete]
+func (h *AuthHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	if h.sessionCookie == nil {
+		sessionsUnavailable(w, r)
+		return
+	}
+	var id string
+	if cookie, err := r.Cookie(h.sessionCookie.Name); err == nil {
+		id = cookie.Value
+	}
+
+	err := h.authUseCase.EndSession(r.Context(), id)
+	if errors.Is(err, usecase.ErrSessionsUnavailable) {
+		sessionsUnavailable(w, r)
+		return
+	}
+	if err != nil {
+		InternalError(w, r, h.logger, err)
+		return
+	}
+
+	cleared := h.cookie("", time.Unix(0, 0))
+	cleared.MaxAge = -1
+	http.SetCookie(w, cleared)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Logged out successfully",
+		Timestamp: time.Now(),
+	})
+}
+
+// cookie builds the session cookie with value. SameSite=Strict keeps other
+// sites from sending it, as the API accepts credentialed CORS requests.
+func (h *AuthHandler) cookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     h.sessionCookie.Name,
+		Value:    value,
+		Path:     "/",
+		Domain:   h.sessionCookie.Domain,
+		Expires:  expires,
+		Secure:   h.sessionCookie.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
+// sessionsUnavailable answers session requests when sessions are disabled
+func sessionsUnavailable(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, http.StatusNotFound)
+	respondJSON(w, r, Response{
+		Status:    "error",
+		Message:   "Sessions are not enabled",
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+func sessionHandler(useCase *MockAuthUseCase) *AuthHandler {
+	return NewAuthHandler(useCase, NewUserPresenter(nil), logger.New()).
+		WithSessions(SessionCookie{Name: "session", Domain: "example.com", Secure: true})
+}
+
+func TestAuthHandler_CreateSession(t *testing.T) {
+	expiresAt := time.Now().Add(12 * time.Hour)
+	mockUseCase := new(MockAuthUseCase)
+	mockUseCase.On("StartSession", mock.Anything, usecase.Credentials{Username: "bjensen", Password: "hunter2", IP: "192.0.2.1"}).Return(&usecase.Session{
+		SessionID: "session_1",
+		ExpiresAt: expiresAt,
+		Claims:    auth.Claims{Subject: "user_1", Roles: []string{"user"}},
+		User:      &entities.User{ID: "user_1", Email: "bjensen@example.com", Name: "Barbara Jensen"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/auth/session", bytes.NewBufferString(`{"username":"bjensen","password":"hunter2"}`))
+	sessionHandler(mockUseCase).CreateSession(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "session", cookies[0].Name)
+	assert.Equal(t, "session_1", cookies[0].Value)
+	assert.Equal(t, "example.com", cookies[0].Domain)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure)
+	assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+	assert.WithinDuration(t, expiresAt, cookies[0].Expires, time.Second)
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Contains(t, body.Data, "user")
+	assert.NotContains(t, body.Data, "access_token", "the session ID is only sent as a cookie")
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestAuthHandler_CreateSession_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		err             error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "invalid credentials",
+			body:            `{"username":"bjensen","password":"wrong"}`,
+			err:             auth.ErrInvalidCredentials,
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "Invalid username or password",
+		},
+		{
+			name:            "sessions disabled",
+			body:            `{"username":"bjensen","password":"hunter2"}`,
+			err:             usecase.ErrSessionsUnavailable,
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "Sessions are not enabled",
+		},
+		{
+			name:            "missing password",
+			body:            `{"username":"bjensen"}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "Username and password are required",
+		},
+		{
+			name:           "malformed body",
+			body:           `{"username":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockAuthUseCase)
+			if tt.err != nil {
+				mockUseCase.On("StartSession", mock.Anything, mock.Anything).Return(nil, tt.err)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/auth/session", bytes.NewBufferString(tt.body))
+			sessionHandler(mockUseCase).CreateSession(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Empty(t, w.Result().Cookies())
+			if tt.expectedMessage != "" {
+				var response Response
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedMessage, response.Message)
+			}
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_CreateSession_Disabled(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/auth/session", bytes.NewBufferString(`{"username":"bjensen","password":"hunter2"}`))
+	NewAuthHandler(new(MockAuthUseCase), NewUserPresenter(nil), logger.New()).CreateSession(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAuthHandler_DeleteSession(t *testing.T) {
+	mockUseCase := new(MockAuthUseCase)
+	mockUseCase.On("EndSession", mock.Anything, "session_1").Return(nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/auth/session", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "session_1"})
+	sessionHandler(mockUseCase).DeleteSession(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "session", cookies[0].Name)
+	assert.Empty(t, cookies[0].Value)
+	assert.Less(t, cookies[0].MaxAge, 0)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestAuthHandler_DeleteSession_WithoutCookie(t *testing.T) {
+	mockUseCase := new(MockAuthUseCase)
+	mockUseCase.On("EndSession", mock.Anything, "").Return(nil)
+
+	w := httptest.NewRecorder()
+	sessionHandler(mockUseCase).DeleteSession(w, httptest.NewRequest("DELETE", "/auth/session", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestAuthHandler_DeleteSession_StoreFailure(t *testing.T) {
+	mockUseCase := new(MockAuthUseCase)
+	mockUseCase.On("EndSession", mock.Anything, "session_1").Return(errors.New("redis: connection refused"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/auth/session", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "session_1"})
+	sessionHandler(mockUseCase).DeleteSession(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Result().Cookies(), "the cookie is kept so logging out can be retried")
+}
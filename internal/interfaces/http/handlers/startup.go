@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"clean-architecture/pkg/startup"
+)
+
+// StartupHandler reports how long each component took to initialize when
+// the instance started and what it was built from
+type StartupHandler struct {
+	report *startup.Report
+}
+
+// NewStartupHandler creates a new startup report handler
+func NewStartupHandler(report *startup.Report) *StartupHandler {
+	return &StartupHandler{report: report}
+}
+
+// Report handles startup report requests
+func (h *StartupHandler) Report(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Startup report retrieved successfully",
+		Data:      h.report,
+		Timestamp: time.Now(),
+	})
+}
+
+// Graph handles requests for the component dependency graph, in the
+// Graphviz DOT language, e.g. for rendering with `dot -Tsvg`
+func (h *StartupHandler) Graph(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+	_ = h.report.WriteDOT(w)
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/startup"
+)
+
+func TestStartupHandler(t *testing.T) {
+	report := startup.NewReport()
+	report.Begin("database")
+	report.Begin("migrations", "database")
+	report.Finish()
+	handler := NewStartupHandler(report)
+
+	w := httptest.NewRecorder()
+	handler.Report(w, httptest.NewRequest("GET", "/startup", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Status string `json:"status"`
+		Data   struct {
+			Finished     bool                `json:"finished"`
+			CriticalPath []string            `json:"critical_path"`
+			Components   []startup.Component `json:"components"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "success", body.Status)
+	assert.True(t, body.Data.Finished)
+	assert.Equal(t, []string{"database", "migrations"}, body.Data.CriticalPath)
+	require.Len(t, body.Data.Components, 2)
+	assert.Equal(t, []string{"database"}, body.Data.Components[1].DependsOn)
+
+	w = httptest.NewRecorder()
+	handler.Graph(w, httptest.NewRequest("GET", "/startup/graph", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/vnd.graphviz; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"database" -> "migrations";`)
+}
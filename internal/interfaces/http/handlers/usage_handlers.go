@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+const (
+	// usageDayFormat is the form of the days of usage reports
+	usageDayFormat = "2006-01-02"
+	// defaultUsageDays is how many days usage reports span without from
+	defaultUsageDays = 30
+)
+
+// usageCSVHeader is the header row of CSV usage reports
+var usageCSVHeader = []string{"day", "key_id", "tenant_id", "method", "route", "requests", "bytes_in", "bytes_out"}
+
+// UsageHandler handles reporting the API usage of keys and tenants
+type UsageHandler struct {
+	usageUseCase usecase.UsageUseCaseInterface
+	logger       logger.Logger
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(usageUseCase usecase.UsageUseCaseInterface, logger logger.Logger) *UsageHandler {
+	return &UsageHandler{
+		usageUseCase: usageUseCase,
+		logger:       logger,
+	}
+}
+
+// UsageDTO is the API representation of a daily usage rollup
+type UsageDTO struct {
+	Day      string `json:"day"`
+	KeyID    string `json:"key_id,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
+	Method   string `json:"method"`
+	Route    string `json:"route"`
+	Requests int64  `json:"requests"`
+	BytesIn  int64  `json:"bytes_in"`
+	BytesOut int64  `json:"bytes_out"`
+}
+
+// GetUsage godoc
+// @Summary      Report API usage
+// @Description  Report the API calls of each day by key, tenant, method and route, with the request and response body bytes. Calls are flushed to the report every USAGE_FLUSH_INTERVAL.
+// @Tags         usage
+// @Produce      json
+// @Produce      text/csv
+// @Param        from       query     string  false  "First day, YYYY-MM-DD; defaults to 29 days before to"
+// @Param        to         query     string  false  "Last day, YYYY-MM-DD; defaults to today (UTC)"
+// @Param        key_id     query     string  false  "Only the calls made with this API key"
+// @Param        tenant_id  query     string  false  "Only the calls made for this organization"
+// @Param        format     query     string  false  "json (default) or csv"
+// @Success      200        {object}  SuccessResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      403        {object}  ErrorResponse
+// @Failure      422        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
+// @Router       /api/v1/usage [get]
+func (h *UsageHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		unprocessable(w, r, "invalid_format", "format must be json or csv")
+		return
+	}
+
+	to := entities.UsageDay(time.Now())
+	if value := query.Get("to"); value != "" {
+		day, err := time.Parse(usageDayFormat, value)
+		if err != nil {
+			unprocessable(w, r, "invalid_day", "to must be a day formatted as YYYY-MM-DD")
+			return
+		}
+		to = day
+	}
+	from := to.AddDate(0, 0, 1-defaultUsageDays)
+	if value := query.Get("from"); value != "" {
+		day, err := time.Parse(usageDayFormat, value)
+		if err != nil {
+			unprocessable(w, r, "invalid_day", "from must be a day formatted as YYYY-MM-DD")
+			return
+		}
+		from = day
+	}
+
+	records, err := h.usageUseCase.Report(r.Context(), repositories.UsageFilter{
+		From:     from,
+		To:       to,
+		KeyID:    query.Get("key_id"),
+		TenantID: query.Get("tenant_id"),
+	})
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	if format == "csv" {
+		h.writeUsageCSV(w, from, to, records)
+		return
+	}
+	dtos := make([]UsageDTO, 0, len(records))
+	for _, record := range records {
+		dtos = append(dtos, presentUsage(record))
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Usage retrieved successfully",
+		Data:      dtos,
+		Timestamp: time.Now(),
+	})
+}
+
+// writeUsageCSV writes records as a CSV attachment named after their days
+func (h *UsageHandler) writeUsageCSV(w http.ResponseWriter, from, to time.Time, records []*entities.UsageRecord) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q",
+		"usage-"+from.Format(usageDayFormat)+"-"+to.Format(usageDayFormat)+".csv"))
+
+	out := csv.NewWriter(w)
+	out.Write(usageCSVHeader)
+	for _, record := range records {
+		out.Write([]string{
+			record.Day.Format(usageDayFormat),
+			record.KeyID,
+			record.TenantID,
+			record.Method,
+			record.Route,
+			strconv.FormatInt(record.Requests, 10),
+			strconv.FormatInt(record.BytesIn, 10),
+			strconv.FormatInt(record.BytesOut, 10),
+		})
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		h.logger.WithField("error", err.Error()).Warn("Failed to write usage report")
+	}
+}
+
+func presentUsage(record *entities.UsageRecord) UsageDTO {
+	return UsageDTO{
+		Day:      record.Day.Format(usageDayFormat),
+		KeyID:    record.KeyID,
+		TenantID: record.TenantID,
+		Method:   record.Method,
+		Route:    record.Route,
+		Requests: record.Requests,
+		BytesIn:  record.BytesIn,
+		BytesOut: record.BytesOut,
+	}
+}
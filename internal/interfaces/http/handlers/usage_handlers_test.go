@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockUsageUseCase is a mock implementation of UsageUseCaseInterface
+type MockUsageUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUsageUseCase) Report(ctx context.Context, filter repositories.UsageFilter) ([]*entities.UsageRecord, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.UsageRecord), args.Error(1)
+}
+
+func usageRecords() []*entities.UsageRecord {
+	return []*entities.UsageRecord{{
+		Day:      time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		KeyID:    "key_1",
+		TenantID: "org_1",
+		Method:   "GET",
+		Route:    "/api/v1/users/{id}",
+		Requests: 12,
+		BytesIn:  0,
+		BytesOut: 4096,
+	}}
+}
+
+func TestUsageHandler_GetUsage(t *testing.T) {
+	mockUseCase := new(MockUsageUseCase)
+	filter := repositories.UsageFilter{
+		From:  time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		To:    time.Date(2026, 10, 7, 0, 0, 0, 0, time.UTC),
+		KeyID: "key_1",
+	}
+	mockUseCase.On("Report", mock.Anything, filter).Return(usageRecords(), nil)
+
+	w := httptest.NewRecorder()
+	NewUsageHandler(mockUseCase, logger.New()).GetUsage(w, httptest.NewRequest("GET", "/usage?from=2026-10-01&to=2026-10-07&key_id=key_1", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, map[string]interface{}{
+		"day": "2026-10-01", "key_id": "key_1", "tenant_id": "org_1", "method": "GET",
+		"route": "/api/v1/users/{id}", "requests": 12.0, "bytes_in": 0.0, "bytes_out": 4096.0,
+	}, response.Data[0])
+}
+
+func TestUsageHandler_GetUsage_DefaultsToLast30Days(t *testing.T) {
+	mockUseCase := new(MockUsageUseCase)
+	today := entities.UsageDay(time.Now())
+	mockUseCase.On("Report", mock.Anything, repositories.UsageFilter{From: today.AddDate(0, 0, -29), To: today}).Return([]*entities.UsageRecord{}, nil)
+
+	w := httptest.NewRecorder()
+	NewUsageHandler(mockUseCase, logger.New()).GetUsage(w, httptest.NewRequest("GET", "/usage", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestUsageHandler_GetUsage_CSV(t *testing.T) {
+	mockUseCase := new(MockUsageUseCase)
+	mockUseCase.On("Report", mock.Anything, mock.Anything).Return(usageRecords(), nil)
+
+	w := httptest.NewRecorder()
+	NewUsageHandler(mockUseCase, logger.New()).GetUsage(w, httptest.NewRequest("GET", "/usage?from=2026-10-01&to=2026-10-07&format=csv", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="usage-2026-10-01-2026-10-07.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "day,key_id,tenant_id,method,route,requests,bytes_in,bytes_out\n"+
+		"2026-10-01,key_1,org_1,GET,/api/v1/users/{id},12,0,4096\n", w.Body.String())
+}
+
+func TestUsageHandler_GetUsage_Invalid(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		expectedBody string
+	}{
+		{"format", "?format=xml", "format must be json or csv"},
+		{"from", "?from=10/01/2026", "from must be a day formatted as YYYY-MM-DD"},
+		{"to", "?to=yesterday", "to must be a day formatted as YYYY-MM-DD"},
+		{"range", "?from=2026-10-07&to=2026-10-01", usecase.ErrInvalidUsageRange.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUsageUseCase)
+			mockUseCase.On("Report", mock.Anything, mock.Anything).Return(nil, usecase.ErrInvalidUsageRange)
+
+			w := httptest.NewRecorder()
+			NewUsageHandler(mockUseCase, logger.New()).GetUsage(w, httptest.NewRequest("GET", "/usage"+tt.query, nil))
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// UserDeletionHandler handles scheduling and cancelling user deletions
+type UserDeletionHandler struct {
+	deletionUseCase usecase.UserDeletionUseCaseInterface
+	defaults        APIDefaults
+	logger          logger.Logger
+}
+
+// NewUserDeletionHandler creates a new user deletion handler
+func NewUserDeletionHandler(deletionUseCase usecase.UserDeletionUseCaseInterface, logger logger.Logger) *UserDeletionHandler {
+	return &UserDeletionHandler{
+		deletionUseCase: deletionUseCase,
+		defaults:        DefaultAPIDefaults(),
+		logger:          logger,
+	}
+}
+
+// WithDefaults replaces the page size of deletion listings
+func (h *UserDeletionHandler) WithDefaults(defaults APIDefaults) *UserDeletionHandler {
+	h.defaults = defaults
+	return h
+}
+
+// UserDeletionDTO is the API representation of a deletion request
+type UserDeletionDTO struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Status      string     `json:"status"`
+	RequestedAt time.Time  `json:"requested_at"`
+	PurgeAfter  time.Time  `json:"purge_after"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	CancelledBy string     `json:"cancelled_by,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// CancelDeletionRequest represents the request body for cancelling a
+// deletion with the token from a cancellation link
+type CancelDeletionRequest struct {
+	Token string `json:"token"`
+}
+
+// ScheduleDeletion godoc
+// @Summary      Delete the current user
+// @Description  Schedule the authenticated user for deletion after the grace period. A cancellation link is sent by email.
+// @Tags         users
+// @Produce      json
+// @Success      202  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/me [delete]
+func (h *UserDeletionHandler) ScheduleDeletion(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.subject(w, r)
+	if !ok {
+		return
+	}
+
+	deletion, err := h.deletionUseCase.ScheduleDeletion(r.Context(), subject.ID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	render.Status(r, http.StatusAccepted)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "User deletion scheduled",
+		Data:      presentUserDeletion(deletion),
+		Timestamp: time.Now(),
+	})
+}
+
+// GetDeletion godoc
+// @Summary      Get the current user's pending deletion
+// @Description  Get the pending deletion request of the authenticated user
+// @Tags         users
+// @Produce      json
+// @Param        fields  query     string  false  "Comma-separated fields to return"
+// @Success      200     {object}  SuccessResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      401     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Router       /api/v1/me/deletion [get]
+func (h *UserDeletionHandler) GetDeletion(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.subject(w, r)
+	if !ok {
+		return
+	}
+	fields, ok := requestFields(w, r, UserDeletionDTO{})
+	if !ok {
+		return
+	}
+
+	deletion, err := h.deletionUseCase.GetPendingDeletion(r.Context(), subject.ID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Deletion request retrieved successfully",
+		Data:      fields.apply(presentUserDeletion(deletion)),
+		Timestamp: time.Now(),
+	})
+}
+
+// CancelDeletion godoc
+// @Summary      Cancel the current user's deletion
+// @Description  Abort the pending deletion request of the authenticated user
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/v1/me/deletion [delete]
+func (h *UserDeletionHandler) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	subject, ok := h.subject(w, r)
+	if !ok {
+		return
+	}
+
+	deletion, err := h.deletionUseCase.CancelDeletion(r.Context(), subject.ID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "User deletion cancelled",
+		Data:      presentUserDeletion(deletion),
+		Timestamp: time.Now(),
+	})
+}
+
+// CancelDeletionByToken godoc
+// @Summary      Cancel a deletion from its cancellation link
+// @Description  Abort a pending deletion request with the token from the cancellation email. No authentication is required.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      CancelDeletionRequest  true  "Cancellation token"
+// @Success      200      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Router       /api/v1/account-deletions/cancel [post]
+func (h *UserDeletionHandler) CancelDeletionByToken(w http.ResponseWriter, r *http.Request) {
+	var req CancelDeletionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if req.Token == "" {
+		unprocessable(w, r, "token_required", "token is required")
+		return
+	}
+
+	deletion, err := h.deletionUseCase.CancelDeletionByToken(r.Context(), req.Token)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "User deletion cancelled",
+		Data:      presentUserDeletion(deletion),
+		Timestamp: time.Now(),
+	})
+}
+
+// ListDeletions handles listing pending deletion requests, soonest purge first
+func (h *UserDeletionHandler) ListDeletions(w http.ResponseWriter, r *http.Request) {
+	limit, offset, warnings := pagination(r.URL.Query(), h.defaults.AdminPageSize, 0)
+
+	fields, ok := requestFields(w, r, UserDeletionDTO{})
+	if !ok {
+		return
+	}
+
+	deletions, err := h.deletionUseCase.ListPendingDeletions(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]UserDeletionDTO, 0, len(deletions))
+	for _, deletion := range deletions {
+		dtos = append(dtos, presentUserDeletion(deletion))
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Deletion requests retrieved successfully",
+		Data:      fields.apply(dtos),
+		Warnings:  warnings,
+		Meta:      listMeta(limit, offset, len(dtos)),
+		Timestamp: time.Now(),
+	})
+}
+
+// DeletionLoader loads the pending deletion requests of users, so clients
+// can fetch them with ?include=deletion
+func DeletionLoader(deletionUseCase usecase.UserDeletionUseCaseInterface) IncludeLoader {
+	return func(ctx context.Context, userIDs []string) (map[string]interface{}, error) {
+		deletions, err := deletionUseCase.GetPendingDeletions(ctx, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		loaded := make(map[string]interface{}, len(deletions))
+		for userID, deletion := range deletions {
+			loaded[userID] = presentUserDeletion(deletion)
+		}
+		return loaded, nil
+	}
+}
+
+// CancelDeletionByID handles an admin aborting a pending deletion request
+func (h *UserDeletionHandler) CancelDeletionByID(w http.ResponseWriter, r *http.Request) {
+	deletion, err := h.deletionUseCase.CancelDeletionByID(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "User deletion cancelled",
+		Data:      presentUserDeletion(deletion),
+		Timestamp: time.Now(),
+	})
+}
+
+// subject returns the authenticated subject, writing a 401 response when
+// the request carries none
+func (h *UserDeletionHandler) subject(w http.ResponseWriter, r *http.Request) (policy.Subject, bool) {
+	return requireSubject(w, r)
+}
+
+// requireSubject returns the authenticated subject of self-service
+// requests, writing a 401 response when the request carries none
+func requireSubject(w http.ResponseWriter, r *http.Request) (policy.Subject, bool) {
+	subject, ok := policy.SubjectFromContext(r.Context())
+	if !ok || subject.ID == "" {
+		render.Status(r, http.StatusUnauthorized)
+		respondJSON(w, r, Response{
+			Status:    "error",
+			Message:   "Authentication required",
+			Timestamp: time.Now(),
+		})
+		return policy.Subject{}, false
+	}
+	return subject, true
+}
+
+func presentUserDeletion(deletion *entities.UserDeletion) UserDeletionDTO {
+	return UserDeletionDTO{
+		ID:          deletion.ID,
+		UserID:      deletion.UserID,
+		Status:      deletion.Status(),
+		RequestedAt: deletion.RequestedAt,
+		PurgeAfter:  deletion.PurgeAfter,
+		CancelledAt: deletion.CancelledAt,
+		CancelledBy: deletion.CancelledBy,
+		CompletedAt: deletion.CompletedAt,
+	}
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/internal/interfaces/http/middleware/requestcontext"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockUserDeletionUseCase is a mock implementation of UserDeletionUseCaseInterface
+type MockUserDeletionUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUserDeletionUseCase) ScheduleDeletion(ctx context.Context, userID string) (*entities.UserDeletion, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.UserDeletion), args.Error(1)
+}
+
+func (m *MockUserDeletionUseCase) GetPendingDeletion(ctx context.Context, userID string) (*entities.UserDeletion, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.UserDeletion), args.Error(1)
+}
+
+func (m *MockUserDeletionUseCase) ListPendingDeletions(ctx context.Context, limit, offset int) ([]*entities.UserDeletion, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.UserDeletion), args.Error(1)
+}
+
+func (m *MockUserDeletionUseCase) GetPendingDeletions(ctx context.Context, userIDs []string) (map[string]*entities.UserDeletion, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*entities.UserDeletion), args.Error(1)
+}
+
+func (m *MockUserDeletionUseCase) CancelDeletion(ctx context.Context, userID string) (*entities.UserDeletion, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.UserDeletion), args.Error(1)
+}
+
+func (m *MockUserDeletionUseCase) CancelDeletionByToken(ctx context.Context, token string) (*entities.UserDeletion, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.UserDeletion), args.Error(1)
+}
+
+func (m *MockUserDeletionUseCase) CancelDeletionByID(ctx context.Context, id string) (*entities.UserDeletion, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.UserDeletion), args.Error(1)
+}
+
+func (m *MockUserDeletionUseCase) PurgeDue(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
+
+func newUserDeletionRouter(uc usecase.UserDeletionUseCaseInterface) http.Handler {
+	handler := NewUserDeletionHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Delete("/me", handler.ScheduleDeletion)
+	r.Get("/me/deletion", handler.GetDeletion)
+	r.Delete("/me/deletion", handler.CancelDeletion)
+	r.Post("/account-deletions/cancel", handler.CancelDeletionByToken)
+	r.Get("/deletions", handler.ListDeletions)
+	r.Delete("/deletions/{id}", handler.CancelDeletionByID)
+	return r
+}
+
+func withSubject(req *http.Request, id string) *http.Request {
+	return withCaller(req, policy.Subject{ID: id})
+}
+
+// withCaller authenticates req as subject and builds its request context,
+// as the auth and request context middleware do
+func withCaller(req *http.Request, subject policy.Subject) *http.Request {
+	req = req.WithContext(policy.WithSubject(req.Context(), subject))
+	return req.WithContext(request.With(req.Context(), requestcontext.Builder{}.Build(req)))
+}
+
+func TestUserDeletionHandler_ScheduleDeletion(t *testing.T) {
+	now := time.Now()
+	mockUseCase := new(MockUserDeletionUseCase)
+	mockUseCase.On("ScheduleDeletion", mock.Anything, "user_1").Return(&entities.UserDeletion{
+		ID:              "del_1",
+		UserID:          "user_1",
+		CancelTokenHash: "secret",
+		RequestedAt:     now,
+		PurgeAfter:      now.Add(time.Hour),
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newUserDeletionRouter(mockUseCase).ServeHTTP(w, withSubject(httptest.NewRequest("DELETE", "/me", nil), "user_1"))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.NotContains(t, w.Body.String(), "secret")
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "del_1", body.Data["id"])
+	assert.Equal(t, entities.UserDeletionPending, body.Data["status"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestUserDeletionHandler_RequiresSubject(t *testing.T) {
+	mockUseCase := new(MockUserDeletionUseCase)
+	router := newUserDeletionRouter(mockUseCase)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("DELETE", "/me", nil),
+		httptest.NewRequest("GET", "/me/deletion", nil),
+		httptest.NewRequest("DELETE", "/me/deletion", nil),
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "%s %s", req.Method, req.URL.Path)
+	}
+	mockUseCase.AssertNotCalled(t, "ScheduleDeletion", mock.Anything, mock.Anything)
+}
+
+func TestUserDeletionHandler_CancelDeletion(t *testing.T) {
+	cancelledAt := time.Now()
+	cancelled := &entities.UserDeletion{ID: "del_1", UserID: "user_1", CancelledAt: &cancelledAt, CancelledBy: usecase.CancelledByUser}
+
+	mockUseCase := new(MockUserDeletionUseCase)
+	mockUseCase.On("CancelDeletion", mock.Anything, "user_1").Return(cancelled, nil)
+
+	w := httptest.NewRecorder()
+	newUserDeletionRouter(mockUseCase).ServeHTTP(w, withSubject(httptest.NewRequest("DELETE", "/me/deletion", nil), "user_1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, entities.UserDeletionCancelled, body.Data["status"])
+	assert.Equal(t, usecase.CancelledByUser, body.Data["cancelled_by"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestUserDeletionHandler_CancelDeletionByToken(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		err             error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "valid token",
+			body:            `{"token":"abc"}`,
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "User deletion cancelled",
+		},
+		{
+			name:            "unknown token",
+			body:            `{"token":"abc"}`,
+			err:             repositories.ErrUserDeletionNotFound,
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: repositories.ErrUserDeletionNotFound.Error(),
+		},
+		{
+			name:            "missing token",
+			body:            `{}`,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: "token is required",
+		},
+		{
+			name:            "malformed body",
+			body:            `{"token":`,
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "Invalid request body",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUserDeletionUseCase)
+			if tt.err != nil {
+				mockUseCase.On("CancelDeletionByToken", mock.Anything, "abc").Return(nil, tt.err)
+			} else {
+				mockUseCase.On("CancelDeletionByToken", mock.Anything, "abc").Return(&entities.UserDeletion{ID: "del_1"}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/account-deletions/cancel", bytes.NewBufferString(tt.body))
+			newUserDeletionRouter(mockUseCase).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedMessage, body["message"])
+		})
+	}
+}
+
+func TestUserDeletionHandler_ListDeletions(t *testing.T) {
+	mockUseCase := new(MockUserDeletionUseCase)
+	mockUseCase.On("ListPendingDeletions", mock.Anything, 10, 5).Return([]*entities.UserDeletion{
+		{ID: "del_1", UserID: "user_1"},
+		{ID: "del_2", UserID: "user_2"},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	newUserDeletionRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/deletions?limit=10&offset=5", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Data, 2)
+	mockUseCase.AssertExpectations(t)
+}
@@ -1,29 +1,94 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/domain/request"
 	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/logger"
 )
 
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
 	userUseCase usecase.UserUseCaseInterface
+	presenter   *UserPresenter
+	// views runs saved views; nil when they are not available
+	views usecase.SavedViewUseCaseInterface
+	// suggestions serves SuggestUsers; nil when it is not available
+	suggestions     usecase.UserSuggestUseCaseInterface
+	suggestLimit    int
+	maxSuggestLimit int
+	// creations runs CreateUser in the background as createMode says; nil
+	// creates every user in the request
+	creations  usecase.UserCreationUseCaseInterface
+	createMode string
+	defaults   APIDefaults
+	logger     logger.Logger
 }
 
+// User creation modes of WithAsyncCreation, as named by USERS_CREATE_MODE
+const (
+	CreateModeSync   = "sync"
+	CreateModeAsync  = "async"
+	CreateModePrefer = "prefer"
+)
+
+// Headers of RFC 7240 preferences. Requests prefer respond-async to have
+// users created in the background in the prefer creation mode.
+const (
+	PreferHeader            = "Prefer"
+	PreferenceAppliedHeader = "Preference-Applied"
+	preferRespondAsync      = "respond-async"
+)
+
 // NewUserHandler creates a new user handler
-func NewUserHandler(userUseCase usecase.UserUseCaseInterface) *UserHandler {
+func NewUserHandler(userUseCase usecase.UserUseCaseInterface, presenter *UserPresenter, logger logger.Logger) *UserHandler {
 	return &UserHandler{
 		userUseCase: userUseCase,
+		presenter:   presenter,
+		defaults:    DefaultAPIDefaults(),
+		logger:      logger,
 	}
 }
 
+// WithDefaults replaces the page sizes and filter bounds of user listings
+func (h *UserHandler) WithDefaults(defaults APIDefaults) *UserHandler {
+	h.defaults = defaults
+	return h
+}
+
+// WithSavedViews lets ListUsers run saved views with ?view={id}
+func (h *UserHandler) WithSavedViews(views usecase.SavedViewUseCaseInterface) *UserHandler {
+	h.views = views
+	return h
+}
+
+// WithSuggestions lets SuggestUsers answer with up to limit users unless
+// asked for fewer, and with at most maxLimit when asked for more
+func (h *UserHandler) WithSuggestions(suggestions usecase.UserSuggestUseCaseInterface, limit, maxLimit int) *UserHandler {
+	h.suggestions = suggestions
+	h.suggestLimit = limit
+	h.maxSuggestLimit = maxLimit
+	return h
+}
+
+// WithAsyncCreation lets CreateUser queue users with creations and answer
+// 202 with the job, always in CreateModeAsync and for requests preferring
+// respond-async in CreateModePrefer
+func (h *UserHandler) WithAsyncCreation(creations usecase.UserCreationUseCaseInterface, mode string) *UserHandler {
+	h.creations = creations
+	h.createMode = mode
+	return h
+}
+
 // CreateUserRequest represents the request body for creating a user
 type CreateUserRequest struct {
 	Email string `json:"email"`
@@ -38,56 +103,114 @@ type UpdateUserRequest struct {
 
 // CreateUser godoc
 // @Summary      Create a new user
-// @Description  Create a new user with email and name
+// @Description  Create a new user with email and name. When USERS_CREATE_MODE is async, or prefer and the request sends Prefer: respond-async, the user is created in the background: the response is 202 with the job, to poll at its Location.
 // @Tags         users
 // @Accept       json
 // @Produce      json
-// @Param        user  body      CreateUserRequest  true  "User info"
-// @Success      200   {object}  UserResponse
-// @Failure      400   {object}  ErrorResponse
+// @Param        user    body      CreateUserRequest  true   "User info"
+// @Param        Prefer  header    string             false  "respond-async to create the user in the background"
+// @Success      200     {object}  UserResponse
+// @Success      202     {object}  SuccessResponse
+// @Failure      400     {object}  ErrorResponse
+// @Failure      403     {object}  ErrorResponse
+// @Failure      409     {object}  ErrorResponse
+// @Failure      422     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
 // @Router       /api/v1/users [post]
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req CreateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		render.JSON(w, r, Response{
-			Status:    "error",
-			Message:   "Invalid request body",
-			Timestamp: time.Now(),
-		})
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+	if h.createsAsync(r) {
+		h.requestCreation(w, r, req)
 		return
 	}
 
 	user, err := h.userUseCase.CreateUser(r.Context(), req.Email, req.Name)
 	if err != nil {
-		render.JSON(w, r, Response{
-			Status:    "error",
-			Message:   err.Error(),
-			Timestamp: time.Now(),
-		})
+		writeError(w, r, h.logger, err)
 		return
 	}
+	setConsistencyToken(w, repositories.UserResource, user.ID)
 
-	render.JSON(w, r, Response{
+	respondJSON(w, r, Response{
 		Status:    "success",
 		Message:   "User created successfully",
-		Data:      user,
+		Data:      h.presenter.Present(r.Context(), user),
+		Timestamp: time.Now(),
+	})
+}
+
+// requestCreation queues the creation of the user of req and answers with
+// the job
+func (h *UserHandler) requestCreation(w http.ResponseWriter, r *http.Request, req CreateUserRequest) {
+	job, err := h.creations.RequestCreation(r.Context(), req.Email, req.Name, request.Current(r.Context()).UserID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	if prefersAsync(r) {
+		w.Header().Set(PreferenceAppliedHeader, preferRespondAsync)
+	}
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	render.Status(r, http.StatusAccepted)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "User creation queued",
+		Data:      presentJob(job),
 		Timestamp: time.Now(),
 	})
 }
 
+// createsAsync reports whether the user of r is created in the background
+func (h *UserHandler) createsAsync(r *http.Request) bool {
+	if h.creations == nil {
+		return false
+	}
+	switch h.createMode {
+	case CreateModeAsync:
+		return true
+	case CreateModePrefer:
+		return prefersAsync(r)
+	default:
+		return false
+	}
+}
+
+// prefersAsync reports whether r prefers respond-async
+func prefersAsync(r *http.Request) bool {
+	for _, header := range r.Header.Values(PreferHeader) {
+		for _, preference := range strings.Split(header, ",") {
+			// Parameters after ";" do not change the preference
+			token, _, _ := strings.Cut(preference, ";")
+			if strings.EqualFold(strings.TrimSpace(token), preferRespondAsync) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GetUser godoc
 // @Summary      Get user by ID
 // @Description  Get a user by their ID
 // @Tags         users
 // @Produce      json
-// @Param        id   path      string  true  "User ID"
-// @Success      200  {object}  UserResponse
-// @Failure      404  {object}  ErrorResponse
+// @Param        id       path      string  true   "User ID"
+// @Param        fields   query     string  false  "Comma-separated fields to return"
+// @Param        include  query     string  false  "Comma-separated related resources to embed: deletion, email_suppression"
+// @Success      200      {object}  UserResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
 // @Router       /api/v1/users/{id} [get]
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "id")
 	if userID == "" {
-		render.JSON(w, r, Response{
+		respondJSON(w, r, Response{
 			Status:    "error",
 			Message:   "User ID is required",
 			Timestamp: time.Now(),
@@ -95,26 +218,33 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	view, ok := h.requestUserView(w, r)
+	if !ok {
+		return
+	}
+
 	user, err := h.userUseCase.GetUserByID(r.Context(), userID)
 	if err != nil {
-		render.JSON(w, r, Response{
-			Status:    "error",
-			Message:   err.Error(),
-			Timestamp: time.Now(),
-		})
+		writeError(w, r, h.logger, err)
 		return
 	}
 
-	render.JSON(w, r, Response{
+	data, err := h.presentUser(r.Context(), view, user)
+	if err != nil {
+		InternalError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
 		Status:    "success",
-		Data:      user,
+		Data:      data,
 		Timestamp: time.Now(),
 	})
 }
 
 // UpdateUser godoc
 // @Summary      Update a user
-// @Description  Update a user's information
+// @Description  Update a user's information. A new email is only applied once the link sent to it is followed; until then the response carries a confirmation_pending warning.
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -122,11 +252,14 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 // @Param        user  body      UpdateUserRequest  true  "User info"
 // @Success      200   {object}  UserResponse
 // @Failure      400   {object}  ErrorResponse
+// @Failure      404   {object}  ErrorResponse
+// @Failure      409   {object}  ErrorResponse
+// @Failure      500   {object}  ErrorResponse
 // @Router       /api/v1/users/{id} [put]
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "id")
 	if userID == "" {
-		render.JSON(w, r, Response{
+		respondJSON(w, r, Response{
 			Status:    "error",
 			Message:   "User ID is required",
 			Timestamp: time.Now(),
@@ -135,29 +268,85 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req UpdateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		render.JSON(w, r, Response{
-			Status:    "error",
-			Message:   "Invalid request body",
-			Timestamp: time.Now(),
-		})
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
 		return
 	}
 
 	user, err := h.userUseCase.UpdateUser(r.Context(), userID, req.Name, req.Email)
 	if err != nil {
-		render.JSON(w, r, Response{
-			Status:    "error",
-			Message:   err.Error(),
-			Timestamp: time.Now(),
-		})
+		writeError(w, r, h.logger, err)
 		return
 	}
+	setConsistencyToken(w, repositories.UserResource, user.ID)
+
+	// A new email the user still has to confirm is not applied yet
+	var warnings []Warning
+	if req.Email != "" && req.Email != user.Email {
+		warnings = append(warnings, Warning{
+			Code:      WarningConfirmationPending,
+			Parameter: "email",
+			Message:   "email is changed once the link sent to the new address is followed",
+		})
+	}
 
-	render.JSON(w, r, Response{
+	respondJSON(w, r, Response{
 		Status:    "success",
 		Message:   "User updated successfully",
-		Data:      user,
+		Data:      h.presenter.Present(r.Context(), user),
+		Warnings:  warnings,
+		Timestamp: time.Now(),
+	})
+}
+
+// UpsertUserResultDTO is the user an upsert created or updated
+type UpsertUserResultDTO struct {
+	// Created is true when no user had the email, and false when the user
+	// that had it was updated
+	Created bool    `json:"created"`
+	User    UserDTO `json:"user"`
+}
+
+// UpsertUser godoc
+// @Summary      Create or update a user by email
+// @Description  Create a user, or update the name of the user with the same email, in one atomic step. Responds 201 when the user was created and 200 when it was updated.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        user  body      CreateUserRequest  true  "User info"
+// @Success      200   {object}  SuccessResponse
+// @Success      201   {object}  SuccessResponse
+// @Failure      400   {object}  ErrorResponse
+// @Failure      403   {object}  ErrorResponse
+// @Failure      422   {object}  ErrorResponse
+// @Failure      500   {object}  ErrorResponse
+// @Router       /api/v1/users:upsert [put]
+func (h *UserHandler) UpsertUser(w http.ResponseWriter, r *http.Request) {
+	var req CreateUserRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	user, created, err := h.userUseCase.UpsertUser(r.Context(), req.Email, req.Name)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+	setConsistencyToken(w, repositories.UserResource, user.ID)
+
+	message := "User updated successfully"
+	if created {
+		render.Status(r, http.StatusCreated)
+		message = "User created successfully"
+	}
+	respondJSON(w, r, Response{
+		Status:  "success",
+		Message: message,
+		Data: UpsertUserResultDTO{
+			Created: created,
+			User:    h.presenter.Present(r.Context(), user),
+		},
 		Timestamp: time.Now(),
 	})
 }
@@ -170,11 +359,12 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 // @Param        id   path      string  true  "User ID"
 // @Success      200  {object}  SuccessResponse
 // @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
 // @Router       /api/v1/users/{id} [delete]
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	userID := chi.URLParam(r, "id")
 	if userID == "" {
-		render.JSON(w, r, Response{
+		respondJSON(w, r, Response{
 			Status:    "error",
 			Message:   "User ID is required",
 			Timestamp: time.Now(),
@@ -184,15 +374,12 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 
 	err := h.userUseCase.DeleteUser(r.Context(), userID)
 	if err != nil {
-		render.JSON(w, r, Response{
-			Status:    "error",
-			Message:   err.Error(),
-			Timestamp: time.Now(),
-		})
+		writeError(w, r, h.logger, err)
 		return
 	}
+	setConsistencyToken(w, repositories.UserResource, userID)
 
-	render.JSON(w, r, Response{
+	respondJSON(w, r, Response{
 		Status:    "success",
 		Message:   "User deleted successfully",
 		Timestamp: time.Now(),
@@ -204,40 +391,157 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 // @Description  Get a list of all users
 // @Tags         users
 // @Produce      json
-// @Success      200  {array}   UserResponse
+// @Param        fields   query     string  false  "Comma-separated fields to return"
+// @Param        include  query     string  false  "Comma-separated related resources to embed: deletion, email_suppression"
+// @Param        filter   query     string  false  "Filters of the form filter[field][operator]=value, e.g. filter[name][contains]=smith"
+// @Param        sort     query     string  false  "Comma-separated fields to sort by, prefixed with - for descending order"
+// @Param        view     query     string  false  "ID of a saved view whose filters and sort to apply"
+// @Success      200      {array}   UserResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
 // @Router       /api/v1/users [get]
 func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	h.listUsers(w, r, false)
+}
 
-	limit := 10 // default limit
-	offset := 0 // default offset
+// ListUsersOrdered serves GET /api/v1/users like ListUsers, but asks the
+// database for one order in every case: by creation when the request does
+// not sort, and by ID among users sorting the same, so offset pages do not
+// skip or repeat users. It is the candidate of the users.list experiment.
+func (h *UserHandler) ListUsersOrdered(w http.ResponseWriter, r *http.Request) {
+	h.listUsers(w, r, true)
+}
 
-	if limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
+// listUsers lists the users requested, in a stable order when ordered is
+// set
+func (h *UserHandler) listUsers(w http.ResponseWriter, r *http.Request, ordered bool) {
+	limit, offset, warnings := pagination(r.URL.Query(), h.defaults.PageSize, h.defaults.MaxPageSize)
+
+	view, ok := h.requestUserView(w, r)
+	if !ok {
+		return
 	}
 
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
+	// A saved view contributes its filters, and its sort unless the request
+	// sorts itself
+	query := r.URL.Query()
+	sortParam := query.Get("sort")
+	if viewID := query.Get("view"); viewID != "" {
+		view, err := h.savedView(r, viewID)
+		if err != nil {
+			writeError(w, r, h.logger, err)
+			return
+		}
+		for key, value := range view.Filters {
+			query.Add(key, value)
+		}
+		if sortParam == "" {
+			sortParam = view.Sort
 		}
 	}
 
-	users, err := h.userUseCase.ListUsers(r.Context(), limit, offset)
+	conditions, err := parseFilters(query, userFilterFields, h.defaults)
 	if err != nil {
-		render.JSON(w, r, Response{
-			Status:    "error",
-			Message:   err.Error(),
-			Timestamp: time.Now(),
+		badRequest(w, r, "Invalid filter: "+err.Error())
+		return
+	}
+	sortOrders, err := parseSort(sortParam, userFilterFields, h.defaults)
+	if err != nil {
+		badRequest(w, r, "Invalid sort parameter: "+err.Error())
+		return
+	}
+
+	if ordered {
+		sortOrders = stableUserOrder(sortOrders)
+	}
+
+	var users []*entities.User
+	if len(conditions) == 0 && len(sortOrders) == 0 {
+		users, err = h.userUseCase.ListUsers(r.Context(), limit, offset)
+	} else {
+		warnUnindexedFilters(h.logger, r, conditions, userFilterFields)
+		users, err = h.userUseCase.SearchUsers(r.Context(), repositories.Specification{
+			Conditions: conditions,
+			Sort:       sortOrders,
+			Limit:      limit,
+			Offset:     offset,
 		})
+	}
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	data, err := h.presentUsers(r.Context(), view, users)
+	if err != nil {
+		InternalError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Data:      data,
+		Warnings:  warnings,
+		Meta:      listMeta(limit, offset, len(users)),
+		Timestamp: time.Now(),
+	})
+}
+
+// stableUserOrder completes orders so that no two users sort the same:
+// requests that do not sort list users by creation, and ties are broken by
+// ID
+func stableUserOrder(orders []repositories.SortOrder) []repositories.SortOrder {
+	if len(orders) == 0 {
+		orders = []repositories.SortOrder{{Field: "created_at"}}
+	}
+	for _, order := range orders {
+		if order.Field == "id" {
+			return orders
+		}
+	}
+	return append(orders, repositories.SortOrder{Field: "id"})
+}
+
+// SuggestUsers godoc
+// @Summary      Suggest users
+// @Description  Search-as-you-type: users whose name, or any word of it, starts with q, ranked alphabetically. Callers who may read every email also match the local part of emails. Suggestions come from an in-memory index refreshed from the database in the background, so they may trail recent changes by up to USERS_SUGGEST_REFRESH_INTERVAL.
+// @Tags         users
+// @Produce      json
+// @Param        q      query     string  true   "Prefix typed so far, at most 100 characters"
+// @Param        limit  query     int     false  "Number of suggestions"
+// @Success      200    {array}   UserResponse
+// @Failure      401    {object}  ErrorResponse
+// @Failure      403    {object}  ErrorResponse
+// @Failure      422    {object}  ErrorResponse
+// @Failure      503    {object}  ErrorResponse
+// @Router       /api/v1/users/suggest [get]
+func (h *UserHandler) SuggestUsers(w http.ResponseWriter, r *http.Request) {
+	limit, _, warnings := pagination(r.URL.Query(), h.suggestLimit, h.maxSuggestLimit)
+
+	users, err := h.suggestions.Suggest(r.Context(), r.URL.Query().Get("q"), limit, h.presenter.CanReadEveryEmail(r.Context()))
+	if err != nil {
+		writeError(w, r, h.logger, err)
 		return
 	}
 
-	render.JSON(w, r, Response{
+	respondJSON(w, r, Response{
 		Status:    "success",
-		Data:      users,
+		Data:      h.presenter.PresentList(r.Context(), users),
+		Warnings:  warnings,
 		Timestamp: time.Now(),
 	})
 }
+
+// savedView retrieves a saved view the caller may run
+func (h *UserHandler) savedView(r *http.Request, id string) (*entities.SavedView, error) {
+	if h.views == nil {
+		return nil, repositories.ErrSavedViewNotFound
+	}
+	return h.views.GetView(r.Context(), request.Current(r.Context()).UserID, id)
+}
+
+// setConsistencyToken returns a consistency token for a write to the entity,
+// which clients send back so their next reads see the write
+func setConsistencyToken(w http.ResponseWriter, resource, id string) {
+	w.Header().Set(consistency.Header, consistency.NewToken(resource, id, time.Now()))
+}
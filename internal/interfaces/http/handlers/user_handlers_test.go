@@ -4,15 +4,26 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	policyinfra "clean-architecture/internal/infrastructure/policy"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/jsoncodec"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/retryafter"
 )
 
 // MockUserUseCase is a mock implementation of UserUseCaseInterface
@@ -28,6 +39,14 @@ func (m *MockUserUseCase) CreateUser(ctx context.Context, email, name string) (*
 	return args.Get(0).(*entities.User), args.Error(1)
 }
 
+func (m *MockUserUseCase) CreateUserWithPassword(ctx context.Context, email, name, passwordHash string) (*entities.User, error) {
+	args := m.Called(ctx, email, name, passwordHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), args.Error(1)
+}
+
 func (m *MockUserUseCase) GetUserByID(ctx context.Context, id string) (*entities.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -44,6 +63,22 @@ func (m *MockUserUseCase) UpdateUser(ctx context.Context, id, name, email string
 	return args.Get(0).(*entities.User), args.Error(1)
 }
 
+func (m *MockUserUseCase) ConfirmEmailChange(ctx context.Context, token string) (*entities.User, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.User), args.Error(1)
+}
+
+func (m *MockUserUseCase) UpsertUser(ctx context.Context, email, name string) (*entities.User, bool, error) {
+	args := m.Called(ctx, email, name)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*entities.User), args.Bool(1), args.Error(2)
+}
+
 func (m *MockUserUseCase) DeleteUser(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -57,6 +92,19 @@ func (m *MockUserUseCase) ListUsers(ctx context.Context, limit, offset int) ([]*
 	return args.Get(0).([]*entities.User), args.Error(1)
 }
 
+func (m *MockUserUseCase) SearchUsers(ctx context.Context, spec repositories.Specification) ([]*entities.User, error) {
+	args := m.Called(ctx, spec)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.User), args.Error(1)
+}
+
+func (m *MockUserUseCase) CountUsers(ctx context.Context, spec repositories.Specification) (int64, error) {
+	args := m.Called(ctx, spec)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestUserHandler_CreateUser(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -87,16 +135,58 @@ func TestUserHandler_CreateUser(t *testing.T) {
 			},
 		},
 		{
-			name: "invalid request body",
+			name: "duplicate email",
+			requestBody: CreateUserRequest{
+				Email: "test@example.com",
+				Name:  "Test User",
+			},
+			mockUser:       nil,
+			mockError:      repositories.ErrUserAlreadyExists,
+			expectedStatus: http.StatusConflict,
+			expectedBody: map[string]interface{}{
+				"status":  "error",
+				"message": "user with this email already exists",
+			},
+		},
+		{
+			name: "missing name",
+			requestBody: CreateUserRequest{
+				Email: "test@example.com",
+			},
+			mockUser:       nil,
+			mockError:      usecase.ErrNameRequired,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody: map[string]interface{}{
+				"status":  "error",
+				"message": "name is required",
+			},
+		},
+		{
+			name: "user quota reached",
+			requestBody: CreateUserRequest{
+				Email: "test@example.com",
+				Name:  "Test User",
+			},
+			mockUser:       nil,
+			mockError:      &usecase.QuotaExceededError{Resource: "users", Limit: 100},
+			expectedStatus: http.StatusForbidden,
+			expectedBody: map[string]interface{}{
+				"status":  "error",
+				"message": "users quota of 100 reached",
+			},
+		},
+		{
+			name: "unexpected error",
 			requestBody: CreateUserRequest{
 				Email: "test@example.com",
 				Name:  "Test User",
 			},
 			mockUser:       nil,
 			mockError:      assert.AnError,
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusInternalServerError,
 			expectedBody: map[string]interface{}{
-				"status": "error",
+				"status":  "error",
+				"message": "An internal error occurred",
 			},
 		},
 	}
@@ -105,9 +195,7 @@ func TestUserHandler_CreateUser(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockUseCase := new(MockUserUseCase)
-			handler := &UserHandler{
-				userUseCase: mockUseCase,
-			}
+			handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
 
 			// Mock expectations
 			if tt.mockError == nil {
@@ -143,6 +231,78 @@ func TestUserHandler_CreateUser(t *testing.T) {
 	}
 }
 
+// MockUserCreationUseCase is a mock implementation of
+// UserCreationUseCaseInterface
+type MockUserCreationUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUserCreationUseCase) RequestCreation(ctx context.Context, email, name, requestedBy string) (*entities.Job, error) {
+	args := m.Called(ctx, email, name, requestedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Job), args.Error(1)
+}
+
+func TestUserHandler_CreateUserAsync(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		prefer  string
+		async   bool
+		applied string
+	}{
+		{name: "sync mode ignores the preference", mode: CreateModeSync, prefer: "respond-async"},
+		{name: "async mode", mode: CreateModeAsync, async: true},
+		{name: "async mode applies the preference", mode: CreateModeAsync, prefer: "respond-async", async: true, applied: "respond-async"},
+		{name: "prefer mode without preference", mode: CreateModePrefer},
+		{name: "prefer mode with preference", mode: CreateModePrefer, prefer: "wait=10, Respond-Async", async: true, applied: "respond-async"},
+		{name: "prefer mode with other preference", mode: CreateModePrefer, prefer: "return=minimal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := new(MockUserUseCase)
+			creations := new(MockUserCreationUseCase)
+			handler := NewUserHandler(users, NewUserPresenter(nil), logger.New()).WithAsyncCreation(creations, tt.mode)
+			if tt.async {
+				creations.On("RequestCreation", mock.Anything, "test@example.com", "Test User", "").
+					Return(&entities.Job{ID: "job_1", Type: usecase.JobTypeUserCreate, Status: entities.JobQueued}, nil)
+			} else {
+				users.On("CreateUser", mock.Anything, "test@example.com", "Test User").
+					Return(&entities.User{ID: "user_123", Email: "test@example.com", Name: "Test User"}, nil)
+			}
+
+			req := httptest.NewRequest("POST", "/users", bytes.NewBufferString(`{"email":"test@example.com","name":"Test User"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.prefer != "" {
+				req.Header.Set(PreferHeader, tt.prefer)
+			}
+			w := httptest.NewRecorder()
+			handler.CreateUser(w, req)
+
+			assert.Equal(t, tt.applied, w.Header().Get(PreferenceAppliedHeader))
+			if tt.async {
+				assert.Equal(t, http.StatusAccepted, w.Code)
+				assert.Equal(t, "/api/v1/jobs/job_1", w.Header().Get("Location"))
+
+				var response struct {
+					Data JobDTO `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "job_1", response.Data.ID)
+				assert.Equal(t, entities.JobQueued, response.Data.Status)
+			} else {
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.Empty(t, w.Header().Get("Location"))
+			}
+			users.AssertExpectations(t)
+			creations.AssertExpectations(t)
+		})
+	}
+}
+
 func TestUserHandler_GetUser(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -172,10 +332,22 @@ func TestUserHandler_GetUser(t *testing.T) {
 			name:           "user not found",
 			userID:         "user_123",
 			mockUser:       nil,
+			mockError:      repositories.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody: map[string]interface{}{
+				"status":  "error",
+				"message": "user not found",
+			},
+		},
+		{
+			name:           "unexpected error",
+			userID:         "user_123",
+			mockUser:       nil,
 			mockError:      assert.AnError,
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusInternalServerError,
 			expectedBody: map[string]interface{}{
-				"status": "error",
+				"status":  "error",
+				"message": "An internal error occurred",
 			},
 		},
 		{
@@ -195,9 +367,7 @@ func TestUserHandler_GetUser(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockUseCase := new(MockUserUseCase)
-			handler := &UserHandler{
-				userUseCase: mockUseCase,
-			}
+			handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
 
 			// Mock expectations
 			if tt.userID != "" {
@@ -284,9 +454,7 @@ func TestUserHandler_UpdateUser(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockUseCase := new(MockUserUseCase)
-			handler := &UserHandler{
-				userUseCase: mockUseCase,
-			}
+			handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
 
 			// Mock expectations
 			if tt.userID != "" {
@@ -326,6 +494,64 @@ func TestUserHandler_UpdateUser(t *testing.T) {
 	}
 }
 
+func TestUserHandler_UpdateUser_EmailAwaitsConfirmation(t *testing.T) {
+	mockUseCase := new(MockUserUseCase)
+	handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
+	mockUseCase.On("UpdateUser", mock.Anything, "user_123", "", "new@example.com").
+		Return(&entities.User{ID: "user_123", Email: "old@example.com", Name: "Test User"}, nil)
+
+	req := httptest.NewRequest("PUT", "/users/user_123", bytes.NewBufferString(`{"email":"new@example.com"}`))
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "user_123")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+	handler.UpdateUser(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Warnings, 1)
+	assert.Equal(t, WarningConfirmationPending, response.Warnings[0].Code)
+	assert.Equal(t, "email", response.Warnings[0].Parameter)
+}
+
+func TestUserHandler_ConfirmEmailChange(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockError      error
+		expectedStatus int
+		expectedMsg    string
+	}{
+		{"confirmed", `{"token":"tok"}`, nil, http.StatusOK, "Email changed successfully"},
+		{"missing token", `{}`, nil, http.StatusUnprocessableEntity, "token is required"},
+		{"unknown token", `{"token":"tok"}`, repositories.ErrEmailChangeNotFound, http.StatusNotFound, "email change not found"},
+		{"expired link", `{"token":"tok"}`, usecase.ErrEmailChangeExpired, http.StatusGone, "email change link has expired"},
+		{"email taken", `{"token":"tok"}`, repositories.ErrUserAlreadyExists, http.StatusConflict, "user with this email already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUserUseCase)
+			handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
+			if tt.mockError != nil {
+				mockUseCase.On("ConfirmEmailChange", mock.Anything, "tok").Return(nil, tt.mockError)
+			} else {
+				mockUseCase.On("ConfirmEmailChange", mock.Anything, "tok").
+					Return(&entities.User{ID: "user_123", Email: "new@example.com", Name: "Test User"}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ConfirmEmailChange(w, httptest.NewRequest("POST", "/email-changes/confirm", bytes.NewBufferString(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedMsg, response.Message)
+		})
+	}
+}
+
 func TestUserHandler_DeleteUser(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -347,10 +573,21 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 		{
 			name:           "user not found",
 			userID:         "user_123",
+			mockError:      repositories.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedBody: map[string]interface{}{
+				"status":  "error",
+				"message": "user not found",
+			},
+		},
+		{
+			name:           "unexpected error",
+			userID:         "user_123",
 			mockError:      assert.AnError,
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusInternalServerError,
 			expectedBody: map[string]interface{}{
-				"status": "error",
+				"status":  "error",
+				"message": "An internal error occurred",
 			},
 		},
 		{
@@ -369,9 +606,7 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockUseCase := new(MockUserUseCase)
-			handler := &UserHandler{
-				userUseCase: mockUseCase,
-			}
+			handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
 
 			// Mock expectations
 			if tt.userID != "" {
@@ -448,9 +683,10 @@ func TestUserHandler_ListUsers(t *testing.T) {
 			queryParams:    "?limit=5&offset=0",
 			mockUsers:      nil,
 			mockError:      assert.AnError,
-			expectedStatus: http.StatusOK,
+			expectedStatus: http.StatusInternalServerError,
 			expectedBody: map[string]interface{}{
-				"status": "error",
+				"status":  "error",
+				"message": "An internal error occurred",
 			},
 		},
 	}
@@ -459,9 +695,7 @@ func TestUserHandler_ListUsers(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockUseCase := new(MockUserUseCase)
-			handler := &UserHandler{
-				userUseCase: mockUseCase,
-			}
+			handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
 
 			// Mock expectations
 			mockUseCase.On("ListUsers", mock.Anything, 5, 0).
@@ -489,3 +723,262 @@ func TestUserHandler_ListUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_ListUsersOrdered(t *testing.T) {
+	sorted := func(orders ...repositories.SortOrder) interface{} {
+		return mock.MatchedBy(func(spec repositories.Specification) bool {
+			return len(spec.Conditions) == 0 && spec.Limit == 5 && assert.ObjectsAreEqual(orders, spec.Sort)
+		})
+	}
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("SearchUsers", mock.Anything, sorted(repositories.SortOrder{Field: "created_at"}, repositories.SortOrder{Field: "id"})).
+		Return([]*entities.User{{ID: "user_1", Name: "Ada Lovelace"}}, nil)
+	mockUseCase.On("SearchUsers", mock.Anything, sorted(repositories.SortOrder{Field: "name", Descending: true}, repositories.SortOrder{Field: "id"})).
+		Return([]*entities.User{{ID: "user_2", Name: "Grace Hopper"}}, nil)
+	mockUseCase.On("SearchUsers", mock.Anything, sorted(repositories.SortOrder{Field: "id", Descending: true})).
+		Return([]*entities.User{}, nil)
+	handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
+
+	w := httptest.NewRecorder()
+	handler.ListUsersOrdered(w, httptest.NewRequest(http.MethodGet, "/users?limit=5", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Ada Lovelace")
+
+	// Ties between users sorting the same are broken by ID
+	w = httptest.NewRecorder()
+	handler.ListUsersOrdered(w, httptest.NewRequest(http.MethodGet, "/users?limit=5&sort=-name", nil))
+	assert.Contains(t, w.Body.String(), "Grace Hopper")
+
+	w = httptest.NewRecorder()
+	handler.ListUsersOrdered(w, httptest.NewRequest(http.MethodGet, "/users?limit=5&sort=-id", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockUseCase.AssertExpectations(t)
+	mockUseCase.AssertNotCalled(t, "ListUsers", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInternalError(t *testing.T) {
+	req := httptest.NewRequest("GET", "/users", nil)
+	w := httptest.NewRecorder()
+
+	InternalError(w, req, logger.New(), assert.AnError)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "error", response["status"])
+	assert.Equal(t, "An internal error occurred", response["message"])
+	assert.Regexp(t, `^err_[0-9a-f]{16}$`, response["error_id"])
+	assert.NotContains(t, w.Body.String(), assert.AnError.Error())
+}
+
+// listUsersStub serves a fixed user list without the bookkeeping of
+// MockUserUseCase, which would dominate a benchmark
+type listUsersStub struct {
+	MockUserUseCase
+	users []*entities.User
+}
+
+func (s *listUsersStub) ListUsers(ctx context.Context, limit, offset int) ([]*entities.User, error) {
+	return s.users, nil
+}
+
+func BenchmarkUserHandler_ListUsers(b *testing.B) {
+	stub := &listUsersStub{users: make([]*entities.User, 50)}
+	for i := range stub.users {
+		stub.users[i] = &entities.User{
+			ID:        fmt.Sprintf("user_%d", i),
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			Name:      fmt.Sprintf("User %d", i),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+	}
+	handler := NewUserHandler(stub, NewUserPresenter(nil), logger.New())
+	req := httptest.NewRequest("GET", "/users?limit=50", nil)
+
+	for _, c := range []jsoncodec.Codec{jsoncodec.Standard, jsoncodec.Segmentio} {
+		b.Run(c.Name(), func(b *testing.B) {
+			defer func(previous jsoncodec.Codec) { codec = previous }(codec)
+			codec = c
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				handler.ListUsers(httptest.NewRecorder(), req)
+			}
+		})
+	}
+}
+
+func TestUserHandler_ConsistencyToken(t *testing.T) {
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("CreateUser", mock.Anything, "new@example.com", "New User").
+		Return(&entities.User{ID: "user_123", Email: "new@example.com", Name: "New User"}, nil)
+	mockUseCase.On("CreateUser", mock.Anything, "taken@example.com", "New User").
+		Return(nil, repositories.ErrUserAlreadyExists)
+	handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
+
+	w := httptest.NewRecorder()
+	handler.CreateUser(w, httptest.NewRequest("POST", "/users", bytes.NewBufferString(`{"email":"new@example.com","name":"New User"}`)))
+	token, err := consistency.Parse(w.Header().Get(consistency.Header))
+	require.NoError(t, err)
+	assert.Equal(t, repositories.UserResource, token.Resource)
+	assert.Equal(t, "user_123", token.ID)
+	assert.WithinDuration(t, time.Now(), token.WrittenAt, time.Minute)
+
+	w = httptest.NewRecorder()
+	handler.CreateUser(w, httptest.NewRequest("POST", "/users", bytes.NewBufferString(`{"email":"taken@example.com","name":"New User"}`)))
+	assert.Empty(t, w.Header().Get(consistency.Header), "failed writes return no token")
+}
+
+func TestUserHandler_UpsertUser(t *testing.T) {
+	tests := []struct {
+		name            string
+		email           string
+		created         bool
+		mockError       error
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{
+			name:            "created",
+			email:           "new@example.com",
+			created:         true,
+			expectedStatus:  http.StatusCreated,
+			expectedMessage: "User created successfully",
+		},
+		{
+			name:            "updated",
+			email:           "existing@example.com",
+			expectedStatus:  http.StatusOK,
+			expectedMessage: "User updated successfully",
+		},
+		{
+			name:            "missing email",
+			mockError:       usecase.ErrEmailRequired,
+			expectedStatus:  http.StatusUnprocessableEntity,
+			expectedMessage: usecase.ErrEmailRequired.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUserUseCase)
+			if tt.mockError != nil {
+				mockUseCase.On("UpsertUser", mock.Anything, tt.email, "Synced User").Return(nil, false, tt.mockError)
+			} else {
+				mockUseCase.On("UpsertUser", mock.Anything, tt.email, "Synced User").
+					Return(&entities.User{ID: "user_123", Email: tt.email, Name: "Synced User"}, tt.created, nil)
+			}
+			handler := NewUserHandler(mockUseCase, NewUserPresenter(nil), logger.New())
+
+			body := fmt.Sprintf(`{"email":%q,"name":"Synced User"}`, tt.email)
+			w := httptest.NewRecorder()
+			handler.UpsertUser(w, httptest.NewRequest(http.MethodPut, "/users:upsert", bytes.NewBufferString(body)))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response struct {
+				Status  string               `json:"status"`
+				Message string               `json:"message"`
+				Data    *UpsertUserResultDTO `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedMessage, response.Message)
+
+			if tt.mockError != nil {
+				assert.Equal(t, "error", response.Status)
+				assert.Nil(t, response.Data)
+				assert.Empty(t, w.Header().Get(consistency.Header))
+				return
+			}
+			require.NotNil(t, response.Data)
+			assert.Equal(t, tt.created, response.Data.Created)
+			assert.Equal(t, "user_123", response.Data.User.ID)
+			assert.NotEmpty(t, w.Header().Get(consistency.Header))
+		})
+	}
+}
+
+// MockUserSuggestUseCase is a mock implementation of
+// UserSuggestUseCaseInterface
+type MockUserSuggestUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUserSuggestUseCase) Suggest(ctx context.Context, query string, limit int, handles bool) ([]*entities.User, error) {
+	args := m.Called(ctx, query, limit, handles)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.User), args.Error(1)
+}
+
+func TestUserHandler_SuggestUsers(t *testing.T) {
+	ada := &entities.User{ID: "user_1", Email: "ada@example.com", Name: "Ada Lovelace"}
+	tests := []struct {
+		name          string
+		role          string
+		url           string
+		limit         int
+		handles       bool
+		expectedEmail string
+		warned        bool
+	}{
+		{"admin matches handles", policy.RoleAdmin, "/users/suggest?q=ad", 10, true, "ada@example.com", false},
+		{"user matches names only", policy.RoleUser, "/users/suggest?q=ad", 10, false, "a**@example.com", false},
+		{"limit", policy.RoleUser, "/users/suggest?q=ad&limit=3", 3, false, "a**@example.com", false},
+		{"limit clamped", policy.RoleUser, "/users/suggest?q=ad&limit=500", 25, false, "a**@example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions := new(MockUserSuggestUseCase)
+			suggestions.On("Suggest", mock.Anything, "ad", tt.limit, tt.handles).Return([]*entities.User{ada}, nil)
+			presenter := NewUserPresenter(policyinfra.NewRoleEngine(policyinfra.DefaultRules()))
+			handler := NewUserHandler(new(MockUserUseCase), presenter, logger.New()).WithSuggestions(suggestions, 10, 25)
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			req = req.WithContext(policy.WithSubject(req.Context(), policy.Subject{ID: "user_2", Roles: []string{tt.role}}))
+			w := httptest.NewRecorder()
+			handler.SuggestUsers(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response struct {
+				Data     []UserDTO `json:"data"`
+				Warnings []Warning `json:"warnings"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			require.Len(t, response.Data, 1)
+			assert.Equal(t, "Ada Lovelace", response.Data[0].Name)
+			assert.Equal(t, tt.expectedEmail, response.Data[0].Email)
+			assert.Equal(t, tt.warned, len(response.Warnings) > 0)
+			suggestions.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserHandler_SuggestUsers_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"missing query", usecase.ErrSuggestQueryRequired, http.StatusUnprocessableEntity},
+		{"index not built", retryafter.Wrap(usecase.ErrSuggestionsUnavailable, 5*time.Second), http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions := new(MockUserSuggestUseCase)
+			suggestions.On("Suggest", mock.Anything, "", 10, true).Return(nil, tt.err)
+			handler := NewUserHandler(new(MockUserUseCase), NewUserPresenter(nil), logger.New()).WithSuggestions(suggestions, 10, 25)
+
+			w := httptest.NewRecorder()
+			handler.SuggestUsers(w, httptest.NewRequest(http.MethodGet, "/users/suggest", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// IncludeLoader loads a related resource of several users in one batch,
+// keyed by user ID. Users without the resource are absent from the result.
+type IncludeLoader func(ctx context.Context, userIDs []string) (map[string]interface{}, error)
+
+// userInclude is a related resource clients may embed in users
+type userInclude struct {
+	action string
+	load   IncludeLoader
+}
+
+// Include registers a related resource clients may embed in users with the
+// include query parameter. The resource is only embedded for users the
+// caller may perform action on. It returns p for chaining.
+func (p *UserPresenter) Include(name, action string, load IncludeLoader) *UserPresenter {
+	if p.includes == nil {
+		p.includes = make(map[string]userInclude)
+	}
+	p.includes[name] = userInclude{action: action, load: load}
+	return p
+}
+
+// unknownIncludesError is returned for an include parameter naming
+// resources that cannot be included
+type unknownIncludesError struct {
+	unknown []string
+	allowed []string
+}
+
+// Error implements the error interface
+func (e *unknownIncludesError) Error() string {
+	allowed := "none"
+	if len(e.allowed) > 0 {
+		allowed = strings.Join(e.allowed, ", ")
+	}
+	return fmt.Sprintf("unknown includes %s (allowed: %s)", strings.Join(e.unknown, ", "), allowed)
+}
+
+// parseIncludes reads the include query parameter, a comma-separated list
+// of registered related resources
+func (p *UserPresenter) parseIncludes(r *http.Request) ([]string, error) {
+	raw := r.URL.Query().Get("include")
+	if raw == "" {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	var names, unknown []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if _, ok := p.includes[name]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		names = append(names, name)
+	}
+	if len(unknown) > 0 {
+		allowed := make([]string, 0, len(p.includes))
+		for name := range p.includes {
+			allowed = append(allowed, name)
+		}
+		sort.Strings(allowed)
+		return nil, &unknownIncludesError{unknown: unknown, allowed: allowed}
+	}
+	return names, nil
+}
+
+// loadIncludes loads the named related resources of users, keyed by
+// include name and then user ID. Each include is loaded in one batch for
+// the users the caller is authorized for; the others are left out so their
+// users are returned without it. Authorized users without the resource map
+// to nil.
+func (p *UserPresenter) loadIncludes(ctx context.Context, users []*entities.User, names []string) (map[string]map[string]interface{}, error) {
+	included := make(map[string]map[string]interface{}, len(names))
+	for _, name := range names {
+		include := p.includes[name]
+
+		var ids []string
+		for _, user := range users {
+			if p.allowed(ctx, include.action, user) {
+				ids = append(ids, user.ID)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		loaded, err := include.load(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", name, err)
+		}
+		byUser := make(map[string]interface{}, len(ids))
+		for _, id := range ids {
+			byUser[id] = loaded[id]
+		}
+		included[name] = byUser
+	}
+	return included, nil
+}
+
+// userView is how a request asked for users to be presented
+type userView struct {
+	fields   fieldSelection
+	includes []string
+}
+
+// requestUserView parses the fields and include query parameters,
+// answering 400 Bad Request and returning false when either is invalid
+func (h *UserHandler) requestUserView(w http.ResponseWriter, r *http.Request) (userView, bool) {
+	fields, ok := requestFields(w, r, UserDTO{})
+	if !ok {
+		return userView{}, false
+	}
+	includes, err := h.presenter.parseIncludes(r)
+	if err != nil {
+		badRequest(w, r, "Invalid include parameter: "+err.Error())
+		return userView{}, false
+	}
+	return userView{fields: fields, includes: includes}, true
+}
+
+// presentUsers presents users as the view asks
+func (h *UserHandler) presentUsers(ctx context.Context, view userView, users []*entities.User) (interface{}, error) {
+	dtos := h.presenter.PresentList(ctx, users)
+	if len(view.includes) == 0 {
+		return view.fields.apply(dtos), nil
+	}
+
+	included, err := h.presenter.loadIncludes(ctx, users, view.includes)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := view.fields
+	if fields == nil {
+		fields = allFields(UserDTO{})
+	}
+	presented := make([]map[string]interface{}, len(dtos))
+	for i, dto := range dtos {
+		presented[i] = fields.selectStruct(reflect.ValueOf(dto))
+		for name, byUser := range included {
+			if related, ok := byUser[dto.ID]; ok {
+				presented[i][name] = related
+			}
+		}
+	}
+	return presented, nil
+}
+
+// presentUser presents a single user as the view asks
+func (h *UserHandler) presentUser(ctx context.Context, view userView, user *entities.User) (interface{}, error) {
+	if len(view.includes) == 0 {
+		return view.fields.apply(h.presenter.Present(ctx, user)), nil
+	}
+	presented, err := h.presentUsers(ctx, view, []*entities.User{user})
+	if err != nil {
+		return nil, err
+	}
+	return presented.([]map[string]interface{})[0], nil
+}
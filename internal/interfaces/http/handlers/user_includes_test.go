@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	policyinfra "clean-architecture/internal/infrastructure/policy"
+	"clean-architecture/pkg/logger"
+)
+
+// recordingLoader returns a deletion for every user but user_3 and records the
+// batches it was asked for
+type recordingLoader struct {
+	batches [][]string
+	err     error
+}
+
+func (l *recordingLoader) load(ctx context.Context, userIDs []string) (map[string]interface{}, error) {
+	l.batches = append(l.batches, userIDs)
+	if l.err != nil {
+		return nil, l.err
+	}
+	loaded := make(map[string]interface{})
+	for _, id := range userIDs {
+		if id != "user_3" {
+			loaded[id] = map[string]interface{}{"status": "pending"}
+		}
+	}
+	return loaded, nil
+}
+
+func TestUserPresenter_ParseIncludes(t *testing.T) {
+	loader := &recordingLoader{}
+	presenter := NewUserPresenter(nil).Include("deletion", ActionReadUserDeletion, loader.load)
+
+	names, err := presenter.parseIncludes(httptest.NewRequest(http.MethodGet, "/users?include=deletion,%20deletion", nil))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deletion"}, names)
+
+	_, err = presenter.parseIncludes(httptest.NewRequest(http.MethodGet, "/users?include=profile,organizations", nil))
+	assert.EqualError(t, err, "unknown includes profile, organizations (allowed: deletion)")
+
+	_, err = NewUserPresenter(nil).parseIncludes(httptest.NewRequest(http.MethodGet, "/users?include=deletion", nil))
+	assert.EqualError(t, err, "unknown includes deletion (allowed: none)")
+}
+
+func TestUserHandler_ListUsers_Include(t *testing.T) {
+	users := []*entities.User{
+		{ID: "user_1", Email: "user1@example.com", Name: "User 1"},
+		{ID: "user_2", Email: "user2@example.com", Name: "User 2"},
+		{ID: "user_3", Email: "user3@example.com", Name: "User 3"},
+	}
+	engine := policyinfra.NewRoleEngine(policyinfra.DefaultRules())
+
+	tests := []struct {
+		name            string
+		subject         policy.Subject
+		query           string
+		expectedBatches [][]string
+		expected        []map[string]interface{}
+	}{
+		{
+			name:            "admin sees every deletion",
+			subject:         policy.Subject{ID: "admin_1", Roles: []string{"admin"}},
+			query:           "?include=deletion&fields=id",
+			expectedBatches: [][]string{{"user_1", "user_2", "user_3"}},
+			expected: []map[string]interface{}{
+				{"id": "user_1", "deletion": map[string]interface{}{"status": "pending"}},
+				{"id": "user_2", "deletion": map[string]interface{}{"status": "pending"}},
+				{"id": "user_3", "deletion": nil},
+			},
+		},
+		{
+			name:            "user only sees own deletion",
+			subject:         policy.Subject{ID: "user_2", Roles: []string{"user"}},
+			query:           "?include=deletion&fields=id",
+			expectedBatches: [][]string{{"user_2"}},
+			expected: []map[string]interface{}{
+				{"id": "user_1"},
+				{"id": "user_2", "deletion": map[string]interface{}{"status": "pending"}},
+				{"id": "user_3"},
+			},
+		},
+		{
+			name:            "unauthorized users are not loaded",
+			subject:         policy.Subject{ID: "user_9", Roles: []string{"user"}},
+			query:           "?include=deletion&fields=id",
+			expectedBatches: nil,
+			expected: []map[string]interface{}{
+				{"id": "user_1"},
+				{"id": "user_2"},
+				{"id": "user_3"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := &recordingLoader{}
+			presenter := NewUserPresenter(engine).Include("deletion", ActionReadUserDeletion, loader.load)
+			mockUseCase := new(MockUserUseCase)
+			mockUseCase.On("ListUsers", mock.Anything, 10, 0).Return(users, nil)
+			handler := NewUserHandler(mockUseCase, presenter, logger.New())
+
+			req := httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil)
+			req = req.WithContext(policy.WithSubject(req.Context(), tt.subject))
+			w := httptest.NewRecorder()
+			handler.ListUsers(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response struct {
+				Data []map[string]interface{} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expected, response.Data)
+			assert.Equal(t, tt.expectedBatches, loader.batches)
+		})
+	}
+}
+
+func TestUserHandler_GetUser_Include(t *testing.T) {
+	loader := &recordingLoader{}
+	presenter := NewUserPresenter(nil).Include("deletion", ActionReadUserDeletion, loader.load)
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("GetUserByID", mock.Anything, "user_1").
+		Return(&entities.User{ID: "user_1", Email: "user1@example.com", Name: "User 1"}, nil)
+	handler := NewUserHandler(mockUseCase, presenter, logger.New())
+
+	req := httptest.NewRequest(http.MethodGet, "/users/user_1?include=deletion", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "user_1")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+	handler.GetUser(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "user1@example.com", response.Data["email"])
+	assert.Equal(t, map[string]interface{}{"status": "pending"}, response.Data["deletion"])
+}
+
+func TestUserHandler_ListUsers_IncludeErrors(t *testing.T) {
+	loader := &recordingLoader{err: assert.AnError}
+	presenter := NewUserPresenter(nil).Include("deletion", ActionReadUserDeletion, loader.load)
+	mockUseCase := new(MockUserUseCase)
+	mockUseCase.On("ListUsers", mock.Anything, 10, 0).Return([]*entities.User{{ID: "user_1"}}, nil)
+	handler := NewUserHandler(mockUseCase, presenter, logger.New())
+
+	w := httptest.NewRecorder()
+	handler.ListUsers(w, httptest.NewRequest(http.MethodGet, "/users?include=profile", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUseCase.AssertNotCalled(t, "ListUsers", mock.Anything, mock.Anything, mock.Anything)
+
+	w = httptest.NewRecorder()
+	handler.ListUsers(w, httptest.NewRequest(http.MethodGet, "/users?include=deletion", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), assert.AnError.Error())
+}
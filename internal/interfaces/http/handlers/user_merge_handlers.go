@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/utils"
+)
+
+// UserMergeHandler handles operators finding duplicate users and merging
+// them on the admin listener
+type UserMergeHandler struct {
+	mergeUseCase usecase.UserMergeUseCaseInterface
+	defaults     APIDefaults
+	logger       logger.Logger
+}
+
+// NewUserMergeHandler creates a new user merge handler
+func NewUserMergeHandler(mergeUseCase usecase.UserMergeUseCaseInterface, logger logger.Logger) *UserMergeHandler {
+	return &UserMergeHandler{
+		mergeUseCase: mergeUseCase,
+		defaults:     DefaultAPIDefaults(),
+		logger:       logger,
+	}
+}
+
+// WithDefaults replaces the page size of duplicate listings
+func (h *UserMergeHandler) WithDefaults(defaults APIDefaults) *UserMergeHandler {
+	h.defaults = defaults
+	return h
+}
+
+// DuplicateUsersDTO is the API representation of a pair of probable
+// duplicate users, the older one first, with their name similarity to
+// three decimals
+type DuplicateUsersDTO struct {
+	Domain string             `json:"domain"`
+	Score  float64            `json:"score"`
+	Users  []DuplicateUserDTO `json:"users"`
+}
+
+// DuplicateUserDTO is a user of a duplicate pair. The admin listener is
+// internal, so emails are not masked.
+type DuplicateUserDTO struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MergeUsersRequest represents the request body for merging two users
+type MergeUsersRequest struct {
+	WinnerID string `json:"winner_id"`
+	LoserID  string `json:"loser_id"`
+}
+
+// UserMergeDTO is the API representation of a completed merge
+type UserMergeDTO struct {
+	WinnerID               string    `json:"winner_id"`
+	LoserID                string    `json:"loser_id"`
+	MergedAt               time.Time `json:"merged_at"`
+	SavedViews             int64     `json:"saved_views"`
+	CancelledDeletions     int64     `json:"cancelled_deletions"`
+	SupersededEmailChanges int64     `json:"superseded_email_changes"`
+}
+
+// FindDuplicates handles listing probable duplicate users, most similar
+// first. The optional threshold query parameter overrides the configured
+// similarity.
+func (h *UserMergeHandler) FindDuplicates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, offset, warnings := pagination(query, h.defaults.AdminPageSize, 0)
+
+	var threshold float64
+	if value := query.Get("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			writeError(w, r, h.logger, usecase.ErrInvalidDuplicateThreshold)
+			return
+		}
+		threshold = parsed
+	}
+
+	duplicates, err := h.mergeUseCase.FindDuplicates(r.Context(), threshold)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	page := duplicates[min(offset, len(duplicates)):]
+	page = page[:min(limit, len(page))]
+	dtos := make([]DuplicateUsersDTO, 0, len(page))
+	for _, duplicate := range page {
+		dtos = append(dtos, presentDuplicateUsers(duplicate))
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Duplicate users retrieved successfully",
+		Data:      dtos,
+		Warnings:  warnings,
+		Meta:      &ResponseMeta{Pagination: utils.NewPagination(limit, offset, len(dtos)).WithTotal(len(duplicates))},
+		Timestamp: time.Now(),
+	})
+}
+
+// MergeUsers handles merging the loser into the winner
+func (h *UserMergeHandler) MergeUsers(w http.ResponseWriter, r *http.Request) {
+	var req MergeUsersRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	merge, err := h.mergeUseCase.Merge(r.Context(), req.WinnerID, req.LoserID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Users merged",
+		Data:      presentUserMerge(merge),
+		Timestamp: time.Now(),
+	})
+}
+
+func presentDuplicateUsers(duplicate *usecase.DuplicateUsers) DuplicateUsersDTO {
+	users := make([]DuplicateUserDTO, 0, len(duplicate.Users))
+	for _, user := range duplicate.Users {
+		users = append(users, DuplicateUserDTO{
+			ID:        user.ID,
+			Email:     user.Email,
+			Name:      user.Name,
+			CreatedAt: user.CreatedAt,
+		})
+	}
+	return DuplicateUsersDTO{
+		Domain: duplicate.Domain,
+		Score:  math.Round(duplicate.Score*1000) / 1000,
+		Users:  users,
+	}
+}
+
+func presentUserMerge(merge *entities.UserMerge) UserMergeDTO {
+	return UserMergeDTO{
+		WinnerID:               merge.WinnerID,
+		LoserID:                merge.LoserID,
+		MergedAt:               merge.MergedAt,
+		SavedViews:             merge.SavedViews,
+		CancelledDeletions:     merge.CancelledDeletions,
+		SupersededEmailChanges: merge.SupersededEmailChanges,
+	}
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockUserMergeUseCase is a mock implementation of UserMergeUseCaseInterface
+type MockUserMergeUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUserMergeUseCase) FindDuplicates(ctx context.Context, threshold float64) ([]*usecase.DuplicateUsers, error) {
+	args := m.Called(ctx, threshold)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*usecase.DuplicateUsers), args.Error(1)
+}
+
+func (m *MockUserMergeUseCase) Merge(ctx context.Context, winnerID, loserID string) (*entities.UserMerge, error) {
+	args := m.Called(ctx, winnerID, loserID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.UserMerge), args.Error(1)
+}
+
+func newUserMergeRouter(uc usecase.UserMergeUseCaseInterface) http.Handler {
+	handler := NewUserMergeHandler(uc, logger.New()).WithDefaults(APIDefaults{AdminPageSize: 2})
+	r := chi.NewRouter()
+	r.Get("/users/duplicates", handler.FindDuplicates)
+	r.Post("/users/merge", handler.MergeUsers)
+	return r
+}
+
+func testDuplicateUsers(score float64, names ...string) *usecase.DuplicateUsers {
+	duplicate := &usecase.DuplicateUsers{Domain: "example.com", Score: score}
+	for i, name := range names {
+		duplicate.Users[i] = &entities.User{ID: "user_" + name, Email: name + "@example.com", Name: name}
+	}
+	return duplicate
+}
+
+func TestUserMergeHandler_FindDuplicates(t *testing.T) {
+	mockUseCase := new(MockUserMergeUseCase)
+	mockUseCase.On("FindDuplicates", mock.Anything, 0.0).Return([]*usecase.DuplicateUsers{
+		testDuplicateUsers(1, "ada", "ada2"),
+		testDuplicateUsers(0.96111, "grace", "grace2"),
+		testDuplicateUsers(0.95, "linus", "linus2"),
+	}, nil)
+	mockUseCase.On("FindDuplicates", mock.Anything, 0.8).Return([]*usecase.DuplicateUsers{}, nil)
+	router := newUserMergeRouter(mockUseCase)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/duplicates", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"email":"ada@example.com"`, "emails are not masked on the admin listener")
+	assert.Contains(t, w.Body.String(), `"score":0.961`)
+	assert.NotContains(t, w.Body.String(), "linus", "the admin page size applies")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/duplicates?offset=2", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "linus")
+	assert.NotContains(t, w.Body.String(), "grace")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/users/duplicates?offset=10&threshold=0.8", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"data":[]`)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestUserMergeHandler_FindDuplicates_InvalidThreshold(t *testing.T) {
+	mockUseCase := new(MockUserMergeUseCase)
+	mockUseCase.On("FindDuplicates", mock.Anything, 2.0).Return(nil, usecase.ErrInvalidDuplicateThreshold)
+	router := newUserMergeRouter(mockUseCase)
+
+	for _, threshold := range []string{"high", "2"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/users/duplicates?threshold="+threshold, nil))
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, threshold)
+		assert.Contains(t, w.Body.String(), usecase.ErrInvalidDuplicateThreshold.Error())
+	}
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestUserMergeHandler_MergeUsers(t *testing.T) {
+	merge := &entities.UserMerge{
+		WinnerID:           "user_1",
+		LoserID:            "user_2",
+		MergedAt:           time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		SavedViews:         3,
+		CancelledDeletions: 1,
+	}
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockUserMergeUseCase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "merged",
+			body: `{"winner_id":"user_1","loser_id":"user_2"}`,
+			setupMock: func(m *MockUserMergeUseCase) {
+				m.On("Merge", mock.Anything, "user_1", "user_2").Return(merge, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"saved_views":3`,
+		},
+		{
+			name: "same user",
+			body: `{"winner_id":"user_1","loser_id":"user_1"}`,
+			setupMock: func(m *MockUserMergeUseCase) {
+				m.On("Merge", mock.Anything, "user_1", "user_1").Return(nil, usecase.ErrMergeSameUser)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   usecase.ErrMergeSameUser.Error(),
+		},
+		{
+			name: "unknown user",
+			body: `{"winner_id":"user_1","loser_id":"user_9"}`,
+			setupMock: func(m *MockUserMergeUseCase) {
+				m.On("Merge", mock.Anything, "user_1", "user_9").Return(nil, repositories.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   repositories.ErrUserNotFound.Error(),
+		},
+		{
+			name:           "malformed body",
+			body:           `{"winner_id":`,
+			setupMock:      func(m *MockUserMergeUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUserMergeUseCase)
+			tt.setupMock(mockUseCase)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/users/merge", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			newUserMergeRouter(mockUseCase).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
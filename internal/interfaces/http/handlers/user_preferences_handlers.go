@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// UserPreferencesHandler handles users choosing how their responses are
+// localized
+type UserPreferencesHandler struct {
+	preferencesUseCase usecase.UserPreferencesUseCaseInterface
+	logger             logger.Logger
+}
+
+// NewUserPreferencesHandler creates a new user preferences handler
+func NewUserPreferencesHandler(preferencesUseCase usecase.UserPreferencesUseCaseInterface, logger logger.Logger) *UserPreferencesHandler {
+	return &UserPreferencesHandler{
+		preferencesUseCase: preferencesUseCase,
+		logger:             logger,
+	}
+}
+
+// UserPreferencesDTO is the localization of the current user's responses.
+// Requests override it with the Accept-Language and Time-Zone headers.
+type UserPreferencesDTO struct {
+	// Locale is a language tag such as de-AT; empty follows Accept-Language
+	Locale string `json:"locale"`
+	// TimeZone is an IANA time zone such as Europe/Berlin; empty shows
+	// times in UTC
+	TimeZone string `json:"time_zone"`
+}
+
+// GetPreferences godoc
+// @Summary      Get the current user's preferences
+// @Description  Return the locale messages and the time zone timestamps of the authenticated user's responses are localized with
+// @Tags         users
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/me/preferences [get]
+func (h *UserPreferencesHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	subject, ok := requireSubject(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.preferencesUseCase.Get(r.Context(), subject.ID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Preferences retrieved successfully",
+		Data:      UserPreferencesDTO(prefs),
+		Timestamp: time.Now(),
+	})
+}
+
+// UpdatePreferences godoc
+// @Summary      Update the current user's preferences
+// @Description  Replace the locale and time zone the authenticated user's responses are localized with. Empty values clear a preference; the Accept-Language and Time-Zone headers of a request take precedence over the time zone and fill in for a missing locale. Other instances may serve the previous preferences for up to USERS_PREFERENCES_CACHE_TTL.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        request  body      UserPreferencesDTO  true  "Preferences"
+// @Success      200      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api/v1/me/preferences [put]
+func (h *UserPreferencesHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	subject, ok := requireSubject(w, r)
+	if !ok {
+		return
+	}
+	var req UserPreferencesDTO
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	prefs, err := h.preferencesUseCase.Update(r.Context(), subject.ID, entities.UserPreferences(req))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Preferences updated successfully",
+		Data:      UserPreferencesDTO(prefs),
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockUserPreferencesUseCase is a mock implementation of
+// UserPreferencesUseCaseInterface
+type MockUserPreferencesUseCase struct {
+	mock.Mock
+}
+
+func (m *MockUserPreferencesUseCase) Get(ctx context.Context, userID string) (entities.UserPreferences, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(entities.UserPreferences), args.Error(1)
+}
+
+func (m *MockUserPreferencesUseCase) Update(ctx context.Context, userID string, prefs entities.UserPreferences) (entities.UserPreferences, error) {
+	args := m.Called(ctx, userID, prefs)
+	return args.Get(0).(entities.UserPreferences), args.Error(1)
+}
+
+func newUserPreferencesRouter(uc usecase.UserPreferencesUseCaseInterface) http.Handler {
+	handler := NewUserPreferencesHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Get("/me/preferences", handler.GetPreferences)
+	r.Put("/me/preferences", handler.UpdatePreferences)
+	return r
+}
+
+func TestUserPreferencesHandler_GetPreferences(t *testing.T) {
+	mockUseCase := new(MockUserPreferencesUseCase)
+	mockUseCase.On("Get", mock.Anything, "user_1").Return(entities.UserPreferences{Locale: "de", TimeZone: "Europe/Berlin"}, nil)
+
+	w := httptest.NewRecorder()
+	newUserPreferencesRouter(mockUseCase).ServeHTTP(w, withSubject(httptest.NewRequest("GET", "/me/preferences", nil), "user_1"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data UserPreferencesDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, UserPreferencesDTO{Locale: "de", TimeZone: "Europe/Berlin"}, body.Data)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestUserPreferencesHandler_UpdatePreferences(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockUserPreferencesUseCase)
+		expectedStatus int
+		expectedData   *UserPreferencesDTO
+	}{
+		{
+			name: "updates the preferences",
+			body: `{"locale":"de_at","time_zone":"Europe/Vienna"}`,
+			setupMock: func(m *MockUserPreferencesUseCase) {
+				m.On("Update", mock.Anything, "user_1", entities.UserPreferences{Locale: "de_at", TimeZone: "Europe/Vienna"}).
+					Return(entities.UserPreferences{Locale: "de-AT", TimeZone: "Europe/Vienna"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedData:   &UserPreferencesDTO{Locale: "de-AT", TimeZone: "Europe/Vienna"},
+		},
+		{
+			name: "invalid time zone",
+			body: `{"time_zone":"Mars/Olympus_Mons"}`,
+			setupMock: func(m *MockUserPreferencesUseCase) {
+				m.On("Update", mock.Anything, "user_1", entities.UserPreferences{TimeZone: "Mars/Olympus_Mons"}).
+					Return(entities.UserPreferences{}, usecase.ErrInvalidTimeZone)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "unknown user",
+			body: `{}`,
+			setupMock: func(m *MockUserPreferencesUseCase) {
+				m.On("Update", mock.Anything, "user_1", entities.UserPreferences{}).
+					Return(entities.UserPreferences{}, repositories.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "malformed body",
+			body:           `{"locale":`,
+			setupMock:      func(m *MockUserPreferencesUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockUserPreferencesUseCase)
+			tt.setupMock(mockUseCase)
+
+			req := withSubject(httptest.NewRequest("PUT", "/me/preferences", strings.NewReader(tt.body)), "user_1")
+			w := httptest.NewRecorder()
+			newUserPreferencesRouter(mockUseCase).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedData != nil {
+				var body struct {
+					Data UserPreferencesDTO `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, *tt.expectedData, body.Data)
+			}
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
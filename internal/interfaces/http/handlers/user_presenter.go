@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+)
+
+// Policy actions guarding parts of a user resource
+const (
+	// ActionReadUserEmail guards a user's email address
+	ActionReadUserEmail = "users:read_email"
+	// ActionReadUserDeletion guards a user's pending deletion request
+	ActionReadUserDeletion = "users:read_deletion"
+)
+
+// UserDTO represents a user as returned by the API
+type UserDTO struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserPresenter converts user entities into response DTOs, masking fields
+// the caller is not permitted to see
+type UserPresenter struct {
+	engine   policy.Engine
+	includes map[string]userInclude
+}
+
+// NewUserPresenter creates a new user presenter. When engine is nil, no
+// fields are masked.
+func NewUserPresenter(engine policy.Engine) *UserPresenter {
+	return &UserPresenter{engine: engine}
+}
+
+// Present converts a single user into a DTO
+func (p *UserPresenter) Present(ctx context.Context, user *entities.User) UserDTO {
+	dto := UserDTO{
+		ID:        user.ID,
+		Email:     user.Email,
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+
+	if !p.canReadEmail(ctx, user) {
+		dto.Email = maskEmail(user.Email)
+	}
+
+	return dto
+}
+
+// PresentList converts a list of users into DTOs
+func (p *UserPresenter) PresentList(ctx context.Context, users []*entities.User) []UserDTO {
+	dtos := make([]UserDTO, 0, len(users))
+	for _, user := range users {
+		dtos = append(dtos, p.Present(ctx, user))
+	}
+	return dtos
+}
+
+// canReadEmail asks the policy engine whether the caller may see the email
+func (p *UserPresenter) canReadEmail(ctx context.Context, user *entities.User) bool {
+	return p.allowed(ctx, ActionReadUserEmail, user)
+}
+
+// CanReadEveryEmail asks the policy engine whether the caller may see the
+// email of any user, not only their own. Evaluation errors fail closed.
+func (p *UserPresenter) CanReadEveryEmail(ctx context.Context) bool {
+	if p.engine == nil {
+		return true
+	}
+
+	subject, _ := policy.SubjectFromContext(ctx)
+	decision, err := p.engine.Evaluate(ctx, policy.Input{
+		Subject:  subject,
+		Action:   ActionReadUserEmail,
+		Resource: policy.Resource{Type: "user"},
+	})
+	if err != nil {
+		return false
+	}
+	return decision.Allowed
+}
+
+// allowed asks the policy engine whether the caller may perform action on
+// user. Evaluation errors fail closed.
+func (p *UserPresenter) allowed(ctx context.Context, action string, user *entities.User) bool {
+	if p.engine == nil {
+		return true
+	}
+
+	subject, _ := policy.SubjectFromContext(ctx)
+	decision, err := p.engine.Evaluate(ctx, policy.Input{
+		Subject:  subject,
+		Action:   action,
+		Resource: policy.Resource{Type: "user", ID: user.ID, OwnerID: user.ID},
+	})
+	if err != nil {
+		return false
+	}
+	return decision.Allowed
+}
+
+// maskEmail redacts the local part of an email, keeping its first character
+// and the domain, e.g. "jane@example.com" becomes "j***@example.com"
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return strings.Repeat("*", len(email))
+	}
+
+	local := []rune(email[:at])
+	return string(local[0]) + strings.Repeat("*", len(local)-1) + email[at:]
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	policyinfra "clean-architecture/internal/infrastructure/policy"
+)
+
+func TestMaskEmail(t *testing.T) {
+	tests := []struct {
+		email    string
+		expected string
+	}{
+		{email: "jane@example.com", expected: "j***@example.com"},
+		{email: "j@example.com", expected: "j@example.com"},
+		{email: "not-an-email", expected: "************"},
+		{email: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			assert.Equal(t, tt.expected, maskEmail(tt.email))
+		})
+	}
+}
+
+func TestUserPresenter_Present(t *testing.T) {
+	user := &entities.User{ID: "user_1", Email: "jane@example.com", Name: "Jane"}
+	engine := policyinfra.NewRoleEngine(policyinfra.DefaultRules())
+
+	tests := []struct {
+		name          string
+		engine        policy.Engine
+		subject       *policy.Subject
+		expectedEmail string
+	}{
+		{
+			name:          "no policy engine",
+			engine:        nil,
+			expectedEmail: "jane@example.com",
+		},
+		{
+			name:          "admin sees email",
+			engine:        engine,
+			subject:       &policy.Subject{ID: "admin_1", Roles: []string{"admin"}},
+			expectedEmail: "jane@example.com",
+		},
+		{
+			name:          "owner sees own email",
+			engine:        engine,
+			subject:       &policy.Subject{ID: "user_1", Roles: []string{"user"}},
+			expectedEmail: "jane@example.com",
+		},
+		{
+			name:          "other user sees masked email",
+			engine:        engine,
+			subject:       &policy.Subject{ID: "user_2", Roles: []string{"user"}},
+			expectedEmail: "j***@example.com",
+		},
+		{
+			name:          "anonymous sees masked email",
+			engine:        engine,
+			expectedEmail: "j***@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.subject != nil {
+				ctx = policy.WithSubject(ctx, *tt.subject)
+			}
+
+			dto := NewUserPresenter(tt.engine).Present(ctx, user)
+
+			assert.Equal(t, user.ID, dto.ID)
+			assert.Equal(t, user.Name, dto.Name)
+			assert.Equal(t, tt.expectedEmail, dto.Email)
+		})
+	}
+}
+
+func TestUserPresenter_PresentList(t *testing.T) {
+	users := []*entities.User{
+		{ID: "user_1", Email: "one@example.com"},
+		{ID: "user_2", Email: "two@example.com"},
+	}
+	presenter := NewUserPresenter(policyinfra.NewRoleEngine(policyinfra.DefaultRules()))
+	ctx := policy.WithSubject(context.Background(), policy.Subject{ID: "user_1", Roles: []string{"user"}})
+
+	dtos := presenter.PresentList(ctx, users)
+
+	assert.Len(t, dtos, 2)
+	assert.Equal(t, "one@example.com", dtos[0].Email)
+	assert.Equal(t, "t**@example.com", dtos[1].Email)
+}
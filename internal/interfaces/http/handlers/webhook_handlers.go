@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// WebhookHandler handles administrators registering webhooks and
+// inspecting their deliveries
+type WebhookHandler struct {
+	webhookUseCase usecase.WebhookUseCaseInterface
+	defaults       APIDefaults
+	logger         logger.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookUseCase usecase.WebhookUseCaseInterface, logger logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUseCase: webhookUseCase,
+		defaults:       DefaultAPIDefaults(),
+		logger:         logger,
+	}
+}
+
+// WithDefaults replaces the page sizes deliveries are listed with
+func (h *WebhookHandler) WithDefaults(defaults APIDefaults) *WebhookHandler {
+	h.defaults = defaults
+	return h
+}
+
+// WebhookDTO is the API representation of a webhook
+type WebhookDTO struct {
+	ID     string   `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+	// Secret authenticates deliveries; it is only returned on creation
+	Secret    string    `json:"secret,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDeliveryDTO is the API representation of a webhook delivery
+type WebhookDeliveryDTO struct {
+	ID            string                    `json:"id"`
+	EventID       string                    `json:"event_id"`
+	EventType     string                    `json:"event_type"`
+	Status        string                    `json:"status"`
+	Attempts      []entities.WebhookAttempt `json:"attempts"`
+	NextAttemptAt *time.Time                `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	DeliveredAt   *time.Time                `json:"delivered_at,omitempty"`
+}
+
+// CreateWebhookRequest represents the request body for registering a
+// webhook. Leaving events empty subscribes to every event.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+}
+
+// UpdateWebhookRequest represents the request body for changing a
+// webhook; omitted fields are kept
+type UpdateWebhookRequest struct {
+	URL    *string   `json:"url,omitempty"`
+	Events *[]string `json:"events,omitempty"`
+	Active *bool     `json:"active,omitempty"`
+}
+
+// CreateWebhook godoc
+// @Summary      Register a webhook
+// @Description  Register a URL that domain events are posted to. The response carries the secret that authenticates deliveries; it is not shown again.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        webhook  body      CreateWebhookRequest  true  "Webhook definition"
+// @Success      201      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api/v1/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	webhook, err := h.webhookUseCase.CreateWebhook(r.Context(), req.URL, req.Events, request.Current(r.Context()).UserID)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dto := presentWebhook(webhook)
+	dto.Secret = webhook.Secret
+	render.Status(r, http.StatusCreated)
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Webhook created successfully",
+		Data:      dto,
+		Timestamp: time.Now(),
+	})
+}
+
+// ListWebhooks godoc
+// @Summary      List webhooks
+// @Description  List every registered webhook, oldest first
+// @Tags         webhooks
+// @Produce      json
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.webhookUseCase.ListWebhooks(r.Context())
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]WebhookDTO, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		dtos = append(dtos, presentWebhook(webhook))
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Webhooks retrieved successfully",
+		Data:      dtos,
+		Timestamp: time.Now(),
+	})
+}
+
+// GetWebhook godoc
+// @Summary      Get a webhook
+// @Description  Get a registered webhook
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      string  true  "Webhook ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, err := h.webhookUseCase.GetWebhook(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Webhook retrieved successfully",
+		Data:      presentWebhook(webhook),
+		Timestamp: time.Now(),
+	})
+}
+
+// UpdateWebhook godoc
+// @Summary      Update a webhook
+// @Description  Change the URL or events of a webhook, or deactivate it. Pending deliveries of a deactivated webhook fail instead of being retried.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string                true  "Webhook ID"
+// @Param        webhook  body      UpdateWebhookRequest  true  "Fields to change"
+// @Success      200      {object}  SuccessResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      401      {object}  ErrorResponse
+// @Failure      403      {object}  ErrorResponse
+// @Failure      404      {object}  ErrorResponse
+// @Failure      422      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router       /api/v1/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req UpdateWebhookRequest
+	if err := decodeJSON(r, &req); err != nil {
+		malformedBody(w, r, err)
+		return
+	}
+
+	webhook, err := h.webhookUseCase.UpdateWebhook(r.Context(), chi.URLParam(r, "id"), usecase.WebhookUpdate{
+		URL:    req.URL,
+		Events: req.Events,
+		Active: req.Active,
+	})
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Webhook updated successfully",
+		Data:      presentWebhook(webhook),
+		Timestamp: time.Now(),
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete a webhook
+// @Description  Delete a webhook and its delivery log
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      string  true  "Webhook ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := h.webhookUseCase.DeleteWebhook(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Webhook deleted successfully",
+		Timestamp: time.Now(),
+	})
+}
+
+// ListDeliveries godoc
+// @Summary      List webhook deliveries
+// @Description  List the deliveries of a webhook with every attempt made, newest first
+// @Tags         webhooks
+// @Produce      json
+// @Param        id      path      string  true   "Webhook ID"
+// @Param        limit   query     int     false  "Page size"
+// @Param        offset  query     int     false  "Page offset"
+// @Success      200     {object}  SuccessResponse
+// @Failure      401     {object}  ErrorResponse
+// @Failure      403     {object}  ErrorResponse
+// @Failure      404     {object}  ErrorResponse
+// @Failure      500     {object}  ErrorResponse
+// @Router       /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit, offset, warnings := pagination(r.URL.Query(), h.defaults.PageSize, h.defaults.MaxPageSize)
+
+	deliveries, err := h.webhookUseCase.ListDeliveries(r.Context(), chi.URLParam(r, "id"), limit, offset)
+	if err != nil {
+		writeError(w, r, h.logger, err)
+		return
+	}
+
+	dtos := make([]WebhookDeliveryDTO, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		dtos = append(dtos, presentWebhookDelivery(delivery))
+	}
+	respondJSON(w, r, Response{
+		Status:    "success",
+		Message:   "Webhook deliveries retrieved successfully",
+		Data:      dtos,
+		Warnings:  warnings,
+		Meta:      listMeta(limit, offset, len(dtos)),
+		Timestamp: time.Now(),
+	})
+}
+
+func presentWebhook(webhook *entities.Webhook) WebhookDTO {
+	eventTypes := webhook.Events
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return WebhookDTO{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    eventTypes,
+		Active:    webhook.Active,
+		CreatedBy: webhook.CreatedBy,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+	}
+}
+
+func presentWebhookDelivery(delivery *entities.WebhookDelivery) WebhookDeliveryDTO {
+	attempts := delivery.Attempts
+	if attempts == nil {
+		attempts = []entities.WebhookAttempt{}
+	}
+	return WebhookDeliveryDTO{
+		ID:            delivery.ID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Status:        delivery.Status,
+		Attempts:      attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+		DeliveredAt:   delivery.DeliveredAt,
+	}
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/usecase"
+	"clean-architecture/pkg/logger"
+)
+
+// MockWebhookUseCase is a mock implementation of WebhookUseCaseInterface
+type MockWebhookUseCase struct {
+	mock.Mock
+}
+
+func (m *MockWebhookUseCase) CreateWebhook(ctx context.Context, url string, events []string, createdBy string) (*entities.Webhook, error) {
+	args := m.Called(ctx, url, events, createdBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) GetWebhook(ctx context.Context, id string) (*entities.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) ListWebhooks(ctx context.Context) ([]*entities.Webhook, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) UpdateWebhook(ctx context.Context, id string, update usecase.WebhookUpdate) (*entities.Webhook, error) {
+	args := m.Called(ctx, id, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookUseCase) DeleteWebhook(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUseCase) ListDeliveries(ctx context.Context, id string, limit, offset int) ([]*entities.WebhookDelivery, error) {
+	args := m.Called(ctx, id, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.WebhookDelivery), args.Error(1)
+}
+
+func newWebhookRouter(uc usecase.WebhookUseCaseInterface) http.Handler {
+	handler := NewWebhookHandler(uc, logger.New())
+	r := chi.NewRouter()
+	r.Post("/webhooks", handler.CreateWebhook)
+	r.Get("/webhooks", handler.ListWebhooks)
+	r.Get("/webhooks/{id}", handler.GetWebhook)
+	r.Put("/webhooks/{id}", handler.UpdateWebhook)
+	r.Delete("/webhooks/{id}", handler.DeleteWebhook)
+	r.Get("/webhooks/{id}/deliveries", handler.ListDeliveries)
+	return r
+}
+
+func testWebhook() *entities.Webhook {
+	webhook := entities.NewWebhook("https://example.com/hooks", "whsec_abc", []string{events.UserCreated}, "usr_admin")
+	webhook.ID = "whk_1"
+	return webhook
+}
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockWebhookUseCase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "created",
+			body: `{"url":"https://example.com/hooks","events":["user.created"]}`,
+			setupMock: func(m *MockWebhookUseCase) {
+				m.On("CreateWebhook", mock.Anything, "https://example.com/hooks", []string{events.UserCreated}, "usr_admin").
+					Return(testWebhook(), nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"secret":"whsec_abc"`,
+		},
+		{
+			name:           "malformed body",
+			body:           `{"url":`,
+			setupMock:      func(m *MockWebhookUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "invalid URL",
+			body: `{"url":"ftp://example.com"}`,
+			setupMock: func(m *MockWebhookUseCase) {
+				m.On("CreateWebhook", mock.Anything, "ftp://example.com", []string(nil), "usr_admin").
+					Return(nil, usecase.ErrInvalidWebhookURL)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   usecase.ErrInvalidWebhookURL.Error(),
+		},
+		{
+			name: "unknown event",
+			body: `{"url":"https://example.com/hooks","events":["user.renamed"]}`,
+			setupMock: func(m *MockWebhookUseCase) {
+				m.On("CreateWebhook", mock.Anything, "https://example.com/hooks", []string{"user.renamed"}, "usr_admin").
+					Return(nil, usecase.ErrUnknownEventType)
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "unknown event type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUseCase := new(MockWebhookUseCase)
+			tt.setupMock(mockUseCase)
+
+			req := httptest.NewRequest("POST", "/webhooks", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = withSubject(req, "usr_admin")
+			w := httptest.NewRecorder()
+			newWebhookRouter(mockUseCase).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookHandler_SecretOnlyOnCreation(t *testing.T) {
+	mockUseCase := new(MockWebhookUseCase)
+	mockUseCase.On("ListWebhooks", mock.Anything).Return([]*entities.Webhook{testWebhook()}, nil)
+	mockUseCase.On("GetWebhook", mock.Anything, "whk_1").Return(testWebhook(), nil)
+
+	for _, target := range []string{"/webhooks", "/webhooks/whk_1"} {
+		w := httptest.NewRecorder()
+		newWebhookRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code, target)
+		assert.Contains(t, w.Body.String(), `"url":"https://example.com/hooks"`, target)
+		assert.NotContains(t, w.Body.String(), "whsec_abc", target)
+	}
+}
+
+func TestWebhookHandler_UpdateWebhook(t *testing.T) {
+	mockUseCase := new(MockWebhookUseCase)
+	updated := testWebhook()
+	updated.Active = false
+	mockUseCase.On("UpdateWebhook", mock.Anything, "whk_1", mock.MatchedBy(func(update usecase.WebhookUpdate) bool {
+		return update.Active != nil && !*update.Active && update.URL == nil && update.Events == nil
+	})).Return(updated, nil)
+	mockUseCase.On("UpdateWebhook", mock.Anything, "whk_missing", mock.Anything).Return(nil, repositories.ErrWebhookNotFound)
+
+	req := httptest.NewRequest("PUT", "/webhooks/whk_1", bytes.NewBufferString(`{"active":false}`))
+	w := httptest.NewRecorder()
+	newWebhookRouter(mockUseCase).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"active":false`)
+
+	req = httptest.NewRequest("PUT", "/webhooks/whk_missing", bytes.NewBufferString(`{"active":false}`))
+	w = httptest.NewRecorder()
+	newWebhookRouter(mockUseCase).ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"webhook_not_found"`)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestWebhookHandler_DeleteWebhook(t *testing.T) {
+	mockUseCase := new(MockWebhookUseCase)
+	mockUseCase.On("DeleteWebhook", mock.Anything, "whk_1").Return(nil)
+
+	w := httptest.NewRecorder()
+	newWebhookRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("DELETE", "/webhooks/whk_1", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	delivery := entities.NewWebhookDelivery("whk_1", "evt_1", events.UserCreated, json.RawMessage(`{}`))
+	delivery.ID = "whd_1"
+	next := time.Now().Add(time.Minute)
+	delivery.Record(entities.WebhookAttempt{At: time.Now(), StatusCode: 503, Error: "webhook: 503 Service Unavailable", DurationMs: 12}, &next)
+
+	mockUseCase := new(MockWebhookUseCase)
+	mockUseCase.On("ListDeliveries", mock.Anything, "whk_1", 5, 10).Return([]*entities.WebhookDelivery{delivery}, nil)
+
+	w := httptest.NewRecorder()
+	newWebhookRouter(mockUseCase).ServeHTTP(w, httptest.NewRequest("GET", "/webhooks/whk_1/deliveries?limit=5&offset=10", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []WebhookDeliveryDTO `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, entities.WebhookDeliveryPending, response.Data[0].Status)
+	require.Len(t, response.Data[0].Attempts, 1)
+	assert.Equal(t, 503, response.Data[0].Attempts[0].StatusCode)
+	assert.NotNil(t, response.Data[0].NextAttemptAt)
+	mockUseCase.AssertExpectations(t)
+}
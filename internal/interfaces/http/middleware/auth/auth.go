@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/utils"
+)
+
+// APIKeyHeader carries the API key of service-to-service requests
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator verifies a bearer token or API key and returns its subject
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (policy.Subject, error)
+}
+
+// invalidTokenKey marks requests whose bearer token failed verification
+var invalidTokenKey = ctxkeys.NewKey[bool]("invalid_token")
+
+// invalidAPIKeyKey marks requests whose API key failed verification
+var invalidAPIKeyKey = ctxkeys.NewKey[bool]("invalid_api_key")
+
+// SessionAuthenticator verifies the cookie of a server-side session and
+// returns its subject
+type SessionAuthenticator interface {
+	AuthenticateSession(ctx context.Context, id string) (policy.Subject, error)
+}
+
+// invalidSessionKey marks requests whose session cookie failed verification
+var invalidSessionKey = ctxkeys.NewKey[bool]("invalid_session")
+
+// Authenticate creates a middleware that verifies the bearer token of the
+// Authorization header and stores its subject in the request context.
+// Requests without a valid token continue anonymously, so public routes
+// still work with a stale token; Require rejects them.
+func Authenticate(authenticator Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := BearerToken(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			subject, err := authenticator.Authenticate(ctx, token)
+			if err != nil {
+				next.ServeHTTP(w, r.WithContext(invalidTokenKey.With(ctx, true)))
+				return
+			}
+
+			ctx = policy.WithSubject(ctx, subject)
+			ctx = ctxkeys.UserID.With(ctx, subject.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// AuthenticateAPIKey creates a middleware that verifies the API key of the
+// X-API-Key header and stores its subject in the request context. Like
+// Authenticate, it lets requests without a valid key continue anonymously.
+// Requests a bearer token already authenticated keep their subject.
+func AuthenticateAPIKey(authenticator Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+			if _, authenticated := policy.SubjectFromContext(ctx); authenticated || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			subject, err := authenticator.Authenticate(ctx, key)
+			if err != nil {
+				next.ServeHTTP(w, r.WithContext(invalidAPIKeyKey.With(ctx, true)))
+				return
+			}
+
+			ctx = policy.WithSubject(ctx, subject)
+			ctx = ctxkeys.UserID.With(ctx, subject.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// AuthenticateSession creates a middleware that verifies the session
+// cookie named cookieName and stores its subject in the request context.
+// Like Authenticate, it lets requests without a valid session continue
+// anonymously. Requests a bearer token already authenticated keep their
+// subject.
+func AuthenticateSession(sessions SessionAuthenticator, cookieName string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			cookie, err := r.Cookie(cookieName)
+			if _, authenticated := policy.SubjectFromContext(ctx); authenticated || err != nil || cookie.Value == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			subject, err := sessions.AuthenticateSession(ctx, cookie.Value)
+			if err != nil {
+				next.ServeHTTP(w, r.WithContext(invalidSessionKey.With(ctx, true)))
+				return
+			}
+
+			ctx = policy.WithSubject(ctx, subject)
+			ctx = ctxkeys.UserID.With(ctx, subject.ID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Require rejects requests without an authenticated subject with 401
+// Unauthorized and an RFC 6750 WWW-Authenticate header
+func Require(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := policy.SubjectFromContext(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if invalidTokenKey.Value(r.Context()) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token", error_description="The access token is invalid or expired"`)
+			utils.WriteErrorFor(w, r, http.StatusUnauthorized, "Invalid or expired access token")
+			return
+		}
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		if invalidAPIKeyKey.Value(r.Context()) {
+			utils.WriteErrorFor(w, r, http.StatusUnauthorized, "Invalid, expired or revoked API key")
+			return
+		}
+		if invalidSessionKey.Value(r.Context()) {
+			utils.WriteErrorFor(w, r, http.StatusUnauthorized, "Session is invalid or expired; log in again")
+			return
+		}
+		utils.WriteErrorFor(w, r, http.StatusUnauthorized, "Authentication required")
+	})
+}
+
+// BearerToken returns the token of an "Authorization: Bearer" header
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/pkg/ctxkeys"
+)
+
+type authenticatorFunc func(ctx context.Context, token string) (policy.Subject, error)
+
+func (f authenticatorFunc) Authenticate(ctx context.Context, token string) (policy.Subject, error) {
+	return f(ctx, token)
+}
+
+var testAuthenticator = authenticatorFunc(func(ctx context.Context, token string) (policy.Subject, error) {
+	if token != "valid-token" {
+		return policy.Subject{}, errors.New("invalid token")
+	}
+	return policy.Subject{ID: "user_1", Scopes: []string{policy.ScopeUsersRead}}, nil
+})
+
+var testKeyAuthenticator = authenticatorFunc(func(ctx context.Context, key string) (policy.Subject, error) {
+	if key != "ak_valid_secret" {
+		return policy.Subject{}, errors.New("invalid API key")
+	}
+	return policy.Subject{ID: "key_1", Scopes: []string{policy.ScopeUsersRead}}, nil
+})
+
+type sessionAuthenticatorFunc func(ctx context.Context, id string) (policy.Subject, error)
+
+func (f sessionAuthenticatorFunc) AuthenticateSession(ctx context.Context, id string) (policy.Subject, error) {
+	return f(ctx, id)
+}
+
+var testSessionAuthenticator = sessionAuthenticatorFunc(func(ctx context.Context, id string) (policy.Subject, error) {
+	if id != "valid-session" {
+		return policy.Subject{}, errors.New("session not found")
+	}
+	return policy.Subject{ID: "user_2", Scopes: []string{policy.ScopeUsersWrite}}, nil
+})
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		expectedID    string
+	}{
+		{name: "valid token", authorization: "Bearer valid-token", expectedID: "user_1"},
+		{name: "case insensitive scheme", authorization: "bearer valid-token", expectedID: "user_1"},
+		{name: "invalid token", authorization: "Bearer expired-token"},
+		{name: "other scheme", authorization: "Basic dXNlcjpwYXNz"},
+		{name: "empty token", authorization: "Bearer "},
+		{name: "no header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subject policy.Subject
+			var authenticated bool
+			var userID string
+			handler := Authenticate(testAuthenticator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				subject, authenticated = policy.SubjectFromContext(r.Context())
+				userID = ctxkeys.UserID.Value(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expectedID != "", authenticated)
+			assert.Equal(t, tt.expectedID, subject.ID)
+			assert.Equal(t, tt.expectedID, userID)
+		})
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		apiKey        string
+		expectedID    string
+	}{
+		{name: "valid key", apiKey: "ak_valid_secret", expectedID: "key_1"},
+		{name: "invalid key", apiKey: "ak_revoked_secret"},
+		{name: "bearer token takes precedence", authorization: "Bearer valid-token", apiKey: "ak_valid_secret", expectedID: "user_1"},
+		{name: "invalid token falls back to key", authorization: "Bearer expired-token", apiKey: "ak_valid_secret", expectedID: "key_1"},
+		{name: "no header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subject policy.Subject
+			var userID string
+			handler := Authenticate(testAuthenticator)(AuthenticateAPIKey(testKeyAuthenticator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				subject, _ = policy.SubjectFromContext(r.Context())
+				userID = ctxkeys.UserID.Value(r.Context())
+			})))
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expectedID, subject.ID)
+			assert.Equal(t, tt.expectedID, userID)
+		})
+	}
+}
+
+func TestAuthenticateSession(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		cookie        string
+		expectedID    string
+	}{
+		{name: "valid session", cookie: "valid-session", expectedID: "user_2"},
+		{name: "unknown session", cookie: "ended-session"},
+		{name: "bearer token takes precedence", authorization: "Bearer valid-token", cookie: "valid-session", expectedID: "user_1"},
+		{name: "invalid token falls back to session", authorization: "Bearer expired-token", cookie: "valid-session", expectedID: "user_2"},
+		{name: "no cookie"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subject policy.Subject
+			var userID string
+			handler := Authenticate(testAuthenticator)(AuthenticateSession(testSessionAuthenticator, "session")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				subject, _ = policy.SubjectFromContext(r.Context())
+				userID = ctxkeys.UserID.Value(r.Context())
+			})))
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tt.cookie})
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expectedID, subject.ID)
+			assert.Equal(t, tt.expectedID, userID)
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name            string
+		authorization   string
+		apiKey          string
+		cookie          string
+		expectedStatus  int
+		expectedHeader  string
+		expectedMessage string
+	}{
+		{
+			name:           "authenticated",
+			authorization:  "Bearer valid-token",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "anonymous",
+			expectedStatus:  http.StatusUnauthorized,
+			expectedHeader:  "Bearer",
+			expectedMessage: "Authentication required",
+		},
+		{
+			name:            "invalid token",
+			authorization:   "Bearer expired-token",
+			expectedStatus:  http.StatusUnauthorized,
+			expectedHeader:  `Bearer error="invalid_token", error_description="The access token is invalid or expired"`,
+			expectedMessage: "Invalid or expired access token",
+		},
+		{
+			name:           "API key",
+			apiKey:         "ak_valid_secret",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "invalid API key",
+			apiKey:          "ak_revoked_secret",
+			expectedStatus:  http.StatusUnauthorized,
+			expectedHeader:  "Bearer",
+			expectedMessage: "Invalid, expired or revoked API key",
+		},
+		{
+			name:           "session",
+			cookie:         "valid-session",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "invalid session",
+			cookie:          "ended-session",
+			expectedStatus:  http.StatusUnauthorized,
+			expectedHeader:  "Bearer",
+			expectedMessage: "Session is invalid or expired; log in again",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Authenticate(testAuthenticator)(AuthenticateSession(testSessionAuthenticator, "session")(AuthenticateAPIKey(testKeyAuthenticator)(Require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})))))
+
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "session", Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedHeader, rec.Header().Get("WWW-Authenticate"))
+			if tt.expectedMessage != "" {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, tt.expectedMessage, body["message"])
+			}
+		})
+	}
+}
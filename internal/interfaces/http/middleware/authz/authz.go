@@ -0,0 +1,72 @@
+package authz
+
+import (
+	"net/http"
+
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/pkg/utils"
+)
+
+// forwardedHeaders lists the request headers exposed to policies
+var forwardedHeaders = []string{"User-Agent", "X-Request-Id", "X-Forwarded-For"}
+
+// ResourceFunc extracts the resource a request operates on
+type ResourceFunc func(r *http.Request) policy.Resource
+
+// Collection returns a ResourceFunc for a resource collection
+func Collection(resourceType string) ResourceFunc {
+	return func(r *http.Request) policy.Resource {
+		return policy.Resource{Type: resourceType}
+	}
+}
+
+// Require creates a middleware that consults the policy engine before
+// letting the request through. Requests are denied when the engine fails.
+func Require(engine policy.Engine, action string, resource ResourceFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject, authenticated := policy.SubjectFromContext(r.Context())
+
+			input := policy.Input{
+				Subject:  subject,
+				Action:   action,
+				Resource: resource(r),
+				Request:  requestInfo(r),
+			}
+
+			decision, err := engine.Evaluate(r.Context(), input)
+			if err != nil {
+				utils.WriteErrorFor(w, r, http.StatusServiceUnavailable, "Authorization service unavailable")
+				return
+			}
+
+			if !decision.Allowed {
+				if !authenticated {
+					utils.WriteErrorFor(w, r, http.StatusUnauthorized, "Authentication required")
+					return
+				}
+				utils.WriteErrorFor(w, r, http.StatusForbidden, "Forbidden")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestInfo collects the request attributes passed to the engine
+func requestInfo(r *http.Request) policy.RequestInfo {
+	headers := make(map[string]string)
+	for _, name := range forwardedHeaders {
+		if value := r.Header.Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+
+	return policy.RequestInfo{
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		RemoteIP: r.RemoteAddr,
+		Headers:  headers,
+	}
+}
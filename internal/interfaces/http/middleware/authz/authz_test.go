@@ -0,0 +1,89 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"clean-architecture/internal/domain/policy"
+)
+
+// stubEngine is a policy.Engine returning a fixed decision
+type stubEngine struct {
+	decision policy.Decision
+	err      error
+	input    policy.Input
+}
+
+func (e *stubEngine) Evaluate(ctx context.Context, input policy.Input) (policy.Decision, error) {
+	e.input = input
+	return e.decision, e.err
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name           string
+		subject        *policy.Subject
+		decision       policy.Decision
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "allowed",
+			subject:        &policy.Subject{ID: "user_1"},
+			decision:       policy.Decision{Allowed: true},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "denied authenticated",
+			subject:        &policy.Subject{ID: "user_1"},
+			decision:       policy.Decision{Allowed: false},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "denied anonymous",
+			decision:       policy.Decision{Allowed: false},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "engine error",
+			subject:        &policy.Subject{ID: "user_1"},
+			err:            assert.AnError,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := &stubEngine{decision: tt.decision, err: tt.err}
+			handler := Require(engine, "users:list", Collection("user"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/users", nil)
+			req.Header.Set("User-Agent", "test-agent")
+			if tt.subject != nil {
+				req = req.WithContext(policy.WithSubject(req.Context(), *tt.subject))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, "users:list", engine.input.Action)
+			assert.Equal(t, "user", engine.input.Resource.Type)
+			assert.Equal(t, "GET", engine.input.Request.Method)
+			assert.Equal(t, "test-agent", engine.input.Request.Headers["User-Agent"])
+
+			if tt.expectedStatus != http.StatusOK {
+				var response map[string]interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "error", response["status"])
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package consistency
+
+import (
+	"net/http"
+	"time"
+
+	"clean-architecture/pkg/consistency"
+)
+
+// Middleware honors the X-Consistency-Token of a request: while the write it
+// records is younger than window, reads of the written entity see it.
+// Malformed and expired tokens are ignored, as the request can be answered
+// either way.
+func Middleware(window time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoded := r.Header.Get(consistency.Header)
+			if encoded == "" || window <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, err := consistency.Parse(encoded)
+			if err != nil || !token.Fresh(time.Now(), window) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(consistency.WithToken(r.Context(), token)))
+		})
+	}
+}
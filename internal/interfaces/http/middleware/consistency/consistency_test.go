@@ -0,0 +1,44 @@
+package consistency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"clean-architecture/pkg/consistency"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		expected bool
+	}{
+		{name: "fresh token", token: consistency.NewToken("users", "user_1", time.Now()), expected: true},
+		{name: "expired token", token: consistency.NewToken("users", "user_1", time.Now().Add(-time.Minute))},
+		{name: "malformed token", token: "garbage"},
+		{name: "no token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var primary bool
+			handler := Middleware(5 * time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				primary = consistency.RequiresPrimary(r.Context(), "users", "user_1")
+			}))
+
+			req := httptest.NewRequest("GET", "/api/v1/users/user_1", nil)
+			if tt.token != "" {
+				req.Header.Set(consistency.Header, tt.token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expected, primary)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package contenttype
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"clean-architecture/pkg/utils"
+)
+
+// Require creates a middleware that rejects request bodies whose
+// Content-Type is missing or not one of the accepted media types with 415
+// Unsupported Media Type. A charset parameter, when present, must be UTF-8.
+// Requests without a body pass, and no accepted types disables the check.
+func Require(accepted ...string) func(http.Handler) http.Handler {
+	types := make(map[string]bool, len(accepted))
+	for _, t := range accepted {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			types[t] = true
+		}
+	}
+	message := "Content-Type must be " + strings.Join(accepted, " or ")
+
+	return func(next http.Handler) http.Handler {
+		if len(types) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !hasBody(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || !types[mediaType] {
+				utils.WriteErrorFor(w, r, http.StatusUnsupportedMediaType, message)
+				return
+			}
+			if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+				utils.WriteErrorFor(w, r, http.StatusUnsupportedMediaType, "Request bodies must be encoded as UTF-8")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasBody reports whether the request carries a body. A content length of
+// -1 means the length is unknown, e.g. for chunked transfer encoding.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
@@ -0,0 +1,91 @@
+package contenttype
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		contentType    string
+		body           string
+		accepted       []string
+		expectedStatus int
+	}{
+		{
+			name:           "json body",
+			method:         http.MethodPost,
+			contentType:    "application/json",
+			body:           `{}`,
+			accepted:       []string{"application/json"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "utf-8 charset",
+			method:         http.MethodPost,
+			contentType:    "Application/JSON; charset=UTF-8",
+			body:           `{}`,
+			accepted:       []string{"application/json"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "other charset",
+			method:         http.MethodPost,
+			contentType:    "application/json; charset=latin1",
+			body:           `{}`,
+			accepted:       []string{"application/json"},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "missing content type",
+			method:         http.MethodPut,
+			body:           `{}`,
+			accepted:       []string{"application/json"},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "form body",
+			method:         http.MethodPost,
+			contentType:    "application/x-www-form-urlencoded",
+			body:           `name=a`,
+			accepted:       []string{"application/json"},
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "no body",
+			method:         http.MethodPost,
+			accepted:       []string{"application/json"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "check disabled",
+			method:         http.MethodPost,
+			contentType:    "text/plain",
+			body:           `hello`,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Require(tt.accepted...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
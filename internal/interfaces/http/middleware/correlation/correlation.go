@@ -0,0 +1,27 @@
+package correlation
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"clean-architecture/pkg/correlation"
+)
+
+// Middleware attaches a correlation ID to the request context and echoes it
+// in the response. A client-supplied X-Correlation-ID is kept so callers can
+// trace their own flows; otherwise the request ID is used.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(correlation.Header)
+		if id == "" {
+			id = middleware.GetReqID(r.Context())
+		}
+		if id == "" {
+			id = correlation.NewID()
+		}
+
+		w.Header().Set(correlation.Header, id)
+		next.ServeHTTP(w, r.WithContext(correlation.WithID(r.Context(), id)))
+	})
+}
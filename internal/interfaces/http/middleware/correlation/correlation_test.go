@@ -0,0 +1,53 @@
+package correlation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+
+	"clean-architecture/pkg/correlation"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected func(id string) bool
+	}{
+		{
+			name:     "keeps client correlation ID",
+			header:   "checkout-42",
+			expected: func(id string) bool { return id == "checkout-42" },
+		},
+		{
+			name:     "falls back to request ID",
+			expected: func(id string) bool { return id != "" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen, requestID string
+			handler := middleware.RequestID(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = correlation.ID(r.Context())
+				requestID = middleware.GetReqID(r.Context())
+			})))
+
+			req := httptest.NewRequest("GET", "/api/v1/users", nil)
+			if tt.header != "" {
+				req.Header.Set(correlation.Header, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.True(t, tt.expected(seen))
+			assert.Equal(t, seen, w.Header().Get(correlation.Header))
+			if tt.header == "" {
+				assert.Equal(t, requestID, seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Package diagnostics captures what slow requests are doing while they are
+// still running.
+package diagnostics
+
+import (
+	"bytes"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/timing"
+)
+
+const (
+	// stackInterval is the least time between two goroutine dumps, which
+	// stop the world while they are taken
+	stackInterval = time.Second
+	// maxStackDump bounds the buffer the goroutine dump is taken into
+	maxStackDump = 16 << 20
+)
+
+// dumper logs the diagnostic bundle of slow requests
+type dumper struct {
+	threshold time.Duration
+	logger    logger.Logger
+	// lastStack is when the last goroutine dump was taken, in Unix
+	// nanoseconds
+	lastStack atomic.Int64
+}
+
+// SlowRequests logs a diagnostic bundle for each request still running
+// threshold after it started: the SQL it is running, the stack of the
+// goroutine handling it and the spans recorded so far, with the request ID.
+// It attaches the timing.Recorder the use cases and repositories record
+// spans in, unless an outer middleware did, so it must run after the
+// request context middleware.
+func SlowRequests(threshold time.Duration, log logger.Logger) func(http.Handler) http.Handler {
+	d := &dumper{threshold: threshold, logger: log}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := timing.FromContext(r.Context())
+			if rec == nil {
+				rec = timing.NewRecorder()
+				r = r.WithContext(timing.WithRecorder(r.Context(), rec))
+			}
+
+			goroutine := goroutineID()
+			timer := time.AfterFunc(threshold, func() { d.dump(r, rec, goroutine) })
+			defer timer.Stop()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// dump logs the bundle of the request r handled by goroutine
+func (d *dumper) dump(r *http.Request, rec *timing.Recorder, goroutine []byte) {
+	spans := rec.Spans()
+	timings := make([]string, len(spans))
+	activeSQL := []string{}
+	for i, span := range spans {
+		timings[i] = span.String()
+		if span.Active && span.Layer == timing.LayerRepository && span.Detail != "" {
+			activeSQL = append(activeSQL, span.Detail)
+		}
+	}
+	layers := make(map[string]string)
+	for layer, total := range rec.Layers() {
+		layers[layer] = total.Round(time.Microsecond).String()
+	}
+
+	ctx := r.Context()
+	fields := map[string]interface{}{
+		"request_id":     ctxkeys.RequestID.Value(ctx),
+		"correlation_id": ctxkeys.CorrelationID.Value(ctx),
+		"method":         r.Method,
+		"path":           r.URL.Path,
+		"elapsed":        rec.Elapsed().Round(time.Millisecond).String(),
+		"threshold":      d.threshold.String(),
+		"active_sql":     activeSQL,
+		"timings":        timings,
+		"layers":         layers,
+	}
+	if dropped := rec.Dropped(); dropped > 0 {
+		fields["timings_dropped"] = dropped
+	}
+	if d.takeStack() {
+		fields["stack"] = goroutineStack(goroutine)
+	} else {
+		fields["stack"] = "skipped: another stack was dumped less than " + stackInterval.String() + " ago"
+	}
+	d.logger.WithFields(fields).Warn("Slow request")
+}
+
+// takeStack reports whether a goroutine dump may be taken now, so a burst
+// of slow requests does not stop the world for each of them
+func (d *dumper) takeStack() bool {
+	now := time.Now().UnixNano()
+	last := d.lastStack.Load()
+	if now-last < int64(stackInterval) {
+		return false
+	}
+	return d.lastStack.CompareAndSwap(last, now)
+}
+
+// goroutineID returns the ID of the calling goroutine, read from the
+// "goroutine 42 [running]:" header of its stack
+func goroutineID() []byte {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	fields := bytes.Fields(buf)
+	if len(fields) < 2 {
+		return nil
+	}
+	return fields[1]
+}
+
+// goroutineStack returns the stack of the goroutine with id, taken from a
+// dump of all goroutines since only the calling goroutine's can be read
+// directly
+func goroutineStack(id []byte) string {
+	var dump []byte
+	for size := 64 << 10; ; size *= 2 {
+		buf := make([]byte, size)
+		n := runtime.Stack(buf, true)
+		dump = buf[:n]
+		if n < size || size >= maxStackDump {
+			break
+		}
+	}
+
+	header := append(append([]byte("goroutine "), id...), " ["...)
+	start := -1
+	if bytes.HasPrefix(dump, header) {
+		start = 0
+	} else if i := bytes.Index(dump, append([]byte("\n\n"), header...)); i >= 0 {
+		start = i + 2
+	}
+	if id == nil || start < 0 {
+		return "unavailable: the goroutine was not found in the dump"
+	}
+
+	stack := dump[start:]
+	if end := bytes.Index(stack, []byte("\n\n")); end >= 0 {
+		stack = stack[:end]
+	}
+	return string(bytes.TrimSpace(stack))
+}
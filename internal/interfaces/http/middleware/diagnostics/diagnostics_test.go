@@ -0,0 +1,134 @@
+package diagnostics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/timing"
+)
+
+// recordingLogger records the fields of the warnings logged through it
+type recordingLogger struct {
+	mu       sync.Mutex
+	fields   map[string]interface{}
+	warnings []map[string]interface{}
+	logged   chan struct{}
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{logged: make(chan struct{}, 10)}
+}
+
+func (l *recordingLogger) Debug(args ...interface{})                 {}
+func (l *recordingLogger) Info(args ...interface{})                  {}
+func (l *recordingLogger) Error(args ...interface{})                 {}
+func (l *recordingLogger) Fatal(args ...interface{})                 {}
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {}
+func (l *recordingLogger) Infof(format string, args ...interface{})  {}
+func (l *recordingLogger) Warnf(format string, args ...interface{})  {}
+func (l *recordingLogger) Errorf(format string, args ...interface{}) {}
+func (l *recordingLogger) Fatalf(format string, args ...interface{}) {}
+
+func (l *recordingLogger) Warn(args ...interface{}) {
+	l.mu.Lock()
+	l.warnings = append(l.warnings, l.fields)
+	l.mu.Unlock()
+	l.logged <- struct{}{}
+}
+
+func (l *recordingLogger) WithContext(ctx context.Context) logger.Logger { return l }
+
+func (l *recordingLogger) WithField(key string, value interface{}) logger.Logger {
+	return l.WithFields(map[string]interface{}{key: value})
+}
+
+func (l *recordingLogger) WithFields(fields map[string]interface{}) logger.Logger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fields = fields
+	return l
+}
+
+func TestSlowRequests(t *testing.T) {
+	log := newRecordingLogger()
+	var recorded bool
+	handler := SlowRequests(20*time.Millisecond, log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorded = timing.FromContext(r.Context()) != nil
+		timing.Start(r.Context(), timing.LayerUseCase, "UserUseCase.ListUsers").End()
+		query := timing.Start(r.Context(), timing.LayerRepository, "query users")
+		query.Describe(`SELECT * FROM "users" LIMIT 10`)
+		defer query.End()
+
+		// The bundle is taken while the request still runs
+		select {
+		case <-log.logged:
+		case <-time.After(5 * time.Second):
+			t.Error("no diagnostics were logged")
+		}
+	}))
+
+	req := httptest.NewRequest("GET", "/api/v1/users", nil)
+	req = req.WithContext(ctxkeys.RequestID.With(req.Context(), "req_1"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.True(t, recorded)
+	require.Len(t, log.warnings, 1)
+	fields := log.warnings[0]
+	assert.Equal(t, "req_1", fields["request_id"])
+	assert.Equal(t, "/api/v1/users", fields["path"])
+	assert.Equal(t, []string{`SELECT * FROM "users" LIMIT 10`}, fields["active_sql"])
+	require.Len(t, fields["timings"], 2)
+	assert.Contains(t, fields["timings"].([]string)[1], "(active)")
+	assert.Contains(t, fields["layers"], timing.LayerUseCase)
+	assert.Contains(t, fields["stack"], "diagnostics.TestSlowRequests", "the stack is the handler's")
+	assert.NotContains(t, fields["stack"], "\n\n")
+}
+
+func TestSlowRequests_ReusesRecorder(t *testing.T) {
+	rec := timing.NewRecorder()
+	var got *timing.Recorder
+	handler := SlowRequests(time.Minute, newRecordingLogger())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = timing.FromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/api/v1/users", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(timing.WithRecorder(req.Context(), rec)))
+
+	assert.Same(t, rec, got)
+}
+
+func TestSlowRequests_FastRequest(t *testing.T) {
+	log := newRecordingLogger()
+	handler := SlowRequests(50*time.Millisecond, log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/users", nil))
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Empty(t, log.warnings)
+}
+
+func TestDumper_TakeStack(t *testing.T) {
+	d := &dumper{}
+	assert.True(t, d.takeStack())
+	assert.False(t, d.takeStack(), "stacks are dumped at most once per interval")
+
+	d.lastStack.Store(time.Now().Add(-stackInterval).UnixNano())
+	assert.True(t, d.takeStack())
+}
+
+func TestGoroutineStack(t *testing.T) {
+	stack := goroutineStack(goroutineID())
+	assert.Contains(t, stack, "[running]")
+	assert.Contains(t, stack, "diagnostics.TestGoroutineStack")
+
+	assert.Contains(t, goroutineStack([]byte("0")), "unavailable")
+}
@@ -0,0 +1,35 @@
+// Package features gates routes on the features of the plan subscribed to.
+package features
+
+import (
+	"context"
+	"net/http"
+
+	"clean-architecture/pkg/utils"
+)
+
+// Gate reports whether the plan in effect includes a feature
+type Gate interface {
+	Allows(ctx context.Context, feature string) (bool, error)
+}
+
+// Require creates a middleware that only lets requests through while the
+// plan in effect includes feature, answering others with 402 Payment
+// Required. Requests are refused when the plan cannot be checked.
+func Require(gate Gate, feature string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, err := gate.Allows(r.Context(), feature)
+			if err != nil {
+				utils.WriteErrorFor(w, r, http.StatusServiceUnavailable, "Plan could not be checked")
+				return
+			}
+			if !allowed {
+				utils.WriteErrorFor(w, r, http.StatusPaymentRequired, "The current plan does not include "+feature)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
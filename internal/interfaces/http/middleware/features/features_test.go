@@ -0,0 +1,48 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubGate allows the listed features
+type stubGate struct {
+	features map[string]bool
+	err      error
+}
+
+func (g stubGate) Allows(ctx context.Context, feature string) (bool, error) {
+	return g.features[feature], g.err
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name           string
+		gate           stubGate
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"included", stubGate{features: map[string]bool{"exports": true}}, http.StatusOK, ""},
+		{"not included", stubGate{features: map[string]bool{"imports": true}}, http.StatusPaymentRequired, "The current plan does not include exports"},
+		{"gate failure", stubGate{err: errors.New("connection refused")}, http.StatusServiceUnavailable, "Plan could not be checked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Require(tt.gate, "exports")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/users/exports", nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package limits
+
+import (
+	"context"
+	"net/http"
+
+	"clean-architecture/pkg/ctxkeys"
+)
+
+// maxCollectionSize holds the largest array a request body may contain
+var maxCollectionSize = ctxkeys.NewKey[int]("max_collection_size")
+
+// MaxCollectionSize creates a middleware that limits arrays in request
+// bodies to n items. The limit is enforced where bodies are decoded; zero
+// or less disables it.
+func MaxCollectionSize(n int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if n <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(maxCollectionSize.With(r.Context(), n)))
+		})
+	}
+}
+
+// CollectionSize returns the largest array allowed in the request body, or
+// zero when there is no limit
+func CollectionSize(ctx context.Context) int {
+	return maxCollectionSize.Value(ctx)
+}
@@ -0,0 +1,22 @@
+package limits
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxCollectionSize(t *testing.T) {
+	var limit int
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit = CollectionSize(r.Context())
+	})
+
+	MaxCollectionSize(100)(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+	assert.Equal(t, 100, limit)
+
+	MaxCollectionSize(0)(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+	assert.Equal(t, 0, limit)
+}
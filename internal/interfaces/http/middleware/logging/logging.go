@@ -4,10 +4,13 @@ import (
 	"net/http"
 	"time"
 
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/cost"
 	"clean-architecture/pkg/logger"
 )
 
-// LoggerMiddleware creates a middleware that logs HTTP requests
+// LoggerMiddleware creates a middleware that logs HTTP requests, with their
+// costs when an outer middleware attached a cost.Counter
 func LoggerMiddleware(log logger.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -23,14 +26,22 @@ func LoggerMiddleware(log logger.Logger) func(http.Handler) http.Handler {
 			duration := time.Since(start)
 
 			// Log request details
-			log.WithFields(map[string]interface{}{
+			fields := map[string]interface{}{
 				"method":     r.Method,
 				"path":       r.URL.Path,
 				"status":     ww.statusCode,
 				"duration":   duration.String(),
 				"user_agent": r.UserAgent(),
 				"remote_ip":  r.RemoteAddr,
-			}).Info("HTTP Request")
+
+				"correlation_id": correlation.ID(r.Context()),
+			}
+			if counter := cost.FromContext(r.Context()); counter != nil {
+				for key, value := range counter.Costs().Fields() {
+					fields[key] = value
+				}
+			}
+			log.WithFields(fields).Info("HTTP Request")
 		})
 	}
 }
@@ -45,3 +56,8 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"clean-architecture/pkg/cost"
 	"clean-architecture/pkg/logger"
 )
 
@@ -172,6 +173,25 @@ func TestLoggerMiddleware(t *testing.T) {
 	}
 }
 
+func TestLoggerMiddleware_LogsCosts(t *testing.T) {
+	mockLogger := new(MockLogger)
+	mockLoggerWithFields := new(MockLogger)
+	mockLogger.On("WithFields", mock.MatchedBy(func(fields map[string]interface{}) bool {
+		return fields["db_queries"] == int64(2) && fields["db_rows"] == int64(7) && fields["external_calls"] == int64(0)
+	})).Return(mockLoggerWithFields)
+	mockLoggerWithFields.On("Info", "HTTP Request").Return()
+
+	handler := LoggerMiddleware(mockLogger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cost.AddQuery(r.Context(), 5)
+		cost.AddQuery(r.Context(), 2)
+	}))
+	req := httptest.NewRequest("GET", "/api/v1/users", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(cost.WithCounter(req.Context(), cost.NewCounter())))
+
+	mockLogger.AssertExpectations(t)
+	mockLoggerWithFields.AssertExpectations(t)
+}
+
 func TestResponseWriter(t *testing.T) {
 	// Create a mock response writer
 	mockWriter := httptest.NewRecorder()
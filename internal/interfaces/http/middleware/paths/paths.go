@@ -0,0 +1,158 @@
+package paths
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Options selects the ways a request path may differ from a route and
+// still be redirected to it
+type Options struct {
+	// TrailingSlash redirects paths with a trailing slash routes lack, or
+	// lacking one routes have
+	TrailingSlash bool
+	// CleanPaths redirects paths with repeated slashes or . and ..
+	// segments to their clean form
+	CleanPaths bool
+	// CaseInsensitive redirects paths whose static segments differ from a
+	// route only in case; parameters such as IDs keep their case
+	CaseInsensitive bool
+}
+
+// Normalize creates a middleware that redirects requests to paths routes
+// does not match with 308 Permanent Redirect, which keeps the method and
+// body, to the path of the route they were meant for. Paths routes match
+// are served as they are, so normalization never shadows a route.
+// Requests whose path was sent with escapes, such as %2F, are not
+// normalized.
+func Normalize(routes chi.Routes, opts Options) func(http.Handler) http.Handler {
+	var (
+		once     sync.Once
+		patterns [][]string
+	)
+	// Routes are complete once requests are served
+	routePatterns := func() [][]string {
+		once.Do(func() {
+			_ = chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+				patterns = append(patterns, strings.Split(route, "/"))
+				return nil
+			})
+		})
+		return patterns
+	}
+
+	return func(next http.Handler) http.Handler {
+		if !opts.TrailingSlash && !opts.CleanPaths && !opts.CaseInsensitive {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.RawPath != "" || matches(routes, r.Method, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var fold func(string) string
+			if opts.CaseInsensitive {
+				fold = func(p string) string { return foldCase(routePatterns(), p) }
+			}
+			target, ok := normalize(r.URL.Path, opts, fold, func(p string) bool {
+				return matches(routes, r.Method, p)
+			})
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			location := (&url.URL{Path: target, RawQuery: r.URL.RawQuery}).String()
+			http.Redirect(w, r, location, http.StatusPermanentRedirect)
+		})
+	}
+}
+
+// normalize returns the first form of p allowed by opts that matches a
+// route
+func normalize(p string, opts Options, fold func(string) string, matches func(string) bool) (string, bool) {
+	base := p
+	if opts.CleanPaths {
+		base = cleanPath(p)
+	}
+
+	candidates := []string{base}
+	if opts.TrailingSlash && base != "/" {
+		if strings.HasSuffix(base, "/") {
+			candidates = append(candidates, strings.TrimSuffix(base, "/"))
+		} else {
+			candidates = append(candidates, base+"/")
+		}
+	}
+	if fold != nil {
+		for _, candidate := range candidates {
+			candidates = append(candidates, fold(candidate))
+		}
+	}
+
+	for _, candidate := range candidates {
+		// A path starting with // would redirect to another host
+		if candidate == p || strings.HasPrefix(candidate, "//") {
+			continue
+		}
+		if matches(candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// cleanPath collapses repeated slashes and resolves . and .. segments,
+// keeping a trailing slash
+func cleanPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// foldCase returns p with each static segment replaced by that of the
+// first route pattern it matches ignoring case, or p when none does
+func foldCase(patterns [][]string, p string) string {
+	segments := strings.Split(p, "/")
+	for _, pattern := range patterns {
+		if folded, ok := foldSegments(pattern, segments); ok {
+			return strings.Join(folded, "/")
+		}
+	}
+	return p
+}
+
+func foldSegments(pattern, segments []string) ([]string, bool) {
+	folded := make([]string, 0, len(segments))
+	for i, part := range pattern {
+		switch {
+		case part == "*":
+			return append(folded, segments[i:]...), i < len(segments)
+		case i >= len(segments):
+			return nil, false
+		case strings.Contains(part, "{"):
+			// Parameters keep the case they were sent in
+			if segments[i] == "" {
+				return nil, false
+			}
+			folded = append(folded, segments[i])
+		case strings.EqualFold(part, segments[i]):
+			folded = append(folded, part)
+		default:
+			return nil, false
+		}
+	}
+	return folded, len(pattern) == len(segments)
+}
+
+func matches(routes chi.Routes, method, p string) bool {
+	return routes.Match(chi.NewRouteContext(), method, p)
+}
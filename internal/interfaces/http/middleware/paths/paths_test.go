@@ -0,0 +1,64 @@
+package paths
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRouter(opts Options) http.Handler {
+	r := chi.NewRouter()
+	r.Use(Normalize(r, opts))
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/", ok)
+		r.Get("/users", ok)
+		r.Post("/users", ok)
+		r.Get("/users/{id}", ok)
+		r.Put("/users:upsert", ok)
+		r.Get("/feature-flags/{name}/", ok)
+	})
+	r.Get("/swagger/*", ok)
+	return r
+}
+
+func TestNormalize(t *testing.T) {
+	all := Options{TrailingSlash: true, CleanPaths: true, CaseInsensitive: true}
+	tests := []struct {
+		name             string
+		opts             Options
+		method           string
+		target           string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{name: "matching path", opts: all, method: http.MethodGet, target: "/api/v1/users/user_1", expectedStatus: http.StatusOK},
+		{name: "trailing slash", opts: all, method: http.MethodGet, target: "/api/v1/users/user_1/?fields=name", expectedStatus: http.StatusPermanentRedirect, expectedLocation: "/api/v1/users/user_1?fields=name"},
+		{name: "missing trailing slash", opts: all, method: http.MethodGet, target: "/api/v1/feature-flags/beta", expectedStatus: http.StatusPermanentRedirect, expectedLocation: "/api/v1/feature-flags/beta/"},
+		{name: "post keeps its method", opts: all, method: http.MethodPost, target: "/api/v1//users/", expectedStatus: http.StatusPermanentRedirect, expectedLocation: "/api/v1/users"},
+		{name: "double slashes", opts: all, method: http.MethodGet, target: "/api//v1/users//user_1", expectedStatus: http.StatusPermanentRedirect, expectedLocation: "/api/v1/users/user_1"},
+		{name: "dot segments", opts: all, method: http.MethodGet, target: "/api/v1/x/../users", expectedStatus: http.StatusPermanentRedirect, expectedLocation: "/api/v1/users"},
+		{name: "case of static segments", opts: all, method: http.MethodGet, target: "/API/V1/Users/User_1", expectedStatus: http.StatusPermanentRedirect, expectedLocation: "/api/v1/users/User_1"},
+		{name: "case of a custom method", opts: all, method: http.MethodPut, target: "/api/v1/Users:Upsert", expectedStatus: http.StatusPermanentRedirect, expectedLocation: "/api/v1/users:upsert"},
+		{name: "case below a wildcard", opts: all, method: http.MethodGet, target: "/Swagger/Index.html", expectedStatus: http.StatusPermanentRedirect, expectedLocation: "/swagger/Index.html"},
+		{name: "no route", opts: all, method: http.MethodGet, target: "/api/v1/groups/", expectedStatus: http.StatusNotFound},
+		{name: "other host", opts: Options{TrailingSlash: true}, method: http.MethodGet, target: "//evil.example/", expectedStatus: http.StatusNotFound},
+		{name: "escaped path", opts: all, method: http.MethodGet, target: "/api/v1/users/a%2Fb/", expectedStatus: http.StatusNotFound},
+		{name: "trailing slash disabled", opts: Options{CleanPaths: true}, method: http.MethodGet, target: "/api/v1/users/user_1/", expectedStatus: http.StatusNotFound},
+		{name: "clean paths disabled", opts: Options{TrailingSlash: true}, method: http.MethodGet, target: "/api//v1/users", expectedStatus: http.StatusNotFound},
+		{name: "case sensitive", opts: Options{TrailingSlash: true, CleanPaths: true}, method: http.MethodGet, target: "/api/v1/Users", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newRouter(tt.opts).ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedLocation, w.Header().Get("Location"))
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Package replay captures the requests the API serves so they can be
+// replayed against another environment.
+package replay
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/replay"
+)
+
+// Capture stores every request in buffer once it is served, redacted by
+// redactor, with at most maxBody bytes of its body. The handler reads the
+// whole body as sent. It must run after the request context middleware so
+// captures carry the request and correlation IDs.
+func Capture(buffer *replay.Buffer, redactor *replay.Redactor, maxBody int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			var body []byte
+			truncated := false
+			if r.Body != nil && r.Body != http.NoBody && maxBody > 0 {
+				var err error
+				body, err = io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+				// The handler sees the read error on its own read of the rest
+				r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+				if err == nil && int64(len(body)) > maxBody {
+					body, truncated = body[:maxBody], true
+				}
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			ctx := r.Context()
+			captured := replay.Request{
+				ID:            replay.NewID(),
+				RequestID:     ctxkeys.RequestID.Value(ctx),
+				CorrelationID: ctxkeys.CorrelationID.Value(ctx),
+				Method:        r.Method,
+				Path:          r.URL.Path,
+				Query:         redactor.Query(r.URL.RawQuery),
+				Header:        redactor.Header(r.Header),
+				Truncated:     truncated,
+				Status:        ww.Status(),
+				Duration:      time.Since(start),
+				CapturedAt:    start,
+			}
+			if captured.Status == 0 {
+				captured.Status = http.StatusOK
+			}
+			captured.Body, captured.BodyOmitted = redactor.Body(r.Header.Get("Content-Type"), body)
+			if r.ContentLength > 0 && maxBody == 0 {
+				captured.BodyOmitted = "body capture is disabled"
+			}
+			buffer.Add(captured)
+		})
+	}
+}
+
+// readCloser reads the captured start of a body followed by its rest and
+// closes the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
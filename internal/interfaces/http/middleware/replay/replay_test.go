@@ -0,0 +1,65 @@
+package replay
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/replay"
+)
+
+func TestCapture(t *testing.T) {
+	buffer := replay.NewBuffer(10)
+	var handled string
+	handler := Capture(buffer, replay.NewRedactor(nil), 1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		handled = string(b)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users?token=abc&limit=5", strings.NewReader(`{"email":"jane@example.com","password":"hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer abc")
+	req = req.WithContext(ctxkeys.RequestID.With(req.Context(), "host/req-000001"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, `{"email":"jane@example.com","password":"hunter2"}`, handled, "the handler reads the body as sent")
+	captured := buffer.List()
+	require.Len(t, captured, 1)
+	assert.NotEmpty(t, captured[0].ID)
+	assert.Equal(t, "host/req-000001", captured[0].RequestID)
+	assert.Equal(t, http.MethodPost, captured[0].Method)
+	assert.Equal(t, "/api/v1/users", captured[0].Path)
+	assert.Equal(t, "limit=5&token=%5BREDACTED%5D", captured[0].Query)
+	assert.Equal(t, replay.Redacted, captured[0].Header.Get("Authorization"))
+	assert.JSONEq(t, `{"email":"jane@example.com","password":"[REDACTED]"}`, captured[0].Body)
+	assert.Equal(t, http.StatusUnprocessableEntity, captured[0].Status)
+	assert.False(t, captured[0].Truncated)
+}
+
+func TestCapture_LargeBody(t *testing.T) {
+	buffer := replay.NewBuffer(10)
+	var handled int
+	handler := Capture(buffer, replay.NewRedactor(nil), 8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		handled = len(b)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/imports", strings.NewReader("name\nJane\nJohn\n"))
+	req.Header.Set("Content-Type", "text/csv")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, 15, handled)
+	captured := buffer.List()
+	require.Len(t, captured, 1)
+	assert.Equal(t, "name\nJan", captured[0].Body)
+	assert.True(t, captured[0].Truncated)
+	assert.Equal(t, http.StatusOK, captured[0].Status)
+}
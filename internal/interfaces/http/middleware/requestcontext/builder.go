@@ -0,0 +1,226 @@
+package requestcontext
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/pkg/ctxkeys"
+)
+
+// DefaultLocale is the locale of requests that name none
+const DefaultLocale = "en"
+
+// TimeZoneHeader names the IANA time zone, such as Europe/Berlin, the
+// times of a response are shown in
+const TimeZoneHeader = "Time-Zone"
+
+// Builder builds the request.Context of each request once, so handlers
+// and use cases read the caller from it instead of deriving it again. Build
+// runs the same steps for every request, in order: trace, identity,
+// tenant, locale, time zone, flags and experiments. The tenant and locale steps may be
+// replaced.
+type Builder struct {
+	// Tenant returns the tenant subject acts for; by default the
+	// organization of service accounts
+	Tenant func(subject policy.Subject) string
+	// Locale returns the locale of r; by default the caller's preferred
+	// locale, then the languages of its Accept-Language header
+	Locale func(r *http.Request) string
+	// DefaultLocale is the locale when Locale returns none; DefaultLocale
+	// when empty
+	DefaultLocale string
+	// Locales are the locales responses are written in. When set, the
+	// default Locale step picks the first locale the caller prefers that
+	// is one of them, or whose language is; when empty, the first it
+	// prefers.
+	Locales []string
+	// Preferences returns the locale and time zone the authenticated
+	// caller chose. A chosen locale comes before Accept-Language, which
+	// browsers send without asking, while a Time-Zone header comes before
+	// the chosen zone. Nil looks up none.
+	Preferences func(ctx context.Context, userID string) entities.UserPreferences
+	// Flags resolves the feature flags the first time a request reads one;
+	// nil leaves every flag off
+	Flags request.FlagSource
+	// Experiments returns the experiment assignments of the caller, by
+	// user ID, empty for anonymous callers; nil puts requests in none
+	Experiments func(userID string) request.Assignments
+}
+
+// Middleware stores the request context built for each request. It must
+// run after the authentication middlewares, and before any middleware that
+// reads the caller once the request is handled.
+func (b Builder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc := b.Build(r)
+		w.Header().Set("Content-Language", rc.Locale)
+		w.Header().Add("Vary", "Accept-Language, "+TimeZoneHeader)
+		ctx := request.With(r.Context(), rc)
+		if rc.TenantID != "" {
+			ctx = ctxkeys.TenantID.With(ctx, rc.TenantID)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Build returns the request context of r
+func (b Builder) Build(r *http.Request) *request.Context {
+	ctx := r.Context()
+	rc := &request.Context{Trace: b.trace(r)}
+
+	if subject, ok := policy.SubjectFromContext(ctx); ok {
+		rc.UserID = subject.ID
+		rc.Roles = subject.Roles
+		rc.Scopes = subject.Scopes
+		tenant := b.Tenant
+		if tenant == nil {
+			tenant = OrganizationTenant
+		}
+		rc.TenantID = tenant(subject)
+	}
+
+	var prefs entities.UserPreferences
+	if b.Preferences != nil && rc.UserID != "" {
+		prefs = b.Preferences(ctx, rc.UserID)
+	}
+
+	if b.Locale != nil {
+		rc.Locale = b.Locale(r)
+	} else {
+		rc.Locale = b.matchLocale(append([]string{strings.ToLower(prefs.Locale)}, acceptLanguages(r)...))
+	}
+	if rc.Locale == "" {
+		rc.Locale = b.DefaultLocale
+	}
+	if rc.Locale == "" {
+		rc.Locale = DefaultLocale
+	}
+	rc.TimeZone = timeZone(r.Header.Get(TimeZoneHeader), prefs.TimeZone)
+
+	if b.Flags != nil {
+		rc.WithFlags(ctx, b.Flags)
+	}
+	if b.Experiments != nil {
+		rc.WithExperiments(ctx, b.Experiments(rc.UserID))
+	}
+	return rc
+}
+
+// trace collects the identifiers of r set by the request ID, correlation
+// and tracing middlewares
+func (b Builder) trace(r *http.Request) request.Trace {
+	ctx := r.Context()
+	t := request.Trace{
+		RequestID:     ctxkeys.RequestID.Value(ctx),
+		CorrelationID: ctxkeys.CorrelationID.Value(ctx),
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		t.TraceID = span.TraceID().String()
+		t.SpanID = span.SpanID().String()
+	}
+	return t
+}
+
+// OrganizationTenant returns the organization of service accounts, which
+// their API keys carry as the organization_id attribute
+func OrganizationTenant(subject policy.Subject) string {
+	tenant, _ := subject.Attributes["organization_id"].(string)
+	return tenant
+}
+
+// matchLocale returns the first of candidates, lowercased locales, that
+// responses are written in, by tag or by language alone
+func (b Builder) matchLocale(candidates []string) string {
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		if len(b.Locales) == 0 || slices.Contains(b.Locales, candidate) {
+			return candidate
+		}
+		if language, _, ok := strings.Cut(candidate, "-"); ok && slices.Contains(b.Locales, language) {
+			return language
+		}
+	}
+	return ""
+}
+
+// locations caches the time zones requests named, since loading one reads
+// the time zone database. Only valid names are stored, which bounds it.
+var locations sync.Map
+
+// timeZone returns the location of the first of names that is an IANA
+// time zone, or nil when none is. Local, the server's own zone, is not
+// one.
+func timeZone(names ...string) *time.Location {
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "Local" {
+			continue
+		}
+		if location, ok := locations.Load(name); ok {
+			return location.(*time.Location)
+		}
+		if location, err := time.LoadLocation(name); err == nil {
+			locations.Store(name, location)
+			return location
+		}
+	}
+	return nil
+}
+
+// AcceptLanguage returns the language r prefers most in its
+// Accept-Language header, lowercased, or "" when it names none
+func AcceptLanguage(r *http.Request) string {
+	if languages := acceptLanguages(r); len(languages) > 0 {
+		return languages[0]
+	}
+	return ""
+}
+
+// acceptLanguages returns the languages of r's Accept-Language header,
+// lowercased, most preferred first
+func acceptLanguages(r *http.Request) []string {
+	type preference struct {
+		tag     string
+		quality float64
+	}
+	var preferences []preference
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		params := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(params[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		if quality > 0 {
+			preferences = append(preferences, preference{tag, quality})
+		}
+	}
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+	languages := make([]string, len(preferences))
+	for i, preference := range preferences {
+		languages[i] = preference.tag
+	}
+	return languages
+}
@@ -0,0 +1,261 @@
+package requestcontext
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/pkg/ctxkeys"
+)
+
+func TestBuilder_Middleware(t *testing.T) {
+	subject := policy.Subject{
+		ID:         "sa_1",
+		Roles:      []string{policy.RoleService},
+		Scopes:     []string{policy.ScopeUsersRead},
+		Attributes: map[string]interface{}{"organization_id": "org_1"},
+	}
+	var rc *request.Context
+	var tenant string
+	handler := Builder{}.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rc = request.Current(r.Context())
+		tenant = ctxkeys.TenantID.Value(r.Context())
+	}))
+
+	req := httptest.NewRequest("GET", "/api/v1/users", nil)
+	req = req.WithContext(policy.WithSubject(req.Context(), subject))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NotNil(t, rc)
+	assert.True(t, rc.Authenticated())
+	assert.Equal(t, "sa_1", rc.UserID)
+	assert.True(t, rc.HasRole(policy.RoleService))
+	assert.Equal(t, []string{policy.ScopeUsersRead}, rc.Scopes)
+	assert.Equal(t, "org_1", rc.TenantID)
+	assert.Equal(t, "org_1", tenant)
+	assert.Equal(t, DefaultLocale, rc.Locale)
+}
+
+func TestBuilder_Anonymous(t *testing.T) {
+	rc := Builder{}.Build(httptest.NewRequest("GET", "/api/v1/users", nil))
+
+	assert.False(t, rc.Authenticated())
+	assert.Empty(t, rc.TenantID)
+	assert.False(t, rc.Flag("beta_search"))
+}
+
+func TestBuilder_Trace(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := ctxkeys.RequestID.With(context.Background(), "req_1")
+	ctx = ctxkeys.CorrelationID.With(ctx, "corr_1")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	rc := Builder{}.Build(httptest.NewRequest("GET", "/api/v1/users", nil).WithContext(ctx))
+
+	assert.Equal(t, request.Trace{
+		RequestID:     "req_1",
+		CorrelationID: "corr_1",
+		TraceID:       "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:        "00f067aa0ba902b7",
+	}, rc.Trace)
+}
+
+func TestBuilder_Locale(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		builder  Builder
+		expected string
+	}{
+		{name: "no header", expected: DefaultLocale},
+		{name: "configured default", builder: Builder{DefaultLocale: "de"}, expected: "de"},
+		{name: "single tag", header: "fr-CA", expected: "fr-ca"},
+		{name: "highest quality", header: "en;q=0.5, nl-BE;q=0.9, fr;q=0.7", expected: "nl-be"},
+		{name: "listed order breaks ties", header: "es, pt", expected: "es"},
+		{name: "refused and wildcard tags", header: "de;q=0, *", builder: Builder{DefaultLocale: "it"}, expected: "it"},
+		{
+			name:     "replaced step",
+			header:   "fr",
+			builder:  Builder{Locale: func(r *http.Request) string { return r.URL.Query().Get("lang") }},
+			expected: DefaultLocale,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/users", nil)
+			if tt.header != "" {
+				req.Header.Set("Accept-Language", tt.header)
+			}
+
+			assert.Equal(t, tt.expected, tt.builder.Build(req).Locale)
+		})
+	}
+}
+
+func TestBuilder_SupportedLocales(t *testing.T) {
+	builder := Builder{Locales: []string{"de", "en", "pt-br"}, DefaultLocale: "en"}
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{header: "fr, de-AT;q=0.8, en;q=0.5", expected: "de"},
+		{header: "pt-BR", expected: "pt-br"},
+		{header: "pt-PT", expected: "en"},
+		{header: "ja", expected: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/users", nil)
+			req.Header.Set("Accept-Language", tt.header)
+
+			assert.Equal(t, tt.expected, builder.Build(req).Locale)
+		})
+	}
+}
+
+func TestBuilder_Preferences(t *testing.T) {
+	var looked []string
+	builder := Builder{
+		Locales: []string{"de", "en"},
+		Preferences: func(ctx context.Context, userID string) entities.UserPreferences {
+			looked = append(looked, userID)
+			return entities.UserPreferences{Locale: "de-AT", TimeZone: "Europe/Vienna"}
+		},
+	}
+	req := httptest.NewRequest("GET", "/api/v1/users", nil)
+	req.Header.Set("Accept-Language", "en-US")
+
+	rc := builder.Build(req)
+	assert.Equal(t, "en", rc.Locale, "anonymous callers have no preferences")
+	assert.Nil(t, rc.TimeZone)
+	assert.Empty(t, looked)
+
+	req = req.WithContext(policy.WithSubject(req.Context(), policy.Subject{ID: "usr_1"}))
+	rc = builder.Build(req)
+	assert.Equal(t, []string{"usr_1"}, looked)
+	assert.Equal(t, "de", rc.Locale, "the chosen locale comes before Accept-Language")
+	require.NotNil(t, rc.TimeZone)
+	assert.Equal(t, "Europe/Vienna", rc.TimeZone.String())
+
+	req.Header.Set(TimeZoneHeader, "America/New_York")
+	assert.Equal(t, "America/New_York", builder.Build(req).TimeZone.String(), "the header comes before the chosen zone")
+}
+
+func TestBuilder_TimeZone(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{header: "Asia/Tokyo", expected: "Asia/Tokyo"},
+		{header: " UTC ", expected: "UTC"},
+		{header: "Local"},
+		{header: "Mars/Olympus_Mons"},
+		{header: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/users", nil)
+			req.Header.Set(TimeZoneHeader, tt.header)
+
+			location := Builder{}.Build(req).TimeZone
+			if tt.expected == "" {
+				assert.Nil(t, location)
+				return
+			}
+			require.NotNil(t, location)
+			assert.Equal(t, tt.expected, location.String())
+		})
+	}
+}
+
+func TestBuilder_MiddlewareContentLanguage(t *testing.T) {
+	handler := Builder{Locales: []string{"de", "en"}}.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest("GET", "/api/v1/users", nil)
+	req.Header.Set("Accept-Language", "de-CH, en;q=0.5")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, "de", w.Header().Get("Content-Language"))
+	assert.Contains(t, w.Header().Get("Vary"), "Accept-Language")
+	assert.Contains(t, w.Header().Get("Vary"), TimeZoneHeader)
+}
+
+func TestBuilder_Tenant(t *testing.T) {
+	builder := Builder{Tenant: func(subject policy.Subject) string { return "tenant_" + subject.ID }}
+	req := httptest.NewRequest("GET", "/api/v1/users", nil)
+	req = req.WithContext(policy.WithSubject(req.Context(), policy.Subject{ID: "usr_1"}))
+
+	assert.Equal(t, "tenant_usr_1", builder.Build(req).TenantID)
+}
+
+func TestBuilder_FlagsResolvedOnce(t *testing.T) {
+	calls := 0
+	builder := Builder{Flags: func(ctx context.Context) (map[string]bool, error) {
+		calls++
+		return map[string]bool{"beta_search": true, "new_dashboard": false}, nil
+	}}
+
+	rc := builder.Build(httptest.NewRequest("GET", "/api/v1/users", nil))
+	assert.Zero(t, calls, "flags are resolved on first use")
+
+	assert.True(t, rc.Flag("beta_search"))
+	assert.False(t, rc.Flag("new_dashboard"))
+	assert.False(t, rc.Flag("dark_mode"))
+	assert.Equal(t, 1, calls)
+}
+
+func TestBuilder_FlagsUnavailable(t *testing.T) {
+	builder := Builder{Flags: func(ctx context.Context) (map[string]bool, error) {
+		return nil, errors.New("database unavailable")
+	}}
+
+	rc := builder.Build(httptest.NewRequest("GET", "/api/v1/users", nil))
+
+	assert.False(t, rc.Flag("beta_search"))
+	assert.Empty(t, rc.Flags())
+}
+
+// stubAssignments assigns every experiment the user's ID as variant
+type stubAssignments string
+
+func (s stubAssignments) Variant(ctx context.Context, experiment string) string {
+	if experiment != "onboarding" {
+		return ""
+	}
+	return string(s)
+}
+
+func (s stubAssignments) All(ctx context.Context) map[string]string {
+	return map[string]string{"onboarding": string(s)}
+}
+
+func TestBuilder_Experiments(t *testing.T) {
+	builder := Builder{Experiments: func(userID string) request.Assignments {
+		return stubAssignments("variant_" + userID)
+	}}
+	req := httptest.NewRequest("GET", "/api/v1/users", nil)
+	req = req.WithContext(policy.WithSubject(req.Context(), policy.Subject{ID: "usr_1"}))
+
+	rc := builder.Build(req)
+	assert.Equal(t, "variant_usr_1", rc.Variant("onboarding"), "assignments are by caller")
+	assert.Equal(t, "", rc.Variant("pricing"))
+	assert.Equal(t, map[string]string{"onboarding": "variant_usr_1"}, rc.Experiments())
+
+	rc = Builder{}.Build(req)
+	assert.Equal(t, "", rc.Variant("onboarding"))
+	assert.Empty(t, rc.Experiments(), "requests are in no experiment without assignments")
+}
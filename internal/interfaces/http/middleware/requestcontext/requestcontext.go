@@ -0,0 +1,30 @@
+package requestcontext
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/logger"
+)
+
+// Middleware stores the request ID and a request-scoped logger in the
+// request context under their typed keys. It must run after the request ID
+// and correlation middlewares.
+func Middleware(log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			requestID := middleware.GetReqID(ctx)
+
+			ctx = ctxkeys.RequestID.With(ctx, requestID)
+			ctx = ctxkeys.Logger.With(ctx, log.WithFields(map[string]interface{}{
+				"request_id":     requestID,
+				"correlation_id": ctxkeys.CorrelationID.Value(ctx),
+			}))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
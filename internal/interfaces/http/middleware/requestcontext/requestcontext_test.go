@@ -0,0 +1,40 @@
+package requestcontext
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/logger"
+)
+
+func TestMiddleware(t *testing.T) {
+	var requestID string
+	var log logger.Logger
+
+	handler := middleware.RequestID(Middleware(logger.New())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = ctxkeys.RequestID.Must(r.Context())
+		log = ctxkeys.Logger.Must(r.Context())
+	})))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/users", nil))
+
+	assert.NotEmpty(t, requestID)
+	assert.NotNil(t, log)
+}
+
+func TestMiddleware_MissingValuePanicsInHandler(t *testing.T) {
+	// Handlers that require a value fail loudly when the chain is miswired
+	handler := middleware.Recoverer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctxkeys.Logger.Must(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/users", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,41 @@
+package scopes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/pkg/utils"
+)
+
+// Require creates a middleware that only lets requests through whose
+// credential was granted every listed scope. Failures carry an RFC 6750
+// WWW-Authenticate header naming the required scopes.
+func Require(required ...string) func(http.Handler) http.Handler {
+	scopeList := strings.Join(required, " ")
+
+	return func(next http.Handler) http.Handler {
+		if len(required) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject, authenticated := policy.SubjectFromContext(r.Context())
+			if !authenticated {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer scope=%q`, scopeList))
+				utils.WriteErrorFor(w, r, http.StatusUnauthorized, "Authentication required")
+				return
+			}
+
+			for _, scope := range required {
+				if !subject.HasScope(scope) {
+					w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, scopeList))
+					utils.WriteErrorFor(w, r, http.StatusForbidden, "Insufficient scope: requires "+scopeList)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
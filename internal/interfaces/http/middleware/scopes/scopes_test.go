@@ -0,0 +1,83 @@
+package scopes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"clean-architecture/internal/domain/policy"
+)
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name           string
+		subject        *policy.Subject
+		required       []string
+		expectedStatus int
+		expectedHeader string
+	}{
+		{
+			name:           "granted",
+			subject:        &policy.Subject{ID: "user_1", Scopes: []string{policy.ScopeUsersRead}},
+			required:       []string{policy.ScopeUsersRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "implied by broader scope",
+			subject:        &policy.Subject{ID: "user_1", Scopes: []string{policy.ScopeUsersWrite}},
+			required:       []string{policy.ScopeUsersRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "admin grants everything",
+			subject:        &policy.Subject{ID: "user_1", Scopes: []string{policy.ScopeAdmin}},
+			required:       []string{policy.ScopeUsersWrite},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "insufficient scope",
+			subject:        &policy.Subject{ID: "user_1", Scopes: []string{policy.ScopeUsersRead}},
+			required:       []string{policy.ScopeUsersWrite},
+			expectedStatus: http.StatusForbidden,
+			expectedHeader: `Bearer error="insufficient_scope", scope="users:write"`,
+		},
+		{
+			name:           "anonymous",
+			required:       []string{policy.ScopeUsersRead},
+			expectedStatus: http.StatusUnauthorized,
+			expectedHeader: `Bearer scope="users:read"`,
+		},
+		{
+			name:           "no scopes required",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Require(tt.required...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/users", nil)
+			if tt.subject != nil {
+				req = req.WithContext(policy.WithSubject(req.Context(), *tt.subject))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedHeader, w.Header().Get("WWW-Authenticate"))
+
+			if tt.expectedStatus != http.StatusOK {
+				var response map[string]interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, "error", response["status"])
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Package servertiming reports where the time of each request went in a
+// Server-Timing header, for profiling handlers, use cases and repositories
+// during development.
+package servertiming
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/timing"
+)
+
+// Header is the response header the breakdown is reported in
+const Header = "Server-Timing"
+
+// allowOriginHeader lets pages of other origins read the breakdown through
+// the browser's Performance API, as CORS allows any origin
+const allowOriginHeader = "Timing-Allow-Origin"
+
+var envelopeKey = ctxkeys.NewKey[bool]("server_timing_envelope")
+
+// Middleware sets the Server-Timing header of each response to the
+// breakdown of its request when the header is written: the total, the
+// middleware before the handler, the handler and the use case and
+// repository spans within it. With envelope set, JSON responses also carry
+// the breakdown in meta.timings. It reuses the timing.Recorder of an outer
+// middleware and attaches one otherwise.
+func Middleware(envelope bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			rec := timing.FromContext(ctx)
+			if rec == nil {
+				rec = timing.NewRecorder()
+				ctx = timing.WithRecorder(ctx, rec)
+			}
+			if envelope {
+				ctx = envelopeKey.With(ctx, true)
+			}
+			w.Header().Set(allowOriginHeader, "*")
+			next.ServeHTTP(&timingWriter{ResponseWriter: w, recorder: rec}, r.WithContext(ctx))
+		})
+	}
+}
+
+// Envelope reports whether responses to the request carry their breakdown
+// in meta.timings
+func Envelope(ctx context.Context) bool {
+	return envelopeKey.Value(ctx)
+}
+
+// Format formats b as a Server-Timing header value, in milliseconds
+func Format(b timing.Breakdown) string {
+	var sb strings.Builder
+	for i, metric := range []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"total", b.Total},
+		{"middleware", b.Middleware},
+		{timing.LayerHandler, b.Handler},
+		{timing.LayerUseCase, b.UseCase},
+		{timing.LayerRepository, b.Repository},
+	} {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(metric.name)
+		sb.WriteString(";dur=")
+		sb.WriteString(strconv.FormatFloat(Milliseconds(metric.duration), 'f', -1, 64))
+	}
+	return sb.String()
+}
+
+// Milliseconds returns d in milliseconds rounded to microseconds
+func Milliseconds(d time.Duration) float64 {
+	return float64(d.Round(time.Microsecond)) / float64(time.Millisecond)
+}
+
+// timingWriter sets the header just before the response header is sent
+type timingWriter struct {
+	http.ResponseWriter
+	recorder    *timing.Recorder
+	wroteHeader bool
+}
+
+func (w *timingWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set(Header, Format(w.recorder.Breakdown()))
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *timingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
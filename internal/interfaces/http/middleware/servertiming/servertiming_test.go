@@ -0,0 +1,56 @@
+package servertiming
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"clean-architecture/pkg/timing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var envelope bool
+	handler := Middleware(false)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		envelope = Envelope(r.Context())
+		defer timing.Start(r.Context(), timing.LayerHandler, "users:list").End()
+		timing.Start(r.Context(), timing.LayerUseCase, "UserUseCase.ListUsers").End()
+		w.Write([]byte("{}")) //nolint:errcheck
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/users", nil))
+
+	assert.False(t, envelope)
+	assert.Regexp(t, `^total;dur=[0-9.]+, middleware;dur=[0-9.]+, handler;dur=[0-9.]+, usecase;dur=[0-9.]+, repository;dur=0$`, w.Header().Get(Header))
+	assert.Equal(t, "*", w.Header().Get("Timing-Allow-Origin"))
+	assert.Equal(t, "{}", w.Body.String())
+}
+
+func TestMiddleware_ReusesRecorder(t *testing.T) {
+	rec := timing.NewRecorder()
+	var got *timing.Recorder
+	handler := Middleware(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = timing.FromContext(r.Context())
+		assert.True(t, Envelope(r.Context()))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest("DELETE", "/api/v1/users/1", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(timing.WithRecorder(req.Context(), rec)))
+
+	assert.Same(t, rec, got)
+}
+
+func TestFormat(t *testing.T) {
+	header := Format(timing.Breakdown{
+		Total:      12500 * time.Microsecond,
+		Middleware: 500 * time.Microsecond,
+		Handler:    12 * time.Millisecond,
+		UseCase:    11 * time.Millisecond,
+		Repository: 8123456 * time.Nanosecond,
+	})
+	assert.Equal(t, "total;dur=12.5, middleware;dur=0.5, handler;dur=12, usecase;dur=11, repository;dur=8.123", header)
+}
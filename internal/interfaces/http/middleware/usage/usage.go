@@ -0,0 +1,73 @@
+// Package usage meters the API calls made with each key on behalf of each
+// tenant.
+package usage
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/request"
+)
+
+// Recorder counts a call to route with the sizes of its request and
+// response bodies
+type Recorder interface {
+	Record(keyID, tenantID, method, route string, bytesIn, bytesOut int64)
+}
+
+// Meter creates a middleware recording each call once it is handled. The
+// key is the API key the call authenticated with and the tenant that of
+// its request context, so it must run after the authentication middlewares
+// and requestcontext.Builder; calls made otherwise are recorded without
+// them. Routes are recorded by pattern, or as unmatched.
+func Meter(recorder Recorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := &countingBody{ReadCloser: r.Body}
+			if r.Body != nil {
+				r.Body = body
+			}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r)
+
+			route := "unmatched"
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			keyID, tenantID := credential(r)
+			recorder.Record(keyID, tenantID, r.Method, route, body.read, int64(ww.BytesWritten()))
+		})
+	}
+}
+
+// credential returns the API key and tenant of the call's subject. Service
+// accounts are the subjects of their keys, while plain API keys are their
+// own subject.
+func credential(r *http.Request) (keyID, tenantID string) {
+	subject, ok := policy.SubjectFromContext(r.Context())
+	if !ok || subject.Attributes["credential"] != "api_key" {
+		return "", ""
+	}
+	keyID, _ = subject.Attributes["key_id"].(string)
+	if keyID == "" {
+		keyID = subject.ID
+	}
+	return keyID, request.Current(r.Context()).TenantID
+}
+
+// countingBody counts the bytes read from a request body
+type countingBody struct {
+	io.ReadCloser
+	read int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
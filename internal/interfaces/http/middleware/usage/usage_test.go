@@ -0,0 +1,96 @@
+package usage
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/interfaces/http/middleware/requestcontext"
+)
+
+// call is a call recorded by recordingRecorder
+type call struct {
+	keyID, tenantID, method, route string
+	bytesIn, bytesOut              int64
+}
+
+type recordingRecorder struct {
+	calls []call
+}
+
+func (r *recordingRecorder) Record(keyID, tenantID, method, route string, bytesIn, bytesOut int64) {
+	r.calls = append(r.calls, call{keyID, tenantID, method, route, bytesIn, bytesOut})
+}
+
+func newMeteredRouter(recorder Recorder, subject *policy.Subject) http.Handler {
+	r := chi.NewRouter()
+	if subject != nil {
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				next.ServeHTTP(w, req.WithContext(policy.WithSubject(req.Context(), *subject)))
+			})
+		})
+	}
+	r.Use(requestcontext.Builder{}.Middleware)
+	r.Use(Meter(recorder))
+	r.Post("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append(body, body...))
+	})
+	return r
+}
+
+func TestMeter(t *testing.T) {
+	tests := []struct {
+		name     string
+		subject  *policy.Subject
+		expected call
+	}{
+		{
+			"api key",
+			&policy.Subject{ID: "key_1", Attributes: map[string]interface{}{"credential": "api_key"}},
+			call{keyID: "key_1"},
+		},
+		{
+			"service account key",
+			&policy.Subject{ID: "usr_svc", Attributes: map[string]interface{}{"credential": "api_key", "key_id": "key_2", "organization_id": "org_1"}},
+			call{keyID: "key_2", tenantID: "org_1"},
+		},
+		{
+			"user token",
+			&policy.Subject{ID: "usr_1"},
+			call{},
+		},
+		{"unauthenticated", nil, call{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordingRecorder{}
+			w := httptest.NewRecorder()
+			newMeteredRouter(recorder, tt.subject).ServeHTTP(w, httptest.NewRequest("POST", "/users/42", strings.NewReader("hello")))
+
+			require.Len(t, recorder.calls, 1)
+			expected := tt.expected
+			expected.method, expected.route, expected.bytesIn, expected.bytesOut = "POST", "/users/{id}", 5, 10
+			assert.Equal(t, expected, recorder.calls[0])
+			assert.Equal(t, "hellohello", w.Body.String())
+		})
+	}
+}
+
+func TestMeter_Unmatched(t *testing.T) {
+	recorder := &recordingRecorder{}
+	newMeteredRouter(recorder, nil).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+
+	require.Len(t, recorder.calls, 1)
+	assert.Equal(t, "unmatched", recorder.calls[0].route)
+	assert.Equal(t, "GET", recorder.calls[0].method)
+}
@@ -0,0 +1,94 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDoc = `{
+	"swagger": "2.0",
+	"paths": {
+		"/api/v1/users": {
+			"get": {"description": "List users"},
+			"post": {"description": "Create a user"}
+		},
+		"/api/v1/users/{id}": {
+			"get": {}
+		}
+	},
+	"securityDefinitions": {
+		"ApiKeyAuth": {"type": "apiKey", "name": "Authorization", "in": "header"}
+	}
+}`
+
+func noop(w http.ResponseWriter, r *http.Request) {}
+
+func newTestRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Get("/", noop)
+		r.Route("/users", func(r chi.Router) {
+			passthrough := func(next http.Handler) http.Handler { return next }
+			r.With(passthrough).Method("GET", "/", Secured(http.HandlerFunc(noop), "users:read"))
+			r.Method("POST", "/", Secured(http.HandlerFunc(noop), "users:write"))
+			r.With(passthrough).Method("GET", "/{id}", Secured(http.HandlerFunc(noop), "users:read"))
+		})
+	})
+	return r
+}
+
+func TestRouteScopes(t *testing.T) {
+	scopes, err := RouteScopes(newTestRouter())
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]map[string][]string{
+		"/api/v1/users": {
+			"get":  {"users:read"},
+			"post": {"users:write"},
+		},
+		"/api/v1/users/{id}": {
+			"get": {"users:read"},
+		},
+	}, scopes)
+}
+
+func TestDocHandler(t *testing.T) {
+	r := newTestRouter()
+	handler := DocHandler(func() (string, error) { return testDoc, nil }, r, map[string]string{
+		"users:read":  "Read users",
+		"users:write": "Write users",
+	})
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/swagger/doc.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Description string                `json:"description"`
+			Security    []map[string][]string `json:"security"`
+		} `json:"paths"`
+		SecurityDefinitions map[string]map[string]interface{} `json:"securityDefinitions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+
+	assert.Contains(t, spec.SecurityDefinitions, "ApiKeyAuth")
+	require.Contains(t, spec.SecurityDefinitions, SecurityScheme)
+	assert.Equal(t, "oauth2", spec.SecurityDefinitions[SecurityScheme]["type"])
+
+	list := spec.Paths["/api/v1/users"]["get"]
+	assert.Equal(t, []map[string][]string{{SecurityScheme: {"users:read"}}}, list.Security)
+	assert.Equal(t, "List users\n\nRequires scopes: users:read", list.Description)
+
+	get := spec.Paths["/api/v1/users/{id}"]["get"]
+	assert.Equal(t, "Requires scopes: users:read", get.Description)
+
+	create := spec.Paths["/api/v1/users"]["post"]
+	assert.Equal(t, []map[string][]string{{SecurityScheme: {"users:write"}}}, create.Security)
+}
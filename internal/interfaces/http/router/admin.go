@@ -0,0 +1,112 @@
+package router
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"clean-architecture/internal/interfaces/http/handlers"
+	"clean-architecture/internal/interfaces/http/middleware/logging"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/metrics"
+)
+
+// AdminDependencies holds the dependencies of the admin router
+type AdminDependencies struct {
+	Logger      logger.Logger
+	Metrics     *metrics.Registry
+	Leadership  *handlers.LeadershipHandler
+	DeadLetters *handlers.DeadLetterHandler
+	Events      *handlers.EventLogHandler
+	Deletions   *handlers.UserDeletionHandler
+	UserMerges  *handlers.UserMergeHandler
+	LogLevels   *handlers.LogLevelHandler
+	Startup     *handlers.StartupHandler
+	// Replays serves /replay/requests; nil when REPLAY_ENABLED is off
+	Replays *handlers.RequestReplayHandler
+}
+
+// NewAdminRouter creates the router for the internal admin listener serving
+// metrics, profiling and operational endpoints. It must not be exposed
+// publicly.
+func NewAdminRouter(deps AdminDependencies) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middleware.RequestID)
+	r.Use(middleware.Recoverer)
+	r.Use(logging.LoggerMiddleware(deps.Logger))
+
+	r.Handle("/metrics", deps.Metrics.Handler())
+	r.Get("/leaders", deps.Leadership.Status)
+
+	r.Route("/dlq", func(r chi.Router) {
+		r.Get("/", deps.DeadLetters.ListDeadLetters)
+		r.Post("/replay", deps.DeadLetters.ReplayDeadLetters)
+		r.Get("/{id}", deps.DeadLetters.GetDeadLetter)
+		r.Post("/{id}/replay", deps.DeadLetters.ReplayDeadLetter)
+	})
+
+	r.Route("/events", func(r chi.Router) {
+		r.Get("/", deps.Events.ListEvents)
+		r.Post("/replay", deps.Events.ReplayEvents)
+		r.Get("/{id}", deps.Events.GetEvent)
+		r.Post("/{id}/replay", deps.Events.ReplayEvent)
+	})
+
+	r.Route("/deletions", func(r chi.Router) {
+		r.Get("/", deps.Deletions.ListDeletions)
+		r.Delete("/{id}", deps.Deletions.CancelDeletionByID)
+	})
+
+	r.Route("/users", func(r chi.Router) {
+		r.Get("/duplicates", deps.UserMerges.FindDuplicates)
+		r.Post("/merge", deps.UserMerges.MergeUsers)
+	})
+
+	r.Route("/loggers", func(r chi.Router) {
+		r.Get("/", deps.LogLevels.ListLevels)
+		r.Put("/{module}", deps.LogLevels.SetLevel)
+	})
+
+	r.Route("/startup", func(r chi.Router) {
+		r.Get("/", deps.Startup.Report)
+		r.Get("/graph", deps.Startup.Graph)
+	})
+
+	if deps.Replays != nil {
+		r.Route("/replay/requests", func(r chi.Router) {
+			r.Get("/", deps.Replays.ListCaptures)
+			r.Get("/{id}", deps.Replays.GetCapture)
+			r.Post("/{id}/replay", deps.Replays.ReplayCapture)
+		})
+	}
+
+	r.Route("/debug/pprof", func(r chi.Router) {
+		r.HandleFunc("/", pprof.Index)
+		r.HandleFunc("/cmdline", pprof.Cmdline)
+		r.HandleFunc("/profile", pprof.Profile)
+		r.HandleFunc("/symbol", pprof.Symbol)
+		r.HandleFunc("/trace", pprof.Trace)
+		r.Handle("/{profile}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pprof.Handler(chi.URLParam(r, "profile")).ServeHTTP(w, r)
+		}))
+	})
+
+	return r
+}
+
+// NewHealthRouter creates the router for the health probe listener. It has
+// a minimal middleware stack so probes stay cheap and are not logged.
+func NewHealthRouter(health *handlers.HealthHandler) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middleware.Recoverer)
+
+	r.Get("/health", health.Ready)
+	r.Get("/health/live", health.Live)
+	r.Get("/health/ready", health.Ready)
+
+	return r
+}
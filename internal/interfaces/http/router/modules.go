@@ -0,0 +1,26 @@
+package router
+
+import (
+	"slices"
+
+	"clean-architecture/configs"
+)
+
+// routeModules mounts the route groups of the API listener. Groups named in
+// SERVER_DISABLED_ROUTES are left out, so their routes answer 404 Not
+// Found and are missing from the served OpenAPI spec.
+type routeModules struct {
+	server configs.ServerConfig
+}
+
+// mount registers the routes of group with register unless the group is
+// disabled. Groups must be listed in configs.RouteGroups, so each one can
+// be disabled.
+func (m routeModules) mount(group string, register func()) {
+	if !slices.Contains(configs.RouteGroups, group) {
+		panic("router: route group " + group + " is missing from configs.RouteGroups")
+	}
+	if m.server.RouteEnabled(group) {
+		register()
+	}
+}
@@ -1,34 +1,247 @@
 package router
 
 import (
+	"context"
 	"net/http"
+	"slices"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/request"
+	"clean-architecture/internal/interfaces/http/adminui"
 	"clean-architecture/internal/interfaces/http/handlers"
+	authmw "clean-architecture/internal/interfaces/http/middleware/auth"
+	"clean-architecture/internal/interfaces/http/middleware/authz"
+	consistencymw "clean-architecture/internal/interfaces/http/middleware/consistency"
+	"clean-architecture/internal/interfaces/http/middleware/contenttype"
+	"clean-architecture/internal/interfaces/http/middleware/correlation"
+	"clean-architecture/internal/interfaces/http/middleware/diagnostics"
+	"clean-architecture/internal/interfaces/http/middleware/features"
+	"clean-architecture/internal/interfaces/http/middleware/limits"
 	"clean-architecture/internal/interfaces/http/middleware/logging"
+	"clean-architecture/internal/interfaces/http/middleware/paths"
+	"clean-architecture/internal/interfaces/http/middleware/requestcontext"
+	"clean-architecture/internal/interfaces/http/middleware/scopes"
+	"clean-architecture/internal/interfaces/http/middleware/servertiming"
+	"clean-architecture/internal/interfaces/http/middleware/usage"
+	"clean-architecture/internal/interfaces/http/openapi"
+	"clean-architecture/pkg/canary"
+	"clean-architecture/pkg/clientip"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/cost"
+	"clean-architecture/pkg/httpserver"
 	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/metrics"
+	"clean-architecture/pkg/retryafter"
+	"clean-architecture/pkg/scim"
+	"clean-architecture/pkg/slo"
+	"clean-architecture/pkg/timing"
+	"clean-architecture/pkg/utils"
 
 	httpSwagger "github.com/swaggo/http-swagger"
+	"github.com/swaggo/swag"
 )
 
+// Dependencies holds everything the router needs to wire routes
+type Dependencies struct {
+	Logger              logger.Logger
+	Config              *configs.Config
+	UserHandler         *handlers.UserHandler
+	UserDeletionHandler *handlers.UserDeletionHandler
+	ExportHandler       *handlers.ExportHandler
+	ImportHandler       *handlers.ImportHandler
+	// JobHandler serves /api/v1/jobs, the status of background jobs such as
+	// user creations answered with 202
+	JobHandler          *handlers.JobHandler
+	SavedViewHandler    *handlers.SavedViewHandler
+	ChangelogHandler    *handlers.ChangelogHandler
+	CapabilitiesHandler *handlers.CapabilitiesHandler
+	SchemaHandler       *handlers.SchemaHandler
+	Metrics             *metrics.Registry
+	// PolicyEngine authorizes API routes; when nil, routes are served
+	// without authorization checks
+	PolicyEngine policy.Engine
+	// SLO tracks route objectives; nil disables tracking
+	SLO *slo.Tracker
+	// Costs counts the queries, rows, cache lookups and external calls of
+	// each request, for the access log and route budgets; nil disables
+	// counting
+	Costs *cost.Tracker
+	// Downloads serves signed storage URLs below /api/v1/downloads; nil
+	// when the storage driver serves them itself
+	Downloads http.Handler
+	// SCIMHandler serves SCIM provisioning below /scim/v2; nil when it is
+	// not configured
+	SCIMHandler *handlers.SCIMHandler
+	// Authenticator verifies bearer tokens. When set, guarded routes reject
+	// unauthenticated requests; when nil, authentication is disabled.
+	Authenticator authmw.Authenticator
+	// AuthHandler serves POST /api/v1/auth/login, /api/v1/auth/signup,
+	// /api/v1/auth/session and /api/v1/auth/logout
+	// and, when configured, SAML and OpenID Connect single sign-on; nil
+	// when authentication is disabled
+	AuthHandler *handlers.AuthHandler
+	// APIKeys verifies the X-API-Key header of service-to-service requests
+	// and APIKeyHandler serves /api/v1/apikeys; both are nil when
+	// authentication is disabled
+	APIKeys       authmw.Authenticator
+	APIKeyHandler *handlers.APIKeyHandler
+	// MFAHandler serves /api/v1/me/mfa; nil when MFA is not configured
+	MFAHandler *handlers.MFAHandler
+	// PreferencesHandler serves /api/v1/me/preferences and Preferences
+	// returns the preferences API requests are localized with
+	PreferencesHandler *handlers.UserPreferencesHandler
+	Preferences        func(ctx context.Context, userID string) entities.UserPreferences
+	// Experiments returns the A/B experiment assignments of a caller, read
+	// by handlers from the request context and listed at
+	// /api/v1/me/experiments; nil puts requests in no experiment
+	Experiments func(userID string) request.Assignments
+	// LockoutHandler serves /api/v1/lockouts; nil when authentication is
+	// disabled
+	LockoutHandler *handlers.LockoutHandler
+	// OrganizationHandler serves /api/v1/organizations and
+	// ServiceAccountHandler the service accounts below each organization;
+	// ServiceAccountHandler is nil when authentication is disabled
+	OrganizationHandler   *handlers.OrganizationHandler
+	ServiceAccountHandler *handlers.ServiceAccountHandler
+	// QuotaHandler serves /api/v1/quotas
+	QuotaHandler *handlers.QuotaHandler
+	// FeatureFlagHandler serves /api/v1/feature-flags
+	FeatureFlagHandler *handlers.FeatureFlagHandler
+	// AuditLogHandler serves /api/v1/audit-log; nil when the audit log is
+	// disabled
+	AuditLogHandler *handlers.AuditLogHandler
+	// WebhookHandler serves /api/v1/webhooks
+	WebhookHandler *handlers.WebhookHandler
+	// EventStreamHandler serves /api/v1/events
+	EventStreamHandler *handlers.EventStreamHandler
+	// AdminUI serves the embedded admin UI below /admin; nil when it is
+	// disabled
+	AdminUI http.Handler
+	// BillingHandler serves /api/v1/billing and Features gates exports and
+	// imports on the plan subscribed to; both are nil when billing is not
+	// configured
+	BillingHandler *handlers.BillingHandler
+	Features       features.Gate
+	// EmailSuppressionHandler serves /api/v1/email-suppressions and, when
+	// EMAIL_FEEDBACK_PROVIDER is set, /api/v1/email/feedback
+	EmailSuppressionHandler *handlers.EmailSuppressionHandler
+	// Usage meters the calls to /api/v1 and UsageHandler serves
+	// /api/v1/usage; both are nil when metering is disabled
+	Usage        usage.Recorder
+	UsageHandler *handlers.UsageHandler
+	// Sessions verifies the session cookie named by AUTH_SESSIONS_COOKIE_NAME;
+	// nil when server-side sessions are disabled
+	Sessions authmw.SessionAuthenticator
+	// FeatureFlags resolves the feature flags of API requests; nil leaves
+	// every flag off
+	FeatureFlags request.FlagSource
+	// Replay captures the requests served for the admin replay endpoints;
+	// nil when REPLAY_ENABLED is off
+	Replay func(http.Handler) http.Handler
+	// Canary splits the traffic of the routes under experiment between
+	// their handlers; nil serves every request with the control
+	Canary *canary.Experiments
+	// ClientIP resolves the client address login lockouts count by; nil
+	// takes the address of the connection
+	ClientIP *clientip.Resolver
+}
+
 // NewRouter creates a new Chi router with middleware
-func NewRouter(logger logger.Logger, userHandler *handlers.UserHandler) http.Handler {
+func NewRouter(deps Dependencies) http.Handler {
 	r := chi.NewRouter()
+	userHandler := deps.UserHandler
+	deletionHandler := deps.UserDeletionHandler
+	exportHandler := deps.ExportHandler
+	importHandler := deps.ImportHandler
+	viewHandler := deps.SavedViewHandler
+	quotaHandler := deps.QuotaHandler
+	flagHandler := deps.FeatureFlagHandler
+	webhookHandler := deps.WebhookHandler
+	eventStreamHandler := deps.EventStreamHandler
+	suppressionHandler := deps.EmailSuppressionHandler
+	api := guard{
+		engine:        deps.PolicyEngine,
+		requireAuth:   deps.Authenticator != nil,
+		requireScopes: deps.Config.Auth.RequireScopes,
+		contentTypes:  deps.Config.Server.ContentTypes,
+		features:      deps.Features,
+	}
+	exports := api.requiring(entities.FeatureExports)
+	imports := api.requiring(entities.FeatureImports)
+	modules := routeModules{server: deps.Config.Server}
+	if disabled := deps.Config.Server.DisabledRoutes; len(disabled) > 0 {
+		deps.Logger.WithField("groups", disabled).Info("Route groups disabled")
+	}
 
 	// Middleware
 	r.Use(middleware.RequestID)
+	r.Use(correlation.Middleware)
+	r.Use(requestcontext.Middleware(deps.Logger))
+	// The client address is resolved before RealIP rewrites it from
+	// headers any client can send
+	clientIPs := deps.ClientIP
+	if clientIPs == nil {
+		clientIPs, _ = clientip.NewResolver(nil)
+	}
+	r.Use(clientIPs.Middleware)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
-	r.Use(logging.LoggerMiddleware(logger))
+	// Sloppily built URLs are redirected to the route they were meant for
+	// rather than answered with 404
+	if server := deps.Config.Server; server.TrailingSlashRedirect || server.CleanPathRedirect || server.CaseInsensitivePaths {
+		r.Use(paths.Normalize(r, paths.Options{
+			TrailingSlash:   server.TrailingSlashRedirect,
+			CleanPaths:      server.CleanPathRedirect,
+			CaseInsensitive: server.CaseInsensitivePaths,
+		}))
+	}
+	// 429 and 503 responses always tell clients when to retry
+	r.Use(retryafter.Middleware(deps.Config.APIDefaults.RetryAfter))
+	if mode := deps.Config.Server.DebugTimings; mode == "header" || mode == "envelope" {
+		r.Use(servertiming.Middleware(mode == "envelope"))
+	}
+	if threshold := deps.Config.Server.SlowRequestThreshold; threshold > 0 {
+		r.Use(diagnostics.SlowRequests(threshold, deps.Logger))
+	}
+	r.Use(deps.Metrics.Middleware)
+	if deps.SLO != nil {
+		r.Use(deps.SLO.Middleware)
+	}
+	if deps.Costs != nil {
+		r.Use(deps.Costs.Middleware)
+	}
+	r.Use(middleware.RequestSize(int64(deps.Config.Server.MaxBodySize)))
+	r.Use(httpserver.SlowClients(httpserver.SlowClientOptions{
+		ReadTimeout:  deps.Config.Server.ReadTimeout,
+		StallTimeout: deps.Config.Server.BodyStallTimeout,
+		MinRate:      int64(deps.Config.Server.MinBodyRate),
+		OnSlowClient: func(r *http.Request, err error) {
+			deps.Logger.WithFields(map[string]interface{}{
+				"remote_addr": r.RemoteAddr,
+				"method":      r.Method,
+				"path":        r.URL.Path,
+			}).Warn("Disconnecting slow client")
+		},
+	}))
+	// Captures read bodies below the size limit and the slow client timeouts
+	if deps.Replay != nil {
+		r.Use(deps.Replay)
+	}
+	r.Use(limits.MaxCollectionSize(deps.Config.Server.MaxCollectionSize))
+	r.Use(logging.LoggerMiddleware(deps.Logger))
+	r.Use(consistencymw.Middleware(deps.Config.Database.ReadYourWritesWindow))
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   allowedHeaders(deps.Config.Canary.Header),
+		ExposedHeaders:   []string{"Link", "Location", "X-Correlation-ID", servertiming.Header, consistency.Header, handlers.UploadOffsetHeader, handlers.UploadLengthHeader, handlers.PreferenceAppliedHeader, utils.EnvelopeHeader, canary.VariantHeader},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -36,23 +249,453 @@ func NewRouter(logger logger.Logger, userHandler *handlers.UserHandler) http.Han
 	// Health check endpoint
 	r.Get("/health", handlers.HealthCheck)
 
-	// Serve Swagger UI
-	r.Get("/swagger/*", httpSwagger.WrapHandler)
+	// Serve Swagger UI; the spec is augmented with the scopes routes declare
+	modules.mount("swagger", func() {
+		r.Get("/swagger/doc.json", openapi.DocHandler(func() (string, error) { return swag.ReadDoc() }, r, policy.Scopes))
+		r.Get("/swagger/*", httpSwagger.WrapHandler)
+	})
 
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
+		if deps.Authenticator != nil {
+			r.Use(authmw.Authenticate(deps.Authenticator))
+		}
+		if deps.Sessions != nil {
+			r.Use(authmw.AuthenticateSession(deps.Sessions, deps.Config.Auth.Sessions.CookieName))
+		}
+		if deps.APIKeys != nil {
+			r.Use(authmw.AuthenticateAPIKey(deps.APIKeys))
+		}
+		// Resolve the caller once credentials are verified, for everything
+		// handling the request after it
+		r.Use(requestcontext.Builder{
+			DefaultLocale: deps.Config.Email.Locale,
+			Locales:       handlers.MessageLocales(),
+			Flags:         deps.FeatureFlags,
+			Preferences:   deps.Preferences,
+			Experiments:   deps.Experiments,
+		}.Middleware)
+		if deps.Usage != nil {
+			r.Use(usage.Meter(deps.Usage))
+		}
+
 		// Root endpoint
 		r.Get("/", handlers.RootHandler)
+		r.Get("/changelog", deps.ChangelogHandler.GetChangelog)
+		r.Get("/capabilities", deps.CapabilitiesHandler.GetCapabilities)
+		r.Get("/schemas", deps.SchemaHandler.ListSchemas)
+		r.Get("/schemas/{name}", deps.SchemaHandler.GetSchema)
+
+		if authHandler := deps.AuthHandler; authHandler != nil {
+			modules.mount("auth", func() {
+				r.With(contenttype.Require(api.contentTypes...)).Post("/auth/login", authHandler.Login)
+				r.With(contenttype.Require(api.contentTypes...)).Post("/auth/signup", authHandler.Signup)
+				r.With(contenttype.Require(api.contentTypes...)).Post("/auth/signup/verify", authHandler.VerifySignup)
+				r.With(contenttype.Require(api.contentTypes...)).Post("/auth/session", authHandler.CreateSession)
+				r.Delete("/auth/session", authHandler.DeleteSession)
+				r.Post("/auth/logout", authHandler.Logout)
+				r.Route("/auth/saml/{tenant}", func(r chi.Router) {
+					r.Get("/metadata", authHandler.SAMLMetadata)
+					r.Get("/login", authHandler.SAMLLogin)
+					r.With(contenttype.Require("application/x-www-form-urlencoded")).Post("/acs", authHandler.SAMLACS)
+				})
+				r.Get("/auth/oidc/login", authHandler.OIDCLogin)
+				r.Get("/auth/oidc/callback", authHandler.OIDCCallback)
+			})
+		}
 
-		// User routes
+		// User routes. Upserts are keyed by email rather than a user ID, so
+		// they take a custom method suffix instead of a path segment.
+		modules.mount("users", func() {
+			api.handle(r, http.MethodPut, "/users:upsert", userHandler.UpsertUser, "users:upsert", authz.Collection("user"), policy.ScopeUsersWrite)
+		})
 		r.Route("/users", func(r chi.Router) {
-			r.Get("/", userHandler.ListUsers)
-			r.Post("/", userHandler.CreateUser)
-			r.Get("/{id}", userHandler.GetUser)
-			r.Put("/{id}", userHandler.UpdateUser)
-			r.Delete("/{id}", userHandler.DeleteUser)
+			// Bulk exports run in the background, or stream to the client, and
+			// are limited to admins and, when billing is configured, to plans
+			// including them
+			modules.mount("bulk", func() {
+				exports.handle(r, http.MethodPost, "/exports", exportHandler.RequestExport, "users:export", authz.Collection("user"), policy.ScopeAdmin)
+				exports.handle(r, http.MethodGet, "/exports/stream", exportHandler.StreamExport, "users:export", authz.Collection("user"), policy.ScopeAdmin)
+				exports.handle(r, http.MethodGet, "/exports/{id}", exportHandler.GetExport, "users:export", authz.Collection("user"), policy.ScopeAdmin)
+
+				// Imports are uploaded in resumable chunks, then processed in the
+				// background; they are gated on the plan like exports
+				imports.handle(r, http.MethodPost, "/imports/uploads", importHandler.CreateUpload, "users:import", authz.Collection("user"), policy.ScopeAdmin)
+				imports.handle(r, http.MethodGet, "/imports/uploads/{id}", importHandler.GetUpload, "users:import", authz.Collection("user"), policy.ScopeAdmin)
+				imports.handle(r, http.MethodHead, "/imports/uploads/{id}", importHandler.GetUpload, "users:import", authz.Collection("user"), policy.ScopeAdmin)
+				imports.accepting(handlers.ChunkContentTypes...).handle(r, http.MethodPatch, "/imports/uploads/{id}", importHandler.AppendChunk, "users:import", authz.Collection("user"), policy.ScopeAdmin)
+				imports.handle(r, http.MethodDelete, "/imports/uploads/{id}", importHandler.AbortUpload, "users:import", authz.Collection("user"), policy.ScopeAdmin)
+				imports.handle(r, http.MethodPost, "/imports/uploads/{id}/complete", importHandler.CompleteUpload, "users:import", authz.Collection("user"), policy.ScopeAdmin)
+				imports.handle(r, http.MethodGet, "/imports/{id}", importHandler.GetImport, "users:import", authz.Collection("user"), policy.ScopeAdmin)
+			})
+
+			// Search-as-you-type is answered from memory, like listing
+			modules.mount("users", func() {
+				api.handle(r, http.MethodGet, "/suggest", userHandler.SuggestUsers, "users:list", authz.Collection("user"), policy.ScopeUsersRead)
+
+				api.handle(r, http.MethodGet, "/", experiment(deps.Canary, "users.list", userHandler.ListUsers, userHandler.ListUsersOrdered), "users:list", authz.Collection("user"), policy.ScopeUsersRead)
+				api.handle(r, http.MethodPost, "/", userHandler.CreateUser, "users:create", authz.Collection("user"), policy.ScopeUsersWrite)
+				api.handle(r, http.MethodGet, "/{id}", userHandler.GetUser, "users:read", userResource, policy.ScopeUsersRead)
+				api.handle(r, http.MethodPut, "/{id}", userHandler.UpdateUser, "users:update", userResource, policy.ScopeUsersWrite)
+				api.handle(r, http.MethodDelete, "/{id}", userHandler.DeleteUser, "users:delete", userResource, policy.ScopeUsersWrite)
+			})
+		})
+
+		// Saved views are personal unless shared with the organization; they
+		// are run with GET /users?view={id}
+		modules.mount("views", func() {
+			r.Route("/views", func(r chi.Router) {
+				api.handle(r, http.MethodGet, "/", viewHandler.ListViews, "views:list", authz.Collection("view"), policy.ScopeUsersRead)
+				api.handle(r, http.MethodPost, "/", viewHandler.CreateView, "views:create", authz.Collection("view"), policy.ScopeUsersRead)
+				api.handle(r, http.MethodGet, "/{id}", viewHandler.GetView, "views:read", viewResource, policy.ScopeUsersRead)
+				api.handle(r, http.MethodDelete, "/{id}", viewHandler.DeleteView, "views:delete", viewResource, policy.ScopeUsersRead)
+			})
+		})
+
+		// API keys authenticate other services and are managed by admins
+		modules.mount("apikeys", func() {
+			if keyHandler := deps.APIKeyHandler; keyHandler != nil {
+				r.Route("/apikeys", func(r chi.Router) {
+					api.handle(r, http.MethodGet, "/", keyHandler.ListAPIKeys, "apikeys:list", authz.Collection("apikey"), policy.ScopeAdmin)
+					api.handle(r, http.MethodPost, "/", keyHandler.CreateAPIKey, "apikeys:create", authz.Collection("apikey"), policy.ScopeAdmin)
+					api.handle(r, http.MethodGet, "/{id}", keyHandler.GetAPIKey, "apikeys:read", apiKeyResource, policy.ScopeAdmin)
+					api.handle(r, http.MethodDelete, "/{id}", keyHandler.RevokeAPIKey, "apikeys:revoke", apiKeyResource, policy.ScopeAdmin)
+				})
+			}
+		})
+
+		// Admins lift login lockouts before they expire
+		modules.mount("lockouts", func() {
+			if lockoutHandler := deps.LockoutHandler; lockoutHandler != nil {
+				r.Route("/lockouts", func(r chi.Router) {
+					api.handle(r, http.MethodDelete, "/users/{username}", lockoutHandler.UnlockUser, "lockouts:delete", authz.Collection("lockout"), policy.ScopeAdmin)
+					api.handle(r, http.MethodDelete, "/ips/{ip}", lockoutHandler.UnlockIP, "lockouts:delete", authz.Collection("lockout"), policy.ScopeAdmin)
+				})
+			}
+		})
+
+		// Service accounts belong to an organization and authenticate with
+		// their own API keys, which grant at most the account's scopes
+		modules.mount("organizations", func() {
+			r.Route("/organizations", func(r chi.Router) {
+				orgHandler := deps.OrganizationHandler
+				api.handle(r, http.MethodGet, "/", orgHandler.ListOrganizations, "organizations:list", authz.Collection("organization"), policy.ScopeAdmin)
+				api.handle(r, http.MethodPost, "/", orgHandler.CreateOrganization, "organizations:create", authz.Collection("organization"), policy.ScopeAdmin)
+				api.handle(r, http.MethodGet, "/{id}", orgHandler.GetOrganization, "organizations:read", organizationResource, policy.ScopeAdmin)
+
+				if accountHandler := deps.ServiceAccountHandler; accountHandler != nil {
+					r.Route("/{id}/service-accounts", func(r chi.Router) {
+						api.handle(r, http.MethodGet, "/", accountHandler.ListServiceAccounts, "serviceaccounts:list", organizationResource, policy.ScopeAdmin)
+						api.handle(r, http.MethodPost, "/", accountHandler.CreateServiceAccount, "serviceaccounts:create", organizationResource, policy.ScopeAdmin)
+						api.handle(r, http.MethodGet, "/{account_id}", accountHandler.GetServiceAccount, "serviceaccounts:read", serviceAccountResource, policy.ScopeAdmin)
+						api.handle(r, http.MethodDelete, "/{account_id}", accountHandler.DeleteServiceAccount, "serviceaccounts:delete", serviceAccountResource, policy.ScopeAdmin)
+						api.handle(r, http.MethodGet, "/{account_id}/keys", accountHandler.ListServiceAccountKeys, "apikeys:list", serviceAccountResource, policy.ScopeAdmin)
+						api.handle(r, http.MethodPost, "/{account_id}/keys", accountHandler.CreateServiceAccountKey, "apikeys:create", serviceAccountResource, policy.ScopeAdmin)
+						api.handle(r, http.MethodDelete, "/{account_id}/keys/{key_id}", accountHandler.RevokeServiceAccountKey, "apikeys:revoke", serviceAccountResource, policy.ScopeAdmin)
+					})
+				}
+			})
+		})
+
+		// Admins view and adjust how many users may exist
+		modules.mount("quotas", func() {
+			r.Route("/quotas", func(r chi.Router) {
+				api.handle(r, http.MethodGet, "/", quotaHandler.ListQuotas, "quotas:list", authz.Collection("quota"), policy.ScopeAdmin)
+				api.handle(r, http.MethodGet, "/{resource}", quotaHandler.GetQuota, "quotas:read", quotaResource, policy.ScopeAdmin)
+				api.handle(r, http.MethodPut, "/{resource}", quotaHandler.UpdateQuota, "quotas:update", quotaResource, policy.ScopeAdmin)
+			})
+		})
+
+		// Admins switch feature flags away from their configured defaults
+		modules.mount("feature-flags", func() {
+			r.Route("/feature-flags", func(r chi.Router) {
+				api.handle(r, http.MethodGet, "/", flagHandler.ListFeatureFlags, "featureflags:list", authz.Collection("featureflag"), policy.ScopeAdmin)
+				api.handle(r, http.MethodPut, "/{key}", flagHandler.UpdateFeatureFlag, "featureflags:update", featureFlagResource, policy.ScopeAdmin)
+			})
+		})
+
+		// Admins register the URLs domain events are posted to and inspect
+		// how each delivery went
+		modules.mount("webhooks", func() {
+			r.Route("/webhooks", func(r chi.Router) {
+				api.handle(r, http.MethodPost, "/", webhookHandler.CreateWebhook, "webhooks:create", authz.Collection("webhook"), policy.ScopeAdmin)
+				api.handle(r, http.MethodGet, "/", webhookHandler.ListWebhooks, "webhooks:list", authz.Collection("webhook"), policy.ScopeAdmin)
+				api.handle(r, http.MethodGet, "/{id}", webhookHandler.GetWebhook, "webhooks:read", webhookResource, policy.ScopeAdmin)
+				api.handle(r, http.MethodPut, "/{id}", webhookHandler.UpdateWebhook, "webhooks:update", webhookResource, policy.ScopeAdmin)
+				api.handle(r, http.MethodDelete, "/{id}", webhookHandler.DeleteWebhook, "webhooks:delete", webhookResource, policy.ScopeAdmin)
+				api.handle(r, http.MethodGet, "/{id}/deliveries", webhookHandler.ListDeliveries, "webhooks:read", webhookResource, policy.ScopeAdmin)
+			})
+		})
+
+		// Admins follow user changes as Server-Sent Events. Events carry
+		// unmasked user data, like webhook deliveries.
+		modules.mount("events", func() {
+			r.Route("/events", func(r chi.Router) {
+				api.handle(r, http.MethodGet, "/", eventStreamHandler.Stream, "events:stream", authz.Collection("event"), policy.ScopeAdmin)
+			})
+		})
+
+		// Admins browse the audit log recorded from domain events
+		modules.mount("audit-log", func() {
+			if auditHandler := deps.AuditLogHandler; auditHandler != nil {
+				api.handle(r, http.MethodGet, "/audit-log", auditHandler.ListAuditEntries, "audit:list", authz.Collection("audit"), policy.ScopeAdmin)
+			}
+		})
+
+		// Admins report the calls made with each key, for billing and quotas
+		modules.mount("usage", func() {
+			if usageHandler := deps.UsageHandler; usageHandler != nil {
+				api.handle(r, http.MethodGet, "/usage", usageHandler.GetUsage, "usage:read", authz.Collection("usage"), policy.ScopeAdmin)
+			}
+		})
+
+		// Billing providers sign their webhooks instead of authenticating
+		modules.mount("billing", func() {
+			if billingHandler := deps.BillingHandler; billingHandler != nil {
+				r.Route("/billing", func(r chi.Router) {
+					r.Post("/webhooks", billingHandler.ReceiveWebhook)
+					api.handle(r, http.MethodGet, "/subscription", billingHandler.GetSubscription, "billing:read", authz.Collection("subscription"), policy.ScopeAdmin)
+				})
+			}
+		})
+
+		// Email providers sign their bounce and complaint reports instead
+		// of authenticating; admins inspect and lift the suppressions they
+		// lead to
+		modules.mount("email-suppressions", func() {
+			if deps.Config.Email.Feedback.Enabled() {
+				r.Post("/email/feedback", suppressionHandler.ReceiveFeedback)
+			}
+			r.Route("/email-suppressions", func(r chi.Router) {
+				api.handle(r, http.MethodGet, "/", suppressionHandler.ListSuppressions, "emailsuppressions:list", authz.Collection("emailsuppression"), policy.ScopeAdmin)
+				api.handle(r, http.MethodGet, "/{email}", suppressionHandler.GetSuppression, "emailsuppressions:read", emailSuppressionResource, policy.ScopeAdmin)
+				api.handle(r, http.MethodDelete, "/{email}", suppressionHandler.LiftSuppression, "emailsuppressions:delete", emailSuppressionResource, policy.ScopeAdmin)
+			})
+		})
+
+		// Self-service deletion is scheduled after a grace period and can be
+		// cancelled until then, signed in or from the emailed link
+		modules.mount("me", func() {
+			r.Route("/me", func(r chi.Router) {
+				api.handle(r, http.MethodDelete, "/", deletionHandler.ScheduleDeletion, "users:delete", selfResource, policy.ScopeUsersWrite)
+				api.handle(r, http.MethodGet, "/deletion", deletionHandler.GetDeletion, "users:read", selfResource, policy.ScopeUsersRead)
+				api.handle(r, http.MethodDelete, "/deletion", deletionHandler.CancelDeletion, "users:update", selfResource, policy.ScopeUsersWrite)
+				api.handle(r, http.MethodGet, "/preferences", deps.PreferencesHandler.GetPreferences, "users:read", selfResource, policy.ScopeUsersRead)
+				api.handle(r, http.MethodPut, "/preferences", deps.PreferencesHandler.UpdatePreferences, "users:update", selfResource, policy.ScopeUsersWrite)
+				api.handle(r, http.MethodGet, "/experiments", handlers.ListMyExperiments, "users:read", selfResource, policy.ScopeUsersRead)
+
+				// Users enroll in MFA, then confirm the secret with a code
+				if mfaHandler := deps.MFAHandler; mfaHandler != nil {
+					api.handle(r, http.MethodGet, "/mfa", mfaHandler.GetMFA, "users:read", selfResource, policy.ScopeUsersRead)
+					api.handle(r, http.MethodPost, "/mfa", mfaHandler.EnrollMFA, "users:update", selfResource, policy.ScopeUsersWrite)
+					api.handle(r, http.MethodPost, "/mfa:enable", mfaHandler.EnableMFA, "users:update", selfResource, policy.ScopeUsersWrite)
+					api.handle(r, http.MethodPost, "/mfa:disable", mfaHandler.DisableMFA, "users:update", selfResource, policy.ScopeUsersWrite)
+				}
+			})
+			r.With(contenttype.Require(api.contentTypes...)).Post("/account-deletions/cancel", deletionHandler.CancelDeletionByToken)
+		})
+
+		// New emails are confirmed from the link sent to them, signed out
+		modules.mount("users", func() {
+			r.With(contenttype.Require(api.contentTypes...)).Post("/email-changes/confirm", userHandler.ConfirmEmailChange)
+		})
+
+		// Callers poll the background jobs they requested, such as user
+		// creations answered with 202
+		modules.mount("jobs", func() {
+			api.handle(r, http.MethodGet, "/jobs/{id}", deps.JobHandler.GetJob, "jobs:read", jobResource, policy.ScopeUsersRead)
+		})
+
+		// Signed download URLs carry their own credentials
+		modules.mount("downloads", func() {
+			if deps.Downloads != nil {
+				r.Handle("/downloads/*", deps.Downloads)
+			}
 		})
 	})
 
+	// SCIM provisioning authenticates identity providers with its own
+	// bearer token rather than user credentials
+	modules.mount("scim", func() {
+		if scimHandler := deps.SCIMHandler; scimHandler != nil {
+			r.Route("/scim/v2", func(r chi.Router) {
+				r.Use(scimHandler.Authenticate, contenttype.Require(scim.MediaType, "application/json"))
+				r.Get("/ServiceProviderConfig", scimHandler.ServiceProviderConfig)
+				r.Get("/Users", scimHandler.ListUsers)
+				r.Post("/Users", scimHandler.CreateUser)
+				r.Get("/Users/{id}", scimHandler.GetUser)
+				r.Put("/Users/{id}", scimHandler.ReplaceUser)
+				r.Patch("/Users/{id}", scimHandler.PatchUser)
+				r.Delete("/Users/{id}", scimHandler.DeleteUser)
+			})
+		}
+	})
+
+	// The admin UI is a static page calling /api/v1 with the credentials
+	// of whoever signs in to it
+	modules.mount("admin", func() {
+		if deps.AdminUI != nil {
+			r.Handle(adminui.Path, deps.AdminUI)
+			r.Handle(adminui.Path+"/*", deps.AdminUI)
+		}
+	})
+
 	return r
 }
+
+// guard mounts routes behind the authentication, scope and policy checks
+// they declare
+type guard struct {
+	engine        policy.Engine
+	requireAuth   bool
+	requireScopes bool
+	// contentTypes are the media types accepted for request bodies
+	contentTypes []string
+	// features checks feature, the plan feature routes require; routes
+	// are not gated when either is unset
+	features features.Gate
+	feature  string
+}
+
+// accepting returns a guard whose routes accept request bodies of the
+// given media types instead of JSON
+func (g guard) accepting(contentTypes ...string) guard {
+	g.contentTypes = contentTypes
+	return g
+}
+
+// requiring returns a guard whose routes are only served while the plan
+// in effect includes feature
+func (g guard) requiring(feature string) guard {
+	g.feature = feature
+	return g
+}
+
+// handle mounts a route that requires the given scopes and is authorized
+// for action on resource. Scopes are enforced when AUTH_REQUIRE_SCOPES is
+// set, and on admin routes whenever authentication is enabled without a
+// policy engine, so users who can merely log in cannot reach them. They
+// are always documented in the served OpenAPI spec. Request bodies of
+// other media types are rejected before authorization runs, and
+// unauthenticated requests before anything else when authentication is
+// enabled. Authorized requests are then checked against the plan feature
+// the guard requires.
+func (g guard) handle(r chi.Router, method, pattern string, h http.HandlerFunc, action string, resource authz.ResourceFunc, required ...string) {
+	middlewares := chi.Middlewares{authorize(g.engine, action, resource)}
+	if g.features != nil && g.feature != "" {
+		middlewares = append(middlewares, features.Require(g.features, g.feature))
+	}
+	unguarded := g.requireAuth && g.engine == nil
+	if g.requireScopes || (unguarded && slices.Contains(required, policy.ScopeAdmin)) {
+		middlewares = append(chi.Middlewares{scopes.Require(required...)}, middlewares...)
+	}
+	middlewares = append(chi.Middlewares{contenttype.Require(g.contentTypes...)}, middlewares...)
+	if g.requireAuth {
+		middlewares = append(chi.Middlewares{authmw.Require}, middlewares...)
+	}
+	r.With(middlewares...).Method(method, pattern, openapi.Secured(timed(action, h), required...))
+}
+
+// allowedHeaders returns the request headers browsers may send, with the
+// header tagging canary requests when one is configured
+func allowedHeaders(canaryHeader string) []string {
+	headers := []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Correlation-ID", authmw.APIKeyHeader, consistency.Header, handlers.UploadOffsetHeader, handlers.UploadChecksumHeader, handlers.PreferHeader, requestcontext.TimeZoneHeader, utils.EnvelopeHeader}
+	if canaryHeader != "" {
+		headers = append(headers, canaryHeader)
+	}
+	return headers
+}
+
+// experiment returns the handler of a route under the experiment called
+// name, which serves control unless experiments assign the request to
+// candidate
+func experiment(experiments *canary.Experiments, name string, control, candidate http.HandlerFunc) http.HandlerFunc {
+	if experiments == nil {
+		return control
+	}
+	return experiments.Split(name, control, candidate).ServeHTTP
+}
+
+// timed records the handler span of the request, named after its action,
+// when the request is being timed
+func timed(action string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer timing.Start(r.Context(), timing.LayerHandler, action).End()
+		h(w, r)
+	}
+}
+
+// authorize returns an authorization middleware, or a no-op one when no
+// policy engine is configured
+func authorize(engine policy.Engine, action string, resource authz.ResourceFunc) func(http.Handler) http.Handler {
+	if engine == nil {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return authz.Require(engine, action, resource)
+}
+
+// userResource describes the user addressed by the {id} URL parameter.
+// Users own their own record.
+func userResource(r *http.Request) policy.Resource {
+	id := chi.URLParam(r, "id")
+	return policy.Resource{Type: "user", ID: id, OwnerID: id}
+}
+
+// viewResource describes the saved view addressed by the {id} URL
+// parameter. Its owner is only known once the view is loaded, so the
+// handler checks visibility and ownership.
+func viewResource(r *http.Request) policy.Resource {
+	return policy.Resource{Type: "view", ID: chi.URLParam(r, "id")}
+}
+
+// apiKeyResource describes the API key addressed by the {id} URL parameter
+func apiKeyResource(r *http.Request) policy.Resource {
+	return policy.Resource{Type: "apikey", ID: chi.URLParam(r, "id")}
+}
+
+// organizationResource describes the organization addressed by the {id}
+// URL parameter
+func organizationResource(r *http.Request) policy.Resource {
+	return policy.Resource{Type: "organization", ID: chi.URLParam(r, "id")}
+}
+
+// serviceAccountResource describes the service account addressed by the
+// {account_id} URL parameter
+func serviceAccountResource(r *http.Request) policy.Resource {
+	return policy.Resource{Type: "serviceaccount", ID: chi.URLParam(r, "account_id")}
+}
+
+// quotaResource describes the quota addressed by the {resource} URL
+// parameter
+func quotaResource(r *http.Request) policy.Resource {
+	return policy.Resource{Type: "quota", ID: chi.URLParam(r, "resource")}
+}
+
+// featureFlagResource describes the feature flag addressed by the {key}
+// URL parameter
+func featureFlagResource(r *http.Request) policy.Resource {
+	return policy.Resource{Type: "featureflag", ID: chi.URLParam(r, "key")}
+}
+
+// webhookResource describes the webhook addressed by the {id} URL
+// parameter
+func webhookResource(r *http.Request) policy.Resource {
+	return policy.Resource{Type: "webhook", ID: chi.URLParam(r, "id")}
+}
+
+// jobResource describes the job in the {id} URL parameter
+func jobResource(r *http.Request) policy.Resource {
+	return policy.Resource{Type: "job", ID: chi.URLParam(r, "id")}
+}
+
+// emailSuppressionResource describes the suppression of the address in
+// the {email} URL parameter
+func emailSuppressionResource(r *http.Request) policy.Resource {
+	return policy.Resource{Type: "emailsuppression", ID: chi.URLParam(r, "email")}
+}
+
+// selfResource describes the authenticated user's own record
+func selfResource(r *http.Request) policy.Resource {
+	subject, _ := policy.SubjectFromContext(r.Context())
+	return policy.Resource{Type: "user", ID: subject.ID, OwnerID: subject.ID}
+}
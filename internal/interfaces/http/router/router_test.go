@@ -0,0 +1,326 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/configs"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/interfaces/http/handlers"
+	"clean-architecture/internal/interfaces/http/router/routertest"
+	"clean-architecture/pkg/canary"
+	"clean-architecture/pkg/cost"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/metrics"
+	"clean-architecture/pkg/slo"
+)
+
+type stubAuthenticator struct{}
+
+func (stubAuthenticator) Authenticate(ctx context.Context, token string) (policy.Subject, error) {
+	return policy.Subject{}, nil
+}
+
+func (stubAuthenticator) AuthenticateSession(ctx context.Context, id string) (policy.Subject, error) {
+	return policy.Subject{}, nil
+}
+
+type stubGate struct{}
+
+func (stubGate) Allows(ctx context.Context, feature string) (bool, error) {
+	return true, nil
+}
+
+type stubRecorder struct{}
+
+func (stubRecorder) Record(keyID, tenantID, method, route string, bytesIn, bytesOut int64) {}
+
+type stubEngine struct{}
+
+func (stubEngine) Evaluate(ctx context.Context, input policy.Input) (policy.Decision, error) {
+	return policy.Decision{Allowed: true}, nil
+}
+
+// newTestDependencies returns dependencies with every optional feature
+// enabled, so every route group is mounted. The router is only walked, never
+// served, so handlers need not be wired.
+func newTestDependencies() Dependencies {
+	cfg := &configs.Config{}
+	cfg.Auth.RequireScopes = true
+	cfg.Server.ContentTypes = []string{"application/json"}
+	cfg.Server.SlowRequestThreshold = time.Second
+	cfg.Server.DebugTimings = "envelope"
+	cfg.Server.TrailingSlashRedirect = true
+	cfg.Email.Feedback.Provider = "stub"
+
+	return Dependencies{
+		Logger:                  logger.New(),
+		Config:                  cfg,
+		UserHandler:             &handlers.UserHandler{},
+		UserDeletionHandler:     &handlers.UserDeletionHandler{},
+		ExportHandler:           &handlers.ExportHandler{},
+		ImportHandler:           &handlers.ImportHandler{},
+		JobHandler:              &handlers.JobHandler{},
+		SavedViewHandler:        &handlers.SavedViewHandler{},
+		ChangelogHandler:        &handlers.ChangelogHandler{},
+		CapabilitiesHandler:     &handlers.CapabilitiesHandler{},
+		SchemaHandler:           &handlers.SchemaHandler{},
+		Metrics:                 metrics.NewRegistry(),
+		PolicyEngine:            stubEngine{},
+		SLO:                     slo.NewTracker(nil, slo.Options{}),
+		Costs:                   cost.NewTracker(nil, logger.New()),
+		Downloads:               http.NotFoundHandler(),
+		SCIMHandler:             &handlers.SCIMHandler{},
+		Authenticator:           stubAuthenticator{},
+		AuthHandler:             &handlers.AuthHandler{},
+		APIKeys:                 stubAuthenticator{},
+		APIKeyHandler:           &handlers.APIKeyHandler{},
+		MFAHandler:              &handlers.MFAHandler{},
+		PreferencesHandler:      &handlers.UserPreferencesHandler{},
+		LockoutHandler:          &handlers.LockoutHandler{},
+		OrganizationHandler:     &handlers.OrganizationHandler{},
+		ServiceAccountHandler:   &handlers.ServiceAccountHandler{},
+		QuotaHandler:            &handlers.QuotaHandler{},
+		FeatureFlagHandler:      &handlers.FeatureFlagHandler{},
+		AuditLogHandler:         &handlers.AuditLogHandler{},
+		WebhookHandler:          &handlers.WebhookHandler{},
+		EventStreamHandler:      &handlers.EventStreamHandler{},
+		AdminUI:                 http.NotFoundHandler(),
+		BillingHandler:          &handlers.BillingHandler{},
+		EmailSuppressionHandler: &handlers.EmailSuppressionHandler{},
+		Features:                stubGate{},
+		Sessions:                stubAuthenticator{},
+		Usage:                   stubRecorder{},
+		UsageHandler:            &handlers.UsageHandler{},
+	}
+}
+
+// requestScoped are the middlewares that identify and log a request. They
+// wrap panic recovery so recovered panics are logged with the request ID.
+var requestScoped = []string{
+	"middleware.RequestID",
+	"correlation.Middleware",
+	"requestcontext.Middleware",
+	"clientip.Resolver.Middleware",
+	"middleware.RealIP",
+	"middleware.Logger",
+}
+
+// guarded are the route groups mounted with guard.handle
+var guarded = []string{"/api/v1/users", "/api/v1/views", "/api/v1/apikeys", "/api/v1/lockouts", "/api/v1/organizations", "/api/v1/quotas", "/api/v1/feature-flags", "/api/v1/audit-log", "/api/v1/webhooks", "/api/v1/events", "/api/v1/usage", "/api/v1/billing/subscription", "/api/v1/email-suppressions", "/api/v1/me"}
+
+func TestNewRouterRecoversOutermost(t *testing.T) {
+	h := NewRouter(newTestDependencies())
+
+	routertest.AssertOutermost(t, h, "/", "middleware.Recoverer", requestScoped...)
+	routertest.AssertOrder(t, h, "/",
+		"middleware.Recoverer",
+		"paths.Normalize",
+		"retryafter.Middleware",
+		"servertiming.Middleware",
+		"diagnostics.SlowRequests",
+		"metrics.Registry.Middleware",
+		"slo.Tracker.Middleware",
+		"middleware.RequestSize",
+		"httpserver.SlowClients",
+		"limits.MaxCollectionSize",
+		"logging.LoggerMiddleware",
+		"consistency.Middleware",
+		"cors.Cors.Handler",
+	)
+}
+
+func TestNewRouterGuardedRoutes(t *testing.T) {
+	h := NewRouter(newTestDependencies())
+
+	for _, prefix := range guarded {
+		t.Run(prefix, func(t *testing.T) {
+			// Credentials are resolved and required before the body is
+			// inspected or anything is authorized
+			routertest.AssertOrder(t, h, prefix,
+				"cors.Cors.Handler",
+				"auth.Authenticate",
+				"auth.AuthenticateSession",
+				"auth.AuthenticateAPIKey",
+				"auth.Require",
+				"contenttype.Require",
+				"scopes.Require",
+				"authz.Require",
+			)
+		})
+	}
+}
+
+func TestNewRouterFeatureGates(t *testing.T) {
+	h := NewRouter(newTestDependencies())
+
+	// Plans are checked only for requests that are authorized anyway
+	for _, prefix := range []string{"/api/v1/users/exports", "/api/v1/users/imports"} {
+		routertest.AssertOrder(t, h, prefix, "auth.Require", "authz.Require", "features.Require")
+	}
+	routertest.AssertAbsent(t, h, "/api/v1/users/{id}", "features.Require")
+
+	deps := newTestDependencies()
+	deps.Features = nil
+	routertest.AssertAbsent(t, NewRouter(deps), "/api/v1/users", "features.Require")
+}
+
+func TestNewRouterMetersUsage(t *testing.T) {
+	h := NewRouter(newTestDependencies())
+
+	// Calls are metered with the credential they authenticated with
+	routertest.AssertOrder(t, h, "/api/v1/users", "auth.AuthenticateAPIKey", "requestcontext.Builder.Middleware", "usage.Meter", "auth.Require")
+	routertest.AssertOrder(t, h, "/api/v1/auth", "auth.AuthenticateAPIKey", "usage.Meter")
+	routertest.AssertAbsent(t, h, "/health", "usage.Meter")
+
+	deps := newTestDependencies()
+	deps.Usage = nil
+	routertest.AssertAbsent(t, NewRouter(deps), "/api/v1/users", "usage.Meter")
+}
+
+func TestNewRouterPublicRoutes(t *testing.T) {
+	h := NewRouter(newTestDependencies())
+
+	t.Run("api", func(t *testing.T) {
+		// Public API routes resolve credentials when sent but never require
+		// them
+		for _, prefix := range []string{"/api/v1/auth", "/api/v1/account-deletions", "/api/v1/email-changes", "/api/v1/schemas", "/api/v1/downloads", "/api/v1/billing/webhooks", "/api/v1/email/feedback"} {
+			routertest.AssertOrder(t, h, prefix, "auth.Authenticate", "auth.AuthenticateSession", "auth.AuthenticateAPIKey")
+			routertest.AssertAbsent(t, h, prefix, "auth.Require", "scopes.Require", "authz.Require")
+		}
+	})
+
+	t.Run("root", func(t *testing.T) {
+		// The admin UI signs in through the API it calls
+		for _, prefix := range []string{"/health", "/swagger", "/admin"} {
+			routertest.AssertAbsent(t, h, prefix, "auth.Authenticate", "auth.AuthenticateSession", "auth.AuthenticateAPIKey", "auth.Require")
+		}
+	})
+
+	t.Run("scim", func(t *testing.T) {
+		// SCIM clients authenticate with their own bearer token
+		routertest.AssertOrder(t, h, "/scim/v2", "middleware.Recoverer", "handlers.SCIMHandler.Authenticate", "contenttype.Require")
+		routertest.AssertAbsent(t, h, "/scim/v2", "auth.Authenticate", "auth.AuthenticateSession", "auth.AuthenticateAPIKey", "auth.Require", "authz.Require")
+	})
+}
+
+func TestNewRouterWithoutAuth(t *testing.T) {
+	deps := newTestDependencies()
+	deps.Config.Auth.RequireScopes = false
+	deps.Authenticator = nil
+	deps.APIKeys = nil
+	deps.Sessions = nil
+	deps.PolicyEngine = nil
+	h := NewRouter(deps)
+
+	for _, prefix := range guarded {
+		routertest.AssertOrder(t, h, prefix, "middleware.Recoverer", "contenttype.Require", "router.authorize")
+		routertest.AssertAbsent(t, h, prefix, "auth.Authenticate", "auth.AuthenticateSession", "auth.AuthenticateAPIKey", "auth.Require", "scopes.Require", "authz.Require")
+	}
+}
+
+func TestNewRouterAdminRoutesWithoutPolicy(t *testing.T) {
+	deps := newTestDependencies()
+	deps.Config.Auth.RequireScopes = false
+	deps.PolicyEngine = nil
+	h := NewRouter(deps)
+
+	// Nothing else keeps users who can log in off admin routes
+	for _, prefix := range []string{"/api/v1/apikeys", "/api/v1/quotas", "/api/v1/feature-flags", "/api/v1/audit-log", "/api/v1/webhooks", "/api/v1/events", "/api/v1/organizations"} {
+		routertest.AssertOrder(t, h, prefix, "auth.Require", "scopes.Require", "router.authorize")
+	}
+	for _, prefix := range []string{"/api/v1/views", "/api/v1/me"} {
+		routertest.AssertAbsent(t, h, prefix, "scopes.Require")
+	}
+}
+
+func TestNewRouterDisabledRoutes(t *testing.T) {
+	deps := newTestDependencies()
+	deps.Config.Server.DisabledRoutes = []string{"swagger", "bulk", "webhooks", "admin"}
+	chains, err := routertest.Chains(NewRouter(deps).(chi.Routes))
+	require.NoError(t, err)
+
+	for route := range chains {
+		_, pattern, _ := strings.Cut(route, " ")
+		for _, prefix := range []string{"/swagger", "/api/v1/users/exports", "/api/v1/users/imports", "/api/v1/webhooks", "/admin"} {
+			assert.False(t, strings.HasPrefix(pattern, prefix), "%s is disabled", route)
+		}
+	}
+	// Groups sharing a path prefix stay mounted
+	routertest.Group(t, NewRouter(deps), "/api/v1/users/{id}")
+	routertest.Group(t, NewRouter(deps), "/api/v1/billing/webhooks")
+}
+
+func TestNewRouterNormalizesPaths(t *testing.T) {
+	h := NewRouter(newTestDependencies())
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/user_1/?fields=name", nil))
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+	assert.Equal(t, "/api/v1/users/user_1?fields=name", w.Header().Get("Location"))
+
+	// The root route of a group matches with and without a slash
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/", nil))
+	assert.NotEqual(t, http.StatusPermanentRedirect, w.Code)
+
+	deps := newTestDependencies()
+	deps.Config.Server.TrailingSlashRedirect = false
+	routertest.AssertAbsent(t, NewRouter(deps), "/api/v1/users", "paths.Normalize")
+}
+
+func TestExperiment(t *testing.T) {
+	control := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("control")) }
+	candidate := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("candidate")) }
+	serve := func(h http.HandlerFunc, tag string) string {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		req.Header.Set("X-Canary", tag)
+		w := httptest.NewRecorder()
+		h(w, req)
+		return w.Body.String()
+	}
+
+	assert.Equal(t, "control", serve(experiment(nil, "users.list", control, candidate), "candidate"), "routes serve the control without experiments")
+
+	h := experiment(canary.New(nil, canary.Options{Header: "X-Canary"}), "users.list", control, candidate)
+	assert.Equal(t, "control", serve(h, ""))
+	assert.Equal(t, "candidate", serve(h, "candidate"))
+}
+
+func TestRouteModulesUnknownGroup(t *testing.T) {
+	assert.PanicsWithValue(t, "router: route group graphql is missing from configs.RouteGroups", func() {
+		routeModules{}.mount("graphql", func() {})
+	})
+}
+
+func TestNewAdminRouterRecoversOutermost(t *testing.T) {
+	h := NewAdminRouter(AdminDependencies{
+		Logger:      logger.New(),
+		Metrics:     metrics.NewRegistry(),
+		Leadership:  &handlers.LeadershipHandler{},
+		DeadLetters: &handlers.DeadLetterHandler{},
+		Events:      &handlers.EventLogHandler{},
+		Deletions:   &handlers.UserDeletionHandler{},
+		UserMerges:  &handlers.UserMergeHandler{},
+		LogLevels:   &handlers.LogLevelHandler{},
+		Startup:     &handlers.StartupHandler{},
+	})
+
+	routertest.AssertOutermost(t, h, "/", "middleware.Recoverer", "middleware.RequestID")
+	routertest.AssertOrder(t, h, "/", "middleware.Recoverer", "logging.LoggerMiddleware")
+}
+
+func TestNewHealthRouterRecoversOutermost(t *testing.T) {
+	h := NewHealthRouter(&handlers.HealthHandler{})
+
+	routertest.AssertOutermost(t, h, "/", "middleware.Recoverer")
+	routertest.AssertAbsent(t, h, "/", "logging.LoggerMiddleware", "middleware.Logger")
+}
@@ -0,0 +1,146 @@
+// Package routertest inspects the middleware chains a router runs for each
+// route, so tests can pin the order requests pass through them in and catch
+// regressions when routes are rearranged.
+package routertest
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// closureSuffix matches what the compiler appends to the names of closures,
+// inlined closures, method values and generic instantiations
+var closureSuffix = regexp.MustCompile(`(\.func\d+|\.\d+|-fm|\[\.\.\.\])+$`)
+
+// Name returns the name a middleware is reported under: its package and
+// function, such as "middleware.Recoverer". Middlewares returned by a
+// constructor are named after the constructor, such as "auth.Authenticate",
+// and methods after their receiver type, such as
+// "metrics.Registry.Middleware".
+func Name(mw func(http.Handler) http.Handler) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(mw).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = closureSuffix.ReplaceAllString(name, "")
+	return strings.NewReplacer("(*", "", ")", "").Replace(name)
+}
+
+// Chains walks routes and returns the names of the middlewares each route
+// runs, outermost first, keyed by method and pattern such as
+// "GET /api/v1/users/{id}"
+func Chains(routes chi.Routes) (map[string][]string, error) {
+	chains := make(map[string][]string)
+	err := chi.Walk(routes, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		names := make([]string, len(middlewares))
+		for i, mw := range middlewares {
+			names[i] = Name(mw)
+		}
+		chains[method+" "+route] = names
+		return nil
+	})
+	return chains, err
+}
+
+// Group returns the chains of the routes whose pattern starts with prefix.
+// It fails the test when h is not a chi router or no route matches, so a
+// group that is no longer mounted is not mistaken for one that passes.
+func Group(t testing.TB, h http.Handler, prefix string) map[string][]string {
+	t.Helper()
+	routes, ok := h.(chi.Routes)
+	if !ok {
+		t.Fatalf("handler %T is not a chi router", h)
+		return nil
+	}
+	chains, err := Chains(routes)
+	if err != nil {
+		t.Fatalf("walking routes: %v", err)
+		return nil
+	}
+	group := make(map[string][]string)
+	for route, chain := range chains {
+		_, pattern, _ := strings.Cut(route, " ")
+		if strings.HasPrefix(pattern, prefix) {
+			group[route] = chain
+		}
+	}
+	if len(group) == 0 {
+		t.Fatalf("no routes mounted below %s", prefix)
+	}
+	return group
+}
+
+// AssertOrder asserts that every route below prefix runs the named
+// middlewares in the given order. Other middlewares may run between them.
+func AssertOrder(t testing.TB, h http.Handler, prefix string, names ...string) {
+	t.Helper()
+	group := Group(t, h, prefix)
+	for _, route := range sortedRoutes(group) {
+		chain := group[route]
+		next := 0
+		for _, name := range chain {
+			if next < len(names) && name == names[next] {
+				next++
+			}
+		}
+		if next < len(names) {
+			t.Errorf("%s: middleware %s missing or out of order\n  want order: %s\n  chain:      %s",
+				route, names[next], strings.Join(names, " -> "), strings.Join(chain, " -> "))
+		}
+	}
+}
+
+// AssertOutermost asserts that on every route below prefix the named
+// middleware runs before all others except those in outer, which must wrap
+// it in the given order. Use it for middlewares such as panic recovery that
+// must see everything the rest of the chain does.
+func AssertOutermost(t testing.TB, h http.Handler, prefix, name string, outer ...string) {
+	t.Helper()
+	group := Group(t, h, prefix)
+	want := append(append([]string{}, outer...), name)
+	for _, route := range sortedRoutes(group) {
+		chain := group[route]
+		if len(chain) < len(want) || !reflect.DeepEqual(chain[:len(want)], want) {
+			t.Errorf("%s: want chain to start with %s\n  chain: %s",
+				route, strings.Join(want, " -> "), strings.Join(chain, " -> "))
+		}
+	}
+}
+
+// AssertAbsent asserts that no route below prefix runs any of the named
+// middlewares
+func AssertAbsent(t testing.TB, h http.Handler, prefix string, names ...string) {
+	t.Helper()
+	group := Group(t, h, prefix)
+	for _, route := range sortedRoutes(group) {
+		for _, name := range group[route] {
+			for _, absent := range names {
+				if name == absent {
+					t.Errorf("%s: unexpected middleware %s", route, name)
+				}
+			}
+		}
+	}
+}
+
+// sortedRoutes returns the routes of group in a stable order so failures are
+// reported deterministically
+func sortedRoutes(group map[string][]string) []string {
+	routes := make([]string, 0, len(group))
+	for route := range group {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
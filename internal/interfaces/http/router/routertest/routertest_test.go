@@ -0,0 +1,121 @@
+package routertest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder captures failures instead of failing the running test
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+type wrapper struct{}
+
+func (wrapper) Wrap(next http.Handler) http.Handler { return next }
+
+func requireHeader(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler { return next }
+}
+
+func noop(w http.ResponseWriter, r *http.Request) {}
+
+func newTestRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID, middleware.Recoverer)
+	r.Get("/health", noop)
+	r.Route("/api", func(r chi.Router) {
+		r.Use(wrapper{}.Wrap)
+		r.With(requireHeader("X-Test")).Get("/users", noop)
+		r.Post("/users", noop)
+	})
+	return r
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "middleware.Recoverer", Name(middleware.Recoverer))
+	assert.Equal(t, "routertest.requireHeader", Name(requireHeader("X-Test")))
+	assert.Equal(t, "routertest.wrapper.Wrap", Name(wrapper{}.Wrap))
+}
+
+func TestChains(t *testing.T) {
+	chains, err := Chains(newTestRouter())
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"GET /health":     {"middleware.RequestID", "middleware.Recoverer"},
+		"GET /api/users":  {"middleware.RequestID", "middleware.Recoverer", "routertest.wrapper.Wrap", "routertest.requireHeader"},
+		"POST /api/users": {"middleware.RequestID", "middleware.Recoverer", "routertest.wrapper.Wrap"},
+	}, chains)
+}
+
+func TestAssertOrder(t *testing.T) {
+	h := newTestRouter()
+
+	tests := []struct {
+		name     string
+		prefix   string
+		names    []string
+		failures int
+	}{
+		{"in order", "/", []string{"middleware.RequestID", "middleware.Recoverer"}, 0},
+		{"others in between", "/api", []string{"middleware.RequestID", "routertest.wrapper.Wrap"}, 0},
+		{"out of order", "/api", []string{"routertest.wrapper.Wrap", "middleware.Recoverer"}, 2},
+		{"missing on one route", "/api", []string{"routertest.requireHeader"}, 1},
+		{"no routes", "/missing", nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{TB: t}
+			AssertOrder(rec, h, tt.prefix, tt.names...)
+			assert.Len(t, rec.failures, tt.failures, rec.failures)
+		})
+	}
+}
+
+func TestAssertOutermost(t *testing.T) {
+	h := newTestRouter()
+
+	rec := &recorder{TB: t}
+	AssertOutermost(rec, h, "/", "middleware.Recoverer", "middleware.RequestID")
+	assert.Empty(t, rec.failures)
+
+	rec = &recorder{TB: t}
+	AssertOutermost(rec, h, "/api", "middleware.Recoverer")
+	assert.Len(t, rec.failures, 2, rec.failures)
+}
+
+func TestAssertAbsent(t *testing.T) {
+	h := newTestRouter()
+
+	rec := &recorder{TB: t}
+	AssertAbsent(rec, h, "/health", "routertest.wrapper.Wrap")
+	assert.Empty(t, rec.failures)
+
+	rec = &recorder{TB: t}
+	AssertAbsent(rec, h, "/api", "routertest.requireHeader")
+	assert.Len(t, rec.failures, 1, rec.failures)
+}
+
+func TestGroupRejectsOtherHandlers(t *testing.T) {
+	rec := &recorder{TB: t}
+	Group(rec, http.NotFoundHandler(), "/")
+	assert.Len(t, rec.failures, 1, rec.failures)
+}
@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/logger"
+)
+
+// apiKeyMarker starts every API key, so leaked keys are easy to recognize.
+// Keys have the form ak_<lookup>_<secret>, where ak_<lookup> is the
+// stored prefix.
+const apiKeyMarker = "ak_"
+
+// maxAPIKeyNameLength bounds the name of an API key
+const maxAPIKeyNameLength = 100
+
+// apiKeyTouchInterval is how often the last use of a key is recorded, so
+// busy keys do not write on every request
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyUseCase implements issuing, revoking and verifying API keys that
+// authenticate other services
+type APIKeyUseCase struct {
+	keyRepo repositories.APIKeyRepository
+	// userRepo, when set, finds the service accounts keys act as; keys of
+	// service accounts are refused without it
+	userRepo repositories.UserRepository
+	logger   logger.Logger
+	now      func() time.Time
+}
+
+// NewAPIKeyUseCase creates a new API key use case instance
+func NewAPIKeyUseCase(keyRepo repositories.APIKeyRepository, logger logger.Logger) *APIKeyUseCase {
+	return &APIKeyUseCase{
+		keyRepo: keyRepo,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// WithServiceAccounts authenticates the keys of service accounts as the
+// accounts, looking them up in userRepo
+func (uc *APIKeyUseCase) WithServiceAccounts(userRepo repositories.UserRepository) *APIKeyUseCase {
+	uc.userRepo = userRepo
+	return uc
+}
+
+// CreateKey issues a key granting scopes on behalf of creator, who must
+// hold every scope the key grants; only admins may grant admin. The
+// returned plaintext key is not stored and cannot be retrieved again.
+// expiresAt may be nil for keys that do not expire.
+func (uc *APIKeyUseCase) CreateKey(ctx context.Context, creator policy.Subject, name string, scopes []string, expiresAt *time.Time) (*entities.APIKey, string, error) {
+	if !creator.HasRole(policy.RoleAdmin) {
+		for _, scope := range scopes {
+			if _, known := policy.Scopes[scope]; known && (scope == policy.ScopeAdmin || !creator.HasScope(scope)) {
+				return nil, "", fmt.Errorf("%w: %q", ErrScopeNotHeld, scope)
+			}
+		}
+	}
+	return uc.createKey(ctx, "", creator.ID, name, scopes, expiresAt)
+}
+
+// createKey issues a key acting as the service account with ownerID, or as
+// itself when ownerID is empty
+func (uc *APIKeyUseCase) createKey(ctx context.Context, ownerID, createdBy, name string, scopes []string, expiresAt *time.Time) (*entities.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrAPIKeyNameRequired
+	}
+	if utf8.RuneCountInString(name) > maxAPIKeyNameLength {
+		return nil, "", ErrAPIKeyNameTooLong
+	}
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if expiresAt != nil && !expiresAt.After(uc.now()) {
+		return nil, "", ErrAPIKeyExpiryInPast
+	}
+
+	prefix, plaintext, err := generateAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := entities.NewAPIKey(name, prefix, hashAPIKey(plaintext), scopes, createdBy, expiresAt)
+	key.OwnerID = ownerID
+	if err := uc.keyRepo.Create(ctx, key); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to create API key")
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"key_id":     key.ID,
+		"prefix":     key.Prefix,
+		"scopes":     key.Scopes,
+		"created_by": createdBy,
+		"owner_id":   ownerID,
+	}).Info("API key created")
+	return key, plaintext, nil
+}
+
+// GetKey retrieves a key by ID
+func (uc *APIKeyUseCase) GetKey(ctx context.Context, id string) (*entities.APIKey, error) {
+	key, err := uc.keyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return key, nil
+}
+
+// ListKeys retrieves keys, newest first, including revoked and expired ones
+func (uc *APIKeyUseCase) ListKeys(ctx context.Context, limit, offset int) ([]*entities.APIKey, error) {
+	keys, err := uc.keyRepo.List(ctx, limit, offset)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to list API keys")
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeKey stops a key from authenticating. Revoking a revoked key
+// returns it unchanged.
+func (uc *APIKeyUseCase) RevokeKey(ctx context.Context, id string) (*entities.APIKey, error) {
+	key, err := uc.keyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	key.Revoke(uc.now())
+	if err := uc.keyRepo.Update(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	uc.logger.WithField("key_id", key.ID).Info("API key revoked")
+	return key, nil
+}
+
+// Authenticate verifies an API key and returns the subject requests made
+// with it act as. Keys granting the admin scope get the admin role, keys
+// that may change users the service role and others the user role, so
+// policies apply to keys like they do to logins.
+func (uc *APIKeyUseCase) Authenticate(ctx context.Context, plaintext string) (policy.Subject, error) {
+	prefix, ok := apiKeyPrefix(plaintext)
+	if !ok {
+		return policy.Subject{}, ErrInvalidAPIKey
+	}
+	key, err := uc.keyRepo.GetByPrefix(ctx, prefix)
+	if errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		return policy.Subject{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return policy.Subject{}, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	now := uc.now()
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(plaintext)), []byte(key.SecretHash)) != 1 || !key.Active(now) {
+		return policy.Subject{}, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if err := uc.keyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			uc.logger.WithFields(map[string]interface{}{
+				"key_id": key.ID,
+				"error":  err.Error(),
+			}).Warn("Failed to record API key use")
+		}
+	}
+
+	if key.OwnerID != "" {
+		return uc.serviceAccountSubject(ctx, key)
+	}
+	return policy.Subject{
+		ID:         key.ID,
+		Roles:      apiKeyRoles(key.Scopes),
+		Scopes:     key.Scopes,
+		Attributes: map[string]interface{}{"credential": "api_key"},
+	}, nil
+}
+
+// serviceAccountSubject returns the subject requests made with a service
+// account's key act as. The key stops working once the account is
+// deleted, and grants no scope the account is no longer granted.
+func (uc *APIKeyUseCase) serviceAccountSubject(ctx context.Context, key *entities.APIKey) (policy.Subject, error) {
+	if uc.userRepo == nil {
+		return policy.Subject{}, ErrInvalidAPIKey
+	}
+	account, err := uc.userRepo.GetByID(consistency.WithPrimary(ctx), key.OwnerID)
+	if err != nil {
+		return policy.Subject{}, fmt.Errorf("failed to get service account: %w", err)
+	}
+	if account == nil || !account.IsServiceAccount() {
+		return policy.Subject{}, ErrInvalidAPIKey
+	}
+
+	granted := policy.Subject{Scopes: account.Scopes}
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		if granted.HasScope(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return policy.Subject{
+		ID:     account.ID,
+		Roles:  apiKeyRoles(scopes),
+		Scopes: scopes,
+		Attributes: map[string]interface{}{
+			"credential":      "api_key",
+			"key_id":          key.ID,
+			"organization_id": account.OrganizationID,
+		},
+	}, nil
+}
+
+// normalizeScopes rejects empty and unknown scopes and drops duplicates
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrAPIKeyScopesRequired
+	}
+	seen := make(map[string]bool, len(scopes))
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if _, ok := policy.Scopes[scope]; !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
+
+// apiKeyRoles returns the roles a key granting scopes acts with
+func apiKeyRoles(scopes []string) []string {
+	subject := policy.Subject{Scopes: scopes}
+	switch {
+	case subject.HasScope(policy.ScopeAdmin):
+		return []string{policy.RoleAdmin}
+	case subject.HasScope(policy.ScopeUsersWrite):
+		return []string{policy.RoleService}
+	default:
+		return []string{policy.RoleUser}
+	}
+}
+
+// generateAPIKey returns a random key and the prefix it is looked up by
+func generateAPIKey() (prefix, key string, err error) {
+	lookup := make([]byte, 8)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(lookup); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	prefix = apiKeyMarker + hex.EncodeToString(lookup)
+	return prefix, prefix + "_" + hex.EncodeToString(secret), nil
+}
+
+// apiKeyPrefix returns the lookup prefix of a well-formed key
+func apiKeyPrefix(key string) (string, bool) {
+	prefix, secret, found := strings.Cut(strings.TrimPrefix(key, apiKeyMarker), "_")
+	if !found || !strings.HasPrefix(key, apiKeyMarker) || prefix == "" || secret == "" {
+		return "", false
+	}
+	return apiKeyMarker + prefix, true
+}
+
+// hashAPIKey returns the stored form of a key. Keys carry 256 random
+// bits, so a fast hash is enough.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
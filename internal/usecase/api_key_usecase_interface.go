@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+)
+
+// APIKeyUseCaseInterface defines the interface for service API keys
+type APIKeyUseCaseInterface interface {
+	CreateKey(ctx context.Context, creator policy.Subject, name string, scopes []string, expiresAt *time.Time) (*entities.APIKey, string, error)
+	GetKey(ctx context.Context, id string) (*entities.APIKey, error)
+	ListKeys(ctx context.Context, limit, offset int) ([]*entities.APIKey, error)
+	RevokeKey(ctx context.Context, id string) (*entities.APIKey, error)
+	Authenticate(ctx context.Context, key string) (policy.Subject, error)
+}
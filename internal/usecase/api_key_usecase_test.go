@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+// apiKeyAdmin is the admin creating keys in tests
+var apiKeyAdmin = policy.Subject{ID: "admin_1", Roles: []string{policy.RoleAdmin}, Scopes: []string{policy.ScopeAdmin}}
+
+func TestAPIKeyUseCase_CreateKey(t *testing.T) {
+	keyUseCase := NewAPIKeyUseCase(database.NewMockAPIKeyRepository(), logger.New())
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		keyName   string
+		scopes    []string
+		expiresAt *time.Time
+		wantErr   error
+	}{
+		{name: "valid", keyName: "  billing  ", scopes: []string{policy.ScopeUsersRead}},
+		{name: "empty name", keyName: " ", scopes: []string{policy.ScopeUsersRead}, wantErr: ErrAPIKeyNameRequired},
+		{name: "long name", keyName: strings.Repeat("é", maxAPIKeyNameLength+1), scopes: []string{policy.ScopeUsersRead}, wantErr: ErrAPIKeyNameTooLong},
+		{name: "no scopes", keyName: "billing", wantErr: ErrAPIKeyScopesRequired},
+		{name: "unknown scope", keyName: "billing", scopes: []string{"users:everything"}, wantErr: ErrUnknownScope},
+		{name: "expired", keyName: "billing", scopes: []string{policy.ScopeUsersRead}, expiresAt: &past, wantErr: ErrAPIKeyExpiryInPast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, plaintext, err := keyUseCase.CreateKey(context.Background(), apiKeyAdmin, tt.keyName, tt.scopes, tt.expiresAt)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateKey() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if key.ID == "" || key.Name != "billing" {
+				t.Errorf("CreateKey() stored %+v", key)
+			}
+			if !strings.HasPrefix(plaintext, key.Prefix+"_") {
+				t.Errorf("CreateKey() key %q does not start with prefix %q", plaintext, key.Prefix)
+			}
+			if key.SecretHash == "" || strings.Contains(key.SecretHash, plaintext) {
+				t.Errorf("CreateKey() stored secret hash %q", key.SecretHash)
+			}
+		})
+	}
+}
+
+func TestAPIKeyUseCase_CreateKeyBeyondCreatorScopes(t *testing.T) {
+	keyUseCase := NewAPIKeyUseCase(database.NewMockAPIKeyRepository(), logger.New())
+	user := policy.Subject{ID: "user_1", Roles: []string{policy.RoleUser}, Scopes: []string{policy.ScopeUsersWrite}}
+	ctx := context.Background()
+
+	for _, scopes := range [][]string{{policy.ScopeAdmin}, {policy.ScopeUsersRead, policy.ScopeAdmin}} {
+		if _, _, err := keyUseCase.CreateKey(ctx, user, "escalate", scopes, nil); !errors.Is(err, ErrScopeNotHeld) {
+			t.Errorf("CreateKey(%v) by a user error = %v, want %v", scopes, err, ErrScopeNotHeld)
+		}
+	}
+	reader := policy.Subject{ID: "user_2", Roles: []string{policy.RoleUser}, Scopes: []string{policy.ScopeUsersRead}}
+	if _, _, err := keyUseCase.CreateKey(ctx, reader, "writer", []string{policy.ScopeUsersWrite}, nil); !errors.Is(err, ErrScopeNotHeld) {
+		t.Errorf("CreateKey() beyond the creator's scopes error = %v, want %v", err, ErrScopeNotHeld)
+	}
+
+	key, _, err := keyUseCase.CreateKey(ctx, user, "reader", []string{policy.ScopeUsersRead}, nil)
+	if err != nil {
+		t.Fatalf("CreateKey() of a held scope unexpected error: %v", err)
+	}
+	if key.CreatedBy != user.ID {
+		t.Errorf("CreateKey() created by %q, want %q", key.CreatedBy, user.ID)
+	}
+}
+
+func TestAPIKeyUseCase_Authenticate(t *testing.T) {
+	repo := database.NewMockAPIKeyRepository()
+	keyUseCase := NewAPIKeyUseCase(repo, logger.New())
+	ctx := context.Background()
+
+	key, plaintext, err := keyUseCase.CreateKey(ctx, apiKeyAdmin, "billing", []string{policy.ScopeUsersWrite, policy.ScopeUsersWrite}, nil)
+	if err != nil {
+		t.Fatalf("CreateKey() unexpected error: %v", err)
+	}
+
+	subject, err := keyUseCase.Authenticate(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("Authenticate() unexpected error: %v", err)
+	}
+	if subject.ID != key.ID || !reflect.DeepEqual(subject.Scopes, []string{policy.ScopeUsersWrite}) || !subject.HasRole(policy.RoleService) {
+		t.Errorf("Authenticate() subject = %+v", subject)
+	}
+	stored, _ := repo.GetByID(ctx, key.ID)
+	if stored.LastUsedAt == nil {
+		t.Error("Authenticate() did not record the key's last use")
+	}
+
+	for _, invalid := range []string{"", "not-a-key", key.Prefix, key.Prefix + "_wrong", "ak_unknown_secret"} {
+		if _, err := keyUseCase.Authenticate(ctx, invalid); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("Authenticate(%q) error = %v, want %v", invalid, err, ErrInvalidAPIKey)
+		}
+	}
+
+	if _, err := keyUseCase.RevokeKey(ctx, key.ID); err != nil {
+		t.Fatalf("RevokeKey() unexpected error: %v", err)
+	}
+	if _, err := keyUseCase.Authenticate(ctx, plaintext); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() of a revoked key error = %v, want %v", err, ErrInvalidAPIKey)
+	}
+}
+
+func TestAPIKeyUseCase_AuthenticateExpired(t *testing.T) {
+	keyUseCase := NewAPIKeyUseCase(database.NewMockAPIKeyRepository(), logger.New())
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour)
+	_, plaintext, err := keyUseCase.CreateKey(ctx, apiKeyAdmin, "billing", []string{policy.ScopeAdmin}, &expiresAt)
+	if err != nil {
+		t.Fatalf("CreateKey() unexpected error: %v", err)
+	}
+	subject, err := keyUseCase.Authenticate(ctx, plaintext)
+	if err != nil || !subject.HasRole(policy.RoleAdmin) {
+		t.Fatalf("Authenticate() = %+v, %v", subject, err)
+	}
+
+	keyUseCase.now = func() time.Time { return expiresAt }
+	if _, err := keyUseCase.Authenticate(ctx, plaintext); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() of an expired key error = %v, want %v", err, ErrInvalidAPIKey)
+	}
+}
+
+func TestAPIKeyUseCase_RevokeKey(t *testing.T) {
+	keyUseCase := NewAPIKeyUseCase(database.NewMockAPIKeyRepository(), logger.New())
+	ctx := context.Background()
+
+	key, _, err := keyUseCase.CreateKey(ctx, apiKeyAdmin, "billing", []string{policy.ScopeUsersRead}, nil)
+	if err != nil {
+		t.Fatalf("CreateKey() unexpected error: %v", err)
+	}
+
+	revoked, err := keyUseCase.RevokeKey(ctx, key.ID)
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("RevokeKey() = %+v, %v", revoked, err)
+	}
+	again, err := keyUseCase.RevokeKey(ctx, key.ID)
+	if err != nil || !again.RevokedAt.Equal(*revoked.RevokedAt) {
+		t.Errorf("RevokeKey() of a revoked key = %+v, %v", again, err)
+	}
+	if _, err := keyUseCase.RevokeKey(ctx, "key_missing"); err == nil {
+		t.Error("RevokeKey() of a missing key succeeded")
+	}
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// auditSettleDelay is how long entries are left unshipped after being
+// recorded, so an entry whose transaction commits after a later one's is
+// not skipped past by a checkpoint
+const auditSettleDelay = 5 * time.Second
+
+// AuditSink is an external system audit entries are shipped to
+type AuditSink interface {
+	// Name identifies the sink's checkpoint
+	Name() string
+	// Ship delivers entries, in order, or fails without any of them being
+	// checkpointed. Entries may be shipped again after a failure, so sinks
+	// see each of them at least once.
+	Ship(ctx context.Context, entries []*entities.AuditEntry) error
+}
+
+// AuditUseCase records domain events in the audit log and ships its
+// entries to external sinks. Each sink has a checkpoint of the last entry
+// it received, which only advances once a batch is delivered.
+type AuditUseCase struct {
+	auditRepo repositories.AuditRepository
+	sinks     []AuditSink
+	batchSize int
+	logger    logger.Logger
+	// settleDelay is how long recorded entries wait before being shipped
+	settleDelay time.Duration
+	// anchored is the Seq of the last entry anchored by this instance
+	anchored atomic.Int64
+}
+
+// NewAuditUseCase creates a new audit use case shipping to sinks in
+// batches of up to batchSize entries
+func NewAuditUseCase(auditRepo repositories.AuditRepository, sinks []AuditSink, batchSize int, logger logger.Logger) *AuditUseCase {
+	return &AuditUseCase{
+		auditRepo:   auditRepo,
+		sinks:       sinks,
+		batchSize:   batchSize,
+		logger:      logger,
+		settleDelay: auditSettleDelay,
+	}
+}
+
+// Record appends event to the audit log. Redelivered events are recorded
+// once.
+func (uc *AuditUseCase) Record(ctx context.Context, event events.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit data: %w", err)
+	}
+	entry := &entities.AuditEntry{
+		EventID:       event.ID,
+		Type:          event.Type,
+		AggregateID:   event.AggregateID,
+		CorrelationID: event.CorrelationID,
+		OccurredAt:    event.OccurredAt,
+		RecordedAt:    time.Now(),
+		Data:          data,
+	}
+	if err := uc.auditRepo.Append(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListEntries returns the audit entries matching filter, newest first
+func (uc *AuditUseCase) ListEntries(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error) {
+	entries, err := uc.auditRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit entries: %w", err)
+	}
+	return entries, nil
+}
+
+// Ship delivers the entries recorded since each sink's checkpoint. A
+// failing sink does not hold back the others; its entries are shipped
+// again on the next call.
+func (uc *AuditUseCase) Ship(ctx context.Context) error {
+	var errs []error
+	for _, sink := range uc.sinks {
+		shipped, err := uc.ship(ctx, sink)
+		if shipped > 0 {
+			uc.logger.WithFields(map[string]interface{}{
+				"sink":    sink.Name(),
+				"entries": shipped,
+			}).Debug("Shipped audit entries")
+		}
+		if err != nil {
+			uc.logger.WithFields(map[string]interface{}{
+				"sink":  sink.Name(),
+				"error": err.Error(),
+			}).Error("Failed to ship audit entries")
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ship delivers the entries after sink's checkpoint batch by batch and
+// returns how many it delivered
+func (uc *AuditUseCase) ship(ctx context.Context, sink AuditSink) (int, error) {
+	seq, err := uc.auditRepo.GetCheckpoint(ctx, sink.Name())
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	shipped := 0
+	settled := time.Now().Add(-uc.settleDelay)
+	for {
+		entries, err := uc.auditRepo.ListAfter(ctx, seq, settled, uc.batchSize)
+		if err != nil {
+			return shipped, fmt.Errorf("failed to list audit entries: %w", err)
+		}
+		if len(entries) == 0 {
+			return shipped, nil
+		}
+		if err := sink.Ship(ctx, entries); err != nil {
+			return shipped, err
+		}
+
+		seq = entries[len(entries)-1].Seq
+		checkpoint := &entities.AuditCheckpoint{Sink: sink.Name(), Seq: seq, UpdatedAt: time.Now()}
+		if err := uc.auditRepo.SaveCheckpoint(ctx, checkpoint); err != nil {
+			return shipped, fmt.Errorf("failed to save checkpoint: %w", err)
+		}
+		shipped += len(entries)
+		if len(entries) < uc.batchSize {
+			return shipped, nil
+		}
+	}
+}
+
+// AuditProblem is a break in the audit chain found by Verify
+type AuditProblem struct {
+	// Seq is the entry the chain breaks at
+	Seq    int64  `json:"seq"`
+	Reason string `json:"reason"`
+}
+
+// AuditVerification is the result of verifying the audit chain
+type AuditVerification struct {
+	// Entries is how many chained entries were checked
+	Entries int64 `json:"entries"`
+	// Unchained is how many entries were recorded before the log was
+	// chained; they cannot be verified
+	Unchained int64 `json:"unchained"`
+	// Anchors is how many anchors were checked against the entries
+	Anchors  int            `json:"anchors"`
+	HeadSeq  int64          `json:"head_seq"`
+	HeadHash string         `json:"head_hash"`
+	Problems []AuditProblem `json:"problems"`
+}
+
+// Intact reports whether the chain verified without problems
+func (v *AuditVerification) Intact() bool {
+	return len(v.Problems) == 0
+}
+
+// problem records a break at seq
+func (v *AuditVerification) problem(seq int64, format string, args ...interface{}) {
+	v.Problems = append(v.Problems, AuditProblem{Seq: seq, Reason: fmt.Sprintf(format, args...)})
+}
+
+// Anchor records the head of the chain as an anchor, unless no entry was
+// recorded since this instance last anchored it. The anchor is logged too, so copies of it
+// outlive a rewrite of the database.
+func (uc *AuditUseCase) Anchor(ctx context.Context) (*entities.AuditAnchor, error) {
+	head, err := uc.auditRepo.GetHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+	if head == nil || head.Hash == "" || head.Seq == uc.anchored.Load() {
+		return nil, nil
+	}
+	anchor := &entities.AuditAnchor{Seq: head.Seq, Hash: head.Hash, CreatedAt: time.Now()}
+	if err := uc.auditRepo.SaveAnchor(ctx, anchor); err != nil {
+		return nil, fmt.Errorf("failed to save audit anchor: %w", err)
+	}
+	uc.anchored.Store(anchor.Seq)
+	uc.logger.WithFields(map[string]interface{}{
+		"seq":  anchor.Seq,
+		"hash": anchor.Hash,
+	}).Info("Anchored audit chain")
+	return anchor, nil
+}
+
+// Verify walks the audit chain and reports where it breaks: entries whose
+// content no longer matches their hash, that do not chain to the entry
+// before, that are missing, or that differ from an anchor. Entries removed
+// from the end of the chain after the last anchor cannot be detected.
+func (uc *AuditUseCase) Verify(ctx context.Context) (*AuditVerification, error) {
+	anchors, err := uc.auditRepo.ListAnchors(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit anchors: %w", err)
+	}
+	anchored := make(map[int64]string, len(anchors))
+	for _, anchor := range anchors {
+		anchored[anchor.Seq] = anchor.Hash
+	}
+
+	result := &AuditVerification{Problems: []AuditProblem{}}
+	var prev *entities.AuditEntry
+	before := time.Now()
+	for seq := int64(0); ; {
+		entries, err := uc.auditRepo.ListAfter(ctx, seq, before, uc.batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list audit entries: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.Hash == "" && prev == nil {
+				result.Unchained++
+				continue
+			}
+			result.Entries++
+			verifyLink(result, prev, entry)
+			if hash, ok := anchored[entry.Seq]; ok {
+				result.Anchors++
+				delete(anchored, entry.Seq)
+				if hash != entry.Hash {
+					result.problem(entry.Seq, "hash differs from its anchor")
+				}
+			}
+			prev = entry
+		}
+		if len(entries) < uc.batchSize {
+			break
+		}
+		seq = entries[len(entries)-1].Seq
+	}
+
+	if prev != nil {
+		result.HeadSeq, result.HeadHash = prev.Seq, prev.Hash
+	}
+	for _, anchor := range anchors {
+		if _, missing := anchored[anchor.Seq]; missing {
+			result.Anchors++
+			result.problem(anchor.Seq, "anchored entry is missing")
+		}
+	}
+	return result, nil
+}
+
+// verifyLink checks entry and its link to prev, the entry before it in
+// the chain or nil for the first one
+func verifyLink(result *AuditVerification, prev, entry *entities.AuditEntry) {
+	switch {
+	case entry.Hash == "":
+		result.problem(entry.Seq, "hash is missing")
+		return
+	case entry.ComputeHash() != entry.Hash:
+		result.problem(entry.Seq, "content does not match its hash")
+	}
+
+	if prev == nil {
+		if entry.PrevHash != "" {
+			result.problem(entry.Seq, "chains to an entry that is missing")
+		}
+		return
+	}
+	switch {
+	case entry.Seq == prev.Seq+2:
+		result.problem(entry.Seq, "entry %d is missing", prev.Seq+1)
+	case entry.Seq != prev.Seq+1:
+		result.problem(entry.Seq, "entries %d to %d are missing", prev.Seq+1, entry.Seq-1)
+	case entry.PrevHash != prev.Hash:
+		result.problem(entry.Seq, "does not chain to entry %d", prev.Seq)
+	}
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// AuditUseCaseInterface defines the interface for browsing the audit log
+type AuditUseCaseInterface interface {
+	ListEntries(ctx context.Context, filter repositories.AuditFilter) ([]*entities.AuditEntry, error)
+}
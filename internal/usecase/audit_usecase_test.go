@@ -0,0 +1,319 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+// recordingSink records the batches shipped to it and fails while failing
+// is set
+type recordingSink struct {
+	name    string
+	batches [][]*entities.AuditEntry
+	failing bool
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Ship(ctx context.Context, entries []*entities.AuditEntry) error {
+	if s.failing {
+		return errors.New("connection refused")
+	}
+	s.batches = append(s.batches, entries)
+	return nil
+}
+
+// shipped returns the sequence numbers shipped to s, in order
+func (s *recordingSink) shipped() []int64 {
+	var seqs []int64
+	for _, batch := range s.batches {
+		for _, entry := range batch {
+			seqs = append(seqs, entry.Seq)
+		}
+	}
+	return seqs
+}
+
+func newTestAuditUseCase(sinks ...AuditSink) *AuditUseCase {
+	audit := NewAuditUseCase(database.NewMockAuditRepository(), sinks, 2, logger.New())
+	audit.settleDelay = 0
+	return audit
+}
+
+func recordEvents(t *testing.T, audit *AuditUseCase, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		event := events.NewEvent(events.UserCreated, "user_1", map[string]string{"email": "jane@example.com"})
+		if err := audit.Record(context.Background(), event); err != nil {
+			t.Fatalf("Record() unexpected error: %v", err)
+		}
+	}
+}
+
+func TestAuditUseCase_ShipsInBatchesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	sink := &recordingSink{name: "http"}
+	audit := newTestAuditUseCase(sink)
+
+	recordEvents(t, audit, 3)
+	if err := audit.Ship(ctx); err != nil {
+		t.Fatalf("Ship() unexpected error: %v", err)
+	}
+	if len(sink.batches) != 2 {
+		t.Fatalf("shipped %d batches, want 2 of at most 2 entries", len(sink.batches))
+	}
+	if got := sink.batches[0][0]; got.Type != events.UserCreated || string(got.Data) != `{"email":"jane@example.com"}` {
+		t.Errorf("first entry = %+v, want the recorded event", got)
+	}
+
+	recordEvents(t, audit, 1)
+	if err := audit.Ship(ctx); err != nil {
+		t.Fatalf("Ship() unexpected error: %v", err)
+	}
+	if got := sink.shipped(); len(got) != 4 || got[3] != 4 {
+		t.Errorf("shipped %v, want entries 1 to 4 once each", got)
+	}
+}
+
+func TestAuditUseCase_RecordsRedeliveredEventsOnce(t *testing.T) {
+	ctx := context.Background()
+	sink := &recordingSink{name: "http"}
+	audit := newTestAuditUseCase(sink)
+
+	event := events.NewEvent(events.UserDeleted, "user_1", nil)
+	for i := 0; i < 2; i++ {
+		if err := audit.Record(ctx, event); err != nil {
+			t.Fatalf("Record() unexpected error: %v", err)
+		}
+	}
+	if err := audit.Ship(ctx); err != nil {
+		t.Fatalf("Ship() unexpected error: %v", err)
+	}
+	if got := sink.shipped(); len(got) != 1 {
+		t.Errorf("shipped %v, want the event once", got)
+	}
+}
+
+func TestAuditUseCase_ListEntries(t *testing.T) {
+	ctx := context.Background()
+	audit := newTestAuditUseCase()
+	recordEvents(t, audit, 3)
+	if err := audit.Record(ctx, events.NewEvent(events.UserDeleted, "user_2", nil)); err != nil {
+		t.Fatalf("Record() unexpected error: %v", err)
+	}
+
+	entries, err := audit.ListEntries(ctx, repositories.AuditFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListEntries() unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Seq != 4 || entries[1].Seq != 3 {
+		t.Errorf("ListEntries() = %d entries, want entries 4 and 3, newest first", len(entries))
+	}
+
+	entries, err = audit.ListEntries(ctx, repositories.AuditFilter{Type: events.UserCreated, Limit: 10, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListEntries() unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Seq != 2 || entries[1].Seq != 1 {
+		t.Errorf("ListEntries() by type = %d entries, want entries 2 and 1", len(entries))
+	}
+}
+
+func TestAuditUseCase_RetriesFailedSinkOnly(t *testing.T) {
+	ctx := context.Background()
+	healthy := &recordingSink{name: "syslog"}
+	failing := &recordingSink{name: "s3", failing: true}
+	audit := newTestAuditUseCase(failing, healthy)
+
+	recordEvents(t, audit, 2)
+	if err := audit.Ship(ctx); err == nil {
+		t.Fatal("Ship() expected an error from the failing sink")
+	}
+	if got := healthy.shipped(); len(got) != 2 {
+		t.Errorf("healthy sink shipped %v, want both entries despite the failing sink", got)
+	}
+
+	failing.failing = false
+	if err := audit.Ship(ctx); err != nil {
+		t.Fatalf("Ship() unexpected error: %v", err)
+	}
+	if got := failing.shipped(); len(got) != 2 || got[0] != 1 {
+		t.Errorf("recovered sink shipped %v, want the entries it missed", got)
+	}
+	if got := healthy.shipped(); len(got) != 2 {
+		t.Errorf("healthy sink shipped %v, want no entry again", got)
+	}
+}
+
+func TestAuditUseCase_WaitsForEntriesToSettle(t *testing.T) {
+	sink := &recordingSink{name: "http"}
+	audit := NewAuditUseCase(database.NewMockAuditRepository(), []AuditSink{sink}, 10, logger.New())
+
+	recordEvents(t, audit, 1)
+	if err := audit.Ship(context.Background()); err != nil {
+		t.Fatalf("Ship() unexpected error: %v", err)
+	}
+	if len(sink.batches) != 0 {
+		t.Errorf("shipped %v, want entries recorded just now held back", sink.shipped())
+	}
+}
+
+// tamperingAuditRepository rewrites the entries read back, as an attacker
+// with database access would have rewritten them
+type tamperingAuditRepository struct {
+	repositories.AuditRepository
+	tamper func(entries []*entities.AuditEntry) []*entities.AuditEntry
+}
+
+func (r *tamperingAuditRepository) ListAfter(ctx context.Context, seq int64, recordedBefore time.Time, limit int) ([]*entities.AuditEntry, error) {
+	if r.tamper == nil {
+		return r.AuditRepository.ListAfter(ctx, seq, recordedBefore, limit)
+	}
+	// Rewrite every entry before paging, so later entries fill the pages
+	// of removed ones as they would in the database
+	entries, err := r.AuditRepository.ListAfter(ctx, seq, recordedBefore, 1000)
+	if err != nil {
+		return nil, err
+	}
+	entries = r.tamper(entries)
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// rewrite changes the entry with seq, recomputing its hash when rehash is
+// set
+func rewrite(seq int64, rehash bool) func(entries []*entities.AuditEntry) []*entities.AuditEntry {
+	return func(entries []*entities.AuditEntry) []*entities.AuditEntry {
+		for _, entry := range entries {
+			if entry.Seq == seq {
+				entry.AggregateID = "user_2"
+				if rehash {
+					entry.Hash = entry.ComputeHash()
+				}
+			}
+		}
+		return entries
+	}
+}
+
+// drop removes the entry with seq
+func drop(seq int64) func(entries []*entities.AuditEntry) []*entities.AuditEntry {
+	return func(entries []*entities.AuditEntry) []*entities.AuditEntry {
+		kept := entries[:0]
+		for _, entry := range entries {
+			if entry.Seq != seq {
+				kept = append(kept, entry)
+			}
+		}
+		return kept
+	}
+}
+
+func TestAuditUseCase_Verify(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(entries []*entities.AuditEntry) []*entities.AuditEntry
+		want   []AuditProblem
+	}{
+		{name: "intact"},
+		{
+			name:   "changed content",
+			tamper: rewrite(3, false),
+			want:   []AuditProblem{{Seq: 3, Reason: "content does not match its hash"}},
+		},
+		{
+			name:   "changed content with a recomputed hash",
+			tamper: rewrite(3, true),
+			want:   []AuditProblem{{Seq: 4, Reason: "does not chain to entry 3"}},
+		},
+		{
+			name:   "rehashed anchored entry",
+			tamper: rewrite(5, true),
+			want:   []AuditProblem{{Seq: 5, Reason: "hash differs from its anchor"}},
+		},
+		{
+			name:   "removed entry",
+			tamper: drop(3),
+			want:   []AuditProblem{{Seq: 4, Reason: "entry 3 is missing"}},
+		},
+		{
+			name:   "removed first entry",
+			tamper: drop(1),
+			want:   []AuditProblem{{Seq: 2, Reason: "chains to an entry that is missing"}},
+		},
+		{
+			name:   "truncated past the anchor",
+			tamper: drop(5),
+			want:   []AuditProblem{{Seq: 5, Reason: "anchored entry is missing"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := &tamperingAuditRepository{AuditRepository: database.NewMockAuditRepository()}
+			audit := NewAuditUseCase(repo, nil, 2, logger.New())
+			recordEvents(t, audit, 5)
+			anchor, err := audit.Anchor(ctx)
+			if err != nil {
+				t.Fatalf("Anchor() unexpected error: %v", err)
+			}
+			if anchor == nil || anchor.Seq != 5 {
+				t.Fatalf("Anchor() = %+v, want an anchor of entry 5", anchor)
+			}
+
+			repo.tamper = tt.tamper
+			result, err := audit.Verify(ctx)
+			if err != nil {
+				t.Fatalf("Verify() unexpected error: %v", err)
+			}
+			if len(tt.want) == 0 {
+				if !result.Intact() || result.Entries != 5 || result.Anchors != 1 || result.HeadHash != anchor.Hash {
+					t.Errorf("Verify() = %+v, want 5 intact entries and 1 anchor", result)
+				}
+				return
+			}
+			if len(result.Problems) != len(tt.want) {
+				t.Fatalf("Verify() problems = %+v, want %+v", result.Problems, tt.want)
+			}
+			for i, want := range tt.want {
+				if result.Problems[i] != want {
+					t.Errorf("problem %d = %+v, want %+v", i, result.Problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditUseCase_AnchorsOnlyNewEntries(t *testing.T) {
+	ctx := context.Background()
+	audit := newTestAuditUseCase()
+
+	if anchor, err := audit.Anchor(ctx); err != nil || anchor != nil {
+		t.Fatalf("Anchor() = %+v, %v, want nothing to anchor", anchor, err)
+	}
+	recordEvents(t, audit, 1)
+	first, err := audit.Anchor(ctx)
+	if err != nil || first == nil {
+		t.Fatalf("Anchor() = %+v, %v, want an anchor", first, err)
+	}
+	if _, err := audit.Anchor(ctx); err != nil {
+		t.Fatalf("Anchor() unexpected error: %v", err)
+	}
+	anchors, err := audit.auditRepo.ListAnchors(ctx)
+	if err != nil {
+		t.Fatalf("ListAnchors() unexpected error: %v", err)
+	}
+	if len(anchors) != 1 {
+		t.Errorf("recorded %d anchors, want the unchanged head anchored once", len(anchors))
+	}
+}
@@ -0,0 +1,587 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/signedstate"
+)
+
+// revocationLeeway keeps revoked tokens refused past their expiry, which
+// token services accept for a little while for clock skew
+const revocationLeeway = time.Minute
+
+// Session is the outcome of a successful login
+type Session struct {
+	// AccessToken is empty for server-side sessions, which SessionID
+	// references instead
+	AccessToken string
+	SessionID   string
+	ExpiresAt   time.Time
+	Claims      auth.Claims
+	User        *entities.User
+	// Created reports whether the login provisioned the local user
+	Created bool
+}
+
+// Credentials are what a user logs in with
+type Credentials struct {
+	Username string
+	Password string
+	// Code is the one-time code of users who enabled MFA
+	Code string
+	// IP is the address of the client logging in, which failures are
+	// counted for besides the username
+	IP string
+}
+
+// SignupNotifier asks users who signed up to verify their email
+type SignupNotifier interface {
+	// SignupVerificationRequested is sent to the user's email. verifyURL
+	// activates the account without signing in.
+	SignupVerificationRequested(ctx context.Context, user *entities.User, verifyURL string, expiresAt time.Time) error
+}
+
+// SignupVerificationPolicy is how users who signed up verify their email
+type SignupVerificationPolicy struct {
+	// Key signs the verification tokens
+	Key []byte
+	// TTL is how long the verification link is valid
+	TTL time.Duration
+	// VerifyURL is the page linked from verification emails, which
+	// receives the token as a query parameter
+	VerifyURL string
+}
+
+// signupToken is the signed payload of a verification link. It names the
+// email it was sent to, so it stops working once the email changes.
+type signupToken struct {
+	UserID  string `json:"sub"`
+	Email   string `json:"email"`
+	Expires int64  `json:"exp"`
+}
+
+// AuthUseCase implements logging in and issuing access tokens
+type AuthUseCase struct {
+	provider auth.Provider
+	tokens   auth.TokenService
+	userRepo repositories.UserRepository
+	users    UserUseCaseInterface
+	logger   logger.Logger
+
+	// hasher hashes the passwords of users signing up; nil disables signup
+	hasher            auth.PasswordHasher
+	minPasswordLength int
+	// signupTokens sign the links signupNotifier sends users who signed up
+	// to verify their email
+	signupTokens       *signedstate.Codec
+	signupNotifier     SignupNotifier
+	signupVerification SignupVerificationPolicy
+
+	// sessions keeps server-side sessions; nil disables them
+	sessions    auth.SessionStore
+	sessionTTL  time.Duration
+	sessionIdle time.Duration
+
+	// mfa verifies the one-time codes of users who enabled MFA; without it
+	// their password logins are refused
+	mfa *MFAUseCase
+	// lockout locks usernames and addresses after too many failed logins;
+	// nil disables lockouts
+	lockout *LockoutUseCase
+	// revocations keeps access tokens revoked by logging out; nil
+	// disables logout
+	revocations auth.RevocationStore
+	now         func() time.Time
+}
+
+// NewAuthUseCase creates a new auth use case instance. Credentials are
+// verified by provider, which may be nil when password login is not
+// configured; users logging in for the first time are created
+// through users, so the usual domain events are published.
+func NewAuthUseCase(provider auth.Provider, tokens auth.TokenService, userRepo repositories.UserRepository, users UserUseCaseInterface, logger logger.Logger) *AuthUseCase {
+	return &AuthUseCase{
+		provider: provider,
+		tokens:   tokens,
+		userRepo: userRepo,
+		users:    users,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// WithSignup lets users sign up with a password of at least
+// minPasswordLength characters, stored hashed by hasher. They log in once
+// they followed the link notifier sends to their email.
+func (uc *AuthUseCase) WithSignup(hasher auth.PasswordHasher, minPasswordLength int, notifier SignupNotifier, verification SignupVerificationPolicy) *AuthUseCase {
+	uc.hasher = hasher
+	uc.minPasswordLength = minPasswordLength
+	uc.signupTokens = signedstate.New(verification.Key, "signup")
+	uc.signupNotifier = notifier
+	uc.signupVerification = verification
+	return uc
+}
+
+// WithSessions lets users log in to server-side sessions kept in store,
+// which end ttl after login or after idle without use
+func (uc *AuthUseCase) WithSessions(store auth.SessionStore, ttl, idle time.Duration) *AuthUseCase {
+	uc.sessions = store
+	uc.sessionTTL = ttl
+	uc.sessionIdle = idle
+	return uc
+}
+
+// WithMFA requires a one-time code, checked by mfa, on the password logins
+// of users who enabled MFA
+func (uc *AuthUseCase) WithMFA(mfa *MFAUseCase) *AuthUseCase {
+	uc.mfa = mfa
+	return uc
+}
+
+// WithLockout refuses password logins of usernames and client addresses
+// that failed too often, as lockout decides
+func (uc *AuthUseCase) WithLockout(lockout *LockoutUseCase) *AuthUseCase {
+	uc.lockout = lockout
+	return uc
+}
+
+// WithRevocations lets users log out, revoking their access token in
+// store until it expires
+func (uc *AuthUseCase) WithRevocations(store auth.RevocationStore) *AuthUseCase {
+	uc.revocations = store
+	return uc
+}
+
+// Login verifies a username and password and issues an access token for
+// the matching local user, which is created on first login
+func (uc *AuthUseCase) Login(ctx context.Context, creds Credentials) (*Session, error) {
+	identity, user, created, err := uc.passwordLogin(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	return uc.session(ctx, user, identity.Roles, identity.Provider, created)
+}
+
+// StartSession verifies a username and password like Login, but keeps the
+// login in a server-side session instead of issuing an access token
+func (uc *AuthUseCase) StartSession(ctx context.Context, creds Credentials) (*Session, error) {
+	if uc.sessions == nil {
+		return nil, ErrSessionsUnavailable
+	}
+	identity, user, created, err := uc.passwordLogin(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := generateSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	now := uc.now()
+	stored := &auth.ServerSession{
+		ID:        hashSessionID(id),
+		UserID:    user.ID,
+		Roles:     identity.Roles,
+		Scopes:    scopesFor(identity.Roles),
+		CreatedAt: now,
+		ExpiresAt: now.Add(uc.sessionTTL),
+	}
+	if err := uc.sessions.Create(ctx, stored, uc.sessionIdle); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": identity.Provider,
+		"created":  created,
+	}).Info("User started a session")
+	return &Session{
+		SessionID: id,
+		ExpiresAt: stored.ExpiresAt,
+		Claims: auth.Claims{
+			Subject:   user.ID,
+			Roles:     stored.Roles,
+			Scopes:    stored.Scopes,
+			IssuedAt:  now,
+			ExpiresAt: stored.ExpiresAt,
+		},
+		User:    user,
+		Created: created,
+	}, nil
+}
+
+// AuthenticateSession returns the subject of a server-side session and
+// extends its idle timeout
+func (uc *AuthUseCase) AuthenticateSession(ctx context.Context, id string) (policy.Subject, error) {
+	if uc.sessions == nil || id == "" {
+		return policy.Subject{}, auth.ErrSessionNotFound
+	}
+	hashed := hashSessionID(id)
+	session, err := uc.sessions.Touch(ctx, hashed, uc.sessionIdle)
+	if err != nil {
+		return policy.Subject{}, err
+	}
+	if !uc.now().Before(session.ExpiresAt) {
+		if err := uc.sessions.Delete(ctx, hashed); err != nil {
+			uc.logger.WithField("error", err.Error()).Warn("Failed to delete expired session")
+		}
+		return policy.Subject{}, auth.ErrSessionNotFound
+	}
+	return policy.Subject{
+		ID:         session.UserID,
+		Roles:      session.Roles,
+		Scopes:     session.Scopes,
+		Attributes: map[string]interface{}{"credential": "session"},
+	}, nil
+}
+
+// EndSession deletes a server-side session. Unknown sessions are no error,
+// so logging out twice succeeds.
+func (uc *AuthUseCase) EndSession(ctx context.Context, id string) error {
+	if uc.sessions == nil {
+		return ErrSessionsUnavailable
+	}
+	if id == "" {
+		return nil
+	}
+	if err := uc.sessions.Delete(ctx, hashSessionID(id)); err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+	return nil
+}
+
+// passwordLogin verifies creds and returns the local user they log in as,
+// checking the one-time code of users who enabled MFA. Failures count
+// towards locking the username and client address.
+func (uc *AuthUseCase) passwordLogin(ctx context.Context, creds Credentials) (*auth.Identity, *entities.User, bool, error) {
+	if uc.lockout == nil {
+		return uc.verifyLogin(ctx, creds)
+	}
+	if err := uc.lockout.check(ctx, creds); err != nil {
+		return nil, nil, false, err
+	}
+	identity, user, created, err := uc.verifyLogin(ctx, creds)
+	switch {
+	case err == nil:
+		uc.lockout.succeeded(ctx, creds)
+	case countsAsFailure(err):
+		uc.lockout.failed(ctx, creds)
+	}
+	return identity, user, created, err
+}
+
+// verifyLogin verifies the password and one-time code of creds and returns
+// the local user they log in as
+func (uc *AuthUseCase) verifyLogin(ctx context.Context, creds Credentials) (*auth.Identity, *entities.User, bool, error) {
+	identity, err := uc.verifyCredentials(ctx, creds.Username, creds.Password)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if identity.Email == "" {
+		return nil, nil, false, ErrIdentityWithoutEmail
+	}
+
+	user, created, err := uc.localUser(ctx, identity)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if user.MFAEnabled {
+		if uc.mfa == nil {
+			return nil, nil, false, ErrMFAUnavailable
+		}
+		if err := uc.mfa.Verify(ctx, user, creds.Code); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	return identity, user, created, nil
+}
+
+// verifyCredentials returns the identity the provider verified a username
+// and password for
+func (uc *AuthUseCase) verifyCredentials(ctx context.Context, username, password string) (*auth.Identity, error) {
+	if uc.provider == nil {
+		return nil, ErrLoginUnavailable
+	}
+
+	identity, err := uc.provider.Authenticate(ctx, username, password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) || errors.Is(err, auth.ErrAccessDenied) || errors.Is(err, auth.ErrEmailNotVerified) {
+			uc.logger.WithFields(map[string]interface{}{
+				"provider": uc.provider.Name(),
+				"reason":   err.Error(),
+			}).Info("Login refused")
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to verify credentials: %w", err)
+	}
+	return identity, nil
+}
+
+// LoginIdentity issues an access token for an identity verified by single
+// sign-on, creating its local user on first login. One-time codes are left
+// to the identity provider.
+func (uc *AuthUseCase) LoginIdentity(ctx context.Context, identity *auth.Identity) (*Session, error) {
+	if identity.Email == "" {
+		return nil, ErrIdentityWithoutEmail
+	}
+
+	user, created, err := uc.localUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	return uc.session(ctx, user, identity.Roles, identity.Provider, created)
+}
+
+// SignUp creates a user who logs in with a password and sends them the
+// link that verifies their email. They cannot log in before following it.
+func (uc *AuthUseCase) SignUp(ctx context.Context, email, name, password string) (*entities.User, error) {
+	if uc.hasher == nil {
+		return nil, ErrSignupUnavailable
+	}
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return nil, ErrEmailRequired
+	}
+	if err := uc.validatePassword(password); err != nil {
+		return nil, err
+	}
+
+	hash, err := uc.hasher.Hash(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user, err := uc.users.CreateUserWithPassword(ctx, email, strings.TrimSpace(name), hash)
+	if err != nil {
+		return nil, err
+	}
+	uc.requestSignupVerification(ctx, user)
+	return user, nil
+}
+
+// requestSignupVerification sends user the link that verifies their email.
+// The user stands even if this fails; the failure is logged.
+func (uc *AuthUseCase) requestSignupVerification(ctx context.Context, user *entities.User) {
+	expiresAt := uc.now().Add(uc.signupVerification.TTL)
+	token, err := uc.signupTokens.Encode(signupToken{UserID: user.ID, Email: user.Email, Expires: expiresAt.Unix()})
+	if err == nil {
+		err = uc.signupNotifier.SignupVerificationRequested(ctx, user, linkWithToken(uc.signupVerification.VerifyURL, token), expiresAt)
+	}
+	if err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		}).Error("Failed to send signup verification link")
+		return
+	}
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":    user.ID,
+		"expires_at": expiresAt,
+	}).Info("User signed up and awaits email verification")
+}
+
+// VerifySignup verifies the email of the user a signup verification link
+// was sent to, from when they can log in. Verifying twice succeeds.
+func (uc *AuthUseCase) VerifySignup(ctx context.Context, token string) (*entities.User, error) {
+	if uc.hasher == nil {
+		return nil, ErrSignupUnavailable
+	}
+	var claims signupToken
+	if !uc.signupTokens.Decode(token, &claims) {
+		return nil, ErrInvalidVerificationLink
+	}
+	if !uc.now().Before(time.Unix(claims.Expires, 0)) {
+		return nil, ErrVerificationLinkExpired
+	}
+
+	user, err := uc.userRepo.GetByID(consistency.WithPrimary(ctx), claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || user.Email != claims.Email {
+		return nil, ErrInvalidVerificationLink
+	}
+	if user.EmailVerified() {
+		return user, nil
+	}
+
+	user.VerifyEmail()
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+	uc.logger.WithField("user_id", user.ID).Info("User verified their email")
+	return user, nil
+}
+
+// validatePassword checks a new password against the length limits
+func (uc *AuthUseCase) validatePassword(password string) error {
+	switch {
+	case password == "":
+		return ErrPasswordRequired
+	case utf8.RuneCountInString(password) < uc.minPasswordLength:
+		return fmt.Errorf("%w: use at least %d characters", ErrPasswordTooShort, uc.minPasswordLength)
+	case len(password) > maxPasswordLength:
+		return ErrPasswordTooLong
+	}
+	return nil
+}
+
+// maxPasswordLength is the most bytes of a password bcrypt uses; longer
+// passwords are refused rather than silently truncated
+const maxPasswordLength = 72
+
+// session issues an access token for user with roles
+func (uc *AuthUseCase) session(ctx context.Context, user *entities.User, roles []string, provider string, created bool) (*Session, error) {
+	token, claims, err := uc.tokens.Issue(ctx, auth.Claims{
+		Subject: user.ID,
+		Roles:   roles,
+		Scopes:  scopesFor(roles),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue access token: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": provider,
+		"created":  created,
+	}).Info("User logged in")
+	return &Session{
+		AccessToken: token,
+		ExpiresAt:   claims.ExpiresAt,
+		Claims:      claims,
+		User:        user,
+		Created:     created,
+	}, nil
+}
+
+// localUser returns the user with the identity's email, creating it when
+// there is none
+func (uc *AuthUseCase) localUser(ctx context.Context, identity *auth.Identity) (*entities.User, bool, error) {
+	email := strings.ToLower(identity.Email)
+	user, err := uc.userRepo.GetByEmail(consistency.WithPrimary(ctx), email)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user != nil && !user.EmailVerified() {
+		return uc.claimUnverified(ctx, user, identity)
+	}
+	if user != nil {
+		return user, false, nil
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = identity.Username
+	}
+	user, err = uc.users.CreateUser(ctx, email, name)
+	if errors.Is(err, repositories.ErrUserAlreadyExists) {
+		// A concurrent first login created the user
+		user, err = uc.userRepo.GetByEmail(consistency.WithPrimary(ctx), email)
+		if err == nil && user == nil {
+			err = repositories.ErrUserNotFound
+		}
+		return user, false, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+// claimUnverified hands a user who signed up but never verified their email
+// to the identity, which proves the email is its own. Whoever signed up may
+// not own the email, so the password and one-time code they set are
+// dropped rather than kept alongside the identity provider's login.
+func (uc *AuthUseCase) claimUnverified(ctx context.Context, user *entities.User, identity *auth.Identity) (*entities.User, bool, error) {
+	user.SetPasswordHash("")
+	user.DisableMFA()
+	user.VerifyEmail()
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, false, fmt.Errorf("failed to claim unverified user: %w", err)
+	}
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": identity.Provider,
+	}).Warn("Unverified signup claimed by a single sign-on login; its password was removed")
+	return user, false, nil
+}
+
+// scopesFor returns the scopes a login grants: admins every scope, other
+// users those of their own profile, which policies further restrict
+func scopesFor(roles []string) []string {
+	if (policy.Subject{Roles: roles}).HasRole(policy.RoleAdmin) {
+		return []string{policy.ScopeAdmin}
+	}
+	return []string{policy.ScopeUsersWrite}
+}
+
+// Authenticate verifies an access token and returns the subject it was
+// issued to
+func (uc *AuthUseCase) Authenticate(ctx context.Context, token string) (policy.Subject, error) {
+	claims, err := uc.tokens.Verify(ctx, token)
+	if err != nil {
+		return policy.Subject{}, err
+	}
+	if uc.revocations != nil {
+		revoked, err := uc.revocations.Revoked(ctx, claims.ID)
+		if err != nil {
+			// Tokens are refused rather than let through unchecked
+			uc.logger.WithField("error", err.Error()).Error("Failed to check token revocation")
+			return policy.Subject{}, err
+		}
+		if revoked {
+			return policy.Subject{}, auth.ErrInvalidToken
+		}
+	}
+	return policy.Subject{ID: claims.Subject, Roles: claims.Roles, Scopes: claims.Scopes}, nil
+}
+
+// Logout revokes an access token, so it is refused before it expires.
+// Revoking a token twice succeeds; invalid and expired tokens return
+// auth.ErrInvalidToken.
+func (uc *AuthUseCase) Logout(ctx context.Context, token string) error {
+	if uc.revocations == nil {
+		return ErrLogoutUnavailable
+	}
+	claims, err := uc.tokens.Verify(ctx, token)
+	if err != nil {
+		return err
+	}
+	if err := uc.revocations.Revoke(ctx, claims.ID, claims.ExpiresAt.Add(revocationLeeway)); err != nil {
+		return fmt.Errorf("failed to log out: %w", err)
+	}
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":  claims.Subject,
+		"token_id": claims.ID,
+	}).Info("Access token revoked")
+	return nil
+}
+
+// generateSessionID returns the 256-bit random cookie value of a session
+func generateSessionID() (string, error) {
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(id), nil
+}
+
+// hashSessionID returns the ID a session is stored under
+func hashSessionID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
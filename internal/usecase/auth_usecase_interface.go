@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+)
+
+// AuthUseCaseInterface defines the interface for authentication business logic
+type AuthUseCaseInterface interface {
+	Login(ctx context.Context, creds Credentials) (*Session, error)
+	LoginIdentity(ctx context.Context, identity *auth.Identity) (*Session, error)
+	SignUp(ctx context.Context, email, name, password string) (*entities.User, error)
+	VerifySignup(ctx context.Context, token string) (*entities.User, error)
+	Authenticate(ctx context.Context, token string) (policy.Subject, error)
+	Logout(ctx context.Context, token string) error
+	StartSession(ctx context.Context, creds Credentials) (*Session, error)
+	AuthenticateSession(ctx context.Context, id string) (policy.Subject, error)
+	EndSession(ctx context.Context, id string) error
+}
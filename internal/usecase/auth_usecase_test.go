@@ -0,0 +1,551 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	authinfra "clean-architecture/internal/infrastructure/auth"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/signedstate"
+)
+
+// stubProvider accepts the password "hunter2" for its identities
+type stubProvider struct {
+	identities map[string]*auth.Identity
+	err        error
+}
+
+func (p *stubProvider) Name() string { return "stub" }
+
+func (p *stubProvider) Authenticate(ctx context.Context, username, password string) (*auth.Identity, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	identity, ok := p.identities[username]
+	if !ok || password != "hunter2" {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return identity, nil
+}
+
+// stubTokens issues tokens that are the subject's ID prefixed with "token:"
+type stubTokens struct {
+	issued []auth.Claims
+}
+
+func (s *stubTokens) Issue(ctx context.Context, claims auth.Claims) (string, auth.Claims, error) {
+	claims.ID = "jti_1"
+	claims.IssuedAt = time.Now()
+	claims.ExpiresAt = claims.IssuedAt.Add(15 * time.Minute)
+	s.issued = append(s.issued, claims)
+	return "token:" + claims.Subject, claims, nil
+}
+
+func (s *stubTokens) Verify(ctx context.Context, token string) (*auth.Claims, error) {
+	for _, claims := range s.issued {
+		if token == "token:"+claims.Subject {
+			return &claims, nil
+		}
+	}
+	return nil, auth.ErrInvalidToken
+}
+
+func newTestAuthUseCase(provider auth.Provider) (*AuthUseCase, repositories.UserRepository, *stubTokens) {
+	log := logger.New()
+	userRepo := database.NewMockUserRepository()
+	tokens := &stubTokens{}
+	return NewAuthUseCase(provider, tokens, userRepo, NewUserUseCase(userRepo, nil, log), log), userRepo, tokens
+}
+
+func TestAuthUseCase_Login(t *testing.T) {
+	provider := &stubProvider{identities: map[string]*auth.Identity{
+		"bjensen": {Provider: "stub", Username: "bjensen", Email: "bjensen@example.com", Name: "Barbara Jensen", Roles: []string{"user"}},
+		"admin":   {Provider: "stub", Username: "admin", Email: "admin@example.com", Roles: []string{"admin", "user"}},
+	}}
+	uc, userRepo, tokens := newTestAuthUseCase(provider)
+
+	session, err := uc.Login(context.Background(), Credentials{Username: "bjensen", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+	if !session.Created || session.User.Email != "bjensen@example.com" || session.User.Name != "Barbara Jensen" {
+		t.Errorf("Login() should provision the user, got created=%v user=%+v", session.Created, session.User)
+	}
+	if session.AccessToken != "token:"+session.User.ID {
+		t.Errorf("Login() access token = %q", session.AccessToken)
+	}
+	if !reflect.DeepEqual(session.Claims.Scopes, []string{policy.ScopeUsersWrite}) || !reflect.DeepEqual(session.Claims.Roles, []string{"user"}) {
+		t.Errorf("Login() claims = %+v", session.Claims)
+	}
+
+	again, err := uc.Login(context.Background(), Credentials{Username: "bjensen", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("second Login() unexpected error: %v", err)
+	}
+	if again.Created || again.User.ID != session.User.ID {
+		t.Errorf("second Login() should reuse user %s, got created=%v user=%s", session.User.ID, again.Created, again.User.ID)
+	}
+
+	// Admins log in to an existing user and receive every scope
+	existing := entities.NewUser("admin@example.com", "Site Admin")
+	if err := userRepo.Create(context.Background(), existing); err != nil {
+		t.Fatal(err)
+	}
+	session, err = uc.Login(context.Background(), Credentials{Username: "admin", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("admin Login() unexpected error: %v", err)
+	}
+	if session.Created || session.User.ID != existing.ID || session.User.Name != "Site Admin" {
+		t.Errorf("admin Login() should use the existing user, got %+v", session.User)
+	}
+	if !reflect.DeepEqual(session.Claims.Scopes, []string{policy.ScopeAdmin}) {
+		t.Errorf("admin Login() scopes = %v", session.Claims.Scopes)
+	}
+	if len(tokens.issued) != 3 {
+		t.Errorf("expected 3 issued tokens, got %d", len(tokens.issued))
+	}
+}
+
+func TestAuthUseCase_Login_Failures(t *testing.T) {
+	unavailable := errors.New("ldap: connecting: connection refused")
+	tests := []struct {
+		name     string
+		provider auth.Provider
+		username string
+		wantErr  error
+	}{
+		{name: "wrong password", provider: &stubProvider{identities: map[string]*auth.Identity{"bjensen": {Email: "bjensen@example.com"}}}, username: "bjensen", wantErr: auth.ErrInvalidCredentials},
+		{name: "access denied", provider: &stubProvider{err: auth.ErrAccessDenied}, username: "bjensen", wantErr: auth.ErrAccessDenied},
+		{name: "provider unavailable", provider: &stubProvider{err: unavailable}, username: "bjensen", wantErr: unavailable},
+		{name: "no email", provider: &stubProvider{identities: map[string]*auth.Identity{"svc": {Username: "svc"}}}, username: "svc", wantErr: ErrIdentityWithoutEmail},
+		{name: "no provider", username: "bjensen", wantErr: ErrLoginUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password := "hunter2"
+			if tt.wantErr == auth.ErrInvalidCredentials {
+				password = "wrong"
+			}
+			uc, _, tokens := newTestAuthUseCase(tt.provider)
+
+			_, err := uc.Login(context.Background(), Credentials{Username: tt.username, Password: password})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Login() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(tokens.issued) != 0 {
+				t.Errorf("Login() should not issue a token on failure")
+			}
+		})
+	}
+}
+
+func TestAuthUseCase_LoginIdentity(t *testing.T) {
+	uc, _, _ := newTestAuthUseCase(nil)
+
+	session, err := uc.LoginIdentity(context.Background(), &auth.Identity{
+		Provider: "saml:acme",
+		Subject:  "00u1abcd",
+		Email:    "BJensen@Example.com",
+		Name:     "Barbara Jensen",
+		Roles:    []string{"admin"},
+	})
+	if err != nil {
+		t.Fatalf("LoginIdentity() unexpected error: %v", err)
+	}
+	if !session.Created || session.User.Email != "bjensen@example.com" {
+		t.Errorf("LoginIdentity() should provision the user, got created=%v user=%+v", session.Created, session.User)
+	}
+	if !reflect.DeepEqual(session.Claims.Scopes, []string{policy.ScopeAdmin}) {
+		t.Errorf("LoginIdentity() scopes = %v", session.Claims.Scopes)
+	}
+
+	if _, err := uc.LoginIdentity(context.Background(), &auth.Identity{Provider: "saml:acme", Subject: "00u2"}); !errors.Is(err, ErrIdentityWithoutEmail) {
+		t.Errorf("LoginIdentity() error = %v, want %v", err, ErrIdentityWithoutEmail)
+	}
+}
+
+func TestAuthUseCase_LoginIdentity_UnverifiedSignup(t *testing.T) {
+	uc, userRepo, _, notifier := newTestSignupUseCase(t)
+	squatter, err := uc.SignUp(context.Background(), "bjensen@example.com", "Squatter", "squatter password")
+	if err != nil {
+		t.Fatalf("SignUp() unexpected error: %v", err)
+	}
+	stored, _ := userRepo.GetByID(context.Background(), squatter.ID)
+	stored.EnrollMFA("encrypted-secret")
+	stored.EnableMFA()
+	if err := userRepo.Update(context.Background(), stored); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+
+	session, err := uc.LoginIdentity(context.Background(), &auth.Identity{
+		Provider: "oidc:acme",
+		Subject:  "00u1abcd",
+		Email:    "bjensen@example.com",
+		Name:     "Barbara Jensen",
+	})
+	if err != nil {
+		t.Fatalf("LoginIdentity() unexpected error: %v", err)
+	}
+	if session.User.ID != squatter.ID || session.Created {
+		t.Errorf("LoginIdentity() should log into the signed-up user, got created=%v user=%+v", session.Created, session.User)
+	}
+
+	claimed, _ := userRepo.GetByID(context.Background(), squatter.ID)
+	if !claimed.EmailVerified() || claimed.HasPassword() || claimed.MFAEnabled || claimed.MFASecret != "" {
+		t.Errorf("LoginIdentity() should verify the user and drop the password and code set at signup, got %+v", claimed)
+	}
+	if _, err := uc.Login(context.Background(), Credentials{Username: "bjensen@example.com", Password: "squatter password"}); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("Login() with the signup password error = %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+	if _, err := uc.VerifySignup(context.Background(), verificationToken(t, notifier.links[0])); err != nil {
+		t.Errorf("VerifySignup() after the claim unexpected error: %v", err)
+	}
+	if after, _ := userRepo.GetByID(context.Background(), squatter.ID); after.HasPassword() {
+		t.Errorf("VerifySignup() after the claim should not restore the password")
+	}
+}
+
+// reverseHasher "hashes" passwords by reversing them
+type reverseHasher struct{}
+
+func (reverseHasher) Hash(password string) (string, error) {
+	runes := []rune(password)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return "reversed:" + string(runes), nil
+}
+
+func (h reverseHasher) Verify(hash, password string) bool {
+	expected, _ := h.Hash(password)
+	return hash == expected
+}
+
+// stubSignupNotifier records the verification links it was asked to send
+type stubSignupNotifier struct {
+	links []string
+}
+
+func (n *stubSignupNotifier) SignupVerificationRequested(ctx context.Context, user *entities.User, verifyURL string, expiresAt time.Time) error {
+	n.links = append(n.links, verifyURL)
+	return nil
+}
+
+// testSignupVerification signs verification links valid for a day
+var testSignupVerification = SignupVerificationPolicy{
+	Key:       []byte("signup-key"),
+	TTL:       24 * time.Hour,
+	VerifyURL: "https://app.example.com/signup/verify",
+}
+
+// newTestSignupUseCase returns an auth use case whose users sign up and
+// log in with passwords
+func newTestSignupUseCase(t *testing.T) (*AuthUseCase, repositories.UserRepository, *stubTokens, *stubSignupNotifier) {
+	t.Helper()
+	log := logger.New()
+	userRepo := database.NewMockUserRepository()
+	passwords, err := authinfra.NewPasswordProvider(userRepo, reverseHasher{})
+	if err != nil {
+		t.Fatalf("NewPasswordProvider() unexpected error: %v", err)
+	}
+	tokens := &stubTokens{}
+	notifier := &stubSignupNotifier{}
+	uc := NewAuthUseCase(passwords, tokens, userRepo, NewUserUseCase(userRepo, nil, log), log).
+		WithSignup(reverseHasher{}, 12, notifier, testSignupVerification)
+	return uc, userRepo, tokens, notifier
+}
+
+// verificationToken returns the token of a verification link
+func verificationToken(t *testing.T, link string) string {
+	t.Helper()
+	u, err := url.Parse(link)
+	if err != nil || !strings.HasPrefix(link, testSignupVerification.VerifyURL+"?") {
+		t.Fatalf("verification link = %q", link)
+	}
+	return u.Query().Get("token")
+}
+
+func TestAuthUseCase_SignUp(t *testing.T) {
+	uc, userRepo, tokens, notifier := newTestSignupUseCase(t)
+
+	user, err := uc.SignUp(context.Background(), " BJensen@Example.com ", "Barbara Jensen", "correct horse")
+	if err != nil {
+		t.Fatalf("SignUp() unexpected error: %v", err)
+	}
+	if user.EmailVerified() || len(tokens.issued) != 0 {
+		t.Errorf("SignUp() should create an unverified user without issuing a token, got %+v", user)
+	}
+	if len(notifier.links) != 1 {
+		t.Fatalf("SignUp() should send one verification link, sent %v", notifier.links)
+	}
+
+	stored, _ := userRepo.GetByEmail(context.Background(), "bjensen@example.com")
+	if stored == nil || stored.PasswordHash != "reversed:esroh tcerroc" {
+		t.Fatalf("SignUp() should store the password hash, got %+v", stored)
+	}
+	encoded, _ := json.Marshal(stored)
+	if strings.Contains(string(encoded), "esroh") {
+		t.Errorf("password hash should never be serialized, got %s", encoded)
+	}
+
+	if _, err := uc.SignUp(context.Background(), "bjensen@example.com", "Impostor", "another password"); !errors.Is(err, repositories.ErrUserAlreadyExists) {
+		t.Errorf("SignUp() error = %v, want %v", err, repositories.ErrUserAlreadyExists)
+	}
+
+	creds := Credentials{Username: "bjensen@example.com", Password: "correct horse"}
+	if _, err := uc.Login(context.Background(), creds); !errors.Is(err, auth.ErrEmailNotVerified) {
+		t.Errorf("Login() before verifying error = %v, want %v", err, auth.ErrEmailNotVerified)
+	}
+
+	token := verificationToken(t, notifier.links[0])
+	for i := 0; i < 2; i++ {
+		verified, err := uc.VerifySignup(context.Background(), token)
+		if err != nil {
+			t.Fatalf("VerifySignup() unexpected error: %v", err)
+		}
+		if verified.ID != user.ID || !verified.EmailVerified() {
+			t.Errorf("VerifySignup() = %+v", verified)
+		}
+	}
+
+	session, err := uc.Login(context.Background(), creds)
+	if err != nil {
+		t.Fatalf("Login() after verifying unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(session.Claims.Roles, []string{policy.RoleUser}) || !reflect.DeepEqual(session.Claims.Scopes, []string{policy.ScopeUsersWrite}) {
+		t.Errorf("Login() claims = %+v", session.Claims)
+	}
+}
+
+func TestAuthUseCase_VerifySignup_Invalid(t *testing.T) {
+	uc, userRepo, _, notifier := newTestSignupUseCase(t)
+	user, err := uc.SignUp(context.Background(), "bjensen@example.com", "Barbara Jensen", "correct horse")
+	if err != nil {
+		t.Fatalf("SignUp() unexpected error: %v", err)
+	}
+	token := verificationToken(t, notifier.links[0])
+
+	t.Run("tampered", func(t *testing.T) {
+		if _, err := uc.VerifySignup(context.Background(), token+"A"); !errors.Is(err, ErrInvalidVerificationLink) {
+			t.Errorf("VerifySignup() error = %v, want %v", err, ErrInvalidVerificationLink)
+		}
+	})
+
+	t.Run("other scope", func(t *testing.T) {
+		other := signedstate.New(testSignupVerification.Key, "other")
+		forged, _ := other.Encode(signupToken{UserID: user.ID, Email: user.Email, Expires: time.Now().Add(time.Hour).Unix()})
+		if _, err := uc.VerifySignup(context.Background(), forged); !errors.Is(err, ErrInvalidVerificationLink) {
+			t.Errorf("VerifySignup() error = %v, want %v", err, ErrInvalidVerificationLink)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		uc.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+		defer func() { uc.now = time.Now }()
+		if _, err := uc.VerifySignup(context.Background(), token); !errors.Is(err, ErrVerificationLinkExpired) {
+			t.Errorf("VerifySignup() error = %v, want %v", err, ErrVerificationLinkExpired)
+		}
+	})
+
+	t.Run("email changed", func(t *testing.T) {
+		stored, _ := userRepo.GetByID(context.Background(), user.ID)
+		stored.Email = "someone@example.com"
+		if err := userRepo.Update(context.Background(), stored); err != nil {
+			t.Fatalf("Update() unexpected error: %v", err)
+		}
+		if _, err := uc.VerifySignup(context.Background(), token); !errors.Is(err, ErrInvalidVerificationLink) {
+			t.Errorf("VerifySignup() error = %v, want %v", err, ErrInvalidVerificationLink)
+		}
+	})
+
+	stored, _ := userRepo.GetByID(context.Background(), user.ID)
+	if stored.EmailVerified() {
+		t.Errorf("invalid links should not verify the email")
+	}
+}
+
+func TestAuthUseCase_SignUp_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		userName string
+		password string
+		wantErr  error
+	}{
+		{name: "no email", userName: "Barbara", password: "correct horse", wantErr: ErrEmailRequired},
+		{name: "no name", email: "bjensen@example.com", password: "correct horse", wantErr: ErrNameRequired},
+		{name: "no password", email: "bjensen@example.com", userName: "Barbara", wantErr: ErrPasswordRequired},
+		{name: "short password", email: "bjensen@example.com", userName: "Barbara", password: "hunter2", wantErr: ErrPasswordTooShort},
+		{name: "long password", email: "bjensen@example.com", userName: "Barbara", password: strings.Repeat("x", 73), wantErr: ErrPasswordTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, _, tokens, notifier := newTestSignupUseCase(t)
+
+			_, err := uc.SignUp(context.Background(), tt.email, tt.userName, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SignUp() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(tokens.issued) != 0 || len(notifier.links) != 0 {
+				t.Errorf("SignUp() should neither issue a token nor send a link on failure")
+			}
+		})
+	}
+
+	uc, _, _ := newTestAuthUseCase(nil)
+	if _, err := uc.SignUp(context.Background(), "bjensen@example.com", "Barbara", "correct horse"); !errors.Is(err, ErrSignupUnavailable) {
+		t.Errorf("SignUp() error = %v, want %v", err, ErrSignupUnavailable)
+	}
+	if _, err := uc.VerifySignup(context.Background(), "token"); !errors.Is(err, ErrSignupUnavailable) {
+		t.Errorf("VerifySignup() error = %v, want %v", err, ErrSignupUnavailable)
+	}
+}
+
+func TestAuthUseCase_Authenticate(t *testing.T) {
+	uc, _, tokens := newTestAuthUseCase(nil)
+	tokens.issued = []auth.Claims{{Subject: "user_1", Roles: []string{"user"}, Scopes: []string{policy.ScopeUsersRead}}}
+
+	subject, err := uc.Authenticate(context.Background(), "token:user_1")
+	if err != nil {
+		t.Fatalf("Authenticate() unexpected error: %v", err)
+	}
+	want := policy.Subject{ID: "user_1", Roles: []string{"user"}, Scopes: []string{policy.ScopeUsersRead}}
+	if !reflect.DeepEqual(subject, want) {
+		t.Errorf("Authenticate() = %+v, want %+v", subject, want)
+	}
+
+	if _, err := uc.Authenticate(context.Background(), "token:someone"); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Authenticate() error = %v, want %v", err, auth.ErrInvalidToken)
+	}
+}
+
+// failingRevocationStore fails like an unreachable Redis
+type failingRevocationStore struct{}
+
+func (failingRevocationStore) Revoke(ctx context.Context, id string, until time.Time) error {
+	return errors.New("connection refused")
+}
+
+func (failingRevocationStore) Revoked(ctx context.Context, id string) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestAuthUseCase_Logout(t *testing.T) {
+	ctx := context.Background()
+	uc, _, tokens := newTestAuthUseCase(nil)
+	tokens.issued = []auth.Claims{
+		{ID: "jti_1", Subject: "user_1", ExpiresAt: time.Now().Add(15 * time.Minute)},
+		{ID: "jti_2", Subject: "user_2", ExpiresAt: time.Now().Add(15 * time.Minute)},
+	}
+	if err := uc.Logout(ctx, "token:user_1"); !errors.Is(err, ErrLogoutUnavailable) {
+		t.Fatalf("Logout() without a store error = %v, want %v", err, ErrLogoutUnavailable)
+	}
+
+	uc.WithRevocations(authinfra.NewMemoryRevocationStore())
+	if err := uc.Logout(ctx, "token:user_1"); err != nil {
+		t.Fatalf("Logout() unexpected error: %v", err)
+	}
+	if _, err := uc.Authenticate(ctx, "token:user_1"); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Authenticate() of a revoked token error = %v, want %v", err, auth.ErrInvalidToken)
+	}
+	if _, err := uc.Authenticate(ctx, "token:user_2"); err != nil {
+		t.Errorf("Authenticate() of another token unexpected error: %v", err)
+	}
+	if err := uc.Logout(ctx, "token:user_1"); err != nil {
+		t.Errorf("Logout() twice unexpected error: %v", err)
+	}
+	if err := uc.Logout(ctx, "token:someone"); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("Logout() of an invalid token error = %v, want %v", err, auth.ErrInvalidToken)
+	}
+
+	// Tokens are refused while revocations cannot be checked
+	uc.WithRevocations(failingRevocationStore{})
+	if _, err := uc.Authenticate(ctx, "token:user_2"); err == nil {
+		t.Error("Authenticate() with the store down should fail")
+	}
+}
+
+func TestAuthUseCase_Sessions(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubProvider{identities: map[string]*auth.Identity{
+		"bjensen": {Provider: "stub", Username: "bjensen", Email: "bjensen@example.com", Roles: []string{"user"}},
+	}}
+	uc, _, tokens := newTestAuthUseCase(provider)
+	if _, err := uc.StartSession(ctx, Credentials{Username: "bjensen", Password: "hunter2"}); !errors.Is(err, ErrSessionsUnavailable) {
+		t.Fatalf("StartSession() without a store error = %v, want %v", err, ErrSessionsUnavailable)
+	}
+
+	store := authinfra.NewMemorySessionStore()
+	now := time.Now()
+	uc.WithSessions(store, time.Hour, time.Minute)
+	uc.now = func() time.Time { return now }
+
+	if _, err := uc.StartSession(ctx, Credentials{Username: "bjensen", Password: "wrong"}); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("StartSession() with a wrong password error = %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+	session, err := uc.StartSession(ctx, Credentials{Username: "bjensen", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("StartSession() unexpected error: %v", err)
+	}
+	if session.SessionID == "" || session.AccessToken != "" || len(tokens.issued) != 0 {
+		t.Errorf("StartSession() should return a session ID instead of a token, got %+v", session)
+	}
+	if !session.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("StartSession() ExpiresAt = %v, want %v", session.ExpiresAt, now.Add(time.Hour))
+	}
+	if _, err := store.Touch(ctx, session.SessionID, time.Minute); !errors.Is(err, auth.ErrSessionNotFound) {
+		t.Error("StartSession() should only store a hash of the session ID")
+	}
+
+	subject, err := uc.AuthenticateSession(ctx, session.SessionID)
+	if err != nil {
+		t.Fatalf("AuthenticateSession() unexpected error: %v", err)
+	}
+	if subject.ID != session.User.ID || !reflect.DeepEqual(subject.Roles, []string{"user"}) {
+		t.Errorf("AuthenticateSession() subject = %+v, want user %s", subject, session.User.ID)
+	}
+	if _, err := uc.AuthenticateSession(ctx, "unknown"); !errors.Is(err, auth.ErrSessionNotFound) {
+		t.Errorf("AuthenticateSession() of an unknown ID error = %v, want %v", err, auth.ErrSessionNotFound)
+	}
+
+	// Sessions end at their TTL however often they are used
+	uc.now = func() time.Time { return now.Add(time.Hour) }
+	if _, err := uc.AuthenticateSession(ctx, session.SessionID); !errors.Is(err, auth.ErrSessionNotFound) {
+		t.Errorf("AuthenticateSession() after the TTL error = %v, want %v", err, auth.ErrSessionNotFound)
+	}
+}
+
+func TestAuthUseCase_EndSession(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubProvider{identities: map[string]*auth.Identity{
+		"bjensen": {Provider: "stub", Username: "bjensen", Email: "bjensen@example.com", Roles: []string{"user"}},
+	}}
+	uc, _, _ := newTestAuthUseCase(provider)
+	uc.WithSessions(authinfra.NewMemorySessionStore(), time.Hour, time.Minute)
+
+	session, err := uc.StartSession(ctx, Credentials{Username: "bjensen", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("StartSession() unexpected error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := uc.EndSession(ctx, session.SessionID); err != nil {
+			t.Errorf("EndSession() call %d unexpected error: %v", i+1, err)
+		}
+	}
+	if _, err := uc.AuthenticateSession(ctx, session.SessionID); !errors.Is(err, auth.ErrSessionNotFound) {
+		t.Errorf("AuthenticateSession() after EndSession() error = %v, want %v", err, auth.ErrSessionNotFound)
+	}
+}
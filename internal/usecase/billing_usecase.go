@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"clean-architecture/internal/domain/billing"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/logger"
+)
+
+// BillingStatus is the plan in effect and the subscription granting it
+type BillingStatus struct {
+	Plan *entities.Plan
+	// Subscription is nil while the default plan is in effect
+	Subscription *entities.Subscription
+}
+
+// BillingUseCase keeps the deployment's subscription in sync with a
+// billing provider's webhooks and gates features and the user quota on
+// the plan subscribed to
+type BillingUseCase struct {
+	provider         billing.Provider
+	subscriptionRepo repositories.SubscriptionRepository
+	quotas           *QuotaUseCase
+	// defaultPlan is in effect while no subscription grants a plan
+	defaultPlan string
+	logger      logger.Logger
+	now         func() time.Time
+}
+
+// NewBillingUseCase creates a new billing use case instance. When the plan
+// in effect changes, its user limit becomes the user quota of quotas,
+// which may be nil to leave quotas alone.
+func NewBillingUseCase(provider billing.Provider, subscriptionRepo repositories.SubscriptionRepository, quotas *QuotaUseCase, defaultPlan string, logger logger.Logger) (*BillingUseCase, error) {
+	if _, ok := entities.LookupPlan(defaultPlan); !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPlan, defaultPlan)
+	}
+	return &BillingUseCase{
+		provider:         provider,
+		subscriptionRepo: subscriptionRepo,
+		quotas:           quotas,
+		defaultPlan:      defaultPlan,
+		logger:           logger,
+		now:              time.Now,
+	}, nil
+}
+
+// HandleWebhook applies the subscription change of a webhook whose body
+// is payload and whose signature header is signature. Events the provider
+// sends for anything else are acknowledged without processing, and events
+// older than the last one applied to their subscription are skipped.
+func (uc *BillingUseCase) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := uc.provider.ParseEvent(payload, signature)
+	if errors.Is(err, billing.ErrIgnoredEvent) {
+		uc.logger.Debug("Ignoring billing event")
+		return nil
+	}
+	if errors.Is(err, billing.ErrInvalidSignature) {
+		uc.logger.Warn("Billing webhook with an invalid signature")
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidWebhook, err)
+	}
+
+	log := uc.logger.WithFields(map[string]interface{}{
+		"event_id":        event.ID,
+		"event_type":      event.Type,
+		"subscription_id": event.Subscription.ID,
+	})
+	before, err := uc.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx = consistency.WithPrimary(ctx)
+	subscription, err := uc.subscriptionRepo.GetByID(ctx, event.Subscription.ID)
+	switch {
+	case errors.Is(err, repositories.ErrSubscriptionNotFound):
+		subscription = &entities.Subscription{
+			ID:        event.Subscription.ID,
+			Provider:  uc.provider.Name(),
+			CreatedAt: uc.now(),
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get subscription: %w", err)
+	case event.OccurredAt.Before(subscription.EventAt),
+		event.OccurredAt.Equal(subscription.EventAt) && event.ID == subscription.EventID:
+		log.Info("Skipping billing event older than the subscription")
+		return nil
+	}
+
+	status := event.Subscription.Status
+	if event.Type == billing.EventSubscriptionDeleted {
+		status = entities.SubscriptionCanceled
+	}
+	planID := event.Subscription.PlanID
+	if planID == "" {
+		planID = subscription.PlanID
+	}
+	if _, ok := entities.LookupPlan(planID); !ok {
+		log.WithField("plan", planID).Error("Billing event for an unknown plan")
+		return ErrUnknownPlan
+	}
+
+	subscription.CustomerID = event.Subscription.CustomerID
+	subscription.PlanID = planID
+	subscription.Status = status
+	subscription.PeriodEnd = event.Subscription.CurrentPeriodEnd
+	subscription.EventAt = event.OccurredAt
+	subscription.EventID = event.ID
+	subscription.UpdatedAt = uc.now()
+	if err := uc.subscriptionRepo.Save(ctx, subscription); err != nil {
+		log.WithField("error", err.Error()).Error("Failed to save subscription")
+		return fmt.Errorf("failed to save subscription: %w", err)
+	}
+	log.WithFields(map[string]interface{}{
+		"plan":   planID,
+		"status": status,
+	}).Info("Subscription updated")
+
+	after, err := uc.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if after.Plan.ID != before.Plan.ID {
+		return uc.applyPlan(ctx, after.Plan)
+	}
+	return nil
+}
+
+// Status returns the plan in effect: that of the most recently updated
+// subscription granting one, or the default plan
+func (uc *BillingUseCase) Status(ctx context.Context) (*BillingStatus, error) {
+	subscription, err := uc.subscriptionRepo.Current(ctx)
+	if errors.Is(err, repositories.ErrSubscriptionNotFound) {
+		plan, _ := entities.LookupPlan(uc.defaultPlan)
+		return &BillingStatus{Plan: plan}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+
+	plan, ok := entities.LookupPlan(subscription.PlanID)
+	if !ok {
+		// The plan was removed from the catalog since
+		uc.logger.WithField("plan", subscription.PlanID).Warn("Subscription to an unknown plan; using the default plan")
+		plan, _ = entities.LookupPlan(uc.defaultPlan)
+	}
+	return &BillingStatus{Plan: plan, Subscription: subscription}, nil
+}
+
+// Allows reports whether the plan in effect includes feature
+func (uc *BillingUseCase) Allows(ctx context.Context, feature string) (bool, error) {
+	status, err := uc.Status(ctx)
+	if err != nil {
+		return false, err
+	}
+	return status.Plan.Includes(feature), nil
+}
+
+// applyPlan makes the user limit of plan the user quota. Admins can still
+// adjust the quota until the plan changes again.
+func (uc *BillingUseCase) applyPlan(ctx context.Context, plan *entities.Plan) error {
+	uc.logger.WithField("plan", plan.ID).Info("Plan changed")
+	if uc.quotas == nil {
+		return nil
+	}
+	if _, err := uc.quotas.SetQuota(ctx, entities.QuotaResourceUsers, plan.MaxUsers); err != nil {
+		return fmt.Errorf("failed to apply plan quota: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package usecase
+
+import "context"
+
+// BillingUseCaseInterface defines the interface for billing webhooks and
+// the plan in effect
+type BillingUseCaseInterface interface {
+	HandleWebhook(ctx context.Context, payload []byte, signature string) error
+	Status(ctx context.Context) (*BillingStatus, error)
+	Allows(ctx context.Context, feature string) (bool, error)
+}
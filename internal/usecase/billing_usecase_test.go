@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/billing"
+	"clean-architecture/internal/domain/entities"
+	billinginfra "clean-architecture/internal/infrastructure/billing"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+// stubWebhook returns a stub webhook body of a subscription event
+func stubWebhook(id, eventType, plan, status string, createdAt time.Time) []byte {
+	return []byte(fmt.Sprintf(`{"id": %q, "type": %q, "created_at": %q,
+		"subscription": {"id": "sub_1", "customer_id": "cus_1", "plan": %q, "status": %q}}`,
+		id, eventType, createdAt.Format(time.RFC3339), plan, status))
+}
+
+func newTestBillingUseCase(t *testing.T) (*BillingUseCase, *QuotaUseCase, *billinginfra.StubProvider) {
+	t.Helper()
+	provider := billinginfra.NewStubProvider("secret")
+	quotas := NewQuotaUseCase(database.NewMockQuotaRepository(), database.NewMockUserRepository(), 0, logger.New())
+	uc, err := NewBillingUseCase(provider, database.NewMockSubscriptionRepository(), quotas, "free", logger.New())
+	if err != nil {
+		t.Fatalf("NewBillingUseCase() unexpected error: %v", err)
+	}
+	return uc, quotas, provider
+}
+
+func TestBillingUseCase_HandleWebhook(t *testing.T) {
+	ctx := context.Background()
+	uc, quotas, provider := newTestBillingUseCase(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	allowed, err := uc.Allows(ctx, entities.FeatureExports)
+	if err != nil || allowed {
+		t.Fatalf("Allows() on the default plan = %v, %v; want false", allowed, err)
+	}
+
+	payload := stubWebhook("evt_1", billing.EventSubscriptionCreated, "pro", entities.SubscriptionActive, start)
+	if err := uc.HandleWebhook(ctx, payload, provider.Sign(payload)); err != nil {
+		t.Fatalf("HandleWebhook() unexpected error: %v", err)
+	}
+	status, err := uc.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() unexpected error: %v", err)
+	}
+	if status.Plan.ID != "pro" || status.Subscription == nil || status.Subscription.CustomerID != "cus_1" {
+		t.Errorf("Status() = %+v, want the pro subscription", status)
+	}
+	if allowed, _ := uc.Allows(ctx, entities.FeatureExports); !allowed {
+		t.Error("Allows() on the pro plan = false, want true")
+	}
+	if quota, _ := quotas.GetQuota(ctx, entities.QuotaResourceUsers); quota.Limit != 100 {
+		t.Errorf("user quota = %d, want the pro plan's 100", quota.Limit)
+	}
+
+	// An event delivered late must not undo the newer one
+	stale := stubWebhook("evt_0", billing.EventSubscriptionUpdated, "enterprise", entities.SubscriptionActive, start.Add(-time.Hour))
+	if err := uc.HandleWebhook(ctx, stale, provider.Sign(stale)); err != nil {
+		t.Fatalf("HandleWebhook() stale unexpected error: %v", err)
+	}
+	if status, _ := uc.Status(ctx); status.Plan.ID != "pro" {
+		t.Errorf("plan after a stale event = %s, want pro", status.Plan.ID)
+	}
+
+	// Cancelling falls back to the default plan
+	deleted := stubWebhook("evt_2", billing.EventSubscriptionDeleted, "pro", entities.SubscriptionActive, start.Add(time.Hour))
+	if err := uc.HandleWebhook(ctx, deleted, provider.Sign(deleted)); err != nil {
+		t.Fatalf("HandleWebhook() deleted unexpected error: %v", err)
+	}
+	status, _ = uc.Status(ctx)
+	if status.Plan.ID != "free" || status.Subscription != nil {
+		t.Errorf("Status() after cancelling = %+v, want the free plan", status)
+	}
+	if quota, _ := quotas.GetQuota(ctx, entities.QuotaResourceUsers); quota.Limit != 5 {
+		t.Errorf("user quota = %d, want the free plan's 5", quota.Limit)
+	}
+}
+
+func TestBillingUseCase_HandleWebhook_Rejected(t *testing.T) {
+	ctx := context.Background()
+	uc, _, provider := newTestBillingUseCase(t)
+	now := time.Now()
+
+	payload := stubWebhook("evt_1", billing.EventSubscriptionCreated, "pro", entities.SubscriptionActive, now)
+	if err := uc.HandleWebhook(ctx, payload, "sha256=00"); !errors.Is(err, billing.ErrInvalidSignature) {
+		t.Errorf("HandleWebhook() error = %v, want ErrInvalidSignature", err)
+	}
+
+	unknown := stubWebhook("evt_2", billing.EventSubscriptionCreated, "platinum", entities.SubscriptionActive, now)
+	if err := uc.HandleWebhook(ctx, unknown, provider.Sign(unknown)); !errors.Is(err, ErrUnknownPlan) {
+		t.Errorf("HandleWebhook() error = %v, want ErrUnknownPlan", err)
+	}
+
+	malformed := []byte(`{"id": "evt_3", "type": "subscription.created", "subscription": {}}`)
+	if err := uc.HandleWebhook(ctx, malformed, provider.Sign(malformed)); !errors.Is(err, ErrInvalidWebhook) {
+		t.Errorf("HandleWebhook() error = %v, want ErrInvalidWebhook", err)
+	}
+
+	ignored := []byte(`{"id": "evt_4", "type": "invoice.paid"}`)
+	if err := uc.HandleWebhook(ctx, ignored, provider.Sign(ignored)); err != nil {
+		t.Errorf("HandleWebhook() ignored event unexpected error: %v", err)
+	}
+}
+
+func TestNewBillingUseCase_UnknownDefaultPlan(t *testing.T) {
+	_, err := NewBillingUseCase(billinginfra.NewStubProvider("secret"), database.NewMockSubscriptionRepository(), nil, "platinum", logger.New())
+	if !errors.Is(err, ErrUnknownPlan) {
+		t.Errorf("NewBillingUseCase() error = %v, want ErrUnknownPlan", err)
+	}
+}
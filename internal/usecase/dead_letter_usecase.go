@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+)
+
+// ReplayedFromHeader names the header linking a replayed message to the dead
+// letter it came from
+const ReplayedFromHeader = "dlq-replayed-from"
+
+// ReplayFailure describes a dead letter that could not be replayed
+type ReplayFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// ReplayResult summarizes a bulk replay
+type ReplayResult struct {
+	Replayed []string        `json:"replayed"`
+	Failed   []ReplayFailure `json:"failed"`
+}
+
+// DeadLetterUseCase implements inspection and replay of dead letters
+type DeadLetterUseCase struct {
+	deadLetterRepo repositories.DeadLetterRepository
+	publisher      messaging.Publisher
+	logger         logger.Logger
+}
+
+// NewDeadLetterUseCase creates a new dead letter use case instance
+func NewDeadLetterUseCase(deadLetterRepo repositories.DeadLetterRepository, publisher messaging.Publisher, logger logger.Logger) *DeadLetterUseCase {
+	return &DeadLetterUseCase{
+		deadLetterRepo: deadLetterRepo,
+		publisher:      publisher,
+		logger:         logger,
+	}
+}
+
+// ListDeadLetters retrieves dead letters matching filter
+func (uc *DeadLetterUseCase) ListDeadLetters(ctx context.Context, filter repositories.DeadLetterFilter) ([]*entities.DeadLetter, error) {
+	deadLetters, err := uc.deadLetterRepo.List(ctx, filter)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to list dead letters")
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return deadLetters, nil
+}
+
+// GetDeadLetter retrieves a dead letter by ID
+func (uc *DeadLetterUseCase) GetDeadLetter(ctx context.Context, id string) (*entities.DeadLetter, error) {
+	deadLetter, err := uc.deadLetterRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	return deadLetter, nil
+}
+
+// ReplayDeadLetter republishes a dead letter to its original topic. Groups
+// that already processed the message skip it thanks to idempotency keys.
+func (uc *DeadLetterUseCase) ReplayDeadLetter(ctx context.Context, id string) (*entities.DeadLetter, error) {
+	deadLetter, err := uc.deadLetterRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+
+	headers := make(map[string]string, len(deadLetter.Headers)+1)
+	for k, v := range deadLetter.Headers {
+		if !strings.HasPrefix(k, "dlq-") {
+			headers[k] = v
+		}
+	}
+	headers[ReplayedFromHeader] = deadLetter.ID
+
+	err = uc.publisher.Publish(ctx, messaging.Message{
+		ID:      deadLetter.MessageID,
+		Topic:   deadLetter.Topic,
+		Key:     deadLetter.MessageKey,
+		Payload: deadLetter.Payload,
+		Headers: headers,
+	})
+	if err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"dead_letter_id": id,
+			"error":          err.Error(),
+		}).Error("Failed to replay dead letter")
+		return nil, fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+
+	deadLetter.MarkReplayed(time.Now())
+	if err := uc.deadLetterRepo.Update(ctx, deadLetter); err != nil {
+		return nil, fmt.Errorf("failed to record replay: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"dead_letter_id": id,
+		"topic":          deadLetter.Topic,
+		"message_id":     deadLetter.MessageID,
+	}).Info("Dead letter replayed")
+	return deadLetter, nil
+}
+
+// ReplayDeadLetters replays the given dead letters, or every dead letter
+// matching filter when ids is empty. Individual failures do not stop the
+// remaining replays.
+func (uc *DeadLetterUseCase) ReplayDeadLetters(ctx context.Context, ids []string, filter repositories.DeadLetterFilter) (*ReplayResult, error) {
+	if len(ids) == 0 {
+		deadLetters, err := uc.ListDeadLetters(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, deadLetter := range deadLetters {
+			ids = append(ids, deadLetter.ID)
+		}
+	}
+
+	result := &ReplayResult{Replayed: []string{}, Failed: []ReplayFailure{}}
+	for _, id := range ids {
+		if _, err := uc.ReplayDeadLetter(ctx, id); err != nil {
+			result.Failed = append(result.Failed, ReplayFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		result.Replayed = append(result.Replayed, id)
+	}
+	return result, nil
+}
@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// DeadLetterUseCaseInterface defines the interface for dead letter inspection and replay
+type DeadLetterUseCaseInterface interface {
+	ListDeadLetters(ctx context.Context, filter repositories.DeadLetterFilter) ([]*entities.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, id string) (*entities.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, id string) (*entities.DeadLetter, error)
+	ReplayDeadLetters(ctx context.Context, ids []string, filter repositories.DeadLetterFilter) (*ReplayResult, error)
+}
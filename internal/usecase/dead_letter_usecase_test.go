@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+)
+
+// recordingMessagePublisher captures published messages
+type recordingMessagePublisher struct {
+	messages []messaging.Message
+	err      error
+}
+
+func (p *recordingMessagePublisher) Publish(ctx context.Context, msg messaging.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func seedDeadLetter(t *testing.T, repo repositories.DeadLetterRepository, id, group string) {
+	t.Helper()
+	err := repo.Create(context.Background(), &entities.DeadLetter{
+		ID:            id,
+		Kind:          entities.DeadLetterKindEvent,
+		Topic:         "user.created",
+		ConsumerGroup: group,
+		MessageID:     "msg_" + id,
+		MessageKey:    "user_1",
+		Payload:       []byte(`{"id":"evt_1"}`),
+		Headers:       map[string]string{"content-type": "application/json", "dlq-error": "timeout"},
+		Error:         "timeout",
+		Attempts:      5,
+		FailedAt:      time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("seed dead letter: %v", err)
+	}
+}
+
+func TestDeadLetterUseCase_ReplayDeadLetter(t *testing.T) {
+	// Setup
+	repo := database.NewMockDeadLetterRepository()
+	publisher := &recordingMessagePublisher{}
+	deadLetterUseCase := NewDeadLetterUseCase(repo, publisher, logger.New())
+	seedDeadLetter(t, repo, "dlq_1", "webhooks")
+
+	deadLetter, err := deadLetterUseCase.ReplayDeadLetter(context.Background(), "dlq_1")
+	if err != nil {
+		t.Fatalf("ReplayDeadLetter() unexpected error: %v", err)
+	}
+	if deadLetter.ReplayCount != 1 || deadLetter.ReplayedAt == nil {
+		t.Errorf("ReplayDeadLetter() replay not recorded: count=%d replayed_at=%v", deadLetter.ReplayCount, deadLetter.ReplayedAt)
+	}
+
+	if len(publisher.messages) != 1 {
+		t.Fatalf("published %d messages, want 1", len(publisher.messages))
+	}
+	msg := publisher.messages[0]
+	if msg.Topic != "user.created" || msg.ID != "msg_dlq_1" || msg.Key != "user_1" {
+		t.Errorf("ReplayDeadLetter() published %+v", msg)
+	}
+	if msg.Headers[ReplayedFromHeader] != "dlq_1" {
+		t.Errorf("ReplayDeadLetter() replayed-from header = %q, want dlq_1", msg.Headers[ReplayedFromHeader])
+	}
+	if _, ok := msg.Headers["dlq-error"]; ok {
+		t.Errorf("ReplayDeadLetter() kept dlq headers on the replayed message")
+	}
+
+	stored, _ := repo.GetByID(context.Background(), "dlq_1")
+	if stored.ReplayCount != 1 {
+		t.Errorf("stored replay count = %d, want 1", stored.ReplayCount)
+	}
+}
+
+func TestDeadLetterUseCase_ReplayDeadLetter_NotFound(t *testing.T) {
+	deadLetterUseCase := NewDeadLetterUseCase(database.NewMockDeadLetterRepository(), &recordingMessagePublisher{}, logger.New())
+
+	_, err := deadLetterUseCase.ReplayDeadLetter(context.Background(), "missing")
+	if !errors.Is(err, repositories.ErrDeadLetterNotFound) {
+		t.Errorf("ReplayDeadLetter() error = %v, want %v", err, repositories.ErrDeadLetterNotFound)
+	}
+}
+
+func TestDeadLetterUseCase_ReplayDeadLetters(t *testing.T) {
+	// Setup
+	repo := database.NewMockDeadLetterRepository()
+	publisher := &recordingMessagePublisher{}
+	deadLetterUseCase := NewDeadLetterUseCase(repo, publisher, logger.New())
+	seedDeadLetter(t, repo, "dlq_1", "webhooks")
+	seedDeadLetter(t, repo, "dlq_2", "webhooks")
+	seedDeadLetter(t, repo, "dlq_3", "audit")
+
+	t.Run("by filter", func(t *testing.T) {
+		result, err := deadLetterUseCase.ReplayDeadLetters(context.Background(), nil, repositories.DeadLetterFilter{
+			ConsumerGroup: "webhooks",
+			Pending:       true,
+		})
+		if err != nil {
+			t.Fatalf("ReplayDeadLetters() unexpected error: %v", err)
+		}
+		if len(result.Replayed) != 2 || len(result.Failed) != 0 {
+			t.Errorf("ReplayDeadLetters() = %+v, want 2 replayed", result)
+		}
+	})
+
+	t.Run("by id with failures", func(t *testing.T) {
+		result, err := deadLetterUseCase.ReplayDeadLetters(context.Background(), []string{"dlq_3", "missing"}, repositories.DeadLetterFilter{})
+		if err != nil {
+			t.Fatalf("ReplayDeadLetters() unexpected error: %v", err)
+		}
+		if len(result.Replayed) != 1 || result.Replayed[0] != "dlq_3" {
+			t.Errorf("ReplayDeadLetters() replayed = %v, want [dlq_3]", result.Replayed)
+		}
+		if len(result.Failed) != 1 || result.Failed[0].ID != "missing" {
+			t.Errorf("ReplayDeadLetters() failed = %v, want [missing]", result.Failed)
+		}
+	})
+
+	t.Run("pending excludes replayed", func(t *testing.T) {
+		pending, err := deadLetterUseCase.ListDeadLetters(context.Background(), repositories.DeadLetterFilter{Pending: true})
+		if err != nil {
+			t.Fatalf("ListDeadLetters() unexpected error: %v", err)
+		}
+		if len(pending) != 0 {
+			t.Errorf("ListDeadLetters() returned %d pending dead letters, want 0", len(pending))
+		}
+	})
+}
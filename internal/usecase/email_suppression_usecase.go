@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// EmailSuppressionUseCase maintains the addresses no email is sent to from
+// the bounces and complaints the email provider reports, and lets admins
+// inspect and lift suppressions
+type EmailSuppressionUseCase struct {
+	// provider is nil when feedback webhooks are not configured
+	provider        email.FeedbackProvider
+	suppressionRepo repositories.EmailSuppressionRepository
+	userRepo        repositories.UserRepository
+	logger          logger.Logger
+	now             func() time.Time
+}
+
+// NewEmailSuppressionUseCase creates a new email suppression use case
+// instance. provider may be nil when feedback webhooks are not received.
+func NewEmailSuppressionUseCase(provider email.FeedbackProvider, suppressionRepo repositories.EmailSuppressionRepository, userRepo repositories.UserRepository, logger logger.Logger) *EmailSuppressionUseCase {
+	return &EmailSuppressionUseCase{
+		provider:        provider,
+		suppressionRepo: suppressionRepo,
+		userRepo:        userRepo,
+		logger:          logger,
+		now:             time.Now,
+	}
+}
+
+// HandleFeedback suppresses the addresses reported by a feedback webhook
+// whose body is payload and whose signature header is signature.
+// Complaints and permanent bounces suppress the address; temporary bounces
+// are retried by the provider and only logged.
+func (uc *EmailSuppressionUseCase) HandleFeedback(ctx context.Context, payload []byte, signature string) error {
+	if uc.provider == nil {
+		return ErrInvalidWebhook
+	}
+	feedback, err := uc.provider.ParseFeedback(payload, signature)
+	if errors.Is(err, email.ErrInvalidSignature) {
+		uc.logger.Warn("Email feedback webhook with an invalid signature")
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidWebhook, err)
+	}
+
+	for _, report := range feedback {
+		address := entities.NormalizeEmailAddress(report.Email)
+		log := uc.logger.WithFields(map[string]interface{}{
+			"feedback_id":   report.ID,
+			"feedback_type": report.Type,
+			"email":         address,
+		})
+		if !report.Permanent {
+			log.Info("Ignoring temporary bounce")
+			continue
+		}
+
+		reason := entities.SuppressionBounce
+		if report.Type == email.FeedbackComplaint {
+			reason = entities.SuppressionComplaint
+		}
+		now := uc.now()
+		suppression := &entities.EmailSuppression{
+			Email:      address,
+			Reason:     reason,
+			Detail:     report.Detail,
+			Provider:   uc.provider.Name(),
+			FeedbackID: report.ID,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := uc.suppressionRepo.Save(ctx, suppression); err != nil {
+			log.WithField("error", err.Error()).Error("Failed to suppress email address")
+			return fmt.Errorf("failed to suppress email address: %w", err)
+		}
+		log.Info("Email address suppressed")
+	}
+	return nil
+}
+
+// GetSuppression retrieves the suppression of an address
+func (uc *EmailSuppressionUseCase) GetSuppression(ctx context.Context, address string) (*entities.EmailSuppression, error) {
+	return uc.suppressionRepo.Get(ctx, entities.NormalizeEmailAddress(address))
+}
+
+// GetUserSuppressions retrieves the suppressions of the addresses of
+// several users, keyed by user ID. Users whose address is not suppressed
+// are absent from the result.
+func (uc *EmailSuppressionUseCase) GetUserSuppressions(ctx context.Context, userIDs []string) (map[string]*entities.EmailSuppression, error) {
+	users, err := uc.userRepo.Find(ctx, repositories.Specification{
+		Conditions: []repositories.Condition{{Field: "id", Operator: repositories.OpIn, Value: userIDs}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	addresses := make([]string, 0, len(users))
+	for _, user := range users {
+		addresses = append(addresses, entities.NormalizeEmailAddress(user.Email))
+	}
+	suppressions, err := uc.suppressionRepo.GetMany(ctx, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email suppressions: %w", err)
+	}
+
+	byUser := make(map[string]*entities.EmailSuppression, len(suppressions))
+	for _, user := range users {
+		if suppression, ok := suppressions[entities.NormalizeEmailAddress(user.Email)]; ok {
+			byUser[user.ID] = suppression
+		}
+	}
+	return byUser, nil
+}
+
+// ListSuppressions retrieves suppressions, most recently updated first
+func (uc *EmailSuppressionUseCase) ListSuppressions(ctx context.Context, limit, offset int) ([]*entities.EmailSuppression, error) {
+	suppressions, err := uc.suppressionRepo.List(ctx, limit, offset)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to list email suppressions")
+		return nil, fmt.Errorf("failed to list email suppressions: %w", err)
+	}
+	return suppressions, nil
+}
+
+// LiftSuppression lets email be sent to an address again, such as after
+// its owner fixed their mailbox
+func (uc *EmailSuppressionUseCase) LiftSuppression(ctx context.Context, address string) error {
+	address = entities.NormalizeEmailAddress(address)
+	if err := uc.suppressionRepo.Delete(ctx, address); err != nil {
+		return err
+	}
+	uc.logger.WithField("email", address).Info("Email suppression lifted")
+	return nil
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// EmailSuppressionUseCaseInterface defines the interface for feedback
+// webhooks and the email suppression list
+type EmailSuppressionUseCaseInterface interface {
+	HandleFeedback(ctx context.Context, payload []byte, signature string) error
+	GetSuppression(ctx context.Context, email string) (*entities.EmailSuppression, error)
+	GetUserSuppressions(ctx context.Context, userIDs []string) (map[string]*entities.EmailSuppression, error)
+	ListSuppressions(ctx context.Context, limit, offset int) ([]*entities.EmailSuppression, error)
+	LiftSuppression(ctx context.Context, email string) error
+}
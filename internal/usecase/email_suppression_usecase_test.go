@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"clean-architecture/internal/domain/email"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	emailinfra "clean-architecture/internal/infrastructure/email"
+	"clean-architecture/pkg/logger"
+)
+
+// stubFeedback returns a stub feedback webhook body
+func stubFeedback(id, feedbackType, address string, permanent bool) []byte {
+	return []byte(fmt.Sprintf(`{"id": %q, "type": %q, "email": %q, "permanent": %t, "detail": "550 5.1.1 mailbox unknown"}`,
+		id, feedbackType, address, permanent))
+}
+
+func TestEmailSuppressionUseCase_HandleFeedback(t *testing.T) {
+	ctx := context.Background()
+	provider := emailinfra.NewStubFeedbackProvider("secret")
+	uc := NewEmailSuppressionUseCase(provider, database.NewMockEmailSuppressionRepository(), database.NewMockUserRepository(), logger.New())
+
+	for _, body := range [][]byte{
+		stubFeedback("fb_1", email.FeedbackBounce, "Ada@Example.com", true),
+		stubFeedback("fb_2", email.FeedbackBounce, "grace@example.com", false),
+		stubFeedback("fb_3", email.FeedbackComplaint, "linus@example.com", false),
+	} {
+		if err := uc.HandleFeedback(ctx, body, provider.Sign(body)); err != nil {
+			t.Fatalf("HandleFeedback() unexpected error: %v", err)
+		}
+	}
+
+	bounce, err := uc.GetSuppression(ctx, "ada@example.com")
+	if err != nil {
+		t.Fatalf("GetSuppression() unexpected error: %v", err)
+	}
+	if bounce.Email != "ada@example.com" || bounce.Reason != entities.SuppressionBounce || bounce.Provider != "stub" || bounce.FeedbackID != "fb_1" {
+		t.Errorf("suppression = %+v, want a stub bounce of ada@example.com by fb_1", bounce)
+	}
+	if _, err := uc.GetSuppression(ctx, "grace@example.com"); !errors.Is(err, repositories.ErrEmailSuppressionNotFound) {
+		t.Errorf("temporary bounce suppressed the address: error = %v", err)
+	}
+	complaint, err := uc.GetSuppression(ctx, "linus@example.com")
+	if err != nil || complaint.Reason != entities.SuppressionComplaint {
+		t.Errorf("GetSuppression() = %+v, %v, want a complaint", complaint, err)
+	}
+
+	if err := uc.LiftSuppression(ctx, "ADA@example.com"); err != nil {
+		t.Fatalf("LiftSuppression() unexpected error: %v", err)
+	}
+	if err := uc.LiftSuppression(ctx, "ada@example.com"); !errors.Is(err, repositories.ErrEmailSuppressionNotFound) {
+		t.Errorf("LiftSuppression() twice error = %v, want %v", err, repositories.ErrEmailSuppressionNotFound)
+	}
+	suppressions, err := uc.ListSuppressions(ctx, 10, 0)
+	if err != nil || len(suppressions) != 1 {
+		t.Errorf("ListSuppressions() = %d suppressions, %v, want 1", len(suppressions), err)
+	}
+}
+
+func TestEmailSuppressionUseCase_HandleFeedback_Rejected(t *testing.T) {
+	ctx := context.Background()
+	provider := emailinfra.NewStubFeedbackProvider("secret")
+	uc := NewEmailSuppressionUseCase(provider, database.NewMockEmailSuppressionRepository(), database.NewMockUserRepository(), logger.New())
+
+	body := stubFeedback("fb_1", email.FeedbackBounce, "ada@example.com", true)
+	if err := uc.HandleFeedback(ctx, body, "sha256=00"); !errors.Is(err, email.ErrInvalidSignature) {
+		t.Errorf("HandleFeedback() error = %v, want %v", err, email.ErrInvalidSignature)
+	}
+	malformed := []byte(`{"id": "fb_1", "type": "bounce"`)
+	if err := uc.HandleFeedback(ctx, malformed, provider.Sign(malformed)); !errors.Is(err, ErrInvalidWebhook) {
+		t.Errorf("HandleFeedback() error = %v, want %v", err, ErrInvalidWebhook)
+	}
+
+	disabled := NewEmailSuppressionUseCase(nil, database.NewMockEmailSuppressionRepository(), database.NewMockUserRepository(), logger.New())
+	if err := disabled.HandleFeedback(ctx, body, provider.Sign(body)); !errors.Is(err, ErrInvalidWebhook) {
+		t.Errorf("HandleFeedback() without a provider error = %v, want %v", err, ErrInvalidWebhook)
+	}
+}
+
+func TestEmailSuppressionUseCase_GetUserSuppressions(t *testing.T) {
+	ctx := context.Background()
+	users := database.NewMockUserRepository()
+	suppressions := database.NewMockEmailSuppressionRepository()
+	uc := NewEmailSuppressionUseCase(nil, suppressions, users, logger.New())
+
+	bounced := entities.NewUser("Ada@Example.com", "Ada")
+	delivered := entities.NewUser("grace@example.com", "Grace")
+	for _, user := range []*entities.User{bounced, delivered} {
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+	}
+	if err := suppressions.Save(ctx, &entities.EmailSuppression{Email: "ada@example.com", Reason: entities.SuppressionBounce, Provider: "stub"}); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	byUser, err := uc.GetUserSuppressions(ctx, []string{bounced.ID, delivered.ID})
+	if err != nil {
+		t.Fatalf("GetUserSuppressions() unexpected error: %v", err)
+	}
+	if len(byUser) != 1 || byUser[bounced.ID] == nil {
+		t.Errorf("GetUserSuppressions() = %v, want only %s", byUser, bounced.ID)
+	}
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"errors"
+
+	"clean-architecture/pkg/apperrors"
+)
+
+var (
+	// ErrEmailRequired is returned when a user is created without an email
+	ErrEmailRequired = apperrors.Validation("email_required", "email is required")
+	// ErrNameRequired is returned when a user is created without a name
+	ErrNameRequired = apperrors.Validation("name_required", "name is required")
+	// ErrViewNameRequired is returned when a saved view is created without
+	// a name
+	ErrViewNameRequired = apperrors.Validation("view_name_required", "view name is required")
+	// ErrViewNameTooLong is returned when a saved view name exceeds 100
+	// characters
+	ErrViewNameTooLong = apperrors.Validation("view_name_too_long", "view name must be at most 100 characters")
+	// ErrLoginUnavailable is returned by logins when no provider verifies
+	// passwords
+	ErrLoginUnavailable = errors.New("password login is not configured")
+	// ErrIdentityWithoutEmail is returned when a provider verified a user
+	// whose account has no email to match a local user by
+	ErrIdentityWithoutEmail = errors.New("account has no email address")
+	// ErrSignupUnavailable is returned by signups when they are not enabled
+	ErrSignupUnavailable = errors.New("signup is not enabled")
+	// ErrSessionsUnavailable is returned by session logins when server-side
+	// sessions are not enabled
+	ErrSessionsUnavailable = errors.New("sessions are not enabled")
+	// ErrMFAUnavailable is returned by MFA operations, and by password
+	// logins of users who enabled MFA, when no encryption key for TOTP
+	// secrets is configured
+	ErrMFAUnavailable = errors.New("multi-factor authentication is not configured")
+	// ErrMFARequired is returned by password logins of users who enabled
+	// MFA when no one-time code was given
+	ErrMFARequired = errors.New("one-time code required")
+	// ErrInvalidMFACode is returned for one-time codes that are wrong,
+	// expired or were already used
+	ErrInvalidMFACode = errors.New("invalid one-time code")
+	// ErrMFAAlreadyEnabled is returned when a user who enabled MFA enrolls
+	// or enables it again
+	ErrMFAAlreadyEnabled = errors.New("multi-factor authentication is already enabled")
+	// ErrMFANotEnrolled is returned when MFA is enabled before enrolling
+	ErrMFANotEnrolled = errors.New("multi-factor authentication enrollment not started")
+	// ErrMFANotEnabled is returned when disabling MFA that is not enabled
+	ErrMFANotEnabled = errors.New("multi-factor authentication is not enabled")
+	// ErrLoginLocked is returned, wrapped in a LockoutError, for logins of
+	// usernames or client addresses locked after too many failures
+	ErrLoginLocked = errors.New("too many failed logins")
+	// ErrLogoutUnavailable is returned by logouts when no store for
+	// revoked tokens is configured
+	ErrLogoutUnavailable = errors.New("logout is not enabled")
+	// ErrPasswordRequired is returned when a user signs up without a
+	// password
+	ErrPasswordRequired = apperrors.Validation("password_required", "password is required")
+	// ErrPasswordTooShort is returned for passwords shorter than the
+	// configured minimum
+	ErrPasswordTooShort = apperrors.Validation("password_too_short", "password is too short")
+	// ErrPasswordTooLong is returned for passwords longer than 72 bytes,
+	// the most bcrypt uses
+	ErrPasswordTooLong = apperrors.Validation("password_too_long", "password must be at most 72 bytes")
+	// ErrAPIKeyNameRequired is returned when an API key is created without
+	// a name
+	ErrAPIKeyNameRequired = apperrors.Validation("api_key_name_required", "API key name is required")
+	// ErrAPIKeyNameTooLong is returned when an API key name exceeds 100
+	// characters
+	ErrAPIKeyNameTooLong = apperrors.Validation("api_key_name_too_long", "API key name must be at most 100 characters")
+	// ErrAPIKeyScopesRequired is returned when an API key is created
+	// without scopes
+	ErrAPIKeyScopesRequired = apperrors.Validation("api_key_scopes_required", "at least one scope is required")
+	// ErrUnknownScope is returned for scopes that are not defined
+	ErrUnknownScope = apperrors.Validation("unknown_scope", "unknown scope")
+	// ErrAPIKeyExpiryInPast is returned when an API key would already be
+	// expired when created
+	ErrAPIKeyExpiryInPast = apperrors.Validation("api_key_expiry_in_past", "expires_at must be in the future")
+	// ErrInvalidAPIKey is returned for API keys that are malformed,
+	// unknown, revoked or expired
+	ErrInvalidAPIKey = errors.New("invalid, expired or revoked API key")
+	// ErrQuotaExceeded is returned, wrapped in a QuotaExceededError, for
+	// creations refused because their resource reached its quota
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrUnknownQuota is returned for resources no quota exists for
+	ErrUnknownQuota = apperrors.NotFound("quota_not_found", "quota not found")
+	// ErrInvalidQuotaLimit is returned when a quota is set to a negative
+	// limit
+	ErrInvalidQuotaLimit = apperrors.Validation("invalid_quota_limit", "limit must not be negative")
+	// ErrUnknownFeatureFlag is returned for feature flags that are not
+	// configured
+	ErrUnknownFeatureFlag = apperrors.NotFound("feature_flag_not_found", "feature flag not found")
+	// ErrInvalidWebhook is returned for signed billing webhooks whose
+	// payload cannot be decoded
+	ErrInvalidWebhook = errors.New("invalid webhook payload")
+	// ErrUnknownPlan is returned for subscriptions to a plan that does not
+	// exist, such as to a Stripe price missing from BILLING_STRIPE_PRICES
+	ErrUnknownPlan = errors.New("subscription is to an unknown plan")
+	// ErrOrganizationNameRequired is returned when an organization is
+	// created without a name
+	ErrOrganizationNameRequired = apperrors.Validation("organization_name_required", "organization name is required")
+	// ErrOrganizationNameTooLong is returned when an organization name
+	// exceeds 100 characters
+	ErrOrganizationNameTooLong = apperrors.Validation("organization_name_too_long", "organization name must be at most 100 characters")
+	// ErrServiceAccountNotFound is returned for service accounts that do
+	// not exist or belong to another organization
+	ErrServiceAccountNotFound = apperrors.NotFound("service_account_not_found", "service account not found")
+	// ErrServiceAccountNameRequired is returned when a service account is
+	// created without a name
+	ErrServiceAccountNameRequired = apperrors.Validation("service_account_name_required", "service account name is required")
+	// ErrServiceAccountNameTooLong is returned when a service account name
+	// exceeds 100 characters
+	ErrServiceAccountNameTooLong = apperrors.Validation("service_account_name_too_long", "service account name must be at most 100 characters")
+	// ErrScopeNotGranted is returned when a service account's key would
+	// grant a scope the account is not granted
+	ErrScopeNotGranted = apperrors.Validation("scope_not_granted", "scope is not granted to the service account")
+	// ErrScopeNotHeld is returned when an API key would grant a scope its
+	// creator does not hold, such as admin for a caller who is no admin
+	ErrScopeNotHeld = apperrors.Validation("scope_not_held", "scope is not held by the key's creator")
+	// ErrEmailChangeExpired is returned when an email change is confirmed
+	// after its link expired
+	ErrEmailChangeExpired = errors.New("email change link has expired")
+	// ErrInvalidVerificationLink is returned for signup verification links
+	// that were tampered with or whose user or email no longer exists
+	ErrInvalidVerificationLink = apperrors.NotFound("verification_link_not_found", "verification link is invalid")
+	// ErrVerificationLinkExpired is returned when an email is verified
+	// after its signup verification link expired
+	ErrVerificationLinkExpired = errors.New("verification link has expired")
+	// ErrInvalidUsageRange is returned for usage reports that end before
+	// they start or span more than 366 days
+	ErrInvalidUsageRange = apperrors.Validation("invalid_usage_range", "usage range must end after it starts and span at most 366 days")
+	// ErrInvalidWebhookURL is returned for webhook URLs that are not
+	// absolute http or https URLs
+	ErrInvalidWebhookURL = apperrors.Validation("invalid_webhook_url", "webhook URL must be an absolute http:// or https:// URL")
+	// ErrUnknownEventType is returned when a webhook subscribes to an event
+	// type that does not exist
+	ErrUnknownEventType = apperrors.Validation("unknown_event_type", "unknown event type")
+	// ErrEventNotStreamed is returned when an event stream is filtered by
+	// an event type that is not streamed
+	ErrEventNotStreamed = apperrors.Validation("event_type_not_streamed", "event type is not streamed")
+	// ErrTooManyEventStreams is returned, with a retry delay, when an
+	// instance has as many event streams open as it allows
+	ErrTooManyEventStreams = errors.New("too many event streams are open")
+	// ErrEventStreamsClosed is returned, with a retry delay, for event
+	// streams opened while the instance shuts down
+	ErrEventStreamsClosed = errors.New("event streams are closed")
+	// ErrEventStreamLagged ends event streams whose client fell too far
+	// behind
+	ErrEventStreamLagged = errors.New("event stream fell behind")
+	// ErrSuggestQueryRequired is returned for user suggestions without a
+	// query
+	ErrSuggestQueryRequired = apperrors.Validation("suggest_query_required", "suggestion query is required")
+	// ErrSuggestQueryTooLong is returned for user suggestion queries
+	// longer than 100 characters
+	ErrSuggestQueryTooLong = apperrors.Validation("suggest_query_too_long", "suggestion query must be at most 100 characters")
+	// ErrSuggestionsUnavailable is returned, with a retry delay, while the
+	// suggestion index has not been built yet
+	ErrSuggestionsUnavailable = errors.New("user suggestions are not available yet")
+	// ErrInvalidDuplicateThreshold is returned when duplicate users are
+	// looked for with a similarity outside (0, 1]
+	ErrInvalidDuplicateThreshold = apperrors.Validation("invalid_duplicate_threshold", "duplicate threshold must be greater than 0 and at most 1")
+	// ErrMergeUsersRequired is returned for merges missing the winner or
+	// the loser
+	ErrMergeUsersRequired = apperrors.Validation("merge_users_required", "winner_id and loser_id are required")
+	// ErrMergeSameUser is returned for merging a user into themselves
+	ErrMergeSameUser = apperrors.Validation("merge_same_user", "cannot merge a user into themselves")
+	// ErrMergeServiceAccount is returned for merges involving a service
+	// account, which are not duplicates of a person
+	ErrMergeServiceAccount = apperrors.Validation("merge_service_account", "service accounts cannot be merged")
+	// ErrInvalidLocale is returned for preferred locales that are not BCP
+	// 47 language tags
+	ErrInvalidLocale = apperrors.Validation("invalid_locale", "locale must be a language tag such as en or de-AT")
+	// ErrInvalidTimeZone is returned for preferred time zones missing
+	// from the IANA time zone database
+	ErrInvalidTimeZone = apperrors.Validation("invalid_time_zone", "time zone must be an IANA time zone such as Europe/Berlin")
+	// ErrReplayTargetConflict is returned for event replays to both a
+	// topic and a handler
+	ErrReplayTargetConflict = apperrors.Validation("replay_target_conflict", "events are replayed to a topic or to a handler, not both")
+	// ErrUnknownEventHandler is returned for event replays to a handler
+	// that is not registered
+	ErrUnknownEventHandler = apperrors.Validation("unknown_event_handler", "unknown event handler")
+	// ErrReplaySelectionRequired is returned for event replays selecting
+	// neither IDs nor a filter, which would replay every event
+	ErrReplaySelectionRequired = apperrors.Validation("replay_selection_required", "ids or a filter is required")
+)
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+)
+
+// EventHandlers delivers messages to one event handler, such as the
+// consumer the handlers are registered on
+type EventHandlers interface {
+	// Names returns the names of the handlers
+	Names() []string
+	// Deliver hands msg to the handler called name only
+	Deliver(ctx context.Context, name string, msg messaging.Message) error
+}
+
+// EventReplay selects published events to replay and where to. Events go
+// to their own topic unless Topic or Handler is set.
+type EventReplay struct {
+	// IDs are the events to replay; when empty, the events matching Filter
+	// are replayed
+	IDs    []string
+	Filter repositories.PublishedEventFilter
+	// Topic publishes the events on another topic than their type, for a
+	// consumer subscribing to it
+	Topic string
+	// Handler hands the events to the event handler of that name only,
+	// without publishing them
+	Handler string
+}
+
+// EventLogUseCase implements inspection and replay of published domain
+// events
+type EventLogUseCase struct {
+	eventRepo repositories.PublishedEventRepository
+	publisher messaging.Publisher
+	handlers  EventHandlers
+	logger    logger.Logger
+}
+
+// NewEventLogUseCase creates a new event log use case instance replaying
+// events with publisher and handlers
+func NewEventLogUseCase(eventRepo repositories.PublishedEventRepository, publisher messaging.Publisher, handlers EventHandlers, logger logger.Logger) *EventLogUseCase {
+	return &EventLogUseCase{
+		eventRepo: eventRepo,
+		publisher: publisher,
+		handlers:  handlers,
+		logger:    logger,
+	}
+}
+
+// ListEvents retrieves published events matching filter
+func (uc *EventLogUseCase) ListEvents(ctx context.Context, filter repositories.PublishedEventFilter) ([]*entities.PublishedEvent, error) {
+	events, err := uc.eventRepo.List(ctx, filter)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to list published events")
+		return nil, fmt.Errorf("failed to list published events: %w", err)
+	}
+	return events, nil
+}
+
+// GetEvent retrieves a published event by ID
+func (uc *EventLogUseCase) GetEvent(ctx context.Context, id string) (*entities.PublishedEvent, error) {
+	event, err := uc.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get published event: %w", err)
+	}
+	return event, nil
+}
+
+// ReplayEvent replays a published event. Published again, it keeps its ID,
+// so groups that already processed it skip it thanks to idempotency keys;
+// handed to a handler, it is processed again.
+func (uc *EventLogUseCase) ReplayEvent(ctx context.Context, id string, replay EventReplay) (*entities.PublishedEvent, error) {
+	if err := uc.validateTarget(replay); err != nil {
+		return nil, err
+	}
+	return uc.replay(ctx, id, replay)
+}
+
+// ReplayEvents replays the events of replay, in the order they were
+// published. Individual failures do not stop the remaining replays.
+func (uc *EventLogUseCase) ReplayEvents(ctx context.Context, replay EventReplay) (*ReplayResult, error) {
+	if err := uc.validateTarget(replay); err != nil {
+		return nil, err
+	}
+	ids := replay.IDs
+	if len(ids) == 0 {
+		// Replaying every event ever published is never a recovery
+		if replay.Filter.Empty() {
+			return nil, ErrReplaySelectionRequired
+		}
+		events, err := uc.ListEvents(ctx, replay.Filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+	}
+
+	result := &ReplayResult{Replayed: []string{}, Failed: []ReplayFailure{}}
+	for _, id := range ids {
+		if _, err := uc.replay(ctx, id, replay); err != nil {
+			result.Failed = append(result.Failed, ReplayFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		result.Replayed = append(result.Replayed, id)
+	}
+	return result, nil
+}
+
+// validateTarget checks that replay goes to one known place
+func (uc *EventLogUseCase) validateTarget(replay EventReplay) error {
+	if replay.Topic != "" && replay.Handler != "" {
+		return ErrReplayTargetConflict
+	}
+	if replay.Handler != "" && (uc.handlers == nil || !slices.Contains(uc.handlers.Names(), replay.Handler)) {
+		return ErrUnknownEventHandler
+	}
+	return nil
+}
+
+// replay sends the event id where replay says and records the replay
+func (uc *EventLogUseCase) replay(ctx context.Context, id string, replay EventReplay) (*entities.PublishedEvent, error) {
+	event, err := uc.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get published event: %w", err)
+	}
+
+	msg := messaging.Message{
+		ID:        event.ID,
+		Topic:     event.Type,
+		Key:       event.AggregateID,
+		Payload:   event.Payload,
+		Headers:   event.Headers,
+		Timestamp: event.OccurredAt,
+	}
+	target := msg.Topic
+	if replay.Handler != "" {
+		target = "handler " + replay.Handler
+		err = uc.handlers.Deliver(ctx, replay.Handler, msg)
+	} else {
+		if replay.Topic != "" {
+			msg.Topic, target = replay.Topic, replay.Topic
+		}
+		err = uc.publisher.Publish(ctx, msg)
+	}
+	log := uc.logger.WithFields(map[string]interface{}{
+		"event_id":   event.ID,
+		"event_type": event.Type,
+		"target":     target,
+	})
+	if err != nil {
+		log.WithField("error", err.Error()).Error("Failed to replay published event")
+		return nil, fmt.Errorf("failed to replay published event: %w", err)
+	}
+
+	event.MarkReplayed(time.Now())
+	if err := uc.eventRepo.Update(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to record replay: %w", err)
+	}
+	log.Info("Published event replayed")
+	return event, nil
+}
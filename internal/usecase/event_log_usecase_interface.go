@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// EventLogUseCaseInterface defines the interface for published event inspection and replay
+type EventLogUseCaseInterface interface {
+	ListEvents(ctx context.Context, filter repositories.PublishedEventFilter) ([]*entities.PublishedEvent, error)
+	GetEvent(ctx context.Context, id string) (*entities.PublishedEvent, error)
+	ReplayEvent(ctx context.Context, id string, replay EventReplay) (*entities.PublishedEvent, error)
+	ReplayEvents(ctx context.Context, replay EventReplay) (*ReplayResult, error)
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+)
+
+// recordingEventHandlers captures the messages delivered to each handler
+type recordingEventHandlers struct {
+	delivered map[string][]messaging.Message
+	err       error
+}
+
+func (h *recordingEventHandlers) Names() []string {
+	return []string{"audit-log", "webhooks"}
+}
+
+func (h *recordingEventHandlers) Deliver(ctx context.Context, name string, msg messaging.Message) error {
+	if h.err != nil {
+		return h.err
+	}
+	if h.delivered == nil {
+		h.delivered = make(map[string][]messaging.Message)
+	}
+	h.delivered[name] = append(h.delivered[name], msg)
+	return nil
+}
+
+func seedPublishedEvent(t *testing.T, repo repositories.PublishedEventRepository, id, eventType string, publishedAt time.Time) {
+	t.Helper()
+	err := repo.Create(context.Background(), &entities.PublishedEvent{
+		ID:          id,
+		Type:        eventType,
+		AggregateID: "user_1",
+		OccurredAt:  publishedAt,
+		PublishedAt: publishedAt,
+		Payload:     []byte(`{"id":"` + id + `"}`),
+		Headers:     map[string]string{"content-type": "application/json"},
+	})
+	if err != nil {
+		t.Fatalf("seed published event: %v", err)
+	}
+}
+
+func TestEventLogUseCase_ReplayEvent(t *testing.T) {
+	repo := database.NewMockPublishedEventRepository()
+	publisher := &recordingMessagePublisher{}
+	handlers := &recordingEventHandlers{}
+	eventLogUseCase := NewEventLogUseCase(repo, publisher, handlers, logger.New())
+	seedPublishedEvent(t, repo, "evt_1", "user.created", time.Now())
+	ctx := context.Background()
+
+	event, err := eventLogUseCase.ReplayEvent(ctx, "evt_1", EventReplay{})
+	if err != nil {
+		t.Fatalf("ReplayEvent() unexpected error: %v", err)
+	}
+	if event.ReplayCount != 1 || event.ReplayedAt == nil {
+		t.Errorf("ReplayEvent() replay not recorded: count=%d replayed_at=%v", event.ReplayCount, event.ReplayedAt)
+	}
+	if len(publisher.messages) != 1 {
+		t.Fatalf("published %d messages, want 1", len(publisher.messages))
+	}
+	msg := publisher.messages[0]
+	if msg.Topic != "user.created" || msg.ID != "evt_1" || msg.Key != "user_1" || string(msg.Payload) != `{"id":"evt_1"}` {
+		t.Errorf("ReplayEvent() published %+v", msg)
+	}
+
+	if _, err := eventLogUseCase.ReplayEvent(ctx, "evt_1", EventReplay{Topic: "users.rebuild"}); err != nil {
+		t.Fatalf("ReplayEvent() to a topic unexpected error: %v", err)
+	}
+	if got := publisher.messages[1].Topic; got != "users.rebuild" {
+		t.Errorf("ReplayEvent() to a topic published on %q, want users.rebuild", got)
+	}
+
+	if _, err := eventLogUseCase.ReplayEvent(ctx, "evt_1", EventReplay{Handler: "webhooks"}); err != nil {
+		t.Fatalf("ReplayEvent() to a handler unexpected error: %v", err)
+	}
+	if len(publisher.messages) != 2 || len(handlers.delivered["webhooks"]) != 1 {
+		t.Errorf("ReplayEvent() to a handler published %d messages and delivered %d, want 2 and 1", len(publisher.messages), len(handlers.delivered["webhooks"]))
+	}
+
+	stored, _ := repo.GetByID(ctx, "evt_1")
+	if stored.ReplayCount != 3 {
+		t.Errorf("stored replay count = %d, want 3", stored.ReplayCount)
+	}
+}
+
+func TestEventLogUseCase_ReplayEvent_Errors(t *testing.T) {
+	repo := database.NewMockPublishedEventRepository()
+	publisher := &recordingMessagePublisher{}
+	eventLogUseCase := NewEventLogUseCase(repo, publisher, &recordingEventHandlers{}, logger.New())
+	seedPublishedEvent(t, repo, "evt_1", "user.created", time.Now())
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		id     string
+		replay EventReplay
+		want   error
+	}{
+		{"unknown event", "evt_9", EventReplay{}, repositories.ErrPublishedEventNotFound},
+		{"topic and handler", "evt_1", EventReplay{Topic: "users.rebuild", Handler: "webhooks"}, ErrReplayTargetConflict},
+		{"unknown handler", "evt_1", EventReplay{Handler: "mailer"}, ErrUnknownEventHandler},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := eventLogUseCase.ReplayEvent(ctx, tt.id, tt.replay); !errors.Is(err, tt.want) {
+				t.Errorf("ReplayEvent() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	publisher.err = errors.New("broker down")
+	if _, err := eventLogUseCase.ReplayEvent(ctx, "evt_1", EventReplay{}); err == nil {
+		t.Fatal("ReplayEvent() expected an error when publishing fails")
+	}
+	stored, _ := repo.GetByID(ctx, "evt_1")
+	if stored.ReplayCount != 0 {
+		t.Errorf("ReplayEvent() recorded a failed replay")
+	}
+}
+
+func TestEventLogUseCase_ReplayEvents(t *testing.T) {
+	repo := database.NewMockPublishedEventRepository()
+	publisher := &recordingMessagePublisher{}
+	eventLogUseCase := NewEventLogUseCase(repo, publisher, nil, logger.New())
+	start := time.Now().Add(-time.Hour)
+	seedPublishedEvent(t, repo, "evt_2", "user.created", start.Add(2*time.Minute))
+	seedPublishedEvent(t, repo, "evt_1", "user.created", start.Add(time.Minute))
+	seedPublishedEvent(t, repo, "evt_3", "user.deleted", start.Add(3*time.Minute))
+	seedPublishedEvent(t, repo, "evt_0", "user.created", start.Add(-time.Minute))
+	ctx := context.Background()
+
+	result, err := eventLogUseCase.ReplayEvents(ctx, EventReplay{
+		Filter: repositories.PublishedEventFilter{Type: "user.created", Since: start},
+	})
+	if err != nil {
+		t.Fatalf("ReplayEvents() unexpected error: %v", err)
+	}
+	if len(result.Replayed) != 2 || result.Replayed[0] != "evt_1" || result.Replayed[1] != "evt_2" {
+		t.Errorf("ReplayEvents() replayed %v, want [evt_1 evt_2] in the order they were published", result.Replayed)
+	}
+
+	result, err = eventLogUseCase.ReplayEvents(ctx, EventReplay{IDs: []string{"evt_3", "evt_9"}})
+	if err != nil {
+		t.Fatalf("ReplayEvents() unexpected error: %v", err)
+	}
+	if len(result.Replayed) != 1 || len(result.Failed) != 1 || result.Failed[0].ID != "evt_9" {
+		t.Errorf("ReplayEvents() = %+v, want evt_3 replayed and evt_9 failed", result)
+	}
+
+	if _, err := eventLogUseCase.ReplayEvents(ctx, EventReplay{}); !errors.Is(err, ErrReplaySelectionRequired) {
+		t.Errorf("ReplayEvents() without a selection error = %v, want %v", err, ErrReplaySelectionRequired)
+	}
+	if _, err := eventLogUseCase.ReplayEvents(ctx, EventReplay{IDs: []string{"evt_1"}, Handler: "webhooks"}); !errors.Is(err, ErrUnknownEventHandler) {
+		t.Errorf("ReplayEvents() to a handler without handlers error = %v, want %v", err, ErrUnknownEventHandler)
+	}
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/retryafter"
+)
+
+// EventStreamOptions configures event streams
+type EventStreamOptions struct {
+	// Buffer is how many events a stream holds for a client that is slow
+	// to read them. A stream whose buffer is full is ended, so one slow
+	// client never holds up the others.
+	Buffer int
+	// MaxStreams bounds the streams open on this instance; 0 leaves them
+	// unbounded
+	MaxStreams int
+	// RetryAfter is the delay suggested to clients whose stream is refused
+	RetryAfter time.Duration
+}
+
+// EventStream receives the user changes published while it is open
+type EventStream struct {
+	// Events delivers the stream's events until it ends, when it is closed
+	Events <-chan events.Event
+
+	uc     *EventStreamUseCase
+	events chan events.Event
+	types  []string
+	err    error
+}
+
+// Err returns why the stream ended: ErrEventStreamLagged when its client
+// fell behind, ErrEventStreamsClosed on shutdown, nil while it is open or
+// after Close
+func (s *EventStream) Err() error {
+	s.uc.mu.Lock()
+	defer s.uc.mu.Unlock()
+	return s.err
+}
+
+// Close ends the stream. It is safe to call more than once.
+func (s *EventStream) Close() {
+	s.uc.mu.Lock()
+	defer s.uc.mu.Unlock()
+	s.uc.end(s, nil)
+}
+
+func (s *EventStream) wants(eventType string) bool {
+	return len(s.types) == 0 || slices.Contains(s.types, eventType)
+}
+
+// EventStreamUseCase fans the user changes consumed from the event bus out
+// to the event streams open on this instance. Streams live in memory, so
+// every instance consumes the events in a group of its own.
+type EventStreamUseCase struct {
+	opts   EventStreamOptions
+	logger logger.Logger
+
+	mu      sync.Mutex
+	streams map[*EventStream]struct{}
+	closed  bool
+}
+
+// NewEventStreamUseCase creates a new event stream use case instance
+func NewEventStreamUseCase(opts EventStreamOptions, logger logger.Logger) *EventStreamUseCase {
+	return &EventStreamUseCase{
+		opts:    opts,
+		logger:  logger,
+		streams: make(map[*EventStream]struct{}),
+	}
+}
+
+// Subscribe opens a stream of the user changes of eventTypes, or of every
+// user change when eventTypes is empty. Callers must Close the stream.
+func (uc *EventStreamUseCase) Subscribe(ctx context.Context, eventTypes []string) (*EventStream, error) {
+	for _, eventType := range eventTypes {
+		if !slices.Contains(events.Types, eventType) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+		}
+		if !slices.Contains(events.UserChanges, eventType) {
+			return nil, fmt.Errorf("%w: %s", ErrEventNotStreamed, eventType)
+		}
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.closed {
+		return nil, retryafter.Wrap(ErrEventStreamsClosed, uc.opts.RetryAfter)
+	}
+	if uc.opts.MaxStreams > 0 && len(uc.streams) >= uc.opts.MaxStreams {
+		return nil, retryafter.Wrap(ErrTooManyEventStreams, uc.opts.RetryAfter)
+	}
+
+	ch := make(chan events.Event, uc.opts.Buffer)
+	stream := &EventStream{
+		Events: ch,
+		uc:     uc,
+		events: ch,
+		types:  slices.Clone(eventTypes),
+	}
+	uc.streams[stream] = struct{}{}
+	return stream, nil
+}
+
+// Publish hands event to the streams subscribing to it without waiting on
+// their clients
+func (uc *EventStreamUseCase) Publish(ctx context.Context, event events.Event) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	for stream := range uc.streams {
+		if !stream.wants(event.Type) {
+			continue
+		}
+		select {
+		case stream.events <- event:
+		default:
+			uc.logger.WithField("event_type", event.Type).Warn("Ending event stream whose client fell behind")
+			uc.end(stream, ErrEventStreamLagged)
+		}
+	}
+	return nil
+}
+
+// Streams returns the number of open streams
+func (uc *EventStreamUseCase) Streams() int {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	return len(uc.streams)
+}
+
+// Close ends every stream and refuses new ones, so clients reconnect to
+// another instance while this one shuts down
+func (uc *EventStreamUseCase) Close() {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.closed = true
+	for stream := range uc.streams {
+		uc.end(stream, ErrEventStreamsClosed)
+	}
+}
+
+// end removes stream and closes its channel; uc.mu must be held
+func (uc *EventStreamUseCase) end(stream *EventStream, err error) {
+	if _, ok := uc.streams[stream]; !ok {
+		return
+	}
+	delete(uc.streams, stream)
+	stream.err = err
+	close(stream.events)
+}
@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"context"
+)
+
+// EventStreamUseCaseInterface defines the interface for streaming user
+// changes to clients
+type EventStreamUseCaseInterface interface {
+	Subscribe(ctx context.Context, eventTypes []string) (*EventStream, error)
+}
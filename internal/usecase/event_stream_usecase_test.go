@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/retryafter"
+)
+
+func TestEventStreamUseCase_Publish(t *testing.T) {
+	ctx := context.Background()
+	streams := NewEventStreamUseCase(EventStreamOptions{Buffer: 4}, logger.New())
+
+	all, err := streams.Subscribe(ctx, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+	defer all.Close()
+	deletions, err := streams.Subscribe(ctx, []string{events.UserDeleted})
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+	defer deletions.Close()
+
+	created := events.NewEvent(events.UserCreated, "user_1", nil)
+	deleted := events.NewEvent(events.UserDeleted, "user_1", nil)
+	for _, event := range []events.Event{created, deleted} {
+		if err := streams.Publish(ctx, event); err != nil {
+			t.Fatalf("Publish() unexpected error: %v", err)
+		}
+	}
+
+	for _, want := range []string{created.ID, deleted.ID} {
+		if got := <-all.Events; got.ID != want {
+			t.Errorf("unfiltered stream got %s, want %s", got.ID, want)
+		}
+	}
+	if got := <-deletions.Events; got.ID != deleted.ID {
+		t.Errorf("filtered stream got %s, want %s", got.ID, deleted.ID)
+	}
+	if len(deletions.Events) != 0 {
+		t.Errorf("filtered stream holds %d more events, want none", len(deletions.Events))
+	}
+}
+
+func TestEventStreamUseCase_Subscribe(t *testing.T) {
+	ctx := context.Background()
+	streams := NewEventStreamUseCase(EventStreamOptions{Buffer: 1, MaxStreams: 1, RetryAfter: 5 * time.Second}, logger.New())
+
+	for _, tc := range []struct {
+		eventType string
+		want      error
+	}{
+		{"user.renamed", ErrUnknownEventType},
+		{events.UserDeletionScheduled, ErrEventNotStreamed},
+	} {
+		if _, err := streams.Subscribe(ctx, []string{tc.eventType}); !errors.Is(err, tc.want) {
+			t.Errorf("Subscribe(%s) error = %v, want %v", tc.eventType, err, tc.want)
+		}
+	}
+
+	stream, err := streams.Subscribe(ctx, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+	_, err = streams.Subscribe(ctx, nil)
+	if !errors.Is(err, ErrTooManyEventStreams) {
+		t.Fatalf("Subscribe() over the limit error = %v, want %v", err, ErrTooManyEventStreams)
+	}
+	if after, ok := retryafter.Of(err); !ok || after != 5*time.Second {
+		t.Errorf("retry after = %v, %v, want 5s", after, ok)
+	}
+
+	stream.Close()
+	stream.Close()
+	if n := streams.Streams(); n != 0 {
+		t.Errorf("Streams() after Close = %d, want 0", n)
+	}
+	if _, open := <-stream.Events; open {
+		t.Error("Events still open after Close")
+	}
+	if err := stream.Err(); err != nil {
+		t.Errorf("Err() after Close = %v, want nil", err)
+	}
+}
+
+func TestEventStreamUseCase_EndsLaggingStreams(t *testing.T) {
+	ctx := context.Background()
+	streams := NewEventStreamUseCase(EventStreamOptions{Buffer: 1}, logger.New())
+	stream, err := streams.Subscribe(ctx, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+
+	for range 2 {
+		if err := streams.Publish(ctx, events.NewEvent(events.UserUpdated, "user_1", nil)); err != nil {
+			t.Fatalf("Publish() unexpected error: %v", err)
+		}
+	}
+
+	received := 0
+	for range stream.Events {
+		received++
+	}
+	if received != 1 {
+		t.Errorf("received %d events, want the 1 buffered", received)
+	}
+	if err := stream.Err(); !errors.Is(err, ErrEventStreamLagged) {
+		t.Errorf("Err() = %v, want %v", err, ErrEventStreamLagged)
+	}
+}
+
+func TestEventStreamUseCase_Close(t *testing.T) {
+	ctx := context.Background()
+	streams := NewEventStreamUseCase(EventStreamOptions{Buffer: 1, RetryAfter: time.Second}, logger.New())
+	stream, err := streams.Subscribe(ctx, nil)
+	if err != nil {
+		t.Fatalf("Subscribe() unexpected error: %v", err)
+	}
+
+	streams.Close()
+
+	if _, open := <-stream.Events; open {
+		t.Error("Events still open after the use case closed")
+	}
+	if err := stream.Err(); !errors.Is(err, ErrEventStreamsClosed) {
+		t.Errorf("Err() = %v, want %v", err, ErrEventStreamsClosed)
+	}
+	if _, err := streams.Subscribe(ctx, nil); !errors.Is(err, ErrEventStreamsClosed) {
+		t.Errorf("Subscribe() after Close error = %v, want %v", err, ErrEventStreamsClosed)
+	}
+	stream.Close()
+}
@@ -0,0 +1,336 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/apperrors"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/storage"
+)
+
+// JobTypeUserExport is the job type of user exports
+const JobTypeUserExport = "users.export"
+
+// Export formats
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// ExportFormats lists the supported export formats
+var ExportFormats = []string{ExportFormatCSV, ExportFormatNDJSON}
+
+// ErrUnsupportedExportFormat is returned when an export is requested in an
+// unknown format
+var ErrUnsupportedExportFormat = apperrors.Validation("unsupported_export_format", "unsupported export format")
+
+// cleanupBatchSize is the number of expired exports removed per query
+const cleanupBatchSize = 100
+
+// ExportOptions configures user exports
+type ExportOptions struct {
+	// PageSize is the number of users read from the repository at a time
+	PageSize int
+	// Retention is how long artifacts are kept after an export finishes
+	Retention time.Duration
+	// URLTTL is how long a download URL stays valid once issued
+	URLTTL time.Duration
+}
+
+// Export is a user export job with its download link once it succeeded
+type Export struct {
+	Job    *entities.Job
+	Format string
+	Rows   int64
+	Size   int64
+	// DownloadURL is set for succeeded exports whose artifact is retained
+	DownloadURL       string
+	DownloadExpiresAt *time.Time
+}
+
+// ExportUseCase runs user exports as background jobs that stream into
+// object storage
+type ExportUseCase struct {
+	userRepo repositories.UserRepository
+	jobRepo  repositories.JobRepository
+	queue    JobQueue
+	store    storage.Storage
+	opts     ExportOptions
+	logger   logger.Logger
+}
+
+// NewExportUseCase creates a new export use case instance
+func NewExportUseCase(userRepo repositories.UserRepository, jobRepo repositories.JobRepository, queue JobQueue, store storage.Storage, opts ExportOptions, logger logger.Logger) *ExportUseCase {
+	return &ExportUseCase{
+		userRepo: userRepo,
+		jobRepo:  jobRepo,
+		queue:    queue,
+		store:    store,
+		opts:     opts,
+		logger:   logger,
+	}
+}
+
+// RequestExport queues an export of every user in format
+func (uc *ExportUseCase) RequestExport(ctx context.Context, format, requestedBy string) (*Export, error) {
+	if !isExportFormat(format) {
+		return nil, ErrUnsupportedExportFormat
+	}
+
+	job := entities.NewJob(JobTypeUserExport, requestedBy, map[string]string{"format": format})
+	if err := uc.jobRepo.Create(ctx, job); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to create export job")
+		return nil, fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	if err := uc.queue.Enqueue(ctx, job); err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"job_id": job.ID,
+			"error":  err.Error(),
+		}).Error("Failed to enqueue export job")
+
+		job.Fail("could not be queued", time.Now())
+		if updateErr := uc.jobRepo.Update(ctx, job); updateErr != nil {
+			uc.logger.WithField("error", updateErr.Error()).Error("Failed to record export job failure")
+		}
+		return nil, fmt.Errorf("failed to enqueue export job: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"job_id": job.ID,
+		"format": format,
+	}).Info("User export queued")
+	return uc.present(ctx, job)
+}
+
+// GetExport retrieves an export job. Succeeded exports carry a freshly
+// signed download URL.
+func (uc *ExportUseCase) GetExport(ctx context.Context, id string) (*Export, error) {
+	job, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export: %w", err)
+	}
+	if job.Type != JobTypeUserExport {
+		return nil, repositories.ErrJobNotFound
+	}
+	return uc.present(ctx, job)
+}
+
+// RunExport streams every user into object storage. It is called by the
+// job workers and may run again for the same job when an attempt fails.
+func (uc *ExportUseCase) RunExport(ctx context.Context, id string) error {
+	job, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get export job: %w", err)
+	}
+	if job.IsFinished() {
+		return nil
+	}
+
+	job.Start(time.Now())
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to start export job: %w", err)
+	}
+
+	format := job.Params["format"]
+	key := "exports/" + job.ID + "." + format
+
+	// Users are encoded into a pipe as they are read, so the export is
+	// never held in memory
+	pr, pw := io.Pipe()
+	encoded := make(chan int64, 1)
+	go func() {
+		rows, err := uc.encodeUsers(ctx, pw, format)
+		pw.CloseWithError(err)
+		encoded <- rows
+	}()
+
+	size, err := uc.store.Put(ctx, key, pr)
+	pr.CloseWithError(err)
+	rows := <-encoded
+	if err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"job_id": job.ID,
+			"error":  err.Error(),
+		}).Error("User export failed")
+
+		// Clients see the job's error, so internal details stay in the log
+		job.Fail("export failed", time.Now())
+		if updateErr := uc.jobRepo.Update(ctx, job); updateErr != nil {
+			uc.logger.WithField("error", updateErr.Error()).Error("Failed to record export job failure")
+		}
+		return fmt.Errorf("failed to export users: %w", err)
+	}
+
+	now := time.Now()
+	job.Succeed(map[string]string{
+		"key":  key,
+		"rows": strconv.FormatInt(rows, 10),
+		"size": strconv.FormatInt(size, 10),
+	}, now.Add(uc.opts.Retention), now)
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to complete export job: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"job_id": job.ID,
+		"rows":   rows,
+		"size":   size,
+	}).Info("User export completed")
+	return nil
+}
+
+// StreamExport writes every user to w in format as they are read, for
+// clients downloading an export directly instead of through a job. It stops
+// with ctx's error once ctx is done, e.g. when the client disconnects.
+func (uc *ExportUseCase) StreamExport(ctx context.Context, w io.Writer, format string) (int64, error) {
+	if !isExportFormat(format) {
+		return 0, ErrUnsupportedExportFormat
+	}
+
+	rows, err := uc.encodeUsers(ctx, w, format)
+	if err != nil {
+		if ctx.Err() != nil {
+			uc.logger.WithField("rows", rows).Info("User export stream canceled")
+			return rows, ctx.Err()
+		}
+		return rows, fmt.Errorf("failed to stream users: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"format": format,
+		"rows":   rows,
+	}).Info("User export streamed")
+	return rows, nil
+}
+
+// CleanupExpired deletes the artifacts of exports whose retention ended
+// before now and returns how many were removed
+func (uc *ExportUseCase) CleanupExpired(ctx context.Context, now time.Time) (int, error) {
+	removed := 0
+	for {
+		expired, err := uc.jobRepo.ListExpired(ctx, JobTypeUserExport, now, cleanupBatchSize)
+		if err != nil {
+			return removed, fmt.Errorf("failed to list expired exports: %w", err)
+		}
+
+		failed := 0
+		for _, job := range expired {
+			if err := uc.expire(ctx, job); err != nil {
+				uc.logger.WithFields(map[string]interface{}{
+					"job_id": job.ID,
+					"error":  err.Error(),
+				}).Error("Failed to remove expired export")
+				failed++
+				continue
+			}
+			removed++
+		}
+
+		if len(expired) < cleanupBatchSize || failed == len(expired) {
+			return removed, nil
+		}
+	}
+}
+
+func (uc *ExportUseCase) expire(ctx context.Context, job *entities.Job) error {
+	if key := job.Result["key"]; key != "" {
+		if err := uc.store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	job.Expire()
+	return uc.jobRepo.Update(ctx, job)
+}
+
+// encodeUsers writes every user to w in format, a page at a time
+func (uc *ExportUseCase) encodeUsers(ctx context.Context, w io.Writer, format string) (int64, error) {
+	var write func(*entities.User) error
+	var flush func() error
+
+	switch format {
+	case ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"id", "email", "name", "created_at", "updated_at"}); err != nil {
+			return 0, err
+		}
+		write = func(u *entities.User) error {
+			return cw.Write([]string{u.ID, u.Email, u.Name, u.CreatedAt.Format(time.RFC3339), u.UpdatedAt.Format(time.RFC3339)})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case ExportFormatNDJSON:
+		enc := json.NewEncoder(w)
+		write = func(u *entities.User) error { return enc.Encode(u) }
+		flush = func() error { return nil }
+	default:
+		return 0, ErrUnsupportedExportFormat
+	}
+
+	var rows int64
+	for offset := 0; ; offset += uc.opts.PageSize {
+		if err := ctx.Err(); err != nil {
+			return rows, err
+		}
+		users, err := uc.userRepo.List(ctx, uc.opts.PageSize, offset)
+		if err != nil {
+			return rows, err
+		}
+		for _, user := range users {
+			if err := write(user); err != nil {
+				return rows, err
+			}
+			rows++
+		}
+		if err := flush(); err != nil {
+			return rows, err
+		}
+		if len(users) < uc.opts.PageSize {
+			return rows, nil
+		}
+	}
+}
+
+// present describes a job as an export, signing a download URL when its
+// artifact is available
+func (uc *ExportUseCase) present(ctx context.Context, job *entities.Job) (*Export, error) {
+	export := &Export{Job: job, Format: job.Params["format"]}
+	export.Rows, _ = strconv.ParseInt(job.Result["rows"], 10, 64)
+	export.Size, _ = strconv.ParseInt(job.Result["size"], 10, 64)
+
+	if job.Status != entities.JobSucceeded || job.ExpiresAt == nil {
+		return export, nil
+	}
+
+	// The link never outlives the artifact
+	expiresAt := time.Now().Add(uc.opts.URLTTL)
+	if job.ExpiresAt.Before(expiresAt) {
+		expiresAt = *job.ExpiresAt
+	}
+	url, err := uc.store.SignedURL(ctx, job.Result["key"], expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign download URL: %w", err)
+	}
+	export.DownloadURL = url
+	export.DownloadExpiresAt = &expiresAt
+	return export, nil
+}
+
+func isExportFormat(format string) bool {
+	for _, f := range ExportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
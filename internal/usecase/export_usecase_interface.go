@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+	"io"
+)
+
+// ExportUseCaseInterface defines the interface for user exports
+type ExportUseCaseInterface interface {
+	RequestExport(ctx context.Context, format, requestedBy string) (*Export, error)
+	GetExport(ctx context.Context, id string) (*Export, error)
+	StreamExport(ctx context.Context, w io.Writer, format string) (int64, error)
+}
@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/storage"
+)
+
+// recordingJobQueue captures enqueued jobs
+type recordingJobQueue struct {
+	jobs []string
+	err  error
+}
+
+func (q *recordingJobQueue) Enqueue(ctx context.Context, job *entities.Job) error {
+	if q.err != nil {
+		return q.err
+	}
+	q.jobs = append(q.jobs, job.ID)
+	return nil
+}
+
+type exportFixture struct {
+	jobRepo repositories.JobRepository
+	queue   *recordingJobQueue
+	store   *storage.Local
+	useCase *ExportUseCase
+}
+
+func newExportFixture(t *testing.T, users int) *exportFixture {
+	t.Helper()
+	store, err := storage.NewLocal(t.TempDir(), "http://localhost:8080/api/v1/downloads", []byte("secret"))
+	if err != nil {
+		t.Fatalf("create storage: %v", err)
+	}
+
+	userRepo := database.NewMockUserRepository()
+	for i := 0; i < users; i++ {
+		user := entities.NewUser(string(rune('a'+i))+"@example.com", "User")
+		user.ID = "user_" + string(rune('a'+i))
+		if err := userRepo.Create(context.Background(), user); err != nil {
+			t.Fatalf("seed user: %v", err)
+		}
+	}
+
+	f := &exportFixture{
+		jobRepo: database.NewMockJobRepository(),
+		queue:   &recordingJobQueue{},
+		store:   store,
+	}
+	f.useCase = NewExportUseCase(userRepo, f.jobRepo, f.queue, store, ExportOptions{
+		PageSize:  2,
+		Retention: time.Hour,
+		URLTTL:    time.Minute,
+	}, logger.New())
+	return f
+}
+
+func TestExportUseCase_RequestExport(t *testing.T) {
+	f := newExportFixture(t, 0)
+
+	export, err := f.useCase.RequestExport(context.Background(), ExportFormatCSV, "user_1")
+	if err != nil {
+		t.Fatalf("RequestExport() unexpected error: %v", err)
+	}
+	if export.Job.Status != entities.JobQueued || export.Job.RequestedBy != "user_1" {
+		t.Errorf("RequestExport() job = %+v, want queued by user_1", export.Job)
+	}
+	if len(f.queue.jobs) != 1 || f.queue.jobs[0] != export.Job.ID {
+		t.Errorf("enqueued %v, want [%s]", f.queue.jobs, export.Job.ID)
+	}
+	if export.DownloadURL != "" {
+		t.Errorf("queued export has a download URL")
+	}
+
+	if _, err := f.useCase.RequestExport(context.Background(), "xlsx", ""); !errors.Is(err, ErrUnsupportedExportFormat) {
+		t.Errorf("RequestExport() error = %v, want ErrUnsupportedExportFormat", err)
+	}
+}
+
+func TestExportUseCase_RequestExport_QueueFailure(t *testing.T) {
+	f := newExportFixture(t, 0)
+	f.queue.err = errors.New("broker closed")
+
+	if _, err := f.useCase.RequestExport(context.Background(), ExportFormatCSV, ""); err == nil {
+		t.Fatalf("RequestExport() expected error but got none")
+	}
+}
+
+func TestExportUseCase_RunExport(t *testing.T) {
+	f := newExportFixture(t, 3)
+	ctx := context.Background()
+
+	requested, err := f.useCase.RequestExport(ctx, ExportFormatCSV, "")
+	if err != nil {
+		t.Fatalf("RequestExport() unexpected error: %v", err)
+	}
+	if err := f.useCase.RunExport(ctx, requested.Job.ID); err != nil {
+		t.Fatalf("RunExport() unexpected error: %v", err)
+	}
+
+	export, err := f.useCase.GetExport(ctx, requested.Job.ID)
+	if err != nil {
+		t.Fatalf("GetExport() unexpected error: %v", err)
+	}
+	if export.Job.Status != entities.JobSucceeded {
+		t.Fatalf("export status = %v, want succeeded", export.Job.Status)
+	}
+	if export.Rows != 3 {
+		t.Errorf("export rows = %d, want 3", export.Rows)
+	}
+	if export.DownloadURL == "" || export.DownloadExpiresAt == nil {
+		t.Errorf("succeeded export has no download URL")
+	}
+	if export.DownloadExpiresAt != nil && export.DownloadExpiresAt.After(time.Now().Add(time.Minute)) {
+		t.Errorf("download URL expires at %v, beyond the URL TTL", export.DownloadExpiresAt)
+	}
+
+	r, err := f.store.Open(ctx, export.Job.Result["key"])
+	if err != nil {
+		t.Fatalf("open artifact: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 4 || lines[0] != "id,email,name,created_at,updated_at" {
+		t.Errorf("artifact = %q, want a header and 3 rows", data)
+	}
+	if export.Size != int64(len(data)) {
+		t.Errorf("export size = %d, want %d", export.Size, len(data))
+	}
+
+	// Redelivered jobs are not run again
+	if err := f.useCase.RunExport(ctx, requested.Job.ID); err != nil {
+		t.Errorf("RunExport() repeat unexpected error: %v", err)
+	}
+	job, _ := f.jobRepo.GetByID(ctx, requested.Job.ID)
+	if job.Attempts != 1 {
+		t.Errorf("job attempts = %d, want 1", job.Attempts)
+	}
+}
+
+func TestExportUseCase_StreamExport(t *testing.T) {
+	f := newExportFixture(t, 3)
+
+	var out strings.Builder
+	rows, err := f.useCase.StreamExport(context.Background(), &out, ExportFormatNDJSON)
+	if err != nil {
+		t.Fatalf("StreamExport() unexpected error: %v", err)
+	}
+	if rows != 3 {
+		t.Errorf("StreamExport() rows = %d, want 3", rows)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 3 {
+		t.Errorf("StreamExport() wrote %q, want 3 lines", out.String())
+	}
+
+	if _, err := f.useCase.StreamExport(context.Background(), &out, "pdf"); !errors.Is(err, ErrUnsupportedExportFormat) {
+		t.Errorf("StreamExport() error = %v, want %v", err, ErrUnsupportedExportFormat)
+	}
+}
+
+// cancelingWriter cancels its context once written to, as a client
+// disconnecting after the first page
+type cancelingWriter struct {
+	cancel context.CancelFunc
+	writes int
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	w.cancel()
+	return len(p), nil
+}
+
+func TestExportUseCase_StreamExport_Canceled(t *testing.T) {
+	f := newExportFixture(t, 5)
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &cancelingWriter{cancel: cancel}
+
+	rows, err := f.useCase.StreamExport(ctx, w, ExportFormatCSV)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("StreamExport() error = %v, want %v", err, context.Canceled)
+	}
+	// The CSV writer flushes once per page of 2 users
+	if rows != 2 || w.writes != 1 {
+		t.Errorf("StreamExport() rows = %d after %d writes, want 2 after 1", rows, w.writes)
+	}
+}
+
+func TestExportUseCase_CleanupExpired(t *testing.T) {
+	f := newExportFixture(t, 1)
+	ctx := context.Background()
+
+	requested, err := f.useCase.RequestExport(ctx, ExportFormatNDJSON, "")
+	if err != nil {
+		t.Fatalf("RequestExport() unexpected error: %v", err)
+	}
+	if err := f.useCase.RunExport(ctx, requested.Job.ID); err != nil {
+		t.Fatalf("RunExport() unexpected error: %v", err)
+	}
+	job, _ := f.jobRepo.GetByID(ctx, requested.Job.ID)
+
+	removed, err := f.useCase.CleanupExpired(ctx, time.Now())
+	if err != nil || removed != 0 {
+		t.Fatalf("CleanupExpired() before retention = %d, %v, want 0, nil", removed, err)
+	}
+
+	removed, err = f.useCase.CleanupExpired(ctx, job.ExpiresAt.Add(time.Second))
+	if err != nil || removed != 1 {
+		t.Fatalf("CleanupExpired() = %d, %v, want 1, nil", removed, err)
+	}
+
+	if _, err := f.store.Open(ctx, job.Result["key"]); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("artifact still exists after cleanup: %v", err)
+	}
+	export, err := f.useCase.GetExport(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("GetExport() unexpected error: %v", err)
+	}
+	if export.Job.Status != entities.JobExpired || export.DownloadURL != "" {
+		t.Errorf("expired export = %v with URL %q, want expired without URL", export.Job.Status, export.DownloadURL)
+	}
+}
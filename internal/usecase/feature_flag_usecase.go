@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/logger"
+)
+
+// FeatureFlagState is whether a feature flag is on
+type FeatureFlagState struct {
+	Key     string
+	Enabled bool
+	// Default reports whether Enabled is the configured default because no
+	// administrator switched the flag
+	Default bool
+	// UpdatedAt is when an administrator last switched the flag; zero for
+	// defaults
+	UpdatedAt time.Time
+}
+
+// FeatureFlagUseCase lets administrators switch features on and off at
+// runtime. The flags and their default states are configured; switching
+// one stores its state, which every instance reads.
+type FeatureFlagUseCase struct {
+	flagRepo repositories.FeatureFlagRepository
+	// defaults are the states of flags no administrator switched, which
+	// also names the flags that exist
+	defaults map[string]bool
+	logger   logger.Logger
+}
+
+// NewFeatureFlagUseCase creates a new feature flag use case for the flags
+// in defaults
+func NewFeatureFlagUseCase(flagRepo repositories.FeatureFlagRepository, defaults map[string]bool, logger logger.Logger) *FeatureFlagUseCase {
+	return &FeatureFlagUseCase{
+		flagRepo: flagRepo,
+		defaults: defaults,
+		logger:   logger,
+	}
+}
+
+// ListFeatureFlags returns the state of every flag, by key
+func (uc *FeatureFlagUseCase) ListFeatureFlags(ctx context.Context) ([]*FeatureFlagState, error) {
+	stored, err := uc.flagRepo.List(consistency.WithPrimary(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	set := make(map[string]*entities.FeatureFlag, len(stored))
+	for _, flag := range stored {
+		set[flag.Key] = flag
+	}
+
+	flags := make([]*FeatureFlagState, 0, len(uc.defaults))
+	for key, enabled := range uc.defaults {
+		if flag, ok := set[key]; ok {
+			flags = append(flags, uc.state(flag))
+			continue
+		}
+		flags = append(flags, &FeatureFlagState{Key: key, Enabled: enabled, Default: true})
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags, nil
+}
+
+// SetFeatureFlag switches the flag key on or off
+func (uc *FeatureFlagUseCase) SetFeatureFlag(ctx context.Context, key string, enabled bool) (*FeatureFlagState, error) {
+	if _, ok := uc.defaults[key]; !ok {
+		return nil, ErrUnknownFeatureFlag
+	}
+
+	flag := entities.NewFeatureFlag(key, enabled)
+	if err := uc.flagRepo.Save(ctx, flag); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to save feature flag")
+		return nil, fmt.Errorf("failed to save feature flag: %w", err)
+	}
+	uc.logger.WithFields(map[string]interface{}{
+		"flag":    key,
+		"enabled": enabled,
+	}).Info("Feature flag switched")
+	return uc.state(flag), nil
+}
+
+// Enabled reports whether the flag key is on. Unknown flags are off.
+func (uc *FeatureFlagUseCase) Enabled(ctx context.Context, key string) (bool, error) {
+	enabled, ok := uc.defaults[key]
+	if !ok {
+		return false, nil
+	}
+	flag, err := uc.flagRepo.Get(ctx, key)
+	if errors.Is(err, repositories.ErrFeatureFlagNotFound) {
+		return enabled, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get feature flag %s: %w", key, err)
+	}
+	return flag.Enabled, nil
+}
+
+// Flags returns whether each flag is on, by key. It is the source request
+// contexts resolve their flags from, so failures are logged here.
+func (uc *FeatureFlagUseCase) Flags(ctx context.Context) (map[string]bool, error) {
+	listed, err := uc.ListFeatureFlags(ctx)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to resolve feature flags")
+		return nil, err
+	}
+	flags := make(map[string]bool, len(listed))
+	for _, flag := range listed {
+		flags[flag.Key] = flag.Enabled
+	}
+	return flags, nil
+}
+
+func (uc *FeatureFlagUseCase) state(flag *entities.FeatureFlag) *FeatureFlagState {
+	return &FeatureFlagState{
+		Key:       flag.Key,
+		Enabled:   flag.Enabled,
+		UpdatedAt: flag.UpdatedAt,
+	}
+}
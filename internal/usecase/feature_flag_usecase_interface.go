@@ -0,0 +1,10 @@
+package usecase
+
+import "context"
+
+// FeatureFlagUseCaseInterface defines the interface for viewing and
+// toggling feature flags
+type FeatureFlagUseCaseInterface interface {
+	ListFeatureFlags(ctx context.Context) ([]*FeatureFlagState, error)
+	SetFeatureFlag(ctx context.Context, key string, enabled bool) (*FeatureFlagState, error)
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+func TestFeatureFlagUseCase_ListAndSet(t *testing.T) {
+	ctx := context.Background()
+	flags := NewFeatureFlagUseCase(database.NewMockFeatureFlagRepository(), map[string]bool{
+		"new_dashboard": false,
+		"beta_search":   true,
+	}, logger.New())
+
+	listed, err := flags.ListFeatureFlags(ctx)
+	if err != nil {
+		t.Fatalf("ListFeatureFlags() unexpected error: %v", err)
+	}
+	if len(listed) != 2 || listed[0].Key != "beta_search" || !listed[0].Enabled || !listed[0].Default ||
+		listed[1].Key != "new_dashboard" || listed[1].Enabled {
+		t.Fatalf("ListFeatureFlags() = %+v, want both defaults by key", listed)
+	}
+
+	state, err := flags.SetFeatureFlag(ctx, "new_dashboard", true)
+	if err != nil {
+		t.Fatalf("SetFeatureFlag() unexpected error: %v", err)
+	}
+	if !state.Enabled || state.Default || state.UpdatedAt.IsZero() {
+		t.Errorf("SetFeatureFlag() = %+v, want the switched state", state)
+	}
+	if enabled, err := flags.Enabled(ctx, "new_dashboard"); err != nil || !enabled {
+		t.Errorf("Enabled() = %v, %v, want true", enabled, err)
+	}
+
+	listed, err = flags.ListFeatureFlags(ctx)
+	if err != nil {
+		t.Fatalf("ListFeatureFlags() unexpected error: %v", err)
+	}
+	if !listed[1].Enabled || listed[1].Default {
+		t.Errorf("ListFeatureFlags() new_dashboard = %+v, want it switched on", listed[1])
+	}
+
+	resolved, err := flags.Flags(ctx)
+	if err != nil {
+		t.Fatalf("Flags() unexpected error: %v", err)
+	}
+	if len(resolved) != 2 || !resolved["beta_search"] || !resolved["new_dashboard"] {
+		t.Errorf("Flags() = %v, want both flags on", resolved)
+	}
+}
+
+func TestFeatureFlagUseCase_UnknownFlag(t *testing.T) {
+	ctx := context.Background()
+	flags := NewFeatureFlagUseCase(database.NewMockFeatureFlagRepository(), map[string]bool{"beta_search": true}, logger.New())
+
+	if _, err := flags.SetFeatureFlag(ctx, "dark_mode", true); !errors.Is(err, ErrUnknownFeatureFlag) {
+		t.Errorf("SetFeatureFlag() error = %v, want ErrUnknownFeatureFlag", err)
+	}
+	if enabled, err := flags.Enabled(ctx, "dark_mode"); err != nil || enabled {
+		t.Errorf("Enabled() = %v, %v, want unknown flags off", enabled, err)
+	}
+	if enabled, _ := flags.Enabled(ctx, "beta_search"); !enabled {
+		t.Error("Enabled() = false, want the default of flags never switched")
+	}
+}
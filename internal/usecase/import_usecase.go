@@ -0,0 +1,630 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/apperrors"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/storage"
+)
+
+// JobTypeUserImport is the job type of user imports and the purpose of the
+// uploads they read from
+const JobTypeUserImport = "users.import"
+
+var (
+	// ErrInvalidChecksum is returned when a checksum is not a SHA-256 digest
+	ErrInvalidChecksum = apperrors.Validation("invalid_checksum", "checksum must be a SHA-256 digest")
+	// ErrUploadTooLarge is returned when an upload exceeds the maximum size
+	// or a chunk extends past the announced size
+	ErrUploadTooLarge = apperrors.Validation("upload_too_large", "upload exceeds its maximum size")
+	// ErrChunkTooLarge is returned when a chunk exceeds the maximum chunk size
+	ErrChunkTooLarge = apperrors.Validation("chunk_too_large", "chunk exceeds the maximum chunk size")
+	// ErrChunkChecksumMismatch is returned when a chunk does not match the
+	// checksum sent with it
+	ErrChunkChecksumMismatch = apperrors.Validation("chunk_checksum_mismatch", "chunk checksum does not match")
+	// ErrUploadOffsetMismatch is returned when a chunk does not start at the
+	// number of bytes received so far
+	ErrUploadOffsetMismatch = apperrors.Conflict("upload_offset_mismatch", "chunk offset does not match the upload offset")
+	// ErrUploadNotActive is returned when an upload no longer accepts chunks
+	ErrUploadNotActive = apperrors.Conflict("upload_not_active", "upload is no longer active")
+	// ErrUploadIncomplete is returned when an upload is completed before
+	// every byte was received
+	ErrUploadIncomplete = apperrors.Conflict("upload_incomplete", "upload is incomplete")
+)
+
+// importCheckpointRows is how many rows are imported between checkpoints, so
+// a retried import resumes close to where the previous attempt stopped
+const importCheckpointRows = 1000
+
+// maxImportErrors is the number of row errors kept on an import
+const maxImportErrors = 20
+
+// ImportOptions configures chunked uploads and user imports
+type ImportOptions struct {
+	// MaxSize is the largest file an upload may announce
+	MaxSize int64
+	// ChunkSize is the largest chunk accepted at a time
+	ChunkSize int64
+	// UploadTTL is how long an unfinished upload is kept
+	UploadTTL time.Duration
+}
+
+// ImportError describes a row that could not be imported
+type ImportError struct {
+	Row   int64  `json:"row"`
+	Error string `json:"error"`
+}
+
+// Import is a user import job with its progress
+type Import struct {
+	Job      *entities.Job
+	UploadID string
+	// Rows is the number of data rows processed so far
+	Rows    int64
+	Created int64
+	Skipped int64
+	Failed  int64
+	// Errors holds the first row errors
+	Errors []ImportError
+}
+
+// ImportUseCase receives CSV files in resumable chunks and imports their
+// users as background jobs
+type ImportUseCase struct {
+	users      UserUseCaseInterface
+	uploadRepo repositories.UploadRepository
+	jobRepo    repositories.JobRepository
+	queue      JobQueue
+	store      storage.Storage
+	opts       ImportOptions
+	logger     logger.Logger
+}
+
+// NewImportUseCase creates a new import use case instance. Rows are created
+// through users so they are validated and announced like any other user.
+func NewImportUseCase(users UserUseCaseInterface, uploadRepo repositories.UploadRepository, jobRepo repositories.JobRepository, queue JobQueue, store storage.Storage, opts ImportOptions, logger logger.Logger) *ImportUseCase {
+	return &ImportUseCase{
+		users:      users,
+		uploadRepo: uploadRepo,
+		jobRepo:    jobRepo,
+		queue:      queue,
+		store:      store,
+		opts:       opts,
+		logger:     logger,
+	}
+}
+
+// CreateUpload starts an upload of size bytes whose hex SHA-256 is checksum
+func (uc *ImportUseCase) CreateUpload(ctx context.Context, filename string, size int64, checksum, createdBy string) (*entities.Upload, error) {
+	checksum = strings.ToLower(checksum)
+	if digest, err := hex.DecodeString(checksum); err != nil || len(digest) != sha256.Size {
+		return nil, ErrInvalidChecksum
+	}
+	if size < 1 || size > uc.opts.MaxSize {
+		return nil, ErrUploadTooLarge
+	}
+
+	upload := entities.NewUpload(JobTypeUserImport, filename, size, checksum, createdBy, uc.opts.UploadTTL)
+	if err := uc.uploadRepo.Create(ctx, upload); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to create upload")
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"upload_id": upload.ID,
+		"size":      size,
+	}).Info("Import upload started")
+	return upload, nil
+}
+
+// GetUpload retrieves an import upload, including how many bytes were
+// received so a client can resume after a dropped connection
+func (uc *ImportUseCase) GetUpload(ctx context.Context, id string) (*entities.Upload, error) {
+	upload, err := uc.uploadRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload: %w", err)
+	}
+	if upload.Purpose != JobTypeUserImport {
+		return nil, repositories.ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+// AppendChunk stores the chunk read from r at offset. When digest is set the
+// chunk must match its SHA-256. A chunk is only accepted at the current
+// offset of the upload.
+func (uc *ImportUseCase) AppendChunk(ctx context.Context, id string, offset int64, r io.Reader, digest []byte) (*entities.Upload, error) {
+	upload, err := uc.GetUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != entities.UploadActive {
+		return nil, ErrUploadNotActive
+	}
+	if offset != upload.Offset {
+		return nil, ErrUploadOffsetMismatch
+	}
+
+	// Chunks for the same offset may race; each is stored under its own key
+	// and only the one that advances the upload is kept
+	key := fmt.Sprintf("uploads/%s/%020d-%s", upload.ID, offset, randomSuffix())
+	limit := upload.Size - offset
+	if limit > uc.opts.ChunkSize {
+		limit = uc.opts.ChunkSize
+	}
+
+	hash := sha256.New()
+	n, err := uc.store.Put(ctx, key, io.TeeReader(io.LimitReader(r, limit+1), hash))
+	if err != nil {
+		uc.discardChunk(ctx, key)
+		return nil, fmt.Errorf("failed to store chunk: %w", err)
+	}
+
+	var reject error
+	switch {
+	case n > limit && limit == uc.opts.ChunkSize:
+		reject = ErrChunkTooLarge
+	case n > limit:
+		reject = ErrUploadTooLarge
+	case digest != nil && !bytes.Equal(hash.Sum(nil), digest):
+		reject = ErrChunkChecksumMismatch
+	case n == 0:
+		uc.discardChunk(ctx, key)
+		return upload, nil
+	}
+	if reject != nil {
+		uc.discardChunk(ctx, key)
+		return nil, reject
+	}
+
+	upload.Append(key, n)
+	if err := uc.uploadRepo.Advance(ctx, upload, offset); err != nil {
+		uc.discardChunk(ctx, key)
+		if errors.Is(err, repositories.ErrUploadConflict) {
+			return nil, ErrUploadOffsetMismatch
+		}
+		return nil, fmt.Errorf("failed to record chunk: %w", err)
+	}
+	return upload, nil
+}
+
+// CompleteUpload queues the import of a fully received upload. Completing
+// an upload again returns its import.
+func (uc *ImportUseCase) CompleteUpload(ctx context.Context, id string) (*Import, error) {
+	upload, err := uc.GetUpload(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status == entities.UploadCompleted {
+		return uc.GetImport(ctx, upload.JobID)
+	}
+	if upload.Status != entities.UploadActive {
+		return nil, ErrUploadNotActive
+	}
+	if !upload.IsComplete() {
+		return nil, ErrUploadIncomplete
+	}
+
+	job := entities.NewJob(JobTypeUserImport, upload.CreatedBy, map[string]string{"upload_id": upload.ID})
+	if err := uc.jobRepo.Create(ctx, job); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to create import job")
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	upload.Complete(job.ID)
+	if err := uc.uploadRepo.Advance(ctx, upload, upload.Offset); err != nil {
+		if errors.Is(err, repositories.ErrUploadConflict) {
+			// Another request completed or aborted the upload first
+			uc.failJob(ctx, job, "upload was completed concurrently")
+			return uc.CompleteUpload(ctx, id)
+		}
+		uc.failJob(ctx, job, "upload could not be completed")
+		return nil, fmt.Errorf("failed to complete upload: %w", err)
+	}
+
+	if err := uc.queue.Enqueue(ctx, job); err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"job_id": job.ID,
+			"error":  err.Error(),
+		}).Error("Failed to enqueue import job")
+
+		// Reopen the upload so the client can complete it again
+		uc.failJob(ctx, job, "could not be queued")
+		upload.Status = entities.UploadActive
+		upload.JobID = ""
+		if updateErr := uc.uploadRepo.Update(ctx, upload); updateErr != nil {
+			uc.logger.WithField("error", updateErr.Error()).Error("Failed to reopen upload")
+		}
+		return nil, fmt.Errorf("failed to enqueue import job: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"job_id":    job.ID,
+		"upload_id": upload.ID,
+	}).Info("User import queued")
+	return describeImport(job), nil
+}
+
+// AbortUpload discards an unfinished upload and its chunks
+func (uc *ImportUseCase) AbortUpload(ctx context.Context, id string) error {
+	upload, err := uc.GetUpload(ctx, id)
+	if err != nil {
+		return err
+	}
+	if upload.Status != entities.UploadActive {
+		return ErrUploadNotActive
+	}
+	return uc.discard(ctx, upload, entities.UploadAborted)
+}
+
+// GetImport retrieves an import job and its progress
+func (uc *ImportUseCase) GetImport(ctx context.Context, id string) (*Import, error) {
+	job, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import: %w", err)
+	}
+	if job.Type != JobTypeUserImport {
+		return nil, repositories.ErrJobNotFound
+	}
+	return describeImport(job), nil
+}
+
+// RunImport assembles the chunks of an upload, verifies its checksum and
+// creates a user per CSV row. It is called by the job workers. Progress is
+// checkpointed on the job, so an attempt that fails resumes where the
+// previous one stopped when the job is retried.
+func (uc *ImportUseCase) RunImport(ctx context.Context, id string) error {
+	job, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get import job: %w", err)
+	}
+	if job.IsFinished() {
+		return nil
+	}
+
+	upload, err := uc.uploadRepo.GetByID(ctx, job.Params["upload_id"])
+	if err != nil {
+		return fmt.Errorf("failed to get import upload: %w", err)
+	}
+
+	job.Start(time.Now())
+	if job.Result == nil {
+		job.Result = map[string]string{}
+	}
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to start import job: %w", err)
+	}
+
+	key := "imports/" + upload.ID + ".csv"
+	if job.Result["assembled"] != "true" {
+		checksum, err := uc.assemble(ctx, upload, key)
+		if err != nil {
+			return fmt.Errorf("failed to assemble upload: %w", err)
+		}
+		if checksum != upload.Checksum {
+			uc.discardChunk(ctx, key)
+			uc.failJob(ctx, job, "checksum does not match the uploaded file")
+			return nil
+		}
+
+		job.Result["assembled"] = "true"
+		if err := uc.jobRepo.Update(ctx, job); err != nil {
+			return fmt.Errorf("failed to checkpoint import job: %w", err)
+		}
+		uc.deleteChunks(ctx, upload)
+	}
+
+	progress := describeImport(job)
+	if err := uc.importRows(ctx, job, key, progress); err != nil {
+		var rejected *csvRejection
+		if errors.As(err, &rejected) {
+			uc.discardChunk(ctx, key)
+			uc.failJob(ctx, job, rejected.reason)
+			return nil
+		}
+
+		uc.logger.WithFields(map[string]interface{}{
+			"job_id": job.ID,
+			"rows":   progress.Rows,
+			"error":  err.Error(),
+		}).Error("User import attempt failed")
+		return fmt.Errorf("failed to import users: %w", err)
+	}
+
+	uc.discardChunk(ctx, key)
+	// Imports leave no artifact behind to retain
+	now := time.Now()
+	job.Succeed(progress.result(), now, now)
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to complete import job: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"job_id":  job.ID,
+		"created": progress.Created,
+		"skipped": progress.Skipped,
+		"failed":  progress.Failed,
+	}).Info("User import completed")
+	return nil
+}
+
+// CleanupExpired discards uploads left unfinished past their expiry before
+// now and returns how many were removed
+func (uc *ImportUseCase) CleanupExpired(ctx context.Context, now time.Time) (int, error) {
+	removed := 0
+	for {
+		expired, err := uc.uploadRepo.ListExpired(ctx, now, cleanupBatchSize)
+		if err != nil {
+			return removed, fmt.Errorf("failed to list expired uploads: %w", err)
+		}
+
+		failed := 0
+		for _, upload := range expired {
+			if err := uc.discard(ctx, upload, entities.UploadExpired); err != nil {
+				uc.logger.WithFields(map[string]interface{}{
+					"upload_id": upload.ID,
+					"error":     err.Error(),
+				}).Error("Failed to remove expired upload")
+				failed++
+				continue
+			}
+			removed++
+		}
+
+		if len(expired) < cleanupBatchSize || failed == len(expired) {
+			return removed, nil
+		}
+	}
+}
+
+// assemble concatenates the chunks of upload into key and returns the hex
+// SHA-256 of the result
+func (uc *ImportUseCase) assemble(ctx context.Context, upload *entities.Upload, key string) (string, error) {
+	hash := sha256.New()
+	chunks := &chunkReader{ctx: ctx, store: uc.store, keys: upload.Chunks}
+	defer chunks.Close()
+
+	size, err := uc.store.Put(ctx, key, io.TeeReader(chunks, hash))
+	if err != nil {
+		return "", err
+	}
+	if size != upload.Size {
+		return "", fmt.Errorf("assembled %d of %d bytes", size, upload.Size)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// importRows creates a user for every row of the CSV file under key that
+// was not processed by a previous attempt
+func (uc *ImportUseCase) importRows(ctx context.Context, job *entities.Job, key string, progress *Import) error {
+	f, err := uc.store.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cr := csv.NewReader(f)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return &csvRejection{reason: "file has no header row"}
+	}
+	emailCol, nameCol := -1, -1
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))) {
+		case "email":
+			emailCol = i
+		case "name":
+			nameCol = i
+		}
+	}
+	if emailCol < 0 || nameCol < 0 {
+		return &csvRejection{reason: "header must contain email and name columns"}
+	}
+
+	var row int64
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		row++
+		if err != nil {
+			return &csvRejection{reason: fmt.Sprintf("row %d is not valid CSV", row)}
+		}
+		if row <= progress.Rows {
+			continue
+		}
+
+		var email, name string
+		if emailCol < len(record) {
+			email = strings.TrimSpace(record[emailCol])
+		}
+		if nameCol < len(record) {
+			name = strings.TrimSpace(record[nameCol])
+		}
+
+		_, err = uc.users.CreateUser(ctx, email, name)
+		switch {
+		case err == nil:
+			progress.Created++
+		case errors.Is(err, repositories.ErrUserAlreadyExists):
+			progress.Skipped++
+		case errors.Is(err, ErrEmailRequired), errors.Is(err, ErrNameRequired), errors.Is(err, ErrQuotaExceeded):
+			progress.Failed++
+			if len(progress.Errors) < maxImportErrors {
+				progress.Errors = append(progress.Errors, ImportError{Row: row, Error: err.Error()})
+			}
+		default:
+			uc.checkpoint(ctx, job, progress)
+			return err
+		}
+		progress.Rows = row
+
+		if row%importCheckpointRows == 0 {
+			if err := uc.checkpoint(ctx, job, progress); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkpoint records the progress of an import on its job
+func (uc *ImportUseCase) checkpoint(ctx context.Context, job *entities.Job, progress *Import) error {
+	job.Result = progress.result()
+	job.Result["assembled"] = "true"
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to checkpoint import job: %w", err)
+	}
+	return nil
+}
+
+// discard marks an upload as aborted or expired after deleting its chunks
+func (uc *ImportUseCase) discard(ctx context.Context, upload *entities.Upload, status string) error {
+	for _, key := range upload.Chunks {
+		if err := uc.store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	upload.Discard(status)
+	return uc.uploadRepo.Update(ctx, upload)
+}
+
+// deleteChunks removes the chunks of an assembled upload. Failures only
+// leave garbage behind, so they are logged rather than retried.
+func (uc *ImportUseCase) deleteChunks(ctx context.Context, upload *entities.Upload) {
+	for _, key := range upload.Chunks {
+		uc.discardChunk(ctx, key)
+	}
+	upload.Chunks = nil
+	if err := uc.uploadRepo.Update(ctx, upload); err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"upload_id": upload.ID,
+			"error":     err.Error(),
+		}).Warn("Failed to forget assembled chunks")
+	}
+}
+
+// discardChunk deletes a stored object that is no longer needed
+func (uc *ImportUseCase) discardChunk(ctx context.Context, key string) {
+	if err := uc.store.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		uc.logger.WithFields(map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		}).Warn("Failed to delete upload object")
+	}
+}
+
+// failJob records that a job failed permanently
+func (uc *ImportUseCase) failJob(ctx context.Context, job *entities.Job, reason string) {
+	job.Fail(reason, time.Now())
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to record import job failure")
+	}
+}
+
+// describeImport describes a job as an import
+func describeImport(job *entities.Job) *Import {
+	result := &Import{Job: job, UploadID: job.Params["upload_id"]}
+	result.Rows, _ = strconv.ParseInt(job.Result["rows"], 10, 64)
+	result.Created, _ = strconv.ParseInt(job.Result["created"], 10, 64)
+	result.Skipped, _ = strconv.ParseInt(job.Result["skipped"], 10, 64)
+	result.Failed, _ = strconv.ParseInt(job.Result["failed"], 10, 64)
+	if raw := job.Result["errors"]; raw != "" {
+		_ = json.Unmarshal([]byte(raw), &result.Errors)
+	}
+	return result
+}
+
+// result encodes the progress of an import as job results
+func (i *Import) result() map[string]string {
+	result := map[string]string{
+		"rows":    strconv.FormatInt(i.Rows, 10),
+		"created": strconv.FormatInt(i.Created, 10),
+		"skipped": strconv.FormatInt(i.Skipped, 10),
+		"failed":  strconv.FormatInt(i.Failed, 10),
+	}
+	if len(i.Errors) > 0 {
+		raw, _ := json.Marshal(i.Errors)
+		result["errors"] = string(raw)
+	}
+	return result
+}
+
+// csvRejection is returned for files that can never be imported, so the job
+// fails instead of being retried
+type csvRejection struct {
+	reason string
+}
+
+func (e *csvRejection) Error() string {
+	return e.reason
+}
+
+// chunkReader reads stored chunks one after another, opening each only when
+// the previous one is exhausted
+type chunkReader struct {
+	ctx     context.Context
+	store   storage.Storage
+	keys    []string
+	current io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.keys) == 0 {
+				return 0, io.EOF
+			}
+			f, err := r.store.Open(r.ctx, r.keys[0])
+			if err != nil {
+				return 0, err
+			}
+			r.current, r.keys = f, r.keys[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if errors.Is(err, io.EOF) {
+			r.current.Close()
+			r.current = nil
+			err = nil
+		}
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// Close closes the chunk being read, if any
+func (r *chunkReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
+
+// randomSuffix returns a short random hex string for unique storage keys
+func randomSuffix() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"context"
+	"io"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// ImportUseCaseInterface defines the interface for chunked uploads and user
+// imports
+type ImportUseCaseInterface interface {
+	CreateUpload(ctx context.Context, filename string, size int64, checksum, createdBy string) (*entities.Upload, error)
+	GetUpload(ctx context.Context, id string) (*entities.Upload, error)
+	AppendChunk(ctx context.Context, id string, offset int64, r io.Reader, digest []byte) (*entities.Upload, error)
+	CompleteUpload(ctx context.Context, id string) (*Import, error)
+	AbortUpload(ctx context.Context, id string) error
+	GetImport(ctx context.Context, id string) (*Import, error)
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/storage"
+)
+
+// flakyUserUseCase fails CreateUser once after a number of calls
+type flakyUserUseCase struct {
+	UserUseCaseInterface
+	failAfter int
+	calls     int
+}
+
+func (u *flakyUserUseCase) CreateUser(ctx context.Context, email, name string) (*entities.User, error) {
+	u.calls++
+	if u.calls == u.failAfter+1 {
+		return nil, errors.New("database unavailable")
+	}
+	return u.UserUseCaseInterface.CreateUser(ctx, email, name)
+}
+
+type importFixture struct {
+	userRepo   repositories.UserRepository
+	uploadRepo repositories.UploadRepository
+	jobRepo    repositories.JobRepository
+	queue      *recordingJobQueue
+	users      *flakyUserUseCase
+	useCase    *ImportUseCase
+}
+
+func newImportFixture(t *testing.T) *importFixture {
+	t.Helper()
+	store, err := storage.NewLocal(t.TempDir(), "http://localhost:8080/api/v1/downloads", []byte("secret"))
+	if err != nil {
+		t.Fatalf("create storage: %v", err)
+	}
+
+	f := &importFixture{
+		userRepo:   database.NewMockUserRepository(),
+		uploadRepo: database.NewMockUploadRepository(),
+		jobRepo:    database.NewMockJobRepository(),
+		queue:      &recordingJobQueue{},
+	}
+	f.users = &flakyUserUseCase{UserUseCaseInterface: NewUserUseCase(f.userRepo, nil, logger.New()), failAfter: -1}
+	f.useCase = NewImportUseCase(f.users, f.uploadRepo, f.jobRepo, f.queue, store, ImportOptions{
+		MaxSize:   1024,
+		ChunkSize: 16,
+		UploadTTL: time.Hour,
+	}, logger.New())
+	return f
+}
+
+func checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// upload sends data in chunks of at most 16 bytes and completes the upload
+func (f *importFixture) upload(t *testing.T, data, sum string) *Import {
+	t.Helper()
+	ctx := context.Background()
+
+	upload, err := f.useCase.CreateUpload(ctx, "users.csv", int64(len(data)), sum, "user_1")
+	if err != nil {
+		t.Fatalf("CreateUpload() unexpected error: %v", err)
+	}
+	for offset := 0; offset < len(data); offset += 16 {
+		end := offset + 16
+		if end > len(data) {
+			end = len(data)
+		}
+		chunk := data[offset:end]
+		digest := sha256.Sum256([]byte(chunk))
+		if _, err := f.useCase.AppendChunk(ctx, upload.ID, int64(offset), strings.NewReader(chunk), digest[:]); err != nil {
+			t.Fatalf("AppendChunk(%d) unexpected error: %v", offset, err)
+		}
+	}
+
+	imp, err := f.useCase.CompleteUpload(ctx, upload.ID)
+	if err != nil {
+		t.Fatalf("CompleteUpload() unexpected error: %v", err)
+	}
+	return imp
+}
+
+const importCSV = "Email,Name\n" +
+	"ada@example.com,Ada\n" +
+	"grace@example.com,Grace\n" +
+	"ada@example.com,Ada again\n" +
+	",Nobody\n" +
+	"linus@example.com,Linus\n"
+
+func TestImportUseCase_RunImport(t *testing.T) {
+	f := newImportFixture(t)
+	ctx := context.Background()
+
+	imp := f.upload(t, importCSV, checksum(importCSV))
+	if imp.Job.Status != entities.JobQueued || imp.UploadID == "" {
+		t.Fatalf("CompleteUpload() import = %+v, want queued", imp)
+	}
+	if len(f.queue.jobs) != 1 || f.queue.jobs[0] != imp.Job.ID {
+		t.Errorf("enqueued %v, want [%s]", f.queue.jobs, imp.Job.ID)
+	}
+
+	if err := f.useCase.RunImport(ctx, imp.Job.ID); err != nil {
+		t.Fatalf("RunImport() unexpected error: %v", err)
+	}
+
+	got, err := f.useCase.GetImport(ctx, imp.Job.ID)
+	if err != nil {
+		t.Fatalf("GetImport() unexpected error: %v", err)
+	}
+	if got.Job.Status != entities.JobSucceeded {
+		t.Fatalf("job status = %s (%s), want succeeded", got.Job.Status, got.Job.Error)
+	}
+	if got.Rows != 5 || got.Created != 3 || got.Skipped != 1 || got.Failed != 1 {
+		t.Errorf("import = rows %d created %d skipped %d failed %d, want 5/3/1/1", got.Rows, got.Created, got.Skipped, got.Failed)
+	}
+	if len(got.Errors) != 1 || got.Errors[0].Row != 4 || got.Errors[0].Error != ErrEmailRequired.Error() {
+		t.Errorf("errors = %+v, want row 4 email is required", got.Errors)
+	}
+
+	upload, _ := f.uploadRepo.GetByID(ctx, imp.UploadID)
+	if upload.Status != entities.UploadCompleted || len(upload.Chunks) != 0 {
+		t.Errorf("upload = %s with %d chunks, want completed without chunks", upload.Status, len(upload.Chunks))
+	}
+
+	// Completing again returns the same import
+	again, err := f.useCase.CompleteUpload(ctx, imp.UploadID)
+	if err != nil || again.Job.ID != imp.Job.ID {
+		t.Errorf("CompleteUpload() again = %v, %v, want job %s", again, err, imp.Job.ID)
+	}
+}
+
+func TestImportUseCase_RunImport_ResumesAfterFailure(t *testing.T) {
+	f := newImportFixture(t)
+	ctx := context.Background()
+	f.users.failAfter = 2
+
+	imp := f.upload(t, importCSV, checksum(importCSV))
+	if err := f.useCase.RunImport(ctx, imp.Job.ID); err == nil {
+		t.Fatal("RunImport() expected error on first attempt")
+	}
+
+	interrupted, _ := f.useCase.GetImport(ctx, imp.Job.ID)
+	if interrupted.Rows != 2 || interrupted.Job.Status != entities.JobRunning {
+		t.Fatalf("after failure rows = %d status = %s, want 2 running", interrupted.Rows, interrupted.Job.Status)
+	}
+
+	if err := f.useCase.RunImport(ctx, imp.Job.ID); err != nil {
+		t.Fatalf("RunImport() retry unexpected error: %v", err)
+	}
+	got, _ := f.useCase.GetImport(ctx, imp.Job.ID)
+	if got.Job.Status != entities.JobSucceeded || got.Created != 3 || got.Skipped != 1 || got.Job.Attempts != 2 {
+		t.Errorf("after retry = %s created %d skipped %d attempts %d, want succeeded 3/1 in 2 attempts",
+			got.Job.Status, got.Created, got.Skipped, got.Job.Attempts)
+	}
+}
+
+func TestImportUseCase_RunImport_ChecksumMismatch(t *testing.T) {
+	f := newImportFixture(t)
+	ctx := context.Background()
+
+	imp := f.upload(t, importCSV, checksum("something else"))
+	if err := f.useCase.RunImport(ctx, imp.Job.ID); err != nil {
+		t.Fatalf("RunImport() unexpected error: %v", err)
+	}
+
+	got, _ := f.useCase.GetImport(ctx, imp.Job.ID)
+	if got.Job.Status != entities.JobFailed || got.Created != 0 {
+		t.Errorf("import = %s created %d, want failed without users", got.Job.Status, got.Created)
+	}
+	if users, _ := f.userRepo.List(ctx, 10, 0); len(users) != 0 {
+		t.Errorf("created %d users, want none", len(users))
+	}
+}
+
+func TestImportUseCase_RunImport_MissingColumns(t *testing.T) {
+	f := newImportFixture(t)
+	data := "id,email\n1,ada@example.com\n"
+
+	imp := f.upload(t, data, checksum(data))
+	if err := f.useCase.RunImport(context.Background(), imp.Job.ID); err != nil {
+		t.Fatalf("RunImport() unexpected error: %v", err)
+	}
+
+	got, _ := f.useCase.GetImport(context.Background(), imp.Job.ID)
+	if got.Job.Status != entities.JobFailed || got.Job.Error != "header must contain email and name columns" {
+		t.Errorf("import = %s (%s), want failed on header", got.Job.Status, got.Job.Error)
+	}
+}
+
+func TestImportUseCase_AppendChunk_Rejections(t *testing.T) {
+	f := newImportFixture(t)
+	ctx := context.Background()
+
+	upload, err := f.useCase.CreateUpload(ctx, "users.csv", 20, checksum(strings.Repeat("x", 20)), "user_1")
+	if err != nil {
+		t.Fatalf("CreateUpload() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		offset int64
+		chunk  string
+		digest []byte
+		want   error
+	}{
+		{"wrong offset", 4, "xxxx", nil, ErrUploadOffsetMismatch},
+		{"chunk too large", 0, strings.Repeat("x", 17), nil, ErrChunkTooLarge},
+		{"checksum mismatch", 0, "xxxx", []byte("not the digest"), ErrChunkChecksumMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.useCase.AppendChunk(ctx, upload.ID, tt.offset, strings.NewReader(tt.chunk), tt.digest)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("AppendChunk() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := f.useCase.AppendChunk(ctx, upload.ID, 0, strings.NewReader(strings.Repeat("x", 16)), nil); err != nil {
+		t.Fatalf("AppendChunk() unexpected error: %v", err)
+	}
+	if _, err := f.useCase.AppendChunk(ctx, upload.ID, 16, strings.NewReader("xxxxxx"), nil); !errors.Is(err, ErrUploadTooLarge) {
+		t.Errorf("AppendChunk() past the end error = %v, want %v", err, ErrUploadTooLarge)
+	}
+	if _, err := f.useCase.CompleteUpload(ctx, upload.ID); !errors.Is(err, ErrUploadIncomplete) {
+		t.Errorf("CompleteUpload() error = %v, want %v", err, ErrUploadIncomplete)
+	}
+
+	got, _ := f.useCase.GetUpload(ctx, upload.ID)
+	if got.Offset != 16 || len(got.Chunks) != 1 {
+		t.Errorf("upload offset = %d with %d chunks, want 16 with 1", got.Offset, len(got.Chunks))
+	}
+}
+
+func TestImportUseCase_CreateUpload_Validation(t *testing.T) {
+	f := newImportFixture(t)
+
+	if _, err := f.useCase.CreateUpload(context.Background(), "users.csv", 10, "abc", "user_1"); !errors.Is(err, ErrInvalidChecksum) {
+		t.Errorf("CreateUpload() error = %v, want %v", err, ErrInvalidChecksum)
+	}
+	if _, err := f.useCase.CreateUpload(context.Background(), "users.csv", 2048, checksum(""), "user_1"); !errors.Is(err, ErrUploadTooLarge) {
+		t.Errorf("CreateUpload() error = %v, want %v", err, ErrUploadTooLarge)
+	}
+}
+
+func TestImportUseCase_CleanupExpired(t *testing.T) {
+	f := newImportFixture(t)
+	ctx := context.Background()
+
+	upload, _ := f.useCase.CreateUpload(ctx, "users.csv", 20, checksum(strings.Repeat("x", 20)), "user_1")
+	if _, err := f.useCase.AppendChunk(ctx, upload.ID, 0, strings.NewReader("xxxx"), nil); err != nil {
+		t.Fatalf("AppendChunk() unexpected error: %v", err)
+	}
+
+	removed, err := f.useCase.CleanupExpired(ctx, time.Now().Add(2*time.Hour))
+	if err != nil || removed != 1 {
+		t.Fatalf("CleanupExpired() = %d, %v, want 1", removed, err)
+	}
+
+	got, _ := f.useCase.GetUpload(ctx, upload.ID)
+	if got.Status != entities.UploadExpired || len(got.Chunks) != 0 {
+		t.Errorf("upload = %s with %d chunks, want expired without chunks", got.Status, len(got.Chunks))
+	}
+	if _, err := f.useCase.AppendChunk(ctx, upload.ID, 4, strings.NewReader("xxxx"), nil); !errors.Is(err, ErrUploadNotActive) {
+		t.Errorf("AppendChunk() after expiry error = %v, want %v", err, ErrUploadNotActive)
+	}
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// JobUseCase reports on background jobs to the callers who requested them
+type JobUseCase struct {
+	jobRepo repositories.JobRepository
+	logger  logger.Logger
+}
+
+// NewJobUseCase creates a new job use case instance
+func NewJobUseCase(jobRepo repositories.JobRepository, logger logger.Logger) *JobUseCase {
+	return &JobUseCase{
+		jobRepo: jobRepo,
+		logger:  logger,
+	}
+}
+
+// GetJob retrieves a job requested by requestedBy. Jobs others requested
+// are reported as not found, so their IDs cannot be probed.
+func (uc *JobUseCase) GetJob(ctx context.Context, id, requestedBy string) (*entities.Job, error) {
+	job, err := uc.jobRepo.GetByID(ctx, id)
+	if errors.Is(err, repositories.ErrJobNotFound) {
+		return nil, err
+	}
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to get job")
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	if job.RequestedBy != requestedBy {
+		return nil, repositories.ErrJobNotFound
+	}
+	return job, nil
+}
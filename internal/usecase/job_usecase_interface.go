@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// JobUseCaseInterface defines the interface for reporting on background jobs
+type JobUseCaseInterface interface {
+	GetJob(ctx context.Context, id, requestedBy string) (*entities.Job, error)
+}
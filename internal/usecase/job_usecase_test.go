@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+func TestJobUseCase_GetJob(t *testing.T) {
+	jobRepo := database.NewMockJobRepository()
+	useCase := NewJobUseCase(jobRepo, logger.New())
+	ctx := context.Background()
+
+	job := entities.NewJob(JobTypeUserCreate, "user_1", nil)
+	if err := jobRepo.Create(ctx, job); err != nil {
+		t.Fatalf("seed job: %v", err)
+	}
+
+	got, err := useCase.GetJob(ctx, job.ID, "user_1")
+	if err != nil {
+		t.Fatalf("GetJob() unexpected error: %v", err)
+	}
+	if got.ID != job.ID {
+		t.Errorf("GetJob() = %s, want %s", got.ID, job.ID)
+	}
+
+	if _, err := useCase.GetJob(ctx, job.ID, "user_2"); !errors.Is(err, repositories.ErrJobNotFound) {
+		t.Errorf("GetJob() of another caller's job error = %v, want ErrJobNotFound", err)
+	}
+	if _, err := useCase.GetJob(ctx, "missing", "user_1"); !errors.Is(err, repositories.ErrJobNotFound) {
+		t.Errorf("GetJob() of a missing job error = %v, want ErrJobNotFound", err)
+	}
+}
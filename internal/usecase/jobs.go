@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// JobQueue hands persisted jobs to background workers
+type JobQueue interface {
+	Enqueue(ctx context.Context, job *entities.Job) error
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/pkg/logger"
+)
+
+// Key prefixes of the usernames and client addresses failures are counted
+// for
+const (
+	lockoutUserPrefix = "user:"
+	lockoutIPPrefix   = "ip:"
+)
+
+// LockoutPolicy is when failed logins lock a username or client address
+type LockoutPolicy struct {
+	// MaxAttempts is the failures of a username within Window that lock
+	// it; 0 never locks usernames
+	MaxAttempts int
+	// MaxAttemptsPerIP is the failures from a client address within
+	// Window that lock it, whichever usernames they tried; 0 never locks
+	// addresses
+	MaxAttemptsPerIP int
+	Window           time.Duration
+	// Duration is how long a lock lasts
+	Duration time.Duration
+}
+
+// LockoutError is returned for logins refused while their username or
+// client address is locked
+type LockoutError struct {
+	// Until is when the lock ends
+	Until time.Time
+}
+
+func (e *LockoutError) Error() string {
+	return ErrLoginLocked.Error()
+}
+
+// Unwrap lets errors.Is match ErrLoginLocked
+func (e *LockoutError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// RetryAfter returns how long until the lock ends
+func (e *LockoutError) RetryAfter() time.Duration {
+	return time.Until(e.Until)
+}
+
+// LockoutUseCase locks usernames and client addresses after too many
+// failed logins, so passwords and one-time codes cannot be guessed
+type LockoutUseCase struct {
+	store  auth.AttemptStore
+	policy LockoutPolicy
+	logger logger.Logger
+	now    func() time.Time
+}
+
+// NewLockoutUseCase creates a new lockout use case instance counting
+// failures in store
+func NewLockoutUseCase(store auth.AttemptStore, policy LockoutPolicy, logger logger.Logger) *LockoutUseCase {
+	return &LockoutUseCase{
+		store:  store,
+		policy: policy,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Unlock lifts the lock of username and forgets its failures
+func (uc *LockoutUseCase) Unlock(ctx context.Context, username string) error {
+	if err := uc.store.Reset(ctx, userLockoutKey(username)); err != nil {
+		return err
+	}
+	uc.logger.WithField("username", normalizeUsername(username)).Info("Login lockout lifted")
+	return nil
+}
+
+// UnlockIP lifts the lock of a client address and forgets its failures
+func (uc *LockoutUseCase) UnlockIP(ctx context.Context, ip string) error {
+	if err := uc.store.Reset(ctx, lockoutIPPrefix+ip); err != nil {
+		return err
+	}
+	uc.logger.WithField("ip", ip).Info("Login lockout lifted")
+	return nil
+}
+
+// check refuses logins of creds whose username or client address is
+// locked. The store failing does not refuse logins, so an outage of Redis
+// does not lock everybody out.
+func (uc *LockoutUseCase) check(ctx context.Context, creds Credentials) error {
+	var until time.Time
+	for _, key := range uc.keys(creds) {
+		locked, err := uc.store.LockedUntil(ctx, key.key)
+		if err != nil {
+			uc.logger.WithField("error", err.Error()).Error("Failed to check login lockout")
+			continue
+		}
+		if locked.After(until) {
+			until = locked
+		}
+	}
+	if uc.now().Before(until) {
+		uc.logger.WithField("until", until).Info("Login refused while locked")
+		return &LockoutError{Until: until}
+	}
+	return nil
+}
+
+// failed counts a failed login of creds, locking its username or client
+// address once they failed too often
+func (uc *LockoutUseCase) failed(ctx context.Context, creds Credentials) {
+	for _, key := range uc.keys(creds) {
+		failures, err := uc.store.Fail(ctx, key.key, uc.policy.Window)
+		if err != nil {
+			uc.logger.WithField("error", err.Error()).Error("Failed to count failed login")
+			continue
+		}
+		if failures < key.limit {
+			continue
+		}
+
+		until := uc.now().Add(uc.policy.Duration)
+		if err := uc.store.Lock(ctx, key.key, until); err != nil {
+			uc.logger.WithField("error", err.Error()).Error("Failed to lock login")
+			continue
+		}
+		uc.logger.WithFields(map[string]interface{}{
+			"key":      key.key,
+			"failures": failures,
+			"until":    until,
+		}).Warn("Login locked after too many failures")
+	}
+}
+
+// succeeded forgets the failures of the username of creds. Failures of
+// the client address are kept, so logging in to an account of one's own
+// does not reset guessing at others.
+func (uc *LockoutUseCase) succeeded(ctx context.Context, creds Credentials) {
+	if uc.policy.MaxAttempts == 0 {
+		return
+	}
+	if err := uc.store.Reset(ctx, userLockoutKey(creds.Username)); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to reset failed logins")
+	}
+}
+
+// lockoutKey is a key failures are counted under and the failures that
+// lock it
+type lockoutKey struct {
+	key   string
+	limit int
+}
+
+// keys returns the keys failures of creds are counted under
+func (uc *LockoutUseCase) keys(creds Credentials) []lockoutKey {
+	var keys []lockoutKey
+	if uc.policy.MaxAttempts > 0 && normalizeUsername(creds.Username) != "" {
+		keys = append(keys, lockoutKey{key: userLockoutKey(creds.Username), limit: uc.policy.MaxAttempts})
+	}
+	if uc.policy.MaxAttemptsPerIP > 0 && creds.IP != "" {
+		keys = append(keys, lockoutKey{key: lockoutIPPrefix + creds.IP, limit: uc.policy.MaxAttemptsPerIP})
+	}
+	return keys
+}
+
+// countsAsFailure reports whether a login refused with err was a guess,
+// as opposed to the right password without a one-time code or an outage
+func countsAsFailure(err error) bool {
+	return errors.Is(err, auth.ErrInvalidCredentials) || errors.Is(err, ErrInvalidMFACode)
+}
+
+// userLockoutKey returns the key the failures of username are counted
+// under
+func userLockoutKey(username string) string {
+	return lockoutUserPrefix + normalizeUsername(username)
+}
+
+// normalizeUsername folds the spellings of a username that log in as the
+// same user
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
@@ -0,0 +1,9 @@
+package usecase
+
+import "context"
+
+// LockoutUseCaseInterface defines the interface for lifting login lockouts
+type LockoutUseCaseInterface interface {
+	Unlock(ctx context.Context, username string) error
+	UnlockIP(ctx context.Context, ip string) error
+}
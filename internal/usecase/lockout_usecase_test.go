@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/auth"
+	authinfra "clean-architecture/internal/infrastructure/auth"
+	"clean-architecture/pkg/logger"
+)
+
+// failingAttemptStore fails every operation, like Redis during an outage
+type failingAttemptStore struct{}
+
+func (failingAttemptStore) Fail(ctx context.Context, key string, window time.Duration) (int, error) {
+	return 0, errors.New("connection refused")
+}
+
+func (failingAttemptStore) Lock(ctx context.Context, key string, until time.Time) error {
+	return errors.New("connection refused")
+}
+
+func (failingAttemptStore) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	return time.Time{}, errors.New("connection refused")
+}
+
+func (failingAttemptStore) Reset(ctx context.Context, key string) error {
+	return errors.New("connection refused")
+}
+
+func newTestLockoutAuthUseCase(store auth.AttemptStore, policy LockoutPolicy) (*AuthUseCase, *LockoutUseCase) {
+	provider := &stubProvider{identities: map[string]*auth.Identity{
+		"bjensen": {Provider: "stub", Username: "bjensen", Email: "bjensen@example.com", Roles: []string{"user"}},
+		"admin":   {Provider: "stub", Username: "admin", Email: "admin@example.com", Roles: []string{"admin"}},
+	}}
+	uc, _, _ := newTestAuthUseCase(provider)
+	lockout := NewLockoutUseCase(store, policy, logger.New())
+	uc.WithLockout(lockout)
+	return uc, lockout
+}
+
+func TestAuthUseCase_Login_LocksUsername(t *testing.T) {
+	ctx := context.Background()
+	uc, lockout := newTestLockoutAuthUseCase(authinfra.NewMemoryAttemptStore(), LockoutPolicy{
+		MaxAttempts: 3,
+		Window:      time.Minute,
+		Duration:    15 * time.Minute,
+	})
+
+	// A success in between forgets the failures
+	uc.Login(ctx, Credentials{Username: "bjensen", Password: "wrong"})
+	uc.Login(ctx, Credentials{Username: "bjensen", Password: "wrong"})
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2"}); err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := uc.Login(ctx, Credentials{Username: "BJensen ", Password: "wrong"}); !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Fatalf("Login() failure %d error = %v, want %v", i+1, err, auth.ErrInvalidCredentials)
+		}
+	}
+
+	_, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2"})
+	var lockoutErr *LockoutError
+	if !errors.As(err, &lockoutErr) || !errors.Is(err, ErrLoginLocked) {
+		t.Fatalf("Login() while locked error = %v, want a LockoutError", err)
+	}
+	if until := time.Until(lockoutErr.Until); until < 14*time.Minute || until > 15*time.Minute {
+		t.Errorf("Login() lock ends in %v, want 15m", until)
+	}
+	if _, err := uc.Login(ctx, Credentials{Username: "admin", Password: "hunter2"}); err != nil {
+		t.Errorf("Login() of another username unexpected error: %v", err)
+	}
+
+	if err := lockout.Unlock(ctx, "bjensen"); err != nil {
+		t.Fatalf("Unlock() unexpected error: %v", err)
+	}
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2"}); err != nil {
+		t.Errorf("Login() after Unlock() unexpected error: %v", err)
+	}
+}
+
+func TestAuthUseCase_Login_LocksIP(t *testing.T) {
+	ctx := context.Background()
+	uc, lockout := newTestLockoutAuthUseCase(authinfra.NewMemoryAttemptStore(), LockoutPolicy{
+		MaxAttempts:      10,
+		MaxAttemptsPerIP: 2,
+		Window:           time.Minute,
+		Duration:         time.Minute,
+	})
+
+	uc.Login(ctx, Credentials{Username: "bjensen", Password: "wrong", IP: "192.0.2.1"})
+	if _, err := uc.Login(ctx, Credentials{Username: "admin", Password: "hunter2", IP: "192.0.2.1"}); err != nil {
+		t.Fatalf("Login() unexpected error: %v", err)
+	}
+	uc.Login(ctx, Credentials{Username: "admin", Password: "wrong", IP: "192.0.2.1"})
+
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2", IP: "192.0.2.1"}); !errors.Is(err, ErrLoginLocked) {
+		t.Errorf("Login() from a locked address error = %v, want %v", err, ErrLoginLocked)
+	}
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2", IP: "192.0.2.2"}); err != nil {
+		t.Errorf("Login() from another address unexpected error: %v", err)
+	}
+
+	if err := lockout.UnlockIP(ctx, "192.0.2.1"); err != nil {
+		t.Fatalf("UnlockIP() unexpected error: %v", err)
+	}
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2", IP: "192.0.2.1"}); err != nil {
+		t.Errorf("Login() after UnlockIP() unexpected error: %v", err)
+	}
+}
+
+func TestAuthUseCase_Login_LockoutStoreDown(t *testing.T) {
+	uc, _ := newTestLockoutAuthUseCase(failingAttemptStore{}, LockoutPolicy{
+		MaxAttempts: 1,
+		Window:      time.Minute,
+		Duration:    time.Minute,
+	})
+
+	ctx := context.Background()
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "wrong"}); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("Login() error = %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2"}); err != nil {
+		t.Errorf("Login() should be allowed while the store is down, got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/totp"
+)
+
+// mfaSkew is the number of TOTP steps before and after the current one
+// whose codes are accepted, allowing for clocks 30 seconds apart
+const mfaSkew = 1
+
+// MFAStatus is whether a user enabled multi-factor authentication
+type MFAStatus struct {
+	Enabled bool
+	// Pending reports an enrollment that was not confirmed with a code
+	Pending bool
+}
+
+// MFAEnrollment is a new TOTP secret for the user to add to their
+// authenticator app. It is only ever returned when enrolling.
+type MFAEnrollment struct {
+	// Secret is the base32 secret for entering by hand
+	Secret string
+	// URI is the otpauth:// URI for showing as a QR code
+	URI string
+}
+
+// MFAUseCase implements enrolling users in TOTP multi-factor
+// authentication and verifying their codes. Secrets are stored encrypted
+// with the user's ID as additional data, so they cannot be moved between
+// users.
+type MFAUseCase struct {
+	userRepo repositories.UserRepository
+	cipher   auth.SecretCipher
+	issuer   string
+	logger   logger.Logger
+	now      func() time.Time
+}
+
+// NewMFAUseCase creates a new MFA use case instance. issuer names the
+// service in authenticator apps.
+func NewMFAUseCase(userRepo repositories.UserRepository, cipher auth.SecretCipher, issuer string, logger logger.Logger) *MFAUseCase {
+	return &MFAUseCase{
+		userRepo: userRepo,
+		cipher:   cipher,
+		issuer:   issuer,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Status returns whether the user enabled MFA
+func (uc *MFAUseCase) Status(ctx context.Context, userID string) (*MFAStatus, error) {
+	user, err := uc.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &MFAStatus{Enabled: user.MFAEnabled, Pending: user.MFAPending()}, nil
+}
+
+// Enroll generates a new TOTP secret for the user, which takes effect once
+// Enable confirms a code of it. Enrolling again replaces a secret that was
+// not confirmed.
+func (uc *MFAUseCase) Enroll(ctx context.Context, userID string) (*MFAEnrollment, error) {
+	user, err := uc.user(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAEnabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := uc.cipher.Encrypt(secret, []byte(user.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt MFA secret: %w", err)
+	}
+	user.EnrollMFA(encrypted)
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	uc.logger.WithField("user_id", user.ID).Info("User started MFA enrollment")
+	return &MFAEnrollment{
+		Secret: totp.EncodeSecret(secret),
+		URI:    totp.URI(uc.issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable confirms the enrolled secret with one of its codes and requires
+// codes on the user's password logins from then on
+func (uc *MFAUseCase) Enable(ctx context.Context, userID, code string) error {
+	user, err := uc.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.MFAEnabled {
+		return ErrMFAAlreadyEnabled
+	}
+	if user.MFASecret == "" {
+		return ErrMFANotEnrolled
+	}
+	if err := uc.check(ctx, user, code); err != nil {
+		return err
+	}
+
+	user.EnableMFA()
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	uc.logger.WithField("user_id", user.ID).Info("User enabled MFA")
+	return nil
+}
+
+// Disable removes the user's secret after checking one of its codes, so a
+// stolen session alone cannot turn MFA off
+func (uc *MFAUseCase) Disable(ctx context.Context, userID, code string) error {
+	user, err := uc.user(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.MFAEnabled {
+		return ErrMFANotEnabled
+	}
+	if err := uc.check(ctx, user, code); err != nil {
+		return err
+	}
+
+	user.DisableMFA()
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	uc.logger.WithField("user_id", user.ID).Info("User disabled MFA")
+	return nil
+}
+
+// Verify checks the one-time code of a password login by user, who
+// enabled MFA
+func (uc *MFAUseCase) Verify(ctx context.Context, user *entities.User, code string) error {
+	if code == "" {
+		return ErrMFARequired
+	}
+	return uc.check(ctx, user, code)
+}
+
+// check verifies code against the user's secret and records its step, so
+// the code is refused when used again
+func (uc *MFAUseCase) check(ctx context.Context, user *entities.User, code string) error {
+	secret, err := uc.cipher.Decrypt(user.MFASecret, []byte(user.ID))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt MFA secret: %w", err)
+	}
+
+	step, ok := totp.Validate(secret, code, uc.now(), mfaSkew)
+	if !ok {
+		uc.refused(user, "invalid code")
+		return ErrInvalidMFACode
+	}
+	advanced, err := uc.userRepo.AdvanceMFAStep(ctx, user.ID, step)
+	if err != nil {
+		return fmt.Errorf("failed to record MFA code: %w", err)
+	}
+	if !advanced {
+		uc.refused(user, "code already used")
+		return ErrInvalidMFACode
+	}
+	// Keep the step when the user is saved after this
+	user.MFALastStep = step
+	return nil
+}
+
+// refused logs a refused one-time code
+func (uc *MFAUseCase) refused(user *entities.User, reason string) {
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id": user.ID,
+		"reason":  reason,
+	}).Info("One-time code refused")
+}
+
+// user returns the current state of the user with id
+func (uc *MFAUseCase) user(ctx context.Context, id string) (*entities.User, error) {
+	user, err := uc.userRepo.GetByID(consistency.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, repositories.ErrUserNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,12 @@
+package usecase
+
+import "context"
+
+// MFAUseCaseInterface defines the interface for managing the TOTP
+// multi-factor authentication of users
+type MFAUseCaseInterface interface {
+	Status(ctx context.Context, userID string) (*MFAStatus, error)
+	Enroll(ctx context.Context, userID string) (*MFAEnrollment, error)
+	Enable(ctx context.Context, userID, code string) error
+	Disable(ctx context.Context, userID, code string) error
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/auth"
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	authinfra "clean-architecture/internal/infrastructure/auth"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/totp"
+)
+
+// mfaTestClock is a clock the tests move between TOTP steps
+type mfaTestClock struct {
+	now time.Time
+}
+
+func (c *mfaTestClock) Now() time.Time { return c.now }
+
+func newTestMFAUseCase(t *testing.T, userRepo repositories.UserRepository) (*MFAUseCase, *mfaTestClock) {
+	t.Helper()
+	cipher, err := authinfra.NewAESCipher(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewAESCipher() unexpected error: %v", err)
+	}
+	clock := &mfaTestClock{now: time.Unix(1700000000, 0)}
+	uc := NewMFAUseCase(userRepo, cipher, "Example", logger.New())
+	uc.now = clock.Now
+	return uc, clock
+}
+
+// currentCode returns the code of the user's stored secret at the clock
+func currentCode(t *testing.T, uc *MFAUseCase, clock *mfaTestClock, userRepo repositories.UserRepository, userID string) string {
+	t.Helper()
+	user, err := userRepo.GetByID(context.Background(), userID)
+	if err != nil || user == nil {
+		t.Fatalf("GetByID() = %v, %v", user, err)
+	}
+	secret, err := uc.cipher.Decrypt(user.MFASecret, []byte(user.ID))
+	if err != nil {
+		t.Fatalf("Decrypt() unexpected error: %v", err)
+	}
+	return totp.Code(secret, totp.Step(clock.now))
+}
+
+func TestMFAUseCase_EnrollAndEnable(t *testing.T) {
+	ctx := context.Background()
+	userRepo := database.NewMockUserRepository()
+	user := &entities.User{Email: "bjensen@example.com", Name: "Barbara Jensen"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	uc, clock := newTestMFAUseCase(t, userRepo)
+
+	if err := uc.Enable(ctx, user.ID, "123456"); !errors.Is(err, ErrMFANotEnrolled) {
+		t.Errorf("Enable() before enrolling error = %v, want %v", err, ErrMFANotEnrolled)
+	}
+
+	enrollment, err := uc.Enroll(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Enroll() unexpected error: %v", err)
+	}
+	if len(enrollment.Secret) != 32 || !strings.HasPrefix(enrollment.URI, "otpauth://totp/Example:bjensen@example.com?") {
+		t.Errorf("Enroll() = %+v", enrollment)
+	}
+	stored, _ := userRepo.GetByID(ctx, user.ID)
+	if !strings.HasPrefix(stored.MFASecret, "v1:") || strings.Contains(stored.MFASecret, enrollment.Secret) {
+		t.Errorf("Enroll() should store the secret encrypted, got %q", stored.MFASecret)
+	}
+	if status, _ := uc.Status(ctx, user.ID); status.Enabled || !status.Pending {
+		t.Errorf("Status() after enrolling = %+v, want pending", status)
+	}
+
+	code := currentCode(t, uc, clock, userRepo, user.ID)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	if err := uc.Enable(ctx, user.ID, wrong); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("Enable() with a wrong code error = %v, want %v", err, ErrInvalidMFACode)
+	}
+	if err := uc.Enable(ctx, user.ID, code); err != nil {
+		t.Fatalf("Enable() unexpected error: %v", err)
+	}
+	if status, _ := uc.Status(ctx, user.ID); !status.Enabled || status.Pending {
+		t.Errorf("Status() after enabling = %+v, want enabled", status)
+	}
+	stored, _ = userRepo.GetByID(ctx, user.ID)
+	if stored.MFALastStep != totp.Step(clock.now) {
+		t.Errorf("Enable() should record the step of the code, got %d", stored.MFALastStep)
+	}
+
+	if _, err := uc.Enroll(ctx, user.ID); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Errorf("Enroll() after enabling error = %v, want %v", err, ErrMFAAlreadyEnabled)
+	}
+	if err := uc.Enable(ctx, user.ID, code); !errors.Is(err, ErrMFAAlreadyEnabled) {
+		t.Errorf("Enable() twice error = %v, want %v", err, ErrMFAAlreadyEnabled)
+	}
+}
+
+func TestMFAUseCase_Disable(t *testing.T) {
+	ctx := context.Background()
+	userRepo := database.NewMockUserRepository()
+	user := &entities.User{Email: "bjensen@example.com", Name: "Barbara Jensen"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	uc, clock := newTestMFAUseCase(t, userRepo)
+
+	if err := uc.Disable(ctx, user.ID, "123456"); !errors.Is(err, ErrMFANotEnabled) {
+		t.Errorf("Disable() before enabling error = %v, want %v", err, ErrMFANotEnabled)
+	}
+	if _, err := uc.Enroll(ctx, user.ID); err != nil {
+		t.Fatalf("Enroll() unexpected error: %v", err)
+	}
+	code := currentCode(t, uc, clock, userRepo, user.ID)
+	if err := uc.Enable(ctx, user.ID, code); err != nil {
+		t.Fatalf("Enable() unexpected error: %v", err)
+	}
+
+	if err := uc.Disable(ctx, user.ID, code); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("Disable() with the code already used error = %v, want %v", err, ErrInvalidMFACode)
+	}
+	clock.now = clock.now.Add(totp.Period)
+	if err := uc.Disable(ctx, user.ID, currentCode(t, uc, clock, userRepo, user.ID)); err != nil {
+		t.Fatalf("Disable() unexpected error: %v", err)
+	}
+	stored, _ := userRepo.GetByID(ctx, user.ID)
+	if stored.MFAEnabled || stored.MFASecret != "" {
+		t.Errorf("Disable() should remove the secret, got %+v", stored)
+	}
+}
+
+func TestMFAUseCase_UnknownUser(t *testing.T) {
+	uc, _ := newTestMFAUseCase(t, database.NewMockUserRepository())
+	if _, err := uc.Status(context.Background(), "missing"); !errors.Is(err, repositories.ErrUserNotFound) {
+		t.Errorf("Status() error = %v, want %v", err, repositories.ErrUserNotFound)
+	}
+}
+
+func TestAuthUseCase_Login_MFA(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubProvider{identities: map[string]*auth.Identity{
+		"bjensen": {Provider: "stub", Username: "bjensen", Email: "bjensen@example.com", Roles: []string{"user"}},
+	}}
+	uc, userRepo, _ := newTestAuthUseCase(provider)
+	session, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("Login() before enabling MFA unexpected error: %v", err)
+	}
+	userID := session.User.ID
+
+	mfa, clock := newTestMFAUseCase(t, userRepo)
+	if _, err := mfa.Enroll(ctx, userID); err != nil {
+		t.Fatalf("Enroll() unexpected error: %v", err)
+	}
+	if err := mfa.Enable(ctx, userID, currentCode(t, mfa, clock, userRepo, userID)); err != nil {
+		t.Fatalf("Enable() unexpected error: %v", err)
+	}
+
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2"}); !errors.Is(err, ErrMFAUnavailable) {
+		t.Errorf("Login() without an MFA use case error = %v, want %v", err, ErrMFAUnavailable)
+	}
+	uc.WithMFA(mfa)
+
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2"}); !errors.Is(err, ErrMFARequired) {
+		t.Errorf("Login() without a code error = %v, want %v", err, ErrMFARequired)
+	}
+	clock.now = clock.now.Add(totp.Period)
+	code := currentCode(t, mfa, clock, userRepo, userID)
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "wrong", Code: code}); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("Login() with a wrong password error = %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2", Code: code}); err != nil {
+		t.Fatalf("Login() with a code unexpected error: %v", err)
+	}
+	if _, err := uc.Login(ctx, Credentials{Username: "bjensen", Password: "hunter2", Code: code}); !errors.Is(err, ErrInvalidMFACode) {
+		t.Errorf("Login() replaying a code error = %v, want %v", err, ErrInvalidMFACode)
+	}
+}
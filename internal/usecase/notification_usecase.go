@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/notification"
+	"clean-architecture/pkg/logger"
+)
+
+// NotificationUseCase turns domain events into notifications and hands
+// them to the channels. Each channel subscribes on its own, so one that
+// fails is retried without notifying the others again.
+type NotificationUseCase struct {
+	channels map[string]notification.Channel
+	names    []string
+	logger   logger.Logger
+}
+
+// NewNotificationUseCase creates a new notification use case delivering
+// through channels
+func NewNotificationUseCase(channels []notification.Channel, logger logger.Logger) *NotificationUseCase {
+	uc := &NotificationUseCase{
+		channels: make(map[string]notification.Channel, len(channels)),
+		logger:   logger,
+	}
+	for _, channel := range channels {
+		uc.channels[channel.Name()] = channel
+		uc.names = append(uc.names, channel.Name())
+	}
+	return uc
+}
+
+// Channels returns the names of the channels, in the order they were
+// given
+func (uc *NotificationUseCase) Channels() []string {
+	return append([]string(nil), uc.names...)
+}
+
+// Notify delivers the notification of event through the channel named
+// channel
+func (uc *NotificationUseCase) Notify(ctx context.Context, channel string, event events.Event) error {
+	ch, ok := uc.channels[channel]
+	if !ok {
+		return fmt.Errorf("unknown notification channel %q", channel)
+	}
+
+	n := notification.Notification{
+		EventID:       event.ID,
+		Type:          event.Type,
+		OccurredAt:    event.OccurredAt,
+		CorrelationID: event.CorrelationID,
+		UserID:        event.AggregateID,
+		User:          eventUser(event),
+	}
+	if err := ch.Notify(ctx, n); err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"channel":  channel,
+			"event_id": event.ID,
+			"type":     event.Type,
+			"error":    err.Error(),
+		}).Warn("Failed to send notification")
+		return fmt.Errorf("failed to notify %s: %w", channel, err)
+	}
+	return nil
+}
+
+// eventUser returns the user event carries as its data. Events arrive
+// decoded from JSON, so the data is decoded again into a user.
+func eventUser(event events.Event) *entities.User {
+	if event.Data == nil {
+		return nil
+	}
+	raw, err := json.Marshal(event.Data)
+	if err != nil {
+		return nil
+	}
+	var user entities.User
+	if err := json.Unmarshal(raw, &user); err != nil || user.ID != event.AggregateID {
+		return nil
+	}
+	return &user
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/notification"
+	"clean-architecture/pkg/logger"
+)
+
+// recordingChannel records the notifications sent through it and fails
+// while failing is set
+type recordingChannel struct {
+	name     string
+	notified []notification.Notification
+	failing  bool
+}
+
+func (c *recordingChannel) Name() string { return c.name }
+
+func (c *recordingChannel) Notify(ctx context.Context, n notification.Notification) error {
+	if c.failing {
+		return errors.New("connection refused")
+	}
+	c.notified = append(c.notified, n)
+	return nil
+}
+
+// decodedEvent returns event as subscribers receive it, decoded from the
+// JSON it was published as
+func decodedEvent(t *testing.T, event events.Event) events.Event {
+	t.Helper()
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("encode event: %v", err)
+	}
+	var decoded events.Event
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	return decoded
+}
+
+func TestNotificationUseCase_Notify(t *testing.T) {
+	ctx := context.Background()
+	email := &recordingChannel{name: "email"}
+	webhook := &recordingChannel{name: "webhook"}
+	notifications := NewNotificationUseCase([]notification.Channel{email, webhook}, logger.New())
+
+	if got := notifications.Channels(); len(got) != 2 || got[0] != "email" || got[1] != "webhook" {
+		t.Fatalf("Channels() = %v, want [email webhook]", got)
+	}
+
+	user := entities.NewUser("jane@example.com", "Jane")
+	event := events.NewEvent(events.UserCreated, user.ID, user)
+	event.CorrelationID = "cor_123"
+	if err := notifications.Notify(ctx, "email", decodedEvent(t, event)); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+
+	if len(webhook.notified) != 0 {
+		t.Errorf("webhook was notified %d times, want 0", len(webhook.notified))
+	}
+	if len(email.notified) != 1 {
+		t.Fatalf("email was notified %d times, want 1", len(email.notified))
+	}
+	got := email.notified[0]
+	if got.EventID != event.ID || got.Type != events.UserCreated || got.UserID != user.ID || got.CorrelationID != "cor_123" {
+		t.Errorf("notification = %+v, want event %s of user %s", got, event.ID, user.ID)
+	}
+	if got.User == nil || got.User.Email != "jane@example.com" {
+		t.Errorf("notification user = %v, want the user the event carries", got.User)
+	}
+}
+
+func TestNotificationUseCase_NotifyWithoutUser(t *testing.T) {
+	log := &recordingChannel{name: "log"}
+	notifications := NewNotificationUseCase([]notification.Channel{log}, logger.New())
+
+	event := events.NewEvent(events.UserDeleted, "user_1", nil)
+	if err := notifications.Notify(context.Background(), "log", decodedEvent(t, event)); err != nil {
+		t.Fatalf("Notify() unexpected error: %v", err)
+	}
+	if len(log.notified) != 1 || log.notified[0].User != nil || log.notified[0].UserID != "user_1" {
+		t.Errorf("notified %+v, want one notification of user_1 without the user", log.notified)
+	}
+}
+
+func TestNotificationUseCase_NotifyFailures(t *testing.T) {
+	ctx := context.Background()
+	webhook := &recordingChannel{name: "webhook", failing: true}
+	notifications := NewNotificationUseCase([]notification.Channel{webhook}, logger.New())
+	event := decodedEvent(t, events.NewEvent(events.UserDeleted, "user_1", nil))
+
+	if err := notifications.Notify(ctx, "webhook", event); err == nil {
+		t.Error("Notify() expected an error from the failing channel, so the event is retried")
+	}
+	if err := notifications.Notify(ctx, "sms", event); err == nil {
+		t.Error("Notify() expected an error for an unknown channel")
+	}
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// maxOrganizationNameLength bounds the name of an organization
+const maxOrganizationNameLength = 100
+
+// OrganizationUseCase implements managing the organizations service
+// accounts belong to
+type OrganizationUseCase struct {
+	orgRepo repositories.OrganizationRepository
+	logger  logger.Logger
+}
+
+// NewOrganizationUseCase creates a new organization use case instance
+func NewOrganizationUseCase(orgRepo repositories.OrganizationRepository, logger logger.Logger) *OrganizationUseCase {
+	return &OrganizationUseCase{
+		orgRepo: orgRepo,
+		logger:  logger,
+	}
+}
+
+// CreateOrganization creates an organization named name
+func (uc *OrganizationUseCase) CreateOrganization(ctx context.Context, name string) (*entities.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrOrganizationNameRequired
+	}
+	if utf8.RuneCountInString(name) > maxOrganizationNameLength {
+		return nil, ErrOrganizationNameTooLong
+	}
+
+	org := entities.NewOrganization(name)
+	if err := uc.orgRepo.Create(ctx, org); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to create organization")
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	uc.logger.WithField("organization_id", org.ID).Info("Organization created")
+	return org, nil
+}
+
+// GetOrganization retrieves an organization by ID
+func (uc *OrganizationUseCase) GetOrganization(ctx context.Context, id string) (*entities.Organization, error) {
+	org, err := uc.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return org, nil
+}
+
+// ListOrganizations retrieves organizations, oldest first
+func (uc *OrganizationUseCase) ListOrganizations(ctx context.Context, limit, offset int) ([]*entities.Organization, error) {
+	orgs, err := uc.orgRepo.List(ctx, limit, offset)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to list organizations")
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	return orgs, nil
+}
@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// OrganizationUseCaseInterface defines the interface for organizations
+type OrganizationUseCaseInterface interface {
+	CreateOrganization(ctx context.Context, name string) (*entities.Organization, error)
+	GetOrganization(ctx context.Context, id string) (*entities.Organization, error)
+	ListOrganizations(ctx context.Context, limit, offset int) ([]*entities.Organization, error)
+}
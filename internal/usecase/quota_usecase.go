@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/logger"
+)
+
+// QuotaUsage is a quota with how much of its resource is used
+type QuotaUsage struct {
+	Resource string
+	// Limit is the most of the resource that may exist; 0 is unlimited
+	Limit int
+	Used  int64
+	// Default reports whether Limit is the configured default because no
+	// administrator set one
+	Default bool
+	// UpdatedAt is when an administrator last set the quota; zero for
+	// defaults
+	UpdatedAt time.Time
+}
+
+// QuotaExceededError is returned for creations refused because their
+// resource reached its quota
+type QuotaExceededError struct {
+	Resource string
+	Limit    int
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %d reached", e.Resource, e.Limit)
+}
+
+// Unwrap lets errors.Is match ErrQuotaExceeded
+func (e *QuotaExceededError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// QuotaUseCase enforces limits on how many of a resource may exist, for
+// now the users of the deployment. Quotas are soft: usage is counted
+// before creating, so concurrent creations may overshoot a quota slightly,
+// and lowering a quota below the usage only refuses further creations.
+type QuotaUseCase struct {
+	quotaRepo repositories.QuotaRepository
+	userRepo  repositories.UserRepository
+	// defaults are the limits of resources no administrator set a quota
+	// on, which also names the resources quotas exist for
+	defaults map[string]int
+	logger   logger.Logger
+}
+
+// NewQuotaUseCase creates a new quota use case instance. maxUsers is the
+// user quota until an administrator sets one; 0 is unlimited.
+func NewQuotaUseCase(quotaRepo repositories.QuotaRepository, userRepo repositories.UserRepository, maxUsers int, logger logger.Logger) *QuotaUseCase {
+	return &QuotaUseCase{
+		quotaRepo: quotaRepo,
+		userRepo:  userRepo,
+		defaults:  map[string]int{entities.QuotaResourceUsers: maxUsers},
+		logger:    logger,
+	}
+}
+
+// ListQuotas returns the quota of every resource, by resource
+func (uc *QuotaUseCase) ListQuotas(ctx context.Context) ([]*QuotaUsage, error) {
+	resources := make([]string, 0, len(uc.defaults))
+	for resource := range uc.defaults {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	quotas := make([]*QuotaUsage, 0, len(resources))
+	for _, resource := range resources {
+		usage, err := uc.GetQuota(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+		quotas = append(quotas, usage)
+	}
+	return quotas, nil
+}
+
+// GetQuota returns the quota of resource
+func (uc *QuotaUseCase) GetQuota(ctx context.Context, resource string) (*QuotaUsage, error) {
+	quota, err := uc.quota(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+	used, err := uc.used(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+	return uc.usage(quota, used), nil
+}
+
+// SetQuota sets the quota of resource to limit, 0 being unlimited. Usage
+// above the new limit is kept.
+func (uc *QuotaUseCase) SetQuota(ctx context.Context, resource string, limit int) (*QuotaUsage, error) {
+	if _, ok := uc.defaults[resource]; !ok {
+		return nil, ErrUnknownQuota
+	}
+	if limit < 0 {
+		return nil, ErrInvalidQuotaLimit
+	}
+
+	quota := entities.NewQuota(resource, limit)
+	if err := uc.quotaRepo.Save(ctx, quota); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to save quota")
+		return nil, fmt.Errorf("failed to save quota: %w", err)
+	}
+	uc.logger.WithFields(map[string]interface{}{
+		"resource": resource,
+		"limit":    limit,
+	}).Info("Quota updated")
+
+	used, err := uc.used(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+	return uc.usage(quota, used), nil
+}
+
+// check refuses creating another of resource once its quota is reached
+func (uc *QuotaUseCase) check(ctx context.Context, resource string) error {
+	quota, err := uc.quota(ctx, resource)
+	if err != nil {
+		return fmt.Errorf("failed to check %s quota: %w", resource, err)
+	}
+	if quota.Limit == 0 {
+		return nil
+	}
+	used, err := uc.used(ctx, resource)
+	if err != nil {
+		return fmt.Errorf("failed to check %s quota: %w", resource, err)
+	}
+	if !quota.Allows(used) {
+		uc.logger.WithFields(map[string]interface{}{
+			"resource": resource,
+			"limit":    quota.Limit,
+			"used":     used,
+		}).Warn("Creation refused by quota")
+		return &QuotaExceededError{Resource: resource, Limit: quota.Limit}
+	}
+	return nil
+}
+
+// quota returns the quota set on resource, or its default when none was.
+// Default quotas have a zero UpdatedAt.
+func (uc *QuotaUseCase) quota(ctx context.Context, resource string) (*entities.Quota, error) {
+	limit, ok := uc.defaults[resource]
+	if !ok {
+		return nil, ErrUnknownQuota
+	}
+	quota, err := uc.quotaRepo.Get(consistency.WithPrimary(ctx), resource)
+	if errors.Is(err, repositories.ErrQuotaNotFound) {
+		return &entities.Quota{Resource: resource, Limit: limit}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return quota, nil
+}
+
+// used returns how many of resource exist, counted on the primary so
+// creations that just happened are seen
+func (uc *QuotaUseCase) used(ctx context.Context, resource string) (int64, error) {
+	switch resource {
+	case entities.QuotaResourceUsers:
+		return uc.userRepo.Count(consistency.WithPrimary(ctx))
+	default:
+		return 0, ErrUnknownQuota
+	}
+}
+
+func (uc *QuotaUseCase) usage(quota *entities.Quota, used int64) *QuotaUsage {
+	return &QuotaUsage{
+		Resource:  quota.Resource,
+		Limit:     quota.Limit,
+		Used:      used,
+		Default:   quota.UpdatedAt.IsZero(),
+		UpdatedAt: quota.UpdatedAt,
+	}
+}
@@ -0,0 +1,11 @@
+package usecase
+
+import "context"
+
+// QuotaUseCaseInterface defines the interface for viewing and adjusting
+// quotas
+type QuotaUseCaseInterface interface {
+	ListQuotas(ctx context.Context) ([]*QuotaUsage, error)
+	GetQuota(ctx context.Context, resource string) (*QuotaUsage, error)
+	SetQuota(ctx context.Context, resource string, limit int) (*QuotaUsage, error)
+}
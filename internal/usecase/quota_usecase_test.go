@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+func TestQuotaUseCase_EnforcesUserQuota(t *testing.T) {
+	ctx := context.Background()
+	userRepo := database.NewMockUserRepository()
+	quotas := NewQuotaUseCase(database.NewMockQuotaRepository(), userRepo, 2, logger.New())
+	users := NewUserUseCase(userRepo, nil, logger.New()).WithQuotas(quotas)
+
+	if _, err := users.CreateUser(ctx, "a@example.com", "A"); err != nil {
+		t.Fatalf("CreateUser() unexpected error: %v", err)
+	}
+	if _, _, err := users.UpsertUser(ctx, "b@example.com", "B"); err != nil {
+		t.Fatalf("UpsertUser() unexpected error: %v", err)
+	}
+
+	_, err := users.CreateUser(ctx, "c@example.com", "C")
+	var exceeded *QuotaExceededError
+	if !errors.As(err, &exceeded) || !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("CreateUser() error = %v, want QuotaExceededError", err)
+	}
+	if exceeded.Resource != entities.QuotaResourceUsers || exceeded.Limit != 2 {
+		t.Errorf("QuotaExceededError = %+v", exceeded)
+	}
+	if _, _, err := users.UpsertUser(ctx, "c@example.com", "C"); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("UpsertUser() creating error = %v, want ErrQuotaExceeded", err)
+	}
+	if _, _, err := users.UpsertUser(ctx, "b@example.com", "Renamed"); err != nil {
+		t.Errorf("UpsertUser() renaming unexpected error: %v", err)
+	}
+
+	// Raising the quota lets creations through again
+	if _, err := quotas.SetQuota(ctx, entities.QuotaResourceUsers, 3); err != nil {
+		t.Fatalf("SetQuota() unexpected error: %v", err)
+	}
+	if _, err := users.CreateUser(ctx, "c@example.com", "C"); err != nil {
+		t.Errorf("CreateUser() after raising the quota unexpected error: %v", err)
+	}
+}
+
+func TestQuotaUseCase_GetAndSet(t *testing.T) {
+	ctx := context.Background()
+	userRepo := database.NewMockUserRepository()
+	quotas := NewQuotaUseCase(database.NewMockQuotaRepository(), userRepo, 0, logger.New())
+	if _, err := NewUserUseCase(userRepo, nil, logger.New()).CreateUser(ctx, "a@example.com", "A"); err != nil {
+		t.Fatalf("CreateUser() unexpected error: %v", err)
+	}
+
+	usage, err := quotas.GetQuota(ctx, entities.QuotaResourceUsers)
+	if err != nil {
+		t.Fatalf("GetQuota() unexpected error: %v", err)
+	}
+	if usage.Limit != 0 || usage.Used != 1 || !usage.Default {
+		t.Errorf("GetQuota() = %+v, want the unlimited default with 1 used", usage)
+	}
+
+	// A quota below the usage is accepted; it only refuses creations
+	usage, err = quotas.SetQuota(ctx, entities.QuotaResourceUsers, 1)
+	if err != nil {
+		t.Fatalf("SetQuota() unexpected error: %v", err)
+	}
+	if usage.Limit != 1 || usage.Used != 1 || usage.Default || usage.UpdatedAt.IsZero() {
+		t.Errorf("SetQuota() = %+v", usage)
+	}
+
+	list, err := quotas.ListQuotas(ctx)
+	if err != nil {
+		t.Fatalf("ListQuotas() unexpected error: %v", err)
+	}
+	if len(list) != 1 || list[0].Limit != 1 {
+		t.Errorf("ListQuotas() = %+v", list)
+	}
+
+	if _, err := quotas.SetQuota(ctx, entities.QuotaResourceUsers, -1); !errors.Is(err, ErrInvalidQuotaLimit) {
+		t.Errorf("SetQuota() negative error = %v, want ErrInvalidQuotaLimit", err)
+	}
+	if _, err := quotas.SetQuota(ctx, "widgets", 1); !errors.Is(err, ErrUnknownQuota) {
+		t.Errorf("SetQuota() unknown error = %v, want ErrUnknownQuota", err)
+	}
+	if _, err := quotas.GetQuota(ctx, "widgets"); !errors.Is(err, ErrUnknownQuota) {
+		t.Errorf("GetQuota() unknown error = %v, want ErrUnknownQuota", err)
+	}
+}
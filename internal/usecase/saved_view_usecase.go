@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// maxViewNameLength bounds the name of a saved view
+const maxViewNameLength = 100
+
+// SavedViewUseCase implements saved list views. The filters of a view are
+// validated by the interface layer, which knows the query grammar.
+type SavedViewUseCase struct {
+	viewRepo repositories.SavedViewRepository
+	logger   logger.Logger
+}
+
+// NewSavedViewUseCase creates a new saved view use case instance
+func NewSavedViewUseCase(viewRepo repositories.SavedViewRepository, logger logger.Logger) *SavedViewUseCase {
+	return &SavedViewUseCase{
+		viewRepo: viewRepo,
+		logger:   logger,
+	}
+}
+
+// CreateView stores a new view
+func (uc *SavedViewUseCase) CreateView(ctx context.Context, view *entities.SavedView) error {
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" {
+		return ErrViewNameRequired
+	}
+	if utf8.RuneCountInString(view.Name) > maxViewNameLength {
+		return ErrViewNameTooLong
+	}
+
+	if err := uc.viewRepo.Create(ctx, view); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to create saved view")
+		return fmt.Errorf("failed to create saved view: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"view_id":  view.ID,
+		"owner_id": view.OwnerID,
+		"shared":   view.Shared,
+	}).Info("Saved view created")
+	return nil
+}
+
+// GetView retrieves a view userID may see. Personal views of other users
+// are reported as not found.
+func (uc *SavedViewUseCase) GetView(ctx context.Context, userID, id string) (*entities.SavedView, error) {
+	view, err := uc.viewRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved view: %w", err)
+	}
+	if !view.VisibleTo(userID) {
+		return nil, fmt.Errorf("failed to get saved view: %w", repositories.ErrSavedViewNotFound)
+	}
+	return view, nil
+}
+
+// ListViews retrieves the views of userID and every shared view, by name
+func (uc *SavedViewUseCase) ListViews(ctx context.Context, userID string) ([]*entities.SavedView, error) {
+	views, err := uc.viewRepo.ListVisible(ctx, userID)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to list saved views")
+		return nil, fmt.Errorf("failed to list saved views: %w", err)
+	}
+	return views, nil
+}
+
+// DeleteView removes a view
+func (uc *SavedViewUseCase) DeleteView(ctx context.Context, id string) error {
+	if err := uc.viewRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete saved view: %w", err)
+	}
+	uc.logger.WithField("view_id", id).Info("Saved view deleted")
+	return nil
+}
@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// SavedViewUseCaseInterface defines the interface for saved user list views
+type SavedViewUseCaseInterface interface {
+	CreateView(ctx context.Context, view *entities.SavedView) error
+	GetView(ctx context.Context, userID, id string) (*entities.SavedView, error)
+	ListViews(ctx context.Context, userID string) ([]*entities.SavedView, error)
+	DeleteView(ctx context.Context, id string) error
+}
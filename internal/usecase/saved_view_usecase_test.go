@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+func TestSavedViewUseCase_CreateView(t *testing.T) {
+	viewUseCase := NewSavedViewUseCase(database.NewMockSavedViewRepository(), logger.New())
+
+	tests := []struct {
+		name    string
+		view    string
+		wantErr error
+	}{
+		{name: "valid", view: "  Recent signups  "},
+		{name: "empty name", view: "   ", wantErr: ErrViewNameRequired},
+		{name: "long name", view: strings.Repeat("é", maxViewNameLength+1), wantErr: ErrViewNameTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := entities.NewSavedView("user_1", tt.view, false, map[string]string{"filter[name][contains]": "smith"}, "-created_at")
+			err := viewUseCase.CreateView(context.Background(), view)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateView() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (view.ID == "" || view.Name != "Recent signups") {
+				t.Errorf("CreateView() stored %+v", view)
+			}
+		})
+	}
+}
+
+func TestSavedViewUseCase_Visibility(t *testing.T) {
+	repo := database.NewMockSavedViewRepository()
+	viewUseCase := NewSavedViewUseCase(repo, logger.New())
+	ctx := context.Background()
+
+	personal := entities.NewSavedView("user_1", "Mine", false, nil, "")
+	shared := entities.NewSavedView("admin_1", "Everyone", true, nil, "")
+	other := entities.NewSavedView("user_2", "Theirs", false, nil, "")
+	for _, view := range []*entities.SavedView{personal, shared, other} {
+		if err := viewUseCase.CreateView(ctx, view); err != nil {
+			t.Fatalf("CreateView() unexpected error: %v", err)
+		}
+	}
+
+	views, err := viewUseCase.ListViews(ctx, "user_1")
+	if err != nil {
+		t.Fatalf("ListViews() unexpected error: %v", err)
+	}
+	var names []string
+	for _, view := range views {
+		names = append(names, view.Name)
+	}
+	if strings.Join(names, ",") != "Everyone,Mine" {
+		t.Errorf("ListViews() = %v, want [Everyone Mine]", names)
+	}
+
+	if _, err := viewUseCase.GetView(ctx, "user_1", shared.ID); err != nil {
+		t.Errorf("GetView() shared view: unexpected error %v", err)
+	}
+	if _, err := viewUseCase.GetView(ctx, "", shared.ID); err != nil {
+		t.Errorf("GetView() shared view without a user: unexpected error %v", err)
+	}
+	if _, err := viewUseCase.GetView(ctx, "user_1", other.ID); !errors.Is(err, repositories.ErrSavedViewNotFound) {
+		t.Errorf("GetView() another user's view: error = %v, want ErrSavedViewNotFound", err)
+	}
+	if _, err := viewUseCase.GetView(ctx, "", personal.ID); !errors.Is(err, repositories.ErrSavedViewNotFound) {
+		t.Errorf("GetView() personal view without a user: error = %v, want ErrSavedViewNotFound", err)
+	}
+
+	if err := viewUseCase.DeleteView(ctx, personal.ID); err != nil {
+		t.Fatalf("DeleteView() unexpected error: %v", err)
+	}
+	if err := viewUseCase.DeleteView(ctx, personal.ID); !errors.Is(err, repositories.ErrSavedViewNotFound) {
+		t.Errorf("DeleteView() twice: error = %v, want ErrSavedViewNotFound", err)
+	}
+}
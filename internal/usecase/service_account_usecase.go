@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/logger"
+)
+
+// maxServiceAccountNameLength bounds the name of a service account
+const maxServiceAccountNameLength = 100
+
+// serviceAccountEmailDomain is the domain of the generated emails that keep
+// service accounts unique among users; .invalid never resolves (RFC 2606)
+const serviceAccountEmailDomain = "service-accounts.invalid"
+
+// ServiceAccountUseCase implements the lifecycle of the service accounts
+// of organizations and of their API keys. Service accounts are users of
+// kind service: they count against no quota, publish no user events and are
+// left out of user listings unless asked for.
+type ServiceAccountUseCase struct {
+	orgRepo  repositories.OrganizationRepository
+	userRepo repositories.UserRepository
+	keys     *APIKeyUseCase
+	logger   logger.Logger
+}
+
+// NewServiceAccountUseCase creates a new service account use case instance
+// issuing keys through keys
+func NewServiceAccountUseCase(orgRepo repositories.OrganizationRepository, userRepo repositories.UserRepository, keys *APIKeyUseCase, logger logger.Logger) *ServiceAccountUseCase {
+	return &ServiceAccountUseCase{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		keys:     keys,
+		logger:   logger,
+	}
+}
+
+// CreateServiceAccount creates a service account of the organization with
+// orgID whose keys may grant at most scopes
+func (uc *ServiceAccountUseCase) CreateServiceAccount(ctx context.Context, orgID, name string, scopes []string) (*entities.User, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrServiceAccountNameRequired
+	}
+	if utf8.RuneCountInString(name) > maxServiceAccountNameLength {
+		return nil, ErrServiceAccountNameTooLong
+	}
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.orgRepo.GetByID(ctx, orgID); err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	email, err := serviceAccountEmail()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+	account := entities.NewServiceAccount(orgID, email, name, scopes)
+	if err := uc.userRepo.Create(ctx, account); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to create service account")
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"organization_id": orgID,
+		"user_id":         account.ID,
+		"scopes":          scopes,
+	}).Info("Service account created")
+	return account, nil
+}
+
+// ListServiceAccounts retrieves the service accounts of an organization,
+// oldest first
+func (uc *ServiceAccountUseCase) ListServiceAccounts(ctx context.Context, orgID string, limit, offset int) ([]*entities.User, error) {
+	if _, err := uc.orgRepo.GetByID(ctx, orgID); err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	accounts, err := uc.userRepo.Find(ctx, repositories.Specification{
+		Conditions: []repositories.Condition{
+			{Field: repositories.UserKindField, Operator: repositories.OpEq, Value: entities.UserKindService},
+			{Field: "organization_id", Operator: repositories.OpEq, Value: orgID},
+		},
+		Sort:   []repositories.SortOrder{{Field: "created_at"}, {Field: "id"}},
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to list service accounts")
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// GetServiceAccount retrieves a service account of an organization
+func (uc *ServiceAccountUseCase) GetServiceAccount(ctx context.Context, orgID, id string) (*entities.User, error) {
+	account, err := uc.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+	if account == nil || !account.IsServiceAccount() || account.OrganizationID != orgID {
+		return nil, ErrServiceAccountNotFound
+	}
+	return account, nil
+}
+
+// DeleteServiceAccount revokes the keys of a service account and deletes it
+func (uc *ServiceAccountUseCase) DeleteServiceAccount(ctx context.Context, orgID, id string) error {
+	account, err := uc.GetServiceAccount(consistency.WithPrimary(ctx), orgID, id)
+	if err != nil {
+		return err
+	}
+
+	keys, err := uc.keys.keyRepo.ListByOwner(ctx, account.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list service account keys: %w", err)
+	}
+	for _, key := range keys {
+		if _, err := uc.keys.RevokeKey(ctx, key.ID); err != nil {
+			return err
+		}
+	}
+	if err := uc.userRepo.Delete(ctx, account.ID); err != nil {
+		return fmt.Errorf("failed to delete service account: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"organization_id": orgID,
+		"user_id":         account.ID,
+		"revoked_keys":    len(keys),
+	}).Info("Service account deleted")
+	return nil
+}
+
+// CreateKey issues a key acting as a service account. Keys default to the
+// account's scopes and may not grant others.
+func (uc *ServiceAccountUseCase) CreateKey(ctx context.Context, orgID, accountID, createdBy, name string, scopes []string, expiresAt *time.Time) (*entities.APIKey, string, error) {
+	account, err := uc.GetServiceAccount(consistency.WithPrimary(ctx), orgID, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(scopes) == 0 {
+		scopes = account.Scopes
+	}
+	granted := policy.Subject{Scopes: account.Scopes}
+	for _, scope := range scopes {
+		if _, known := policy.Scopes[scope]; known && !granted.HasScope(scope) {
+			return nil, "", fmt.Errorf("%w: %q", ErrScopeNotGranted, scope)
+		}
+	}
+	return uc.keys.createKey(ctx, account.ID, createdBy, name, scopes, expiresAt)
+}
+
+// ListKeys retrieves the keys of a service account, newest first,
+// including revoked and expired ones
+func (uc *ServiceAccountUseCase) ListKeys(ctx context.Context, orgID, accountID string) ([]*entities.APIKey, error) {
+	account, err := uc.GetServiceAccount(ctx, orgID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := uc.keys.keyRepo.ListByOwner(ctx, account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service account keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeKey stops a key of a service account from authenticating
+func (uc *ServiceAccountUseCase) RevokeKey(ctx context.Context, orgID, accountID, keyID string) (*entities.APIKey, error) {
+	account, err := uc.GetServiceAccount(ctx, orgID, accountID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := uc.keys.GetKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.OwnerID != account.ID {
+		return nil, fmt.Errorf("failed to revoke API key: %w", repositories.ErrAPIKeyNotFound)
+	}
+	return uc.keys.RevokeKey(ctx, key.ID)
+}
+
+// serviceAccountEmail returns a new email for a service account, which
+// never receives mail
+func serviceAccountEmail() (string, error) {
+	local := make([]byte, 8)
+	if _, err := rand.Read(local); err != nil {
+		return "", err
+	}
+	return "sa-" + hex.EncodeToString(local) + "@" + serviceAccountEmailDomain, nil
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// ServiceAccountUseCaseInterface defines the interface for the service
+// accounts of organizations and their API keys
+type ServiceAccountUseCaseInterface interface {
+	CreateServiceAccount(ctx context.Context, orgID, name string, scopes []string) (*entities.User, error)
+	ListServiceAccounts(ctx context.Context, orgID string, limit, offset int) ([]*entities.User, error)
+	GetServiceAccount(ctx context.Context, orgID, id string) (*entities.User, error)
+	DeleteServiceAccount(ctx context.Context, orgID, id string) error
+	CreateKey(ctx context.Context, orgID, accountID, createdBy, name string, scopes []string, expiresAt *time.Time) (*entities.APIKey, string, error)
+	ListKeys(ctx context.Context, orgID, accountID string) ([]*entities.APIKey, error)
+	RevokeKey(ctx context.Context, orgID, accountID, keyID string) (*entities.APIKey, error)
+}
@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/policy"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+// newServiceAccountFixture returns a service account use case with an
+// organization, the key use case it issues keys through and its users
+func newServiceAccountFixture(t *testing.T) (*ServiceAccountUseCase, *APIKeyUseCase, repositories.UserRepository, *entities.Organization) {
+	t.Helper()
+	orgRepo := database.NewMockOrganizationRepository()
+	userRepo := database.NewMockUserRepository()
+	keys := NewAPIKeyUseCase(database.NewMockAPIKeyRepository(), logger.New()).WithServiceAccounts(userRepo)
+
+	org, err := NewOrganizationUseCase(orgRepo, logger.New()).CreateOrganization(context.Background(), " Acme ")
+	if err != nil {
+		t.Fatalf("CreateOrganization() unexpected error: %v", err)
+	}
+	return NewServiceAccountUseCase(orgRepo, userRepo, keys, logger.New()), keys, userRepo, org
+}
+
+func TestOrganizationUseCase_CreateOrganization(t *testing.T) {
+	orgs := NewOrganizationUseCase(database.NewMockOrganizationRepository(), logger.New())
+	ctx := context.Background()
+
+	org, err := orgs.CreateOrganization(ctx, " Acme ")
+	if err != nil || org.ID == "" || org.Name != "Acme" {
+		t.Fatalf("CreateOrganization() = %+v, %v", org, err)
+	}
+	if _, err := orgs.CreateOrganization(ctx, " "); !errors.Is(err, ErrOrganizationNameRequired) {
+		t.Errorf("CreateOrganization() without a name error = %v, want %v", err, ErrOrganizationNameRequired)
+	}
+	if _, err := orgs.CreateOrganization(ctx, strings.Repeat("a", maxOrganizationNameLength+1)); !errors.Is(err, ErrOrganizationNameTooLong) {
+		t.Errorf("CreateOrganization() with a long name error = %v, want %v", err, ErrOrganizationNameTooLong)
+	}
+	if _, err := orgs.GetOrganization(ctx, "org_missing"); !errors.Is(err, repositories.ErrOrganizationNotFound) {
+		t.Errorf("GetOrganization() of a missing organization error = %v, want %v", err, repositories.ErrOrganizationNotFound)
+	}
+}
+
+func TestServiceAccountUseCase_CreateServiceAccount(t *testing.T) {
+	accounts, _, userRepo, org := newServiceAccountFixture(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		orgID   string
+		account string
+		scopes  []string
+		wantErr error
+	}{
+		{name: "valid", orgID: org.ID, account: "billing", scopes: []string{policy.ScopeUsersRead}},
+		{name: "unknown organization", orgID: "org_missing", account: "billing", scopes: []string{policy.ScopeUsersRead}, wantErr: repositories.ErrOrganizationNotFound},
+		{name: "empty name", orgID: org.ID, account: " ", scopes: []string{policy.ScopeUsersRead}, wantErr: ErrServiceAccountNameRequired},
+		{name: "no scopes", orgID: org.ID, account: "billing", wantErr: ErrAPIKeyScopesRequired},
+		{name: "unknown scope", orgID: org.ID, account: "billing", scopes: []string{"users:everything"}, wantErr: ErrUnknownScope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, err := accounts.CreateServiceAccount(ctx, tt.orgID, tt.account, tt.scopes)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateServiceAccount() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !account.IsServiceAccount() || account.OrganizationID != org.ID || !strings.HasSuffix(account.Email, "@"+serviceAccountEmailDomain) {
+				t.Errorf("CreateServiceAccount() = %+v", account)
+			}
+		})
+	}
+
+	// Service accounts are left out of user listings and counts
+	if err := userRepo.Create(ctx, entities.NewUser("alice@example.com", "Alice")); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := userRepo.Count(ctx); count != 1 {
+		t.Errorf("Count() = %d, want 1", count)
+	}
+	users, _ := userRepo.Find(ctx, repositories.Specification{})
+	if len(users) != 1 || users[0].IsServiceAccount() {
+		t.Errorf("Find() = %+v, want only the human user", users)
+	}
+	listed, err := accounts.ListServiceAccounts(ctx, org.ID, 10, 0)
+	if err != nil || len(listed) != 1 || listed[0].Name != "billing" {
+		t.Errorf("ListServiceAccounts() = %+v, %v", listed, err)
+	}
+	if _, err := accounts.ListServiceAccounts(ctx, "org_missing", 10, 0); !errors.Is(err, repositories.ErrOrganizationNotFound) {
+		t.Errorf("ListServiceAccounts() of a missing organization error = %v", err)
+	}
+}
+
+func TestServiceAccountUseCase_Keys(t *testing.T) {
+	accounts, keys, _, org := newServiceAccountFixture(t)
+	ctx := context.Background()
+
+	account, err := accounts.CreateServiceAccount(ctx, org.ID, "billing", []string{policy.ScopeUsersWrite})
+	if err != nil {
+		t.Fatalf("CreateServiceAccount() unexpected error: %v", err)
+	}
+
+	if _, _, err := accounts.CreateKey(ctx, org.ID, account.ID, "admin_1", "ci", []string{policy.ScopeAdmin}, nil); !errors.Is(err, ErrScopeNotGranted) {
+		t.Errorf("CreateKey() beyond the account's scopes error = %v, want %v", err, ErrScopeNotGranted)
+	}
+	if _, _, err := accounts.CreateKey(ctx, "org_other", account.ID, "admin_1", "ci", nil, nil); !errors.Is(err, ErrServiceAccountNotFound) {
+		t.Errorf("CreateKey() in another organization error = %v, want %v", err, ErrServiceAccountNotFound)
+	}
+
+	key, plaintext, err := accounts.CreateKey(ctx, org.ID, account.ID, "admin_1", "ci", nil, nil)
+	if err != nil {
+		t.Fatalf("CreateKey() unexpected error: %v", err)
+	}
+	if key.OwnerID != account.ID || !reflect.DeepEqual(key.Scopes, []string{policy.ScopeUsersWrite}) {
+		t.Errorf("CreateKey() = %+v, want the account's scopes", key)
+	}
+
+	// Requests made with the key act as the account
+	subject, err := keys.Authenticate(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("Authenticate() unexpected error: %v", err)
+	}
+	if subject.ID != account.ID || subject.Attributes["organization_id"] != org.ID || subject.Attributes["key_id"] != key.ID || !subject.HasRole(policy.RoleService) {
+		t.Errorf("Authenticate() subject = %+v", subject)
+	}
+
+	listed, err := accounts.ListKeys(ctx, org.ID, account.ID)
+	if err != nil || len(listed) != 1 || listed[0].ID != key.ID {
+		t.Errorf("ListKeys() = %+v, %v", listed, err)
+	}
+	other, _, err := keys.CreateKey(ctx, apiKeyAdmin, "other", []string{policy.ScopeUsersRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := accounts.RevokeKey(ctx, org.ID, account.ID, other.ID); !errors.Is(err, repositories.ErrAPIKeyNotFound) {
+		t.Errorf("RevokeKey() of another key error = %v, want %v", err, repositories.ErrAPIKeyNotFound)
+	}
+
+	// Deleting the account revokes its keys
+	if err := accounts.DeleteServiceAccount(ctx, org.ID, account.ID); err != nil {
+		t.Fatalf("DeleteServiceAccount() unexpected error: %v", err)
+	}
+	if _, err := keys.Authenticate(ctx, plaintext); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() after deleting the account error = %v, want %v", err, ErrInvalidAPIKey)
+	}
+	if _, err := accounts.GetServiceAccount(ctx, org.ID, account.ID); !errors.Is(err, ErrServiceAccountNotFound) {
+		t.Errorf("GetServiceAccount() of a deleted account error = %v", err)
+	}
+}
+
+func TestAPIKeyUseCase_AuthenticateDeletedOwner(t *testing.T) {
+	userRepo := database.NewMockUserRepository()
+	keys := NewAPIKeyUseCase(database.NewMockAPIKeyRepository(), logger.New()).WithServiceAccounts(userRepo)
+	ctx := context.Background()
+
+	account := entities.NewServiceAccount("org_1", "sa@"+serviceAccountEmailDomain, "billing", []string{policy.ScopeUsersRead})
+	if err := userRepo.Create(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	_, plaintext, err := keys.createKey(ctx, account.ID, "admin_1", "ci", []string{policy.ScopeUsersRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := userRepo.Delete(ctx, account.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// A user deleted outside the service account endpoints keeps its keys,
+	// which authenticate no one
+	if _, err := keys.Authenticate(ctx, plaintext); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("Authenticate() error = %v, want %v", err, ErrInvalidAPIKey)
+	}
+}
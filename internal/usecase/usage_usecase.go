@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// maxUsageRange is the most days a usage report spans
+const maxUsageRange = 366
+
+// usageKey identifies the rollup a call is counted in
+type usageKey struct {
+	day      time.Time
+	keyID    string
+	tenantID string
+	method   string
+	route    string
+}
+
+// UsageUseCase meters API calls per key, tenant and route into daily
+// rollups. Calls are counted in memory and added to the stored rollups on
+// each Flush, so metering costs no query per call; calls counted since the
+// last flush are lost if the process dies.
+type UsageUseCase struct {
+	usageRepo repositories.UsageRepository
+	logger    logger.Logger
+
+	mutex   sync.Mutex
+	pending map[usageKey]*entities.UsageRecord
+}
+
+// NewUsageUseCase creates a new usage use case instance
+func NewUsageUseCase(usageRepo repositories.UsageRepository, logger logger.Logger) *UsageUseCase {
+	return &UsageUseCase{
+		usageRepo: usageRepo,
+		logger:    logger,
+		pending:   make(map[usageKey]*entities.UsageRecord),
+	}
+}
+
+// Record counts a call to route made now, with the sizes of its request
+// and response bodies
+func (uc *UsageUseCase) Record(keyID, tenantID, method, route string, bytesIn, bytesOut int64) {
+	uc.add(&entities.UsageRecord{
+		Day:      entities.UsageDay(time.Now()),
+		KeyID:    keyID,
+		TenantID: tenantID,
+		Method:   method,
+		Route:    route,
+		Requests: 1,
+		BytesIn:  bytesIn,
+		BytesOut: bytesOut,
+	})
+}
+
+// Flush adds the calls counted since the last flush to the stored rollups.
+// When the store fails they are kept for the next flush.
+func (uc *UsageUseCase) Flush(ctx context.Context) error {
+	uc.mutex.Lock()
+	batch := uc.pending
+	uc.pending = make(map[usageKey]*entities.UsageRecord)
+	uc.mutex.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+
+	records := make([]*entities.UsageRecord, 0, len(batch))
+	for _, record := range batch {
+		records = append(records, record)
+	}
+	if err := uc.usageRepo.Add(ctx, records); err != nil {
+		for _, record := range records {
+			uc.add(record)
+		}
+		uc.logger.WithFields(map[string]interface{}{
+			"error":   err.Error(),
+			"records": len(records),
+		}).Error("Failed to flush API usage")
+		return fmt.Errorf("failed to flush usage: %w", err)
+	}
+	return nil
+}
+
+// Report returns the daily rollups of the days from filter.From to
+// filter.To, both included. Calls not yet flushed are not counted.
+func (uc *UsageUseCase) Report(ctx context.Context, filter repositories.UsageFilter) ([]*entities.UsageRecord, error) {
+	filter.From = entities.UsageDay(filter.From)
+	filter.To = entities.UsageDay(filter.To)
+	if filter.To.Before(filter.From) || filter.To.Sub(filter.From) >= maxUsageRange*24*time.Hour {
+		return nil, ErrInvalidUsageRange
+	}
+
+	records, err := uc.usageRepo.List(ctx, filter)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to list API usage")
+		return nil, fmt.Errorf("failed to list usage: %w", err)
+	}
+	return records, nil
+}
+
+// add adds record to the counts awaiting the next flush
+func (uc *UsageUseCase) add(record *entities.UsageRecord) {
+	key := usageKey{record.Day, record.KeyID, record.TenantID, record.Method, record.Route}
+
+	uc.mutex.Lock()
+	defer uc.mutex.Unlock()
+	if pending, ok := uc.pending[key]; ok {
+		pending.Add(record)
+		return
+	}
+	uc.pending[key] = record
+}
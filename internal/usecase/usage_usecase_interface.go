@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+)
+
+// UsageUseCaseInterface defines the interface for reporting API usage
+type UsageUseCaseInterface interface {
+	Report(ctx context.Context, filter repositories.UsageFilter) ([]*entities.UsageRecord, error)
+}
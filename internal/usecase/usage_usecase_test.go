@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+// failingUsageRepository fails adds while failing is set
+type failingUsageRepository struct {
+	repositories.UsageRepository
+	failing bool
+}
+
+func (r *failingUsageRepository) Add(ctx context.Context, records []*entities.UsageRecord) error {
+	if r.failing {
+		return errors.New("connection refused")
+	}
+	return r.UsageRepository.Add(ctx, records)
+}
+
+func TestUsageUseCase_RollsUpCalls(t *testing.T) {
+	ctx := context.Background()
+	usage := NewUsageUseCase(database.NewMockUsageRepository(), logger.New())
+
+	usage.Record("key_1", "org_1", "GET", "/api/v1/users", 0, 100)
+	usage.Record("key_1", "org_1", "GET", "/api/v1/users", 0, 50)
+	usage.Record("key_1", "org_1", "POST", "/api/v1/users", 40, 80)
+	if err := usage.Flush(ctx); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+	usage.Record("key_1", "org_1", "GET", "/api/v1/users", 0, 25)
+	usage.Record("key_2", "", "GET", "/api/v1/users/{id}", 0, 10)
+	if err := usage.Flush(ctx); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+
+	today := time.Now()
+	records, err := usage.Report(ctx, repositories.UsageFilter{From: today, To: today, KeyID: "key_1"})
+	if err != nil {
+		t.Fatalf("Report() unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Report() returned %d records, want 2", len(records))
+	}
+	if got := records[0]; got.Method != "GET" || got.Requests != 3 || got.BytesOut != 175 || !got.Day.Equal(entities.UsageDay(today)) {
+		t.Errorf("GET rollup = %+v, want 3 requests and 175 bytes out today", got)
+	}
+	if got := records[1]; got.Method != "POST" || got.Requests != 1 || got.BytesIn != 40 {
+		t.Errorf("POST rollup = %+v, want 1 request and 40 bytes in", got)
+	}
+
+	all, err := usage.Report(ctx, repositories.UsageFilter{From: today.AddDate(0, 0, -30), To: today})
+	if err != nil {
+		t.Fatalf("Report() unexpected error: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Report() without key returned %d records, want 3", len(all))
+	}
+}
+
+func TestUsageUseCase_FlushKeepsCallsWhenStoreFails(t *testing.T) {
+	ctx := context.Background()
+	repo := &failingUsageRepository{UsageRepository: database.NewMockUsageRepository(), failing: true}
+	usage := NewUsageUseCase(repo, logger.New())
+
+	usage.Record("key_1", "", "GET", "/api/v1/users", 0, 100)
+	if err := usage.Flush(ctx); err == nil {
+		t.Fatal("Flush() expected an error while the store fails")
+	}
+	usage.Record("key_1", "", "GET", "/api/v1/users", 0, 100)
+
+	repo.failing = false
+	if err := usage.Flush(ctx); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+	today := time.Now()
+	records, err := usage.Report(ctx, repositories.UsageFilter{From: today, To: today})
+	if err != nil {
+		t.Fatalf("Report() unexpected error: %v", err)
+	}
+	if len(records) != 1 || records[0].Requests != 2 || records[0].BytesOut != 200 {
+		t.Errorf("Report() = %+v, want both calls in one rollup", records)
+	}
+}
+
+func TestUsageUseCase_Report_InvalidRange(t *testing.T) {
+	usage := NewUsageUseCase(database.NewMockUsageRepository(), logger.New())
+	today := time.Now()
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+	}{
+		{"ends before it starts", today, today.AddDate(0, 0, -1)},
+		{"longer than a year", today.AddDate(0, 0, -366), today},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := usage.Report(context.Background(), repositories.UsageFilter{From: tt.from, To: tt.to}); !errors.Is(err, ErrInvalidUsageRange) {
+				t.Errorf("Report() error = %v, want ErrInvalidUsageRange", err)
+			}
+		})
+	}
+	if _, err := usage.Report(context.Background(), repositories.UsageFilter{From: today.AddDate(0, 0, -365), To: today}); err != nil {
+		t.Errorf("Report() over 366 days unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// JobTypeUserCreate is the job type of user creations run in the background
+const JobTypeUserCreate = "users.create"
+
+// UserCreationUseCase creates users as background jobs, so requests do not
+// wait for the side effects of creating them
+type UserCreationUseCase struct {
+	users   UserUseCaseInterface
+	jobRepo repositories.JobRepository
+	queue   JobQueue
+	logger  logger.Logger
+}
+
+// NewUserCreationUseCase creates a new user creation use case instance.
+// Users are created through users so they are validated and announced like
+// any other user.
+func NewUserCreationUseCase(users UserUseCaseInterface, jobRepo repositories.JobRepository, queue JobQueue, logger logger.Logger) *UserCreationUseCase {
+	return &UserCreationUseCase{
+		users:   users,
+		jobRepo: jobRepo,
+		queue:   queue,
+		logger:  logger,
+	}
+}
+
+// RequestCreation queues the creation of a user. Whether the email is taken
+// or the quota is reached is only known once the job ran.
+func (uc *UserCreationUseCase) RequestCreation(ctx context.Context, email, name, requestedBy string) (*entities.Job, error) {
+	if email == "" {
+		return nil, ErrEmailRequired
+	}
+	if name == "" {
+		return nil, ErrNameRequired
+	}
+
+	job := entities.NewJob(JobTypeUserCreate, requestedBy, map[string]string{"email": email, "name": name})
+	if err := uc.jobRepo.Create(ctx, job); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to create user creation job")
+		return nil, fmt.Errorf("failed to create user creation job: %w", err)
+	}
+
+	if err := uc.queue.Enqueue(ctx, job); err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"job_id": job.ID,
+			"error":  err.Error(),
+		}).Error("Failed to enqueue user creation job")
+
+		job.Fail("could not be queued", time.Now())
+		if updateErr := uc.jobRepo.Update(ctx, job); updateErr != nil {
+			uc.logger.WithField("error", updateErr.Error()).Error("Failed to record user creation job failure")
+		}
+		return nil, fmt.Errorf("failed to enqueue user creation job: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"job_id": job.ID,
+		"email":  email,
+	}).Info("User creation queued")
+	return job, nil
+}
+
+// RunCreation creates the user of a creation job. It is called by the job
+// workers and may run again for the same job when an attempt fails.
+func (uc *UserCreationUseCase) RunCreation(ctx context.Context, id string) error {
+	job, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get user creation job: %w", err)
+	}
+	if job.IsFinished() {
+		return nil
+	}
+
+	job.Start(time.Now())
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to start user creation job: %w", err)
+	}
+
+	user, err := uc.users.CreateUser(ctx, job.Params["email"], job.Params["name"])
+	switch {
+	case err == nil:
+	case errors.Is(err, repositories.ErrUserAlreadyExists), errors.Is(err, ErrEmailRequired),
+		errors.Is(err, ErrNameRequired), errors.Is(err, ErrQuotaExceeded):
+		// Another attempt would fail the same way, so the job fails for good
+		job.Fail(err.Error(), time.Now())
+		if updateErr := uc.jobRepo.Update(ctx, job); updateErr != nil {
+			return fmt.Errorf("failed to record user creation job failure: %w", updateErr)
+		}
+		uc.logger.WithFields(map[string]interface{}{
+			"job_id": job.ID,
+			"error":  err.Error(),
+		}).Info("User creation rejected")
+		return nil
+	default:
+		// Clients see the job's error, so internal details stay in the log
+		job.Fail("creation failed", time.Now())
+		if updateErr := uc.jobRepo.Update(ctx, job); updateErr != nil {
+			uc.logger.WithField("error", updateErr.Error()).Error("Failed to record user creation job failure")
+		}
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	job.Complete(map[string]string{"user_id": user.ID}, time.Now())
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		return fmt.Errorf("failed to complete user creation job: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"job_id":  job.ID,
+		"user_id": user.ID,
+	}).Info("User creation completed")
+	return nil
+}
@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// UserCreationUseCaseInterface defines the interface for user creations run
+// in the background
+type UserCreationUseCaseInterface interface {
+	RequestCreation(ctx context.Context, email, name, requestedBy string) (*entities.Job, error)
+}
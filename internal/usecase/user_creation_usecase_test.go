@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+type userCreationFixture struct {
+	userRepo repositories.UserRepository
+	jobRepo  repositories.JobRepository
+	queue    *recordingJobQueue
+	users    *flakyUserUseCase
+	useCase  *UserCreationUseCase
+}
+
+func newUserCreationFixture() *userCreationFixture {
+	f := &userCreationFixture{
+		userRepo: database.NewMockUserRepository(),
+		jobRepo:  database.NewMockJobRepository(),
+		queue:    &recordingJobQueue{},
+	}
+	f.users = &flakyUserUseCase{UserUseCaseInterface: NewUserUseCase(f.userRepo, nil, logger.New()), failAfter: -1}
+	f.useCase = NewUserCreationUseCase(f.users, f.jobRepo, f.queue, logger.New())
+	return f
+}
+
+func TestUserCreationUseCase_RequestCreation(t *testing.T) {
+	f := newUserCreationFixture()
+	ctx := context.Background()
+
+	job, err := f.useCase.RequestCreation(ctx, "ada@example.com", "Ada", "user_1")
+	if err != nil {
+		t.Fatalf("RequestCreation() unexpected error: %v", err)
+	}
+	if job.Type != JobTypeUserCreate || job.Status != entities.JobQueued || job.RequestedBy != "user_1" {
+		t.Errorf("RequestCreation() job = %+v, want a queued users.create job of user_1", job)
+	}
+	if len(f.queue.jobs) != 1 || f.queue.jobs[0] != job.ID {
+		t.Errorf("enqueued %v, want [%s]", f.queue.jobs, job.ID)
+	}
+	if users, _ := f.userRepo.List(ctx, 10, 0); len(users) != 0 {
+		t.Errorf("RequestCreation() created %d users before the job ran", len(users))
+	}
+
+	if _, err := f.useCase.RequestCreation(ctx, "", "Ada", ""); !errors.Is(err, ErrEmailRequired) {
+		t.Errorf("RequestCreation() error = %v, want ErrEmailRequired", err)
+	}
+	if _, err := f.useCase.RequestCreation(ctx, "ada@example.com", "", ""); !errors.Is(err, ErrNameRequired) {
+		t.Errorf("RequestCreation() error = %v, want ErrNameRequired", err)
+	}
+}
+
+func TestUserCreationUseCase_RequestCreation_QueueFailure(t *testing.T) {
+	f := newUserCreationFixture()
+	f.queue.err = errors.New("broker closed")
+
+	if _, err := f.useCase.RequestCreation(context.Background(), "ada@example.com", "Ada", ""); err == nil {
+		t.Fatalf("RequestCreation() expected error but got none")
+	}
+}
+
+func TestUserCreationUseCase_RunCreation(t *testing.T) {
+	f := newUserCreationFixture()
+	ctx := context.Background()
+
+	job, err := f.useCase.RequestCreation(ctx, "ada@example.com", "Ada", "")
+	if err != nil {
+		t.Fatalf("RequestCreation() unexpected error: %v", err)
+	}
+	if err := f.useCase.RunCreation(ctx, job.ID); err != nil {
+		t.Fatalf("RunCreation() unexpected error: %v", err)
+	}
+
+	job, _ = f.jobRepo.GetByID(ctx, job.ID)
+	if job.Status != entities.JobSucceeded || job.ExpiresAt != nil {
+		t.Fatalf("job = %+v, want succeeded without expiry", job)
+	}
+	user, err := f.userRepo.GetByID(ctx, job.Result["user_id"])
+	if err != nil || user.Email != "ada@example.com" {
+		t.Errorf("created user = %+v, %v, want ada@example.com", user, err)
+	}
+
+	// A redelivered job does not create the user again
+	if err := f.useCase.RunCreation(ctx, job.ID); err != nil {
+		t.Errorf("RunCreation() of a finished job unexpected error: %v", err)
+	}
+}
+
+func TestUserCreationUseCase_RunCreation_Failures(t *testing.T) {
+	f := newUserCreationFixture()
+	ctx := context.Background()
+	if _, err := f.users.CreateUser(ctx, "ada@example.com", "Ada"); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	// Users that already exist fail the job without a retry
+	taken, _ := f.useCase.RequestCreation(ctx, "ada@example.com", "Ada", "")
+	if err := f.useCase.RunCreation(ctx, taken.ID); err != nil {
+		t.Fatalf("RunCreation() unexpected error: %v", err)
+	}
+	taken, _ = f.jobRepo.GetByID(ctx, taken.ID)
+	if taken.Status != entities.JobFailed || taken.Error != repositories.ErrUserAlreadyExists.Error() {
+		t.Errorf("job = %+v, want failed with %q", taken, repositories.ErrUserAlreadyExists)
+	}
+
+	// Other errors are returned so the attempt is retried, without leaking
+	// their details to clients
+	f.users.calls, f.users.failAfter = 0, 0
+	flaky, _ := f.useCase.RequestCreation(ctx, "grace@example.com", "Grace", "")
+	if err := f.useCase.RunCreation(ctx, flaky.ID); err == nil {
+		t.Fatalf("RunCreation() expected error but got none")
+	}
+	flaky, _ = f.jobRepo.GetByID(ctx, flaky.ID)
+	if flaky.Status != entities.JobFailed || flaky.Error != "creation failed" {
+		t.Errorf("job = %+v, want failed with %q", flaky, "creation failed")
+	}
+
+	if err := f.useCase.RunCreation(ctx, flaky.ID); err != nil {
+		t.Fatalf("RunCreation() retry unexpected error: %v", err)
+	}
+	flaky, _ = f.jobRepo.GetByID(ctx, flaky.ID)
+	if flaky.Status != entities.JobSucceeded || flaky.Attempts != 2 {
+		t.Errorf("job = %+v, want succeeded on the second attempt", flaky)
+	}
+}
@@ -0,0 +1,295 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/logger"
+)
+
+// Who cancelled a deletion request
+const (
+	CancelledByUser  = "user"
+	CancelledByAdmin = "admin"
+	CancelledByToken = "link"
+)
+
+// purgeBatchSize is the number of due deletions purged per query
+const purgeBatchSize = 100
+
+// DeletionNotifier tells users about their scheduled deletion
+type DeletionNotifier interface {
+	// DeletionScheduled is sent once when a deletion is scheduled.
+	// cancelURL aborts the deletion without signing in.
+	DeletionScheduled(ctx context.Context, user *entities.User, deletion *entities.UserDeletion, cancelURL string) error
+}
+
+// UserDeletionUseCase schedules user deletions after a grace period and
+// purges them once it has passed
+type UserDeletionUseCase struct {
+	userRepo     repositories.UserRepository
+	deletionRepo repositories.UserDeletionRepository
+	notifier     DeletionNotifier
+	publisher    events.Publisher
+	gracePeriod  time.Duration
+	cancelURL    string
+	logger       logger.Logger
+}
+
+// NewUserDeletionUseCase creates a new user deletion use case instance.
+// Cancellation links point at cancelURL with the token appended as a query
+// parameter. publisher may be nil to disable domain events.
+func NewUserDeletionUseCase(userRepo repositories.UserRepository, deletionRepo repositories.UserDeletionRepository, notifier DeletionNotifier, publisher events.Publisher, gracePeriod time.Duration, cancelURL string, logger logger.Logger) *UserDeletionUseCase {
+	return &UserDeletionUseCase{
+		userRepo:     userRepo,
+		deletionRepo: deletionRepo,
+		notifier:     notifier,
+		publisher:    publisher,
+		gracePeriod:  gracePeriod,
+		cancelURL:    cancelURL,
+		logger:       logger,
+	}
+}
+
+// ScheduleDeletion schedules a user for deletion once the grace period has
+// passed and sends them a cancellation link. Scheduling an already pending
+// deletion returns the existing request.
+func (uc *UserDeletionUseCase) ScheduleDeletion(ctx context.Context, userID string) (*entities.UserDeletion, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, repositories.ErrUserNotFound
+	}
+
+	existing, err := uc.deletionRepo.GetPendingByUserID(ctx, userID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, repositories.ErrUserDeletionNotFound) {
+		return nil, fmt.Errorf("failed to get deletion request: %w", err)
+	}
+
+	token, err := generateLinkToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate cancellation token: %w", err)
+	}
+
+	deletion := entities.NewUserDeletion(userID, hashLinkToken(token), uc.gracePeriod)
+	if err := uc.deletionRepo.Create(ctx, deletion); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to schedule user deletion")
+		return nil, fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+
+	// The request stands even if the notification fails; the user can
+	// still cancel it while signed in
+	if err := uc.notifier.DeletionScheduled(ctx, user, deletion, linkWithToken(uc.cancelURL, token)); err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		}).Error("Failed to send deletion cancellation link")
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":     userID,
+		"deletion_id": deletion.ID,
+		"purge_after": deletion.PurgeAfter,
+	}).Info("User deletion scheduled")
+	uc.publish(ctx, events.NewEvent(events.UserDeletionScheduled, userID, deletion))
+	return deletion, nil
+}
+
+// GetPendingDeletion retrieves the pending deletion request of a user
+func (uc *UserDeletionUseCase) GetPendingDeletion(ctx context.Context, userID string) (*entities.UserDeletion, error) {
+	deletion, err := uc.deletionRepo.GetPendingByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deletion request: %w", err)
+	}
+	return deletion, nil
+}
+
+// GetPendingDeletions retrieves the pending deletion requests of several
+// users at once, keyed by user ID. Users without one are absent.
+func (uc *UserDeletionUseCase) GetPendingDeletions(ctx context.Context, userIDs []string) (map[string]*entities.UserDeletion, error) {
+	deletions, err := uc.deletionRepo.ListPendingByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deletion requests: %w", err)
+	}
+	byUser := make(map[string]*entities.UserDeletion, len(deletions))
+	for _, deletion := range deletions {
+		byUser[deletion.UserID] = deletion
+	}
+	return byUser, nil
+}
+
+// ListPendingDeletions retrieves pending deletion requests, soonest purge
+// first
+func (uc *UserDeletionUseCase) ListPendingDeletions(ctx context.Context, limit, offset int) ([]*entities.UserDeletion, error) {
+	deletions, err := uc.deletionRepo.ListPending(ctx, limit, offset)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to list deletion requests")
+		return nil, fmt.Errorf("failed to list deletion requests: %w", err)
+	}
+	return deletions, nil
+}
+
+// CancelDeletion cancels the pending deletion request of a user
+func (uc *UserDeletionUseCase) CancelDeletion(ctx context.Context, userID string) (*entities.UserDeletion, error) {
+	deletion, err := uc.deletionRepo.GetPendingByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deletion request: %w", err)
+	}
+	return uc.cancel(ctx, deletion, CancelledByUser)
+}
+
+// CancelDeletionByToken cancels the pending deletion request a cancellation
+// link was issued for
+func (uc *UserDeletionUseCase) CancelDeletionByToken(ctx context.Context, token string) (*entities.UserDeletion, error) {
+	deletion, err := uc.deletionRepo.GetPendingByTokenHash(ctx, hashLinkToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deletion request: %w", err)
+	}
+	return uc.cancel(ctx, deletion, CancelledByToken)
+}
+
+// CancelDeletionByID cancels a pending deletion request on behalf of an admin
+func (uc *UserDeletionUseCase) CancelDeletionByID(ctx context.Context, id string) (*entities.UserDeletion, error) {
+	deletion, err := uc.deletionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deletion request: %w", err)
+	}
+	if deletion.Status() != entities.UserDeletionPending {
+		return nil, repositories.ErrUserDeletionNotFound
+	}
+	return uc.cancel(ctx, deletion, CancelledByAdmin)
+}
+
+// PurgeDue deletes every user whose grace period ended before now and
+// returns how many were purged. A failed purge is left pending and retried
+// on the next run.
+func (uc *UserDeletionUseCase) PurgeDue(ctx context.Context, now time.Time) (int, error) {
+	purged := 0
+	for {
+		due, err := uc.deletionRepo.ListDue(ctx, now, purgeBatchSize)
+		if err != nil {
+			return purged, fmt.Errorf("failed to list due deletions: %w", err)
+		}
+
+		failed := 0
+		for _, deletion := range due {
+			if err := uc.purge(ctx, deletion, now); err != nil {
+				uc.logger.WithFields(map[string]interface{}{
+					"user_id":     deletion.UserID,
+					"deletion_id": deletion.ID,
+					"error":       err.Error(),
+				}).Error("Failed to purge user")
+				failed++
+				continue
+			}
+			purged++
+		}
+
+		// Stop once a batch came back short, or held only requests that
+		// keep failing and would be listed again
+		if len(due) < purgeBatchSize || failed == len(due) {
+			return purged, nil
+		}
+	}
+}
+
+func (uc *UserDeletionUseCase) purge(ctx context.Context, deletion *entities.UserDeletion, now time.Time) error {
+	// The deletion event carries the user as they were, nil when they
+	// were deleted already
+	user, err := uc.userRepo.GetByID(ctx, deletion.UserID)
+	if err != nil {
+		return err
+	}
+	err = uc.userRepo.Delete(ctx, deletion.UserID)
+	if err != nil && !errors.Is(err, repositories.ErrUserNotFound) {
+		return err
+	}
+
+	deletion.Complete(now)
+	if err := uc.deletionRepo.Update(ctx, deletion); err != nil {
+		return err
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":     deletion.UserID,
+		"deletion_id": deletion.ID,
+	}).Info("User purged after deletion grace period")
+	uc.publish(ctx, events.NewEvent(events.UserDeleted, deletion.UserID, user))
+	return nil
+}
+
+func (uc *UserDeletionUseCase) cancel(ctx context.Context, deletion *entities.UserDeletion, by string) (*entities.UserDeletion, error) {
+	deletion.Cancel(by, time.Now())
+	if err := uc.deletionRepo.Update(ctx, deletion); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to cancel user deletion")
+		return nil, fmt.Errorf("failed to cancel deletion: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":      deletion.UserID,
+		"deletion_id":  deletion.ID,
+		"cancelled_by": by,
+	}).Info("User deletion cancelled")
+	uc.publish(ctx, events.NewEvent(events.UserDeletionCancelled, deletion.UserID, deletion))
+	return deletion, nil
+}
+
+// linkWithToken returns the emailed link to base carrying token as its
+// token query parameter
+func linkWithToken(base, token string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// publish emits a domain event. Failures are logged rather than returned
+// because the change has already been committed.
+func (uc *UserDeletionUseCase) publish(ctx context.Context, event events.Event) {
+	if uc.publisher == nil {
+		return
+	}
+	event.CorrelationID = correlation.ID(ctx)
+	if err := uc.publisher.Publish(ctx, event); err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"event_type": event.Type,
+			"event_id":   event.ID,
+			"error":      err.Error(),
+		}).Error("Failed to publish domain event")
+	}
+}
+
+// generateLinkToken returns a random token for an emailed link, such as a
+// deletion cancellation link
+func generateLinkToken() (string, error) {
+	randBytes := make([]byte, 32)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(randBytes), nil
+}
+
+// hashLinkToken returns the stored form of a link token
+func hashLinkToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// UserDeletionUseCaseInterface defines the interface for scheduled user deletions
+type UserDeletionUseCaseInterface interface {
+	ScheduleDeletion(ctx context.Context, userID string) (*entities.UserDeletion, error)
+	GetPendingDeletion(ctx context.Context, userID string) (*entities.UserDeletion, error)
+	ListPendingDeletions(ctx context.Context, limit, offset int) ([]*entities.UserDeletion, error)
+	GetPendingDeletions(ctx context.Context, userIDs []string) (map[string]*entities.UserDeletion, error)
+	CancelDeletion(ctx context.Context, userID string) (*entities.UserDeletion, error)
+	CancelDeletionByToken(ctx context.Context, token string) (*entities.UserDeletion, error)
+	CancelDeletionByID(ctx context.Context, id string) (*entities.UserDeletion, error)
+	PurgeDue(ctx context.Context, now time.Time) (int, error)
+}
@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+// recordingDeletionNotifier captures the cancellation links sent to users
+type recordingDeletionNotifier struct {
+	links []string
+}
+
+func (n *recordingDeletionNotifier) DeletionScheduled(ctx context.Context, user *entities.User, deletion *entities.UserDeletion, cancelURL string) error {
+	n.links = append(n.links, cancelURL)
+	return nil
+}
+
+type deletionFixture struct {
+	userRepo     repositories.UserRepository
+	deletionRepo repositories.UserDeletionRepository
+	notifier     *recordingDeletionNotifier
+	publisher    *recordingPublisher
+	useCase      *UserDeletionUseCase
+	user         *entities.User
+}
+
+func newDeletionFixture(t *testing.T) *deletionFixture {
+	t.Helper()
+	f := &deletionFixture{
+		userRepo:     database.NewMockUserRepository(),
+		deletionRepo: database.NewMockUserDeletionRepository(),
+		notifier:     &recordingDeletionNotifier{},
+		publisher:    &recordingPublisher{},
+	}
+	f.useCase = NewUserDeletionUseCase(f.userRepo, f.deletionRepo, f.notifier, f.publisher,
+		24*time.Hour, "https://app.example.com/account/restore", logger.New())
+
+	f.user = entities.NewUser("test@example.com", "Test User")
+	if err := f.userRepo.Create(context.Background(), f.user); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	return f
+}
+
+// cancelToken extracts the token from the most recent cancellation link
+func (f *deletionFixture) cancelToken(t *testing.T) string {
+	t.Helper()
+	if len(f.notifier.links) == 0 {
+		t.Fatalf("no cancellation link was sent")
+	}
+	u, err := url.Parse(f.notifier.links[len(f.notifier.links)-1])
+	if err != nil {
+		t.Fatalf("parse cancellation link: %v", err)
+	}
+	return u.Query().Get("token")
+}
+
+func TestUserDeletionUseCase_ScheduleDeletion(t *testing.T) {
+	f := newDeletionFixture(t)
+	ctx := context.Background()
+
+	deletion, err := f.useCase.ScheduleDeletion(ctx, f.user.ID)
+	if err != nil {
+		t.Fatalf("ScheduleDeletion() unexpected error: %v", err)
+	}
+	if deletion.Status() != entities.UserDeletionPending {
+		t.Errorf("ScheduleDeletion() status = %v, want pending", deletion.Status())
+	}
+	if got := deletion.PurgeAfter.Sub(deletion.RequestedAt); got != 24*time.Hour {
+		t.Errorf("ScheduleDeletion() grace period = %v, want 24h", got)
+	}
+
+	// The user is kept until the purge
+	if _, err := f.userRepo.GetByID(ctx, f.user.ID); err != nil {
+		t.Errorf("user was deleted before the grace period ended: %v", err)
+	}
+
+	token := f.cancelToken(t)
+	if token == "" || deletion.CancelTokenHash == token {
+		t.Errorf("cancellation token must be sent and stored only as a hash")
+	}
+
+	// Scheduling again returns the pending request without a second email
+	again, err := f.useCase.ScheduleDeletion(ctx, f.user.ID)
+	if err != nil {
+		t.Fatalf("ScheduleDeletion() repeat unexpected error: %v", err)
+	}
+	if again.ID != deletion.ID {
+		t.Errorf("ScheduleDeletion() repeat ID = %v, want %v", again.ID, deletion.ID)
+	}
+	if len(f.notifier.links) != 1 {
+		t.Errorf("sent %d cancellation links, want 1", len(f.notifier.links))
+	}
+
+	if _, err := f.useCase.ScheduleDeletion(ctx, "missing"); !errors.Is(err, repositories.ErrUserNotFound) {
+		t.Errorf("ScheduleDeletion() unknown user error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestUserDeletionUseCase_Cancel(t *testing.T) {
+	tests := []struct {
+		name   string
+		cancel func(f *deletionFixture, deletion *entities.UserDeletion) (*entities.UserDeletion, error)
+		by     string
+	}{
+		{
+			name: "by user",
+			cancel: func(f *deletionFixture, deletion *entities.UserDeletion) (*entities.UserDeletion, error) {
+				return f.useCase.CancelDeletion(context.Background(), deletion.UserID)
+			},
+			by: CancelledByUser,
+		},
+		{
+			name: "by link",
+			cancel: func(f *deletionFixture, deletion *entities.UserDeletion) (*entities.UserDeletion, error) {
+				return f.useCase.CancelDeletionByToken(context.Background(), f.cancelToken(t))
+			},
+			by: CancelledByToken,
+		},
+		{
+			name: "by admin",
+			cancel: func(f *deletionFixture, deletion *entities.UserDeletion) (*entities.UserDeletion, error) {
+				return f.useCase.CancelDeletionByID(context.Background(), deletion.ID)
+			},
+			by: CancelledByAdmin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newDeletionFixture(t)
+			deletion, err := f.useCase.ScheduleDeletion(context.Background(), f.user.ID)
+			if err != nil {
+				t.Fatalf("ScheduleDeletion() unexpected error: %v", err)
+			}
+
+			cancelled, err := tt.cancel(f, deletion)
+			if err != nil {
+				t.Fatalf("cancel unexpected error: %v", err)
+			}
+			if cancelled.Status() != entities.UserDeletionCancelled || cancelled.CancelledBy != tt.by {
+				t.Errorf("cancel status = %v by %v, want cancelled by %v", cancelled.Status(), cancelled.CancelledBy, tt.by)
+			}
+
+			// A cancelled request cannot be cancelled again
+			if _, err := tt.cancel(f, deletion); !errors.Is(err, repositories.ErrUserDeletionNotFound) {
+				t.Errorf("second cancel error = %v, want ErrUserDeletionNotFound", err)
+			}
+
+			// Nothing is purged once cancelled
+			purged, err := f.useCase.PurgeDue(context.Background(), time.Now().Add(48*time.Hour))
+			if err != nil || purged != 0 {
+				t.Errorf("PurgeDue() = %d, %v, want 0, nil", purged, err)
+			}
+		})
+	}
+}
+
+func TestUserDeletionUseCase_GetPendingDeletions(t *testing.T) {
+	f := newDeletionFixture(t)
+	other := entities.NewUser("other@example.com", "Other User")
+	if err := f.userRepo.Create(context.Background(), other); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	deletion, err := f.useCase.ScheduleDeletion(context.Background(), f.user.ID)
+	if err != nil {
+		t.Fatalf("ScheduleDeletion() unexpected error: %v", err)
+	}
+
+	byUser, err := f.useCase.GetPendingDeletions(context.Background(), []string{f.user.ID, other.ID})
+	if err != nil {
+		t.Fatalf("GetPendingDeletions() unexpected error: %v", err)
+	}
+	if len(byUser) != 1 || byUser[f.user.ID] == nil || byUser[f.user.ID].ID != deletion.ID {
+		t.Errorf("GetPendingDeletions() = %v, want only the deletion of %s", byUser, f.user.ID)
+	}
+}
+
+func TestUserDeletionUseCase_CancelDeletionByToken_Invalid(t *testing.T) {
+	f := newDeletionFixture(t)
+	if _, err := f.useCase.ScheduleDeletion(context.Background(), f.user.ID); err != nil {
+		t.Fatalf("ScheduleDeletion() unexpected error: %v", err)
+	}
+
+	if _, err := f.useCase.CancelDeletionByToken(context.Background(), "forged"); !errors.Is(err, repositories.ErrUserDeletionNotFound) {
+		t.Errorf("CancelDeletionByToken() error = %v, want ErrUserDeletionNotFound", err)
+	}
+}
+
+func TestUserDeletionUseCase_PurgeDue(t *testing.T) {
+	f := newDeletionFixture(t)
+	ctx := context.Background()
+
+	deletion, err := f.useCase.ScheduleDeletion(ctx, f.user.ID)
+	if err != nil {
+		t.Fatalf("ScheduleDeletion() unexpected error: %v", err)
+	}
+
+	// Nothing is due before the grace period ends
+	purged, err := f.useCase.PurgeDue(ctx, time.Now())
+	if err != nil || purged != 0 {
+		t.Fatalf("PurgeDue() before grace period = %d, %v, want 0, nil", purged, err)
+	}
+
+	purged, err = f.useCase.PurgeDue(ctx, deletion.PurgeAfter.Add(time.Second))
+	if err != nil || purged != 1 {
+		t.Fatalf("PurgeDue() = %d, %v, want 1, nil", purged, err)
+	}
+
+	if user, _ := f.userRepo.GetByID(ctx, f.user.ID); user != nil {
+		t.Errorf("user still exists after purge")
+	}
+	stored, _ := f.deletionRepo.GetByID(ctx, deletion.ID)
+	if stored.Status() != entities.UserDeletionCompleted {
+		t.Errorf("deletion status = %v, want completed", stored.Status())
+	}
+
+	want := []string{events.UserDeletionScheduled, events.UserDeleted}
+	if len(f.publisher.events) != len(want) {
+		t.Fatalf("published %d events, want %d", len(f.publisher.events), len(want))
+	}
+	for i, event := range f.publisher.events {
+		if event.Type != want[i] {
+			t.Errorf("event %d type = %v, want %v", i, event.Type, want[i])
+		}
+	}
+}
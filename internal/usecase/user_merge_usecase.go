@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/fuzzy"
+	"clean-architecture/pkg/logger"
+)
+
+// domainAliases maps email domains to the domain they are another name
+// of, so duplicates registered under either are found together
+var domainAliases = map[string]string{
+	"googlemail.com": "gmail.com",
+}
+
+// UserMergeOptions tunes duplicate detection
+type UserMergeOptions struct {
+	// Threshold is the name similarity, from 0 to 1, above which users of
+	// the same email domain are reported as probable duplicates unless a
+	// search asks for another
+	Threshold float64
+	// MaxGroupSize is the most users of one email domain whose names are
+	// compared with each other; larger domains are skipped, as comparing
+	// every pair of them would take too long
+	MaxGroupSize int
+	// PageSize is how many users each query of a search reads
+	PageSize int
+}
+
+// DuplicateUsers is a pair of users who are probably the same person: they
+// have emails of the same domain and names at least as similar as the
+// threshold. The older user comes first.
+type DuplicateUsers struct {
+	Domain string
+	Score  float64
+	Users  [2]*entities.User
+}
+
+// UserMergeUseCase finds users who registered twice and merges their
+// accounts. Merges are recorded in the audit log through the user.merged
+// event.
+type UserMergeUseCase struct {
+	userRepo  repositories.UserRepository
+	mergeRepo repositories.UserMergeRepository
+	publisher events.Publisher
+	opts      UserMergeOptions
+	logger    logger.Logger
+}
+
+// NewUserMergeUseCase creates a new user merge use case. publisher may be
+// nil to disable domain events.
+func NewUserMergeUseCase(userRepo repositories.UserRepository, mergeRepo repositories.UserMergeRepository, publisher events.Publisher, opts UserMergeOptions, logger logger.Logger) *UserMergeUseCase {
+	if opts.Threshold <= 0 {
+		opts.Threshold = 0.9
+	}
+	if opts.MaxGroupSize <= 0 {
+		opts.MaxGroupSize = 2000
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 1000
+	}
+	return &UserMergeUseCase{
+		userRepo:  userRepo,
+		mergeRepo: mergeRepo,
+		publisher: publisher,
+		opts:      opts,
+		logger:    logger,
+	}
+}
+
+// FindDuplicates returns the pairs of users whose emails have the same
+// domain and whose names are at least threshold similar, most similar
+// first. A zero threshold uses the configured one. Service accounts are
+// left out.
+func (uc *UserMergeUseCase) FindDuplicates(ctx context.Context, threshold float64) ([]*DuplicateUsers, error) {
+	if threshold == 0 {
+		threshold = uc.opts.Threshold
+	}
+	if threshold <= 0 || threshold > 1 {
+		return nil, ErrInvalidDuplicateThreshold
+	}
+
+	groups := make(map[string][]*entities.User)
+	err := eachUser(ctx, uc.userRepo, uc.opts.PageSize, func(user *entities.User) {
+		if domain := emailDomain(user.Email); domain != "" {
+			groups[domain] = append(groups[domain], user)
+		}
+	})
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to read users for duplicates")
+		return nil, fmt.Errorf("failed to find duplicate users: %w", err)
+	}
+
+	var duplicates []*DuplicateUsers
+	for domain, users := range groups {
+		if len(users) > uc.opts.MaxGroupSize {
+			uc.logger.WithFields(map[string]interface{}{
+				"domain": domain,
+				"users":  len(users),
+			}).Warn("Skipped email domain with too many users to compare")
+			continue
+		}
+		sort.Slice(users, func(i, j int) bool { return olderUser(users[i], users[j]) })
+		for i, a := range users {
+			for _, b := range users[i+1:] {
+				if score := fuzzy.Similarity(a.Name, b.Name); score >= threshold {
+					duplicates = append(duplicates, &DuplicateUsers{Domain: domain, Score: score, Users: [2]*entities.User{a, b}})
+				}
+			}
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Score != duplicates[j].Score {
+			return duplicates[i].Score > duplicates[j].Score
+		}
+		if duplicates[i].Users[0] != duplicates[j].Users[0] {
+			return olderUser(duplicates[i].Users[0], duplicates[j].Users[0])
+		}
+		return olderUser(duplicates[i].Users[1], duplicates[j].Users[1])
+	})
+	return duplicates, nil
+}
+
+// Merge merges loserID into winnerID: the winner takes over the loser's
+// saved views, and the loser is deleted along with their pending deletion
+// request and email change. The winner's own details are kept. It
+// publishes user.merged for the winner and user.deleted for the loser.
+func (uc *UserMergeUseCase) Merge(ctx context.Context, winnerID, loserID string) (*entities.UserMerge, error) {
+	winnerID, loserID = strings.TrimSpace(winnerID), strings.TrimSpace(loserID)
+	if winnerID == "" || loserID == "" {
+		return nil, ErrMergeUsersRequired
+	}
+	if winnerID == loserID {
+		return nil, ErrMergeSameUser
+	}
+
+	var loser *entities.User
+	for _, id := range []string{winnerID, loserID} {
+		user, err := uc.userRepo.GetByID(ctx, id)
+		if err != nil {
+			uc.logger.WithField("error", err.Error()).Error("Failed to get user to merge")
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if user == nil {
+			return nil, repositories.ErrUserNotFound
+		}
+		if user.IsServiceAccount() {
+			return nil, ErrMergeServiceAccount
+		}
+		loser = user
+	}
+
+	merge := entities.NewUserMerge(winnerID, loserID)
+	if err := uc.mergeRepo.Merge(ctx, merge); err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return nil, err
+		}
+		uc.logger.WithField("error", err.Error()).Error("Failed to merge users")
+		return nil, fmt.Errorf("failed to merge users: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"winner_id":                merge.WinnerID,
+		"loser_id":                 merge.LoserID,
+		"saved_views":              merge.SavedViews,
+		"cancelled_deletions":      merge.CancelledDeletions,
+		"superseded_email_changes": merge.SupersededEmailChanges,
+	}).Info("Users merged")
+	uc.publish(ctx, events.NewEvent(events.UserMerged, merge.WinnerID, merge))
+	uc.publish(ctx, events.NewEvent(events.UserDeleted, merge.LoserID, loser))
+	return merge, nil
+}
+
+// publish emits a domain event. Failures are logged rather than returned
+// because the merge has already been committed.
+func (uc *UserMergeUseCase) publish(ctx context.Context, event events.Event) {
+	if uc.publisher == nil {
+		return
+	}
+	event.CorrelationID = correlation.ID(ctx)
+	if err := uc.publisher.Publish(ctx, event); err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"event_type": event.Type,
+			"event_id":   event.ID,
+			"error":      err.Error(),
+		}).Error("Failed to publish domain event")
+	}
+}
+
+// emailDomain returns the lowercased domain of email, as the domain it is
+// an alias of if any, or "" for addresses without one
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(email[at+1:])), ".")
+	if alias, ok := domainAliases[domain]; ok {
+		return alias
+	}
+	return domain
+}
+
+// olderUser reports whether a was created before b, by ID when they were
+// created at the same time
+func olderUser(a, b *entities.User) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// UserMergeUseCaseInterface defines the interface for finding and merging
+// duplicate users
+type UserMergeUseCaseInterface interface {
+	FindDuplicates(ctx context.Context, threshold float64) ([]*DuplicateUsers, error)
+	Merge(ctx context.Context, winnerID, loserID string) (*entities.UserMerge, error)
+}
@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+type mergeFixture struct {
+	userRepo     repositories.UserRepository
+	deletionRepo repositories.UserDeletionRepository
+	publisher    *recordingPublisher
+	useCase      *UserMergeUseCase
+}
+
+func newMergeFixture(t *testing.T, users ...*entities.User) *mergeFixture {
+	t.Helper()
+	f := &mergeFixture{
+		userRepo:     database.NewMockUserRepository(),
+		deletionRepo: database.NewMockUserDeletionRepository(),
+		publisher:    &recordingPublisher{},
+	}
+	f.useCase = NewUserMergeUseCase(f.userRepo, database.NewMockUserMergeRepository(f.userRepo, f.deletionRepo),
+		f.publisher, UserMergeOptions{PageSize: 2}, logger.New())
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, user := range users {
+		user.CreatedAt = created.Add(time.Duration(i) * time.Hour)
+		if err := f.userRepo.Create(context.Background(), user); err != nil {
+			t.Fatalf("seed user: %v", err)
+		}
+	}
+	return f
+}
+
+func TestUserMergeUseCase_FindDuplicates(t *testing.T) {
+	ada := entities.NewUser("ada@example.com", "Ada Lovelace")
+	adaAgain := entities.NewUser("a.lovelace@EXAMPLE.com", "Lovelace, Ada")
+	adaTypo := entities.NewUser("countess@example.com", "Ada Lovelase")
+	adaElsewhere := entities.NewUser("ada@example.org", "Ada Lovelace")
+	grace := entities.NewUser("grace@example.com", "Grace Hopper")
+	linus := entities.NewUser("linus@gmail.com", "Linus Torvalds")
+	linusAgain := entities.NewUser("torvalds@googlemail.com", "Linus Torvalds")
+	service := entities.NewServiceAccount("org_1", "ada.bot@example.com", "Ada Lovelace", nil)
+	f := newMergeFixture(t, ada, adaAgain, adaTypo, adaElsewhere, grace, linus, linusAgain, service)
+	ctx := context.Background()
+
+	duplicates, err := f.useCase.FindDuplicates(ctx, 0)
+	if err != nil {
+		t.Fatalf("FindDuplicates() unexpected error: %v", err)
+	}
+	type pair struct{ domain, a, b string }
+	want := []pair{
+		{"example.com", ada.ID, adaAgain.ID},
+		{"gmail.com", linus.ID, linusAgain.ID},
+		{"example.com", ada.ID, adaTypo.ID},
+		{"example.com", adaAgain.ID, adaTypo.ID},
+	}
+	if len(duplicates) != len(want) {
+		t.Fatalf("FindDuplicates() returned %d pairs, want %d", len(duplicates), len(want))
+	}
+	for i, duplicate := range duplicates {
+		got := pair{duplicate.Domain, duplicate.Users[0].ID, duplicate.Users[1].ID}
+		if got != want[i] {
+			t.Errorf("FindDuplicates()[%d] = %+v, want %+v", i, got, want[i])
+		}
+		if i > 0 && duplicate.Score > duplicates[i-1].Score {
+			t.Errorf("FindDuplicates()[%d] score %v is above the pair before", i, duplicate.Score)
+		}
+	}
+
+	strict, err := f.useCase.FindDuplicates(ctx, 1)
+	if err != nil {
+		t.Fatalf("FindDuplicates(1) unexpected error: %v", err)
+	}
+	if len(strict) != 2 {
+		t.Errorf("FindDuplicates(1) returned %d pairs, want the 2 identical names", len(strict))
+	}
+
+	for _, threshold := range []float64{-0.5, 1.5} {
+		if _, err := f.useCase.FindDuplicates(ctx, threshold); !errors.Is(err, ErrInvalidDuplicateThreshold) {
+			t.Errorf("FindDuplicates(%v) error = %v, want ErrInvalidDuplicateThreshold", threshold, err)
+		}
+	}
+}
+
+func TestUserMergeUseCase_FindDuplicates_SkipsLargeDomains(t *testing.T) {
+	f := newMergeFixture(t,
+		entities.NewUser("ada@example.com", "Ada Lovelace"),
+		entities.NewUser("a.lovelace@example.com", "Ada Lovelace"),
+		entities.NewUser("grace@example.com", "Grace Hopper"),
+	)
+	f.useCase.opts.MaxGroupSize = 2
+
+	duplicates, err := f.useCase.FindDuplicates(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("FindDuplicates() unexpected error: %v", err)
+	}
+	if len(duplicates) != 0 {
+		t.Errorf("FindDuplicates() returned %d pairs from a domain above the group size", len(duplicates))
+	}
+}
+
+func TestUserMergeUseCase_Merge(t *testing.T) {
+	winner := entities.NewUser("ada@example.com", "Ada Lovelace")
+	loser := entities.NewUser("a.lovelace@example.com", "Ada Lovelace")
+	f := newMergeFixture(t, winner, loser)
+	ctx := context.Background()
+	if err := f.deletionRepo.Create(ctx, entities.NewUserDeletion(loser.ID, "hash", time.Hour)); err != nil {
+		t.Fatalf("seed deletion: %v", err)
+	}
+
+	merge, err := f.useCase.Merge(ctx, winner.ID, loser.ID)
+	if err != nil {
+		t.Fatalf("Merge() unexpected error: %v", err)
+	}
+	if merge.WinnerID != winner.ID || merge.LoserID != loser.ID || merge.CancelledDeletions != 1 {
+		t.Errorf("Merge() = %+v, want %s merged into %s with its deletion cancelled", merge, loser.ID, winner.ID)
+	}
+	if gone, _ := f.userRepo.GetByID(ctx, loser.ID); gone != nil {
+		t.Errorf("Merge() left the loser")
+	}
+	if kept, _ := f.userRepo.GetByID(ctx, winner.ID); kept == nil || kept.Email != winner.Email {
+		t.Errorf("Merge() winner = %+v, want them unchanged", kept)
+	}
+	if _, err := f.deletionRepo.GetPendingByUserID(ctx, loser.ID); !errors.Is(err, repositories.ErrUserDeletionNotFound) {
+		t.Errorf("Merge() left the loser's deletion pending: %v", err)
+	}
+
+	if len(f.publisher.events) != 2 {
+		t.Fatalf("Merge() published %d events, want 2", len(f.publisher.events))
+	}
+	if got := f.publisher.events[0]; got.Type != events.UserMerged || got.AggregateID != winner.ID {
+		t.Errorf("first event = %s of %s, want user.merged of the winner", got.Type, got.AggregateID)
+	}
+	if got := f.publisher.events[1]; got.Type != events.UserDeleted || got.AggregateID != loser.ID {
+		t.Errorf("second event = %s of %s, want user.deleted of the loser", got.Type, got.AggregateID)
+	}
+
+	if _, err := f.useCase.Merge(ctx, winner.ID, loser.ID); !errors.Is(err, repositories.ErrUserNotFound) {
+		t.Errorf("Merge() of a merged user error = %v, want ErrUserNotFound", err)
+	}
+}
+
+func TestUserMergeUseCase_Merge_Invalid(t *testing.T) {
+	user := entities.NewUser("ada@example.com", "Ada Lovelace")
+	service := entities.NewServiceAccount("org_1", "bot@example.com", "Ada Bot", nil)
+	f := newMergeFixture(t, user, service)
+
+	tests := []struct {
+		name          string
+		winner, loser string
+		wantErr       error
+	}{
+		{"missing loser", user.ID, " ", ErrMergeUsersRequired},
+		{"same user", user.ID, user.ID, ErrMergeSameUser},
+		{"unknown user", user.ID, "user_missing", repositories.ErrUserNotFound},
+		{"service account", user.ID, service.ID, ErrMergeServiceAccount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := f.useCase.Merge(context.Background(), tt.winner, tt.loser); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Merge() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if len(f.publisher.events) != 0 {
+		t.Errorf("invalid merges published %d events", len(f.publisher.events))
+	}
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/cache"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/logger"
+)
+
+// UserPreferencesUseCase implements users choosing the locale and time
+// zone their API responses are localized with. Requests read them through
+// Lookup, which caches them per instance, so a change may take the cache
+// TTL to reach other instances.
+type UserPreferencesUseCase struct {
+	userRepo  repositories.UserRepository
+	publisher events.Publisher
+	cache     *cache.Cache[string, entities.UserPreferences]
+	logger    logger.Logger
+}
+
+// NewUserPreferencesUseCase creates a new user preferences use case.
+// publisher may be nil to disable domain events; a zero cacheTTL reads the
+// preferences of every request from the repository.
+func NewUserPreferencesUseCase(userRepo repositories.UserRepository, publisher events.Publisher, cacheTTL time.Duration, logger logger.Logger) *UserPreferencesUseCase {
+	uc := &UserPreferencesUseCase{
+		userRepo:  userRepo,
+		publisher: publisher,
+		logger:    logger,
+	}
+	if cacheTTL > 0 {
+		uc.cache = cache.New[string, entities.UserPreferences](cache.Options{Name: "user_preferences", TTL: cacheTTL})
+	}
+	return uc
+}
+
+// Get returns the preferences of the user
+func (uc *UserPreferencesUseCase) Get(ctx context.Context, userID string) (entities.UserPreferences, error) {
+	user, err := uc.user(ctx, userID)
+	if err != nil {
+		return entities.UserPreferences{}, err
+	}
+	return user.Preferences, nil
+}
+
+// Update replaces the preferences of the user. The locale is stored in its
+// canonical form, e.g. en-GB for en_gb; empty values clear a preference.
+func (uc *UserPreferencesUseCase) Update(ctx context.Context, userID string, prefs entities.UserPreferences) (entities.UserPreferences, error) {
+	prefs, err := normalizePreferences(prefs)
+	if err != nil {
+		return entities.UserPreferences{}, err
+	}
+	user, err := uc.user(ctx, userID)
+	if err != nil {
+		return entities.UserPreferences{}, err
+	}
+
+	user.Preferences = prefs
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to update user preferences")
+		return entities.UserPreferences{}, fmt.Errorf("failed to update user: %w", err)
+	}
+	if uc.cache != nil {
+		uc.cache.Delete(user.ID)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":   user.ID,
+		"locale":    prefs.Locale,
+		"time_zone": prefs.TimeZone,
+	}).Info("User preferences updated")
+	if uc.publisher != nil {
+		event := events.NewEvent(events.UserUpdated, user.ID, user)
+		event.CorrelationID = correlation.ID(ctx)
+		if err := uc.publisher.Publish(ctx, event); err != nil {
+			uc.logger.WithFields(map[string]interface{}{
+				"event_type": event.Type,
+				"event_id":   event.ID,
+				"error":      err.Error(),
+			}).Error("Failed to publish domain event")
+		}
+	}
+	return prefs, nil
+}
+
+// Lookup returns the preferences requests of the user are localized with.
+// Unknown users and failed reads have none, so the request falls back to
+// its headers.
+func (uc *UserPreferencesUseCase) Lookup(ctx context.Context, userID string) entities.UserPreferences {
+	load := func(ctx context.Context) (entities.UserPreferences, error) {
+		user, err := uc.userRepo.GetByID(ctx, userID)
+		if err != nil || user == nil {
+			return entities.UserPreferences{}, err
+		}
+		return user.Preferences, nil
+	}
+
+	var prefs entities.UserPreferences
+	var err error
+	if uc.cache != nil {
+		prefs, err = uc.cache.GetOrLoad(ctx, userID, load)
+	} else {
+		prefs, err = load(ctx)
+	}
+	if err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		}).Warn("Failed to look up user preferences")
+	}
+	return prefs
+}
+
+func (uc *UserPreferencesUseCase) user(ctx context.Context, id string) (*entities.User, error) {
+	user, err := uc.userRepo.GetByID(consistency.WithPrimary(ctx), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, repositories.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// normalizePreferences validates prefs and returns them in canonical form
+func normalizePreferences(prefs entities.UserPreferences) (entities.UserPreferences, error) {
+	locale := strings.ReplaceAll(strings.TrimSpace(prefs.Locale), "_", "-")
+	if locale != "" {
+		tag, err := language.Parse(locale)
+		if err != nil || len(locale) > 35 {
+			return entities.UserPreferences{}, ErrInvalidLocale
+		}
+		locale = tag.String()
+	}
+
+	zone := strings.TrimSpace(prefs.TimeZone)
+	if zone != "" {
+		// Local is the server's own zone rather than one of the database
+		location, err := time.LoadLocation(zone)
+		if err != nil || zone == "Local" || len(zone) > 64 {
+			return entities.UserPreferences{}, ErrInvalidTimeZone
+		}
+		zone = location.String()
+	}
+	return entities.UserPreferences{Locale: locale, TimeZone: zone}, nil
+}
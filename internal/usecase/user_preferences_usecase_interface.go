@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// UserPreferencesUseCaseInterface defines the interface for users managing
+// the localization of their responses
+type UserPreferencesUseCaseInterface interface {
+	Get(ctx context.Context, userID string) (entities.UserPreferences, error)
+	Update(ctx context.Context, userID string, prefs entities.UserPreferences) (entities.UserPreferences, error)
+}
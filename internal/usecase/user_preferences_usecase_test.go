@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+func TestUserPreferencesUseCase_Update(t *testing.T) {
+	ctx := context.Background()
+	userRepo := database.NewMockUserRepository()
+	publisher := &recordingPublisher{}
+	uc := NewUserPreferencesUseCase(userRepo, publisher, 0, logger.New())
+	user := entities.NewUser("jane@example.com", "Jane Doe")
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	prefs, err := uc.Update(ctx, user.ID, entities.UserPreferences{Locale: " de_at ", TimeZone: "Europe/Vienna"})
+	if err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	want := entities.UserPreferences{Locale: "de-AT", TimeZone: "Europe/Vienna"}
+	if prefs != want {
+		t.Errorf("Update() = %+v, want %+v", prefs, want)
+	}
+	if got, _ := uc.Get(ctx, user.ID); got != want {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Type != events.UserUpdated {
+		t.Errorf("Update() published %v, want one user.updated", publisher.events)
+	}
+
+	if prefs, err := uc.Update(ctx, user.ID, entities.UserPreferences{}); err != nil || prefs != (entities.UserPreferences{}) {
+		t.Errorf("Update() clearing = %+v, %v, want no preferences", prefs, err)
+	}
+}
+
+func TestUserPreferencesUseCase_Update_Invalid(t *testing.T) {
+	ctx := context.Background()
+	userRepo := database.NewMockUserRepository()
+	uc := NewUserPreferencesUseCase(userRepo, nil, 0, logger.New())
+	user := entities.NewUser("jane@example.com", "Jane Doe")
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		prefs   entities.UserPreferences
+		wantErr error
+	}{
+		{"malformed locale", user.ID, entities.UserPreferences{Locale: "not a locale"}, ErrInvalidLocale},
+		{"unknown time zone", user.ID, entities.UserPreferences{TimeZone: "Mars/Olympus_Mons"}, ErrInvalidTimeZone},
+		{"server time zone", user.ID, entities.UserPreferences{TimeZone: "Local"}, ErrInvalidTimeZone},
+		{"unknown user", "user_missing", entities.UserPreferences{Locale: "de"}, repositories.ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := uc.Update(ctx, tt.userID, tt.prefs); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserPreferencesUseCase_Lookup(t *testing.T) {
+	ctx := context.Background()
+	userRepo := database.NewMockUserRepository()
+	uc := NewUserPreferencesUseCase(userRepo, nil, time.Minute, logger.New())
+	user := entities.NewUser("jane@example.com", "Jane Doe")
+	user.Preferences = entities.UserPreferences{Locale: "de", TimeZone: "Europe/Berlin"}
+	if err := userRepo.Create(ctx, user); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+
+	if got := uc.Lookup(ctx, user.ID); got != user.Preferences {
+		t.Errorf("Lookup() = %+v, want %+v", got, user.Preferences)
+	}
+	if got := uc.Lookup(ctx, "user_missing"); got != (entities.UserPreferences{}) {
+		t.Errorf("Lookup() of an unknown user = %+v, want none", got)
+	}
+
+	// Updates drop the cached preferences of the user
+	if _, err := uc.Update(ctx, user.ID, entities.UserPreferences{Locale: "en-GB"}); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if got := uc.Lookup(ctx, user.ID); got.Locale != "en-GB" || got.TimeZone != "" {
+		t.Errorf("Lookup() after an update = %+v, want en-GB without a time zone", got)
+	}
+}
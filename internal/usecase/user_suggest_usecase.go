@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/prefixindex"
+	"clean-architecture/pkg/retryafter"
+)
+
+// maxSuggestQueryLength bounds suggestion queries, in characters
+const maxSuggestQueryLength = 100
+
+// UserSuggestOptions tunes the user suggestion index
+type UserSuggestOptions struct {
+	// PageSize is how many users each query of a refresh reads
+	PageSize int
+	// RetryAfter is how long clients are asked to wait while the index
+	// has not been built yet
+	RetryAfter time.Duration
+}
+
+// UserSuggestUseCase suggests users whose name or handle starts with what
+// a client typed. Suggestions come from an in-memory index of every user,
+// rebuilt from the read model by Refresh, so they never wait on the
+// database but may trail recent changes by up to a refresh.
+type UserSuggestUseCase struct {
+	userRepo repositories.UserRepository
+	opts     UserSuggestOptions
+	logger   logger.Logger
+	index    atomic.Pointer[userSuggestIndex]
+}
+
+// userSuggestIndex is one build of the suggestion index. Handles are kept
+// apart from names so callers that may not see emails cannot match them.
+type userSuggestIndex struct {
+	names   *prefixindex.Index
+	handles *prefixindex.Index
+	users   map[string]*entities.User
+}
+
+// NewUserSuggestUseCase creates a new user suggestion use case. Suggest
+// fails with ErrSuggestionsUnavailable until the first Refresh.
+func NewUserSuggestUseCase(userRepo repositories.UserRepository, opts UserSuggestOptions, logger logger.Logger) *UserSuggestUseCase {
+	if opts.PageSize <= 0 {
+		opts.PageSize = 1000
+	}
+	return &UserSuggestUseCase{
+		userRepo: userRepo,
+		opts:     opts,
+		logger:   logger,
+	}
+}
+
+// Suggest returns up to limit users whose name, or handle when handles is
+// set, starts with query, name matches first. A user's handle is the local
+// part of their email.
+func (uc *UserSuggestUseCase) Suggest(ctx context.Context, query string, limit int, handles bool) ([]*entities.User, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrSuggestQueryRequired
+	}
+	if utf8.RuneCountInString(query) > maxSuggestQueryLength {
+		return nil, ErrSuggestQueryTooLong
+	}
+	index := uc.index.Load()
+	if index == nil {
+		return nil, retryafter.Wrap(ErrSuggestionsUnavailable, uc.opts.RetryAfter)
+	}
+
+	ids := index.names.Search(query, limit)
+	if handles && len(ids) < limit {
+		for _, id := range index.handles.Search(query, limit) {
+			if len(ids) == limit {
+				break
+			}
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	users := make([]*entities.User, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, index.users[id])
+	}
+	return users, nil
+}
+
+// Refresh rebuilds the index from every user, reading them a page at a
+// time. The previous index keeps serving until the new one is complete,
+// and again if the refresh fails.
+func (uc *UserSuggestUseCase) Refresh(ctx context.Context) error {
+	started := time.Now()
+	users := make(map[string]*entities.User)
+	var names, handles []prefixindex.Entry
+	err := eachUser(ctx, uc.userRepo, uc.opts.PageSize, func(user *entities.User) {
+		users[user.ID] = user
+		names = append(names, prefixindex.Entry{ID: user.ID, Terms: []string{user.Name}})
+		if at := strings.LastIndex(user.Email, "@"); at > 0 {
+			handles = append(handles, prefixindex.Entry{ID: user.ID, Terms: []string{user.Email[:at]}})
+		}
+	})
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to refresh user suggestions")
+		return fmt.Errorf("failed to refresh user suggestions: %w", err)
+	}
+
+	uc.index.Store(&userSuggestIndex{
+		names:   prefixindex.New(names),
+		handles: prefixindex.New(handles),
+		users:   users,
+	})
+	uc.logger.WithFields(map[string]interface{}{
+		"users":       len(users),
+		"duration_ms": time.Since(started).Milliseconds(),
+	}).Debug("User suggestions refreshed")
+	return nil
+}
+
+// eachUser calls fn with every user, reading them a page of pageSize at a
+// time in ID order so pages stay consistent while users are added
+func eachUser(ctx context.Context, userRepo repositories.UserRepository, pageSize int, fn func(*entities.User)) error {
+	after := ""
+	for {
+		spec := repositories.Specification{
+			Sort:  []repositories.SortOrder{{Field: "id"}},
+			Limit: pageSize,
+		}
+		if after != "" {
+			spec.Conditions = []repositories.Condition{{Field: "id", Operator: repositories.OpGt, Value: after}}
+		}
+		page, err := userRepo.Find(ctx, spec)
+		if err != nil {
+			return err
+		}
+		for _, user := range page {
+			fn(user)
+			after = user.ID
+		}
+		if len(page) < pageSize {
+			return nil
+		}
+	}
+}
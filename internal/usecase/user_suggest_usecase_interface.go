@@ -0,0 +1,13 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// UserSuggestUseCaseInterface defines the interface for search-as-you-type
+// user suggestions
+type UserSuggestUseCaseInterface interface {
+	Suggest(ctx context.Context, query string, limit int, handles bool) ([]*entities.User, error)
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/retryafter"
+)
+
+// suggestedNames returns the names of suggested users
+func suggestedNames(users []*entities.User) []string {
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	return names
+}
+
+func TestUserSuggestUseCase_Suggest(t *testing.T) {
+	ctx := context.Background()
+	userRepo := database.NewMockUserRepository()
+	for _, user := range []*entities.User{
+		entities.NewUser("countess@example.com", "Ada Lovelace"),
+		entities.NewUser("adam.smith@example.com", "Adam Smith"),
+		entities.NewUser("amazing.grace@example.com", "Grace Hopper"),
+	} {
+		if err := userRepo.Create(ctx, user); err != nil {
+			t.Fatalf("Create() unexpected error: %v", err)
+		}
+	}
+	// Small pages exercise reading the users a page at a time
+	uc := NewUserSuggestUseCase(userRepo, UserSuggestOptions{PageSize: 2}, logger.New())
+	if err := uc.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		query   string
+		limit   int
+		handles bool
+		want    []string
+	}{
+		{"ada", 10, false, []string{"Ada Lovelace", "Adam Smith"}},
+		{"ada", 1, false, []string{"Ada Lovelace"}},
+		{"  hop ", 10, false, []string{"Grace Hopper"}},
+		{"count", 10, false, []string{}},
+		{"count", 10, true, []string{"Ada Lovelace"}},
+		{"a", 10, true, []string{"Ada Lovelace", "Adam Smith", "Grace Hopper"}},
+	}
+	for _, tt := range tests {
+		users, err := uc.Suggest(ctx, tt.query, tt.limit, tt.handles)
+		if err != nil {
+			t.Fatalf("Suggest(%q) unexpected error: %v", tt.query, err)
+		}
+		if got := suggestedNames(users); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("Suggest(%q, %d, %t) = %v, want %v", tt.query, tt.limit, tt.handles, got, tt.want)
+		}
+	}
+}
+
+func TestUserSuggestUseCase_Suggest_IndexesChangesOnRefresh(t *testing.T) {
+	ctx := context.Background()
+	userRepo := database.NewMockUserRepository()
+	uc := NewUserSuggestUseCase(userRepo, UserSuggestOptions{RetryAfter: 2 * time.Second}, logger.New())
+
+	_, err := uc.Suggest(ctx, "ada", 10, false)
+	if !errors.Is(err, ErrSuggestionsUnavailable) {
+		t.Fatalf("Suggest() before Refresh error = %v, want %v", err, ErrSuggestionsUnavailable)
+	}
+	if after, ok := retryafter.Of(err); !ok || after != 2*time.Second {
+		t.Errorf("retry delay = %v, %t, want 2s", after, ok)
+	}
+
+	if err := uc.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if err := userRepo.Create(ctx, entities.NewUser("ada@example.com", "Ada Lovelace")); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if users, _ := uc.Suggest(ctx, "ada", 10, false); len(users) != 0 {
+		t.Errorf("Suggest() before the next refresh = %v, want none", suggestedNames(users))
+	}
+	if err := uc.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	if users, _ := uc.Suggest(ctx, "ada", 10, false); len(users) != 1 {
+		t.Errorf("Suggest() after the next refresh = %v, want Ada Lovelace", suggestedNames(users))
+	}
+}
+
+func TestUserSuggestUseCase_Suggest_InvalidQuery(t *testing.T) {
+	uc := NewUserSuggestUseCase(database.NewMockUserRepository(), UserSuggestOptions{}, logger.New())
+
+	if _, err := uc.Suggest(context.Background(), "   ", 10, false); !errors.Is(err, ErrSuggestQueryRequired) {
+		t.Errorf("Suggest() blank error = %v, want %v", err, ErrSuggestQueryRequired)
+	}
+	if _, err := uc.Suggest(context.Background(), strings.Repeat("a", 101), 10, false); !errors.Is(err, ErrSuggestQueryTooLong) {
+		t.Errorf("Suggest() long error = %v, want %v", err, ErrSuggestQueryTooLong)
+	}
+}
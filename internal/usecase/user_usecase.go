@@ -4,60 +4,128 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
 	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/consistency"
+	"clean-architecture/pkg/correlation"
 	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/timing"
 )
 
 // UserUseCase implements business logic for user operations
 type UserUseCase struct {
-	userRepo repositories.UserRepository
-	logger   logger.Logger
+	userRepo  repositories.UserRepository
+	publisher events.Publisher
+	logger    logger.Logger
+	// quotas, when set, refuses creating users beyond the user quota
+	quotas *QuotaUseCase
+	// emailChanges, when set, holds email changes until the new address
+	// confirms them
+	emailChanges      repositories.EmailChangeRepository
+	emailNotifier     EmailChangeNotifier
+	emailConfirmation EmailConfirmationPolicy
 }
 
-// NewUserUseCase creates a new user use case instance
-func NewUserUseCase(userRepo repositories.UserRepository, logger logger.Logger) *UserUseCase {
+// EmailChangeNotifier asks users to confirm their new email
+type EmailChangeNotifier interface {
+	// EmailChangeRequested is sent to the new address of change.
+	// confirmURL applies the change without signing in.
+	EmailChangeRequested(ctx context.Context, user *entities.User, change *entities.EmailChange, confirmURL string) error
+}
+
+// EmailConfirmationPolicy is how email changes are confirmed
+type EmailConfirmationPolicy struct {
+	// TTL is how long the confirmation link is valid
+	TTL time.Duration
+	// ConfirmURL is the page linked from confirmation emails, which
+	// receives the token as a query parameter
+	ConfirmURL string
+}
+
+// NewUserUseCase creates a new user use case instance. Domain events are
+// published to publisher, which may be nil to disable them.
+func NewUserUseCase(userRepo repositories.UserRepository, publisher events.Publisher, logger logger.Logger) *UserUseCase {
 	return &UserUseCase{
-		userRepo: userRepo,
-		logger:   logger,
+		userRepo:  userRepo,
+		publisher: publisher,
+		logger:    logger,
 	}
 }
 
+// WithQuotas enforces the user quota of quotas on every user created
+func (uc *UserUseCase) WithQuotas(quotas *QuotaUseCase) *UserUseCase {
+	uc.quotas = quotas
+	return uc
+}
+
+// WithEmailConfirmation makes UpdateUser hold email changes in changes
+// until the new address confirms them through the link notifier sends it
+func (uc *UserUseCase) WithEmailConfirmation(changes repositories.EmailChangeRepository, notifier EmailChangeNotifier, policy EmailConfirmationPolicy) *UserUseCase {
+	uc.emailChanges = changes
+	uc.emailNotifier = notifier
+	uc.emailConfirmation = policy
+	return uc
+}
+
 // CreateUser creates a new user
 func (uc *UserUseCase) CreateUser(ctx context.Context, email, name string) (*entities.User, error) {
-	uc.logger.WithField("email", email).Info("Creating new user")
+	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.CreateUser").End()
+	return uc.createUser(ctx, entities.NewUser(email, name))
+}
+
+// CreateUserWithPassword creates a new user who logs in with the password
+// passwordHash was hashed from once they verified their email
+func (uc *UserUseCase) CreateUserWithPassword(ctx context.Context, email, name, passwordHash string) (*entities.User, error) {
+	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.CreateUserWithPassword").End()
+	user := entities.NewUser(email, name)
+	user.SetPasswordHash(passwordHash)
+	user.EmailUnverified = true
+	return uc.createUser(ctx, user)
+}
+
+func (uc *UserUseCase) createUser(ctx context.Context, user *entities.User) (*entities.User, error) {
+	uc.logger.WithField("email", user.Email).Info("Creating new user")
 
 	// Validate input
-	if email == "" {
-		return nil, errors.New("email is required")
+	if user.Email == "" {
+		return nil, ErrEmailRequired
 	}
-	if name == "" {
-		return nil, errors.New("name is required")
+	if user.Name == "" {
+		return nil, ErrNameRequired
 	}
 
-	// Check if user already exists
-	existingUser, err := uc.userRepo.GetByEmail(ctx, email)
+	// Check if user already exists, on the primary so a user that was just
+	// created is seen
+	existingUser, err := uc.userRepo.GetByEmail(consistency.WithPrimary(ctx), user.Email)
 	if err == nil && existingUser != nil {
-		return nil, errors.New("user with this email already exists")
+		return nil, repositories.ErrUserAlreadyExists
+	}
+	if err := uc.checkQuota(ctx); err != nil {
+		return nil, err
 	}
-
-	// Create new user
-	user := entities.NewUser(email, name)
 
 	// Save to repository
 	err = uc.userRepo.Create(ctx, user)
+	if errors.Is(err, repositories.ErrUserAlreadyExists) {
+		// Another request created the user since the check above
+		return nil, repositories.ErrUserAlreadyExists
+	}
 	if err != nil {
 		uc.logger.WithField("error", err.Error()).Error("Failed to create user")
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
 	uc.logger.WithField("user_id", user.ID).Info("User created successfully")
+	uc.publish(ctx, events.NewEvent(events.UserCreated, user.ID, user))
 	return user, nil
 }
 
 // GetUserByID retrieves a user by ID
 func (uc *UserUseCase) GetUserByID(ctx context.Context, id string) (*entities.User, error) {
+	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.GetUserByID").End()
 	uc.logger.WithField("user_id", id).Debug("Getting user by ID")
 
 	user, err := uc.userRepo.GetByID(ctx, id)
@@ -67,32 +135,40 @@ func (uc *UserUseCase) GetUserByID(ctx context.Context, id string) (*entities.Us
 	}
 
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, repositories.ErrUserNotFound
 	}
 
 	return user, nil
 }
 
-// UpdateUser updates user information
+// UpdateUser updates user information. With email confirmation, a new
+// email is not applied but awaits confirmation, and the returned user keeps
+// the current one.
 func (uc *UserUseCase) UpdateUser(ctx context.Context, id, name, email string) (*entities.User, error) {
+	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.UpdateUser").End()
 	uc.logger.WithField("user_id", id).Info("Updating user")
 
-	// Get existing user
-	user, err := uc.userRepo.GetByID(ctx, id)
+	// Get existing user; the update is based on it, so it must be current
+	user, err := uc.userRepo.GetByID(consistency.WithPrimary(ctx), id)
 	if err != nil {
 		uc.logger.WithField("error", err.Error()).Error("Failed to get user for update")
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 
 	if user == nil {
-		return nil, errors.New("user not found")
+		return nil, repositories.ErrUserNotFound
 	}
 
 	// Update fields if provided
 	if name != "" {
 		user.UpdateName(name)
 	}
-	if email != "" {
+	confirmEmail := email != "" && email != user.Email && uc.emailChanges != nil
+	if confirmEmail {
+		if err := uc.checkEmailAvailable(ctx, user.ID, email); err != nil {
+			return nil, err
+		}
+	} else if email != "" {
 		user.UpdateEmail(email)
 	}
 
@@ -104,25 +180,204 @@ func (uc *UserUseCase) UpdateUser(ctx context.Context, id, name, email string) (
 	}
 
 	uc.logger.WithField("user_id", user.ID).Info("User updated successfully")
+	uc.publish(ctx, events.NewEvent(events.UserUpdated, user.ID, user))
+
+	if confirmEmail {
+		if err := uc.requestEmailChange(ctx, user, email); err != nil {
+			return nil, err
+		}
+	}
 	return user, nil
 }
 
-// DeleteUser deletes a user
+// ConfirmEmailChange applies the email change a confirmation link was
+// issued for
+func (uc *UserUseCase) ConfirmEmailChange(ctx context.Context, token string) (*entities.User, error) {
+	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.ConfirmEmailChange").End()
+	if uc.emailChanges == nil {
+		return nil, repositories.ErrEmailChangeNotFound
+	}
+
+	change, err := uc.emailChanges.GetPendingByTokenHash(ctx, hashLinkToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email change: %w", err)
+	}
+	now := time.Now()
+	if change.Expired(now) {
+		return nil, ErrEmailChangeExpired
+	}
+
+	user, err := uc.userRepo.GetByID(consistency.WithPrimary(ctx), change.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return nil, repositories.ErrUserNotFound
+	}
+	// The address may have been taken since the change was requested
+	if err := uc.checkEmailAvailable(ctx, user.ID, change.NewEmail); err != nil {
+		return nil, err
+	}
+
+	user.UpdateEmail(change.NewEmail)
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to change user email")
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	change.Confirm(now)
+	if err := uc.emailChanges.Update(ctx, change); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to confirm email change")
+		return nil, fmt.Errorf("failed to confirm email change: %w", err)
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":         user.ID,
+		"email_change_id": change.ID,
+	}).Info("User email changed")
+	uc.publish(ctx, events.NewEvent(events.UserUpdated, user.ID, user))
+	return user, nil
+}
+
+// requestEmailChange stores a change of user's email to email, replacing
+// any earlier one awaiting confirmation, and sends the new address its
+// confirmation link
+func (uc *UserUseCase) requestEmailChange(ctx context.Context, user *entities.User, email string) error {
+	now := time.Now()
+	previous, err := uc.emailChanges.GetPendingByUserID(ctx, user.ID)
+	switch {
+	case err == nil:
+		previous.Supersede(now)
+		if err := uc.emailChanges.Update(ctx, previous); err != nil {
+			return fmt.Errorf("failed to supersede email change: %w", err)
+		}
+	case !errors.Is(err, repositories.ErrEmailChangeNotFound):
+		return fmt.Errorf("failed to get email change: %w", err)
+	}
+
+	token, err := generateLinkToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	change := entities.NewEmailChange(user.ID, email, hashLinkToken(token), uc.emailConfirmation.TTL)
+	if err := uc.emailChanges.Create(ctx, change); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to request email change")
+		return fmt.Errorf("failed to request email change: %w", err)
+	}
+
+	// The request stands even if the notification fails; updating the
+	// email again sends a new link
+	if err := uc.emailNotifier.EmailChangeRequested(ctx, user, change, linkWithToken(uc.emailConfirmation.ConfirmURL, token)); err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		}).Error("Failed to send email change confirmation link")
+	}
+
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id":         user.ID,
+		"email_change_id": change.ID,
+		"expires_at":      change.ExpiresAt,
+	}).Info("User email change awaits confirmation")
+	uc.publish(ctx, events.NewEvent(events.UserEmailChangeRequested, user.ID, change))
+	return nil
+}
+
+// checkEmailAvailable refuses email when a user other than userID has it,
+// checked on the primary so a user that was just created is seen
+func (uc *UserUseCase) checkEmailAvailable(ctx context.Context, userID, email string) error {
+	existing, err := uc.userRepo.GetByEmail(consistency.WithPrimary(ctx), email)
+	if err == nil && existing != nil && existing.ID != userID {
+		return repositories.ErrUserAlreadyExists
+	}
+	return nil
+}
+
+// UpsertUser creates a user with email, or renames the user that has it, in
+// one atomic step. It reports whether the user was created.
+func (uc *UserUseCase) UpsertUser(ctx context.Context, email, name string) (*entities.User, bool, error) {
+	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.UpsertUser").End()
+	uc.logger.WithField("email", email).Info("Upserting user")
+
+	if email == "" {
+		return nil, false, ErrEmailRequired
+	}
+	if name == "" {
+		return nil, false, ErrNameRequired
+	}
+
+	// Only upserts creating a user count against the quota
+	if uc.quotas != nil {
+		if existing, err := uc.userRepo.GetByEmail(consistency.WithPrimary(ctx), email); err != nil || existing == nil {
+			if err := uc.checkQuota(ctx); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	user := entities.NewUser(email, name)
+	created, err := uc.userRepo.Upsert(ctx, user)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to upsert user")
+		return nil, false, fmt.Errorf("failed to upsert user: %w", err)
+	}
+
+	eventType := events.UserUpdated
+	if created {
+		eventType = events.UserCreated
+	}
+	uc.logger.WithFields(map[string]interface{}{
+		"user_id": user.ID,
+		"created": created,
+	}).Info("User upserted successfully")
+	uc.publish(ctx, events.NewEvent(eventType, user.ID, user))
+	return user, created, nil
+}
+
+// DeleteUser deletes a user. The deletion event carries the user as they
+// were, so subscribers can still reach them.
 func (uc *UserUseCase) DeleteUser(ctx context.Context, id string) error {
+	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.DeleteUser").End()
 	uc.logger.WithField("user_id", id).Info("Deleting user")
 
-	err := uc.userRepo.Delete(ctx, id)
+	user, err := uc.userRepo.GetByID(consistency.WithPrimary(ctx), id)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to get user for deletion")
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return repositories.ErrUserNotFound
+	}
+
+	err = uc.userRepo.Delete(ctx, id)
 	if err != nil {
 		uc.logger.WithField("error", err.Error()).Error("Failed to delete user")
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
 	uc.logger.WithField("user_id", id).Info("User deleted successfully")
+	uc.publish(ctx, events.NewEvent(events.UserDeleted, id, user))
 	return nil
 }
 
+// PurgeDeletedUsers permanently removes the users deleted before before,
+// in batches, and returns how many were removed
+func (uc *UserUseCase) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	for {
+		n, err := uc.userRepo.PurgeDeleted(ctx, before, purgeBatchSize)
+		purged += n
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge deleted users: %w", err)
+		}
+		if n < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
 // ListUsers retrieves a list of users
 func (uc *UserUseCase) ListUsers(ctx context.Context, limit, offset int) ([]*entities.User, error) {
+	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.ListUsers").End()
 	uc.logger.WithFields(map[string]interface{}{
 		"limit":  limit,
 		"offset": offset,
@@ -136,3 +391,56 @@ func (uc *UserUseCase) ListUsers(ctx context.Context, limit, offset int) ([]*ent
 
 	return users, nil
 }
+
+// SearchUsers retrieves the users matching spec
+func (uc *UserUseCase) SearchUsers(ctx context.Context, spec repositories.Specification) ([]*entities.User, error) {
+	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.SearchUsers").End()
+	uc.logger.WithFields(map[string]interface{}{
+		"conditions": len(spec.Conditions),
+		"limit":      spec.Limit,
+		"offset":     spec.Offset,
+	}).Debug("Searching users")
+
+	users, err := uc.userRepo.Find(ctx, spec)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to search users")
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	return users, nil
+}
+
+// CountUsers returns the number of users matching spec's conditions
+func (uc *UserUseCase) CountUsers(ctx context.Context, spec repositories.Specification) (int64, error) {
+	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.CountUsers").End()
+	count, err := uc.userRepo.CountMatching(ctx, spec)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to count users")
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return count, nil
+}
+
+// checkQuota refuses creating a user once the user quota is reached
+func (uc *UserUseCase) checkQuota(ctx context.Context) error {
+	if uc.quotas == nil {
+		return nil
+	}
+	return uc.quotas.check(ctx, entities.QuotaResourceUsers)
+}
+
+// publish emits a domain event. Failures are logged rather than returned
+// because the change has already been committed.
+func (uc *UserUseCase) publish(ctx context.Context, event events.Event) {
+	if uc.publisher == nil {
+		return
+	}
+	event.CorrelationID = correlation.ID(ctx)
+	if err := uc.publisher.Publish(ctx, event); err != nil {
+		uc.logger.WithFields(map[string]interface{}{
+			"event_type": event.Type,
+			"event_id":   event.ID,
+			"error":      err.Error(),
+		}).Error("Failed to publish domain event")
+	}
+}
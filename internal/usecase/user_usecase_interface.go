@@ -4,13 +4,19 @@ import (
 	"context"
 
 	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/repositories"
 )
 
 // UserUseCaseInterface defines the interface for user business logic
 type UserUseCaseInterface interface {
 	CreateUser(ctx context.Context, email, name string) (*entities.User, error)
+	CreateUserWithPassword(ctx context.Context, email, name, passwordHash string) (*entities.User, error)
 	GetUserByID(ctx context.Context, id string) (*entities.User, error)
 	UpdateUser(ctx context.Context, id, name, email string) (*entities.User, error)
+	ConfirmEmailChange(ctx context.Context, token string) (*entities.User, error)
+	UpsertUser(ctx context.Context, email, name string) (user *entities.User, created bool, err error)
 	DeleteUser(ctx context.Context, id string) error
 	ListUsers(ctx context.Context, limit, offset int) ([]*entities.User, error)
+	SearchUsers(ctx context.Context, spec repositories.Specification) ([]*entities.User, error)
+	CountUsers(ctx context.Context, spec repositories.Specification) (int64, error)
 }
@@ -2,9 +2,16 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"net/url"
 	"testing"
+	"time"
 
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
 	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/correlation"
 	"clean-architecture/pkg/logger"
 )
 
@@ -12,7 +19,7 @@ func TestUserUseCase_CreateUser(t *testing.T) {
 	// Setup
 	logger := logger.New()
 	userRepo := database.NewMockUserRepository()
-	userUseCase := NewUserUseCase(userRepo, logger)
+	userUseCase := NewUserUseCase(userRepo, nil, logger)
 
 	tests := []struct {
 		name     string
@@ -76,7 +83,7 @@ func TestUserUseCase_GetUserByID(t *testing.T) {
 	// Setup
 	logger := logger.New()
 	userRepo := database.NewMockUserRepository()
-	userUseCase := NewUserUseCase(userRepo, logger)
+	userUseCase := NewUserUseCase(userRepo, nil, logger)
 
 	// Create a test user first
 	user, err := userUseCase.CreateUser(context.Background(), "test@example.com", "Test User")
@@ -106,8 +113,8 @@ func TestUserUseCase_GetUserByID(t *testing.T) {
 			result, err := userUseCase.GetUserByID(context.Background(), tt.userID)
 
 			if tt.wantErr {
-				if err == nil {
-					t.Errorf("GetUserByID() expected error but got none")
+				if !errors.Is(err, repositories.ErrUserNotFound) {
+					t.Errorf("GetUserByID() error = %v, want %v", err, repositories.ErrUserNotFound)
 				}
 				return
 			}
@@ -128,3 +135,301 @@ func TestUserUseCase_GetUserByID(t *testing.T) {
 		})
 	}
 }
+
+func TestUserUseCase_DeleteUser_NotFound(t *testing.T) {
+	userRepo := database.NewMockUserRepository()
+	publisher := &recordingPublisher{}
+	userUseCase := NewUserUseCase(userRepo, publisher, logger.New())
+
+	err := userUseCase.DeleteUser(context.Background(), "non-existing-id")
+	if !errors.Is(err, repositories.ErrUserNotFound) {
+		t.Errorf("DeleteUser() error = %v, want %v", err, repositories.ErrUserNotFound)
+	}
+	if len(publisher.events) != 0 {
+		t.Errorf("DeleteUser() published %d events, want none", len(publisher.events))
+	}
+}
+
+// recordingPublisher captures published events
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestUserUseCase_PublishesEvents(t *testing.T) {
+	// Setup
+	logger := logger.New()
+	userRepo := database.NewMockUserRepository()
+	publisher := &recordingPublisher{}
+	userUseCase := NewUserUseCase(userRepo, publisher, logger)
+	ctx := correlation.WithID(context.Background(), "cor_123")
+
+	user, err := userUseCase.CreateUser(ctx, "test@example.com", "Test User")
+	if err != nil {
+		t.Fatalf("CreateUser() unexpected error: %v", err)
+	}
+	if _, err := userUseCase.UpdateUser(ctx, user.ID, "Renamed", ""); err != nil {
+		t.Fatalf("UpdateUser() unexpected error: %v", err)
+	}
+	if err := userUseCase.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteUser() unexpected error: %v", err)
+	}
+
+	// Failed operations must not emit events
+	if _, err := userUseCase.CreateUser(ctx, "", "No Email"); err == nil {
+		t.Fatalf("CreateUser() expected error but got none")
+	}
+
+	want := []string{events.UserCreated, events.UserUpdated, events.UserDeleted}
+	if len(publisher.events) != len(want) {
+		t.Fatalf("published %d events, want %d", len(publisher.events), len(want))
+	}
+	for i, event := range publisher.events {
+		if event.Type != want[i] {
+			t.Errorf("event %d type = %v, want %v", i, event.Type, want[i])
+		}
+		if event.AggregateID != user.ID {
+			t.Errorf("event %d aggregate ID = %v, want %v", i, event.AggregateID, user.ID)
+		}
+		if event.ID == "" {
+			t.Errorf("event %d has no ID", i)
+		}
+		if event.CorrelationID != "cor_123" {
+			t.Errorf("event %d correlation ID = %v, want cor_123", i, event.CorrelationID)
+		}
+	}
+	if deleted, ok := publisher.events[2].Data.(*entities.User); !ok || deleted.Email != "test@example.com" {
+		t.Errorf("deletion event data = %v, want the deleted user", publisher.events[2].Data)
+	}
+}
+
+// purgingUserRepository has deleted users waiting to be purged
+type purgingUserRepository struct {
+	repositories.UserRepository
+	deleted int64
+	before  time.Time
+}
+
+func (r *purgingUserRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	r.before = before
+	n := min(r.deleted, int64(limit))
+	r.deleted -= n
+	return n, nil
+}
+
+func TestUserUseCase_PurgeDeletedUsers(t *testing.T) {
+	userRepo := &purgingUserRepository{UserRepository: database.NewMockUserRepository(), deleted: 2*purgeBatchSize + 3}
+	useCase := NewUserUseCase(userRepo, nil, logger.New())
+	before := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	purged, err := useCase.PurgeDeletedUsers(context.Background(), before)
+	if err != nil {
+		t.Fatalf("PurgeDeletedUsers() error = %v", err)
+	}
+	if purged != 2*purgeBatchSize+3 {
+		t.Errorf("PurgeDeletedUsers() = %d, want %d", purged, 2*purgeBatchSize+3)
+	}
+	if userRepo.deleted != 0 {
+		t.Errorf("%d deleted users were left, want every batch purged", userRepo.deleted)
+	}
+	if !userRepo.before.Equal(before) {
+		t.Errorf("purged users deleted before %v, want %v", userRepo.before, before)
+	}
+}
+
+func TestUserUseCase_UpsertUser(t *testing.T) {
+	// Setup
+	logger := logger.New()
+	userRepo := database.NewMockUserRepository()
+	publisher := &recordingPublisher{}
+	userUseCase := NewUserUseCase(userRepo, publisher, logger)
+	ctx := context.Background()
+
+	user, created, err := userUseCase.UpsertUser(ctx, "test@example.com", "Test User")
+	if err != nil {
+		t.Fatalf("UpsertUser() unexpected error: %v", err)
+	}
+	if !created {
+		t.Errorf("UpsertUser() created = false for a new email")
+	}
+
+	updated, created, err := userUseCase.UpsertUser(ctx, "test@example.com", "Renamed")
+	if err != nil {
+		t.Fatalf("UpsertUser() unexpected error: %v", err)
+	}
+	if created {
+		t.Errorf("UpsertUser() created = true for an existing email")
+	}
+	if updated.ID != user.ID {
+		t.Errorf("UpsertUser() ID = %v, want %v", updated.ID, user.ID)
+	}
+
+	stored, err := userUseCase.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() unexpected error: %v", err)
+	}
+	if stored.Name != "Renamed" {
+		t.Errorf("stored name = %v, want Renamed", stored.Name)
+	}
+
+	if _, _, err := userUseCase.UpsertUser(ctx, "test@example.com", ""); err != ErrNameRequired {
+		t.Errorf("UpsertUser() error = %v, want %v", err, ErrNameRequired)
+	}
+
+	want := []string{events.UserCreated, events.UserUpdated}
+	if len(publisher.events) != len(want) {
+		t.Fatalf("published %d events, want %d", len(publisher.events), len(want))
+	}
+	for i, event := range publisher.events {
+		if event.Type != want[i] {
+			t.Errorf("event %d type = %v, want %v", i, event.Type, want[i])
+		}
+	}
+}
+
+// recordingEmailNotifier captures the confirmation links sent to new
+// addresses
+type recordingEmailNotifier struct {
+	emails []string
+	links  []string
+}
+
+func (n *recordingEmailNotifier) EmailChangeRequested(ctx context.Context, user *entities.User, change *entities.EmailChange, confirmURL string) error {
+	n.emails = append(n.emails, change.NewEmail)
+	n.links = append(n.links, confirmURL)
+	return nil
+}
+
+type emailChangeFixture struct {
+	userRepo  repositories.UserRepository
+	changes   repositories.EmailChangeRepository
+	notifier  *recordingEmailNotifier
+	publisher *recordingPublisher
+	useCase   *UserUseCase
+	user      *entities.User
+}
+
+func newEmailChangeFixture(t *testing.T) *emailChangeFixture {
+	t.Helper()
+	f := &emailChangeFixture{
+		userRepo:  database.NewMockUserRepository(),
+		changes:   database.NewMockEmailChangeRepository(),
+		notifier:  &recordingEmailNotifier{},
+		publisher: &recordingPublisher{},
+	}
+	f.useCase = NewUserUseCase(f.userRepo, f.publisher, logger.New()).
+		WithEmailConfirmation(f.changes, f.notifier, EmailConfirmationPolicy{
+			TTL:        time.Hour,
+			ConfirmURL: "https://app.example.com/account/confirm-email",
+		})
+
+	f.user = entities.NewUser("old@example.com", "Test User")
+	if err := f.userRepo.Create(context.Background(), f.user); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	return f
+}
+
+// confirmToken extracts the token from the most recent confirmation link
+func (f *emailChangeFixture) confirmToken(t *testing.T) string {
+	t.Helper()
+	if len(f.notifier.links) == 0 {
+		t.Fatalf("no confirmation link was sent")
+	}
+	u, err := url.Parse(f.notifier.links[len(f.notifier.links)-1])
+	if err != nil {
+		t.Fatalf("parse confirmation link: %v", err)
+	}
+	return u.Query().Get("token")
+}
+
+func TestUserUseCase_UpdateUser_EmailAwaitsConfirmation(t *testing.T) {
+	f := newEmailChangeFixture(t)
+	ctx := context.Background()
+
+	updated, err := f.useCase.UpdateUser(ctx, f.user.ID, "Renamed", "new@example.com")
+	if err != nil {
+		t.Fatalf("UpdateUser() unexpected error: %v", err)
+	}
+	if updated.Email != "old@example.com" || updated.Name != "Renamed" {
+		t.Errorf("UpdateUser() = %q <%s>, want the name applied and the email kept", updated.Name, updated.Email)
+	}
+	if len(f.notifier.emails) != 1 || f.notifier.emails[0] != "new@example.com" {
+		t.Fatalf("confirmation sent to %v, want new@example.com", f.notifier.emails)
+	}
+	if last := f.publisher.events[len(f.publisher.events)-1]; last.Type != events.UserEmailChangeRequested {
+		t.Errorf("last event = %q, want %q", last.Type, events.UserEmailChangeRequested)
+	}
+
+	confirmed, err := f.useCase.ConfirmEmailChange(ctx, f.confirmToken(t))
+	if err != nil {
+		t.Fatalf("ConfirmEmailChange() unexpected error: %v", err)
+	}
+	if confirmed.Email != "new@example.com" {
+		t.Errorf("ConfirmEmailChange() email = %q, want new@example.com", confirmed.Email)
+	}
+	stored, _ := f.userRepo.GetByID(ctx, f.user.ID)
+	if stored.Email != "new@example.com" {
+		t.Errorf("stored email = %q, want new@example.com", stored.Email)
+	}
+
+	if _, err := f.useCase.ConfirmEmailChange(ctx, f.confirmToken(t)); !errors.Is(err, repositories.ErrEmailChangeNotFound) {
+		t.Errorf("second ConfirmEmailChange() error = %v, want ErrEmailChangeNotFound", err)
+	}
+}
+
+func TestUserUseCase_UpdateUser_SupersedesEarlierChange(t *testing.T) {
+	f := newEmailChangeFixture(t)
+	ctx := context.Background()
+
+	if _, err := f.useCase.UpdateUser(ctx, f.user.ID, "", "typo@exmaple.com"); err != nil {
+		t.Fatalf("UpdateUser() unexpected error: %v", err)
+	}
+	first := f.confirmToken(t)
+	if _, err := f.useCase.UpdateUser(ctx, f.user.ID, "", "new@example.com"); err != nil {
+		t.Fatalf("UpdateUser() unexpected error: %v", err)
+	}
+
+	if _, err := f.useCase.ConfirmEmailChange(ctx, first); !errors.Is(err, repositories.ErrEmailChangeNotFound) {
+		t.Errorf("ConfirmEmailChange(superseded) error = %v, want ErrEmailChangeNotFound", err)
+	}
+	if _, err := f.useCase.ConfirmEmailChange(ctx, f.confirmToken(t)); err != nil {
+		t.Errorf("ConfirmEmailChange(latest) unexpected error: %v", err)
+	}
+}
+
+func TestUserUseCase_ConfirmEmailChange_Rejected(t *testing.T) {
+	f := newEmailChangeFixture(t)
+	ctx := context.Background()
+
+	other := entities.NewUser("taken@example.com", "Other User")
+	if err := f.userRepo.Create(ctx, other); err != nil {
+		t.Fatalf("seed user: %v", err)
+	}
+	if _, err := f.useCase.UpdateUser(ctx, f.user.ID, "", "taken@example.com"); !errors.Is(err, repositories.ErrUserAlreadyExists) {
+		t.Errorf("UpdateUser(taken email) error = %v, want ErrUserAlreadyExists", err)
+	}
+
+	if _, err := f.useCase.UpdateUser(ctx, f.user.ID, "", "new@example.com"); err != nil {
+		t.Fatalf("UpdateUser() unexpected error: %v", err)
+	}
+	change, err := f.changes.GetPendingByUserID(ctx, f.user.ID)
+	if err != nil {
+		t.Fatalf("GetPendingByUserID() unexpected error: %v", err)
+	}
+	change.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := f.changes.Update(ctx, change); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if _, err := f.useCase.ConfirmEmailChange(ctx, f.confirmToken(t)); !errors.Is(err, ErrEmailChangeExpired) {
+		t.Errorf("ConfirmEmailChange(expired) error = %v, want ErrEmailChangeExpired", err)
+	}
+
+	if _, err := f.useCase.ConfirmEmailChange(ctx, "unknown"); !errors.Is(err, repositories.ErrEmailChangeNotFound) {
+		t.Errorf("ConfirmEmailChange(unknown) error = %v, want ErrEmailChangeNotFound", err)
+	}
+}
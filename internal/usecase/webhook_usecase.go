@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/domain/repositories"
+	"clean-architecture/pkg/logger"
+)
+
+// webhookSecretMarker starts every webhook secret, so leaked secrets are
+// easy to recognize
+const webhookSecretMarker = "whsec_"
+
+// WebhookSender posts deliveries to the URL of their webhook
+type WebhookSender interface {
+	// Send posts the payload of delivery to webhook and returns the status
+	// the receiver answered with, 0 when no response was received. Any
+	// status but 2xx is an error.
+	Send(ctx context.Context, webhook *entities.Webhook, delivery *entities.WebhookDelivery) (int, error)
+}
+
+// WebhookOptions configures how deliveries are attempted
+type WebhookOptions struct {
+	// MaxAttempts is how often a delivery is attempted before it fails
+	MaxAttempts int
+	// InitialBackoff is the wait after the first failed attempt, doubled
+	// after each further one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// BatchSize is the most deliveries attempted per call of DeliverDue
+	BatchSize int
+	// Timeout bounds each attempt, from connecting to reading the status
+	Timeout time.Duration
+}
+
+// Backoff returns how long to wait after the failed attempt-th attempt
+func (o WebhookOptions) Backoff(attempt int) time.Duration {
+	backoff := o.InitialBackoff
+	for i := 1; i < attempt && backoff < o.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, o.MaxBackoff)
+}
+
+// WebhookUpdate changes the fields of a webhook that are set
+type WebhookUpdate struct {
+	URL    *string
+	Events *[]string
+	Active *bool
+}
+
+// WebhookUseCase manages webhooks and posts the domain events they
+// subscribe to. Events are recorded as deliveries when they are consumed and
+// attempted by DeliverDue, which backs off exponentially between failed
+// attempts.
+type WebhookUseCase struct {
+	webhookRepo repositories.WebhookRepository
+	sender      WebhookSender
+	opts        WebhookOptions
+	logger      logger.Logger
+}
+
+// NewWebhookUseCase creates a new webhook use case instance
+func NewWebhookUseCase(webhookRepo repositories.WebhookRepository, sender WebhookSender, opts WebhookOptions, logger logger.Logger) *WebhookUseCase {
+	return &WebhookUseCase{
+		webhookRepo: webhookRepo,
+		sender:      sender,
+		opts:        opts,
+		logger:      logger,
+	}
+}
+
+// CreateWebhook registers a webhook posting the events of eventTypes to
+// rawURL, or every event when eventTypes is empty. The returned webhook
+// carries its generated secret.
+func (uc *WebhookUseCase) CreateWebhook(ctx context.Context, rawURL string, eventTypes []string, createdBy string) (*entities.Webhook, error) {
+	if err := validateWebhook(rawURL, eventTypes); err != nil {
+		return nil, err
+	}
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	webhook := entities.NewWebhook(rawURL, secret, eventTypes, createdBy)
+	if err := uc.webhookRepo.Create(ctx, webhook); err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to create webhook")
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	uc.logger.WithFields(map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"events":     webhook.Events,
+	}).Info("Webhook created")
+	return webhook, nil
+}
+
+// GetWebhook retrieves a webhook by ID
+func (uc *WebhookUseCase) GetWebhook(ctx context.Context, id string) (*entities.Webhook, error) {
+	webhook, err := uc.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// ListWebhooks retrieves every webhook, oldest first
+func (uc *WebhookUseCase) ListWebhooks(ctx context.Context) ([]*entities.Webhook, error) {
+	webhooks, err := uc.webhookRepo.List(ctx)
+	if err != nil {
+		uc.logger.WithField("error", err.Error()).Error("Failed to list webhooks")
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook applies update to the webhook id. Deactivated webhooks
+// receive no new events, and their pending deliveries fail when they are
+// next attempted.
+func (uc *WebhookUseCase) UpdateWebhook(ctx context.Context, id string, update WebhookUpdate) (*entities.Webhook, error) {
+	webhook, err := uc.webhookRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if update.URL != nil {
+		webhook.URL = *update.URL
+	}
+	if update.Events != nil {
+		webhook.Events = *update.Events
+	}
+	if update.Active != nil {
+		webhook.Active = *update.Active
+	}
+	if err := validateWebhook(webhook.URL, webhook.Events); err != nil {
+		return nil, err
+	}
+
+	webhook.UpdatedAt = time.Now()
+	if err := uc.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	uc.logger.WithFields(map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"active":     webhook.Active,
+	}).Info("Webhook updated")
+	return webhook, nil
+}
+
+// DeleteWebhook removes a webhook and its deliveries
+func (uc *WebhookUseCase) DeleteWebhook(ctx context.Context, id string) error {
+	if err := uc.webhookRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	uc.logger.WithField("webhook_id", id).Info("Webhook deleted")
+	return nil
+}
+
+// ListDeliveries retrieves the deliveries of the webhook id with their
+// attempts, newest first
+func (uc *WebhookUseCase) ListDeliveries(ctx context.Context, id string, limit, offset int) ([]*entities.WebhookDelivery, error) {
+	if _, err := uc.webhookRepo.GetByID(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	deliveries, err := uc.webhookRepo.ListDeliveries(ctx, id, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Enqueue records a delivery of event to every active webhook subscribing
+// to it. Redelivered events are recorded once per webhook.
+func (uc *WebhookUseCase) Enqueue(ctx context.Context, event events.Event) error {
+	webhooks, err := uc.webhookRepo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	var payload json.RawMessage
+	for _, webhook := range webhooks {
+		if !webhook.Active || !webhook.Subscribes(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+			}
+		}
+		delivery := entities.NewWebhookDelivery(webhook.ID, event.ID, event.Type, payload)
+		if err := uc.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to record webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeliverDue attempts the deliveries due at now and returns how many
+// succeeded. Failed attempts are logged on the delivery and retried after
+// a backoff until MaxAttempts is reached.
+func (uc *WebhookUseCase) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	deliveries, err := uc.webhookRepo.ListDueDeliveries(ctx, now, uc.opts.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due webhook deliveries: %w", err)
+	}
+
+	webhooks := make(map[string]*entities.Webhook)
+	delivered := 0
+	var errs []error
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = uc.webhookRepo.GetByID(ctx, delivery.WebhookID)
+			if errors.Is(err, repositories.ErrWebhookNotFound) {
+				// Deleted along with its deliveries since they were listed
+				continue
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to get webhook %s: %w", delivery.WebhookID, err))
+				continue
+			}
+			webhooks[webhook.ID] = webhook
+		}
+
+		if uc.deliver(ctx, webhook, delivery) {
+			delivered++
+		}
+		if err := uc.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+			errs = append(errs, fmt.Errorf("failed to record webhook delivery %s: %w", delivery.ID, err))
+		}
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// deliver makes one attempt of delivery and records it, reporting whether
+// it succeeded
+func (uc *WebhookUseCase) deliver(ctx context.Context, webhook *entities.Webhook, delivery *entities.WebhookDelivery) bool {
+	start := time.Now()
+	attempt := entities.WebhookAttempt{At: start}
+	if !webhook.Active {
+		attempt.Error = "webhook is inactive"
+		delivery.Record(attempt, nil)
+		return false
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, uc.opts.Timeout)
+	status, err := uc.sender.Send(sendCtx, webhook, delivery)
+	cancel()
+	attempt.StatusCode = status
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	var next *time.Time
+	if n := len(delivery.Attempts) + 1; !attempt.Succeeded() && n < uc.opts.MaxAttempts {
+		at := start.Add(uc.opts.Backoff(n))
+		next = &at
+	}
+	delivery.Record(attempt, next)
+
+	if !attempt.Succeeded() {
+		fields := map[string]interface{}{
+			"webhook_id":  webhook.ID,
+			"delivery_id": delivery.ID,
+			"event_id":    delivery.EventID,
+			"attempt":     len(delivery.Attempts),
+			"status_code": status,
+			"error":       attempt.Error,
+		}
+		if next == nil {
+			uc.logger.WithFields(fields).Error("Webhook delivery failed")
+		} else {
+			fields["next_attempt_at"] = next.UTC().Format(time.RFC3339)
+			uc.logger.WithFields(fields).Warn("Webhook delivery attempt failed")
+		}
+	}
+	return attempt.Succeeded()
+}
+
+// validateWebhook checks the URL and event types of a webhook
+func validateWebhook(rawURL string, eventTypes []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ErrInvalidWebhookURL
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(events.Types, eventType) {
+			return ErrUnknownEventType
+		}
+	}
+	return nil
+}
+
+// generateWebhookSecret returns a random secret to authenticate deliveries
+// with
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return webhookSecretMarker + hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"context"
+
+	"clean-architecture/internal/domain/entities"
+)
+
+// WebhookUseCaseInterface defines the interface for managing webhooks and
+// inspecting their deliveries
+type WebhookUseCaseInterface interface {
+	CreateWebhook(ctx context.Context, url string, events []string, createdBy string) (*entities.Webhook, error)
+	GetWebhook(ctx context.Context, id string) (*entities.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*entities.Webhook, error)
+	UpdateWebhook(ctx context.Context, id string, update WebhookUpdate) (*entities.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, id string, limit, offset int) ([]*entities.WebhookDelivery, error)
+}
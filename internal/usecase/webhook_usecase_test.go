@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"clean-architecture/internal/domain/entities"
+	"clean-architecture/internal/domain/events"
+	"clean-architecture/internal/infrastructure/database"
+	"clean-architecture/pkg/logger"
+)
+
+// recordingSender records the deliveries sent through it and answers with
+// status, or fails while failing is set
+type recordingSender struct {
+	sent    []*entities.WebhookDelivery
+	status  int
+	failing bool
+}
+
+func (s *recordingSender) Send(ctx context.Context, webhook *entities.Webhook, delivery *entities.WebhookDelivery) (int, error) {
+	s.sent = append(s.sent, delivery)
+	if s.failing {
+		return 0, errors.New("connection refused")
+	}
+	if s.status < 200 || s.status > 299 {
+		return s.status, errors.New("unexpected status")
+	}
+	return s.status, nil
+}
+
+var testWebhookOptions = WebhookOptions{
+	MaxAttempts:    3,
+	InitialBackoff: time.Minute,
+	MaxBackoff:     time.Hour,
+	BatchSize:      10,
+	Timeout:        time.Second,
+}
+
+func TestWebhookUseCase_CreateWebhook(t *testing.T) {
+	ctx := context.Background()
+	webhooks := NewWebhookUseCase(database.NewMockWebhookRepository(), &recordingSender{}, testWebhookOptions, logger.New())
+
+	webhook, err := webhooks.CreateWebhook(ctx, "https://example.com/hooks", []string{events.UserCreated}, "usr_admin")
+	if err != nil {
+		t.Fatalf("CreateWebhook() unexpected error: %v", err)
+	}
+	if webhook.ID == "" || !webhook.Active || webhook.CreatedBy != "usr_admin" {
+		t.Errorf("CreateWebhook() = %+v, want an active webhook created by usr_admin", webhook)
+	}
+	if len(webhook.Secret) != len(webhookSecretMarker)+64 || webhook.Secret[:len(webhookSecretMarker)] != webhookSecretMarker {
+		t.Errorf("secret = %q, want %s and 64 hex digits", webhook.Secret, webhookSecretMarker)
+	}
+
+	for _, tc := range []struct {
+		url    string
+		events []string
+		want   error
+	}{
+		{"ftp://example.com/hooks", nil, ErrInvalidWebhookURL},
+		{"/hooks", nil, ErrInvalidWebhookURL},
+		{"https://example.com/hooks", []string{"user.renamed"}, ErrUnknownEventType},
+	} {
+		if _, err := webhooks.CreateWebhook(ctx, tc.url, tc.events, "usr_admin"); !errors.Is(err, tc.want) {
+			t.Errorf("CreateWebhook(%q, %v) error = %v, want %v", tc.url, tc.events, err, tc.want)
+		}
+	}
+}
+
+func TestWebhookUseCase_UpdateWebhook(t *testing.T) {
+	ctx := context.Background()
+	webhooks := NewWebhookUseCase(database.NewMockWebhookRepository(), &recordingSender{}, testWebhookOptions, logger.New())
+	webhook, err := webhooks.CreateWebhook(ctx, "https://example.com/hooks", nil, "usr_admin")
+	if err != nil {
+		t.Fatalf("CreateWebhook() unexpected error: %v", err)
+	}
+
+	inactive := false
+	subscribed := []string{events.UserDeleted}
+	updated, err := webhooks.UpdateWebhook(ctx, webhook.ID, WebhookUpdate{Events: &subscribed, Active: &inactive})
+	if err != nil {
+		t.Fatalf("UpdateWebhook() unexpected error: %v", err)
+	}
+	if updated.Active || len(updated.Events) != 1 || updated.URL != webhook.URL || updated.Secret != webhook.Secret {
+		t.Errorf("UpdateWebhook() = %+v, want the URL and secret kept", updated)
+	}
+
+	badURL := "example.com"
+	if _, err := webhooks.UpdateWebhook(ctx, webhook.ID, WebhookUpdate{URL: &badURL}); !errors.Is(err, ErrInvalidWebhookURL) {
+		t.Errorf("UpdateWebhook() error = %v, want %v", err, ErrInvalidWebhookURL)
+	}
+	if got, _ := webhooks.GetWebhook(ctx, webhook.ID); got.URL != webhook.URL {
+		t.Errorf("URL = %q after a rejected update, want %q", got.URL, webhook.URL)
+	}
+}
+
+func TestWebhookUseCase_EnqueueAndDeliver(t *testing.T) {
+	ctx := context.Background()
+	sender := &recordingSender{status: 204}
+	webhooks := NewWebhookUseCase(database.NewMockWebhookRepository(), sender, testWebhookOptions, logger.New())
+	all, _ := webhooks.CreateWebhook(ctx, "https://example.com/all", nil, "usr_admin")
+	deletions, _ := webhooks.CreateWebhook(ctx, "https://example.com/deletions", []string{events.UserDeleted}, "usr_admin")
+
+	event := events.NewEvent(events.UserCreated, "usr_1", nil)
+	for range 2 {
+		if err := webhooks.Enqueue(ctx, event); err != nil {
+			t.Fatalf("Enqueue() unexpected error: %v", err)
+		}
+	}
+
+	delivered, err := webhooks.DeliverDue(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("DeliverDue() unexpected error: %v", err)
+	}
+	if delivered != 1 || len(sender.sent) != 1 {
+		t.Fatalf("DeliverDue() delivered %d, sent %d, want the redelivered event sent once", delivered, len(sender.sent))
+	}
+
+	deliveries, err := webhooks.ListDeliveries(ctx, all.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("ListDeliveries() unexpected error: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("ListDeliveries() returned %d deliveries, want 1", len(deliveries))
+	}
+	got := deliveries[0]
+	if got.Status != entities.WebhookDeliverySucceeded || got.EventID != event.ID || got.DeliveredAt == nil ||
+		len(got.Attempts) != 1 || got.Attempts[0].StatusCode != 204 {
+		t.Errorf("delivery = %+v, want one successful attempt", got)
+	}
+	if deliveries, _ := webhooks.ListDeliveries(ctx, deletions.ID, 10, 0); len(deliveries) != 0 {
+		t.Errorf("unsubscribed webhook has %d deliveries, want 0", len(deliveries))
+	}
+}
+
+func TestWebhookUseCase_DeliverRetries(t *testing.T) {
+	ctx := context.Background()
+	sender := &recordingSender{failing: true}
+	webhooks := NewWebhookUseCase(database.NewMockWebhookRepository(), sender, testWebhookOptions, logger.New())
+	webhook, _ := webhooks.CreateWebhook(ctx, "https://example.com/hooks", nil, "usr_admin")
+	if err := webhooks.Enqueue(ctx, events.NewEvent(events.UserCreated, "usr_1", nil)); err != nil {
+		t.Fatalf("Enqueue() unexpected error: %v", err)
+	}
+
+	now := time.Now()
+	if _, err := webhooks.DeliverDue(ctx, now); err != nil {
+		t.Fatalf("DeliverDue() unexpected error: %v", err)
+	}
+	deliveries, _ := webhooks.ListDeliveries(ctx, webhook.ID, 10, 0)
+	first := deliveries[0]
+	if first.Status != entities.WebhookDeliveryPending || first.NextAttemptAt == nil ||
+		first.NextAttemptAt.Sub(first.Attempts[0].At) != time.Minute || first.Attempts[0].Error != "connection refused" {
+		t.Fatalf("delivery = %+v, want a failed attempt retried a minute later", first)
+	}
+
+	if _, err := webhooks.DeliverDue(ctx, now); err != nil || len(sender.sent) != 1 {
+		t.Fatalf("DeliverDue() sent %d, %v, want the backing off delivery left alone", len(sender.sent), err)
+	}
+
+	sender.failing, sender.status = false, 500
+	for range 2 {
+		now = now.Add(time.Hour)
+		if _, err := webhooks.DeliverDue(ctx, now); err != nil {
+			t.Fatalf("DeliverDue() unexpected error: %v", err)
+		}
+	}
+	deliveries, _ = webhooks.ListDeliveries(ctx, webhook.ID, 10, 0)
+	last := deliveries[0]
+	if last.Status != entities.WebhookDeliveryFailed || last.NextAttemptAt != nil || len(last.Attempts) != 3 ||
+		last.Attempts[2].StatusCode != 500 {
+		t.Errorf("delivery = %+v, want it failed after 3 attempts", last)
+	}
+
+	if _, err := webhooks.DeliverDue(ctx, now.Add(24*time.Hour)); err != nil || len(sender.sent) != 3 {
+		t.Errorf("DeliverDue() sent %d, %v, want the failed delivery not retried", len(sender.sent), err)
+	}
+}
+
+func TestWebhookUseCase_DeliverToInactiveWebhook(t *testing.T) {
+	ctx := context.Background()
+	sender := &recordingSender{status: 200}
+	webhooks := NewWebhookUseCase(database.NewMockWebhookRepository(), sender, testWebhookOptions, logger.New())
+	webhook, _ := webhooks.CreateWebhook(ctx, "https://example.com/hooks", nil, "usr_admin")
+	if err := webhooks.Enqueue(ctx, events.NewEvent(events.UserCreated, "usr_1", nil)); err != nil {
+		t.Fatalf("Enqueue() unexpected error: %v", err)
+	}
+
+	inactive := false
+	if _, err := webhooks.UpdateWebhook(ctx, webhook.ID, WebhookUpdate{Active: &inactive}); err != nil {
+		t.Fatalf("UpdateWebhook() unexpected error: %v", err)
+	}
+	if _, err := webhooks.DeliverDue(ctx, time.Now()); err != nil {
+		t.Fatalf("DeliverDue() unexpected error: %v", err)
+	}
+
+	deliveries, _ := webhooks.ListDeliveries(ctx, webhook.ID, 10, 0)
+	if len(sender.sent) != 0 || deliveries[0].Status != entities.WebhookDeliveryFailed ||
+		deliveries[0].Attempts[0].Error != "webhook is inactive" {
+		t.Errorf("delivery = %+v, sent %d, want it failed without sending", deliveries[0], len(sender.sent))
+	}
+}
+
+func TestWebhookOptions_Backoff(t *testing.T) {
+	opts := WebhookOptions{InitialBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+	for attempt, want := range map[int]time.Duration{
+		1: 30 * time.Second,
+		2: time.Minute,
+		4: 4 * time.Minute,
+		5: 5 * time.Minute,
+		9: 5 * time.Minute,
+	} {
+		if got := opts.Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package apperrors defines errors that carry a kind and a machine-readable
+// code, so callers branch on what went wrong rather than on messages.
+//
+// Use cases and repositories declare their sentinels with NotFound,
+// Conflict, Validation or Internal and return them, wrapped or not, as
+// before: errors.Is keeps matching each sentinel, while As, KindOf and
+// CodeOf find the kind and code in any error chain. The HTTP error mapping
+// derives the response status from the kind and reports the code to
+// clients.
+package apperrors
+
+import "errors"
+
+// Kind classifies what went wrong
+type Kind string
+
+const (
+	// KindNotFound is a resource that does not exist or is not visible to
+	// the caller
+	KindNotFound Kind = "not_found"
+	// KindConflict is a change clashing with the current state, such as a
+	// duplicate
+	KindConflict Kind = "conflict"
+	// KindValidation is a request whose content is invalid
+	KindValidation Kind = "validation"
+	// KindInternal is a failure the caller cannot resolve. It is also the
+	// kind of errors not created by this package.
+	KindInternal Kind = "internal"
+)
+
+// Error is an error with a kind and a code
+type Error struct {
+	Kind Kind
+	// Code is stable and snake_case, e.g. user_not_found
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NotFound returns an error of KindNotFound
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+// Conflict returns an error of KindConflict
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// Validation returns an error of KindValidation
+func Validation(code, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+// Internal returns an error of KindInternal
+func Internal(code, message string) *Error {
+	return &Error{Kind: KindInternal, Code: code, Message: message}
+}
+
+// As returns the first Error in err's chain
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// KindOf returns the kind of the first Error in err's chain, or
+// KindInternal when there is none
+func KindOf(err error) Kind {
+	if appErr, ok := As(err); ok {
+		return appErr.Kind
+	}
+	return KindInternal
+}
+
+// CodeOf returns the code of the first Error in err's chain, or "" when
+// there is none
+func CodeOf(err error) string {
+	if appErr, ok := As(err); ok {
+		return appErr.Code
+	}
+	return ""
+}
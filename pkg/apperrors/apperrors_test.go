@@ -0,0 +1,47 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError(t *testing.T) {
+	errNotFound := NotFound("user_not_found", "user not found")
+	err := fmt.Errorf("failed to get user: %w", errNotFound)
+
+	assert.ErrorIs(t, err, errNotFound)
+	assert.NotErrorIs(t, err, NotFound("user_not_found", "user not found"), "sentinels match by identity")
+	assert.Equal(t, "failed to get user: user not found", err.Error())
+
+	appErr, ok := As(err)
+	require.True(t, ok)
+	assert.Same(t, errNotFound, appErr)
+	assert.Equal(t, KindNotFound, KindOf(err))
+	assert.Equal(t, "user_not_found", CodeOf(err))
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected Kind
+		code     string
+	}{
+		{name: "conflict", err: Conflict("user_exists", "user exists"), expected: KindConflict, code: "user_exists"},
+		{name: "validation", err: Validation("name_required", "name is required"), expected: KindValidation, code: "name_required"},
+		{name: "internal", err: Internal("upload_conflict", "upload was modified"), expected: KindInternal, code: "upload_conflict"},
+		{name: "plain error", err: errors.New("connection refused"), expected: KindInternal},
+		{name: "nil", err: nil, expected: KindInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, KindOf(tt.err))
+			assert.Equal(t, tt.code, CodeOf(tt.err))
+		})
+	}
+}
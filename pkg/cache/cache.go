@@ -0,0 +1,256 @@
+// Package cache provides a generic, concurrency-safe in-memory cache. Keys
+// are spread over independently locked shards; each shard evicts its least
+// recently used entries once full, and entries expire after a TTL. Loads
+// through GetOrLoad are deduplicated per key so a cold or expired entry is
+// fetched once no matter how many callers ask for it at the same time.
+//
+// It is meant as the single-process fallback for caches, rate limiters and
+// idempotency stores when no shared store such as Redis is configured.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"sync/atomic"
+	"time"
+
+	"clean-architecture/pkg/cost"
+)
+
+// Defaults applied to zero Options values
+const (
+	DefaultShards     = 16
+	DefaultMaxEntries = 10000
+)
+
+// Options configures a Cache
+type Options struct {
+	// Name identifies the cache in metrics
+	Name string
+	// Shards is the number of independently locked partitions, rounded up
+	// to a power of two. Zero uses DefaultShards.
+	Shards int
+	// MaxEntries bounds the number of entries across all shards. Zero uses
+	// DefaultMaxEntries.
+	MaxEntries int
+	// TTL is how long entries live when set without an explicit TTL. Zero
+	// keeps them until they are evicted.
+	TTL time.Duration
+}
+
+// Stats are cumulative counters of a cache
+type Stats struct {
+	Hits       uint64
+	Misses     uint64
+	Evictions  uint64
+	Expired    uint64
+	LoadErrors uint64
+	Entries    int
+}
+
+// Cache is a sharded TTL and LRU cache
+type Cache[K comparable, V any] struct {
+	name   string
+	ttl    time.Duration
+	shards []*shard[K, V]
+	mask   uint64
+	seed   maphash.Seed
+	now    func() time.Time
+
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+	evictions  atomic.Uint64
+	expired    atomic.Uint64
+	loadErrors atomic.Uint64
+}
+
+// New creates a cache
+func New[K comparable, V any](opts Options) *Cache[K, V] {
+	if opts.Shards <= 0 {
+		opts.Shards = DefaultShards
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+
+	n := 1
+	for n < opts.Shards {
+		n <<= 1
+	}
+	perShard := (opts.MaxEntries + n - 1) / n
+
+	c := &Cache[K, V]{
+		name:   opts.Name,
+		ttl:    opts.TTL,
+		shards: make([]*shard[K, V], n),
+		mask:   uint64(n - 1),
+		seed:   maphash.MakeSeed(),
+		now:    time.Now,
+	}
+	for i := range c.shards {
+		c.shards[i] = newShard[K, V](perShard)
+	}
+	return c
+}
+
+// Name returns the name the cache reports metrics under
+func (c *Cache[K, V]) Name() string {
+	return c.name
+}
+
+// Get returns the live value stored under key
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	value, ok, expired := c.shardFor(key).get(key, c.now())
+	if expired {
+		c.expired.Add(1)
+	}
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return value, ok
+}
+
+// Set stores value under key for the default TTL
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores value under key for ttl. A ttl of zero or less keeps the
+// entry until it is evicted.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+	if c.shardFor(key).set(key, value, expiresAt) {
+		c.evictions.Add(1)
+	}
+}
+
+// Delete removes key
+func (c *Cache[K, V]) Delete(key K) {
+	c.shardFor(key).delete(key)
+}
+
+// GetOrLoad returns the value stored under key, calling load to fetch and
+// store it on a miss. Concurrent misses for the same key share a single
+// call to load. The load runs detached from the cancellation of the caller
+// that started it, so one caller giving up does not fail the others; each
+// caller still stops waiting when its own ctx is done. Errors are returned
+// to every waiting caller and not cached. Hits and misses count towards the
+// cost.Counter of ctx.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, load func(ctx context.Context) (V, error)) (V, error) {
+	if value, ok := c.Get(key); ok {
+		cost.AddCacheHit(ctx)
+		return value, nil
+	}
+	cost.AddCacheMiss(ctx)
+
+	s := c.shardFor(key)
+	call, leader := s.join(key)
+	if leader {
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					call.err = fmt.Errorf("cache: load panicked: %v", r)
+				}
+				if call.err != nil {
+					c.loadErrors.Add(1)
+				} else {
+					c.Set(key, call.value)
+				}
+				s.leave(key)
+				close(call.done)
+			}()
+			call.value, call.err = load(context.WithoutCancel(ctx))
+		}()
+	}
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// Len returns the number of stored entries, including expired entries not
+// yet removed
+func (c *Cache[K, V]) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.len()
+	}
+	return n
+}
+
+// Clear removes every entry
+func (c *Cache[K, V]) Clear() {
+	for _, s := range c.shards {
+		s.clear()
+	}
+}
+
+// DeleteExpired removes every expired entry and returns how many were
+// removed. Expired entries are otherwise removed when they are read or
+// evicted.
+func (c *Cache[K, V]) DeleteExpired() int {
+	now := c.now()
+	removed := 0
+	for _, s := range c.shards {
+		removed += s.deleteExpired(now)
+	}
+	c.expired.Add(uint64(removed))
+	return removed
+}
+
+// Stats returns the cumulative counters of the cache
+func (c *Cache[K, V]) Stats() Stats {
+	return Stats{
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+		Evictions:  c.evictions.Load(),
+		Expired:    c.expired.Load(),
+		LoadErrors: c.loadErrors.Load(),
+		Entries:    c.Len(),
+	}
+}
+
+func (c *Cache[K, V]) shardFor(key K) *shard[K, V] {
+	return c.shards[c.hash(key)&c.mask]
+}
+
+// hash hashes common key types directly and falls back to their formatted
+// value for other comparable types
+func (c *Cache[K, V]) hash(key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(c.seed, k)
+	case int:
+		return mix(uint64(k))
+	case int64:
+		return mix(uint64(k))
+	case uint64:
+		return mix(k)
+	case int32:
+		return mix(uint64(k))
+	case uint32:
+		return mix(uint64(k))
+	default:
+		return maphash.String(c.seed, fmt.Sprintf("%#v", key))
+	}
+}
+
+// mix spreads integer keys over the shards (splitmix64 finalizer)
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,227 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/cost"
+)
+
+func newTestCache(opts Options) (*Cache[string, int], *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := New[string, int](opts)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestCache_GetSet(t *testing.T) {
+	c, _ := newTestCache(Options{})
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	c.Set("a", 1)
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	c.Set("a", 2)
+	value, _ = c.Get("a")
+	assert.Equal(t, 2, value)
+
+	c.Delete("a")
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+}
+
+func TestCache_TTL(t *testing.T) {
+	c, now := newTestCache(Options{TTL: time.Minute})
+
+	c.Set("default", 1)
+	c.SetWithTTL("short", 2, time.Second)
+	c.SetWithTTL("forever", 3, 0)
+
+	*now = now.Add(30 * time.Second)
+	_, ok := c.Get("short")
+	assert.False(t, ok, "short TTL entry should have expired")
+	_, ok = c.Get("default")
+	assert.True(t, ok)
+
+	*now = now.Add(time.Minute)
+	assert.Equal(t, 1, c.DeleteExpired())
+	_, ok = c.Get("forever")
+	assert.True(t, ok)
+	assert.Equal(t, 1, c.Len())
+	assert.Equal(t, uint64(2), c.Stats().Expired)
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, _ := newTestCache(Options{Shards: 1, MaxEntries: 2})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a") // a is now more recently used than b
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok, "least recently used entry should be evicted")
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), c.Stats().Evictions)
+}
+
+func TestCache_BoundsEntriesAcrossShards(t *testing.T) {
+	c := New[int, int](Options{Shards: 4, MaxEntries: 100})
+	for i := 0; i < 1000; i++ {
+		c.Set(i, i)
+	}
+	assert.LessOrEqual(t, c.Len(), 100)
+}
+
+func TestCache_GetOrLoad_DeduplicatesConcurrentMisses(t *testing.T) {
+	c := New[string, int](Options{})
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(ctx context.Context) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	const callers = 50
+	var wg sync.WaitGroup
+	results := make([]int, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, err := c.GetOrLoad(context.Background(), "key", load)
+			assert.NoError(t, err)
+			results[i] = value
+		}(i)
+	}
+
+	// Let every caller reach the in-flight load before it completes
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load())
+	for _, value := range results {
+		assert.Equal(t, 42, value)
+	}
+
+	value, err := c.GetOrLoad(context.Background(), "key", func(ctx context.Context) (int, error) {
+		t.Fatal("cached value should not be loaded again")
+		return 0, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 42, value)
+}
+
+func TestCache_GetOrLoad_ErrorsAreNotCached(t *testing.T) {
+	c := New[string, int](Options{})
+	failure := errors.New("backend down")
+
+	_, err := c.GetOrLoad(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 0, failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	value, err := c.GetOrLoad(context.Background(), "key", func(ctx context.Context) (int, error) {
+		return 7, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 7, value)
+	assert.Equal(t, uint64(1), c.Stats().LoadErrors)
+}
+
+func TestCache_GetOrLoad_CallerCancellation(t *testing.T) {
+	c := New[string, int](Options{})
+	release := make(chan struct{})
+	loaded := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.GetOrLoad(ctx, "key", func(ctx context.Context) (int, error) {
+		defer close(loaded)
+		<-release
+		// The load is detached from the caller that gave up
+		return 1, ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(release)
+	<-loaded
+	assert.Eventually(t, func() bool {
+		value, ok := c.Get("key")
+		return ok && value == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestCache_GetOrLoad_RecoversPanics(t *testing.T) {
+	c := New[string, int](Options{})
+
+	_, err := c.GetOrLoad(context.Background(), "key", func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestCache_GetOrLoad_CountsRequestCosts(t *testing.T) {
+	c := New[string, int](Options{})
+	counter := cost.NewCounter()
+	ctx := cost.WithCounter(context.Background(), counter)
+	load := func(ctx context.Context) (int, error) { return 1, nil }
+
+	for i := 0; i < 3; i++ {
+		_, err := c.GetOrLoad(ctx, "key", load)
+		require.NoError(t, err)
+	}
+
+	costs := counter.Costs()
+	assert.Equal(t, int64(1), costs.CacheMisses)
+	assert.Equal(t, int64(2), costs.CacheHits)
+}
+
+func TestCollector(t *testing.T) {
+	c, _ := newTestCache(Options{Name: "users"})
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("b")
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewCollector(c))
+
+	expected := `
+# HELP cache_entries Number of entries currently stored.
+# TYPE cache_entries gauge
+cache_entries{cache="users"} 1
+# HELP cache_hits_total Total number of cache lookups that found a live entry.
+# TYPE cache_hits_total counter
+cache_hits_total{cache="users"} 1
+# HELP cache_misses_total Total number of cache lookups that found no live entry.
+# TYPE cache_misses_total counter
+cache_misses_total{cache="users"} 1
+`
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "cache_entries", "cache_hits_total", "cache_misses_total")
+	assert.NoError(t, err)
+}
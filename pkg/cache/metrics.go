@@ -0,0 +1,66 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// StatsSource is a cache whose counters can be exported
+type StatsSource interface {
+	Name() string
+	Stats() Stats
+}
+
+// Collector exports the counters of caches as Prometheus metrics labelled
+// by cache name
+type Collector struct {
+	caches []StatsSource
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	evictions  *prometheus.Desc
+	expired    *prometheus.Desc
+	loadErrors *prometheus.Desc
+	entries    *prometheus.Desc
+}
+
+// NewCollector creates a collector for caches
+func NewCollector(caches ...StatsSource) *Collector {
+	labels := []string{"cache"}
+	return &Collector{
+		caches: caches,
+		hits: prometheus.NewDesc("cache_hits_total",
+			"Total number of cache lookups that found a live entry.", labels, nil),
+		misses: prometheus.NewDesc("cache_misses_total",
+			"Total number of cache lookups that found no live entry.", labels, nil),
+		evictions: prometheus.NewDesc("cache_evictions_total",
+			"Total number of entries evicted to make room for new ones.", labels, nil),
+		expired: prometheus.NewDesc("cache_expired_total",
+			"Total number of entries removed after their TTL passed.", labels, nil),
+		loadErrors: prometheus.NewDesc("cache_load_errors_total",
+			"Total number of loads on a miss that failed.", labels, nil),
+		entries: prometheus.NewDesc("cache_entries",
+			"Number of entries currently stored.", labels, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.evictions
+	ch <- c.expired
+	ch <- c.loadErrors
+	ch <- c.entries
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, cache := range c.caches {
+		s := cache.Stats()
+		name := cache.Name()
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(s.Hits), name)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(s.Misses), name)
+		ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(s.Evictions), name)
+		ch <- prometheus.MustNewConstMetric(c.expired, prometheus.CounterValue, float64(s.Expired), name)
+		ch <- prometheus.MustNewConstMetric(c.loadErrors, prometheus.CounterValue, float64(s.LoadErrors), name)
+		ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(s.Entries), name)
+	}
+}
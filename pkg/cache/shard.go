@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry is a stored value. A zero expiresAt never expires.
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// call is a load in flight that concurrent misses wait on
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// shard is one LRU partition of a cache. The list is ordered from most to
+// least recently used.
+type shard[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[K]*list.Element
+	order    *list.List
+	calls    map[K]*call[V]
+}
+
+func newShard[K comparable, V any](capacity int) *shard[K, V] {
+	return &shard[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+		calls:    make(map[K]*call[V]),
+	}
+}
+
+// get returns the live value under key, removing it when it has expired
+func (s *shard[K, V]) get(key K, now time.Time) (value V, ok, expired bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, found := s.items[key]
+	if !found {
+		return value, false, false
+	}
+	e := el.Value.(*entry[K, V])
+	if e.expired(now) {
+		s.remove(el)
+		return value, false, true
+	}
+	s.order.MoveToFront(el)
+	return e.value, true, false
+}
+
+// set stores value under key and reports whether another entry was evicted
+// to make room
+func (s *shard[K, V]) set(key K, value V, expiresAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, found := s.items[key]; found {
+		e := el.Value.(*entry[K, V])
+		e.value, e.expiresAt = value, expiresAt
+		s.order.MoveToFront(el)
+		return false
+	}
+
+	s.items[key] = s.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if s.order.Len() <= s.capacity {
+		return false
+	}
+	s.remove(s.order.Back())
+	return true
+}
+
+func (s *shard[K, V]) delete(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, found := s.items[key]; found {
+		s.remove(el)
+	}
+}
+
+func (s *shard[K, V]) deleteExpired(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for el := s.order.Back(); el != nil; {
+		prev := el.Prev()
+		if el.Value.(*entry[K, V]).expired(now) {
+			s.remove(el)
+			removed++
+		}
+		el = prev
+	}
+	return removed
+}
+
+func (s *shard[K, V]) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *shard[K, V]) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[K]*list.Element)
+	s.order.Init()
+}
+
+// join returns the load in flight for key, starting one when there is none.
+// leader reports whether the caller must perform the load.
+func (s *shard[K, V]) join(key K) (c *call[V], leader bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.calls[key]; ok {
+		return c, false
+	}
+	c = &call[V]{done: make(chan struct{})}
+	s.calls[key] = c
+	return c, true
+}
+
+// leave forgets the load in flight for key
+func (s *shard[K, V]) leave(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.calls, key)
+}
+
+// remove must be called with mu held
+func (s *shard[K, V]) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.items, el.Value.(*entry[K, V]).key)
+}
@@ -0,0 +1,132 @@
+// Package canary runs in-process experiments: it routes a share of the
+// requests of a route, or the requests tagged with a header, to an
+// alternative implementation of its handler, and exports the outcome of
+// each variant as Prometheus metrics so they can be compared before the
+// candidate replaces the control.
+package canary
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"clean-architecture/pkg/httpserver"
+)
+
+// Variants an experiment serves
+const (
+	VariantControl   = "control"
+	VariantCandidate = "candidate"
+)
+
+// VariantHeader is the response header naming the variant that served the
+// request
+const VariantHeader = "X-Canary-Variant"
+
+// Options configures Experiments
+type Options struct {
+	// Header names the request header forcing a variant, as in
+	// "X-Canary: candidate"; empty ignores tags
+	Header string
+	// Key returns what keeps a client on one variant, such as its user ID,
+	// so its requests are not split between implementations. Requests
+	// without a key are assigned at random; nil assigns every request at
+	// random.
+	Key func(r *http.Request) string
+}
+
+// Experiments splits the traffic of the routes it wraps between their
+// control and candidate handlers
+type Experiments struct {
+	opts     Options
+	rollouts map[string]float64
+	metrics  *collector
+}
+
+// New creates experiments sending rollouts[name] percent of the traffic of
+// the experiment called name to its candidate. Experiments missing from
+// rollouts only serve their candidate to tagged requests.
+func New(rollouts map[string]float64, opts Options) *Experiments {
+	copied := make(map[string]float64, len(rollouts))
+	for name, percent := range rollouts {
+		copied[name] = percent
+	}
+	return &Experiments{opts: opts, rollouts: copied, metrics: newCollector()}
+}
+
+// Percent returns the share of traffic, from 0 to 100, the experiment
+// called name sends to its candidate
+func (e *Experiments) Percent(name string) float64 {
+	return e.rollouts[name]
+}
+
+// Split returns a handler serving requests with control or candidate,
+// depending on the variant the request is assigned for the experiment
+// called name. Both handlers must answer the same requests.
+func (e *Experiments) Split(name string, control, candidate http.Handler) http.Handler {
+	e.metrics.add(name, e.Percent(name))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		variant := e.Variant(name, r)
+		handler := control
+		if variant == VariantCandidate {
+			handler = candidate
+		}
+
+		w.Header().Set(VariantHeader, variant)
+		start := time.Now()
+		sw := httpserver.NewStatusWriter(w)
+		handler.ServeHTTP(sw, r)
+		e.metrics.observe(name, variant, sw.Status, time.Since(start))
+	})
+}
+
+// Middleware returns the Split of the experiment called name between the
+// next handler, its control, and candidate
+func (e *Experiments) Middleware(name string, candidate http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return e.Split(name, next, candidate)
+	}
+}
+
+// Variant returns the variant r is assigned for the experiment called
+// name. A tag in the request header wins; otherwise clients with a key
+// land in the same bucket of the experiment on every request, and the
+// lowest Percent(name) of the buckets get the candidate.
+func (e *Experiments) Variant(name string, r *http.Request) string {
+	if e.opts.Header != "" {
+		switch tag := r.Header.Get(e.opts.Header); tag {
+		case VariantControl, VariantCandidate:
+			return tag
+		}
+	}
+
+	percent := e.Percent(name)
+	if percent <= 0 {
+		return VariantControl
+	}
+	if bucket(name, e.key(r)) < percent {
+		return VariantCandidate
+	}
+	return VariantControl
+}
+
+func (e *Experiments) key(r *http.Request) string {
+	if e.opts.Key == nil {
+		return ""
+	}
+	return e.opts.Key(r)
+}
+
+// bucket places key in [0, 100) for the experiment called name. Hashing
+// the name with the key gives each experiment its own share of clients.
+func bucket(name, key string) float64 {
+	if key == "" {
+		return rand.Float64() * 100
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) / 100
+}
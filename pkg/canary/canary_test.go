@@ -0,0 +1,102 @@
+package canary
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func respond(body string, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	})
+}
+
+func userKey(r *http.Request) string {
+	return r.Header.Get("X-User")
+}
+
+func TestSplit_Header(t *testing.T) {
+	e := New(nil, Options{Header: "X-Canary", Key: userKey})
+	h := e.Split("users.list", respond("v1", http.StatusOK), respond("v2", http.StatusOK))
+
+	for tag, want := range map[string]string{"": "v1", "control": "v1", "candidate": "v2", "v3": "v1"} {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("X-Canary", tag)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		assert.Equal(t, want, w.Body.String(), "tag %q", tag)
+		assert.Equal(t, map[string]string{"v1": VariantControl, "v2": VariantCandidate}[want], w.Header().Get(VariantHeader))
+	}
+}
+
+func TestSplit_Percent(t *testing.T) {
+	e := New(map[string]float64{"users.list": 25}, Options{Key: userKey})
+	h := e.Split("users.list", respond("v1", http.StatusOK), respond("v2", http.StatusOK))
+
+	candidates := 0
+	for i := 0; i < 2000; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("X-User", fmt.Sprintf("user_%d", i))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Body.String() == "v2" {
+			candidates++
+		}
+	}
+	assert.InDelta(t, 500, candidates, 100, "about a quarter of users get the candidate")
+}
+
+func TestVariant_Sticky(t *testing.T) {
+	e := New(map[string]float64{"users.list": 50}, Options{Key: userKey})
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("X-User", "user_42")
+
+	first := e.Variant("users.list", req)
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, first, e.Variant("users.list", req), "a user stays on one variant")
+	}
+}
+
+func TestVariant_Bounds(t *testing.T) {
+	e := New(map[string]float64{"all": 100, "none": 0}, Options{})
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, VariantCandidate, e.Variant("all", req))
+		assert.Equal(t, VariantControl, e.Variant("none", req))
+		assert.Equal(t, VariantControl, e.Variant("unconfigured", req))
+	}
+}
+
+func TestCollect(t *testing.T) {
+	e := New(map[string]float64{"users.list": 100}, Options{Header: "X-Canary"})
+	h := e.Split("users.list", respond("v1", http.StatusOK), respond("v2", http.StatusInternalServerError))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("X-Canary", VariantControl)
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(e))
+	err := testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP canary_requests_total Requests served by each variant of an experiment, by status.
+# TYPE canary_requests_total counter
+canary_requests_total{experiment="users.list",status="200",variant="control"} 1
+canary_requests_total{experiment="users.list",status="500",variant="candidate"} 1
+# HELP canary_rollout_percent Share of the traffic of an experiment assigned to its candidate, from 0 to 100.
+# TYPE canary_rollout_percent gauge
+canary_rollout_percent{experiment="users.list"} 100
+`), "canary_requests_total", "canary_rollout_percent")
+	assert.NoError(t, err)
+}
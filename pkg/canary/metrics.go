@@ -0,0 +1,70 @@
+package canary
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collector holds the metrics of the experiments, labelled by experiment
+// and variant so the candidate can be compared with the control
+type collector struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	rollout  *prometheus.Desc
+
+	mu       sync.RWMutex
+	percents map[string]float64
+}
+
+func newCollector() *collector {
+	return &collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "canary_requests_total",
+			Help: "Requests served by each variant of an experiment, by status.",
+		}, []string{"experiment", "variant", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "canary_request_duration_seconds",
+			Help:    "Latency of the requests served by each variant of an experiment.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"experiment", "variant"}),
+		rollout: prometheus.NewDesc("canary_rollout_percent",
+			"Share of the traffic of an experiment assigned to its candidate, from 0 to 100.",
+			[]string{"experiment"}, nil),
+		percents: make(map[string]float64),
+	}
+}
+
+// add records an experiment that is serving requests
+func (c *collector) add(name string, percent float64) {
+	c.mu.Lock()
+	c.percents[name] = percent
+	c.mu.Unlock()
+}
+
+func (c *collector) observe(name, variant string, status int, duration time.Duration) {
+	c.requests.WithLabelValues(name, variant, strconv.Itoa(status)).Inc()
+	c.duration.WithLabelValues(name, variant).Observe(duration.Seconds())
+}
+
+// Describe implements prometheus.Collector
+func (e *Experiments) Describe(ch chan<- *prometheus.Desc) {
+	e.metrics.requests.Describe(ch)
+	e.metrics.duration.Describe(ch)
+	ch <- e.metrics.rollout
+}
+
+// Collect implements prometheus.Collector
+func (e *Experiments) Collect(ch chan<- prometheus.Metric) {
+	c := e.metrics
+	c.requests.Collect(ch)
+	c.duration.Collect(ch)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, percent := range c.percents {
+		ch <- prometheus.MustNewConstMetric(c.rollout, prometheus.GaugeValue, percent, name)
+	}
+}
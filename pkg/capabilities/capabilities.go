@@ -0,0 +1,70 @@
+package capabilities
+
+import (
+	"sort"
+	"sync"
+)
+
+// Capability describes whether an optional subsystem is available in this
+// deployment, with any client-relevant settings it exposes
+type Capability struct {
+	Enabled bool                   `json:"enabled"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Registry collects the capabilities reported by each module so clients can
+// feature-detect instead of hardcoding assumptions about a deployment
+type Registry struct {
+	mu           sync.RWMutex
+	capabilities map[string]Capability
+}
+
+// NewRegistry creates an empty capability registry
+func NewRegistry() *Registry {
+	return &Registry{capabilities: make(map[string]Capability)}
+}
+
+// Register records a capability, replacing any earlier registration under
+// the same name
+func (r *Registry) Register(name string, enabled bool, details map[string]interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.capabilities[name] = Capability{Enabled: enabled, Details: details}
+}
+
+// Get returns the capability registered under name
+func (r *Registry) Get(name string) (Capability, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.capabilities[name]
+	return c, ok
+}
+
+// Enabled reports whether the named capability is registered and enabled
+func (r *Registry) Enabled(name string) bool {
+	c, ok := r.Get(name)
+	return ok && c.Enabled
+}
+
+// Names returns the registered capability names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.capabilities))
+	for name := range r.capabilities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// All returns a snapshot of every registered capability keyed by name
+func (r *Registry) All() map[string]Capability {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	all := make(map[string]Capability, len(r.capabilities))
+	for name, c := range r.capabilities {
+		all[name] = c
+	}
+	return all
+}
@@ -0,0 +1,29 @@
+package capabilities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Register("webhooks", false, nil)
+	r.Register("authorization", true, map[string]interface{}{"driver": "builtin"})
+
+	assert.True(t, r.Enabled("authorization"))
+	assert.False(t, r.Enabled("webhooks"))
+	assert.False(t, r.Enabled("search"))
+	assert.Equal(t, []string{"authorization", "webhooks"}, r.Names())
+
+	c, ok := r.Get("authorization")
+	assert.True(t, ok)
+	assert.Equal(t, "builtin", c.Details["driver"])
+
+	r.Register("webhooks", true, nil)
+	assert.True(t, r.Enabled("webhooks"))
+
+	all := r.All()
+	delete(all, "webhooks")
+	assert.True(t, r.Enabled("webhooks"), "All must return a copy")
+}
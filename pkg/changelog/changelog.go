@@ -0,0 +1,185 @@
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Change types
+const (
+	Added      = "added"
+	Changed    = "changed"
+	Deprecated = "deprecated"
+	Removed    = "removed"
+	Fixed      = "fixed"
+)
+
+var changeTypes = map[string]bool{
+	Added:      true,
+	Changed:    true,
+	Deprecated: true,
+	Removed:    true,
+	Fixed:      true,
+}
+
+// Change is a single client-visible change to the API
+type Change struct {
+	Type string `json:"type"`
+	// Endpoint is the affected route, e.g. "GET /api/v1/users". Empty for
+	// changes that apply to every endpoint.
+	Endpoint    string `json:"endpoint,omitempty"`
+	Description string `json:"description"`
+	// Breaking marks changes that may require clients to be updated
+	Breaking bool `json:"breaking"`
+}
+
+// Release groups the changes shipped in one API version
+type Release struct {
+	Version string   `json:"version"`
+	Date    string   `json:"date"`
+	Changes []Change `json:"changes"`
+}
+
+// Deprecation describes an endpoint or behavior scheduled for removal
+type Deprecation struct {
+	Endpoint    string `json:"endpoint"`
+	Since       string `json:"since"`
+	RemovalIn   string `json:"removal_in,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+	Description string `json:"description"`
+}
+
+// Changelog is the machine-readable history of the API, newest release first
+type Changelog struct {
+	CurrentVersion string        `json:"current_version"`
+	Releases       []Release     `json:"releases"`
+	Deprecations   []Deprecation `json:"deprecations"`
+}
+
+// Parse decodes and validates a JSON changelog. Unknown fields are rejected
+// so typos in the source file are caught at startup.
+func Parse(data []byte) (*Changelog, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var c Changelog
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("changelog: %w", err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("changelog: %w", err)
+	}
+	if c.Deprecations == nil {
+		c.Deprecations = []Deprecation{}
+	}
+	return &c, nil
+}
+
+// Since returns a copy of the changelog holding only releases newer than
+// version. Deprecations are always kept since they may still be pending.
+func (c *Changelog) Since(version string) (*Changelog, error) {
+	since, err := parseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make([]Release, 0, len(c.Releases))
+	for _, release := range c.Releases {
+		v, _ := parseVersion(release.Version)
+		if compareVersions(v, since) > 0 {
+			releases = append(releases, release)
+		}
+	}
+
+	return &Changelog{
+		CurrentVersion: c.CurrentVersion,
+		Releases:       releases,
+		Deprecations:   c.Deprecations,
+	}, nil
+}
+
+func (c *Changelog) validate() error {
+	if len(c.Releases) == 0 {
+		return fmt.Errorf("at least one release is required")
+	}
+	if c.CurrentVersion != c.Releases[0].Version {
+		return fmt.Errorf("current_version %q must match the newest release %q", c.CurrentVersion, c.Releases[0].Version)
+	}
+
+	known := make(map[string]bool, len(c.Releases))
+	var previous [3]int
+	for i, release := range c.Releases {
+		v, err := parseVersion(release.Version)
+		if err != nil {
+			return err
+		}
+		if i > 0 && compareVersions(v, previous) >= 0 {
+			return fmt.Errorf("release %s: releases must be listed newest first", release.Version)
+		}
+		previous = v
+		known[release.Version] = true
+
+		if _, err := time.Parse(time.DateOnly, release.Date); err != nil {
+			return fmt.Errorf("release %s: invalid date %q", release.Version, release.Date)
+		}
+		for _, change := range release.Changes {
+			if !changeTypes[change.Type] {
+				return fmt.Errorf("release %s: unknown change type %q", release.Version, change.Type)
+			}
+			if change.Description == "" {
+				return fmt.Errorf("release %s: change description is required", release.Version)
+			}
+		}
+	}
+
+	for _, deprecation := range c.Deprecations {
+		if deprecation.Endpoint == "" {
+			return fmt.Errorf("deprecation endpoint is required")
+		}
+		if !known[deprecation.Since] {
+			return fmt.Errorf("deprecation of %s: since %q is not a listed release", deprecation.Endpoint, deprecation.Since)
+		}
+		if deprecation.RemovalIn != "" {
+			if _, err := parseVersion(deprecation.RemovalIn); err != nil {
+				return fmt.Errorf("deprecation of %s: %w", deprecation.Endpoint, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseVersion parses a MAJOR.MINOR.PATCH version
+func parseVersion(version string) ([3]int, error) {
+	var v [3]int
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) != 3 {
+		return v, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", version)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q: expected MAJOR.MINOR.PATCH", version)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// compareVersions returns -1, 0 or 1 as a is older than, equal to or newer
+// than b
+func compareVersions(a, b [3]int) int {
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,112 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `{
+  "current_version": "1.2.0",
+  "releases": [
+    {"version": "1.2.0", "date": "2024-03-01", "changes": [
+      {"type": "deprecated", "endpoint": "GET /api/v1/legacy", "description": "Use /api/v1/modern", "breaking": false}
+    ]},
+    {"version": "1.1.0", "date": "2024-02-01", "changes": [
+      {"type": "added", "endpoint": "GET /api/v1/modern", "description": "New endpoint", "breaking": false}
+    ]},
+    {"version": "1.0.0", "date": "2024-01-01", "changes": [
+      {"type": "added", "description": "Initial release", "breaking": false}
+    ]}
+  ],
+  "deprecations": [
+    {"endpoint": "GET /api/v1/legacy", "since": "1.2.0", "removal_in": "2.0.0", "replacement": "GET /api/v1/modern", "description": "Superseded"}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(sample))
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.2.0", c.CurrentVersion)
+	assert.Len(t, c.Releases, 3)
+	require.Len(t, c.Deprecations, 1)
+	assert.Equal(t, "2.0.0", c.Deprecations[0].RemovalIn)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "unknown field",
+			input:    `{"current_version": "1.0.0", "releases": [{"version": "1.0.0", "date": "2024-01-01", "changes": []}], "extra": true}`,
+			expected: `unknown field "extra"`,
+		},
+		{
+			name:     "no releases",
+			input:    `{"current_version": "1.0.0", "releases": []}`,
+			expected: "at least one release is required",
+		},
+		{
+			name:     "current version mismatch",
+			input:    `{"current_version": "2.0.0", "releases": [{"version": "1.0.0", "date": "2024-01-01", "changes": []}]}`,
+			expected: `current_version "2.0.0" must match the newest release "1.0.0"`,
+		},
+		{
+			name:     "out of order",
+			input:    `{"current_version": "1.0.0", "releases": [{"version": "1.0.0", "date": "2024-01-01", "changes": []}, {"version": "1.1.0", "date": "2024-02-01", "changes": []}]}`,
+			expected: "releases must be listed newest first",
+		},
+		{
+			name:     "invalid version",
+			input:    `{"current_version": "1.0", "releases": [{"version": "1.0", "date": "2024-01-01", "changes": []}]}`,
+			expected: `invalid version "1.0"`,
+		},
+		{
+			name:     "invalid date",
+			input:    `{"current_version": "1.0.0", "releases": [{"version": "1.0.0", "date": "January", "changes": []}]}`,
+			expected: `invalid date "January"`,
+		},
+		{
+			name:     "unknown change type",
+			input:    `{"current_version": "1.0.0", "releases": [{"version": "1.0.0", "date": "2024-01-01", "changes": [{"type": "tweaked", "description": "x"}]}]}`,
+			expected: `unknown change type "tweaked"`,
+		},
+		{
+			name:     "deprecation of unknown release",
+			input:    `{"current_version": "1.0.0", "releases": [{"version": "1.0.0", "date": "2024-01-01", "changes": []}], "deprecations": [{"endpoint": "GET /x", "since": "0.9.0", "description": "x"}]}`,
+			expected: `since "0.9.0" is not a listed release`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestChangelog_Since(t *testing.T) {
+	c, err := Parse([]byte(sample))
+	require.NoError(t, err)
+
+	filtered, err := c.Since("1.0.0")
+	require.NoError(t, err)
+	require.Len(t, filtered.Releases, 2)
+	assert.Equal(t, "1.2.0", filtered.Releases[0].Version)
+	assert.Equal(t, "1.1.0", filtered.Releases[1].Version)
+	assert.Len(t, filtered.Deprecations, 1)
+
+	filtered, err = c.Since("1.2.0")
+	require.NoError(t, err)
+	assert.Empty(t, filtered.Releases)
+
+	_, err = c.Since("latest")
+	assert.Error(t, err)
+}
@@ -0,0 +1,104 @@
+// Package clientip resolves the address of the client a request came from.
+//
+// Forwarding headers such as X-Forwarded-For are set by whoever sends the
+// request, so they are only believed when the connection comes from a
+// trusted proxy. Security decisions keyed on the client address, such as
+// login lockouts, use the resolved address rather than r.RemoteAddr, which
+// middleware.RealIP rewrites from those headers unconditionally.
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"clean-architecture/pkg/ctxkeys"
+)
+
+// addrKey stores the resolved client address of a request
+var addrKey = ctxkeys.NewKey[string]("client_ip")
+
+// Resolver resolves client addresses, trusting the X-Forwarded-For header
+// of connections from its proxies only
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// NewResolver creates a resolver. trustedProxies lists the CIDR ranges of
+// the proxies in front of the API; empty trusts none, so the address of the
+// connection is the client's.
+func NewResolver(trustedProxies []string) (*Resolver, error) {
+	res := &Resolver{}
+	for _, network := range trustedProxies {
+		network = strings.TrimSpace(network)
+		if network == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, fmt.Errorf("clientip: invalid trusted proxy network %q", network)
+		}
+		res.trusted = append(res.trusted, prefix.Masked())
+	}
+	return res, nil
+}
+
+// Resolve returns the address of the client of r. When the connection
+// comes from a trusted proxy, X-Forwarded-For is walked from the nearest
+// hop back and the first address that is not a trusted proxy is the
+// client's; hops a client prepended itself are never reached.
+func (res *Resolver) Resolve(r *http.Request) string {
+	peer := hostOf(r.RemoteAddr)
+	addr, err := netip.ParseAddr(peer)
+	if err != nil || !res.trusts(addr) {
+		return peer
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// A malformed hop was not added by a proxy we trust
+			break
+		}
+		if !res.trusts(hop) {
+			return hop.Unmap().String()
+		}
+		peer = hop.Unmap().String()
+	}
+	return peer
+}
+
+func (res *Resolver) trusts(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range res.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware stores the resolved client address in the request context. It
+// must run before middleware.RealIP rewrites r.RemoteAddr.
+func (res *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(addrKey.With(r.Context(), res.Resolve(r))))
+	})
+}
+
+// FromContext returns the client address stored by Middleware
+func FromContext(ctx context.Context) (string, bool) {
+	return addrKey.From(ctx)
+}
+
+// hostOf strips the port from addr
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
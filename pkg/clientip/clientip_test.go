@@ -0,0 +1,88 @@
+package clientip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	res, err := NewResolver([]string{"10.0.0.0/8", "fd00::/8"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		expected     string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.7:51000", expected: "203.0.113.7"},
+		{
+			name:         "untrusted peer cannot forward",
+			remoteAddr:   "203.0.113.7:51000",
+			forwardedFor: []string{"198.51.100.1"},
+			expected:     "203.0.113.7",
+		},
+		{
+			name:         "trusted proxy",
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"198.51.100.1"},
+			expected:     "198.51.100.1",
+		},
+		{
+			name:         "client-prepended hops are ignored",
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"192.0.2.99, 198.51.100.1, 10.0.0.3"},
+			expected:     "198.51.100.1",
+		},
+		{
+			name:         "repeated headers",
+			remoteAddr:   "[fd00::2]:443",
+			forwardedFor: []string{"192.0.2.99", "198.51.100.1"},
+			expected:     "198.51.100.1",
+		},
+		{
+			name:         "malformed hop",
+			remoteAddr:   "10.0.0.2:443",
+			forwardedFor: []string{"198.51.100.1, not-an-ip"},
+			expected:     "10.0.0.2",
+		},
+		{name: "trusted proxy without header", remoteAddr: "10.0.0.2:443", expected: "10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Real-IP", "192.0.2.200")
+			for _, value := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			assert.Equal(t, tt.expected, res.Resolve(req))
+		})
+	}
+}
+
+func TestNewResolver_InvalidNetwork(t *testing.T) {
+	_, err := NewResolver([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
+
+func TestResolver_Middleware(t *testing.T) {
+	res, err := NewResolver(nil)
+	require.NoError(t, err)
+
+	var seen string
+	handler := res.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = FromContext(r.Context())
+	}))
+	req := httptest.NewRequest("POST", "/api/v1/auth/login", nil)
+	req.RemoteAddr = "203.0.113.7:51000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "203.0.113.7", seen)
+}
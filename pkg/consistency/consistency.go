@@ -0,0 +1,92 @@
+// Package consistency provides read-your-writes consistency. Mutations return a
+// token naming the entity they changed; clients send it back on the reads that
+// follow, and for a short window those reads skip replicas and caches that
+// may not have seen the write yet.
+package consistency
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"clean-architecture/pkg/ctxkeys"
+)
+
+// Header is the HTTP header carrying the consistency token, on mutation
+// responses and on the requests that follow them
+const Header = "X-Consistency-Token"
+
+// Token records a write to an entity
+type Token struct {
+	// Resource is the kind of entity written, such as users
+	Resource string
+	ID       string
+	// WrittenAt is when the write was made
+	WrittenAt time.Time
+}
+
+var (
+	tokenKey   = ctxkeys.NewKey[Token]("consistency_token")
+	primaryKey = ctxkeys.NewKey[bool]("consistency_primary")
+)
+
+// NewToken returns the encoded token for a write to the entity
+func NewToken(resource, id string, writtenAt time.Time) string {
+	raw := resource + ":" + id + ":" + strconv.FormatInt(writtenAt.UnixMilli(), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Parse decodes a token made by NewToken
+func Parse(encoded string) (Token, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Token{}, fmt.Errorf("malformed consistency token: %w", err)
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return Token{}, fmt.Errorf("malformed consistency token")
+	}
+	millis, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return Token{}, fmt.Errorf("malformed consistency token: %w", err)
+	}
+	return Token{Resource: parts[0], ID: parts[1], WrittenAt: time.UnixMilli(millis)}, nil
+}
+
+// Fresh reports whether the write is recent enough, within window of now,
+// that replicas may still lag behind it. Tokens from the future are only
+// accepted within the same window, to allow for clock skew between
+// instances.
+func (t Token) Fresh(now time.Time, window time.Duration) bool {
+	age := now.Sub(t.WrittenAt)
+	return age <= window && age >= -window
+}
+
+// WithToken returns a copy of ctx whose reads of the token's entity must see
+// the write
+func WithToken(ctx context.Context, token Token) context.Context {
+	return tokenKey.With(ctx, token)
+}
+
+// WithPrimary returns a copy of ctx whose reads must all see the latest
+// writes, for reads that a write is based on
+func WithPrimary(ctx context.Context) context.Context {
+	return primaryKey.With(ctx, true)
+}
+
+// RequiresPrimary reports whether a read of the resource made with ctx must
+// see the latest writes. An empty id stands for reads of many entities,
+// such as lists, which must see a write to any of them.
+func RequiresPrimary(ctx context.Context, resource, id string) bool {
+	if primaryKey.Value(ctx) {
+		return true
+	}
+	token, ok := tokenKey.From(ctx)
+	if !ok || token.Resource != resource {
+		return false
+	}
+	return id == "" || token.ID == id
+}
@@ -0,0 +1,47 @@
+package consistency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToken_RoundTrip(t *testing.T) {
+	writtenAt := time.UnixMilli(1700000000123)
+
+	token, err := Parse(NewToken("users", "user_1", writtenAt))
+	require.NoError(t, err)
+	assert.Equal(t, "users", token.Resource)
+	assert.Equal(t, "user_1", token.ID)
+	assert.True(t, token.WrittenAt.Equal(writtenAt))
+
+	for _, encoded := range []string{"", "not base64!", NewToken("", "user_1", writtenAt), "dXNlcnM6dXNlcl8xOm5vdw"} {
+		_, err := Parse(encoded)
+		assert.Error(t, err, encoded)
+	}
+}
+
+func TestToken_Fresh(t *testing.T) {
+	now := time.Now()
+	token := Token{Resource: "users", ID: "user_1", WrittenAt: now.Add(-3 * time.Second)}
+
+	assert.True(t, token.Fresh(now, 5*time.Second))
+	assert.False(t, token.Fresh(now, 2*time.Second))
+	assert.False(t, Token{WrittenAt: now.Add(time.Hour)}.Fresh(now, 5*time.Second), "tokens from the future are rejected")
+}
+
+func TestRequiresPrimary(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, RequiresPrimary(ctx, "users", "user_1"))
+
+	ctx = WithToken(ctx, Token{Resource: "users", ID: "user_1", WrittenAt: time.Now()})
+	assert.True(t, RequiresPrimary(ctx, "users", "user_1"))
+	assert.True(t, RequiresPrimary(ctx, "users", ""), "lists see writes to any user")
+	assert.False(t, RequiresPrimary(ctx, "users", "user_2"))
+	assert.False(t, RequiresPrimary(ctx, "views", "user_1"))
+
+	assert.True(t, RequiresPrimary(WithPrimary(context.Background()), "views", "view_1"))
+}
@@ -0,0 +1,38 @@
+// Package correlation carries a correlation ID linking an API call to every
+// asynchronous effect it causes, across HTTP requests and messages.
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"clean-architecture/pkg/ctxkeys"
+)
+
+const (
+	// Header is the HTTP header carrying the correlation ID
+	Header = "X-Correlation-ID"
+	// MessageHeader is the message header carrying the correlation ID
+	MessageHeader = "correlation-id"
+)
+
+// WithID returns a copy of ctx carrying the correlation ID
+func WithID(ctx context.Context, id string) context.Context {
+	return ctxkeys.CorrelationID.With(ctx, id)
+}
+
+// ID returns the correlation ID carried by ctx, or an empty string
+func ID(ctx context.Context) string {
+	return ctxkeys.CorrelationID.Value(ctx)
+}
+
+// NewID generates a random correlation ID
+func NewID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "cor_" + time.Now().Format("20060102150405.000000")
+	}
+	return "cor_" + hex.EncodeToString(randBytes)
+}
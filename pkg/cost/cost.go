@@ -0,0 +1,106 @@
+// Package cost accounts for the work each request causes: the database
+// queries it runs and the rows they return, the cache lookups it makes and
+// the external calls it sends. A Counter travels in the request context
+// and the repositories and clients doing the work increment it, so the
+// access log can report the cost of every request and a Tracker can alert
+// on routes exceeding their budget, such as a list that regressed into a
+// query per row.
+package cost
+
+import (
+	"context"
+	"sync/atomic"
+
+	"clean-architecture/pkg/ctxkeys"
+)
+
+var counterKey = ctxkeys.NewKey[*Counter]("cost_counter")
+
+// Costs is a snapshot of a Counter
+type Costs struct {
+	Queries int64
+	// Rows counts the rows returned by queries, not the rows written
+	Rows          int64
+	CacheHits     int64
+	CacheMisses   int64
+	ExternalCalls int64
+}
+
+// Fields returns the costs as log fields
+func (c Costs) Fields() map[string]interface{} {
+	return map[string]interface{}{
+		"db_queries":     c.Queries,
+		"db_rows":        c.Rows,
+		"cache_hits":     c.CacheHits,
+		"cache_misses":   c.CacheMisses,
+		"external_calls": c.ExternalCalls,
+	}
+}
+
+// Counter counts the costs of one request. It is safe for concurrent use,
+// so goroutines started by a handler may count towards it too.
+type Counter struct {
+	queries       atomic.Int64
+	rows          atomic.Int64
+	cacheHits     atomic.Int64
+	cacheMisses   atomic.Int64
+	externalCalls atomic.Int64
+}
+
+// NewCounter creates a counter with every cost at zero
+func NewCounter() *Counter {
+	return &Counter{}
+}
+
+// WithCounter returns a copy of ctx carrying counter
+func WithCounter(ctx context.Context, counter *Counter) context.Context {
+	return counterKey.With(ctx, counter)
+}
+
+// FromContext returns the counter carried by ctx, or nil
+func FromContext(ctx context.Context) *Counter {
+	return counterKey.Value(ctx)
+}
+
+// Costs returns a snapshot of the counter
+func (c *Counter) Costs() Costs {
+	return Costs{
+		Queries:       c.queries.Load(),
+		Rows:          c.rows.Load(),
+		CacheHits:     c.cacheHits.Load(),
+		CacheMisses:   c.cacheMisses.Load(),
+		ExternalCalls: c.externalCalls.Load(),
+	}
+}
+
+// The helpers below count towards the counter carried by ctx and do
+// nothing without one, so instrumented code needs no checks.
+
+// AddQuery counts a database query returning rows
+func AddQuery(ctx context.Context, rows int64) {
+	if c := FromContext(ctx); c != nil {
+		c.queries.Add(1)
+		c.rows.Add(rows)
+	}
+}
+
+// AddCacheHit counts a cache lookup served from the cache
+func AddCacheHit(ctx context.Context) {
+	if c := FromContext(ctx); c != nil {
+		c.cacheHits.Add(1)
+	}
+}
+
+// AddCacheMiss counts a cache lookup that had to load its value
+func AddCacheMiss(ctx context.Context) {
+	if c := FromContext(ctx); c != nil {
+		c.cacheMisses.Add(1)
+	}
+}
+
+// AddExternalCall counts a call to an external service
+func AddExternalCall(ctx context.Context) {
+	if c := FromContext(ctx); c != nil {
+		c.externalCalls.Add(1)
+	}
+}
@@ -0,0 +1,99 @@
+package cost
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/logger"
+)
+
+func TestCounter(t *testing.T) {
+	// Without a counter the helpers do nothing
+	AddQuery(context.Background(), 3)
+	AddExternalCall(context.Background())
+
+	counter := NewCounter()
+	ctx := WithCounter(context.Background(), counter)
+	require.Same(t, counter, FromContext(ctx))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			AddQuery(ctx, 2)
+		}()
+	}
+	wg.Wait()
+	AddCacheHit(ctx)
+	AddCacheMiss(ctx)
+	AddExternalCall(ctx)
+
+	assert.Equal(t, Costs{Queries: 10, Rows: 20, CacheHits: 1, CacheMisses: 1, ExternalCalls: 1}, counter.Costs())
+	assert.Equal(t, int64(10), counter.Costs().Fields()["db_queries"])
+}
+
+func TestBudget_Exceeded(t *testing.T) {
+	budget := Budget{Queries: 5, Rows: 100}
+
+	assert.Empty(t, budget.Exceeded(Costs{Queries: 5, Rows: 100, ExternalCalls: 50}))
+	assert.Equal(t, []string{ResourceQueries}, budget.Exceeded(Costs{Queries: 6}))
+	assert.Equal(t, []string{ResourceQueries, ResourceRows}, budget.Exceeded(Costs{Queries: 40, Rows: 400}))
+}
+
+func TestTracker_Middleware(t *testing.T) {
+	tracker := NewTracker([]Budget{{Method: "GET", Route: "/users/", Queries: 2}}, logger.New())
+	queries := 0
+
+	r := chi.NewRouter()
+	r.Use(tracker.Middleware)
+	r.Route("/users", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			for i := 0; i < queries; i++ {
+				AddQuery(r.Context(), 1)
+			}
+		})
+	})
+	r.Get("/other", func(w http.ResponseWriter, r *http.Request) {
+		AddQuery(r.Context(), 1)
+	})
+
+	queries = 2
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+	queries = 3
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+	// Routes without a budget are measured but never exceed one
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(tracker.exceeded.WithLabelValues("GET", "/users", ResourceQueries)))
+	assert.Equal(t, 2, testutil.CollectAndCount(tracker, "http_request_db_queries"))
+
+	expected := `
+# HELP request_cost_budget_exceeded_total Requests exceeding the cost budget of their route, by resource.
+# TYPE request_cost_budget_exceeded_total counter
+request_cost_budget_exceeded_total{method="GET",resource="queries",route="/users"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(tracker, strings.NewReader(expected), "request_cost_budget_exceeded_total"))
+}
+
+func TestTracker_Middleware_ReusesCounter(t *testing.T) {
+	tracker := NewTracker(nil, logger.New())
+	counter := NewCounter()
+
+	handler := tracker.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		AddExternalCall(r.Context())
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(WithCounter(req.Context(), counter)))
+
+	assert.Equal(t, int64(1), counter.Costs().ExternalCalls)
+}
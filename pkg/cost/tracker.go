@@ -0,0 +1,149 @@
+package cost
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/logger"
+)
+
+// Resources a budget limits, as reported in the resource metric label
+const (
+	ResourceQueries       = "queries"
+	ResourceRows          = "rows"
+	ResourceExternalCalls = "external_calls"
+)
+
+// Budget bounds the costs of each request to a route. Zero limits are not
+// enforced.
+type Budget struct {
+	Method string
+	// Route is the chi route pattern, e.g. /api/v1/users/{id}
+	Route         string
+	Queries       int64
+	Rows          int64
+	ExternalCalls int64
+}
+
+// Exceeded returns the resources of costs above the budget
+func (b Budget) Exceeded(costs Costs) []string {
+	var exceeded []string
+	for _, limit := range []struct {
+		resource string
+		limit    int64
+		used     int64
+	}{
+		{ResourceQueries, b.Queries, costs.Queries},
+		{ResourceRows, b.Rows, costs.Rows},
+		{ResourceExternalCalls, b.ExternalCalls, costs.ExternalCalls},
+	} {
+		if limit.limit > 0 && limit.used > limit.limit {
+			exceeded = append(exceeded, limit.resource)
+		}
+	}
+	return exceeded
+}
+
+// Tracker attaches a Counter to each request, exports the queries of each
+// route as a histogram and alerts on requests exceeding the budget of
+// their route
+type Tracker struct {
+	budgets map[string]Budget
+	log     logger.Logger
+
+	queries  *prometheus.HistogramVec
+	exceeded *prometheus.CounterVec
+}
+
+// NewTracker creates a tracker enforcing budgets. Requests exceeding one
+// are logged as warnings through log.
+func NewTracker(budgets []Budget, log logger.Logger) *Tracker {
+	t := &Tracker{
+		budgets: make(map[string]Budget, len(budgets)),
+		log:     log,
+		queries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_db_queries",
+			Help:    "Database queries run by each request.",
+			Buckets: []float64{0, 1, 2, 5, 10, 20, 50, 100},
+		}, []string{"method", "route"}),
+		exceeded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "request_cost_budget_exceeded_total",
+			Help: "Requests exceeding the cost budget of their route, by resource.",
+		}, []string{"method", "route", "resource"}),
+	}
+	for _, budget := range budgets {
+		t.budgets[budget.Method+" "+normalizeRoute(budget.Route)] = budget
+	}
+	return t
+}
+
+// Middleware counts the costs of each request. It reuses the Counter of an
+// outer middleware and attaches one otherwise, so middleware and handlers
+// further in, such as the access log, can read the costs once the request
+// is served.
+func (t *Tracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter := FromContext(r.Context())
+		if counter == nil {
+			counter = NewCounter()
+			r = r.WithContext(WithCounter(r.Context(), counter))
+		}
+
+		next.ServeHTTP(w, r)
+
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil || rctx.RoutePattern() == "" {
+			return
+		}
+		route := normalizeRoute(rctx.RoutePattern())
+		costs := counter.Costs()
+		t.queries.WithLabelValues(r.Method, route).Observe(float64(costs.Queries))
+
+		budget, ok := t.budgets[r.Method+" "+route]
+		if !ok {
+			return
+		}
+		exceeded := budget.Exceeded(costs)
+		if len(exceeded) == 0 {
+			return
+		}
+		for _, resource := range exceeded {
+			t.exceeded.WithLabelValues(r.Method, route, resource).Inc()
+		}
+
+		fields := costs.Fields()
+		fields["method"] = r.Method
+		fields["route"] = route
+		fields["exceeded"] = strings.Join(exceeded, ",")
+		fields["budget_queries"] = budget.Queries
+		fields["budget_rows"] = budget.Rows
+		fields["budget_external_calls"] = budget.ExternalCalls
+		fields["correlation_id"] = correlation.ID(r.Context())
+		t.log.WithFields(fields).Warn("Request exceeded its cost budget")
+	})
+}
+
+// Describe implements prometheus.Collector
+func (t *Tracker) Describe(ch chan<- *prometheus.Desc) {
+	t.queries.Describe(ch)
+	t.exceeded.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (t *Tracker) Collect(ch chan<- prometheus.Metric) {
+	t.queries.Collect(ch)
+	t.exceeded.Collect(ch)
+}
+
+// normalizeRoute strips the trailing slash chi leaves on the patterns of
+// index routes mounted below a prefix
+func normalizeRoute(route string) string {
+	if len(route) > 1 {
+		return strings.TrimSuffix(route, "/")
+	}
+	return route
+}
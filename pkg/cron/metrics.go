@@ -0,0 +1,79 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collector exports the runs of the scheduled jobs as Prometheus metrics
+type collector struct {
+	runs        *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	running     *prometheus.Desc
+	lastSuccess *prometheus.Desc
+	nextRun     *prometheus.Desc
+}
+
+func newCollector() *collector {
+	return &collector{
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cron_job_runs_total",
+			Help: "Number of scheduled job runs by result: success, failure or skipped.",
+		}, []string{"job", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cron_job_duration_seconds",
+			Help:    "Duration of the scheduled job runs.",
+			Buckets: []float64{0.01, 0.1, 0.5, 1, 5, 15, 30, 60, 300, 900, 3600},
+		}, []string{"job"}),
+		running: prometheus.NewDesc("cron_job_running",
+			"Whether the job is running on this instance (1) or not (0).", []string{"job"}, nil),
+		lastSuccess: prometheus.NewDesc("cron_job_last_success_timestamp_seconds",
+			"Unix time of the start of the last successful run; 0 before one.", []string{"job"}, nil),
+		nextRun: prometheus.NewDesc("cron_job_next_run_timestamp_seconds",
+			"Unix time the job runs next; 0 when it is not scheduled.", []string{"job"}, nil),
+	}
+}
+
+// observe records a run of job. Skipped runs did no work, so their
+// duration is not observed.
+func (c *collector) observe(job, result string, duration time.Duration) {
+	c.runs.WithLabelValues(job, result).Inc()
+	if result != ResultSkipped {
+		c.duration.WithLabelValues(job).Observe(duration.Seconds())
+	}
+}
+
+// Describe implements prometheus.Collector
+func (s *Scheduler) Describe(ch chan<- *prometheus.Desc) {
+	s.metrics.runs.Describe(ch)
+	s.metrics.duration.Describe(ch)
+	ch <- s.metrics.running
+	ch <- s.metrics.lastSuccess
+	ch <- s.metrics.nextRun
+}
+
+// Collect implements prometheus.Collector
+func (s *Scheduler) Collect(ch chan<- prometheus.Metric) {
+	s.metrics.runs.Collect(ch)
+	s.metrics.duration.Collect(ch)
+	for _, status := range s.Statuses() {
+		ch <- prometheus.MustNewConstMetric(s.metrics.running, prometheus.GaugeValue, gauge(status.Running), status.Name)
+		ch <- prometheus.MustNewConstMetric(s.metrics.lastSuccess, prometheus.GaugeValue, timestamp(status.LastSuccess), status.Name)
+		ch <- prometheus.MustNewConstMetric(s.metrics.nextRun, prometheus.GaugeValue, timestamp(status.NextRun), status.Name)
+	}
+}
+
+func gauge(ok bool) float64 {
+	if ok {
+		return 1
+	}
+	return 0
+}
+
+func timestamp(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
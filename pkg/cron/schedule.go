@@ -0,0 +1,226 @@
+// Package cron runs periodic jobs on cron schedules, logging each run and
+// exporting its outcome as Prometheus metrics. Jobs can be made singletons
+// so that only one replica of the service runs them at a time.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchLimit bounds how far ahead Next looks for a matching time, so
+// schedules naming dates that never occur, such as February 30, end
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// Schedule tells when a job runs next
+type Schedule interface {
+	// Next returns the first time after t the job runs, in the location of
+	// t, or the zero time if it never runs again
+	Next(t time.Time) time.Time
+}
+
+// Every returns a schedule running every interval, counted from the
+// previous run
+func Every(interval time.Duration) Schedule {
+	return every(interval)
+}
+
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// descriptors are the shorthands Parse accepts in place of the five fields
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field describes one of the five fields of a cron expression
+type field struct {
+	name     string
+	min, max int
+	names    []string // names of the values from min, if any
+}
+
+var (
+	minutes = field{name: "minute", min: 0, max: 59}
+	hours   = field{name: "hour", min: 0, max: 23}
+	days    = field{name: "day of month", min: 1, max: 31}
+	months  = field{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	// Sunday is both 0 and 7
+	weekdays = field{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat", "sun"}}
+)
+
+// spec is a parsed cron expression. Each field is a bit set of the values
+// it matches.
+type spec struct {
+	minute, hour, dom, month, dow uint64
+	// Like cron, a job restricting both the day of the month and of the
+	// week runs on days matching either
+	domAny, dowAny bool
+}
+
+// Parse parses a cron expression of five fields, minute, hour, day of
+// month, month and day of week, each a list of values, ranges such as
+// 1-5, or * for every value, optionally stepped with /n. Months and days
+// of the week can be named by their first three letters. The shorthands
+// @yearly, @monthly, @weekly, @daily, @hourly and "@every <duration>" are
+// accepted too.
+func Parse(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("cron: invalid interval %q: %w", rest, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("cron: interval %s must be positive", interval)
+		}
+		return Every(interval), nil
+	}
+	if descriptor, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron: %q must have 5 fields, has %d", expr, len(parts))
+	}
+	s := &spec{}
+	var err error
+	if s.minute, err = parseField(parts[0], minutes); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(parts[1], hours); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(parts[2], days); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(parts[3], months); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(parts[4], weekdays); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAny = parts[2] == "*" || strings.HasPrefix(parts[2], "*/")
+	s.dowAny = parts[4] == "*" || strings.HasPrefix(parts[4], "*/")
+
+	if s.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("cron: %q never runs", expr)
+	}
+	return s, nil
+}
+
+// parseField parses a comma separated list of the values of f
+func parseField(expr string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(expr, ",") {
+		rangeExpr, stepExpr, stepped := strings.Cut(item, "/")
+		step := 1
+		if stepped {
+			n, err := strconv.Atoi(stepExpr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("cron: invalid step %q in %s field", stepExpr, f.name)
+			}
+			step = n
+		}
+
+		var low, high int
+		switch {
+		case rangeExpr == "*":
+			low, high = f.min, f.max
+		case strings.Contains(rangeExpr, "-"):
+			lowExpr, highExpr, _ := strings.Cut(rangeExpr, "-")
+			var err error
+			if low, err = f.value(lowExpr); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(highExpr); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("cron: range %q in %s field is reversed", rangeExpr, f.name)
+			}
+		default:
+			value, err := f.value(rangeExpr)
+			if err != nil {
+				return 0, err
+			}
+			// A stepped single value runs from it to the end of the range
+			low, high = value, value
+			if stepped {
+				high = f.max
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses one number or name of f
+func (f field) value(expr string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(expr, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(expr)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron: invalid %s %q, must be between %d and %d", f.name, expr, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next implements Schedule. It advances field by field, from the month
+// down to the minute, to the first time all of them match.
+func (s *spec) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *spec) matchesDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if !s.domAny && !s.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
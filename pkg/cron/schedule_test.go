@@ -0,0 +1,76 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	// Wednesday, January 10 2024, 10:30
+	from := time.Date(2024, time.January, 10, 10, 30, 15, 0, time.UTC)
+
+	for _, tt := range []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "* * * * *", want: time.Date(2024, time.January, 10, 10, 31, 0, 0, time.UTC)},
+		{expr: "0 3 * * *", want: time.Date(2024, time.January, 11, 3, 0, 0, 0, time.UTC)},
+		{expr: "*/15 * * * *", want: time.Date(2024, time.January, 10, 10, 45, 0, 0, time.UTC)},
+		{expr: "5,50 10 * * *", want: time.Date(2024, time.January, 10, 10, 50, 0, 0, time.UTC)},
+		{expr: "0 9-17/4 * * *", want: time.Date(2024, time.January, 10, 13, 0, 0, 0, time.UTC)},
+		{expr: "0 0 1 * *", want: time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 feb *", want: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 12 * * sat", want: time.Date(2024, time.January, 13, 12, 0, 0, 0, time.UTC)},
+		{expr: "0 12 * * 7", want: time.Date(2024, time.January, 14, 12, 0, 0, 0, time.UTC)},
+		{expr: "0 12 * * Mon-Fri", want: time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC)},
+		// Restricting both days matches either, like cron
+		{expr: "0 0 15 * fri", want: time.Date(2024, time.January, 12, 0, 0, 0, 0, time.UTC)},
+		{expr: "@hourly", want: time.Date(2024, time.January, 10, 11, 0, 0, 0, time.UTC)},
+		{expr: "@daily", want: time.Date(2024, time.January, 11, 0, 0, 0, 0, time.UTC)},
+		{expr: "@weekly", want: time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC)},
+		{expr: "@yearly", want: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "@every 90s", want: from.Add(90 * time.Second)},
+	} {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+}
+
+func TestParse_Location(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	schedule, err := Parse("0 3 * * *")
+	require.NoError(t, err)
+
+	next := schedule.Next(time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC).In(loc))
+	assert.Equal(t, time.Date(2024, time.January, 10, 3, 0, 0, 0, loc), next)
+	assert.Equal(t, loc, next.Location(), "times are in the location of the time they follow")
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for expr, want := range map[string]string{
+		"* * * *":         `cron: "* * * *" must have 5 fields, has 4`,
+		"60 * * * *":      `cron: invalid minute "60", must be between 0 and 59`,
+		"* 24 * * *":      `cron: invalid hour "24", must be between 0 and 23`,
+		"* * 0 * *":       `cron: invalid day of month "0", must be between 1 and 31`,
+		"* * * foo *":     `cron: invalid month "foo", must be between 1 and 12`,
+		"* * * * 8":       `cron: invalid day of week "8", must be between 0 and 7`,
+		"*/0 * * * *":     `cron: invalid step "0" in minute field`,
+		"30-10 * * * *":   `cron: range "30-10" in minute field is reversed`,
+		"0 0 30 feb *":    `cron: "0 0 30 feb *" never runs`,
+		"@every soon":     `cron: invalid interval "soon": time: invalid duration "soon"`,
+		"@every -1m":      `cron: interval -1m0s must be positive`,
+		"@fortnightly":    `cron: "@fortnightly" must have 5 fields, has 1`,
+		"0 0 1 1 * extra": `cron: "0 0 1 1 * extra" must have 5 fields, has 6`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr)
+			assert.EqualError(t, err, want)
+		})
+	}
+}
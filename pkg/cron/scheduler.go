@@ -0,0 +1,256 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/distlock"
+	"clean-architecture/pkg/logger"
+)
+
+// Results of a run, as logged and counted in cron_job_runs_total
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	// ResultSkipped is a singleton run another instance is doing
+	ResultSkipped = "skipped"
+)
+
+// Job is periodic work run by a Scheduler
+type Job struct {
+	// Name identifies the job in logs, metrics and, for singletons, the
+	// name of the lock held while it runs
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+	// Singleton runs the job on one instance at a time; instances finding
+	// the lock held skip the run. It requires the scheduler to have a
+	// locker.
+	Singleton bool
+	// Timeout cancels runs taking longer; 0 lets them run until the
+	// scheduler stops
+	Timeout time.Duration
+}
+
+// Status is the state of a registered job
+type Status struct {
+	Name         string        `json:"name"`
+	Running      bool          `json:"running"`
+	Runs         int64         `json:"runs"`
+	Failures     int64         `json:"failures"`
+	Skipped      int64         `json:"skipped"`
+	LastRun      time.Time     `json:"last_run,omitempty"`
+	LastDuration time.Duration `json:"last_duration_ns"`
+	LastSuccess  time.Time     `json:"last_success,omitempty"`
+	LastError    string        `json:"last_error,omitempty"`
+	NextRun      time.Time     `json:"next_run,omitempty"`
+}
+
+// entry is a registered job and its status
+type entry struct {
+	job    Job
+	status Status
+}
+
+// Scheduler runs registered jobs on their schedules. Each job runs in its
+// own goroutine, so a run never overlaps the previous run of the same job;
+// runs due while one is in progress are skipped.
+type Scheduler struct {
+	logger   logger.Logger
+	locker   distlock.Locker
+	location *time.Location
+	metrics  *collector
+	now      func() time.Time
+
+	mu      sync.RWMutex
+	entries []*entry
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// New creates a scheduler evaluating schedules in UTC
+func New(logger logger.Logger) *Scheduler {
+	return &Scheduler{
+		logger:   logger,
+		location: time.UTC,
+		metrics:  newCollector(),
+		now:      time.Now,
+	}
+}
+
+// WithLocker sets the locker singleton jobs take their lock from
+func (s *Scheduler) WithLocker(locker distlock.Locker) *Scheduler {
+	s.locker = locker
+	return s
+}
+
+// WithLocation evaluates schedules in loc, so "0 3 * * *" runs at 3 am
+// there
+func (s *Scheduler) WithLocation(loc *time.Location) *Scheduler {
+	s.location = loc
+	return s
+}
+
+// Register adds job to the scheduler. Jobs must be registered before
+// Start.
+func (s *Scheduler) Register(job Job) error {
+	switch {
+	case job.Name == "":
+		return errors.New("cron: job name is required")
+	case job.Schedule == nil:
+		return fmt.Errorf("cron: job %s has no schedule", job.Name)
+	case job.Run == nil:
+		return fmt.Errorf("cron: job %s has no run function", job.Name)
+	case job.Singleton && s.locker == nil:
+		return fmt.Errorf("cron: singleton job %s requires a locker", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		if e.job.Name == job.Name {
+			return fmt.Errorf("cron: job %s is already registered", job.Name)
+		}
+	}
+	s.entries = append(s.entries, &entry{job: job, status: Status{Name: job.Name}})
+	return nil
+}
+
+// Start runs the registered jobs until ctx ends or Stop is called
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	entries := append([]*entry(nil), s.entries...)
+	s.mu.Unlock()
+
+	for _, e := range entries {
+		s.wg.Add(1)
+		go s.loop(ctx, e)
+	}
+	s.logger.WithField("jobs", len(entries)).Info("Cron scheduler started")
+}
+
+// Stop cancels the running jobs and waits for them to return. It always
+// returns nil; the error is for shutdown reports.
+func (s *Scheduler) Stop() error {
+	s.mu.RLock()
+	cancel := s.cancel
+	s.mu.RUnlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// Statuses reports the state of every registered job, in registration
+// order
+func (s *Scheduler) Statuses() []Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	statuses := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		statuses = append(statuses, e.status)
+	}
+	return statuses
+}
+
+// loop runs e each time its schedule is due until ctx ends
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	defer s.wg.Done()
+	for {
+		next := e.job.Schedule.Next(s.now().In(s.location))
+		s.mu.Lock()
+		e.status.NextRun = next
+		s.mu.Unlock()
+		if next.IsZero() {
+			s.logger.WithField("job", e.job.Name).Warn("Cron job has no further runs scheduled")
+			return
+		}
+
+		timer := time.NewTimer(next.Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.run(ctx, e)
+	}
+}
+
+// run runs e once, then logs and records the outcome
+func (s *Scheduler) run(ctx context.Context, e *entry) {
+	started := s.now()
+	s.mu.Lock()
+	e.status.Running = true
+	s.mu.Unlock()
+
+	err := s.execute(ctx, e.job)
+	duration := s.now().Sub(started)
+
+	result := ResultSuccess
+	switch {
+	case errors.Is(err, distlock.ErrNotAcquired):
+		result = ResultSkipped
+	case err != nil:
+		result = ResultFailure
+	}
+
+	s.mu.Lock()
+	e.status.Running = false
+	switch result {
+	case ResultSkipped:
+		e.status.Skipped++
+	case ResultFailure:
+		e.status.Runs++
+		e.status.Failures++
+		e.status.LastRun, e.status.LastDuration, e.status.LastError = started, duration, err.Error()
+	default:
+		e.status.Runs++
+		e.status.LastRun, e.status.LastDuration, e.status.LastError = started, duration, ""
+		e.status.LastSuccess = started
+	}
+	s.mu.Unlock()
+	s.metrics.observe(e.job.Name, result, duration)
+
+	log := s.logger.WithFields(map[string]interface{}{
+		"job":         e.job.Name,
+		"result":      result,
+		"duration_ms": duration.Milliseconds(),
+	})
+	switch result {
+	case ResultSkipped:
+		log.Debug("Cron job skipped; another instance is running it")
+	case ResultFailure:
+		log.WithField("error", err.Error()).Error("Cron job failed")
+	default:
+		// Jobs log what they did themselves; frequent jobs would flood the
+		// log otherwise
+		log.Debug("Cron job completed")
+	}
+}
+
+// execute runs job under its timeout and, for singletons, its lock.
+// Panics are returned as errors so one job cannot stop the scheduler.
+func (s *Scheduler) execute(ctx context.Context, job Job) (err error) {
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cron job panicked: %v", r)
+		}
+	}()
+
+	if job.Singleton {
+		return distlock.RunExclusive(ctx, s.locker, "cron:"+job.Name, job.Run)
+	}
+	return job.Run(ctx)
+}
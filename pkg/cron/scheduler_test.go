@@ -0,0 +1,138 @@
+package cron
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/distlock"
+	"clean-architecture/pkg/logger"
+)
+
+func TestScheduler_Register(t *testing.T) {
+	s := New(logger.New())
+	run := func(ctx context.Context) error { return nil }
+
+	require.NoError(t, s.Register(Job{Name: "purge", Schedule: Every(time.Hour), Run: run}))
+	assert.EqualError(t, s.Register(Job{Name: "purge", Schedule: Every(time.Hour), Run: run}), "cron: job purge is already registered")
+	assert.EqualError(t, s.Register(Job{Schedule: Every(time.Hour), Run: run}), "cron: job name is required")
+	assert.EqualError(t, s.Register(Job{Name: "refresh", Run: run}), "cron: job refresh has no schedule")
+	assert.EqualError(t, s.Register(Job{Name: "refresh", Schedule: Every(time.Hour)}), "cron: job refresh has no run function")
+	assert.EqualError(t, s.Register(Job{Name: "sweep", Schedule: Every(time.Hour), Run: run, Singleton: true}), "cron: singleton job sweep requires a locker")
+}
+
+func TestScheduler_Run(t *testing.T) {
+	s := New(logger.New())
+	var runs atomic.Int32
+	done := make(chan struct{}, 10)
+	require.NoError(t, s.Register(Job{
+		Name:     "refresh",
+		Schedule: Every(10 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			defer func() { done <- struct{}{} }()
+			if runs.Add(1) == 2 {
+				return errors.New("cache unavailable")
+			}
+			return nil
+		},
+	}))
+	require.NoError(t, s.Register(Job{
+		Name:     "explode",
+		Schedule: Every(10 * time.Millisecond),
+		Run:      func(ctx context.Context) error { panic("nil map") },
+	}))
+
+	s.Start(context.Background())
+	for i := 0; i < 3; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the job did not run")
+		}
+	}
+	require.NoError(t, s.Stop())
+
+	statuses := s.Statuses()
+	require.Len(t, statuses, 2)
+	refresh := statuses[0]
+	assert.Equal(t, "refresh", refresh.Name)
+	assert.GreaterOrEqual(t, refresh.Runs, int64(3))
+	assert.Equal(t, int64(1), refresh.Failures)
+	assert.False(t, refresh.LastSuccess.IsZero())
+	assert.False(t, refresh.Running)
+	assert.Equal(t, "cron job panicked: nil map", statuses[1].LastError, "panics fail the run, not the scheduler")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(s.metrics.runs.WithLabelValues("refresh", ResultFailure)))
+	assert.GreaterOrEqual(t, testutil.ToFloat64(s.metrics.runs.WithLabelValues("refresh", ResultSuccess)), float64(2))
+}
+
+func TestScheduler_Singleton(t *testing.T) {
+	locker := distlock.NewMemoryLocker()
+	held, err := locker.TryAcquire(context.Background(), "cron:purge")
+	require.NoError(t, err)
+
+	s := New(logger.New()).WithLocker(locker)
+	var ran atomic.Bool
+	require.NoError(t, s.Register(Job{
+		Name:      "purge",
+		Schedule:  Every(time.Hour),
+		Singleton: true,
+		Run: func(ctx context.Context) error {
+			ran.Store(true)
+			return nil
+		},
+	}))
+
+	e := s.entries[0]
+	s.run(context.Background(), e)
+	assert.False(t, ran.Load(), "runs are skipped while another instance holds the lock")
+	assert.Equal(t, int64(1), s.Statuses()[0].Skipped)
+	assert.Zero(t, s.Statuses()[0].Runs)
+
+	require.NoError(t, held.Release(context.Background()))
+	s.run(context.Background(), e)
+	assert.True(t, ran.Load())
+	assert.Equal(t, int64(1), s.Statuses()[0].Runs)
+}
+
+func TestScheduler_Timeout(t *testing.T) {
+	s := New(logger.New())
+	require.NoError(t, s.Register(Job{
+		Name:     "slow",
+		Schedule: Every(time.Hour),
+		Timeout:  10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}))
+
+	s.run(context.Background(), s.entries[0])
+	assert.Equal(t, context.DeadlineExceeded.Error(), s.Statuses()[0].LastError)
+}
+
+func TestScheduler_Collect(t *testing.T) {
+	s := New(logger.New())
+	require.NoError(t, s.Register(Job{Name: "purge", Schedule: Every(time.Hour), Run: func(ctx context.Context) error { return nil }}))
+	s.run(context.Background(), s.entries[0])
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(s)
+	err := testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP cron_job_runs_total Number of scheduled job runs by result: success, failure or skipped.
+# TYPE cron_job_runs_total counter
+cron_job_runs_total{job="purge",result="success"} 1
+# HELP cron_job_running Whether the job is running on this instance (1) or not (0).
+# TYPE cron_job_running gauge
+cron_job_running{job="purge"} 0
+`), "cron_job_runs_total", "cron_job_running")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,76 @@
+// Package ctxkeys provides typed context keys so request-scoped values are
+// stored and read without string keys or unchecked type assertions.
+package ctxkeys
+
+import (
+	"context"
+	"fmt"
+
+	"clean-architecture/pkg/logger"
+)
+
+// ClaimSet holds the verified claims of the caller's credentials
+type ClaimSet map[string]interface{}
+
+// Request-scoped values shared across layers
+var (
+	RequestID     = NewKey[string]("request_id")
+	CorrelationID = NewKey[string]("correlation_id")
+	UserID        = NewKey[string]("user_id")
+	TenantID      = NewKey[string]("tenant_id")
+	Logger        = NewKey[logger.Logger]("logger")
+	Claims        = NewKey[ClaimSet]("claims")
+)
+
+// Key is a typed context key. Every key returned by NewKey is distinct, even
+// if two share a name.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a new key for values of type T
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// Name returns the key's name
+func (k *Key[T]) Name() string {
+	return k.name
+}
+
+// With returns a copy of ctx carrying value under the key
+func (k *Key[T]) With(ctx context.Context, value T) context.Context {
+	return context.WithValue(ctx, k, value)
+}
+
+// From returns the value stored under the key and whether it was present
+func (k *Key[T]) From(ctx context.Context) (T, bool) {
+	value, ok := ctx.Value(k).(T)
+	return value, ok
+}
+
+// Value returns the value stored under the key, or the zero value
+func (k *Key[T]) Value(ctx context.Context) T {
+	value, _ := k.From(ctx)
+	return value
+}
+
+// Must returns the value stored under the key and panics with a
+// *MissingError if it is absent. Use it in code that cannot run without the
+// value, so a miswired middleware chain fails loudly.
+func (k *Key[T]) Must(ctx context.Context) T {
+	value, ok := k.From(ctx)
+	if !ok {
+		panic(&MissingError{Key: k.name})
+	}
+	return value
+}
+
+// MissingError reports a required context value that was not set
+type MissingError struct {
+	Key string
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("ctxkeys: required context value %q is missing", e.Key)
+}
@@ -0,0 +1,53 @@
+package ctxkeys
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey_WithAndFrom(t *testing.T) {
+	ctx := UserID.With(context.Background(), "user_123")
+
+	value, ok := UserID.From(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "user_123", value)
+	assert.Equal(t, "user_123", UserID.Value(ctx))
+	assert.Equal(t, "user_123", UserID.Must(ctx))
+
+	_, ok = TenantID.From(ctx)
+	assert.False(t, ok)
+	assert.Empty(t, TenantID.Value(ctx))
+}
+
+func TestKey_DistinctKeysDoNotCollide(t *testing.T) {
+	shadow := NewKey[string]("user_id")
+	ctx := shadow.With(context.Background(), "spoofed")
+
+	_, ok := UserID.From(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, "user_id", shadow.Name())
+}
+
+func TestKey_Must_PanicsWhenMissing(t *testing.T) {
+	defer func() {
+		recovered := recover()
+		require.NotNil(t, recovered)
+
+		err, ok := recovered.(*MissingError)
+		require.True(t, ok)
+		assert.Equal(t, "tenant_id", err.Key)
+		assert.EqualError(t, err, `ctxkeys: required context value "tenant_id" is missing`)
+	}()
+
+	TenantID.Must(context.Background())
+}
+
+func TestClaims(t *testing.T) {
+	ctx := Claims.With(context.Background(), ClaimSet{"sub": "user_123", "scope": "users:read"})
+
+	claims := Claims.Must(ctx)
+	assert.Equal(t, "user_123", claims["sub"])
+}
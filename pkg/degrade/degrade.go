@@ -0,0 +1,247 @@
+// Package degrade watches the health of optional dependencies and switches
+// off the features relying on them while they are down, so an unavailable
+// dependency degrades those features instead of failing every request or
+// taking the service out of rotation.
+package degrade
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/logger"
+)
+
+// defaultFailureThreshold is the default number of consecutive failed
+// checks after which a dependency is down, so a single slow check does not
+// flap features off and on
+const defaultFailureThreshold = 3
+
+// Check reports whether a dependency is healthy
+type Check func(ctx context.Context) error
+
+// Options configures a Manager
+type Options struct {
+	// Interval is how often every dependency is checked
+	Interval time.Duration
+	// Timeout bounds a single check
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failed checks after
+	// which a dependency is down. Zero uses a default of 3. A single
+	// passing check brings the dependency back up.
+	FailureThreshold int
+}
+
+// DependencyStatus is the health of one dependency
+type DependencyStatus struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	// Since is when the dependency last went down or came back up
+	Since time.Time `json:"since"`
+	// Error is the error of the last failed check while the dependency is
+	// down
+	Error string `json:"error,omitempty"`
+	// Features lists the features depending on the dependency
+	Features []string `json:"features"`
+}
+
+type dependency struct {
+	check    Check
+	healthy  bool
+	failures int
+	since    time.Time
+	err      string
+}
+
+// Manager tracks dependency health and the features depending on it
+type Manager struct {
+	opts    Options
+	logger  logger.Logger
+	now     func() time.Time
+	metrics *collector
+
+	mu           sync.RWMutex
+	dependencies map[string]*dependency
+	features     map[string][]string
+}
+
+// NewManager creates a manager without dependencies. Register them and the
+// features depending on them before calling Run.
+func NewManager(opts Options, logger logger.Logger) *Manager {
+	if opts.FailureThreshold == 0 {
+		opts.FailureThreshold = defaultFailureThreshold
+	}
+	m := &Manager{
+		opts:         opts,
+		logger:       logger,
+		now:          time.Now,
+		dependencies: make(map[string]*dependency),
+		features:     make(map[string][]string),
+	}
+	m.metrics = newCollector()
+	return m
+}
+
+// AddDependency registers a dependency checked by check. Dependencies
+// start out healthy, so features are available until checks say otherwise.
+func (m *Manager) AddDependency(name string, check Check) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dependencies[name] = &dependency{check: check, healthy: true, since: m.now()}
+}
+
+// AddFeature registers a feature that is degraded while any of its
+// dependencies is down. Dependencies that are never registered count as
+// healthy, so features can be declared whether or not their dependencies
+// are configured.
+func (m *Manager) AddFeature(name string, dependencies ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.features[name] = append(m.features[name], dependencies...)
+}
+
+// Enabled reports whether every dependency of feature is healthy
+func (m *Manager) Enabled(feature string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabled(feature)
+}
+
+func (m *Manager) enabled(feature string) bool {
+	for _, name := range m.features[feature] {
+		if d, ok := m.dependencies[name]; ok && !d.healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// Degraded returns the features that are currently off, sorted by name
+func (m *Manager) Degraded() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var degraded []string
+	for feature := range m.features {
+		if !m.enabled(feature) {
+			degraded = append(degraded, feature)
+		}
+	}
+	sort.Strings(degraded)
+	return degraded
+}
+
+// Dependencies returns the health of every dependency, sorted by name
+func (m *Manager) Dependencies() []DependencyStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	statuses := make([]DependencyStatus, 0, len(m.dependencies))
+	for name, d := range m.dependencies {
+		statuses = append(statuses, DependencyStatus{
+			Name:     name,
+			Healthy:  d.healthy,
+			Since:    d.since,
+			Error:    d.err,
+			Features: m.dependents(name),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// dependents returns the features depending on a dependency, sorted by
+// name
+func (m *Manager) dependents(dependency string) []string {
+	features := []string{}
+	for feature, dependencies := range m.features {
+		for _, name := range dependencies {
+			if name == dependency {
+				features = append(features, feature)
+				break
+			}
+		}
+	}
+	sort.Strings(features)
+	return features
+}
+
+// Run checks every dependency right away and then at the configured
+// interval until ctx is done
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		m.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check runs every dependency's check concurrently, so one hanging
+// dependency does not delay noticing another
+func (m *Manager) check(ctx context.Context) {
+	m.mu.RLock()
+	checks := make(map[string]Check, len(m.dependencies))
+	for name, d := range m.dependencies {
+		checks[name] = d.check
+	}
+	m.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+			err := check(checkCtx)
+			cancel()
+			if ctx.Err() == nil {
+				m.record(name, err)
+			}
+		}(name, check)
+	}
+	wg.Wait()
+}
+
+// record applies the outcome of a check and logs dependencies going down
+// or coming back up
+func (m *Manager) record(name string, err error) {
+	m.mu.Lock()
+	d := m.dependencies[name]
+	wasHealthy := d.healthy
+	if err == nil {
+		d.failures = 0
+		d.healthy = true
+		d.err = ""
+	} else {
+		d.failures++
+		d.err = err.Error()
+		if d.failures >= m.opts.FailureThreshold {
+			d.healthy = false
+		}
+	}
+	healthy := d.healthy
+	changed := healthy != wasHealthy
+	if changed {
+		d.since = m.now()
+	}
+	features := m.dependents(name)
+	m.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	log := m.logger.WithFields(map[string]interface{}{
+		"dependency": name,
+		"features":   features,
+	})
+	if healthy {
+		log.Info("Dependency recovered; restoring dependent features")
+	} else {
+		log.WithField("error", err.Error()).Warn("Dependency is down; degrading dependent features")
+	}
+}
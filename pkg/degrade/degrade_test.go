@@ -0,0 +1,139 @@
+package degrade
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/logger"
+)
+
+// switchCheck fails while its error is set
+type switchCheck struct {
+	err atomic.Pointer[error]
+}
+
+func (c *switchCheck) fail(err error) { c.err.Store(&err) }
+func (c *switchCheck) pass()          { c.err.Store(nil) }
+
+func (c *switchCheck) check(ctx context.Context) error {
+	if err := c.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+func newManager(threshold int) *Manager {
+	return NewManager(Options{Interval: time.Hour, Timeout: time.Second, FailureThreshold: threshold}, logger.New())
+}
+
+func TestManager_DegradesAfterThreshold(t *testing.T) {
+	ldap := &switchCheck{}
+	m := newManager(2)
+	m.AddDependency("ldap", ldap.check)
+	m.AddFeature("ldap_login", "ldap")
+	m.AddFeature("signup")
+	ctx := context.Background()
+
+	assert.True(t, m.Enabled("ldap_login"), "dependencies start out healthy")
+
+	ldap.fail(errors.New("connection refused"))
+	m.check(ctx)
+	assert.True(t, m.Enabled("ldap_login"), "a single failure is tolerated")
+	assert.Empty(t, m.Degraded())
+
+	m.check(ctx)
+	assert.False(t, m.Enabled("ldap_login"))
+	assert.True(t, m.Enabled("signup"))
+	assert.Equal(t, []string{"ldap_login"}, m.Degraded())
+
+	statuses := m.Dependencies()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "ldap", statuses[0].Name)
+	assert.False(t, statuses[0].Healthy)
+	assert.Equal(t, "connection refused", statuses[0].Error)
+	assert.Equal(t, []string{"ldap_login"}, statuses[0].Features)
+
+	ldap.pass()
+	m.check(ctx)
+	assert.True(t, m.Enabled("ldap_login"), "a single pass restores the feature")
+	assert.Empty(t, m.Degraded())
+	assert.Empty(t, m.Dependencies()[0].Error)
+}
+
+func TestManager_UnknownDependencies(t *testing.T) {
+	m := newManager(1)
+	m.AddFeature("search", "elasticsearch")
+	m.check(context.Background())
+
+	assert.True(t, m.Enabled("search"), "unregistered dependencies count as healthy")
+	assert.True(t, m.Enabled("unknown"))
+	assert.Empty(t, m.Dependencies())
+}
+
+func TestManager_CheckTimeout(t *testing.T) {
+	m := NewManager(Options{Interval: time.Hour, Timeout: 10 * time.Millisecond, FailureThreshold: 1}, logger.New())
+	m.AddDependency("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	m.AddFeature("reports", "slow")
+
+	m.check(context.Background())
+	assert.False(t, m.Enabled("reports"))
+}
+
+func TestManager_Run(t *testing.T) {
+	ldap := &switchCheck{}
+	ldap.fail(errors.New("connection refused"))
+	m := NewManager(Options{Interval: time.Millisecond, Timeout: time.Second, FailureThreshold: 1}, logger.New())
+	m.AddDependency("ldap", ldap.check)
+	m.AddFeature("ldap_login", "ldap")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return !m.Enabled("ldap_login") }, time.Second, time.Millisecond)
+	ldap.pass()
+	assert.Eventually(t, func() bool { return m.Enabled("ldap_login") }, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestManager_Metrics(t *testing.T) {
+	ldap := &switchCheck{}
+	ldap.fail(errors.New("connection refused"))
+	m := newManager(1)
+	m.AddDependency("ldap", ldap.check)
+	m.AddFeature("ldap_login", "ldap")
+	m.AddFeature("signup")
+	m.check(context.Background())
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(m)
+
+	expected := `
+# HELP degradation_dependency_up Whether the dependency is healthy (1) or down (0).
+# TYPE degradation_dependency_up gauge
+degradation_dependency_up{dependency="ldap"} 0
+# HELP degradation_feature_enabled Whether the feature is available (1) or degraded because a dependency is down (0).
+# TYPE degradation_feature_enabled gauge
+degradation_feature_enabled{feature="ldap_login"} 0
+degradation_feature_enabled{feature="signup"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"degradation_dependency_up", "degradation_feature_enabled"))
+}
@@ -0,0 +1,44 @@
+package degrade
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// collector exports dependency health and feature state as Prometheus
+// metrics
+type collector struct {
+	up      *prometheus.Desc
+	enabled *prometheus.Desc
+}
+
+func newCollector() *collector {
+	return &collector{
+		up: prometheus.NewDesc("degradation_dependency_up",
+			"Whether the dependency is healthy (1) or down (0).", []string{"dependency"}, nil),
+		enabled: prometheus.NewDesc("degradation_feature_enabled",
+			"Whether the feature is available (1) or degraded because a dependency is down (0).", []string{"feature"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (m *Manager) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.metrics.up
+	ch <- m.metrics.enabled
+}
+
+// Collect implements prometheus.Collector
+func (m *Manager) Collect(ch chan<- prometheus.Metric) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for name, d := range m.dependencies {
+		ch <- prometheus.MustNewConstMetric(m.metrics.up, prometheus.GaugeValue, gauge(d.healthy), name)
+	}
+	for feature := range m.features {
+		ch <- prometheus.MustNewConstMetric(m.metrics.enabled, prometheus.GaugeValue, gauge(m.enabled(feature)), feature)
+	}
+}
+
+func gauge(ok bool) float64 {
+	if ok {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,60 @@
+// Package distlock provides named locks shared by every replica of the
+// service, so singleton work such as scheduled jobs, the outbox relay and
+// retention sweeps runs on exactly one instance at a time.
+package distlock
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotAcquired is returned when the lock is held by another instance
+var ErrNotAcquired = errors.New("distlock: lock is held by another instance")
+
+// Locker acquires named locks
+type Locker interface {
+	// TryAcquire takes the named lock without blocking. It returns
+	// ErrNotAcquired if another holder already owns it.
+	TryAcquire(ctx context.Context, name string) (Lock, error)
+}
+
+// Lock is a held lock
+type Lock interface {
+	// Name returns the name the lock was acquired under
+	Name() string
+	// Lost is closed if the lock is lost before Release, e.g. because the
+	// connection backing it dropped
+	Lost() <-chan struct{}
+	// Release gives the lock up. It is safe to call more than once.
+	Release(ctx context.Context) error
+}
+
+// Inspector is implemented by lockers that can report who holds a lock
+type Inspector interface {
+	// Holder returns the ID of the instance holding the named lock, or an
+	// empty string if it is free
+	Holder(ctx context.Context, name string) (string, error)
+}
+
+// RunExclusive runs fn while holding the named lock. The context passed to fn
+// is cancelled if the lock is lost. It returns ErrNotAcquired without calling
+// fn when another instance holds the lock.
+func RunExclusive(ctx context.Context, locker Locker, name string, fn func(ctx context.Context) error) error {
+	lock, err := locker.TryAcquire(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer lock.Release(context.WithoutCancel(ctx))
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lock.Lost():
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+
+	return fn(runCtx)
+}
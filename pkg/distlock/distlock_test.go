@@ -0,0 +1,122 @@
+package distlock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLocker_TryAcquire(t *testing.T) {
+	locker := NewMemoryLocker()
+	ctx := context.Background()
+
+	lock, err := locker.TryAcquire(ctx, "scheduler")
+	require.NoError(t, err)
+	assert.Equal(t, "scheduler", lock.Name())
+
+	_, err = locker.TryAcquire(ctx, "scheduler")
+	assert.ErrorIs(t, err, ErrNotAcquired)
+
+	other, err := locker.TryAcquire(ctx, "outbox-relay")
+	require.NoError(t, err)
+	require.NoError(t, other.Release(ctx))
+
+	require.NoError(t, lock.Release(ctx))
+	require.NoError(t, lock.Release(ctx))
+
+	again, err := locker.TryAcquire(ctx, "scheduler")
+	require.NoError(t, err)
+	require.NoError(t, again.Release(ctx))
+}
+
+func TestMemoryLocker_Revoke(t *testing.T) {
+	locker := NewMemoryLocker()
+	ctx := context.Background()
+
+	lock, err := locker.TryAcquire(ctx, "scheduler")
+	require.NoError(t, err)
+
+	locker.Revoke("scheduler")
+
+	select {
+	case <-lock.Lost():
+	default:
+		t.Fatal("expected lock to be lost")
+	}
+
+	takeover, err := locker.TryAcquire(ctx, "scheduler")
+	require.NoError(t, err)
+
+	// Releasing the stale lock must not drop the new holder's lock
+	require.NoError(t, lock.Release(ctx))
+	_, err = locker.TryAcquire(ctx, "scheduler")
+	assert.ErrorIs(t, err, ErrNotAcquired)
+	require.NoError(t, takeover.Release(ctx))
+}
+
+func TestRunExclusive(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("runs and releases", func(t *testing.T) {
+		locker := NewMemoryLocker()
+		ran := false
+
+		err := RunExclusive(ctx, locker, "retention", func(ctx context.Context) error {
+			ran = true
+			_, err := locker.TryAcquire(ctx, "retention")
+			assert.ErrorIs(t, err, ErrNotAcquired)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.True(t, ran)
+		lock, err := locker.TryAcquire(ctx, "retention")
+		require.NoError(t, err)
+		require.NoError(t, lock.Release(ctx))
+	})
+
+	t.Run("skips when held elsewhere", func(t *testing.T) {
+		locker := NewMemoryLocker()
+		lock, err := locker.TryAcquire(ctx, "retention")
+		require.NoError(t, err)
+		defer lock.Release(ctx)
+
+		err = RunExclusive(ctx, locker, "retention", func(ctx context.Context) error {
+			t.Fatal("fn must not run without the lock")
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrNotAcquired)
+	})
+
+	t.Run("returns fn error", func(t *testing.T) {
+		boom := errors.New("boom")
+		err := RunExclusive(ctx, NewMemoryLocker(), "retention", func(ctx context.Context) error {
+			return boom
+		})
+		assert.ErrorIs(t, err, boom)
+	})
+
+	t.Run("cancels on lost lock", func(t *testing.T) {
+		locker := NewMemoryLocker()
+
+		err := RunExclusive(ctx, locker, "retention", func(ctx context.Context) error {
+			locker.Revoke("retention")
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+				return errors.New("context was not cancelled")
+			}
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestAdvisoryKey(t *testing.T) {
+	assert.Equal(t, advisoryKey("scheduler"), advisoryKey("scheduler"))
+	assert.NotEqual(t, advisoryKey("scheduler"), advisoryKey("outbox-relay"))
+}
@@ -0,0 +1,94 @@
+package distlock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"clean-architecture/pkg/logger"
+)
+
+var (
+	leaderDesc = prometheus.NewDesc(
+		"distlock_leader",
+		"Whether this instance holds the singleton lock (1) or not (0).",
+		[]string{"lock", "instance"}, nil,
+	)
+	transitionsDesc = prometheus.NewDesc(
+		"distlock_leader_transitions_total",
+		"Number of times this instance gained or lost the singleton lock.",
+		[]string{"lock", "instance"}, nil,
+	)
+)
+
+// Elections tracks the electors running in this process and exposes their
+// state as Prometheus metrics
+type Elections struct {
+	locker     Locker
+	instanceID string
+	retry      time.Duration
+	logger     logger.Logger
+
+	mu       sync.RWMutex
+	electors []*Elector
+}
+
+// NewElections creates a new set of electors sharing one locker
+func NewElections(locker Locker, instanceID string, retry time.Duration, logger logger.Logger) *Elections {
+	return &Elections{
+		locker:     locker,
+		instanceID: instanceID,
+		retry:      retry,
+		logger:     logger,
+	}
+}
+
+// Elector creates and tracks an elector for the named lock
+func (e *Elections) Elector(name string) *Elector {
+	elector := NewElector(e.locker, name, e.instanceID, e.retry, e.logger)
+
+	e.mu.Lock()
+	e.electors = append(e.electors, elector)
+	e.mu.Unlock()
+
+	return elector
+}
+
+// Statuses reports the state of every tracked lock
+func (e *Elections) Statuses(ctx context.Context) []Status {
+	e.mu.RLock()
+	electors := append([]*Elector(nil), e.electors...)
+	e.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(electors))
+	for _, elector := range electors {
+		statuses = append(statuses, elector.Status(ctx))
+	}
+	return statuses
+}
+
+// Describe implements prometheus.Collector
+func (e *Elections) Describe(ch chan<- *prometheus.Desc) {
+	ch <- leaderDesc
+	ch <- transitionsDesc
+}
+
+// Collect implements prometheus.Collector
+func (e *Elections) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for _, elector := range e.electors {
+		elector.mu.RLock()
+		leader, transitions := 0.0, float64(elector.transitions)
+		if elector.leader {
+			leader = 1
+		}
+		elector.mu.RUnlock()
+
+		ch <- prometheus.MustNewConstMetric(leaderDesc, prometheus.GaugeValue, leader, elector.name, e.instanceID)
+		ch <- prometheus.MustNewConstMetric(transitionsDesc, prometheus.CounterValue, transitions, elector.name, e.instanceID)
+	}
+}
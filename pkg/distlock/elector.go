@@ -0,0 +1,133 @@
+package distlock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/logger"
+)
+
+// DefaultRetryInterval is how often a follower tries to take over a lock
+const DefaultRetryInterval = 5 * time.Second
+
+// Status describes one singleton lock as seen by this instance
+type Status struct {
+	Lock        string    `json:"lock"`
+	Instance    string    `json:"instance"`
+	Leader      bool      `json:"leader"`
+	Holder      string    `json:"holder,omitempty"`
+	Since       time.Time `json:"since"`
+	Transitions uint64    `json:"transitions"`
+}
+
+// Elector keeps campaigning for a named lock and runs leader-only work while
+// it holds it. When the leader loses its lock, e.g. because its database
+// session expired, a follower takes over on its next attempt.
+type Elector struct {
+	locker     Locker
+	name       string
+	instanceID string
+	retry      time.Duration
+	logger     logger.Logger
+
+	mu          sync.RWMutex
+	leader      bool
+	since       time.Time
+	transitions uint64
+}
+
+// NewElector creates a new elector for the named lock
+func NewElector(locker Locker, name, instanceID string, retry time.Duration, logger logger.Logger) *Elector {
+	return &Elector{
+		locker:     locker,
+		name:       name,
+		instanceID: instanceID,
+		retry:      retry,
+		logger: logger.WithFields(map[string]interface{}{
+			"lock":     name,
+			"instance": instanceID,
+		}),
+		since: time.Now(),
+	}
+}
+
+// Name returns the name of the lock campaigned for
+func (e *Elector) Name() string {
+	return e.name
+}
+
+// IsLeader reports whether this instance currently holds the lock
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Run campaigns until ctx is done. Each time leadership is won, work is
+// called with a context that is cancelled when leadership is lost.
+func (e *Elector) Run(ctx context.Context, work func(ctx context.Context)) {
+	for {
+		err := RunExclusive(ctx, e.locker, e.name, func(leaderCtx context.Context) error {
+			e.setLeader(true)
+			e.logger.Info("Acquired leadership")
+			work(leaderCtx)
+			return leaderCtx.Err()
+		})
+
+		switch {
+		case errors.Is(err, ErrNotAcquired):
+		case e.IsLeader():
+			e.setLeader(false)
+			reason := "work finished"
+			if ctx.Err() != nil {
+				reason = "shutting down"
+			} else if errors.Is(err, context.Canceled) {
+				reason = "lock lost"
+			}
+			e.logger.WithField("reason", reason).Warn("Released leadership")
+		case err != nil && ctx.Err() == nil:
+			e.logger.WithField("error", err.Error()).Error("Failed to campaign for leadership")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.retry):
+		}
+	}
+}
+
+// Status reports this instance's view of the lock. Followers look up the
+// current holder when the locker supports it.
+func (e *Elector) Status(ctx context.Context) Status {
+	e.mu.RLock()
+	status := Status{
+		Lock:        e.name,
+		Instance:    e.instanceID,
+		Leader:      e.leader,
+		Since:       e.since,
+		Transitions: e.transitions,
+	}
+	e.mu.RUnlock()
+
+	if status.Leader {
+		status.Holder = e.instanceID
+	} else if inspector, ok := e.locker.(Inspector); ok {
+		if holder, err := inspector.Holder(ctx, e.name); err == nil {
+			status.Holder = holder
+		} else {
+			e.logger.WithField("error", err.Error()).Warn("Failed to look up lock holder")
+		}
+	}
+	return status
+}
+
+func (e *Elector) setLeader(leader bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leader = leader
+	e.since = time.Now()
+	e.transitions++
+}
@@ -0,0 +1,113 @@
+package distlock
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/logger"
+)
+
+func TestElector_Takeover(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	locker := NewMemoryLocker()
+	retry := 10 * time.Millisecond
+	first := NewElector(locker, "scheduler", "instance-a", retry, logger.New())
+	second := NewElector(locker, "scheduler", "instance-b", retry, logger.New())
+
+	leading := make(chan string, 4)
+	work := func(id string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			leading <- id
+			<-ctx.Done()
+		}
+	}
+
+	go first.Run(ctx, work("instance-a"))
+	require.Equal(t, "instance-a", <-leading)
+	go second.Run(ctx, work("instance-b"))
+
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+
+	// Simulate the leader's session expiring
+	locker.Revoke("scheduler")
+
+	select {
+	case id := <-leading:
+		// Either instance may win the new election
+		assert.Contains(t, []string{"instance-a", "instance-b"}, id)
+	case <-time.After(time.Second):
+		t.Fatal("leadership was not taken over")
+	}
+
+	require.Eventually(t, func() bool {
+		return first.IsLeader() != second.IsLeader()
+	}, time.Second, retry)
+}
+
+func TestElector_Status(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	elector := NewElector(NewMemoryLocker(), "outbox-relay", "instance-a", 10*time.Millisecond, logger.New())
+
+	status := elector.Status(ctx)
+	assert.Equal(t, "outbox-relay", status.Lock)
+	assert.Equal(t, "instance-a", status.Instance)
+	assert.False(t, status.Leader)
+	assert.Empty(t, status.Holder)
+
+	started := make(chan struct{})
+	go elector.Run(ctx, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	})
+	<-started
+
+	status = elector.Status(ctx)
+	assert.True(t, status.Leader)
+	assert.Equal(t, "instance-a", status.Holder)
+	assert.Equal(t, uint64(1), status.Transitions)
+}
+
+func TestElections_Collect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	elections := NewElections(NewMemoryLocker(), "instance-a", 10*time.Millisecond, logger.New())
+	scheduler := elections.Elector("scheduler")
+	elections.Elector("outbox-relay")
+
+	started := make(chan struct{})
+	go scheduler.Run(ctx, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	})
+	<-started
+
+	statuses := elections.Statuses(ctx)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Leader)
+	assert.False(t, statuses[1].Leader)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(elections)
+
+	expected := `
+# HELP distlock_leader Whether this instance holds the singleton lock (1) or not (0).
+# TYPE distlock_leader gauge
+distlock_leader{instance="instance-a",lock="outbox-relay"} 0
+distlock_leader{instance="instance-a",lock="scheduler"} 1
+`
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "distlock_leader")
+	assert.NoError(t, err)
+}
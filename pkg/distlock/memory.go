@@ -0,0 +1,67 @@
+package distlock
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryLocker is an in-process Locker for tests and single-instance
+// deployments
+type MemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]*memoryLock
+}
+
+// NewMemoryLocker creates a new in-memory locker
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{locks: make(map[string]*memoryLock)}
+}
+
+// TryAcquire takes the named lock if nobody in this process holds it
+func (m *MemoryLocker) TryAcquire(ctx context.Context, name string) (Lock, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, held := m.locks[name]; held {
+		return nil, ErrNotAcquired
+	}
+	lock := &memoryLock{locker: m, name: name, lost: make(chan struct{})}
+	m.locks[name] = lock
+	return lock, nil
+}
+
+// Revoke drops the named lock as if its holder had crashed, closing its Lost
+// channel. It simulates losing a lock in tests.
+func (m *MemoryLocker) Revoke(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if lock, held := m.locks[name]; held {
+		delete(m.locks, name)
+		close(lock.lost)
+	}
+}
+
+type memoryLock struct {
+	locker *MemoryLocker
+	name   string
+	lost   chan struct{}
+}
+
+func (l *memoryLock) Name() string { return l.name }
+
+func (l *memoryLock) Lost() <-chan struct{} { return l.lost }
+
+func (l *memoryLock) Release(ctx context.Context) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+
+	if l.locker.locks[l.name] == l {
+		delete(l.locker.locks, l.name)
+	}
+	return nil
+}
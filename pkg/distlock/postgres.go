@@ -0,0 +1,156 @@
+package distlock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// defaultKeepAlive is how often a held advisory lock checks its connection
+const defaultKeepAlive = 5 * time.Second
+
+// PostgresLocker implements Locker with PostgreSQL session-level advisory
+// locks. Each held lock pins one pooled connection; if the holder crashes or
+// the connection drops, PostgreSQL releases the lock so another replica can
+// take over. The pinned session's application_name is set to the holder ID so
+// other replicas can see who holds a lock.
+type PostgresLocker struct {
+	db        *sql.DB
+	holderID  string
+	keepAlive time.Duration
+}
+
+// NewPostgresLocker creates a new advisory-lock based locker identifying
+// itself as holderID
+func NewPostgresLocker(db *sql.DB, holderID string) *PostgresLocker {
+	return &PostgresLocker{db: db, holderID: holderID, keepAlive: defaultKeepAlive}
+}
+
+// TryAcquire takes the named advisory lock without blocking
+func (p *PostgresLocker) TryAcquire(ctx context.Context, name string) (Lock, error) {
+	conn, err := p.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("distlock: get connection: %w", err)
+	}
+
+	key := advisoryKey(name)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("distlock: acquire %q: %w", name, err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, ErrNotAcquired
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT set_config('application_name', $1, false)", p.holderID); err != nil {
+		conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key)
+		conn.Close()
+		return nil, fmt.Errorf("distlock: tag holder of %q: %w", name, err)
+	}
+
+	lock := &postgresLock{
+		conn: conn,
+		name: name,
+		key:  key,
+		lost: make(chan struct{}),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go lock.watch(p.keepAlive)
+	return lock, nil
+}
+
+// Holder returns the ID of the instance holding the named lock, or an empty
+// string if nobody holds it
+func (p *PostgresLocker) Holder(ctx context.Context, name string) (string, error) {
+	key := uint64(advisoryKey(name))
+	var holder string
+	err := p.db.QueryRowContext(ctx, `
+		SELECT a.application_name
+		FROM pg_locks l
+		JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.granted
+			AND l.classid = $1::bigint::oid AND l.objid = $2::bigint::oid AND l.objsubid = 1`,
+		int64(key>>32), int64(key&0xffffffff),
+	).Scan(&holder)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("distlock: look up holder of %q: %w", name, err)
+	}
+	return holder, nil
+}
+
+// advisoryKey maps a lock name onto PostgreSQL's 64-bit advisory lock space
+func advisoryKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+type postgresLock struct {
+	conn *sql.Conn
+	name string
+	key  int64
+
+	lost     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (l *postgresLock) Name() string { return l.name }
+
+func (l *postgresLock) Lost() <-chan struct{} { return l.lost }
+
+// watch pings the pinned connection and reports the lock lost when it fails
+func (l *postgresLock) watch(interval time.Duration) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			err := l.conn.PingContext(ctx)
+			cancel()
+			if err != nil {
+				close(l.lost)
+				l.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+func (l *postgresLock) Release(ctx context.Context) error {
+	var err error
+	l.stopOnce.Do(func() {
+		close(l.stop)
+		<-l.done
+
+		select {
+		case <-l.lost:
+			// The session is gone and PostgreSQL already released the lock
+			return
+		default:
+		}
+
+		if _, execErr := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key); execErr != nil {
+			err = fmt.Errorf("distlock: release %q: %w", l.name, execErr)
+		}
+		l.conn.ExecContext(ctx, "RESET application_name")
+		l.conn.Close()
+	})
+	return err
+}
@@ -0,0 +1,205 @@
+// Package experiments assigns users to the variants of A/B experiments.
+// Assignments are deterministic: a hash of the experiment name and the user
+// ID picks the variant, so a user sees the same variant on every request
+// and instance without anything being stored. The first time a variant is
+// read for a user it is reported as an exposure, so analyses only count
+// users who were actually shown the experiment.
+package experiments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Variant is one arm of an experiment
+type Variant struct {
+	Name string
+	// Weight is the share of users assigned to the variant, relative to the
+	// weights of the other variants
+	Weight int
+}
+
+// Experiment is a named set of variants. The first variant is the control,
+// which users without an ID are shown.
+type Experiment struct {
+	Name     string
+	Variants []Variant
+}
+
+// Parse parses the variants of the experiment called name from spec, such
+// as "control|guided" or "control=9|annual=1". Variants are separated by
+// "|" and take an optional "=weight", 1 by default.
+func Parse(name, spec string) (Experiment, error) {
+	e := Experiment{Name: name}
+	if name == "" {
+		return e, errors.New("experiment name is required")
+	}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(spec, "|") {
+		variant, weight, weighted := strings.Cut(strings.TrimSpace(part), "=")
+		v := Variant{Name: strings.TrimSpace(variant), Weight: 1}
+		if v.Name == "" {
+			return e, fmt.Errorf("experiment %s has a variant without a name", name)
+		}
+		if seen[v.Name] {
+			return e, fmt.Errorf("experiment %s has variant %s twice", name, v.Name)
+		}
+		seen[v.Name] = true
+		if weighted {
+			w, err := strconv.Atoi(strings.TrimSpace(weight))
+			if err != nil || w < 1 {
+				return e, fmt.Errorf("weight of variant %s of experiment %s must be a positive integer", v.Name, name)
+			}
+			v.Weight = w
+		}
+		e.Variants = append(e.Variants, v)
+	}
+	if len(e.Variants) < 2 {
+		return e, fmt.Errorf("experiment %s needs at least two variants", name)
+	}
+	return e, nil
+}
+
+// Exposure records that a user was shown a variant of an experiment
+type Exposure struct {
+	Experiment string
+	Variant    string
+	UserID     string
+}
+
+// ExposureLogger records exposures for analysis
+type ExposureLogger interface {
+	LogExposure(ctx context.Context, exposure Exposure)
+}
+
+// Assigner assigns users to the variants of its experiments
+type Assigner struct {
+	experiments map[string]Experiment
+	names       []string
+	exposures   ExposureLogger
+	metrics     *collector
+}
+
+// New creates an assigner of experiments reporting exposures to exposures,
+// which may be nil
+func New(experiments []Experiment, exposures ExposureLogger) (*Assigner, error) {
+	a := &Assigner{
+		experiments: make(map[string]Experiment, len(experiments)),
+		exposures:   exposures,
+		metrics:     newCollector(),
+	}
+	for _, e := range experiments {
+		if _, ok := a.experiments[e.Name]; ok {
+			return nil, fmt.Errorf("experiment %s is defined twice", e.Name)
+		}
+		if len(e.Variants) == 0 {
+			return nil, fmt.Errorf("experiment %s has no variants", e.Name)
+		}
+		a.experiments[e.Name] = e
+		a.names = append(a.names, e.Name)
+	}
+	slices.Sort(a.names)
+	return a, nil
+}
+
+// Names returns the names of the experiments, sorted
+func (a *Assigner) Names() []string {
+	return slices.Clone(a.names)
+}
+
+// Assign returns the variant of the experiment called name userID is
+// assigned to, without recording an exposure. Users without an ID get the
+// control. ok is false for unknown experiments.
+func (a *Assigner) Assign(name, userID string) (variant string, ok bool) {
+	e, ok := a.experiments[name]
+	if !ok {
+		return "", false
+	}
+	if userID == "" {
+		return e.Variants[0].Name, true
+	}
+
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	// The name is hashed with the user so experiments split users
+	// independently of each other. FNV would not do: the low bits of its
+	// hashes follow the last bytes, the user's, whatever the experiment.
+	sum := sha256.Sum256([]byte(name + "\x00" + userID))
+	bucket := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+	for _, v := range e.Variants {
+		if bucket < v.Weight {
+			return v.Name, true
+		}
+		bucket -= v.Weight
+	}
+	return e.Variants[len(e.Variants)-1].Name, true
+}
+
+// For returns the assignments of userID, which record an exposure the
+// first time each experiment is read
+func (a *Assigner) For(userID string) *Assignments {
+	return &Assignments{assigner: a, userID: userID}
+}
+
+// Assignments are the variants one user is assigned to. They are meant to
+// live as long as a request, so exposures are recorded once per request.
+type Assignments struct {
+	assigner *Assigner
+	userID   string
+
+	mu      sync.Mutex
+	exposed map[string]bool
+}
+
+// Variant returns the variant of the experiment called name, or "" for
+// unknown experiments, and records the exposure of the user
+func (s *Assignments) Variant(ctx context.Context, name string) string {
+	variant, ok := s.assigner.Assign(name, s.userID)
+	if !ok {
+		return ""
+	}
+	s.expose(ctx, name, variant)
+	return variant
+}
+
+// All returns the variant of every experiment by experiment name and
+// records the exposures of the user
+func (s *Assignments) All(ctx context.Context) map[string]string {
+	all := make(map[string]string, len(s.assigner.names))
+	for _, name := range s.assigner.names {
+		all[name] = s.Variant(ctx, name)
+	}
+	return all
+}
+
+// expose records the first exposure of the user to an experiment. Users
+// without an ID were not assigned, so they are not counted.
+func (s *Assignments) expose(ctx context.Context, name, variant string) {
+	if s.userID == "" {
+		return
+	}
+	s.mu.Lock()
+	if s.exposed[name] {
+		s.mu.Unlock()
+		return
+	}
+	if s.exposed == nil {
+		s.exposed = make(map[string]bool)
+	}
+	s.exposed[name] = true
+	s.mu.Unlock()
+
+	s.assigner.metrics.exposures.WithLabelValues(name, variant).Inc()
+	if s.assigner.exposures != nil {
+		s.assigner.exposures.LogExposure(ctx, Exposure{Experiment: name, Variant: variant, UserID: s.userID})
+	}
+}
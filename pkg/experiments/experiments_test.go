@@ -0,0 +1,134 @@
+package experiments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedExposures collects the exposures logged
+type recordedExposures struct {
+	mu        sync.Mutex
+	exposures []Exposure
+}
+
+func (r *recordedExposures) LogExposure(ctx context.Context, exposure Exposure) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exposures = append(r.exposures, exposure)
+}
+
+func mustParse(t *testing.T, name, spec string) Experiment {
+	t.Helper()
+	e, err := Parse(name, spec)
+	require.NoError(t, err)
+	return e
+}
+
+func TestParse(t *testing.T) {
+	e, err := Parse("pricing", "control=9| annual = 1")
+	require.NoError(t, err)
+	assert.Equal(t, Experiment{Name: "pricing", Variants: []Variant{{Name: "control", Weight: 9}, {Name: "annual", Weight: 1}}}, e)
+
+	for spec, want := range map[string]string{
+		"control":          "needs at least two variants",
+		"control|":         "variant without a name",
+		"control|control":  "has variant control twice",
+		"control|guided=0": "must be a positive integer",
+		"control|guided=x": "must be a positive integer",
+	} {
+		_, err := Parse("onboarding", spec)
+		assert.ErrorContains(t, err, want, "spec %q", spec)
+	}
+}
+
+func TestAssign_Deterministic(t *testing.T) {
+	a, err := New([]Experiment{mustParse(t, "onboarding", "control|guided")}, nil)
+	require.NoError(t, err)
+	b, err := New([]Experiment{mustParse(t, "onboarding", "control|guided")}, nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 50; i++ {
+		user := fmt.Sprintf("user_%d", i)
+		first, ok := a.Assign("onboarding", user)
+		require.True(t, ok)
+		second, _ := b.Assign("onboarding", user)
+		assert.Equal(t, first, second, "every instance assigns %s the same variant", user)
+	}
+
+	variant, ok := a.Assign("onboarding", "")
+	assert.True(t, ok)
+	assert.Equal(t, "control", variant, "users without an ID get the control")
+	_, ok = a.Assign("checkout", "user_1")
+	assert.False(t, ok)
+}
+
+func TestAssign_Weights(t *testing.T) {
+	a, err := New([]Experiment{
+		mustParse(t, "pricing", "control=3|annual=1"),
+		mustParse(t, "onboarding", "control|guided"),
+	}, nil)
+	require.NoError(t, err)
+
+	counts := map[string]int{}
+	both := 0
+	for i := 0; i < 4000; i++ {
+		user := fmt.Sprintf("user_%d", i)
+		pricing, _ := a.Assign("pricing", user)
+		onboarding, _ := a.Assign("onboarding", user)
+		counts[pricing]++
+		if pricing == "annual" && onboarding == "guided" {
+			both++
+		}
+	}
+	assert.InDelta(t, 1000, counts["annual"], 150, "a quarter of users get annual")
+	assert.InDelta(t, 500, both, 100, "experiments split users independently")
+}
+
+func TestAssignments_Exposures(t *testing.T) {
+	recorded := &recordedExposures{}
+	a, err := New([]Experiment{
+		mustParse(t, "onboarding", "control|guided"),
+		mustParse(t, "pricing", "control|annual"),
+	}, recorded)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	s := a.For("user_1")
+	variant := s.Variant(ctx, "onboarding")
+	assert.Equal(t, variant, s.Variant(ctx, "onboarding"))
+	assert.Equal(t, "", s.Variant(ctx, "checkout"))
+	all := s.All(ctx)
+	assert.Equal(t, []string{"onboarding", "pricing"}, a.Names())
+	assert.Equal(t, variant, all["onboarding"])
+
+	require.Len(t, recorded.exposures, 2, "each experiment is exposed once")
+	assert.Equal(t, Exposure{Experiment: "onboarding", Variant: variant, UserID: "user_1"}, recorded.exposures[0])
+	assert.Equal(t, "pricing", recorded.exposures[1].Experiment)
+
+	a.For("").All(ctx)
+	assert.Len(t, recorded.exposures, 2, "users without an ID are not exposed")
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(a))
+	err = testutil.GatherAndCompare(registry, strings.NewReader(fmt.Sprintf(`
+# HELP experiment_exposures_total Users shown a variant of an experiment, counted once per request.
+# TYPE experiment_exposures_total counter
+experiment_exposures_total{experiment="onboarding",variant=%q} 1
+experiment_exposures_total{experiment="pricing",variant=%q} 1
+`, variant, all["pricing"])), "experiment_exposures_total")
+	assert.NoError(t, err)
+}
+
+func TestNew_Duplicate(t *testing.T) {
+	e := mustParse(t, "onboarding", "control|guided")
+	_, err := New([]Experiment{e, e}, nil)
+	assert.EqualError(t, err, "experiment onboarding is defined twice")
+}
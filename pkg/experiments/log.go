@@ -0,0 +1,27 @@
+package experiments
+
+import (
+	"context"
+
+	"clean-architecture/pkg/ctxkeys"
+	"clean-architecture/pkg/logger"
+)
+
+// LogExposures returns an ExposureLogger writing each exposure to log, with
+// the correlation ID of the request it happened in
+func LogExposures(log logger.Logger) ExposureLogger {
+	return logExposures{log: log}
+}
+
+type logExposures struct {
+	log logger.Logger
+}
+
+func (l logExposures) LogExposure(ctx context.Context, exposure Exposure) {
+	l.log.WithFields(map[string]interface{}{
+		"experiment":     exposure.Experiment,
+		"variant":        exposure.Variant,
+		"user_id":        exposure.UserID,
+		"correlation_id": ctxkeys.CorrelationID.Value(ctx),
+	}).Info("Experiment exposure")
+}
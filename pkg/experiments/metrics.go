@@ -0,0 +1,28 @@
+package experiments
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// collector counts exposures by experiment and variant, so the share of
+// users shown each variant can be checked against its weight
+type collector struct {
+	exposures *prometheus.CounterVec
+}
+
+func newCollector() *collector {
+	return &collector{
+		exposures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "experiment_exposures_total",
+			Help: "Users shown a variant of an experiment, counted once per request.",
+		}, []string{"experiment", "variant"}),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (a *Assigner) Describe(ch chan<- *prometheus.Desc) {
+	a.metrics.exposures.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (a *Assigner) Collect(ch chan<- prometheus.Metric) {
+	a.metrics.exposures.Collect(ch)
+}
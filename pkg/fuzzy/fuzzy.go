@@ -0,0 +1,105 @@
+// Package fuzzy scores how alike two names are, for finding records that
+// were probably entered twice.
+//
+// Names are compared with the Jaro-Winkler similarity once normalized as
+// by prefixindex, so case, accents and punctuation do not count, and again
+// with their words sorted, so "Lovelace, Ada" matches "Ada Lovelace".
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+
+	"clean-architecture/pkg/prefixindex"
+)
+
+// winklerPrefix is the longest common prefix Jaro-Winkler rewards, and
+// winklerScale how much each of its characters adds
+const (
+	winklerPrefix = 4
+	winklerScale  = 0.1
+)
+
+// Similarity returns how alike names a and b are, from 0 for nothing in
+// common to 1 for the same name. Empty names are alike to nothing.
+func Similarity(a, b string) float64 {
+	a, b = prefixindex.Normalize(a), prefixindex.Normalize(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	score := JaroWinkler(a, b)
+	if sorted := JaroWinkler(sortWords(a), sortWords(b)); sorted > score {
+		score = sorted
+	}
+	return score
+}
+
+// JaroWinkler returns the Jaro-Winkler similarity of a and b, comparing
+// them rune by rune as they are
+func JaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	score := jaro(ra, rb)
+
+	prefix := 0
+	for prefix < len(ra) && prefix < len(rb) && prefix < winklerPrefix && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return score + float64(prefix)*winklerScale*(1-score)
+}
+
+// jaro returns the Jaro similarity of a and b: the share of their runes
+// that match within half the longer length of each other, less half of
+// the matches that are out of order
+func jaro(a, b []rune) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	window := max(len(a), len(b))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matchedA := make([]bool, len(a))
+	matchedB := make([]bool, len(b))
+	matches := 0
+	for i := range a {
+		for j := max(0, i-window); j < min(len(b), i+window+1); j++ {
+			if !matchedB[j] && a[i] == b[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range a {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions)/2)/m) / 3
+}
+
+// sortWords returns the words of normalized name s in alphabetical order
+func sortWords(s string) string {
+	words := strings.Fields(s)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,37 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"martha", "marhta", 0.961},
+		{"dwayne", "duane", 0.840},
+		{"dixon", "dicksonx", 0.813},
+		{"same", "same", 1},
+		{"abc", "xyz", 0},
+		{"", "", 1},
+		{"a", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			assert.InDelta(t, tt.want, JaroWinkler(tt.a, tt.b), 0.001)
+			assert.InDelta(t, tt.want, JaroWinkler(tt.b, tt.a), 0.001, "similarity is symmetric")
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, Similarity("José O'Brien", "JOSE O BRIEN"))
+	assert.Equal(t, 1.0, Similarity("Lovelace, Ada", "Ada Lovelace"), "word order does not count")
+	assert.Greater(t, Similarity("Ada Lovelace", "Ada Lovelase"), 0.95)
+	assert.Less(t, Similarity("Ada Lovelace", "Grace Hopper"), 0.6)
+	assert.Equal(t, 0.0, Similarity("", "Ada"))
+	assert.Equal(t, 0.0, Similarity("--", "--"))
+}
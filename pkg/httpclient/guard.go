@@ -0,0 +1,139 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrForbiddenAddress is returned for destinations that resolve to a
+// loopback, private, link-local or otherwise non-public address
+var ErrForbiddenAddress = errors.New("httpclient: destination address not allowed")
+
+// reservedPrefixes are non-public ranges not covered by the netip.Addr
+// predicates used in Guard.Allows
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // TEST-NET-1
+	netip.MustParsePrefix("198.18.0.0/15"),   // Benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // TEST-NET-2
+	netip.MustParsePrefix("203.0.113.0/24"),  // TEST-NET-3
+	netip.MustParsePrefix("240.0.0.0/4"),     // Reserved, including broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, which can reach private IPv4
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // Local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // Documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, which embeds arbitrary IPv4
+}
+
+// Guard protects against server-side request forgery by refusing to
+// connect to non-public addresses. The check runs when each connection is
+// dialed, against the address actually dialed, so a host name that
+// resolves to a public address when validated and to a private one when
+// used (DNS rebinding) is still rejected.
+type Guard struct {
+	allowed  []netip.Prefix
+	resolver *net.Resolver
+}
+
+// NewGuard creates a guard. allowedNetworks lists CIDR ranges that are
+// reachable even though they are not public, e.g. an internal webhook
+// relay.
+func NewGuard(allowedNetworks []string) (*Guard, error) {
+	g := &Guard{resolver: net.DefaultResolver}
+	for _, network := range allowedNetworks {
+		network = strings.TrimSpace(network)
+		if network == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, fmt.Errorf("httpclient: invalid allowed network %q", network)
+		}
+		g.allowed = append(g.allowed, prefix.Masked())
+	}
+	return g, nil
+}
+
+// Allows reports whether connections to addr are permitted
+func (g *Guard) Allows(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range g.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		// Link-local covers cloud metadata endpoints such as
+		// 169.254.169.254; private covers fd00:ec2::254
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL validates a user-supplied URL before it is stored, so users get
+// immediate feedback. It requires an http or https URL whose host resolves
+// only to permitted addresses. Requests are checked again when they are
+// made, because DNS answers may change in between.
+func (g *Guard) CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("httpclient: invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("httpclient: unsupported URL scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("httpclient: URL has no host")
+	}
+	return g.checkHost(ctx, u.Hostname())
+}
+
+// checkHost resolves host and rejects it when any of its addresses is not
+// permitted
+func (g *Guard) checkHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !g.Allows(addr) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+		}
+		return nil
+	}
+
+	addrs, err := g.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("httpclient: resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !g.Allows(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, host, addr)
+		}
+	}
+	return nil
+}
+
+// control is a net.Dialer Control function rejecting connections to
+// addresses the guard does not permit
+func (g *Guard) control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !g.Allows(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard_Allows(t *testing.T) {
+	g, err := NewGuard(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		addr    string
+		allowed bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"255.255.255.255", false},
+		{"224.0.0.1", false},
+		{"::1", false},
+		{"::", false},
+		{"fe80::1", false},
+		{"fd00:ec2::254", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, g.Allows(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+func TestGuard_AllowedNetworks(t *testing.T) {
+	g, err := NewGuard([]string{"10.20.0.0/16"})
+	require.NoError(t, err)
+	assert.True(t, g.Allows(netip.MustParseAddr("10.20.1.1")))
+	assert.False(t, g.Allows(netip.MustParseAddr("10.21.1.1")))
+
+	_, err = NewGuard([]string{"10.20.0.0"})
+	assert.Error(t, err)
+}
+
+func TestGuard_CheckURL(t *testing.T) {
+	g, err := NewGuard(nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	assert.NoError(t, g.CheckURL(ctx, "https://93.184.216.34/hook"))
+	assert.ErrorIs(t, g.CheckURL(ctx, "http://169.254.169.254/latest/meta-data/"), ErrForbiddenAddress)
+	assert.ErrorIs(t, g.CheckURL(ctx, "http://[::1]:8080/"), ErrForbiddenAddress)
+	assert.ErrorIs(t, g.CheckURL(ctx, "http://localhost/"), ErrForbiddenAddress)
+	assert.Error(t, g.CheckURL(ctx, "file:///etc/passwd"))
+	assert.Error(t, g.CheckURL(ctx, "http:///path"))
+}
+
+func TestClient_BlocksPrivateNetworksAtDialTime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{BlockPrivateNetworks: true})
+	require.NoError(t, err)
+
+	// The host name passes no pre-validation; the dialer rejects the
+	// loopback address it resolves to
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+	_, err = client.Get(fmt.Sprintf("http://localhost:%d/", port))
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+
+	_, err = client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+}
+
+func TestClient_BlocksRedirectsToPrivateNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	// Loopback is exempt so the test server is reachable; the metadata
+	// address it redirects to is not
+	client, err := New(Config{BlockPrivateNetworks: true, AllowedNetworks: []string{"127.0.0.0/8"}})
+	require.NoError(t, err)
+	_, err = client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+}
+
+func TestClient_AllowedNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{BlockPrivateNetworks: true, AllowedNetworks: []string{"127.0.0.0/8"}})
+	require.NoError(t, err)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestClient_GuardExemptsProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer proxy.Close()
+
+	client, err := New(Config{ProxyURL: proxy.URL, BlockPrivateNetworks: true})
+	require.NoError(t, err)
+
+	resp, err := client.Get("http://93.184.216.34/hook")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "http://93.184.216.34/hook", proxied)
+
+	// Proxied hosts are checked before the request reaches the proxy
+	_, err = client.Get("http://169.254.169.254/latest/meta-data/")
+	assert.ErrorIs(t, err, ErrForbiddenAddress)
+}
@@ -0,0 +1,275 @@
+// Package httpclient builds HTTP clients for outbound calls such as
+// webhooks, identity providers and email APIs. Requests can be routed
+// through a forward proxy and restricted to an allowlist of destinations,
+// so the application works in locked-down networks that only permit
+// egress to known hosts. Clients calling user-supplied URLs can also refuse
+// private and metadata addresses to prevent server-side request forgery.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"clean-architecture/pkg/cost"
+)
+
+// ErrEgressDenied is returned for requests to a host outside the allowlist
+var ErrEgressDenied = errors.New("httpclient: destination not allowed")
+
+// Config holds configuration for outbound HTTP clients.
+type Config struct {
+	Timeout time.Duration // Overall request timeout; zero means none
+
+	// ProxyURL is an http://, https:// or socks5:// forward proxy. When empty
+	// the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables apply.
+	ProxyURL string
+	// NoProxy lists hosts, domains (.example.com) and CIDR ranges reached
+	// directly instead of through ProxyURL
+	NoProxy []string
+
+	// AllowedHosts restricts destinations; empty allows every host. See
+	// NewAllowlist for the pattern syntax.
+	AllowedHosts []string
+
+	// BlockPrivateNetworks rejects destinations resolving to loopback,
+	// private, link-local (including cloud metadata endpoints) and other
+	// non-public addresses. Enable it for clients calling user-supplied URLs.
+	BlockPrivateNetworks bool
+	// AllowedNetworks lists CIDR ranges exempt from BlockPrivateNetworks
+	AllowedNetworks []string
+}
+
+// New creates a client for outbound calls.
+func New(config Config) (*http.Client, error) {
+	transport, err := NewTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport, Timeout: config.Timeout}, nil
+}
+
+// NewTransport creates the round tripper used by New. Every request,
+// including each redirect a client follows, is checked against the
+// allowlist before it is sent, and counts as an external call towards the
+// cost.Counter of its context.
+func NewTransport(config Config) (http.RoundTripper, error) {
+	proxy, err := proxyFunc(config)
+	if err != nil {
+		return nil, err
+	}
+	allowlist, err := NewAllowlist(config.AllowedHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+
+	var rt http.RoundTripper = transport
+	if config.BlockPrivateNetworks {
+		guard, err := NewGuard(config.AllowedNetworks)
+		if err != nil {
+			return nil, err
+		}
+		rt = guardTransport(transport, guard)
+	}
+	if allowlist != nil {
+		rt = &egressTransport{next: rt, allowlist: allowlist}
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		cost.AddExternalCall(req.Context())
+		return rt.RoundTrip(req)
+	}), nil
+}
+
+// guardTransport makes transport dial only addresses the guard permits.
+// Connections to the configured proxy are exempt, since operators choose
+// it; the proxy resolves proxied hosts itself, so those are checked before
+// the request is sent instead.
+func guardTransport(transport *http.Transport, guard *Guard) http.RoundTripper {
+	proxies := &proxySet{addrs: make(map[string]bool)}
+	proxy := transport.Proxy
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		u, err := proxy(req)
+		if u != nil {
+			proxies.add(proxyAddr(u))
+		}
+		return u, err
+	}
+
+	direct := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	guarded := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: guard.control}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if proxies.has(addr) {
+			return direct.DialContext(ctx, network, addr)
+		}
+		return guarded.DialContext(ctx, network, addr)
+	}
+
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if u, err := proxy(req); err == nil && u != nil {
+			if err := guard.checkHost(req.Context(), req.URL.Hostname()); err != nil {
+				if req.Body != nil {
+					req.Body.Close()
+				}
+				return nil, err
+			}
+		}
+		return transport.RoundTrip(req)
+	})
+}
+
+// proxySet records the addresses of proxies the transport connects to
+type proxySet struct {
+	mu    sync.RWMutex
+	addrs map[string]bool
+}
+
+func (s *proxySet) add(addr string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addrs[addr] = true
+}
+
+func (s *proxySet) has(addr string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.addrs[addr]
+}
+
+// proxyAddr returns the host:port the transport dials for a proxy URL
+func proxyAddr(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return net.JoinHostPort(u.Hostname(), port)
+	}
+	port := map[string]string{"http": "80", "https": "443", "socks5": "1080"}[u.Scheme]
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// proxyFunc selects the proxy for each request
+func proxyFunc(config Config) (func(*http.Request) (*url.URL, error), error) {
+	if config.ProxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := url.Parse(config.ProxyURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("httpclient: invalid proxy URL")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("httpclient: unsupported proxy scheme %q", u.Scheme)
+	}
+
+	proxy := (&httpproxy.Config{
+		HTTPProxy:  config.ProxyURL,
+		HTTPSProxy: config.ProxyURL,
+		NoProxy:    strings.Join(config.NoProxy, ","),
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}, nil
+}
+
+// egressTransport rejects requests to hosts outside the allowlist
+type egressTransport struct {
+	next      http.RoundTripper
+	allowlist *Allowlist
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *egressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if host := req.URL.Hostname(); !t.allowlist.Allows(host) {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: %s", ErrEgressDenied, host)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// Allowlist matches destination hosts against a set of patterns.
+type Allowlist struct {
+	hosts    map[string]bool
+	suffixes []string
+	networks []*net.IPNet
+}
+
+// NewAllowlist parses host patterns: an exact host name or IP address, a
+// wildcard such as *.example.com matching every subdomain of example.com,
+// or a CIDR range such as 10.0.0.0/8 matching IP literals. It returns nil,
+// which allows every host, when there are no patterns.
+func NewAllowlist(patterns []string) (*Allowlist, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	a := &Allowlist{hosts: make(map[string]bool)}
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		switch {
+		case pattern == "":
+			continue
+		case strings.Contains(pattern, "/"):
+			_, network, err := net.ParseCIDR(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("httpclient: invalid allowed host %q", pattern)
+			}
+			a.networks = append(a.networks, network)
+		case strings.HasPrefix(pattern, "*."):
+			if strings.ContainsAny(pattern[2:], "*:") || pattern[2:] == "" {
+				return nil, fmt.Errorf("httpclient: invalid allowed host %q", pattern)
+			}
+			a.suffixes = append(a.suffixes, pattern[1:])
+		default:
+			if strings.ContainsAny(pattern, "*:") && net.ParseIP(pattern) == nil {
+				return nil, fmt.Errorf("httpclient: invalid allowed host %q", pattern)
+			}
+			a.hosts[pattern] = true
+		}
+	}
+	return a, nil
+}
+
+// Allows reports whether requests to host are permitted. A nil allowlist
+// permits every host.
+func (a *Allowlist) Allows(host string) bool {
+	if a == nil {
+		return true
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if a.hosts[host] {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for _, network := range a.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, suffix := range a.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/cost"
+)
+
+func TestAllowlist(t *testing.T) {
+	a, err := NewAllowlist([]string{"api.example.com", "*.hooks.example.org", "10.0.0.0/8", "::1"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"api.example.com", true},
+		{"API.Example.com.", true},
+		{"www.example.com", false},
+		{"a.hooks.example.org", true},
+		{"a.b.hooks.example.org", true},
+		{"hooks.example.org", false},
+		{"evilhooks.example.org", false},
+		{"10.1.2.3", true},
+		{"11.1.2.3", false},
+		{"::1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, a.Allows(tt.host))
+		})
+	}
+}
+
+func TestAllowlist_Empty(t *testing.T) {
+	a, err := NewAllowlist(nil)
+	require.NoError(t, err)
+	assert.True(t, a.Allows("anything.example.com"))
+}
+
+func TestNewAllowlist_Invalid(t *testing.T) {
+	for _, pattern := range []string{"10.0.0.0/33", "*.", "api.*.com", "host:443"} {
+		_, err := NewAllowlist([]string{pattern})
+		assert.Error(t, err, pattern)
+	}
+}
+
+func TestClient_DeniesHostsOutsideAllowlist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	allowed, err := New(Config{AllowedHosts: []string{"127.0.0.1"}})
+	require.NoError(t, err)
+	resp, err := allowed.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	denied, err := New(Config{AllowedHosts: []string{"api.example.com"}})
+	require.NoError(t, err)
+	_, err = denied.Get(server.URL)
+	assert.ErrorIs(t, err, ErrEgressDenied)
+}
+
+func TestClient_DeniesRedirectsOutsideAllowlist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://elsewhere.example.com/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{AllowedHosts: []string{"127.0.0.1"}})
+	require.NoError(t, err)
+	_, err = client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrEgressDenied)
+}
+
+func TestClient_CountsExternalCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/moved", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{})
+	require.NoError(t, err)
+	counter := cost.NewCounter()
+	req, err := http.NewRequestWithContext(cost.WithCounter(context.Background(), counter), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// The redirect is a call of its own
+	assert.Equal(t, int64(2), counter.Costs().ExternalCalls)
+}
+
+func TestProxy(t *testing.T) {
+	proxy, err := proxyFunc(Config{
+		ProxyURL: "http://proxy.internal:3128",
+		NoProxy:  []string{".internal.example.com", "10.0.0.0/8"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		target  string
+		proxied bool
+	}{
+		{"https://api.example.com/hook", true},
+		{"http://api.example.com/hook", true},
+		{"https://auth.internal.example.com/", false},
+		{"http://10.2.3.4/", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			target, _ := url.Parse(tt.target)
+			u, err := proxy(&http.Request{URL: target})
+			require.NoError(t, err)
+			if tt.proxied {
+				require.NotNil(t, u)
+				assert.Equal(t, "proxy.internal:3128", u.Host)
+			} else {
+				assert.Nil(t, u)
+			}
+		})
+	}
+}
+
+func TestProxy_Invalid(t *testing.T) {
+	_, err := New(Config{ProxyURL: "ftp://proxy.internal"})
+	assert.Error(t, err)
+
+	_, err = New(Config{ProxyURL: "not a url"})
+	assert.Error(t, err)
+}
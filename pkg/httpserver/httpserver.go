@@ -0,0 +1,127 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Server is a named HTTP server managed by a Group
+type Server struct {
+	Name   string
+	Server *http.Server
+
+	listener net.Listener
+}
+
+// Addr returns the address the server is listening on, or nil before Start
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// ListenFunc opens a listener for the given network address
+type ListenFunc func(network, addr string) (net.Listener, error)
+
+// ShutdownResult describes how one server of a Group shut down
+type ShutdownResult struct {
+	Server   *Server
+	Started  time.Time
+	Duration time.Duration // Time spent draining connections
+	Err      error
+	// Forced is set when connections were still open at the deadline and
+	// the server was closed forcibly
+	Forced bool
+}
+
+// Group runs several HTTP servers and shuts them down in the order they
+// were added
+type Group struct {
+	// Listen opens the servers' listeners, e.g. to inherit them from a parent
+	// process during a graceful upgrade. Defaults to net.Listen.
+	Listen ListenFunc
+	// OnShutdown, when set, is called as each server finishes shutting down
+	OnShutdown func(ShutdownResult)
+
+	servers []*Server
+	wg      sync.WaitGroup
+}
+
+// NewGroup creates a new server group
+func NewGroup() *Group {
+	return &Group{}
+}
+
+// Add registers a server with the group
+func (g *Group) Add(name string, server *http.Server) {
+	g.servers = append(g.servers, &Server{Name: name, Server: server})
+}
+
+// Servers returns the registered servers in shutdown order
+func (g *Group) Servers() []*Server {
+	return g.servers
+}
+
+// Start binds every server's address and starts serving in the background.
+// Errors from listeners that stop unexpectedly are sent to errs.
+func (g *Group) Start(errs chan<- error) error {
+	listen := g.Listen
+	if listen == nil {
+		listen = net.Listen
+	}
+
+	listeners := make([]net.Listener, 0, len(g.servers))
+	for _, s := range g.servers {
+		ln, err := listen("tcp", s.Server.Addr)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			return fmt.Errorf("%s server: %w", s.Name, err)
+		}
+		listeners = append(listeners, ln)
+	}
+
+	for i, s := range g.servers {
+		s.listener = listeners[i]
+		g.wg.Add(1)
+		go func(s *Server, ln net.Listener) {
+			defer g.wg.Done()
+			if err := s.Server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("%s server: %w", s.Name, err)
+			}
+		}(s, listeners[i])
+	}
+
+	return nil
+}
+
+// Shutdown gracefully stops the servers one after another, in the order they
+// were added, and waits for their serve loops to return. Servers still
+// draining when ctx ends are closed forcibly, dropping their connections.
+func (g *Group) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, s := range g.servers {
+		result := ShutdownResult{Server: s, Started: time.Now()}
+		result.Err = s.Server.Shutdown(ctx)
+		if result.Err != nil && ctx.Err() != nil {
+			s.Server.Close()
+			result.Forced = true
+		}
+		result.Duration = time.Since(result.Started)
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s server: %w", s.Name, result.Err))
+		}
+		if g.OnShutdown != nil {
+			g.OnShutdown(result)
+		}
+	}
+	g.wg.Wait()
+	return errors.Join(errs...)
+}
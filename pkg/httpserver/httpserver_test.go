@@ -0,0 +1,102 @@
+package httpserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(body string) *http.Server {
+	return &http.Server{
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, body)
+		}),
+	}
+}
+
+func TestGroup_StartAndShutdown(t *testing.T) {
+	group := NewGroup()
+	group.Add("api", newTestServer("api"))
+	group.Add("admin", newTestServer("admin"))
+
+	errs := make(chan error, 2)
+	require.NoError(t, group.Start(errs))
+
+	for _, s := range group.Servers() {
+		require.NotNil(t, s.Addr())
+
+		resp, err := http.Get("http://" + s.Addr().String())
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, s.Name, string(body))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, group.Shutdown(ctx))
+
+	for _, s := range group.Servers() {
+		_, err := net.DialTimeout("tcp", s.Addr().String(), 100*time.Millisecond)
+		assert.Error(t, err)
+	}
+	assert.Empty(t, errs)
+}
+
+func TestGroup_StartBindFailure(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer occupied.Close()
+
+	group := NewGroup()
+	group.Add("api", newTestServer("api"))
+	group.Add("admin", &http.Server{Addr: occupied.Addr().String()})
+
+	err = group.Start(make(chan error, 2))
+	assert.ErrorContains(t, err, "admin server")
+}
+
+func TestGroup_ShutdownForcesStuckServers(t *testing.T) {
+	stuck := make(chan struct{})
+	defer close(stuck)
+	entered := make(chan struct{})
+
+	group := NewGroup()
+	group.Add("api", &http.Server{
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(entered)
+			<-stuck
+		}),
+	})
+	group.Add("health", newTestServer("health"))
+
+	var results []ShutdownResult
+	group.OnShutdown = func(result ShutdownResult) {
+		results = append(results, result)
+	}
+	require.NoError(t, group.Start(make(chan error, 2)))
+
+	go http.Get("http://" + group.Servers()[0].Addr().String())
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := group.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "api", results[0].Server.Name)
+	assert.True(t, results[0].Forced)
+	assert.GreaterOrEqual(t, results[0].Duration, 100*time.Millisecond)
+	assert.Equal(t, "health", results[1].Server.Name)
+	assert.False(t, results[1].Forced, "idle servers stop without being forced")
+	assert.NoError(t, results[1].Err)
+}
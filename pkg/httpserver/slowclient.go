@@ -0,0 +1,126 @@
+package httpserver
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ErrSlowClient is returned from request body reads once a client stalls or
+// sends its body slower than the configured minimum rate
+var ErrSlowClient = errors.New("httpserver: request body sent too slowly")
+
+// SlowClientOptions configures the SlowClients middleware
+type SlowClientOptions struct {
+	// ReadTimeout is the server's read timeout. Body reads never extend the
+	// connection's read deadline past the start of the request plus
+	// ReadTimeout; zero means no overall bound.
+	ReadTimeout time.Duration
+	// StallTimeout is the longest a client may pause while sending a body
+	StallTimeout time.Duration
+	// MinRate is the average number of bytes per second a body must arrive
+	// at once StallTimeout has passed; zero disables the rate check
+	MinRate int64
+	// OnSlowClient is called when a client is cut off, e.g. to log it
+	OnSlowClient func(r *http.Request, err error)
+}
+
+// SlowClients creates a middleware that terminates clients drip-feeding
+// request bodies (slowloris-style attacks). Slow headers are handled by
+// http.Server.ReadHeaderTimeout; this covers the body, which a single
+// ReadTimeout can only bound as a whole. Before each read the connection's
+// read deadline is moved to StallTimeout from now, and reads fail with
+// ErrSlowClient once the average rate falls below MinRate. The connection is
+// closed after the response.
+func SlowClients(opts SlowClientOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if opts.StallTimeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			body := &guardedBody{
+				ReadCloser: r.Body,
+				controller: http.NewResponseController(w),
+				opts:       opts,
+				start:      start,
+				now:        time.Now,
+			}
+			if opts.ReadTimeout > 0 {
+				body.deadline = start.Add(opts.ReadTimeout)
+			}
+			body.report = func(err error) {
+				w.Header().Set("Connection", "close")
+				if opts.OnSlowClient != nil {
+					opts.OnSlowClient(r, err)
+				}
+			}
+			r.Body = body
+
+			next.ServeHTTP(w, r)
+			if body.err == nil {
+				// Do not leave a per-read deadline on a kept-alive connection
+				_ = body.controller.SetReadDeadline(time.Time{})
+			}
+		})
+	}
+}
+
+// guardedBody enforces the stall timeout and minimum rate on a request body
+type guardedBody struct {
+	io.ReadCloser
+	controller *http.ResponseController
+	opts       SlowClientOptions
+	start      time.Time
+	deadline   time.Time // Zero when the server sets no read timeout
+	now        func() time.Time
+	report     func(error)
+
+	received int64
+	err      error
+}
+
+// Read implements io.Reader
+func (b *guardedBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	now := b.now()
+	deadline := now.Add(b.opts.StallTimeout)
+	if !b.deadline.IsZero() && b.deadline.Before(deadline) {
+		deadline = b.deadline
+	}
+	// Writers that cannot set deadlines, such as test recorders, only get
+	// the rate check
+	_ = b.controller.SetReadDeadline(deadline)
+
+	n, err := b.ReadCloser.Read(p)
+	b.received += int64(n)
+	if errors.Is(err, os.ErrDeadlineExceeded) && (b.deadline.IsZero() || b.now().Before(b.deadline)) {
+		return n, b.fail()
+	}
+	if err == nil && b.opts.MinRate > 0 {
+		elapsed := b.now().Sub(b.start)
+		if elapsed > b.opts.StallTimeout && float64(b.received) < float64(b.opts.MinRate)*elapsed.Seconds() {
+			return n, b.fail()
+		}
+	}
+	return n, err
+}
+
+// fail cuts the client off and reports it
+func (b *guardedBody) fail() error {
+	b.err = ErrSlowClient
+	// Abort any further reads on the connection
+	_ = b.controller.SetReadDeadline(time.Now())
+	b.report(b.err)
+	return b.err
+}
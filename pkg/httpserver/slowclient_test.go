@@ -0,0 +1,123 @@
+package httpserver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowClientServer starts a server whose handler reads the whole body and
+// records the read error
+func slowClientServer(t *testing.T, opts SlowClientOptions) (addr string, readErr func() error, reported func() bool) {
+	var mu sync.Mutex
+	var err error
+	var slow bool
+	done := make(chan struct{}, 1)
+
+	opts.OnSlowClient = func(r *http.Request, e error) {
+		mu.Lock()
+		defer mu.Unlock()
+		slow = true
+	}
+	handler := SlowClients(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, e := io.ReadAll(r.Body)
+		mu.Lock()
+		err = e
+		mu.Unlock()
+		done <- struct{}{}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server.Listener.Addr().String(), func() error {
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("handler did not finish reading the body")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			return err
+		}, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return slow
+		}
+}
+
+func sendHeaders(t *testing.T, addr string, length int) net.Conn {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	_, err = fmt.Fprintf(conn, "POST / HTTP/1.1\r\nHost: test\r\nContent-Length: %d\r\n\r\n", length)
+	require.NoError(t, err)
+	return conn
+}
+
+func TestSlowClients_TerminatesStalledBodies(t *testing.T) {
+	addr, readErr, reported := slowClientServer(t, SlowClientOptions{StallTimeout: 100 * time.Millisecond})
+
+	conn := sendHeaders(t, addr, 100)
+	_, err := io.WriteString(conn, "only part of the body")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, readErr(), ErrSlowClient)
+	assert.True(t, reported())
+}
+
+func TestSlowClients_TerminatesBodiesBelowMinimumRate(t *testing.T) {
+	addr, readErr, reported := slowClientServer(t, SlowClientOptions{StallTimeout: 100 * time.Millisecond, MinRate: 1000})
+
+	conn := sendHeaders(t, addr, 1000)
+	go func() {
+		// One byte every 20ms stays under the stall timeout but far below
+		// the minimum rate
+		for i := 0; i < 1000; i++ {
+			if _, err := conn.Write([]byte("x")); err != nil {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+
+	assert.ErrorIs(t, readErr(), ErrSlowClient)
+	assert.True(t, reported())
+}
+
+func TestSlowClients_AllowsTimelyBodies(t *testing.T) {
+	addr, readErr, reported := slowClientServer(t, SlowClientOptions{StallTimeout: 100 * time.Millisecond, MinRate: 1000})
+
+	conn := sendHeaders(t, addr, 11)
+	_, err := io.WriteString(conn, "hello world")
+	require.NoError(t, err)
+
+	assert.NoError(t, readErr())
+	assert.False(t, reported())
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("Connection"))
+}
+
+func TestSlowClients_Disabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := SlowClients(SlowClientOptions{})(next)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/", strings.NewReader("body")))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,26 @@
+package httpserver
+
+import "net/http"
+
+// StatusWriter wraps http.ResponseWriter to capture the status code of the
+// response for middlewares observing it
+type StatusWriter struct {
+	http.ResponseWriter
+	// Status is the code written, http.StatusOK until WriteHeader is called
+	Status int
+}
+
+// NewStatusWriter wraps w
+func NewStatusWriter(w http.ResponseWriter) *StatusWriter {
+	return &StatusWriter{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (w *StatusWriter) WriteHeader(code int) {
+	w.Status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *StatusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,20 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := NewStatusWriter(rec)
+	assert.Equal(t, http.StatusOK, w.Status, "implicit 200 until a header is written")
+
+	w.WriteHeader(http.StatusTeapot)
+	assert.Equal(t, http.StatusTeapot, w.Status)
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.NoError(t, http.NewResponseController(w).Flush(), "the underlying writer is reachable")
+}
@@ -0,0 +1,198 @@
+package httpserver
+
+import (
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StreamOptions configures a StreamWriter
+type StreamOptions struct {
+	// ContentType and ContentDisposition are set on the response
+	ContentType        string
+	ContentDisposition string
+	// FlushInterval is how often written bytes are flushed to the client;
+	// zero flushes after every write
+	FlushInterval time.Duration
+	// StallTimeout is the longest a client may take to accept a flush
+	// before the connection is cut. The write deadline is moved to
+	// StallTimeout from now at every flush, so a stream may run longer than
+	// the server's write timeout while the client keeps reading. Zero keeps
+	// the server's write timeout.
+	StallTimeout time.Duration
+}
+
+// StreamWriter writes a response body of unknown length as it is produced,
+// sent with chunked transfer encoding and compressed with gzip when the
+// request accepts it. The response header is sent on the first write, so a
+// producer failing before it wrote anything can still answer with an error
+// status.
+type StreamWriter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	opts       StreamOptions
+	gzip       bool
+
+	gz        *gzip.Writer
+	started   bool
+	lastFlush time.Time
+	now       func() time.Time
+}
+
+// NewStreamWriter creates a StreamWriter for the response to r
+func NewStreamWriter(w http.ResponseWriter, r *http.Request, opts StreamOptions) *StreamWriter {
+	return &StreamWriter{
+		w:          w,
+		controller: http.NewResponseController(w),
+		opts:       opts,
+		gzip:       AcceptsGzip(r.Header.Get("Accept-Encoding")),
+		now:        time.Now,
+	}
+}
+
+// Started reports whether the response header was sent
+func (s *StreamWriter) Started() bool {
+	return s.started
+}
+
+// Write implements io.Writer, flushing once FlushInterval has passed since
+// the last flush
+func (s *StreamWriter) Write(p []byte) (int, error) {
+	if err := s.start(); err != nil {
+		return 0, err
+	}
+	var (
+		n   int
+		err error
+	)
+	if s.gz != nil {
+		n, err = s.gz.Write(p)
+	} else {
+		n, err = s.w.Write(p)
+	}
+	if err != nil {
+		return n, err
+	}
+	if s.now().Sub(s.lastFlush) >= s.opts.FlushInterval {
+		return n, s.Flush()
+	}
+	return n, nil
+}
+
+// Flush sends the bytes written so far to the client
+func (s *StreamWriter) Flush() error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	if s.gz != nil {
+		if err := s.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := s.extendDeadline(); err != nil {
+		return err
+	}
+	s.lastFlush = s.now()
+	// Writers that cannot flush, such as some test recorders, send the
+	// body once the handler returns
+	if err := s.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// Close completes the response. It must not be called for a stream that
+// failed, so the client sees an incomplete body rather than a complete,
+// truncated one.
+func (s *StreamWriter) Close() error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	if s.gz != nil {
+		if err := s.gz.Close(); err != nil {
+			return err
+		}
+	}
+	return s.Flush()
+}
+
+// start sends the response header
+func (s *StreamWriter) start() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+
+	h := s.w.Header()
+	if s.opts.ContentType != "" {
+		h.Set("Content-Type", s.opts.ContentType)
+	}
+	if s.opts.ContentDisposition != "" {
+		h.Set("Content-Disposition", s.opts.ContentDisposition)
+	}
+	h.Add("Vary", "Accept-Encoding")
+	h.Del("Content-Length")
+	// Proxies such as nginx would otherwise buffer the stream
+	h.Set("X-Accel-Buffering", "no")
+	if s.gzip {
+		h.Set("Content-Encoding", "gzip")
+		s.gz = gzip.NewWriter(s.w)
+	}
+	if err := s.extendDeadline(); err != nil {
+		return err
+	}
+	s.w.WriteHeader(http.StatusOK)
+	s.lastFlush = s.now()
+	return nil
+}
+
+func (s *StreamWriter) extendDeadline() error {
+	if s.opts.StallTimeout <= 0 {
+		return nil
+	}
+	err := s.controller.SetWriteDeadline(s.now().Add(s.opts.StallTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// AcceptsGzip reports whether an Accept-Encoding header accepts gzip, by
+// name or through *, with a quality above 0
+func AcceptsGzip(header string) bool {
+	accepted := false
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "x-gzip" && coding != "*" {
+			continue
+		}
+		ok := quality(params) > 0
+		// An explicit gzip entry overrides *
+		if coding != "*" {
+			return ok
+		}
+		accepted = ok
+	}
+	return accepted
+}
+
+// quality returns the q parameter of an Accept-Encoding entry, 1 when it
+// has none
+func quality(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
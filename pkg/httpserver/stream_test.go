@@ -0,0 +1,97 @@
+package httpserver
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamWriter(t *testing.T) {
+	w := httptest.NewRecorder()
+	s := NewStreamWriter(w, httptest.NewRequest(http.MethodGet, "/export", nil), StreamOptions{
+		ContentType:        "text/csv",
+		ContentDisposition: `attachment; filename="users.csv"`,
+		FlushInterval:      time.Second,
+	})
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	assert.False(t, s.Started(), "the header waits for the first write")
+	_, err := s.Write([]byte("id\n"))
+	require.NoError(t, err)
+	assert.True(t, s.Started())
+	assert.False(t, w.Flushed, "flushes wait for the interval")
+
+	now = now.Add(time.Second)
+	_, err = s.Write([]byte("user_1\n"))
+	require.NoError(t, err)
+	assert.True(t, w.Flushed)
+	require.NoError(t, s.Close())
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="users.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "id\nuser_1\n", w.Body.String())
+}
+
+func TestStreamWriter_Gzip(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/export", nil)
+	r.Header.Set("Accept-Encoding", "br;q=1.0, gzip;q=0.8")
+	w := httptest.NewRecorder()
+	s := NewStreamWriter(w, r, StreamOptions{ContentType: "application/x-ndjson"})
+
+	for i := 0; i < 3; i++ {
+		_, err := s.Write([]byte(`{"id":"user_1"}` + "\n"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.Close())
+
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+	gz, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"user_1"}`+"\n"+`{"id":"user_1"}`+"\n"+`{"id":"user_1"}`+"\n", string(body))
+}
+
+func TestStreamWriter_EmptyBody(t *testing.T) {
+	w := httptest.NewRecorder()
+	s := NewStreamWriter(w, httptest.NewRequest(http.MethodGet, "/export", nil), StreamOptions{ContentType: "application/x-ndjson"})
+
+	require.NoError(t, s.Close())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Empty(t, w.Body.String())
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{header: "", expected: false},
+		{header: "gzip", expected: true},
+		{header: "deflate, GZIP", expected: true},
+		{header: "x-gzip", expected: true},
+		{header: "gzip;q=0", expected: false},
+		{header: "gzip; q=0.5", expected: true},
+		{header: "*", expected: true},
+		{header: "*;q=0", expected: false},
+		{header: "gzip;q=0, *", expected: false},
+		{header: "br, identity", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.expected, AcceptsGzip(tt.header))
+		})
+	}
+}
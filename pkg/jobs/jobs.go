@@ -0,0 +1,314 @@
+// Package jobs is a job queue backed by Redis. A Client enqueues tasks, a
+// type and a payload, on named queues and a Worker runs them with the
+// handler registered for their type.
+//
+// Tasks are delivered at least once. A worker leases each task it runs and
+// renews the lease while its handler runs; tasks whose lease expires, as
+// when their worker died, run again. Failed tasks are retried with
+// exponential backoff up to their retry limit and then kept as dead tasks
+// until the retention of the worker expires.
+//
+// Each queue keeps its state in keys sharing the hash tag {queue}, so a
+// queue lives on one node of a Redis Cluster:
+//
+//	<prefix>:{queue}:pending    list of the IDs of tasks ready to run
+//	<prefix>:{queue}:scheduled  sorted set of IDs by the time they are due
+//	<prefix>:{queue}:active     sorted set of IDs by the end of their lease
+//	<prefix>:{queue}:dead       sorted set of IDs by the time they died
+//	<prefix>:{queue}:t:<id>     hash of the task
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Defaults of options left unset
+const (
+	DefaultPrefix   = "jobs"
+	DefaultQueue    = "default"
+	DefaultMaxRetry = 5
+)
+
+// ErrDuplicate is returned when enqueueing a task with the ID of a task
+// that has not finished yet
+var ErrDuplicate = errors.New("jobs: task already exists")
+
+// Task is a unit of work queued for a worker
+type Task struct {
+	ID      string
+	Type    string
+	Payload []byte
+	// Headers carry metadata such as correlation IDs next to the payload
+	Headers map[string]string
+	Queue   string
+
+	// Retried is how often the task failed and was retried, counting
+	// leases that expired. A task runs at most MaxRetry+1 times.
+	Retried  int
+	MaxRetry int
+	// LastError is the error of the last failed attempt
+	LastError  string
+	EnqueuedAt time.Time
+}
+
+// Stats are the numbers of tasks of a queue in each state
+type Stats struct {
+	Pending   int64
+	Scheduled int64
+	Active    int64
+	Dead      int64
+}
+
+// Option tunes how a task is enqueued
+type Option func(*options)
+
+type options struct {
+	id       string
+	queue    string
+	maxRetry int
+	headers  map[string]string
+	runAt    time.Time
+}
+
+// ID sets the ID of the task, which is random otherwise. Enqueueing a task
+// with the ID of one that has not finished yet fails with ErrDuplicate.
+func ID(id string) Option {
+	return func(o *options) { o.id = id }
+}
+
+// Queue puts the task on the named queue instead of the client's
+func Queue(name string) Option {
+	return func(o *options) { o.queue = name }
+}
+
+// MaxRetry sets how often the task is retried after failing; 0 runs it
+// once
+func MaxRetry(n int) Option {
+	return func(o *options) { o.maxRetry = n }
+}
+
+// Headers attaches metadata to the task
+func Headers(headers map[string]string) Option {
+	return func(o *options) { o.headers = headers }
+}
+
+// ProcessAt holds the task back until t
+func ProcessAt(t time.Time) Option {
+	return func(o *options) { o.runAt = t }
+}
+
+// ProcessIn holds the task back for d
+func ProcessIn(d time.Duration) Option {
+	return func(o *options) { o.runAt = time.Now().Add(d) }
+}
+
+// ClientConfig configures a Client
+type ClientConfig struct {
+	// Prefix starts every key of the queues (default: jobs)
+	Prefix string
+	// Queue is the queue of tasks enqueued without the Queue option
+	// (default: default)
+	Queue string
+	// MaxRetry is the retry limit of tasks enqueued without the MaxRetry
+	// option; negative values use DefaultMaxRetry
+	MaxRetry int
+}
+
+// Client enqueues tasks
+type Client struct {
+	rdb      *goredis.Client
+	prefix   string
+	queue    string
+	maxRetry int
+}
+
+// NewClient creates a client enqueueing tasks on rdb
+func NewClient(rdb *goredis.Client, cfg ClientConfig) *Client {
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultPrefix
+	}
+	if cfg.Queue == "" {
+		cfg.Queue = DefaultQueue
+	}
+	if cfg.MaxRetry < 0 {
+		cfg.MaxRetry = DefaultMaxRetry
+	}
+	return &Client{rdb: rdb, prefix: cfg.Prefix, queue: cfg.Queue, maxRetry: cfg.MaxRetry}
+}
+
+// enqueueScript stores a task and queues it, unless a task with its ID
+// exists
+var enqueueScript = goredis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 0
+end
+redis.call("HSET", KEYS[1], "type", ARGV[2], "payload", ARGV[3], "headers", ARGV[4],
+	"max_retry", ARGV[5], "retried", 0, "enqueued_at", ARGV[6])
+if tonumber(ARGV[7]) > tonumber(ARGV[6]) then
+	redis.call("ZADD", KEYS[3], ARGV[7], ARGV[1])
+else
+	redis.call("LPUSH", KEYS[2], ARGV[1])
+end
+return 1
+`)
+
+// Enqueue queues a task of taskType carrying payload
+func (c *Client) Enqueue(ctx context.Context, taskType string, payload []byte, opts ...Option) (*Task, error) {
+	o := options{queue: c.queue, maxRetry: c.maxRetry}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if taskType == "" {
+		return nil, errors.New("jobs: task type is required")
+	}
+	if o.id == "" {
+		o.id = newID()
+	}
+	headers, err := json.Marshal(o.headers)
+	if err != nil {
+		return nil, fmt.Errorf("jobs: encode headers: %w", err)
+	}
+
+	now := time.Now()
+	k := c.keys(o.queue)
+	created, err := enqueueScript.Run(ctx, c.rdb,
+		[]string{k.task(o.id), k.pending, k.scheduled},
+		o.id, taskType, payload, headers, o.maxRetry, now.UnixMilli(), o.runAt.UnixMilli(),
+	).Int()
+	if err != nil {
+		return nil, fmt.Errorf("jobs: enqueue %s: %w", taskType, err)
+	}
+	if created == 0 {
+		return nil, ErrDuplicate
+	}
+	return &Task{
+		ID:         o.id,
+		Type:       taskType,
+		Payload:    payload,
+		Headers:    o.headers,
+		Queue:      o.queue,
+		MaxRetry:   o.maxRetry,
+		EnqueuedAt: time.UnixMilli(now.UnixMilli()),
+	}, nil
+}
+
+// Stats counts the tasks of queue in each state
+func (c *Client) Stats(ctx context.Context, queue string) (Stats, error) {
+	k := c.keys(queue)
+	var pending, scheduled, active, dead *goredis.IntCmd
+	_, err := c.rdb.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pending = pipe.LLen(ctx, k.pending)
+		scheduled = pipe.ZCard(ctx, k.scheduled)
+		active = pipe.ZCard(ctx, k.active)
+		dead = pipe.ZCard(ctx, k.dead)
+		return nil
+	})
+	if err != nil {
+		return Stats{}, fmt.Errorf("jobs: stats of %s: %w", queue, err)
+	}
+	return Stats{Pending: pending.Val(), Scheduled: scheduled.Val(), Active: active.Val(), Dead: dead.Val()}, nil
+}
+
+// Dead returns up to limit of the tasks of queue that died, latest first
+func (c *Client) Dead(ctx context.Context, queue string, limit int) ([]*Task, error) {
+	k := c.keys(queue)
+	ids, err := c.rdb.ZRevRange(ctx, k.dead, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("jobs: list dead tasks of %s: %w", queue, err)
+	}
+	tasks := make([]*Task, 0, len(ids))
+	for _, id := range ids {
+		fields, err := c.rdb.HGetAll(ctx, k.task(id)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("jobs: get dead task %s: %w", id, err)
+		}
+		// Pruned since it was listed
+		if len(fields) == 0 {
+			continue
+		}
+		tasks = append(tasks, decodeTask(queue, id, fields))
+	}
+	return tasks, nil
+}
+
+// keys names the keys of one queue
+type keys struct {
+	pending, scheduled, active, dead string
+	taskPrefix                       string
+}
+
+func (c *Client) keys(queue string) keys {
+	return queueKeys(c.prefix, queue)
+}
+
+func queueKeys(prefix, queue string) keys {
+	base := prefix + ":{" + queue + "}:"
+	return keys{
+		pending:    base + "pending",
+		scheduled:  base + "scheduled",
+		active:     base + "active",
+		dead:       base + "dead",
+		taskPrefix: base + "t:",
+	}
+}
+
+func (k keys) task(id string) string {
+	return k.taskPrefix + id
+}
+
+// decodeTask reads a task from the fields of its hash
+func decodeTask(queue, id string, fields map[string]string) *Task {
+	task := &Task{
+		ID:        id,
+		Type:      fields["type"],
+		Payload:   []byte(fields["payload"]),
+		Queue:     queue,
+		LastError: fields["error"],
+	}
+	task.Retried, _ = strconv.Atoi(fields["retried"])
+	task.MaxRetry, _ = strconv.Atoi(fields["max_retry"])
+	if ms, err := strconv.ParseInt(fields["enqueued_at"], 10, 64); err == nil {
+		task.EnqueuedAt = time.UnixMilli(ms)
+	}
+	// Headers are written by Enqueue, so they decode
+	_ = json.Unmarshal([]byte(fields["headers"]), &task.Headers)
+	return task
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// permanentError marks a failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the task dies without being retried
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
@@ -0,0 +1,271 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/logger"
+)
+
+func TestQueueKeys(t *testing.T) {
+	k := queueKeys("jobs", "emails")
+	assert.Equal(t, "jobs:{emails}:pending", k.pending)
+	assert.Equal(t, "jobs:{emails}:scheduled", k.scheduled)
+	assert.Equal(t, "jobs:{emails}:active", k.active)
+	assert.Equal(t, "jobs:{emails}:dead", k.dead)
+	assert.Equal(t, "jobs:{emails}:t:42", k.task("42"))
+}
+
+func TestDecodeTask(t *testing.T) {
+	task := decodeTask("default", "42", map[string]string{
+		"type":        "email.send",
+		"payload":     `{"to":"ada@example.com"}`,
+		"headers":     `{"correlation_id":"req-1"}`,
+		"retried":     "2",
+		"max_retry":   "5",
+		"error":       "smtp: connection refused",
+		"enqueued_at": "1704110400000",
+	})
+	assert.Equal(t, &Task{
+		ID:         "42",
+		Type:       "email.send",
+		Payload:    []byte(`{"to":"ada@example.com"}`),
+		Headers:    map[string]string{"correlation_id": "req-1"},
+		Queue:      "default",
+		Retried:    2,
+		MaxRetry:   5,
+		LastError:  "smtp: connection refused",
+		EnqueuedAt: time.UnixMilli(1704110400000),
+	}, task)
+
+	task = decodeTask("default", "43", map[string]string{"type": "email.send", "headers": "null"})
+	assert.Nil(t, task.Headers, "tasks enqueued without headers have none")
+}
+
+func TestParseDequeued(t *testing.T) {
+	task, err := parseDequeued("default", []interface{}{"42", []interface{}{"type", "email.send", "retried", "1"}})
+	require.NoError(t, err)
+	assert.Equal(t, "42", task.ID)
+	assert.Equal(t, "email.send", task.Type)
+	assert.Equal(t, 1, task.Retried)
+
+	_, err = parseDequeued("default", []interface{}{"42"})
+	assert.Error(t, err)
+}
+
+func TestPermanent(t *testing.T) {
+	err := Permanent(errors.New("invalid payload"))
+	assert.True(t, IsPermanent(err))
+	assert.True(t, IsPermanent(fmt.Errorf("send email: %w", err)))
+	assert.EqualError(t, err, "invalid payload")
+	assert.False(t, IsPermanent(errors.New("timeout")))
+	assert.Nil(t, Permanent(nil))
+}
+
+func TestNewWorker_Defaults(t *testing.T) {
+	w := NewWorker(nil, WorkerConfig{}, logger.New())
+	assert.Equal(t, WorkerConfig{
+		Prefix:          DefaultPrefix,
+		Queues:          []string{DefaultQueue},
+		Concurrency:     DefaultConcurrency,
+		Lease:           DefaultLease,
+		PollInterval:    DefaultPollInterval,
+		RetryBackoff:    DefaultRetryBackoff,
+		MaxRetryBackoff: DefaultMaxRetryBackoff,
+		DeadRetention:   DefaultDeadRetention,
+	}, w.cfg)
+
+	w = NewWorker(nil, WorkerConfig{RetryBackoff: time.Hour}, logger.New())
+	assert.Equal(t, time.Hour, w.cfg.MaxRetryBackoff, "the cap is never below the first backoff")
+}
+
+func TestWorker_Backoff(t *testing.T) {
+	w := NewWorker(nil, WorkerConfig{RetryBackoff: time.Second, MaxRetryBackoff: 10 * time.Second}, logger.New())
+	for _, tt := range []struct {
+		retried  int
+		min, max time.Duration
+	}{
+		{retried: 0, min: 500 * time.Millisecond, max: time.Second},
+		{retried: 1, min: time.Second, max: 2 * time.Second},
+		{retried: 3, min: 4 * time.Second, max: 8 * time.Second},
+		{retried: 10, min: 5 * time.Second, max: 10 * time.Second},
+		{retried: 1000, min: 5 * time.Second, max: 10 * time.Second},
+	} {
+		d := w.backoff(tt.retried)
+		assert.GreaterOrEqual(t, d, tt.min, "retried %d", tt.retried)
+		assert.LessOrEqual(t, d, tt.max, "retried %d", tt.retried)
+	}
+}
+
+func TestWorker_Handle(t *testing.T) {
+	w := NewWorker(nil, WorkerConfig{}, logger.New())
+	w.Handle("boom", func(ctx context.Context, task *Task) error {
+		panic("nil map")
+	})
+
+	err := w.handle(context.Background(), &Task{Type: "boom"})
+	assert.EqualError(t, err, "job panicked: nil map")
+	assert.False(t, IsPermanent(err), "panics are retried")
+
+	err = w.handle(context.Background(), &Task{Type: "unknown"})
+	assert.True(t, IsPermanent(err), "tasks without a handler die")
+}
+
+func TestClient_EnqueueWithoutType(t *testing.T) {
+	_, err := NewClient(nil, ClientConfig{}).Enqueue(context.Background(), "", nil)
+	assert.EqualError(t, err, "jobs: task type is required")
+}
+
+// testRedis connects to the server at REDIS_ADDR, skipping the test
+// without one
+func testRedis(t *testing.T) *goredis.Client {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping Redis tests in short mode")
+	}
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_ADDR is not set")
+	}
+	rdb := goredis.NewClient(&goredis.Options{Addr: addr})
+	require.NoError(t, rdb.Ping(context.Background()).Err())
+	t.Cleanup(func() { rdb.Close() })
+	return rdb
+}
+
+func TestWorker_Redis(t *testing.T) {
+	rdb := testRedis(t)
+	ctx := context.Background()
+	prefix := "jobs-test-" + newID()
+	t.Cleanup(func() {
+		keys, _ := rdb.Keys(ctx, prefix+":*").Result()
+		if len(keys) > 0 {
+			rdb.Del(ctx, keys...)
+		}
+	})
+
+	client := NewClient(rdb, ClientConfig{Prefix: prefix, MaxRetry: 1})
+	worker := NewWorker(rdb, WorkerConfig{
+		Prefix:          prefix,
+		Concurrency:     2,
+		Lease:           time.Second,
+		PollInterval:    10 * time.Millisecond,
+		RetryBackoff:    10 * time.Millisecond,
+		MaxRetryBackoff: 10 * time.Millisecond,
+	}, logger.New())
+
+	var mu sync.Mutex
+	ran := map[string]int{}
+	done := make(chan string, 10)
+	worker.Handle("greet", func(ctx context.Context, task *Task) error {
+		mu.Lock()
+		ran[task.ID]++
+		mu.Unlock()
+		done <- task.ID
+		return nil
+	})
+	worker.Handle("flaky", func(ctx context.Context, task *Task) error {
+		mu.Lock()
+		ran[task.ID]++
+		mu.Unlock()
+		done <- task.ID
+		return errors.New("unavailable")
+	})
+	worker.Start(ctx)
+	defer worker.Stop()
+
+	wait := func(id string) {
+		t.Helper()
+		select {
+		case got := <-done:
+			assert.Equal(t, id, got)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("task %s did not run", id)
+		}
+	}
+
+	task, err := client.Enqueue(ctx, "greet", []byte("ada"), ID("greet-1"), Headers(map[string]string{"correlation_id": "req-1"}))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultQueue, task.Queue)
+	wait("greet-1")
+
+	_, err = client.Enqueue(ctx, "flaky", nil, ID("flaky-1"))
+	require.NoError(t, err)
+	_, err = client.Enqueue(ctx, "flaky", nil, ID("flaky-1"))
+	assert.ErrorIs(t, err, ErrDuplicate, "unfinished tasks are not queued twice")
+	wait("flaky-1")
+	wait("flaky-1")
+
+	require.Eventually(t, func() bool {
+		stats, err := client.Stats(ctx, DefaultQueue)
+		return err == nil && stats == Stats{Dead: 1}
+	}, 5*time.Second, 10*time.Millisecond, "the failed task dies after its retry")
+	dead, err := client.Dead(ctx, DefaultQueue, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "flaky-1", dead[0].ID)
+	assert.Equal(t, 1, dead[0].Retried)
+	assert.Equal(t, "unavailable", dead[0].LastError)
+
+	_, err = client.Enqueue(ctx, "greet", nil, ID("greet-1"))
+	require.NoError(t, err, "IDs of finished tasks can be used again")
+	wait("greet-1")
+
+	_, err = client.Enqueue(ctx, "greet", nil, ID("later"), ProcessIn(200*time.Millisecond))
+	require.NoError(t, err)
+	started := time.Now()
+	wait("later")
+	assert.GreaterOrEqual(t, time.Since(started), 150*time.Millisecond, "scheduled tasks wait until they are due")
+
+	mu.Lock()
+	assert.Equal(t, map[string]int{"greet-1": 2, "flaky-1": 2, "later": 1}, ran)
+	mu.Unlock()
+}
+
+func TestWorker_RedisExpiredLease(t *testing.T) {
+	rdb := testRedis(t)
+	ctx := context.Background()
+	prefix := "jobs-test-" + newID()
+	t.Cleanup(func() {
+		keys, _ := rdb.Keys(ctx, prefix+":*").Result()
+		if len(keys) > 0 {
+			rdb.Del(ctx, keys...)
+		}
+	})
+
+	// A worker that died holding the task leaves its lease to expire
+	client := NewClient(rdb, ClientConfig{Prefix: prefix})
+	_, err := client.Enqueue(ctx, "greet", nil, ID("orphan"))
+	require.NoError(t, err)
+	dead := NewWorker(rdb, WorkerConfig{Prefix: prefix, Lease: 50 * time.Millisecond}, logger.New())
+	task, err := dead.dequeue(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, task)
+
+	ran := make(chan *Task, 1)
+	worker := NewWorker(rdb, WorkerConfig{Prefix: prefix, PollInterval: 10 * time.Millisecond}, logger.New())
+	worker.Handle("greet", func(ctx context.Context, task *Task) error {
+		ran <- task
+		return nil
+	})
+	worker.Start(ctx)
+	defer worker.Stop()
+
+	select {
+	case task := <-ran:
+		assert.Equal(t, "orphan", task.ID)
+		assert.Equal(t, 1, task.Retried, "an expired lease counts as a failure")
+		assert.Equal(t, "lease expired", task.LastError)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the orphaned task did not run again")
+	}
+}
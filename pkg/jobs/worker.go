@@ -0,0 +1,426 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"clean-architecture/pkg/logger"
+)
+
+// Defaults of worker settings left unset
+const (
+	DefaultConcurrency     = 10
+	DefaultLease           = 30 * time.Second
+	DefaultPollInterval    = time.Second
+	DefaultRetryBackoff    = time.Second
+	DefaultMaxRetryBackoff = 10 * time.Minute
+	DefaultDeadRetention   = 7 * 24 * time.Hour
+)
+
+// settleTimeout bounds recording the outcome of a task
+const settleTimeout = 10 * time.Second
+
+// maxErrorSize caps the error kept with a failed task
+const maxErrorSize = 1024
+
+// Handler runs a task. Errors wrapped with Permanent kill the task without
+// retrying it.
+type Handler func(ctx context.Context, task *Task) error
+
+// WorkerConfig configures a Worker
+type WorkerConfig struct {
+	// Prefix starts every key of the queues (default: jobs)
+	Prefix string
+	// Queues are polled in order, so the tasks of a queue run before those
+	// of the queues after it (default: default)
+	Queues []string
+	// Concurrency is the number of tasks run at once (default: 10)
+	Concurrency int
+	// Lease is how long a task is reserved for its worker, renewed while
+	// its handler runs. Tasks of workers that died run again once it ends.
+	// (default: 30s)
+	Lease time.Duration
+	// PollInterval is the pause after finding every queue empty
+	// (default: 1s)
+	PollInterval time.Duration
+	// RetryBackoff is the delay before the first retry, doubled for each
+	// retry up to MaxRetryBackoff (defaults: 1s and 10m)
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// DeadRetention is how long dead tasks are kept (default: 168h)
+	DeadRetention time.Duration
+}
+
+// Worker runs the tasks of its queues with the handler registered for
+// their type
+type Worker struct {
+	rdb    *goredis.Client
+	cfg    WorkerConfig
+	keys   []keys
+	logger logger.Logger
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewWorker creates a worker running the tasks queued on rdb
+func NewWorker(rdb *goredis.Client, cfg WorkerConfig, logger logger.Logger) *Worker {
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultPrefix
+	}
+	if len(cfg.Queues) == 0 {
+		cfg.Queues = []string{DefaultQueue}
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultConcurrency
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = DefaultLease
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultRetryBackoff
+	}
+	if cfg.MaxRetryBackoff < cfg.RetryBackoff {
+		cfg.MaxRetryBackoff = max(DefaultMaxRetryBackoff, cfg.RetryBackoff)
+	}
+	if cfg.DeadRetention <= 0 {
+		cfg.DeadRetention = DefaultDeadRetention
+	}
+	queues := make([]keys, len(cfg.Queues))
+	for i, queue := range cfg.Queues {
+		queues[i] = queueKeys(cfg.Prefix, queue)
+	}
+	return &Worker{
+		rdb:      rdb,
+		cfg:      cfg,
+		keys:     queues,
+		logger:   logger,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers handler for tasks of taskType. Tasks of types without a
+// handler die.
+func (w *Worker) Handle(taskType string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[taskType] = handler
+}
+
+// Start runs tasks in the background until Stop
+func (w *Worker) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+	go w.run(ctx, w.done)
+}
+
+// Stop stops taking tasks and waits for the running ones to finish
+func (w *Worker) Stop() error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-done
+	return nil
+}
+
+// run takes tasks while fewer than Concurrency run, polling the queues
+// again after PollInterval when they are empty
+func (w *Worker) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	slots := make(chan struct{}, w.cfg.Concurrency)
+	for {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		task, err := w.dequeue(ctx)
+		if task == nil {
+			<-slots
+			if err != nil && ctx.Err() == nil {
+				w.logger.WithField("error", err.Error()).Warn("Failed to take job")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.cfg.PollInterval):
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			w.process(task)
+		}()
+	}
+}
+
+// dequeueScript makes the due scheduled tasks pending, requeues or kills
+// the tasks whose lease expired, then leases the next pending task
+var dequeueScript = goredis.NewScript(`
+local due = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", ARGV[1], "LIMIT", 0, 100)
+for _, id in ipairs(due) do
+	redis.call("ZREM", KEYS[2], id)
+	redis.call("LPUSH", KEYS[1], id)
+end
+
+local expired = redis.call("ZRANGEBYSCORE", KEYS[3], "-inf", ARGV[1], "LIMIT", 0, 100)
+for _, id in ipairs(expired) do
+	redis.call("ZREM", KEYS[3], id)
+	local task = ARGV[3] .. id
+	if redis.call("EXISTS", task) == 1 then
+		redis.call("HSET", task, "error", "lease expired")
+		local retried = redis.call("HINCRBY", task, "retried", 1)
+		if retried > tonumber(redis.call("HGET", task, "max_retry")) then
+			redis.call("ZADD", KEYS[4], ARGV[1], id)
+		else
+			redis.call("RPUSH", KEYS[1], id)
+		end
+	end
+end
+
+while true do
+	local id = redis.call("RPOP", KEYS[1])
+	if not id then
+		return false
+	end
+	local task = ARGV[3] .. id
+	if redis.call("EXISTS", task) == 1 then
+		redis.call("ZADD", KEYS[3], ARGV[2], id)
+		return {id, redis.call("HGETALL", task)}
+	end
+end
+`)
+
+// dequeue leases the next task of the first queue that has one. It returns
+// nil when every queue is empty.
+func (w *Worker) dequeue(ctx context.Context) (*Task, error) {
+	for i, k := range w.keys {
+		now := time.Now()
+		res, err := dequeueScript.Run(ctx, w.rdb,
+			[]string{k.pending, k.scheduled, k.active, k.dead},
+			now.UnixMilli(), now.Add(w.cfg.Lease).UnixMilli(), k.taskPrefix,
+		).Slice()
+		if errors.Is(err, goredis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("jobs: dequeue from %s: %w", w.cfg.Queues[i], err)
+		}
+		return parseDequeued(w.cfg.Queues[i], res)
+	}
+	return nil, nil
+}
+
+// parseDequeued reads the ID and hash fields returned by dequeueScript
+func parseDequeued(queue string, res []interface{}) (*Task, error) {
+	if len(res) != 2 {
+		return nil, fmt.Errorf("jobs: unexpected dequeue reply of %d values", len(res))
+	}
+	id, _ := res[0].(string)
+	values, _ := res[1].([]interface{})
+	fields := make(map[string]string, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		field, _ := values[i].(string)
+		value, _ := values[i+1].(string)
+		fields[field] = value
+	}
+	return decodeTask(queue, id, fields), nil
+}
+
+// process runs task, renewing its lease meanwhile, and records the outcome
+func (w *Worker) process(task *Task) {
+	k := queueKeys(w.cfg.Prefix, task.Queue)
+	log := w.logger.WithFields(map[string]interface{}{
+		"job_id":   task.ID,
+		"job_type": task.Type,
+		"queue":    task.Queue,
+		"retried":  task.Retried,
+	})
+
+	// Tasks run to completion once taken, also while the worker stops;
+	// only losing the lease to another worker cancels them
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		w.renew(ctx, cancel, k, task.ID, log)
+	}()
+
+	err := w.handle(ctx, task)
+	cancel()
+	<-renewed
+
+	settleCtx, settleCancel := context.WithTimeout(context.Background(), settleTimeout)
+	defer settleCancel()
+	var settled bool
+	var settleErr error
+	switch {
+	case err == nil:
+		settled, settleErr = w.complete(settleCtx, k, task.ID)
+		log.Debug("Job succeeded")
+	case IsPermanent(err) || task.Retried >= task.MaxRetry:
+		settled, settleErr = w.fail(settleCtx, k, task.ID, err, time.Time{})
+		log.WithField("error", err.Error()).Error("Job failed, giving up")
+	default:
+		retryAt := time.Now().Add(w.backoff(task.Retried))
+		settled, settleErr = w.fail(settleCtx, k, task.ID, err, retryAt)
+		log.WithFields(map[string]interface{}{
+			"error":    err.Error(),
+			"retry_at": retryAt,
+		}).Warn("Job failed, retrying")
+	}
+	if settleErr != nil {
+		// The lease expires and the task runs again
+		log.WithField("error", settleErr.Error()).Error("Failed to record job outcome")
+	} else if !settled {
+		log.Warn("Job lease expired before it finished; it runs again")
+	}
+}
+
+// handle runs the handler of task
+func (w *Worker) handle(ctx context.Context, task *Task) (err error) {
+	w.mu.Lock()
+	handler := w.handlers[task.Type]
+	w.mu.Unlock()
+	if handler == nil {
+		return Permanent(fmt.Errorf("no handler for job type %q", task.Type))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, task)
+}
+
+// renew extends the lease of the task every third of the lease until ctx
+// ends, cancelling the task when another worker took it over
+func (w *Worker) renew(ctx context.Context, cancel context.CancelFunc, k keys, id string, log logger.Logger) {
+	ticker := time.NewTicker(w.cfg.Lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		deadline := time.Now().Add(w.cfg.Lease)
+		changed, err := w.rdb.ZAddArgs(ctx, k.active, goredis.ZAddArgs{
+			XX:      true,
+			Ch:      true,
+			Members: []goredis.Z{{Score: float64(deadline.UnixMilli()), Member: id}},
+		}).Result()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.WithField("error", err.Error()).Warn("Failed to renew job lease")
+			}
+			continue
+		}
+		if changed == 0 {
+			log.Warn("Job lease expired, cancelling it")
+			cancel()
+			return
+		}
+	}
+}
+
+// completeScript drops a task that succeeded, unless its lease expired
+var completeScript = goredis.NewScript(`
+if redis.call("ZREM", KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call("DEL", KEYS[2])
+return 1
+`)
+
+// complete drops the succeeded task id. It reports false when the task
+// lost its lease and was requeued.
+func (w *Worker) complete(ctx context.Context, k keys, id string) (bool, error) {
+	done, err := completeScript.Run(ctx, w.rdb, []string{k.active, k.task(id)}, id).Int()
+	if err != nil {
+		return false, fmt.Errorf("jobs: complete %s: %w", id, err)
+	}
+	return done == 1, nil
+}
+
+// failScript schedules a failed task to be retried at ARGV[3], or kills it
+// when that is 0 and prunes the dead tasks older than ARGV[5]
+var failScript = goredis.NewScript(`
+if redis.call("ZREM", KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call("HSET", KEYS[2], "error", ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call("HINCRBY", KEYS[2], "retried", 1)
+	redis.call("ZADD", KEYS[3], ARGV[3], ARGV[1])
+	return 1
+end
+
+redis.call("ZADD", KEYS[4], ARGV[4], ARGV[1])
+local expired = redis.call("ZRANGEBYSCORE", KEYS[4], "-inf", ARGV[5], "LIMIT", 0, 100)
+for _, id in ipairs(expired) do
+	redis.call("DEL", ARGV[6] .. id)
+	redis.call("ZREM", KEYS[4], id)
+end
+return 1
+`)
+
+// fail records the failure of task id, retrying it at retryAt or killing
+// it when retryAt is zero. It reports false when the task lost its lease
+// and was requeued.
+func (w *Worker) fail(ctx context.Context, k keys, id string, cause error, retryAt time.Time) (bool, error) {
+	message := cause.Error()
+	if len(message) > maxErrorSize {
+		message = message[:maxErrorSize]
+	}
+	var retryMs int64
+	if !retryAt.IsZero() {
+		retryMs = retryAt.UnixMilli()
+	}
+	now := time.Now()
+	done, err := failScript.Run(ctx, w.rdb,
+		[]string{k.active, k.task(id), k.scheduled, k.dead},
+		id, message, retryMs, now.UnixMilli(), now.Add(-w.cfg.DeadRetention).UnixMilli(), k.taskPrefix,
+	).Int()
+	if err != nil {
+		return false, fmt.Errorf("jobs: record failure of %s: %w", id, err)
+	}
+	return done == 1, nil
+}
+
+// backoff returns the delay before the retry following retried retries:
+// RetryBackoff doubled per retry up to MaxRetryBackoff, of which the
+// second half is random so tasks failing together are not retried together
+func (w *Worker) backoff(retried int) time.Duration {
+	d := w.cfg.RetryBackoff
+	for i := 0; i < retried && d < w.cfg.MaxRetryBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, w.cfg.MaxRetryBackoff)
+	return d/2 + rand.N(d/2+1)
+}
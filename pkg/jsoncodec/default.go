@@ -0,0 +1,6 @@
+//go:build !segmentio
+
+package jsoncodec
+
+// Default is the codec selected at build time
+var Default = Standard
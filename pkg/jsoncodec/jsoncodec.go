@@ -0,0 +1,43 @@
+// Package jsoncodec hides the JSON implementation behind a small interface
+// so a faster one can replace encoding/json without touching callers.
+// Default is encoding/json unless the binary is built with the segmentio
+// build tag.
+package jsoncodec
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// Encoder writes JSON values to a stream, each followed by a newline
+type Encoder interface {
+	Encode(v interface{}) error
+	SetEscapeHTML(on bool)
+}
+
+// Decoder reads JSON values from a stream
+type Decoder interface {
+	Decode(v interface{}) error
+}
+
+// Codec creates encoders and decoders of one JSON implementation
+type Codec interface {
+	// Name identifies the implementation in logs
+	Name() string
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+	Marshal(v interface{}) ([]byte, error)
+}
+
+// Standard is encoding/json
+var Standard Codec = standardCodec{}
+
+type standardCodec struct{}
+
+func (standardCodec) Name() string { return "encoding/json" }
+
+func (standardCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+
+func (standardCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+func (standardCodec) Marshal(v interface{}) ([]byte, error) { return json.Marshal(v) }
@@ -0,0 +1,62 @@
+package jsoncodec
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sample struct {
+	Name      string                 `json:"name"`
+	Note      string                 `json:"note,omitempty"`
+	Tags      []string               `json:"tags"`
+	Meta      map[string]interface{} `json:"meta"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+func TestCodecs_MatchStandard(t *testing.T) {
+	value := sample{
+		Name:      "<b>Ünïcode</b> &   quotes \"",
+		Tags:      []string{"a", "b"},
+		Meta:      map[string]interface{}{"z": 1, "a": 2.5, "m": nil},
+		CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 123, time.UTC),
+	}
+
+	var expected bytes.Buffer
+	require.NoError(t, Standard.NewEncoder(&expected).Encode(value))
+
+	for _, c := range []Codec{Standard, Segmentio} {
+		t.Run(c.Name(), func(t *testing.T) {
+			var got bytes.Buffer
+			enc := c.NewEncoder(&got)
+			enc.SetEscapeHTML(true)
+			require.NoError(t, enc.Encode(value))
+			assert.Equal(t, expected.String(), got.String())
+
+			marshaled, err := c.Marshal(value)
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSuffix(expected.String(), "\n"), string(marshaled))
+
+			var decoded sample
+			require.NoError(t, c.NewDecoder(&got).Decode(&decoded))
+			assert.Equal(t, value.Name, decoded.Name)
+			assert.True(t, value.CreatedAt.Equal(decoded.CreatedAt))
+		})
+	}
+}
+
+func TestCodecs_DecodeErrors(t *testing.T) {
+	for _, c := range []Codec{Standard, Segmentio} {
+		t.Run(c.Name(), func(t *testing.T) {
+			var v sample
+			assert.ErrorIs(t, c.NewDecoder(strings.NewReader("")).Decode(&v), io.EOF)
+			assert.Error(t, c.NewDecoder(strings.NewReader(`{"name":`)).Decode(&v))
+			assert.Error(t, c.NewDecoder(strings.NewReader(`{"name":1}`)).Decode(&v))
+		})
+	}
+}
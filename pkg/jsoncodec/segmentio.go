@@ -0,0 +1,21 @@
+package jsoncodec
+
+import (
+	"io"
+
+	segmentio "github.com/segmentio/encoding/json"
+)
+
+// Segmentio is github.com/segmentio/encoding/json, a drop-in replacement
+// for encoding/json
+var Segmentio Codec = segmentioCodec{}
+
+type segmentioCodec struct{}
+
+func (segmentioCodec) Name() string { return "segmentio" }
+
+func (segmentioCodec) NewEncoder(w io.Writer) Encoder { return segmentio.NewEncoder(w) }
+
+func (segmentioCodec) NewDecoder(r io.Reader) Decoder { return segmentio.NewDecoder(r) }
+
+func (segmentioCodec) Marshal(v interface{}) ([]byte, error) { return segmentio.Marshal(v) }
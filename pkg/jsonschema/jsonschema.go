@@ -0,0 +1,174 @@
+// Package jsonschema derives JSON Schemas (draft 2020-12) from Go types, so
+// the schemas of API payloads follow the structs that encode them. Fields are
+// named by their json tags; fields without omitempty are required.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect generated schemas declare
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema. Only the keywords generated by For are modelled.
+type Schema struct {
+	Schema      string `json:"$schema,omitempty"`
+	ID          string `json:"$id,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Type is a type name, or a list of them for nullable values
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// For returns the schema of the values of v's type
+func For(v interface{}) (*Schema, error) {
+	return forType(reflect.TypeOf(v), nil)
+}
+
+// forType derives the schema of t. seen holds the structs being derived,
+// as recursive types have no finite inline schema.
+func forType(t reflect.Type, seen []reflect.Type) (*Schema, error) {
+	if t == nil {
+		return &Schema{}, nil
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case rawMessageType:
+		return &Schema{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema, err := forType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		if name, ok := schema.Type.(string); ok {
+			schema.Type = []string{name, "null"}
+		}
+		return schema, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}, nil
+		}
+		items, err := forType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("jsonschema: map key of %s is not a string", t)
+		}
+		values, err := forType(t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return forStruct(t, seen)
+	default:
+		return nil, fmt.Errorf("jsonschema: %s cannot be encoded as JSON", t)
+	}
+}
+
+func forStruct(t reflect.Type, seen []reflect.Type) (*Schema, error) {
+	for _, s := range seen {
+		if s == t {
+			return nil, fmt.Errorf("jsonschema: %s is recursive", t)
+		}
+	}
+	seen = append(seen, t)
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	if err := addFields(schema, t, seen); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// addFields adds the fields of struct t to schema, flattening embedded
+// structs the way encoding/json does
+func addFields(schema *Schema, t reflect.Type, seen []reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := addFields(schema, embedded, seen); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		// Nil pointers are omitted rather than encoded as null when the
+		// field is omitempty
+		omitEmpty := hasOption(options, "omitempty")
+		fieldType := field.Type
+		if omitEmpty && fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		property, err := forType(fieldType, seen)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+		}
+		schema.Properties[name] = property
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
+
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
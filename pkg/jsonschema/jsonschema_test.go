@@ -0,0 +1,74 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Audit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type item struct {
+	Audit
+	ID        string            `json:"id"`
+	Count     int64             `json:"count"`
+	Ratio     float64           `json:"ratio,omitempty"`
+	Enabled   bool              `json:"enabled"`
+	Tags      []string          `json:"tags,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Payload   json.RawMessage   `json:"payload"`
+	Data      []byte            `json:"data,omitempty"`
+	DeletedAt *time.Time        `json:"deleted_at,omitempty"`
+	ParentID  *string           `json:"parent_id"`
+	Ignored   string            `json:"-"`
+	internal  string
+	Untagged  string
+}
+
+func TestFor(t *testing.T) {
+	schema, err := For(item{})
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"created_by": {"type": "string"},
+			"id": {"type": "string"},
+			"count": {"type": "integer"},
+			"ratio": {"type": "number"},
+			"enabled": {"type": "boolean"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"payload": {},
+			"data": {"type": "string", "contentEncoding": "base64"},
+			"deleted_at": {"type": "string", "format": "date-time"},
+			"parent_id": {"type": ["string", "null"]},
+			"Untagged": {"type": "string"}
+		},
+		"required": ["created_by", "id", "count", "enabled", "payload", "parent_id", "Untagged"]
+	}`, string(encoded))
+}
+
+type node struct {
+	Children []node `json:"children"`
+}
+
+func TestFor_Unsupported(t *testing.T) {
+	_, err := For(node{})
+	assert.ErrorContains(t, err, "recursive")
+
+	_, err = For(struct {
+		Callback func() `json:"callback"`
+	}{})
+	assert.ErrorContains(t, err, "cannot be encoded as JSON")
+
+	_, err = For(map[int]string{})
+	assert.ErrorContains(t, err, "is not a string")
+}
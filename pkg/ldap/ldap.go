@@ -0,0 +1,390 @@
+// Package ldap verifies credentials against an LDAP directory such as
+// Active Directory over a bounded pool of connections. It mirrors
+// pkg/redis: build a Config, call New and Close the pool on shutdown.
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+)
+
+// Defaults applied when the corresponding Config field is zero
+const (
+	DefaultPoolSize = 5
+	DefaultTimeout  = 5 * time.Second
+)
+
+var (
+	// ErrInvalidCredentials is returned when the password is empty or the
+	// directory rejects the bind
+	ErrInvalidCredentials = errors.New("ldap: invalid credentials")
+	// ErrNotFound is returned when no entry matches a filter
+	ErrNotFound = errors.New("ldap: entry not found")
+	// ErrAmbiguous is returned when a filter that must identify one entry
+	// matches several
+	ErrAmbiguous = errors.New("ldap: filter matches several entries")
+	// ErrPoolClosed is returned once Close has been called
+	ErrPoolClosed = errors.New("ldap: pool closed")
+)
+
+// Config holds configuration for the directory connection and pool.
+type Config struct {
+	URL      string // ldap:// or ldaps:// URL
+	StartTLS bool   // Upgrade ldap:// connections with StartTLS before binding
+
+	CAFile             string // PEM bundle of CAs trusted instead of the system roots
+	ServerName         string // Name verified in the server certificate; defaults to the URL's host
+	InsecureSkipVerify bool   // Skip certificate verification; for testing only
+
+	// BindDN and BindPassword identify the service account used for
+	// searches; an empty BindDN searches anonymously
+	BindDN       string
+	BindPassword string
+
+	PoolSize int           // Maximum number of open connections
+	Timeout  time.Duration // Timeout for dialing and for each operation
+}
+
+// Entry is a directory entry with the attributes that were requested
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Get returns the first value of the named attribute, or ""
+func (e *Entry) Get(name string) string {
+	for attribute, values := range e.Attributes {
+		if strings.EqualFold(attribute, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// Values returns every value of the named attribute
+func (e *Entry) Values(name string) []string {
+	for attribute, values := range e.Attributes {
+		if strings.EqualFold(attribute, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+// conn is the part of *goldap.Conn the pool uses
+type conn interface {
+	Bind(username, password string) error
+	Search(request *goldap.SearchRequest) (*goldap.SearchResult, error)
+	SetTimeout(timeout time.Duration)
+	IsClosing() bool
+	Close() error
+}
+
+// Pool is a bounded pool of directory connections. Connections are dialed
+// lazily and rebound as the service account before every use, so a
+// connection left bound as a user is never used to search.
+type Pool struct {
+	config Config
+	dial   func() (conn, error)
+
+	// slots bounds the open connections; idle holds those not in use
+	slots chan struct{}
+	idle  chan conn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// New creates a pool. It does not connect: use Ping to check that the
+// directory is reachable.
+func New(config Config) (*Pool, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		return nil, fmt.Errorf("ldap: URL must be an ldap:// or ldaps:// URL")
+	}
+	if config.StartTLS && u.Scheme == "ldaps" {
+		return nil, fmt.Errorf("ldap: StartTLS cannot be used with an ldaps:// URL")
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = DefaultPoolSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	tlsConfig, err := buildTLSConfig(config, u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	dial := func() (conn, error) {
+		c, err := goldap.DialURL(config.URL, goldap.DialWithDialer(dialer), goldap.DialWithTLSConfig(tlsConfig))
+		if err != nil {
+			return nil, err
+		}
+		if config.StartTLS {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				c.Close()
+				return nil, err
+			}
+		}
+		return c, nil
+	}
+
+	return newPool(config, dial), nil
+}
+
+func newPool(config Config, dial func() (conn, error)) *Pool {
+	return &Pool{
+		config: config,
+		dial:   dial,
+		slots:  make(chan struct{}, config.PoolSize),
+		idle:   make(chan conn, config.PoolSize),
+	}
+}
+
+// buildTLSConfig returns the TLS settings for ldaps:// and StartTLS
+// connections
+func buildTLSConfig(config Config, host string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         host,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+	if config.ServerName != "" {
+		tlsConfig.ServerName = config.ServerName
+	}
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("ldap: reading CA file: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ldap: CA file %s contains no PEM certificates", config.CAFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+	return tlsConfig, nil
+}
+
+// Authenticate finds the single entry under baseDN matching filter and
+// binds as it with password. It returns ErrInvalidCredentials if the
+// password is empty, which directories would otherwise accept as an
+// unauthenticated bind, or wrong.
+func (p *Pool) Authenticate(ctx context.Context, baseDN, filter string, attributes []string, password string) (*Entry, error) {
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	var entry *Entry
+	err := p.withConn(ctx, func(c conn) error {
+		entries, err := search(c, baseDN, filter, attributes, 2)
+		if err != nil {
+			return err
+		}
+		switch len(entries) {
+		case 0:
+			return ErrNotFound
+		case 1:
+		default:
+			return ErrAmbiguous
+		}
+
+		if err := c.Bind(entries[0].DN, password); err != nil {
+			if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+				return ErrInvalidCredentials
+			}
+			return err
+		}
+		entry = entries[0]
+		return nil
+	})
+	return entry, err
+}
+
+// Search returns the entries under baseDN matching filter, searching as
+// the service account
+func (p *Pool) Search(ctx context.Context, baseDN, filter string, attributes []string) ([]*Entry, error) {
+	var entries []*Entry
+	err := p.withConn(ctx, func(c conn) error {
+		var err error
+		entries, err = search(c, baseDN, filter, attributes, 0)
+		return err
+	})
+	return entries, err
+}
+
+// Ping checks that the directory is reachable and accepts the service
+// account's credentials
+func (p *Pool) Ping(ctx context.Context) error {
+	return p.withConn(ctx, func(conn) error { return nil })
+}
+
+// Close closes idle connections; connections in use are closed when
+// released. Later calls fail with ErrPoolClosed.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	close(p.idle)
+	for c := range p.idle {
+		c.Close()
+	}
+	return nil
+}
+
+func search(c conn, baseDN, filter string, attributes []string, sizeLimit int) ([]*Entry, error) {
+	result, err := c.Search(goldap.NewSearchRequest(
+		baseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, sizeLimit, 0, false,
+		filter, attributes, nil,
+	))
+	if err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+			return nil, ErrAmbiguous
+		}
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		entry := &Entry{DN: e.DN, Attributes: make(map[string][]string, len(e.Attributes))}
+		for _, attribute := range e.Attributes {
+			entry.Attributes[attribute.Name] = attribute.Values
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// withConn runs fn on a pooled connection bound as the service account.
+// Connections that fail with a network error are discarded.
+func (p *Pool) withConn(ctx context.Context, fn func(conn) error) error {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	c, err := p.get()
+	if err != nil {
+		return err
+	}
+
+	timeout := p.config.Timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < timeout {
+			timeout = remaining
+		}
+	}
+	if timeout <= 0 {
+		c.Close()
+		return context.DeadlineExceeded
+	}
+	c.SetTimeout(timeout)
+
+	if p.config.BindDN != "" {
+		err = c.Bind(p.config.BindDN, p.config.BindPassword)
+		if err != nil && goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			err = fmt.Errorf("ldap: service account bind rejected: %w", err)
+		}
+	}
+	if err == nil {
+		err = fn(c)
+	}
+
+	p.put(c, err)
+	return err
+}
+
+// get returns an idle connection or dials a new one
+func (p *Pool) get() (conn, error) {
+	p.mu.Lock()
+	closed := p.closed
+	p.mu.Unlock()
+	if closed {
+		return nil, ErrPoolClosed
+	}
+
+	for {
+		select {
+		case c, ok := <-p.idle:
+			if !ok {
+				return nil, ErrPoolClosed
+			}
+			if c.IsClosing() {
+				c.Close()
+				continue
+			}
+			return c, nil
+		default:
+			c, err := p.dial()
+			if err != nil {
+				return nil, fmt.Errorf("ldap: connecting: %w", err)
+			}
+			return c, nil
+		}
+	}
+}
+
+// put returns c to the idle connections unless err shows it is broken or
+// the pool was closed
+func (p *Pool) put(c conn, err error) {
+	if c.IsClosing() || (err != nil && goldap.IsErrorWithCode(err, goldap.ErrorNetwork)) {
+		c.Close()
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		c.Close()
+		return
+	}
+	select {
+	case p.idle <- c:
+	default:
+		c.Close()
+	}
+}
+
+// UserFilter substitutes username, escaped, for every {username} in
+// template
+func UserFilter(template, username string) string {
+	return strings.ReplaceAll(template, "{username}", goldap.EscapeFilter(username))
+}
+
+// DNFilter substitutes dn, escaped, for every {dn} in template
+func DNFilter(template, dn string) string {
+	return strings.ReplaceAll(template, "{dn}", goldap.EscapeFilter(dn))
+}
+
+// CommonName returns the value of the first RDN of dn, such as "Admins"
+// for "CN=Admins,OU=Groups,DC=example,DC=com", or dn itself if it cannot
+// be parsed
+func CommonName(dn string) string {
+	parsed, err := goldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return dn
+	}
+	return parsed.RDNs[0].Attributes[0].Value
+}
@@ -0,0 +1,241 @@
+package ldap
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDirectory serves every search with its entries and accepts binds
+// with the listed passwords
+type fakeDirectory struct {
+	mu        sync.Mutex
+	passwords map[string]string
+	entries   []*goldap.Entry
+	searchErr error
+	dials     int
+	open      int
+	filters   []string
+}
+
+func (d *fakeDirectory) dial() (conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dials++
+	d.open++
+	return &fakeConn{directory: d}, nil
+}
+
+type fakeConn struct {
+	directory *fakeDirectory
+	bound     string
+	closed    bool
+}
+
+func (c *fakeConn) Bind(username, password string) error {
+	if want, ok := c.directory.passwords[username]; !ok || want != password {
+		return goldap.NewError(goldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	c.bound = username
+	return nil
+}
+
+func (c *fakeConn) Search(request *goldap.SearchRequest) (*goldap.SearchResult, error) {
+	d := c.directory
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.filters = append(d.filters, request.Filter)
+	if d.searchErr != nil {
+		return nil, d.searchErr
+	}
+	if request.SizeLimit > 0 && len(d.entries) > request.SizeLimit {
+		return nil, goldap.NewError(goldap.LDAPResultSizeLimitExceeded, errors.New("size limit exceeded"))
+	}
+	return &goldap.SearchResult{Entries: d.entries}, nil
+}
+
+func (c *fakeConn) SetTimeout(time.Duration) {}
+
+func (c *fakeConn) IsClosing() bool { return c.closed }
+
+func (c *fakeConn) Close() error {
+	if !c.closed {
+		c.closed = true
+		c.directory.mu.Lock()
+		c.directory.open--
+		c.directory.mu.Unlock()
+	}
+	return nil
+}
+
+const serviceDN = "cn=service,dc=example,dc=com"
+
+func newFakePool(d *fakeDirectory, poolSize int) *Pool {
+	if d.passwords == nil {
+		d.passwords = map[string]string{}
+	}
+	d.passwords[serviceDN] = "service-secret"
+	return newPool(Config{
+		BindDN:       serviceDN,
+		BindPassword: "service-secret",
+		PoolSize:     poolSize,
+		Timeout:      time.Second,
+	}, d.dial)
+}
+
+func bjensen() *goldap.Entry {
+	return goldap.NewEntry("uid=bjensen,ou=people,dc=example,dc=com", map[string][]string{
+		"mail":     {"bjensen@example.com"},
+		"memberOf": {"cn=admins,ou=groups,dc=example,dc=com", "cn=staff,ou=groups,dc=example,dc=com"},
+	})
+}
+
+func TestPool_Authenticate(t *testing.T) {
+	d := &fakeDirectory{
+		passwords: map[string]string{"uid=bjensen,ou=people,dc=example,dc=com": "hunter2"},
+		entries:   []*goldap.Entry{bjensen()},
+	}
+	pool := newFakePool(d, 2)
+
+	entry, err := pool.Authenticate(context.Background(), "dc=example,dc=com", "(uid=bjensen)", []string{"mail", "memberOf"}, "hunter2")
+	require.NoError(t, err)
+	assert.Equal(t, "uid=bjensen,ou=people,dc=example,dc=com", entry.DN)
+	assert.Equal(t, "bjensen@example.com", entry.Get("MAIL"))
+	assert.Len(t, entry.Values("memberof"), 2)
+
+	_, err = pool.Authenticate(context.Background(), "dc=example,dc=com", "(uid=bjensen)", nil, "wrong")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// A rejected password leaves the connection usable
+	assert.Equal(t, 1, d.dials)
+	assert.Equal(t, 1, d.open)
+}
+
+func TestPool_Authenticate_EmptyPassword(t *testing.T) {
+	d := &fakeDirectory{entries: []*goldap.Entry{bjensen()}}
+	pool := newFakePool(d, 1)
+
+	_, err := pool.Authenticate(context.Background(), "dc=example,dc=com", "(uid=bjensen)", nil, "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Zero(t, d.dials)
+}
+
+func TestPool_Authenticate_Lookup(t *testing.T) {
+	pool := newFakePool(&fakeDirectory{}, 1)
+	_, err := pool.Authenticate(context.Background(), "dc=example,dc=com", "(uid=nobody)", nil, "secret")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	pool = newFakePool(&fakeDirectory{entries: []*goldap.Entry{bjensen(), bjensen(), bjensen()}}, 1)
+	_, err = pool.Authenticate(context.Background(), "dc=example,dc=com", "(mail=*)", nil, "secret")
+	assert.ErrorIs(t, err, ErrAmbiguous)
+}
+
+func TestPool_ServiceAccountRejected(t *testing.T) {
+	d := &fakeDirectory{}
+	pool := newFakePool(d, 1)
+	d.passwords[serviceDN] = "rotated"
+
+	err := pool.Ping(context.Background())
+	assert.ErrorContains(t, err, "service account bind rejected")
+}
+
+func TestPool_DiscardsBrokenConnections(t *testing.T) {
+	d := &fakeDirectory{searchErr: goldap.NewError(goldap.ErrorNetwork, errors.New("connection reset"))}
+	pool := newFakePool(d, 1)
+
+	_, err := pool.Search(context.Background(), "dc=example,dc=com", "(cn=*)", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 0, d.open)
+
+	d.searchErr = nil
+	_, err = pool.Search(context.Background(), "dc=example,dc=com", "(cn=*)", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, d.dials)
+}
+
+func TestPool_BoundsConnections(t *testing.T) {
+	d := &fakeDirectory{}
+	pool := newFakePool(d, 1)
+
+	held := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_ = pool.withConn(context.Background(), func(conn) error {
+			close(held)
+			<-release
+			return nil
+		})
+	}()
+	<-held
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Ping(ctx), context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, pool.Ping(context.Background()))
+	assert.Equal(t, 1, d.dials)
+}
+
+func TestPool_Close(t *testing.T) {
+	d := &fakeDirectory{}
+	pool := newFakePool(d, 2)
+	require.NoError(t, pool.Ping(context.Background()))
+
+	require.NoError(t, pool.Close())
+	assert.Equal(t, 0, d.open)
+	assert.ErrorIs(t, pool.Ping(context.Background()), ErrPoolClosed)
+	assert.NoError(t, pool.Close())
+}
+
+func TestNew(t *testing.T) {
+	for _, config := range []Config{
+		{URL: "http://ldap.example.com"},
+		{URL: "ldap://"},
+		{URL: "ldaps://ldap.example.com", StartTLS: true},
+		{URL: "ldaps://ldap.example.com", CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+	} {
+		_, err := New(config)
+		assert.Error(t, err, "%+v", config)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+	_, err := New(Config{URL: "ldaps://ldap.example.com", CAFile: caFile})
+	assert.ErrorContains(t, err, "no PEM certificates")
+
+	pool, err := New(Config{URL: "ldap://ldap.example.com:389", StartTLS: true})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPoolSize, cap(pool.slots))
+	assert.Equal(t, DefaultTimeout, pool.config.Timeout)
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	tlsConfig, err := buildTLSConfig(Config{}, "ldap.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "ldap.example.com", tlsConfig.ServerName)
+	assert.Nil(t, tlsConfig.RootCAs)
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+
+	tlsConfig, err = buildTLSConfig(Config{ServerName: "dc1.corp.example.com"}, "10.0.0.5")
+	require.NoError(t, err)
+	assert.Equal(t, "dc1.corp.example.com", tlsConfig.ServerName)
+}
+
+func TestFilters(t *testing.T) {
+	assert.Equal(t, `(&(objectClass=person)(uid=b\2a\29\28uid=\2a))`, UserFilter("(&(objectClass=person)(uid={username}))", "b*)(uid=*"))
+	assert.Equal(t, `(member=cn=a\28b\29,dc=example)`, DNFilter("(member={dn})", "cn=a(b),dc=example"))
+}
+
+func TestCommonName(t *testing.T) {
+	assert.Equal(t, "Domain Admins", CommonName("CN=Domain Admins,OU=Groups,DC=corp,DC=example,DC=com"))
+	assert.Equal(t, "engineers", CommonName("engineers"))
+}
@@ -26,35 +26,30 @@ type Logger interface {
 
 // logger implements the Logger interface
 type logger struct {
-	logrus *logrus.Logger
+	logrus *logrus.Entry
 }
 
-// New creates a new logger instance
+// New creates a new logger instance using the LOG_LEVEL environment variable
 func New() Logger {
+	return NewWithLevel(os.Getenv("LOG_LEVEL"))
+}
+
+// NewWithLevel creates a new logger instance with the given level. Unknown
+// levels default to info.
+func NewWithLevel(level string) Logger {
 	l := logrus.New()
 
 	// Set output to stdout
 	l.SetOutput(os.Stdout)
 
-	// Set log level from environment or default to info
-	logLevel := os.Getenv("LOG_LEVEL")
-	switch logLevel {
-	case "debug":
-		l.SetLevel(logrus.DebugLevel)
-	case "warn":
-		l.SetLevel(logrus.WarnLevel)
-	case "error":
-		l.SetLevel(logrus.ErrorLevel)
-	default:
-		l.SetLevel(logrus.InfoLevel)
-	}
+	l.SetLevel(levelOrInfo(level))
 
 	// Set formatter to JSON for structured logging
 	l.SetFormatter(&logrus.JSONFormatter{
 		TimestampFormat: "2006-01-02T15:04:05.000Z",
 	})
 
-	return &logger{logrus: l}
+	return &logger{logrus: logrus.NewEntry(l)}
 }
 
 // Debug logs debug level message
@@ -109,15 +104,15 @@ func (l *logger) Fatalf(format string, args ...interface{}) {
 
 // WithContext returns a logger with context
 func (l *logger) WithContext(ctx context.Context) Logger {
-	return &logger{logrus: l.logrus.WithContext(ctx).Logger}
+	return &logger{logrus: l.logrus.WithContext(ctx)}
 }
 
-// WithField returns a logger with a single field
+// WithField returns a logger that adds a single field to every entry
 func (l *logger) WithField(key string, value interface{}) Logger {
-	return &logger{logrus: l.logrus.WithField(key, value).Logger}
+	return &logger{logrus: l.logrus.WithField(key, value)}
 }
 
-// WithFields returns a logger with multiple fields
+// WithFields returns a logger that adds multiple fields to every entry
 func (l *logger) WithFields(fields map[string]interface{}) Logger {
-	return &logger{logrus: l.logrus.WithFields(fields).Logger}
+	return &logger{logrus: l.logrus.WithFields(fields)}
 }
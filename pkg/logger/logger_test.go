@@ -1,13 +1,21 @@
 package logger
 
 import (
+	"bytes"
 	"context"
 	"os"
 	"testing"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// entryOf returns the logrus entry backing l
+func entryOf(l Logger) *logrus.Entry {
+	return l.(*logger).logrus
+}
+
 func TestNew(t *testing.T) {
 	// Test default logger creation
 	logger := New()
@@ -39,8 +47,7 @@ func TestLogger_WithContext(t *testing.T) {
 
 	loggerWithContext := logger.WithContext(ctx)
 	assert.NotNil(t, loggerWithContext)
-	// Note: WithContext returns the same logger instance, so they should be equal
-	assert.Equal(t, logger, loggerWithContext)
+	assert.Equal(t, ctx, entryOf(loggerWithContext).Context)
 }
 
 func TestLogger_WithField(t *testing.T) {
@@ -48,8 +55,8 @@ func TestLogger_WithField(t *testing.T) {
 
 	loggerWithField := logger.WithField("key", "value")
 	assert.NotNil(t, loggerWithField)
-	// Note: WithField returns the same logger instance, so they should be equal
-	assert.Equal(t, logger, loggerWithField)
+	assert.Equal(t, "value", entryOf(loggerWithField).Data["key"])
+	assert.Empty(t, entryOf(logger).Data)
 }
 
 func TestLogger_WithFields(t *testing.T) {
@@ -61,8 +68,24 @@ func TestLogger_WithFields(t *testing.T) {
 
 	loggerWithFields := logger.WithFields(fields)
 	assert.NotNil(t, loggerWithFields)
-	// Note: WithFields returns the same logger instance, so they should be equal
-	assert.Equal(t, logger, loggerWithFields)
+	assert.Equal(t, "value1", entryOf(loggerWithFields).Data["key1"])
+	assert.Equal(t, "value2", entryOf(loggerWithFields).Data["key2"])
+	assert.Empty(t, entryOf(logger).Data)
+}
+
+func TestLogger_FieldsAreWritten(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWithLevel("info")
+	entryOf(logger).Logger.SetOutput(&buf)
+
+	logger.WithField("user_id", "user_1").WithFields(map[string]interface{}{"attempt": 2}).Info("retrying")
+	logger.Info("unrelated")
+
+	logged := entries(t, &buf)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "user_1", logged[0]["user_id"])
+	assert.Equal(t, float64(2), logged[0]["attempt"])
+	assert.NotContains(t, logged[1], "user_id", "fields stay on the logger they were added to")
 }
 
 func TestLogger_EnvironmentLevels(t *testing.T) {
@@ -130,6 +153,8 @@ func TestLogger_Chaining(t *testing.T) {
 	assert.NotNil(t, loggerWithContext)
 	assert.NotNil(t, loggerWithField)
 	assert.NotNil(t, loggerWithFields)
+	assert.Equal(t, "value", entryOf(loggerWithFields).Data["key"])
+	assert.Equal(t, "another_value", entryOf(loggerWithFields).Data["another_key"])
 }
 
 func TestLogger_ConcurrentAccess(t *testing.T) {
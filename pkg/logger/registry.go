@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Root is the name of a registry's root logger in Levels and SetLevel
+const Root = "root"
+
+var (
+	// ErrUnknownLevel is returned for levels other than debug, info, warn
+	// and error
+	ErrUnknownLevel = errors.New("unknown log level")
+	// ErrUnknownModule is returned for modules no logger was created for
+	ErrUnknownModule = errors.New("unknown log module")
+)
+
+// Registry creates the named loggers of an application's modules. They
+// share the registry's output and format, but each module has its own
+// level, which can be changed at runtime.
+type Registry struct {
+	out       io.Writer
+	formatter logrus.Formatter
+	root      *logrus.Logger
+
+	mu      sync.Mutex
+	modules map[string]*logrus.Logger
+}
+
+// ModuleLevel is the level of one module
+type ModuleLevel struct {
+	Module string `json:"module"`
+	Level  string `json:"level"`
+}
+
+// NewRegistry creates a registry whose root logger, and modules until they
+// are set otherwise, log at level. Unknown levels default to info.
+func NewRegistry(level string) *Registry {
+	r := &Registry{
+		out: os.Stdout,
+		formatter: &logrus.JSONFormatter{
+			TimestampFormat: "2006-01-02T15:04:05.000Z",
+		},
+		modules: make(map[string]*logrus.Logger),
+	}
+	r.root = r.newLogrus(levelOrInfo(level))
+	return r
+}
+
+// Root returns the logger of everything not belonging to a module
+func (r *Registry) Root() Logger {
+	return &logger{logrus: logrus.NewEntry(r.root)}
+}
+
+// Logger returns the logger of module, creating it at the root's current
+// level on first use. Its entries carry the module in the "module" field.
+func (r *Registry) Logger(module string) Logger {
+	return &logger{logrus: r.module(module).WithField("module", module)}
+}
+
+// SetLevel changes the level of module, or of the root logger when module
+// is Root
+func (r *Registry) SetLevel(module, level string) error {
+	lvl, ok := parseLevel(level)
+	if !ok {
+		return ErrUnknownLevel
+	}
+	if module == Root {
+		r.root.SetLevel(lvl)
+		return nil
+	}
+
+	r.mu.Lock()
+	l, ok := r.modules[module]
+	r.mu.Unlock()
+	if !ok {
+		return ErrUnknownModule
+	}
+	l.SetLevel(lvl)
+	return nil
+}
+
+// Level returns the level of module, or of the root logger when module is
+// Root
+func (r *Registry) Level(module string) (string, error) {
+	if module == Root {
+		return levelName(r.root.GetLevel()), nil
+	}
+
+	r.mu.Lock()
+	l, ok := r.modules[module]
+	r.mu.Unlock()
+	if !ok {
+		return "", ErrUnknownModule
+	}
+	return levelName(l.GetLevel()), nil
+}
+
+// Levels returns the level of the root logger followed by those of the
+// modules, sorted by name
+func (r *Registry) Levels() []ModuleLevel {
+	r.mu.Lock()
+	levels := make([]ModuleLevel, 0, len(r.modules)+1)
+	for module, l := range r.modules {
+		levels = append(levels, ModuleLevel{Module: module, Level: levelName(l.GetLevel())})
+	}
+	r.mu.Unlock()
+
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Module < levels[j].Module
+	})
+	return append([]ModuleLevel{{Module: Root, Level: levelName(r.root.GetLevel())}}, levels...)
+}
+
+// module returns the logrus logger of module, creating it when needed
+func (r *Registry) module(module string) *logrus.Logger {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.modules[module]
+	if !ok {
+		l = r.newLogrus(r.root.GetLevel())
+		r.modules[module] = l
+	}
+	return l
+}
+
+// newLogrus creates a logrus logger writing to the registry's output
+func (r *Registry) newLogrus(level logrus.Level) *logrus.Logger {
+	l := logrus.New()
+	l.SetOutput(r.out)
+	l.SetFormatter(r.formatter)
+	l.SetLevel(level)
+	return l
+}
+
+// parseLevel parses the levels accepted in configuration
+func parseLevel(level string) (logrus.Level, bool) {
+	switch level {
+	case "debug":
+		return logrus.DebugLevel, true
+	case "info":
+		return logrus.InfoLevel, true
+	case "warn":
+		return logrus.WarnLevel, true
+	case "error":
+		return logrus.ErrorLevel, true
+	}
+	return logrus.InfoLevel, false
+}
+
+// levelOrInfo parses level, defaulting to info
+func levelOrInfo(level string) logrus.Level {
+	lvl, _ := parseLevel(level)
+	return lvl
+}
+
+// levelName returns the configuration name of level
+func levelName(level logrus.Level) string {
+	if level == logrus.WarnLevel {
+		return "warn"
+	}
+	return level.String()
+}
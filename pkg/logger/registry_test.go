@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRegistry returns a registry writing to the returned buffer
+func newTestRegistry(level string) (*Registry, *bytes.Buffer) {
+	var buf bytes.Buffer
+	r := NewRegistry(level)
+	r.out = &buf
+	r.root.SetOutput(&buf)
+	return r, &buf
+}
+
+// entries decodes the JSON lines written to buf
+func entries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		out = append(out, entry)
+	}
+	return out
+}
+
+func TestRegistry_ModuleLevels(t *testing.T) {
+	r, buf := newTestRegistry("info")
+	database := r.Logger("database")
+	http := r.Logger("http")
+
+	require.NoError(t, r.SetLevel("database", "debug"))
+	require.NoError(t, r.SetLevel("http", "warn"))
+
+	database.Debug("query")
+	http.Info("request")
+	http.Warn("slow request")
+	r.Root().Debug("hidden")
+
+	logged := entries(t, buf)
+	require.Len(t, logged, 2)
+	assert.Equal(t, "query", logged[0]["msg"])
+	assert.Equal(t, "database", logged[0]["module"])
+	assert.Equal(t, "slow request", logged[1]["msg"])
+	assert.Equal(t, "http", logged[1]["module"])
+}
+
+func TestRegistry_LoggersShareModuleLevel(t *testing.T) {
+	r, buf := newTestRegistry("info")
+	before := r.Logger("database").WithField("table", "users")
+
+	require.NoError(t, r.SetLevel("database", "debug"))
+	before.Debug("created before the change")
+	r.Logger("database").Debug("created after the change")
+
+	assert.Len(t, entries(t, buf), 2)
+}
+
+func TestRegistry_NewModulesStartAtRootLevel(t *testing.T) {
+	r, _ := newTestRegistry("warn")
+	require.NoError(t, r.SetLevel(Root, "error"))
+	r.Logger("http")
+
+	level, err := r.Level("http")
+	require.NoError(t, err)
+	assert.Equal(t, "error", level)
+}
+
+func TestRegistry_SetLevel_Errors(t *testing.T) {
+	r, _ := newTestRegistry("info")
+	r.Logger("http")
+
+	assert.ErrorIs(t, r.SetLevel("http", "verbose"), ErrUnknownLevel)
+	assert.ErrorIs(t, r.SetLevel("http", "trace"), ErrUnknownLevel)
+	assert.ErrorIs(t, r.SetLevel("database", "debug"), ErrUnknownModule)
+	_, err := r.Level("database")
+	assert.ErrorIs(t, err, ErrUnknownModule)
+}
+
+func TestRegistry_Levels(t *testing.T) {
+	r, _ := newTestRegistry("unknown")
+	r.Logger("http")
+	r.Logger("database")
+	require.NoError(t, r.SetLevel("http", "warn"))
+
+	assert.Equal(t, []ModuleLevel{
+		{Module: Root, Level: "info"},
+		{Module: "database", Level: "info"},
+		{Module: "http", Level: "warn"},
+	}, r.Levels())
+}
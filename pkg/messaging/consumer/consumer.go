@@ -0,0 +1,297 @@
+// Package consumer runs event handlers on top of a messaging.Subscriber,
+// taking care of per-handler concurrency, retries with backoff, dead-letter
+// routing and idempotent processing.
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+)
+
+// IdempotencyKeyHeader names the header carrying a message's idempotency
+// key. Messages without it are deduplicated by ID.
+const IdempotencyKeyHeader = "idempotency-key"
+
+// ErrUnknownHandler is returned when delivering to a handler that is not
+// registered
+var ErrUnknownHandler = errors.New("consumer: unknown handler")
+
+// Handler describes an event handler and how its messages are processed
+type Handler struct {
+	// Name is the consumer group the handler joins
+	Name   string
+	Topics []string
+	Handle messaging.Handler
+
+	// Concurrency is the number of messages processed in parallel
+	Concurrency int
+	// MaxAttempts bounds how often a failing message is tried before it is
+	// dead-lettered. Zero uses the consumer default.
+	MaxAttempts int
+}
+
+// Options configures a Consumer
+type Options struct {
+	// DeadLetters receives messages that exhausted their retries; nil drops
+	// them after logging
+	DeadLetters DeadLetterSink
+	// Idempotency skips messages already handled by the same group; nil
+	// disables deduplication
+	Idempotency IdempotencyStore
+
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Consumer subscribes registered handlers and supervises their processing
+type Consumer struct {
+	subscriber messaging.Subscriber
+	opts       Options
+	logger     logger.Logger
+
+	mu       sync.Mutex
+	handlers []Handler
+	subs     []messaging.Subscription
+	ctx      context.Context
+	cancel   context.CancelFunc
+}
+
+// permanentError marks a failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the message is dead-lettered without further retries
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// New creates a new consumer reading from subscriber
+func New(subscriber messaging.Subscriber, opts Options, logger logger.Logger) *Consumer {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	return &Consumer{subscriber: subscriber, opts: opts, logger: logger}
+}
+
+// Register adds a handler. Handlers registered after Start are ignored until
+// the next Start.
+func (c *Consumer) Register(h Handler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers = append(c.handlers, h)
+}
+
+// Start subscribes every registered handler. Each unit of concurrency joins
+// the handler's consumer group as its own member.
+func (c *Consumer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ctx, c.cancel = context.WithCancel(ctx)
+	for _, h := range c.handlers {
+		concurrency := h.Concurrency
+		if concurrency <= 0 {
+			concurrency = 1
+		}
+		for _, topic := range h.Topics {
+			for i := 0; i < concurrency; i++ {
+				sub, err := c.subscriber.Subscribe(c.ctx, topic, h.Name, c.wrap(h))
+				if err != nil {
+					c.stopLocked()
+					return fmt.Errorf("subscribe %s to %s: %w", h.Name, topic, err)
+				}
+				c.subs = append(c.subs, sub)
+			}
+		}
+		c.logger.WithFields(map[string]interface{}{
+			"handler":     h.Name,
+			"topics":      h.Topics,
+			"concurrency": concurrency,
+		}).Info("Event handler started")
+	}
+	return nil
+}
+
+// Stop unsubscribes every handler and aborts pending retries
+func (c *Consumer) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopLocked()
+}
+
+func (c *Consumer) stopLocked() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	var errs []error
+	for _, sub := range c.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	c.subs = nil
+	return errors.Join(errs...)
+}
+
+// Names returns the names of the registered handlers
+func (c *Consumer) Names() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, 0, len(c.handlers))
+	for _, h := range c.handlers {
+		names = append(names, h.Name)
+	}
+	return names
+}
+
+// Deliver hands msg to the handler called name directly, bypassing the
+// broker so other groups do not see it again. It retries like a delivered
+// message but neither deduplicates nor dead-letters it, so a message the
+// group already processed is processed again, and returns the last error.
+func (c *Consumer) Deliver(ctx context.Context, name string, msg messaging.Message) error {
+	c.mu.Lock()
+	var handler *Handler
+	for i := range c.handlers {
+		if c.handlers[i].Name == name {
+			handler = &c.handlers[i]
+			break
+		}
+	}
+	c.mu.Unlock()
+	if handler == nil {
+		return fmt.Errorf("%w %s", ErrUnknownHandler, name)
+	}
+
+	maxAttempts := handler.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = c.opts.MaxAttempts
+	}
+	if correlationID := msg.Headers[correlation.MessageHeader]; correlationID != "" {
+		ctx = correlation.WithID(ctx, correlationID)
+	}
+	_, err := c.process(ctx, *handler, msg, maxAttempts)
+	return err
+}
+
+// wrap adds idempotency, retries and dead-lettering around a handler
+func (c *Consumer) wrap(h Handler) messaging.Handler {
+	maxAttempts := h.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = c.opts.MaxAttempts
+	}
+
+	return func(ctx context.Context, msg messaging.Message) error {
+		correlationID := msg.Headers[correlation.MessageHeader]
+		log := c.logger.WithFields(map[string]interface{}{
+			"handler":        h.Name,
+			"topic":          msg.Topic,
+			"message_id":     msg.ID,
+			"correlation_id": correlationID,
+		})
+
+		key := idempotencyKey(msg)
+		if c.opts.Idempotency != nil {
+			claimed, err := c.opts.Idempotency.Claim(ctx, h.Name, key)
+			if err != nil {
+				return fmt.Errorf("claim message %s: %w", msg.ID, err)
+			}
+			if !claimed {
+				log.Debug("Skipping already processed message")
+				return nil
+			}
+		}
+
+		// Handlers see the correlation ID of the call that caused the message
+		handlerCtx := c.ctx
+		if correlationID != "" {
+			handlerCtx = correlation.WithID(handlerCtx, correlationID)
+		}
+
+		attempts, err := c.process(handlerCtx, h, msg, maxAttempts)
+		if err == nil {
+			return nil
+		}
+
+		// Let a replay of the dead-lettered message run again
+		if c.opts.Idempotency != nil {
+			if releaseErr := c.opts.Idempotency.Release(context.WithoutCancel(ctx), h.Name, key); releaseErr != nil {
+				log.WithField("error", releaseErr.Error()).Error("Failed to release idempotency key")
+			}
+		}
+
+		log = log.WithFields(map[string]interface{}{
+			"attempts": attempts,
+			"error":    err.Error(),
+		})
+		if c.opts.DeadLetters == nil {
+			log.Error("Event handler failed, dropping message")
+			return nil
+		}
+		dlqErr := c.opts.DeadLetters.DeadLetter(context.WithoutCancel(ctx), DeadLetter{
+			Message:  msg,
+			Group:    h.Name,
+			Error:    err.Error(),
+			Attempts: attempts,
+			FailedAt: time.Now(),
+		})
+		if dlqErr != nil {
+			log.WithField("dlq_error", dlqErr.Error()).Error("Failed to dead-letter message")
+			return dlqErr
+		}
+		log.Warn("Event handler failed, message dead-lettered")
+		return nil
+	}
+}
+
+// process runs the handler until it succeeds, fails permanently, runs out of
+// attempts or the consumer stops. It returns the number of attempts made.
+func (c *Consumer) process(ctx context.Context, h Handler, msg messaging.Message, maxAttempts int) (int, error) {
+	backoff := c.opts.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := h.Handle(ctx, msg)
+		if err == nil {
+			return attempt, nil
+		}
+
+		if IsPermanent(err) || attempt >= maxAttempts {
+			return attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if c.opts.MaxBackoff > 0 && backoff > c.opts.MaxBackoff {
+			backoff = c.opts.MaxBackoff
+		}
+	}
+}
+
+// idempotencyKey returns the key a message is deduplicated by
+func idempotencyKey(msg messaging.Message) string {
+	if key := msg.Headers[IdempotencyKeyHeader]; key != "" {
+		return key
+	}
+	return msg.ID
+}
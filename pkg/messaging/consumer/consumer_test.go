@@ -0,0 +1,325 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/correlation"
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+	"clean-architecture/pkg/messaging/memory"
+)
+
+// recordingSink captures dead-lettered messages
+type recordingSink struct {
+	mu      sync.Mutex
+	letters []DeadLetter
+}
+
+func (s *recordingSink) DeadLetter(ctx context.Context, dl DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters = append(s.letters, dl)
+	return nil
+}
+
+func (s *recordingSink) all() []DeadLetter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]DeadLetter(nil), s.letters...)
+}
+
+func newTestConsumer(broker messaging.Subscriber, sink DeadLetterSink, store IdempotencyStore) *Consumer {
+	return New(broker, Options{
+		DeadLetters:    sink,
+		Idempotency:    store,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}, logger.New())
+}
+
+func TestConsumer_RetriesThenSucceeds(t *testing.T) {
+	ctx := context.Background()
+	broker := memory.New(8, logger.New())
+	sink := &recordingSink{}
+	c := newTestConsumer(broker, sink, nil)
+
+	var calls atomic.Int32
+	c.Register(Handler{
+		Name:   "projector",
+		Topics: []string{"user.created"},
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			if calls.Add(1) < 3 {
+				return errors.New("database busy")
+			}
+			return nil
+		},
+	})
+	require.NoError(t, c.Start(ctx))
+
+	require.NoError(t, broker.Publish(ctx, messaging.Message{Topic: "user.created"}))
+	require.NoError(t, broker.Close())
+
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Empty(t, sink.all())
+}
+
+func TestConsumer_DeadLettersAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	broker := memory.New(8, logger.New())
+	sink := &recordingSink{}
+	c := newTestConsumer(broker, sink, nil)
+
+	var calls atomic.Int32
+	c.Register(Handler{
+		Name:        "webhooks",
+		Topics:      []string{"user.created"},
+		MaxAttempts: 2,
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			calls.Add(1)
+			return errors.New("endpoint unavailable")
+		},
+	})
+	require.NoError(t, c.Start(ctx))
+
+	require.NoError(t, broker.Publish(ctx, messaging.Message{ID: "msg_1", Topic: "user.created"}))
+	require.NoError(t, broker.Close())
+
+	assert.Equal(t, int32(2), calls.Load())
+	letters := sink.all()
+	require.Len(t, letters, 1)
+	assert.Equal(t, "msg_1", letters[0].Message.ID)
+	assert.Equal(t, "webhooks", letters[0].Group)
+	assert.Equal(t, "endpoint unavailable", letters[0].Error)
+	assert.Equal(t, 2, letters[0].Attempts)
+}
+
+func TestConsumer_PermanentErrorSkipsRetries(t *testing.T) {
+	ctx := context.Background()
+	broker := memory.New(8, logger.New())
+	sink := &recordingSink{}
+	c := newTestConsumer(broker, sink, nil)
+
+	var calls atomic.Int32
+	c.Register(Handler{
+		Name:   "audit",
+		Topics: []string{"user.created"},
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			calls.Add(1)
+			return Permanent(errors.New("malformed payload"))
+		},
+	})
+	require.NoError(t, c.Start(ctx))
+
+	require.NoError(t, broker.Publish(ctx, messaging.Message{Topic: "user.created"}))
+	require.NoError(t, broker.Close())
+
+	assert.Equal(t, int32(1), calls.Load())
+	require.Len(t, sink.all(), 1)
+}
+
+func TestIsPermanent(t *testing.T) {
+	err := Permanent(errors.New("malformed payload"))
+	assert.True(t, IsPermanent(err))
+	assert.True(t, IsPermanent(fmt.Errorf("import user: %w", err)))
+	assert.False(t, IsPermanent(errors.New("database unavailable")))
+	assert.False(t, IsPermanent(nil))
+}
+
+func TestConsumer_Idempotency(t *testing.T) {
+	ctx := context.Background()
+	broker := memory.New(8, logger.New())
+	c := newTestConsumer(broker, nil, NewMemoryIdempotencyStore(time.Hour))
+
+	var calls atomic.Int32
+	c.Register(Handler{
+		Name:   "audit",
+		Topics: []string{"user.created"},
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			calls.Add(1)
+			return nil
+		},
+	})
+	require.NoError(t, c.Start(ctx))
+
+	require.NoError(t, broker.Publish(ctx, messaging.Message{ID: "msg_1", Topic: "user.created"}))
+	require.NoError(t, broker.Publish(ctx, messaging.Message{ID: "msg_1", Topic: "user.created"}))
+	require.NoError(t, broker.Publish(ctx, messaging.Message{
+		ID:      "msg_2",
+		Topic:   "user.created",
+		Headers: map[string]string{IdempotencyKeyHeader: "msg_1"},
+	}))
+	require.NoError(t, broker.Publish(ctx, messaging.Message{ID: "msg_3", Topic: "user.created"}))
+	require.NoError(t, broker.Close())
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestConsumer_Deliver(t *testing.T) {
+	ctx := context.Background()
+	broker := memory.New(8, logger.New())
+	sink := &recordingSink{}
+	c := newTestConsumer(broker, sink, NewMemoryIdempotencyStore(time.Hour))
+
+	var audited, projected atomic.Int32
+	seen := make(chan string, 1)
+	c.Register(Handler{
+		Name:   "audit",
+		Topics: []string{"user.created"},
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			audited.Add(1)
+			return nil
+		},
+	})
+	c.Register(Handler{
+		Name:   "projector",
+		Topics: []string{"user.created"},
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			switch projected.Add(1) {
+			case 2:
+				return errors.New("database busy")
+			case 3:
+				seen <- correlation.ID(ctx)
+			}
+			return nil
+		},
+	})
+	assert.Equal(t, []string{"audit", "projector"}, c.Names())
+	require.NoError(t, c.Start(ctx))
+
+	msg := messaging.Message{ID: "msg_1", Topic: "user.created", Headers: map[string]string{correlation.MessageHeader: "cor_123"}}
+	require.NoError(t, broker.Publish(ctx, msg))
+	require.NoError(t, broker.Close())
+	require.Equal(t, int32(1), projected.Load())
+
+	// The message was processed, yet the handler gets it again, with
+	// retries, while the other group does not
+	require.NoError(t, c.Deliver(ctx, "projector", msg))
+	assert.Equal(t, int32(3), projected.Load())
+	assert.Equal(t, int32(1), audited.Load())
+	assert.Equal(t, "cor_123", <-seen)
+
+	err := c.Deliver(ctx, "mailer", msg)
+	assert.ErrorIs(t, err, ErrUnknownHandler)
+	assert.Empty(t, sink.all())
+}
+
+func TestConsumer_Concurrency(t *testing.T) {
+	ctx := context.Background()
+	broker := memory.New(8, logger.New())
+	c := newTestConsumer(broker, nil, nil)
+
+	var active, peak atomic.Int32
+	release := make(chan struct{})
+	c.Register(Handler{
+		Name:        "projector",
+		Topics:      []string{"user.updated"},
+		Concurrency: 3,
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			n := active.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			<-release
+			active.Add(-1)
+			return nil
+		},
+	})
+	require.NoError(t, c.Start(ctx))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, broker.Publish(ctx, messaging.Message{Topic: "user.updated"}))
+	}
+	assert.Eventually(t, func() bool { return peak.Load() == 3 }, time.Second, time.Millisecond)
+
+	close(release)
+	require.NoError(t, broker.Close())
+}
+
+func TestConsumer_PropagatesCorrelationID(t *testing.T) {
+	ctx := context.Background()
+	broker := memory.New(8, logger.New())
+	c := newTestConsumer(broker, nil, nil)
+
+	seen := make(chan string, 1)
+	c.Register(Handler{
+		Name:   "audit",
+		Topics: []string{"user.created"},
+		Handle: func(ctx context.Context, msg messaging.Message) error {
+			seen <- correlation.ID(ctx)
+			return nil
+		},
+	})
+	require.NoError(t, c.Start(ctx))
+
+	require.NoError(t, broker.Publish(ctx, messaging.Message{
+		Topic:   "user.created",
+		Headers: map[string]string{correlation.MessageHeader: "cor_123"},
+	}))
+	require.NoError(t, broker.Close())
+
+	assert.Equal(t, "cor_123", <-seen)
+}
+
+func TestPublishDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	broker := memory.New(8, logger.New())
+
+	received := make(chan messaging.Message, 1)
+	_, err := broker.Subscribe(ctx, "user.created"+DeadLetterTopicSuffix, "ops", func(ctx context.Context, msg messaging.Message) error {
+		received <- msg
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = PublishDeadLetters(broker).DeadLetter(ctx, DeadLetter{
+		Message:  messaging.Message{ID: "msg_1", Topic: "user.created", Headers: map[string]string{"trace": "abc"}},
+		Group:    "webhooks",
+		Error:    "timeout",
+		Attempts: 5,
+	})
+	require.NoError(t, err)
+	require.NoError(t, broker.Close())
+
+	msg := <-received
+	assert.Equal(t, "msg_1", msg.ID)
+	assert.Equal(t, map[string]string{
+		"trace":              "abc",
+		"dlq-group":          "webhooks",
+		"dlq-error":          "timeout",
+		"dlq-attempts":       "5",
+		"dlq-original-topic": "user.created",
+	}, msg.Headers)
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore(time.Hour)
+
+	claimed, err := store.Claim(ctx, "audit", "msg_1")
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, _ = store.Claim(ctx, "audit", "msg_1")
+	assert.False(t, claimed)
+
+	// Other groups process the same message independently
+	claimed, _ = store.Claim(ctx, "projector", "msg_1")
+	assert.True(t, claimed)
+
+	require.NoError(t, store.Release(ctx, "audit", "msg_1"))
+	claimed, _ = store.Claim(ctx, "audit", "msg_1")
+	assert.True(t, claimed)
+}
@@ -0,0 +1,52 @@
+package consumer
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"clean-architecture/pkg/messaging"
+)
+
+// DeadLetterTopicSuffix is appended to a topic to name its dead-letter topic
+const DeadLetterTopicSuffix = ".dead"
+
+// DeadLetter is a message that could not be processed
+type DeadLetter struct {
+	Message  messaging.Message
+	Group    string
+	Error    string
+	Attempts int
+	FailedAt time.Time
+}
+
+// DeadLetterSink stores messages that exhausted their retries
+type DeadLetterSink interface {
+	DeadLetter(ctx context.Context, dl DeadLetter) error
+}
+
+// PublishDeadLetters returns a sink that republishes failed messages to
+// their topic's dead-letter topic, recording the failure in headers
+func PublishDeadLetters(publisher messaging.Publisher) DeadLetterSink {
+	return &topicSink{publisher: publisher}
+}
+
+type topicSink struct {
+	publisher messaging.Publisher
+}
+
+func (s *topicSink) DeadLetter(ctx context.Context, dl DeadLetter) error {
+	headers := make(map[string]string, len(dl.Message.Headers)+4)
+	for k, v := range dl.Message.Headers {
+		headers[k] = v
+	}
+	headers["dlq-group"] = dl.Group
+	headers["dlq-error"] = dl.Error
+	headers["dlq-attempts"] = strconv.Itoa(dl.Attempts)
+	headers["dlq-original-topic"] = dl.Message.Topic
+
+	msg := dl.Message
+	msg.Topic = dl.Message.Topic + DeadLetterTopicSuffix
+	msg.Headers = headers
+	return s.publisher.Publish(ctx, msg)
+}
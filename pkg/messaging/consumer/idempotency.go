@@ -0,0 +1,63 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// IdempotencyStore remembers which messages a consumer group has processed
+type IdempotencyStore interface {
+	// Claim records key as processed by group. It returns false if the key
+	// was already claimed.
+	Claim(ctx context.Context, group, key string) (bool, error)
+	// Release forgets a claim so the message can be processed again
+	Release(ctx context.Context, group, key string) error
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore whose claims
+// expire after a TTL
+type MemoryIdempotencyStore struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	claims    map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryIdempotencyStore creates a new in-memory store keeping claims for ttl
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{ttl: ttl, claims: make(map[string]time.Time)}
+}
+
+// Claim records key as processed by group
+func (s *MemoryIdempotencyStore) Claim(ctx context.Context, group, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	id := group + "/" + key
+	if expires, ok := s.claims[id]; ok && now.Before(expires) {
+		return false, nil
+	}
+	s.claims[id] = now.Add(s.ttl)
+
+	// Drop expired claims about once per TTL so the map stays bounded
+	if now.Sub(s.lastSweep) > s.ttl {
+		for claim, expires := range s.claims {
+			if now.After(expires) {
+				delete(s.claims, claim)
+			}
+		}
+		s.lastSweep = now
+	}
+	return true, nil
+}
+
+// Release forgets a claim
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, group, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.claims, group+"/"+key)
+	return nil
+}
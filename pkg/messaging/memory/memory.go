@@ -0,0 +1,217 @@
+// Package memory provides an in-process messaging broker with the same
+// consumer group semantics as the external brokers, for development and
+// tests.
+package memory
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+)
+
+// Broker is an in-process messaging.Broker. Messages are buffered per
+// subscriber and lost when the process exits.
+type Broker struct {
+	bufferSize int
+	logger     logger.Logger
+
+	mu     sync.RWMutex
+	topics map[string]map[string]*group
+	closed bool
+	wg     sync.WaitGroup
+}
+
+type group struct {
+	members []*subscription
+	next    int
+}
+
+type subscription struct {
+	broker  *Broker
+	topic   string
+	group   string
+	handler messaging.Handler
+	ch      chan messaging.Message
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New creates a new in-memory broker buffering up to bufferSize messages
+// per subscriber
+func New(bufferSize int, logger logger.Logger) *Broker {
+	return &Broker{
+		bufferSize: bufferSize,
+		logger:     logger,
+		topics:     make(map[string]map[string]*group),
+	}
+}
+
+// Publish delivers msg to one member of every group subscribed to its topic.
+// It blocks while the chosen member's buffer is full.
+func (b *Broker) Publish(ctx context.Context, msg messaging.Message) error {
+	if msg.ID == "" {
+		msg.ID = messaging.NewMessageID()
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return messaging.ErrClosed
+	}
+	targets := make([]*subscription, 0, len(b.topics[msg.Topic]))
+	for _, g := range b.topics[msg.Topic] {
+		targets = append(targets, g.pick(msg.Key))
+	}
+	b.mu.Unlock()
+
+	for _, sub := range targets {
+		if err := sub.deliver(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe registers handler as a member of group on topic
+func (b *Broker) Subscribe(ctx context.Context, topic, groupName string, handler messaging.Handler) (messaging.Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, messaging.ErrClosed
+	}
+
+	sub := &subscription{
+		broker:  b,
+		topic:   topic,
+		group:   groupName,
+		handler: handler,
+		ch:      make(chan messaging.Message, b.bufferSize),
+	}
+
+	groups, ok := b.topics[topic]
+	if !ok {
+		groups = make(map[string]*group)
+		b.topics[topic] = groups
+	}
+	g, ok := groups[groupName]
+	if !ok {
+		g = &group{}
+		groups[groupName] = g
+	}
+	g.members = append(g.members, sub)
+
+	b.wg.Add(1)
+	go sub.run()
+
+	return sub, nil
+}
+
+// Close stops accepting messages and waits for buffered messages to be
+// handled
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	var subs []*subscription
+	for _, groups := range b.topics {
+		for _, g := range groups {
+			subs = append(subs, g.members...)
+		}
+	}
+	b.topics = make(map[string]map[string]*group)
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.close()
+	}
+	b.wg.Wait()
+	return nil
+}
+
+// pick chooses the member receiving a message, keeping keyed messages on the
+// same member so they stay ordered
+func (g *group) pick(key string) *subscription {
+	if key != "" {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		return g.members[h.Sum32()%uint32(len(g.members))]
+	}
+	sub := g.members[g.next%len(g.members)]
+	g.next++
+	return sub
+}
+
+func (s *subscription) deliver(ctx context.Context, msg messaging.Message) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// The member left its group after the message was routed to it
+	if s.closed {
+		return nil
+	}
+
+	select {
+	case s.ch <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *subscription) run() {
+	defer s.broker.wg.Done()
+	for msg := range s.ch {
+		if err := s.handler(context.Background(), msg); err != nil {
+			s.broker.logger.WithFields(map[string]interface{}{
+				"topic":      s.topic,
+				"group":      s.group,
+				"message_id": msg.ID,
+				"error":      err.Error(),
+			}).Error("Message handler failed")
+		}
+	}
+}
+
+func (s *subscription) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+// Unsubscribe removes the member from its group. Messages already buffered
+// for it are still handled.
+func (s *subscription) Unsubscribe() error {
+	b := s.broker
+	b.mu.Lock()
+	if g, ok := b.topics[s.topic][s.group]; ok {
+		for i, member := range g.members {
+			if member == s {
+				g.members = append(g.members[:i], g.members[i+1:]...)
+				break
+			}
+		}
+		if len(g.members) == 0 {
+			delete(b.topics[s.topic], s.group)
+		}
+	}
+	b.mu.Unlock()
+
+	s.close()
+	return nil
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/logger"
+	"clean-architecture/pkg/messaging"
+)
+
+// collector records the messages a handler received
+type collector struct {
+	mu       sync.Mutex
+	messages []messaging.Message
+}
+
+func (c *collector) handle(ctx context.Context, msg messaging.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, msg)
+	return nil
+}
+
+func (c *collector) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.messages)
+}
+
+func TestBroker_ConsumerGroups(t *testing.T) {
+	ctx := context.Background()
+	broker := New(16, logger.New())
+
+	var auditA, auditB, projector collector
+	_, err := broker.Subscribe(ctx, "user.created", "audit", auditA.handle)
+	require.NoError(t, err)
+	_, err = broker.Subscribe(ctx, "user.created", "audit", auditB.handle)
+	require.NoError(t, err)
+	_, err = broker.Subscribe(ctx, "user.created", "projector", projector.handle)
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, broker.Publish(ctx, messaging.Message{Topic: "user.created", Payload: []byte("{}")}))
+	}
+	require.NoError(t, broker.Publish(ctx, messaging.Message{Topic: "user.deleted"}))
+	require.NoError(t, broker.Close())
+
+	// Each group sees every message once, split between its members
+	assert.Equal(t, 4, projector.count())
+	assert.Equal(t, 4, auditA.count()+auditB.count())
+	assert.Equal(t, 2, auditA.count())
+
+	projector.mu.Lock()
+	defer projector.mu.Unlock()
+	assert.NotEmpty(t, projector.messages[0].ID)
+	assert.False(t, projector.messages[0].Timestamp.IsZero())
+}
+
+func TestBroker_KeyedMessagesStayOnOneMember(t *testing.T) {
+	ctx := context.Background()
+	broker := New(16, logger.New())
+
+	var first, second collector
+	_, err := broker.Subscribe(ctx, "user.updated", "projector", first.handle)
+	require.NoError(t, err)
+	_, err = broker.Subscribe(ctx, "user.updated", "projector", second.handle)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, broker.Publish(ctx, messaging.Message{Topic: "user.updated", Key: "user_1"}))
+	}
+	require.NoError(t, broker.Close())
+
+	assert.Contains(t, []int{0, 5}, first.count())
+	assert.Equal(t, 5, first.count()+second.count())
+}
+
+func TestBroker_Unsubscribe(t *testing.T) {
+	ctx := context.Background()
+	broker := New(16, logger.New())
+	defer broker.Close()
+
+	var c collector
+	sub, err := broker.Subscribe(ctx, "user.created", "audit", c.handle)
+	require.NoError(t, err)
+
+	require.NoError(t, broker.Publish(ctx, messaging.Message{Topic: "user.created"}))
+	require.NoError(t, sub.Unsubscribe())
+	require.NoError(t, broker.Publish(ctx, messaging.Message{Topic: "user.created"}))
+
+	assert.Eventually(t, func() bool { return c.count() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestBroker_HandlerErrorDoesNotStopDelivery(t *testing.T) {
+	ctx := context.Background()
+	broker := New(16, logger.New())
+
+	var calls int
+	_, err := broker.Subscribe(ctx, "user.created", "webhooks", func(ctx context.Context, msg messaging.Message) error {
+		calls++
+		return errors.New("endpoint unavailable")
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, broker.Publish(ctx, messaging.Message{Topic: "user.created"}))
+	require.NoError(t, broker.Publish(ctx, messaging.Message{Topic: "user.created"}))
+	require.NoError(t, broker.Close())
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestBroker_Closed(t *testing.T) {
+	ctx := context.Background()
+	broker := New(16, logger.New())
+	require.NoError(t, broker.Close())
+
+	err := broker.Publish(ctx, messaging.Message{Topic: "user.created"})
+	assert.ErrorIs(t, err, messaging.ErrClosed)
+
+	_, err = broker.Subscribe(ctx, "user.created", "audit", func(context.Context, messaging.Message) error { return nil })
+	assert.ErrorIs(t, err, messaging.ErrClosed)
+}
@@ -0,0 +1,62 @@
+// Package messaging defines a transport-agnostic publish/subscribe interface
+// so event-driven features can run on an in-process broker in development
+// and on an external broker in production.
+package messaging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrClosed is returned when publishing to or subscribing on a closed broker
+var ErrClosed = errors.New("messaging: broker is closed")
+
+// Message is a unit of data published to a topic
+type Message struct {
+	ID        string
+	Topic     string
+	Key       string // Messages sharing a key are delivered in order to the same group member
+	Payload   []byte
+	Headers   map[string]string
+	Timestamp time.Time
+}
+
+// Handler processes a delivered message
+type Handler func(ctx context.Context, msg Message) error
+
+// Publisher sends messages to a topic
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// Subscriber delivers messages from a topic to a handler
+type Subscriber interface {
+	// Subscribe registers handler as a member of the consumer group on
+	// topic. Every group receives each message once; members of the same
+	// group share the messages between them.
+	Subscribe(ctx context.Context, topic, group string, handler Handler) (Subscription, error)
+}
+
+// Subscription is an active group membership
+type Subscription interface {
+	Unsubscribe() error
+}
+
+// Broker is a publisher and subscriber that owns its connections
+type Broker interface {
+	Publisher
+	Subscriber
+	Close() error
+}
+
+// NewMessageID generates a random message identifier
+func NewMessageID() string {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "msg_" + time.Now().Format("20060102150405.000000")
+	}
+	return "msg_" + hex.EncodeToString(randBytes)
+}
@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"clean-architecture/pkg/httpserver"
+)
+
+// Registry holds the Prometheus collectors exposed by the application
+type Registry struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewRegistry creates a new registry with Go runtime, process and HTTP
+// request collectors registered
+func NewRegistry() *Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	r := &Registry{
+		registry: reg,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests processed.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+	reg.MustRegister(r.requests, r.duration)
+
+	return r
+}
+
+// MustRegister registers additional collectors, panicking on conflicts
+func (r *Registry) MustRegister(cs ...prometheus.Collector) {
+	r.registry.MustRegister(cs...)
+}
+
+// Handler returns an HTTP handler exposing the registered metrics in the
+// Prometheus text format, or in OpenMetrics when the scraper asks for it
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{
+		Registry:          r.registry,
+		EnableOpenMetrics: true,
+	})
+}
+
+// Middleware records request counts and latencies labelled by route pattern
+func (r *Registry) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		ww := httpserver.NewStatusWriter(w)
+
+		next.ServeHTTP(ww, req)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(req.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		r.requests.WithLabelValues(req.Method, route, strconv.Itoa(ww.Status)).Inc()
+		r.duration.WithLabelValues(req.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
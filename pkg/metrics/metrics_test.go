@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Middleware(t *testing.T) {
+	registry := NewRegistry()
+
+	r := chi.NewRouter()
+	r.Use(registry.Middleware)
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest("GET", "/users/user_123", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/users/{id}",status="404"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/users/{id}"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestRegistry_HandlerNegotiatesOpenMetrics(t *testing.T) {
+	registry := NewRegistry()
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, req)
+
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/openmetrics-text")
+	assert.Contains(t, w.Body.String(), "# EOF")
+
+	w = httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+}
@@ -0,0 +1,607 @@
+// Package oidc implements the relying party side of the OpenID Connect
+// authorization code flow with PKCE. Provider metadata is discovered from
+// the issuer and cached, ID tokens are verified against the keys the
+// provider publishes, and pending logins are tracked in signed cookies.
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"clean-architecture/pkg/signedstate"
+)
+
+// Defaults applied when the corresponding Config field is zero
+const (
+	DefaultDiscoveryRefresh = 24 * time.Hour
+	DefaultRequestTTL       = 5 * time.Minute
+)
+
+// DefaultScopes are requested when Config.Scopes is empty
+var DefaultScopes = []string{"openid", "email", "profile"}
+
+// maxDocumentSize bounds discovery documents, key sets and token responses
+const maxDocumentSize = 1 << 20
+
+// cookiePrefix prefixes the cookies tracking pending login requests; the
+// state completes the name
+const cookiePrefix = "oidc_"
+
+// keyRefetchInterval is how often an ID token signed with an unknown key
+// may trigger a fetch of the provider's keys, which it rotates
+const keyRefetchInterval = time.Minute
+
+// clockSkew is tolerated when checking the times of ID tokens
+const clockSkew = time.Minute
+
+// signingMethods are the ID token algorithms accepted; none and the HMAC
+// algorithms keyed with the client secret are not
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+var (
+	// ErrUnknownRequest is returned when a callback does not belong to a
+	// login started by this browser, or the login took too long
+	ErrUnknownRequest = errors.New("oidc: unknown or expired login request")
+	// ErrInvalidResponse is returned when a callback or its ID token fails
+	// verification
+	ErrInvalidResponse = errors.New("oidc: invalid response")
+	// ErrAccessDenied is returned when the user or the provider declined
+	// the login
+	ErrAccessDenied = errors.New("oidc: access denied")
+	// ErrProviderUnavailable is returned when the provider's metadata,
+	// keys or token endpoint cannot be reached
+	ErrProviderUnavailable = errors.New("oidc: provider unavailable")
+)
+
+// Config holds configuration for a client of one OpenID provider
+type Config struct {
+	// ID prefixes the state of every login, so a callback URL shared by
+	// several providers can tell which one a login belongs to; see
+	// ProviderID. It must not contain dots.
+	ID string
+
+	// Issuer is the provider's issuer URL; its metadata is discovered at
+	// /.well-known/openid-configuration below it and refreshed every
+	// DiscoveryRefresh
+	Issuer           string
+	DiscoveryRefresh time.Duration
+	HTTPClient       *http.Client
+
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string // Callback URL registered at the provider
+	// Scopes requested; openid is added when missing
+	Scopes []string
+	// AuthParams are added to authorization requests, such as Google's hd
+	AuthParams url.Values
+
+	StateKey   []byte        // Signs the cookies tracking pending requests
+	RequestTTL time.Duration // How long a login may take at the provider
+}
+
+// Claims are the verified claims of an ID token
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	// ReturnTo is the URL passed to StartLogin
+	ReturnTo string
+	// Raw holds every claim of the token
+	Raw map[string]interface{}
+}
+
+// Get returns the claim name if it is a string, or ""
+func (c *Claims) Get(name string) string {
+	value, _ := c.Raw[name].(string)
+	return value
+}
+
+// Values returns the claim name as a list of strings. A single string is
+// returned as one value; values of other types are skipped.
+func (c *Claims) Values(name string) []string {
+	switch value := c.Raw[name].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// ProviderID returns the ID of the provider that started the login with
+// state, for routing a shared callback. The state is only trusted once the
+// provider's FinishLogin verified it.
+func ProviderID(state string) string {
+	id, _, _ := strings.Cut(state, ".")
+	return id
+}
+
+// metadata is the part of a provider's discovery document the client uses
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider starts logins at an OpenID provider and verifies their
+// callbacks. It is safe for concurrent use.
+type Provider struct {
+	cfg          Config
+	callbackPath string
+	secure       bool
+	state        *signedstate.Codec // Scoped to the issuer and client, so state cannot be completed at another provider
+	now          func() time.Time
+
+	mu           sync.Mutex
+	metadata     *metadata
+	loadedAt     time.Time
+	keys         map[string]crypto.PublicKey
+	keysLoadedAt time.Time
+}
+
+// New creates a client of the provider at cfg.Issuer. Its metadata is
+// fetched on first use.
+func New(cfg Config) (*Provider, error) {
+	if cfg.ID == "" || strings.Contains(cfg.ID, ".") {
+		return nil, fmt.Errorf("oidc: invalid provider ID %q", cfg.ID)
+	}
+	if len(cfg.StateKey) == 0 {
+		return nil, errors.New("oidc: a state key is required")
+	}
+	if cfg.ClientID == "" {
+		return nil, errors.New("oidc: a client ID is required")
+	}
+	if issuer, err := url.Parse(cfg.Issuer); err != nil || !issuer.IsAbs() {
+		return nil, fmt.Errorf("oidc: invalid issuer %q", cfg.Issuer)
+	}
+	redirectURL, err := url.Parse(cfg.RedirectURL)
+	if err != nil || !redirectURL.IsAbs() {
+		return nil, fmt.Errorf("oidc: invalid redirect URL %q", cfg.RedirectURL)
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = DefaultScopes
+	}
+	if !contains(cfg.Scopes, "openid") {
+		cfg.Scopes = append([]string{"openid"}, cfg.Scopes...)
+	}
+	if cfg.DiscoveryRefresh == 0 {
+		cfg.DiscoveryRefresh = DefaultDiscoveryRefresh
+	}
+	if cfg.RequestTTL == 0 {
+		cfg.RequestTTL = DefaultRequestTTL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return &Provider{
+		cfg:          cfg,
+		callbackPath: redirectURL.Path,
+		secure:       redirectURL.Scheme == "https",
+		state:        signedstate.New(cfg.StateKey, cfg.Issuer, cfg.ClientID),
+		now:          time.Now,
+	}, nil
+}
+
+// StartLogin returns the provider's authorization URL to redirect the
+// browser to. A cookie scoped to the redirect URL tracks the request, so
+// only this browser can complete it; returnTo is handed back by
+// FinishLogin.
+func (p *Provider) StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error) {
+	md, err := p.discover(r.Context())
+	if err != nil {
+		return "", err
+	}
+	authURL, err := url.Parse(md.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid authorization endpoint: %v", ErrProviderUnavailable, err)
+	}
+
+	random, err := randomStrings(3)
+	if err != nil {
+		return "", fmt.Errorf("oidc: generating login state: %w", err)
+	}
+	tracked := trackedRequest{
+		State:    p.cfg.ID + "." + random[0],
+		Nonce:    random[1],
+		Verifier: random[2],
+		ReturnTo: returnTo,
+		Expires:  p.now().Add(p.cfg.RequestTTL).Unix(),
+	}
+	challenge := sha256.Sum256([]byte(tracked.Verifier))
+
+	query := authURL.Query()
+	for name, values := range p.cfg.AuthParams {
+		query[name] = values
+	}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", tracked.State)
+	query.Set("nonce", tracked.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	value, err := p.state.Encode(tracked)
+	if err != nil {
+		return "", err
+	}
+	http.SetCookie(w, p.cookie(tracked.State, value, int(p.cfg.RequestTTL.Seconds())))
+	return authURL.String(), nil
+}
+
+// FinishLogin verifies the callback the provider redirected the browser
+// to against the request tracked for its state, redeems the authorization
+// code and returns the claims of the verified ID token. The tracking
+// cookie is cleared, so a callback can only be used once.
+func (p *Provider) FinishLogin(w http.ResponseWriter, r *http.Request) (*Claims, error) {
+	query := r.URL.Query()
+	state := query.Get("state")
+	cookie, err := r.Cookie(cookiePrefix + state)
+	if state == "" || err != nil {
+		return nil, ErrUnknownRequest
+	}
+	http.SetCookie(w, p.cookie(state, "", -1))
+
+	var tracked trackedRequest
+	if !p.state.Decode(cookie.Value, &tracked) || tracked.State != state || p.now().Unix() > tracked.Expires {
+		return nil, ErrUnknownRequest
+	}
+
+	switch code := query.Get("error"); code {
+	case "":
+	case "access_denied":
+		return nil, ErrAccessDenied
+	default:
+		return nil, fmt.Errorf("%w: %s: %s", ErrInvalidResponse, code, query.Get("error_description"))
+	}
+	code := query.Get("code")
+	if code == "" {
+		return nil, fmt.Errorf("%w: no authorization code", ErrInvalidResponse)
+	}
+
+	md, err := p.discover(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := p.exchange(r.Context(), md, code, tracked.Verifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := p.verify(r.Context(), md, idToken, tracked.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	claims.ReturnTo = tracked.ReturnTo
+	return claims, nil
+}
+
+// exchange redeems an authorization code at the token endpoint and returns
+// the ID token
+func (p *Provider) exchange(ctx context.Context, md *metadata, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid token endpoint: %v", ErrProviderUnavailable, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// Client credentials are form-encoded before basic authentication
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return "", fmt.Errorf("%w: token endpoint returned status %d", ErrProviderUnavailable, resp.StatusCode)
+	}
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(&token); err != nil {
+		return "", fmt.Errorf("%w: decoding token response: %v", ErrInvalidResponse, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: redeeming code: %s: %s", ErrInvalidResponse, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", fmt.Errorf("%w: token response has no id_token", ErrInvalidResponse)
+	}
+	return token.IDToken, nil
+}
+
+// verify checks the signature, issuer, audience, times and nonce of an ID
+// token and returns its claims
+func (p *Provider) verify(ctx context.Context, md *metadata, idToken, nonce string) (*Claims, error) {
+	raw := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, raw, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, md, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(md.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(p.now),
+	)
+	if errors.Is(err, ErrProviderUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+
+	if got, _ := raw["nonce"].(string); got == "" || !hmac.Equal([]byte(got), []byte(nonce)) {
+		return nil, fmt.Errorf("%w: nonce does not match the login request", ErrInvalidResponse)
+	}
+	// Tokens issued to several clients name the one they were issued for
+	if audience, _ := raw.GetAudience(); len(audience) > 1 {
+		if azp, _ := raw["azp"].(string); azp != p.cfg.ClientID {
+			return nil, fmt.Errorf("%w: token was issued for %q", ErrInvalidResponse, azp)
+		}
+	}
+	subject, _ := raw.GetSubject()
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidResponse)
+	}
+
+	claims := &Claims{Subject: subject, Raw: raw}
+	claims.Email = claims.Get("email")
+	claims.Name = claims.Get("name")
+	// Some providers send the flag as a string
+	switch verified := raw["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = verified
+	case string:
+		claims.EmailVerified = verified == "true"
+	}
+	return claims, nil
+}
+
+// discover returns the provider's metadata, fetching it when missing or
+// stale. When a refresh fails, the previous metadata is kept.
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata == nil || p.now().Sub(p.loadedAt) > p.cfg.DiscoveryRefresh {
+		md, err := p.fetchMetadata(ctx)
+		if err != nil && p.metadata == nil {
+			return nil, fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+		}
+		if err == nil {
+			p.metadata = md
+		}
+		p.loadedAt = p.now()
+	}
+	return p.metadata, nil
+}
+
+// fetchMetadata downloads and checks the provider's discovery document
+func (p *Provider) fetchMetadata(ctx context.Context) (*metadata, error) {
+	var md metadata
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", &md); err != nil {
+		return nil, err
+	}
+	// The issuer is compared exactly so one provider cannot claim to be
+	// another
+	if md.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("discovery document is for issuer %q", md.Issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, errors.New("discovery document lacks an authorization, token or keys endpoint")
+	}
+	return &md, nil
+}
+
+// key returns the provider's public key kid. Keys are fetched on first use
+// and again, at most every keyRefetchInterval, when a token names an
+// unknown key. Tokens without a key ID are accepted from providers
+// publishing a single key.
+func (p *Provider) key(ctx context.Context, md *metadata, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.lookupKey(kid)
+	if !ok && (p.keys == nil || p.now().Sub(p.keysLoadedAt) > keyRefetchInterval) {
+		keys, err := p.fetchKeys(ctx, md.JWKSURI)
+		if err != nil && p.keys == nil {
+			return nil, fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+		}
+		if err == nil {
+			p.keys = keys
+		}
+		p.keysLoadedAt = p.now()
+		key, ok = p.lookupKey(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok && kid != ""
+}
+
+// jsonWebKey is a public key of a JSON Web Key Set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchKeys downloads the provider's signing keys, keyed by key ID. Keys of
+// unsupported types are skipped.
+func (p *Provider) fetchKeys(ctx context.Context, jwksURI string) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("key set has no usable signing keys")
+	}
+	return keys, nil
+}
+
+// publicKey decodes an RSA or elliptic curve key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var checker ecdh.Curve
+		switch k.Crv {
+		case "P-256":
+			curve, checker = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, checker = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, checker = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		size := (curve.Params().BitSize + 7) / 8
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return nil, errors.New("invalid EC point")
+		}
+		// Decoding the uncompressed point checks that it is on the curve
+		if _, err := checker.NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// getJSON fetches and decodes a JSON document
+func (p *Provider) getJSON(ctx context.Context, uri string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: unexpected status %d", uri, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxDocumentSize)).Decode(v)
+}
+
+// trackedRequest is the state of a pending login kept in its cookie
+type trackedRequest struct {
+	State    string `json:"st"`
+	Nonce    string `json:"n"`
+	Verifier string `json:"v"`
+	ReturnTo string `json:"rt,omitempty"`
+	Expires  int64  `json:"exp"`
+}
+
+// cookie returns the cookie tracking the request with state. Providers
+// redirect back with a top-level GET, which SameSite=Lax cookies are sent
+// with.
+func (p *Provider) cookie(state, value string, maxAge int) *http.Cookie {
+	return signedstate.Cookie(cookiePrefix+state, value, p.callbackPath, maxAge, p.secure, http.SameSiteLaxMode)
+}
+
+// randomStrings returns n hex encoded random strings of 32 bytes each
+func randomStrings(n int) ([]string, error) {
+	values := make([]string, n)
+	for i := range values {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		values[i] = hex.EncodeToString(b)
+	}
+	return values, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
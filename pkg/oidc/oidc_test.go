@@ -0,0 +1,349 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testClientID    = "client_1"
+	testRedirectURL = "https://sp.example.com/api/v1/auth/oidc/callback"
+)
+
+// testOP is an OpenID provider that issues ID tokens with claims for every
+// authorization code
+type testOP struct {
+	server     *httptest.Server
+	key        *rsa.PrivateKey
+	kid        string
+	claims     jwt.MapClaims
+	tokenError string
+	keyFetches atomic.Int32
+
+	// nonce and challenge are those of the last authorization request
+	nonce     string
+	challenge string
+}
+
+func newTestOP(t *testing.T) *testOP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	op := &testOP{key: key, kid: "key_1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 op.server.URL,
+			"authorization_endpoint": op.server.URL + "/authorize",
+			"token_endpoint":         op.server.URL + "/token",
+			"jwks_uri":               op.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		op.keyFetches.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": op.kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(op.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(op.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != testClientID || secret != "secret" || r.PostFormValue("redirect_uri") != testRedirectURL {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		if op.tokenError != "" || r.PostFormValue("code") != "code_1" || !verifierMatches(r.PostFormValue("code_verifier"), op.challenge) {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "at_1", "token_type": "Bearer", "id_token": op.sign(t)})
+	})
+	op.server = httptest.NewTLSServer(mux)
+	t.Cleanup(op.server.Close)
+
+	op.claims = jwt.MapClaims{
+		"iss":            op.server.URL,
+		"aud":            testClientID,
+		"sub":            "10769150350006150715113082367",
+		"email":          "bjensen@example.com",
+		"email_verified": true,
+		"name":           "Barbara Jensen",
+		"groups":         []string{"admins", "everyone"},
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+	return op
+}
+
+func verifierMatches(verifier, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:]) == challenge
+}
+
+// sign returns an ID token with the provider's claims and the nonce of the
+// last authorization request
+func (op *testOP) sign(t *testing.T) string {
+	claims := jwt.MapClaims{"nonce": op.nonce}
+	for name, value := range op.claims {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = op.kid
+	signed, err := token.SignedString(op.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newTestProvider(t *testing.T, op *testOP) *Provider {
+	t.Helper()
+	p, err := New(Config{
+		ID:           "acme",
+		Issuer:       op.server.URL,
+		HTTPClient:   op.server.Client(),
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  testRedirectURL,
+		AuthParams:   url.Values{"hd": {"example.com"}},
+		StateKey:     []byte("state-key"),
+	})
+	require.NoError(t, err)
+	return p
+}
+
+// startLogin starts a login and returns the authorization request the
+// browser is sent to and the tracking cookie
+func startLogin(t *testing.T, p *Provider, op *testOP, returnTo string) (url.Values, *http.Cookie) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	location, err := p.StartLogin(w, httptest.NewRequest("GET", "/api/v1/auth/oidc/login", nil), returnTo)
+	require.NoError(t, err)
+
+	u, err := url.Parse(location)
+	require.NoError(t, err)
+	assert.Equal(t, op.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	query := u.Query()
+	op.nonce = query.Get("nonce")
+	op.challenge = query.Get("code_challenge")
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	return query, cookies[0]
+}
+
+// callback returns the request the provider redirects the browser back with
+func callback(query url.Values, cookie *http.Cookie) *http.Request {
+	r := httptest.NewRequest("GET", testRedirectURL+"?"+query.Encode(), nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	return r
+}
+
+func TestProvider_Login(t *testing.T) {
+	op := newTestOP(t)
+	p := newTestProvider(t, op)
+
+	query, cookie := startLogin(t, p, op, "https://app.example.com/sso")
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, testClientID, query.Get("client_id"))
+	assert.Equal(t, testRedirectURL, query.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "example.com", query.Get("hd"))
+	assert.Equal(t, "acme", ProviderID(query.Get("state")))
+	assert.Equal(t, "/api/v1/auth/oidc/callback", cookie.Path)
+	assert.True(t, cookie.Secure)
+	assert.True(t, cookie.HttpOnly)
+
+	w := httptest.NewRecorder()
+	claims, err := p.FinishLogin(w, callback(url.Values{"state": {query.Get("state")}, "code": {"code_1"}}, cookie))
+	require.NoError(t, err)
+	assert.Equal(t, "10769150350006150715113082367", claims.Subject)
+	assert.Equal(t, "bjensen@example.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, "Barbara Jensen", claims.Name)
+	assert.Equal(t, []string{"admins", "everyone"}, claims.Values("groups"))
+	assert.Equal(t, "https://app.example.com/sso", claims.ReturnTo)
+
+	// The tracking cookie is cleared so the callback cannot be replayed
+	cleared := w.Result().Cookies()
+	require.Len(t, cleared, 1)
+	assert.Equal(t, cookie.Name, cleared[0].Name)
+	assert.Negative(t, cleared[0].MaxAge)
+}
+
+func TestProvider_FinishLogin_UnknownRequest(t *testing.T) {
+	op := newTestOP(t)
+	p := newTestProvider(t, op)
+	query, cookie := startLogin(t, p, op, "")
+	state := query.Get("state")
+
+	t.Run("without cookie", func(t *testing.T) {
+		_, err := p.FinishLogin(httptest.NewRecorder(), callback(url.Values{"state": {state}, "code": {"code_1"}}, nil))
+		assert.ErrorIs(t, err, ErrUnknownRequest)
+	})
+
+	t.Run("tampered cookie", func(t *testing.T) {
+		tampered := *cookie
+		tampered.Value = tampered.Value[:len(tampered.Value)-2] + "xx"
+		_, err := p.FinishLogin(httptest.NewRecorder(), callback(url.Values{"state": {state}, "code": {"code_1"}}, &tampered))
+		assert.ErrorIs(t, err, ErrUnknownRequest)
+	})
+
+	t.Run("another provider's cookie", func(t *testing.T) {
+		other, err := New(Config{ID: "acme", Issuer: op.server.URL, ClientID: "client_2", RedirectURL: testRedirectURL, StateKey: []byte("state-key")})
+		require.NoError(t, err)
+		_, err = other.FinishLogin(httptest.NewRecorder(), callback(url.Values{"state": {state}, "code": {"code_1"}}, cookie))
+		assert.ErrorIs(t, err, ErrUnknownRequest)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		p.now = func() time.Time { return time.Now().Add(DefaultRequestTTL + time.Minute) }
+		defer func() { p.now = time.Now }()
+		_, err := p.FinishLogin(httptest.NewRecorder(), callback(url.Values{"state": {state}, "code": {"code_1"}}, cookie))
+		assert.ErrorIs(t, err, ErrUnknownRequest)
+	})
+}
+
+func TestProvider_FinishLogin_Denied(t *testing.T) {
+	op := newTestOP(t)
+	p := newTestProvider(t, op)
+	query, cookie := startLogin(t, p, op, "")
+
+	_, err := p.FinishLogin(httptest.NewRecorder(), callback(url.Values{"state": {query.Get("state")}, "error": {"access_denied"}}, cookie))
+	assert.ErrorIs(t, err, ErrAccessDenied)
+}
+
+func TestProvider_FinishLogin_InvalidResponse(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(op *testOP)
+		code  string
+	}{
+		{"rejected code", func(op *testOP) { op.tokenError = "invalid_grant" }, "code_1"},
+		{"wrong code", func(op *testOP) {}, "code_2"},
+		{"wrong audience", func(op *testOP) { op.claims["aud"] = "client_2" }, "code_1"},
+		{"wrong issuer", func(op *testOP) { op.claims["iss"] = "https://evil.example.com" }, "code_1"},
+		{"expired token", func(op *testOP) { op.claims["exp"] = time.Now().Add(-time.Hour).Unix() }, "code_1"},
+		{"wrong nonce", func(op *testOP) { op.claims["nonce"] = "other" }, "code_1"},
+		{"issued for another client", func(op *testOP) {
+			op.claims["aud"] = []string{testClientID, "client_2"}
+			op.claims["azp"] = "client_2"
+		}, "code_1"},
+		{"without subject", func(op *testOP) { delete(op.claims, "sub") }, "code_1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := newTestOP(t)
+			p := newTestProvider(t, op)
+			query, cookie := startLogin(t, p, op, "")
+			tt.setup(op)
+
+			_, err := p.FinishLogin(httptest.NewRecorder(), callback(url.Values{"state": {query.Get("state")}, "code": {tt.code}}, cookie))
+			assert.ErrorIs(t, err, ErrInvalidResponse)
+		})
+	}
+}
+
+func TestProvider_KeyRotation(t *testing.T) {
+	op := newTestOP(t)
+	p := newTestProvider(t, op)
+
+	query, cookie := startLogin(t, p, op, "")
+	_, err := p.FinishLogin(httptest.NewRecorder(), callback(url.Values{"state": {query.Get("state")}, "code": {"code_1"}}, cookie))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), op.keyFetches.Load())
+
+	rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	op.key, op.kid = rotated, "key_2"
+
+	// Unknown keys are fetched at most every keyRefetchInterval
+	query, cookie = startLogin(t, p, op, "")
+	_, err = p.FinishLogin(httptest.NewRecorder(), callback(url.Values{"state": {query.Get("state")}, "code": {"code_1"}}, cookie))
+	assert.ErrorIs(t, err, ErrInvalidResponse)
+	assert.Equal(t, int32(1), op.keyFetches.Load())
+
+	p.keysLoadedAt = time.Now().Add(-2 * keyRefetchInterval)
+	query, cookie = startLogin(t, p, op, "")
+	_, err = p.FinishLogin(httptest.NewRecorder(), callback(url.Values{"state": {query.Get("state")}, "code": {"code_1"}}, cookie))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), op.keyFetches.Load())
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	op := newTestOP(t)
+	p, err := New(Config{
+		ID:          "acme",
+		Issuer:      op.server.URL + "/tenant",
+		HTTPClient:  op.server.Client(),
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+		StateKey:    []byte("state-key"),
+	})
+	require.NoError(t, err)
+
+	_, err = p.StartLogin(httptest.NewRecorder(), httptest.NewRequest("GET", "/login", nil), "")
+	assert.ErrorIs(t, err, ErrProviderUnavailable)
+}
+
+func TestJSONWebKey_EC(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	jwk := jsonWebKey{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+	public, err := jwk.publicKey()
+	require.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(public))
+
+	jwk.Y = jwk.X
+	_, err = jwk.publicKey()
+	assert.Error(t, err, "a point off the curve is rejected")
+}
+
+func TestNew_RequiresConfig(t *testing.T) {
+	valid := Config{ID: "acme", Issuer: "https://accounts.example.com", ClientID: testClientID, RedirectURL: testRedirectURL, StateKey: []byte("key")}
+	_, err := New(valid)
+	require.NoError(t, err)
+
+	for name, mutate := range map[string]func(*Config){
+		"dotted ID":         func(c *Config) { c.ID = "acme.corp" },
+		"relative issuer":   func(c *Config) { c.Issuer = "accounts.example.com" },
+		"no client ID":      func(c *Config) { c.ClientID = "" },
+		"relative redirect": func(c *Config) { c.RedirectURL = "/callback" },
+		"no state key":      func(c *Config) { c.StateKey = nil },
+	} {
+		cfg := valid
+		mutate(&cfg)
+		_, err := New(cfg)
+		assert.Error(t, err, name)
+	}
+}
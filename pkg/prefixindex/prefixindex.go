@@ -0,0 +1,114 @@
+// Package prefixindex finds entries by the prefix of their terms, for
+// search-as-you-type without a round trip to the database.
+//
+// An Index is built once from every entry and never changes, so any number
+// of goroutines search it without locking; callers rebuild it and swap the
+// new one in to pick up changes. Terms are matched case-insensitively from
+// their start or the start of any of their words, so "lov" and "ada lo"
+// both match "Ada Lovelace", and accents are ignored.
+package prefixindex
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Entry is what is searched for, identified by ID and found by Terms
+type Entry struct {
+	ID    string
+	Terms []string
+}
+
+// Index is a sorted list of every word-start suffix of every term
+type Index struct {
+	keys []key
+	size int
+}
+
+// key is a suffix of a normalized term starting at a word, and the entry
+// it belongs to
+type key struct {
+	text string
+	id   string
+}
+
+// New builds the index of entries
+func New(entries []Entry) *Index {
+	index := &Index{size: len(entries)}
+	for _, entry := range entries {
+		for _, term := range entry.Terms {
+			text := Normalize(term)
+			for start := 0; start < len(text); {
+				index.keys = append(index.keys, key{text: text[start:], id: entry.ID})
+				next := strings.IndexByte(text[start:], ' ')
+				if next < 0 {
+					break
+				}
+				start += next + 1
+			}
+		}
+	}
+	sort.Slice(index.keys, func(i, j int) bool {
+		if index.keys[i].text != index.keys[j].text {
+			return index.keys[i].text < index.keys[j].text
+		}
+		return index.keys[i].id < index.keys[j].id
+	})
+	return index
+}
+
+// Len returns the number of entries indexed
+func (i *Index) Len() int {
+	return i.size
+}
+
+// Search returns the IDs of up to limit entries with a term or word
+// starting with prefix, in the alphabetical order of the first term or
+// word they match with. An empty prefix matches nothing.
+func (i *Index) Search(prefix string, limit int) []string {
+	prefix = Normalize(prefix)
+	if prefix == "" || limit <= 0 {
+		return nil
+	}
+
+	start := sort.Search(len(i.keys), func(n int) bool { return i.keys[n].text >= prefix })
+	var ids []string
+	seen := make(map[string]bool)
+	for _, k := range i.keys[start:] {
+		if !strings.HasPrefix(k.text, prefix) || len(ids) == limit {
+			break
+		}
+		if !seen[k.id] {
+			seen[k.id] = true
+			ids = append(ids, k.id)
+		}
+	}
+	return ids
+}
+
+// Normalize lowercases s, strips its accents and collapses each run of
+// spaces, punctuation and symbols into a single space, as terms and
+// prefixes are compared
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space := false
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+			continue
+		}
+		space = true
+	}
+	return b.String()
+}
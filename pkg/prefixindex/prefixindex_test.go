@@ -0,0 +1,57 @@
+package prefixindex
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex_Search(t *testing.T) {
+	index := New([]Entry{
+		{ID: "ada", Terms: []string{"Ada Lovelace", "ada.king"}},
+		{ID: "adam", Terms: []string{"Adam Smith"}},
+		{ID: "grace", Terms: []string{"Grace  Brewster Hopper"}},
+		{ID: "linus", Terms: []string{"Linus O'Lovell"}},
+	})
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"ada", []string{"ada", "adam"}},
+		{"ADA L", []string{"ada"}},
+		{"lov", []string{"ada", "linus"}},
+		{"king", []string{"ada"}},
+		{"brewster ho", []string{"grace"}},
+		{"o lovell", []string{"linus"}},
+		{"hopper grace", nil},
+		{"z", nil},
+		{"", nil},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			assert.Equal(t, tt.want, index.Search(tt.prefix, 10))
+		})
+	}
+	assert.Equal(t, 4, index.Len())
+}
+
+func TestIndex_Search_Limit(t *testing.T) {
+	entries := make([]Entry, 0, 20)
+	for n := 0; n < 20; n++ {
+		entries = append(entries, Entry{ID: fmt.Sprintf("user_%02d", n), Terms: []string{fmt.Sprintf("Sam %02d", n), "Sam"}})
+	}
+	index := New(entries)
+
+	ids := index.Search("sa", 5)
+	assert.Equal(t, []string{"user_00", "user_01", "user_02", "user_03", "user_04"}, ids)
+	assert.Empty(t, index.Search("sa", 0))
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "jose maria o brien", Normalize("  José-María  O'Brien "))
+	assert.Equal(t, "jose maria o brien", Normalize("JOSÉ MARÍA O’BRIEN"))
+	assert.Equal(t, "", Normalize("--"))
+}
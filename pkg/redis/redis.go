@@ -0,0 +1,148 @@
+// Package redis connects to Redis with pooling, timeouts and OpenTelemetry
+// instrumentation. It mirrors pkg/postgres: build a Config, call New and
+// Close the client on shutdown.
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultConnectTimeout bounds the initial ping when no dial timeout is set
+const defaultConnectTimeout = 5 * time.Second
+
+// Config holds configuration for the Redis connection and pool.
+type Config struct {
+	URL      string // redis:// or rediss:// URL; overrides Addr, Username, Password, DB and TLS
+	Addr     string // host:port
+	Username string
+	Password string
+	DB       int
+	TLS      bool // Connect over TLS
+
+	PoolSize        int           // Maximum number of connections; zero uses go-redis' default of 10 per CPU
+	MinIdleConns    int           // Idle connections kept open
+	DialTimeout     time.Duration // Timeout for establishing new connections
+	ReadTimeout     time.Duration // Timeout for socket reads
+	WriteTimeout    time.Duration // Timeout for socket writes
+	PoolTimeout     time.Duration // How long to wait for a free connection when the pool is exhausted
+	ConnMaxIdleTime time.Duration // Maximum amount of time a connection may be idle
+	ConnMaxLifetime time.Duration // Maximum amount of time a connection may be reused
+
+	// TracerProvider and MeterProvider receive the client's spans and
+	// metrics; nil uses the global OpenTelemetry providers
+	TracerProvider trace.TracerProvider
+	MeterProvider  metric.MeterProvider
+}
+
+// BuildOptions converts config into go-redis client options.
+func BuildOptions(config Config) (*goredis.Options, error) {
+	opts := &goredis.Options{}
+	if config.URL != "" {
+		parsed, err := goredis.ParseURL(config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid URL: %w", err)
+		}
+		opts = parsed
+	} else {
+		if config.Addr == "" {
+			return nil, fmt.Errorf("redis: an address or URL is required")
+		}
+		opts.Addr = config.Addr
+		opts.Username = config.Username
+		opts.Password = config.Password
+		opts.DB = config.DB
+		if config.TLS {
+			opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+	}
+
+	if config.PoolSize > 0 {
+		opts.PoolSize = config.PoolSize
+	}
+	if config.MinIdleConns > 0 {
+		opts.MinIdleConns = config.MinIdleConns
+	}
+	if config.DialTimeout > 0 {
+		opts.DialTimeout = config.DialTimeout
+	}
+	if config.ReadTimeout > 0 {
+		opts.ReadTimeout = config.ReadTimeout
+	}
+	if config.WriteTimeout > 0 {
+		opts.WriteTimeout = config.WriteTimeout
+	}
+	if config.PoolTimeout > 0 {
+		opts.PoolTimeout = config.PoolTimeout
+	}
+	if config.ConnMaxIdleTime > 0 {
+		opts.ConnMaxIdleTime = config.ConnMaxIdleTime
+	}
+	if config.ConnMaxLifetime > 0 {
+		opts.ConnMaxLifetime = config.ConnMaxLifetime
+	}
+	return opts, nil
+}
+
+// New creates an instrumented Redis client and checks that the server is
+// reachable. The client is closed again when the check fails.
+func New(ctx context.Context, config Config) (*goredis.Client, error) {
+	opts, err := BuildOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	client := goredis.NewClient(opts)
+
+	var tracing []redisotel.TracingOption
+	if config.TracerProvider != nil {
+		tracing = append(tracing, redisotel.WithTracerProvider(config.TracerProvider))
+	}
+	var metrics []redisotel.MetricsOption
+	if config.MeterProvider != nil {
+		metrics = append(metrics, redisotel.WithMeterProvider(config.MeterProvider))
+	}
+	if err := redisotel.InstrumentTracing(client, tracing...); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("redis: failed to instrument tracing: %w", err)
+	}
+	if err := redisotel.InstrumentMetrics(client, metrics...); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("redis: failed to instrument metrics: %w", err)
+	}
+
+	timeout := opts.DialTimeout
+	if timeout <= 0 {
+		timeout = defaultConnectTimeout
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := Ping(pingCtx, client); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Ping checks that the server answers. It suits health checks.
+func Ping(ctx context.Context, client *goredis.Client) error {
+	if err := client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis: ping failed: %w", err)
+	}
+	return nil
+}
+
+// Close closes the client and its connection pool.
+func Close(client *goredis.Client) error {
+	if client == nil {
+		return fmt.Errorf("cannot close nil redis client")
+	}
+	return client.Close()
+}
@@ -0,0 +1,146 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Redacted replaces the values of redacted headers, parameters and fields
+const Redacted = "[REDACTED]"
+
+// sensitiveWords are redacted wherever they appear in a header, parameter
+// or field name, once lowercased and stripped of dashes and underscores
+var sensitiveWords = []string{
+	"authorization", "apikey", "cookie", "credential", "passwd", "password",
+	"privatekey", "secret", "session", "signature", "token",
+}
+
+// sensitiveNames are redacted only when they are the whole name, being too
+// short to look for inside others
+var sensitiveNames = []string{"code", "otp", "pin", "ssn"}
+
+// Redactor removes credentials and other sensitive values from requests
+type Redactor struct {
+	words []string
+	names map[string]bool
+}
+
+// NewRedactor creates a redactor for the built-in sensitive names and
+// extra, which are redacted wherever they appear in a name like the
+// built-in words
+func NewRedactor(extra []string) *Redactor {
+	r := &Redactor{words: append([]string(nil), sensitiveWords...), names: make(map[string]bool)}
+	for _, name := range sensitiveNames {
+		r.names[name] = true
+	}
+	for _, word := range extra {
+		if word = normalize(word); word != "" {
+			r.words = append(r.words, word)
+		}
+	}
+	return r
+}
+
+// Sensitive reports whether values named name are redacted
+func (r *Redactor) Sensitive(name string) bool {
+	name = normalize(name)
+	if r.names[name] {
+		return true
+	}
+	for _, word := range r.words {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(name string) string {
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// Header returns a copy of h with the values of sensitive headers redacted
+func (r *Redactor) Header(h http.Header) http.Header {
+	out := make(http.Header, len(h))
+	for key, values := range h {
+		if r.Sensitive(key) {
+			out[key] = []string{Redacted}
+			continue
+		}
+		out[key] = append([]string(nil), values...)
+	}
+	return out
+}
+
+// Query returns the raw query with the values of sensitive parameters
+// redacted. Parameters are sorted by name.
+func (r *Redactor) Query(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	// Parameters that cannot be parsed are dropped
+	values, _ := url.ParseQuery(raw)
+	for key := range values {
+		if r.Sensitive(key) {
+			values[key] = []string{Redacted}
+		}
+	}
+	return values.Encode()
+}
+
+// Body returns body, of the given content type, with the sensitive fields
+// of JSON and form bodies redacted. Bodies that cannot be redacted are not
+// returned; omitted says why.
+func (r *Redactor) Body(contentType string, body []byte) (redacted string, omitted string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return "", "malformed or truncated JSON cannot be redacted"
+		}
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(r.value(value)); err != nil {
+			return "", "malformed or truncated JSON cannot be redacted"
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), ""
+	case mediaType == "application/x-www-form-urlencoded":
+		return r.Query(string(body)), ""
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return "", "multipart bodies are not captured"
+	case !utf8.Valid(body):
+		return "", "binary bodies are not captured"
+	}
+	return string(body), ""
+}
+
+// value redacts the sensitive fields of a decoded JSON value
+func (r *Redactor) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.Sensitive(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = r.value(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.value(item)
+		}
+	}
+	return v
+}
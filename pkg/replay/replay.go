@@ -0,0 +1,189 @@
+// Package replay captures HTTP requests with their credentials and
+// personal data redacted, and sends captured requests again to another
+// server, to reproduce a bug seen in one environment in another.
+package replay
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FromHeader is set on replayed requests to the ID of the capture they
+// replay
+const FromHeader = "X-Replayed-From"
+
+// maxResponseBody bounds the response body a replay returns
+const maxResponseBody = 1 << 20
+
+// Request is a captured request. Its headers, query and body are redacted
+// as they were captured.
+type Request struct {
+	ID            string      `json:"id"`
+	RequestID     string      `json:"request_id,omitempty"`
+	CorrelationID string      `json:"correlation_id,omitempty"`
+	Method        string      `json:"method"`
+	Path          string      `json:"path"`
+	Query         string      `json:"query,omitempty"`
+	Header        http.Header `json:"header"`
+	Body          string      `json:"body,omitempty"`
+	// BodyOmitted says why the body was not captured, if it was not
+	BodyOmitted string `json:"body_omitted,omitempty"`
+	// Truncated is set when the body was cut at the capture limit, so a
+	// replay sends only part of it
+	Truncated bool `json:"truncated,omitempty"`
+	// Status and Duration are those of the response the request got
+	Status     int           `json:"status"`
+	Duration   time.Duration `json:"duration"`
+	CapturedAt time.Time     `json:"captured_at"`
+}
+
+// NewID generates a capture identifier
+func NewID() string {
+	randBytes := make([]byte, 12)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "cap_" + time.Now().Format("20060102150405.000000")
+	}
+	return "cap_" + hex.EncodeToString(randBytes)
+}
+
+// Buffer keeps the latest captured requests in memory. It is safe for
+// concurrent use.
+type Buffer struct {
+	mu       sync.Mutex
+	requests []Request
+	next     int
+	full     bool
+}
+
+// NewBuffer creates a buffer keeping the latest size requests
+func NewBuffer(size int) *Buffer {
+	if size < 1 {
+		size = 1
+	}
+	return &Buffer{requests: make([]Request, size)}
+}
+
+// Add stores req, dropping the oldest request once the buffer is full
+func (b *Buffer) Add(req Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.requests[b.next] = req
+	b.next = (b.next + 1) % len(b.requests)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// List returns the requests kept, newest first
+func (b *Buffer) List() []Request {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := b.next
+	if b.full {
+		n = len(b.requests)
+	}
+	list := make([]Request, 0, n)
+	for i := 1; i <= n; i++ {
+		list = append(list, b.requests[(b.next-i+len(b.requests))%len(b.requests)])
+	}
+	return list
+}
+
+// Get returns the request captured under id, if it is still kept
+func (b *Buffer) Get(id string) (Request, bool) {
+	for _, req := range b.List() {
+		if req.ID == id {
+			return req, true
+		}
+	}
+	return Request{}, false
+}
+
+// Result is the response to a replayed request
+type Result struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body,omitempty"`
+	// Truncated is set when the body was cut at 1 MiB
+	Truncated bool          `json:"truncated,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// skipHeaders are not replayed: they describe the original connection or
+// are recomputed for the new one
+var skipHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Host":              true,
+	"Keep-Alive":        true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+	"X-Forwarded-For":   true,
+	"X-Real-Ip":         true,
+}
+
+// Send sends req to the server at target, a base URL its path is appended
+// to. Redacted headers are left out; header sets others, such as the
+// credentials of the target environment, replacing captured ones.
+func Send(ctx context.Context, client *http.Client, req Request, target string, header http.Header) (*Result, error) {
+	base, err := url.Parse(target)
+	if err != nil || base.Host == "" || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("replay: target must be an http:// or https:// URL, got %q", target)
+	}
+	u := *base
+	u.Path = strings.TrimSuffix(base.Path, "/") + req.Path
+	u.RawQuery = req.Query
+
+	outgoing, err := http.NewRequestWithContext(ctx, req.Method, u.String(), bytes.NewReader([]byte(req.Body)))
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	for key, values := range req.Header {
+		key = http.CanonicalHeaderKey(key)
+		if skipHeaders[key] || redacted(values) {
+			continue
+		}
+		outgoing.Header[key] = append([]string(nil), values...)
+	}
+	for key, values := range header {
+		outgoing.Header[http.CanonicalHeaderKey(key)] = values
+	}
+	outgoing.Header.Set(FromHeader, req.ID)
+
+	start := time.Now()
+	resp, err := client.Do(outgoing)
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("replay: read response: %w", err)
+	}
+	result := &Result{Status: resp.StatusCode, Header: resp.Header, Duration: time.Since(start)}
+	if len(body) > maxResponseBody {
+		body, result.Truncated = body[:maxResponseBody], true
+	}
+	result.Body = string(body)
+	return result, nil
+}
+
+func redacted(values []string) bool {
+	for _, value := range values {
+		if value == Redacted {
+			return true
+		}
+	}
+	return false
+}
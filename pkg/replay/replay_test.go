@@ -0,0 +1,125 @@
+package replay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuffer(t *testing.T) {
+	buffer := NewBuffer(3)
+	assert.Empty(t, buffer.List())
+
+	for i := 1; i <= 4; i++ {
+		buffer.Add(Request{ID: strconv.Itoa(i)})
+	}
+	var ids []string
+	for _, req := range buffer.List() {
+		ids = append(ids, req.ID)
+	}
+	assert.Equal(t, []string{"4", "3", "2"}, ids, "the oldest request is dropped once the buffer is full")
+
+	req, ok := buffer.Get("3")
+	assert.True(t, ok)
+	assert.Equal(t, "3", req.ID)
+	_, ok = buffer.Get("1")
+	assert.False(t, ok)
+}
+
+func TestRedactor(t *testing.T) {
+	redactor := NewRedactor([]string{"email"})
+
+	header := redactor.Header(http.Header{
+		"Authorization": {"Bearer abc"},
+		"X-Api-Key":     {"key_123"},
+		"X-Auth-Token":  {"t"},
+		"Accept":        {"application/json"},
+	})
+	assert.Equal(t, http.Header{
+		"Authorization": {Redacted},
+		"X-Api-Key":     {Redacted},
+		"X-Auth-Token":  {Redacted},
+		"Accept":        {"application/json"},
+	}, header)
+
+	assert.Equal(t, "access_token=%5BREDACTED%5D&code=%5BREDACTED%5D&encode=utf8&limit=10", redactor.Query("limit=10&access_token=abc&code=123&encode=utf8"), "short names are only redacted whole")
+
+	body, omitted := redactor.Body("application/json; charset=utf-8", []byte(`{"name":"Jane <j>","Password":"hunter2","profile":{"user_email":"jane@example.com"},"keys":[{"api_key":"k"}],"age":1.50}`))
+	assert.Empty(t, omitted)
+	assert.JSONEq(t, `{"name":"Jane <j>","Password":"[REDACTED]","profile":{"user_email":"[REDACTED]"},"keys":[{"api_key":"[REDACTED]"}],"age":1.50}`, body)
+	assert.Contains(t, body, `"Jane <j>"`, "HTML is not escaped")
+	assert.Contains(t, body, `1.50`, "numbers keep their text")
+
+	body, _ = redactor.Body("application/x-www-form-urlencoded", []byte("username=jane&password=hunter2"))
+	assert.Equal(t, "password=%5BREDACTED%5D&username=jane", body)
+
+	for contentType, payload := range map[string]string{
+		"application/json":    `{"password":`,
+		"multipart/form-data": "--boundary",
+		"image/png":           "\x89PNG\xff",
+	} {
+		body, omitted := redactor.Body(contentType, []byte(payload))
+		assert.Empty(t, body, contentType)
+		assert.NotEmpty(t, omitted, contentType)
+	}
+
+	body, omitted = redactor.Body("text/csv", []byte("name\nJane\n"))
+	assert.Equal(t, "name\nJane\n", body)
+	assert.Empty(t, omitted)
+}
+
+func TestSend(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.Header().Set("X-Result", "ok")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"success"}`)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	captured := Request{
+		ID:     "cap_1",
+		Method: http.MethodPost,
+		Path:   "/api/v1/users",
+		Query:  "notify=false",
+		Header: http.Header{
+			"Authorization":   {Redacted},
+			"Content-Type":    {"application/json"},
+			"Content-Length":  {"99"},
+			"X-Forwarded-For": {"10.0.0.1"},
+		},
+		Body: `{"name":"Jane"}`,
+	}
+	result, err := Send(context.Background(), server.Client(), captured, server.URL+"/", http.Header{"Authorization": {"Bearer staging"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, result.Status)
+	assert.Equal(t, "ok", result.Header.Get("X-Result"))
+	assert.Equal(t, `{"status":"success"}`, result.Body)
+	assert.Equal(t, "/api/v1/users", got.URL.Path)
+	assert.Equal(t, "notify=false", got.URL.RawQuery)
+	assert.Equal(t, `{"name":"Jane"}`, gotBody)
+	assert.Equal(t, "Bearer staging", got.Header.Get("Authorization"), "overrides replace redacted headers")
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Empty(t, got.Header.Get("X-Forwarded-For"))
+	assert.Equal(t, "cap_1", got.Header.Get(FromHeader))
+
+	delete(captured.Header, "Authorization")
+	captured.Header.Set("X-Api-Key", Redacted)
+	_, err = Send(context.Background(), server.Client(), captured, server.URL, nil)
+	require.NoError(t, err)
+	assert.Empty(t, got.Header.Get("X-Api-Key"), "redacted values are not sent")
+
+	_, err = Send(context.Background(), server.Client(), captured, "ftp://example.com", nil)
+	assert.ErrorContains(t, err, "target must be an http:// or https:// URL")
+}
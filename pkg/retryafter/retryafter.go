@@ -0,0 +1,116 @@
+// Package retryafter tells clients when a request refused with 429 Too Many
+// Requests or 503 Service Unavailable is worth retrying.
+//
+// Errors carry the delay their cause implies, such as the end of a lockout
+// or the next health check of a dependency that is down, by implementing
+// Hinter or being wrapped with Wrap. The error mapping writes it as the
+// Retry-After header, and Middleware gives every other 429 and 503 answer a
+// fallback delay so clients never retry immediately.
+package retryafter
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Header is the response header carrying the delay
+const Header = "Retry-After"
+
+// Hinter is implemented by errors that know how long until a retry may
+// succeed
+type Hinter interface {
+	RetryAfter() time.Duration
+}
+
+// Error is an error with the delay after which a retry may succeed
+type Error struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap lets errors.Is match the wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter implements Hinter
+func (e *Error) RetryAfter() time.Duration {
+	return e.After
+}
+
+// Wrap returns err with the delay after which a retry may succeed
+func Wrap(err error, after time.Duration) error {
+	return &Error{Err: err, After: after}
+}
+
+// Of returns the delay of the first Hinter in err's chain
+func Of(err error) (time.Duration, bool) {
+	var hinter Hinter
+	if errors.As(err, &hinter) {
+		return hinter.RetryAfter(), true
+	}
+	return 0, false
+}
+
+// Set sets the Retry-After header to d in whole seconds, rounded up and at
+// least 1, as RFC 9110 allows no fractions and 0 invites an immediate retry
+func Set(h http.Header, d time.Duration) {
+	seconds := int64(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	h.Set(Header, strconv.FormatInt(seconds, 10))
+}
+
+// Retryable reports whether clients may retry requests answered with
+// status after a delay
+func Retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// Middleware sets Retry-After to fallback on 429 and 503 responses that do
+// not set it themselves
+func Middleware(fallback time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(&fallbackWriter{ResponseWriter: w, fallback: fallback}, r)
+		})
+	}
+}
+
+// fallbackWriter sets the header just before a retryable response header
+// is sent
+type fallbackWriter struct {
+	http.ResponseWriter
+	fallback    time.Duration
+	wroteHeader bool
+}
+
+func (w *fallbackWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if Retryable(code) && w.Header().Get(Header) == "" {
+			Set(w.Header(), w.fallback)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *fallbackWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *fallbackWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,88 @@
+package retryafter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errUnavailable = errors.New("directory unavailable")
+
+func TestOf(t *testing.T) {
+	err := fmt.Errorf("ldap: %w", Wrap(errUnavailable, 15*time.Second))
+
+	after, ok := Of(err)
+	assert.True(t, ok)
+	assert.Equal(t, 15*time.Second, after)
+	assert.ErrorIs(t, err, errUnavailable)
+	assert.Equal(t, "ldap: directory unavailable", err.Error())
+
+	_, ok = Of(errUnavailable)
+	assert.False(t, ok)
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		after    time.Duration
+		expected string
+	}{
+		{30 * time.Second, "30"},
+		{1500 * time.Millisecond, "2"},
+		{0, "1"},
+		{-time.Minute, "1"},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		Set(h, tt.after)
+		assert.Equal(t, tt.expected, h.Get(Header), tt.after.String())
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		expected string
+	}{
+		{
+			name:     "fallback for 503",
+			handler:  func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) },
+			expected: "30",
+		},
+		{
+			name:     "fallback for 429",
+			handler:  func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTooManyRequests) },
+			expected: "30",
+		},
+		{
+			name: "handler's own delay kept",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				Set(w.Header(), 5*time.Second)
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			expected: "5",
+		},
+		{
+			name:    "other statuses untouched",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+		},
+		{
+			name:    "implicit 200 untouched",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Middleware(30*time.Second)(tt.handler).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			assert.Equal(t, tt.expected, w.Header().Get(Header))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Package s3 is a minimal client of the Amazon S3 API, and of compatible
+// stores such as MinIO, that puts objects into a bucket with requests
+// signed by AWS Signature Version 4.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// service is the name requests are signed for
+	service = "s3"
+	// amzDateFormat is the form of the X-Amz-Date header
+	amzDateFormat = "20060102T150405Z"
+	// maxErrorBody bounds how much of an error response is read into the
+	// returned error
+	maxErrorBody = 1024
+)
+
+// Config locates a bucket and the credentials it is written with
+type Config struct {
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// Endpoint is the base URL of an S3 compatible store, whose buckets
+	// are addressed in the path. When empty, the bucket's virtual-hosted
+	// AWS endpoint in Region is used.
+	Endpoint string
+}
+
+// Client puts objects into the bucket of its Config
+type Client struct {
+	cfg        Config
+	base       *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// New creates a client of the bucket in cfg that sends its requests with
+// httpClient
+func New(cfg Config, httpClient *http.Client) (*Client, error) {
+	if cfg.Bucket == "" || cfg.Region == "" {
+		return nil, errors.New("s3: bucket and region are required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("s3: access key ID and secret access key are required")
+	}
+
+	endpoint := "https://" + cfg.Bucket + ".s3." + cfg.Region + ".amazonaws.com"
+	if cfg.Endpoint != "" {
+		endpoint = strings.TrimSuffix(cfg.Endpoint, "/") + "/" + cfg.Bucket
+	}
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("s3: invalid endpoint: %w", err)
+	}
+	return &Client{cfg: cfg, base: base, httpClient: httpClient, now: time.Now}, nil
+}
+
+// PutObject stores body under key, replacing any object already there
+func (c *Client) PutObject(ctx context.Context, key string, body []byte, contentType string) error {
+	u := *c.base
+	u.Path = u.Path + "/" + strings.TrimPrefix(key, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("s3: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	c.sign(req, body)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("s3: put %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("s3: put %s: %s: %s", key, resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// sign adds the headers authenticating req, whose payload is body, with
+// Signature Version 4
+func (c *Client) sign(req *http.Request, body []byte) {
+	now := c.now().UTC()
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("Authorization", authorization(c.cfg, req, payloadHash, now))
+}
+
+// authorization returns the Authorization header of req signed at now.
+// The host and every X-Amz-, Content- and Range header are signed.
+func authorization(cfg Config, req *http.Request, payloadHash string, now time.Time) string {
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || strings.HasPrefix(lower, "content-") || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	day := now.Format("20060102")
+	scope := day + "/" + cfg.Region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format(amzDateFormat),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(signingKey(cfg.SecretAccessKey, day, cfg.Region, service), stringToSign))
+
+	return "AWS4-HMAC-SHA256 Credential=" + cfg.AccessKeyID + "/" + scope +
+		",SignedHeaders=" + signedHeaders + ",Signature=" + signature
+}
+
+// signingKey derives the key of secret signing requests to service in
+// region on day
+func signingKey(secret, day, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// canonicalQuery returns query sorted by name, escaped as SigV4 requires
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var pairs []string
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, escape(name)+"="+escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escape percent-encodes everything but the unreserved characters
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,404 @@
+// Package saml implements the service provider side of SP-initiated SAML
+// 2.0 single sign-on with the HTTP-Redirect and HTTP-POST bindings. XML
+// signatures and assertion conditions are verified by crewjam/saml; this
+// package adds request tracking in signed cookies and cached identity
+// provider metadata.
+package saml
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	crewjam "github.com/crewjam/saml"
+	xrv "github.com/mattermost/xml-roundtrip-validator"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"clean-architecture/pkg/signedstate"
+)
+
+// Defaults applied when the corresponding Config field is zero
+const (
+	DefaultMetadataRefresh = 24 * time.Hour
+	DefaultRequestTTL      = 5 * time.Minute
+)
+
+// MetadataContentType is the media type of SAML metadata documents
+const MetadataContentType = "application/samlmetadata+xml"
+
+// maxMetadataSize bounds identity provider metadata documents
+const maxMetadataSize = 1 << 20
+
+// cookiePrefix prefixes the cookies tracking pending login requests; the
+// relay state completes the name
+const cookiePrefix = "saml_"
+
+var (
+	// ErrUnknownRequest is returned when a response does not belong to a
+	// login started by this browser, or the login took too long
+	ErrUnknownRequest = errors.New("saml: unknown or expired login request")
+	// ErrInvalidResponse is returned when a response fails verification
+	ErrInvalidResponse = errors.New("saml: invalid response")
+	// ErrMetadataUnavailable is returned when the identity provider
+	// metadata cannot be loaded
+	ErrMetadataUnavailable = errors.New("saml: identity provider metadata unavailable")
+)
+
+// Config holds configuration for a service provider trusting one identity
+// provider.
+type Config struct {
+	EntityID string // Identifies the service provider; by convention the URL of its metadata
+	ACSURL   string // Assertion consumer service URL responses are posted to
+
+	// Certificate and Key sign authentication requests and decrypt
+	// encrypted assertions
+	Certificate *x509.Certificate
+	Key         *rsa.PrivateKey
+
+	// The identity provider is described by IDPMetadata, or fetched from
+	// IDPMetadataURL and refreshed every MetadataRefresh
+	IDPMetadata     []byte
+	IDPMetadataURL  string
+	MetadataRefresh time.Duration
+	HTTPClient      *http.Client
+
+	StateKey   []byte        // Signs the cookies tracking pending requests
+	RequestTTL time.Duration // How long a login may take at the identity provider
+}
+
+// Attribute is a SAML attribute of the authenticated user
+type Attribute struct {
+	Name         string
+	FriendlyName string
+	Values       []string
+}
+
+// Assertion is the verified outcome of a login
+type Assertion struct {
+	NameID       string
+	NameIDFormat string
+	SessionIndex string
+	Attributes   []Attribute
+	// ReturnTo is the URL passed to StartLogin
+	ReturnTo string
+}
+
+// Get returns the first value of the attribute whose name or friendly name
+// is name, or ""
+func (a *Assertion) Get(name string) string {
+	if values := a.Values(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Values returns every value of the attribute whose name or friendly name
+// is name. Names are compared case-insensitively.
+func (a *Assertion) Values(name string) []string {
+	var values []string
+	for _, attribute := range a.Attributes {
+		if strings.EqualFold(attribute.Name, name) || strings.EqualFold(attribute.FriendlyName, name) {
+			values = append(values, attribute.Values...)
+		}
+	}
+	return values
+}
+
+// ServiceProvider starts logins at its identity provider and verifies the
+// responses. It is safe for concurrent use.
+type ServiceProvider struct {
+	cfg      Config
+	sp       crewjam.ServiceProvider
+	acsPath  string
+	secure   bool
+	state    *signedstate.Codec // Scoped to the entity ID, so state cannot be replayed at another tenant's ACS URL
+	mu       sync.Mutex
+	idp      *crewjam.EntityDescriptor
+	loadedAt time.Time
+	now      func() time.Time
+}
+
+// New creates a service provider. Static metadata is parsed immediately;
+// metadata URLs are fetched on first use.
+func New(cfg Config) (*ServiceProvider, error) {
+	if cfg.Certificate == nil || cfg.Key == nil {
+		return nil, errors.New("saml: a certificate and key are required")
+	}
+	if len(cfg.StateKey) == 0 {
+		return nil, errors.New("saml: a state key is required")
+	}
+	if (len(cfg.IDPMetadata) == 0) == (cfg.IDPMetadataURL == "") {
+		return nil, errors.New("saml: exactly one of the identity provider metadata and its URL is required")
+	}
+	acsURL, err := url.Parse(cfg.ACSURL)
+	if err != nil || !acsURL.IsAbs() {
+		return nil, fmt.Errorf("saml: invalid ACS URL %q", cfg.ACSURL)
+	}
+	metadataURL, err := url.Parse(cfg.EntityID)
+	if err != nil || cfg.EntityID == "" {
+		return nil, fmt.Errorf("saml: invalid entity ID %q", cfg.EntityID)
+	}
+	if cfg.MetadataRefresh == 0 {
+		cfg.MetadataRefresh = DefaultMetadataRefresh
+	}
+	if cfg.RequestTTL == 0 {
+		cfg.RequestTTL = DefaultRequestTTL
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	sp := &ServiceProvider{
+		cfg: cfg,
+		sp: crewjam.ServiceProvider{
+			EntityID:          cfg.EntityID,
+			Key:               cfg.Key,
+			Certificate:       cfg.Certificate,
+			HTTPClient:        cfg.HTTPClient,
+			MetadataURL:       *metadataURL,
+			AcsURL:            *acsURL,
+			AuthnNameIDFormat: crewjam.UnspecifiedNameIDFormat,
+			SignatureMethod:   dsig.RSASHA256SignatureMethod,
+		},
+		acsPath: acsURL.Path,
+		secure:  acsURL.Scheme == "https",
+		state:   signedstate.New(cfg.StateKey, cfg.EntityID),
+		now:     time.Now,
+	}
+	if len(cfg.IDPMetadata) > 0 {
+		if sp.idp, err = parseMetadata(cfg.IDPMetadata); err != nil {
+			return nil, fmt.Errorf("saml: parsing identity provider metadata: %w", err)
+		}
+	}
+	return sp, nil
+}
+
+// LoadKeyPair reads the PEM encoded certificate and RSA private key of a
+// service provider
+func LoadKeyPair(certFile, keyFile string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("saml: loading key pair: %w", err)
+	}
+	key, ok := pair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("saml: the private key must be an RSA key")
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("saml: parsing certificate: %w", err)
+	}
+	return cert, key, nil
+}
+
+// Metadata returns the service provider's metadata document for
+// registering it with the identity provider
+func (sp *ServiceProvider) Metadata() ([]byte, error) {
+	data, err := xml.MarshalIndent(sp.sp.Metadata(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("saml: encoding metadata: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// StartLogin creates a signed authentication request and returns the
+// identity provider URL to redirect the browser to. A cookie scoped to the
+// ACS URL tracks the request, so only this browser can complete it;
+// returnTo is handed back by FinishLogin.
+func (sp *ServiceProvider) StartLogin(w http.ResponseWriter, r *http.Request, returnTo string) (string, error) {
+	provider, err := sp.provider(r.Context())
+	if err != nil {
+		return "", err
+	}
+	request, err := provider.MakeAuthenticationRequest(provider.GetSSOBindingLocation(crewjam.HTTPRedirectBinding), crewjam.HTTPRedirectBinding, crewjam.HTTPPostBinding)
+	if err != nil {
+		return "", fmt.Errorf("saml: creating authentication request: %w", err)
+	}
+
+	relay := make([]byte, 16)
+	if _, err := rand.Read(relay); err != nil {
+		return "", fmt.Errorf("saml: generating relay state: %w", err)
+	}
+	relayState := hex.EncodeToString(relay)
+	redirect, err := request.Redirect(relayState, provider)
+	if err != nil {
+		return "", fmt.Errorf("saml: signing authentication request: %w", err)
+	}
+
+	value, err := sp.state.Encode(trackedRequest{
+		RelayState: relayState,
+		RequestID:  request.ID,
+		ReturnTo:   returnTo,
+		Expires:    sp.now().Add(sp.cfg.RequestTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	http.SetCookie(w, sp.cookie(relayState, value, int(sp.cfg.RequestTTL.Seconds())))
+	return redirect.String(), nil
+}
+
+// FinishLogin verifies the response posted to the ACS URL against the
+// request tracked for its relay state and returns the assertion. The
+// tracking cookie is cleared, so a response can only be used once.
+func (sp *ServiceProvider) FinishLogin(w http.ResponseWriter, r *http.Request) (*Assertion, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	relayState := r.PostForm.Get("RelayState")
+	cookie, err := r.Cookie(cookiePrefix + relayState)
+	if relayState == "" || err != nil {
+		return nil, ErrUnknownRequest
+	}
+	http.SetCookie(w, sp.cookie(relayState, "", -1))
+
+	var tracked trackedRequest
+	if !sp.state.Decode(cookie.Value, &tracked) || tracked.RelayState != relayState || sp.now().Unix() > tracked.Expires {
+		return nil, ErrUnknownRequest
+	}
+
+	provider, err := sp.provider(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	assertion, err := provider.ParseResponse(r, []string{tracked.RequestID})
+	if err != nil {
+		var invalid *crewjam.InvalidResponseError
+		if errors.As(err, &invalid) && invalid.PrivateErr != nil {
+			err = invalid.PrivateErr
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+	return newAssertion(assertion, tracked.ReturnTo), nil
+}
+
+// provider returns the underlying service provider with current identity
+// provider metadata. When a refresh fails, the previous metadata is kept.
+func (sp *ServiceProvider) provider(ctx context.Context) (*crewjam.ServiceProvider, error) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if sp.cfg.IDPMetadataURL != "" && (sp.idp == nil || sp.now().Sub(sp.loadedAt) > sp.cfg.MetadataRefresh) {
+		idp, err := sp.fetchMetadata(ctx)
+		if err != nil && sp.idp == nil {
+			return nil, fmt.Errorf("%w: %v", ErrMetadataUnavailable, err)
+		}
+		if err == nil {
+			sp.idp = idp
+		}
+		sp.loadedAt = sp.now()
+	}
+
+	provider := sp.sp
+	provider.IDPMetadata = sp.idp
+	return &provider, nil
+}
+
+// fetchMetadata downloads and parses the identity provider metadata
+func (sp *ServiceProvider) fetchMetadata(ctx context.Context) (*crewjam.EntityDescriptor, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sp.cfg.IDPMetadataURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := sp.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: unexpected status %d", sp.cfg.IDPMetadataURL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataSize))
+	if err != nil {
+		return nil, err
+	}
+	return parseMetadata(data)
+}
+
+// parseMetadata parses identity provider metadata, which may be an
+// EntityDescriptor or an EntitiesDescriptor wrapping it
+func parseMetadata(data []byte) (*crewjam.EntityDescriptor, error) {
+	if err := xrv.Validate(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	var entities crewjam.EntitiesDescriptor
+	if err := xml.Unmarshal(data, &entities); err == nil {
+		for i, entity := range entities.EntityDescriptors {
+			if len(entity.IDPSSODescriptors) > 0 {
+				return &entities.EntityDescriptors[i], nil
+			}
+		}
+		return nil, errors.New("no entity with an IDPSSODescriptor")
+	}
+
+	var entity crewjam.EntityDescriptor
+	if err := xml.Unmarshal(data, &entity); err != nil {
+		return nil, err
+	}
+	if len(entity.IDPSSODescriptors) == 0 {
+		return nil, errors.New("no IDPSSODescriptor")
+	}
+	return &entity, nil
+}
+
+// newAssertion converts a verified assertion
+func newAssertion(a *crewjam.Assertion, returnTo string) *Assertion {
+	assertion := &Assertion{ReturnTo: returnTo}
+	if a.Subject != nil && a.Subject.NameID != nil {
+		assertion.NameID = a.Subject.NameID.Value
+		assertion.NameIDFormat = a.Subject.NameID.Format
+	}
+	for _, statement := range a.AuthnStatements {
+		if statement.SessionIndex != "" {
+			assertion.SessionIndex = statement.SessionIndex
+			break
+		}
+	}
+	for _, statement := range a.AttributeStatements {
+		for _, attribute := range statement.Attributes {
+			values := make([]string, 0, len(attribute.Values))
+			for _, value := range attribute.Values {
+				values = append(values, value.Value)
+			}
+			assertion.Attributes = append(assertion.Attributes, Attribute{
+				Name:         attribute.Name,
+				FriendlyName: attribute.FriendlyName,
+				Values:       values,
+			})
+		}
+	}
+	return assertion
+}
+
+// trackedRequest is the state of a pending login kept in its cookie
+type trackedRequest struct {
+	RelayState string `json:"rs"`
+	RequestID  string `json:"id"`
+	ReturnTo   string `json:"rt,omitempty"`
+	Expires    int64  `json:"exp"`
+}
+
+// cookie returns the cookie tracking the request with relayState. Identity
+// providers post responses cross-site, so HTTPS deployments need
+// SameSite=None for browsers to send it.
+func (sp *ServiceProvider) cookie(relayState, value string, maxAge int) *http.Cookie {
+	sameSite := http.SameSiteLaxMode
+	if sp.secure {
+		sameSite = http.SameSiteNoneMode
+	}
+	return signedstate.Cookie(cookiePrefix+relayState, value, sp.acsPath, maxAge, sp.secure, sameSite)
+}
@@ -0,0 +1,321 @@
+package saml
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/xml"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	crewjam "github.com/crewjam/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testEntityID = "https://sp.example.com/api/v1/auth/saml/acme/metadata"
+	testACSURL   = "https://sp.example.com/api/v1/auth/saml/acme/acs"
+)
+
+func newKeyPair(t *testing.T, name string) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// testIdP is an identity provider that authenticates every request as
+// session
+type testIdP struct {
+	idp     *crewjam.IdentityProvider
+	sp      *crewjam.EntityDescriptor
+	session *crewjam.Session
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	cert, key := newKeyPair(t, "idp.example.com")
+	metadataURL, _ := url.Parse("https://idp.example.com/metadata")
+	ssoURL, _ := url.Parse("https://idp.example.com/sso")
+	idp := &testIdP{session: &crewjam.Session{
+		ID:         "session_1",
+		CreateTime: time.Now(),
+		ExpireTime: time.Now().Add(time.Hour),
+		Index:      "index_1",
+		NameID:     "bjensen@example.com",
+		UserEmail:  "bjensen@example.com",
+		Groups:     []string{"Engineering", "Admins"},
+		CustomAttributes: []crewjam.Attribute{{
+			Name:   "displayName",
+			Values: []crewjam.AttributeValue{{Type: "xs:string", Value: "Barbara Jensen"}},
+		}},
+	}}
+	idp.idp = &crewjam.IdentityProvider{
+		Key:                     key,
+		Certificate:             cert,
+		MetadataURL:             *metadataURL,
+		SSOURL:                  *ssoURL,
+		ServiceProviderProvider: idp,
+	}
+	return idp
+}
+
+func (i *testIdP) GetServiceProvider(r *http.Request, serviceProviderID string) (*crewjam.EntityDescriptor, error) {
+	if serviceProviderID != i.sp.EntityID {
+		return nil, os.ErrNotExist
+	}
+	return i.sp, nil
+}
+
+func (i *testIdP) metadata(t *testing.T) []byte {
+	data, err := xml.Marshal(i.idp.Metadata())
+	require.NoError(t, err)
+	return data
+}
+
+// respond answers the authentication request in redirect with the form the
+// browser would post to the ACS URL
+func (i *testIdP) respond(t *testing.T, redirect string) url.Values {
+	t.Helper()
+	req, err := crewjam.NewIdpAuthnRequest(i.idp, httptest.NewRequest(http.MethodGet, redirect, nil))
+	require.NoError(t, err)
+	require.NoError(t, req.Validate())
+	require.NoError(t, crewjam.DefaultAssertionMaker{}.MakeAssertion(req, i.session))
+	form, err := req.PostBinding()
+	require.NoError(t, err)
+	assert.Equal(t, testACSURL, form.URL)
+	return url.Values{"SAMLResponse": {form.SAMLResponse}, "RelayState": {form.RelayState}}
+}
+
+func newTestServiceProvider(t *testing.T, idp *testIdP) *ServiceProvider {
+	cert, key := newKeyPair(t, "sp.example.com")
+	sp, err := New(Config{
+		EntityID:    testEntityID,
+		ACSURL:      testACSURL,
+		Certificate: cert,
+		Key:         key,
+		IDPMetadata: idp.metadata(t),
+		StateKey:    []byte("0123456789abcdef0123456789abcdef"),
+	})
+	require.NoError(t, err)
+
+	metadata, err := sp.Metadata()
+	require.NoError(t, err)
+	idp.sp = &crewjam.EntityDescriptor{}
+	require.NoError(t, xml.Unmarshal(metadata, idp.sp))
+	return sp
+}
+
+// startLogin starts a login and returns the identity provider redirect and
+// the tracking cookie
+func startLogin(t *testing.T, sp *ServiceProvider, returnTo string) (string, *http.Cookie) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	redirect, err := sp.StartLogin(rec, httptest.NewRequest(http.MethodGet, "/login", nil), returnTo)
+	require.NoError(t, err)
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	return redirect, cookies[0]
+}
+
+func postResponse(form url.Values, cookies ...*http.Cookie) (*httptest.ResponseRecorder, *http.Request) {
+	req := httptest.NewRequest(http.MethodPost, "/acs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	return httptest.NewRecorder(), req
+}
+
+func TestServiceProvider_Login(t *testing.T) {
+	idp := newTestIdP(t)
+	sp := newTestServiceProvider(t, idp)
+
+	redirect, cookie := startLogin(t, sp, "https://app.example.com/sso")
+	assert.True(t, strings.HasPrefix(redirect, "https://idp.example.com/sso?SAMLRequest="))
+	assert.Contains(t, redirect, "&Signature=")
+	assert.Equal(t, "/api/v1/auth/saml/acme/acs", cookie.Path)
+	assert.True(t, cookie.Secure)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, http.SameSiteNoneMode, cookie.SameSite)
+
+	form := idp.respond(t, redirect)
+	rec, req := postResponse(form, cookie)
+	assertion, err := sp.FinishLogin(rec, req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "bjensen@example.com", assertion.NameID)
+	assert.Equal(t, "index_1", assertion.SessionIndex)
+	assert.Equal(t, "https://app.example.com/sso", assertion.ReturnTo)
+	assert.Equal(t, "Barbara Jensen", assertion.Get("DISPLAYNAME"))
+	assert.Equal(t, "bjensen@example.com", assertion.Get("urn:oid:1.3.6.1.4.1.5923.1.1.1.6"))
+	assert.Equal(t, []string{"Engineering", "Admins"}, assertion.Values("eduPersonAffiliation"))
+
+	cleared := rec.Result().Cookies()
+	require.Len(t, cleared, 1)
+	assert.Equal(t, cookie.Name, cleared[0].Name)
+	assert.Negative(t, cleared[0].MaxAge)
+}
+
+func TestServiceProvider_FinishLogin_Rejects(t *testing.T) {
+	idp := newTestIdP(t)
+	sp := newTestServiceProvider(t, idp)
+
+	t.Run("no tracking cookie", func(t *testing.T) {
+		redirect, _ := startLogin(t, sp, "")
+		rec, req := postResponse(idp.respond(t, redirect))
+		_, err := sp.FinishLogin(rec, req)
+		assert.ErrorIs(t, err, ErrUnknownRequest)
+	})
+
+	t.Run("cookie of another login", func(t *testing.T) {
+		redirect, _ := startLogin(t, sp, "")
+		_, other := startLogin(t, sp, "")
+		form := idp.respond(t, redirect)
+		other.Name = cookiePrefix + form.Get("RelayState")
+		rec, req := postResponse(form, other)
+		_, err := sp.FinishLogin(rec, req)
+		assert.ErrorIs(t, err, ErrUnknownRequest)
+	})
+
+	t.Run("forged cookie", func(t *testing.T) {
+		redirect, cookie := startLogin(t, sp, "")
+		cookie.Value = strings.Replace(cookie.Value, ".", "x.", 1)
+		rec, req := postResponse(idp.respond(t, redirect), cookie)
+		_, err := sp.FinishLogin(rec, req)
+		assert.ErrorIs(t, err, ErrUnknownRequest)
+	})
+
+	t.Run("expired request", func(t *testing.T) {
+		redirect, cookie := startLogin(t, sp, "")
+		sp.now = func() time.Time { return time.Now().Add(DefaultRequestTTL + time.Second) }
+		defer func() { sp.now = time.Now }()
+		rec, req := postResponse(idp.respond(t, redirect), cookie)
+		_, err := sp.FinishLogin(rec, req)
+		assert.ErrorIs(t, err, ErrUnknownRequest)
+	})
+
+	t.Run("tampered response", func(t *testing.T) {
+		redirect, cookie := startLogin(t, sp, "")
+		form := idp.respond(t, redirect)
+		form.Set("SAMLResponse", "PHNhbWxwOlJlc3BvbnNlLz4=")
+		rec, req := postResponse(form, cookie)
+		_, err := sp.FinishLogin(rec, req)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("response signed by another identity provider", func(t *testing.T) {
+		redirect, cookie := startLogin(t, sp, "")
+		impostor := newTestIdP(t)
+		impostor.sp = idp.sp
+		impostor.idp.MetadataURL = idp.idp.MetadataURL
+		rec, req := postResponse(impostor.respond(t, redirect), cookie)
+		_, err := sp.FinishLogin(rec, req)
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+}
+
+func TestServiceProvider_MetadataURL(t *testing.T) {
+	idp := newTestIdP(t)
+	var fetches, failing atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if failing.Load() == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(idp.metadata(t))
+	}))
+	defer server.Close()
+
+	cert, key := newKeyPair(t, "sp.example.com")
+	sp, err := New(Config{
+		EntityID:       testEntityID,
+		ACSURL:         testACSURL,
+		Certificate:    cert,
+		Key:            key,
+		IDPMetadataURL: server.URL,
+		HTTPClient:     server.Client(),
+		StateKey:       []byte("0123456789abcdef0123456789abcdef"),
+	})
+	require.NoError(t, err)
+	assert.Zero(t, fetches.Load(), "metadata should be fetched on first use")
+
+	startLogin(t, sp, "")
+	startLogin(t, sp, "")
+	assert.Equal(t, int32(1), fetches.Load())
+
+	// Refresh failures keep the previous metadata
+	failing.Store(1)
+	sp.now = func() time.Time { return time.Now().Add(DefaultMetadataRefresh + time.Minute) }
+	startLogin(t, sp, "")
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestServiceProvider_MetadataUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	cert, key := newKeyPair(t, "sp.example.com")
+	sp, err := New(Config{
+		EntityID:       testEntityID,
+		ACSURL:         testACSURL,
+		Certificate:    cert,
+		Key:            key,
+		IDPMetadataURL: server.URL,
+		HTTPClient:     server.Client(),
+		StateKey:       []byte("0123456789abcdef0123456789abcdef"),
+	})
+	require.NoError(t, err)
+
+	_, err = sp.StartLogin(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/login", nil), "")
+	assert.ErrorIs(t, err, ErrMetadataUnavailable)
+}
+
+func TestNew_Validation(t *testing.T) {
+	cert, key := newKeyPair(t, "sp.example.com")
+	valid := func() Config {
+		return Config{EntityID: testEntityID, ACSURL: testACSURL, Certificate: cert, Key: key, IDPMetadataURL: "https://idp.example.com/metadata", StateKey: []byte("key")}
+	}
+
+	tests := map[string]func(*Config){
+		"no key pair":           func(c *Config) { c.Key = nil },
+		"no state key":          func(c *Config) { c.StateKey = nil },
+		"no metadata":           func(c *Config) { c.IDPMetadataURL = "" },
+		"both metadata sources": func(c *Config) { c.IDPMetadata = []byte("<EntityDescriptor/>") },
+		"relative ACS URL":      func(c *Config) { c.ACSURL = "/acs" },
+		"invalid metadata": func(c *Config) {
+			c.IDPMetadataURL = ""
+			c.IDPMetadata = []byte(`<EntityDescriptor xmlns="urn:oasis:names:tc:SAML:2.0:metadata" entityID="x"/>`)
+		},
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := valid()
+			modify(&cfg)
+			_, err := New(cfg)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := New(valid())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,169 @@
+package scim
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// Filter operators
+const (
+	Eq = "eq"
+	Ne = "ne"
+	Co = "co"
+	Sw = "sw"
+	Ew = "ew"
+	Pr = "pr"
+	Gt = "gt"
+	Ge = "ge"
+	Lt = "lt"
+	Le = "le"
+)
+
+var filterOperators = map[string]bool{Eq: true, Ne: true, Co: true, Sw: true, Ew: true, Pr: true, Gt: true, Ge: true, Lt: true, Le: true}
+
+// Comparison is one attribute expression of a filter. Attribute and
+// Operator are lowercased, as SCIM names are case-insensitive. Value is a
+// string, bool, float64 or nil, and nil for the pr operator.
+type Comparison struct {
+	Attribute string
+	Operator  string
+	Value     interface{}
+}
+
+// Filter is a parsed filter: comparisons that must all match. Identity
+// providers only send such filters, so or, not, grouping and value paths
+// are rejected.
+type Filter []Comparison
+
+// ParseFilter parses the filter query parameter of a list request. An
+// empty filter matches every resource.
+func ParseFilter(raw string) (Filter, error) {
+	tokens, err := tokenize(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter Filter
+	for len(tokens) > 0 {
+		if len(filter) > 0 {
+			if !strings.EqualFold(tokens[0].text, "and") || tokens[0].quoted {
+				return nil, BadRequest(InvalidFilter, "expected and, got %q; only and is supported", tokens[0].text)
+			}
+			tokens = tokens[1:]
+		}
+
+		comparison, rest, err := parseComparison(tokens)
+		if err != nil {
+			return nil, err
+		}
+		filter = append(filter, comparison)
+		tokens = rest
+	}
+	return filter, nil
+}
+
+func parseComparison(tokens []token) (Comparison, []token, error) {
+	if len(tokens) < 2 || tokens[0].quoted {
+		return Comparison{}, nil, BadRequest(InvalidFilter, "expected an attribute and an operator")
+	}
+	attribute := strings.ToLower(tokens[0].text)
+	if !isAttributePath(attribute) {
+		return Comparison{}, nil, BadRequest(InvalidFilter, "unsupported attribute path %q", tokens[0].text)
+	}
+	operator := strings.ToLower(tokens[1].text)
+	if tokens[1].quoted || !filterOperators[operator] {
+		return Comparison{}, nil, BadRequest(InvalidFilter, "unsupported operator %q", tokens[1].text)
+	}
+
+	comparison := Comparison{Attribute: attribute, Operator: operator}
+	if operator == Pr {
+		return comparison, tokens[2:], nil
+	}
+	if len(tokens) < 3 {
+		return Comparison{}, nil, BadRequest(InvalidFilter, "%s %s needs a value", tokens[0].text, operator)
+	}
+	value, err := tokens[2].value()
+	if err != nil {
+		return Comparison{}, nil, err
+	}
+	comparison.Value = value
+	return comparison, tokens[3:], nil
+}
+
+// isAttributePath reports whether path is an attribute name, optionally
+// prefixed by a schema URN and followed by a sub-attribute
+func isAttributePath(path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, r := range path {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune(".:_-$", r) {
+			return false
+		}
+	}
+	return true
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+// value returns the comparison value a token denotes
+func (t token) value() (interface{}, error) {
+	if t.quoted {
+		var s string
+		if err := json.Unmarshal([]byte(t.text), &s); err != nil {
+			return nil, BadRequest(InvalidFilter, "invalid string %s", t.text)
+		}
+		return s, nil
+	}
+	switch strings.ToLower(t.text) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	var number float64
+	if err := json.Unmarshal([]byte(t.text), &number); err != nil {
+		return nil, BadRequest(InvalidFilter, "invalid value %q; strings must be quoted", t.text)
+	}
+	return number, nil
+}
+
+// tokenize splits a filter into words and JSON strings, which keep their
+// quotes
+func tokenize(raw string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(raw); {
+		switch c := raw[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(raw) && raw[end] != '"'; end++ {
+				if raw[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(raw) {
+				return nil, BadRequest(InvalidFilter, "unterminated string")
+			}
+			tokens = append(tokens, token{text: raw[i : end+1], quoted: true})
+			i = end + 1
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			return nil, BadRequest(InvalidFilter, "grouping and value paths are not supported")
+		default:
+			end := i
+			for end < len(raw) && !strings.ContainsRune(" \t\"()[]", rune(raw[end])) {
+				end++
+			}
+			tokens = append(tokens, token{text: raw[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
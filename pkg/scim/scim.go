@@ -0,0 +1,157 @@
+// Package scim implements the protocol messages of SCIM 2.0 (RFC 7643 and
+// RFC 7644) that a provisioning server exchanges with identity providers:
+// list responses, errors, filters and PATCH requests. Resources themselves
+// are defined by the server.
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MediaType is the media type of SCIM requests and responses
+const MediaType = "application/scim+json"
+
+// Schema URNs
+const (
+	UserSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	ServiceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	ListResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	PatchOpSchema               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	ErrorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// Error types of 400 Bad Request and 409 Conflict responses
+const (
+	InvalidFilter = "invalidFilter"
+	InvalidSyntax = "invalidSyntax"
+	InvalidPath   = "invalidPath"
+	InvalidValue  = "invalidValue"
+	NoTarget      = "noTarget"
+	Mutability    = "mutability"
+	Uniqueness    = "uniqueness"
+)
+
+// Error is a SCIM error response. It is also returned by the parsers of
+// this package, with the status and type to respond with.
+type Error struct {
+	Schemas []string `json:"schemas"`
+	// Status is the HTTP status code, as a string
+	Status   string `json:"status"`
+	ScimType string `json:"scimType,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// NewError returns an error response with the given status, type and detail
+func NewError(status int, scimType, detail string) *Error {
+	return &Error{
+		Schemas:  []string{ErrorSchema},
+		Status:   fmt.Sprint(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
+
+// BadRequest returns a 400 Bad Request error of the given type
+func BadRequest(scimType, format string, args ...interface{}) *Error {
+	return NewError(http.StatusBadRequest, scimType, fmt.Sprintf(format, args...))
+}
+
+func (e *Error) Error() string {
+	if e.ScimType == "" {
+		return "scim: " + e.Detail
+	}
+	return "scim: " + e.ScimType + ": " + e.Detail
+}
+
+// ListResponse is the envelope of query results. StartIndex is 1-based.
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int64         `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// NewListResponse returns the page of resources starting at startIndex out
+// of total results
+func NewListResponse(resources []interface{}, total int64, startIndex int) ListResponse {
+	if resources == nil {
+		resources = []interface{}{}
+	}
+	return ListResponse{
+		Schemas:      []string{ListResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+// Meta is the resource metadata common to every resource
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location,omitempty"`
+}
+
+// Patch operation types
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// PatchOperation is a single change of a PATCH request. Path is empty when
+// Value holds the attributes to change.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// PatchRequest is the body of a PATCH request
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// Validate checks the request's schema and operations, and lowercases
+// their op names, which some identity providers capitalize
+func (p *PatchRequest) Validate() error {
+	if !hasSchema(p.Schemas, PatchOpSchema) {
+		return BadRequest(InvalidSyntax, "PATCH requests must use the %s schema", PatchOpSchema)
+	}
+	if len(p.Operations) == 0 {
+		return BadRequest(InvalidSyntax, "PATCH requests need at least one operation")
+	}
+	for i := range p.Operations {
+		op := &p.Operations[i]
+		op.Op = strings.ToLower(op.Op)
+		switch op.Op {
+		case OpAdd, OpReplace:
+			if len(op.Value) == 0 {
+				return BadRequest(InvalidValue, "%s operations need a value", op.Op)
+			}
+		case OpRemove:
+			if op.Path == "" {
+				return BadRequest(NoTarget, "remove operations need a path")
+			}
+		default:
+			return BadRequest(InvalidSyntax, "unsupported PATCH operation %q", op.Op)
+		}
+	}
+	return nil
+}
+
+func hasSchema(schemas []string, schema string) bool {
+	for _, s := range schemas {
+		if strings.EqualFold(s, schema) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   Filter
+	}{
+		{name: "empty", filter: ""},
+		{
+			name:   "string equality",
+			filter: `userName eq "bjensen@example.com"`,
+			want:   Filter{{Attribute: "username", Operator: Eq, Value: "bjensen@example.com"}},
+		},
+		{
+			name:   "case-insensitive names and escapes",
+			filter: `DisplayName SW "Barbara \"Babs\""`,
+			want:   Filter{{Attribute: "displayname", Operator: Sw, Value: `Barbara "Babs"`}},
+		},
+		{
+			name:   "conjunction",
+			filter: `meta.lastModified gt "2011-05-13T04:42:34Z" and active eq true and title pr`,
+			want: Filter{
+				{Attribute: "meta.lastmodified", Operator: Gt, Value: "2011-05-13T04:42:34Z"},
+				{Attribute: "active", Operator: Eq, Value: true},
+				{Attribute: "title", Operator: Pr},
+			},
+		},
+		{
+			name:   "schema prefix",
+			filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bjensen"`,
+			want:   Filter{{Attribute: "urn:ietf:params:scim:schemas:core:2.0:user:username", Operator: Eq, Value: "bjensen"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, filter)
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	for _, filter := range []string{
+		`userName`,
+		`userName eq`,
+		`userName is "bjensen"`,
+		`userName eq bjensen`,
+		`userName eq "bjensen`,
+		`userName eq "a" or userName eq "b"`,
+		`(userName eq "a")`,
+		`emails[type eq "work"]`,
+		`"userName" eq "a"`,
+	} {
+		_, err := ParseFilter(filter)
+		var scimErr *Error
+		if assert.ErrorAs(t, err, &scimErr, filter) {
+			assert.Equal(t, "400", scimErr.Status)
+			assert.Equal(t, InvalidFilter, scimErr.ScimType)
+		}
+	}
+}
+
+func TestPatchRequest_Validate(t *testing.T) {
+	var patch PatchRequest
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "Replace", "path": "active", "value": false},
+			{"op": "remove", "path": "displayName"}
+		]
+	}`), &patch))
+	require.NoError(t, patch.Validate())
+	assert.Equal(t, OpReplace, patch.Operations[0].Op)
+
+	invalid := []PatchRequest{
+		{Operations: []PatchOperation{{Op: OpRemove, Path: "title"}}},
+		{Schemas: []string{PatchOpSchema}},
+		{Schemas: []string{PatchOpSchema}, Operations: []PatchOperation{{Op: "move", Path: "title"}}},
+		{Schemas: []string{PatchOpSchema}, Operations: []PatchOperation{{Op: OpAdd, Path: "title"}}},
+		{Schemas: []string{PatchOpSchema}, Operations: []PatchOperation{{Op: OpRemove}}},
+	}
+	for _, patch := range invalid {
+		assert.Error(t, patch.Validate(), "%+v", patch)
+	}
+}
+
+func TestNewListResponse(t *testing.T) {
+	encoded, err := json.Marshal(NewListResponse(nil, 0, 1))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:ListResponse"],
+		"totalResults": 0,
+		"startIndex": 1,
+		"itemsPerPage": 0,
+		"Resources": []
+	}`, string(encoded))
+}
@@ -0,0 +1,166 @@
+// Package shutdown records what happened during a graceful shutdown: each
+// step that ran, how long it took, whether it failed and whether it had to
+// be aborted because the shutdown deadline passed. The report is logged and
+// can be written to a file, to debug slow or stuck shutdowns.
+package shutdown
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/logger"
+)
+
+// Step is one completed shutdown step
+type Step struct {
+	Phase    string        `json:"phase"` // e.g. http, workers, broker, database
+	Name     string        `json:"name"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration_ns"`
+	Error    string        `json:"error,omitempty"`
+	// Aborted is set when the step did not finish gracefully, e.g. a server
+	// whose connections were closed forcibly after the deadline
+	Aborted bool `json:"aborted,omitempty"`
+}
+
+// Report collects the steps of one shutdown. It is safe for concurrent use.
+type Report struct {
+	Reason   string        `json:"reason"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration_ns"`
+	Steps    []Step        `json:"steps"`
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewReport starts a report for a shutdown triggered by reason
+func NewReport(reason string) *Report {
+	return &Report{Reason: reason, Started: time.Now(), Steps: []Step{}, now: time.Now}
+}
+
+// Run times fn as a step of phase and records its outcome. When ctx ends
+// before fn returns, Run stops waiting and returns ctx's error, leaving fn
+// running in the background. Such steps, and steps failing because ctx
+// ended, are recorded as aborted. Otherwise it returns fn's error.
+func (r *Report) Run(ctx context.Context, phase, name string, fn func() error) error {
+	started := r.now()
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("abandoned: %w", ctx.Err())
+	}
+	r.Add(Step{
+		Phase:    phase,
+		Name:     name,
+		Started:  started,
+		Duration: r.now().Sub(started),
+		Error:    errorString(err),
+		Aborted:  err != nil && (ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)),
+	})
+	return err
+}
+
+// Add records a step measured elsewhere
+func (r *Report) Add(step Step) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Steps = append(r.Steps, step)
+}
+
+// Finish records the total duration of the shutdown
+func (r *Report) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Duration = r.now().Sub(r.Started)
+}
+
+// Aborted returns the steps that did not finish gracefully
+func (r *Report) Aborted() []Step {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var aborted []Step
+	for _, step := range r.Steps {
+		if step.Aborted {
+			aborted = append(aborted, step)
+		}
+	}
+	return aborted
+}
+
+// Log writes one structured entry per step followed by a summary
+func (r *Report) Log(log logger.Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	failed, aborted := 0, 0
+	for _, step := range r.Steps {
+		fields := map[string]interface{}{
+			"phase":       step.Phase,
+			"step":        step.Name,
+			"duration_ms": step.Duration.Milliseconds(),
+		}
+		switch {
+		case step.Aborted:
+			aborted++
+			fields["error"] = step.Error
+			log.WithFields(fields).Warn("Shutdown step aborted")
+		case step.Error != "":
+			failed++
+			fields["error"] = step.Error
+			log.WithFields(fields).Error("Shutdown step failed")
+		default:
+			log.WithFields(fields).Info("Shutdown step completed")
+		}
+	}
+
+	log.WithFields(map[string]interface{}{
+		"reason":      r.Reason,
+		"duration_ms": r.Duration.Milliseconds(),
+		"steps":       len(r.Steps),
+		"failed":      failed,
+		"aborted":     aborted,
+	}).Info("Shutdown report")
+}
+
+// WriteFile writes the report as JSON to path, replacing any previous
+// report atomically
+func (r *Report) WriteFile(path string) error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".shutdown-report-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,73 @@
+package shutdown
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/logger"
+)
+
+func TestReport(t *testing.T) {
+	report := NewReport("signal")
+	clock := report.Started
+	report.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	ctx := context.Background()
+	assert.NoError(t, report.Run(ctx, "workers", "event handlers", func() error { return nil }))
+	failure := errors.New("connection reset")
+	assert.ErrorIs(t, report.Run(ctx, "broker", "messaging", func() error { return failure }), failure)
+
+	expired, cancel := context.WithCancel(ctx)
+	cancel()
+	stuck := make(chan struct{})
+	defer close(stuck)
+	err := report.Run(expired, "database", "postgres", func() error {
+		<-stuck
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	report.Finish()
+
+	require.Len(t, report.Steps, 3)
+	assert.Equal(t, time.Second, report.Steps[0].Duration)
+	assert.Empty(t, report.Steps[0].Error)
+	assert.Equal(t, "connection reset", report.Steps[1].Error)
+	assert.False(t, report.Steps[1].Aborted)
+	assert.True(t, report.Steps[2].Aborted)
+	assert.Equal(t, []Step{report.Steps[2]}, report.Aborted())
+	assert.Equal(t, 7*time.Second, report.Duration)
+
+	report.Log(logger.New())
+}
+
+func TestReport_WriteFile(t *testing.T) {
+	report := NewReport("upgrade")
+	report.Add(Step{Phase: "http", Name: "api", Duration: 2 * time.Second, Aborted: true, Error: "context deadline exceeded"})
+	report.Finish()
+
+	path := filepath.Join(t.TempDir(), "shutdown.json")
+	require.NoError(t, report.WriteFile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var written struct {
+		Reason string `json:"reason"`
+		Steps  []Step `json:"steps"`
+	}
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, "upgrade", written.Reason)
+	require.Len(t, written.Steps, 1)
+	assert.Equal(t, "api", written.Steps[0].Name)
+	assert.True(t, written.Steps[0].Aborted)
+}
@@ -0,0 +1,43 @@
+package signedstate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testState struct {
+	State   string `json:"st"`
+	Expires int64  `json:"exp"`
+}
+
+func TestCodec(t *testing.T) {
+	codec := New([]byte("state-key"), "https://idp.example.com", "client_1")
+
+	value, err := codec.Encode(testState{State: "abc", Expires: 1700000000})
+	require.NoError(t, err)
+
+	var state testState
+	require.True(t, codec.Decode(value, &state))
+	assert.Equal(t, testState{State: "abc", Expires: 1700000000}, state)
+
+	encoded, signature, _ := strings.Cut(value, ".")
+	tests := map[string]string{
+		"unsigned":       encoded,
+		"bad signature":  encoded + ".AAAA",
+		"other payload":  "eyJzdCI6ImRlZiJ9." + signature,
+		"not base64 mac": encoded + ".!",
+	}
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.False(t, codec.Decode(value, &testState{}))
+		})
+	}
+
+	t.Run("other scope", func(t *testing.T) {
+		assert.False(t, New([]byte("state-key"), "https://idp.example.com", "client_2").Decode(value, &testState{}))
+		assert.False(t, New([]byte("other-key"), "https://idp.example.com", "client_1").Decode(value, &testState{}))
+	})
+}
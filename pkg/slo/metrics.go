@@ -0,0 +1,62 @@
+package slo
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// collector exports the tracker's statuses as Prometheus metrics
+type collector struct {
+	tracker *Tracker
+
+	target     *prometheus.Desc
+	compliance *prometheus.Desc
+	remaining  *prometheus.Desc
+	burnRate   *prometheus.Desc
+	firing     *prometheus.Desc
+}
+
+func newCollector(t *Tracker) *collector {
+	labels := []string{"method", "route", "sli"}
+	return &collector{
+		tracker: t,
+		target: prometheus.NewDesc("slo_target_ratio",
+			"Target fraction of good requests.", labels, nil),
+		compliance: prometheus.NewDesc("slo_compliance_ratio",
+			"Fraction of good requests over the long window.", labels, nil),
+		remaining: prometheus.NewDesc("slo_error_budget_remaining_ratio",
+			"Fraction of the error budget left over the long window; negative once exhausted.", labels, nil),
+		burnRate: prometheus.NewDesc("slo_error_budget_burn_rate",
+			"Rate at which the error budget is spent; 1 spends it exactly over the long window.",
+			append(labels, "window"), nil),
+		firing: prometheus.NewDesc("slo_burn_rate_alert_firing",
+			"Whether the burn rate alert is firing (1) or not (0).", labels, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (t *Tracker) Describe(ch chan<- *prometheus.Desc) {
+	c := t.metrics
+	ch <- c.target
+	ch <- c.compliance
+	ch <- c.remaining
+	ch <- c.burnRate
+	ch <- c.firing
+}
+
+// Collect implements prometheus.Collector
+func (t *Tracker) Collect(ch chan<- prometheus.Metric) {
+	c := t.metrics
+	for _, s := range t.Statuses() {
+		labels := []string{s.Method, s.Route, s.SLI}
+
+		firing := 0.0
+		if t.isFiring(s) {
+			firing = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.target, prometheus.GaugeValue, s.Target, labels...)
+		ch <- prometheus.MustNewConstMetric(c.compliance, prometheus.GaugeValue, s.Compliance, labels...)
+		ch <- prometheus.MustNewConstMetric(c.remaining, prometheus.GaugeValue, s.BudgetRemaining, labels...)
+		ch <- prometheus.MustNewConstMetric(c.burnRate, prometheus.GaugeValue, s.ShortBurnRate, append(labels, "short")...)
+		ch <- prometheus.MustNewConstMetric(c.burnRate, prometheus.GaugeValue, s.LongBurnRate, append(labels, "long")...)
+		ch <- prometheus.MustNewConstMetric(c.firing, prometheus.GaugeValue, firing, labels...)
+	}
+}
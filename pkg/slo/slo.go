@@ -0,0 +1,296 @@
+// Package slo tracks per-route service level objectives in-process. It
+// computes rolling compliance over a short and a long window, reports error
+// budget burn rates as Prometheus metrics and notifies when a budget burns
+// fast over both windows.
+package slo
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"clean-architecture/pkg/httpserver"
+	"clean-architecture/pkg/logger"
+)
+
+// Service level indicators tracked per objective
+const (
+	SLIAvailability = "availability"
+	SLILatency      = "latency"
+)
+
+// defaultMinRequests is the default number of requests the short window
+// must hold before an alert may fire, so a single failure on an idle route
+// does not page anyone
+const defaultMinRequests = 10
+
+// Objective is the service level objective of a single route
+type Objective struct {
+	Method string
+	// Route is the chi route pattern, e.g. /api/v1/users/{id}
+	Route string
+	// Availability is the fraction of requests that must not fail with a
+	// 5xx status
+	Availability float64
+	// Latency, when set, is the threshold LatencyTarget of requests must be
+	// served within
+	Latency       time.Duration
+	LatencyTarget float64
+}
+
+// Options configures a Tracker
+type Options struct {
+	ShortWindow time.Duration
+	LongWindow  time.Duration
+	// BurnRateThreshold is the burn rate both windows must reach for an
+	// alert to fire. A burn rate of 1 spends the budget exactly over the
+	// long window.
+	BurnRateThreshold float64
+	CheckInterval     time.Duration
+	// MinRequests is the number of requests the short window must hold
+	// before an alert may fire. Zero uses a default of 10.
+	MinRequests uint64
+	// Notifier is told when an alert starts or stops firing
+	Notifier Notifier
+}
+
+// Status is the rolling compliance of one indicator of an objective
+type Status struct {
+	Method string  `json:"method"`
+	Route  string  `json:"route"`
+	SLI    string  `json:"sli"`
+	Target float64 `json:"target"`
+
+	// Requests and Compliance cover the long window
+	Requests        uint64  `json:"requests"`
+	Compliance      float64 `json:"compliance"`
+	BudgetRemaining float64 `json:"budget_remaining"`
+	ShortRequests   uint64  `json:"short_requests"`
+	ShortBurnRate   float64 `json:"short_burn_rate"`
+	LongBurnRate    float64 `json:"long_burn_rate"`
+}
+
+type tracked struct {
+	objective Objective
+	window    *window
+}
+
+// Tracker records request outcomes for the configured objectives
+type Tracker struct {
+	opts       Options
+	objectives map[string]*tracked
+	keys       []string
+	now        func() time.Time
+	metrics    *collector
+
+	mu     sync.Mutex
+	firing map[string]bool
+}
+
+// NewTracker creates a tracker for the given objectives
+func NewTracker(objectives []Objective, opts Options) *Tracker {
+	if opts.MinRequests == 0 {
+		opts.MinRequests = defaultMinRequests
+	}
+
+	// Buckets are a fraction of the short window so it rolls smoothly
+	width := opts.ShortWindow / 5
+	if width < time.Second {
+		width = time.Second
+	}
+
+	t := &Tracker{
+		opts:       opts,
+		objectives: make(map[string]*tracked, len(objectives)),
+		now:        time.Now,
+		firing:     make(map[string]bool),
+	}
+	for _, o := range objectives {
+		o.Method = strings.ToUpper(o.Method)
+		o.Route = normalizeRoute(o.Route)
+		key := o.Method + " " + o.Route
+		t.objectives[key] = &tracked{objective: o, window: newWindow(width, opts.LongWindow)}
+		t.keys = append(t.keys, key)
+	}
+	sort.Strings(t.keys)
+	t.metrics = newCollector(t)
+
+	return t
+}
+
+// Middleware records the outcome of requests to routes with an objective.
+// It must run inside the chi router so the matched route pattern is known.
+func (t *Tracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := httpserver.NewStatusWriter(w)
+
+		next.ServeHTTP(ww, r)
+
+		rctx := chi.RouteContext(r.Context())
+		if rctx == nil || rctx.RoutePattern() == "" {
+			return
+		}
+		tr, ok := t.objectives[r.Method+" "+normalizeRoute(rctx.RoutePattern())]
+		if !ok {
+			return
+		}
+
+		elapsed := time.Since(start)
+		failed := ww.Status >= http.StatusInternalServerError
+		slow := tr.objective.Latency > 0 && elapsed > tr.objective.Latency
+		tr.window.record(t.now(), failed, slow)
+	})
+}
+
+// Statuses returns the rolling compliance of every tracked indicator
+func (t *Tracker) Statuses() []Status {
+	now := t.now()
+	statuses := make([]Status, 0, len(t.keys))
+	for _, key := range t.keys {
+		tr := t.objectives[key]
+		short := tr.window.sum(now, t.opts.ShortWindow)
+		long := tr.window.sum(now, t.opts.LongWindow)
+
+		statuses = append(statuses, status(tr.objective, SLIAvailability, tr.objective.Availability,
+			short.total, short.errors, long.total, long.errors))
+		if tr.objective.Latency > 0 {
+			statuses = append(statuses, status(tr.objective, SLILatency, tr.objective.LatencyTarget,
+				short.total, short.slow, long.total, long.slow))
+		}
+	}
+	return statuses
+}
+
+// Run periodically checks burn rates and notifies on alert transitions
+// until ctx is done
+func (t *Tracker) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.check(ctx)
+		}
+	}
+}
+
+// check evaluates every indicator and notifies about alerts that started or
+// stopped firing since the previous check
+func (t *Tracker) check(ctx context.Context) {
+	for _, s := range t.Statuses() {
+		firing := s.ShortRequests >= t.opts.MinRequests &&
+			s.ShortBurnRate >= t.opts.BurnRateThreshold &&
+			s.LongBurnRate >= t.opts.BurnRateThreshold
+
+		key := s.Method + " " + s.Route + " " + s.SLI
+		t.mu.Lock()
+		changed := t.firing[key] != firing
+		t.firing[key] = firing
+		t.mu.Unlock()
+
+		if changed && t.opts.Notifier != nil {
+			t.opts.Notifier.Notify(ctx, Alert{Status: s, Threshold: t.opts.BurnRateThreshold, Firing: firing})
+		}
+	}
+}
+
+// isFiring reports whether the alert for an indicator is firing
+func (t *Tracker) isFiring(s Status) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.firing[s.Method+" "+s.Route+" "+s.SLI]
+}
+
+// status computes the compliance of one indicator from its bad request
+// counts over the short and long window
+func status(o Objective, sli string, target float64, shortTotal, shortBad, longTotal, longBad uint64) Status {
+	budget := 1 - target
+	longBurn := burnRate(longTotal, longBad, budget)
+
+	compliance := 1.0
+	if longTotal > 0 {
+		compliance = 1 - float64(longBad)/float64(longTotal)
+	}
+
+	return Status{
+		Method:          o.Method,
+		Route:           o.Route,
+		SLI:             sli,
+		Target:          target,
+		Requests:        longTotal,
+		Compliance:      compliance,
+		BudgetRemaining: 1 - longBurn,
+		ShortRequests:   shortTotal,
+		ShortBurnRate:   burnRate(shortTotal, shortBad, budget),
+		LongBurnRate:    longBurn,
+	}
+}
+
+// burnRate returns how many times faster than sustainable the error budget
+// is being spent
+func burnRate(total, bad uint64, budget float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(bad) / float64(total) / budget
+}
+
+// normalizeRoute strips the trailing slash chi leaves on patterns of
+// subrouter index routes, so "/api/v1/users/" and "/api/v1/users" match
+func normalizeRoute(route string) string {
+	if len(route) > 1 {
+		return strings.TrimSuffix(route, "/")
+	}
+	return route
+}
+
+// Alert describes an error budget that started or stopped burning fast
+type Alert struct {
+	Status
+	Threshold float64
+	Firing    bool
+}
+
+// Notifier is told about alert transitions
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert)
+}
+
+// NotifierFunc adapts a function to the Notifier interface
+type NotifierFunc func(ctx context.Context, alert Alert)
+
+// Notify calls f
+func (f NotifierFunc) Notify(ctx context.Context, alert Alert) {
+	f(ctx, alert)
+}
+
+// NewLogNotifier returns a notifier that logs alerts as warnings and
+// recoveries as info
+func NewLogNotifier(log logger.Logger) Notifier {
+	return NotifierFunc(func(ctx context.Context, alert Alert) {
+		entry := log.WithFields(map[string]interface{}{
+			"method":           alert.Method,
+			"route":            alert.Route,
+			"sli":              alert.SLI,
+			"target":           alert.Target,
+			"short_burn_rate":  alert.ShortBurnRate,
+			"long_burn_rate":   alert.LongBurnRate,
+			"threshold":        alert.Threshold,
+			"budget_remaining": alert.BudgetRemaining,
+		})
+		if alert.Firing {
+			entry.Warn("SLO error budget is burning fast")
+			return
+		}
+		entry.Info("SLO error budget burn rate recovered")
+	})
+}
@@ -0,0 +1,154 @@
+package slo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTracker(notifier Notifier) (*Tracker, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker([]Objective{
+		{Method: "GET", Route: "/users/", Availability: 0.99, Latency: 50 * time.Millisecond, LatencyTarget: 0.9},
+	}, Options{
+		ShortWindow:       5 * time.Minute,
+		LongWindow:        time.Hour,
+		BurnRateThreshold: 10,
+		CheckInterval:     time.Second,
+		Notifier:          notifier,
+	})
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func newTestRouter(tracker *Tracker, status *int) http.Handler {
+	r := chi.NewRouter()
+	r.Use(tracker.Middleware)
+	r.Route("/users", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(*status)
+		})
+	})
+	r.Get("/other", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	return r
+}
+
+func TestTracker_Statuses(t *testing.T) {
+	tracker, _ := newTestTracker(nil)
+	status := http.StatusOK
+	router := newTestRouter(tracker, &status)
+
+	for i := 0; i < 98; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+	}
+	status = http.StatusInternalServerError
+	for i := 0; i < 2; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+	}
+	// Routes without an objective are ignored
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil))
+
+	statuses := tracker.Statuses()
+	require.Len(t, statuses, 2)
+
+	availability := statuses[0]
+	assert.Equal(t, SLIAvailability, availability.SLI)
+	assert.Equal(t, "/users", availability.Route)
+	assert.Equal(t, uint64(100), availability.Requests)
+	assert.InDelta(t, 0.98, availability.Compliance, 1e-9)
+	assert.InDelta(t, 2, availability.LongBurnRate, 1e-9)
+	assert.InDelta(t, 2, availability.ShortBurnRate, 1e-9)
+	assert.InDelta(t, -1, availability.BudgetRemaining, 1e-9)
+
+	latency := statuses[1]
+	assert.Equal(t, SLILatency, latency.SLI)
+	assert.InDelta(t, 1, latency.Compliance, 1e-9)
+	assert.Zero(t, latency.LongBurnRate)
+}
+
+func TestTracker_WindowsRoll(t *testing.T) {
+	tracker, now := newTestTracker(nil)
+	status := http.StatusInternalServerError
+	router := newTestRouter(tracker, &status)
+
+	for i := 0; i < 10; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+	}
+
+	*now = now.Add(10 * time.Minute)
+	s := tracker.Statuses()[0]
+	assert.Zero(t, s.ShortRequests, "failures left the short window")
+	assert.Equal(t, uint64(10), s.Requests, "failures are still in the long window")
+
+	*now = now.Add(time.Hour)
+	s = tracker.Statuses()[0]
+	assert.Zero(t, s.Requests)
+	assert.Equal(t, 1.0, s.Compliance)
+}
+
+func TestTracker_Alerts(t *testing.T) {
+	var alerts []Alert
+	tracker, now := newTestTracker(NotifierFunc(func(ctx context.Context, alert Alert) {
+		alerts = append(alerts, alert)
+	}))
+	status := http.StatusInternalServerError
+	router := newTestRouter(tracker, &status)
+
+	// Too few requests to alert on
+	for i := 0; i < 5; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+	}
+	tracker.check(context.Background())
+	assert.Empty(t, alerts)
+
+	for i := 0; i < 5; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+	}
+	tracker.check(context.Background())
+	require.Len(t, alerts, 1)
+	assert.True(t, alerts[0].Firing)
+	assert.Equal(t, SLIAvailability, alerts[0].SLI)
+	assert.Equal(t, 10.0, alerts[0].Threshold)
+
+	// Still firing: no repeated notification
+	tracker.check(context.Background())
+	assert.Len(t, alerts, 1)
+
+	*now = now.Add(10 * time.Minute)
+	tracker.check(context.Background())
+	require.Len(t, alerts, 2)
+	assert.False(t, alerts[1].Firing)
+}
+
+func TestTracker_Collect(t *testing.T) {
+	tracker, _ := newTestTracker(nil)
+	status := http.StatusOK
+	router := newTestRouter(tracker, &status)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(tracker)
+
+	expected := `
+# HELP slo_compliance_ratio Fraction of good requests over the long window.
+# TYPE slo_compliance_ratio gauge
+slo_compliance_ratio{method="GET",route="/users",sli="availability"} 1
+slo_compliance_ratio{method="GET",route="/users",sli="latency"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "slo_compliance_ratio"))
+
+	count, err := testutil.GatherAndCount(registry, "slo_error_budget_burn_rate")
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
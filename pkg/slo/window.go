@@ -0,0 +1,84 @@
+package slo
+
+import (
+	"sync"
+	"time"
+)
+
+// counts holds request outcomes over a span of time
+type counts struct {
+	total  uint64
+	errors uint64
+	slow   uint64
+}
+
+func (c *counts) add(o counts) {
+	c.total += o.total
+	c.errors += o.errors
+	c.slow += o.slow
+}
+
+// bucket holds the outcomes of requests that finished within one bucket
+// width. index identifies the time slot so stale buckets can be recognised
+// after the ring wraps around.
+type bucket struct {
+	index int64
+	counts
+}
+
+// window is a ring of fixed-width time buckets covering at least the long
+// window, so rolling counts over any shorter span can be summed cheaply
+type window struct {
+	mu      sync.Mutex
+	width   time.Duration
+	buckets []bucket
+}
+
+func newWindow(width, span time.Duration) *window {
+	n := int(span/width) + 1
+	return &window{width: width, buckets: make([]bucket, n)}
+}
+
+// record adds the outcome of one request finished at now
+func (w *window) record(now time.Time, failed, slow bool) {
+	index := now.UnixNano() / int64(w.width)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	b := &w.buckets[index%int64(len(w.buckets))]
+	if b.index != index {
+		*b = bucket{index: index}
+	}
+	b.total++
+	if failed {
+		b.errors++
+	}
+	if slow {
+		b.slow++
+	}
+}
+
+// sum returns the outcomes of requests finished within span before now
+func (w *window) sum(now time.Time, span time.Duration) counts {
+	current := now.UnixNano() / int64(w.width)
+	n := int64(span / w.width)
+	if n < 1 {
+		n = 1
+	}
+	if n > int64(len(w.buckets)) {
+		n = int64(len(w.buckets))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var c counts
+	for i := int64(0); i < n; i++ {
+		index := current - i
+		if b := w.buckets[index%int64(len(w.buckets))]; b.index == index {
+			c.add(b.counts)
+		}
+	}
+	return c
+}
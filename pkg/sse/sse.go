@@ -0,0 +1,118 @@
+// Package sse writes Server-Sent Events streams, as specified by the HTML
+// Living Standard, to HTTP responses.
+//
+// A Stream takes over its response: it lifts the server's write and read
+// deadlines, which would otherwise cut a long-lived stream off, and flushes
+// every event so clients receive it right away.
+package sse
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type of event streams
+const ContentType = "text/event-stream"
+
+// LastEventIDHeader is the request header reconnecting clients send the ID
+// of the last event they received in
+const LastEventIDHeader = "Last-Event-ID"
+
+// ErrStreamingUnsupported is returned by NewStream for response writers
+// that cannot flush
+var ErrStreamingUnsupported = errors.New("sse: response writer does not support flushing")
+
+// Event is one message of a stream
+type Event struct {
+	// ID becomes the client's last event ID; empty leaves it unchanged
+	ID string
+	// Type names the event; empty is dispatched as "message"
+	Type string
+	// Data is the payload. Each of its lines is sent as a data field.
+	Data []byte
+}
+
+// Stream writes events to a response
+type Stream struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+// NewStream starts an event stream on w. It sends the response header at
+// once, along with the reconnection delay clients should wait for when
+// the stream ends, unless retry is 0.
+func NewStream(w http.ResponseWriter, retry time.Duration) (*Stream, error) {
+	controller := http.NewResponseController(w)
+	// Writers that cannot set deadlines, such as test recorders, have none
+	// to lift
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+	if err := controller.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", ContentType)
+	h.Set("Cache-Control", "no-cache")
+	// Proxies such as nginx would otherwise buffer the stream
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	s := &Stream{w: w, controller: controller}
+	if retry > 0 {
+		if _, err := w.Write([]byte("retry: " + strconv.FormatInt(retry.Milliseconds(), 10) + "\n\n")); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.flush(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Send writes event and flushes it to the client
+func (s *Stream) Send(event Event) error {
+	var b strings.Builder
+	if event.ID != "" {
+		b.WriteString("id: " + singleLine(event.ID) + "\n")
+	}
+	if event.Type != "" {
+		b.WriteString("event: " + singleLine(event.Type) + "\n")
+	}
+	for _, line := range strings.Split(string(event.Data), "\n") {
+		b.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+	}
+	b.WriteString("\n")
+	if _, err := s.w.Write([]byte(b.String())); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// Comment writes a comment, which clients ignore. Sending one periodically
+// keeps idle connections from being closed by proxies.
+func (s *Stream) Comment(text string) error {
+	if _, err := s.w.Write([]byte(": " + singleLine(text) + "\n\n")); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *Stream) flush() error {
+	if err := s.controller.Flush(); err != nil {
+		if errors.Is(err, http.ErrNotSupported) {
+			return ErrStreamingUnsupported
+		}
+		return err
+	}
+	return nil
+}
+
+// singleLine drops line breaks, which would end a field early
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,55 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	stream, err := NewStream(w, 3*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(Event{ID: "evt_1", Type: "user.created", Data: []byte(`{"id":"evt_1"}`)}))
+	require.NoError(t, stream.Comment("keepalive"))
+	require.NoError(t, stream.Send(Event{Data: []byte("first\r\nsecond")}))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+	assert.True(t, w.Flushed)
+	assert.Equal(t, "retry: 3000\n\n"+
+		"id: evt_1\nevent: user.created\ndata: {\"id\":\"evt_1\"}\n\n"+
+		": keepalive\n\n"+
+		"data: first\ndata: second\n\n", w.Body.String())
+}
+
+func TestStream_FieldsStaySingleLine(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	stream, err := NewStream(w, 0)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(Event{ID: "evt_1\nevent: forged", Type: "user.created\r\n", Data: []byte("{}")}))
+
+	assert.Equal(t, "id: evt_1event: forged\nevent: user.created\ndata: {}\n\n", w.Body.String())
+}
+
+// plainWriter is a response writer without flushing
+type plainWriter struct {
+	header http.Header
+}
+
+func (w *plainWriter) Header() http.Header         { return w.header }
+func (w *plainWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *plainWriter) WriteHeader(int)             {}
+
+func TestNewStream_RequiresFlushing(t *testing.T) {
+	_, err := NewStream(&plainWriter{header: http.Header{}}, 0)
+
+	assert.ErrorIs(t, err, ErrStreamingUnsupported)
+}
@@ -0,0 +1,252 @@
+// Package startup records how an application was assembled at startup:
+// each component initialized, how long it took and which components it was
+// built from. The report is logged as a summary and can be served as JSON
+// or as a Graphviz graph, to diagnose slow boots as subsystems are added.
+package startup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/logger"
+)
+
+// slowestLogged is the number of components named in the logged summary
+const slowestLogged = 5
+
+// Component is one initialized component
+type Component struct {
+	Name string `json:"name"`
+	// DependsOn names the components this one was built from
+	DependsOn []string      `json:"depends_on,omitempty"`
+	Started   time.Time     `json:"started"`
+	Duration  time.Duration `json:"duration_ns"`
+}
+
+// Report collects the components initialized during one startup. They are
+// initialized one after the other: Begin ends the component before it. It
+// is safe for concurrent use.
+type Report struct {
+	Started    time.Time     `json:"started"`
+	Duration   time.Duration `json:"duration_ns"`
+	Components []Component   `json:"components"`
+
+	mu       sync.Mutex
+	current  int // index of the component being initialized, or -1
+	finished bool
+	now      func() time.Time
+}
+
+// NewReport starts a report of a startup beginning now
+func NewReport() *Report {
+	return &Report{Started: time.Now(), Components: []Component{}, current: -1, now: time.Now}
+}
+
+// Begin ends the component being initialized, if any, and starts timing
+// name, which is built from the components dependsOn. Dependencies on
+// components that were never initialized, such as disabled features, are
+// kept but do not count toward the critical path.
+func (r *Report) Begin(name string, dependsOn ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	r.endLocked(now)
+	r.Components = append(r.Components, Component{Name: name, DependsOn: dependsOn, Started: now})
+	r.current = len(r.Components) - 1
+}
+
+// End ends the component being initialized
+func (r *Report) End() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.endLocked(r.now())
+}
+
+func (r *Report) endLocked(now time.Time) {
+	if r.current < 0 {
+		return
+	}
+	c := &r.Components[r.current]
+	c.Duration = now.Sub(c.Started)
+	r.current = -1
+}
+
+// Finish ends the component being initialized and records the total
+// duration of the startup
+func (r *Report) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	r.endLocked(now)
+	r.Duration = now.Sub(r.Started)
+	r.finished = true
+}
+
+// Slowest returns the n components that took longest, slowest first
+func (r *Report) Slowest(n int) []Component {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.slowestLocked(n)
+}
+
+func (r *Report) slowestLocked(n int) []Component {
+	slowest := append([]Component(nil), r.Components...)
+	sort.SliceStable(slowest, func(i, j int) bool { return slowest[i].Duration > slowest[j].Duration })
+	if len(slowest) > n {
+		slowest = slowest[:n]
+	}
+	return slowest
+}
+
+// CriticalPath returns the chain of components, each built from the one
+// before it, whose durations add up to the most, and that sum. It is how
+// long startup would take at least if components not depending on each
+// other were initialized concurrently.
+func (r *Report) CriticalPath() ([]string, time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.criticalPathLocked()
+}
+
+func (r *Report) criticalPathLocked() ([]string, time.Duration) {
+	// Components only depend on those initialized before them, so one pass
+	// in order finds the longest chain ending at each
+	index := make(map[string]int, len(r.Components))
+	total := make([]time.Duration, len(r.Components))
+	prev := make([]int, len(r.Components))
+	last := -1
+	for i, c := range r.Components {
+		prev[i] = -1
+		for _, dep := range c.DependsOn {
+			if j, ok := index[dep]; ok && (prev[i] < 0 || total[j] > total[prev[i]]) {
+				prev[i] = j
+			}
+		}
+		total[i] = c.Duration
+		if prev[i] >= 0 {
+			total[i] += total[prev[i]]
+		}
+		index[c.Name] = i
+		if last < 0 || total[i] > total[last] {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, 0
+	}
+
+	var path []string
+	for i := last; i >= 0; i = prev[i] {
+		path = append(path, r.Components[i].Name)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, total[last]
+}
+
+// untrackedLocked returns the time of the startup spent outside of any
+// component
+func (r *Report) untrackedLocked() time.Duration {
+	tracked := time.Duration(0)
+	for _, c := range r.Components {
+		tracked += c.Duration
+	}
+	return max(r.Duration-tracked, 0)
+}
+
+// MarshalJSON encodes the report with its critical path
+func (r *Report) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path, pathDuration := r.criticalPathLocked()
+	return json.Marshal(struct {
+		Started              time.Time     `json:"started"`
+		Duration             time.Duration `json:"duration_ns"`
+		Finished             bool          `json:"finished"`
+		Untracked            time.Duration `json:"untracked_ns"`
+		CriticalPath         []string      `json:"critical_path"`
+		CriticalPathDuration time.Duration `json:"critical_path_duration_ns"`
+		Components           []Component   `json:"components"`
+	}{
+		Started:              r.Started,
+		Duration:             r.Duration,
+		Finished:             r.finished,
+		Untracked:            r.untrackedLocked(),
+		CriticalPath:         path,
+		CriticalPathDuration: pathDuration,
+		Components:           r.Components,
+	})
+}
+
+// Log writes one debug entry per component followed by a summary naming
+// the slowest components and the critical path
+func (r *Report) Log(log logger.Logger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.Components {
+		log.WithFields(map[string]interface{}{
+			"component":   c.Name,
+			"depends_on":  c.DependsOn,
+			"duration_ms": c.Duration.Milliseconds(),
+		}).Debug("Startup component initialized")
+	}
+
+	slowest := make([]string, 0, slowestLogged)
+	for _, c := range r.slowestLocked(slowestLogged) {
+		slowest = append(slowest, fmt.Sprintf("%s=%dms", c.Name, c.Duration.Milliseconds()))
+	}
+	path, pathDuration := r.criticalPathLocked()
+	log.WithFields(map[string]interface{}{
+		"duration_ms":               r.Duration.Milliseconds(),
+		"components":                len(r.Components),
+		"untracked_ms":              r.untrackedLocked().Milliseconds(),
+		"slowest":                   slowest,
+		"critical_path":             strings.Join(path, " -> "),
+		"critical_path_duration_ms": pathDuration.Milliseconds(),
+	}).Info("Startup report")
+}
+
+// WriteDOT writes the dependency graph in the Graphviz DOT language, each
+// component labelled with its duration and the critical path in bold
+func (r *Report) WriteDOT(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path, _ := r.criticalPathLocked()
+	critical := make(map[string]bool, len(path))
+	for _, name := range path {
+		critical[name] = true
+	}
+	initialized := make(map[string]bool, len(r.Components))
+	for _, c := range r.Components {
+		initialized[c.Name] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph startup {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, c := range r.Components {
+		style := ""
+		if critical[c.Name] {
+			style = ", style=bold"
+		}
+		fmt.Fprintf(&b, "\t%q [label=%q%s];\n", c.Name, fmt.Sprintf("%s\n%s", c.Name, c.Duration.Round(time.Microsecond)), style)
+	}
+	for _, c := range r.Components {
+		for _, dep := range c.DependsOn {
+			if !initialized[dep] {
+				continue
+			}
+			fmt.Fprintf(&b, "\t%q -> %q;\n", dep, c.Name)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
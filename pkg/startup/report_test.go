@@ -0,0 +1,112 @@
+package startup
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/logger"
+)
+
+// newTestReport returns a report whose clock advances by the durations
+// given, one per reading after the start
+func newTestReport(steps ...time.Duration) *Report {
+	report := NewReport()
+	clock := report.Started
+	report.now = func() time.Time {
+		if len(steps) > 0 {
+			clock = clock.Add(steps[0])
+			steps = steps[1:]
+		}
+		return clock
+	}
+	return report
+}
+
+func TestReport(t *testing.T) {
+	report := newTestReport(0, 3*time.Second, time.Second, 2*time.Second, 4*time.Second, time.Second)
+	report.Begin("database")
+	report.Begin("migrations", "database")
+	report.Begin("redis")
+	report.Begin("sessions", "redis", "ldap")
+	report.End()
+	report.Finish()
+
+	require.Len(t, report.Components, 4)
+	assert.Equal(t, 3*time.Second, report.Components[0].Duration)
+	assert.Equal(t, time.Second, report.Components[1].Duration)
+	assert.Equal(t, 2*time.Second, report.Components[2].Duration)
+	assert.Equal(t, 4*time.Second, report.Components[3].Duration)
+	assert.Equal(t, 11*time.Second, report.Duration)
+
+	path, duration := report.CriticalPath()
+	assert.Equal(t, []string{"redis", "sessions"}, path, "ldap was never initialized")
+	assert.Equal(t, 6*time.Second, duration)
+
+	slowest := report.Slowest(2)
+	require.Len(t, slowest, 2)
+	assert.Equal(t, "sessions", slowest[0].Name)
+	assert.Equal(t, "database", slowest[1].Name)
+	assert.Len(t, report.Slowest(10), 4)
+
+	report.Log(logger.New())
+}
+
+func TestReport_Empty(t *testing.T) {
+	report := NewReport()
+	path, duration := report.CriticalPath()
+	assert.Nil(t, path)
+	assert.Zero(t, duration)
+	report.End()
+	report.Finish()
+	assert.Empty(t, report.Components)
+}
+
+func TestReport_MarshalJSON(t *testing.T) {
+	report := newTestReport(0, time.Second, 2*time.Second, time.Second)
+	report.Begin("broker")
+	report.Begin("event consumer", "broker")
+	report.End()
+	report.Finish()
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	var decoded struct {
+		Finished             bool          `json:"finished"`
+		Duration             time.Duration `json:"duration_ns"`
+		Untracked            time.Duration `json:"untracked_ns"`
+		CriticalPath         []string      `json:"critical_path"`
+		CriticalPathDuration time.Duration `json:"critical_path_duration_ns"`
+		Components           []Component   `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, decoded.Finished)
+	assert.Equal(t, 4*time.Second, decoded.Duration)
+	assert.Equal(t, time.Second, decoded.Untracked, "time between End and Finish belongs to no component")
+	assert.Equal(t, []string{"broker", "event consumer"}, decoded.CriticalPath)
+	assert.Equal(t, 3*time.Second, decoded.CriticalPathDuration)
+	require.Len(t, decoded.Components, 2)
+	assert.Equal(t, []string{"broker"}, decoded.Components[1].DependsOn)
+}
+
+func TestReport_WriteDOT(t *testing.T) {
+	report := newTestReport(0, time.Second, 2*time.Second, time.Millisecond)
+	report.Begin("database")
+	report.Begin("users", "database", "policy engine")
+	report.Begin("admin ui")
+	report.Finish()
+
+	var b strings.Builder
+	require.NoError(t, report.WriteDOT(&b))
+	dot := b.String()
+	assert.True(t, strings.HasPrefix(dot, "digraph startup {"))
+	assert.Contains(t, dot, `"database" [label="database\n1s", style=bold];`)
+	assert.Contains(t, dot, `"users" [label="users\n2s", style=bold];`)
+	assert.Contains(t, dot, `"admin ui" [label="admin ui\n1ms"];`)
+	assert.Contains(t, dot, `"database" -> "users";`)
+	assert.NotContains(t, dot, "policy engine", "edges to components never initialized are left out")
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Local stores objects as files below a directory. Its signed URLs point at
+// baseURL, where the Local itself must be mounted as an http.Handler to
+// serve them.
+type Local struct {
+	signer
+	dir string
+}
+
+// NewLocal creates a filesystem store rooted at dir, creating it if needed.
+// Download URLs are signed with signingKey, which every instance serving
+// them must share.
+func NewLocal(dir, baseURL string, signingKey []byte) (*Local, error) {
+	signer, err := newSigner(baseURL, signingKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("storage: create %s: %w", dir, err)
+	}
+	return &Local{signer: signer, dir: dir}, nil
+}
+
+// Put writes the object to a temporary file and renames it into place, so
+// readers never observe a partial object
+func (l *Local) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	target, err := l.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, contextReader{ctx: ctx, r: r})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, os.Rename(tmp.Name(), target)
+}
+
+// Open returns a reader for the object stored under key
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	target, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// Delete removes the object stored under key
+func (l *Local) Delete(ctx context.Context, key string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL returns a download URL for key that expires at expiresAt
+func (l *Local) SignedURL(ctx context.Context, key string, expiresAt time.Time) (string, error) {
+	return l.signedURL(key, expiresAt)
+}
+
+// ServeHTTP serves objects to requests carrying a valid, unexpired
+// signature
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, ok := l.verify(w, r)
+	if !ok {
+		return
+	}
+
+	f, err := os.Open(filepath.Join(l.dir, filepath.FromSlash(key)))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	serveObject(w, r, key, info.ModTime(), f)
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+// contextReader stops reading once ctx is done, so cancelled uploads do not
+// keep copying
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLocal(t *testing.T) *Local {
+	t.Helper()
+	store, err := NewLocal(t.TempDir(), "http://localhost:8080/api/v1/downloads", []byte("secret"))
+	require.NoError(t, err)
+	return store
+}
+
+func TestLocal_PutOpenDelete(t *testing.T) {
+	store := newLocal(t)
+	ctx := context.Background()
+
+	n, err := store.Put(ctx, "exports/job_1.csv", strings.NewReader("id,email\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), n)
+
+	r, err := store.Open(ctx, "exports/job_1.csv")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "id,email\n", string(data))
+
+	require.NoError(t, store.Delete(ctx, "exports/job_1.csv"))
+	require.NoError(t, store.Delete(ctx, "exports/job_1.csv"), "deleting a missing object is not an error")
+
+	_, err = store.Open(ctx, "exports/job_1.csv")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocal_RejectsEscapingKeys(t *testing.T) {
+	store := newLocal(t)
+	for _, key := range []string{"", "/etc/passwd", "../secret", "exports/../../secret", "exports//x"} {
+		_, err := store.Put(context.Background(), key, strings.NewReader("x"))
+		assert.Error(t, err, key)
+	}
+}
+
+func TestLocal_SignedURL(t *testing.T) {
+	store := newLocal(t)
+	ctx := context.Background()
+	_, err := store.Put(ctx, "exports/job_1.csv", strings.NewReader("id,email\n"))
+	require.NoError(t, err)
+
+	signed, err := store.SignedURL(ctx, "exports/job_1.csv", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/downloads/exports/job_1.csv", u.Path)
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		store.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		w := serve(u.RequestURI())
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "id,email\n", w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "job_1.csv")
+	})
+
+	t.Run("tampered key", func(t *testing.T) {
+		w := serve("/api/v1/downloads/exports/job_2.csv?" + u.RawQuery)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		store.now = func() time.Time { return time.Now().Add(time.Hour) }
+		defer func() { store.now = time.Now }()
+
+		w := serve(u.RequestURI())
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrFull is returned when an object does not fit in a Memory store
+var ErrFull = errors.New("storage: memory store is full")
+
+// Memory keeps objects in memory, for deployments without persistent
+// storage. Objects are lost on restart and are not shared between
+// instances, so their signed URLs only work against the instance that
+// issued them. Like Local, it must be mounted at baseURL to serve them.
+type Memory struct {
+	signer
+	maxSize int64
+
+	mu      sync.RWMutex
+	size    int64
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemory creates an in-memory store holding at most maxSize bytes
+func NewMemory(baseURL string, signingKey []byte, maxSize int64) (*Memory, error) {
+	signer, err := newSigner(baseURL, signingKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Memory{signer: signer, maxSize: maxSize, objects: make(map[string]memoryObject)}, nil
+}
+
+// Put reads the object into memory, failing with ErrFull rather than
+// reading more than the store has room for
+func (m *Memory) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	if err := ValidateKey(key); err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(contextReader{ctx: ctx, r: r}, m.maxSize+1))
+	if err != nil {
+		return n, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	size := m.size + n - int64(len(m.objects[key].data))
+	if size > m.maxSize {
+		return n, ErrFull
+	}
+	m.objects[key] = memoryObject{data: buf.Bytes(), modTime: m.now()}
+	m.size = size
+	return n, nil
+}
+
+// Open returns a reader for the object stored under key
+func (m *Memory) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	object, ok := m.object(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(object.data)), nil
+}
+
+// Delete removes the object stored under key
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.size -= int64(len(m.objects[key].data))
+	delete(m.objects, key)
+	return nil
+}
+
+// SignedURL returns a download URL for key that expires at expiresAt
+func (m *Memory) SignedURL(ctx context.Context, key string, expiresAt time.Time) (string, error) {
+	return m.signedURL(key, expiresAt)
+}
+
+// ServeHTTP serves objects to requests carrying a valid, unexpired
+// signature
+func (m *Memory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, ok := m.verify(w, r)
+	if !ok {
+		return
+	}
+	object, ok := m.object(key)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	serveObject(w, r, key, object.modTime, bytes.NewReader(object.data))
+}
+
+func (m *Memory) object(key string) (memoryObject, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	object, ok := m.objects[key]
+	return object, ok
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMemory(t *testing.T, maxSize int64) *Memory {
+	t.Helper()
+	store, err := NewMemory("http://localhost:8080/api/v1/downloads", []byte("secret"), maxSize)
+	require.NoError(t, err)
+	return store
+}
+
+func TestMemory_PutOpenDelete(t *testing.T) {
+	store := newMemory(t, 1024)
+	ctx := context.Background()
+
+	n, err := store.Put(ctx, "exports/job_1.csv", strings.NewReader("id,email\n"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), n)
+
+	r, err := store.Open(ctx, "exports/job_1.csv")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "id,email\n", string(data))
+
+	require.NoError(t, store.Delete(ctx, "exports/job_1.csv"))
+	require.NoError(t, store.Delete(ctx, "exports/job_1.csv"), "deleting a missing object is not an error")
+
+	_, err = store.Open(ctx, "exports/job_1.csv")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = store.Put(ctx, "../secret", strings.NewReader("x"))
+	assert.Error(t, err)
+}
+
+func TestMemory_MaxSize(t *testing.T) {
+	store := newMemory(t, 10)
+	ctx := context.Background()
+
+	_, err := store.Put(ctx, "a", strings.NewReader("12345678"))
+	require.NoError(t, err)
+	_, err = store.Put(ctx, "b", strings.NewReader("123"))
+	assert.ErrorIs(t, err, ErrFull)
+	_, err = store.Open(ctx, "b")
+	assert.ErrorIs(t, err, ErrNotFound, "objects that do not fit are not stored")
+
+	_, err = store.Put(ctx, "a", strings.NewReader("1234567890"))
+	require.NoError(t, err, "replacing an object frees its space")
+
+	require.NoError(t, store.Delete(ctx, "a"))
+	_, err = store.Put(ctx, "b", strings.NewReader("1234567890"))
+	require.NoError(t, err, "deleting an object frees its space")
+
+	_, err = store.Put(ctx, "c", strings.NewReader(strings.Repeat("x", 64)))
+	assert.ErrorIs(t, err, ErrFull)
+}
+
+func TestMemory_SignedURL(t *testing.T) {
+	store := newMemory(t, 1024)
+	ctx := context.Background()
+	_, err := store.Put(ctx, "exports/job_1.csv", strings.NewReader("id,email\n"))
+	require.NoError(t, err)
+
+	signed, err := store.SignedURL(ctx, "exports/job_1.csv", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		store.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := serve(u.RequestURI())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "id,email\n", w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "job_1.csv")
+
+	w = serve("/api/v1/downloads/exports/job_2.csv?" + u.RawQuery)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	require.NoError(t, store.Delete(ctx, "exports/job_1.csv"))
+	w = serve(u.RequestURI())
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
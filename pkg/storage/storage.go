@@ -0,0 +1,41 @@
+// Package storage stores binary objects, such as export artifacts, behind a
+// driver-agnostic interface and issues time-limited download URLs for them.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage stores objects under slash-separated keys
+type Storage interface {
+	// Put stores everything read from r under key, replacing any existing
+	// object, and returns the number of bytes written. Only the Memory
+	// driver buffers readers, so objects may otherwise be arbitrarily large.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open returns a reader for the object stored under key
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key. Deleting a missing object
+	// is not an error.
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that downloads the object without further
+	// credentials until expiresAt
+	SignedURL(ctx context.Context, key string, expiresAt time.Time) (string, error)
+}
+
+// ValidateKey checks that key is a clean relative path that cannot escape
+// the storage root
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("storage: invalid key %q", key)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+// Package timing records where the time of a request goes. A Recorder
+// travels in the request context and each layer wraps its work in a span,
+// so a slow request can be broken down while it is still running.
+package timing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/ctxkeys"
+)
+
+// Layers spans are recorded for
+const (
+	// LayerHandler is the HTTP handler serving the request; the time
+	// before its span starts is spent in middleware
+	LayerHandler    = "handler"
+	LayerUseCase    = "usecase"
+	LayerRepository = "repository"
+)
+
+// maxSpans bounds the spans a Recorder keeps, so a request running a query
+// per row does not grow without bound; later spans only count towards the
+// layer totals
+const maxSpans = 256
+
+var recorderKey = ctxkeys.NewKey[*Recorder]("timing_recorder")
+
+// Span is a unit of work of a request
+type Span struct {
+	Layer string
+	Name  string
+	// Detail describes the work, such as the SQL of a query
+	Detail string
+	// Offset is when the span started, relative to the recorder
+	Offset   time.Duration
+	Duration time.Duration
+	// Active is set for spans still running, whose Duration is the time so
+	// far
+	Active bool
+}
+
+// String formats the span for logs
+func (s Span) String() string {
+	text := fmt.Sprintf("+%s %s %s %s", s.Offset.Round(time.Microsecond), s.Layer, s.Name, s.Duration.Round(time.Microsecond))
+	if s.Active {
+		text += " (active)"
+	}
+	if s.Detail != "" {
+		text += ": " + s.Detail
+	}
+	return text
+}
+
+// Recorder collects the spans of one request. It is safe for concurrent
+// use, so goroutines started by a handler may record spans too.
+type Recorder struct {
+	start time.Time
+
+	mu      sync.Mutex
+	spans   []*Span
+	totals  map[string]time.Duration
+	dropped int
+}
+
+// NewRecorder creates a recorder whose span offsets count from now
+func NewRecorder() *Recorder {
+	return &Recorder{start: time.Now(), totals: make(map[string]time.Duration)}
+}
+
+// WithRecorder returns a copy of ctx carrying rec
+func WithRecorder(ctx context.Context, rec *Recorder) context.Context {
+	return recorderKey.With(ctx, rec)
+}
+
+// FromContext returns the recorder carried by ctx, or nil
+func FromContext(ctx context.Context) *Recorder {
+	return recorderKey.Value(ctx)
+}
+
+// Start begins a span in the recorder carried by ctx. Without one the
+// returned timer does nothing, so instrumented code needs no checks:
+//
+//	defer timing.Start(ctx, timing.LayerUseCase, "UserUseCase.GetUserByID").End()
+func Start(ctx context.Context, layer, name string) *Timer {
+	rec := FromContext(ctx)
+	if rec == nil {
+		return nil
+	}
+	return rec.Start(layer, name)
+}
+
+// Start begins a span of layer
+func (r *Recorder) Start(layer, name string) *Timer {
+	now := time.Now()
+	timer := &Timer{recorder: r, layer: layer, start: now}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.spans) < maxSpans {
+		timer.span = &Span{Layer: layer, Name: name, Offset: now.Sub(r.start), Active: true}
+		r.spans = append(r.spans, timer.span)
+	} else {
+		r.dropped++
+	}
+	return timer
+}
+
+// Elapsed returns the time since the recorder was created
+func (r *Recorder) Elapsed() time.Duration {
+	return time.Since(r.start)
+}
+
+// Spans returns a snapshot of the spans in the order they started
+func (r *Recorder) Spans() []Span {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spans := make([]Span, len(r.spans))
+	for i, span := range r.spans {
+		spans[i] = *span
+		if span.Active {
+			spans[i].Duration = now.Sub(r.start) - span.Offset
+		}
+	}
+	return spans
+}
+
+// Dropped returns the number of spans started beyond the recorder's limit
+func (r *Recorder) Dropped() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dropped
+}
+
+// Layers returns the time spent in each layer by ended spans. Spans nested
+// in one layer are counted once per span.
+func (r *Recorder) Layers() map[string]time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	totals := make(map[string]time.Duration, len(r.totals))
+	for layer, total := range r.totals {
+		totals[layer] = total
+	}
+	return totals
+}
+
+// Breakdown is where the time of a request went so far
+type Breakdown struct {
+	Total time.Duration
+	// Middleware is the time before the first handler span started, or
+	// all of it when no handler span was recorded
+	Middleware time.Duration
+	// Handler is the time since the first handler span started, or its
+	// duration once it ended; it includes UseCase and Repository
+	Handler time.Duration
+	// UseCase and Repository are the layer totals of ended spans
+	UseCase    time.Duration
+	Repository time.Duration
+}
+
+// Breakdown returns where the time of the request went so far
+func (r *Recorder) Breakdown() Breakdown {
+	elapsed := time.Since(r.start)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := Breakdown{
+		Total:      elapsed,
+		Middleware: elapsed,
+		UseCase:    r.totals[LayerUseCase],
+		Repository: r.totals[LayerRepository],
+	}
+	for _, span := range r.spans {
+		if span.Layer != LayerHandler {
+			continue
+		}
+		b.Middleware = span.Offset
+		b.Handler = span.Duration
+		if span.Active {
+			b.Handler = elapsed - span.Offset
+		}
+		break
+	}
+	return b
+}
+
+// Timer ends a span. A nil Timer does nothing.
+type Timer struct {
+	recorder *Recorder
+	layer    string
+	start    time.Time
+	// span is nil once the recorder is full
+	span  *Span
+	ended bool
+}
+
+// Describe sets the detail of the span
+func (t *Timer) Describe(detail string) {
+	if t == nil || t.span == nil {
+		return
+	}
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+	t.span.Detail = detail
+}
+
+// End ends the span; later calls do nothing
+func (t *Timer) End() {
+	if t == nil {
+		return
+	}
+	duration := time.Since(t.start)
+
+	r := t.recorder
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t.ended {
+		return
+	}
+	t.ended = true
+	if t.span != nil {
+		t.span.Active = false
+		t.span.Duration = duration
+	}
+	r.totals[t.layer] += duration
+}
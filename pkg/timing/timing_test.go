@@ -0,0 +1,98 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart_WithoutRecorder(t *testing.T) {
+	timer := Start(context.Background(), LayerUseCase, "UserUseCase.GetUserByID")
+	assert.Nil(t, timer)
+
+	// A nil timer is safe to use
+	timer.Describe("detail")
+	timer.End()
+}
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder()
+	ctx := WithRecorder(context.Background(), rec)
+	require.Same(t, rec, FromContext(ctx))
+
+	usecase := Start(ctx, LayerUseCase, "UserUseCase.ListUsers")
+	query := Start(ctx, LayerRepository, "SELECT users")
+	query.Describe(`SELECT * FROM "users" LIMIT 10`)
+	time.Sleep(5 * time.Millisecond)
+	query.End()
+
+	spans := rec.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "UserUseCase.ListUsers", spans[0].Name)
+	assert.True(t, spans[0].Active)
+	assert.GreaterOrEqual(t, spans[0].Duration, 5*time.Millisecond, "active spans report the time so far")
+	assert.Equal(t, LayerRepository, spans[1].Layer)
+	assert.False(t, spans[1].Active)
+	assert.Equal(t, `SELECT * FROM "users" LIMIT 10`, spans[1].Detail)
+	assert.GreaterOrEqual(t, spans[1].Duration, 5*time.Millisecond)
+
+	layers := rec.Layers()
+	assert.NotContains(t, layers, LayerUseCase, "active spans are not totalled")
+	assert.Equal(t, spans[1].Duration, layers[LayerRepository])
+
+	usecase.End()
+	usecase.End()
+	assert.Equal(t, rec.Spans()[0].Duration, rec.Layers()[LayerUseCase], "ending twice counts once")
+}
+
+func TestRecorder_Breakdown(t *testing.T) {
+	rec := NewRecorder()
+	time.Sleep(2 * time.Millisecond)
+	b := rec.Breakdown()
+	assert.Equal(t, b.Total, b.Middleware, "without a handler span all the time is middleware")
+	assert.Zero(t, b.Handler)
+
+	handler := rec.Start(LayerHandler, "users:list")
+	usecase := rec.Start(LayerUseCase, "UserUseCase.ListUsers")
+	rec.Start(LayerRepository, "query users").End()
+	usecase.End()
+
+	b = rec.Breakdown()
+	assert.GreaterOrEqual(t, b.Middleware, 2*time.Millisecond)
+	assert.Less(t, b.Middleware, b.Total)
+	assert.Equal(t, b.Total, b.Middleware+b.Handler, "the active handler span runs until now")
+	assert.GreaterOrEqual(t, b.Handler, b.UseCase)
+	assert.GreaterOrEqual(t, b.UseCase, b.Repository)
+	assert.NotZero(t, b.Repository)
+
+	handler.End()
+	ended := rec.Breakdown().Handler
+	time.Sleep(time.Millisecond)
+	assert.Equal(t, ended, rec.Breakdown().Handler, "an ended handler span keeps its duration")
+}
+
+func TestRecorder_MaxSpans(t *testing.T) {
+	rec := NewRecorder()
+	for i := 0; i < maxSpans+3; i++ {
+		rec.Start(LayerRepository, "SELECT users").End()
+	}
+
+	assert.Len(t, rec.Spans(), maxSpans)
+	assert.Equal(t, 3, rec.Dropped())
+	assert.Contains(t, rec.Layers(), LayerRepository)
+}
+
+func TestSpan_String(t *testing.T) {
+	span := Span{
+		Layer:    LayerRepository,
+		Name:     "SELECT users",
+		Detail:   `SELECT * FROM "users"`,
+		Offset:   1500 * time.Microsecond,
+		Duration: 2 * time.Second,
+		Active:   true,
+	}
+	assert.Equal(t, `+1.5ms repository SELECT users 2s (active): SELECT * FROM "users"`, span.String())
+}
@@ -0,0 +1,77 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 secret of the RFC 6238 test vectors
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode_RFC6238(t *testing.T) {
+	// The RFC lists 8 digit codes; these are their last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.code, Code(rfcSecret, Step(time.Unix(tt.unix, 0))), "at %d", tt.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := Step(now)
+
+	got, ok := Validate(rfcSecret, Code(rfcSecret, step), now, 1)
+	assert.True(t, ok)
+	assert.Equal(t, step, got)
+
+	got, ok = Validate(rfcSecret, Code(rfcSecret, step-1), now, 1)
+	assert.True(t, ok, "the previous code is accepted for clock drift")
+	assert.Equal(t, step-1, got)
+
+	_, ok = Validate(rfcSecret, Code(rfcSecret, step-2), now, 1)
+	assert.False(t, ok)
+	_, ok = Validate(rfcSecret, Code(rfcSecret, step+1), now, 0)
+	assert.False(t, ok)
+	_, ok = Validate(rfcSecret, "", now, 1)
+	assert.False(t, ok)
+	_, ok = Validate(rfcSecret, "05924", now, 1)
+	assert.False(t, ok)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	require.NoError(t, err)
+	b, err := GenerateSecret()
+	require.NoError(t, err)
+
+	assert.Len(t, a, SecretSize)
+	assert.NotEqual(t, a, b)
+	assert.Len(t, EncodeSecret(a), 32, "160 bits are 32 base32 characters")
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("Acme Corp", "bjensen@example.com", rfcSecret))
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Acme Corp:bjensen@example.com", uri.Path)
+	assert.Equal(t, "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", uri.Query().Get("secret"))
+	assert.Equal(t, "Acme Corp", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+	assert.Equal(t, "30", uri.Query().Get("period"))
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Envelope versions. Version 1 is the original envelope of status,
+// message, data and timestamp, which clients get unless they ask for
+// another. Version 2 adds the version field itself and a meta object with
+// the pagination of list responses.
+const (
+	EnvelopeV1 = 1
+	EnvelopeV2 = 2
+	// LatestEnvelope is the newest version clients can ask for
+	LatestEnvelope = EnvelopeV2
+)
+
+// EnvelopeHeader is the request header clients ask for an envelope version
+// with, e.g. X-Envelope-Version: 2. Responses carry it too, with the
+// version they are written in.
+const EnvelopeHeader = "X-Envelope-Version"
+
+// EnvelopeVersion returns the envelope version r asks for. Requests asking
+// for none, or for a malformed one, get version 1; versions newer than the
+// server knows get the latest, so clients may ask for one ahead of a
+// deployment.
+func EnvelopeVersion(r *http.Request) int {
+	version, err := strconv.Atoi(strings.TrimSpace(r.Header.Get(EnvelopeHeader)))
+	switch {
+	case err != nil || version < EnvelopeV1:
+		return EnvelopeV1
+	case version > LatestEnvelope:
+		return LatestEnvelope
+	}
+	return version
+}
+
+// Meta carries the details added by version 2 envelopes
+type Meta struct {
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+// Pagination describes the page of a list a response holds
+type Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	// Count is the number of items on this page
+	Count int `json:"count"`
+	// Total is the number of items in the whole list, if known
+	Total *int `json:"total,omitempty"`
+	// NextOffset is the offset of the next page, nil on the last one.
+	// Without a total, a full page is taken to have a next one, which may
+	// turn out empty.
+	NextOffset *int `json:"next_offset,omitempty"`
+}
+
+// NewPagination describes a page of count items, requested with limit and
+// offset
+func NewPagination(limit, offset, count int) *Pagination {
+	p := &Pagination{Limit: limit, Offset: offset, Count: count}
+	if count > 0 && count >= limit {
+		next := offset + count
+		p.NextOffset = &next
+	}
+	return p
+}
+
+// WithTotal sets the size of the whole list, which decides whether there
+// is a next page
+func (p *Pagination) WithTotal(total int) *Pagination {
+	p.Total = &total
+	p.NextOffset = nil
+	if next := p.Offset + p.Count; p.Count > 0 && next < total {
+		p.NextOffset = &next
+	}
+	return p
+}
+
+// Versioned returns the response as written in the given envelope version.
+// Version 1 drops every field added since, so existing clients see the
+// envelope they were written against.
+func (r APIResponse) Versioned(version int) APIResponse {
+	if version < EnvelopeV2 {
+		r.Version = 0
+		r.Meta = nil
+		return r
+	}
+	r.Version = version
+	return r
+}
+
+// WriteErrorFor writes an error JSON response in the envelope version the
+// request asks for
+func WriteErrorFor(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	version := EnvelopeVersion(r)
+	w.Header().Set(EnvelopeHeader, strconv.Itoa(version))
+	WriteJSON(w, statusCode, ErrorResponse(message).Versioned(version))
+}
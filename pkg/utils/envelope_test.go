@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeVersion(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected int
+	}{
+		{"", EnvelopeV1},
+		{"1", EnvelopeV1},
+		{" 2 ", EnvelopeV2},
+		{"99", LatestEnvelope},
+		{"0", EnvelopeV1},
+		{"two", EnvelopeV1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(EnvelopeHeader, tt.header)
+			}
+			assert.Equal(t, tt.expected, EnvelopeVersion(req))
+		})
+	}
+}
+
+func TestNewPagination(t *testing.T) {
+	tests := []struct {
+		name          string
+		pagination    *Pagination
+		expectedNext  *int
+		expectedTotal *int
+	}{
+		{"full page", NewPagination(10, 20, 10), intPtr(30), nil},
+		{"short page", NewPagination(10, 20, 4), nil, nil},
+		{"empty page", NewPagination(10, 0, 0), nil, nil},
+		{"full last page", NewPagination(10, 20, 10).WithTotal(30), nil, intPtr(30)},
+		{"full page with more", NewPagination(10, 0, 10).WithTotal(25), intPtr(10), intPtr(25)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedNext, tt.pagination.NextOffset)
+			assert.Equal(t, tt.expectedTotal, tt.pagination.Total)
+		})
+	}
+}
+
+func TestAPIResponse_Versioned(t *testing.T) {
+	response := SuccessResponse([]string{"a"}, "listed")
+	response.Meta = &Meta{Pagination: NewPagination(1, 0, 1)}
+
+	v1, err := json.Marshal(response.Versioned(EnvelopeV1))
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(v1, &fields))
+	assert.NotContains(t, fields, "version", "version 1 envelopes keep their original fields")
+	assert.NotContains(t, fields, "meta")
+
+	v2, err := json.Marshal(response.Versioned(EnvelopeV2))
+	require.NoError(t, err)
+	fields = nil
+	require.NoError(t, json.Unmarshal(v2, &fields))
+	assert.Equal(t, float64(EnvelopeV2), fields["version"])
+	assert.Equal(t, map[string]interface{}{
+		"pagination": map[string]interface{}{"limit": float64(1), "offset": float64(0), "count": float64(1), "next_offset": float64(1)},
+	}, fields["meta"])
+}
+
+func TestWriteErrorFor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(EnvelopeHeader, "2")
+	w := httptest.NewRecorder()
+
+	WriteErrorFor(w, req, http.StatusForbidden, "Forbidden")
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "2", w.Header().Get(EnvelopeHeader))
+	var response APIResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, EnvelopeV2, response.Version)
+	assert.Equal(t, "error", response.Status)
+	assert.Equal(t, "Forbidden", response.Message)
+}
+
+func intPtr(i int) *int { return &i }
@@ -6,11 +6,14 @@ import (
 	"time"
 )
 
-// APIResponse represents a standard API response
+// APIResponse represents a standard API response. Version and Meta are
+// only written in version 2 envelopes and later; see Versioned.
 type APIResponse struct {
+	Version   int         `json:"version,omitempty"`
 	Status    string      `json:"status"`
 	Message   string      `json:"message,omitempty"`
 	Data      interface{} `json:"data,omitempty"`
+	Meta      *Meta       `json:"meta,omitempty"`
 	Timestamp time.Time   `json:"timestamp"`
 }
 
@@ -0,0 +1,78 @@
+package webhooksig
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "whsec_test"
+
+var payload = []byte(`{"id":"evt_1","type":"user.created"}`)
+
+func TestSignAndVerify(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	header := Sign(secret, at, payload)
+
+	assert.True(t, strings.HasPrefix(header, "t=1700000000,v1="))
+	assert.NoError(t, Verify(secret, header, payload, at.Add(time.Minute), 0))
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	header := Sign(secret, at, payload)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		payload []byte
+		now     time.Time
+		want    error
+	}{
+		{"missing header", secret, "", payload, at, ErrMissingSignature},
+		{"tampered payload", secret, header, []byte(`{"id":"evt_2"}`), at, ErrInvalidSignature},
+		{"other secret", "whsec_other", header, payload, at, ErrInvalidSignature},
+		{"no timestamp", secret, header[strings.Index(header, ",")+1:], payload, at, ErrInvalidSignature},
+		{"malformed signature", secret, "t=1700000000,v1=zz", payload, at, ErrInvalidSignature},
+		{"too old", secret, header, payload, at.Add(DefaultTolerance + time.Second), ErrExpiredSignature},
+		{"from the future", secret, header, payload, at.Add(-DefaultTolerance - time.Second), ErrExpiredSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, Verify(tt.secret, tt.header, tt.payload, tt.now, 0), tt.want)
+		})
+	}
+}
+
+func TestVerify_RotatedSecrets(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	old := Sign("whsec_old", at, payload)
+	current := Sign(secret, at, payload)
+	header := current + "," + old[strings.Index(old, "v1="):]
+
+	assert.NoError(t, Verify(secret, header, payload, at, 0))
+	assert.NoError(t, Verify("whsec_old", header, payload, at, 0))
+}
+
+func TestVerifyRequest(t *testing.T) {
+	req := httptest.NewRequest("POST", "/hooks", strings.NewReader(string(payload)))
+	req.Header.Set(Header, Sign(secret, time.Now(), payload))
+
+	body, err := VerifyRequest(req, secret, 1024, 0)
+	require.NoError(t, err)
+	assert.Equal(t, payload, body)
+
+	req = httptest.NewRequest("POST", "/hooks", strings.NewReader(string(payload)))
+	req.Header.Set(Header, Sign(secret, time.Now(), payload))
+	_, err = VerifyRequest(req, secret, 8, 0)
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+
+	req = httptest.NewRequest("POST", "/hooks", strings.NewReader(string(payload)))
+	_, err = VerifyRequest(req, secret, 1024, 0)
+	assert.ErrorIs(t, err, ErrMissingSignature)
+}
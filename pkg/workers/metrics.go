@@ -0,0 +1,74 @@
+package workers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collector holds the metrics of a pool, labelled with its name so several
+// pools can share a registry
+type collector struct {
+	queued   prometheus.Gauge
+	running  prometheus.Gauge
+	tasks    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	rejected *prometheus.CounterVec
+}
+
+func newCollector(pool string) *collector {
+	labels := prometheus.Labels{"pool": pool}
+	return &collector{
+		queued: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "worker_pool_queued_tasks",
+			Help:        "Tasks waiting for a worker.",
+			ConstLabels: labels,
+		}),
+		running: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "worker_pool_running_tasks",
+			Help:        "Tasks being run by a worker.",
+			ConstLabels: labels,
+		}),
+		tasks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "worker_pool_tasks_total",
+			Help:        "Tasks run by result: success or failure.",
+			ConstLabels: labels,
+		}, []string{"task", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "worker_pool_task_duration_seconds",
+			Help:        "How long tasks ran.",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"task"}),
+		rejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "worker_pool_rejected_tasks_total",
+			Help:        "Tasks refused because the queue was full.",
+			ConstLabels: labels,
+		}, []string{"task"}),
+	}
+}
+
+func (c *collector) observe(task, result string, duration time.Duration) {
+	c.tasks.WithLabelValues(task, result).Inc()
+	c.duration.WithLabelValues(task).Observe(duration.Seconds())
+}
+
+// Describe implements prometheus.Collector
+func (p *Pool) Describe(ch chan<- *prometheus.Desc) {
+	c := p.metrics
+	c.queued.Describe(ch)
+	c.running.Describe(ch)
+	c.tasks.Describe(ch)
+	c.duration.Describe(ch)
+	c.rejected.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (p *Pool) Collect(ch chan<- prometheus.Metric) {
+	c := p.metrics
+	c.queued.Collect(ch)
+	c.running.Collect(ch)
+	c.tasks.Collect(ch)
+	c.duration.Collect(ch)
+	c.rejected.Collect(ch)
+}
@@ -0,0 +1,173 @@
+// Package workers runs asynchronous tasks on a bounded pool of goroutines.
+// Stopping the pool stops it taking tasks and waits for the queued and
+// running ones to finish, so work handed off by a request is not lost when
+// the process shuts down.
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"clean-architecture/pkg/logger"
+)
+
+var (
+	// ErrQueueFull is returned by Submit when every worker is busy and the
+	// queue holds QueueSize tasks
+	ErrQueueFull = errors.New("workers: queue is full")
+	// ErrStopped is returned by Submit once the pool is stopping
+	ErrStopped = errors.New("workers: pool is stopped")
+)
+
+// Results of a task, as logged and counted in worker_pool_tasks_total
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Config configures a Pool
+type Config struct {
+	// Name identifies the pool in logs and metrics
+	Name string
+	// Concurrency is the number of tasks run at once
+	Concurrency int
+	// QueueSize is the number of tasks waiting for a worker before Submit
+	// refuses more
+	QueueSize int
+}
+
+// task is a submitted function and what to call it in logs and metrics
+type task struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Pool runs submitted tasks on Concurrency goroutines
+type Pool struct {
+	cfg     Config
+	logger  logger.Logger
+	metrics *collector
+
+	mu      sync.RWMutex
+	queue   chan task
+	started bool
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// New creates a pool; tasks submitted before Start wait in the queue
+func New(cfg Config, logger logger.Logger) *Pool {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	return &Pool{
+		cfg:     cfg,
+		logger:  logger.WithField("pool", cfg.Name),
+		metrics: newCollector(cfg.Name),
+		queue:   make(chan task, cfg.QueueSize),
+	}
+}
+
+// Start runs the workers. Tasks get a context carrying the values of ctx
+// that is not cancelled with it, so they finish once taken.
+func (p *Pool) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started || p.stopped {
+		return
+	}
+	p.started = true
+
+	ctx = context.WithoutCancel(ctx)
+	for i := 0; i < p.cfg.Concurrency; i++ {
+		p.wg.Add(1)
+		go p.work(ctx)
+	}
+	p.logger.WithField("concurrency", p.cfg.Concurrency).Info("Worker pool started")
+}
+
+// Submit queues run to be called by a worker under name. It never blocks:
+// it returns ErrQueueFull when the queue is full and ErrStopped once Stop
+// was called.
+func (p *Pool) Submit(name string, run func(ctx context.Context) error) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.stopped {
+		return ErrStopped
+	}
+	select {
+	case p.queue <- task{name: name, run: run}:
+		p.metrics.queued.Inc()
+		return nil
+	default:
+		p.metrics.rejected.WithLabelValues(name).Inc()
+		return ErrQueueFull
+	}
+}
+
+// Stop stops taking tasks and waits for the queued and running ones to
+// finish. It always returns nil; the error is for shutdown reports, which
+// stop waiting when their deadline passes.
+func (p *Pool) Stop() error {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		p.wg.Wait()
+		return nil
+	}
+	p.stopped = true
+	close(p.queue)
+	started := p.started
+	p.mu.Unlock()
+
+	if !started {
+		// Nobody would run the queued tasks
+		for t := range p.queue {
+			p.metrics.queued.Dec()
+			p.logger.WithField("task", t.name).Warn("Worker pool stopped before running task")
+		}
+		return nil
+	}
+	p.wg.Wait()
+	return nil
+}
+
+// work runs queued tasks until the queue is closed and drained
+func (p *Pool) work(ctx context.Context) {
+	defer p.wg.Done()
+	for t := range p.queue {
+		p.metrics.queued.Dec()
+		p.run(ctx, t)
+	}
+}
+
+// run runs t, then logs and records the outcome. Panics fail the task
+// instead of the worker.
+func (p *Pool) run(ctx context.Context, t task) {
+	p.metrics.running.Inc()
+	started := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("task panicked: %v", r)
+			}
+		}()
+		return t.run(ctx)
+	}()
+	duration := time.Since(started)
+	p.metrics.running.Dec()
+
+	result := ResultSuccess
+	if err != nil {
+		result = ResultFailure
+		p.logger.WithFields(map[string]interface{}{
+			"task":        t.name,
+			"duration_ms": duration.Milliseconds(),
+			"error":       err.Error(),
+		}).Error("Worker pool task failed")
+	}
+	p.metrics.observe(t.name, result, duration)
+}
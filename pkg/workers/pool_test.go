@@ -0,0 +1,103 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"clean-architecture/pkg/logger"
+)
+
+func TestPool_StopDrains(t *testing.T) {
+	p := New(Config{Name: "test", Concurrency: 2, QueueSize: 10}, logger.New())
+	ctx, cancel := context.WithCancel(context.Background())
+	p.Start(ctx)
+
+	var done atomic.Int32
+	release := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		require.NoError(t, p.Submit("slow", func(ctx context.Context) error {
+			<-release
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			done.Add(1)
+			return nil
+		}))
+	}
+
+	// Cancelling the context the pool started with does not cancel tasks
+	cancel()
+	stopped := make(chan struct{})
+	go func() {
+		assert.NoError(t, p.Stop())
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("Stop returned before the tasks finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-stopped
+	assert.Equal(t, int32(5), done.Load(), "queued and running tasks finish")
+	assert.ErrorIs(t, p.Submit("late", func(ctx context.Context) error { return nil }), ErrStopped)
+}
+
+func TestPool_QueueFull(t *testing.T) {
+	p := New(Config{Name: "test", Concurrency: 1, QueueSize: 1}, logger.New())
+	run := func(ctx context.Context) error { return nil }
+
+	require.NoError(t, p.Submit("task", run))
+	assert.ErrorIs(t, p.Submit("task", run), ErrQueueFull)
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.metrics.rejected.WithLabelValues("task")))
+
+	p.Start(context.Background())
+	require.NoError(t, p.Stop())
+	assert.Equal(t, float64(1), testutil.ToFloat64(p.metrics.tasks.WithLabelValues("task", ResultSuccess)), "tasks submitted before Start run")
+}
+
+func TestPool_Failures(t *testing.T) {
+	p := New(Config{Name: "test", Concurrency: 1, QueueSize: 10}, logger.New())
+	p.Start(context.Background())
+	require.NoError(t, p.Submit("notify", func(ctx context.Context) error { return errors.New("smtp unavailable") }))
+	require.NoError(t, p.Submit("notify", func(ctx context.Context) error { panic("nil map") }))
+	require.NoError(t, p.Submit("notify", func(ctx context.Context) error { return nil }))
+	require.NoError(t, p.Stop())
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(p)
+	err := testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP worker_pool_queued_tasks Tasks waiting for a worker.
+# TYPE worker_pool_queued_tasks gauge
+worker_pool_queued_tasks{pool="test"} 0
+# HELP worker_pool_tasks_total Tasks run by result: success or failure.
+# TYPE worker_pool_tasks_total counter
+worker_pool_tasks_total{pool="test",result="failure",task="notify"} 2
+worker_pool_tasks_total{pool="test",result="success",task="notify"} 1
+`), "worker_pool_tasks_total", "worker_pool_queued_tasks")
+	assert.NoError(t, err, "panics fail the task, not the worker")
+}
+
+func TestPool_StopUnstarted(t *testing.T) {
+	p := New(Config{Name: "test", Concurrency: 1, QueueSize: 1}, logger.New())
+	var ran atomic.Bool
+	require.NoError(t, p.Submit("task", func(ctx context.Context) error {
+		ran.Store(true)
+		return nil
+	}))
+
+	require.NoError(t, p.Stop())
+	require.NoError(t, p.Stop(), "Stop may be called again")
+	p.Start(context.Background())
+	assert.False(t, ran.Load(), "a stopped pool does not start")
+}